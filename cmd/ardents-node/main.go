@@ -4,12 +4,14 @@ import (
 	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
 	"aim-chat/go-backend/internal/bootstrap/wakuconfig"
 	"aim-chat/go-backend/internal/nodeagent"
+	"aim-chat/go-backend/internal/platform/secrets"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 )
 
 const (
@@ -99,6 +101,8 @@ func runEnroll(args []string) {
 		"subject_node_group": enrollment.SubjectNodeGroup,
 		"expires_at":         enrollment.ExpiresAt,
 		"enrolled_at":        enrollment.EnrolledAt,
+		"capabilities":       enrollment.Capabilities,
+		"network_segments":   enrollment.NetworkSegments,
 	}); err != nil {
 		writeStderrln(err.Error(), exitNetworkFailed)
 	}
@@ -110,13 +114,13 @@ func runStatus(args []string) {
 	dataDir := fs.String("data-dir", ".", "node-agent data directory")
 	asJSON := fs.Bool("json", false, "emit json")
 	rpcAddr := fs.String("rpc-addr", "", "daemon rpc address host:port")
-	rpcToken := fs.String("rpc-token", "", "daemon rpc token")
+	rpcToken := fs.String("rpc-token", "", "daemon rpc token or secret:// reference")
 	if err := fs.Parse(args); err != nil {
 		writeStderrln(err.Error(), exitInvalidInput)
 	}
 
 	svc := nodeagent.New(*dataDir)
-	status, err := svc.Status(context.TODO(), *rpcAddr, *rpcToken)
+	status, err := svc.Status(context.TODO(), *rpcAddr, resolveRPCTokenFlag(*rpcToken))
 	if err != nil {
 		writeStderrln(err.Error(), exitNetworkFailed)
 		return
@@ -127,12 +131,13 @@ func runStatus(args []string) {
 		}
 	} else {
 		writeStdoutf(exitNetworkFailed,
-			"node_id=%s health=%s peer_count=%d enrolled=%v profile_id=%s\n",
+			"node_id=%s health=%s peer_count=%d enrolled=%v profile_id=%s capabilities=%s\n",
 			status.NodeID,
 			status.Health,
 			status.PeerCount,
 			status.Enrolled,
 			status.ProfileID,
+			strings.Join(status.Capabilities, ","),
 		)
 	}
 	os.Exit(exitOK)
@@ -145,7 +150,7 @@ func runDoctor(args []string) {
 	listenPort := fs.Int("listen-port", 0, "listen port override")
 	advertiseAddress := fs.String("advertise-address", "", "advertise address override")
 	rpcAddr := fs.String("rpc-addr", "127.0.0.1:8787", "daemon rpc address host:port")
-	rpcToken := fs.String("rpc-token", "", "daemon rpc token")
+	rpcToken := fs.String("rpc-token", "", "daemon rpc token or secret:// reference")
 	minPeers := fs.Int("min-peers", 1, "minimum peer count for readiness")
 	asJSON := fs.Bool("json", false, "emit json")
 	if err := fs.Parse(args); err != nil {
@@ -167,8 +172,10 @@ func runDoctor(args []string) {
 		ListenPort:       port,
 		AdvertiseAddress: adv,
 		RPCAddr:          *rpcAddr,
-		RPCToken:         *rpcToken,
+		RPCToken:         resolveRPCTokenFlag(*rpcToken),
 		MinPeers:         *minPeers,
+		StoreNodeEnabled: cfg.StoreNodeEnabled,
+		NetworkSegment:   cfg.NetworkSegment,
 	})
 	if err != nil {
 		writeStderrln(err.Error(), exitNetworkFailed)
@@ -180,6 +187,17 @@ func runDoctor(args []string) {
 		}
 	} else {
 		writeStdoutf(exitNetworkFailed, "ready=%v checks=%d\n", report.Ready, len(report.Checks))
+		if len(report.Capabilities) > 0 {
+			writeStdoutf(exitNetworkFailed, "capabilities: %s\n", strings.Join(report.Capabilities, ","))
+		}
+		if lock := report.DataDirLock; lock.Held && lock.Owner != nil {
+			writeStdoutf(exitNetworkFailed, "data_dir_lock: pid=%d host=%s heartbeat=%s stale=%v\n",
+				lock.Owner.PID, lock.Owner.Hostname, lock.Owner.HeartbeatAt.Format(time.RFC3339), lock.Stale)
+		} else if lock.Held {
+			writeStdoutln(exitNetworkFailed, "data_dir_lock: unreadable")
+		} else {
+			writeStdoutln(exitNetworkFailed, "data_dir_lock: free")
+		}
 		for _, c := range report.Checks {
 			if c.Pass {
 				writeStdoutf(exitNetworkFailed, "[PASS] %s\n", c.Name)
@@ -221,6 +239,14 @@ func writeStdoutf(exitCode int, format string, args ...any) {
 	}
 }
 
+func resolveRPCTokenFlag(value string) string {
+	token, err := secrets.Resolve(value)
+	if err != nil {
+		writeStderrln(err.Error(), exitInvalidInput)
+	}
+	return token
+}
+
 func writeStderrln(line string, exitCode int) {
 	if _, err := fmt.Fprintln(os.Stderr, line); err != nil {
 		os.Exit(exitCode)
@@ -0,0 +1,16 @@
+// Command audit-export-fields prints the field mapping of the audit export
+// as Markdown, generated from the typed events it carries.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"aim-chat/go-backend/internal/platform/auditexport"
+)
+
+func main() {
+	if _, err := fmt.Fprint(os.Stdout, auditexport.FieldMappingDoc()); err != nil {
+		os.Exit(1)
+	}
+}
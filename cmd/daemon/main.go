@@ -5,11 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"aim-chat/go-backend/internal/composition/daemonserver"
+	"aim-chat/go-backend/internal/platform/datadirlock"
 )
 
 var (
@@ -23,8 +25,11 @@ func main() {
 	rpcAddr := flag.String("rpc-addr", "127.0.0.1:8787", "JSON-RPC listen address")
 	configPath := flag.String("config", "", "Path to config.yaml (optional)")
 	dataDir := flag.String("data-dir", "", "Directory for daemon local data (optional)")
-	rpcToken := flag.String("rpc-token", "", "RPC token for Authorization/X-AIM-RPC-Token (optional)")
+	rpcToken := flag.String("rpc-token", "", "RPC token for Authorization/X-AIM-RPC-Token, preferably a secret:// reference (optional)")
 	transport := flag.String("transport", "", "Network transport override: go-waku | mock")
+	forceTakeover := flag.Bool("force-takeover", false, "take the data dir lock over from another running daemon")
+	takeOver := flag.Bool("handover", false, "take the RPC listener and data dir over from the running daemon without downtime")
+	metricsAddr := flag.String("metrics-addr", "", "serve Prometheus metrics on this address; loopback only unless an RPC token is set (optional)")
 	flag.Parse()
 	if *showVersion {
 		fmt.Printf("chat-daemon version=%s commit=%s build_date=%s\n", version, commit, buildDate)
@@ -40,14 +45,106 @@ func main() {
 		_ = os.Setenv("AIM_NETWORK_TRANSPORT", *transport)
 	}
 
+	var (
+		inherited net.Listener
+		lock      *datadirlock.Lock
+		err       error
+	)
+	if *takeOver {
+		inherited, lock = takeOverRunningDaemon(ctx, *dataDir)
+	} else {
+		lock, err = daemonserver.LockDataDir(*dataDir, *forceTakeover)
+		if err != nil {
+			log.Fatalf("chat-daemon failed to lock data dir: %v (use --force-takeover if that process is gone)", err)
+		}
+	}
+	defer func() { _ = lock.Release() }()
+	go func() {
+		select {
+		case <-lock.Lost():
+			log.Println("chat-daemon data dir lock was taken over by another process, stopping")
+			stop()
+		case <-ctx.Done():
+		}
+	}()
+
 	srv, err := daemonserver.NewRPCServerWithOptions(*rpcAddr, *configPath, *dataDir)
 	if err != nil {
 		log.Fatalf("chat-daemon failed to initialize: %v", err)
 	}
+	if inherited != nil {
+		srv.UseListener(inherited)
+	}
+	if err := srv.ServeMetrics(*metricsAddr); err != nil {
+		log.Fatalf("chat-daemon failed to set up metrics exporter: %v", err)
+	}
+	source, err := daemonserver.OfferHandover(*dataDir)
+	if err != nil {
+		log.Printf("chat-daemon handover socket unavailable, upgrades will need a restart: %v", err)
+	} else {
+		defer func() { _ = source.Close() }()
+		srv.AcceptHandovers(source.Requests())
+	}
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for {
+			select {
+			case <-reload:
+				if err := daemonserver.ReloadLogging(*configPath); err != nil {
+					log.Printf("chat-daemon log sink reload failed: %v", err)
+				}
+				if err := srv.ReloadSecrets(); err != nil {
+					log.Printf("chat-daemon secret reload failed: %v", err)
+					continue
+				}
+				log.Println("chat-daemon secrets reloaded")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	log.Println("chat-daemon starting")
 	if err := srv.Run(ctx); err != nil {
 		log.Fatalf("chat-daemon failed: %v", err)
 	}
+	if req := srv.HandedOver(); req != nil {
+		// The successor opens its own handover socket and the stores once it
+		// hears the data dir is free, so both go first.
+		_ = source.Close()
+		_ = lock.Release()
+		if err := req.Complete(); err != nil {
+			log.Printf("chat-daemon handover completion failed: %v", err)
+		}
+		log.Printf("chat-daemon handed over to pid %d", req.PID)
+		return
+	}
 	log.Println("chat-daemon stopped")
 }
+
+// takeOverRunningDaemon inherits the RPC listener of the daemon running on
+// dataDir and locks the data dir once that daemon released it. The previous
+// daemon stops serving as soon as the listener is passed, so a handover that
+// does not finish cleanly is not given up on: connections keep queueing on
+// the inherited listener while the lock is retried until the previous owner
+// let go or its lock went stale.
+func takeOverRunningDaemon(ctx context.Context, dataDir string) (net.Listener, *datadirlock.Lock) {
+	takeover, err := daemonserver.TakeOver(ctx, dataDir)
+	if err != nil {
+		log.Fatalf("chat-daemon handover failed: %v", err)
+	}
+	defer func() { _ = takeover.Close() }()
+	log.Printf("chat-daemon took over rpc listener %s, waiting for the running daemon to stop", takeover.Addr)
+	if err := takeover.WaitReleased(ctx); err != nil {
+		log.Printf("chat-daemon handover did not finish cleanly, waiting for the data dir lock: %v", err)
+	}
+	lock, err := daemonserver.LockDataDirAfterHandover(ctx, dataDir)
+	if err != nil {
+		_ = takeover.Listener.Close()
+		log.Fatalf("chat-daemon failed to lock data dir after handover: %v", err)
+	}
+	return takeover.Listener, lock
+}
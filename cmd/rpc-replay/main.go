@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/adapters/rpc/rpcrecord"
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage  `json:"result"`
+	Error  *rpcrecord.Error `json:"error"`
+}
+
+func main() {
+	var (
+		file     = flag.String("file", "", "recording produced with AIM_RPC_RECORD_PATH")
+		url      = flag.String("url", "http://127.0.0.1:8787/rpc", "daemon RPC endpoint")
+		token    = flag.String("token", os.Getenv("AIM_RPC_TOKEN"), "RPC token (defaults to AIM_RPC_TOKEN)")
+		speed    = flag.Float64("speed", 1, "timing factor: 1 keeps recorded gaps, 2 is twice as fast, 0 sends back to back")
+		maxDelay = flag.Duration("max-delay", 5*time.Second, "upper bound for a single gap between requests (0 disables)")
+		methods  = flag.String("methods", "", "comma-separated methods to replay (default: all)")
+		strict   = flag.Bool("strict", false, "compare results as well as error codes")
+		timeout  = flag.Duration("timeout", 30*time.Second, "per-request timeout")
+	)
+	flag.Parse()
+
+	if strings.TrimSpace(*file) == "" {
+		fail("file is required")
+	}
+	if *speed < 0 {
+		fail("speed must not be negative")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		fail(err.Error())
+	}
+	entries, err := rpcrecord.ReadEntries(f)
+	_ = f.Close()
+	if err != nil {
+		fail(err.Error())
+	}
+	entries = filterMethods(entries, splitCSV(*methods))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := &http.Client{Timeout: *timeout}
+	replayer := &rpcrecord.Replayer{
+		Send:           newSender(client, *url, *token),
+		Speed:          *speed,
+		MaxDelay:       *maxDelay,
+		CompareResults: *strict,
+	}
+	var sent, skipped, mismatched, failed int
+	err = replayer.Replay(ctx, entries, func(o rpcrecord.Outcome) {
+		switch {
+		case o.Skipped:
+			skipped++
+			fmt.Printf("#%d %s skipped (redacted)\n", o.Index, o.Method)
+		case o.Err != nil:
+			failed++
+			fmt.Printf("#%d %s transport error: %v\n", o.Index, o.Method, o.Err)
+		case o.Mismatch != "":
+			sent++
+			mismatched++
+			fmt.Printf("#%d %s MISMATCH: %s (%s)\n", o.Index, o.Method, o.Mismatch, o.Latency.Round(time.Millisecond))
+		default:
+			sent++
+			fmt.Printf("#%d %s ok (%s)\n", o.Index, o.Method, o.Latency.Round(time.Millisecond))
+		}
+	})
+	fmt.Printf("replayed=%d skipped=%d mismatched=%d failed=%d\n", sent, skipped, mismatched, failed)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fail(err.Error())
+	}
+	if mismatched > 0 || failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func newSender(client *http.Client, url, token string) rpcrecord.SendFunc {
+	nextID := 0
+	return func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *rpcrecord.Error, error) {
+		nextID++
+		body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: nextID, Method: method, Params: params})
+		if err != nil {
+			return nil, nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("X-AIM-RPC-Token", token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, nil, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+		}
+		var decoded rpcResponse
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return nil, nil, err
+		}
+		return decoded.Result, decoded.Error, nil
+	}
+}
+
+func filterMethods(entries []rpcrecord.Entry, methods []string) []rpcrecord.Entry {
+	if len(methods) == 0 {
+		return entries
+	}
+	allowed := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		allowed[method] = struct{}{}
+	}
+	out := entries[:0]
+	for _, entry := range entries {
+		if _, ok := allowed[entry.Method]; ok {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func splitCSV(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func fail(msg string) {
+	_, _ = fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
@@ -1,6 +1,9 @@
 package rpc
 
-import identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+import (
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/platform/i18n"
+)
 
 func rpcCapabilitiesInfo() map[string]any {
 	methods := []string{
@@ -9,8 +12,28 @@ func rpcCapabilitiesInfo() map[string]any {
 		"health_check",
 		"network.status",
 		"network.listen_addresses",
+		"network.bootstrap.stats",
+		"network.retry_policy.get",
+		methodRetryPolicySet,
+		"store.status",
 		"metrics.get",
+		"metrics.query",
 		"diagnostics.export",
+		methodAdminTokensUsage,
+		methodRPCTokenCreateGuest,
+		methodRPCTokenListGuests,
+		methodRPCTokenRevokeGuest,
+		methodManifestFetchNow,
+		methodAuditList,
+		methodAuditVerify,
+		methodAuditExportStatus,
+		methodHandoffCreate,
+		methodHandoffOpen,
+		methodHandoffImport,
+		methodConversationExportPrepare,
+		methodConversationExport,
+		methodExportConsentGrant,
+		methodConversationExportVerify,
 		identitytransport.MethodIdentityGet,
 		identitytransport.MethodIdentityCreate,
 		identitytransport.MethodIdentitySelfCard,
@@ -19,25 +42,81 @@ func rpcCapabilitiesInfo() map[string]any {
 		identitytransport.MethodIdentityMnemonic,
 		identitytransport.MethodIdentityImportSeed,
 		identitytransport.MethodIdentityChangePwd,
+		identitytransport.MethodSecurityLockout,
 		identitytransport.MethodAccountList,
 		identitytransport.MethodAccountCurrent,
 		identitytransport.MethodAccountSwitch,
 		identitytransport.MethodBackupExport,
 		identitytransport.MethodBackupRestore,
 		identitytransport.MethodDataWipe,
+		identitytransport.MethodClientStateSet,
+		identitytransport.MethodClientStateGet,
+		identitytransport.MethodClientStateDelete,
+		identitytransport.MethodStorageRotateKey,
+		identitytransport.MethodStorageRotation,
+		identitytransport.MethodStorageSnapCreate,
+		identitytransport.MethodStorageSnapList,
+		identitytransport.MethodStorageSnapRestore,
+		identitytransport.MethodSafetyFeedApply,
+		identitytransport.MethodSafetyFeedStatus,
+		identitytransport.MethodStorageVersion,
+		identitytransport.MethodUsernameClaim,
+		identitytransport.MethodUsernameRelease,
 		"contact.list",
 		"contact.verify",
 		"contact.add",
 		"contact.add_by_id",
 		"contact.remove",
+		"contact.merge",
+		"contact.update",
+		"contact.stats",
+		"contact.capabilities",
+		"contact.cleanup.suggest",
+		"contact.attestations.verify",
+		"identity.attestations.set",
+		"identity.attestations.get",
+		"contact.attachment_policy.get",
+		"contact.attachment_policy.set",
+		"contact.attachment_policy.held",
+		"contact.attachment_policy.approve",
+		"contact.sharing.get",
+		"contact.sharing.set",
+		"contact.username_display.set",
 		"message.list",
+		"message.get",
+		"message.search",
+		methodMessageAnnotate,
+		methodMessageAnnotationsList,
 		"message.send",
+		"message.send_location",
+		methodMessageSendTemplate,
 		"message.thread.send",
 		"message.thread.list",
 		"message.edit",
 		"message.delete",
+		"message.cancel",
+		"message.outbox",
+		"message.outbox.retry",
+		"message.outbox.cancel",
+		"message.deadletter.list",
+		"message.deadletter.requeue",
+		"message.pin",
+		"message.unpin",
+		"message.pins.list",
+		"state.hashes",
+		"state.hashes.exchange",
+		"message.flush",
 		"message.clear",
 		"session.init",
+		"chat.security_info",
+		"chat.flags.list",
+		"chat.flags.set",
+		"chat.list",
+		"chat.language.set",
+		"chat.media.list",
+		methodSnippetsList,
+		methodSnippetsSet,
+		methodSnippetsDelete,
 		"group.list",
 		"group.create",
 		"group.get",
@@ -48,8 +127,14 @@ func rpcCapabilitiesInfo() map[string]any {
 		"group.message.status",
 		"group.message.delete",
 		"group.members.list",
+		"group.history",
+		"group.activity.list",
 		"group.update_title",
 		"group.update_profile",
+		"group.avatar.set",
+		"group.avatar.get",
+		"group.rules.set",
+		"group.rules.ack",
 		"group.delete",
 		"group.invite",
 		"group.accept_invite",
@@ -57,6 +142,14 @@ func rpcCapabilitiesInfo() map[string]any {
 		"group.remove_member",
 		"group.promote",
 		"group.demote",
+		"group.block_member",
+		"group.member.mute",
+		"group.mode.set",
+		"group.members.privacy.set",
+		"group.pseudonym.resolve",
+		"group.transfer_ownership",
+		"group.message.pin",
+		"group.unblock_member",
 		"group.leave",
 		"channel.create",
 		"channel.get",
@@ -78,12 +171,27 @@ func rpcCapabilitiesInfo() map[string]any {
 		"channel.leave",
 		"channel.message.status",
 		"channel.message.delete",
+		"channel.comments.set",
+		"channel.comment.remove",
+		"channel.thread.lock",
+		"channel.thread.unlock",
+		"channel.thread.subscribe",
+		"channel.thread.unsubscribe",
+		"channel.thread.subscriptions",
+		"channel.stats",
+		"channel.emergency.prepare",
+		"channel.emergency.send",
 		"file.open",
 		"file.put",
 		"file.upload.init",
 		"file.upload.chunk",
 		"file.upload.status",
 		"file.upload.commit",
+		"file.download.init",
+		"file.download.chunk",
+		"file.alt_text.set",
+		"file.extend",
+		"file.thumbnail.get",
 		"blob.providers.list",
 		"blob.pin",
 		"blob.unpin",
@@ -93,22 +201,36 @@ func rpcCapabilitiesInfo() map[string]any {
 		"blob.features.set",
 		"blob.acl.get",
 		"blob.acl.set",
+		"blob.acl.override.set",
+		"blob.acl.override.get",
+		"blob.acl.override.remove",
 		"blob.preset.get",
 		"blob.preset.set",
 		"node.binding.link.create",
 		"node.binding.complete",
 		"node.binding.get",
 		"node.binding.unbind",
+		"mailbox.status",
+		"mailbox.policy.set",
+		"mailbox.flush",
+		"mailbox.purge",
 		"node.getPolicies",
 		"node.updatePolicies",
 		"privacy.get",
 		"privacy.set",
+		"privacy.pow.set",
+		"privacy.discoverability.set",
+		"privacy.cover_traffic.set",
+		"privacy.daily_summary.set",
 		"privacy.storage.get",
 		"privacy.storage.set",
 		"privacy.storage.scope.set",
 		"privacy.storage.scope.get",
 		"privacy.storage.scope.resolve",
 		"privacy.storage.scope.delete",
+		"privacy.storage.scope.list",
+		"privacy.storage.scope.bulk_set",
+		"privacy.storage.scope.bulk_delete",
 		"blocklist.list",
 		"blocklist.add",
 		"blocklist.remove",
@@ -120,5 +242,6 @@ func rpcCapabilitiesInfo() map[string]any {
 	}
 	return map[string]any{
 		"methods": methods,
+		"locales": i18n.Default().Locales(),
 	}
 }
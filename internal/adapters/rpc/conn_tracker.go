@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rpcConnTracker counts connections that were accepted but have not gone
+// idle, so a handover can wait for them before this process stops.
+type rpcConnTracker struct {
+	mu   sync.Mutex
+	busy map[net.Conn]struct{}
+}
+
+func newRPCConnTracker() *rpcConnTracker {
+	return &rpcConnTracker{busy: map[net.Conn]struct{}{}}
+}
+
+func (t *rpcConnTracker) track(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch state {
+	case http.StateNew, http.StateActive:
+		t.busy[conn] = struct{}{}
+	default:
+		delete(t.busy, conn)
+	}
+}
+
+func (t *rpcConnTracker) busyCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.busy)
+}
+
+// waitIdle returns once no connection is busy or ctx ends.
+func (t *rpcConnTracker) waitIdle(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for t.busyCount() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
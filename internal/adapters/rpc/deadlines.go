@@ -0,0 +1,156 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rpcDeadlinesEnv = "AIM_RPC_DEADLINES"
+	// rpcDeadlineDefaultKey sets the deadline for methods without their own.
+	rpcDeadlineDefaultKey = "*"
+	defaultRPCDeadline    = 30 * time.Second
+	rpcDeadlineErrorCode  = -32098
+)
+
+// unboundedRPCMethods run as long as they need unless AIM_RPC_DEADLINES
+// says otherwise: they copy or rewrite whole stores, and cutting the client
+// off would not stop them anyway.
+var unboundedRPCMethods = []string{
+	"backup.export",
+	"backup.restore",
+	"conversation.export",
+	"conversation.export.prepare",
+	"conversation.handoff.import",
+	"data.wipe",
+	"diagnostics.export",
+}
+
+// rpcDeadlines maps method names to how long a request may run. A zero
+// duration disables the deadline.
+type rpcDeadlines struct {
+	fallback time.Duration
+	methods  map[string]time.Duration
+}
+
+// loadRPCDeadlines reads AIM_RPC_DEADLINES, a comma separated list of
+// method=duration entries such as "*=30s,message.send=5s,backup.restore=1h".
+// Entries override unboundedRPCMethods; malformed ones are ignored.
+func loadRPCDeadlines() rpcDeadlines {
+	return parseRPCDeadlines(os.Getenv(rpcDeadlinesEnv))
+}
+
+func parseRPCDeadlines(raw string) rpcDeadlines {
+	cfg := rpcDeadlines{fallback: defaultRPCDeadline, methods: map[string]time.Duration{}}
+	for _, method := range unboundedRPCMethods {
+		cfg.methods[method] = 0
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		method, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		method = strings.TrimSpace(method)
+		if !ok || method == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || parsed < 0 {
+			continue
+		}
+		if method == rpcDeadlineDefaultKey {
+			cfg.fallback = parsed
+			continue
+		}
+		cfg.methods[method] = parsed
+	}
+	return cfg
+}
+
+func (d rpcDeadlines) forMethod(method string) time.Duration {
+	if timeout, ok := d.methods[method]; ok {
+		return timeout
+	}
+	return d.fallback
+}
+
+// withDeadline derives the context a request runs under.
+func (d rpcDeadlines) withDeadline(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	timeout := d.forMethod(method)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// dispatchWithDeadline runs a request until it finishes or its deadline
+// passes. A request that outlives its deadline keeps running in the
+// background, since the storage and crypto work below the service is not
+// interruptible; the client gets a timeout error instead of waiting for it.
+// Whenever a timeout is returned, late, if set, is called once the request
+// has finished, with the result the client would have got.
+func (s *Server) dispatchWithDeadline(ctx context.Context, caller, method string, rawParams json.RawMessage, late func(any, *rpcError)) (any, *rpcError) {
+	ctx, cancel := s.deadlines.withDeadline(ctx, method)
+
+	type outcome struct {
+		result any
+		err    *rpcError
+	}
+	var (
+		mu        sync.Mutex
+		abandoned bool
+	)
+	done := make(chan outcome, 1)
+	go func() {
+		defer cancel()
+		result, rpcErr := s.dispatchRPCForCaller(ctx, caller, method, rawParams)
+		mu.Lock()
+		if !abandoned {
+			done <- outcome{result: result, err: rpcErr}
+			mu.Unlock()
+			return
+		}
+		mu.Unlock()
+		if late != nil {
+			if rpcErr != nil {
+				rpcErr = rpcDeadlineError()
+			}
+			late(result, rpcErr)
+		}
+	}()
+	select {
+	case out := <-done:
+		return settleDeadlineOutcome(ctx, out.result, out.err, late)
+	case <-ctx.Done():
+	}
+	mu.Lock()
+	abandoned = true
+	mu.Unlock()
+	select {
+	case out := <-done:
+		return settleDeadlineOutcome(ctx, out.result, out.err, late)
+	default:
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, rpcDeadlineError()
+	}
+	return nil, &rpcError{Code: rpcDeadlineErrorCode, Message: "request cancelled"}
+}
+
+// settleDeadlineOutcome reports a request that finished in time as it is.
+// One that failed once its context was done counts as timed out.
+func settleDeadlineOutcome(ctx context.Context, result any, rpcErr *rpcError, late func(any, *rpcError)) (any, *rpcError) {
+	if rpcErr == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, rpcErr
+	}
+	if late != nil {
+		late(nil, rpcDeadlineError())
+	}
+	return nil, rpcDeadlineError()
+}
+
+func rpcDeadlineError() *rpcError {
+	return &rpcError{Code: rpcDeadlineErrorCode, Message: "request deadline exceeded"}
+}
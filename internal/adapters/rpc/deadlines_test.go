@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestParseRPCDeadlines(t *testing.T) {
+	cfg := parseRPCDeadlines(" *=10s, message.send=2s,backup.restore=0,bad,group.send=soon,=1s")
+	if got := cfg.forMethod("contact.list"); got != 10*time.Second {
+		t.Fatalf("unexpected default deadline: %s", got)
+	}
+	if got := cfg.forMethod("message.send"); got != 2*time.Second {
+		t.Fatalf("unexpected message.send deadline: %s", got)
+	}
+	if got := cfg.forMethod("backup.restore"); got != 0 {
+		t.Fatalf("expected backup.restore deadline to be disabled, got %s", got)
+	}
+	if got := cfg.forMethod("group.send"); got != 10*time.Second {
+		t.Fatalf("malformed entry should fall back to the default, got %s", got)
+	}
+	if got := parseRPCDeadlines("").forMethod("message.send"); got != defaultRPCDeadline {
+		t.Fatalf("unexpected built-in default: %s", got)
+	}
+	if got := parseRPCDeadlines("*=10s").forMethod("backup.export"); got != 0 {
+		t.Fatalf("expected backup.export to run without a deadline, got %s", got)
+	}
+	if got := parseRPCDeadlines("backup.export=1m").forMethod("backup.export"); got != time.Minute {
+		t.Fatalf("expected the configured backup.export deadline to win, got %s", got)
+	}
+}
+
+func TestDispatchWithDeadlineReturnsTimeoutCode(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+	t.Setenv(rpcDeadlinesEnv, "channel.send=20ms")
+
+	release := make(chan struct{})
+	defer close(release)
+	svc := &channelMockService{
+		getGroupFn: func(groupID string) (groupdomain.Group, error) {
+			return groupdomain.Group{ID: groupID, Title: "[channel:public] General"}, nil
+		},
+		getIdentityFn: func() (models.Identity, error) {
+			return models.Identity{ID: "aim1admin"}, nil
+		},
+		listMembersFn: func(groupID string) ([]groupdomain.GroupMember, error) {
+			return []groupdomain.GroupMember{
+				{GroupID: groupID, MemberID: "aim1admin", Role: groupdomain.GroupMemberRoleAdmin, Status: groupdomain.GroupMemberStatusActive},
+			}, nil
+		},
+		sendGroupMessageFn: func(groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+			<-release
+			return groupdomain.GroupMessageFanoutResult{GroupID: groupID}, nil
+		},
+	}
+	s := newServerWithService(DefaultRPCAddr, svc, "", false)
+
+	params, _ := json.Marshal([]string{"g1", "hello"})
+	_, rpcErr := s.dispatchWithDeadline(context.Background(), s.rpcCallerNamespace(""), "channel.send", params, nil)
+	if rpcErr == nil || rpcErr.Code != rpcDeadlineErrorCode {
+		t.Fatalf("expected deadline error, got %+v", rpcErr)
+	}
+
+	result, rpcErr := s.dispatchWithDeadline(context.Background(), s.rpcCallerNamespace(""), "health_check", nil, nil)
+	if rpcErr != nil || result == nil {
+		t.Fatalf("unexpected health_check outcome: %v %+v", result, rpcErr)
+	}
+}
+
+func TestRPCIdempotencyKeyHoldsTimedOutRequestUntilItFinishes(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+	t.Setenv(rpcDeadlinesEnv, "channel.send=20ms")
+
+	release := make(chan struct{})
+	var sends atomic.Int32
+	svc := &channelMockService{
+		getGroupFn: func(groupID string) (groupdomain.Group, error) {
+			return groupdomain.Group{ID: groupID, Title: "[channel:public] General"}, nil
+		},
+		getIdentityFn: func() (models.Identity, error) {
+			return models.Identity{ID: "aim1admin"}, nil
+		},
+		listMembersFn: func(groupID string) ([]groupdomain.GroupMember, error) {
+			return []groupdomain.GroupMember{
+				{GroupID: groupID, MemberID: "aim1admin", Role: groupdomain.GroupMemberRoleAdmin, Status: groupdomain.GroupMemberStatusActive},
+			}, nil
+		},
+		sendGroupMessageFn: func(groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+			sends.Add(1)
+			<-release
+			return groupdomain.GroupMessageFanoutResult{GroupID: groupID}, nil
+		},
+	}
+	s := newServerWithService(DefaultRPCAddr, svc, "", false)
+	call := func() rpcResponse {
+		req := httptest.NewRequest("POST", "/rpc", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"channel.send","params":["g1","hello"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(rpcIdempotencyHeader, "idem-slow")
+		rec := httptest.NewRecorder()
+		s.HandleRPC(rec, req)
+		var resp rpcResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp
+	}
+
+	if resp := call(); resp.Error == nil || resp.Error.Code != rpcDeadlineErrorCode {
+		t.Fatalf("expected the first call to time out, got %+v", resp)
+	}
+	if resp := call(); resp.Error == nil || resp.Error.Code != rpcDeadlineErrorCode {
+		t.Fatalf("expected the retry to time out waiting for the first call, got %+v", resp)
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp := call()
+		if resp.Error == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the first call's result once it finished, got %+v", resp.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := sends.Load(); got != 1 {
+		t.Fatalf("expected the send to run once, ran %d times", got)
+	}
+}
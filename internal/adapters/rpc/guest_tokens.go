@@ -0,0 +1,204 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rpcGuestNamespacePrefix = "guest:"
+
+	defaultGuestTokenTTL = 24 * time.Hour
+	maxGuestTokenTTL     = 7 * 24 * time.Hour
+	maxGuestTokens       = 100
+)
+
+const (
+	rpcGuestScopeConversationRead = "conversation.read"
+	rpcGuestScopeGroupRead        = "group.read"
+	rpcGuestScopeGroupPost        = "group.post"
+)
+
+// rpcGuestScopeMethods lists what each guest scope may call. Every method
+// takes the conversation or group id as its first positional param, which
+// must match the token target.
+var rpcGuestScopeMethods = map[string][]string{
+	rpcGuestScopeConversationRead: {"message.list"},
+	rpcGuestScopeGroupRead:        {"group.messages.list"},
+	rpcGuestScopeGroupPost:        {"group.send"},
+}
+
+type rpcGuestToken struct {
+	ID        string    `json:"id"`
+	Scope     string    `json:"scope"`
+	TargetID  string    `json:"target_id"`
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// rpcGuestTokenStore holds short-lived tokens scoped to a single
+// conversation or group. Tokens are kept in memory only, so a daemon restart
+// revokes all of them.
+type rpcGuestTokenStore struct {
+	mu      sync.Mutex
+	byToken map[string]rpcGuestToken
+}
+
+func newRPCGuestTokenStore() *rpcGuestTokenStore {
+	return &rpcGuestTokenStore{byToken: map[string]rpcGuestToken{}}
+}
+
+func (g *rpcGuestTokenStore) create(scope, targetID, label string, ttl time.Duration, now time.Time) (string, rpcGuestToken, error) {
+	scope = strings.TrimSpace(scope)
+	targetID = strings.TrimSpace(targetID)
+	if _, ok := rpcGuestScopeMethods[scope]; !ok {
+		return "", rpcGuestToken{}, errors.New("unknown guest token scope")
+	}
+	if targetID == "" {
+		return "", rpcGuestToken{}, errors.New("guest token target is required")
+	}
+	if ttl <= 0 {
+		ttl = defaultGuestTokenTTL
+	}
+	if ttl > maxGuestTokenTTL {
+		return "", rpcGuestToken{}, errors.New("guest token lifetime exceeds 7 days")
+	}
+	token, err := randomHex(32)
+	if err != nil {
+		return "", rpcGuestToken{}, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return "", rpcGuestToken{}, err
+	}
+	guest := rpcGuestToken{
+		ID:        "gst_" + id,
+		Scope:     scope,
+		TargetID:  targetID,
+		Label:     strings.TrimSpace(label),
+		CreatedAt: now.UTC(),
+		ExpiresAt: now.Add(ttl).UTC(),
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pruneLocked(now)
+	if len(g.byToken) >= maxGuestTokens {
+		return "", rpcGuestToken{}, errors.New("too many active guest tokens")
+	}
+	g.byToken["guest_"+token] = guest
+	return "guest_" + token, guest, nil
+}
+
+// lookup returns the unexpired guest token for a presented credential.
+func (g *rpcGuestTokenStore) lookup(token string, now time.Time) (rpcGuestToken, bool) {
+	if g == nil || token == "" {
+		return rpcGuestToken{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	guest, ok := g.byToken[token]
+	if !ok || !now.Before(guest.ExpiresAt) {
+		return rpcGuestToken{}, false
+	}
+	return guest, true
+}
+
+func (g *rpcGuestTokenStore) get(id string, now time.Time) (rpcGuestToken, bool) {
+	if g == nil {
+		return rpcGuestToken{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, guest := range g.byToken {
+		if guest.ID == id && now.Before(guest.ExpiresAt) {
+			return guest, true
+		}
+	}
+	return rpcGuestToken{}, false
+}
+
+func (g *rpcGuestTokenStore) list(now time.Time) []rpcGuestToken {
+	out := make([]rpcGuestToken, 0)
+	if g == nil {
+		return out
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pruneLocked(now)
+	for _, guest := range g.byToken {
+		out = append(out, guest)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ExpiresAt.Before(out[j].ExpiresAt) })
+	return out
+}
+
+func (g *rpcGuestTokenStore) revoke(id string) bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for token, guest := range g.byToken {
+		if guest.ID == id {
+			delete(g.byToken, token)
+			return true
+		}
+	}
+	return false
+}
+
+func (g *rpcGuestTokenStore) pruneLocked(now time.Time) {
+	for token, guest := range g.byToken {
+		if !now.Before(guest.ExpiresAt) {
+			delete(g.byToken, token)
+		}
+	}
+}
+
+// authorizeGuestCall rejects anything outside the guest token's scope.
+// Capability discovery stays available so guest clients can negotiate.
+func (s *Server) authorizeGuestCall(guestID, method string, rawParams json.RawMessage) *rpcError {
+	denied := &rpcError{Code: -32337, Message: "guest token does not allow this call"}
+	guest, ok := s.guests.get(guestID, time.Now())
+	if !ok {
+		return denied
+	}
+	switch method {
+	case "rpc.version", "rpc.capabilities", "health_check":
+		return nil
+	}
+	allowed := false
+	for _, scoped := range rpcGuestScopeMethods[guest.Scope] {
+		if scoped == method {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return denied
+	}
+	var params []json.RawMessage
+	var targetID string
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params) == 0 {
+		return denied
+	}
+	if err := json.Unmarshal(params[0], &targetID); err != nil || strings.TrimSpace(targetID) != guest.TargetID {
+		return denied
+	}
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// guestReadMockService tells side-effect-free listing apart from owner
+// reads, which mark messages read and send receipts.
+type guestReadMockService struct {
+	*channelMockService
+	ownerReads int
+	peeks      int
+}
+
+func (m *guestReadMockService) GetMessages(_ string, _, _ int) ([]models.Message, error) {
+	m.ownerReads++
+	return []models.Message{}, nil
+}
+
+func (m *guestReadMockService) PeekMessages(_ string, _, _ int) ([]models.Message, error) {
+	m.peeks++
+	return []models.Message{}, nil
+}
+
+func postGuestRPC(t *testing.T, s *Server, token, method string, params any) (int, rpcResponse) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewReader(body))
+	req.Header.Set("X-AIM-RPC-Token", token)
+	rec := httptest.NewRecorder()
+	s.HandleRPC(rec, req)
+	var resp rpcResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func TestGuestTokenIsScopedToOneConversation(t *testing.T) {
+	svc := &guestReadMockService{channelMockService: &channelMockService{}}
+	s := newServerWithService(DefaultRPCAddr, svc, "primary-token", true)
+
+	_, resp := postGuestRPC(t, s, "primary-token", methodRPCTokenCreateGuest, map[string]any{
+		"scope": rpcGuestScopeConversationRead, "target_id": "aim1alice", "label": "auditor",
+	})
+	if resp.Error != nil {
+		t.Fatalf("create guest failed: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var created struct {
+		Token string        `json:"token"`
+		Guest rpcGuestToken `json:"guest"`
+	}
+	_ = json.Unmarshal(raw, &created)
+	if created.Token == "" || created.Guest.ExpiresAt.Sub(created.Guest.CreatedAt) != defaultGuestTokenTTL {
+		t.Fatalf("unexpected guest token: %+v", created)
+	}
+
+	if code, resp := postGuestRPC(t, s, created.Token, "message.list", []any{"aim1alice", 20, 0}); code != http.StatusOK || resp.Error != nil {
+		t.Fatalf("scoped read failed: code=%d err=%+v", code, resp.Error)
+	}
+	if svc.peeks != 1 || svc.ownerReads != 0 {
+		t.Fatalf("guest reads must not mark messages read: peeks=%d owner_reads=%d", svc.peeks, svc.ownerReads)
+	}
+	if _, resp := postGuestRPC(t, s, created.Token, "message.list", []any{"aim1bob", 20, 0}); resp.Error == nil || resp.Error.Code != -32337 {
+		t.Fatalf("expected other conversation to be denied, got %+v", resp.Error)
+	}
+	if _, resp := postGuestRPC(t, s, created.Token, "message.send", []any{"aim1alice", "hi"}); resp.Error == nil || resp.Error.Code != -32337 {
+		t.Fatalf("expected send to be denied, got %+v", resp.Error)
+	}
+	if _, resp := postGuestRPC(t, s, created.Token, methodRPCTokenCreateGuest, map[string]any{"scope": rpcGuestScopeGroupPost, "target_id": "g1"}); resp.Error == nil {
+		t.Fatal("guest must not mint tokens")
+	}
+	stream := httptest.NewRequest(http.MethodGet, "/rpc/stream", nil)
+	stream.Header.Set("X-AIM-RPC-Token", created.Token)
+	rec := httptest.NewRecorder()
+	s.HandleRPCStream(rec, stream)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("guest must not subscribe to notifications, got %d", rec.Code)
+	}
+
+	if _, resp := postGuestRPC(t, s, "primary-token", methodRPCTokenRevokeGuest, []string{created.Guest.ID}); resp.Error != nil {
+		t.Fatalf("revoke failed: %+v", resp.Error)
+	}
+	if code, _ := postGuestRPC(t, s, created.Token, "message.list", []any{"aim1alice", 20, 0}); code != http.StatusUnauthorized {
+		t.Fatalf("revoked token must be rejected, got %d", code)
+	}
+}
+
+func TestGuestTokenExpires(t *testing.T) {
+	store := newRPCGuestTokenStore()
+	now := time.Now()
+	token, guest, err := store.create(rpcGuestScopeGroupPost, "g1", "", time.Hour, now)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, ok := store.lookup(token, now.Add(59*time.Minute)); !ok {
+		t.Fatal("token must be valid before expiry")
+	}
+	if _, ok := store.lookup(token, now.Add(time.Hour)); ok {
+		t.Fatal("token must expire")
+	}
+	if list := store.list(now.Add(2 * time.Hour)); len(list) != 0 {
+		t.Fatalf("expired token must be pruned: %+v", list)
+	}
+	if store.revoke(guest.ID) {
+		t.Fatal("pruned token cannot be revoked")
+	}
+	if _, _, err := store.create(rpcGuestScopeGroupPost, "g1", "", maxGuestTokenTTL+time.Second, now); err == nil {
+		t.Fatal("expected lifetime limit")
+	}
+}
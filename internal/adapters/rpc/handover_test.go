@@ -0,0 +1,146 @@
+//go:build !windows
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/platform/handover"
+)
+
+type handoverMockService struct {
+	channelMockService
+	stopped atomic.Bool
+	closed  atomic.Bool
+}
+
+func (m *handoverMockService) StopNetworking(context.Context) error {
+	m.stopped.Store(true)
+	return nil
+}
+
+func (m *handoverMockService) Close() error {
+	m.closed.Store(true)
+	return nil
+}
+
+// TestUpgradeHandoverKeepsServingRPC runs the upgrade sequence of two daemon
+// processes inside one test: clients keep calling while the running server
+// hands its listener to a successor.
+func TestUpgradeHandoverKeepsServingRPC(t *testing.T) {
+	dataDir := t.TempDir()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	oldService := &handoverMockService{}
+	oldServer := newServerWithService(addr, oldService, "", false)
+	oldServer.UseListener(ln)
+	source, err := handover.Listen(dataDir)
+	if err != nil {
+		t.Fatalf("offer handover: %v", err)
+	}
+	oldServer.AcceptHandovers(source.Requests())
+	oldDone := make(chan error, 1)
+	go func() { oldDone <- oldServer.Run(context.Background()) }()
+
+	var (
+		calls    atomic.Int64
+		failures atomic.Int64
+		stop     = make(chan struct{})
+		wg       sync.WaitGroup
+	)
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "health_check"})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				resp, err := client.Post("http://"+addr+"/rpc", "application/json", bytes.NewReader(body))
+				if err != nil {
+					failures.Add(1)
+					t.Logf("rpc call failed: %v", err)
+					continue
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					failures.Add(1)
+					continue
+				}
+				calls.Add(1)
+			}
+		}()
+	}
+	waitForCalls(t, &calls, 20)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	takeover, err := handover.Take(ctx, dataDir)
+	if err != nil {
+		t.Fatalf("take listener: %v", err)
+	}
+	if err := <-oldDone; err != nil {
+		t.Fatalf("old server run: %v", err)
+	}
+	req := oldServer.HandedOver()
+	if req == nil || !oldService.stopped.Load() || !oldService.closed.Load() {
+		t.Fatalf("old server must stop networking, close the service and report the handover: req=%v", req)
+	}
+	_ = source.Close()
+	if err := req.Complete(); err != nil {
+		t.Fatalf("complete handover: %v", err)
+	}
+	if err := takeover.WaitReleased(ctx); err != nil {
+		t.Fatalf("wait released: %v", err)
+	}
+	_ = takeover.Close()
+
+	newService := &handoverMockService{}
+	newServer := newServerWithService(addr, newService, "", false)
+	newServer.UseListener(takeover.Listener)
+	newCtx, stopNew := context.WithCancel(context.Background())
+	newDone := make(chan error, 1)
+	go func() { newDone <- newServer.Run(newCtx) }()
+
+	before := calls.Load()
+	waitForCalls(t, &calls, before+20)
+	close(stop)
+	wg.Wait()
+	stopNew()
+	if err := <-newDone; err != nil {
+		t.Fatalf("new server run: %v", err)
+	}
+	if !newService.closed.Load() {
+		t.Fatal("new server must close the service when it stops")
+	}
+	if n := failures.Load(); n != 0 {
+		t.Fatalf("%d rpc calls failed during the upgrade", n)
+	}
+}
+
+func waitForCalls(t *testing.T, calls *atomic.Int64, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d rpc calls succeeded", calls.Load(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// hookGroupTargetPrefix marks a hook target as a group id instead of a
+// contact id.
+const hookGroupTargetPrefix = "group:"
+
+var errHookAttachmentToGroup = errors.New("attachments can only be sent to contacts")
+
+type hookAttachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+// hookSendRequest is the body of POST /hooks/send. Target is a contact id
+// or "group:<group_id>"; the attachment data is base64.
+type hookSendRequest struct {
+	Target         string          `json:"target"`
+	Text           string          `json:"text"`
+	Attachment     *hookAttachment `json:"attachment,omitempty"`
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`
+}
+
+// handleHookSend lets scripts and CI jobs send a message with one plain JSON
+// POST instead of a JSON-RPC call. It takes the RPC or an integration token
+// and goes through message.send or group.send, so group gating, quotas and
+// idempotency keys work as they do for /rpc.
+func (s *Server) handleHookSend(w http.ResponseWriter, r *http.Request) {
+	if !s.applyCORS(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := s.extractRPCToken(r)
+	if !s.rpcLimiter.allow(rpcRateLimitKey(r, token), time.Now()) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if !s.authorizeTokenOrLoopback(w, r) {
+		return
+	}
+	caller := s.rpcCallerNamespace(token)
+	if wait, ok := s.usage.admit(caller, time.Now()); !ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(wait))
+		http.Error(w, "token quota exceeded", http.StatusTooManyRequests)
+		return
+	}
+	metered := &meteredResponseWriter{ResponseWriter: w}
+	body := &countingReadCloser{ReadCloser: http.MaxBytesReader(w, r.Body, maxRPCBodyBytes)}
+	w, r.Body = metered, body
+	defer func() {
+		s.usage.record(caller, body.bytes, metered.bytes, metered.failed(), time.Now())
+	}()
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	var req hookSendRequest
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil || strings.TrimSpace(req.Target) == "" || strings.TrimSpace(req.Text) == "" {
+		writeHookResponse(w, rpcResponse{Error: &rpcError{Code: -32602, Message: "invalid params"}})
+		return
+	}
+	if s.service == nil {
+		writeHookResponse(w, rpcResponse{Error: &rpcError{Code: -32099, Message: "service is not initialized"}})
+		return
+	}
+
+	rawKey := r.Header.Get(rpcIdempotencyHeader)
+	if strings.TrimSpace(rawKey) == "" {
+		rawKey = req.IdempotencyKey
+	}
+	idempotencyKey := rpcIdempotencyKey(rawKey, token)
+	requestHash := ""
+	if idempotencyKey != "" {
+		requestHash = rpcRequestHash(rpcRequest{Method: "hooks.send", Params: raw})
+		if resp, handled := s.beginIdempotentRequest(r.Context(), "message.send", idempotencyKey, requestHash); handled {
+			writeHookResponse(w, resp)
+			return
+		}
+	}
+
+	reqID := resolveRPCRequestID(r, nil)
+	w.Header().Set(rpcRequestIDHeader, reqID)
+	started := time.Now()
+	var late func(any, *rpcError)
+	if idempotencyKey != "" {
+		late = func(result any, rpcErr *rpcError) {
+			s.finishIdempotentRequest(idempotencyKey, rpcResponse{Result: result, Error: rpcErr})
+		}
+	}
+	result, rpcErr := s.dispatchHookSend(r, caller, req, late)
+	if rpcErr != nil {
+		slog.Default().Error("hook send failed", "correlation_id", reqID, "caller", caller, "rpc_code", rpcErr.Code, "latency_ms", time.Since(started).Milliseconds())
+	} else {
+		slog.Default().Info("hook send", "correlation_id", reqID, "caller", caller, "latency_ms", time.Since(started).Milliseconds())
+	}
+	resp := rpcResponse{Result: result, Error: rpcErr}
+	if idempotencyKey != "" && (rpcErr == nil || rpcErr.Code != rpcDeadlineErrorCode) {
+		s.finishIdempotentRequest(idempotencyKey, resp)
+	}
+	writeHookResponse(w, resp)
+}
+
+// dispatchHookSend stores the attachment, if any, and sends the message
+// through the same methods a JSON-RPC client would call. late is passed on
+// to dispatchWithDeadline for the send.
+func (s *Server) dispatchHookSend(r *http.Request, caller string, req hookSendRequest, late func(any, *rpcError)) (any, *rpcError) {
+	target := strings.TrimSpace(req.Target)
+	if groupID, ok := strings.CutPrefix(target, hookGroupTargetPrefix); ok {
+		if req.Attachment != nil {
+			return nil, &rpcError{Code: -32602, Message: errHookAttachmentToGroup.Error()}
+		}
+		params, _ := json.Marshal([]string{strings.TrimSpace(groupID), req.Text})
+		return s.dispatchWithDeadline(r.Context(), caller, "group.send", params, late)
+	}
+	sendParams := []any{target, req.Text}
+	if req.Attachment != nil {
+		putParams, _ := json.Marshal([]string{req.Attachment.Name, req.Attachment.MimeType, req.Attachment.Data})
+		var putLate func(any, *rpcError)
+		if late != nil {
+			// The message is never sent then, so the hook has no outcome.
+			putLate = func(any, *rpcError) { late(nil, rpcDeadlineError()) }
+		}
+		stored, rpcErr := s.dispatchWithDeadline(r.Context(), caller, "file.put", putParams, putLate)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		var meta struct {
+			ID string `json:"id"`
+		}
+		if encoded, err := json.Marshal(stored); err != nil || json.Unmarshal(encoded, &meta) != nil || meta.ID == "" {
+			return nil, &rpcError{Code: -32060, Message: "attachment was not stored"}
+		}
+		sendParams = append(sendParams, []string{meta.ID})
+	}
+	params, _ := json.Marshal(sendParams)
+	return s.dispatchWithDeadline(r.Context(), caller, "message.send", params, late)
+}
+
+// writeHookResponse answers with the plain result, or with the error and a
+// status a shell script can branch on.
+func writeHookResponse(w http.ResponseWriter, resp rpcResponse) {
+	if metered, ok := w.(*meteredResponseWriter); ok && resp.Error != nil {
+		metered.rpcFailed = true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Error == nil {
+		_ = json.NewEncoder(w).Encode(resp.Result)
+		return
+	}
+	status := http.StatusUnprocessableEntity
+	switch resp.Error.Code {
+	case -32602:
+		status = http.StatusBadRequest
+	case -32082:
+		status = http.StatusConflict
+	case -32099:
+		status = http.StatusServiceUnavailable
+	case rpcDeadlineErrorCode:
+		status = http.StatusGatewayTimeout
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": resp.Error})
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type hookMockService struct {
+	channelMockService
+	sent       []string
+	groupSends []string
+}
+
+func (m *hookMockService) SendMessage(_ context.Context, contactID, content string) (string, error) {
+	m.sent = append(m.sent, contactID+"|"+content)
+	return "msg-1", nil
+}
+
+func (m *hookMockService) SendMessageWithAttachments(_ context.Context, contactID, content string, attachmentIDs []string) (string, error) {
+	m.sent = append(m.sent, contactID+"|"+content+"|"+strings.Join(attachmentIDs, ","))
+	return "msg-2", nil
+}
+
+func (m *hookMockService) PutAttachment(name, mimeType, _ string) (models.AttachmentMeta, error) {
+	return models.AttachmentMeta{ID: "att-1", Name: name, MimeType: mimeType}, nil
+}
+
+func (m *hookMockService) SendGroupMessage(_ context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+	m.groupSends = append(m.groupSends, groupID+"|"+content)
+	return groupdomain.GroupMessageFanoutResult{GroupID: groupID}, nil
+}
+
+func postHook(s *Server, remoteAddr, token, idempotencyKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/hooks/send", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(rpcIdempotencyHeader, idempotencyKey)
+	}
+	rec := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHookSendRoutesToMessageAndGroupSend(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+	t.Setenv("AIM_GROUPS_ENABLED", "true")
+	t.Setenv(rpcIntegrationTokensEnv, "ci=ci-token")
+
+	svc := &hookMockService{}
+	s := newServerWithService(DefaultRPCAddr, svc, "secret-token", true)
+
+	if rec := postHook(s, "127.0.0.1:5000", "", "", `{"target":"aim1bob","text":"hi"}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected hook without token to be refused, got %d", rec.Code)
+	}
+	rec := postHook(s, "192.0.2.10:5000", "ci-token", "build-42", `{"target":"aim1bob","text":"build green"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"message_id":"msg-1"`) {
+		t.Fatalf("unexpected hook response: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := postHook(s, "192.0.2.10:5000", "ci-token", "build-42", `{"target":"aim1bob","text":"build green"}`); rec.Code != http.StatusOK {
+		t.Fatalf("retry must replay the first response, got %d", rec.Code)
+	}
+	if len(svc.sent) != 1 {
+		t.Fatalf("idempotent retry must not send again: %v", svc.sent)
+	}
+	if rec := postHook(s, "192.0.2.10:5000", "ci-token", "build-42", `{"target":"aim1bob","text":"build red"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("key reuse with another body must conflict, got %d", rec.Code)
+	}
+
+	rec = postHook(s, "192.0.2.10:5000", "ci-token", "", `{"target":"aim1bob","text":"log","attachment":{"name":"log.txt","mime_type":"text/plain","data":"aGk="}}`)
+	if rec.Code != http.StatusOK || svc.sent[1] != "aim1bob|log|att-1" {
+		t.Fatalf("attachment must be stored and sent: %d %s %v", rec.Code, rec.Body.String(), svc.sent)
+	}
+
+	if rec := postHook(s, "192.0.2.10:5000", "ci-token", "", `{"target":"group:g1","text":"deployed"}`); rec.Code != http.StatusOK {
+		t.Fatalf("group hook failed: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(svc.groupSends) != 1 || svc.groupSends[0] != "g1|deployed" {
+		t.Fatalf("unexpected group sends: %v", svc.groupSends)
+	}
+	if rec := postHook(s, "192.0.2.10:5000", "ci-token", "", `{"target":"group:g1","text":"x","attachment":{"name":"a","mime_type":"text/plain","data":"aGk="}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("group attachment must be rejected, got %d", rec.Code)
+	}
+	if rec := postHook(s, "192.0.2.10:5000", "ci-token", "", `{"target":"aim1bob"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing text must be rejected, got %d", rec.Code)
+	}
+}
+
+func TestHookSendWithoutAuthIsLoopbackOnly(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	s := newServerWithService(DefaultRPCAddr, &hookMockService{}, "", false)
+	if rec := postHook(s, "192.0.2.10:5000", "", "", `{"target":"aim1bob","text":"hi"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected remote hook to be forbidden, got %d", rec.Code)
+	}
+	if rec := postHook(s, "127.0.0.1:5000", "", "", `{"target":"aim1bob","text":"hi"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected loopback hook to succeed, got %d", rec.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -20,13 +21,63 @@ type rpcIdempotencyEntry struct {
 	createdAt   time.Time
 }
 
+// rpcIdempotencyFlight is a request whose key is taken but whose outcome is
+// not known yet. done is closed once response is set.
+type rpcIdempotencyFlight struct {
+	requestHash string
+	done        chan struct{}
+	response    rpcResponse
+}
+
 type rpcIdempotencyCache struct {
-	entries map[string]rpcIdempotencyEntry
+	entries  map[string]rpcIdempotencyEntry
+	inFlight map[string]*rpcIdempotencyFlight
 }
 
 func newRPCIdempotencyCache() *rpcIdempotencyCache {
 	return &rpcIdempotencyCache{
-		entries: make(map[string]rpcIdempotencyEntry),
+		entries:  make(map[string]rpcIdempotencyEntry),
+		inFlight: make(map[string]*rpcIdempotencyFlight),
+	}
+}
+
+// begin looks cacheKey up before a request runs. It returns the cached
+// response if there is one, or the flight of a request with the same key
+// that is still running. Otherwise the key is marked in flight and the
+// caller must report the outcome with finish.
+func (c *rpcIdempotencyCache) begin(cacheKey, requestHash string, now time.Time) (resp rpcResponse, running *rpcIdempotencyFlight, found, conflict bool) {
+	if c == nil {
+		return rpcResponse{}, nil, false, false
+	}
+	if flight, ok := c.inFlight[cacheKey]; ok {
+		if flight.requestHash != requestHash {
+			return rpcResponse{}, nil, false, true
+		}
+		return rpcResponse{}, flight, false, false
+	}
+	if resp, found, conflict = c.get(cacheKey, requestHash, now); found || conflict {
+		return resp, nil, found, conflict
+	}
+	c.inFlight[cacheKey] = &rpcIdempotencyFlight{requestHash: requestHash, done: make(chan struct{})}
+	return rpcResponse{}, nil, false, false
+}
+
+// finish records the outcome of the request begin let run and wakes the
+// retries waiting for it. A timeout is not an outcome, so it is handed to
+// the waiters but not cached, and the next retry runs the request again.
+func (c *rpcIdempotencyCache) finish(cacheKey string, resp rpcResponse, now time.Time) {
+	if c == nil {
+		return
+	}
+	flight, ok := c.inFlight[cacheKey]
+	if !ok {
+		return
+	}
+	delete(c.inFlight, cacheKey)
+	flight.response = resp
+	close(flight.done)
+	if resp.Error == nil || resp.Error.Code != rpcDeadlineErrorCode {
+		c.set(cacheKey, flight.requestHash, resp, now)
 	}
 }
 
@@ -82,6 +133,40 @@ func (c *rpcIdempotencyCache) prune(now time.Time) {
 	}
 }
 
+// beginIdempotentRequest takes the idempotency key for a request about to
+// run. It returns true with the response to send when the request must not
+// run: the key was used for another payload, the outcome is cached, or a
+// request with the key is still running, in which case its outcome is waited
+// for as long as this request may run. Otherwise the caller runs the request
+// and reports the outcome with finishIdempotentRequest.
+func (s *Server) beginIdempotentRequest(ctx context.Context, method, key, requestHash string) (rpcResponse, bool) {
+	s.idempotencyMu.Lock()
+	cached, running, found, conflict := s.idempotency.begin(key, requestHash, time.Now().UTC())
+	s.idempotencyMu.Unlock()
+	switch {
+	case conflict:
+		return rpcResponse{Error: &rpcError{Code: -32082, Message: "idempotency key reuse with different request payload"}}, true
+	case found:
+		return cached, true
+	case running != nil:
+		waitCtx, cancel := s.deadlines.withDeadline(ctx, method)
+		defer cancel()
+		select {
+		case <-running.done:
+			return running.response, true
+		case <-waitCtx.Done():
+			return rpcResponse{Error: rpcDeadlineError()}, true
+		}
+	}
+	return rpcResponse{}, false
+}
+
+func (s *Server) finishIdempotentRequest(key string, resp rpcResponse) {
+	s.idempotencyMu.Lock()
+	s.idempotency.finish(key, resp, time.Now().UTC())
+	s.idempotencyMu.Unlock()
+}
+
 func rpcIdempotencyKey(raw string, authToken string) string {
 	key := strings.TrimSpace(raw)
 	if key == "" {
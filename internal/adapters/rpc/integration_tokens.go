@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/platform/secrets"
+)
+
+const rpcIntegrationTokensEnv = "AIM_RPC_INTEGRATION_TOKENS"
+
+// loadRPCIntegrationTokens parses "name=token" pairs that let bots and bridges
+// authenticate with their own credential instead of the primary RPC token.
+// Tokens may be secret:// references. The result maps each token to its
+// integration name.
+func loadRPCIntegrationTokens() map[string]string {
+	out := map[string]string{}
+	for _, entry := range strings.Split(os.Getenv(rpcIntegrationTokensEnv), ",") {
+		name, token, found := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		token = strings.TrimSpace(token)
+		if !found || name == "" || token == "" {
+			continue
+		}
+		resolved, err := secrets.Resolve(token)
+		if err != nil {
+			slog.Default().Warn("rpc integration token skipped", "integration", name, "error", err.Error())
+			continue
+		}
+		out[resolved] = name
+	}
+	return out
+}
+
+// rpcCallerNamespace isolates caller-private data such as message annotations.
+// Integration tokens map to their configured name; any other token is hashed
+// so the namespace never reveals the credential.
+func (s *Server) rpcCallerNamespace(token string) string {
+	if token == "" {
+		return "anonymous"
+	}
+	s.authMu.RLock()
+	name, ok := s.integrationTokens[token]
+	s.authMu.RUnlock()
+	if ok {
+		return rpcIntegrationNamespacePrefix + name
+	}
+	if guest, ok := s.guests.lookup(token, time.Now()); ok {
+		return rpcGuestNamespacePrefix + guest.ID
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:8])
+}
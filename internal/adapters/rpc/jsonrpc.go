@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,11 +26,13 @@ type rpcRequest struct {
 	Method     string          `json:"method"`
 	Params     json.RawMessage `json:"params"`
 	APIVersion *int            `json:"api_version,omitempty"`
+	Locale     string          `json:"locale,omitempty"`
 }
 
 type rpcError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
 }
 
 type rpcResponse struct {
@@ -64,8 +67,20 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	caller := s.rpcCallerNamespace(s.extractRPCToken(r))
+	if wait, ok := s.usage.admit(caller, time.Now()); !ok {
+		w.Header().Set("Retry-After", retryAfterSeconds(wait))
+		http.Error(w, "token quota exceeded", http.StatusTooManyRequests)
+		return
+	}
+	metered := &meteredResponseWriter{ResponseWriter: w}
+	body := &countingReadCloser{ReadCloser: http.MaxBytesReader(w, r.Body, maxRPCBodyBytes)}
+	w, r.Body = metered, body
+	defer func() {
+		s.usage.record(caller, body.bytes, metered.bytes, metered.failed(), time.Now())
+	}()
 
-	r.Body = http.MaxBytesReader(w, r.Body, maxRPCBodyBytes)
+	locale := s.negotiateRPCLocale(r, "")
 	var req rpcRequest
 	dec := json.NewDecoder(r.Body)
 	if err := dec.Decode(&req); err != nil {
@@ -77,16 +92,17 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 		writeRPC(w, rpcResponse{
 			JSONRPC: "2.0",
 			Error:   &rpcError{Code: -32700, Message: "parse error"},
-		})
+		}, locale)
 		return
 	}
+	locale = s.negotiateRPCLocale(r, req.Locale)
 	if err := dec.Decode(&struct{}{}); err != io.EOF {
-		writeRPCInvalidRequest(w, req.ID)
+		writeRPCInvalidRequest(w, req.ID, locale)
 		return
 	}
 
 	if req.JSONRPC != "2.0" || req.Method == "" {
-		writeRPCInvalidRequest(w, req.ID)
+		writeRPCInvalidRequest(w, req.ID, locale)
 		return
 	}
 	if strings.HasPrefix(req.Method, "node.") && !isLoopbackRequest(r) {
@@ -94,7 +110,7 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 			JSONRPC: "2.0",
 			ID:      req.ID,
 			Error:   &rpcError{Code: -32084, Message: "node methods are available only from loopback client"},
-		})
+		}, locale)
 		return
 	}
 	if versionErr := validateRPCAPIVersion(req.APIVersion); versionErr != nil {
@@ -102,27 +118,17 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 			JSONRPC: "2.0",
 			ID:      req.ID,
 			Error:   versionErr,
-		})
+		}, locale)
 		return
 	}
 	idempotencyKey := rpcIdempotencyKey(r.Header.Get(rpcIdempotencyHeader), s.extractRPCToken(r))
 	requestHash := ""
 	if idempotencyKey != "" {
 		requestHash = rpcRequestHash(req)
-		s.idempotencyMu.Lock()
-		cached, found, conflict := s.idempotency.get(idempotencyKey, requestHash, time.Now().UTC())
-		s.idempotencyMu.Unlock()
-		if conflict {
-			writeRPC(w, rpcResponse{
-				JSONRPC: "2.0",
-				ID:      req.ID,
-				Error:   &rpcError{Code: -32082, Message: "idempotency key reuse with different request payload"},
-			})
-			return
-		}
-		if found {
-			cached.ID = req.ID
-			writeRPC(w, cached)
+		if resp, handled := s.beginIdempotentRequest(r.Context(), req.Method, idempotencyKey, requestHash); handled {
+			resp.JSONRPC = "2.0"
+			resp.ID = req.ID
+			writeRPC(w, resp, locale)
 			return
 		}
 	}
@@ -132,11 +138,9 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 			Error:   &rpcError{Code: -32099, Message: "service is not initialized"},
 		}
 		if idempotencyKey != "" {
-			s.idempotencyMu.Lock()
-			s.idempotency.set(idempotencyKey, requestHash, resp, time.Now().UTC())
-			s.idempotencyMu.Unlock()
+			s.finishIdempotentRequest(idempotencyKey, resp)
 		}
-		writeRPC(w, resp)
+		writeRPC(w, resp, locale)
 		return
 	}
 	reqID := resolveRPCRequestID(r, req.ID)
@@ -144,7 +148,13 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 	started := time.Now()
 	slog.Default().Info("rpc request", "correlation_id", reqID, "request_id", reqID, "method", req.Method, "rpc_id", string(req.ID))
 
-	result, rpcErr := s.dispatchRPC(req.Method, req.Params)
+	var late func(any, *rpcError)
+	if idempotencyKey != "" {
+		late = func(result any, rpcErr *rpcError) {
+			s.finishIdempotentRequest(idempotencyKey, rpcResponse{JSONRPC: "2.0", Result: result, Error: rpcErr})
+		}
+	}
+	result, rpcErr := s.dispatchWithDeadline(r.Context(), caller, req.Method, req.Params, late)
 	if rpcErr != nil {
 		slog.Default().Error("rpc failed", "correlation_id", reqID, "request_id", reqID, "method", req.Method, "rpc_code", rpcErr.Code, "latency_ms", time.Since(started).Milliseconds())
 	} else {
@@ -156,37 +166,66 @@ func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
 		Result:  result,
 		Error:   rpcErr,
 	}
-	if idempotencyKey != "" {
-		s.idempotencyMu.Lock()
-		s.idempotency.set(idempotencyKey, requestHash, resp, time.Now().UTC())
-		s.idempotencyMu.Unlock()
+	s.recorder.record(started, reqID, caller, req, resp)
+	// A timed out request keeps its key in flight until late reports how
+	// it ended.
+	if idempotencyKey != "" && (rpcErr == nil || rpcErr.Code != rpcDeadlineErrorCode) {
+		s.finishIdempotentRequest(idempotencyKey, resp)
 	}
-	writeRPC(w, resp)
+	writeRPC(w, resp, locale)
 }
 
 func (s *Server) dispatchRPC(method string, rawParams json.RawMessage) (any, *rpcError) {
+	return s.dispatchRPCForCaller(context.Background(), s.rpcCallerNamespace(""), method, rawParams)
+}
+
+// dispatchRPCForCaller routes a request on behalf of a caller namespace that
+// scopes caller-private methods. Methods that can block honor ctx.
+func (s *Server) dispatchRPCForCaller(ctx context.Context, callerNamespace, method string, rawParams json.RawMessage) (any, *rpcError) {
+	dispatchMessaging := messagingrpc.Dispatch
+	if guestID, ok := strings.CutPrefix(callerNamespace, rpcGuestNamespacePrefix); ok {
+		if rpcErr := s.authorizeGuestCall(guestID, method, rawParams); rpcErr != nil {
+			return nil, rpcErr
+		}
+		dispatchMessaging = messagingrpc.DispatchGuest
+	}
 	if result, rpcErr, ok := s.dispatchCoreRPC(method); ok {
 		return result, rpcErr
 	}
+	if result, rpcErr, ok := s.dispatchAdminRPC(ctx, callerNamespace, method, rawParams); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.dispatchAnnotationRPC(callerNamespace, method, rawParams); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.dispatchSnippetRPC(ctx, callerNamespace, method, rawParams); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.dispatchHandoffRPC(callerNamespace, method, rawParams); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.dispatchConversationExportRPC(callerNamespace, method, rawParams); ok {
+		return result, rpcErr
+	}
 	if result, rpcErr, ok := identityrpc.Dispatch(s.service, method, rawParams); ok {
 		return result, mapKitError(rpcErr)
 	}
 	if result, rpcErr, ok := privacyrpc.Dispatch(s.service, method, rawParams); ok {
 		return result, mapKitError(rpcErr)
 	}
-	if result, rpcErr, ok := inboxrpc.Dispatch(s.service, method, rawParams); ok {
+	if result, rpcErr, ok := inboxrpc.Dispatch(ctx, s.service, method, rawParams); ok {
 		return result, mapKitError(rpcErr)
 	}
-	if result, rpcErr, ok := messagingrpc.Dispatch(s.service, method, rawParams); ok {
+	if result, rpcErr, ok := dispatchMessaging(ctx, s.service, method, rawParams); ok {
 		return result, mapKitError(rpcErr)
 	}
 	if (strings.HasPrefix(method, "group.") || strings.HasPrefix(method, "channel.")) && !s.groupsEnabled {
 		return nil, &rpcError{Code: -32199, Message: "groups feature is disabled"}
 	}
-	if result, rpcErr, ok := grouprpc.Dispatch(s.service, method, rawParams); ok {
+	if result, rpcErr, ok := grouprpc.Dispatch(ctx, s.service, method, rawParams); ok {
 		return result, mapKitError(rpcErr)
 	}
-	if result, rpcErr, ok := s.dispatchNetworkRPC(method); ok {
+	if result, rpcErr, ok := s.dispatchNetworkRPC(method, rawParams); ok {
 		return result, rpcErr
 	}
 	return nil, &rpcError{Code: -32601, Message: "method not found"}
@@ -205,17 +244,21 @@ func (s *Server) dispatchCoreRPC(method string) (any, *rpcError, bool) {
 	}
 }
 
-func writeRPC(w http.ResponseWriter, resp rpcResponse) {
+func writeRPC(w http.ResponseWriter, resp rpcResponse, locale string) {
+	if metered, ok := w.(*meteredResponseWriter); ok && resp.Error != nil {
+		metered.rpcFailed = true
+	}
+	resp.Error = localizeRPCError(resp.Error, locale)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
-func writeRPCInvalidRequest(w http.ResponseWriter, id json.RawMessage) {
+func writeRPCInvalidRequest(w http.ResponseWriter, id json.RawMessage, locale string) {
 	writeRPC(w, rpcResponse{
 		JSONRPC: "2.0",
 		ID:      id,
 		Error:   &rpcError{Code: -32600, Message: "invalid request"},
-	})
+	}, locale)
 }
 
 func resolveRPCRequestID(r *http.Request, rpcID json.RawMessage) string {
@@ -280,8 +323,12 @@ func mapKitError(err *rpckit.Error) *rpcError {
 	if err == nil {
 		return nil
 	}
-	return &rpcError{
+	mapped := &rpcError{
 		Code:    err.Code,
 		Message: err.Message,
 	}
+	if err.Data != nil {
+		mapped.Data = &rpcErrorData{Details: err.Data}
+	}
+	return mapped
 }
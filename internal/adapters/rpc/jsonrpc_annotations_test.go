@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type annotationMockService struct {
+	*channelMockService
+	store *storage.MessageAnnotationStore
+}
+
+func (m *annotationMockService) AnnotateMessage(namespace, messageID, key, value string) ([]models.MessageAnnotation, error) {
+	if _, err := m.store.Set(namespace, messageID, key, value); err != nil {
+		return nil, err
+	}
+	return m.store.List(namespace, messageID), nil
+}
+
+func (m *annotationMockService) ListMessageAnnotations(namespace, messageID string) ([]models.MessageAnnotation, error) {
+	return m.store.List(namespace, messageID), nil
+}
+
+func postAnnotationRPC(t *testing.T, s *Server, token, method string, params []string) (int, rpcResponse) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AIM-RPC-Token", token)
+	rec := httptest.NewRecorder()
+	s.HandleRPC(rec, req)
+	var resp rpcResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func TestMessageAnnotationsAreIsolatedPerToken(t *testing.T) {
+	t.Setenv(rpcIntegrationTokensEnv, "ticket-bot=bot-token, bridge=bridge-token")
+	svc := &annotationMockService{channelMockService: &channelMockService{}, store: storage.NewMessageAnnotationStore()}
+	s := newServerWithService(DefaultRPCAddr, svc, "primary-token", true)
+
+	code, resp := postAnnotationRPC(t, s, "bot-token", methodMessageAnnotate, []string{"m-1", "ticket", "T-42"})
+	if code != http.StatusOK || resp.Error != nil {
+		t.Fatalf("annotate failed: code=%d err=%+v", code, resp.Error)
+	}
+
+	_, resp = postAnnotationRPC(t, s, "bot-token", methodMessageAnnotationsList, []string{"m-1"})
+	raw, _ := json.Marshal(resp.Result)
+	var own struct {
+		Annotations []models.MessageAnnotation `json:"annotations"`
+	}
+	_ = json.Unmarshal(raw, &own)
+	if len(own.Annotations) != 1 || own.Annotations[0].Value != "T-42" {
+		t.Fatalf("integration must read its own annotations: %s", raw)
+	}
+
+	for _, token := range []string{"bridge-token", "primary-token"} {
+		_, resp = postAnnotationRPC(t, s, token, methodMessageAnnotationsList, []string{"m-1"})
+		raw, _ = json.Marshal(resp.Result)
+		var other struct {
+			Annotations []models.MessageAnnotation `json:"annotations"`
+		}
+		_ = json.Unmarshal(raw, &other)
+		if len(other.Annotations) != 0 {
+			t.Fatalf("token %q must not see foreign annotations: %s", token, raw)
+		}
+	}
+
+	if code, _ := postAnnotationRPC(t, s, "unknown-token", methodMessageAnnotationsList, []string{"m-1"}); code != http.StatusUnauthorized {
+		t.Fatalf("expected unknown token to be rejected, got %d", code)
+	}
+}
+
+func TestRPCCallerNamespaceDoesNotExposeToken(t *testing.T) {
+	t.Setenv(rpcIntegrationTokensEnv, "bot=bot-token")
+	s := newServerWithService(DefaultRPCAddr, nil, "primary-token", true)
+	if got := s.rpcCallerNamespace("bot-token"); got != "integration:bot" {
+		t.Fatalf("unexpected integration namespace: %q", got)
+	}
+	if got := s.rpcCallerNamespace("primary-token"); got == "" || bytes.Contains([]byte(got), []byte("primary-token")) {
+		t.Fatalf("namespace must not contain the raw token: %q", got)
+	}
+}
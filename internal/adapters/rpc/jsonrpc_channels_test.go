@@ -19,6 +19,7 @@ import (
 )
 
 type channelMockService struct {
+	subscribeNotificationsFn   func(cursor int64) ([]contracts.NotificationEvent, <-chan contracts.NotificationEvent, func())
 	createGroupFn              func(title string) (groupdomain.Group, error)
 	getGroupFn                 func(groupID string) (groupdomain.Group, error)
 	listGroupsFn               func() ([]groupdomain.Group, error)
@@ -62,7 +63,7 @@ type channelMockService struct {
 	getStorageScopeOverrideFn    func(scope string, scopeID string) (privacydomain.StoragePolicyOverride, bool, error)
 	removeStorageScopeOverrideFn func(scope string, scopeID string) (bool, error)
 	resolveStoragePolicyFn       func(scope string, scopeID string, isPinned bool) (privacydomain.StoragePolicy, error)
-	initAttachmentUploadFn       func(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256 string) (identityusecase.AttachmentUploadInitResult, error)
+	initAttachmentUploadFn       func(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256, altText string) (identityusecase.AttachmentUploadInitResult, error)
 	putAttachmentChunkFn         func(uploadID string, chunkIndex int, dataBase64, chunkSHA256 string) (identityusecase.AttachmentUploadChunkResult, error)
 	getAttachmentUploadStatusFn  func(uploadID string) (identityusecase.AttachmentUploadStatus, error)
 	commitAttachmentUploadFn     func(uploadID string) (models.AttachmentMeta, error)
@@ -162,9 +163,9 @@ func (m *channelMockService) AddDevice(_ string) (models.Device, error) {
 func (m *channelMockService) RevokeDevice(_ string) (models.DeviceRevocation, error) {
 	return models.DeviceRevocation{}, nil
 }
-func (m *channelMockService) InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256 string) (identityusecase.AttachmentUploadInitResult, error) {
+func (m *channelMockService) InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256, altText string) (identityusecase.AttachmentUploadInitResult, error) {
 	if m.initAttachmentUploadFn != nil {
-		return m.initAttachmentUploadFn(name, mimeType, totalSize, totalChunks, chunkSize, fileSHA256)
+		return m.initAttachmentUploadFn(name, mimeType, totalSize, totalChunks, chunkSize, fileSHA256, altText)
 	}
 	return identityusecase.AttachmentUploadInitResult{}, nil
 }
@@ -303,10 +304,10 @@ func (m *channelMockService) UnbindNode(nodeID string, confirm bool) (bool, erro
 	}
 	return false, nil
 }
-func (m *channelMockService) SendMessage(_, _ string) (string, error) {
+func (m *channelMockService) SendMessage(_ context.Context, _, _ string) (string, error) {
 	return "", nil
 }
-func (m *channelMockService) SendMessageInThread(_, _, _ string) (string, error) {
+func (m *channelMockService) SendMessageInThread(_ context.Context, _, _, _ string) (string, error) {
 	return "", nil
 }
 func (m *channelMockService) EditMessage(_, _, _ string) (models.Message, error) {
@@ -385,13 +386,13 @@ func (m *channelMockService) PromoteGroupMember(_, _ string) (groupdomain.GroupM
 func (m *channelMockService) DemoteGroupMember(_, _ string) (groupdomain.GroupMember, error) {
 	return groupdomain.GroupMember{}, nil
 }
-func (m *channelMockService) SendGroupMessage(groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+func (m *channelMockService) SendGroupMessage(_ context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
 	if m.sendGroupMessageFn != nil {
 		return m.sendGroupMessageFn(groupID, content)
 	}
 	return groupdomain.GroupMessageFanoutResult{}, nil
 }
-func (m *channelMockService) SendGroupMessageInThread(groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error) {
+func (m *channelMockService) SendGroupMessageInThread(_ context.Context, groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error) {
 	if m.sendGroupMessageInThreadFn != nil {
 		return m.sendGroupMessageInThreadFn(groupID, content, threadID)
 	}
@@ -419,7 +420,9 @@ func (m *channelMockService) ListMessageRequests() ([]models.MessageRequest, err
 func (m *channelMockService) GetMessageRequest(_ string) (models.MessageRequestThread, error) {
 	return models.MessageRequestThread{}, nil
 }
-func (m *channelMockService) AcceptMessageRequest(_ string) (bool, error)  { return false, nil }
+func (m *channelMockService) AcceptMessageRequest(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
 func (m *channelMockService) DeclineMessageRequest(_ string) (bool, error) { return false, nil }
 func (m *channelMockService) BlockSender(_ string) (models.BlockSenderResult, error) {
 	return models.BlockSenderResult{}, nil
@@ -525,7 +528,10 @@ func (m *channelMockService) GetNetworkStatus() models.NetworkStatus  { return m
 func (m *channelMockService) GetMetrics() models.MetricsSnapshot      { return models.MetricsSnapshot{} }
 func (m *channelMockService) StartNetworking(_ context.Context) error { return nil }
 func (m *channelMockService) StopNetworking(_ context.Context) error  { return nil }
-func (m *channelMockService) SubscribeNotifications(_ int64) ([]contracts.NotificationEvent, <-chan contracts.NotificationEvent, func()) {
+func (m *channelMockService) SubscribeNotifications(cursor int64) ([]contracts.NotificationEvent, <-chan contracts.NotificationEvent, func()) {
+	if m.subscribeNotificationsFn != nil {
+		return m.subscribeNotificationsFn(cursor)
+	}
 	ch := make(chan contracts.NotificationEvent)
 	close(ch)
 	return nil, ch, func() {}
@@ -974,9 +980,9 @@ func TestDispatchRPCFileUploadChunkFlow(t *testing.T) {
 	t.Setenv("AIM_ENV", "test")
 
 	svc := &channelMockService{
-		initAttachmentUploadFn: func(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256 string) (identityusecase.AttachmentUploadInitResult, error) {
-			if name != "doc.txt" || totalChunks != 2 || chunkSize != 256 {
-				t.Fatalf("unexpected init params: name=%q totalChunks=%d chunkSize=%d", name, totalChunks, chunkSize)
+		initAttachmentUploadFn: func(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256, altText string) (identityusecase.AttachmentUploadInitResult, error) {
+			if name != "doc.txt" || totalChunks != 2 || chunkSize != 256 || altText != "Quarterly report" {
+				t.Fatalf("unexpected init params: name=%q totalChunks=%d chunkSize=%d altText=%q", name, totalChunks, chunkSize, altText)
 			}
 			return identityusecase.AttachmentUploadInitResult{
 				UploadID:    "upl-1",
@@ -1010,6 +1016,7 @@ func TestDispatchRPCFileUploadChunkFlow(t *testing.T) {
 		"total_size":   12,
 		"total_chunks": 2,
 		"chunk_size":   256,
+		"alt_text":     "Quarterly report",
 	}})
 	initResult, rpcErr := s.dispatchRPC("file.upload.init", initParams)
 	if rpcErr != nil {
@@ -1640,3 +1647,94 @@ func TestRPCIdentityLoginReturnsLoggedIn(t *testing.T) {
 		t.Fatalf("expected logged_in=true, got %#v", payload)
 	}
 }
+
+func TestDispatchRPCChannelSendFailureCarriesDeliveryData(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	svc := &channelMockService{
+		getGroupFn: func(groupID string) (groupdomain.Group, error) {
+			return groupdomain.Group{ID: groupID, Title: "[channel:public] General"}, nil
+		},
+		sendGroupMessageFn: func(groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+			return groupdomain.GroupMessageFanoutResult{}, &groupdomain.GroupMessageDeliveryError{
+				GroupID:   groupID,
+				EventID:   "evt1",
+				Attempted: 2,
+				Failed:    2,
+				Failures:  map[string]string{"aim1a": "network", "aim1b": "crypto"},
+			}
+		},
+	}
+	s := newServerWithService(DefaultRPCAddr, svc, "", false)
+
+	params, _ := json.Marshal([]string{"g1", "hello"})
+	_, rpcErr := s.dispatchRPC("channel.send", params)
+	if rpcErr == nil || rpcErr.Code != -32220 {
+		t.Fatalf("expected rpc code -32220, got %+v", rpcErr)
+	}
+	if rpcErr.Data == nil {
+		t.Fatal("expected delivery data on rpc error")
+	}
+	raw, err := json.Marshal(rpcErr)
+	if err != nil {
+		t.Fatalf("marshal rpc error: %v", err)
+	}
+	var wire struct {
+		Data struct {
+			Details struct {
+				Attempted int               `json:"attempted"`
+				Failed    int               `json:"failed"`
+				Failures  map[string]string `json:"failures"`
+			} `json:"details"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &wire); err != nil {
+		t.Fatalf("unmarshal rpc error: %v", err)
+	}
+	details := wire.Data.Details
+	if details.Attempted != 2 || details.Failed != 2 || details.Failures["aim1b"] != "crypto" {
+		t.Fatalf("unexpected delivery data: %s", raw)
+	}
+}
+
+type memberStatusMockService struct {
+	*channelMockService
+}
+
+func (m memberStatusMockService) GetMessageStatusWithMembers(messageID string) (models.MessageStatus, error) {
+	return models.MessageStatus{
+		MessageID:      messageID,
+		Status:         "sent",
+		RecipientCount: 1,
+		PendingCount:   1,
+		Recipients:     []models.MessageRecipientStatus{{MemberID: "aim1bob", MessageID: messageID, Status: "sent"}},
+	}, nil
+}
+
+func TestDispatchRPCMessageStatusIncludeMembers(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	s := newServerWithService(DefaultRPCAddr, memberStatusMockService{&channelMockService{}}, "", false)
+
+	params, _ := json.Marshal([]any{"m1", true})
+	result, rpcErr := s.dispatchRPC("message.status", params)
+	if rpcErr != nil {
+		t.Fatalf("unexpected rpc error: %+v", rpcErr)
+	}
+	raw, _ := json.Marshal(result)
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if recipients, _ := decoded["recipients"].([]any); len(recipients) != 1 {
+		t.Fatalf("expected per-member receipts, got %s", raw)
+	}
+	if read, ok := decoded["read_count"]; !ok || read != float64(0) {
+		t.Fatalf("expected zero counts to be reported, got %s", raw)
+	}
+
+	params, _ = json.Marshal([]any{"m1", "yes"})
+	if _, rpcErr := s.dispatchRPC("message.status", params); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Fatalf("expected invalid params for a non-boolean flag, got %+v", rpcErr)
+	}
+}
@@ -0,0 +1,182 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	methodAdminTokensUsage    = "admin.tokens.usage"
+	methodRPCTokenCreateGuest = "rpc.token.create_guest"
+	methodRPCTokenListGuests  = "rpc.token.list_guests"
+	methodRPCTokenRevokeGuest = "rpc.token.revoke_guest"
+	methodManifestFetchNow    = "manifest.fetch.now"
+	methodAuditList           = "audit.list"
+	methodAuditVerify         = "audit.verify"
+	methodAuditExportStatus   = "audit.export.status"
+	methodRetryPolicySet      = "network.retry_policy.set"
+	methodPluginList          = "plugin.list"
+	methodPluginEnable        = "plugin.enable"
+	methodPluginDisable       = "plugin.disable"
+)
+
+type auditLogService interface {
+	ListAuditEvents(kind string, limit, offset int) models.AuditLogPage
+	VerifyAuditLog() models.AuditVerification
+}
+
+type auditListParams struct {
+	Kind   string `json:"kind"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+var errAuditLogNotSupported = errors.New("audit log is not supported")
+
+type pluginService interface {
+	ListPlugins() ([]models.PluginInfo, error)
+	SetPluginEnabled(name string, enabled bool) (models.PluginInfo, error)
+}
+
+var errPluginsNotSupported = errors.New("message plugins are not supported")
+
+type guestTokenCreateParams struct {
+	Scope      string `json:"scope"`
+	TargetID   string `json:"target_id"`
+	Label      string `json:"label"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+}
+
+// dispatchAdminRPC serves daemon administration methods. They are refused to
+// integration and guest tokens so a bot cannot inspect or mint credentials
+// or read the audit log.
+func (s *Server) dispatchAdminRPC(ctx context.Context, callerNamespace, method string, rawParams json.RawMessage) (any, *rpcError, bool) {
+	switch method {
+	case methodAdminTokensUsage, methodRPCTokenCreateGuest, methodRPCTokenListGuests, methodRPCTokenRevokeGuest, methodManifestFetchNow,
+		methodAuditList, methodAuditVerify, methodAuditExportStatus, methodRetryPolicySet, methodPluginList, methodPluginEnable, methodPluginDisable:
+	default:
+		return nil, nil, false
+	}
+	if strings.HasPrefix(callerNamespace, rpcIntegrationNamespacePrefix) || strings.HasPrefix(callerNamespace, rpcGuestNamespacePrefix) {
+		return nil, &rpcError{Code: -32336, Message: "admin methods require the primary rpc token"}, true
+	}
+	now := time.Now()
+	switch method {
+	case methodAdminTokensUsage:
+		return map[string]any{
+			"window_seconds": int(rpcTokenQuotaWindow / time.Second),
+			"tokens":         s.usage.snapshot(now),
+		}, nil, true
+	case methodRPCTokenCreateGuest:
+		var params guestTokenCreateParams
+		if err := json.Unmarshal(rawParams, &params); err != nil || params.TTLSeconds < 0 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32338, func() (any, error) {
+			if s.guests == nil {
+				return nil, errors.New("guest tokens are not supported")
+			}
+			token, guest, err := s.guests.create(params.Scope, params.TargetID, params.Label, time.Duration(params.TTLSeconds)*time.Second, now)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"token": token, "guest": guest}, nil
+		})
+	case methodRPCTokenListGuests:
+		return map[string]any{"guests": s.guests.list(now)}, nil, true
+	case methodManifestFetchNow:
+		return serviceCall(-32347, func() (any, error) {
+			fetcher, ok := s.service.(interface {
+				FetchManifestNow(ctx context.Context) (models.ManifestFetchResult, error)
+			})
+			if !ok {
+				return nil, errors.New("manifest fetching is not supported")
+			}
+			return fetcher.FetchManifestNow(ctx)
+		})
+	case methodAuditList:
+		var params auditListParams
+		if trimmed := strings.TrimSpace(string(rawParams)); trimmed != "" && trimmed != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil || params.Limit < 0 || params.Offset < 0 {
+				return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+			}
+		}
+		return serviceCall(-32368, func() (any, error) {
+			audit, ok := s.service.(auditLogService)
+			if !ok {
+				return nil, errAuditLogNotSupported
+			}
+			return audit.ListAuditEvents(params.Kind, params.Limit, params.Offset), nil
+		})
+	case methodAuditVerify:
+		return serviceCall(-32369, func() (any, error) {
+			audit, ok := s.service.(auditLogService)
+			if !ok {
+				return nil, errAuditLogNotSupported
+			}
+			return audit.VerifyAuditLog(), nil
+		})
+	case methodAuditExportStatus:
+		return serviceCall(-32394, func() (any, error) {
+			exports, ok := s.service.(interface {
+				GetAuditExportStatus() models.AuditExportStatus
+			})
+			if !ok {
+				return nil, errors.New("audit export is not supported")
+			}
+			return exports.GetAuditExportStatus(), nil
+		})
+	case methodRetryPolicySet:
+		var policy models.RetryPolicy
+		if err := json.Unmarshal(rawParams, &policy); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32376, func() (any, error) {
+			retries, ok := s.service.(retryPolicyService)
+			if !ok {
+				return nil, errRetryPolicyNotSupported
+			}
+			return retries.SetRetryPolicy(policy)
+		})
+	case methodPluginList:
+		return serviceCall(-32380, func() (any, error) {
+			plugins, ok := s.service.(pluginService)
+			if !ok {
+				return nil, errPluginsNotSupported
+			}
+			list, err := plugins.ListPlugins()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"plugins": list}, nil
+		})
+	case methodPluginEnable, methodPluginDisable:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32381, func() (any, error) {
+			plugins, ok := s.service.(pluginService)
+			if !ok {
+				return nil, errPluginsNotSupported
+			}
+			return plugins.SetPluginEnabled(params[0], method == methodPluginEnable)
+		})
+	default:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32339, func() (any, error) {
+			if !s.guests.revoke(strings.TrimSpace(params[0])) {
+				return nil, errors.New("guest token not found")
+			}
+			return map[string]bool{"revoked": true}, nil
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	methodMessageAnnotate        = "message.annotate"
+	methodMessageAnnotationsList = "message.annotations.list"
+)
+
+type messageAnnotationService interface {
+	AnnotateMessage(namespace, messageID, key, value string) ([]models.MessageAnnotation, error)
+	ListMessageAnnotations(namespace, messageID string) ([]models.MessageAnnotation, error)
+}
+
+// dispatchAnnotationRPC serves annotation methods. The namespace comes from
+// the caller credential, never from params, so integrations cannot address
+// each other's annotations.
+func (s *Server) dispatchAnnotationRPC(namespace, method string, rawParams json.RawMessage) (any, *rpcError, bool) {
+	switch method {
+	case methodMessageAnnotate:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 3 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32305, func() (any, error) {
+			annotator, ok := s.service.(messageAnnotationService)
+			if !ok {
+				return nil, errors.New("message annotations are not supported")
+			}
+			annotations, err := annotator.AnnotateMessage(namespace, params[0], params[1], params[2])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"message_id": params[0], "annotations": annotations}, nil
+		})
+	case methodMessageAnnotationsList:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32306, func() (any, error) {
+			annotator, ok := s.service.(messageAnnotationService)
+			if !ok {
+				return nil, errors.New("message annotations are not supported")
+			}
+			annotations, err := annotator.ListMessageAnnotations(namespace, params[0])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"message_id": params[0], "annotations": annotations}, nil
+		})
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,108 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	methodConversationExportPrepare = "conversation.export.prepare"
+	methodConversationExport        = "conversation.export"
+	methodExportConsentGrant        = "conversation.export.consent.grant"
+	methodConversationExportVerify  = "conversation.export.verify"
+)
+
+type conversationExportService interface {
+	PrepareConversationExport(contactID string) (models.ExportConsentToken, error)
+	ExportConversation(req models.ConversationExportRequest) (models.ConversationExport, error)
+	GrantExportConsent(contactID string, ttlSeconds int64) (models.ExportConsent, error)
+	VerifyConversationExport(bundle models.ConversationExport) error
+}
+
+var errConversationExportNotSupported = errors.New("conversation exports are not supported")
+
+// dispatchConversationExportRPC serves consented plaintext exports. Like
+// handoffs they need the primary rpc token: no bot or guest may export a
+// conversation or consent to one on the user's behalf.
+func (s *Server) dispatchConversationExportRPC(callerNamespace, method string, rawParams json.RawMessage) (any, *rpcError, bool) {
+	switch method {
+	case methodConversationExportPrepare, methodConversationExport, methodExportConsentGrant, methodConversationExportVerify:
+	default:
+		return nil, nil, false
+	}
+	if strings.HasPrefix(callerNamespace, rpcIntegrationNamespacePrefix) || strings.HasPrefix(callerNamespace, rpcGuestNamespacePrefix) {
+		return nil, &rpcError{Code: -32336, Message: "admin methods require the primary rpc token"}, true
+	}
+	exports, ok := s.service.(conversationExportService)
+	switch method {
+	case methodConversationExportPrepare:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 || strings.TrimSpace(params[0]) == "" {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32385, func() (any, error) {
+			if !ok {
+				return nil, errConversationExportNotSupported
+			}
+			return exports.PrepareConversationExport(params[0])
+		})
+	case methodConversationExport:
+		var params models.ConversationExportRequest
+		if err := json.Unmarshal(rawParams, &params); err != nil || strings.TrimSpace(params.ContactID) == "" {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32386, func() (any, error) {
+			if !ok {
+				return nil, errConversationExportNotSupported
+			}
+			return exports.ExportConversation(params)
+		})
+	case methodExportConsentGrant:
+		contactID, ttlSeconds, rpcErr := decodeExportConsentGrantParams(rawParams)
+		if rpcErr != nil {
+			return nil, rpcErr, true
+		}
+		return serviceCall(-32387, func() (any, error) {
+			if !ok {
+				return nil, errConversationExportNotSupported
+			}
+			return exports.GrantExportConsent(contactID, ttlSeconds)
+		})
+	default:
+		var bundle models.ConversationExport
+		if err := json.Unmarshal(rawParams, &bundle); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32388, func() (any, error) {
+			if !ok {
+				return nil, errConversationExportNotSupported
+			}
+			if err := exports.VerifyConversationExport(bundle); err != nil {
+				return map[string]any{"valid": false, "reason": err.Error()}, nil
+			}
+			return map[string]any{"valid": true}, nil
+		})
+	}
+}
+
+// decodeExportConsentGrantParams reads [contact_id, ttl_seconds?].
+func decodeExportConsentGrantParams(rawParams json.RawMessage) (string, int64, *rpcError) {
+	var params []json.RawMessage
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params) < 1 || len(params) > 2 {
+		return "", 0, &rpcError{Code: -32602, Message: "invalid params"}
+	}
+	var contactID string
+	if err := json.Unmarshal(params[0], &contactID); err != nil || strings.TrimSpace(contactID) == "" {
+		return "", 0, &rpcError{Code: -32602, Message: "invalid params"}
+	}
+	var ttlSeconds int64
+	if len(params) == 2 {
+		if err := json.Unmarshal(params[1], &ttlSeconds); err != nil {
+			return "", 0, &rpcError{Code: -32602, Message: "invalid params"}
+		}
+	}
+	return strings.TrimSpace(contactID), ttlSeconds, nil
+}
@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	methodHandoffCreate = "conversation.handoff.create"
+	methodHandoffOpen   = "conversation.handoff.open"
+	methodHandoffImport = "conversation.handoff.import"
+)
+
+type conversationHandoffService interface {
+	CreateConversationHandoff(req models.HandoffCreateRequest) (models.ConversationHandoff, error)
+	OpenConversationHandoff(handoffID string) (models.ConversationHandoff, error)
+	ImportConversationHandoff(handoffID string) (models.ConversationHandoff, error)
+}
+
+var errHandoffNotSupported = errors.New("conversation handoffs are not supported")
+
+// dispatchHandoffRPC serves conversation handoffs. Like admin methods they
+// need the primary rpc token: a handoff carries a whole conversation across
+// accounts, which no bot or guest may do.
+func (s *Server) dispatchHandoffRPC(callerNamespace, method string, rawParams json.RawMessage) (any, *rpcError, bool) {
+	switch method {
+	case methodHandoffCreate, methodHandoffOpen, methodHandoffImport:
+	default:
+		return nil, nil, false
+	}
+	if strings.HasPrefix(callerNamespace, rpcIntegrationNamespacePrefix) || strings.HasPrefix(callerNamespace, rpcGuestNamespacePrefix) {
+		return nil, &rpcError{Code: -32336, Message: "admin methods require the primary rpc token"}, true
+	}
+	switch method {
+	case methodHandoffCreate:
+		var params models.HandoffCreateRequest
+		if err := json.Unmarshal(rawParams, &params); err != nil || strings.TrimSpace(params.ContactID) == "" {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32370, func() (any, error) {
+			handoffs, ok := s.service.(conversationHandoffService)
+			if !ok {
+				return nil, errHandoffNotSupported
+			}
+			return handoffs.CreateConversationHandoff(params)
+		})
+	case methodHandoffOpen:
+		handoffID, rpcErr := decodeHandoffIDParam(rawParams)
+		if rpcErr != nil {
+			return nil, rpcErr, true
+		}
+		return serviceCall(-32371, func() (any, error) {
+			handoffs, ok := s.service.(conversationHandoffService)
+			if !ok {
+				return nil, errHandoffNotSupported
+			}
+			return handoffs.OpenConversationHandoff(handoffID)
+		})
+	default:
+		handoffID, rpcErr := decodeHandoffIDParam(rawParams)
+		if rpcErr != nil {
+			return nil, rpcErr, true
+		}
+		return serviceCall(-32372, func() (any, error) {
+			handoffs, ok := s.service.(conversationHandoffService)
+			if !ok {
+				return nil, errHandoffNotSupported
+			}
+			return handoffs.ImportConversationHandoff(handoffID)
+		})
+	}
+}
+
+func decodeHandoffIDParam(rawParams json.RawMessage) (string, *rpcError) {
+	var params []string
+	if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 || strings.TrimSpace(params[0]) == "" {
+		return "", &rpcError{Code: -32602, Message: "invalid params"}
+	}
+	return strings.TrimSpace(params[0]), nil
+}
@@ -1,12 +1,27 @@
 package rpc
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 
 	"aim-chat/go-backend/pkg/models"
 )
 
-func (s *Server) dispatchNetworkRPC(method string) (any, *rpcError, bool) {
+type metricsQueryParams struct {
+	Range      string   `json:"range"`
+	Resolution string   `json:"resolution"`
+	Series     []string `json:"series"`
+}
+
+type retryPolicyService interface {
+	GetRetryPolicy() models.RetryPolicy
+	SetRetryPolicy(policy models.RetryPolicy) (models.RetryPolicy, error)
+}
+
+var errRetryPolicyNotSupported = errors.New("retry policy is not supported")
+
+func (s *Server) dispatchNetworkRPC(method string, rawParams json.RawMessage) (any, *rpcError, bool) {
 	switch method {
 	case "network.status":
 		return serviceCall(-32031, func() (any, error) {
@@ -16,10 +31,54 @@ func (s *Server) dispatchNetworkRPC(method string) (any, *rpcError, bool) {
 		return serviceCall(-32032, func() (any, error) {
 			return map[string]any{"addresses": s.service.ListenAddresses()}, nil
 		})
+	case "store.status":
+		return serviceCall(-32311, func() (any, error) {
+			reporter, ok := s.service.(interface {
+				GetStoreNodeStatus() (models.StoreNodeStatus, error)
+			})
+			if !ok {
+				return nil, errors.New("store node status is not supported")
+			}
+			return reporter.GetStoreNodeStatus()
+		})
+	case "network.bootstrap.stats":
+		return serviceCall(-32390, func() (any, error) {
+			reporter, ok := s.service.(interface {
+				GetBootstrapStats() (models.BootstrapStats, error)
+			})
+			if !ok {
+				return nil, errors.New("bootstrap stats are not supported")
+			}
+			return reporter.GetBootstrapStats()
+		})
+	case "network.retry_policy.get":
+		return serviceCall(-32375, func() (any, error) {
+			retries, ok := s.service.(retryPolicyService)
+			if !ok {
+				return nil, errRetryPolicyNotSupported
+			}
+			return retries.GetRetryPolicy(), nil
+		})
 	case "metrics.get":
 		return serviceCall(-32070, func() (any, error) {
 			return s.service.GetMetrics(), nil
 		})
+	case "metrics.query":
+		params := metricsQueryParams{Range: "1h"}
+		if trimmed := strings.TrimSpace(string(rawParams)); trimmed != "" && trimmed != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil {
+				return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+			}
+		}
+		return serviceCall(-32365, func() (any, error) {
+			history, ok := s.service.(interface {
+				QueryMetrics(span, resolution string, series []string) (models.MetricsQueryResult, error)
+			})
+			if !ok {
+				return nil, errors.New("metrics history is not supported")
+			}
+			return history.QueryMetrics(params.Range, params.Resolution, params.Series)
+		})
 	case "diagnostics.export":
 		return serviceCall(-32071, func() (any, error) {
 			exporter, ok := s.service.(interface {
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	methodSnippetsList        = "snippets.list"
+	methodSnippetsSet         = "snippets.set"
+	methodSnippetsDelete      = "snippets.delete"
+	methodMessageSendTemplate = "message.send_template"
+)
+
+type snippetService interface {
+	ListSnippets(namespace, groupID string) []models.Snippet
+	SaveSnippet(namespace string, snippet models.Snippet) (models.Snippet, error)
+	DeleteSnippet(namespace, snippetID string) error
+	SendTemplate(ctx context.Context, namespace string, req models.TemplateSendRequest) (any, error)
+}
+
+// dispatchSnippetRPC serves canned responses. Like annotations, the caller
+// namespace scopes private snippets, so a bot never sees another's set.
+func (s *Server) dispatchSnippetRPC(ctx context.Context, namespace, method string, rawParams json.RawMessage) (any, *rpcError, bool) {
+	switch method {
+	case methodSnippetsList:
+		var params []string
+		if trimmed := strings.TrimSpace(string(rawParams)); trimmed != "" && trimmed != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil || len(params) > 1 {
+				return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+			}
+		}
+		return serviceCall(-32332, func() (any, error) {
+			snippets, ok := s.service.(snippetService)
+			if !ok {
+				return nil, errors.New("snippets are not supported")
+			}
+			groupID := ""
+			if len(params) == 1 {
+				groupID = params[0]
+			}
+			return map[string]any{"snippets": snippets.ListSnippets(namespace, groupID)}, nil
+		})
+	case methodSnippetsSet:
+		var params models.Snippet
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32333, func() (any, error) {
+			snippets, ok := s.service.(snippetService)
+			if !ok {
+				return nil, errors.New("snippets are not supported")
+			}
+			return snippets.SaveSnippet(namespace, params)
+		})
+	case methodSnippetsDelete:
+		var params []string
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) != 1 {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32334, func() (any, error) {
+			snippets, ok := s.service.(snippetService)
+			if !ok {
+				return nil, errors.New("snippets are not supported")
+			}
+			if err := snippets.DeleteSnippet(namespace, params[0]); err != nil {
+				return nil, err
+			}
+			return map[string]bool{"deleted": true}, nil
+		})
+	case methodMessageSendTemplate:
+		var params models.TemplateSendRequest
+		if err := json.Unmarshal(rawParams, &params); err != nil || strings.TrimSpace(params.SnippetID) == "" {
+			return nil, &rpcError{Code: -32602, Message: "invalid params"}, true
+		}
+		return serviceCall(-32335, func() (any, error) {
+			snippets, ok := s.service.(snippetService)
+			if !ok {
+				return nil, errors.New("snippets are not supported")
+			}
+			return snippets.SendTemplate(ctx, namespace, params)
+		})
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"aim-chat/go-backend/internal/platform/i18n"
+	"aim-chat/go-backend/pkg/events"
+)
+
+const rpcLocaleEnv = "AIM_LOCALE"
+
+// rpcErrorData carries the stable catalog key of a localized error so clients
+// can match errors without parsing translated text, plus any structured
+// detail the service attached to the error.
+type rpcErrorData struct {
+	MessageKey string `json:"message_key,omitempty"`
+	Locale     string `json:"locale,omitempty"`
+	Details    any    `json:"details,omitempty"`
+}
+
+func loadRPCDefaultLocale() string {
+	return strings.TrimSpace(os.Getenv(rpcLocaleEnv))
+}
+
+// negotiateRPCLocale prefers the locale named by the request, then the
+// client's Accept-Language header, then the daemon-wide AIM_LOCALE default.
+func (s *Server) negotiateRPCLocale(r *http.Request, requested string) string {
+	return i18n.Default().Negotiate(requested, r.Header.Get("Accept-Language"), s.defaultLocale)
+}
+
+// localizeRPCError translates known error text. Error codes never change;
+// unknown messages are passed through as they are.
+func localizeRPCError(err *rpcError, locale string) *rpcError {
+	if err == nil {
+		return nil
+	}
+	text, key, ok := i18n.Default().Translate(locale, err.Message)
+	if !ok {
+		return err
+	}
+	data := &rpcErrorData{MessageKey: key, Locale: locale}
+	if err.Data != nil {
+		data.Details = err.Data.Details
+	}
+	return &rpcError{
+		Code:    err.Code,
+		Message: text,
+		Data:    data,
+	}
+}
+
+// localizeNotificationPayload re-renders human-readable notification text for
+// the subscriber locale. Machine-readable fields are left untouched.
+func localizeNotificationPayload(method string, payload any, locale string) any {
+	if method != events.MethodSecurityAlert || locale == i18n.DefaultLocale {
+		return payload
+	}
+	if alert, ok := payload.(events.SecurityAlert); ok {
+		if text, ok := i18n.Default().Message(locale, "notify.security.alert."+alert.Kind, alert.MessageArgs); ok {
+			alert.Message = text
+		}
+		return alert
+	}
+	fields, ok := payload.(map[string]any)
+	if !ok {
+		return payload
+	}
+	kind, _ := fields["kind"].(string)
+	args, _ := fields["message_args"].(map[string]string)
+	text, ok := i18n.Default().Message(locale, "notify.security.alert."+kind, args)
+	if !ok {
+		return payload
+	}
+	localized := make(map[string]any, len(fields))
+	for key, value := range fields {
+		localized[key] = value
+	}
+	localized["message"] = text
+	return localized
+}
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/pkg/events"
+)
+
+func postLocalizedRPC(t *testing.T, s *Server, body, acceptLanguage string) rpcResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	rec := httptest.NewRecorder()
+	s.HandleRPC(rec, req)
+	var resp rpcResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+	}
+	return resp
+}
+
+func TestRPCErrorsAreLocalizedWithStableCodes(t *testing.T) {
+	t.Setenv(rpcLocaleEnv, "")
+	s := newServerWithService(DefaultRPCAddr, &channelMockService{}, "", false)
+
+	resp := postLocalizedRPC(t, s, `{"jsonrpc":"2.0","id":1,"method":"no.such.method","locale":"ru"}`, "")
+	if resp.Error == nil || resp.Error.Code != -32601 {
+		t.Fatalf("expected method-not-found code, got %+v", resp.Error)
+	}
+	if resp.Error.Message == "method not found" || resp.Error.Data == nil || resp.Error.Data.MessageKey != "rpc.method_not_found" || resp.Error.Data.Locale != "ru" {
+		t.Fatalf("expected russian message with key, got %+v", resp.Error)
+	}
+
+	resp = postLocalizedRPC(t, s, `{"jsonrpc":"2.0","id":2,"method":"no.such.method"}`, "ru-RU,ru;q=0.9")
+	if resp.Error == nil || resp.Error.Data == nil || resp.Error.Data.Locale != "ru" {
+		t.Fatalf("Accept-Language must select locale, got %+v", resp.Error)
+	}
+
+	resp = postLocalizedRPC(t, s, `{"jsonrpc":"2.0","id":3,"method":"no.such.method","locale":"en"}`, "ru")
+	if resp.Error == nil || resp.Error.Message != "method not found" {
+		t.Fatalf("request locale must win over header, got %+v", resp.Error)
+	}
+}
+
+func TestRPCDefaultLocaleComesFromConfig(t *testing.T) {
+	t.Setenv(rpcLocaleEnv, "ru")
+	s := newServerWithService(DefaultRPCAddr, &channelMockService{}, "", false)
+	resp := postLocalizedRPC(t, s, `{"jsonrpc":"2.0","id":1,"method":"no.such.method"}`, "")
+	if resp.Error == nil || resp.Error.Data == nil || resp.Error.Data.Locale != "ru" {
+		t.Fatalf("expected configured locale, got %+v", resp.Error)
+	}
+}
+
+func TestSecurityAlertNotificationIsLocalized(t *testing.T) {
+	payload := map[string]any{
+		"kind":         "inbound_limit_violation",
+		"contact_id":   "aim1peer",
+		"message":      "5 inbound payloads rejected: wire too large",
+		"message_args": map[string]string{"count": "5"},
+	}
+	localized, ok := localizeNotificationPayload("notify.security.alert", payload, "ru").(map[string]any)
+	if !ok {
+		t.Fatal("expected map payload")
+	}
+	text, _ := localized["message"].(string)
+	if !strings.Contains(text, "5") || strings.Contains(text, "inbound payloads rejected") {
+		t.Fatalf("unexpected localized message: %q", text)
+	}
+	if localized["kind"] != "inbound_limit_violation" {
+		t.Fatal("machine-readable kind must be preserved")
+	}
+	if payload["message"] != "5 inbound payloads rejected: wire too large" {
+		t.Fatal("shared payload must not be mutated")
+	}
+	if same := localizeNotificationPayload("notify.security.alert", payload, "en"); same.(map[string]any)["message"] != payload["message"] {
+		t.Fatal("source locale must keep the original message")
+	}
+
+	alert := events.SecurityAlert{Kind: "inbound_limit_violation", ContactID: "aim1peer", Message: "5 inbound payloads rejected: wire too large", MessageArgs: map[string]string{"count": "5"}}
+	typed, ok := localizeNotificationPayload(events.MethodSecurityAlert, alert, "ru").(events.SecurityAlert)
+	if !ok || typed.Message != text {
+		t.Fatalf("expected typed alert to be localized like the map payload, got %#v", typed)
+	}
+}
@@ -0,0 +1,153 @@
+package rpc
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var errMetricsAddrNotLoopback = errors.New("metrics address must be loopback unless an RPC token is set")
+
+type operationHistogramService interface {
+	OperationLatencyHistograms() map[string]models.LatencyHistogram
+}
+
+var (
+	metricsPeerCountDesc = prometheus.NewDesc("aim_peer_count",
+		"Peers the node is connected to.", nil, nil)
+	metricsPendingQueueDesc = prometheus.NewDesc("aim_pending_queue_size",
+		"Outbound messages waiting for delivery or retry.", nil, nil)
+	metricsNotificationBacklogDesc = prometheus.NewDesc("aim_notification_backlog",
+		"Notifications buffered for clients.", nil, nil)
+	metricsErrorsDesc = prometheus.NewDesc("aim_errors_total",
+		"Errors recorded by the daemon.", []string{"category"}, nil)
+	metricsRetryAttemptsDesc = prometheus.NewDesc("aim_retry_attempts_total",
+		"Delivery retry attempts.", nil, nil)
+	metricsOperationErrorsDesc = prometheus.NewDesc("aim_operation_errors_total",
+		"Failed daemon operations.", []string{"operation"}, nil)
+	metricsOperationDurationDesc = prometheus.NewDesc("aim_operation_duration_seconds",
+		"Latency of daemon operations.", []string{"operation"}, nil)
+)
+
+// metricsCollector reads the daemon metrics at scrape time, so the exporter
+// keeps no state of its own.
+type metricsCollector struct {
+	service interface{ GetMetrics() models.MetricsSnapshot }
+}
+
+func (c metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metricsPeerCountDesc
+	ch <- metricsPendingQueueDesc
+	ch <- metricsNotificationBacklogDesc
+	ch <- metricsErrorsDesc
+	ch <- metricsRetryAttemptsDesc
+	ch <- metricsOperationErrorsDesc
+	ch <- metricsOperationDurationDesc
+}
+
+func (c metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot := c.service.GetMetrics()
+	ch <- prometheus.MustNewConstMetric(metricsPeerCountDesc, prometheus.GaugeValue, float64(snapshot.PeerCount))
+	ch <- prometheus.MustNewConstMetric(metricsPendingQueueDesc, prometheus.GaugeValue, float64(snapshot.PendingQueueSize))
+	ch <- prometheus.MustNewConstMetric(metricsNotificationBacklogDesc, prometheus.GaugeValue, float64(snapshot.NotificationBacklog))
+	for category, count := range snapshot.ErrorCounters {
+		ch <- prometheus.MustNewConstMetric(metricsErrorsDesc, prometheus.CounterValue, float64(count), category)
+	}
+	ch <- prometheus.MustNewConstMetric(metricsRetryAttemptsDesc, prometheus.CounterValue, float64(snapshot.RetryAttemptsTotal))
+	for operation, stats := range snapshot.OperationStats {
+		ch <- prometheus.MustNewConstMetric(metricsOperationErrorsDesc, prometheus.CounterValue, float64(stats.Errors), operation)
+	}
+	histograms, ok := c.service.(operationHistogramService)
+	if !ok {
+		return
+	}
+	for operation, hist := range histograms.OperationLatencyHistograms() {
+		buckets := make(map[float64]uint64, len(hist.UpperBounds))
+		for i, bound := range hist.UpperBounds {
+			buckets[bound] = hist.CumulativeCounts[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(metricsOperationDurationDesc, hist.Count, hist.SumSeconds, buckets, operation)
+	}
+}
+
+// ServeMetrics makes Run also serve /metrics in the Prometheus text format
+// on addr. Scrapes need the RPC token when one is set; without it addr must
+// be a loopback address.
+func (s *Server) ServeMetrics(addr string) error {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if !s.authEnabled() && !isLoopbackHost(host) {
+		return errMetricsAddrNotLoopback
+	}
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(metricsCollector{service: s.service}); err != nil {
+		return err
+	}
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		applySecurityHeaders(w)
+		if !s.authorizeTokenOrLoopback(w, r) {
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	s.metricsServer = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return nil
+}
+
+// authorizeTokenOrLoopback takes the RPC or an integration token when auth
+// is on and otherwise only answers loopback peers, in case the token was
+// dropped by a secret reload.
+func (s *Server) authorizeTokenOrLoopback(w http.ResponseWriter, r *http.Request) bool {
+	if s.authEnabled() {
+		return s.authorizeRPC(w, r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !isLoopbackHost(host) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// startMetrics binds the metrics address. A metrics address already in use
+// only costs the exporter, not the daemon.
+func (s *Server) startMetrics() func() {
+	if s.metricsServer == nil {
+		return func() {}
+	}
+	ln, err := net.Listen("tcp", s.metricsServer.Addr)
+	if err != nil {
+		slog.Default().Warn("metrics exporter unavailable", "addr", s.metricsServer.Addr, "error", err.Error())
+		return func() {}
+	}
+	go func() { _ = s.metricsServer.Serve(ln) }()
+	return func() { _ = s.metricsServer.Close() }
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type metricsExporterMockService struct {
+	channelMockService
+}
+
+func (m *metricsExporterMockService) GetMetrics() models.MetricsSnapshot {
+	return models.MetricsSnapshot{
+		PeerCount:           3,
+		PendingQueueSize:    7,
+		NotificationBacklog: 2,
+		ErrorCounters:       map[string]int{"network": 4},
+		OperationStats:      map[string]models.OperationMetric{"message.send": {Count: 2, Errors: 1}},
+	}
+}
+
+func (m *metricsExporterMockService) OperationLatencyHistograms() map[string]models.LatencyHistogram {
+	return map[string]models.LatencyHistogram{
+		"message.send": {Count: 2, SumSeconds: 0.3, UpperBounds: []float64{0.1, 1}, CumulativeCounts: []uint64{1, 2}},
+	}
+}
+
+func scrapeMetrics(s *Server, remoteAddr, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.metricsServer.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMetricsExporterServesPrometheusTextOnLoopback(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	s := newServerWithService(DefaultRPCAddr, &metricsExporterMockService{}, "", false)
+	if err := s.ServeMetrics("0.0.0.0:9464"); !errors.Is(err, errMetricsAddrNotLoopback) {
+		t.Fatalf("expected non-loopback address without token to be refused, got %v", err)
+	}
+	if err := s.ServeMetrics("127.0.0.1:9464"); err != nil {
+		t.Fatalf("serve metrics: %v", err)
+	}
+	if rec := scrapeMetrics(s, "192.0.2.10:5000", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected remote scrape to be forbidden, got %d", rec.Code)
+	}
+	rec := scrapeMetrics(s, "127.0.0.1:5000", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"aim_peer_count 3",
+		"aim_pending_queue_size 7",
+		"aim_notification_backlog 2",
+		`aim_errors_total{category="network"} 4`,
+		`aim_operation_errors_total{operation="message.send"} 1`,
+		`aim_operation_duration_seconds_bucket{operation="message.send",le="0.1"} 1`,
+		`aim_operation_duration_seconds_count{operation="message.send"} 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics output misses %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsExporterRequiresTokenWhenAuthEnabled(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	s := newServerWithService(DefaultRPCAddr, &metricsExporterMockService{}, "secret-token", true)
+	if err := s.ServeMetrics("0.0.0.0:9464"); err != nil {
+		t.Fatalf("token-protected exporter may bind any address: %v", err)
+	}
+	if rec := scrapeMetrics(s, "127.0.0.1:5000", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected scrape without token to be refused, got %d", rec.Code)
+	}
+	if rec := scrapeMetrics(s, "192.0.2.10:5000", "secret-token"); rec.Code != http.StatusOK {
+		t.Fatalf("expected scrape with token to succeed, got %d", rec.Code)
+	}
+}
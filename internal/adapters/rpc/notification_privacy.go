@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"os"
+	"strings"
+
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	notifyPrivacyEnv        = "AIM_NOTIFY_PRIVACY"
+	notifyPrivacyByTokenEnv = "AIM_NOTIFY_PRIVACY_BY_TOKEN"
+)
+
+type notificationPrivacyLevel int
+
+// Levels are ordered from least to most restrictive so that a subscriber may
+// only tighten, never relax, the level configured for its token.
+const (
+	notificationPrivacyFull notificationPrivacyLevel = iota
+	notificationPrivacySenderOnly
+	notificationPrivacyCountOnly
+)
+
+func parseNotificationPrivacyLevel(raw string) (notificationPrivacyLevel, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "full":
+		return notificationPrivacyFull, true
+	case "sender-only", "sender_only":
+		return notificationPrivacySenderOnly, true
+	case "count-only", "count_only":
+		return notificationPrivacyCountOnly, true
+	default:
+		return notificationPrivacyFull, false
+	}
+}
+
+func (l notificationPrivacyLevel) String() string {
+	switch l {
+	case notificationPrivacySenderOnly:
+		return "sender-only"
+	case notificationPrivacyCountOnly:
+		return "count-only"
+	default:
+		return "full"
+	}
+}
+
+type notificationPrivacyConfig struct {
+	Global  notificationPrivacyLevel
+	ByToken map[string]notificationPrivacyLevel
+}
+
+func loadNotificationPrivacyConfig() notificationPrivacyConfig {
+	cfg := notificationPrivacyConfig{
+		Global:  notificationPrivacyFull,
+		ByToken: map[string]notificationPrivacyLevel{},
+	}
+	if level, ok := parseNotificationPrivacyLevel(os.Getenv(notifyPrivacyEnv)); ok {
+		cfg.Global = level
+	}
+	for _, entry := range strings.Split(os.Getenv(notifyPrivacyByTokenEnv), ",") {
+		token, rawLevel, found := strings.Cut(strings.TrimSpace(entry), "=")
+		token = strings.TrimSpace(token)
+		if !found || token == "" {
+			continue
+		}
+		if level, ok := parseNotificationPrivacyLevel(rawLevel); ok {
+			cfg.ByToken[token] = level
+		}
+	}
+	return cfg
+}
+
+// resolve picks the effective level for a subscriber. The requested level is
+// honored only when it is stricter than the configured one.
+func (c notificationPrivacyConfig) resolve(token, requested string) notificationPrivacyLevel {
+	level := c.Global
+	if byToken, ok := c.ByToken[token]; ok && token != "" {
+		level = byToken
+	}
+	if wanted, ok := parseNotificationPrivacyLevel(requested); ok && wanted > level {
+		level = wanted
+	}
+	return level
+}
+
+// redactNotificationPayload strips message content from new-message and
+// thread-reply notifications according to the privacy level. Other
+// notifications carry no message bodies and pass through unchanged.
+func redactNotificationPayload(method string, payload any, level notificationPrivacyLevel) any {
+	if level == notificationPrivacyFull {
+		return payload
+	}
+	switch method {
+	case events.MethodMessageNew, events.MethodRequestNew, "notify.group.message.new", "notify.group.thread.reply":
+	default:
+		return payload
+	}
+	fields, ok := messagePayloadFields(payload)
+	if !ok {
+		return payload
+	}
+	if level == notificationPrivacyCountOnly {
+		counted := map[string]any{"count": 1}
+		// Alerting hints reveal nothing about the message and keep
+		// count-only clients ringing like the others.
+		if hints, ok := fields["notification"]; ok {
+			counted["notification"] = hints
+		}
+		return counted
+	}
+	redacted := make(map[string]any, len(fields))
+	for key, value := range fields {
+		if key != "message" {
+			redacted[key] = value
+		}
+	}
+	if msg, ok := fields["message"].(models.Message); ok {
+		redacted["message"] = map[string]any{
+			"id":                msg.ID,
+			"contact_id":        msg.ContactID,
+			"conversation_id":   msg.ConversationID,
+			"conversation_type": msg.ConversationType,
+			"timestamp":         msg.Timestamp,
+			"direction":         msg.Direction,
+		}
+	}
+	return redacted
+}
+
+// messagePayloadFields returns the wire fields of a message notification,
+// with the nested message as a value so that redaction can read it.
+func messagePayloadFields(payload any) (map[string]any, bool) {
+	switch evt := payload.(type) {
+	case map[string]any:
+		msg, ok := evt["message"].(*models.Message)
+		if !ok {
+			return evt, true
+		}
+		fields := make(map[string]any, len(evt))
+		for key, value := range evt {
+			fields[key] = value
+		}
+		if msg != nil {
+			fields["message"] = *msg
+		}
+		return fields, true
+	case events.MessageNew:
+		fields := map[string]any{"contact_id": evt.ContactID, "message": evt.Message}
+		if evt.Notification != nil {
+			fields["notification"] = *evt.Notification
+		}
+		return fields, true
+	case events.RequestNew:
+		return map[string]any{"contact_id": evt.ContactID, "message": evt.Message}, true
+	default:
+		return nil, false
+	}
+}
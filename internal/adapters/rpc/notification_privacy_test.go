@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestNotificationPrivacyConfig_TokenOverrideAndStricterRequest(t *testing.T) {
+	t.Setenv(notifyPrivacyEnv, "sender-only")
+	t.Setenv(notifyPrivacyByTokenEnv, "tok-a=full, tok-b=count-only, broken")
+	cfg := loadNotificationPrivacyConfig()
+
+	if got := cfg.resolve("", ""); got != notificationPrivacySenderOnly {
+		t.Fatalf("expected global sender-only, got %s", got)
+	}
+	if got := cfg.resolve("tok-a", ""); got != notificationPrivacyFull {
+		t.Fatalf("expected token override full, got %s", got)
+	}
+	if got := cfg.resolve("tok-a", "count-only"); got != notificationPrivacyCountOnly {
+		t.Fatalf("expected stricter requested level, got %s", got)
+	}
+	if got := cfg.resolve("tok-b", "full"); got != notificationPrivacyCountOnly {
+		t.Fatalf("expected requested level not to relax token level, got %s", got)
+	}
+}
+
+func TestRedactNotificationPayload_Levels(t *testing.T) {
+	payload := map[string]any{
+		"contact_id": "aim1sender",
+		"message": models.Message{
+			ID:        "msg-1",
+			ContactID: "aim1sender",
+			Content:   []byte("secret"),
+			Timestamp: time.Now(),
+			Direction: "in",
+		},
+	}
+
+	if got := redactNotificationPayload("notify.message.new", payload, notificationPrivacyFull); got == nil {
+		t.Fatal("expected full payload")
+	}
+
+	senderOnly, ok := redactNotificationPayload("notify.message.new", payload, notificationPrivacySenderOnly).(map[string]any)
+	if !ok {
+		t.Fatal("expected map payload for sender-only level")
+	}
+	if senderOnly["contact_id"] != "aim1sender" {
+		t.Fatalf("expected sender to be preserved, got %#v", senderOnly["contact_id"])
+	}
+	msg, ok := senderOnly["message"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected message metadata map, got %T", senderOnly["message"])
+	}
+	if _, leaked := msg["content"]; leaked {
+		t.Fatal("message content leaked at sender-only level")
+	}
+
+	countOnly, ok := redactNotificationPayload("notify.message.new", payload, notificationPrivacyCountOnly).(map[string]any)
+	if !ok || len(countOnly) != 1 || countOnly["count"] != 1 {
+		t.Fatalf("unexpected count-only payload: %#v", countOnly)
+	}
+
+	typed := events.MessageNew{ContactID: "aim1sender", Message: payload["message"].(models.Message), Notification: &models.NotificationHints{Sound: "chime"}}
+	typedSenderOnly, ok := redactNotificationPayload(events.MethodMessageNew, typed, notificationPrivacySenderOnly).(map[string]any)
+	if !ok {
+		t.Fatal("expected typed event to be redacted to a map")
+	}
+	if typedMsg, _ := typedSenderOnly["message"].(map[string]any); typedMsg == nil || typedMsg["content"] != nil {
+		t.Fatalf("typed event content leaked at sender-only level: %#v", typedSenderOnly)
+	}
+	typedCountOnly, _ := redactNotificationPayload(events.MethodMessageNew, typed, notificationPrivacyCountOnly).(map[string]any)
+	if typedCountOnly["count"] != 1 || typedCountOnly["notification"] != *typed.Notification {
+		t.Fatalf("unexpected typed count-only payload: %#v", typedCountOnly)
+	}
+
+	status := map[string]any{"message_id": "msg-1", "status": "read"}
+	passthrough, ok := redactNotificationPayload("notify.message.status", status, notificationPrivacyCountOnly).(map[string]any)
+	if !ok || passthrough["status"] != "read" {
+		t.Fatalf("expected non-message notifications to pass through, got %#v", passthrough)
+	}
+}
+
+func TestRedactNotificationPayload_ThreadReply(t *testing.T) {
+	msg := &models.Message{
+		ID:             "msg-2",
+		ContactID:      "aim1sender",
+		ConversationID: "group-1",
+		ThreadID:       "thread-1",
+		Content:        []byte("secret reply"),
+		Timestamp:      time.Now(),
+		Direction:      "in",
+	}
+	for name, message := range map[string]any{"value": *msg, "pointer": msg} {
+		payload := map[string]any{
+			"group_id":  "group-1",
+			"thread_id": "thread-1",
+			"message":   message,
+		}
+
+		senderOnly, ok := redactNotificationPayload("notify.group.thread.reply", payload, notificationPrivacySenderOnly).(map[string]any)
+		if !ok {
+			t.Fatalf("%s: expected map payload for sender-only level", name)
+		}
+		if senderOnly["thread_id"] != "thread-1" {
+			t.Fatalf("%s: expected thread id to be preserved, got %#v", name, senderOnly["thread_id"])
+		}
+		redacted, ok := senderOnly["message"].(map[string]any)
+		if !ok {
+			t.Fatalf("%s: expected message metadata map, got %T", name, senderOnly["message"])
+		}
+		if redacted["id"] != "msg-2" || redacted["content"] != nil {
+			t.Fatalf("%s: thread reply content leaked at sender-only level: %#v", name, redacted)
+		}
+
+		countOnly, ok := redactNotificationPayload("notify.group.thread.reply", payload, notificationPrivacyCountOnly).(map[string]any)
+		if !ok || len(countOnly) != 1 || countOnly["count"] != 1 {
+			t.Fatalf("%s: unexpected count-only payload: %#v", name, countOnly)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"aim-chat/go-backend/internal/adapters/rpc/rpcrecord"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+)
+
+const (
+	rpcRecordPathEnv = "AIM_RPC_RECORD_PATH"
+
+	rpcRecordRedacted       = "[redacted]"
+	maxRecordedParamsBytes  = 64 << 10
+	maxRecordedResultsBytes = 256 << 10
+)
+
+// rpcSecretParamMethods take credentials as positional params; their params
+// are never written to a recording.
+var rpcSecretParamMethods = map[string]struct{}{
+	identitytransport.MethodIdentityLogin:      {},
+	identitytransport.MethodIdentityCreate:     {},
+	identitytransport.MethodIdentityExportSeed: {},
+	identitytransport.MethodIdentityImportSeed: {},
+	identitytransport.MethodIdentityMnemonic:   {},
+	identitytransport.MethodIdentityChangePwd:  {},
+	identitytransport.MethodBackupExport:       {},
+	identitytransport.MethodBackupRestore:      {},
+	identitytransport.MethodStorageRotateKey:   {},
+	"node.binding.complete":                    {},
+	"session.init":                             {},
+}
+
+var rpcSecretFieldMarkers = []string{"password", "passphrase", "mnemonic", "secret", "token", "private_key", "seed", "backup_blob"}
+
+// rpcRecorder captures dispatched RPC traffic for later replay. Credentials
+// are redacted before anything reaches the file.
+type rpcRecorder struct {
+	writer *rpcrecord.Writer
+	failed atomic.Bool
+}
+
+func loadRPCRecorder() *rpcRecorder {
+	path := strings.TrimSpace(os.Getenv(rpcRecordPathEnv))
+	if path == "" {
+		return nil
+	}
+	writer, err := rpcrecord.OpenWriter(path)
+	if err != nil {
+		slog.Default().Warn("rpc recording disabled", "path", path, "error", err.Error())
+		return nil
+	}
+	slog.Default().Warn("rpc recording enabled; traffic is written to disk", "path", path)
+	return &rpcRecorder{writer: writer}
+}
+
+func (r *rpcRecorder) record(started time.Time, requestID, caller string, req rpcRequest, resp rpcResponse) {
+	if r == nil {
+		return
+	}
+	entry := rpcrecord.Entry{
+		Time:      started.UTC(),
+		RequestID: requestID,
+		Caller:    caller,
+		Method:    req.Method,
+		LatencyMS: time.Since(started).Milliseconds(),
+	}
+	entry.Params, entry.Redacted = redactRecordedParams(req.Method, req.Params)
+	if resp.Error != nil {
+		entry.Error = &rpcrecord.Error{Code: resp.Error.Code, Message: resp.Error.Message}
+	} else if resp.Result != nil {
+		entry.Result = redactRecordedResult(resp.Result)
+	}
+	if err := r.writer.Write(entry); err != nil && r.failed.CompareAndSwap(false, true) {
+		slog.Default().Warn("rpc recording write failed", "error", err.Error())
+	}
+}
+
+func (r *rpcRecorder) close() {
+	if r == nil {
+		return
+	}
+	_ = r.writer.Close()
+}
+
+func redactRecordedParams(method string, params json.RawMessage) (json.RawMessage, bool) {
+	if len(params) == 0 {
+		return nil, false
+	}
+	if _, secret := rpcSecretParamMethods[method]; secret || len(params) > maxRecordedParamsBytes {
+		return json.RawMessage(`"` + rpcRecordRedacted + `"`), true
+	}
+	return redactRecordedJSON(params), false
+}
+
+func redactRecordedResult(result any) json.RawMessage {
+	raw, err := json.Marshal(result)
+	if err != nil || len(raw) > maxRecordedResultsBytes {
+		return json.RawMessage(`"` + rpcRecordRedacted + `"`)
+	}
+	return redactRecordedJSON(raw)
+}
+
+func redactRecordedJSON(raw json.RawMessage) json.RawMessage {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return json.RawMessage(`"` + rpcRecordRedacted + `"`)
+	}
+	out, err := json.Marshal(redactSecretFields(value))
+	if err != nil {
+		return json.RawMessage(`"` + rpcRecordRedacted + `"`)
+	}
+	return out
+}
+
+func redactSecretFields(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecretFieldName(key) {
+				v[key] = rpcRecordRedacted
+				continue
+			}
+			v[key] = redactSecretFields(field)
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactSecretFields(v[i])
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func isSecretFieldName(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range rpcSecretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/adapters/rpc/rpcrecord"
+	"aim-chat/go-backend/internal/storage"
+)
+
+func TestRPCRecorderWritesRedactedTraffic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpc.jsonl")
+	t.Setenv(rpcRecordPathEnv, path)
+	svc := &annotationMockService{channelMockService: &channelMockService{}, store: storage.NewMessageAnnotationStore()}
+	s := newServerWithService(DefaultRPCAddr, svc, "primary-token", true)
+	defer s.recorder.close()
+
+	postAnnotationRPC(t, s, "primary-token", methodMessageAnnotate, []string{"m-1", "ticket", "T-42"})
+	postAnnotationRPC(t, s, "primary-token", "identity.export_seed", []string{"hunter2-password"})
+	postAnnotationRPC(t, s, "primary-token", "backup.export", []string{"consent", "hunter2-password"})
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	if strings.Contains(string(raw), "hunter2") || strings.Contains(string(raw), "primary-token") {
+		t.Fatalf("recording leaks credentials: %s", raw)
+	}
+	entries, err := rpcrecord.ReadEntries(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("parse recording: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	annotate := entries[0]
+	if annotate.Method != methodMessageAnnotate || annotate.Redacted || !strings.Contains(string(annotate.Params), "T-42") {
+		t.Fatalf("regular params must be kept: %+v", annotate)
+	}
+	if !strings.HasPrefix(annotate.Caller, "token:") {
+		t.Fatalf("caller must be the hashed namespace, got %q", annotate.Caller)
+	}
+	exportSeed := entries[1]
+	if !exportSeed.Redacted || !strings.Contains(string(exportSeed.Result), rpcRecordRedacted) {
+		t.Fatalf("seed export must be redacted: params=%s result=%s", exportSeed.Params, exportSeed.Result)
+	}
+}
+
+func TestRedactSecretFieldsWalksNestedValues(t *testing.T) {
+	got := string(redactRecordedJSON([]byte(`{"items":[{"link_token":"abc","name":"n"}],"mnemonic":"words"}`)))
+	if strings.Contains(got, "abc") || strings.Contains(got, "words") || !strings.Contains(got, `"name":"n"`) {
+		t.Fatalf("unexpected redaction result: %s", got)
+	}
+}
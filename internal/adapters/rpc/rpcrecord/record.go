@@ -0,0 +1,110 @@
+// Package rpcrecord defines the on-disk format of recorded RPC traffic and
+// replays recordings against a running daemon.
+package rpcrecord
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const maxEntryBytes = 4 << 20
+
+// Error mirrors a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Entry is one request/response pair. Params and Result are already redacted
+// when written; Redacted marks entries whose params were dropped entirely and
+// therefore cannot be replayed.
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	RequestID string          `json:"request_id,omitempty"`
+	Caller    string          `json:"caller,omitempty"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+	LatencyMS int64           `json:"latency_ms"`
+	Redacted  bool            `json:"redacted,omitempty"`
+}
+
+// Writer appends entries to a JSON lines file. It is safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func OpenWriter(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{f: f}, nil
+}
+
+func (w *Writer) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	_, err = w.f.Write(append(line, '\n'))
+	return err
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// ReadEntries parses a recording. Blank lines are ignored.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxEntryBytes)
+	var out []Entry
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if entry.Method == "" {
+			return nil, fmt.Errorf("line %d: method is required", lineNo)
+		}
+		out = append(out, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d: entry exceeds %d bytes", lineNo+1, maxEntryBytes)
+		}
+		return nil, err
+	}
+	return out, nil
+}
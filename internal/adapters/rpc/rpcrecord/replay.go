@@ -0,0 +1,124 @@
+package rpcrecord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SendFunc issues one JSON-RPC call. A non-nil error means transport failure;
+// JSON-RPC errors are reported through the *Error result.
+type SendFunc func(ctx context.Context, method string, params json.RawMessage) (json.RawMessage, *Error, error)
+
+// Outcome describes how one recorded entry behaved on replay.
+type Outcome struct {
+	Index    int
+	Method   string
+	Skipped  bool
+	Mismatch string
+	Err      error
+	Latency  time.Duration
+}
+
+// Replayer sends recorded requests again. Speed scales the recorded gaps
+// between requests: 1 keeps the original timing, 2 replays twice as fast and
+// 0 sends back to back. MaxDelay caps a single gap when positive.
+type Replayer struct {
+	Send           SendFunc
+	Speed          float64
+	MaxDelay       time.Duration
+	CompareResults bool
+	Sleep          func(ctx context.Context, d time.Duration) error
+}
+
+func (r *Replayer) Replay(ctx context.Context, entries []Entry, visit func(Outcome)) error {
+	sleep := r.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+	for i, entry := range entries {
+		if i > 0 && r.Speed > 0 {
+			if err := sleep(ctx, r.delay(entries[i-1].Time, entry.Time)); err != nil {
+				return err
+			}
+		}
+		outcome := Outcome{Index: i, Method: entry.Method}
+		if entry.Redacted {
+			outcome.Skipped = true
+			visit(outcome)
+			continue
+		}
+		started := time.Now()
+		result, rpcErr, err := r.Send(ctx, entry.Method, entry.Params)
+		outcome.Latency = time.Since(started)
+		if err != nil {
+			outcome.Err = err
+		} else {
+			outcome.Mismatch = r.compare(entry, result, rpcErr)
+		}
+		visit(outcome)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+	return nil
+}
+
+func (r *Replayer) delay(prev, next time.Time) time.Duration {
+	gap := next.Sub(prev)
+	if gap <= 0 {
+		return 0
+	}
+	gap = time.Duration(float64(gap) / r.Speed)
+	if r.MaxDelay > 0 && gap > r.MaxDelay {
+		gap = r.MaxDelay
+	}
+	return gap
+}
+
+func (r *Replayer) compare(entry Entry, result json.RawMessage, rpcErr *Error) string {
+	switch {
+	case entry.Error != nil && rpcErr == nil:
+		return fmt.Sprintf("expected error %d, got success", entry.Error.Code)
+	case entry.Error == nil && rpcErr != nil:
+		return fmt.Sprintf("expected success, got error %d: %s", rpcErr.Code, rpcErr.Message)
+	case entry.Error != nil && rpcErr.Code != entry.Error.Code:
+		return fmt.Sprintf("expected error %d, got %d: %s", entry.Error.Code, rpcErr.Code, rpcErr.Message)
+	}
+	if !r.CompareResults || entry.Error != nil {
+		return ""
+	}
+	if !equalJSON(entry.Result, result) {
+		return "result differs from recording"
+	}
+	return ""
+}
+
+func equalJSON(a, b json.RawMessage) bool {
+	if len(bytes.TrimSpace(a)) == 0 || len(bytes.TrimSpace(b)) == 0 {
+		return len(bytes.TrimSpace(a)) == len(bytes.TrimSpace(b))
+	}
+	var left, right any
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return bytes.Equal(a, b)
+	}
+	normalizedLeft, _ := json.Marshal(left)
+	normalizedRight, _ := json.Marshal(right)
+	return bytes.Equal(normalizedLeft, normalizedRight)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
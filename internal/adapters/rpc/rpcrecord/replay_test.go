@@ -0,0 +1,100 @@
+package rpcrecord
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriterAndReadEntriesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "rpc.jsonl")
+	w, err := OpenWriter(path)
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := w.Write(Entry{Time: now, Method: "message.list", Params: json.RawMessage(`["c1"]`)}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Write(Entry{Time: now, Method: "identity.login", Redacted: true, Error: &Error{Code: -32029, Message: "bad"}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := w.Write(Entry{Method: "late"}); err == nil {
+		t.Fatal("expected write after close to fail")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open recording: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	entries, err := ReadEntries(f)
+	if err != nil {
+		t.Fatalf("read entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Method != "message.list" || !entries[1].Redacted || entries[1].Error.Code != -32029 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestReplayerScalesTimingSkipsRedactedAndReportsMismatches(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Method: "a", Result: json.RawMessage(`{"x":1,"y":2}`)},
+		{Time: base.Add(4 * time.Second), Method: "identity.login", Redacted: true},
+		{Time: base.Add(10 * time.Second), Method: "b", Error: &Error{Code: -32001}},
+		{Time: base.Add(11 * time.Second), Method: "c", Result: json.RawMessage(`{"x":1}`)},
+	}
+	responses := map[string]struct {
+		result json.RawMessage
+		err    *Error
+	}{
+		"a": {result: json.RawMessage(`{"y":2,"x":1}`)},
+		"b": {result: json.RawMessage(`{}`)},
+		"c": {result: json.RawMessage(`{"x":2}`)},
+	}
+	var sent []string
+	var delays []time.Duration
+	r := &Replayer{
+		Speed:          2,
+		MaxDelay:       2 * time.Second,
+		CompareResults: true,
+		Send: func(_ context.Context, method string, _ json.RawMessage) (json.RawMessage, *Error, error) {
+			sent = append(sent, method)
+			resp := responses[method]
+			return resp.result, resp.err, nil
+		},
+		Sleep: func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+	var outcomes []Outcome
+	if err := r.Replay(context.Background(), entries, func(o Outcome) { outcomes = append(outcomes, o) }); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(sent) != 3 {
+		t.Fatalf("redacted entry must not be sent: %v", sent)
+	}
+	wantDelays := []time.Duration{2 * time.Second, 2 * time.Second, 500 * time.Millisecond}
+	for i, want := range wantDelays {
+		if delays[i] != want {
+			t.Fatalf("delay %d: got %s want %s", i, delays[i], want)
+		}
+	}
+	if outcomes[0].Mismatch != "" {
+		t.Fatalf("key order must not cause a mismatch: %s", outcomes[0].Mismatch)
+	}
+	if !outcomes[1].Skipped {
+		t.Fatal("expected redacted entry to be skipped")
+	}
+	if outcomes[2].Mismatch == "" || outcomes[3].Mismatch == "" {
+		t.Fatalf("expected mismatches for error and result drift: %+v", outcomes)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,22 +21,38 @@ import (
 	"time"
 
 	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/platform/handover"
+	"aim-chat/go-backend/internal/platform/secrets"
+	"aim-chat/go-backend/pkg/models"
 )
 
 const DefaultRPCAddr = "127.0.0.1:8787"
 
 type Server struct {
-	httpServer    *http.Server
-	service       contracts.DaemonService
-	initErr       error
-	rpcToken      string
-	requireRPC    bool
-	groupsEnabled bool
-	rpcLimiter    *rpcRateLimiter
-	fileLimiter   *rpcRateLimiter
-	streams       *rpcStreamLimiter
-	idempotency   *rpcIdempotencyCache
-	idempotencyMu sync.Mutex
+	httpServer        *http.Server
+	service           contracts.DaemonService
+	initErr           error
+	authMu            sync.RWMutex
+	rpcToken          string
+	requireRPC        bool
+	integrationTokens map[string]string
+	guests            *rpcGuestTokenStore
+	groupsEnabled     bool
+	rpcLimiter        *rpcRateLimiter
+	usage             *rpcTokenUsageMeter
+	fileLimiter       *rpcRateLimiter
+	streams           *rpcStreamLimiter
+	notifyPrivacy     notificationPrivacyConfig
+	idempotency       *rpcIdempotencyCache
+	idempotencyMu     sync.Mutex
+	recorder          *rpcRecorder
+	defaultLocale     string
+	deadlines         rpcDeadlines
+	listener          net.Listener
+	conns             *rpcConnTracker
+	handovers         <-chan *handover.Request
+	handedOver        *handover.Request
+	metricsServer     *http.Server
 }
 
 func NewServerWithService(rpcAddr string, svc contracts.DaemonService) *Server {
@@ -57,28 +75,41 @@ func newServerWithService(rpcAddr string, svc contracts.DaemonService, rpcToken
 	}
 
 	mux := http.NewServeMux()
+	conns := newRPCConnTracker()
 	s := &Server{
 		httpServer: &http.Server{
 			Addr:              rpcAddr,
 			Handler:           mux,
 			ReadHeaderTimeout: 5 * time.Second,
+			ConnState:         conns.track,
 		},
-		service:       svc,
-		rpcToken:      rpcToken,
-		requireRPC:    requireRPC,
-		groupsEnabled: groupsEnabled(),
-		rpcLimiter:    newRPCRateLimiter(loadRPCRateLimitConfig()),
-		fileLimiter:   newFileRateLimiter(loadFileRateLimitConfig()),
-		streams:       newRPCStreamLimiter(loadRPCStreamLimitConfig()),
-		idempotency:   newRPCIdempotencyCache(),
-	}
-	if s.rpcToken == "" && !s.requireRPC {
+		conns:             conns,
+		service:           svc,
+		rpcToken:          rpcToken,
+		requireRPC:        requireRPC,
+		integrationTokens: loadRPCIntegrationTokens(),
+		guests:            newRPCGuestTokenStore(),
+		groupsEnabled:     groupsEnabled(),
+		rpcLimiter:        newRPCRateLimiter(loadRPCRateLimitConfig()),
+		usage:             newRPCTokenUsageMeter(loadRPCTokenQuotas()),
+		fileLimiter:       newFileRateLimiter(loadFileRateLimitConfig()),
+		streams:           newRPCStreamLimiter(loadRPCStreamLimitConfig()),
+		notifyPrivacy:     loadNotificationPrivacyConfig(),
+		idempotency:       newRPCIdempotencyCache(),
+		recorder:          loadRPCRecorder(),
+		defaultLocale:     loadRPCDefaultLocale(),
+		deadlines:         loadRPCDeadlines(),
+	}
+	if !s.authEnabled() {
 		slog.Default().Warn("AIM_RPC_TOKEN is not set; RPC auth disabled")
 	}
 	mux.HandleFunc("/healthz", s.handleHealth)
 	mux.HandleFunc("/rpc", s.handleRPC)
 	mux.HandleFunc("/rpc/stream", s.handleRPCStream)
+	mux.HandleFunc("/ws", s.handleNotificationsWebSocket)
 	mux.HandleFunc("/files/", s.handleFileDownload)
+	mux.HandleFunc("/thumbnails/", s.handleThumbnail)
+	mux.HandleFunc("/hooks/send", s.handleHookSend)
 	return s
 }
 
@@ -91,13 +122,27 @@ func (s *Server) Run(ctx context.Context) error {
 		return nil
 	default:
 	}
+	defer s.closeService()
 	if err := s.service.StartNetworking(ctx); err != nil {
 		return err
 	}
 
+	defer s.recorder.close()
+
+	ln := s.listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", s.httpServer.Addr); err != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = s.service.StopNetworking(shutdownCtx)
+			cancel()
+			return err
+		}
+	}
+	defer s.startMetrics()()
 	errCh := make(chan error, 1)
 	go func() {
-		err := s.httpServer.ListenAndServe()
+		err := s.httpServer.Serve(ln)
 		if errors.Is(err, http.ErrServerClosed) {
 			errCh <- nil
 			return
@@ -105,27 +150,82 @@ func (s *Server) Run(ctx context.Context) error {
 		errCh <- err
 	}()
 
-	select {
-	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+				cancel()
+				return err
+			}
+			if err := s.service.StopNetworking(shutdownCtx); err != nil {
+				cancel()
+				return err
+			}
 			cancel()
-			return err
-		}
-		if err := s.service.StopNetworking(shutdownCtx); err != nil {
+			return <-errCh
+		case err := <-errCh:
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			_ = s.service.StopNetworking(shutdownCtx)
 			cancel()
 			return err
+		case req := <-s.handovers:
+			if err := req.SendListener(ln); err != nil {
+				slog.Default().Warn("rpc listener handover failed", "error", err.Error(), "successor_pid", req.PID)
+				req.Reject(err)
+				continue
+			}
+			s.handedOver = req
+			return s.drainForHandover(ln, errCh)
 		}
-		cancel()
-		return <-errCh
-	case err := <-errCh:
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		_ = s.service.StopNetworking(shutdownCtx)
-		cancel()
-		return err
 	}
 }
 
+// closeService releases the service once Run stops for good. Services that
+// hold nothing beyond networking do not implement io.Closer.
+func (s *Server) closeService() {
+	closer, ok := s.service.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		slog.Default().Warn("rpc service close failed", "error", err.Error())
+	}
+}
+
+// UseListener makes Run serve on ln, typically one inherited from the
+// previous daemon, instead of binding the configured address.
+func (s *Server) UseListener(ln net.Listener) {
+	s.listener = ln
+}
+
+// AcceptHandovers lets Run pass its listener to a successor daemon. Run then
+// returns nil once this process stopped serving, and HandedOver reports the
+// request to complete after the data dir is released.
+func (s *Server) AcceptHandovers(requests <-chan *handover.Request) {
+	s.handovers = requests
+}
+
+// HandedOver returns the handover Run ended with, or nil.
+func (s *Server) HandedOver() *handover.Request {
+	return s.handedOver
+}
+
+// drainForHandover stops accepting on this process's copy of the listener
+// and lets the connections already accepted finish. Shutdown is not used
+// because it drops requests read after it starts, and the successor cannot
+// pick those up. Connections arriving meanwhile wait in the shared backlog.
+func (s *Server) drainForHandover(ln net.Listener, errCh <-chan error) error {
+	s.httpServer.SetKeepAlivesEnabled(false)
+	_ = ln.Close()
+	<-errCh
+	drainCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.conns.waitIdle(drainCtx)
+	_ = s.httpServer.Close()
+	return s.service.StopNetworking(drainCtx)
+}
+
 func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	s.handleHealth(w, r)
 }
@@ -169,7 +269,8 @@ func (s *Server) handleRPCStream(w http.ResponseWriter, r *http.Request) {
 	if !s.authorizeRPC(w, r) {
 		return
 	}
-	clientKey := rpcRateLimitKey(r, s.extractRPCToken(r))
+	token := s.extractRPCToken(r)
+	clientKey := rpcRateLimitKey(r, token)
 	release, allowed := s.streams.acquire(clientKey)
 	if !allowed {
 		http.Error(w, "too many stream subscriptions", http.StatusTooManyRequests)
@@ -195,6 +296,8 @@ func (s *Server) handleRPCStream(w http.ResponseWriter, r *http.Request) {
 		}
 		cursor = v
 	}
+	privacyLevel := s.notifyPrivacy.resolve(token, r.URL.Query().Get("privacy"))
+	locale := s.negotiateRPCLocale(r, r.URL.Query().Get("locale"))
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -204,7 +307,7 @@ func (s *Server) handleRPCStream(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	for _, evt := range replay {
-		if err := writeSSEEvent(w, evt); err != nil {
+		if err := writeSSEEvent(w, evt, privacyLevel, locale); err != nil {
 			return
 		}
 		flusher.Flush()
@@ -221,7 +324,7 @@ func (s *Server) handleRPCStream(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
-			if err := writeSSEEvent(w, evt); err != nil {
+			if err := writeSSEEvent(w, evt, privacyLevel, locale); err != nil {
 				return
 			}
 			flusher.Flush()
@@ -232,18 +335,8 @@ func (s *Server) handleRPCStream(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func writeSSEEvent(w http.ResponseWriter, evt NotificationEvent) error {
-	notification := map[string]any{
-		"jsonrpc": "2.0",
-		"method":  evt.Method,
-		"params": map[string]any{
-			"version":   rpcNotificationVersion,
-			"seq":       evt.Seq,
-			"timestamp": evt.Timestamp,
-			"payload":   evt.Payload,
-		},
-	}
-	data, err := json.Marshal(notification)
+func writeSSEEvent(w http.ResponseWriter, evt NotificationEvent, privacyLevel notificationPrivacyLevel, locale string) error {
+	data, err := encodeNotification(evt, privacyLevel, locale)
 	if err != nil {
 		return err
 	}
@@ -256,6 +349,22 @@ func writeSSEEvent(w http.ResponseWriter, evt NotificationEvent) error {
 	return nil
 }
 
+// encodeNotification renders evt as a JSON-RPC notification for a stream
+// client, redacted and localized for it.
+func encodeNotification(evt NotificationEvent, privacyLevel notificationPrivacyLevel, locale string) ([]byte, error) {
+	notification := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  evt.Method,
+		"params": map[string]any{
+			"version":   rpcNotificationVersion,
+			"seq":       evt.Seq,
+			"timestamp": evt.Timestamp,
+			"payload":   localizeNotificationPayload(evt.Method, redactNotificationPayload(evt.Method, evt.Payload, privacyLevel), locale),
+		},
+	}
+	return json.Marshal(notification)
+}
+
 func (s *Server) applyCORS(w http.ResponseWriter, r *http.Request) bool {
 	applySecurityHeaders(w)
 	origin := strings.TrimSpace(r.Header.Get("Origin"))
@@ -285,7 +394,7 @@ func (s *Server) isAllowedRequestOrigin(origin string) bool {
 		return false
 	}
 	// When RPC auth is disabled in non-prod, block browser origins to reduce CSRF-like local abuse.
-	if s.rpcToken == "" && !s.requireRPC {
+	if !s.authEnabled() {
 		return false
 	}
 	return true
@@ -342,18 +451,133 @@ func (s *Server) handleFileDownload(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// handleThumbnail serves small cached images such as group avatars at
+// /thumbnails/groups/<group_id> and attachment previews at
+// /thumbnails/attachments/<blob_id> so clients can use them as image sources.
+func (s *Server) handleThumbnail(w http.ResponseWriter, r *http.Request) {
+	if !s.applyCORS(w, r) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !s.fileLimiter.allow(rpcRateLimitKey(r, s.extractRPCToken(r)), time.Now()) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if !s.authorizeRPC(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cleaned := path.Clean(r.URL.Path)
+	if blobID, ok := strings.CutPrefix(cleaned, "/thumbnails/attachments/"); ok {
+		s.serveAttachmentThumbnail(w, r, blobID)
+		return
+	}
+	groupID, ok := strings.CutPrefix(cleaned, "/thumbnails/groups/")
+	if !ok || groupID == "" || strings.Contains(groupID, "/") {
+		http.Error(w, "invalid thumbnail id", http.StatusBadRequest)
+		return
+	}
+	avatars, supported := s.service.(interface {
+		GetGroupAvatar(groupID string) (models.GroupAvatar, error)
+	})
+	if !supported {
+		http.Error(w, "thumbnail not found", http.StatusNotFound)
+		return
+	}
+	avatar, err := avatars.GetGroupAvatar(groupID)
+	if err != nil {
+		http.Error(w, "thumbnail not found", http.StatusNotFound)
+		return
+	}
+	writeThumbnail(w, r, `"`+avatar.SHA256+`"`, "private, max-age=0, must-revalidate", avatar.MimeType, avatar.Data)
+}
+
+// serveAttachmentThumbnail serves the preview of an image attachment. Blobs
+// are immutable, so the preview may be cached for as long as the client likes.
+func (s *Server) serveAttachmentThumbnail(w http.ResponseWriter, r *http.Request, blobID string) {
+	if blobID == "" || strings.Contains(blobID, "/") {
+		http.Error(w, "invalid thumbnail id", http.StatusBadRequest)
+		return
+	}
+	thumbnails, supported := s.service.(interface {
+		GetAttachmentThumbnail(blobID string) (models.AttachmentThumbnail, error)
+	})
+	if !supported {
+		http.Error(w, "thumbnail not found", http.StatusNotFound)
+		return
+	}
+	thumb, err := thumbnails.GetAttachmentThumbnail(blobID)
+	if err != nil {
+		http.Error(w, "thumbnail not found", http.StatusNotFound)
+		return
+	}
+	writeThumbnail(w, r, `"`+thumb.BlobID+`"`, "private, max-age=86400, immutable", thumb.MimeType, thumb.Data)
+}
+
+func writeThumbnail(w http.ResponseWriter, r *http.Request, etag, cacheControl, mimeType string, data []byte) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
 func (s *Server) authorizeRPC(w http.ResponseWriter, r *http.Request) bool {
-	if s.rpcToken == "" && !s.requireRPC {
+	if !s.authEnabled() {
 		return true
 	}
 	token := s.extractRPCToken(r)
-	if token != s.rpcToken {
+	s.authMu.RLock()
+	_, integration := s.integrationTokens[token]
+	valid := token == s.rpcToken || integration
+	s.authMu.RUnlock()
+	if !valid && path.Clean(r.URL.Path) == "/rpc" {
+		// Guest tokens reach JSON-RPC only, never the stream or files.
+		_, valid = s.guests.lookup(token, time.Now())
+	}
+	if !valid {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return false
 	}
 	return true
 }
 
+func (s *Server) authEnabled() bool {
+	s.authMu.RLock()
+	defer s.authMu.RUnlock()
+	return s.rpcToken != "" || s.requireRPC
+}
+
+// ReloadSecrets re-resolves the RPC token and integration tokens, picking up
+// rotated secret files or password manager entries without a restart. The
+// token is not regenerated even if rotation on start is enabled.
+func (s *Server) ReloadSecrets() error {
+	token, err := secrets.ResolveEnv("AIM_RPC_TOKEN")
+	if err != nil {
+		return err
+	}
+	if s.requireRPC && token == "" {
+		return errors.New("AIM_RPC_TOKEN resolved to an empty token")
+	}
+	integrationTokens := loadRPCIntegrationTokens()
+	s.authMu.Lock()
+	s.rpcToken = token
+	s.integrationTokens = integrationTokens
+	s.authMu.Unlock()
+	return nil
+}
+
 func (s *Server) extractRPCToken(r *http.Request) string {
 	token := strings.TrimSpace(r.Header.Get("X-AIM-RPC-Token"))
 	if token != "" {
@@ -363,7 +587,8 @@ func (s *Server) extractRPCToken(r *http.Request) string {
 	if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
 		return strings.TrimSpace(auth[len("bearer "):])
 	}
-	if r.Method == http.MethodGet && strings.HasPrefix(path.Clean(r.URL.Path), "/files/") {
+	if cleaned := path.Clean(r.URL.Path); r.Method == http.MethodGet &&
+		(strings.HasPrefix(cleaned, "/files/") || strings.HasPrefix(cleaned, "/thumbnails/") || cleaned == "/ws") {
 		queryToken := strings.TrimSpace(r.URL.Query().Get("rpc_token"))
 		if queryToken != "" {
 			return queryToken
@@ -429,7 +654,10 @@ func parseBoolEnv(name string) (bool, bool) {
 }
 
 func resolveRPCToken() (string, error) {
-	token := strings.TrimSpace(os.Getenv("AIM_RPC_TOKEN"))
+	token, err := secrets.ResolveEnv("AIM_RPC_TOKEN")
+	if err != nil {
+		return "", err
+	}
 	rotate := strings.EqualFold(token, "auto")
 	if !rotate {
 		if v, ok := parseBoolEnv("AIM_RPC_TOKEN_ROTATE_ON_START"); ok && v {
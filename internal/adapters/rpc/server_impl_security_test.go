@@ -138,6 +138,58 @@ func TestResolveRPCToken_RotateOnStartOverridesStaticToken(t *testing.T) {
 	}
 }
 
+func TestResolveRPCToken_ResolvesSecretFileReference(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "rpc.token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	t.Setenv("AIM_RPC_TOKEN", "secret://file/"+tokenFile)
+
+	token, err := resolveRPCToken()
+	if err != nil {
+		t.Fatalf("resolve token: %v", err)
+	}
+	if token != "file-token" {
+		t.Fatalf("unexpected token: %q", token)
+	}
+}
+
+func TestReloadSecrets_PicksUpRotatedTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "rpc.token")
+	if err := os.WriteFile(tokenFile, []byte("old-token"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	t.Setenv("AIM_RPC_TOKEN", "secret://file/"+tokenFile)
+	t.Setenv(rpcIntegrationTokensEnv, "")
+	s := &Server{rpcToken: "old-token", requireRPC: true}
+
+	if err := os.WriteFile(tokenFile, []byte("new-token"), 0o600); err != nil {
+		t.Fatalf("rotate token file: %v", err)
+	}
+	if err := s.ReloadSecrets(); err != nil {
+		t.Fatalf("reload secrets: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	req.Header.Set("X-AIM-RPC-Token", "old-token")
+	if s.authorizeRPC(httptest.NewRecorder(), req) {
+		t.Fatal("expected old token to be rejected after reload")
+	}
+	req.Header.Set("X-AIM-RPC-Token", "new-token")
+	if !s.authorizeRPC(httptest.NewRecorder(), req) {
+		t.Fatal("expected new token to be accepted after reload")
+	}
+
+	if err := os.Remove(tokenFile); err != nil {
+		t.Fatalf("remove token file: %v", err)
+	}
+	if err := s.ReloadSecrets(); err == nil {
+		t.Fatal("expected reload to fail for missing token file")
+	}
+	if !s.authorizeRPC(httptest.NewRecorder(), req) {
+		t.Fatal("expected failed reload to keep the previous token")
+	}
+}
+
 func TestApplyCORS_SetsSecurityHeaders(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/rpc", nil)
 	rr := httptest.NewRecorder()
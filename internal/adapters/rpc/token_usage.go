@@ -0,0 +1,231 @@
+package rpc
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rpcTokenQuotasEnv = "AIM_RPC_TOKEN_QUOTAS"
+
+	// rpcTokenQuotaWindow is the fixed window quotas are counted in.
+	rpcTokenQuotaWindow = time.Minute
+
+	rpcIntegrationNamespacePrefix = "integration:"
+	rpcDefaultQuotaName           = "*"
+)
+
+type rpcTokenQuota struct {
+	CallsPerMinute int   `json:"calls_per_minute,omitempty"`
+	BytesPerMinute int64 `json:"bytes_per_minute,omitempty"`
+}
+
+// rpcTokenUsage is the metering snapshot of one caller namespace.
+type rpcTokenUsage struct {
+	Caller      string         `json:"caller"`
+	Calls       uint64         `json:"calls"`
+	Errors      uint64         `json:"errors"`
+	ErrorRate   float64        `json:"error_rate"`
+	Throttled   uint64         `json:"throttled"`
+	BytesIn     int64          `json:"bytes_in"`
+	BytesOut    int64          `json:"bytes_out"`
+	WindowCalls int            `json:"window_calls"`
+	WindowBytes int64          `json:"window_bytes"`
+	Quota       *rpcTokenQuota `json:"quota,omitempty"`
+	LastSeen    time.Time      `json:"last_seen"`
+}
+
+type rpcTokenUsageEntry struct {
+	usage       rpcTokenUsage
+	windowStart time.Time
+}
+
+// rpcTokenUsageMeter counts RPC calls per caller namespace and enforces the
+// optional per-integration quotas. Only integration tokens can be throttled,
+// so a noisy bot never locks the primary client out. Counters live in memory
+// and restart with the daemon.
+type rpcTokenUsageMeter struct {
+	mu      sync.Mutex
+	quotas  map[string]rpcTokenQuota
+	entries map[string]*rpcTokenUsageEntry
+}
+
+// loadRPCTokenQuotas parses "name=calls[:bytes]" entries giving the calls and
+// request plus response bytes an integration may use per minute. The name "*"
+// applies to every integration without its own entry; zero means unlimited.
+func loadRPCTokenQuotas() map[string]rpcTokenQuota {
+	out := map[string]rpcTokenQuota{}
+	for _, entry := range strings.Split(os.Getenv(rpcTokenQuotasEnv), ",") {
+		name, limits, found := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			continue
+		}
+		rawCalls, rawBytes, _ := strings.Cut(limits, ":")
+		calls, err := strconv.Atoi(strings.TrimSpace(rawCalls))
+		if err != nil || calls < 0 {
+			slog.Default().Warn("rpc token quota skipped", "integration", name, "quota", limits)
+			continue
+		}
+		quota := rpcTokenQuota{CallsPerMinute: calls}
+		if strings.TrimSpace(rawBytes) != "" {
+			bytes, err := strconv.ParseInt(strings.TrimSpace(rawBytes), 10, 64)
+			if err != nil || bytes < 0 {
+				slog.Default().Warn("rpc token quota skipped", "integration", name, "quota", limits)
+				continue
+			}
+			quota.BytesPerMinute = bytes
+		}
+		out[name] = quota
+	}
+	return out
+}
+
+func newRPCTokenUsageMeter(quotas map[string]rpcTokenQuota) *rpcTokenUsageMeter {
+	return &rpcTokenUsageMeter{quotas: quotas, entries: map[string]*rpcTokenUsageEntry{}}
+}
+
+func (m *rpcTokenUsageMeter) quotaFor(caller string) *rpcTokenQuota {
+	name, ok := strings.CutPrefix(caller, rpcIntegrationNamespacePrefix)
+	if !ok {
+		return nil
+	}
+	quota, ok := m.quotas[name]
+	if !ok {
+		quota, ok = m.quotas[rpcDefaultQuotaName]
+	}
+	if !ok || (quota.CallsPerMinute == 0 && quota.BytesPerMinute == 0) {
+		return nil
+	}
+	return &quota
+}
+
+func (m *rpcTokenUsageMeter) entryLocked(caller string, now time.Time) *rpcTokenUsageEntry {
+	entry, ok := m.entries[caller]
+	if !ok {
+		entry = &rpcTokenUsageEntry{usage: rpcTokenUsage{Caller: caller}, windowStart: now}
+		m.entries[caller] = entry
+	}
+	if now.Sub(entry.windowStart) >= rpcTokenQuotaWindow {
+		entry.windowStart = now
+		entry.usage.WindowCalls = 0
+		entry.usage.WindowBytes = 0
+	}
+	return entry
+}
+
+// admit counts a call against caller's quota. When the quota is spent it
+// returns how long the caller should wait before retrying.
+func (m *rpcTokenUsageMeter) admit(caller string, now time.Time) (time.Duration, bool) {
+	if m == nil {
+		return 0, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.entryLocked(caller, now)
+	entry.usage.LastSeen = now.UTC()
+	if quota := m.quotaFor(caller); quota != nil {
+		if (quota.CallsPerMinute > 0 && entry.usage.WindowCalls >= quota.CallsPerMinute) ||
+			(quota.BytesPerMinute > 0 && entry.usage.WindowBytes >= quota.BytesPerMinute) {
+			entry.usage.Throttled++
+			return entry.windowStart.Add(rpcTokenQuotaWindow).Sub(now), false
+		}
+	}
+	entry.usage.Calls++
+	entry.usage.WindowCalls++
+	return 0, true
+}
+
+// record adds the traffic and outcome of an admitted call.
+func (m *rpcTokenUsageMeter) record(caller string, bytesIn, bytesOut int64, failed bool, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := m.entryLocked(caller, now)
+	entry.usage.BytesIn += bytesIn
+	entry.usage.BytesOut += bytesOut
+	entry.usage.WindowBytes += bytesIn + bytesOut
+	if failed {
+		entry.usage.Errors++
+	}
+}
+
+func (m *rpcTokenUsageMeter) snapshot(now time.Time) []rpcTokenUsage {
+	out := make([]rpcTokenUsage, 0)
+	if m == nil {
+		return out
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for caller, entry := range m.entries {
+		usage := entry.usage
+		if now.Sub(entry.windowStart) >= rpcTokenQuotaWindow {
+			usage.WindowCalls = 0
+			usage.WindowBytes = 0
+		}
+		if usage.Calls > 0 {
+			usage.ErrorRate = float64(usage.Errors) / float64(usage.Calls)
+		}
+		usage.Quota = m.quotaFor(caller)
+		out = append(out, usage)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Caller < out[j].Caller })
+	return out
+}
+
+func retryAfterSeconds(wait time.Duration) string {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+// meteredResponseWriter counts response bytes and remembers whether the call
+// failed, either at the HTTP level or with a JSON-RPC error.
+type meteredResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	bytes     int64
+	rpcFailed bool
+}
+
+func (w *meteredResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *meteredResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *meteredResponseWriter) failed() bool {
+	return w.rpcFailed || w.status >= http.StatusBadRequest
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	bytes int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.bytes += int64(n)
+	return n, err
+}
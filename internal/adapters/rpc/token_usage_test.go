@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTokenQuotaThrottlesOnlyTheNoisyIntegration(t *testing.T) {
+	t.Setenv(rpcIntegrationTokensEnv, "bot=bot-token,bridge=bridge-token")
+	t.Setenv(rpcTokenQuotasEnv, "bot=2")
+	s := newServerWithService(DefaultRPCAddr, &channelMockService{}, "primary-token", true)
+
+	for i := 0; i < 2; i++ {
+		if code, resp := postAnnotationRPC(t, s, "bot-token", "health_check", nil); code != http.StatusOK || resp.Error != nil {
+			t.Fatalf("call %d within quota failed: code=%d err=%+v", i, code, resp.Error)
+		}
+	}
+	if code, _ := postAnnotationRPC(t, s, "bot-token", "health_check", nil); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the quota is spent, got %d", code)
+	}
+	if code, resp := postAnnotationRPC(t, s, "bridge-token", "no.such.method", nil); code != http.StatusOK || resp.Error == nil {
+		t.Fatalf("other integrations must not be throttled: code=%d", code)
+	}
+	for i := 0; i < 3; i++ {
+		if code, _ := postAnnotationRPC(t, s, "primary-token", "health_check", nil); code != http.StatusOK {
+			t.Fatalf("primary token must never be throttled, got %d", code)
+		}
+	}
+
+	if _, resp := postAnnotationRPC(t, s, "bridge-token", methodAdminTokensUsage, nil); resp.Error == nil || resp.Error.Code != -32336 {
+		t.Fatalf("integration must not read token usage: %+v", resp.Error)
+	}
+	_, resp := postAnnotationRPC(t, s, "primary-token", methodAdminTokensUsage, nil)
+	if resp.Error != nil {
+		t.Fatalf("usage failed: %+v", resp.Error)
+	}
+	raw, _ := json.Marshal(resp.Result)
+	var report struct {
+		Tokens []rpcTokenUsage `json:"tokens"`
+	}
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("decode usage: %v", err)
+	}
+	byCaller := map[string]rpcTokenUsage{}
+	for _, usage := range report.Tokens {
+		byCaller[usage.Caller] = usage
+	}
+	bot := byCaller["integration:bot"]
+	if bot.Calls != 2 || bot.Throttled != 1 || bot.Errors != 0 || bot.Quota == nil || bot.Quota.CallsPerMinute != 2 {
+		t.Fatalf("unexpected bot usage: %+v", bot)
+	}
+	if bot.BytesIn == 0 || bot.BytesOut == 0 {
+		t.Fatalf("bytes must be metered: %+v", bot)
+	}
+	if bridge := byCaller["integration:bridge"]; bridge.Calls != 2 || bridge.ErrorRate != 1 || bridge.Quota != nil {
+		t.Fatalf("unexpected bridge usage: %+v", bridge)
+	}
+}
@@ -0,0 +1,125 @@
+package rpc
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout   = 10 * time.Second
+	wsPingInterval   = 20 * time.Second
+	wsPongTimeout    = 2 * wsPingInterval
+	wsMaxClientFrame = 4 << 10
+)
+
+func (s *Server) HandleNotificationsWebSocket(w http.ResponseWriter, r *http.Request) {
+	s.handleNotificationsWebSocket(w, r)
+}
+
+// handleNotificationsWebSocket streams the same notify.* events as
+// /rpc/stream over a WebSocket, one JSON-RPC notification per text frame.
+// Clients resume by reconnecting with the seq of the last event they saw
+// as ?cursor=. Browsers cannot set headers on the upgrade request, so the
+// RPC token may also be passed as ?rpc_token=.
+func (s *Server) handleNotificationsWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.applyCORS(w, r) {
+		return
+	}
+	if !s.authorizeRPC(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cursor := int64(0)
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		cursor = v
+	}
+	token := s.extractRPCToken(r)
+	release, allowed := s.streams.acquire(rpcRateLimitKey(r, token))
+	if !allowed {
+		http.Error(w, "too many stream subscriptions", http.StatusTooManyRequests)
+		return
+	}
+	defer release()
+	privacyLevel := s.notifyPrivacy.resolve(token, r.URL.Query().Get("privacy"))
+	locale := s.negotiateRPCLocale(r, r.URL.Query().Get("locale"))
+
+	upgrader := websocket.Upgrader{
+		// applyCORS has already rejected disallowed origins.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Clients only send control frames; reading them keeps pongs and the
+	// close handshake flowing and tells us when the peer goes away.
+	closed := make(chan struct{})
+	conn.SetReadLimit(wsMaxClientFrame)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	replay, ch, cancel := s.service.SubscribeNotifications(cursor)
+	defer cancel()
+
+	send := func(evt NotificationEvent) error {
+		data, err := encodeNotification(evt, privacyLevel, locale)
+		if err != nil {
+			return err
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, data)
+	}
+	for _, evt := range replay {
+		if err := send(evt); err != nil {
+			return
+		}
+	}
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-closed:
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "notifications closed"),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+			if err := send(evt); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
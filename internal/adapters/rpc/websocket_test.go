@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNotificationsWebSocketReplaysFromCursorAndStreams(t *testing.T) {
+	t.Setenv("AIM_ENV", "test")
+
+	live := make(chan contracts.NotificationEvent, 1)
+	cursors := make(chan int64, 1)
+	svc := &channelMockService{
+		subscribeNotificationsFn: func(cursor int64) ([]contracts.NotificationEvent, <-chan contracts.NotificationEvent, func()) {
+			cursors <- cursor
+			replay := []contracts.NotificationEvent{{Seq: cursor + 1, Method: "notify.message.status", Timestamp: time.Now().UTC()}}
+			return replay, live, func() {}
+		},
+	}
+	s := newServerWithService(DefaultRPCAddr, svc, "ws-token", true)
+	server := httptest.NewServer(http.HandlerFunc(s.HandleNotificationsWebSocket))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unauthenticated upgrade to be rejected, got resp=%v err=%v", resp, err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?cursor=41&rpc_token=ws-token", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	readSeq := func() (string, int64) {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg struct {
+			Method string `json:"method"`
+			Params struct {
+				Seq int64 `json:"seq"`
+			} `json:"params"`
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		return msg.Method, msg.Params.Seq
+	}
+	if method, seq := readSeq(); method != "notify.message.status" || seq != 42 {
+		t.Fatalf("expected replay after cursor 41, got %s seq=%d", method, seq)
+	}
+	if cursor := <-cursors; cursor != 41 {
+		t.Fatalf("expected the subscription to resume from 41, got %d", cursor)
+	}
+	live <- contracts.NotificationEvent{Seq: 43, Method: "notify.network.status", Timestamp: time.Now().UTC()}
+	if method, seq := readSeq(); method != "notify.network.status" || seq != 43 {
+		t.Fatalf("expected the live event, got %s seq=%d", method, seq)
+	}
+}
@@ -0,0 +1,324 @@
+package bootstrapmanager
+
+import (
+	"aim-chat/go-backend/internal/bootstrap/manifesttrust"
+	"aim-chat/go-backend/internal/bootstrap/networkmanifest"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FetchSkipped     = "skipped"
+	FetchNotModified = "not_modified"
+	FetchUpdated     = "updated"
+
+	// maxFetchBytes bounds a downloaded manifest or trust bundle.
+	maxFetchBytes = 1 << 20
+)
+
+var (
+	ErrFetchNotConfigured = errors.New("manifest fetching is not configured")
+	ErrFetchProxyInvalid  = errors.New("manifest fetch proxy is invalid")
+	// ErrTrustBundleNotPinned means there is no local trust bundle to check a
+	// downloaded update against.
+	ErrTrustBundleNotPinned = errors.New("no local trust bundle to pin the update to")
+)
+
+// FetchConfig points the fetcher at remote copies of the manifest and trust
+// bundle.
+type FetchConfig struct {
+	ManifestURL    string
+	TrustBundleURL string
+	// ProxyURL overrides the HTTP_PROXY/HTTPS_PROXY environment.
+	ProxyURL    string
+	Timeout     time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	JitterRatio float64
+}
+
+type FetchResult struct {
+	Manifest    string    `json:"manifest"`
+	TrustBundle string    `json:"trust_bundle"`
+	Attempts    int       `json:"attempts"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// Fetcher downloads the manifest and trust bundle and persists them only once
+// they verify. A trust bundle is accepted only as an update envelope signed
+// by a root key of the bundle already on disk, so the local bundle pins every
+// later one.
+type Fetcher struct {
+	manager *Manager
+	cfg     FetchConfig
+	client  *http.Client
+
+	mu    sync.Mutex
+	etags map[string]string
+	rnd   *rand.Rand
+	sleep func(context.Context, time.Duration) error
+}
+
+func NewFetcher(manager *Manager, cfg FetchConfig) (*Fetcher, error) {
+	cfg.ManifestURL = strings.TrimSpace(cfg.ManifestURL)
+	cfg.TrustBundleURL = strings.TrimSpace(cfg.TrustBundleURL)
+	if manager == nil || (cfg.ManifestURL == "" && cfg.TrustBundleURL == "") {
+		return nil, ErrFetchNotConfigured
+	}
+	for _, raw := range []string{cfg.ManifestURL, cfg.TrustBundleURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid fetch url %q", raw)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 500 * time.Millisecond
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = 10 * cfg.BackoffBase
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy := strings.TrimSpace(cfg.ProxyURL); proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return nil, fmt.Errorf("%w: %q", ErrFetchProxyInvalid, proxy)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	return &Fetcher{
+		manager: manager,
+		cfg:     cfg,
+		client:  &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		etags:   map[string]string{},
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		sleep:   sleepContext,
+	}, nil
+}
+
+// Fetch downloads and persists whatever changed upstream. The trust bundle is
+// handled first so a manifest signed by a freshly rotated key verifies.
+func (f *Fetcher) Fetch(ctx context.Context) (FetchResult, error) {
+	result := FetchResult{Manifest: FetchSkipped, TrustBundle: FetchSkipped}
+	if f.cfg.TrustBundleURL != "" {
+		status, attempts, err := f.fetchTrustBundle(ctx)
+		result.Attempts += attempts
+		if err != nil {
+			return result, fmt.Errorf("trust bundle fetch failed: %w", err)
+		}
+		result.TrustBundle = status
+	}
+	if f.cfg.ManifestURL != "" {
+		status, attempts, err := f.fetchManifest(ctx)
+		result.Attempts += attempts
+		if err != nil {
+			return result, fmt.Errorf("manifest fetch failed: %w", err)
+		}
+		result.Manifest = status
+	}
+	result.FetchedAt = f.manager.now()
+	return result, nil
+}
+
+func (f *Fetcher) fetchTrustBundle(ctx context.Context) (string, int, error) {
+	path := f.manager.trustBundlePath
+	currentRaw, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %v", ErrTrustBundleNotPinned, err)
+	}
+	current, err := manifesttrust.ParseBundle(currentRaw)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %v", ErrTrustBundleNotPinned, err)
+	}
+	body, etag, attempts, err := f.download(ctx, f.cfg.TrustBundleURL, path)
+	if err != nil || body == nil {
+		return FetchNotModified, attempts, err
+	}
+	var update manifesttrust.BundleUpdateEnvelope
+	if err := json.Unmarshal(body, &update); err != nil {
+		return "", attempts, fmt.Errorf("%w: %v", manifesttrust.ErrTrustBundleInvalid, err)
+	}
+	next, err := manifesttrust.VerifyAndApplyUpdate(current, update, f.manager.now())
+	if err != nil {
+		return "", attempts, err
+	}
+	if next.Version == current.Version && next.BundleID == current.BundleID {
+		f.rememberETag(f.cfg.TrustBundleURL, etag)
+		return FetchNotModified, attempts, nil
+	}
+	raw, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return "", attempts, err
+	}
+	if err := writeFileAtomic(path, raw); err != nil {
+		return "", attempts, err
+	}
+	f.rememberETag(f.cfg.TrustBundleURL, etag)
+	return FetchUpdated, attempts, nil
+}
+
+func (f *Fetcher) fetchManifest(ctx context.Context) (string, int, error) {
+	path := f.manager.manifestPath
+	body, etag, attempts, err := f.download(ctx, f.cfg.ManifestURL, path)
+	if err != nil || body == nil {
+		return FetchNotModified, attempts, err
+	}
+	trustRaw, err := os.ReadFile(f.manager.trustBundlePath)
+	if err != nil {
+		return "", attempts, fmt.Errorf("trust bundle load failed: %w", err)
+	}
+	trustBundle, err := manifesttrust.ParseBundle(trustRaw)
+	if err != nil {
+		return "", attempts, err
+	}
+	if _, err := networkmanifest.Verify(networkmanifest.VerifyRequest{
+		Raw:                body,
+		TrustBundle:        trustBundle,
+		Now:                f.manager.now(),
+		LastAppliedVersion: f.manager.readCachedManifestVersion(),
+	}); err != nil {
+		return "", attempts, err
+	}
+	if err := writeFileAtomic(path, body); err != nil {
+		return "", attempts, err
+	}
+	f.rememberETag(f.cfg.ManifestURL, etag)
+	return FetchUpdated, attempts, nil
+}
+
+// download fetches rawURL with retries. A nil body with a nil error means the
+// server answered 304 for the copy at localPath.
+func (f *Fetcher) download(ctx context.Context, rawURL, localPath string) ([]byte, string, int, error) {
+	ifNoneMatch := ""
+	if _, err := os.Stat(localPath); err == nil {
+		ifNoneMatch = f.etag(rawURL)
+	}
+	for attempt := 1; ; attempt++ {
+		body, etag, retry, err := f.downloadOnce(ctx, rawURL, ifNoneMatch)
+		if err == nil || !retry || attempt >= f.cfg.MaxAttempts {
+			return body, etag, attempt, err
+		}
+		if sleepErr := f.sleep(ctx, f.backoff(attempt)); sleepErr != nil {
+			return nil, "", attempt, sleepErr
+		}
+	}
+}
+
+func (f *Fetcher) downloadOnce(ctx context.Context, rawURL, ifNoneMatch string) ([]byte, string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", ctx.Err() == nil, fmt.Errorf("network error: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, "", false, nil
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, "", true, fmt.Errorf("temporary server error: %s", resp.Status)
+	default:
+		return nil, "", false, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return nil, "", true, fmt.Errorf("network error: %w", err)
+	}
+	if len(body) > maxFetchBytes {
+		return nil, "", false, errors.New("response exceeds size limit")
+	}
+	return body, resp.Header.Get("ETag"), false, nil
+}
+
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	value := float64(f.cfg.BackoffBase) * math.Pow(2, float64(attempt-1))
+	if value > float64(f.cfg.BackoffMax) {
+		value = float64(f.cfg.BackoffMax)
+	}
+	if f.cfg.JitterRatio <= 0 {
+		return time.Duration(value)
+	}
+	f.mu.Lock()
+	spread := (f.rnd.Float64()*2 - 1) * f.cfg.JitterRatio
+	f.mu.Unlock()
+	return time.Duration(math.Max(0, value*(1+spread)))
+}
+
+func (f *Fetcher) etag(rawURL string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.etags[rawURL]
+}
+
+func (f *Fetcher) rememberETag(rawURL, etag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if etag == "" {
+		delete(f.etags, rawURL)
+		return
+	}
+	f.etags[rawURL] = etag
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// writeFileAtomic replaces path so a reader never sees a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,212 @@
+package bootstrapmanager
+
+import (
+	"aim-chat/go-backend/internal/bootstrap/manifesttrust"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fetchFixture struct {
+	now          time.Time
+	root         kp
+	signer       kp
+	manifestPath string
+	trustPath    string
+	mgr          *Manager
+}
+
+func newFetchFixture(t *testing.T) fetchFixture {
+	t.Helper()
+	tmp := t.TempDir()
+	fx := fetchFixture{
+		now:          time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC),
+		root:         mustKP(t),
+		signer:       mustKP(t),
+		manifestPath: filepath.Join(tmp, "manifest.json"),
+		trustPath:    filepath.Join(tmp, "trust_bundle.json"),
+	}
+	writeTrustBundle(t, fx.trustPath, fx.now, fx.root, "manifest-2026-q1", fx.signer)
+	fx.mgr = New(fx.manifestPath, fx.trustPath, filepath.Join(tmp, "cache.json"), bakedSet())
+	fx.mgr.now = func() time.Time { return fx.now }
+	return fx
+}
+
+func (fx fetchFixture) signedManifest(t *testing.T, version int, signer kp) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "upstream.json")
+	writeManifest(t, path, fx.now, version, "manifest-2026-q1", signer, nil)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read upstream manifest: %v", err)
+	}
+	return raw
+}
+
+func newTestFetcher(t *testing.T, mgr *Manager, cfg FetchConfig) *Fetcher {
+	t.Helper()
+	f, err := NewFetcher(mgr, cfg)
+	if err != nil {
+		t.Fatalf("NewFetcher: %v", err)
+	}
+	f.sleep = func(context.Context, time.Duration) error { return nil }
+	return f
+}
+
+func TestFetcherPersistsVerifiedManifestAndUsesETag(t *testing.T) {
+	fx := newFetchFixture(t)
+	body := fx.signedManifest(t, 7, fx.signer)
+	var conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v7"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v7"`)
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, fx.mgr, FetchConfig{ManifestURL: srv.URL})
+	result, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	if result.Manifest != FetchUpdated || result.TrustBundle != FetchSkipped {
+		t.Fatalf("unexpected first result: %+v", result)
+	}
+	if stored, _ := os.ReadFile(fx.manifestPath); !bytes.Equal(stored, body) {
+		t.Fatal("verified manifest was not persisted")
+	}
+
+	result, err = f.Fetch(context.Background())
+	if err != nil || result.Manifest != FetchNotModified || conditional.Load() != 1 {
+		t.Fatalf("expected conditional not-modified fetch, got %+v err=%v", result, err)
+	}
+	if load := fx.mgr.LoadBootstrapSet(); !load.OK || load.Set.Source != SourceManifest {
+		t.Fatalf("persisted manifest did not load: %+v", load)
+	}
+}
+
+func TestFetcherRejectsUnsignedManifestWithoutPersisting(t *testing.T) {
+	fx := newFetchFixture(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(fx.signedManifest(t, 7, mustKP(t)))
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, fx.mgr, FetchConfig{ManifestURL: srv.URL})
+	if _, err := f.Fetch(context.Background()); err == nil {
+		t.Fatal("expected a manifest signed by an unknown key to be rejected")
+	}
+	if _, err := os.Stat(fx.manifestPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("rejected manifest must not be persisted, stat: %v", err)
+	}
+}
+
+func TestFetcherRetriesTemporaryFailures(t *testing.T) {
+	fx := newFetchFixture(t)
+	body := fx.signedManifest(t, 7, fx.signer)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(t, fx.mgr, FetchConfig{ManifestURL: srv.URL, MaxAttempts: 3})
+	result, err := f.Fetch(context.Background())
+	if err != nil || result.Attempts != 3 || result.Manifest != FetchUpdated {
+		t.Fatalf("expected success on the third attempt, got %+v err=%v", result, err)
+	}
+
+	calls.Store(0)
+	f = newTestFetcher(t, fx.mgr, FetchConfig{ManifestURL: srv.URL, MaxAttempts: 2})
+	if _, err := f.Fetch(context.Background()); err == nil {
+		t.Fatal("expected failure once attempts run out")
+	}
+}
+
+func TestFetcherRoutesThroughConfiguredProxy(t *testing.T) {
+	fx := newFetchFixture(t)
+	body := fx.signedManifest(t, 7, fx.signer)
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == "manifests.example.test" {
+			proxied.Add(1)
+			_, _ = w.Write(body)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	f := newTestFetcher(t, fx.mgr, FetchConfig{ManifestURL: "http://manifests.example.test/manifest.json", ProxyURL: proxy.URL})
+	if _, err := f.Fetch(context.Background()); err != nil || proxied.Load() != 1 {
+		t.Fatalf("expected the fetch to go through the proxy, proxied=%d err=%v", proxied.Load(), err)
+	}
+	if _, err := NewFetcher(fx.mgr, FetchConfig{ManifestURL: "https://x.test", ProxyURL: "ftp://proxy"}); !errors.Is(err, ErrFetchProxyInvalid) {
+		t.Fatalf("expected ErrFetchProxyInvalid, got %v", err)
+	}
+}
+
+func TestFetcherPinsTrustBundleUpdatesToLocalRoot(t *testing.T) {
+	fx := newFetchFixture(t)
+	current, err := os.ReadFile(fx.trustPath)
+	if err != nil {
+		t.Fatalf("read trust bundle: %v", err)
+	}
+	bundle, err := manifesttrust.ParseBundle(current)
+	if err != nil {
+		t.Fatalf("parse trust bundle: %v", err)
+	}
+	bundle.Version = 2
+	bundle.BundleID = "tb-2"
+	envelope := func(signer kp) []byte {
+		payload, _ := json.Marshal(bundle)
+		raw, _ := json.Marshal(manifesttrust.BundleUpdateEnvelope{
+			Bundle:          bundle,
+			SignedByKeyID:   "root-1",
+			SignatureBase64: base64.StdEncoding.EncodeToString(ed25519.Sign(signer.prv, payload)),
+		})
+		return raw
+	}
+	var served atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(served.Load().([]byte))
+	}))
+	defer srv.Close()
+	f := newTestFetcher(t, fx.mgr, FetchConfig{TrustBundleURL: srv.URL})
+
+	served.Store(envelope(mustKP(t)))
+	if _, err := f.Fetch(context.Background()); !errors.Is(err, manifesttrust.ErrTrustUpdateSignatureInvalid) {
+		t.Fatalf("expected forged update to be rejected, got %v", err)
+	}
+	if raw, _ := os.ReadFile(fx.trustPath); !bytes.Equal(raw, current) {
+		t.Fatal("rejected update must not replace the local trust bundle")
+	}
+
+	served.Store(envelope(fx.root))
+	result, err := f.Fetch(context.Background())
+	if err != nil || result.TrustBundle != FetchUpdated {
+		t.Fatalf("expected root-signed update to apply, got %+v err=%v", result, err)
+	}
+	raw, _ := os.ReadFile(fx.trustPath)
+	if updated, err := manifesttrust.ParseBundle(raw); err != nil || updated.BundleID != "tb-2" {
+		t.Fatalf("unexpected persisted bundle: %+v err=%v", updated, err)
+	}
+}
@@ -72,6 +72,8 @@ type Manager struct {
 	manifestMeta   *ManifestMeta
 	lastReason     string
 	lastRejectCode string
+	// lastRejectExpiry is the expiry of a manifest rejected as expired.
+	lastRejectExpiry time.Time
 }
 
 func New(manifestPath, trustBundlePath, cachePath string, baked BootstrapSet) *Manager {
@@ -91,10 +93,16 @@ func (m *Manager) LoadBootstrapSet() LoadResult {
 	manifestSet, manifestErr := m.loadManifest(now)
 	if manifestErr == nil {
 		m.lastRejectCode = ""
+		m.lastRejectExpiry = time.Time{}
 		return LoadResult{OK: true, Set: manifestSet}
 	}
 	m.lastReason = fmt.Sprintf("manifest rejected: %v", manifestErr)
 	m.lastRejectCode = mapManifestRejectCode(manifestErr)
+	m.lastRejectExpiry = time.Time{}
+	var verifyErr *networkmanifest.VerifyError
+	if errors.As(manifestErr, &verifyErr) && verifyErr.Code == networkmanifest.RejectExpired {
+		m.lastRejectExpiry = verifyErr.ExpiresAt
+	}
 
 	cacheSet, cacheErr := m.loadCache()
 	if cacheErr == nil {
@@ -123,7 +131,7 @@ func (m *Manager) ApplyBootstrapSet(cfg *waku.Config, set BootstrapSet) ApplyRes
 		return ApplyResult{Applied: false, ErrorCode: "BOOTSTRAP_SET_INVALID", Reason: err.Error()}
 	}
 
-	cfg.BootstrapNodes = append([]string(nil), set.BootstrapNodes...)
+	cfg.BootstrapNodes = rankedNodes(cfg.BootstrapRankingPath, set.BootstrapNodes)
 	cfg.MinPeers = set.MinPeers
 	cfg.ReconnectInterval = time.Duration(set.ReconnectPolicy.BaseIntervalMS) * time.Millisecond
 	cfg.ReconnectBackoffMax = time.Duration(set.ReconnectPolicy.MaxIntervalMS) * time.Millisecond
@@ -167,6 +175,12 @@ func (m *Manager) LastRejectCode() string {
 	return m.lastRejectCode
 }
 
+// LastRejectedExpiry returns the expiry of the manifest last rejected as
+// expired, or the zero time.
+func (m *Manager) LastRejectedExpiry() time.Time {
+	return m.lastRejectExpiry
+}
+
 func (m *Manager) loadManifest(now time.Time) (*BootstrapSet, error) {
 	if m.manifestPath == "" || m.trustBundlePath == "" {
 		return nil, errors.New("manifest or trust bundle path is not configured")
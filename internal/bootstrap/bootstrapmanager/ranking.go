@@ -0,0 +1,142 @@
+package bootstrapmanager
+
+import (
+	"aim-chat/go-backend/internal/waku"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NodeLatency is the last latency measurement of one bootstrap node.
+type NodeLatency struct {
+	Address          string    `json:"address"`
+	ConnectLatencyMS int64     `json:"connect_latency_ms"`
+	PublishLatencyMS int64     `json:"publish_latency_ms"`
+	Healthy          bool      `json:"healthy"`
+	LastError        string    `json:"last_error,omitempty"`
+	MeasuredAt       time.Time `json:"measured_at"`
+}
+
+// Ranking lists measured bootstrap nodes from most to least preferred:
+// healthy nodes fastest first, then nodes that failed their last probe.
+type Ranking struct {
+	UpdatedAt time.Time     `json:"updated_at"`
+	Nodes     []NodeLatency `json:"nodes"`
+}
+
+// LoadRanking reads the persisted ranking. A missing file is an empty
+// ranking.
+func LoadRanking(path string) (Ranking, error) {
+	if strings.TrimSpace(path) == "" {
+		return Ranking{}, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Ranking{}, nil
+	}
+	if err != nil {
+		return Ranking{}, err
+	}
+	var ranking Ranking
+	if err := json.Unmarshal(raw, &ranking); err != nil {
+		return Ranking{}, err
+	}
+	return ranking, nil
+}
+
+func SaveRanking(path string, ranking Ranking) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(ranking, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// RecordProbes merges fresh measurements into the ranking and re-sorts it.
+// Nodes that are no longer in nodes are dropped.
+func (r Ranking) RecordProbes(nodes []string, probes []waku.BootstrapProbe, now time.Time) Ranking {
+	byAddress := make(map[string]NodeLatency, len(r.Nodes))
+	for _, node := range r.Nodes {
+		byAddress[node.Address] = node
+	}
+	for _, probe := range probes {
+		entry := NodeLatency{
+			Address:          probe.Address,
+			ConnectLatencyMS: probe.ConnectLatency.Milliseconds(),
+			PublishLatencyMS: probe.PublishLatency.Milliseconds(),
+			Healthy:          probe.Healthy(),
+			MeasuredAt:       probe.MeasuredAt,
+		}
+		if probe.Err != nil {
+			entry.LastError = probe.Err.Error()
+		}
+		byAddress[probe.Address] = entry
+	}
+	out := Ranking{UpdatedAt: now}
+	for _, addr := range nodes {
+		if entry, ok := byAddress[strings.TrimSpace(addr)]; ok {
+			out.Nodes = append(out.Nodes, entry)
+			delete(byAddress, entry.Address)
+		}
+	}
+	sort.SliceStable(out.Nodes, func(i, j int) bool {
+		a, b := out.Nodes[i], out.Nodes[j]
+		if a.Healthy != b.Healthy {
+			return a.Healthy
+		}
+		return latencyScore(a) < latencyScore(b)
+	})
+	return out
+}
+
+// Order returns nodes with the healthy ranked ones first, fastest first,
+// then nodes never measured in their given order, then nodes that failed
+// their last probe.
+func (r Ranking) Order(nodes []string) []string {
+	rank := make(map[string]int, len(r.Nodes))
+	healthy := make(map[string]bool, len(r.Nodes))
+	for i, node := range r.Nodes {
+		rank[node.Address] = i
+		healthy[node.Address] = node.Healthy
+	}
+	tier := func(addr string) int {
+		if _, measured := rank[addr]; !measured {
+			return 1
+		}
+		if healthy[addr] {
+			return 0
+		}
+		return 2
+	}
+	out := append([]string(nil), nodes...)
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := tier(out[i]), tier(out[j])
+		if ti != tj {
+			return ti < tj
+		}
+		return ti != 1 && rank[out[i]] < rank[out[j]]
+	})
+	return out
+}
+
+func latencyScore(node NodeLatency) int64 {
+	return node.ConnectLatencyMS + node.PublishLatencyMS
+}
+
+func rankedNodes(rankingPath string, nodes []string) []string {
+	ranking, err := LoadRanking(rankingPath)
+	if err != nil {
+		return append([]string(nil), nodes...)
+	}
+	return ranking.Order(nodes)
+}
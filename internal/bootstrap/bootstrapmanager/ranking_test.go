@@ -0,0 +1,51 @@
+package bootstrapmanager
+
+import (
+	"aim-chat/go-backend/internal/waku"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRankingPrefersFastestHealthyNodesAfterRestart(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	nodes := []string{"/dns4/slow", "/dns4/down", "/dns4/fast", "/dns4/new"}
+	probes := []waku.BootstrapProbe{
+		{Address: "/dns4/slow", ConnectLatency: 300 * time.Millisecond, PublishLatency: 90 * time.Millisecond, MeasuredAt: now},
+		{Address: "/dns4/down", Err: errors.New("dial timeout"), MeasuredAt: now},
+		{Address: "/dns4/fast", ConnectLatency: 40 * time.Millisecond, PublishLatency: 15 * time.Millisecond, MeasuredAt: now},
+		{Address: "/dns4/gone", ConnectLatency: time.Millisecond, MeasuredAt: now},
+	}
+	ranking := Ranking{}.RecordProbes(nodes, probes, now)
+	if len(ranking.Nodes) != 3 || ranking.Nodes[0].Address != "/dns4/fast" || ranking.Nodes[2].LastError != "dial timeout" {
+		t.Fatalf("unexpected ranking: %+v", ranking.Nodes)
+	}
+
+	path := filepath.Join(t.TempDir(), "network", "bootstrap-ranking.json")
+	if err := SaveRanking(path, ranking); err != nil {
+		t.Fatalf("save ranking: %v", err)
+	}
+	cfg := waku.DefaultConfig()
+	cfg.BootstrapRankingPath = path
+	set := bakedSet()
+	set.BootstrapNodes = nodes
+	if result := New("", "", "", bakedSet()).ApplyBootstrapSet(&cfg, set); !result.Applied {
+		t.Fatalf("apply failed: %+v", result)
+	}
+	want := []string{"/dns4/fast", "/dns4/slow", "/dns4/new", "/dns4/down"}
+	if !reflect.DeepEqual(cfg.BootstrapNodes, want) {
+		t.Fatalf("expected dial order %v, got %v", want, cfg.BootstrapNodes)
+	}
+	if !reflect.DeepEqual(set.BootstrapNodes, nodes) {
+		t.Fatal("ranking must not reorder the set itself")
+	}
+}
+
+func TestLoadRankingMissingFileIsEmpty(t *testing.T) {
+	ranking, err := LoadRanking(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(ranking.Nodes) != 0 {
+		t.Fatalf("expected empty ranking, got %+v %v", ranking, err)
+	}
+}
@@ -4,15 +4,38 @@ import (
 	"aim-chat/go-backend/internal/bootstrap/manifestruntime"
 	"aim-chat/go-backend/internal/waku"
 	"context"
+	"errors"
+	"log/slog"
 	"strings"
 	"time"
 )
 
+var ErrRefresherNotRunning = errors.New("manifest refresher is not running")
+
 type Refresher struct {
 	manager    *Manager
 	cfg        *waku.Config
 	controller *manifestruntime.Controller
 	onApplied  func(waku.Config)
+	fetcher    *Fetcher
+	requests   chan refreshRequest
+}
+
+// RefreshResult reports a forced refresh: what was downloaded and which
+// bootstrap source is active afterwards.
+type RefreshResult struct {
+	FetchResult
+	Source          string `json:"source"`
+	ManifestVersion int    `json:"manifest_version,omitempty"`
+}
+
+type refreshRequest struct {
+	reply chan refreshReply
+}
+
+type refreshReply struct {
+	result RefreshResult
+	err    error
 }
 
 func NewRefresher(manager *Manager, cfg *waku.Config, onApplied func(waku.Config)) *Refresher {
@@ -29,11 +52,54 @@ func NewRefresher(manager *Manager, cfg *waku.Config, onApplied func(waku.Config
 		BackoffFactor:        cfg.ManifestBackoffFactor,
 		BackoffJitterRatio:   cfg.ManifestBackoffJitterRatio,
 	}
-	return &Refresher{
+	r := &Refresher{
 		manager:    manager,
 		cfg:        cfg,
 		controller: manifestruntime.NewController(policyCfg, nil),
 		onApplied:  onApplied,
+		requests:   make(chan refreshRequest),
+	}
+	if cfg.ManifestURL != "" || cfg.TrustBundleURL != "" {
+		fetcher, err := NewFetcher(manager, FetchConfig{
+			ManifestURL:    cfg.ManifestURL,
+			TrustBundleURL: cfg.TrustBundleURL,
+			ProxyURL:       cfg.ManifestProxyURL,
+			Timeout:        cfg.ManifestRefreshTimeout,
+			BackoffBase:    cfg.ManifestBackoffBase,
+			BackoffMax:     cfg.ManifestBackoffMax,
+			JitterRatio:    cfg.ManifestBackoffJitterRatio,
+		})
+		if err != nil {
+			slog.Warn("manifest.fetch.disabled",
+				"event_type", "manifest.fetch.disabled",
+				"reason", err.Error(),
+			)
+		}
+		r.fetcher = fetcher
+	}
+	return r
+}
+
+// RefreshNow makes the running refresher fetch and apply the manifest
+// immediately instead of waiting for its next scheduled attempt.
+func (r *Refresher) RefreshNow(ctx context.Context) (RefreshResult, error) {
+	if r == nil {
+		return RefreshResult{}, ErrRefresherNotRunning
+	}
+	if r.fetcher == nil {
+		return RefreshResult{}, ErrFetchNotConfigured
+	}
+	req := refreshRequest{reply: make(chan refreshReply, 1)}
+	select {
+	case r.requests <- req:
+	case <-ctx.Done():
+		return RefreshResult{}, ctx.Err()
+	}
+	select {
+	case reply := <-req.reply:
+		return reply.result, reply.err
+	case <-ctx.Done():
+		return RefreshResult{}, ctx.Err()
 	}
 }
 
@@ -43,26 +109,58 @@ func (r *Refresher) Run(ctx context.Context) {
 	}
 	delay := time.Duration(0)
 	for {
-		if delay > 0 {
-			timer := time.NewTimer(delay)
-			select {
-			case <-ctx.Done():
-				timer.Stop()
-				return
-			case <-timer.C:
-			}
-		} else {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case req := <-r.requests:
+			timer.Stop()
+			fetched, err := r.fetch(ctx)
+			delay = r.step(time.Now().UTC()).NextDelay
+			req.reply <- refreshReply{result: r.refreshResult(fetched), err: err}
+		case <-timer.C:
+			_, _ = r.fetch(ctx)
+			delay = r.step(time.Now().UTC()).NextDelay
 		}
-		decision := r.step(time.Now().UTC())
-		delay = decision.NextDelay
 	}
 }
 
+// fetch downloads the remote manifest and trust bundle when configured. A
+// failed fetch leaves the files on disk untouched, so step falls back as it
+// would for a bad local manifest.
+func (r *Refresher) fetch(ctx context.Context) (FetchResult, error) {
+	if r.fetcher == nil {
+		return FetchResult{Manifest: FetchSkipped, TrustBundle: FetchSkipped}, nil
+	}
+	result, err := r.fetcher.Fetch(ctx)
+	if err != nil {
+		slog.Warn("manifest.fetch.failed",
+			"event_type", "manifest.fetch",
+			"result", "failed",
+			"attempts", result.Attempts,
+			"reason", err.Error(),
+		)
+		return result, err
+	}
+	slog.Info("manifest.fetch.completed",
+		"event_type", "manifest.fetch",
+		"result", "ok",
+		"manifest", result.Manifest,
+		"trust_bundle", result.TrustBundle,
+		"attempts", result.Attempts,
+	)
+	return result, nil
+}
+
+func (r *Refresher) refreshResult(fetched FetchResult) RefreshResult {
+	out := RefreshResult{FetchResult: fetched, Source: r.manager.GetBootstrapSource()}
+	if meta := r.manager.GetManifestMeta(); meta != nil {
+		out.ManifestVersion = meta.Version
+	}
+	return out
+}
+
 func (r *Refresher) step(now time.Time) manifestruntime.Decision {
 	load := r.manager.LoadBootstrapSet()
 	outcome := manifestruntime.AttemptOutcome{
@@ -2,8 +2,12 @@ package bootstrapmanager
 
 import (
 	"aim-chat/go-backend/internal/waku"
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -60,3 +64,42 @@ func TestRefresherFallbackAndRestore(t *testing.T) {
 		t.Fatal("expected restored manifest decision")
 	}
 }
+
+func TestRefresherRefreshNowFetchesAndApplies(t *testing.T) {
+	fx := newFetchFixture(t)
+	body := fx.signedManifest(t, 9, fx.signer)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	cfg := waku.DefaultConfig()
+	cfg.ManifestRefreshInterval = time.Hour
+	ref := NewRefresher(fx.mgr, &cfg, nil)
+	if _, err := ref.RefreshNow(context.Background()); !errors.Is(err, ErrFetchNotConfigured) {
+		t.Fatalf("expected ErrFetchNotConfigured, got %v", err)
+	}
+
+	cfg.ManifestURL = srv.URL
+	ref = NewRefresher(fx.mgr, &cfg, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ref.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	reqCtx, reqCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer reqCancel()
+	result, err := ref.RefreshNow(reqCtx)
+	if err != nil {
+		t.Fatalf("RefreshNow: %v", err)
+	}
+	if result.Source != SourceManifest || result.ManifestVersion != 9 || cfg.BootstrapSource != SourceManifest {
+		t.Fatalf("unexpected refresh result: %+v source=%s", result, cfg.BootstrapSource)
+	}
+}
@@ -0,0 +1,118 @@
+package enrollmenttoken
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Capability claims an enrollment token may carry. relay_only excludes the
+// other two.
+const (
+	CapabilityRelayOnly    = "relay_only"
+	CapabilityStoreEnabled = "store_enabled"
+	CapabilityBlobProvider = "blob_provider"
+)
+
+// GrantFileName is where an enrolled node keeps what its token grants,
+// inside the data dir shared by the node agent and the daemon.
+const GrantFileName = "enrollment-grant.json"
+
+var ErrSegmentNotGranted = errors.New("network segment is not claimed by the enrollment token")
+
+// Grant is what an enrollment lets a node run. A token without capability
+// claims predates capability grants and leaves every subsystem allowed; one without
+// segments allows any network segment.
+type Grant struct {
+	TokenID         string   `json:"token_id,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+	NetworkSegments []string `json:"network_segments,omitempty"`
+}
+
+// Grant is the part of the claims an enrolled node keeps.
+func (c Claims) Grant() Grant {
+	return Grant{
+		TokenID:         c.TokenID,
+		Capabilities:    append([]string(nil), c.Capabilities...),
+		NetworkSegments: append([]string(nil), c.NetworkSegments...),
+	}
+}
+
+// Restricted reports whether the grant limits subsystems at all.
+func (g Grant) Restricted() bool {
+	return len(g.Capabilities) > 0
+}
+
+// Allows reports whether the subsystem behind capability may be enabled.
+func (g Grant) Allows(capability string) bool {
+	return !g.Restricted() || slices.Contains(g.Capabilities, capability)
+}
+
+// AllowsSegment reports whether a node configured for segment may run under
+// this grant. An empty segment is only allowed by grants without segments.
+func (g Grant) AllowsSegment(segment string) bool {
+	return len(g.NetworkSegments) == 0 || slices.Contains(g.NetworkSegments, strings.TrimSpace(segment))
+}
+
+// ActiveCapabilities lists the capabilities in effect, spelling out what an
+// unrestricted grant allows.
+func (g Grant) ActiveCapabilities() []string {
+	if !g.Restricted() {
+		return []string{CapabilityStoreEnabled, CapabilityBlobProvider}
+	}
+	return append([]string(nil), g.Capabilities...)
+}
+
+// LoadGrant reads the grant saved in dataDir. ok is false when the node has
+// never been enrolled with a token.
+func LoadGrant(dataDir string) (grant Grant, ok bool, err error) {
+	raw, err := os.ReadFile(filepath.Join(dataDir, GrantFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Grant{}, false, nil
+	}
+	if err != nil {
+		return Grant{}, false, err
+	}
+	if err := json.Unmarshal(raw, &grant); err != nil {
+		return Grant{}, false, err
+	}
+	return grant, true, nil
+}
+
+func SaveGrant(dataDir string, grant Grant) error {
+	raw, err := json.MarshalIndent(grant, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dataDir, GrantFileName), raw, 0o600)
+}
+
+func validateGrantClaims(claims Claims) error {
+	seen := map[string]bool{}
+	for _, capability := range claims.Capabilities {
+		switch capability {
+		case CapabilityRelayOnly, CapabilityStoreEnabled, CapabilityBlobProvider:
+		default:
+			return ErrTokenClaimsInvalid
+		}
+		if seen[capability] {
+			return ErrTokenClaimsInvalid
+		}
+		seen[capability] = true
+	}
+	if seen[CapabilityRelayOnly] && len(seen) > 1 {
+		return ErrTokenClaimsInvalid
+	}
+	for _, segment := range claims.NetworkSegments {
+		if strings.TrimSpace(segment) == "" || segment != strings.TrimSpace(segment) {
+			return ErrTokenClaimsInvalid
+		}
+	}
+	return nil
+}
@@ -31,6 +31,10 @@ type Claims struct {
 	SubjectNodeGroup string    `json:"subject_node_group"`
 	Issuer           string    `json:"issuer"`
 	KeyID            string    `json:"key_id"`
+	// Capabilities and NetworkSegments limit what the enrolled node may
+	// run; see Grant.
+	Capabilities    []string `json:"capabilities,omitempty"`
+	NetworkSegments []string `json:"network_segments,omitempty"`
 }
 
 type AuditEvent struct {
@@ -38,6 +42,7 @@ type AuditEvent struct {
 	TokenID   string    `json:"token_id,omitempty"`
 	Issuer    string    `json:"issuer,omitempty"`
 	KeyID     string    `json:"key_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
 	Result    string    `json:"result"`
 	Reason    string    `json:"reason,omitempty"`
 	At        time.Time `json:"at"`
@@ -96,6 +101,7 @@ func (v Verifier) VerifyAndRedeem(token string, store RedemptionStore) (Claims,
 		TokenID:   claims.TokenID,
 		Issuer:    claims.Issuer,
 		KeyID:     claims.KeyID,
+		ExpiresAt: claims.ExpiresAt,
 		Result:    "accepted",
 		At:        now,
 	}, nil
@@ -107,6 +113,7 @@ func rejectedAudit(at time.Time, claims Claims, reason string) AuditEvent {
 		TokenID:   claims.TokenID,
 		Issuer:    claims.Issuer,
 		KeyID:     claims.KeyID,
+		ExpiresAt: claims.ExpiresAt,
 		Result:    "rejected",
 		Reason:    reason,
 		At:        at,
@@ -136,7 +143,7 @@ func validateClaims(claims Claims, requiredIssuer, requiredScope string) error {
 	if !claims.ExpiresAt.After(claims.IssuedAt) {
 		return ErrTokenClaimsInvalid
 	}
-	return nil
+	return validateGrantClaims(claims)
 }
 
 func decodeToken(token string) (Claims, []byte, []byte, error) {
@@ -180,3 +180,53 @@ func TestEncodeSignedTokenFormat(t *testing.T) {
 		t.Fatalf("signature is not base64url: %v", err)
 	}
 }
+
+func TestVerifierValidatesCapabilityClaims(t *testing.T) {
+	now := time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)
+	pub, prv := mustKeys(t)
+	verifier := Verifier{
+		RequiredIssuer: RequiredIssuer,
+		PublicKeys:     map[string]ed25519.PublicKey{"issuer-k1": pub},
+		Now:            func() time.Time { return now },
+	}
+	invalid := map[string]Claims{}
+	for name, mutate := range map[string]func(*Claims){
+		"unknown capability": func(c *Claims) { c.Capabilities = []string{"admin"} },
+		"relay only mixed":   func(c *Claims) { c.Capabilities = []string{CapabilityRelayOnly, CapabilityStoreEnabled} },
+		"duplicate":          func(c *Claims) { c.Capabilities = []string{CapabilityBlobProvider, CapabilityBlobProvider} },
+		"blank segment":      func(c *Claims) { c.NetworkSegments = []string{" "} },
+	} {
+		claims := baseClaims(now)
+		claims.TokenID = "tok-" + strings.ReplaceAll(name, " ", "-")
+		mutate(&claims)
+		invalid[name] = claims
+	}
+	for name, claims := range invalid {
+		token, err := EncodeSignedToken(claims, prv)
+		if err != nil {
+			t.Fatalf("encode token: %v", err)
+		}
+		if _, _, err := verifier.VerifyAndRedeem(token, NewInMemoryStore()); !errors.Is(err, ErrTokenClaimsInvalid) {
+			t.Fatalf("%s: expected ErrTokenClaimsInvalid, got %v", name, err)
+		}
+	}
+
+	claims := baseClaims(now)
+	claims.Capabilities = []string{CapabilityRelayOnly}
+	claims.NetworkSegments = []string{"eu-1"}
+	token, err := EncodeSignedToken(claims, prv)
+	if err != nil {
+		t.Fatalf("encode token: %v", err)
+	}
+	redeemed, _, err := verifier.VerifyAndRedeem(token, NewInMemoryStore())
+	if err != nil {
+		t.Fatalf("redeem relay-only token: %v", err)
+	}
+	grant := redeemed.Grant()
+	if grant.Allows(CapabilityStoreEnabled) || grant.Allows(CapabilityBlobProvider) || !grant.AllowsSegment("eu-1") || grant.AllowsSegment("") {
+		t.Fatalf("unexpected grant: %+v", grant)
+	}
+	if unrestricted := (Grant{}); !unrestricted.Allows(CapabilityStoreEnabled) || !unrestricted.AllowsSegment("") {
+		t.Fatal("expected a grant without claims to allow everything")
+	}
+}
@@ -45,6 +45,9 @@ type Bundle struct {
 	GeneratedAt  time.Time     `json:"generated_at"`
 	RootKeys     []RootKey     `json:"root_keys"`
 	ManifestKeys []ManifestKey `json:"manifest_keys"`
+	// AuthorityKeys are community authorities whose signatures on contact
+	// cards earn a verified badge.
+	AuthorityKeys []RootKey `json:"authority_keys,omitempty"`
 }
 
 func ParseBundle(data []byte) (Bundle, error) {
@@ -87,6 +90,11 @@ func (b Bundle) Validate() error {
 			return fmt.Errorf("%w: invalid manifest key validity window", ErrTrustBundleInvalid)
 		}
 	}
+	for _, key := range b.AuthorityKeys {
+		if err := validatePublicKeyFields(key.KeyID, key.Algorithm, key.PublicKeyBase64); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -125,6 +133,19 @@ func (b Bundle) findManifestKey(keyID string) (ManifestKey, bool) {
 	return ManifestKey{}, false
 }
 
+// AuthorityPublicKeys returns the authority keys by key id.
+func (b Bundle) AuthorityPublicKeys() map[string]ed25519.PublicKey {
+	out := make(map[string]ed25519.PublicKey, len(b.AuthorityKeys))
+	for _, k := range b.AuthorityKeys {
+		pub, err := decodeEd25519PublicKey(k.PublicKeyBase64)
+		if err != nil {
+			continue
+		}
+		out[k.KeyID] = pub
+	}
+	return out
+}
+
 func (b Bundle) ActiveManifestKeys(at time.Time) []ManifestKey {
 	out := make([]ManifestKey, 0, len(b.ManifestKeys))
 	for _, k := range b.ManifestKeys {
@@ -156,3 +156,23 @@ func TestVerifyAndApplyUpdateRejectsNoOverlapRotation(t *testing.T) {
 		t.Fatalf("expected ErrTrustUpdateChainInvalid for no-overlap rotation, got %v", err)
 	}
 }
+
+func TestBundleAuthorityKeys(t *testing.T) {
+	now := time.Now().UTC()
+	root := mustKeyPair(t)
+	authority := mustKeyPair(t)
+	bundle := makeBundle(now, root, map[string]keyPair{"mk-1": mustKeyPair(t)}, 1, "bundle-1")
+	bundle.AuthorityKeys = []RootKey{{KeyID: "community-1", Algorithm: "ed25519", PublicKeyBase64: b64(authority.pub)}}
+	if err := bundle.Validate(); err != nil {
+		t.Fatalf("bundle with authority keys must validate: %v", err)
+	}
+	keys := bundle.AuthorityPublicKeys()
+	if len(keys) != 1 || !keys["community-1"].Equal(authority.pub) {
+		t.Fatalf("unexpected authority keys: %v", keys)
+	}
+
+	bundle.AuthorityKeys[0].PublicKeyBase64 = b64([]byte("short"))
+	if err := bundle.Validate(); !errors.Is(err, ErrTrustBundleInvalid) {
+		t.Fatalf("malformed authority key must be rejected, got %v", err)
+	}
+}
@@ -46,6 +46,8 @@ type Manifest struct {
 type VerifyError struct {
 	Code RejectCode
 	Err  error
+	// ExpiresAt is set for RejectExpired.
+	ExpiresAt time.Time
 }
 
 func (e *VerifyError) Error() string {
@@ -109,7 +111,7 @@ func Verify(req VerifyRequest) (Manifest, error) {
 		return Manifest{}, err
 	}
 	if !manifest.ExpiresAt.After(req.Now) {
-		return Manifest{}, &VerifyError{Code: RejectExpired, Err: errors.New("manifest expired")}
+		return Manifest{}, &VerifyError{Code: RejectExpired, Err: errors.New("manifest expired"), ExpiresAt: manifest.ExpiresAt}
 	}
 	if req.LastAppliedVersion > 0 && manifest.Version < req.LastAppliedVersion {
 		return Manifest{}, &VerifyError{Code: RejectReplay, Err: errors.New("manifest version is older than last applied")}
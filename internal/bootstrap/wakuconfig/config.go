@@ -9,36 +9,63 @@ import (
 	"strings"
 	"time"
 
+	"aim-chat/go-backend/internal/platform/logsink"
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
 
 	"gopkg.in/yaml.v3"
 )
 
 type DaemonConfig struct {
 	Network DaemonNetworkConfig `yaml:"network"`
+	Logging logsink.Spec        `yaml:"logging"`
 }
 
 type DaemonNetworkConfig struct {
-	Transport                  string        `yaml:"transport"`
-	Port                       int           `yaml:"port"`
-	AdvertiseAddress           string        `yaml:"advertiseAddress"`
-	EnableRelay                *bool         `yaml:"enableRelay"`
-	EnableStore                *bool         `yaml:"enableStore"`
-	EnableFilter               *bool         `yaml:"enableFilter"`
-	EnableLightPush            *bool         `yaml:"enableLightPush"`
-	BootstrapNodes             []string      `yaml:"bootstrapNodes"`
-	FailoverV1                 *bool         `yaml:"failoverV1"`
-	MinPeers                   int           `yaml:"minPeers"`
-	StoreQueryFanout           int           `yaml:"storeQueryFanout"`
-	ReconnectInterval          time.Duration `yaml:"reconnectInterval"`
-	ReconnectBackoffMax        time.Duration `yaml:"reconnectBackoffMax"`
-	ManifestRefreshInterval    time.Duration `yaml:"manifestRefreshInterval"`
-	ManifestStaleWindow        time.Duration `yaml:"manifestStaleWindow"`
-	ManifestRefreshTimeout     time.Duration `yaml:"manifestRefreshTimeout"`
-	ManifestBackoffBase        time.Duration `yaml:"manifestBackoffBase"`
-	ManifestBackoffMax         time.Duration `yaml:"manifestBackoffMax"`
-	ManifestBackoffFactor      float64       `yaml:"manifestBackoffFactor"`
-	ManifestBackoffJitterRatio float64       `yaml:"manifestBackoffJitterRatio"`
+	Transport                  string            `yaml:"transport"`
+	Port                       int               `yaml:"port"`
+	AdvertiseAddress           string            `yaml:"advertiseAddress"`
+	NetworkSegment             string            `yaml:"networkSegment"`
+	EnableRelay                *bool             `yaml:"enableRelay"`
+	EnableStore                *bool             `yaml:"enableStore"`
+	EnableFilter               *bool             `yaml:"enableFilter"`
+	EnableLightPush            *bool             `yaml:"enableLightPush"`
+	BootstrapNodes             []string          `yaml:"bootstrapNodes"`
+	FailoverV1                 *bool             `yaml:"failoverV1"`
+	MinPeers                   int               `yaml:"minPeers"`
+	StoreQueryFanout           int               `yaml:"storeQueryFanout"`
+	TopicShards                int               `yaml:"topicShards"`
+	TopicMigrationUntil        time.Time         `yaml:"topicMigrationUntil"`
+	PairTopics                 *bool             `yaml:"pairTopics"`
+	PairTopicOverlap           time.Duration     `yaml:"pairTopicOverlap"`
+	StoreNodeEnabled           *bool             `yaml:"storeNodeEnabled"`
+	StoreNodeTopics            []string          `yaml:"storeNodeTopics"`
+	StoreNodeQuotaBytes        int64             `yaml:"storeNodeQuotaBytes"`
+	StoreNodeRetention         time.Duration     `yaml:"storeNodeRetention"`
+	StoreNodeQueriesPerMinute  int               `yaml:"storeNodeQueriesPerMinute"`
+	ReconnectInterval          time.Duration     `yaml:"reconnectInterval"`
+	ReconnectBackoffMax        time.Duration     `yaml:"reconnectBackoffMax"`
+	ManifestRefreshInterval    time.Duration     `yaml:"manifestRefreshInterval"`
+	ManifestStaleWindow        time.Duration     `yaml:"manifestStaleWindow"`
+	ManifestRefreshTimeout     time.Duration     `yaml:"manifestRefreshTimeout"`
+	ManifestBackoffBase        time.Duration     `yaml:"manifestBackoffBase"`
+	ManifestBackoffMax         time.Duration     `yaml:"manifestBackoffMax"`
+	ManifestBackoffFactor      float64           `yaml:"manifestBackoffFactor"`
+	ManifestBackoffJitterRatio float64           `yaml:"manifestBackoffJitterRatio"`
+	ManifestURL                string            `yaml:"manifestURL"`
+	TrustBundleURL             string            `yaml:"trustBundleURL"`
+	ManifestProxyURL           string            `yaml:"manifestProxy"`
+	RetryPolicy                RetryPolicyConfig `yaml:"retryPolicy"`
+}
+
+// RetryPolicyConfig is the retry policy of outbound messages the daemon
+// starts with; network.retry_policy.set changes it until the next restart.
+type RetryPolicyConfig struct {
+	BaseInterval time.Duration `yaml:"baseInterval"`
+	MaxInterval  time.Duration `yaml:"maxInterval"`
+	JitterRatio  float64       `yaml:"jitterRatio"`
+	MaxAttempts  int           `yaml:"maxAttempts"`
+	DeadLetter   *bool         `yaml:"deadLetter"`
 }
 
 //func LoadFromPath(configPath string) waku.Config {
@@ -48,6 +75,44 @@ type DaemonNetworkConfig struct {
 func LoadFromPathWithDataDir(configPath, dataDir string) waku.Config {
 	cfg := waku.DefaultConfig()
 
+	if parsed, ok := readDaemonConfig(configPath); ok {
+		merged := cfg
+		Merge(&merged, parsed.Network)
+		ApplyEnvOverrides(&merged)
+		applyBootstrapManager(&merged, dataDir)
+		merged.StoreNodePath = resolveStoreNodePath(dataDir)
+		return merged
+	}
+
+	ApplyEnvOverrides(&cfg)
+	applyBootstrapManager(&cfg, dataDir)
+	cfg.StoreNodePath = resolveStoreNodePath(dataDir)
+	return cfg
+}
+
+// LoadLoggingFromPath returns the logging section of the daemon config. The
+// AIM_LOG_* environment overrides are applied by logsink, not here.
+func LoadLoggingFromPath(configPath string) logsink.Spec {
+	parsed, _ := readDaemonConfig(configPath)
+	return parsed.Logging
+}
+
+// LoadRetryPolicyFromPath returns base with the retry policy fields set in
+// the daemon config applied. The result is validated by the service.
+func LoadRetryPolicyFromPath(configPath string, base models.RetryPolicy) models.RetryPolicy {
+	parsed, _ := readDaemonConfig(configPath)
+	src := parsed.Network.RetryPolicy
+	mergeIfSet(&base.BaseIntervalMS, src.BaseInterval.Milliseconds())
+	mergeIfSet(&base.MaxIntervalMS, src.MaxInterval.Milliseconds())
+	mergeIfSet(&base.JitterRatio, src.JitterRatio)
+	mergeIfSet(&base.MaxAttempts, src.MaxAttempts)
+	if src.DeadLetter != nil {
+		base.DeadLetter = *src.DeadLetter
+	}
+	return base
+}
+
+func readDaemonConfig(configPath string) (DaemonConfig, bool) {
 	candidates := make([]string, 0, 2)
 	if configPath != "" {
 		candidates = append(candidates, configPath)
@@ -68,17 +133,9 @@ func LoadFromPathWithDataDir(configPath, dataDir string) waku.Config {
 		if err := yaml.Unmarshal(data, &parsed); err != nil {
 			continue
 		}
-
-		merged := cfg
-		Merge(&merged, parsed.Network)
-		ApplyEnvOverrides(&merged)
-		applyBootstrapManager(&merged, dataDir)
-		return merged
+		return parsed, true
 	}
-
-	ApplyEnvOverrides(&cfg)
-	applyBootstrapManager(&cfg, dataDir)
-	return cfg
+	return DaemonConfig{}, false
 }
 
 func Merge(dst *waku.Config, src DaemonNetworkConfig) {
@@ -89,6 +146,9 @@ func Merge(dst *waku.Config, src DaemonNetworkConfig) {
 	if src.AdvertiseAddress != "" {
 		dst.AdvertiseAddress = src.AdvertiseAddress
 	}
+	if segment := strings.TrimSpace(src.NetworkSegment); segment != "" {
+		dst.NetworkSegment = segment
+	}
 	if src.EnableRelay != nil {
 		dst.EnableRelay = *src.EnableRelay
 	}
@@ -109,6 +169,21 @@ func Merge(dst *waku.Config, src DaemonNetworkConfig) {
 	}
 	mergeIfSet(&dst.MinPeers, src.MinPeers)
 	mergeIfSet(&dst.StoreQueryFanout, src.StoreQueryFanout)
+	mergeIfSet(&dst.TopicShards, src.TopicShards)
+	mergeIfSet(&dst.TopicMigrationUntil, src.TopicMigrationUntil)
+	if src.PairTopics != nil {
+		dst.PairTopics = *src.PairTopics
+	}
+	mergeIfSet(&dst.PairTopicOverlap, src.PairTopicOverlap)
+	if src.StoreNodeEnabled != nil {
+		dst.StoreNodeEnabled = *src.StoreNodeEnabled
+	}
+	if src.StoreNodeTopics != nil {
+		dst.StoreNodeTopics = src.StoreNodeTopics
+	}
+	mergeIfSet(&dst.StoreNodeQuotaBytes, src.StoreNodeQuotaBytes)
+	mergeIfSet(&dst.StoreNodeRetention, src.StoreNodeRetention)
+	mergeIfSet(&dst.StoreNodeQueriesPerMinute, src.StoreNodeQueriesPerMinute)
 	mergeIfSet(&dst.ReconnectInterval, src.ReconnectInterval)
 	mergeIfSet(&dst.ReconnectBackoffMax, src.ReconnectBackoffMax)
 	mergeIfSet(&dst.ManifestRefreshInterval, src.ManifestRefreshInterval)
@@ -118,6 +193,9 @@ func Merge(dst *waku.Config, src DaemonNetworkConfig) {
 	mergeIfSet(&dst.ManifestBackoffMax, src.ManifestBackoffMax)
 	mergeIfSet(&dst.ManifestBackoffFactor, src.ManifestBackoffFactor)
 	mergeIfSet(&dst.ManifestBackoffJitterRatio, src.ManifestBackoffJitterRatio)
+	mergeIfSet(&dst.ManifestURL, src.ManifestURL)
+	mergeIfSet(&dst.TrustBundleURL, src.TrustBundleURL)
+	mergeIfSet(&dst.ManifestProxyURL, src.ManifestProxyURL)
 }
 
 func mergeIfSet[T comparable](dst *T, src T) {
@@ -131,6 +209,9 @@ func ApplyEnvOverrides(cfg *waku.Config) {
 	if transport := strings.TrimSpace(os.Getenv("AIM_NETWORK_TRANSPORT")); transport != "" {
 		cfg.Transport = transport
 	}
+	if segment := strings.TrimSpace(os.Getenv("AIM_NETWORK_SEGMENT")); segment != "" {
+		cfg.NetworkSegment = segment
+	}
 
 	raw := strings.TrimSpace(os.Getenv("AIM_NETWORK_FAILOVER_V1"))
 	if raw != "" {
@@ -139,6 +220,47 @@ func ApplyEnvOverrides(cfg *waku.Config) {
 		}
 	}
 
+	if shards := strings.TrimSpace(os.Getenv("AIM_NETWORK_TOPIC_SHARDS")); shards != "" {
+		if v, err := strconv.Atoi(shards); err == nil {
+			cfg.TopicShards = v
+		}
+	}
+	if until := strings.TrimSpace(os.Getenv("AIM_NETWORK_TOPIC_MIGRATION_UNTIL")); until != "" {
+		if t, err := time.Parse(time.RFC3339, until); err == nil {
+			cfg.TopicMigrationUntil = t
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("AIM_NETWORK_PAIR_TOPICS")); raw != "" {
+		if v, err := strconv.ParseBool(raw); err == nil {
+			cfg.PairTopics = v
+		}
+	}
+	if overlap := strings.TrimSpace(os.Getenv("AIM_NETWORK_PAIR_TOPIC_OVERLAP")); overlap != "" {
+		if d, err := time.ParseDuration(overlap); err == nil {
+			cfg.PairTopicOverlap = d
+		}
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("AIM_STORE_NODE_ENABLED")); raw != "" {
+		if v, err := strconv.ParseBool(raw); err == nil {
+			cfg.StoreNodeEnabled = v
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("AIM_STORE_NODE_TOPICS")); raw != "" {
+		topics := make([]string, 0)
+		for _, topic := range strings.Split(raw, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+		cfg.StoreNodeTopics = topics
+	}
+	if raw := strings.TrimSpace(os.Getenv("AIM_STORE_NODE_QUOTA_BYTES")); raw != "" {
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			cfg.StoreNodeQuotaBytes = v
+		}
+	}
+
 	if refreshInterval := strings.TrimSpace(os.Getenv("AIM_MANIFEST_REFRESH_INTERVAL")); refreshInterval != "" {
 		if d, err := time.ParseDuration(refreshInterval); err == nil {
 			cfg.ManifestRefreshInterval = d
@@ -174,6 +296,15 @@ func ApplyEnvOverrides(cfg *waku.Config) {
 			cfg.ManifestBackoffJitterRatio = v
 		}
 	}
+	if manifestURL := strings.TrimSpace(os.Getenv("AIM_NETWORK_MANIFEST_URL")); manifestURL != "" {
+		cfg.ManifestURL = manifestURL
+	}
+	if trustBundleURL := strings.TrimSpace(os.Getenv("AIM_TRUST_BUNDLE_URL")); trustBundleURL != "" {
+		cfg.TrustBundleURL = trustBundleURL
+	}
+	if proxy := strings.TrimSpace(os.Getenv("AIM_MANIFEST_PROXY")); proxy != "" {
+		cfg.ManifestProxyURL = proxy
+	}
 }
 
 func applyBootstrapManager(cfg *waku.Config, dataDir string) {
@@ -199,6 +330,7 @@ func applyBootstrapManager(cfg *waku.Config, dataDir string) {
 	cfg.BootstrapManifestPath = manifestPath
 	cfg.BootstrapTrustBundlePath = trustBundlePath
 	cfg.BootstrapCachePath = cachePath
+	cfg.BootstrapRankingPath = resolveBootstrapRankingPath(dataDir)
 	load := manager.LoadBootstrapSet()
 	if !load.OK || load.Set == nil {
 		cfg.BootstrapSource = "unavailable"
@@ -231,6 +363,16 @@ func applyBootstrapManager(cfg *waku.Config, dataDir string) {
 	}
 }
 
+// resolveStoreNodePath places the community store database next to the other
+// network state of the daemon.
+func resolveStoreNodePath(dataDir string) string {
+	baseDir := strings.TrimSpace(dataDir)
+	if baseDir == "" {
+		baseDir = "."
+	}
+	return filepath.Join(baseDir, "network", "store")
+}
+
 func resolveBootstrapCachePath(dataDir string) string {
 	baseDir := strings.TrimSpace(dataDir)
 	if baseDir == "" {
@@ -238,3 +380,13 @@ func resolveBootstrapCachePath(dataDir string) string {
 	}
 	return filepath.Join(baseDir, "network", "bootstrap-cache.json")
 }
+
+// resolveBootstrapRankingPath places the measured bootstrap node ranking
+// next to the bootstrap cache.
+func resolveBootstrapRankingPath(dataDir string) string {
+	baseDir := strings.TrimSpace(dataDir)
+	if baseDir == "" {
+		baseDir = "."
+	}
+	return filepath.Join(baseDir, "network", "bootstrap-ranking.json")
+}
@@ -1,10 +1,15 @@
 package wakuconfig
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+
+	"gopkg.in/yaml.v3"
 )
 
 func boolPtr(v bool) *bool {
@@ -188,3 +193,71 @@ func TestApplyEnvOverridesIncludesManifestRefreshPolicy(t *testing.T) {
 		t.Fatalf("expected manifestBackoffJitterRatio=0.4, got %v", cfg.ManifestBackoffJitterRatio)
 	}
 }
+
+func TestTopicShardingFromYAMLAndEnv(t *testing.T) {
+	var parsed DaemonConfig
+	if err := yaml.Unmarshal([]byte("network:\n  topicShards: 16\n  topicMigrationUntil: 2030-01-02T00:00:00Z\n"), &parsed); err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	cfg := waku.DefaultConfig()
+	Merge(&cfg, parsed.Network)
+	want := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	if cfg.TopicShards != 16 || !cfg.TopicMigrationUntil.Equal(want) {
+		t.Fatalf("unexpected sharding config: shards=%d until=%s", cfg.TopicShards, cfg.TopicMigrationUntil)
+	}
+
+	t.Setenv("AIM_NETWORK_TOPIC_SHARDS", "8")
+	t.Setenv("AIM_NETWORK_TOPIC_MIGRATION_UNTIL", "2031-05-06T07:08:09Z")
+	ApplyEnvOverrides(&cfg)
+	if cfg.TopicShards != 8 || !cfg.TopicMigrationUntil.Equal(time.Date(2031, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Fatalf("env must override sharding config: shards=%d until=%s", cfg.TopicShards, cfg.TopicMigrationUntil)
+	}
+}
+
+func TestPairTopicsFromYAMLAndEnv(t *testing.T) {
+	var parsed DaemonConfig
+	if err := yaml.Unmarshal([]byte("network:\n  pairTopics: true\n  pairTopicOverlap: 30m\n"), &parsed); err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	cfg := waku.DefaultConfig()
+	Merge(&cfg, parsed.Network)
+	if !cfg.PairTopics || cfg.PairTopicOverlap != 30*time.Minute {
+		t.Fatalf("unexpected pair topic config: enabled=%v overlap=%s", cfg.PairTopics, cfg.PairTopicOverlap)
+	}
+
+	t.Setenv("AIM_NETWORK_PAIR_TOPICS", "false")
+	t.Setenv("AIM_NETWORK_PAIR_TOPIC_OVERLAP", "2h")
+	ApplyEnvOverrides(&cfg)
+	if cfg.PairTopics || cfg.PairTopicOverlap != 2*time.Hour {
+		t.Fatalf("env must override pair topic config: enabled=%v overlap=%s", cfg.PairTopics, cfg.PairTopicOverlap)
+	}
+}
+
+func TestLoadLoggingFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	raw := "logging:\n  sinks: [stderr, \"tcp:127.0.0.1:5170\"]\n  level: warn\n  levels: {network: debug}\n"
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	spec := LoadLoggingFromPath(path)
+	if len(spec.Sinks) != 2 || spec.Sinks[1] != "tcp:127.0.0.1:5170" || spec.Level != "warn" || spec.Levels["network"] != "debug" {
+		t.Fatalf("unexpected logging spec: %+v", spec)
+	}
+	if _, err := spec.Config(); err != nil {
+		t.Fatalf("logging spec must parse: %v", err)
+	}
+}
+
+func TestLoadRetryPolicyFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	raw := "network:\n  retryPolicy:\n    baseInterval: 500ms\n    maxAttempts: 3\n    deadLetter: false\n"
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	base := models.RetryPolicy{BaseIntervalMS: 2000, MaxIntervalMS: 30000, JitterRatio: 0.1, MaxAttempts: 8, DeadLetter: true}
+	policy := LoadRetryPolicyFromPath(path, base)
+	want := models.RetryPolicy{BaseIntervalMS: 500, MaxIntervalMS: 30000, JitterRatio: 0.1, MaxAttempts: 3}
+	if policy != want {
+		t.Fatalf("unexpected retry policy: %+v", policy)
+	}
+}
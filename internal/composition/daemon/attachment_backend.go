@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"aim-chat/go-backend/internal/platform/secrets"
+	"aim-chat/go-backend/internal/storage"
+)
+
+const (
+	attachmentBackendEnv     = "AIM_ATTACHMENT_BACKEND"
+	attachmentS3EndpointEnv  = "AIM_ATTACHMENT_S3_ENDPOINT"
+	attachmentS3BucketEnv    = "AIM_ATTACHMENT_S3_BUCKET"
+	attachmentS3RegionEnv    = "AIM_ATTACHMENT_S3_REGION"
+	attachmentS3AccessKeyEnv = "AIM_ATTACHMENT_S3_ACCESS_KEY"
+	attachmentS3SecretKeyEnv = "AIM_ATTACHMENT_S3_SECRET_KEY"
+	attachmentS3PrefixEnv    = "AIM_ATTACHMENT_S3_PREFIX"
+	attachmentS3PartSizeEnv  = "AIM_ATTACHMENT_S3_PART_SIZE_MB"
+
+	attachmentBackendFilesystem = "filesystem"
+	attachmentBackendS3         = "s3"
+)
+
+var ErrStorageKeyRotationObjectBackend = fmt.Errorf("storage key cannot be rotated while attachments are kept in an object backend (%s)", attachmentBackendEnv)
+
+// AttachmentObjectBackendConfigured reports whether attachment blobs live
+// outside the data dir.
+func AttachmentObjectBackendConfigured() bool {
+	return attachmentBackendName() == attachmentBackendS3
+}
+
+func attachmentBackendName() string {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv(attachmentBackendEnv)))
+	if name == "" {
+		return attachmentBackendFilesystem
+	}
+	return name
+}
+
+// attachmentBlobBackend returns the configured blob backend for the profile
+// in dataDir, or nil for the filesystem default.
+func attachmentBlobBackend(dataDir string) (storage.AttachmentBlobBackend, error) {
+	switch name := attachmentBackendName(); name {
+	case attachmentBackendFilesystem:
+		return nil, nil
+	case attachmentBackendS3:
+		accessKey, err := secrets.ResolveEnv(attachmentS3AccessKeyEnv)
+		if err != nil {
+			return nil, err
+		}
+		secretKey, err := secrets.ResolveEnv(attachmentS3SecretKeyEnv)
+		if err != nil {
+			return nil, err
+		}
+		partSize := int64(0)
+		if raw := strings.TrimSpace(os.Getenv(attachmentS3PartSizeEnv)); raw != "" {
+			mb, err := strconv.Atoi(raw)
+			if err != nil || mb <= 0 {
+				return nil, fmt.Errorf("%w: %s must be a positive number of MiB", storage.ErrInvalidS3Config, attachmentS3PartSizeEnv)
+			}
+			partSize = int64(mb) << 20
+		}
+		prefix := strings.Trim(strings.TrimSpace(os.Getenv(attachmentS3PrefixEnv)), "/")
+		if prefix != "" {
+			prefix += "/"
+		}
+		return storage.NewS3BlobBackend(storage.S3BlobConfig{
+			Endpoint:  os.Getenv(attachmentS3EndpointEnv),
+			Bucket:    os.Getenv(attachmentS3BucketEnv),
+			Region:    os.Getenv(attachmentS3RegionEnv),
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			Prefix:    prefix + attachmentObjectScope(dataDir),
+			PartSize:  partSize,
+		})
+	default:
+		return nil, fmt.Errorf("unknown %s %q (want %s or %s)", attachmentBackendEnv, name, attachmentBackendFilesystem, attachmentBackendS3)
+	}
+}
+
+// attachmentObjectScope keeps the blobs and lifecycle rules of account
+// profiles apart when they share a bucket.
+func attachmentObjectScope(dataDir string) string {
+	clean := filepath.Clean(dataDir)
+	if filepath.Base(filepath.Dir(clean)) == storageProfilesDir {
+		return storageProfilesDir + "/" + filepath.Base(clean)
+	}
+	return "default"
+}
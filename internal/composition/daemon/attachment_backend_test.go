@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/storage"
+)
+
+func TestAttachmentBlobBackendFromEnv(t *testing.T) {
+	dataDir := t.TempDir()
+	if backend, err := attachmentBlobBackend(dataDir); err != nil || backend != nil {
+		t.Fatalf("expected the filesystem default, got %T err=%v", backend, err)
+	}
+
+	t.Setenv(attachmentBackendEnv, "ftp")
+	if _, err := attachmentBlobBackend(dataDir); err == nil {
+		t.Fatal("expected an unknown backend to be rejected")
+	}
+
+	t.Setenv(attachmentBackendEnv, "s3")
+	t.Setenv(attachmentS3EndpointEnv, "https://minio.example:9000")
+	t.Setenv(attachmentS3AccessKeyEnv, "access")
+	t.Setenv(attachmentS3SecretKeyEnv, "secret")
+	if _, err := attachmentBlobBackend(dataDir); !errors.Is(err, storage.ErrInvalidS3Config) {
+		t.Fatalf("expected a missing bucket to be rejected, got %v", err)
+	}
+	t.Setenv(attachmentS3BucketEnv, "media")
+	if backend, err := attachmentBlobBackend(dataDir); err != nil || backend == nil {
+		t.Fatalf("expected an s3 backend, got %T err=%v", backend, err)
+	}
+	if _, err := BeginStorageKeyRotation(dataDir, "secret"); !errors.Is(err, ErrStorageKeyRotationObjectBackend) {
+		t.Fatalf("expected key rotation to be refused, got %v", err)
+	}
+}
+
+func TestAttachmentObjectScopeSeparatesProfiles(t *testing.T) {
+	dataDir := t.TempDir()
+	if got := attachmentObjectScope(dataDir); got != "default" {
+		t.Fatalf("expected default scope, got %q", got)
+	}
+	if got := attachmentObjectScope(filepath.Join(dataDir, "profiles", "acct_1")); got != "profiles/acct_1" {
+		t.Fatalf("expected profile scope, got %q", got)
+	}
+}
@@ -10,18 +10,27 @@ import (
 
 const DefaultDataDir = "go-backend/data"
 
-func ResolveStorage(dataDir string) (resolvedDir, secret string, bundle StorageBundle, err error) {
-	resolvedDir = strings.TrimSpace(dataDir)
-	if resolvedDir == "" {
-		resolvedDir = DefaultDataDir
+// ResolveDataDir applies the default to an empty data dir option.
+func ResolveDataDir(dataDir string) string {
+	if resolved := strings.TrimSpace(dataDir); resolved != "" {
+		return resolved
 	}
+	return DefaultDataDir
+}
+
+func ResolveStorage(dataDir string) (resolvedDir, secret string, bundle StorageBundle, err error) {
+	resolvedDir = ResolveDataDir(dataDir)
 
 	secret, err = StoragePassphrase(resolvedDir)
 	if err != nil {
 		if !errors.Is(err, ErrLegacyStorageSecretRequired) {
 			return "", "", StorageBundle{}, err
 		}
-		secret = LegacyMigrationSecret()
+		legacySecret, legacyErr := LegacyMigrationSecret()
+		if legacyErr != nil {
+			return "", "", StorageBundle{}, legacyErr
+		}
+		secret = legacySecret
 		if secret == "" {
 			return "", "", StorageBundle{}, err
 		}
@@ -30,6 +39,20 @@ func ResolveStorage(dataDir string) (resolvedDir, secret string, bundle StorageB
 		}
 	}
 
+	secret, err = ResumeStorageKeyRotation(resolvedDir, secret)
+	if err != nil {
+		return "", "", StorageBundle{}, fmt.Errorf("resume storage key rotation: %w", err)
+	}
+	if err := ResumeStorageSnapshotRestore(resolvedDir); err != nil {
+		return "", "", StorageBundle{}, fmt.Errorf("resume storage snapshot restore: %w", err)
+	}
+	if _, err := EnsurePreUpgradeSnapshot(resolvedDir, AppVersion()); err != nil {
+		return "", "", StorageBundle{}, err
+	}
+	if _, err := MigrateStorage(resolvedDir, secret); err != nil {
+		return "", "", StorageBundle{}, err
+	}
+
 	bundle, err = BuildStorageBundle(resolvedDir, secret)
 	if err == nil {
 		return resolvedDir, secret, bundle, nil
@@ -37,7 +60,10 @@ func ResolveStorage(dataDir string) (resolvedDir, secret string, bundle StorageB
 	if !errors.Is(err, securestore.ErrAuthFailed) {
 		return "", "", StorageBundle{}, err
 	}
-	legacySecret := LegacyMigrationSecret()
+	legacySecret, legacyErr := LegacyMigrationSecret()
+	if legacyErr != nil {
+		return "", "", StorageBundle{}, legacyErr
+	}
 	if legacySecret == "" || legacySecret == secret {
 		return "", "", StorageBundle{}, fmt.Errorf(
 			"storage authentication failed: set %s to correct secret or %s for explicit migration: %w",
@@ -8,5 +8,13 @@ import (
 
 // BuildDaemonService composes daemon-ready service from config path and data dir.
 func BuildDaemonService(configPath, dataDir string) (contracts.DaemonService, error) {
-	return daemonservice.NewServiceForDaemonWithDataDir(wakuconfig.LoadFromPathWithDataDir(configPath, dataDir), dataDir)
+	svc, err := daemonservice.NewServiceForDaemonWithDataDir(wakuconfig.LoadFromPathWithDataDir(configPath, dataDir), dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := svc.SetRetryPolicy(wakuconfig.LoadRetryPolicyFromPath(configPath, svc.GetRetryPolicy())); err != nil {
+		_ = svc.Close()
+		return nil, err
+	}
+	return svc, nil
 }
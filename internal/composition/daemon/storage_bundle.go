@@ -8,15 +8,27 @@ import (
 )
 
 type StorageBundle struct {
-	MessageStore     *storage.MessageStore
-	SessionStore     crypto.SessionStore
-	AttachmentStore  *storage.AttachmentStore
-	IdentityPath     string
-	PrivacyPath      string
-	BlocklistPath    string
-	RequestInboxPath string
-	GroupStatePath   string
-	NodeBindingPath  string
+	MessageStore         *storage.MessageStore
+	SessionStore         crypto.SessionStore
+	AttachmentStore      *storage.AttachmentStore
+	IdentityPath         string
+	PrivacyPath          string
+	BlocklistPath        string
+	RequestInboxPath     string
+	GroupStatePath       string
+	NodeBindingPath      string
+	ClientStatePath      string
+	AnnotationsPath      string
+	ConversationSyncPath string
+	GroupAvatarsPath     string
+	MessageSequencesPath string
+	SnippetsPath         string
+	AttachmentPolicyPath string
+	ThreadSubsPath       string
+	MetricsHistoryPath   string
+	ContactSharingPath   string
+	AuditLogPath         string
+	DeadLettersPath      string
 }
 
 func BuildStorageBundle(dataDir, secret string) (StorageBundle, error) {
@@ -28,20 +40,36 @@ func BuildStorageBundle(dataDir, secret string) (StorageBundle, error) {
 	if err != nil {
 		return StorageBundle{}, err
 	}
-	attachmentStore, err := storage.NewAttachmentStoreWithSecret(attachmentsPath, secret)
+	blobBackend, err := attachmentBlobBackend(dataDir)
+	if err != nil {
+		return StorageBundle{}, err
+	}
+	attachmentStore, err := storage.NewAttachmentStoreWithBackend(attachmentsPath, secret, blobBackend)
 	if err != nil {
 		return StorageBundle{}, err
 	}
 
 	return StorageBundle{
-		MessageStore:     msgStore,
-		SessionStore:     crypto.NewEncryptedFileSessionStore(sessionsPath, secret),
-		AttachmentStore:  attachmentStore,
-		IdentityPath:     filepath.Join(dataDir, "identity.enc"),
-		PrivacyPath:      filepath.Join(dataDir, "privacy.enc"),
-		BlocklistPath:    filepath.Join(dataDir, "blocklist.enc"),
-		RequestInboxPath: filepath.Join(dataDir, "requests.enc"),
-		GroupStatePath:   filepath.Join(dataDir, "groups.enc"),
-		NodeBindingPath:  filepath.Join(dataDir, "node_binding.enc"),
+		MessageStore:         msgStore,
+		SessionStore:         crypto.NewEncryptedFileSessionStore(sessionsPath, secret),
+		AttachmentStore:      attachmentStore,
+		IdentityPath:         filepath.Join(dataDir, "identity.enc"),
+		PrivacyPath:          filepath.Join(dataDir, "privacy.enc"),
+		BlocklistPath:        filepath.Join(dataDir, "blocklist.enc"),
+		RequestInboxPath:     filepath.Join(dataDir, "requests.enc"),
+		GroupStatePath:       filepath.Join(dataDir, "groups.enc"),
+		NodeBindingPath:      filepath.Join(dataDir, "node_binding.enc"),
+		ClientStatePath:      filepath.Join(dataDir, "client_state.enc"),
+		AnnotationsPath:      filepath.Join(dataDir, "message_annotations.enc"),
+		ConversationSyncPath: filepath.Join(dataDir, "conversation_sync.enc"),
+		GroupAvatarsPath:     filepath.Join(dataDir, "group_avatars.enc"),
+		MessageSequencesPath: filepath.Join(dataDir, "message_sequences.enc"),
+		SnippetsPath:         filepath.Join(dataDir, "snippets.enc"),
+		AttachmentPolicyPath: filepath.Join(dataDir, "attachment_policies.enc"),
+		ThreadSubsPath:       filepath.Join(dataDir, "thread_subscriptions.enc"),
+		MetricsHistoryPath:   filepath.Join(dataDir, "metrics_history.enc"),
+		ContactSharingPath:   filepath.Join(dataDir, "contact_sharing.enc"),
+		AuditLogPath:         filepath.Join(dataDir, "audit_log.enc"),
+		DeadLettersPath:      filepath.Join(dataDir, "dead_letters.enc"),
 	}, nil
 }
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"aim-chat/go-backend/internal/platform/secrets"
 )
 
 const (
@@ -20,8 +22,14 @@ const (
 var ErrLegacyStorageSecretRequired = errors.New("legacy storage secret is required")
 var ErrInsecureStorageKeyMode = errors.New("insecure storage key mode is forbidden in production")
 
+// StoragePassphrase returns the storage secret. AIM_STORAGE_PASSPHRASE may hold
+// a secret:// reference instead of the passphrase itself.
 func StoragePassphrase(dataDir string) (string, error) {
-	if secret := strings.TrimSpace(os.Getenv(storagePassphraseEnv)); secret != "" {
+	secret, err := secrets.ResolveEnv(storagePassphraseEnv)
+	if err != nil {
+		return "", err
+	}
+	if secret != "" {
 		return secret, nil
 	}
 	keyPath := filepath.Join(dataDir, "storage.key")
@@ -52,7 +60,7 @@ func StoragePassphrase(dataDir string) (string, error) {
 	if _, err := rand.Read(buf); err != nil {
 		return "", err
 	}
-	secret := base64.RawStdEncoding.EncodeToString(buf)
+	secret = base64.RawStdEncoding.EncodeToString(buf)
 	if err := WriteStorageKey(dataDir, secret); err != nil {
 		return "", err
 	}
@@ -70,8 +78,8 @@ func WriteStorageKey(dataDir, secret string) error {
 	return os.WriteFile(keyPath, []byte(secret), 0o600)
 }
 
-func LegacyMigrationSecret() string {
-	return strings.TrimSpace(os.Getenv(legacyMigrationSecretEnv))
+func LegacyMigrationSecret() (string, error) {
+	return secrets.ResolveEnv(legacyMigrationSecretEnv)
 }
 
 func hasLegacyPersistentData(dataDir string) bool {
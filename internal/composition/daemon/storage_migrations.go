@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const storageProfilesDir = "profiles"
+
+// StorageSchemas lists every store of an account profile dir with its
+// on-disk format. Domain stores have had a single format so far and declare
+// no migrations yet.
+func StorageSchemas() []storage.StoreSchema {
+	schemas := storage.StoreSchemas()
+	for _, store := range []struct{ name, file string }{
+		{name: "identity", file: "identity.enc"},
+		{name: "privacy", file: "privacy.enc"},
+		{name: "blocklist", file: "blocklist.enc"},
+		{name: "requests", file: "requests.enc"},
+		{name: "groups", file: "groups.enc"},
+		{name: "node_binding", file: "node_binding.enc"},
+	} {
+		schemas = append(schemas, storage.StoreSchema{
+			Store:        store.name,
+			File:         store.file,
+			VersionField: "version",
+			Current:      1,
+		})
+	}
+	return schemas
+}
+
+// MigrateStorage brings the stores of every account profile to their current
+// schema. All migrations run in memory first; if any of them fails nothing
+// is written. Before the first write the data dir is snapshotted, and a
+// failed write restores that snapshot. It returns the applied migrations.
+func MigrateStorage(dataDir, secret string) ([]storage.PendingSchemaMigration, error) {
+	profiles, err := storageProfileDirs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var pending []storage.PendingSchemaMigration
+	for _, profile := range profiles {
+		for _, schema := range StorageSchemas() {
+			migration, ok, err := storage.PlanStoreMigration(filepath.Join(dataDir, profile), secret, schema)
+			if err != nil {
+				return nil, fmt.Errorf("storage migration (%s): %w", profile, err)
+			}
+			if ok {
+				pending = append(pending, migration)
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	backup, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonMigration, "schema migration")
+	if err != nil {
+		return nil, fmt.Errorf("pre-migration snapshot: %w", err)
+	}
+	for _, migration := range pending {
+		if err := migration.Write(); err != nil {
+			err = fmt.Errorf("write %s migration: %w", migration.Store, err)
+			if _, restoreErr := RestoreStorageSnapshot(dataDir, backup.ID); restoreErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("roll back storage migration: %w", restoreErr))
+			}
+			return nil, err
+		}
+	}
+	if err := PruneStorageSnapshots(dataDir, DefaultStorageSnapshotRetention, backup.ID); err != nil {
+		return pending, err
+	}
+	return pending, nil
+}
+
+// StorageSchemaVersions reports the on-disk schema version of every store in
+// every account profile.
+func StorageSchemaVersions(dataDir, secret string) ([]models.StorageSchemaVersion, error) {
+	profiles, err := storageProfileDirs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.StorageSchemaVersion, 0, len(profiles)*len(StorageSchemas()))
+	for _, profile := range profiles {
+		for _, schema := range StorageSchemas() {
+			state, err := storage.ReadStoreSchemaState(filepath.Join(dataDir, profile), secret, schema)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, models.StorageSchemaVersion{
+				Profile:   filepath.ToSlash(profile),
+				Store:     schema.Store,
+				Present:   state.Present,
+				Version:   state.Version,
+				Supported: schema.Current,
+			})
+		}
+	}
+	return out, nil
+}
+
+// storageProfileDirs returns the data dir itself, which holds the legacy
+// profile, followed by every profile dir below it.
+func storageProfileDirs(dataDir string) ([]string, error) {
+	out := []string{"."}
+	entries, err := os.ReadDir(filepath.Join(dataDir, storageProfilesDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return out, nil
+		}
+		return nil, err
+	}
+	profiles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			profiles = append(profiles, filepath.Join(storageProfilesDir, entry.Name()))
+		}
+	}
+	sort.Strings(profiles)
+	return append(out, profiles...), nil
+}
@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestMigrateStorageUpgradesEveryProfileWithBackup(t *testing.T) {
+	dataDir := t.TempDir()
+	const secret = "migration-secret"
+	legacy := map[string]any{"schema_version": 1, "messages": map[string]any{}, "pending": map[string]any{}}
+	for _, dir := range []string{dataDir, filepath.Join(dataDir, "profiles", "acct_1")} {
+		if err := securestore.WriteEncryptedJSON(filepath.Join(dir, "messages.json"), secret, legacy); err != nil {
+			t.Fatalf("seed messages: %v", err)
+		}
+	}
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dataDir, "client_state.enc"), secret, map[string]any{"version": 1}); err != nil {
+		t.Fatalf("seed client state: %v", err)
+	}
+
+	applied, err := MigrateStorage(dataDir, secret)
+	if err != nil {
+		t.Fatalf("migrate storage: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected both message stores to migrate, got %+v", applied)
+	}
+	snapshots, err := ListStorageSnapshots(dataDir)
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Reason != models.StorageSnapshotReasonMigration {
+		t.Fatalf("expected a pre-migration snapshot, got %+v", snapshots)
+	}
+
+	versions, err := StorageSchemaVersions(dataDir, secret)
+	if err != nil {
+		t.Fatalf("schema versions: %v", err)
+	}
+	seen := 0
+	for _, version := range versions {
+		if version.Store != "messages" {
+			continue
+		}
+		seen++
+		if !version.Present || version.Version != version.Supported {
+			t.Fatalf("message store was not migrated: %+v", version)
+		}
+	}
+	if seen != 2 {
+		t.Fatalf("expected message store versions for both profiles, got %+v", versions)
+	}
+
+	if applied, err := MigrateStorage(dataDir, secret); err != nil || len(applied) != 0 {
+		t.Fatalf("second run must be a no-op: applied=%+v err=%v", applied, err)
+	}
+}
+
+func TestMigrateStorageFailureWritesNothing(t *testing.T) {
+	dataDir := t.TempDir()
+	const secret = "migration-secret"
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dataDir, "messages.json"), secret, map[string]any{"schema_version": 1}); err != nil {
+		t.Fatalf("seed messages: %v", err)
+	}
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dataDir, "groups.enc"), secret, map[string]any{"version": 9}); err != nil {
+		t.Fatalf("seed groups: %v", err)
+	}
+
+	if _, err := MigrateStorage(dataDir, secret); !errors.Is(err, storage.ErrUnsupportedStorageSchema) {
+		t.Fatalf("expected unsupported schema error, got %v", err)
+	}
+	state, err := storage.ReadStoreSchemaState(dataDir, secret, StorageSchemas()[0])
+	if err != nil || state.Version != 1 {
+		t.Fatalf("messages must be left at version 1: %+v err=%v", state, err)
+	}
+	snapshots, err := ListStorageSnapshots(dataDir)
+	if err != nil || len(snapshots) != 0 {
+		t.Fatalf("failed planning must not snapshot: %+v err=%v", snapshots, err)
+	}
+}
@@ -0,0 +1,339 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+const (
+	storageRotationJournalFile = "storage.rotation"
+	storageRotationJournalV1   = 1
+	storageRotationTempSuffix  = ".rekey"
+	groupEventLogSuffix        = ".events.log"
+)
+
+var ErrStorageKeyManagedByEnv = fmt.Errorf("storage key is supplied by %s and cannot be rotated", storagePassphraseEnv)
+
+// errStorageFileUnreadable marks an envelope that opens with neither the old
+// nor the new secret.
+var errStorageFileUnreadable = errors.New("storage file opens with neither secret")
+
+// storageRotationJournal is written before any file is touched and encrypted
+// with the old secret, so an interrupted rotation can be resumed with the
+// secret that is still recorded in storage.key.
+type storageRotationJournal struct {
+	Version   int       `json:"version"`
+	NewSecret string    `json:"new_secret"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StorageKeyFromEnv reports whether the storage secret comes from the
+// environment instead of the storage.key file.
+func StorageKeyFromEnv() bool {
+	return strings.TrimSpace(os.Getenv(storagePassphraseEnv)) != ""
+}
+
+// BeginStorageKeyRotation generates a new storage secret and records it in the
+// rotation journal. An unfinished rotation is continued with its secret.
+func BeginStorageKeyRotation(dataDir, currentSecret string) (string, error) {
+	if StorageKeyFromEnv() {
+		return "", ErrStorageKeyManagedByEnv
+	}
+	if AttachmentObjectBackendConfigured() {
+		// Remote blobs are encrypted with the current key and are not rekeyed.
+		return "", ErrStorageKeyRotationObjectBackend
+	}
+	if pending, ok, err := PendingStorageKeyRotation(dataDir, currentSecret); err != nil || ok {
+		return pending, err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	newSecret := base64.RawStdEncoding.EncodeToString(buf)
+	payload, err := json.Marshal(storageRotationJournal{
+		Version:   storageRotationJournalV1,
+		NewSecret: newSecret,
+		StartedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := securestore.Encrypt(currentSecret, payload)
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, storageRotationJournalFile), encrypted, 0o600); err != nil {
+		return "", err
+	}
+	return newSecret, nil
+}
+
+// PendingStorageKeyRotation returns the new secret of an unfinished rotation.
+// A journal that no longer opens with the current secret belongs to a
+// rotation whose key was already committed and is removed.
+func PendingStorageKeyRotation(dataDir, currentSecret string) (string, bool, error) {
+	path := filepath.Join(dataDir, storageRotationJournalFile)
+	plaintext, err := securestore.ReadDecryptedFile(path, currentSecret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		if errors.Is(err, securestore.ErrAuthFailed) {
+			if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+				return "", false, rmErr
+			}
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	var journal storageRotationJournal
+	if err := json.Unmarshal(plaintext, &journal); err != nil {
+		return "", false, err
+	}
+	if journal.Version != storageRotationJournalV1 || strings.TrimSpace(journal.NewSecret) == "" {
+		return "", false, errors.New("storage rotation journal is invalid")
+	}
+	return journal.NewSecret, true, nil
+}
+
+// RekeyStorage re-encrypts every encrypted file below dataDir from oldSecret
+// to newSecret. Files already readable with newSecret are left as they are,
+// which makes the walk safe to repeat after a crash. Files that open with
+// neither secret, such as exported backups or damaged stores, are left alone
+// and returned relative to dataDir, so the caller can report them.
+func RekeyStorage(dataDir, oldSecret, newSecret string, progress func(done, total int)) ([]string, error) {
+	files, err := listRekeyCandidates(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var unreadable []string
+	for i, path := range files {
+		if progress != nil {
+			progress(i, len(files))
+		}
+		err := rekeyStorageFile(path, oldSecret, newSecret)
+		if errors.Is(err, errStorageFileUnreadable) {
+			rel, relErr := filepath.Rel(dataDir, path)
+			if relErr != nil {
+				rel = path
+			}
+			unreadable = append(unreadable, filepath.ToSlash(rel))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rekey %s: %w", path, err)
+		}
+	}
+	if progress != nil {
+		progress(len(files), len(files))
+	}
+	return unreadable, nil
+}
+
+// CommitStorageKeyRotation switches storage.key to the new secret and drops
+// the journal. It must only run after RekeyStorage succeeded.
+func CommitStorageKeyRotation(dataDir, newSecret string) error {
+	if err := WriteStorageKey(dataDir, newSecret); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dataDir, storageRotationJournalFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ResumeStorageKeyRotation finishes a rotation interrupted by a crash and
+// returns the secret the storage is encrypted with afterwards. Files that
+// open with neither secret were unreadable before the crash too; the storage
+// scrub reports damaged stores once the daemon runs.
+func ResumeStorageKeyRotation(dataDir, secret string) (string, error) {
+	newSecret, ok, err := PendingStorageKeyRotation(dataDir, secret)
+	if err != nil || !ok {
+		return secret, err
+	}
+	if _, err := RekeyStorage(dataDir, secret, newSecret, nil); err != nil {
+		return "", err
+	}
+	if err := CommitStorageKeyRotation(dataDir, newSecret); err != nil {
+		return "", err
+	}
+	return newSecret, nil
+}
+
+func listRekeyCandidates(dataDir string) ([]string, error) {
+	var out []string
+	err := filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		name := d.Name()
+		switch {
+		case strings.HasSuffix(name, storageRotationTempSuffix):
+			if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+				return rmErr
+			}
+			return nil
+		case name == "storage.key" || name == storageRotationJournalFile:
+			return nil
+		case strings.HasSuffix(name, groupEventLogSuffix):
+			out = append(out, path)
+			return nil
+		}
+		encrypted, err := hasEnvelopeHeader(path)
+		if err != nil {
+			return err
+		}
+		if encrypted {
+			out = append(out, path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return out, err
+}
+
+func hasEnvelopeHeader(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, 16)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return securestore.IsEnvelope(head[:n]), nil
+}
+
+func rekeyStorageFile(path, oldSecret, newSecret string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var next []byte
+	if strings.HasSuffix(path, groupEventLogSuffix) {
+		next, err = rekeyEventLog(raw, oldSecret, newSecret)
+	} else {
+		next, err = rekeyEnvelope(raw, oldSecret, newSecret)
+	}
+	if next != nil {
+		if werr := writeFileAtomic(path, next, info.Mode().Perm()); werr != nil {
+			return werr
+		}
+	}
+	return err
+}
+
+// rekeyEnvelope returns nil when the envelope already opens with newSecret
+// and errStorageFileUnreadable when it opens with neither secret.
+func rekeyEnvelope(raw []byte, oldSecret, newSecret string) ([]byte, error) {
+	plaintext, err := securestore.Decrypt(oldSecret, raw)
+	if err == nil {
+		return securestore.Encrypt(newSecret, plaintext)
+	}
+	if !errors.Is(err, securestore.ErrAuthFailed) {
+		return nil, err
+	}
+	if _, err := securestore.Decrypt(newSecret, raw); err != nil {
+		if errors.Is(err, securestore.ErrAuthFailed) {
+			return nil, errStorageFileUnreadable
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// rekeyEventLog rewrites a group event log line by line. Envelope size does
+// not depend on the key, so every line keeps its length and the byte offsets
+// recorded in group snapshots stay valid. Lines that open with neither secret
+// are kept as they are and the log is reported as unreadable.
+func rekeyEventLog(raw []byte, oldSecret, newSecret string) ([]byte, error) {
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	changed, unreadable := false, false
+	var out bytes.Buffer
+	out.Grow(len(raw))
+	for _, line := range lines {
+		trimmed := bytes.TrimRight(line, "\n")
+		if len(trimmed) == 0 {
+			out.Write(line)
+			continue
+		}
+		encrypted, err := base64.StdEncoding.DecodeString(string(trimmed))
+		if err != nil {
+			out.Write(line)
+			continue
+		}
+		next, err := rekeyEnvelope(encrypted, oldSecret, newSecret)
+		if errors.Is(err, errStorageFileUnreadable) {
+			unreadable = true
+		}
+		if err != nil || next == nil {
+			out.Write(line)
+			continue
+		}
+		encoded := base64.StdEncoding.EncodeToString(next)
+		if len(encoded) != len(trimmed) {
+			return nil, errors.New("re-encrypted event log line changed size")
+		}
+		out.WriteString(encoded)
+		out.Write(line[len(trimmed):])
+		changed = true
+	}
+	var err error
+	if unreadable {
+		err = errStorageFileUnreadable
+	}
+	if !changed {
+		return nil, err
+	}
+	return out.Bytes(), err
+}
+
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + storageRotationTempSuffix
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
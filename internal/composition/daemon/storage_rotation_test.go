@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+func writeEncryptedTestFile(t *testing.T, path, secret, plaintext string) {
+	t.Helper()
+	encrypted, err := securestore.Encrypt(secret, []byte(plaintext))
+	if err != nil {
+		t.Fatalf("encrypt %s: %v", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, encrypted, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestResumeStorageKeyRotationFinishesInterruptedRekey(t *testing.T) {
+	t.Setenv(storagePassphraseEnv, "")
+	dataDir := t.TempDir()
+	const oldSecret = "old-secret"
+	if err := WriteStorageKey(dataDir, oldSecret); err != nil {
+		t.Fatalf("write storage key: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(dataDir, "identity.enc"):                     "identity",
+		filepath.Join(dataDir, "profiles", "acct_1", "groups.enc"): "groups",
+		filepath.Join(dataDir, "attachments", "blob_1"):            "blob",
+	}
+	for path, plaintext := range files {
+		writeEncryptedTestFile(t, path, oldSecret, plaintext)
+	}
+	backupPath := filepath.Join(dataDir, "export.backup")
+	writeEncryptedTestFile(t, backupPath, "user-backup-password", "backup")
+
+	newSecret, err := BeginStorageKeyRotation(dataDir, oldSecret)
+	if err != nil {
+		t.Fatalf("begin rotation: %v", err)
+	}
+	// Simulate a crash after one file was rewritten.
+	writeEncryptedTestFile(t, filepath.Join(dataDir, "identity.enc"), newSecret, "identity")
+	if err := os.WriteFile(filepath.Join(dataDir, "groups.enc"+storageRotationTempSuffix), []byte("torn"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	_, resumed, _, err := ResolveStorage(dataDir)
+	if err != nil {
+		t.Fatalf("resolve storage: %v", err)
+	}
+	if resumed != newSecret {
+		t.Fatalf("expected resumed rotation secret, got %q want %q", resumed, newSecret)
+	}
+	stored, err := os.ReadFile(filepath.Join(dataDir, "storage.key"))
+	if err != nil || string(stored) != newSecret {
+		t.Fatalf("storage.key was not committed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, storageRotationJournalFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("rotation journal must be removed, stat err=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "groups.enc"+storageRotationTempSuffix)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale temp file must be removed, stat err=%v", err)
+	}
+	for path, plaintext := range files {
+		got, err := securestore.ReadDecryptedFile(path, newSecret)
+		if err != nil || string(got) != plaintext {
+			t.Fatalf("%s not readable with new secret: %v", path, err)
+		}
+	}
+	if got, err := securestore.ReadDecryptedFile(backupPath, "user-backup-password"); err != nil || string(got) != "backup" {
+		t.Fatalf("foreign envelope must be left untouched: %v", err)
+	}
+}
+
+func TestRekeyStorageKeepsEventLogLineOffsets(t *testing.T) {
+	dataDir := t.TempDir()
+	var log strings.Builder
+	for _, payload := range []string{`[{"seq":1}]`, `[{"seq":2}]`} {
+		encrypted, err := securestore.Encrypt("old", []byte(payload))
+		if err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		log.WriteString(base64.StdEncoding.EncodeToString(encrypted) + "\n")
+	}
+	path := filepath.Join(dataDir, "groups.events.log")
+	if err := os.WriteFile(path, []byte(log.String()), 0o600); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+
+	var progressCalls int
+	unreadable, err := RekeyStorage(dataDir, "old", "new", func(done, total int) { progressCalls++ })
+	if err != nil || len(unreadable) != 0 {
+		t.Fatalf("rekey: unreadable=%v err=%v", unreadable, err)
+	}
+	if progressCalls == 0 {
+		t.Fatal("expected progress reports")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if len(raw) != log.Len() {
+		t.Fatalf("event log size changed: %d != %d", len(raw), log.Len())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		encrypted, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			t.Fatalf("decode line: %v", err)
+		}
+		if _, err := securestore.Decrypt("new", encrypted); err != nil {
+			t.Fatalf("line not re-encrypted: %v", err)
+		}
+	}
+}
+
+func TestRekeyStorageReportsFilesNeitherSecretOpens(t *testing.T) {
+	dataDir := t.TempDir()
+	writeEncryptedTestFile(t, filepath.Join(dataDir, "messages.json"), "old", "messages")
+	writeEncryptedTestFile(t, filepath.Join(dataDir, "sessions.json"), "new", "sessions")
+	writeEncryptedTestFile(t, filepath.Join(dataDir, "profiles", "acct_1", "privacy.enc"), "lost", "privacy")
+
+	unreadable, err := RekeyStorage(dataDir, "old", "new", nil)
+	if err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	if len(unreadable) != 1 || unreadable[0] != "profiles/acct_1/privacy.enc" {
+		t.Fatalf("unexpected unreadable files: %v", unreadable)
+	}
+	for _, name := range []string{"messages.json", "sessions.json"} {
+		if _, err := securestore.ReadDecryptedFile(filepath.Join(dataDir, name), "new"); err != nil {
+			t.Fatalf("%s not readable with new secret: %v", name, err)
+		}
+	}
+	if got, err := securestore.ReadDecryptedFile(filepath.Join(dataDir, "profiles", "acct_1", "privacy.enc"), "lost"); err != nil || string(got) != "privacy" {
+		t.Fatalf("unreadable file must be left untouched: %v", err)
+	}
+}
+
+func TestBeginStorageKeyRotationRejectsEnvManagedKey(t *testing.T) {
+	t.Setenv(storagePassphraseEnv, "explicit-secret")
+	if _, err := BeginStorageKeyRotation(t.TempDir(), "explicit-secret"); !errors.Is(err, ErrStorageKeyManagedByEnv) {
+		t.Fatalf("expected ErrStorageKeyManagedByEnv, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// ScrubStorage checks every store in every account profile. A file that
+// fails authentication is reported as one whole-file corruption; records of
+// plaintext files are checked against their checksums. With repair set, unreadable
+// files are replaced by the newest snapshot copy that decrypts and corrupt
+// records are copied back from the newest snapshot that still holds a good
+// copy; callers must stop writers and reload the stores afterwards. pace runs between files so a background
+// pass can yield to live traffic.
+func ScrubStorage(dataDir, secret string, repair bool, pace func()) (models.StorageScrubReport, error) {
+	report := models.StorageScrubReport{StartedAt: time.Now().UTC()}
+	profiles, err := storageProfileDirs(dataDir)
+	if err != nil {
+		return report, err
+	}
+	var snapshots []models.StorageSnapshot
+	if repair {
+		if snapshots, err = ListStorageSnapshots(dataDir); err != nil {
+			return report, err
+		}
+	}
+	for _, profile := range profiles {
+		dir := filepath.Join(dataDir, profile)
+		for _, schema := range StorageSchemas() {
+			if pace != nil {
+				pace()
+			}
+			result, err := storage.ScrubStoreFile(dir, secret, schema)
+			if err != nil {
+				return report, fmt.Errorf("storage scrub (%s): %w", profile, err)
+			}
+			if !result.Present {
+				continue
+			}
+			report.Stores++
+			if result.Unreadable {
+				corruption := models.StorageCorruption{Profile: filepath.ToSlash(profile), Store: schema.Store, WholeFile: true}
+				if repair {
+					if corruption.SnapshotID, err = restoreFromSnapshots(dataDir, profile, secret, schema, snapshots); err != nil {
+						return report, fmt.Errorf("storage repair (%s): %w", profile, err)
+					}
+					corruption.Restored = corruption.SnapshotID != ""
+				}
+				report.Corrupt = append(report.Corrupt, corruption)
+				continue
+			}
+			report.Records += result.Records
+			report.Unverified += result.Unverified
+			if len(result.Corrupt) == 0 {
+				continue
+			}
+			restoredFrom := map[storage.RecordCorruption]string{}
+			if repair {
+				if restoredFrom, err = repairFromSnapshots(dataDir, profile, secret, schema, result.Corrupt, snapshots); err != nil {
+					return report, fmt.Errorf("storage repair (%s): %w", profile, err)
+				}
+			}
+			for _, record := range result.Corrupt {
+				snapshotID, restored := restoredFrom[record]
+				report.Corrupt = append(report.Corrupt, models.StorageCorruption{
+					Profile:    filepath.ToSlash(profile),
+					Store:      schema.Store,
+					Field:      record.Field,
+					Record:     record.Key,
+					Missing:    record.Missing,
+					Restored:   restored,
+					SnapshotID: snapshotID,
+				})
+			}
+		}
+	}
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// restoreFromSnapshots replaces an unreadable store file with the copy from
+// the newest snapshot that decrypts. It returns that snapshot's ID, or ""
+// when no snapshot holds a usable copy.
+func restoreFromSnapshots(dataDir, profile, secret string, schema storage.StoreSchema, snapshots []models.StorageSnapshot) (string, error) {
+	for _, snapshot := range snapshots {
+		snapshotDir := filepath.Join(dataDir, storageSnapshotDir, snapshot.ID, storageSnapshotFilesDir, profile)
+		restored, err := storage.RestoreStoreFile(filepath.Join(dataDir, profile), snapshotDir, secret, schema)
+		if err != nil {
+			return "", err
+		}
+		if restored {
+			return snapshot.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// repairFromSnapshots walks the snapshots newest first until every corrupt
+// record is restored or none are left to try.
+func repairFromSnapshots(dataDir, profile, secret string, schema storage.StoreSchema, corrupt []storage.RecordCorruption, snapshots []models.StorageSnapshot) (map[storage.RecordCorruption]string, error) {
+	restoredFrom := map[storage.RecordCorruption]string{}
+	remaining := corrupt
+	for _, snapshot := range snapshots {
+		if len(remaining) == 0 {
+			break
+		}
+		snapshotDir := filepath.Join(dataDir, storageSnapshotDir, snapshot.ID, storageSnapshotFilesDir, profile)
+		restored, err := storage.RepairStoreRecords(filepath.Join(dataDir, profile), snapshotDir, secret, schema, remaining)
+		if err != nil {
+			return restoredFrom, err
+		}
+		next := remaining[:0:0]
+		for _, record := range remaining {
+			done := false
+			for _, fixed := range restored {
+				if fixed == record {
+					done = true
+					break
+				}
+			}
+			if done {
+				restoredFrom[record] = snapshot.ID
+				continue
+			}
+			next = append(next, record)
+		}
+		remaining = next
+	}
+	return restoredFrom, nil
+}
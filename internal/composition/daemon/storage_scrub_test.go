@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestScrubStorageRestoresCorruptRecordsFromSnapshot(t *testing.T) {
+	dataDir := t.TempDir()
+	secret := "test-secret"
+	path := filepath.Join(dataDir, "profiles", "acct_1", "client_state.enc")
+	entries := []models.ClientStateEntry{
+		{Namespace: "ui", Key: "theme", Value: "dark"},
+		{Namespace: "ui", Key: "lang", Value: "en"},
+	}
+	// Record checksums only guard plaintext stores.
+	plaintext, _ := json.Marshal(map[string]any{"version": 1, "entries": entries})
+	plaintext, err := securestore.AddRecordChecksums(plaintext)
+	if err != nil {
+		t.Fatalf("add checksums: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	if err := os.WriteFile(path, plaintext, 0o600); err != nil {
+		t.Fatalf("write store: %v", err)
+	}
+	snapshot, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, "")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	doc := map[string]json.RawMessage{}
+	_ = json.Unmarshal(plaintext, &doc)
+	entries[1].Value = "xx"
+	doc["entries"], _ = json.Marshal(entries)
+	raw, _ := json.Marshal(doc)
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("tamper store: %v", err)
+	}
+
+	report, err := ScrubStorage(dataDir, secret, false, nil)
+	if err != nil || len(report.Corrupt) != 1 || report.Corrupt[0].Restored {
+		t.Fatalf("expected one unrepaired corruption, got %+v err=%v", report, err)
+	}
+	if got := report.Corrupt[0]; got.Profile != "profiles/acct_1" || got.Store != "client_state" || got.Record != "1" {
+		t.Fatalf("unexpected corruption: %+v", got)
+	}
+
+	report, err = ScrubStorage(dataDir, secret, true, nil)
+	if err != nil || len(report.Corrupt) != 1 || !report.Corrupt[0].Restored || report.Corrupt[0].SnapshotID != snapshot.ID {
+		t.Fatalf("expected the record to be restored from %s, got %+v err=%v", snapshot.ID, report, err)
+	}
+	if report, err := ScrubStorage(dataDir, secret, false, nil); err != nil || len(report.Corrupt) != 0 || report.Records != 2 {
+		t.Fatalf("expected a clean pass after repair, got %+v err=%v", report, err)
+	}
+}
+
+func TestScrubStorageRestoresUnreadableFileFromNewestDecryptableSnapshot(t *testing.T) {
+	dataDir := t.TempDir()
+	secret := "test-secret"
+	path := filepath.Join(dataDir, "profiles", "acct_1", "client_state.enc")
+	entries := []models.ClientStateEntry{
+		{Namespace: "ui", Key: "theme", Value: "dark"},
+		{Namespace: "ui", Key: "lang", Value: "en"},
+	}
+	if err := securestore.WriteEncryptedJSON(path, secret, map[string]any{"version": 1, "entries": entries}); err != nil {
+		t.Fatalf("write store: %v", err)
+	}
+	good, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, "")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read store: %v", err)
+	}
+	var env securestore.Envelope
+	if err := json.Unmarshal(bytes.TrimPrefix(raw, []byte("AIMENC1\n")), &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	env.Ciphertext[0] ^= 0x01
+	damaged, _ := json.Marshal(env)
+	if err := os.WriteFile(path, append([]byte("AIMENC1\n"), damaged...), 0o600); err != nil {
+		t.Fatalf("flip ciphertext byte: %v", err)
+	}
+	// The newest snapshot already holds the damaged file.
+	if _, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, ""); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	report, err := ScrubStorage(dataDir, secret, false, nil)
+	if err != nil || report.Stores != 1 || len(report.Corrupt) != 1 {
+		t.Fatalf("expected the damaged file to be reported, got %+v err=%v", report, err)
+	}
+	if got := report.Corrupt[0]; !got.WholeFile || got.Restored || got.Store != "client_state" || got.Profile != "profiles/acct_1" {
+		t.Fatalf("unexpected corruption: %+v", got)
+	}
+
+	report, err = ScrubStorage(dataDir, secret, true, nil)
+	if err != nil || len(report.Corrupt) != 1 || !report.Corrupt[0].Restored || report.Corrupt[0].SnapshotID != good.ID {
+		t.Fatalf("expected the file to be restored from %s, got %+v err=%v", good.ID, report, err)
+	}
+	if report, err := ScrubStorage(dataDir, secret, false, nil); err != nil || len(report.Corrupt) != 0 || report.Records != 2 {
+		t.Fatalf("expected a clean pass after repair, got %+v err=%v", report, err)
+	}
+}
@@ -0,0 +1,451 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	storageSnapshotDir              = "snapshots"
+	storageSnapshotFilesDir         = "files"
+	storageSnapshotManifestFile     = "manifest.json"
+	storageSnapshotManifestV1       = 1
+	storageSnapshotStagingSuffix    = ".partial"
+	storageRestoreJournalFile       = "storage.restore"
+	storageRestoreJournalV1         = 1
+	storageVersionFile              = "app.version"
+	appVersionEnv                   = "AIM_APP_VERSION"
+	DefaultStorageSnapshotRetention = 5
+)
+
+var (
+	ErrStorageSnapshotNotFound = errors.New("storage snapshot is not found")
+	ErrStorageSnapshotInvalid  = errors.New("storage snapshot is invalid")
+)
+
+// storageSnapshotManifest lists the copied files. Snapshot files stay below
+// the data dir, so key rotation re-encrypts them together with live state
+// and a snapshot always opens with the current storage.key.
+type storageSnapshotManifest struct {
+	Version  int                    `json:"version"`
+	Snapshot models.StorageSnapshot `json:"snapshot"`
+	Files    []storageSnapshotFile  `json:"files"`
+}
+
+type storageSnapshotFile struct {
+	Path string      `json:"path"`
+	Mode fs.FileMode `json:"mode"`
+	Size int64       `json:"size"`
+}
+
+// storageRestoreJournal is written before a restore touches live files, so a
+// crash halfway through is finished on the next start instead of leaving a
+// mix of old and restored state.
+type storageRestoreJournal struct {
+	Version    int       `json:"version"`
+	SnapshotID string    `json:"snapshot_id"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// AppVersion returns the version of the running build, or "" when unknown.
+func AppVersion() string {
+	return strings.TrimSpace(os.Getenv(appVersionEnv))
+}
+
+// CreateStorageSnapshot copies every state file below dataDir into a new
+// snapshot. Callers must hold store writes with securestore.HoldWrites; the
+// copy is only consistent if nothing changes while it runs. The snapshot
+// becomes visible atomically once all files and the manifest are written.
+func CreateStorageSnapshot(dataDir, reason, label string) (models.StorageSnapshot, error) {
+	id, err := newStorageSnapshotID()
+	if err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	root := filepath.Join(dataDir, storageSnapshotDir)
+	staging := filepath.Join(root, id+storageSnapshotStagingSuffix)
+	if err := os.RemoveAll(staging); err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	files, err := listSnapshotCandidates(dataDir)
+	if err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	manifest := storageSnapshotManifest{
+		Version: storageSnapshotManifestV1,
+		Snapshot: models.StorageSnapshot{
+			ID:         id,
+			Reason:     reason,
+			Label:      strings.TrimSpace(label),
+			AppVersion: AppVersion(),
+			CreatedAt:  time.Now().UTC(),
+		},
+		Files: make([]storageSnapshotFile, 0, len(files)),
+	}
+	for _, rel := range files {
+		entry, err := copyStorageFile(filepath.Join(dataDir, rel), filepath.Join(staging, storageSnapshotFilesDir, rel))
+		if err != nil {
+			_ = os.RemoveAll(staging)
+			return models.StorageSnapshot{}, fmt.Errorf("snapshot %s: %w", rel, err)
+		}
+		entry.Path = filepath.ToSlash(rel)
+		manifest.Files = append(manifest.Files, entry)
+		manifest.Snapshot.SizeBytes += entry.Size
+	}
+	manifest.Snapshot.Files = len(manifest.Files)
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return models.StorageSnapshot{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(staging, storageSnapshotManifestFile), raw, 0o600); err != nil {
+		_ = os.RemoveAll(staging)
+		return models.StorageSnapshot{}, err
+	}
+	if err := os.Rename(staging, filepath.Join(root, id)); err != nil {
+		_ = os.RemoveAll(staging)
+		return models.StorageSnapshot{}, err
+	}
+	return manifest.Snapshot, nil
+}
+
+// ListStorageSnapshots returns the complete snapshots below dataDir, newest
+// first. Leftovers of interrupted snapshot runs are removed.
+func ListStorageSnapshots(dataDir string) ([]models.StorageSnapshot, error) {
+	root := filepath.Join(dataDir, storageSnapshotDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []models.StorageSnapshot{}, nil
+		}
+		return nil, err
+	}
+	out := make([]models.StorageSnapshot, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), storageSnapshotStagingSuffix) {
+			if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		manifest, err := readStorageSnapshotManifest(dataDir, entry.Name())
+		if err != nil {
+			continue
+		}
+		out = append(out, manifest.Snapshot)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID > out[j].ID
+		}
+		return out[i].CreatedAt.After(out[j].CreatedAt)
+	})
+	return out, nil
+}
+
+// PruneStorageSnapshots keeps the newest keep snapshots and deletes the rest.
+// Snapshots listed in protect survive regardless of their age.
+func PruneStorageSnapshots(dataDir string, keep int, protect ...string) error {
+	snapshots, err := ListStorageSnapshots(dataDir)
+	if err != nil {
+		return err
+	}
+	if keep < 1 {
+		keep = 1
+	}
+	for i, snapshot := range snapshots {
+		if i < keep || slices.Contains(protect, snapshot.ID) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dataDir, storageSnapshotDir, snapshot.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveStorageSnapshots deletes every snapshot, e.g. when local data is wiped.
+func RemoveStorageSnapshots(dataDir string) error {
+	return os.RemoveAll(filepath.Join(dataDir, storageSnapshotDir))
+}
+
+// RestoreStorageSnapshot replaces the state stores below dataDir with the
+// snapshot contents. Stores created after the snapshot are removed; other
+// files, storage.key and the audit log among them, stay as they are. Callers
+// must hold store writes, discard the ones that waited and reload every
+// store afterwards.
+func RestoreStorageSnapshot(dataDir, snapshotID string) (models.StorageSnapshot, error) {
+	manifest, err := readStorageSnapshotManifest(dataDir, snapshotID)
+	if err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	src := filepath.Join(dataDir, storageSnapshotDir, manifest.Snapshot.ID, storageSnapshotFilesDir)
+	for _, file := range manifest.Files {
+		if _, err := os.Stat(filepath.Join(src, filepath.FromSlash(file.Path))); err != nil {
+			return models.StorageSnapshot{}, fmt.Errorf("%w: %s is missing", ErrStorageSnapshotInvalid, file.Path)
+		}
+	}
+	payload, err := json.Marshal(storageRestoreJournal{
+		Version:    storageRestoreJournalV1,
+		SnapshotID: manifest.Snapshot.ID,
+		StartedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	if err := writeFileAtomic(filepath.Join(dataDir, storageRestoreJournalFile), payload, 0o600); err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	if err := applyStorageSnapshot(dataDir, manifest); err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	if err := os.Remove(filepath.Join(dataDir, storageRestoreJournalFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return models.StorageSnapshot{}, err
+	}
+	return manifest.Snapshot, nil
+}
+
+// ResumeStorageSnapshotRestore finishes a restore interrupted by a crash.
+func ResumeStorageSnapshotRestore(dataDir string) error {
+	path := filepath.Join(dataDir, storageRestoreJournalFile)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var journal storageRestoreJournal
+	if err := json.Unmarshal(raw, &journal); err != nil {
+		return err
+	}
+	if journal.Version != storageRestoreJournalV1 || strings.TrimSpace(journal.SnapshotID) == "" {
+		return errors.New("storage restore journal is invalid")
+	}
+	_, err = RestoreStorageSnapshot(dataDir, journal.SnapshotID)
+	return err
+}
+
+// EnsurePreUpgradeSnapshot snapshots existing state the first time a new
+// build starts on dataDir and records the build version. Nothing happens when
+// the running version is unknown.
+func EnsurePreUpgradeSnapshot(dataDir, version string) (bool, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return false, nil
+	}
+	versionPath := filepath.Join(dataDir, storageVersionFile)
+	previous, err := os.ReadFile(versionPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	if strings.TrimSpace(string(previous)) == version {
+		return false, nil
+	}
+	created := false
+	files, err := listSnapshotCandidates(dataDir)
+	if err != nil {
+		return false, err
+	}
+	if len(files) > 0 {
+		label := "before " + version
+		if prev := strings.TrimSpace(string(previous)); prev != "" {
+			label = prev + " -> " + version
+		}
+		snapshot, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonPreUpgrade, label)
+		if err != nil {
+			return false, fmt.Errorf("pre-upgrade snapshot: %w", err)
+		}
+		if err := PruneStorageSnapshots(dataDir, DefaultStorageSnapshotRetention, snapshot.ID); err != nil {
+			return false, err
+		}
+		created = true
+	}
+	if err := writeFileAtomic(versionPath, []byte(version+"\n"), 0o600); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+func applyStorageSnapshot(dataDir string, manifest storageSnapshotManifest) error {
+	src := filepath.Join(dataDir, storageSnapshotDir, manifest.Snapshot.ID, storageSnapshotFilesDir)
+	keep := make(map[string]struct{}, len(manifest.Files))
+	for _, file := range manifest.Files {
+		rel := filepath.FromSlash(file.Path)
+		if !isSnapshotStateFile(rel) {
+			continue
+		}
+		keep[rel] = struct{}{}
+		raw, err := os.ReadFile(filepath.Join(src, rel))
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", file.Path, err)
+		}
+		if err := writeFileAtomic(filepath.Join(dataDir, rel), raw, file.Mode.Perm()); err != nil {
+			return fmt.Errorf("restore %s: %w", file.Path, err)
+		}
+	}
+	current, err := listSnapshotCandidates(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, rel := range current {
+		if _, ok := keep[rel]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dataDir, rel)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func readStorageSnapshotManifest(dataDir, snapshotID string) (storageSnapshotManifest, error) {
+	snapshotID = strings.TrimSpace(snapshotID)
+	if snapshotID == "" || snapshotID != filepath.Base(snapshotID) || strings.HasPrefix(snapshotID, ".") {
+		return storageSnapshotManifest{}, ErrStorageSnapshotNotFound
+	}
+	raw, err := os.ReadFile(filepath.Join(dataDir, storageSnapshotDir, snapshotID, storageSnapshotManifestFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return storageSnapshotManifest{}, ErrStorageSnapshotNotFound
+		}
+		return storageSnapshotManifest{}, err
+	}
+	var manifest storageSnapshotManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return storageSnapshotManifest{}, ErrStorageSnapshotInvalid
+	}
+	if manifest.Version != storageSnapshotManifestV1 || manifest.Snapshot.ID != snapshotID {
+		return storageSnapshotManifest{}, ErrStorageSnapshotInvalid
+	}
+	for _, file := range manifest.Files {
+		rel := filepath.FromSlash(file.Path)
+		if !filepath.IsLocal(rel) {
+			return storageSnapshotManifest{}, ErrStorageSnapshotInvalid
+		}
+	}
+	return manifest, nil
+}
+
+// snapshotProfileFiles are the state stores of one account profile, relative
+// to its dir. The audit log is left out so a restore cannot roll back or
+// erase security history, and only the attachment index is kept: blobs are
+// large, content addressed and never rewritten.
+var snapshotProfileFiles = []string{
+	"messages.json",
+	"sessions.json",
+	"identity.enc",
+	"privacy.enc",
+	"blocklist.enc",
+	"requests.enc",
+	"groups.enc",
+	"groups" + groupEventLogSuffix,
+	"node_binding.enc",
+	"client_state.enc",
+	"message_annotations.enc",
+	"conversation_sync.enc",
+	"group_avatars.enc",
+	"message_sequences.enc",
+	"snippets.enc",
+	"attachment_policies.enc",
+	"thread_subscriptions.enc",
+	"metrics_history.enc",
+	"contact_sharing.enc",
+	"dead_letters.enc",
+	filepath.Join("attachments", "index.json"),
+}
+
+// snapshotDataDirFiles are the state stores shared by every profile. The
+// enrollment grant and redeemed tokens are not state a user may roll back.
+var snapshotDataDirFiles = []string{
+	"accounts.json",
+	"handoffs.enc",
+	"plugins-state.json",
+}
+
+// listSnapshotCandidates returns the state files present below dataDir,
+// relative to it. Only the stores listed above are part of a snapshot;
+// anything else in the data dir is neither copied nor touched by a restore.
+func listSnapshotCandidates(dataDir string) ([]string, error) {
+	profiles, err := storageProfileDirs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	add := func(rel string) error {
+		info, err := os.Lstat(filepath.Join(dataDir, rel))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			out = append(out, rel)
+		}
+		return nil
+	}
+	for _, rel := range snapshotDataDirFiles {
+		if err := add(rel); err != nil {
+			return nil, err
+		}
+	}
+	for _, profile := range profiles {
+		for _, file := range snapshotProfileFiles {
+			if err := add(filepath.Join(profile, file)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// isSnapshotStateFile reports whether rel is one of the snapshotted stores.
+// Restores skip anything else, which older snapshots may still list.
+func isSnapshotStateFile(rel string) bool {
+	if slices.Contains(snapshotDataDirFiles, rel) {
+		return true
+	}
+	file := rel
+	if parts := strings.SplitN(filepath.ToSlash(rel), "/", 3); len(parts) == 3 && parts[0] == storageProfilesDir {
+		file = filepath.FromSlash(parts[2])
+	}
+	return slices.Contains(snapshotProfileFiles, file)
+}
+
+func copyStorageFile(src, dst string) (storageSnapshotFile, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return storageSnapshotFile{}, err
+	}
+	raw, err := os.ReadFile(src)
+	if err != nil {
+		return storageSnapshotFile{}, err
+	}
+	if err := writeFileAtomic(dst, raw, 0o600); err != nil {
+		return storageSnapshotFile{}, err
+	}
+	return storageSnapshotFile{Mode: info.Mode().Perm(), Size: int64(len(raw))}, nil
+}
+
+func newStorageSnapshotID() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "snap_" + time.Now().UTC().Format("20060102T150405Z") + "_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func writeSnapshotTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func readSnapshotTestFile(t *testing.T, path string) string {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(raw)
+}
+
+func TestStorageSnapshotRestoresPointInTimeState(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "storage.key"), "key-1")
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "messages.json"), "messages-v1")
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "profiles", "acct_1", "groups.enc"), "groups-v1")
+
+	snapshot, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, " nightly ")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if snapshot.Files != 2 || snapshot.Label != "nightly" || snapshot.SizeBytes != int64(len("messages-v1")+len("groups-v1")) {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "storage.key"), "key-2")
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "messages.json"), "messages-v2")
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "profiles", "acct_2", "identity.enc"), "new-account")
+
+	restored, err := RestoreStorageSnapshot(dataDir, snapshot.ID)
+	if err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
+	if restored.ID != snapshot.ID {
+		t.Fatalf("unexpected restored snapshot: %+v", restored)
+	}
+	if got := readSnapshotTestFile(t, filepath.Join(dataDir, "messages.json")); got != "messages-v1" {
+		t.Fatalf("messages not restored: %q", got)
+	}
+	if got := readSnapshotTestFile(t, filepath.Join(dataDir, "profiles", "acct_1", "groups.enc")); got != "groups-v1" {
+		t.Fatalf("groups not restored: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "profiles", "acct_2", "identity.enc")); !os.IsNotExist(err) {
+		t.Fatalf("file created after the snapshot must be removed, stat err=%v", err)
+	}
+	if got := readSnapshotTestFile(t, filepath.Join(dataDir, "storage.key")); got != "key-2" {
+		t.Fatalf("storage key must not be rolled back: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, storageRestoreJournalFile)); !os.IsNotExist(err) {
+		t.Fatalf("restore journal must be removed, stat err=%v", err)
+	}
+	if _, err := RestoreStorageSnapshot(dataDir, "../"+snapshot.ID); err != ErrStorageSnapshotNotFound {
+		t.Fatalf("expected not found for escaping id, got %v", err)
+	}
+}
+
+func TestResumeStorageSnapshotRestoreFinishesInterruptedRestore(t *testing.T) {
+	t.Setenv(storagePassphraseEnv, "")
+	dataDir := t.TempDir()
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "accounts.json"), "state-v1")
+	snapshot, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, "")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "accounts.json"), "state-v2")
+	journal, err := json.Marshal(storageRestoreJournal{Version: storageRestoreJournalV1, SnapshotID: snapshot.ID})
+	if err != nil {
+		t.Fatalf("marshal journal: %v", err)
+	}
+	writeSnapshotTestFile(t, filepath.Join(dataDir, storageRestoreJournalFile), string(journal))
+
+	if _, _, _, err := ResolveStorage(dataDir); err != nil {
+		t.Fatalf("resolve storage: %v", err)
+	}
+	if got := readSnapshotTestFile(t, filepath.Join(dataDir, "accounts.json")); got != "state-v1" {
+		t.Fatalf("interrupted restore was not finished: %q", got)
+	}
+}
+
+func TestPruneStorageSnapshotsKeepsNewestAndProtected(t *testing.T) {
+	dataDir := t.TempDir()
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "messages.json"), "messages")
+	var ids []string
+	for range 4 {
+		snapshot, err := CreateStorageSnapshot(dataDir, models.StorageSnapshotReasonManual, "")
+		if err != nil {
+			t.Fatalf("create snapshot: %v", err)
+		}
+		ids = append(ids, snapshot.ID)
+	}
+	if err := os.MkdirAll(filepath.Join(dataDir, storageSnapshotDir, "snap_torn"+storageSnapshotStagingSuffix), 0o700); err != nil {
+		t.Fatalf("mkdir staging: %v", err)
+	}
+
+	if err := PruneStorageSnapshots(dataDir, 2, ids[0]); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	snapshots, err := ListStorageSnapshots(dataDir)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	got := map[string]bool{}
+	for _, snapshot := range snapshots {
+		got[snapshot.ID] = true
+	}
+	if len(snapshots) != 3 || !got[ids[0]] || !got[ids[2]] || !got[ids[3]] {
+		t.Fatalf("unexpected snapshots after prune: %+v", snapshots)
+	}
+	if snapshots[0].ID != ids[3] {
+		t.Fatalf("snapshots must be listed newest first: %+v", snapshots)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, storageSnapshotDir, "snap_torn"+storageSnapshotStagingSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("staging leftovers must be removed, stat err=%v", err)
+	}
+}
+
+func TestEnsurePreUpgradeSnapshotRunsOncePerVersion(t *testing.T) {
+	dataDir := t.TempDir()
+	created, err := EnsurePreUpgradeSnapshot(dataDir, "1.0.0")
+	if err != nil || created {
+		t.Fatalf("empty data dir must not be snapshotted: created=%v err=%v", created, err)
+	}
+	writeSnapshotTestFile(t, filepath.Join(dataDir, "messages.json"), "messages")
+
+	if created, err = EnsurePreUpgradeSnapshot(dataDir, "1.0.0"); err != nil || created {
+		t.Fatalf("same version must not be snapshotted: created=%v err=%v", created, err)
+	}
+	if created, err = EnsurePreUpgradeSnapshot(dataDir, "1.1.0"); err != nil || !created {
+		t.Fatalf("upgrade must be snapshotted: created=%v err=%v", created, err)
+	}
+	if created, err = EnsurePreUpgradeSnapshot(dataDir, "1.1.0"); err != nil || created {
+		t.Fatalf("second start must not snapshot again: created=%v err=%v", created, err)
+	}
+	if created, err = EnsurePreUpgradeSnapshot(dataDir, ""); err != nil || created {
+		t.Fatalf("unknown version must be ignored: created=%v err=%v", created, err)
+	}
+	snapshots, err := ListStorageSnapshots(dataDir)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Reason != models.StorageSnapshotReasonPreUpgrade || snapshots[0].Label != "1.0.0 -> 1.1.0" {
+		t.Fatalf("unexpected snapshots: %+v", snapshots)
+	}
+}
@@ -1,15 +1,57 @@
 package daemonserver
 
 import (
+	"context"
+
 	"aim-chat/go-backend/internal/adapters/rpc"
+	"aim-chat/go-backend/internal/bootstrap/wakuconfig"
+	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
 	"aim-chat/go-backend/internal/composition/daemon/servicefactory"
+	"aim-chat/go-backend/internal/platform/datadirlock"
+	"aim-chat/go-backend/internal/platform/handover"
+	"aim-chat/go-backend/internal/platform/logsink"
 )
 
 // NewRPCServerWithOptions wires daemon service and RPC transport.
 func NewRPCServerWithOptions(rpcAddr, configPath, dataDir string) (*rpc.Server, error) {
+	if err := ReloadLogging(configPath); err != nil {
+		return nil, err
+	}
 	svc, err := servicefactory.BuildDaemonService(configPath, dataDir)
 	if err != nil {
 		return nil, err
 	}
 	return rpc.NewServerWithService(rpcAddr, svc), nil
 }
+
+// LockDataDir takes the process lock of the daemon data dir. It must be held
+// before any store is opened and released after the service stopped.
+func LockDataDir(dataDir string, forceTakeover bool) (*datadirlock.Lock, error) {
+	return datadirlock.Acquire(daemoncomposition.ResolveDataDir(dataDir), forceTakeover)
+}
+
+// LockDataDirAfterHandover takes the data dir lock once the daemon that
+// handed its listener over let go of it. A daemon that died meanwhile is
+// waited out until its lock counts as stale.
+func LockDataDirAfterHandover(ctx context.Context, dataDir string) (*datadirlock.Lock, error) {
+	return datadirlock.AcquireWithin(ctx, daemoncomposition.ResolveDataDir(dataDir), datadirlock.StaleAfter+datadirlock.HeartbeatInterval)
+}
+
+// OfferHandover opens the handover socket in the data dir so a newer daemon
+// can take the RPC listener over. The data dir lock must be held.
+func OfferHandover(dataDir string) (*handover.Source, error) {
+	return handover.Listen(daemoncomposition.ResolveDataDir(dataDir))
+}
+
+// TakeOver asks the daemon running on the data dir for its RPC listener.
+// The caller must wait for the release before locking the data dir.
+func TakeOver(ctx context.Context, dataDir string) (*handover.Takeover, error) {
+	return handover.Take(ctx, daemoncomposition.ResolveDataDir(dataDir))
+}
+
+// ReloadLogging re-reads the logging section of the config file and swaps
+// the log sinks of the running daemon. On error the current sinks stay in
+// use.
+func ReloadLogging(configPath string) error {
+	return logsink.Apply(wakuconfig.LoadLoggingFromPath(configPath))
+}
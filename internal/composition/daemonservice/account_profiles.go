@@ -17,7 +17,7 @@ import (
 	"aim-chat/go-backend/internal/domains/contracts"
 	identityapp "aim-chat/go-backend/internal/domains/identity"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
-	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/securestore"
 	"aim-chat/go-backend/pkg/models"
 )
 
@@ -90,6 +90,11 @@ func (s *Service) saveAccountRegistry(reg persistedAccountRegistry) error {
 	if err != nil {
 		return err
 	}
+	release, err := securestore.BeginWrite("")
+	if err != nil {
+		return err
+	}
+	defer release()
 	return os.WriteFile(path, raw, 0o600)
 }
 
@@ -156,6 +161,9 @@ func (s *Service) reloadFromBundle(bundle daemoncomposition.StorageBundle, secre
 	if err := s.applyStoragePolicyFromSettings(settings); err != nil {
 		return err
 	}
+	s.applyFirstContactPowFromSettings(settings)
+	s.advertiseCapabilities()
+	s.applyCoverTrafficFromSettings(settings)
 
 	s.bootstrapStateStores(bundle, secret)
 
@@ -167,14 +175,29 @@ func (s *Service) reloadFromBundle(bundle daemoncomposition.StorageBundle, secre
 		s.attachmentStore,
 		s.logger,
 	)
+	s.configureIdentityCore()
 	s.messagingCore = messagingapp.NewService(buildMessagingDeps(s))
 	s.inboundMessagingCore = messagingapp.NewInboundService(buildInboundMessagingDeps(s))
 	s.groupCore = s.groupUseCases()
 	s.inboxCore = s.inboxUseCases()
 	s.notifier.Reset()
+	s.deferredDecryption.reset()
+	s.dailySummary.reset()
+	s.thumbnails.reset()
+	s.downloads.reset()
 	s.bindingLinkMu.Lock()
 	s.bindingLinks = map[string]pendingNodeBindingLink{}
 	s.bindingLinkMu.Unlock()
+	s.emergencyMu.Lock()
+	s.emergencyConfirms = map[string]pendingEmergencyBroadcast{}
+	s.emergencyMu.Unlock()
+	s.stateHashMu.Lock()
+	s.stateDivergence = map[string]models.StateDivergence{}
+	s.stateHashMu.Unlock()
+	s.exportMu.Lock()
+	s.exportTokens = map[string]pendingConversationExport{}
+	s.peerExportConsents = map[string]models.ExportConsent{}
+	s.exportMu.Unlock()
 	return nil
 }
 
@@ -210,7 +233,7 @@ func (s *Service) createAccountProfileLocked() (persistedAccountMeta, persistedA
 	if err != nil {
 		return persistedAccountMeta{}, persistedAccountRegistry{}, err
 	}
-	profileID, err := runtimeapp.GeneratePrefixedID(accountPrefix)
+	profileID, err := s.generateID(accountPrefix)
 	if err != nil {
 		return persistedAccountMeta{}, persistedAccountRegistry{}, err
 	}
@@ -0,0 +1,105 @@
+package daemonservice
+
+import (
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestRuntimeE2E_AttachmentAltTextTravelsWithMessage(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+
+	baseDir := t.TempDir()
+	makeService := func(name string) *Service {
+		svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new service %s: %v", name, err)
+		}
+		return svc
+	}
+	alice := makeService("alice")
+	bob := makeService("bob")
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, err := alice.GetIdentity()
+	if err != nil {
+		t.Fatalf("alice identity: %v", err)
+	}
+	bobIdentity, err := bob.GetIdentity()
+	if err != nil {
+		t.Fatalf("bob identity: %v", err)
+	}
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	meta, err := alice.PutAttachmentWithAltText("notes.txt", "text/plain", base64.StdEncoding.EncodeToString([]byte("payload")), "  Meeting notes  ")
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if meta.AltText != "Meeting notes" {
+		t.Fatalf("alt text must be stored trimmed, got %q", meta.AltText)
+	}
+	edited, err := alice.SetAttachmentAltText(meta.ID, "Notes from the planning meeting")
+	if err != nil {
+		t.Fatalf("edit alt text: %v", err)
+	}
+	if edited.AltText != "Notes from the planning meeting" {
+		t.Fatalf("unexpected edited alt text: %q", edited.AltText)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	if _, err := alice.SendMessageWithAttachments(context.Background(), bobIdentity.ID, "see attached", []string{"att-missing"}); err == nil {
+		t.Fatal("expected unknown attachment to be rejected")
+	}
+	if _, err := alice.SendMessageWithAttachments(context.Background(), bobIdentity.ID, "see attached", []string{meta.ID}); err != nil {
+		t.Fatalf("send message with attachments: %v", err)
+	}
+
+	sent, err := alice.GetMessages(bobIdentity.ID, 10, 0)
+	if err != nil || len(sent) != 1 || len(sent[0].Attachments) != 1 || sent[0].Attachments[0].AltText != edited.AltText {
+		t.Fatalf("sender history must carry attachment alt text: %+v err=%v", sent, err)
+	}
+
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		received, err := bob.GetMessages(aliceIdentity.ID, 10, 0)
+		if err != nil {
+			t.Fatalf("list bob messages: %v", err)
+		}
+		if len(received) == 1 {
+			attachments := received[0].Attachments
+			if len(attachments) != 1 || attachments[0].ID != meta.ID || attachments[0].AltText != edited.AltText || attachments[0].MimeType != "text/plain" {
+				t.Fatalf("unexpected received attachments: %+v", attachments)
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("message with attachments was not delivered")
+}
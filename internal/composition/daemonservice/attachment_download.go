@@ -0,0 +1,150 @@
+package daemonservice
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	// attachmentDownloadTTL is how long an idle download keeps its blob in
+	// memory.
+	attachmentDownloadTTL = 15 * time.Minute
+	// maxAttachmentDownloads bounds the blobs held for downloads at once; the
+	// least recently used download is dropped first.
+	maxAttachmentDownloads = 8
+)
+
+var errAttachmentDownloadNotFound = errors.New("download session not found")
+
+type attachmentDownloadSession struct {
+	info      models.AttachmentDownload
+	data      []byte
+	updatedAt time.Time
+}
+
+// attachmentDownloads holds the blobs of open chunked downloads so each chunk
+// does not load and decrypt the whole blob again.
+type attachmentDownloads struct {
+	mu       sync.Mutex
+	sessions map[string]*attachmentDownloadSession
+}
+
+func newAttachmentDownloads() *attachmentDownloads {
+	return &attachmentDownloads{sessions: map[string]*attachmentDownloadSession{}}
+}
+
+func (d *attachmentDownloads) open(session *attachmentDownloadSession, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.purgeLocked(now)
+	for len(d.sessions) >= maxAttachmentDownloads {
+		oldestID := ""
+		for id, existing := range d.sessions {
+			if oldestID == "" || existing.updatedAt.Before(d.sessions[oldestID].updatedAt) {
+				oldestID = id
+			}
+		}
+		delete(d.sessions, oldestID)
+	}
+	d.sessions[session.info.DownloadID] = session
+}
+
+// chunk returns the bytes of one chunk and refreshes the session's expiry.
+func (d *attachmentDownloads) chunk(downloadID string, index int, now time.Time) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.purgeLocked(now)
+	session, ok := d.sessions[downloadID]
+	if !ok {
+		return nil, errAttachmentDownloadNotFound
+	}
+	if index < 0 || index >= session.info.TotalChunks {
+		return nil, errors.New("invalid chunk index")
+	}
+	session.updatedAt = now
+	start := index * session.info.ChunkSize
+	end := min(start+session.info.ChunkSize, len(session.data))
+	return session.data[start:end], nil
+}
+
+func (d *attachmentDownloads) purgeLocked(now time.Time) {
+	for id, session := range d.sessions {
+		if now.Sub(session.updatedAt) > attachmentDownloadTTL {
+			delete(d.sessions, id)
+		}
+	}
+}
+
+func (d *attachmentDownloads) reset() {
+	d.mu.Lock()
+	d.sessions = map[string]*attachmentDownloadSession{}
+	d.mu.Unlock()
+}
+
+// InitAttachmentDownload opens a chunked download of an attachment, fetching
+// it from providers like GetAttachment when it is not stored locally. A zero
+// chunkSize uses the default.
+func (s *Service) InitAttachmentDownload(attachmentID string, chunkSize int) (models.AttachmentDownload, error) {
+	chunkSize, err := identityapp.NormalizeAttachmentDownloadChunkSize(chunkSize)
+	if err != nil {
+		return models.AttachmentDownload{}, err
+	}
+	meta, data, err := s.GetAttachment(attachmentID)
+	if err != nil {
+		return models.AttachmentDownload{}, err
+	}
+	if len(data) == 0 {
+		return models.AttachmentDownload{}, errors.New("attachment is empty")
+	}
+	downloadID, err := s.generateID("dl")
+	if err != nil {
+		return models.AttachmentDownload{}, err
+	}
+	totalChunks := (len(data) + chunkSize - 1) / chunkSize
+	chunkHashes := make([]string, 0, totalChunks)
+	for start := 0; start < len(data); start += chunkSize {
+		sum := sha256.Sum256(data[start:min(start+chunkSize, len(data))])
+		chunkHashes = append(chunkHashes, hex.EncodeToString(sum[:]))
+	}
+	fileSum := sha256.Sum256(data)
+	now := s.now()
+	info := models.AttachmentDownload{
+		DownloadID:   downloadID,
+		AttachmentID: meta.ID,
+		Name:         meta.Name,
+		MimeType:     meta.MimeType,
+		TotalSize:    int64(len(data)),
+		ChunkSize:    chunkSize,
+		TotalChunks:  totalChunks,
+		FileSHA256:   hex.EncodeToString(fileSum[:]),
+		ChunkSHA256:  chunkHashes,
+		ExpiresAt:    now.Add(attachmentDownloadTTL).UTC(),
+	}
+	s.downloads.open(&attachmentDownloadSession{info: info, data: data, updatedAt: now}, now)
+	return info, nil
+}
+
+// GetAttachmentDownloadChunk returns one chunk of an open download with its
+// SHA-256. Each call extends the download's idle expiry.
+func (s *Service) GetAttachmentDownloadChunk(downloadID string, chunkIndex int) (models.AttachmentDownloadChunk, error) {
+	downloadID = strings.TrimSpace(downloadID)
+	data, err := s.downloads.chunk(downloadID, chunkIndex, s.now())
+	if err != nil {
+		return models.AttachmentDownloadChunk{}, err
+	}
+	sum := sha256.Sum256(data)
+	return models.AttachmentDownloadChunk{
+		DownloadID:  downloadID,
+		ChunkIndex:  chunkIndex,
+		DataBase64:  base64.StdEncoding.EncodeToString(data),
+		ChunkSHA256: hex.EncodeToString(sum[:]),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package daemonservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestAttachmentDownloadResumesAcrossSessions(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "node"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	content := bytes.Repeat([]byte("resumable download line\n"), 2000)
+	meta, err := svc.PutAttachment("notes.txt", "text/plain", base64.StdEncoding.EncodeToString(content))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if _, err := svc.InitAttachmentDownload(meta.ID, 1024); err == nil {
+		t.Fatal("expected a chunk size below the minimum to be rejected")
+	}
+
+	first, err := svc.InitAttachmentDownload(meta.ID, 16*1024)
+	if err != nil {
+		t.Fatalf("init download: %v", err)
+	}
+	if first.TotalChunks != 3 || first.TotalSize != int64(len(content)) || len(first.ChunkSHA256) != 3 {
+		t.Fatalf("unexpected download: %+v", first)
+	}
+	fetch := func(downloadID string, index int) []byte {
+		t.Helper()
+		chunk, err := svc.GetAttachmentDownloadChunk(downloadID, index)
+		if err != nil {
+			t.Fatalf("chunk %d: %v", index, err)
+		}
+		data, err := base64.StdEncoding.DecodeString(chunk.DataBase64)
+		if err != nil {
+			t.Fatalf("decode chunk %d: %v", index, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != first.ChunkSHA256[index] || chunk.ChunkSHA256 != first.ChunkSHA256[index] {
+			t.Fatalf("chunk %d does not match its hash", index)
+		}
+		return data
+	}
+	got := fetch(first.DownloadID, 0)
+
+	// Losing the session must not lose progress: a new download of the same
+	// chunk size has the same hashes, so the client picks up at chunk 1.
+	svc.downloads.reset()
+	if _, err := svc.GetAttachmentDownloadChunk(first.DownloadID, 1); !errors.Is(err, errAttachmentDownloadNotFound) {
+		t.Fatalf("expected the old download to be gone, got %v", err)
+	}
+	second, err := svc.InitAttachmentDownload(meta.ID, 16*1024)
+	if err != nil {
+		t.Fatalf("reopen download: %v", err)
+	}
+	if second.FileSHA256 != first.FileSHA256 || !reflect.DeepEqual(second.ChunkSHA256, first.ChunkSHA256) {
+		t.Fatal("expected stable hashes across downloads")
+	}
+	got = append(got, fetch(second.DownloadID, 1)...)
+	got = append(got, fetch(second.DownloadID, 2)...)
+	if !bytes.Equal(got, content) {
+		t.Fatal("reassembled download does not match the attachment")
+	}
+	if _, err := svc.GetAttachmentDownloadChunk(second.DownloadID, 3); err == nil {
+		t.Fatal("expected an out of range chunk to be rejected")
+	}
+}
@@ -0,0 +1,76 @@
+package daemonservice
+
+import (
+	"errors"
+	"time"
+
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// attachmentExpiryNoticeWindow is how long before TTL deletion an attachment
+// is announced through notify.storage.expiring.
+const attachmentExpiryNoticeWindow = 24 * time.Hour
+
+type attachmentExpiryTracker interface {
+	ListExpiring(now time.Time, imageTTLSeconds, fileTTLSeconds int, within time.Duration) []models.AttachmentExpiry
+	ExtendGrace(id string, extraSeconds int, imageTTLSeconds, fileTTLSeconds int) (models.AttachmentExpiry, error)
+}
+
+// ExtendAttachmentExpiry grants an attachment extra time before the storage
+// policy TTL deletes it.
+func (s *Service) ExtendAttachmentExpiry(blobID string, extraSeconds int) (models.AttachmentExpiry, error) {
+	tracker, ok := s.attachmentStore.(attachmentExpiryTracker)
+	if !ok {
+		return models.AttachmentExpiry{}, errors.New("attachment grace extension is not supported")
+	}
+	imageTTL, fileTTL, err := s.attachmentTTLs()
+	if err != nil {
+		return models.AttachmentExpiry{}, err
+	}
+	return tracker.ExtendGrace(blobID, extraSeconds, imageTTL, fileTTL)
+}
+
+// attachmentTTLs returns the TTLs the retention loop applies; they are only
+// enforced in ephemeral mode.
+func (s *Service) attachmentTTLs() (int, int, error) {
+	policy, err := s.GetStoragePolicy()
+	if err != nil {
+		return 0, 0, err
+	}
+	if policy.ContentRetentionMode != privacydomain.RetentionEphemeral {
+		return 0, 0, nil
+	}
+	return policy.ImageTTLSeconds, policy.FileTTLSeconds, nil
+}
+
+// notifyExpiringAttachments announces attachments entering the notice window.
+// Each deadline is announced once; a grace extension moves the deadline and
+// makes the attachment eligible for another notice.
+func (s *Service) notifyExpiringAttachments(now time.Time, imageTTLSeconds, fileTTLSeconds int) {
+	tracker, ok := s.attachmentStore.(attachmentExpiryTracker)
+	if !ok {
+		return
+	}
+	expiring := tracker.ListExpiring(now, imageTTLSeconds, fileTTLSeconds, attachmentExpiryNoticeWindow)
+
+	s.expiryNoticeMu.Lock()
+	fresh := make([]models.AttachmentExpiry, 0, len(expiring))
+	current := make(map[string]time.Time, len(expiring))
+	for _, item := range expiring {
+		current[item.BlobID] = item.ExpiresAt
+		if notified, seen := s.expiryNotified[item.BlobID]; seen && notified.Equal(item.ExpiresAt) {
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+	s.expiryNotified = current
+	s.expiryNoticeMu.Unlock()
+
+	if len(fresh) == 0 {
+		return
+	}
+	s.notify("notify.storage.expiring", map[string]any{
+		"blobs": fresh,
+	})
+}
@@ -0,0 +1,28 @@
+package daemonservice
+
+import (
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+)
+
+const (
+	attachmentAllowedMimeEnv = "AIM_ATTACHMENT_ALLOWED_MIME"
+	attachmentDeniedMimeEnv  = "AIM_ATTACHMENT_DENIED_MIME"
+	attachmentAllowedExtEnv  = "AIM_ATTACHMENT_ALLOWED_EXT"
+	attachmentDeniedExtEnv   = "AIM_ATTACHMENT_DENIED_EXT"
+)
+
+// resolveAttachmentMimePolicyFromEnv starts from the default executable
+// blocklist. A configured deny list replaces the default one, so operators
+// can relax it explicitly; allow lists are empty (unrestricted) by default.
+func resolveAttachmentMimePolicyFromEnv() identityapp.AttachmentMimePolicy {
+	policy := identityapp.DefaultAttachmentMimePolicy()
+	policy.AllowedMimes = envCSV(attachmentAllowedMimeEnv)
+	policy.AllowedExtensions = envCSV(attachmentAllowedExtEnv)
+	if denied := envCSV(attachmentDeniedMimeEnv); denied != nil {
+		policy.DeniedMimes = denied
+	}
+	if denied := envCSV(attachmentDeniedExtEnv); denied != nil {
+		policy.DeniedExtensions = denied
+	}
+	return policy
+}
@@ -0,0 +1,123 @@
+package daemonservice
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	attachmentPolicyDroppedAlertKind = "attachment_policy_dropped"
+	attachmentPolicyHeldAlertKind    = "attachment_policy_held"
+)
+
+var (
+	errAttachmentAwaitsApproval = errors.New("attachment awaits approval")
+	errHeldAttachmentNotFound   = errors.New("held attachment not found")
+)
+
+// GetContactAttachmentPolicy returns the attachment policy of a contact.
+// Contacts without one accept every attachment.
+func (s *Service) GetContactAttachmentPolicy(contactID string) (models.ContactAttachmentPolicy, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return models.ContactAttachmentPolicy{}, messagingapp.ErrInvalidAttachmentPolicy
+	}
+	if policy, ok := s.attachmentPolicies.Get(contactID); ok {
+		return policy, nil
+	}
+	return models.ContactAttachmentPolicy{ContactID: contactID, Mode: models.AttachmentPolicyAllow}, nil
+}
+
+// SetContactAttachmentPolicy replaces the attachment policy of a contact.
+// It only affects messages received from now on.
+func (s *Service) SetContactAttachmentPolicy(policy models.ContactAttachmentPolicy) (models.ContactAttachmentPolicy, error) {
+	policy, err := messagingapp.NormalizeAttachmentPolicy(policy)
+	if err != nil {
+		return models.ContactAttachmentPolicy{}, err
+	}
+	if !s.identityManager.HasContact(policy.ContactID) {
+		return models.ContactAttachmentPolicy{}, errors.New("contact not found")
+	}
+	policy.UpdatedAt = time.Now().UTC()
+	if err := s.attachmentPolicies.Put(policy, messagingapp.IsDefaultAttachmentPolicy(policy)); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.ContactAttachmentPolicy{}, err
+	}
+	return policy, nil
+}
+
+// ListHeldAttachments returns the attachments waiting for approval, for one
+// contact or, with an empty id, for all of them.
+func (s *Service) ListHeldAttachments(contactID string) []models.HeldAttachment {
+	return s.attachmentPolicies.Held(contactID)
+}
+
+// ApproveHeldAttachment lets an attachment held by a contact's policy be
+// fetched like any other.
+func (s *Service) ApproveHeldAttachment(attachmentID string) (models.HeldAttachment, error) {
+	entry, ok, err := s.attachmentPolicies.Release(attachmentID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.HeldAttachment{}, err
+	}
+	if !ok {
+		return models.HeldAttachment{}, errHeldAttachmentNotFound
+	}
+	return entry, nil
+}
+
+// filterInboundAttachments applies the sender's attachment policy before a
+// message is stored. Dropped attachments are removed from the message so
+// their blobs are never fetched; held ones stay listed but cannot be fetched
+// until approved.
+func (s *Service) filterInboundAttachments(msg messagingapp.InboundPrivateMessage, attachments []models.MessageAttachment) []models.MessageAttachment {
+	policy, ok := s.attachmentPolicies.Get(msg.SenderID)
+	if !ok {
+		return attachments
+	}
+	kept, held, dropped := messagingapp.ApplyAttachmentPolicy(policy, attachments)
+	if len(held) > 0 {
+		now := time.Now().UTC()
+		entries := make([]models.HeldAttachment, 0, len(held))
+		for _, attachment := range held {
+			entries = append(entries, models.HeldAttachment{ContactID: msg.SenderID, MessageID: msg.ID, Attachment: attachment, HeldAt: now})
+		}
+		if err := s.attachmentPolicies.Hold(entries); err != nil {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+			dropped = append(dropped, held...)
+			kept, held = withoutAttachments(kept, held), nil
+		}
+	}
+	if len(dropped) > 0 {
+		s.notifySecurityAlertWithArgs(attachmentPolicyDroppedAlertKind, msg.SenderID, fmt.Sprintf("%d attachments dropped by contact policy", len(dropped)), map[string]string{
+			"count": strconv.Itoa(len(dropped)),
+		})
+	}
+	if len(held) > 0 {
+		s.notifySecurityAlertWithArgs(attachmentPolicyHeldAlertKind, msg.SenderID, fmt.Sprintf("%d attachments await approval", len(held)), map[string]string{
+			"count": strconv.Itoa(len(held)),
+		})
+	}
+	return kept
+}
+
+func withoutAttachments(in, remove []models.MessageAttachment) []models.MessageAttachment {
+	removed := make(map[string]bool, len(remove))
+	for _, attachment := range remove {
+		removed[attachment.ID] = true
+	}
+	out := make([]models.MessageAttachment, 0, len(in))
+	for _, attachment := range in {
+		if !removed[attachment.ID] {
+			out = append(out, attachment)
+		}
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package daemonservice
+
+import (
+	"errors"
+	"testing"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactAttachmentPolicyFiltersInboundAttachments(t *testing.T) {
+	svc, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	peer, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new peer service: %v", err)
+	}
+	if _, _, err := peer.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create peer identity: %v", err)
+	}
+	card, err := peer.identityManager.SelfContactCard("peer")
+	if err != nil {
+		t.Fatalf("peer self card: %v", err)
+	}
+	if _, err := svc.SetContactAttachmentPolicy(models.ContactAttachmentPolicy{ContactID: card.IdentityID, Mode: models.AttachmentPolicyBlock}); err == nil {
+		t.Fatal("policy for an unknown contact must be rejected")
+	}
+	if err := svc.identityManager.AddContact(card); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if _, err := svc.SetContactAttachmentPolicy(models.ContactAttachmentPolicy{ContactID: card.IdentityID, Mode: "everything"}); !errors.Is(err, messagingapp.ErrInvalidAttachmentPolicy) {
+		t.Fatalf("expected invalid policy, got %v", err)
+	}
+	if _, err := svc.SetContactAttachmentPolicy(models.ContactAttachmentPolicy{
+		ContactID:       card.IdentityID,
+		Mode:            models.AttachmentPolicyImagesOnly,
+		MaxSizeBytes:    1024,
+		RequireApproval: true,
+	}); err != nil {
+		t.Fatalf("set policy: %v", err)
+	}
+
+	msg := messagingapp.InboundPrivateMessage{ID: "msg-1", SenderID: card.IdentityID}
+	kept := svc.filterInboundAttachments(msg, []models.MessageAttachment{
+		{ID: "att-photo", MimeType: "image/png", Size: 512},
+		{ID: "att-huge", MimeType: "image/png", Size: 4096},
+		{ID: "att-doc", MimeType: "application/pdf", Size: 100},
+	})
+	if len(kept) != 1 || kept[0].ID != "att-photo" {
+		t.Fatalf("unexpected kept attachments: %+v", kept)
+	}
+	if held := svc.ListHeldAttachments(card.IdentityID); len(held) != 1 || held[0].MessageID != "msg-1" {
+		t.Fatalf("unexpected held attachments: %+v", held)
+	}
+	if _, _, err := svc.GetAttachment("att-photo"); !errors.Is(err, errAttachmentAwaitsApproval) {
+		t.Fatalf("held attachment must not be fetched, got %v", err)
+	}
+	kinds := map[string]bool{}
+	for _, alert := range svc.recentSecurityAlerts(card.IdentityID) {
+		kinds[alert.Kind] = true
+	}
+	if !kinds[attachmentPolicyDroppedAlertKind] || !kinds[attachmentPolicyHeldAlertKind] {
+		t.Fatalf("expected dropped and held alerts, got %+v", kinds)
+	}
+
+	if _, err := svc.ApproveHeldAttachment("att-photo"); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if _, _, err := svc.GetAttachment("att-photo"); errors.Is(err, errAttachmentAwaitsApproval) {
+		t.Fatal("approved attachment must no longer be held")
+	}
+	if _, err := svc.ApproveHeldAttachment("att-photo"); !errors.Is(err, errHeldAttachmentNotFound) {
+		t.Fatalf("expected not found on second approval, got %v", err)
+	}
+
+	if _, err := svc.SetContactAttachmentPolicy(models.ContactAttachmentPolicy{ContactID: card.IdentityID}); err != nil {
+		t.Fatalf("reset policy: %v", err)
+	}
+	if policy, _ := svc.GetContactAttachmentPolicy(card.IdentityID); policy.Mode != models.AttachmentPolicyAllow {
+		t.Fatalf("reset policy must allow everything, got %+v", policy)
+	}
+	if kept := svc.filterInboundAttachments(msg, []models.MessageAttachment{{ID: "att-doc", MimeType: "application/pdf"}}); len(kept) != 1 {
+		t.Fatalf("default policy must keep attachments, got %+v", kept)
+	}
+}
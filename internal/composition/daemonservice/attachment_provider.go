@@ -6,7 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
 	"aim-chat/go-backend/internal/domains/contracts"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/pkg/models"
 )
@@ -19,10 +21,14 @@ const (
 )
 
 func (s *Service) PutAttachment(name, mimeType, dataBase64 string) (models.AttachmentMeta, error) {
+	return s.PutAttachmentWithAltText(name, mimeType, dataBase64, "")
+}
+
+func (s *Service) PutAttachmentWithAltText(name, mimeType, dataBase64, altText string) (models.AttachmentMeta, error) {
 	if err := s.authorizeBlobOperation(s.localPeerID(), "upload"); err != nil {
 		return models.AttachmentMeta{}, err
 	}
-	meta, err := s.identityCore.PutAttachment(name, mimeType, dataBase64)
+	meta, err := s.identityCore.PutAttachmentWithAltText(name, mimeType, dataBase64, altText)
 	if err != nil {
 		return models.AttachmentMeta{}, err
 	}
@@ -38,6 +44,7 @@ func (s *Service) CommitAttachmentUpload(uploadID string) (models.AttachmentMeta
 	if err != nil {
 		return models.AttachmentMeta{}, err
 	}
+	meta = s.storeAttachmentThumbnail(meta)
 	s.announceLocalBlobProvider(meta)
 	return meta, nil
 }
@@ -47,6 +54,9 @@ func (s *Service) GetAttachment(attachmentID string) (models.AttachmentMeta, []b
 	if attachmentID == "" {
 		return models.AttachmentMeta{}, nil, errors.New("attachment id is required")
 	}
+	if s.attachmentPolicies.IsHeld(attachmentID) {
+		return models.AttachmentMeta{}, nil, errAttachmentAwaitsApproval
+	}
 	meta, data, err := s.identityCore.GetAttachment(attachmentID)
 	if err == nil {
 		return meta, data, nil
@@ -97,7 +107,43 @@ func (s *Service) announceLocalBlobProvider(meta models.AttachmentMeta) {
 	if !s.shouldAnnounceBlobFromPeer(peerID) {
 		return
 	}
-	s.announceBlobProvider(meta, peerID, time.Now().UTC())
+	now := time.Now().UTC()
+	if !s.blobAnnounce.allow(now) {
+		s.blobAnnounce.schedule(meta.ID, now)
+		s.metrics.RecordBlobAnnouncements("deferred", 1)
+		return
+	}
+	s.announceBlobProvider(meta, peerID, now)
+}
+
+// resolveMessageAttachments turns attachment ids into the metadata sent along
+// with a message, alt text included.
+func (s *Service) resolveMessageAttachments(attachmentIDs []string) ([]models.MessageAttachment, error) {
+	lister, ok := s.attachmentStore.(interface {
+		ListMetas() []models.AttachmentMeta
+	})
+	if !ok {
+		return nil, errors.New("message attachments are not supported")
+	}
+	metas := make(map[string]models.AttachmentMeta)
+	for _, meta := range lister.ListMetas() {
+		metas[meta.ID] = meta
+	}
+	out := make([]models.MessageAttachment, 0, len(attachmentIDs))
+	for _, id := range attachmentIDs {
+		meta, ok := metas[strings.TrimSpace(id)]
+		if !ok {
+			return nil, storage.ErrAttachmentNotFound
+		}
+		out = append(out, models.MessageAttachment{
+			ID:       meta.ID,
+			Name:     meta.Name,
+			MimeType: meta.MimeType,
+			Size:     meta.Size,
+			AltText:  meta.AltText,
+		})
+	}
+	return out, nil
 }
 
 func (s *Service) announceAllLocalBlobProviders() {
@@ -114,22 +160,34 @@ func (s *Service) announceAllLocalBlobProviders() {
 	if !ok {
 		return
 	}
+	// Everything is queued and the first window goes out right away; the
+	// retry tick announces the rest batch by batch.
 	now := time.Now().UTC()
 	for _, meta := range lister.ListMetas() {
 		if !s.shouldAnnounceBlob(meta) {
 			continue
 		}
-		s.announceBlobProvider(meta, peerID, now)
+		s.blobAnnounce.schedule(meta.ID, now)
 	}
+	s.announceDueBlobs(now)
 }
 
+// announceBlobProvider registers one provider record and queues its renewal
+// before the record expires.
 func (s *Service) announceBlobProvider(meta models.AttachmentMeta, peerID string, now time.Time) {
-	_ = s.blobProviders.announceBlob(meta.ID, peerID, defaultBlobProviderTTL, s.localBlobFetchProvider(), now)
+	cfg := s.blobAnnounce.config()
+	if err := s.blobProviders.announceBlob(meta.ID, peerID, cfg.ProviderTTL, s.localBlobFetchProvider(), now); err != nil {
+		s.metrics.RecordBlobAnnouncements("rejected", 1)
+		s.blobAnnounce.schedule(meta.ID, now.Add(cfg.BatchInterval))
+		return
+	}
+	s.metrics.RecordBlobAnnouncements("announced", 1)
+	s.blobAnnounce.renew(meta.ID, now)
 }
 
 func (s *Service) localBlobFetchProvider() func(string, string) (models.AttachmentMeta, []byte, error) {
 	return func(requestBlobID, requesterPeerID string) (models.AttachmentMeta, []byte, error) {
-		if err := s.authorizeBlobOperation(requesterPeerID, "fetch"); err != nil {
+		if err := s.authorizeBlobFetch(requestBlobID, requesterPeerID); err != nil {
 			return models.AttachmentMeta{}, nil, err
 		}
 		if !s.isPublicServingAllowed() {
@@ -336,6 +394,7 @@ func (s *Service) SetBlobReplicationMode(mode string) error {
 		return nil
 	}
 	s.blobProviders.removePeer(s.localPeerID())
+	s.blobAnnounce.reset()
 	s.announceAllLocalBlobProviders()
 	return nil
 }
@@ -348,6 +407,9 @@ func (s *Service) GetBlobFeatureFlags() models.BlobFeatureFlags {
 }
 
 func (s *Service) SetBlobFeatureFlags(announceEnabled, fetchEnabled bool, rolloutPercent int) (models.BlobFeatureFlags, error) {
+	if announceEnabled && !s.enrollmentGrant.Allows(enrollmenttoken.CapabilityBlobProvider) {
+		return models.BlobFeatureFlags{}, errBlobProviderNotGranted
+	}
 	rolloutPercent = clampRolloutPercent(rolloutPercent)
 	s.replicationMu.Lock()
 	s.blobFlags = blobFeatureFlags{
@@ -362,6 +424,7 @@ func (s *Service) SetBlobFeatureFlags(announceEnabled, fetchEnabled bool, rollou
 	}
 	peerID := s.localPeerID()
 	s.blobProviders.removePeer(peerID)
+	s.blobAnnounce.reset()
 	s.announceAllLocalBlobProviders()
 	return flags.toModel(), nil
 }
@@ -398,6 +461,7 @@ func (s *Service) setBlobPinState(blobID, pinState string) (models.AttachmentMet
 		return meta, nil
 	}
 	s.blobProviders.removeBlobPeer(blobID, s.localPeerID())
+	s.blobAnnounce.forget(blobID)
 	return meta, nil
 }
 
@@ -420,6 +484,10 @@ func (s *Service) shouldCacheFetchedBlob() bool {
 }
 
 func (s *Service) shouldAnnounceBlobFromPeer(peerID string) bool {
+	// A hidden identity advertises nothing to provider directories.
+	if s.privacyCore.Discoverability() == privacydomain.DiscoverabilityContactsOnly {
+		return false
+	}
 	s.replicationMu.RLock()
 	flags := s.blobFlags
 	s.replicationMu.RUnlock()
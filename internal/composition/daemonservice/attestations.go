@@ -0,0 +1,155 @@
+package daemonservice
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/bootstrap/manifesttrust"
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	domainAttestationTimeout  = 10 * time.Second
+	domainAttestationMaxBytes = 64 * 1024
+)
+
+// trustBundleAttestationSources checks authority attestations against the
+// trust bundle on disk, which the bootstrap refresher keeps current, and
+// fetches domain files over HTTPS through the manifest proxy when one is
+// configured, so lookups do not reveal more than manifest fetches do.
+type trustBundleAttestationSources struct {
+	trustBundlePath string
+	client          *http.Client
+}
+
+func newTrustBundleAttestationSources(cfg waku.Config) identityapp.AttestationSources {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy := strings.TrimSpace(cfg.ManifestProxyURL); proxy != "" {
+		if u, err := url.Parse(proxy); err == nil && u.Host != "" {
+			transport.Proxy = http.ProxyURL(u)
+		}
+	}
+	return &trustBundleAttestationSources{
+		trustBundlePath: cfg.BootstrapTrustBundlePath,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   domainAttestationTimeout,
+			CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+				if req.URL.Scheme != "https" {
+					return errors.New("attestation redirect left https")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func (a *trustBundleAttestationSources) AuthorityKeys() map[string]ed25519.PublicKey {
+	if a.trustBundlePath == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(a.trustBundlePath)
+	if err != nil {
+		return nil
+	}
+	bundle, err := manifesttrust.ParseBundle(raw)
+	if err != nil {
+		return nil
+	}
+	return bundle.AuthorityPublicKeys()
+}
+
+func (a *trustBundleAttestationSources) FetchDomainAttestation(ctx context.Context, domain string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, identityapp.DomainAttestationURL(domain), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("attestation fetch failed: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, domainAttestationMaxBytes))
+}
+
+// SetSelfAttestations replaces the attestations on this identity's cards.
+// Contacts pick them up with their next card refresh.
+func (s *Service) SetSelfAttestations(attestations []models.Attestation) ([]models.Attestation, error) {
+	card, err := s.identityCore.SetSelfAttestations(attestations)
+	if err != nil {
+		return nil, err
+	}
+	if card.Attestations == nil {
+		return []models.Attestation{}, nil
+	}
+	return card.Attestations, nil
+}
+
+// VerifyContactAttestations re-checks a contact's attestations now.
+func (s *Service) VerifyContactAttestations(contactID string) (models.Contact, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*domainAttestationTimeout)
+	defer cancel()
+	return s.verifyContactAttestations(ctx, strings.TrimSpace(contactID))
+}
+
+// AddContactCard adds a contact and checks the attestations its card
+// carries in the background, since domain checks go over the network.
+func (s *Service) AddContactCard(card models.ContactCard) error {
+	if err := s.identityCore.AddContactCard(card); err != nil {
+		return err
+	}
+	s.verifyContactAttestationsAsync(card)
+	return nil
+}
+
+func (s *Service) verifyContactAttestationsAsync(card models.ContactCard) {
+	if len(card.Attestations) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*domainAttestationTimeout)
+		defer cancel()
+		if _, err := s.verifyContactAttestations(ctx, card.IdentityID); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}()
+}
+
+func (s *Service) verifyContactAttestations(ctx context.Context, contactID string) (models.Contact, error) {
+	before := s.contactByID(contactID)
+	contact, err := s.identityCore.VerifyContactAttestations(ctx, contactID, s.attestationSources, time.Now())
+	if err != nil {
+		return models.Contact{}, err
+	}
+	if contact.Verified != before.Verified || contact.VerifiedSource != before.VerifiedSource {
+		s.notify("notify.contact.verification", map[string]any{
+			"contact_id":      contact.ID,
+			"verified":        contact.Verified,
+			"verified_source": contact.VerifiedSource,
+		})
+	}
+	return contact, nil
+}
+
+func (s *Service) contactByID(contactID string) models.Contact {
+	for _, contact := range s.identityManager.Contacts() {
+		if contact.ID == contactID {
+			return contact
+		}
+	}
+	return models.Contact{}
+}
@@ -0,0 +1,48 @@
+package daemonservice
+
+import (
+	"aim-chat/go-backend/internal/platform/auditexport"
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	auditExportTargetEnv = "AIM_AUDIT_EXPORT"
+	auditExportFormatEnv = "AIM_AUDIT_EXPORT_FORMAT"
+	auditExportQueueEnv  = "AIM_AUDIT_EXPORT_QUEUE"
+)
+
+// configureAuditExport streams audit events and security alerts to the SIEM
+// target in AIM_AUDIT_EXPORT ("file:<path>" or "syslog:<host:port>"),
+// encoded as AIM_AUDIT_EXPORT_FORMAT (jsonl or cef). Without a target
+// nothing is exported.
+func (s *Service) configureAuditExport() error {
+	spec := envString(auditExportTargetEnv)
+	if spec == "" {
+		return nil
+	}
+	target, err := auditexport.ParseTarget(spec)
+	if err != nil {
+		return err
+	}
+	exporter, err := auditexport.New(auditexport.Config{
+		Format:    envString(auditExportFormatEnv),
+		Target:    target,
+		QueueSize: envIntWithFallback(auditExportQueueEnv, auditexport.DefaultQueueSize),
+	})
+	if err != nil {
+		return err
+	}
+	s.auditExport = exporter
+	s.logger.Info("audit export enabled", "target", target.String())
+	return nil
+}
+
+// GetAuditExportStatus reports the export queue and its losses.
+func (s *Service) GetAuditExportStatus() models.AuditExportStatus {
+	return s.auditExport.Status()
+}
+
+func (s *Service) exportSecurityAlert(alert events.SecurityAlert) {
+	s.auditExport.Publish(auditexport.FromSecurityAlert(s.now(), alert))
+}
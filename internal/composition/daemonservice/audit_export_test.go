@@ -0,0 +1,48 @@
+package daemonservice
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestAuditExportWritesAuditEventsAndAlertsAsCEF(t *testing.T) {
+	dir := t.TempDir()
+	exportPath := filepath.Join(dir, "siem", "audit.cef")
+	t.Setenv(auditExportTargetEnv, "file:"+exportPath)
+	t.Setenv(auditExportFormatEnv, "cef")
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(dir, "data"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if status := svc.GetAuditExportStatus(); !status.Enabled || status.Format != "cef" {
+		t.Fatalf("expected cef export to be enabled, got %+v", status)
+	}
+
+	svc.recordAudit(models.AuditKindBlocklistAdded, "identity_id", "aim1_spammer")
+	svc.notifySecurityAlert("key.changed", "aim1_bob", "contact key changed")
+	if err := svc.Close(); err != nil {
+		t.Fatalf("close service: %v", err)
+	}
+
+	raw, err := os.ReadFile(exportPath)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two exported records, got %q", raw)
+	}
+	if !strings.Contains(lines[0], "|blocklist.added|") || !strings.Contains(lines[0], "cs1=identity_id\\=aim1_spammer") || !strings.Contains(lines[0], "externalId=1") {
+		t.Fatalf("unexpected audit record %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "|key.changed|contact key changed|7|") || !strings.Contains(lines[1], "duid=aim1_bob") {
+		t.Fatalf("unexpected alert record %q", lines[1])
+	}
+}
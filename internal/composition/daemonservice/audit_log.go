@@ -0,0 +1,84 @@
+package daemonservice
+
+import (
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/platform/auditexport"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// ListAuditEvents returns a page of the security audit log, newest first.
+func (s *Service) ListAuditEvents(kind string, limit, offset int) models.AuditLogPage {
+	return s.auditLog.List(kind, limit, offset)
+}
+
+// VerifyAuditLog checks the hash chain of the security audit log.
+func (s *Service) VerifyAuditLog() models.AuditVerification {
+	return s.auditLog.Verify()
+}
+
+// recordAudit appends a security event with alternating key/value details
+// and hands it to the audit export. The change it describes already
+// happened, so a failed write is recorded as a storage error rather than
+// returned, and the event is still exported.
+func (s *Service) recordAudit(kind string, keyvals ...string) {
+	var details map[string]string
+	if len(keyvals) > 0 {
+		details = make(map[string]string, len(keyvals)/2)
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			details[keyvals[i]] = keyvals[i+1]
+		}
+	}
+	evt, err := s.auditLog.Append(s.now(), kind, details)
+	if err != nil {
+		s.recordErrorWithContext(contracts.ErrorCategoryStorage, err, "audit.append", "", "kind", kind)
+		evt = models.AuditEvent{At: s.now(), Kind: kind, Details: details}
+	}
+	s.auditExport.Publish(auditexport.FromAuditEvent(evt))
+}
+
+// RevokeDevice revokes one of the user's devices and records it in the
+// audit log, even when announcing the revocation to some contacts failed.
+func (s *Service) RevokeDevice(deviceID string) (models.DeviceRevocation, error) {
+	rev, err := s.messagingCore.RevokeDevice(deviceID)
+	if rev.DeviceID != "" {
+		s.recordAudit(models.AuditKindDeviceRevoked, "device_id", rev.DeviceID)
+	}
+	return rev, err
+}
+
+func (s *Service) AddToBlocklist(identityID string) ([]string, error) {
+	blocked, err := s.privacyCore.AddToBlocklist(identityID)
+	if err == nil {
+		s.recordAudit(models.AuditKindBlocklistAdded, "identity_id", strings.TrimSpace(identityID))
+	}
+	return blocked, err
+}
+
+func (s *Service) RemoveFromBlocklist(identityID string) ([]string, error) {
+	blocked, err := s.privacyCore.RemoveFromBlocklist(identityID)
+	if err == nil {
+		s.recordAudit(models.AuditKindBlocklistRemoved, "identity_id", strings.TrimSpace(identityID))
+	}
+	return blocked, err
+}
+
+// UpdatePrivacySettings changes the message privacy mode and records the
+// change when the mode actually moved.
+func (s *Service) UpdatePrivacySettings(mode string) (privacydomain.PrivacySettings, error) {
+	previous, err := s.privacyCore.GetPrivacySettings()
+	if err != nil {
+		return privacydomain.PrivacySettings{}, err
+	}
+	updated, err := s.privacyCore.UpdatePrivacySettings(mode)
+	if err != nil {
+		return privacydomain.PrivacySettings{}, err
+	}
+	if updated.MessagePrivacyMode != previous.MessagePrivacyMode {
+		s.recordAudit(models.AuditKindPrivacyModeChanged,
+			"from", string(previous.MessagePrivacyMode), "to", string(updated.MessagePrivacyMode))
+	}
+	return updated, nil
+}
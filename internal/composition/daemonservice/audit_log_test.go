@@ -0,0 +1,51 @@
+package daemonservice
+
+import (
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestSecurityChangesAreAudited(t *testing.T) {
+	svc, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	if _, err := svc.AddToBlocklist("aim1_spammer"); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	settings, err := svc.GetPrivacySettings()
+	if err != nil {
+		t.Fatalf("get privacy settings: %v", err)
+	}
+	mode := string(settings.MessagePrivacyMode)
+	if _, err := svc.UpdatePrivacySettings(mode); err != nil {
+		t.Fatalf("keep privacy mode: %v", err)
+	}
+	if _, err := svc.UpdatePrivacySettings("contacts_only"); err != nil {
+		t.Fatalf("change privacy mode: %v", err)
+	}
+	if _, err := svc.RemoveFromBlocklist("aim1_spammer"); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+
+	page := svc.ListAuditEvents("", 0, 0)
+	if page.Total != 3 {
+		t.Fatalf("expected an unchanged mode not to be audited: %+v", page)
+	}
+	want := []string{models.AuditKindBlocklistRemoved, models.AuditKindPrivacyModeChanged, models.AuditKindBlocklistAdded}
+	for i, kind := range want {
+		if page.Events[i].Kind != kind {
+			t.Fatalf("event %d: got %q, want %q", i, page.Events[i].Kind, kind)
+		}
+	}
+	if page.Events[2].Details["identity_id"] != "aim1_spammer" || page.Events[1].Details["from"] != mode {
+		t.Fatalf("unexpected event details: %+v", page.Events)
+	}
+	if check := svc.VerifyAuditLog(); !check.Valid || check.Entries != 3 {
+		t.Fatalf("audit log must verify: %+v", check)
+	}
+}
@@ -0,0 +1,176 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	blobACLOverrideOwnerOnly    = "owner_only"
+	blobACLOverrideIdentities   = "identities"
+	blobACLOverrideGroupMembers = "group_members"
+)
+
+var errBlobACLOverrideUnsupported = errors.New("blob acl overrides are not supported")
+
+// normalizeBlobACLOverride validates an override and drops the fields its
+// mode does not use.
+func normalizeBlobACLOverride(mode string, identities []string, groupID string) (models.BlobACLOverride, error) {
+	out := models.BlobACLOverride{Mode: strings.ToLower(strings.TrimSpace(mode))}
+	switch out.Mode {
+	case blobACLOverrideOwnerOnly:
+	case blobACLOverrideIdentities:
+		out.Identities = blobACLAllowlistSlice(normalizeBlobACLAllowlist(identities))
+		if len(out.Identities) == 0 {
+			return models.BlobACLOverride{}, errors.New("blob acl override needs at least one identity")
+		}
+	case blobACLOverrideGroupMembers:
+		out.GroupID = strings.TrimSpace(groupID)
+		if out.GroupID == "" {
+			return models.BlobACLOverride{}, errors.New("blob acl override needs a group id")
+		}
+	default:
+		return models.BlobACLOverride{}, errors.New("invalid blob acl override mode")
+	}
+	return out, nil
+}
+
+func (s *Service) SetBlobACLOverride(blobID, mode string, identities []string, groupID string) (models.BlobACLOverrideState, error) {
+	blobID = strings.TrimSpace(blobID)
+	if blobID == "" {
+		return models.BlobACLOverrideState{}, errors.New("blob id is required")
+	}
+	override, err := normalizeBlobACLOverride(mode, identities, groupID)
+	if err != nil {
+		return models.BlobACLOverrideState{}, err
+	}
+	if override.GroupID != "" {
+		if _, err := s.groupCore.GetGroup(override.GroupID); err != nil {
+			return models.BlobACLOverrideState{}, err
+		}
+	}
+	override.UpdatedAt = time.Now().UTC()
+	return s.storeBlobACLOverride(blobID, &override)
+}
+
+func (s *Service) GetBlobACLOverride(blobID string) (models.BlobACLOverrideState, error) {
+	blobID = strings.TrimSpace(blobID)
+	if blobID == "" {
+		return models.BlobACLOverrideState{}, errors.New("blob id is required")
+	}
+	reader, ok := s.attachmentStore.(interface {
+		Meta(id string) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		return models.BlobACLOverrideState{}, errBlobACLOverrideUnsupported
+	}
+	meta, err := reader.Meta(blobID)
+	if err != nil {
+		return models.BlobACLOverrideState{}, err
+	}
+	return models.BlobACLOverrideState{BlobID: blobID, Override: meta.ACL}, nil
+}
+
+func (s *Service) RemoveBlobACLOverride(blobID string) (models.BlobACLOverrideState, error) {
+	blobID = strings.TrimSpace(blobID)
+	if blobID == "" {
+		return models.BlobACLOverrideState{}, errors.New("blob id is required")
+	}
+	return s.storeBlobACLOverride(blobID, nil)
+}
+
+func (s *Service) storeBlobACLOverride(blobID string, override *models.BlobACLOverride) (models.BlobACLOverrideState, error) {
+	setter, ok := s.attachmentStore.(interface {
+		SetACLOverride(id string, override *models.BlobACLOverride) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		return models.BlobACLOverrideState{}, errBlobACLOverrideUnsupported
+	}
+	meta, err := setter.SetACLOverride(blobID, override)
+	if err != nil {
+		if errors.Is(err, storage.ErrAttachmentNotFound) {
+			return models.BlobACLOverrideState{}, storage.ErrAttachmentNotFound
+		}
+		return models.BlobACLOverrideState{}, err
+	}
+	return models.BlobACLOverrideState{BlobID: blobID, Override: meta.ACL}, nil
+}
+
+// authorizeBlobFetch checks a peer fetch of one blob. An override on the blob
+// replaces the node-wide policy and applies whether or not the node is bound.
+// Overrides travel with the metadata, so nodes caching the blob keep
+// enforcing them.
+func (s *Service) authorizeBlobFetch(blobID, requesterPeerID string) error {
+	override := s.localBlobACLOverride(blobID)
+	if override == nil {
+		return s.authorizeBlobOperation(requesterPeerID, "fetch")
+	}
+	allowed, reason := s.isBlobFetchAllowedByOverride(*override, requesterPeerID)
+	if allowed {
+		return nil
+	}
+	s.auditBlobACLDenied("fetch", requesterPeerID, reason)
+	return contracts.ErrAttachmentAccessDenied
+}
+
+func (s *Service) localBlobACLOverride(blobID string) *models.BlobACLOverride {
+	if reader, ok := s.attachmentStore.(interface {
+		Meta(id string) (models.AttachmentMeta, error)
+	}); ok {
+		if meta, err := reader.Meta(blobID); err == nil {
+			return meta.ACL
+		}
+	}
+	if meta, _, ok := s.getEphemeralPublicBlob(blobID); ok {
+		return meta.ACL
+	}
+	return nil
+}
+
+func (s *Service) isBlobFetchAllowedByOverride(override models.BlobACLOverride, requesterPeerID string) (bool, string) {
+	ownerID := strings.TrimSpace(s.localPeerID())
+	requesterPeerID = strings.TrimSpace(requesterPeerID)
+	if ownerID == "" || requesterPeerID == "" {
+		return false, "missing_subject"
+	}
+	if requesterPeerID == ownerID {
+		return true, ""
+	}
+	switch override.Mode {
+	case blobACLOverrideOwnerOnly:
+		return false, "override_owner_only"
+	case blobACLOverrideIdentities:
+		for _, identityID := range override.Identities {
+			if strings.TrimSpace(identityID) == requesterPeerID {
+				return true, ""
+			}
+		}
+		return false, "override_not_listed"
+	case blobACLOverrideGroupMembers:
+		if s.isActiveGroupMember(override.GroupID, requesterPeerID) {
+			return true, ""
+		}
+		return false, "override_not_group_member"
+	default:
+		return false, "override_invalid_mode"
+	}
+}
+
+func (s *Service) isActiveGroupMember(groupID, memberID string) bool {
+	members, err := s.groupCore.ListGroupMembers(groupID)
+	if err != nil {
+		return false
+	}
+	for _, member := range members {
+		if strings.TrimSpace(member.MemberID) == memberID && member.Status == groupdomain.GroupMemberStatusActive {
+			return true
+		}
+	}
+	return false
+}
@@ -110,3 +110,73 @@ func TestBlobACLAllowlistRuntimeUpdateWithoutRestart(t *testing.T) {
 		t.Fatalf("unexpected payload: %q", string(data))
 	}
 }
+
+func TestBlobACLOverrideReplacesGlobalPolicy(t *testing.T) {
+	owner, receiver := newBlobACLPair(t)
+	if err := owner.AddContact(receiver.localPeerID(), "Receiver"); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	if _, err := owner.SetBlobACLPolicy("owner_contacts", nil); err != nil {
+		t.Fatalf("set acl: %v", err)
+	}
+	meta, err := owner.PutAttachment("a.txt", "text/plain", base64.StdEncoding.EncodeToString([]byte("payload")))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	state, err := owner.SetBlobACLOverride(meta.ID, "owner_only", nil, "")
+	if err != nil || state.Override == nil || state.Override.Mode != "owner_only" {
+		t.Fatalf("set override: %#v err=%v", state, err)
+	}
+	if _, _, err := receiver.GetAttachment(meta.ID); !errors.Is(err, contracts.ErrAttachmentAccessDenied) {
+		t.Fatalf("expected owner_only override to deny a contact, got: %v", err)
+	}
+
+	state, err = owner.RemoveBlobACLOverride(meta.ID)
+	if err != nil || state.Override != nil {
+		t.Fatalf("remove override: %#v err=%v", state, err)
+	}
+	if _, _, err := receiver.GetAttachment(meta.ID); err != nil {
+		t.Fatalf("expected the global policy to allow the contact again, got: %v", err)
+	}
+}
+
+func TestBlobACLOverrideAllowsListedIdentity(t *testing.T) {
+	owner, receiver := newBlobACLPair(t)
+	if _, err := owner.SetBlobACLPolicy("owner_only", nil); err != nil {
+		t.Fatalf("set acl: %v", err)
+	}
+	meta, err := owner.PutAttachment("a.txt", "text/plain", base64.StdEncoding.EncodeToString([]byte("payload")))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if _, err := owner.SetBlobACLOverride(meta.ID, "identities", []string{" " + receiver.localPeerID() + " "}, ""); err != nil {
+		t.Fatalf("set override: %v", err)
+	}
+	state, err := owner.GetBlobACLOverride(meta.ID)
+	if err != nil || state.Override == nil || len(state.Override.Identities) != 1 || state.Override.Identities[0] != receiver.localPeerID() {
+		t.Fatalf("unexpected stored override: %#v err=%v", state, err)
+	}
+	if _, data, err := receiver.GetAttachment(meta.ID); err != nil || string(data) != "payload" {
+		t.Fatalf("expected listed identity to fetch, got: %v", err)
+	}
+}
+
+func TestBlobACLOverrideValidation(t *testing.T) {
+	owner, _ := newBlobACLPair(t)
+	meta, err := owner.PutAttachment("a.txt", "text/plain", base64.StdEncoding.EncodeToString([]byte("payload")))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if _, err := owner.SetBlobACLOverride(meta.ID, "identities", []string{" "}, ""); err == nil {
+		t.Fatal("expected an empty identity list to be rejected")
+	}
+	if _, err := owner.SetBlobACLOverride(meta.ID, "group_members", nil, "missing-group"); err == nil {
+		t.Fatal("expected an unknown group to be rejected")
+	}
+	if _, err := owner.SetBlobACLOverride(meta.ID, "everyone", nil, ""); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+	if _, err := owner.SetBlobACLOverride("att1_missing", "owner_only", nil, ""); err == nil {
+		t.Fatal("expected a missing blob to be rejected")
+	}
+}
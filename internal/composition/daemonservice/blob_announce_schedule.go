@@ -0,0 +1,218 @@
+package daemonservice
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// blobAnnounceConfig paces provider announcements: at most BatchSize per
+// BatchInterval, the interval stretched or shrunk by up to JitterPercent so
+// nodes do not announce in lockstep. Records live for ProviderTTL.
+type blobAnnounceConfig struct {
+	BatchSize     int
+	BatchInterval time.Duration
+	JitterPercent int
+	ProviderTTL   time.Duration
+}
+
+func blobAnnounceConfigFromPreset(cfg blobNodePresetConfig) blobAnnounceConfig {
+	out := blobAnnounceConfig{
+		BatchSize:     cfg.AnnounceBatchSize,
+		BatchInterval: time.Duration(cfg.AnnounceBatchIntervalSec) * time.Second,
+		JitterPercent: cfg.AnnounceJitterPercent,
+		ProviderTTL:   time.Duration(cfg.ProviderRecordTTLSec) * time.Second,
+	}
+	if out.BatchSize < 1 {
+		out.BatchSize = 1
+	}
+	if out.BatchInterval <= 0 {
+		out.BatchInterval = time.Second
+	}
+	out.JitterPercent = max(0, min(out.JitterPercent, 50))
+	if out.ProviderTTL <= 0 {
+		out.ProviderTTL = defaultBlobProviderTTL
+	}
+	return out
+}
+
+// blobAnnounceSchedule holds the announcement budget of the current window
+// and the blobs waiting for a slot, either deferred or due for renewal.
+type blobAnnounceSchedule struct {
+	mu        sync.Mutex
+	cfg       blobAnnounceConfig
+	due       map[string]time.Time
+	budget    int
+	windowEnd time.Time
+	random    *rand.Rand
+}
+
+func newBlobAnnounceSchedule(cfg blobAnnounceConfig) *blobAnnounceSchedule {
+	return &blobAnnounceSchedule{
+		cfg:    cfg,
+		due:    make(map[string]time.Time),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (q *blobAnnounceSchedule) configure(cfg blobAnnounceConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.cfg = cfg
+	q.windowEnd = time.Time{}
+}
+
+func (q *blobAnnounceSchedule) config() blobAnnounceConfig {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.cfg
+}
+
+// allow spends one slot of the current window.
+func (q *blobAnnounceSchedule) allow(now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refillLocked(now)
+	if q.budget <= 0 {
+		return false
+	}
+	q.budget--
+	return true
+}
+
+// schedule queues blobID for at, keeping an earlier slot it already has.
+func (q *blobAnnounceSchedule) schedule(blobID string, at time.Time) {
+	blobID = strings.TrimSpace(blobID)
+	if blobID == "" {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if current, ok := q.due[blobID]; ok && current.Before(at) {
+		return
+	}
+	q.due[blobID] = at
+}
+
+// renew queues blobID ahead of the expiry of the record just announced: at
+// three quarters of the TTL, minus up to a tenth of it as jitter.
+func (q *blobAnnounceSchedule) renew(blobID string, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ttl := q.cfg.ProviderTTL
+	jitter := time.Duration(q.random.Int63n(int64(ttl/10) + 1))
+	q.due[strings.TrimSpace(blobID)] = now.Add(ttl*3/4 - jitter)
+}
+
+// takeDue removes and returns the due blobs the current window still has
+// slots for, oldest first.
+func (q *blobAnnounceSchedule) takeDue(now time.Time) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refillLocked(now)
+	if q.budget <= 0 {
+		return nil
+	}
+	due := make([]string, 0)
+	for blobID, at := range q.due {
+		if !at.After(now) {
+			due = append(due, blobID)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if q.due[due[i]].Equal(q.due[due[j]]) {
+			return due[i] < due[j]
+		}
+		return q.due[due[i]].Before(q.due[due[j]])
+	})
+	if len(due) > q.budget {
+		due = due[:q.budget]
+	}
+	for _, blobID := range due {
+		delete(q.due, blobID)
+	}
+	q.budget -= len(due)
+	return due
+}
+
+func (q *blobAnnounceSchedule) forget(blobID string) {
+	q.mu.Lock()
+	delete(q.due, strings.TrimSpace(blobID))
+	q.mu.Unlock()
+}
+
+func (q *blobAnnounceSchedule) reset() {
+	q.mu.Lock()
+	q.due = make(map[string]time.Time)
+	q.mu.Unlock()
+}
+
+func (q *blobAnnounceSchedule) queued() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.due)
+}
+
+func (q *blobAnnounceSchedule) refillLocked(now time.Time) {
+	if now.Before(q.windowEnd) {
+		return
+	}
+	q.budget = q.cfg.BatchSize
+	interval := q.cfg.BatchInterval
+	if spread := int64(interval) * int64(q.cfg.JitterPercent) / 100; spread > 0 {
+		interval += time.Duration(q.random.Int63n(2*spread+1) - spread)
+	}
+	q.windowEnd = now.Add(interval)
+}
+
+// blobAnnouncementStats adds the current queue depth to the counters.
+func (s *Service) blobAnnouncementStats() map[string]int {
+	stats := s.metrics.BlobAnnouncements()
+	stats["queued"] = s.blobAnnounce.queued()
+	return stats
+}
+
+// drainBlobAnnouncements runs on the retry tick and announces the queued
+// blobs the current window has room for.
+func (s *Service) drainBlobAnnouncements(now time.Time) {
+	if !s.runtime.IsNetworking() {
+		return
+	}
+	s.announceDueBlobs(now)
+}
+
+// announceDueBlobs announces due blobs up to the window budget. Blobs that
+// were deleted or stopped qualifying are dropped from the schedule.
+func (s *Service) announceDueBlobs(now time.Time) {
+	due := s.blobAnnounce.takeDue(now)
+	if len(due) == 0 {
+		return
+	}
+	peerID := s.localPeerID()
+	if peerID == "" || !s.shouldAnnounceBlobFromPeer(peerID) {
+		s.blobAnnounce.reset()
+		return
+	}
+	lister, ok := s.attachmentStore.(interface {
+		ListMetas() []models.AttachmentMeta
+	})
+	if !ok {
+		return
+	}
+	metas := make(map[string]models.AttachmentMeta)
+	for _, meta := range lister.ListMetas() {
+		metas[meta.ID] = meta
+	}
+	for _, blobID := range due {
+		meta, ok := metas[blobID]
+		if !ok || !s.shouldAnnounceBlob(meta) {
+			continue
+		}
+		s.announceBlobProvider(meta, peerID, now)
+	}
+	s.metrics.RecordBlobAnnouncements("batches", 1)
+}
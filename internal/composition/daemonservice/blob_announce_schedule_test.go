@@ -0,0 +1,75 @@
+package daemonservice
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBlobAnnounceScheduleBatchesAndRenews(t *testing.T) {
+	cfg := blobAnnounceConfig{BatchSize: 2, BatchInterval: time.Second, ProviderTTL: 100 * time.Second}
+	q := newBlobAnnounceSchedule(cfg)
+	now := time.Unix(1_700_000_000, 0)
+	for i := 0; i < 5; i++ {
+		q.schedule(fmt.Sprintf("att1_%d", i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if got := q.takeDue(now.Add(10 * time.Millisecond)); len(got) != 2 || got[0] != "att1_0" || got[1] != "att1_1" {
+		t.Fatalf("expected the two oldest blobs, got %v", got)
+	}
+	if got := q.takeDue(now.Add(20 * time.Millisecond)); len(got) != 0 {
+		t.Fatalf("expected the window to be spent, got %v", got)
+	}
+	if q.allow(now.Add(30 * time.Millisecond)) {
+		t.Fatal("expected no immediate slot in a spent window")
+	}
+	if got := q.takeDue(now.Add(2 * time.Second)); len(got) != 2 {
+		t.Fatalf("expected the next batch after the interval, got %v", got)
+	}
+
+	q.renew("att1_0", now)
+	q.mu.Lock()
+	renewAt := q.due["att1_0"]
+	q.mu.Unlock()
+	if renewAt.Before(now.Add(65*time.Second)) || renewAt.After(now.Add(75*time.Second)) {
+		t.Fatalf("expected renewal ahead of expiry, got %s", renewAt.Sub(now))
+	}
+}
+
+func TestBlobAnnouncementsDeferBeyondTheBatch(t *testing.T) {
+	svc := newBlobTestService(t, newMockConfig(), "sender")
+	createBlobTestIdentity(t, svc, "sender")
+	startBlobNetworking(t, svc)
+	cleanupBlobNetworking(t, svc)
+	svc.blobAnnounce.configure(blobAnnounceConfig{BatchSize: 2, BatchInterval: time.Hour, ProviderTTL: time.Minute})
+
+	ids := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		meta, err := svc.PutAttachment(fmt.Sprintf("f%d.txt", i), "text/plain", base64.StdEncoding.EncodeToString([]byte("payload")))
+		if err != nil {
+			t.Fatalf("put attachment: %v", err)
+		}
+		ids = append(ids, meta.ID)
+	}
+	announced := func() int {
+		n := 0
+		for _, id := range ids {
+			if providers, _ := svc.ListBlobProviders(id); len(providers) > 0 {
+				n++
+			}
+		}
+		return n
+	}
+	if got := announced(); got != 2 {
+		t.Fatalf("expected only the first batch announced, got %d", got)
+	}
+	stats := svc.blobAnnouncementStats()
+	if stats["deferred"] != 2 || stats["queued"] != 4 {
+		t.Fatalf("expected two deferred and four queued (renewals included), got %v", stats)
+	}
+
+	svc.drainBlobAnnouncements(time.Now().Add(2 * time.Hour))
+	if got := announced(); got != 4 {
+		t.Fatalf("expected the deferred blobs in the next window, got %d", got)
+	}
+}
@@ -3,6 +3,7 @@ package daemonservice
 import (
 	"errors"
 	"strings"
+	"time"
 
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
 	"aim-chat/go-backend/pkg/models"
@@ -49,6 +50,10 @@ type blobNodePresetConfig struct {
 	HighWatermarkPercent       int
 	FullCapPercent             int
 	AggressiveTargetPercent    int
+	AnnounceBatchSize          int
+	AnnounceBatchIntervalSec   int
+	AnnounceJitterPercent      int
+	ProviderRecordTTLSec       int
 }
 
 var errInvalidBlobNodePreset = errors.New("invalid blob node preset")
@@ -87,6 +92,10 @@ func defaultBlobNodePresetProfile() blobNodePresetConfig {
 		HighWatermarkPercent:       90,
 		FullCapPercent:             100,
 		AggressiveTargetPercent:    75,
+		AnnounceBatchSize:          32,
+		AnnounceBatchIntervalSec:   5,
+		AnnounceJitterPercent:      20,
+		ProviderRecordTTLSec:       int(defaultBlobProviderTTL / time.Second),
 	}
 }
 
@@ -147,6 +156,8 @@ func buildBlobNodePresetConfig(preset blobNodePreset) blobNodePresetConfig {
 		cfg.FetchBandwidthKBps = 512
 		cfg.HighWatermarkPercent = 85
 		cfg.AggressiveTargetPercent = 65
+		cfg.AnnounceBatchSize = 8
+		cfg.AnnounceBatchIntervalSec = 10
 	case blobNodePresetCache:
 		cfg.ImageQuotaMB = 1024
 		cfg.FileQuotaMB = 4096
@@ -165,6 +176,7 @@ func buildBlobNodePresetConfig(preset blobNodePreset) blobNodePresetConfig {
 		cfg.FetchBandwidthKBps = 2048
 		cfg.HighWatermarkPercent = 90
 		cfg.AggressiveTargetPercent = 75
+		cfg.AnnounceBatchSize = 64
 	case blobNodePresetPin:
 		cfg.ImageQuotaMB = 2048
 		cfg.FileQuotaMB = 8192
@@ -183,6 +195,9 @@ func buildBlobNodePresetConfig(preset blobNodePreset) blobNodePresetConfig {
 		cfg.FetchBandwidthKBps = 4096
 		cfg.HighWatermarkPercent = 90
 		cfg.AggressiveTargetPercent = 80
+		// Pin nodes hold many long-lived blobs: bigger batches, fewer renewals.
+		cfg.AnnounceBatchSize = 128
+		cfg.ProviderRecordTTLSec = 900
 	}
 	return cfg
 }
@@ -220,6 +235,10 @@ func (s *Service) GetBlobNodePreset() models.BlobNodePresetConfig {
 		HighWatermarkPercent:       cfg.HighWatermarkPercent,
 		FullCapPercent:             cfg.FullCapPercent,
 		AggressiveTargetPercent:    cfg.AggressiveTargetPercent,
+		AnnounceBatchSize:          cfg.AnnounceBatchSize,
+		AnnounceBatchIntervalSec:   cfg.AnnounceBatchIntervalSec,
+		AnnounceJitterPercent:      cfg.AnnounceJitterPercent,
+		ProviderRecordTTLSec:       cfg.ProviderRecordTTLSec,
 	}
 }
 
@@ -242,6 +261,7 @@ func (s *Service) SetBlobNodePreset(rawPreset string) (models.BlobNodePresetConf
 	); err != nil {
 		return models.BlobNodePresetConfig{}, err
 	}
+	s.blobAnnounce.configure(blobAnnounceConfigFromPreset(cfg))
 	if err := s.SetBlobReplicationMode(cfg.ReplicationMode); err != nil {
 		return models.BlobNodePresetConfig{}, err
 	}
@@ -0,0 +1,78 @@
+package daemonservice
+
+import (
+	"errors"
+
+	"aim-chat/go-backend/internal/bootstrap/bootstrapmanager"
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// bootstrapRanking merges the transport's latest bootstrap probes into the
+// persisted ranking. measured is false when the transport probed nothing.
+func (s *Service) bootstrapRanking() (ranking bootstrapmanager.Ranking, measured bool, err error) {
+	ranking, err = bootstrapmanager.LoadRanking(s.wakuCfg.BootstrapRankingPath)
+	if err != nil {
+		return bootstrapmanager.Ranking{}, false, err
+	}
+	prober, ok := s.wakuNode.(interface{ BootstrapProbes() []waku.BootstrapProbe })
+	if !ok {
+		return ranking, false, nil
+	}
+	probes := prober.BootstrapProbes()
+	if len(probes) == 0 {
+		return ranking, false, nil
+	}
+	return ranking.RecordProbes(s.wakuCfg.BootstrapNodes, probes, s.now().UTC()), true, nil
+}
+
+// recordBootstrapLatencies persists the latest bootstrap node measurements
+// so the next startup dials the fastest healthy nodes first.
+func (s *Service) recordBootstrapLatencies() {
+	if s.wakuCfg == nil || s.wakuCfg.BootstrapRankingPath == "" {
+		return
+	}
+	ranking, measured, err := s.bootstrapRanking()
+	if err == nil && measured {
+		err = bootstrapmanager.SaveRanking(s.wakuCfg.BootstrapRankingPath, ranking)
+	}
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+	}
+}
+
+// GetBootstrapStats lists the bootstrap nodes in the order the next startup
+// will dial them, with their last measured latencies.
+func (s *Service) GetBootstrapStats() (models.BootstrapStats, error) {
+	if s.wakuCfg == nil {
+		return models.BootstrapStats{}, errors.New("bootstrap stats are not supported")
+	}
+	ranking, _, err := s.bootstrapRanking()
+	if err != nil {
+		return models.BootstrapStats{}, err
+	}
+	measured := make(map[string]bootstrapmanager.NodeLatency, len(ranking.Nodes))
+	for _, node := range ranking.Nodes {
+		measured[node.Address] = node
+	}
+	order := ranking.Order(s.wakuCfg.BootstrapNodes)
+	stats := models.BootstrapStats{
+		Source:    s.wakuCfg.BootstrapSource,
+		UpdatedAt: ranking.UpdatedAt,
+		Nodes:     make([]models.BootstrapNodeStats, 0, len(order)),
+	}
+	for i, addr := range order {
+		entry := models.BootstrapNodeStats{Address: addr, Rank: i + 1}
+		if node, ok := measured[addr]; ok {
+			entry.Measured = true
+			entry.Healthy = node.Healthy
+			entry.ConnectLatencyMS = node.ConnectLatencyMS
+			entry.PublishLatencyMS = node.PublishLatencyMS
+			entry.LastError = node.LastError
+			entry.MeasuredAt = node.MeasuredAt
+		}
+		stats.Nodes = append(stats.Nodes, entry)
+	}
+	return stats, nil
+}
@@ -0,0 +1,159 @@
+package daemonservice
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type capabilityAdvertiser interface {
+	SetAdvertisedCapabilities(capabilities []string) error
+}
+
+// capabilityCache remembers the features each contact device advertised on
+// its latest wire. It is kept in memory; until a contact writes again after
+// a restart, the capabilities from its card stand in.
+type capabilityCache struct {
+	mu      sync.Mutex
+	devices map[string]map[string]models.DeviceCapabilities
+}
+
+func newCapabilityCache() *capabilityCache {
+	return &capabilityCache{devices: map[string]map[string]models.DeviceCapabilities{}}
+}
+
+func (c *capabilityCache) observe(contactID, deviceID string, capabilities []string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	byDevice := c.devices[contactID]
+	if byDevice == nil {
+		byDevice = map[string]models.DeviceCapabilities{}
+		c.devices[contactID] = byDevice
+	}
+	byDevice[deviceID] = models.DeviceCapabilities{
+		DeviceID:     deviceID,
+		Capabilities: capabilities,
+		SeenAt:       now.UTC(),
+	}
+}
+
+func (c *capabilityCache) devicesOf(contactID string) []models.DeviceCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]models.DeviceCapabilities, 0, len(c.devices[contactID]))
+	for _, device := range c.devices[contactID] {
+		out = append(out, device)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeviceID < out[j].DeviceID })
+	return out
+}
+
+// localCapabilities lists the features this client can receive. Only
+// features the tree actually implements are advertised.
+func (s *Service) localCapabilities() []string {
+	capabilities := []string{identityapp.CapabilityHistoryBackfill}
+	if _, ok := s.wakuNode.(receiptChannelTransport); ok {
+		capabilities = append(capabilities, identityapp.CapabilityReceiptsChannel)
+	}
+	if s.wakuCfg != nil && s.wakuCfg.PairTopics {
+		capabilities = append(capabilities, identityapp.CapabilityPairTopics)
+	}
+	normalized, _ := identityapp.NormalizeCapabilities(capabilities)
+	return normalized
+}
+
+// advertiseCapabilities puts the local capabilities into cards signed from
+// now on.
+func (s *Service) advertiseCapabilities() {
+	advertiser, ok := s.identityManager.(capabilityAdvertiser)
+	if !ok {
+		return
+	}
+	if err := advertiser.SetAdvertisedCapabilities(s.localCapabilities()); err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+	}
+}
+
+// observeWireCapabilities caches the capabilities a contact device sent
+// along with a wire. Malformed lists are dropped rather than failing the
+// message they came with.
+func (s *Service) observeWireCapabilities(senderID string, wire contracts.WirePayload) {
+	capabilities, err := identityapp.NormalizeCapabilities(wire.Caps)
+	if err != nil || len(capabilities) == 0 {
+		return
+	}
+	deviceID := wire.SenderDeviceID
+	if wire.Device != nil {
+		deviceID = wire.Device.ID
+	}
+	s.peerCapabilities.observe(senderID, deviceID, capabilities, s.now())
+}
+
+// ContactCapabilities reports the features a contact advertised through its
+// card and the wires of each of its devices.
+func (s *Service) ContactCapabilities(contactID string) (models.ContactCapabilities, error) {
+	if !s.identityManager.HasContact(contactID) {
+		return models.ContactCapabilities{}, errors.New("contact not found")
+	}
+	return s.contactCapabilities(contactID), nil
+}
+
+func (s *Service) contactCapabilities(contactID string) models.ContactCapabilities {
+	result := models.ContactCapabilities{ContactID: contactID}
+	for _, contact := range s.identityManager.Contacts() {
+		if contact.ID == contactID {
+			result.Card = append([]string(nil), contact.Capabilities...)
+			break
+		}
+	}
+	result.Devices = s.peerCapabilities.devicesOf(contactID)
+	switch {
+	case len(result.Devices) > 0:
+		result.Effective = intersectCapabilities(result.Devices)
+	case len(result.Card) > 0:
+		result.Effective = append([]string(nil), result.Card...)
+	}
+	result.Known = len(result.Card) > 0 || len(result.Devices) > 0
+	if result.Effective == nil {
+		result.Effective = []string{}
+	}
+	return result
+}
+
+// contactSupports reports whether a feature may be used towards a contact.
+// Contacts that never advertised anything predate capability negotiation,
+// so the answer for them is fallback.
+func (s *Service) contactSupports(contactID, capability string, fallback bool) bool {
+	capabilities := s.contactCapabilities(contactID)
+	if !capabilities.Known {
+		return fallback
+	}
+	for _, name := range capabilities.Effective {
+		if name == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectCapabilities(devices []models.DeviceCapabilities) []string {
+	counts := map[string]int{}
+	for _, device := range devices {
+		for _, name := range device.Capabilities {
+			counts[name]++
+		}
+	}
+	out := make([]string, 0, len(counts))
+	for name, count := range counts {
+		if count == len(devices) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
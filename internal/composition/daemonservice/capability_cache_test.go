@@ -0,0 +1,69 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactCapabilitiesFromCardAndWires(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new service alice: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new service bob: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	if got := strings.Join(bobCard.Capabilities, ","); got != "history_backfill,receipts_channel" {
+		t.Fatalf("unexpected advertised capabilities: %q", got)
+	}
+	if _, err := alice.ContactCapabilities(bobCard.IdentityID); err == nil {
+		t.Fatal("expected an error for an unknown contact")
+	}
+	mustAddContactCard(t, alice, bobCard)
+
+	caps, err := alice.ContactCapabilities(bobCard.IdentityID)
+	if err != nil {
+		t.Fatalf("contact capabilities: %v", err)
+	}
+	if !caps.Known || strings.Join(caps.Effective, ",") != "history_backfill,receipts_channel" {
+		t.Fatalf("expected card capabilities to apply, got %+v", caps)
+	}
+
+	alice.observeWireCapabilities(bobCard.IdentityID, contracts.WirePayload{
+		Device: &models.Device{ID: "dev-1"},
+		Caps:   []string{"history_backfill", "receipts_channel", "future_feature"},
+	})
+	alice.observeWireCapabilities(bobCard.IdentityID, contracts.WirePayload{
+		SenderDeviceID: "dev-2",
+		Caps:           []string{"history_backfill"},
+	})
+	caps, _ = alice.ContactCapabilities(bobCard.IdentityID)
+	if len(caps.Devices) != 2 || strings.Join(caps.Effective, ",") != "history_backfill" {
+		t.Fatalf("expected the device intersection to win, got %+v", caps)
+	}
+	if alice.contactSupports(bobCard.IdentityID, identityapp.CapabilityReceiptsChannel, true) {
+		t.Fatal("receipts channel must not be selected while a device lacks it")
+	}
+	if !alice.contactSupports("aim1unknown", identityapp.CapabilityReceiptsChannel, true) {
+		t.Fatal("contacts without advertisements must use the fallback")
+	}
+	if alice.contactSupports("aim1unknown", identityapp.CapabilityReceiptsChannel, false) {
+		t.Fatal("receipts must stay inline for contacts that never advertised the channel")
+	}
+}
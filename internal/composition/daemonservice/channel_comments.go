@@ -0,0 +1,146 @@
+package daemonservice
+
+import (
+	"context"
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// SetChannelComments turns per-post comment threads on or off and sends the
+// change to the subscribers, who enforce it on inbound replies.
+func (s *Service) SetChannelComments(groupID string, enabled bool) (groupdomain.Group, error) {
+	group, event, err := s.groupCore.SetChannelComments(groupID, enabled)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return group, nil
+}
+
+func (s *Service) SetGroupThreadLocked(groupID, threadID string, locked bool) (groupdomain.Group, error) {
+	group, event, err := s.groupCore.SetGroupThreadLocked(groupID, threadID, locked)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return group, nil
+}
+
+// RemoveGroupMessage takes a message down for every member. The local copy
+// goes when the event is applied here; members drop theirs on receipt.
+func (s *Service) RemoveGroupMessage(groupID, messageID string) error {
+	event, err := s.groupCore.RemoveGroupMessage(groupID, messageID)
+	if err != nil {
+		return err
+	}
+	s.distributeGroupEvent(event, nil)
+	return nil
+}
+
+// removeModeratedGroupMessageLocked drops the local copy of a message taken
+// down by a moderator. Every member stores a group message under the id
+// derived from its event and the member's own id. The caller holds the group
+// state lock.
+func (s *Service) removeModeratedGroupMessageLocked(event groupdomain.GroupEvent) {
+	selfID := strings.TrimSpace(s.identityManager.GetIdentity().ID)
+	messageID := groupdomain.DeriveRecipientMessageID(event.MessageEventID, selfID)
+	msg, ok := s.messageStore.GetMessage(messageID)
+	if !ok || msg.ConversationType != models.ConversationTypeGroup || msg.ConversationID != event.GroupID {
+		return
+	}
+	deleted, err := s.messageStore.DeleteMessage(msg.ContactID, messageID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	if !deleted {
+		return
+	}
+	s.notify("notify.group.message.deleted", map[string]any{
+		"group_id":   event.GroupID,
+		"message_id": messageID,
+		"removed_by": event.ActorID,
+	})
+}
+
+// SendGroupMessage passes the post through the outbound plugins first.
+func (s *Service) SendGroupMessage(ctx context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error) {
+	content, err := s.processOutboundDraft(ctx, groupID, models.ConversationTypeGroup, "", content)
+	if err != nil {
+		return groupdomain.GroupMessageFanoutResult{}, err
+	}
+	return s.groupCore.SendGroupMessage(ctx, groupID, content)
+}
+
+// SendGroupMessageInThread posts a reply and follows its thread, so the
+// author hears about later replies.
+func (s *Service) SendGroupMessageInThread(ctx context.Context, groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error) {
+	content, err := s.processOutboundDraft(ctx, groupID, models.ConversationTypeGroup, threadID, content)
+	if err != nil {
+		return groupdomain.GroupMessageFanoutResult{}, err
+	}
+	result, err := s.groupCore.SendGroupMessageInThread(ctx, groupID, content, threadID)
+	if result.EventID != "" {
+		if _, subErr := s.threadSubs.Set(result.GroupID, threadID, true); subErr != nil {
+			s.recordError(contracts.ErrorCategoryStorage, subErr)
+		}
+	}
+	return result, err
+}
+
+// SubscribeGroupThread follows a thread of a group the user belongs to and
+// returns the threads now followed in that group.
+func (s *Service) SubscribeGroupThread(groupID, threadID string) ([]string, error) {
+	return s.setGroupThreadSubscription(groupID, threadID, true)
+}
+
+func (s *Service) UnsubscribeGroupThread(groupID, threadID string) ([]string, error) {
+	return s.setGroupThreadSubscription(groupID, threadID, false)
+}
+
+func (s *Service) ListGroupThreadSubscriptions(groupID string) ([]string, error) {
+	group, err := s.groupCore.GetGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.threadSubs.List(group.ID), nil
+}
+
+func (s *Service) setGroupThreadSubscription(groupID, threadID string, subscribed bool) ([]string, error) {
+	group, err := s.groupCore.GetGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	threadID = strings.TrimSpace(threadID)
+	if threadID == "" {
+		return nil, groupdomain.ErrInvalidGroupThreadID
+	}
+	if _, err := s.threadSubs.Set(group.ID, threadID, subscribed); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return nil, err
+	}
+	return s.threadSubs.List(group.ID), nil
+}
+
+// notifyThreadReply tells the user about a reply from someone else in a
+// thread they follow, on top of the regular group message notification.
+func (s *Service) notifyThreadReply(groupID string, msg models.Message) {
+	if msg.ThreadID == "" || msg.ContentType == models.MessageContentTypeSystem {
+		return
+	}
+	if !s.threadSubs.IsSubscribed(groupID, msg.ThreadID) {
+		return
+	}
+	s.notify("notify.group.thread.reply", map[string]any{
+		"group_id":  groupID,
+		"thread_id": msg.ThreadID,
+		"message":   msg,
+	})
+}
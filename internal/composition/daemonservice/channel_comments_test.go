@@ -0,0 +1,126 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestRuntimeE2E_ChannelCommentThreads(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceCard.IdentityID, aliceCard.PublicKey, bob, bobCard.IdentityID, bobCard.PublicKey)
+
+	groupID := "group_channel_comments_e2e"
+	title := "[channel:public] Comments E2E"
+	members := []string{aliceCard.IdentityID, bobCard.IdentityID}
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, title, aliceCard.IdentityID, members), alice)
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, title, aliceCard.IdentityID, members), bob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	post, err := alice.SendGroupMessage(context.Background(), groupID, "channel-post")
+	if err != nil {
+		t.Fatalf("alice post: %v", err)
+	}
+	waitForGroupMessage(t, bob, groupID, "channel-post")
+	if _, err := bob.SendGroupMessageInThread(context.Background(), groupID, "early-comment", post.EventID); !errors.Is(err, groupdomain.ErrGroupPermissionDenied) {
+		t.Fatalf("comments must be off by default, got %v", err)
+	}
+
+	if _, err := bob.SetChannelComments(groupID, true); err == nil {
+		t.Fatal("subscribers must not toggle comments")
+	}
+	if _, err := alice.SetChannelComments(groupID, true); err != nil {
+		t.Fatalf("alice enable comments: %v", err)
+	}
+	waitForGroup(t, bob, groupID, func(group groupdomain.Group) bool { return group.CommentsEnabled })
+
+	if _, err := bob.SendGroupMessageInThread(context.Background(), groupID, "bob-comment", post.EventID); err != nil {
+		t.Fatalf("bob comment: %v", err)
+	}
+	if threads, err := bob.ListGroupThreadSubscriptions(groupID); err != nil || len(threads) != 1 || threads[0] != post.EventID {
+		t.Fatalf("commenting must follow the thread: threads=%v err=%v", threads, err)
+	}
+	waitForGroupMessage(t, alice, groupID, "bob-comment")
+
+	if _, err := alice.SetGroupThreadLocked(groupID, post.EventID, true); err != nil {
+		t.Fatalf("alice lock thread: %v", err)
+	}
+	waitForGroup(t, bob, groupID, func(group groupdomain.Group) bool { return group.IsThreadLocked(post.EventID) })
+	if _, err := bob.SendGroupMessageInThread(context.Background(), groupID, "late-comment", post.EventID); !errors.Is(err, groupdomain.ErrGroupThreadLocked) {
+		t.Fatalf("locked thread must reject comments, got %v", err)
+	}
+
+	comments, err := alice.ListGroupMessagesByThread(groupID, post.EventID, 10, 0)
+	if err != nil || len(comments) != 1 {
+		t.Fatalf("alice thread: comments=%v err=%v", comments, err)
+	}
+	if err := alice.RemoveGroupMessage(groupID, comments[0].ID); err != nil {
+		t.Fatalf("alice remove comment: %v", err)
+	}
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		remaining, err := bob.ListGroupMessagesByThread(groupID, post.EventID, 10, 0)
+		if err != nil {
+			t.Fatalf("bob thread: %v", err)
+		}
+		if len(remaining) == 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("removed comment was not dropped by the member")
+}
+
+func waitForGroup(t *testing.T, svc *Service, groupID string, ready func(groupdomain.Group) bool) {
+	t.Helper()
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		group, err := svc.GetGroup(groupID)
+		if err != nil {
+			t.Fatalf("get group %s: %v", groupID, err)
+		}
+		if ready(group) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("group %s did not reach the expected state", groupID)
+}
@@ -0,0 +1,96 @@
+package daemonservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type pendingEmergencyBroadcast struct {
+	GroupID     string
+	ContentHash [sha256.Size]byte
+	ExpiresAt   time.Time
+}
+
+// PrepareChannelEmergencyBroadcast checks that the user owns the channel and
+// issues the one-time token SendChannelEmergencyBroadcast requires, bound to
+// the exact content so the owner confirms what is about to go out.
+func (s *Service) PrepareChannelEmergencyBroadcast(groupID, content string) (groupdomain.EmergencyBroadcastConfirmation, error) {
+	groupID = strings.TrimSpace(groupID)
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return groupdomain.EmergencyBroadcastConfirmation{}, groupdomain.ErrInvalidGroupMessageContent
+	}
+	if err := s.groupCore.CheckChannelEmergencyBroadcast(groupID); err != nil {
+		return groupdomain.EmergencyBroadcastConfirmation{}, err
+	}
+	token, err := randomBase64URL(24)
+	if err != nil {
+		return groupdomain.EmergencyBroadcastConfirmation{}, err
+	}
+	now := s.now().UTC()
+	expiresAt := now.Add(groupdomain.EmergencyBroadcastConfirmationTTL)
+
+	s.emergencyMu.Lock()
+	for key, pending := range s.emergencyConfirms {
+		if !pending.ExpiresAt.After(now) {
+			delete(s.emergencyConfirms, key)
+		}
+	}
+	s.emergencyConfirms[token] = pendingEmergencyBroadcast{
+		GroupID:     groupID,
+		ContentHash: sha256.Sum256([]byte(content)),
+		ExpiresAt:   expiresAt,
+	}
+	s.emergencyMu.Unlock()
+
+	return groupdomain.EmergencyBroadcastConfirmation{
+		GroupID:   groupID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// SendChannelEmergencyBroadcast sends an emergency post once its
+// confirmation token checks out, and records it in the audit log.
+func (s *Service) SendChannelEmergencyBroadcast(ctx context.Context, groupID, content, token string) (groupdomain.GroupMessageFanoutResult, error) {
+	groupID = strings.TrimSpace(groupID)
+	content = strings.TrimSpace(content)
+	if !s.consumeEmergencyConfirmation(groupID, content, strings.TrimSpace(token)) {
+		return groupdomain.GroupMessageFanoutResult{}, groupdomain.ErrInvalidEmergencyConfirmation
+	}
+	result, err := s.groupCore.SendChannelEmergencyBroadcast(ctx, groupID, content)
+	if result.EventID != "" {
+		s.recordAudit(models.AuditKindEmergencyBroadcast,
+			"group_id", result.GroupID,
+			"event_id", result.EventID,
+			"recipients", strconv.Itoa(result.Attempted))
+	}
+	return result, err
+}
+
+// consumeEmergencyConfirmation redeems token for groupID and content. A
+// token is spent by any attempt, matching or not.
+func (s *Service) consumeEmergencyConfirmation(groupID, content, token string) bool {
+	if token == "" {
+		return false
+	}
+	now := s.now().UTC()
+	s.emergencyMu.Lock()
+	defer s.emergencyMu.Unlock()
+	pending, ok := s.emergencyConfirms[token]
+	if !ok {
+		return false
+	}
+	delete(s.emergencyConfirms, token)
+	hash := sha256.Sum256([]byte(content))
+	return pending.GroupID == groupID &&
+		subtle.ConstantTimeCompare(pending.ContentHash[:], hash[:]) == 1 &&
+		now.Before(pending.ExpiresAt)
+}
@@ -0,0 +1,64 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestChannelEmergencyBroadcastRequiresConfirmationAndIsAudited(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	channel := seededActiveGroupState("group_emergency_channel", "[channel:public] Alerts", self.ID, []string{self.ID})
+	plain := seededActiveGroupState("group_emergency_plain", "Plain", self.ID, []string{self.ID})
+	svc.groupRuntime.SetSnapshot(
+		map[string]groupdomain.GroupState{channel.Group.ID: channel, plain.Group.ID: plain},
+		map[string][]groupdomain.GroupEvent{channel.Group.ID: {}, plain.Group.ID: {}},
+	)
+	ctx := context.Background()
+
+	if _, err := svc.PrepareChannelEmergencyBroadcast(plain.Group.ID, "evacuate"); !errors.Is(err, groupdomain.ErrGroupNotChannel) {
+		t.Fatalf("expected not-channel error, got %v", err)
+	}
+	confirm, err := svc.PrepareChannelEmergencyBroadcast(channel.Group.ID, "evacuate")
+	if err != nil || confirm.Token == "" {
+		t.Fatalf("prepare emergency broadcast: %+v %v", confirm, err)
+	}
+	if _, err := svc.SendChannelEmergencyBroadcast(ctx, channel.Group.ID, "something else", confirm.Token); !errors.Is(err, groupdomain.ErrInvalidEmergencyConfirmation) {
+		t.Fatalf("expected content mismatch to be rejected, got %v", err)
+	}
+	if _, err := svc.SendChannelEmergencyBroadcast(ctx, channel.Group.ID, "evacuate", confirm.Token); !errors.Is(err, groupdomain.ErrInvalidEmergencyConfirmation) {
+		t.Fatalf("expected a spent token to be rejected, got %v", err)
+	}
+
+	confirm, err = svc.PrepareChannelEmergencyBroadcast(channel.Group.ID, "evacuate")
+	if err != nil {
+		t.Fatalf("prepare emergency broadcast again: %v", err)
+	}
+	result, err := svc.SendChannelEmergencyBroadcast(ctx, channel.Group.ID, "evacuate", confirm.Token)
+	if err != nil || result.EventID == "" {
+		t.Fatalf("send emergency broadcast: %+v %v", result, err)
+	}
+	msgs, err := svc.ListGroupMessages(channel.Group.ID, 10, 0)
+	if err != nil || len(msgs) != 1 || !msgs[0].Emergency {
+		t.Fatalf("expected one emergency post, got %+v %v", msgs, err)
+	}
+
+	page := svc.ListAuditEvents(models.AuditKindEmergencyBroadcast, 0, 0)
+	if page.Total != 1 || page.Events[0].Details["group_id"] != channel.Group.ID || page.Events[0].Details["event_id"] != result.EventID {
+		t.Fatalf("expected the broadcast in the audit log, got %+v", page)
+	}
+}
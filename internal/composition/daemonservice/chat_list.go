@@ -0,0 +1,85 @@
+package daemonservice
+
+import (
+	"sort"
+	"strings"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// ListChats returns the saved messages, direct and group conversations with
+// their synced flags and a language hint for client spellcheckers. Detection
+// reads only local message history.
+func (s *Service) ListChats() ([]models.ChatSummary, error) {
+	flags := make(map[string]models.ConversationFlags)
+	for _, entry := range s.ListConversationFlags() {
+		flags[entry.ConversationID] = entry
+	}
+	out := make([]models.ChatSummary, 0)
+	if selfID := s.identityManager.GetIdentity().ID; selfID != "" {
+		recent := s.messageStore.ListMessages(selfID, 0, 0)
+		out = append(out, buildChatSummary(selfID, models.ConversationTypeSaved, savedMessagesTitle, flags[selfID], recent))
+	}
+	for _, contact := range s.identityManager.Contacts() {
+		recent := s.messageStore.ListMessages(contact.ID, 0, 0)
+		out = append(out, buildChatSummary(contact.ID, models.ConversationTypeDirect, contact.DisplayName, flags[contact.ID], recent))
+	}
+	groups, err := s.groupCore.ListGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		recent := s.messageStore.ListMessagesByConversation(group.ID, models.ConversationTypeGroup, 0, 0)
+		out = append(out, buildChatSummary(group.ID, models.ConversationTypeGroup, group.Title, flags[group.ID], recent))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Pinned != out[j].Pinned {
+			return out[i].Pinned
+		}
+		return out[i].ConversationID < out[j].ConversationID
+	})
+	return out, nil
+}
+
+// SetChatLanguage pins the language hint of a conversation; an empty
+// language returns it to local detection. The choice syncs to our devices.
+func (s *Service) SetChatLanguage(conversationID, language string) (models.ConversationFlags, error) {
+	normalized := ""
+	if strings.TrimSpace(language) != "" {
+		tag, err := messagingapp.NormalizeLanguageTag(language)
+		if err != nil {
+			return models.ConversationFlags{}, err
+		}
+		normalized = tag
+	}
+	return s.SetConversationFlags(conversationID, models.ConversationFlagsUpdate{Language: &normalized})
+}
+
+func buildChatSummary(conversationID, conversationType, title string, flags models.ConversationFlags, messages []models.Message) models.ChatSummary {
+	if len(messages) > messagingapp.LanguageDetectionSampleSize {
+		messages = messages[len(messages)-messagingapp.LanguageDetectionSampleSize:]
+	}
+	texts := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if msg.ContentType != "text" {
+			continue
+		}
+		texts = append(texts, string(msg.Content))
+	}
+	language, source := messagingapp.ResolveChatLanguage(flags.Language, texts)
+	return models.ChatSummary{
+		ConversationID:   conversationID,
+		ConversationType: conversationType,
+		Title:            title,
+		Language:         language,
+		LanguageSource:   source,
+		Pinned:           flags.Pinned,
+		Archived:         flags.Archived,
+		Muted:            flags.Muted,
+		Draft:            flags.Draft,
+
+		NotificationSound:    flags.NotificationSound,
+		NotificationPriority: flags.NotificationPriority,
+	}
+}
@@ -0,0 +1,79 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestListChatsReportsDetectedAndManualLanguage(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	for i, text := range []string{"Привет! Как дела?", "Всё хорошо, спасибо. Завтра увидимся?"} {
+		if err := alice.messageStore.SaveMessage(models.Message{
+			ID:          "msg-" + string(rune('a'+i)),
+			ContactID:   bobCard.IdentityID,
+			Content:     []byte(text),
+			ContentType: "text",
+			Direction:   "in",
+			Timestamp:   time.Now().Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+	}
+
+	chat := findChat(t, alice, bobCard.IdentityID)
+	if chat.Language != "ru" || chat.LanguageSource != models.ChatLanguageSourceDetected || chat.Title != "Bob" {
+		t.Fatalf("expected detected russian chat, got %+v", chat)
+	}
+
+	if _, err := alice.SetChatLanguage(bobCard.IdentityID, "uk"); err != nil {
+		t.Fatalf("set chat language: %v", err)
+	}
+	chat = findChat(t, alice, bobCard.IdentityID)
+	if chat.Language != "uk" || chat.LanguageSource != models.ChatLanguageSourceManual {
+		t.Fatalf("expected manual ukrainian hint, got %+v", chat)
+	}
+	if _, err := alice.SetChatLanguage(bobCard.IdentityID, "not a tag"); err == nil {
+		t.Fatal("invalid language tag must be rejected")
+	}
+
+	if _, err := alice.SetChatLanguage(bobCard.IdentityID, ""); err != nil {
+		t.Fatalf("clear chat language: %v", err)
+	}
+	if chat = findChat(t, alice, bobCard.IdentityID); chat.LanguageSource != models.ChatLanguageSourceDetected {
+		t.Fatalf("clearing the hint must fall back to detection, got %+v", chat)
+	}
+}
+
+func findChat(t *testing.T, svc *Service, conversationID string) models.ChatSummary {
+	t.Helper()
+	chats, err := svc.ListChats()
+	if err != nil {
+		t.Fatalf("list chats: %v", err)
+	}
+	for _, chat := range chats {
+		if chat.ConversationID == conversationID {
+			return chat
+		}
+	}
+	t.Fatalf("chat %s not listed in %+v", conversationID, chats)
+	return models.ChatSummary{}
+}
@@ -0,0 +1,234 @@
+package daemonservice
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/platform/imaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	attachmentThumbnailPath     = "/thumbnails/attachments/"
+	attachmentThumbnailMaxSide  = 320
+	attachmentThumbnailMaxBytes = 64 << 10
+	// attachmentThumbnailCacheSize bounds the decoded previews kept in memory
+	// so scrolling a gallery does not decode full-size images again.
+	attachmentThumbnailCacheSize = 256
+)
+
+var (
+	errChatMediaUnsupported   = errors.New("chat media listing is not supported")
+	errInvalidChatMediaClass  = errors.New("invalid media class")
+	errAttachmentNotThumbable = errors.New("attachment has no thumbnail")
+)
+
+// attachmentThumbnailCache keeps recent previews in insertion order. Blobs are
+// immutable, so entries never go stale; they are only evicted.
+type attachmentThumbnailCache struct {
+	mu    sync.Mutex
+	byID  map[string]models.AttachmentThumbnail
+	order []string
+}
+
+func newAttachmentThumbnailCache() *attachmentThumbnailCache {
+	return &attachmentThumbnailCache{byID: map[string]models.AttachmentThumbnail{}}
+}
+
+func (c *attachmentThumbnailCache) get(blobID string) (models.AttachmentThumbnail, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thumb, ok := c.byID[blobID]
+	return thumb, ok
+}
+
+func (c *attachmentThumbnailCache) put(thumb models.AttachmentThumbnail) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.byID[thumb.BlobID]; ok {
+		return
+	}
+	if len(c.order) >= attachmentThumbnailCacheSize {
+		delete(c.byID, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.byID[thumb.BlobID] = thumb
+	c.order = append(c.order, thumb.BlobID)
+}
+
+func (c *attachmentThumbnailCache) reset() {
+	c.mu.Lock()
+	c.byID = map[string]models.AttachmentThumbnail{}
+	c.order = nil
+	c.mu.Unlock()
+}
+
+// ListChatMedia returns the attachment-bearing messages of a direct chat or
+// group, newest first, for a shared media gallery. class is "image", "file"
+// or empty for both.
+func (s *Service) ListChatMedia(conversationID, class string, limit, offset int) ([]models.ChatMediaItem, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return nil, errors.New("conversation id is required")
+	}
+	mediaClass := models.AttachmentClass(strings.ToLower(strings.TrimSpace(class)))
+	switch mediaClass {
+	case "", models.AttachmentClassImage, models.AttachmentClassFile:
+	default:
+		return nil, errInvalidChatMediaClass
+	}
+	lister, ok := s.messageStore.(interface {
+		ListMediaMessages(conversationID, conversationType string, class models.AttachmentClass, limit, offset int) []models.Message
+	})
+	if !ok {
+		return nil, errChatMediaUnsupported
+	}
+	conversationType := models.ConversationTypeDirect
+	if _, err := s.groupCore.GetGroup(conversationID); err == nil {
+		conversationType = models.ConversationTypeGroup
+	}
+	messages := lister.ListMediaMessages(conversationID, conversationType, mediaClass, limit, offset)
+	items := make([]models.ChatMediaItem, 0, len(messages))
+	for _, msg := range messages {
+		items = append(items, buildChatMediaItem(msg, mediaClass))
+	}
+	return items, nil
+}
+
+func buildChatMediaItem(msg models.Message, class models.AttachmentClass) models.ChatMediaItem {
+	item := models.ChatMediaItem{
+		MessageID:        msg.ID,
+		ConversationID:   msg.ConversationID,
+		ConversationType: msg.ConversationType,
+		ContactID:        msg.ContactID,
+		ThreadID:         msg.ThreadID,
+		Direction:        msg.Direction,
+		Timestamp:        msg.Timestamp,
+		Attachments:      make([]models.ChatMediaAttachment, 0, len(msg.Attachments)),
+	}
+	for _, attachment := range msg.Attachments {
+		attachmentClass := models.ClassifyAttachmentMime(attachment.MimeType)
+		if class != "" && attachmentClass != class {
+			continue
+		}
+		entry := models.ChatMediaAttachment{MessageAttachment: attachment, Class: attachmentClass}
+		if attachmentClass == models.AttachmentClassImage {
+			entry.ThumbnailURL = attachmentThumbnailPath + attachment.ID
+		}
+		item.Attachments = append(item.Attachments, entry)
+	}
+	return item
+}
+
+// GetAttachmentThumbnail returns a small JPEG preview of a locally stored
+// image attachment.
+func (s *Service) GetAttachmentThumbnail(blobID string) (models.AttachmentThumbnail, error) {
+	blobID = strings.TrimSpace(blobID)
+	if blobID == "" {
+		return models.AttachmentThumbnail{}, errors.New("blob id is required")
+	}
+	if thumb, ok := s.thumbnails.get(blobID); ok {
+		return thumb, nil
+	}
+	if thumb, ok := s.storedAttachmentThumbnail(blobID); ok {
+		s.thumbnails.put(thumb)
+		return thumb, nil
+	}
+	meta, data, err := s.attachmentStore.Get(blobID)
+	if err != nil {
+		return models.AttachmentThumbnail{}, err
+	}
+	if models.ClassifyAttachmentMime(meta.MimeType) != models.AttachmentClassImage {
+		return models.AttachmentThumbnail{}, errAttachmentNotThumbable
+	}
+	preview, err := imaging.Thumbnail(data, attachmentThumbnailMaxSide, attachmentThumbnailMaxBytes)
+	if err != nil {
+		return models.AttachmentThumbnail{}, errAttachmentNotThumbable
+	}
+	thumb := models.AttachmentThumbnail{
+		BlobID:   blobID,
+		MimeType: imaging.ThumbnailMimeType,
+		Width:    preview.Width,
+		Height:   preview.Height,
+		Data:     preview.Data,
+	}
+	s.thumbnails.put(thumb)
+	return thumb, nil
+}
+
+// storeAttachmentThumbnail keeps a preview of a newly committed image as its
+// own blob and links it from the image's metadata, so listing a chat never
+// needs the full image. Attachments that are not images, or cannot be
+// decoded, are returned unchanged.
+func (s *Service) storeAttachmentThumbnail(meta models.AttachmentMeta) models.AttachmentMeta {
+	if models.ClassifyAttachmentMime(meta.MimeType) != models.AttachmentClassImage {
+		return meta
+	}
+	linker, ok := s.attachmentStore.(interface {
+		SetThumbnailID(id, thumbnailID string) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		return meta
+	}
+	_, data, err := s.attachmentStore.Get(meta.ID)
+	if err != nil {
+		return meta
+	}
+	preview, err := imaging.Thumbnail(data, attachmentThumbnailMaxSide, attachmentThumbnailMaxBytes)
+	if err != nil {
+		return meta
+	}
+	stored, err := s.attachmentStore.Put("thumbnail-"+meta.ID+".jpg", imaging.ThumbnailMimeType, preview.Data)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return meta
+	}
+	linked, err := linker.SetThumbnailID(meta.ID, stored.ID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return meta
+	}
+	s.thumbnails.put(models.AttachmentThumbnail{
+		BlobID:   meta.ID,
+		MimeType: imaging.ThumbnailMimeType,
+		Width:    preview.Width,
+		Height:   preview.Height,
+		Data:     preview.Data,
+	})
+	return linked
+}
+
+// storedAttachmentThumbnail reads the preview blob linked from an image's
+// metadata. It reports false when there is none or it has been evicted, and
+// the caller falls back to downscaling the image.
+func (s *Service) storedAttachmentThumbnail(blobID string) (models.AttachmentThumbnail, bool) {
+	reader, ok := s.attachmentStore.(interface {
+		Meta(id string) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		return models.AttachmentThumbnail{}, false
+	}
+	meta, err := reader.Meta(blobID)
+	if err != nil || meta.ThumbnailID == "" {
+		return models.AttachmentThumbnail{}, false
+	}
+	_, data, err := s.attachmentStore.Get(meta.ThumbnailID)
+	if err != nil {
+		return models.AttachmentThumbnail{}, false
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.AttachmentThumbnail{}, false
+	}
+	return models.AttachmentThumbnail{
+		BlobID:   blobID,
+		MimeType: imaging.ThumbnailMimeType,
+		Width:    config.Width,
+		Height:   config.Height,
+		Data:     data,
+	}, true
+}
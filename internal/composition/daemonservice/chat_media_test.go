@@ -0,0 +1,136 @@
+package daemonservice
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestListChatMediaWithThumbnails(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "node"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 1200, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 1200; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	photo, err := svc.attachmentStore.Put("photo.png", "image/png", buf.Bytes())
+	if err != nil {
+		t.Fatalf("put photo: %v", err)
+	}
+	doc, err := svc.attachmentStore.Put("notes.txt", "text/plain", []byte("hello"))
+	if err != nil {
+		t.Fatalf("put doc: %v", err)
+	}
+	msg := models.Message{
+		ID:        "m1",
+		ContactID: "alice",
+		Direction: "in",
+		Timestamp: time.Now().UTC(),
+		Attachments: []models.MessageAttachment{
+			{ID: photo.ID, Name: photo.Name, MimeType: photo.MimeType, Size: photo.Size},
+			{ID: doc.ID, Name: doc.Name, MimeType: doc.MimeType, Size: doc.Size},
+		},
+	}
+	if err := svc.messageStore.SaveMessage(msg); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+
+	items, err := svc.ListChatMedia("alice", "image", 10, 0)
+	if err != nil {
+		t.Fatalf("list chat media: %v", err)
+	}
+	if len(items) != 1 || len(items[0].Attachments) != 1 {
+		t.Fatalf("expected one image item, got %+v", items)
+	}
+	entry := items[0].Attachments[0]
+	if entry.ID != photo.ID || entry.Class != models.AttachmentClassImage || entry.ThumbnailURL != attachmentThumbnailPath+photo.ID {
+		t.Fatalf("unexpected image entry: %+v", entry)
+	}
+	if _, err := svc.ListChatMedia("alice", "video", 10, 0); err == nil {
+		t.Fatal("expected unknown class to be rejected")
+	}
+
+	thumb, err := svc.GetAttachmentThumbnail(photo.ID)
+	if err != nil {
+		t.Fatalf("thumbnail: %v", err)
+	}
+	if thumb.Width != attachmentThumbnailMaxSide || thumb.Height != attachmentThumbnailMaxSide/2 || len(thumb.Data) > attachmentThumbnailMaxBytes {
+		t.Fatalf("unexpected thumbnail: %dx%d %d bytes", thumb.Width, thumb.Height, len(thumb.Data))
+	}
+	if _, err := svc.GetAttachmentThumbnail(doc.ID); err == nil {
+		t.Fatal("expected files to have no thumbnail")
+	}
+}
+
+func TestCommittedImageUploadStoresThumbnail(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "node"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 400, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x40, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	upload, err := svc.InitAttachmentUpload("tall.png", "image/png", int64(buf.Len()), 1, 1<<20, "", "")
+	if err != nil {
+		t.Fatalf("init upload: %v", err)
+	}
+	if _, err := svc.PutAttachmentChunk(upload.UploadID, 0, base64.StdEncoding.EncodeToString(buf.Bytes()), ""); err != nil {
+		t.Fatalf("put chunk: %v", err)
+	}
+	meta, err := svc.CommitAttachmentUpload(upload.UploadID)
+	if err != nil {
+		t.Fatalf("commit upload: %v", err)
+	}
+	if meta.ThumbnailID == "" || meta.ThumbnailID == meta.ID {
+		t.Fatalf("expected a separate thumbnail blob, got %+v", meta)
+	}
+	thumbMeta, stored, err := svc.attachmentStore.Get(meta.ThumbnailID)
+	if err != nil || thumbMeta.MimeType != "image/jpeg" || len(stored) > attachmentThumbnailMaxBytes {
+		t.Fatalf("unexpected thumbnail blob: %+v (%d bytes) %v", thumbMeta, len(stored), err)
+	}
+
+	// A fresh cache must serve the stored blob rather than the full image.
+	svc.thumbnails.reset()
+	thumb, err := svc.GetAttachmentThumbnail(meta.ID)
+	if err != nil {
+		t.Fatalf("thumbnail: %v", err)
+	}
+	if thumb.BlobID != meta.ID || thumb.Width != attachmentThumbnailMaxSide/2 || thumb.Height != attachmentThumbnailMaxSide || !bytes.Equal(thumb.Data, stored) {
+		t.Fatalf("unexpected thumbnail: %+v", thumb)
+	}
+}
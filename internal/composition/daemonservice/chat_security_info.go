@@ -0,0 +1,100 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const securityAlertsPerContactLimit = 20
+
+func (s *Service) recordSecurityAlert(kind, contactID, message string) {
+	contactID = strings.TrimSpace(contactID)
+	if s == nil || s.securityAlertsMu == nil || contactID == "" {
+		return
+	}
+	alert := models.SecurityAlert{
+		Kind:       kind,
+		ContactID:  contactID,
+		Message:    sanitizeDiagnosticText(message),
+		OccurredAt: time.Now().UTC(),
+	}
+	s.securityAlertsMu.Lock()
+	defer s.securityAlertsMu.Unlock()
+	alerts := append(s.securityAlerts[contactID], alert)
+	if len(alerts) > securityAlertsPerContactLimit {
+		alerts = append([]models.SecurityAlert(nil), alerts[len(alerts)-securityAlertsPerContactLimit:]...)
+	}
+	s.securityAlerts[contactID] = alerts
+}
+
+func (s *Service) recentSecurityAlerts(contactID string) []models.SecurityAlert {
+	if s.securityAlertsMu == nil {
+		return []models.SecurityAlert{}
+	}
+	s.securityAlertsMu.Lock()
+	defer s.securityAlertsMu.Unlock()
+	return append([]models.SecurityAlert{}, s.securityAlerts[contactID]...)
+}
+
+// GetChatSecurityInfo reports the encryption health of a direct conversation:
+// session state, contact key pinning, known peer devices, recent alerts and
+// messages known to be missing.
+func (s *Service) GetChatSecurityInfo(contactID string) (models.ChatSecurityInfo, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return models.ChatSecurityInfo{}, errors.New("contact id is required")
+	}
+	if !s.identityManager.HasContact(contactID) {
+		return models.ChatSecurityInfo{}, errors.New("contact not found")
+	}
+	info := models.ChatSecurityInfo{
+		ContactID:       contactID,
+		ContactVerified: s.identityManager.HasVerifiedContact(contactID),
+		PeerDevices:     []models.PeerDeviceSecurity{},
+		RecentAlerts:    s.recentSecurityAlerts(contactID),
+		HistoryGaps:     s.historyGaps(contactID),
+	}
+
+	session, ok, err := s.sessionManager.GetSession(contactID)
+	if err != nil {
+		return models.ChatSecurityInfo{}, err
+	}
+	if ok {
+		info.E2EEActive = true
+		info.SessionID = session.SessionID
+		info.SessionCreatedAt = session.CreatedAt.UTC()
+		info.SendChainIndex = session.SendChainIndex
+		info.RecvChainIndex = session.RecvChainIndex
+		if !session.CreatedAt.IsZero() {
+			info.SessionAgeSeconds = int64(time.Since(session.CreatedAt).Seconds())
+		}
+	}
+
+	if lister, ok := s.identityManager.(interface {
+		PeerDevices(contactID string) []models.Device
+	}); ok {
+		for _, device := range lister.PeerDevices(contactID) {
+			state := "cross_signed"
+			switch {
+			case device.IsRevoked:
+				state = "revoked"
+			case len(device.SelfSigningKey) == 0:
+				state = "legacy"
+			}
+			if !device.IsRevoked {
+				info.PeerDeviceCount++
+			}
+			info.PeerDevices = append(info.PeerDevices, models.PeerDeviceSecurity{
+				DeviceID:          device.ID,
+				Name:              device.Name,
+				Fingerprint:       device.CertFingerprint,
+				VerificationState: state,
+				FirstSeenAt:       device.CreatedAt,
+			})
+		}
+	}
+	return info, nil
+}
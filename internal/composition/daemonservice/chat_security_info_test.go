@@ -0,0 +1,59 @@
+package daemonservice
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetChatSecurityInfoReportsSessionAndAlerts(t *testing.T) {
+	svc, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	peer, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new peer service: %v", err)
+	}
+	if _, _, err := peer.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create peer identity: %v", err)
+	}
+	card, err := peer.identityManager.SelfContactCard("peer")
+	if err != nil {
+		t.Fatalf("peer self card: %v", err)
+	}
+	if err := svc.identityManager.AddContact(card); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+
+	info, err := svc.GetChatSecurityInfo(card.IdentityID)
+	if err != nil {
+		t.Fatalf("security info before session: %v", err)
+	}
+	if info.E2EEActive || !info.ContactVerified || len(info.RecentAlerts) != 0 {
+		t.Fatalf("unexpected initial security info: %+v", info)
+	}
+
+	if _, err := svc.InitSession(card.IdentityID, bytes.Repeat([]byte{7}, 32)); err != nil {
+		t.Fatalf("init session: %v", err)
+	}
+	svc.notifySecurityAlert("contact_key_pin_mismatch", card.IdentityID, "pinned key changed")
+	svc.notifySecurityAlert("contact_key_pin_mismatch", "aim1other", "unrelated")
+
+	info, err = svc.GetChatSecurityInfo(card.IdentityID)
+	if err != nil {
+		t.Fatalf("security info after session: %v", err)
+	}
+	if !info.E2EEActive || info.SessionID == "" || info.SessionCreatedAt.IsZero() {
+		t.Fatalf("expected active session details, got %+v", info)
+	}
+	if len(info.RecentAlerts) != 1 || info.RecentAlerts[0].Kind != "contact_key_pin_mismatch" {
+		t.Fatalf("expected one conversation alert, got %+v", info.RecentAlerts)
+	}
+
+	if _, err := svc.GetChatSecurityInfo("aim1unknown"); err == nil {
+		t.Fatal("expected error for unknown contact")
+	}
+}
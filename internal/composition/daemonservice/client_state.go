@@ -0,0 +1,57 @@
+package daemonservice
+
+import (
+	"errors"
+
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	clientStateMaxValueBytesEnv = "AIM_CLIENT_STATE_MAX_VALUE_BYTES"
+	clientStateMaxEntriesEnv    = "AIM_CLIENT_STATE_MAX_ENTRIES_PER_NAMESPACE"
+	clientStateMaxTotalBytesEnv = "AIM_CLIENT_STATE_MAX_TOTAL_BYTES"
+)
+
+var errClientStateUnavailable = errors.New("client state store is not available")
+
+func newClientStateStoreFromEnv() *storage.ClientStateStore {
+	def := storage.DefaultClientStateQuota()
+	store := storage.NewClientStateStore()
+	store.SetQuota(storage.ClientStateQuota{
+		MaxNameBytes:           def.MaxNameBytes,
+		MaxValueBytes:          envIntWithFallback(clientStateMaxValueBytesEnv, def.MaxValueBytes),
+		MaxEntriesPerNamespace: envIntWithFallback(clientStateMaxEntriesEnv, def.MaxEntriesPerNamespace),
+		MaxTotalBytes:          envIntWithFallback(clientStateMaxTotalBytesEnv, def.MaxTotalBytes),
+	})
+	return store
+}
+
+func (s *Service) SetClientState(namespace, key, value string) (models.ClientStateEntry, error) {
+	if s.clientState == nil {
+		return models.ClientStateEntry{}, errClientStateUnavailable
+	}
+	return s.clientState.Set(namespace, key, value)
+}
+
+func (s *Service) GetClientState(namespace, key string) (models.ClientStateEntry, bool, error) {
+	if s.clientState == nil {
+		return models.ClientStateEntry{}, false, errClientStateUnavailable
+	}
+	entry, ok := s.clientState.Get(namespace, key)
+	return entry, ok, nil
+}
+
+func (s *Service) ListClientState(namespace string) ([]models.ClientStateEntry, error) {
+	if s.clientState == nil {
+		return nil, errClientStateUnavailable
+	}
+	return s.clientState.List(namespace), nil
+}
+
+func (s *Service) DeleteClientState(namespace, key string) (bool, error) {
+	if s.clientState == nil {
+		return false, errClientStateUnavailable
+	}
+	return s.clientState.Delete(namespace, key)
+}
@@ -0,0 +1,56 @@
+package daemonservice
+
+import (
+	"time"
+
+	"aim-chat/go-backend/internal/bootstrap/bootstrapmanager"
+	"aim-chat/go-backend/internal/waku"
+)
+
+// clockEstimate returns the transport's peer clock estimate, or an empty
+// estimate when the transport does not gather one.
+func (s *Service) clockEstimate() waku.ClockEstimate {
+	estimator, ok := s.wakuNode.(interface{ ClockEstimate() waku.ClockEstimate })
+	if !ok {
+		return waku.ClockEstimate{Confidence: waku.ClockConfidenceNone}
+	}
+	return estimator.ClockEstimate()
+}
+
+// annotateExpiry compares an expiry verdict taken with the local clock to the
+// verdict under the peers' clock. The local verdict always stands; the
+// returned log attributes let operators spot a skewed clock, and a dispute is
+// logged as a warning.
+func (s *Service) annotateExpiry(event string, expiresAt time.Time) []any {
+	check := s.clockEstimate().CheckExpiry(expiresAt, s.now())
+	attrs := []any{
+		"clock_offset_ms", check.Offset.Milliseconds(),
+		"clock_confidence", check.Confidence,
+		"peer_clock_expired", check.PeerExpired,
+	}
+	if check.Disputed() {
+		s.logger.Warn("expiry verdict disputed by peer clock",
+			append([]any{
+				"event_type", event,
+				"local_expired", check.LocalExpired,
+				"expires_at", expiresAt.UTC(),
+			}, attrs...)...,
+		)
+	}
+	return attrs
+}
+
+// annotateManifestExpiry checks the expiry verdict of the last bootstrap
+// manifest load against the peer clock.
+func (s *Service) annotateManifestExpiry() {
+	if s.bootstrapManager == nil {
+		return
+	}
+	if meta := s.bootstrapManager.GetManifestMeta(); meta != nil && s.bootstrapManager.GetBootstrapSource() == bootstrapmanager.SourceManifest {
+		s.annotateExpiry("bootstrap.manifest.accepted", meta.ExpiresAt)
+		return
+	}
+	if expiresAt := s.bootstrapManager.LastRejectedExpiry(); !expiresAt.IsZero() {
+		s.annotateExpiry("bootstrap.manifest.rejected", expiresAt)
+	}
+}
@@ -0,0 +1,160 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+)
+
+const (
+	// contactCardCheckInterval is how often the retry loop looks for cards
+	// that need refreshing.
+	contactCardCheckInterval = 10 * time.Minute
+	// contactCardRefreshAfter is the card age at which a fresh copy is
+	// requested from the contact.
+	contactCardRefreshAfter = 7 * 24 * time.Hour
+	// contactCardRequestRetry spaces repeated requests to a contact that has
+	// not answered yet.
+	contactCardRequestRetry = 24 * time.Hour
+	// contactCardStaleAfter flags contacts whose card could not be refreshed
+	// for this long.
+	contactCardStaleAfter = 30 * 24 * time.Hour
+	// contactCardReplyInterval limits how often a contact can make us sign
+	// and send our own card.
+	contactCardReplyInterval = 10 * time.Minute
+)
+
+// contactCardRefreshState tracks outstanding card requests and recent replies.
+// It is kept in memory; losing it only delays the next refresh.
+type contactCardRefreshState struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	requested map[string]time.Time
+	replied   map[string]time.Time
+}
+
+func newContactCardRefreshState() *contactCardRefreshState {
+	return &contactCardRefreshState{
+		requested: map[string]time.Time{},
+		replied:   map[string]time.Time{},
+	}
+}
+
+// refreshContactCards requests updated cards from verified contacts whose card
+// is getting old and flags the ones that stayed unrefreshed for too long.
+func (s *Service) refreshContactCards(ctx context.Context, now time.Time) {
+	state := s.cardRefresh
+	state.mu.Lock()
+	if !state.lastCheck.IsZero() && now.Sub(state.lastCheck) < contactCardCheckInterval {
+		state.mu.Unlock()
+		return
+	}
+	state.lastCheck = now
+	due := make([]string, 0)
+	for _, contact := range s.identityManager.Contacts() {
+		if !s.identityManager.HasVerifiedContact(contact.ID) {
+			continue
+		}
+		freshness := contact.CardRefreshedAt
+		if freshness.IsZero() {
+			freshness = contact.AddedAt
+		}
+		if now.Sub(freshness) < contactCardRefreshAfter {
+			continue
+		}
+		if last, ok := state.requested[contact.ID]; ok && now.Sub(last) < contactCardRequestRetry {
+			continue
+		}
+		state.requested[contact.ID] = now
+		due = append(due, contact.ID)
+	}
+	state.mu.Unlock()
+
+	for _, contactID := range due {
+		if err := s.sendContactCardWire(ctx, contactID, messagingapp.NewCardRequestWire()); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}
+
+	marked, err := s.identityCore.MarkStaleContactCards(now.Add(-contactCardStaleAfter))
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	for _, contactID := range marked {
+		s.notify("notify.contact.card_stale", map[string]any{
+			"contact_id": contactID,
+		})
+	}
+}
+
+// handleContactCardWire answers card requests from verified contacts and
+// applies card responses that match a request we sent, as well as username
+// claims pushed by the contact.
+func (s *Service) handleContactCardWire(senderID string, wire contracts.WirePayload) {
+	if !s.identityManager.HasVerifiedContact(senderID) {
+		return
+	}
+	now := time.Now()
+	state := s.cardRefresh
+	switch wire.Kind {
+	case messagingapp.WireKindCardRequest:
+		state.mu.Lock()
+		if last, ok := state.replied[senderID]; ok && now.Sub(last) < contactCardReplyInterval {
+			state.mu.Unlock()
+			return
+		}
+		state.replied[senderID] = now
+		state.mu.Unlock()
+		card, err := s.identityCore.SelfCardForRefresh()
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryCrypto, err)
+			return
+		}
+		ctx, err := s.networkContext(contracts.ErrorCategoryNetwork)
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+			return
+		}
+		if err := s.sendContactCardWire(ctx, senderID, messagingapp.NewCardResponseWire(card)); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	case messagingapp.WireKindCardResponse:
+		state.mu.Lock()
+		_, requested := state.requested[senderID]
+		delete(state.requested, senderID)
+		state.mu.Unlock()
+		if !requested || wire.Card == nil {
+			return
+		}
+		contact, changed, err := s.identityCore.ApplyContactCardRefresh(*wire.Card, now)
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryCrypto, err)
+			return
+		}
+		s.notify("notify.contact.card_refreshed", map[string]any{
+			"contact_id":   contact.ID,
+			"display_name": contact.DisplayName,
+			"card_name":    contact.CardName,
+			"changed":      changed,
+		})
+		s.verifyContactAttestationsAsync(*wire.Card)
+	case messagingapp.WireKindUsernameClaim:
+		s.handleUsernameClaim(senderID, wire.Card, now)
+	}
+}
+
+func (s *Service) sendContactCardWire(ctx context.Context, contactID string, wire contracts.WirePayload) error {
+	if ctx == nil {
+		return errors.New("networking is not started")
+	}
+	wireID, err := s.generateID("card")
+	if err != nil {
+		return err
+	}
+	return s.publishSignedWireWithContext(ctx, wireID, contactID, wire)
+}
@@ -0,0 +1,118 @@
+package daemonservice
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestRuntimeE2E_ContactCardRefreshAppliesRenamedCard(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+
+	baseDir := t.TempDir()
+	makeService := func(name string) *Service {
+		svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new service %s: %v", name, err)
+		}
+		return svc
+	}
+	alice := makeService("alice")
+	bob := makeService("bob")
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, err := alice.GetIdentity()
+	if err != nil {
+		t.Fatalf("alice identity: %v", err)
+	}
+	bobIdentity, err := bob.GetIdentity()
+	if err != nil {
+		t.Fatalf("bob identity: %v", err)
+	}
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	// Bob renames himself; the next card he shares advertises the new name.
+	if _, err := bob.SelfContactCard("Robert"); err != nil {
+		t.Fatalf("bob renamed card: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	alice.refreshContactCards(ctx, time.Now().Add(contactCardRefreshAfter+time.Hour))
+
+	deadline := time.Now().Add(5 * time.Second)
+	var contact models.Contact
+	for time.Now().Before(deadline) {
+		contact = findContact(alice, bobIdentity.ID)
+		if contact.CardName == "Robert" {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if contact.CardName != "Robert" || contact.DisplayName != "Robert" || contact.CardStale {
+		t.Fatalf("refreshed card was not applied: %+v", contact)
+	}
+}
+
+func TestContactCardRefreshMarksLongUnrefreshedContactsStale(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new service alice: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new service bob: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+
+	// Networking is not started, so the request cannot go out and the
+	// contact stays unrefreshed past the staleness threshold.
+	alice.refreshContactCards(context.Background(), time.Now().Add(contactCardStaleAfter+time.Hour))
+	if contact := findContact(alice, bobCard.IdentityID); !contact.CardStale {
+		t.Fatalf("expected contact to be flagged stale: %+v", contact)
+	}
+}
+
+func findContact(svc *Service, contactID string) models.Contact {
+	for _, contact := range svc.identityManager.Contacts() {
+		if contact.ID == contactID {
+			return contact
+		}
+	}
+	return models.Contact{}
+}
@@ -0,0 +1,117 @@
+package daemonservice
+
+import (
+	"sort"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	contactDormantAfterEnv     = "AIM_CONTACT_DORMANT_AFTER"
+	defaultContactDormantAfter = 90 * 24 * time.Hour
+)
+
+// contactActivity is what the message history says about reaching a
+// contact: the latest message received from it or delivered to it, and the
+// send times of the messages to it that failed.
+type contactActivity struct {
+	lastActivity time.Time
+	failedAt     []time.Time
+}
+
+// ListDormantContacts lists contacts that have been silent for the dormancy
+// period (AIM_CONTACT_DORMANT_AFTER) with every delivery since then failed,
+// longest silent first. A contact we simply stopped writing to is not
+// dormant.
+func (s *Service) ListDormantContacts() ([]models.DormantContact, error) {
+	contacts, err := s.GetContacts()
+	if err != nil {
+		return nil, err
+	}
+	activity := s.contactActivityByID()
+	cutoff := s.now().Add(-envDurationWithFallback(contactDormantAfterEnv, defaultContactDormantAfter))
+	out := make([]models.DormantContact, 0)
+	for _, contact := range contacts {
+		if contact.MergedInto != "" {
+			continue
+		}
+		seen := activity[contact.ID]
+		last := seen.lastActivity
+		if receipt := s.metrics.ContactDeliveryStats(contact.ID).LastReceiptAt; receipt.After(last) {
+			last = receipt
+		}
+		if contact.AddedAt.After(last) {
+			last = contact.AddedAt
+		}
+		if last.After(cutoff) {
+			continue
+		}
+		failed := 0
+		for _, at := range seen.failedAt {
+			if at.After(last) {
+				failed++
+			}
+		}
+		if failed == 0 {
+			continue
+		}
+		out = append(out, models.DormantContact{Contact: contact, LastActivityAt: last, FailedDeliveries: failed})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].LastActivityAt.Before(out[j].LastActivityAt)
+	})
+	return out, nil
+}
+
+// SuggestContactCleanup offers, for each dormant contact, archiving its
+// history unless that is already archived and removing it. Nothing is
+// changed until the user applies a suggestion.
+func (s *Service) SuggestContactCleanup() ([]models.ContactCleanupSuggestion, error) {
+	dormant, err := s.ListDormantContacts()
+	if err != nil {
+		return nil, err
+	}
+	archived := make(map[string]bool)
+	for _, flags := range s.ListConversationFlags() {
+		archived[flags.ConversationID] = flags.Archived
+	}
+	out := make([]models.ContactCleanupSuggestion, 0, len(dormant))
+	for _, contact := range dormant {
+		actions := make([]string, 0, 2)
+		if !archived[contact.ID] {
+			actions = append(actions, models.ContactCleanupArchiveHistory)
+		}
+		actions = append(actions, models.ContactCleanupRemoveContact)
+		out = append(out, models.ContactCleanupSuggestion{
+			ContactID:      contact.ID,
+			DisplayName:    contact.DisplayName,
+			LastActivityAt: contact.LastActivityAt,
+			Actions:        actions,
+		})
+	}
+	return out, nil
+}
+
+func (s *Service) contactActivityByID() map[string]contactActivity {
+	messages, _ := s.messageStore.Snapshot()
+	out := make(map[string]contactActivity)
+	for _, msg := range messages {
+		if msg.ConversationType == models.ConversationTypeGroup || msg.ContentType == models.MessageContentTypeSystem {
+			continue
+		}
+		seen := out[msg.ContactID]
+		switch {
+		case msg.Direction == "in", msg.Status == "delivered", msg.Status == "read":
+			if msg.Timestamp.After(seen.lastActivity) {
+				seen.lastActivity = msg.Timestamp
+			}
+		case msg.Status == "failed":
+			seen.failedAt = append(seen.failedAt, msg.Timestamp)
+		default:
+			continue
+		}
+		out[msg.ContactID] = seen
+	}
+	return out
+}
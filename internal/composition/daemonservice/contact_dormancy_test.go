@@ -0,0 +1,83 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestDormantContactsNeedSilenceAndFailedDeliveries(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	var contactIDs []string
+	for _, name := range []string{"bob", "carol"} {
+		peer, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new %s service: %v", name, err)
+		}
+		card, err := peer.SelfContactCard(name)
+		if err != nil {
+			t.Fatalf("%s card: %v", name, err)
+		}
+		mustAddContactCard(t, alice, card)
+		contactIDs = append(contactIDs, card.IdentityID)
+	}
+	bobID, carolID := contactIDs[0], contactIDs[1]
+
+	start := time.Now().UTC()
+	save := func(id, contactID, direction, status string, at time.Time) {
+		t.Helper()
+		msg := models.Message{ID: id, ContactID: contactID, Direction: direction, Status: status, ContentType: "text", Content: []byte("hi"), Timestamp: at}
+		if err := alice.messageStore.SaveMessage(msg); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+	}
+	save("m1", bobID, "in", "read", start)
+	save("m2", bobID, "out", "failed", start.Add(time.Hour))
+	// Carol only stopped hearing from us; nothing to her failed.
+	save("m3", carolID, "in", "read", start)
+	alice.now = func() time.Time { return start.Add(120 * 24 * time.Hour) }
+
+	dormant, err := alice.ListDormantContacts()
+	if err != nil {
+		t.Fatalf("list dormant contacts: %v", err)
+	}
+	if len(dormant) != 1 || dormant[0].ID != bobID || dormant[0].FailedDeliveries != 1 || !dormant[0].LastActivityAt.Equal(start) {
+		t.Fatalf("expected only bob to be dormant, got %+v", dormant)
+	}
+	suggestions, err := alice.SuggestContactCleanup()
+	if err != nil {
+		t.Fatalf("suggest cleanup: %v", err)
+	}
+	if len(suggestions) != 1 || len(suggestions[0].Actions) != 2 || suggestions[0].Actions[0] != models.ContactCleanupArchiveHistory {
+		t.Fatalf("expected archive and remove suggestions, got %+v", suggestions)
+	}
+
+	archived := true
+	if _, err := alice.SetConversationFlags(bobID, models.ConversationFlagsUpdate{Archived: &archived}); err != nil {
+		t.Fatalf("archive conversation: %v", err)
+	}
+	suggestions, err = alice.SuggestContactCleanup()
+	if err != nil {
+		t.Fatalf("suggest cleanup: %v", err)
+	}
+	if len(suggestions) != 1 || len(suggestions[0].Actions) != 1 || suggestions[0].Actions[0] != models.ContactCleanupRemoveContact {
+		t.Fatalf("expected only the remove suggestion, got %+v", suggestions)
+	}
+	if !alice.identityManager.HasContact(bobID) {
+		t.Fatal("suggestions must not remove anything")
+	}
+
+	save("m4", bobID, "out", "delivered", start.Add(100*24*time.Hour))
+	if dormant, err := alice.ListDormantContacts(); err != nil || len(dormant) != 0 {
+		t.Fatalf("expected a recent delivery to end dormancy, got %+v (%v)", dormant, err)
+	}
+}
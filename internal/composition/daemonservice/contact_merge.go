@@ -0,0 +1,22 @@
+package daemonservice
+
+import (
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// MergeContact moves a contact to the identity it rotated to. Later sends to
+// oldID go to newID, and the old thread stays readable but can't be edited.
+func (s *Service) MergeContact(oldID, newID string, transition models.IdentityTransition) (models.Contact, error) {
+	contact, err := s.identityCore.MergeContact(oldID, newID, transition, time.Now())
+	if err != nil {
+		return models.Contact{}, err
+	}
+	s.notify("notify.contact.merged", map[string]any{
+		"old_contact_id": transition.OldID,
+		"contact_id":     contact.ID,
+		"merged_from":    contact.MergedFrom,
+	})
+	return contact, nil
+}
@@ -0,0 +1,51 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// GetContactSharing returns the sharing profile in effect for a contact, or
+// the default profile for an empty id.
+func (s *Service) GetContactSharing(contactID string) (models.ContactSharingProfile, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return s.contactSharing.Default(), nil
+	}
+	return s.contactSharing.Get(contactID), nil
+}
+
+// SetContactSharing replaces the sharing profile of a contact, or the
+// default profile for an empty id. Setting Inherited puts the contact back
+// on the default profile.
+func (s *Service) SetContactSharing(profile models.ContactSharingProfile) (models.ContactSharingProfile, error) {
+	profile.ContactID = strings.TrimSpace(profile.ContactID)
+	profile.UpdatedAt = time.Now().UTC()
+	if profile.ContactID == "" {
+		if err := s.contactSharing.SetDefault(profile); err != nil {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+			return models.ContactSharingProfile{}, err
+		}
+		return s.contactSharing.Default(), nil
+	}
+	if !s.identityManager.HasContact(profile.ContactID) {
+		return models.ContactSharingProfile{}, errors.New("contact not found")
+	}
+	if err := s.contactSharing.Put(profile, profile.Inherited); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.ContactSharingProfile{}, err
+	}
+	return s.contactSharing.Get(profile.ContactID), nil
+}
+
+// sharingWith returns what a contact may learn about the user. Read
+// receipts are the only such signal the daemon sends itself; clients that
+// emit presence, typing or profile photo updates read the profile through
+// contact.sharing.get.
+func (s *Service) sharingWith(contactID string) models.SharingProfile {
+	return s.contactSharing.Get(contactID).SharingProfile
+}
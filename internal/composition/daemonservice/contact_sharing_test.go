@@ -0,0 +1,51 @@
+package daemonservice
+
+import (
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactSharingWithholdsReadReceipts(t *testing.T) {
+	svc, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	if _, err := svc.SetContactSharing(models.ContactSharingProfile{ContactID: "aim1_stranger"}); err == nil {
+		t.Fatal("profile for an unknown contact must be rejected")
+	}
+	if _, err := svc.SetContactSharing(models.ContactSharingProfile{SharingProfile: models.SharingProfile{Presence: true}}); err != nil {
+		t.Fatalf("set default profile: %v", err)
+	}
+	profile, err := svc.GetContactSharing("aim1_stranger")
+	if err != nil || !profile.Inherited || profile.ReadReceipts || !profile.Presence {
+		t.Fatalf("contacts without a profile must inherit the default: %+v %v", profile, err)
+	}
+
+	autoRead := func(id string) int {
+		msg := models.Message{ID: id, ContactID: "aim1_stranger", Content: []byte("hi"), ContentType: "text", Timestamp: time.Now().UTC(), Direction: "in", Status: "delivered"}
+		if err := svc.messageStore.SaveMessage(msg); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+		svc.applyAutoRead(&msg, msg.ContactID)
+		if msg.Status != "read" {
+			t.Fatalf("message must still be marked read locally: %+v", msg)
+		}
+		return svc.GetMetrics().ErrorCounters["network"]
+	}
+	// The stranger is no verified contact, so every receipt actually sent
+	// fails and shows up as a network error.
+	if errs := autoRead("msg-withheld"); errs != 0 {
+		t.Fatalf("read receipt must not be sent when sharing is off, got %d network errors", errs)
+	}
+	if _, err := svc.SetContactSharing(models.ContactSharingProfile{SharingProfile: models.SharingProfile{ReadReceipts: true}}); err != nil {
+		t.Fatalf("set default profile: %v", err)
+	}
+	if errs := autoRead("msg-shared"); errs != 1 {
+		t.Fatalf("read receipt must be attempted when sharing is on, got %d network errors", errs)
+	}
+}
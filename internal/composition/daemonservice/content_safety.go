@@ -0,0 +1,53 @@
+package daemonservice
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const safetyFeedStoreFile = "safety-feed.json"
+
+// configureContentSafety loads the feed signing keys from
+// AIM_SAFETY_FEED_KEYS ("key_id:base64,...") and the last applied feed.
+// Without keys, attachment and display-name checks still run but no link
+// feed can be applied.
+func (s *Service) configureContentSafety() error {
+	s.contentSafety = contentsafety.NewChecker(filepath.Join(s.dataDir, safetyFeedStoreFile))
+	s.safetyFeedKeys = nil
+	keysRaw := strings.TrimSpace(os.Getenv("AIM_SAFETY_FEED_KEYS"))
+	if keysRaw == "" {
+		return nil
+	}
+	keys, err := enrollmenttoken.ParseIssuerKeys(keysRaw)
+	if err != nil {
+		return err
+	}
+	s.safetyFeedKeys = keys
+	if err := s.contentSafety.Bootstrap(keys); err != nil {
+		s.logger.Warn("safety feed bootstrap failed, starting without link feed", "error", err.Error())
+	}
+	return nil
+}
+
+func (s *Service) ApplySafetyFeed(feed contentsafety.Feed) (models.SafetyFeedStatus, error) {
+	status, err := s.contentSafety.Apply(feed, s.safetyFeedKeys, time.Now())
+	if err != nil {
+		return models.SafetyFeedStatus{}, err
+	}
+	s.logger.Info("safety feed applied", "version", status.Version, "key_id", status.KeyID, "domains", status.DomainCount)
+	return status, nil
+}
+
+func (s *Service) GetSafetyFeedStatus() (models.SafetyFeedStatus, error) {
+	return s.contentSafety.Status(), nil
+}
+
+func (s *Service) safetyFlagsForMessage(msg models.Message) []models.SafetyFlag {
+	return s.contentSafety.CheckMessage(msg.Content, msg.ContentType, msg.Attachments)
+}
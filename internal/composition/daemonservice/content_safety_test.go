@@ -0,0 +1,77 @@
+package daemonservice
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestInboundRequestCarriesSafetyFlags(t *testing.T) {
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	t.Setenv("AIM_SAFETY_FEED_KEYS", "feed-k1:"+base64.StdEncoding.EncodeToString(pub))
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	dataDir := filepath.Join(t.TempDir(), "bob")
+	svc, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	identity, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(privacydomain.MessagePrivacyRequests)); err != nil {
+		t.Fatalf("requests mode: %v", err)
+	}
+	feed := contentsafety.Feed{
+		Version:  1,
+		IssuedAt: time.Now().UTC(),
+		KeyID:    "feed-k1",
+		Domains:  []string{"phish.example"},
+	}
+	feed.Signature = ed25519.Sign(prv, feed.SigningPayload())
+	if _, err := svc.ApplySafetyFeed(feed); err != nil {
+		t.Fatalf("apply feed: %v", err)
+	}
+
+	payload, err := json.Marshal(contracts.WirePayload{Kind: "plain", Plain: []byte("claim at https://phish.example/login")})
+	if err != nil {
+		t.Fatalf("marshal wire: %v", err)
+	}
+	svc.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+		ID:        "msg-phish",
+		SenderID:  "aim1stranger",
+		Recipient: identity.ID,
+		Payload:   payload,
+	})
+	thread := svc.snapshotRequestInbox()["aim1stranger"]
+	if len(thread) != 1 {
+		t.Fatalf("expected one request, got %d", len(thread))
+	}
+	flags := thread[0].SafetyFlags
+	if len(flags) != 1 || flags[0].Kind != contentsafety.FlagMaliciousLink || flags[0].Detail != "phish.example" {
+		t.Fatalf("unexpected safety flags: %+v", flags)
+	}
+
+	restarted, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("restart service: %v", err)
+	}
+	if status, _ := restarted.GetSafetyFeedStatus(); status.Version != 1 || status.DomainCount != 1 {
+		t.Fatalf("feed not restored after restart: %+v", status)
+	}
+}
@@ -0,0 +1,327 @@
+package daemonservice
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	conversationExportSchemaVersion = 1
+	exportConsentTokenTTL           = 5 * time.Minute
+	defaultExportConsentTTL         = 7 * 24 * time.Hour
+	maxExportConsentTTL             = 30 * 24 * time.Hour
+)
+
+var (
+	errExportConsentRequired     = errors.New("conversation export requires a valid consent token")
+	errExportPeerConsentRequired = errors.New("conversation export requires the contact's consent")
+	errExportConsentSignature    = errors.New("export consent signature is invalid")
+	errExportSignature           = errors.New("conversation export signature is invalid")
+	errExportContentHash         = errors.New("conversation export content hash does not match its messages")
+)
+
+type pendingConversationExport struct {
+	ContactID string
+	ExpiresAt time.Time
+}
+
+// PrepareConversationExport issues the one-time token ExportConversation
+// requires, so exporting plaintext always takes a deliberate second step.
+func (s *Service) PrepareConversationExport(contactID string) (models.ExportConsentToken, error) {
+	contactID = strings.TrimSpace(contactID)
+	if !s.identityManager.HasContact(contactID) {
+		return models.ExportConsentToken{}, errors.New("contact not found")
+	}
+	token, err := randomBase64URL(24)
+	if err != nil {
+		return models.ExportConsentToken{}, err
+	}
+	now := s.now().UTC()
+	expiresAt := now.Add(exportConsentTokenTTL)
+
+	s.exportMu.Lock()
+	for key, pending := range s.exportTokens {
+		if !pending.ExpiresAt.After(now) {
+			delete(s.exportTokens, key)
+		}
+	}
+	s.exportTokens[token] = pendingConversationExport{ContactID: contactID, ExpiresAt: expiresAt}
+	s.exportMu.Unlock()
+
+	return models.ExportConsentToken{ContactID: contactID, Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// GrantExportConsent signs our consent that contactID may export the
+// plaintext of our conversation and sends it to them. A zero ttlSeconds
+// uses the default validity.
+func (s *Service) GrantExportConsent(contactID string, ttlSeconds int64) (models.ExportConsent, error) {
+	contactID = strings.TrimSpace(contactID)
+	if !s.identityManager.HasContact(contactID) {
+		return models.ExportConsent{}, errors.New("contact not found")
+	}
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if ttlSeconds < 0 || ttl > maxExportConsentTTL {
+		return models.ExportConsent{}, errors.New("invalid export consent ttl")
+	}
+	if ttl == 0 {
+		ttl = defaultExportConsentTTL
+	}
+	ctx, err := s.networkContext("")
+	if err != nil {
+		return models.ExportConsent{}, err
+	}
+	selfID := s.identityManager.GetIdentity().ID
+	now := s.now().UTC()
+	consent, err := s.signExportConsent(models.ExportConsent{
+		GrantorID:  selfID,
+		ExporterID: contactID,
+		ContactID:  selfID,
+		GrantedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	})
+	if err != nil {
+		return models.ExportConsent{}, err
+	}
+	wireID, err := s.generateID("xconsent")
+	if err != nil {
+		return models.ExportConsent{}, err
+	}
+	wire := contracts.WirePayload{Kind: messagingapp.WireKindExportConsent, ExportConsent: &consent}
+	if err := s.publishSignedWireWithContext(ctx, wireID, contactID, wire); err != nil {
+		return models.ExportConsent{}, err
+	}
+	s.recordAudit(models.AuditKindExportConsentGranted, "contact_id", contactID,
+		"expires_at", consent.ExpiresAt.Format(time.RFC3339))
+	return consent, nil
+}
+
+// handleExportConsentWire keeps a contact's consent to our exporting the
+// conversation with them. It must be signed by the key pinned for the
+// contact and name us as the exporter.
+func (s *Service) handleExportConsentWire(senderID string, wire contracts.WirePayload) {
+	consent := wire.ExportConsent
+	if consent == nil || !s.identityManager.HasContact(senderID) {
+		return
+	}
+	selfID := s.identityManager.GetIdentity().ID
+	if consent.GrantorID != senderID || consent.ContactID != senderID || consent.ExporterID != selfID {
+		return
+	}
+	pinned, ok := s.identityManager.ContactPublicKey(senderID)
+	if !ok || consent.PublicKey != base64.StdEncoding.EncodeToString(pinned) {
+		s.recordError(contracts.ErrorCategoryCrypto, errExportConsentSignature)
+		return
+	}
+	if err := verifyExportConsent(*consent); err != nil {
+		s.recordError(contracts.ErrorCategoryCrypto, err)
+		return
+	}
+	if !consent.ExpiresAt.After(s.now()) {
+		return
+	}
+	s.exportMu.Lock()
+	s.peerExportConsents[senderID] = *consent
+	s.exportMu.Unlock()
+	s.notify("notify.export.consent_received", *consent)
+}
+
+// ExportConversation redeems a token from PrepareConversationExport for the
+// plaintext of the direct conversation with the contact. The bundle embeds
+// our consent, the contact's consent when one was received and is still
+// valid, and a hash of the messages, all signed with our identity key.
+func (s *Service) ExportConversation(req models.ConversationExportRequest) (models.ConversationExport, error) {
+	contactID := strings.TrimSpace(req.ContactID)
+	now := s.now().UTC()
+	if !s.consumeExportToken(contactID, strings.TrimSpace(req.Token), now) {
+		return models.ConversationExport{}, errExportConsentRequired
+	}
+	s.exportMu.Lock()
+	peerConsent, hasPeerConsent := s.peerExportConsents[contactID]
+	if hasPeerConsent && !peerConsent.ExpiresAt.After(now) {
+		delete(s.peerExportConsents, contactID)
+		hasPeerConsent = false
+	}
+	s.exportMu.Unlock()
+	if req.RequirePeerConsent && !hasPeerConsent {
+		return models.ConversationExport{}, errExportPeerConsentRequired
+	}
+
+	selfID := s.identityManager.GetIdentity().ID
+	localConsent, err := s.signExportConsent(models.ExportConsent{
+		GrantorID:  selfID,
+		ExporterID: selfID,
+		ContactID:  contactID,
+		GrantedAt:  now,
+		ExpiresAt:  now.Add(exportConsentTokenTTL),
+	})
+	if err != nil {
+		return models.ConversationExport{}, err
+	}
+	messages := s.messageStore.ListMessagesByConversation(contactID, models.ConversationTypeDirect, 0, 0)
+	contentHash, err := exportContentHash(messages)
+	if err != nil {
+		return models.ConversationExport{}, err
+	}
+	bundle := models.ConversationExport{
+		SchemaVersion: conversationExportSchemaVersion,
+		ExporterID:    selfID,
+		ContactID:     contactID,
+		ExportedAt:    now,
+		Messages:      messages,
+		ContentHash:   contentHash,
+		LocalConsent:  localConsent,
+		PublicKey:     localConsent.PublicKey,
+	}
+	if hasPeerConsent {
+		bundle.PeerConsent = &peerConsent
+	}
+	_, privateKey := s.identityManager.SnapshotIdentityKeys()
+	payload, err := conversationExportSigningBytes(bundle)
+	if err != nil {
+		return models.ConversationExport{}, err
+	}
+	bundle.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	s.recordAudit(models.AuditKindConversationExport, "contact_id", contactID,
+		"messages", strconv.Itoa(len(messages)), "peer_consent", strconv.FormatBool(hasPeerConsent))
+	return bundle, nil
+}
+
+// VerifyConversationExport checks a bundle from any exporter: its
+// signature, that the hash matches the messages, and that each consent is
+// signed by its grantor and covers this conversation at the export time.
+func (s *Service) VerifyConversationExport(bundle models.ConversationExport) error {
+	return verifyConversationExport(bundle)
+}
+
+// consumeExportToken redeems token for contactID. A token is spent by any
+// attempt, matching or not.
+func (s *Service) consumeExportToken(contactID, token string, now time.Time) bool {
+	if token == "" {
+		return false
+	}
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+	pending, ok := s.exportTokens[token]
+	if !ok {
+		return false
+	}
+	delete(s.exportTokens, token)
+	return pending.ContactID == contactID && now.Before(pending.ExpiresAt)
+}
+
+func (s *Service) signExportConsent(consent models.ExportConsent) (models.ExportConsent, error) {
+	publicKey, privateKey := s.identityManager.SnapshotIdentityKeys()
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return models.ExportConsent{}, errors.New("identity private key is unavailable")
+	}
+	consent.PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+	payload, err := exportConsentSigningBytes(consent)
+	if err != nil {
+		return models.ExportConsent{}, err
+	}
+	consent.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	return consent, nil
+}
+
+func verifyConversationExport(bundle models.ConversationExport) error {
+	if err := verifyExportSignature(bundle.ExporterID, bundle.PublicKey, bundle.Signature, func() ([]byte, error) {
+		return conversationExportSigningBytes(bundle)
+	}); err != nil {
+		return errExportSignature
+	}
+	if hash, err := exportContentHash(bundle.Messages); err != nil || hash != bundle.ContentHash {
+		return errExportContentHash
+	}
+	local := bundle.LocalConsent
+	if local.GrantorID != bundle.ExporterID || !exportConsentCovers(local, bundle) {
+		return messagingapp.ErrInvalidExportConsent
+	}
+	if err := verifyExportConsent(local); err != nil {
+		return err
+	}
+	if peer := bundle.PeerConsent; peer != nil {
+		if peer.GrantorID != bundle.ContactID || !exportConsentCovers(*peer, bundle) {
+			return messagingapp.ErrInvalidExportConsent
+		}
+		if err := verifyExportConsent(*peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportConsentCovers reports whether consent lets the bundle's exporter
+// export this conversation at the time it did.
+func exportConsentCovers(consent models.ExportConsent, bundle models.ConversationExport) bool {
+	return consent.ExporterID == bundle.ExporterID &&
+		consent.ContactID == bundle.ContactID &&
+		!bundle.ExportedAt.Before(consent.GrantedAt) &&
+		bundle.ExportedAt.Before(consent.ExpiresAt)
+}
+
+func verifyExportConsent(consent models.ExportConsent) error {
+	if err := messagingapp.ValidateExportConsent(&consent); err != nil {
+		return err
+	}
+	if err := verifyExportSignature(consent.GrantorID, consent.PublicKey, consent.Signature, func() ([]byte, error) {
+		return exportConsentSigningBytes(consent)
+	}); err != nil {
+		return errExportConsentSignature
+	}
+	return nil
+}
+
+// verifyExportSignature checks that publicKey belongs to identityID and made
+// signature over the payload.
+func verifyExportSignature(identityID, encodedKey, encodedSignature string, payload func() ([]byte, error)) error {
+	publicKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errExportSignature
+	}
+	if ok, err := identityapp.VerifyIdentityID(identityID, publicKey); err != nil || !ok {
+		return errExportSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return errExportSignature
+	}
+	data, err := payload()
+	if err != nil || !ed25519.Verify(publicKey, data, signature) {
+		return errExportSignature
+	}
+	return nil
+}
+
+func exportConsentSigningBytes(consent models.ExportConsent) ([]byte, error) {
+	consent.Signature = ""
+	return json.Marshal(consent)
+}
+
+func conversationExportSigningBytes(bundle models.ConversationExport) ([]byte, error) {
+	bundle.Signature = ""
+	return json.Marshal(bundle)
+}
+
+func exportContentHash(messages []models.Message) (string, error) {
+	if messages == nil {
+		messages = []models.Message{}
+	}
+	raw, err := json.Marshal(messages)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,109 @@
+package daemonservice
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestConversationExportNeedsTokenAndEmbedsPeerConsent(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	aliceID, bobID := aliceCard.IdentityID, bobCard.IdentityID
+
+	now := time.Now().UTC()
+	for i, text := range []string{"see you at 5", "confirmed"} {
+		msg := models.Message{ID: "m" + string(rune('1'+i)), ContactID: bobID, Direction: "in", Status: "read", ContentType: "text", Content: []byte(text), Timestamp: now.Add(time.Duration(i) * time.Second)}
+		if err := alice.messageStore.SaveMessage(msg); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+	}
+
+	token, err := alice.PrepareConversationExport(bobID)
+	if err != nil {
+		t.Fatalf("prepare export: %v", err)
+	}
+	req := models.ConversationExportRequest{ContactID: bobID, Token: token.Token, RequirePeerConsent: true}
+	if _, err := alice.ExportConversation(req); !errors.Is(err, errExportPeerConsentRequired) {
+		t.Fatalf("expected the contact's consent to be required, got %v", err)
+	}
+	if _, err := alice.ExportConversation(req); !errors.Is(err, errExportConsentRequired) {
+		t.Fatalf("expected the token to be spent, got %v", err)
+	}
+
+	deliver := func(consent models.ExportConsent) {
+		t.Helper()
+		wire := contracts.WirePayload{Kind: messagingapp.WireKindExportConsent, ExportConsent: &consent}
+		wmsg, err := messagingapp.ComposeSignedPrivateMessage("xconsent-"+consent.ExporterID[:8], aliceID, wire, bob.identityManager)
+		if err != nil {
+			t.Fatalf("compose consent: %v", err)
+		}
+		alice.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{ID: wmsg.ID, SenderID: wmsg.SenderID, Recipient: wmsg.Recipient, Payload: wmsg.Payload})
+	}
+	consent, err := bob.signExportConsent(models.ExportConsent{GrantorID: bobID, ExporterID: aliceID, ContactID: bobID, GrantedAt: now, ExpiresAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("sign consent: %v", err)
+	}
+	deliver(consent)
+	// A consent naming someone else as the exporter must not replace it.
+	misdirected, err := bob.signExportConsent(models.ExportConsent{GrantorID: bobID, ExporterID: bobID + "x", ContactID: bobID, GrantedAt: now, ExpiresAt: now.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("sign consent: %v", err)
+	}
+	deliver(misdirected)
+
+	token, err = alice.PrepareConversationExport(bobID)
+	if err != nil {
+		t.Fatalf("prepare export: %v", err)
+	}
+	req.Token = token.Token
+	bundle, err := alice.ExportConversation(req)
+	if err != nil {
+		t.Fatalf("export conversation: %v", err)
+	}
+	if len(bundle.Messages) != 2 || bundle.PeerConsent == nil || bundle.PeerConsent.Signature != consent.Signature || bundle.LocalConsent.GrantorID != aliceID {
+		t.Fatalf("expected both consents and both messages, got %+v", bundle)
+	}
+
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	var received models.ConversationExport
+	if err := json.Unmarshal(raw, &received); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+	if err := bob.VerifyConversationExport(received); err != nil {
+		t.Fatalf("expected the bundle to verify anywhere, got %v", err)
+	}
+	received.Messages[1].Content = []byte("cancelled")
+	if err := bob.VerifyConversationExport(received); err == nil {
+		t.Fatal("expected an edited message to fail verification")
+	}
+}
@@ -0,0 +1,224 @@
+package daemonservice
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	handoffStoreFile      = "handoffs.enc"
+	defaultHandoffTTL     = 24 * time.Hour
+	maxHandoffTTL         = 7 * 24 * time.Hour
+	defaultHandoffLimit   = 200
+	maxHandoffMessageSpan = 1000
+)
+
+var (
+	errHandoffConsentRequired = errors.New("handoff requires the account owner's consent")
+	errHandoffNotFound        = errors.New("handoff not found or expired")
+	errHandoffSignature       = errors.New("handoff signature is invalid")
+	errHandoffReadOnly        = errors.New("handoff is read-only")
+	errHandoffOwnAccount      = errors.New("handoff cannot be imported into the account that created it")
+)
+
+// CreateConversationHandoff snapshots the latest messages of a direct
+// conversation, signs them with the account identity and stores the bundle
+// where every account on the daemon can open it.
+func (s *Service) CreateConversationHandoff(req models.HandoffCreateRequest) (models.ConversationHandoff, error) {
+	if !req.Consent {
+		return models.ConversationHandoff{}, errHandoffConsentRequired
+	}
+	contactID := strings.TrimSpace(req.ContactID)
+	if !s.identityManager.HasContact(contactID) {
+		return models.ConversationHandoff{}, errors.New("contact not found")
+	}
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	if req.TTLSeconds < 0 || ttl > maxHandoffTTL || req.Limit < 0 || req.Limit > maxHandoffMessageSpan {
+		return models.ConversationHandoff{}, errors.New("invalid handoff ttl or limit")
+	}
+	if ttl == 0 {
+		ttl = defaultHandoffTTL
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultHandoffLimit
+	}
+	publicKey, privateKey := s.identityManager.SnapshotIdentityKeys()
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return models.ConversationHandoff{}, errors.New("identity private key is unavailable")
+	}
+	id, err := s.generateID("handoff")
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	now := s.now().UTC()
+	identityID := s.identityManager.GetIdentity().ID
+	handoff := models.ConversationHandoff{
+		ID:             id,
+		ContactID:      contactID,
+		ContactName:    s.contactDisplayName(contactID),
+		FromAccountID:  s.currentProfileID,
+		FromIdentityID: identityID,
+		ReadOnly:       req.ReadOnly,
+		Note:           strings.TrimSpace(req.Note),
+		Messages:       s.messageStore.ListMessages(contactID, limit, 0),
+		Consent:        models.HandoffConsent{AccountID: s.currentProfileID, IdentityID: identityID, GrantedAt: now},
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+		PublicKey:      base64.StdEncoding.EncodeToString(publicKey),
+	}
+	payload, err := handoffSigningBytes(handoff)
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	handoff.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	store, err := s.loadHandoffStoreLocked()
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	if err := store.Put(handoff, now); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.ConversationHandoff{}, err
+	}
+	s.recordAudit(models.AuditKindHandoffCreated, "handoff_id", handoff.ID, "contact_id", contactID,
+		"read_only", strconv.FormatBool(handoff.ReadOnly), "messages", strconv.Itoa(len(handoff.Messages)))
+	return handoff, nil
+}
+
+// OpenConversationHandoff returns a handoff after checking its signature
+// and records the open on the handoff and in the opener's audit log.
+func (s *Service) OpenConversationHandoff(handoffID string) (models.ConversationHandoff, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	handoff, err := s.accessHandoffLocked(handoffID, models.HandoffActionOpen)
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	s.recordAudit(models.AuditKindHandoffOpened, "handoff_id", handoff.ID, "from_account_id", handoff.FromAccountID,
+		"contact_id", handoff.ContactID)
+	return handoff, nil
+}
+
+// ImportConversationHandoff copies a writable handoff into the active
+// account: the contact is added if needed and messages it lacks are saved,
+// so the account can carry the conversation on.
+func (s *Service) ImportConversationHandoff(handoffID string) (models.ConversationHandoff, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	handoff, err := s.accessHandoffLocked(handoffID, models.HandoffActionImport)
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	if !s.identityManager.HasContact(handoff.ContactID) {
+		if err := s.AddContact(handoff.ContactID, handoff.ContactName); err != nil {
+			return models.ConversationHandoff{}, err
+		}
+	}
+	imported := 0
+	for _, msg := range handoff.Messages {
+		if _, exists := s.messageStore.GetMessage(msg.ID); exists {
+			continue
+		}
+		if err := s.messageStore.SaveMessage(msg); err != nil {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+			return models.ConversationHandoff{}, err
+		}
+		imported++
+	}
+	s.recordAudit(models.AuditKindHandoffImported, "handoff_id", handoff.ID, "from_account_id", handoff.FromAccountID,
+		"contact_id", handoff.ContactID, "messages", strconv.Itoa(imported))
+	return handoff, nil
+}
+
+// accessHandoffLocked loads and verifies a handoff and records the access
+// by the active account before returning it. The caller holds profileMu.
+func (s *Service) accessHandoffLocked(handoffID, action string) (models.ConversationHandoff, error) {
+	store, err := s.loadHandoffStoreLocked()
+	if err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	now := s.now().UTC()
+	handoff, ok := store.Get(handoffID)
+	if !ok || !handoff.ExpiresAt.After(now) {
+		return models.ConversationHandoff{}, errHandoffNotFound
+	}
+	if err := verifyHandoffSignature(handoff); err != nil {
+		return models.ConversationHandoff{}, err
+	}
+	if action == models.HandoffActionImport {
+		if handoff.ReadOnly {
+			return models.ConversationHandoff{}, errHandoffReadOnly
+		}
+		if handoff.FromAccountID == s.currentProfileID {
+			return models.ConversationHandoff{}, errHandoffOwnAccount
+		}
+	}
+	handoff.Access = append(handoff.Access, models.HandoffAccess{AccountID: s.currentProfileID, Action: action, At: now})
+	if err := store.Put(handoff, now); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.ConversationHandoff{}, err
+	}
+	return handoff, nil
+}
+
+// loadHandoffStoreLocked reads the daemon-wide handoff file with the
+// current storage secret, which also follows key rotations.
+func (s *Service) loadHandoffStoreLocked() (*storage.ConversationHandoffStore, error) {
+	store := storage.NewConversationHandoffStore()
+	store.Configure(filepath.Join(s.dataDir, handoffStoreFile), s.storageSecret)
+	if err := store.Bootstrap(); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Service) contactDisplayName(contactID string) string {
+	for _, contact := range s.identityManager.Contacts() {
+		if contact.ID == contactID {
+			return contact.DisplayName
+		}
+	}
+	return ""
+}
+
+// handoffSigningBytes is the signed form of a handoff: everything but the
+// signature and the access records, which change after signing.
+func handoffSigningBytes(handoff models.ConversationHandoff) ([]byte, error) {
+	handoff.Signature = ""
+	handoff.Access = nil
+	return json.Marshal(handoff)
+}
+
+func verifyHandoffSignature(handoff models.ConversationHandoff) error {
+	publicKey, err := base64.StdEncoding.DecodeString(handoff.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errHandoffSignature
+	}
+	if ok, err := identityapp.VerifyIdentityID(handoff.FromIdentityID, publicKey); err != nil || !ok {
+		return errHandoffSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(handoff.Signature)
+	if err != nil {
+		return errHandoffSignature
+	}
+	payload, err := handoffSigningBytes(handoff)
+	if err != nil || !ed25519.Verify(publicKey, payload, signature) {
+		return errHandoffSignature
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package daemonservice
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestConversationHandoffAcrossAccounts(t *testing.T) {
+	svc, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	owner, err := svc.GetCurrentAccount()
+	if err != nil {
+		t.Fatalf("current account: %v", err)
+	}
+	peer, err := NewServiceForDaemonWithDataDir(newMockConfig(), t.TempDir())
+	if err != nil {
+		t.Fatalf("new peer service: %v", err)
+	}
+	if _, _, err := peer.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create peer identity: %v", err)
+	}
+	card, err := peer.identityManager.SelfContactCard("customer")
+	if err != nil {
+		t.Fatalf("peer self card: %v", err)
+	}
+	if err := svc.identityManager.AddContact(card); err != nil {
+		t.Fatalf("add contact: %v", err)
+	}
+	for i, id := range []string{"msg-1", "msg-2"} {
+		msg := models.Message{ID: id, ContactID: card.IdentityID, Content: []byte("help " + id), ContentType: "text",
+			Timestamp: time.Now().Add(time.Duration(i) * time.Second), Direction: "in", Status: "read"}
+		if err := svc.messageStore.SaveMessage(msg); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+	}
+
+	req := models.HandoffCreateRequest{ContactID: card.IdentityID, ReadOnly: true}
+	if _, err := svc.CreateConversationHandoff(req); !errors.Is(err, errHandoffConsentRequired) {
+		t.Fatalf("expected handoff without consent to be refused, got %v", err)
+	}
+	req.Consent = true
+	readOnly, err := svc.CreateConversationHandoff(req)
+	if err != nil {
+		t.Fatalf("create read-only handoff: %v", err)
+	}
+	if len(readOnly.Messages) != 2 || readOnly.Consent.AccountID != owner.ID || readOnly.Signature == "" {
+		t.Fatalf("unexpected handoff: %+v", readOnly)
+	}
+	req.ReadOnly = false
+	writable, err := svc.CreateConversationHandoff(req)
+	if err != nil {
+		t.Fatalf("create writable handoff: %v", err)
+	}
+	if _, err := svc.ImportConversationHandoff(writable.ID); !errors.Is(err, errHandoffOwnAccount) {
+		t.Fatalf("expected import into the owner account to be refused, got %v", err)
+	}
+
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create second identity: %v", err)
+	}
+	opened, err := svc.OpenConversationHandoff(readOnly.ID)
+	if err != nil {
+		t.Fatalf("open handoff from another account: %v", err)
+	}
+	if len(opened.Access) != 1 || opened.Access[0].Action != models.HandoffActionOpen || opened.Access[0].AccountID == owner.ID {
+		t.Fatalf("open must be recorded for the opening account: %+v", opened.Access)
+	}
+	if _, err := svc.ImportConversationHandoff(readOnly.ID); !errors.Is(err, errHandoffReadOnly) {
+		t.Fatalf("expected read-only import to be refused, got %v", err)
+	}
+	if _, err := svc.ImportConversationHandoff(writable.ID); err != nil {
+		t.Fatalf("import handoff: %v", err)
+	}
+	if !svc.identityManager.HasContact(card.IdentityID) || len(svc.messageStore.ListMessages(card.IdentityID, 10, 0)) != 2 {
+		t.Fatal("import must add the contact and its messages to the active account")
+	}
+	page := svc.ListAuditEvents("", 0, 0)
+	if page.Total != 2 || page.Events[0].Kind != models.AuditKindHandoffImported || page.Events[1].Kind != models.AuditKindHandoffOpened {
+		t.Fatalf("unexpected audit events of the opening account: %+v", page.Events)
+	}
+
+	opened.Note = "edited"
+	if err := verifyHandoffSignature(opened); !errors.Is(err, errHandoffSignature) {
+		t.Fatalf("expected edited handoff to fail verification, got %v", err)
+	}
+	if _, err := svc.OpenConversationHandoff("handoff-missing"); !errors.Is(err, errHandoffNotFound) {
+		t.Fatalf("expected unknown handoff to be rejected, got %v", err)
+	}
+}
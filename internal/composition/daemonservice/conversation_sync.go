@@ -0,0 +1,123 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type ownDeviceIdentity interface {
+	VerifyOwnDevice(device models.Device, payload, sig []byte) error
+}
+
+// ListConversationFlags returns the pins, archives, mutes and drafts shared
+// between the user's own devices.
+func (s *Service) ListConversationFlags() []models.ConversationFlags {
+	states := s.conversationSync.List()
+	out := make([]models.ConversationFlags, 0, len(states))
+	for _, state := range states {
+		out = append(out, messagingapp.ResolveConversationFlags(state))
+	}
+	return out
+}
+
+// SetConversationFlags records a local change and sends the resulting state
+// to our other devices. Networking being down only delays the broadcast; the
+// registers converge once a later state is delivered.
+func (s *Service) SetConversationFlags(conversationID string, update models.ConversationFlagsUpdate) (models.ConversationFlags, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return models.ConversationFlags{}, errors.New("conversation id is required")
+	}
+	deviceID, err := s.activeDeviceID()
+	if err != nil {
+		return models.ConversationFlags{}, err
+	}
+	local := messagingapp.BuildConversationSyncUpdate(conversationID, update, deviceID, time.Now())
+	if len(local.Registers) > 0 {
+		if err := messagingapp.ValidateConversationSync([]models.ConversationSyncState{local}); err != nil {
+			return models.ConversationFlags{}, err
+		}
+		changed, err := s.conversationSync.Merge([]models.ConversationSyncState{local}, messagingapp.MergeConversationSyncState)
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+			return models.ConversationFlags{}, err
+		}
+		if len(changed) > 0 {
+			s.notifyConversationFlags(changed)
+			if err := s.broadcastConversationSync(changed); err != nil {
+				s.recordError(contracts.ErrorCategoryNetwork, err)
+			}
+		}
+	}
+	state, _ := s.conversationSync.Get(conversationID)
+	state.ConversationID = conversationID
+	return messagingapp.ResolveConversationFlags(state), nil
+}
+
+// handleDeviceSyncWire merges conversation registers, stores saved messages
+// and compares state hashes sent by another of our devices. Payloads from other identities or
+// unverifiable devices are dropped.
+func (s *Service) handleDeviceSyncWire(msg messagingapp.InboundPrivateMessage, wire contracts.WirePayload) {
+	self := s.identityManager.GetIdentity()
+	if msg.SenderID != self.ID {
+		s.recordError(contracts.ErrorCategoryCrypto, errors.New("device sync from a foreign identity"))
+		return
+	}
+	verifier, ok := s.identityManager.(ownDeviceIdentity)
+	if !ok {
+		return
+	}
+	if wire.Device == nil || len(wire.DeviceSig) == 0 {
+		s.recordError(contracts.ErrorCategoryCrypto, errors.New("missing device authentication"))
+		return
+	}
+	authPayload, err := messagingapp.BuildWireAuthPayload(msg.ID, msg.SenderID, msg.Recipient, wire)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+		return
+	}
+	if err := verifier.VerifyOwnDevice(*wire.Device, authPayload, wire.DeviceSig); err != nil {
+		s.recordError(contracts.ErrorCategoryCrypto, err)
+		return
+	}
+	if wire.SavedMessage != nil {
+		s.storeSyncedSavedMessage(self.ID, *wire.SavedMessage)
+		return
+	}
+	if wire.StateHashes != nil {
+		s.handleOwnDeviceStateHashes(*wire.StateHashes)
+		return
+	}
+	changed, err := s.conversationSync.Merge(wire.ConversationSync, messagingapp.MergeConversationSyncState)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	if len(changed) > 0 {
+		s.notifyConversationFlags(changed)
+	}
+}
+
+func (s *Service) broadcastConversationSync(states []models.ConversationSyncState) error {
+	ctx, err := s.networkContext("")
+	if err != nil {
+		return nil
+	}
+	wireID, err := s.generateID("sync")
+	if err != nil {
+		return err
+	}
+	wire := contracts.WirePayload{Kind: messagingapp.WireKindDeviceSync, ConversationSync: states}
+	return s.publishSignedWireWithContext(ctx, wireID, s.identityManager.GetIdentity().ID, wire)
+}
+
+func (s *Service) notifyConversationFlags(states []models.ConversationSyncState) {
+	for _, state := range states {
+		s.notify("notify.conversation.flags", messagingapp.ResolveConversationFlags(state))
+	}
+}
@@ -0,0 +1,88 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func deliverDeviceSync(t *testing.T, from, to *Service, messageID string) {
+	t.Helper()
+	self := from.identityManager.GetIdentity().ID
+	wire := contracts.WirePayload{Kind: messagingapp.WireKindDeviceSync, ConversationSync: from.conversationSync.List()}
+	wmsg, err := messagingapp.ComposeSignedPrivateMessage(messageID, self, wire, from.identityManager)
+	if err != nil {
+		t.Fatalf("compose device sync: %v", err)
+	}
+	to.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+		ID:        wmsg.ID,
+		SenderID:  wmsg.SenderID,
+		Recipient: wmsg.Recipient,
+		Payload:   wmsg.Payload,
+	})
+}
+
+func TestConversationFlagsSyncBetweenOwnDevices(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	phone, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "phone"))
+	if err != nil {
+		t.Fatalf("new phone service: %v", err)
+	}
+	laptop, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "laptop"))
+	if err != nil {
+		t.Fatalf("new laptop service: %v", err)
+	}
+	stranger, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "stranger"))
+	if err != nil {
+		t.Fatalf("new stranger service: %v", err)
+	}
+	if _, _, err := stranger.CreateIdentity("stranger-pass"); err != nil {
+		t.Fatalf("stranger identity: %v", err)
+	}
+	_, mnemonic, err := phone.CreateIdentity("shared-pass")
+	if err != nil {
+		t.Fatalf("phone identity: %v", err)
+	}
+	if _, err := laptop.ImportIdentity(mnemonic, "shared-pass"); err != nil {
+		t.Fatalf("laptop import: %v", err)
+	}
+
+	pinned, muted := true, true
+	draft := "see you at"
+	if _, err := phone.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{Pinned: &pinned, Draft: &draft}); err != nil {
+		t.Fatalf("phone set flags: %v", err)
+	}
+	if _, err := laptop.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{Muted: &muted}); err != nil {
+		t.Fatalf("laptop set flags: %v", err)
+	}
+
+	deliverDeviceSync(t, phone, laptop, "sync-1")
+	deliverDeviceSync(t, laptop, phone, "sync-2")
+
+	phoneFlags := phone.ListConversationFlags()
+	laptopFlags := laptop.ListConversationFlags()
+	if len(phoneFlags) != 1 || len(laptopFlags) != 1 || phoneFlags[0] != laptopFlags[0] {
+		t.Fatalf("devices must converge: phone=%+v laptop=%+v", phoneFlags, laptopFlags)
+	}
+	got := phoneFlags[0]
+	if !got.Pinned || !got.Muted || got.Archived || got.Draft != draft {
+		t.Fatalf("unexpected converged flags: %+v", got)
+	}
+
+	archived := true
+	if _, err := stranger.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{Archived: &archived}); err != nil {
+		t.Fatalf("stranger set flags: %v", err)
+	}
+	deliverDeviceSync(t, stranger, laptop, "sync-3")
+	if flags := laptop.ListConversationFlags(); flags[0].Archived {
+		t.Fatal("device sync from another identity must be ignored")
+	}
+}
@@ -0,0 +1,168 @@
+package daemonservice
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+)
+
+const (
+	// coverTrafficWindow is how far back messages count towards picking the
+	// contacts that receive dummy messages.
+	coverTrafficWindow = 7 * 24 * time.Hour
+	// coverTrafficMaxContacts limits dummies to the busiest conversations,
+	// which are the ones whose timing is worth hiding.
+	coverTrafficMaxContacts = 5
+	// coverTrafficMaxFiller varies the plaintext length of dummies. It stays
+	// well inside the smallest cover bucket.
+	coverTrafficMaxFiller = 512
+)
+
+// coverTrafficState tracks the daily budget. It is kept in memory; a restart
+// resets the budget for the rest of the day.
+type coverTrafficState struct {
+	mu       sync.Mutex
+	lastSent time.Time
+	day      string
+	spent    int
+}
+
+func newCoverTrafficState() *coverTrafficState {
+	return &coverTrafficState{}
+}
+
+// UpdateCoverTraffic changes the cover traffic mode and applies the padding
+// policy right away.
+func (s *Service) UpdateCoverTraffic(settings privacydomain.CoverTrafficSettings) (privacydomain.PrivacySettings, error) {
+	updated, err := s.privacyCore.UpdateCoverTraffic(settings)
+	if err != nil {
+		return privacydomain.PrivacySettings{}, err
+	}
+	s.applyCoverTrafficFromSettings(updated)
+	return updated, nil
+}
+
+func (s *Service) applyCoverTrafficFromSettings(settings privacydomain.PrivacySettings) {
+	s.metaHardening.setCoverMode(settings.CoverTraffic.Enabled)
+}
+
+// sendCoverTraffic sends one dummy message per interval to a frequent
+// contact while the daily budget lasts. Dummies are encrypted with the
+// session like real chat messages, so only the recipient can tell them apart.
+// They carry no sequence number and therefore never open history gaps.
+func (s *Service) sendCoverTraffic(ctx context.Context, now time.Time) {
+	settings := s.privacyCore.CoverTraffic()
+	if !settings.Enabled {
+		return
+	}
+	state := s.coverTraffic
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	interval := time.Duration(settings.IntervalSeconds) * time.Second
+	if !state.lastSent.IsZero() && now.Sub(state.lastSent) < interval {
+		return
+	}
+	if day := now.UTC().Format(time.DateOnly); day != state.day {
+		state.day = day
+		state.spent = 0
+	}
+	if state.spent+coverSizeBuckets[0] > settings.DailyBudgetKB*1024 {
+		return
+	}
+	contacts := s.coverTrafficContacts(now)
+	if len(contacts) == 0 {
+		return
+	}
+	pick, err := rand.Int(rand.Reader, big.NewInt(int64(len(contacts))))
+	if err != nil {
+		return
+	}
+	state.lastSent = now
+	sent, err := s.publishCoverMessage(ctx, contacts[pick.Int64()])
+	if err != nil {
+		s.recordError(messagingapp.ErrorCategory(err), err)
+		return
+	}
+	state.spent += sent
+}
+
+// coverTrafficContacts returns the verified contacts with the most recent
+// messages. Only sessions that already decrypted a message from the contact
+// qualify, so the contact is known to be able to read (and drop) a dummy.
+func (s *Service) coverTrafficContacts(now time.Time) []string {
+	sessions, err := s.sessionManager.Snapshot()
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return nil
+	}
+	type candidate struct {
+		contactID string
+		recent    int
+	}
+	since := now.Add(-coverTrafficWindow)
+	candidates := make([]candidate, 0, len(sessions))
+	for _, session := range sessions {
+		if session.RecvChainIndex == 0 || !s.identityManager.HasVerifiedContact(session.ContactID) {
+			continue
+		}
+		recent := 0
+		for _, msg := range s.messageStore.ListMessages(session.ContactID, 0, 0) {
+			if msg.Timestamp.After(since) {
+				recent++
+			}
+		}
+		if recent > 0 {
+			candidates = append(candidates, candidate{contactID: session.ContactID, recent: recent})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].recent != candidates[j].recent {
+			return candidates[i].recent > candidates[j].recent
+		}
+		return candidates[i].contactID < candidates[j].contactID
+	})
+	out := make([]string, 0, coverTrafficMaxContacts)
+	for _, c := range candidates {
+		if len(out) == coverTrafficMaxContacts {
+			break
+		}
+		out = append(out, c.contactID)
+	}
+	return out
+}
+
+// publishCoverMessage sends a dummy to contactID and returns the size of the
+// published payload.
+func (s *Service) publishCoverMessage(ctx context.Context, contactID string) (int, error) {
+	fillerLen, err := rand.Int(rand.Reader, big.NewInt(coverTrafficMaxFiller+1))
+	if err != nil {
+		return 0, err
+	}
+	filler := make([]byte, fillerLen.Int64())
+	if _, err := rand.Read(filler); err != nil {
+		return 0, err
+	}
+	env, err := s.sessionManager.Encrypt(contactID, messagingapp.NewCoverTrafficContent(filler))
+	if err != nil {
+		return 0, contracts.WrapCategorizedError(contracts.ErrorCategoryCrypto, err)
+	}
+	wireID, err := s.generateID("msg")
+	if err != nil {
+		return 0, err
+	}
+	wmsg, err := s.composeHardenedPrivateMessage(ctx, wireID, contactID, contracts.WirePayload{Kind: "e2ee", Envelope: env})
+	if err != nil {
+		return 0, err
+	}
+	if err := s.publishWithTimeout(ctx, wmsg); err != nil {
+		return 0, contracts.WrapCategorizedError(contracts.ErrorCategoryNetwork, err)
+	}
+	return len(wmsg.Payload), nil
+}
@@ -0,0 +1,192 @@
+package daemonservice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const dailySummaryWindow = 24 * time.Hour
+
+// dailySummaryState remembers the last scheduled slot that was handled and
+// the storage usage it was measured at. It is kept in memory: after a restart
+// the current slot counts as handled, so a summary is never sent twice, and
+// storage growth is measured from daemon start.
+type dailySummaryState struct {
+	mu              sync.Mutex
+	lastSlot        time.Time
+	storageBaseline int64
+	hasBaseline     bool
+}
+
+func newDailySummaryState() *dailySummaryState {
+	return &dailySummaryState{}
+}
+
+// reschedule forgets the handled slot so a changed schedule starts with the
+// next slot instead of firing for one that already passed today.
+func (st *dailySummaryState) reschedule() {
+	st.mu.Lock()
+	st.lastSlot = time.Time{}
+	st.mu.Unlock()
+}
+
+func (st *dailySummaryState) reset() {
+	st.mu.Lock()
+	st.lastSlot = time.Time{}
+	st.storageBaseline = 0
+	st.hasBaseline = false
+	st.mu.Unlock()
+}
+
+// UpdateDailySummary changes the daily summary schedule of the active
+// account.
+func (s *Service) UpdateDailySummary(settings privacydomain.DailySummarySettings) (privacydomain.PrivacySettings, error) {
+	updated, err := s.privacyCore.UpdateDailySummary(settings)
+	if err != nil {
+		return privacydomain.PrivacySettings{}, err
+	}
+	s.dailySummary.reschedule()
+	return updated, nil
+}
+
+// dailySummarySlot returns the latest scheduled summary time at or before now.
+func dailySummarySlot(settings privacydomain.DailySummarySettings, now time.Time) time.Time {
+	zone := time.FixedZone("", settings.UTCOffsetMinutes*60)
+	local := now.In(zone)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), settings.Hour, 0, 0, 0, zone)
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot.UTC()
+}
+
+// publishDailySummary runs on the retry tick and publishes the summary once
+// the next scheduled slot is reached.
+func (s *Service) publishDailySummary(now time.Time) {
+	settings := s.privacyCore.DailySummary()
+	if !settings.Enabled {
+		return
+	}
+	slot := dailySummarySlot(settings, now)
+	state := s.dailySummary
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.hasBaseline {
+		state.storageBaseline = s.storageUsageBytes()
+		state.hasBaseline = true
+	}
+	if state.lastSlot.IsZero() {
+		state.lastSlot = slot
+		return
+	}
+	if !slot.After(state.lastSlot) {
+		return
+	}
+	state.lastSlot = slot
+	summary := s.buildDailySummary(slot.Add(-dailySummaryWindow), slot, state.storageBaseline)
+	state.storageBaseline = summary.StorageBytes
+	s.notify("notify.daily.summary", summary)
+	if settings.SaveToSavedMessages {
+		s.saveDailySummaryNote(summary, now)
+	}
+}
+
+// buildDailySummary counts activity with timestamps in [start, end). Notes to
+// self and local system entries are not messages anyone sent or received.
+func (s *Service) buildDailySummary(start, end time.Time, storageBaseline int64) models.DailySummary {
+	summary := models.DailySummary{WindowStart: start, WindowEnd: end}
+	selfID := strings.TrimSpace(s.identityManager.GetIdentity().ID)
+	inWindow := func(at time.Time) bool {
+		return !at.Before(start) && at.Before(end)
+	}
+	messages, _ := s.messageStore.Snapshot()
+	for _, msg := range messages {
+		if !inWindow(msg.Timestamp) || msg.ContactID == selfID || msg.ContentType == models.MessageContentTypeSystem {
+			continue
+		}
+		switch msg.Direction {
+		case "out":
+			summary.MessagesSent++
+			if msg.Status == "failed" {
+				summary.FailedDeliveries++
+			}
+		case "in":
+			summary.MessagesReceived++
+		}
+	}
+	requests, err := s.ListMessageRequests()
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+	}
+	for _, req := range requests {
+		if inWindow(req.FirstMessageAt) {
+			summary.NewRequests++
+		}
+	}
+	summary.StorageBytes = s.storageUsageBytes()
+	summary.StorageGrowthBytes = summary.StorageBytes - storageBaseline
+	return summary
+}
+
+func (s *Service) storageUsageBytes() int64 {
+	usageReader, ok := s.attachmentStore.(interface {
+		UsageByClass() map[string]int64
+	})
+	if !ok {
+		return 0
+	}
+	var total int64
+	for _, used := range usageReader.UsageByClass() {
+		total += used
+	}
+	return total
+}
+
+// saveDailySummaryNote stores the summary in Saved Messages. The note stays
+// on this node: every node reports its own activity, so it is not synced to
+// the user's other devices.
+func (s *Service) saveDailySummaryNote(summary models.DailySummary, now time.Time) {
+	selfID := strings.TrimSpace(s.identityManager.GetIdentity().ID)
+	if selfID == "" {
+		return
+	}
+	noteID, err := s.generateID("msg")
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	note := models.SavedMessage{ID: noteID, Content: []byte(formatDailySummaryNote(summary)), Timestamp: now.UTC()}
+	stored := messagingapp.BuildStoredSavedMessage(note, selfID, now)
+	if err := s.messageStore.SaveMessage(stored); err != nil {
+		if !errors.Is(err, storage.ErrMessageIDConflict) {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+		}
+		return
+	}
+	s.notify(events.MethodMessageNew, events.MessageNew{ContactID: selfID, Message: stored})
+}
+
+func formatDailySummaryNote(summary models.DailySummary) string {
+	const mb = 1024 * 1024
+	return fmt.Sprintf(
+		"Daily summary %s – %s\nMessages sent: %d\nMessages received: %d\nNew requests: %d\nFailed deliveries: %d\nStorage: %.1f MB (%+.1f MB)",
+		summary.WindowStart.Format(time.RFC3339),
+		summary.WindowEnd.Format(time.RFC3339),
+		summary.MessagesSent,
+		summary.MessagesReceived,
+		summary.NewRequests,
+		summary.FailedDeliveries,
+		float64(summary.StorageBytes)/mb,
+		float64(summary.StorageGrowthBytes)/mb,
+	)
+}
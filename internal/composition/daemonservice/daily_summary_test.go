@@ -0,0 +1,84 @@
+package daemonservice
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestDailySummarySlotFollowsOffset(t *testing.T) {
+	settings := privacydomain.DailySummarySettings{Hour: 9, UTCOffsetMinutes: 180}
+	now := time.Date(2026, 3, 10, 5, 30, 0, 0, time.UTC) // 08:30 at UTC+3
+	if got, want := dailySummarySlot(settings, now), time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected yesterday's slot %v, got %v", want, got)
+	}
+	if got, want := dailySummarySlot(settings, now.Add(30*time.Minute)), time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected today's slot %v, got %v", want, got)
+	}
+}
+
+func TestDailySummaryPublishedOncePerSlot(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "node"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, _, err := svc.CreateIdentity("pass")
+	if err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	if _, err := svc.UpdateDailySummary(privacydomain.DailySummarySettings{Enabled: true, Hour: 9, SaveToSavedMessages: true}); err != nil {
+		t.Fatalf("update daily summary: %v", err)
+	}
+
+	slot := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	for _, msg := range []models.Message{
+		{ID: "in-1", ContactID: "alice", Direction: "in", Status: "delivered", Timestamp: slot.Add(-2 * time.Hour)},
+		{ID: "out-1", ContactID: "alice", Direction: "out", Status: "sent", Timestamp: slot.Add(-3 * time.Hour)},
+		{ID: "out-2", ContactID: "alice", Direction: "out", Status: "failed", Timestamp: slot.Add(-4 * time.Hour)},
+		{ID: "old", ContactID: "alice", Direction: "in", Status: "delivered", Timestamp: slot.Add(-25 * time.Hour)},
+		{ID: "note", ContactID: self.ID, Direction: "out", Status: "sent", Timestamp: slot.Add(-time.Hour)},
+	} {
+		if err := svc.messageStore.SaveMessage(msg); err != nil {
+			t.Fatalf("save %s: %v", msg.ID, err)
+		}
+	}
+
+	// The slot current at startup counts as handled.
+	svc.publishDailySummary(slot.Add(-time.Hour))
+	svc.publishDailySummary(slot.Add(-time.Minute))
+	svc.publishDailySummary(slot)
+	svc.publishDailySummary(slot.Add(time.Minute))
+
+	replay, _, cancel := svc.SubscribeNotifications(0)
+	defer cancel()
+	var summaries []models.DailySummary
+	for _, evt := range replay {
+		if evt.Method != "notify.daily.summary" {
+			continue
+		}
+		raw, _ := json.Marshal(evt.Payload)
+		var summary models.DailySummary
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			t.Fatalf("decode summary: %v", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("expected exactly one summary, got %d", len(summaries))
+	}
+	got := summaries[0]
+	if got.MessagesSent != 2 || got.MessagesReceived != 1 || got.FailedDeliveries != 1 || !got.WindowEnd.Equal(slot) {
+		t.Fatalf("unexpected summary: %+v", got)
+	}
+	notes := svc.messageStore.ListMessages(self.ID, 0, 0)
+	if len(notes) != 2 {
+		t.Fatalf("expected the summary saved next to the existing note, got %d notes", len(notes))
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
 )
 
 const DataWipeConsentToken = "I_UNDERSTAND_LOCAL_DATA_WIPE"
@@ -38,6 +40,12 @@ func (s *Service) WipeData(consentToken string) (bool, error) {
 			wipeErr = errors.Join(wipeErr, err)
 		}
 	}
+	// Snapshots hold copies of the wiped content.
+	if strings.TrimSpace(s.dataDir) != "" {
+		if err := daemoncomposition.RemoveStorageSnapshots(s.dataDir); err != nil {
+			wipeErr = errors.Join(wipeErr, err)
+		}
+	}
 	if wipeErr != nil {
 		return false, wipeErr
 	}
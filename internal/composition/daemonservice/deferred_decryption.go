@@ -0,0 +1,173 @@
+package daemonservice
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+)
+
+const (
+	deferredDecryptionAlertKind     = "deferred_decryption_expired"
+	deferredDecryptionMaxPerContact = 32
+	deferredDecryptionMaxTotal      = 512
+)
+
+// deferredCiphertext is an e2ee wire that arrived before the session it was
+// encrypted for, e.g. when a prekey flow completes out of order.
+type deferredCiphertext struct {
+	msg      messagingapp.InboundPrivateMessage
+	wire     contracts.WirePayload
+	queuedAt time.Time
+}
+
+type deferredDecryptionQueue struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	byContact map[string][]deferredCiphertext
+	total     int
+}
+
+func newDeferredDecryptionQueue() *deferredDecryptionQueue {
+	return &deferredDecryptionQueue{
+		ttl:       time.Duration(envBoundedIntWithFallback("AIM_DEFERRED_DECRYPT_TTL_SEC", 120, 5, 3600)) * time.Second,
+		byContact: map[string][]deferredCiphertext{},
+	}
+}
+
+// park queues a ciphertext and reports false when the queue is full, in which
+// case the caller stores the message as unreadable right away. A message that
+// is already parked keeps its original deadline.
+func (q *deferredDecryptionQueue) park(msg messagingapp.InboundPrivateMessage, wire contracts.WirePayload, now time.Time) bool {
+	contactID := strings.TrimSpace(msg.SenderID)
+	if contactID == "" {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := q.byContact[contactID]
+	for _, entry := range entries {
+		if entry.msg.ID == msg.ID {
+			return true
+		}
+	}
+	if len(entries) >= deferredDecryptionMaxPerContact || q.total >= deferredDecryptionMaxTotal {
+		return false
+	}
+	q.byContact[contactID] = append(entries, deferredCiphertext{msg: msg, wire: wire, queuedAt: now})
+	q.total++
+	return true
+}
+
+func (q *deferredDecryptionQueue) take(contactID string) []deferredCiphertext {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := q.byContact[contactID]
+	delete(q.byContact, contactID)
+	q.total -= len(entries)
+	return entries
+}
+
+// restore puts back entries a retry could not decrypt yet, ahead of anything
+// parked in the meantime.
+func (q *deferredDecryptionQueue) restore(contactID string, entries []deferredCiphertext) {
+	if len(entries) == 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.byContact[contactID] = append(entries, q.byContact[contactID]...)
+	q.total += len(entries)
+}
+
+func (q *deferredDecryptionQueue) contacts() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]string, 0, len(q.byContact))
+	for contactID := range q.byContact {
+		out = append(out, contactID)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// expire removes and returns the entries parked for longer than the TTL.
+func (q *deferredDecryptionQueue) expire(now time.Time) map[string][]deferredCiphertext {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out map[string][]deferredCiphertext
+	for contactID, entries := range q.byContact {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if now.Sub(entry.queuedAt) < q.ttl {
+				kept = append(kept, entry)
+				continue
+			}
+			if out == nil {
+				out = map[string][]deferredCiphertext{}
+			}
+			out[contactID] = append(out[contactID], entry)
+			q.total--
+		}
+		if len(kept) == 0 {
+			delete(q.byContact, contactID)
+			continue
+		}
+		q.byContact[contactID] = kept
+	}
+	return out
+}
+
+func (q *deferredDecryptionQueue) reset() {
+	q.mu.Lock()
+	q.byContact = map[string][]deferredCiphertext{}
+	q.total = 0
+	q.mu.Unlock()
+}
+
+func (s *Service) deferInboundDecryption(msg messagingapp.InboundPrivateMessage, wire contracts.WirePayload) bool {
+	return s.deferredDecryption.park(msg, wire, time.Now().UTC())
+}
+
+// retryDeferredDecryption retries the ciphertexts parked for contactID, e.g.
+// right after its session was set up or replaced.
+func (s *Service) retryDeferredDecryption(contactID string) {
+	contactID = strings.TrimSpace(contactID)
+	entries := s.deferredDecryption.take(contactID)
+	var pending []deferredCiphertext
+	for _, entry := range entries {
+		if done, _ := s.inboundMessagingCore.RetryDeferredDecryption(entry.msg, entry.wire, false); !done {
+			pending = append(pending, entry)
+		}
+	}
+	s.deferredDecryption.restore(contactID, pending)
+}
+
+// drainDeferredDecryption runs on the retry tick. Parked ciphertexts past the
+// TTL get one last attempt and are otherwise stored as unreadable with a
+// security alert; the rest are retried in case a session appeared without
+// going through InitSession, e.g. a snapshot restore.
+func (s *Service) drainDeferredDecryption(now time.Time) {
+	for contactID, entries := range s.deferredDecryption.expire(now) {
+		unreadable := 0
+		for _, entry := range entries {
+			if _, err := s.inboundMessagingCore.RetryDeferredDecryption(entry.msg, entry.wire, true); err != nil {
+				unreadable++
+			}
+		}
+		if unreadable == 0 {
+			continue
+		}
+		s.notifySecurityAlertWithArgs(deferredDecryptionAlertKind, contactID,
+			fmt.Sprintf("%d messages could not be decrypted: no matching session was set up in time", unreadable),
+			map[string]string{"count": strconv.Itoa(unreadable)})
+	}
+	for _, contactID := range s.deferredDecryption.contacts() {
+		s.retryDeferredDecryption(contactID)
+	}
+}
@@ -0,0 +1,45 @@
+package daemonservice
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+)
+
+func TestDeferredDecryptionQueueBoundsAndExpires(t *testing.T) {
+	q := &deferredDecryptionQueue{ttl: time.Minute, byContact: map[string][]deferredCiphertext{}}
+	now := time.Unix(1_700_000_000, 0)
+	wire := contracts.WirePayload{Kind: "e2ee"}
+	for i := 0; i < deferredDecryptionMaxPerContact; i++ {
+		if !q.park(messagingapp.InboundPrivateMessage{ID: fmt.Sprintf("m%d", i), SenderID: "alice"}, wire, now) {
+			t.Fatalf("expected message %d to be parked", i)
+		}
+	}
+	if q.park(messagingapp.InboundPrivateMessage{ID: "overflow", SenderID: "alice"}, wire, now) {
+		t.Fatal("expected the per-contact cap to refuse more ciphertexts")
+	}
+	if !q.park(messagingapp.InboundPrivateMessage{ID: "m0", SenderID: "alice"}, wire, now.Add(time.Hour)) {
+		t.Fatal("expected a redelivered message to count as parked")
+	}
+	if !q.park(messagingapp.InboundPrivateMessage{ID: "b1", SenderID: "bob"}, wire, now.Add(30*time.Second)) {
+		t.Fatal("expected another contact to have room")
+	}
+
+	expired := q.expire(now.Add(time.Minute))
+	if len(expired["alice"]) != deferredDecryptionMaxPerContact || len(expired["bob"]) != 0 {
+		t.Fatalf("expected only alice's ciphertexts to expire, got alice=%d bob=%d", len(expired["alice"]), len(expired["bob"]))
+	}
+	if got := q.contacts(); len(got) != 1 || got[0] != "bob" || q.total != 1 {
+		t.Fatalf("expected bob left parked, got %v total=%d", got, q.total)
+	}
+
+	taken := q.take("bob")
+	q.park(messagingapp.InboundPrivateMessage{ID: "b2", SenderID: "bob"}, wire, now)
+	q.restore("bob", taken)
+	if entries := q.byContact["bob"]; len(entries) != 2 || entries[0].msg.ID != "b1" || q.total != 2 {
+		t.Fatalf("expected restored entries ahead of new ones, got %+v total=%d", entries, q.total)
+	}
+}
@@ -0,0 +1,78 @@
+package daemonservice
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// GetContactDeliveryStats reports send-to-receipt latencies, failures and the
+// retry backlog for messages sent to one contact.
+func (s *Service) GetContactDeliveryStats(contactID string) (models.ContactDeliveryStats, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return models.ContactDeliveryStats{}, errors.New("contact id is required")
+	}
+	stats := s.metrics.ContactDeliveryStats(contactID)
+	stats.PendingBacklog = s.pendingBacklogByContact()[contactID]
+	return stats, nil
+}
+
+// ListContactDeliveryStats reports delivery statistics for every contact
+// with outbound activity, slowest delivery first.
+func (s *Service) ListContactDeliveryStats() ([]models.ContactDeliveryStats, error) {
+	backlog := s.pendingBacklogByContact()
+	contactIDs := make(map[string]struct{}, len(backlog))
+	for _, contactID := range s.metrics.DeliveryContacts() {
+		contactIDs[contactID] = struct{}{}
+	}
+	for contactID := range backlog {
+		contactIDs[contactID] = struct{}{}
+	}
+	out := make([]models.ContactDeliveryStats, 0, len(contactIDs))
+	for contactID := range contactIDs {
+		stats := s.metrics.ContactDeliveryStats(contactID)
+		stats.PendingBacklog = backlog[contactID]
+		out = append(out, stats)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Delivered.P90Ms != out[j].Delivered.P90Ms {
+			return out[i].Delivered.P90Ms > out[j].Delivered.P90Ms
+		}
+		return out[i].ContactID < out[j].ContactID
+	})
+	return out, nil
+}
+
+func (s *Service) pendingBacklogByContact() map[string]int {
+	_, pending := s.messageStore.Snapshot()
+	out := make(map[string]int, len(pending))
+	for _, p := range pending {
+		out[p.Message.ContactID]++
+	}
+	return out
+}
+
+// recordDeliveryReceipt feeds the first delivered and read receipts of an
+// outbound message into the per-contact latency windows.
+func (s *Service) recordDeliveryReceipt(before models.Message, status string, now time.Time) {
+	if before.Direction != "out" {
+		return
+	}
+	switch status {
+	case "delivered":
+		if before.Status == "delivered" || before.Status == "read" {
+			return
+		}
+	case "read":
+		if before.Status == "read" {
+			return
+		}
+	default:
+		return
+	}
+	s.metrics.RecordDeliveryReceipt(before.ContactID, status, now.Sub(before.Timestamp))
+}
@@ -0,0 +1,82 @@
+package daemonservice
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactDeliveryStatsTrackReceiptsFailuresAndBacklog(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewMessageStore()
+	svc := &Service{
+		messageStore:  store,
+		logger:        runtimeapp.DefaultLogger(),
+		metrics:       runtimeapp.NewServiceMetricsState(),
+		notifier:      runtimeapp.NewNotificationHub(32),
+		deadLetters:   storage.NewDeadLetterStore(),
+		retryPolicyMu: &sync.RWMutex{},
+		retryPolicy:   messagingapp.DefaultRetryPolicy(),
+	}
+	const contactID = "aim1_slow_contact"
+	save := func(id, status string, sentAgo time.Duration) models.Message {
+		msg := models.Message{
+			ID:        id,
+			ContactID: contactID,
+			Content:   []byte("payload"),
+			Timestamp: time.Now().UTC().Add(-sentAgo),
+			Direction: "out",
+			Status:    status,
+		}
+		if err := store.SaveMessage(msg); err != nil {
+			t.Fatalf("save message: %v", err)
+		}
+		return msg
+	}
+
+	save("msg-delivered", "pending", 3*time.Second)
+	svc.markMessageAsSent("msg-delivered")
+	svc.applyInboundReceiptStatus(messagingapp.InboundReceiptHandling{MessageID: "msg-delivered", Status: "delivered"})
+	svc.applyInboundReceiptStatus(messagingapp.InboundReceiptHandling{MessageID: "msg-delivered", Status: "delivered"})
+	svc.applyInboundReceiptStatus(messagingapp.InboundReceiptHandling{MessageID: "msg-delivered", Status: "read"})
+
+	failed := save("msg-failed", "pending", time.Second)
+	svc.handleRetryPublishError(storage.PendingMessage{Message: failed, RetryCount: 8}, assertErr("network"))
+
+	queued := save("msg-queued", "pending", 0)
+	if err := store.AddOrUpdatePending(queued, 1, time.Now().Add(time.Minute), "network down"); err != nil {
+		t.Fatalf("add pending: %v", err)
+	}
+
+	stats, err := svc.GetContactDeliveryStats(contactID)
+	if err != nil {
+		t.Fatalf("contact stats: %v", err)
+	}
+	if stats.SentTotal != 1 || stats.FailedTotal != 1 || stats.FailureRateBps != 5000 {
+		t.Fatalf("unexpected send/failure counters: %+v", stats)
+	}
+	if stats.DeliveredTotal != 1 || stats.Delivered.Samples != 1 || stats.Delivered.P50Ms < 3000 {
+		t.Fatalf("duplicate receipts must count once with send-to-receipt latency: %+v", stats.Delivered)
+	}
+	if stats.ReadTotal != 1 || stats.Read.Samples != 1 {
+		t.Fatalf("unexpected read latency: %+v", stats.Read)
+	}
+	if stats.PendingBacklog != 1 {
+		t.Fatalf("unexpected pending backlog: got=%d want=1", stats.PendingBacklog)
+	}
+
+	all, err := svc.ListContactDeliveryStats()
+	if err != nil || len(all) != 1 || all[0].ContactID != contactID {
+		t.Fatalf("unexpected stats listing: %+v err=%v", all, err)
+	}
+	summary := svc.metrics.DeliveryLatencySummary()
+	if summary.Contacts != 1 || summary.Delivered.Samples != 1 || summary.Delivered.P99Ms != stats.Delivered.P99Ms {
+		t.Fatalf("unexpected aggregate summary: %+v", summary)
+	}
+}
@@ -0,0 +1,52 @@
+package daemonservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+)
+
+func TestInjectedIDGeneratorAndClockMakeMessagesReproducible(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	send := func() (string, time.Time) {
+		svc, err := NewServiceForDaemonWithOptions(newMockConfig(), t.TempDir(), contracts.ServiceOptions{
+			GenerateID: runtimeapp.NewSequentialIDGenerator().GeneratePrefixedID,
+			Now:        runtimeapp.NewSteppingClock(start, time.Second).Now,
+		})
+		if err != nil {
+			t.Fatalf("new service: %v", err)
+		}
+		peer := newBlobTestService(t, newMockConfig(), "peer")
+		peerCard, err := peer.SelfContactCard("peer")
+		if err != nil {
+			t.Fatalf("peer card: %v", err)
+		}
+		mustAddContactCard(t, svc, peerCard)
+		messageID, err := svc.SendMessage(context.Background(), peerCard.IdentityID, "hello")
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		msg, ok := svc.messageStore.GetMessage(messageID)
+		if !ok {
+			t.Fatalf("message %s not stored", messageID)
+		}
+		return messageID, msg.Timestamp
+	}
+
+	firstID, firstAt := send()
+	secondID, secondAt := send()
+	if firstID != secondID || !firstAt.Equal(secondAt) {
+		t.Fatalf("runs differ: %s@%v vs %s@%v", firstID, firstAt, secondID, secondAt)
+	}
+	if firstID != "msg_000000000000000000000001" {
+		t.Fatalf("unexpected message id %q", firstID)
+	}
+	if firstAt.Before(start) || firstAt.After(start.Add(time.Minute)) {
+		t.Fatalf("message time %v does not come from the injected clock", firstAt)
+	}
+}
@@ -16,7 +16,7 @@ const (
 
 var (
 	diagnosticTokenPattern    = regexp.MustCompile(`(?i)\b(rpc_[a-z0-9._-]+)\b`)
-	diagnosticIdentityPattern = regexp.MustCompile(`\baim1[0-9a-zA-Z]+\b`)
+	diagnosticIdentityPattern = regexp.MustCompile(`\b(aim[1-9])[0-9a-zA-Z]+\b`)
 	diagnosticSecretKVPattern = regexp.MustCompile(`(?i)\b(token|secret|password|passphrase|private[_-]?key)\s*[:=]\s*([^\s,;]+)`)
 )
 
@@ -123,7 +123,7 @@ func sanitizeDiagnosticText(value string) string {
 		return parts[1] + "=[REDACTED]"
 	})
 	value = diagnosticTokenPattern.ReplaceAllString(value, "rpc_[REDACTED]")
-	value = diagnosticIdentityPattern.ReplaceAllString(value, "aim1[REDACTED]")
+	value = diagnosticIdentityPattern.ReplaceAllString(value, "${1}[REDACTED]")
 	return value
 }
 
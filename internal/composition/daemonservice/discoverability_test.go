@@ -0,0 +1,56 @@
+package daemonservice
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestHiddenIdentityIgnoresStrangers(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "bob"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	identity, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(privacydomain.MessagePrivacyRequests)); err != nil {
+		t.Fatalf("requests mode: %v", err)
+	}
+	if !svc.shouldAnnounceBlobFromPeer("peer-1") {
+		t.Fatal("discoverable identity should announce blobs")
+	}
+	if _, err := svc.UpdateDiscoverability(string(privacydomain.DiscoverabilityContactsOnly)); err != nil {
+		t.Fatalf("hide identity: %v", err)
+	}
+
+	payload, err := json.Marshal(contracts.WirePayload{Kind: "plain", Plain: []byte("hi")})
+	if err != nil {
+		t.Fatalf("marshal wire: %v", err)
+	}
+	svc.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+		ID:        "msg-stranger",
+		SenderID:  "aim1stranger",
+		Recipient: identity.ID,
+		Payload:   payload,
+	})
+	if _, ok := svc.snapshotRequestInbox()["aim1stranger"]; ok {
+		t.Fatal("hidden identity must not queue requests from strangers")
+	}
+	if svc.ignoreInboundSender(identity.ID) {
+		t.Fatal("own devices must never be ignored")
+	}
+	if svc.shouldAnnounceBlobFromPeer("peer-1") {
+		t.Fatal("hidden identity must not announce blobs to provider directories")
+	}
+}
@@ -0,0 +1,35 @@
+package daemonservice
+
+import (
+	"context"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+)
+
+// The hooks below give embedders' integration tests deterministic control
+// over work the daemon otherwise does on its own schedule. They are not part
+// of the RPC surface.
+
+// SignWire composes wire as a signed private message from this daemon to
+// recipient without publishing it. Handing the result to the recipient's
+// HandleIncomingPrivateMessage delivers it without a transport.
+func (s *Service) SignWire(messageID, recipient string, wire contracts.WirePayload) (messagingapp.InboundPrivateMessage, error) {
+	msg, err := messagingapp.ComposeSignedPrivateMessage(messageID, recipient, wire, s.identityManager)
+	if err != nil {
+		return messagingapp.InboundPrivateMessage{}, err
+	}
+	return toInboundPrivateMessage(msg), nil
+}
+
+// RunRetryTick runs one pass of the retry loop as if the clock read now, so
+// retries, card refreshes and retention can be driven past their deadlines
+// without waiting. Network work is skipped while networking is stopped.
+func (s *Service) RunRetryTick(now time.Time) {
+	ctx, err := s.networkContext("")
+	if err != nil {
+		ctx = context.Background()
+	}
+	s.runRetryTick(ctx, now, 0)
+}
@@ -2,7 +2,10 @@ package daemonservice
 
 import (
 	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
+	"aim-chat/go-backend/internal/waku"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +13,8 @@ import (
 
 const enrollmentRedeemedStoreFile = "enrollment-redeemed-server.json"
 
+var errBlobProviderNotGranted = errors.New("blob provider is not claimed by the enrollment token")
+
 func (s *Service) configureEnrollmentTokenFlow() error {
 	keysRaw := strings.TrimSpace(os.Getenv("AIM_ENROLLMENT_ISSUER_KEYS"))
 	if keysRaw == "" {
@@ -31,6 +36,24 @@ func (s *Service) configureEnrollmentTokenFlow() error {
 	return nil
 }
 
+// applyEnrollmentGrant enforces the grant saved in dataDir by the last
+// enrollment before the node starts: unclaimed subsystems stay off and a
+// node configured for an unclaimed network segment does not start at all.
+func applyEnrollmentGrant(wakuCfg *waku.Config, dataDir string, logger *slog.Logger) (enrollmenttoken.Grant, error) {
+	grant, ok, err := enrollmenttoken.LoadGrant(dataDir)
+	if err != nil || !ok {
+		return enrollmenttoken.Grant{}, err
+	}
+	if !grant.AllowsSegment(wakuCfg.NetworkSegment) {
+		return enrollmenttoken.Grant{}, fmt.Errorf("%w: %q", enrollmenttoken.ErrSegmentNotGranted, wakuCfg.NetworkSegment)
+	}
+	if wakuCfg.StoreNodeEnabled && !grant.Allows(enrollmenttoken.CapabilityStoreEnabled) {
+		logger.Warn("store node disabled: not claimed by the enrollment token", "token_id", grant.TokenID)
+		wakuCfg.StoreNodeEnabled = false
+	}
+	return grant, nil
+}
+
 func (s *Service) RedeemEnrollmentToken(token string) (enrollmenttoken.Claims, error) {
 	if len(s.enrollmentKeys) == 0 || s.enrollmentStore == nil {
 		return enrollmenttoken.Claims{}, errors.New("enrollment token flow is not configured")
@@ -39,22 +62,27 @@ func (s *Service) RedeemEnrollmentToken(token string) (enrollmenttoken.Claims, e
 		RequiredIssuer: enrollmenttoken.RequiredIssuer,
 		RequiredScope:  enrollmenttoken.RequiredScope,
 		PublicKeys:     s.enrollmentKeys,
+		Now:            s.now,
 	}
 	claims, audit, err := verifier.VerifyAndRedeem(token, s.enrollmentStore)
+	var clockAttrs []any
+	if err == nil || errors.Is(err, enrollmenttoken.ErrTokenExpired) {
+		clockAttrs = s.annotateExpiry(audit.EventType, audit.ExpiresAt)
+	}
 	if err != nil {
-		s.logger.Warn("enrollment token redeem rejected",
+		s.logger.Warn("enrollment token redeem rejected", append([]any{
 			"event_type", "enrollment.token.redeemed",
 			"result", "rejected",
 			"reason", err.Error(),
-		)
+		}, clockAttrs...)...)
 		return enrollmenttoken.Claims{}, err
 	}
-	s.logger.Info("enrollment token redeemed",
+	s.logger.Info("enrollment token redeemed", append([]any{
 		"event_type", audit.EventType,
 		"token_id", audit.TokenID,
 		"issuer", audit.Issuer,
 		"key_id", audit.KeyID,
 		"result", audit.Result,
-	)
+	}, clockAttrs...)...)
 	return claims, nil
 }
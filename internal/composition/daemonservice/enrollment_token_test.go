@@ -49,7 +49,8 @@ func TestRedeemEnrollmentTokenExpiredRejected(t *testing.T) {
 func TestRedeemEnrollmentTokenSingleUse(t *testing.T) {
 	pub, prv := mustIssuerPair(t)
 	t.Setenv("AIM_ENROLLMENT_ISSUER_KEYS", "issuer-k1:"+base64.StdEncoding.EncodeToString(pub))
-	svc, err := NewServiceForDaemonWithDataDir(waku.DefaultConfig(), t.TempDir())
+	dataDir := t.TempDir()
+	svc, err := NewServiceForDaemonWithDataDir(waku.DefaultConfig(), dataDir)
 	if err != nil {
 		t.Fatalf("create daemon service: %v", err)
 	}
@@ -73,6 +74,9 @@ func TestRedeemEnrollmentTokenSingleUse(t *testing.T) {
 	if first.TokenID != claims.TokenID {
 		t.Fatalf("unexpected first redeem token_id: %q", first.TokenID)
 	}
+	if _, ok, err := enrollmenttoken.LoadGrant(dataDir); err != nil || ok {
+		t.Fatalf("redeeming on the issuing daemon must not restrict it: ok=%v err=%v", ok, err)
+	}
 	_, err = svc.RedeemEnrollmentToken(token)
 	if !errors.Is(err, enrollmenttoken.ErrTokenAlreadyUsed) {
 		t.Fatalf("expected ErrTokenAlreadyUsed, got %v", err)
@@ -104,3 +108,32 @@ func TestRedeemEnrollmentTokenInvalidIssuerRejected(t *testing.T) {
 		t.Fatalf("expected ErrTokenIssuerInvalid, got %v", err)
 	}
 }
+
+func TestDaemonStartupEnforcesEnrollmentGrant(t *testing.T) {
+	dataDir := t.TempDir()
+	grant := enrollmenttoken.Grant{TokenID: "tok-relay", Capabilities: []string{enrollmenttoken.CapabilityRelayOnly}, NetworkSegments: []string{"eu-1"}}
+	if err := enrollmenttoken.SaveGrant(dataDir, grant); err != nil {
+		t.Fatalf("save grant: %v", err)
+	}
+	cfg := waku.DefaultConfig()
+	cfg.NetworkSegment = "us-1"
+	if _, err := NewServiceForDaemonWithDataDir(cfg, dataDir); !errors.Is(err, enrollmenttoken.ErrSegmentNotGranted) {
+		t.Fatalf("expected ErrSegmentNotGranted, got %v", err)
+	}
+
+	cfg.NetworkSegment = "eu-1"
+	cfg.StoreNodeEnabled = true
+	svc, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("create daemon service: %v", err)
+	}
+	if svc.wakuCfg.StoreNodeEnabled {
+		t.Fatal("expected the unclaimed store node to stay disabled")
+	}
+	if svc.GetBlobFeatureFlags().AnnounceEnabled {
+		t.Fatal("expected blob provider announcements to stay disabled")
+	}
+	if _, err := svc.SetBlobFeatureFlags(true, true, 100); !errors.Is(err, errBlobProviderNotGranted) {
+		t.Fatalf("expected errBlobProviderNotGranted, got %v", err)
+	}
+}
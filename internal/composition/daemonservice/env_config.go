@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func envString(key string) string {
@@ -54,3 +55,15 @@ func envBoundedIntWithFallback(key string, fallback, min, max int) int {
 	}
 	return value
 }
+
+func envDurationWithFallback(key string, fallback time.Duration) time.Duration {
+	raw := envString(key)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed < 0 {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,78 @@
+package daemonservice
+
+import (
+	"context"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+)
+
+type powDifficultyAdvertiser interface {
+	SetAdvertisedPowDifficulty(bits int)
+}
+
+// UpdateFirstContactPow sets the proof-of-work unknown senders must attach to
+// their first messages and advertises it in cards signed from now on.
+func (s *Service) UpdateFirstContactPow(bits int) (privacydomain.PrivacySettings, error) {
+	settings, err := s.privacyCore.UpdateFirstContactPow(bits)
+	if err != nil {
+		return privacydomain.PrivacySettings{}, err
+	}
+	s.applyFirstContactPowFromSettings(settings)
+	return settings, nil
+}
+
+func (s *Service) applyFirstContactPowFromSettings(settings privacydomain.PrivacySettings) {
+	if advertiser, ok := s.identityManager.(powDifficultyAdvertiser); ok {
+		advertiser.SetAdvertisedPowDifficulty(settings.FirstContactPowBits)
+	}
+}
+
+// verifyFirstContactPow enforces our difficulty on chat messages from senders
+// that are not contacts yet.
+func (s *Service) verifyFirstContactPow(msg messagingapp.InboundPrivateMessage, wire contracts.WirePayload) error {
+	required := s.privacyCore.FirstContactPowBits()
+	if !privacydomain.FirstContactPowRequired(s.identityManager.HasContact(msg.SenderID), required) {
+		return nil
+	}
+	return privacydomain.VerifyPowStamp(wire.Pow, msg.SenderID, s.identityManager.GetIdentity().ID, msg.ID, required, time.Now())
+}
+
+// attachFirstContactPow stamps chat messages to a contact that advertised a
+// difficulty until that contact has written back, which proves they accepted
+// us and no longer treat us as a stranger.
+func (s *Service) attachFirstContactPow(ctx context.Context, messageID, recipient string, wire contracts.WirePayload) (contracts.WirePayload, error) {
+	if !messagingapp.IsDirectChatWire(wire) {
+		return wire, nil
+	}
+	difficulty := s.contactPowDifficulty(recipient)
+	if difficulty <= 0 || s.hasInboundMessageFrom(recipient) {
+		return wire, nil
+	}
+	stamp, err := privacydomain.MintPowStamp(ctx, s.identityManager.GetIdentity().ID, recipient, messageID, difficulty, time.Now())
+	if err != nil {
+		return contracts.WirePayload{}, contracts.WrapCategorizedError(contracts.ErrorCategoryAPI, err)
+	}
+	wire.Pow = &stamp
+	return wire, nil
+}
+
+func (s *Service) contactPowDifficulty(contactID string) int {
+	for _, contact := range s.identityManager.Contacts() {
+		if contact.ID == contactID {
+			return contact.PowDifficulty
+		}
+	}
+	return 0
+}
+
+func (s *Service) hasInboundMessageFrom(contactID string) bool {
+	for _, msg := range s.messageStore.ListMessages(contactID, 0, 0) {
+		if msg.Direction == "in" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package daemonservice
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestRuntimeE2E_FirstContactPowGatesMessageRequests(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+
+	baseDir := t.TempDir()
+	makeService := func(name string) *Service {
+		svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new service %s: %v", name, err)
+		}
+		return svc
+	}
+	alice := makeService("alice")
+	bob := makeService("bob")
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	bobIdentity, err := bob.GetIdentity()
+	if err != nil {
+		t.Fatalf("bob identity: %v", err)
+	}
+	if _, err := bob.UpdatePrivacySettings(string(privacydomain.MessagePrivacyRequests)); err != nil {
+		t.Fatalf("bob requests mode: %v", err)
+	}
+	if _, err := bob.UpdateFirstContactPow(8); err != nil {
+		t.Fatalf("bob pow difficulty: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	if bobCard.PowDifficulty != 8 {
+		t.Fatalf("card must advertise the difficulty, got %d", bobCard.PowDifficulty)
+	}
+	mustAddContactCard(t, alice, bobCard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	// A stranger without a stamp is dropped before reaching the request inbox.
+	unstamped, err := json.Marshal(contracts.WirePayload{Kind: "plain", Plain: []byte("spam")})
+	if err != nil {
+		t.Fatalf("marshal wire: %v", err)
+	}
+	bob.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+		ID:        "msg-spam",
+		SenderID:  "aim1stranger",
+		Recipient: bobIdentity.ID,
+		Payload:   unstamped,
+	})
+	if _, ok := bob.snapshotRequestInbox()["aim1stranger"]; ok {
+		t.Fatal("unstamped first-contact message must not be queued")
+	}
+
+	aliceIdentity, err := alice.GetIdentity()
+	if err != nil {
+		t.Fatalf("alice identity: %v", err)
+	}
+	if _, err := alice.SendMessage(context.Background(), bobIdentity.ID, "hello bob"); err != nil {
+		t.Fatalf("alice send: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(bob.snapshotRequestInbox()[aliceIdentity.ID]) > 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("stamped first-contact message was not queued as a request")
+}
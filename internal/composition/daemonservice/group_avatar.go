@@ -0,0 +1,102 @@
+package daemonservice
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// SetGroupAvatar resizes an uploaded image, makes it the group avatar and
+// sends the bytes to the other active members inside the signed profile
+// change so they can verify them against the event's hash.
+func (s *Service) SetGroupAvatar(groupID, dataBase64 string) (groupdomain.Group, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(dataBase64))
+	if err != nil {
+		return groupdomain.Group{}, groupdomain.ErrInvalidGroupAvatar
+	}
+	avatar, err := groupdomain.NormalizeGroupAvatar(data)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	current, err := s.groupCore.GetGroup(groupID)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	ref := groupdomain.GroupAvatarRef(avatar.SHA256)
+	group, err := s.groupCore.UpdateGroupProfile(current.ID, current.Title, current.Description, ref)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	avatar.GroupID = group.ID
+	avatar.UpdatedAt = time.Now().UTC()
+	if err := s.groupAvatars.Put(avatar); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return groupdomain.Group{}, err
+	}
+	s.distributeGroupAvatar(group.ID, ref, avatar)
+	return group, nil
+}
+
+// GetGroupAvatar returns the cached avatar image while it is still the one
+// the group profile points at.
+func (s *Service) GetGroupAvatar(groupID string) (models.GroupAvatar, error) {
+	group, err := s.groupCore.GetGroup(groupID)
+	if err != nil {
+		return models.GroupAvatar{}, err
+	}
+	hash, ok := groupdomain.ParseGroupAvatarRef(group.Avatar)
+	if !ok {
+		return models.GroupAvatar{}, storage.ErrGroupAvatarNotFound
+	}
+	avatar, ok := s.groupAvatars.Get(group.ID)
+	if !ok || avatar.SHA256 != hash {
+		return models.GroupAvatar{}, storage.ErrGroupAvatarNotFound
+	}
+	return avatar, nil
+}
+
+func (s *Service) distributeGroupAvatar(groupID, ref string, avatar models.GroupAvatar) {
+	event, ok := s.latestGroupAvatarEvent(groupID, ref)
+	if !ok {
+		return
+	}
+	s.distributeGroupEvent(event, &avatar)
+}
+
+func (s *Service) latestGroupAvatarEvent(groupID, ref string) (groupdomain.GroupEvent, bool) {
+	events := s.snapshotGroupEvents(groupID)
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Type == groupdomain.GroupEventTypeProfileChange {
+			return events[i], events[i].Avatar == ref
+		}
+	}
+	return groupdomain.GroupEvent{}, false
+}
+
+// cacheInboundGroupAvatar stores avatar bytes that arrived with a profile
+// change once that change is the group's current profile. The caller holds
+// the group state lock and has already verified the hash.
+func (s *Service) cacheInboundGroupAvatar(event groupdomain.GroupEvent, avatar models.GroupAvatar) {
+	state, ok := s.groupRuntime.States[event.GroupID]
+	if !ok || state.Group.Avatar != event.Avatar {
+		return
+	}
+	if cached, ok := s.groupAvatars.Get(event.GroupID); ok && cached.SHA256 == avatar.SHA256 {
+		return
+	}
+	avatar.GroupID = event.GroupID
+	avatar.UpdatedAt = event.OccurredAt
+	if err := s.groupAvatars.Put(avatar); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	s.notify("notify.group.avatar", map[string]any{
+		"group_id": event.GroupID,
+		"sha256":   avatar.SHA256,
+	})
+}
@@ -0,0 +1,106 @@
+package daemonservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func testAvatarPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestRuntimeE2E_GroupAvatarReachesMembers(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+
+	groupID := "group_avatar_e2e"
+	members := []string{aliceCard.IdentityID, bobCard.IdentityID}
+	// Separate seeds so the two runtimes do not share membership maps.
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, "Avatar Group", aliceCard.IdentityID, members), alice)
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, "Avatar Group", aliceCard.IdentityID, members), bob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	if _, err := bob.SetGroupAvatar(groupID, testAvatarPNG(t, 64, 64)); err == nil {
+		t.Fatal("regular members must not change the group avatar")
+	}
+	group, err := alice.SetGroupAvatar(groupID, testAvatarPNG(t, 1024, 512))
+	if err != nil {
+		t.Fatalf("alice set avatar: %v", err)
+	}
+	local, err := alice.GetGroupAvatar(groupID)
+	if err != nil {
+		t.Fatalf("alice get avatar: %v", err)
+	}
+	if local.Width != groupdomain.GroupAvatarMaxDimension || local.Height != groupdomain.GroupAvatarMaxDimension/2 {
+		t.Fatalf("avatar must be resized to fit, got %dx%d", local.Width, local.Height)
+	}
+	if group.Avatar != groupdomain.GroupAvatarRef(local.SHA256) {
+		t.Fatalf("group avatar must reference the asset hash, got %q", group.Avatar)
+	}
+
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		remote, err := bob.GetGroupAvatar(groupID)
+		if err == nil {
+			if !bytes.Equal(remote.Data, local.Data) {
+				t.Fatal("member cached different avatar bytes")
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("group avatar was not delivered to the member")
+}
@@ -0,0 +1,96 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestGroupMemberBlockHidesMessagesInThatGroupOnly(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	const (
+		noisy  = "aim1noisymember0001"
+		quiet  = "aim1quietmember0001"
+		banned = "aim1bannedmember001"
+	)
+	members := []string{self.ID, noisy, quiet, banned}
+	first := seededActiveGroupState("group_blocks_first", "First", self.ID, members)
+	second := seededActiveGroupState("group_blocks_second", "Second", self.ID, members)
+	svc.groupRuntime.SetSnapshot(
+		map[string]groupdomain.GroupState{first.Group.ID: first, second.Group.ID: second},
+		map[string][]groupdomain.GroupEvent{first.Group.ID: {}, second.Group.ID: {}},
+	)
+
+	if _, err := svc.BlockGroupMember(first.Group.ID, self.ID); err == nil {
+		t.Fatal("expected blocking self to be rejected")
+	}
+	if _, err := svc.BlockGroupMember("group_unknown", noisy); err == nil {
+		t.Fatal("expected an unknown group to be rejected")
+	}
+	blocked, err := svc.BlockGroupMember(first.Group.ID, noisy)
+	if err != nil || len(blocked) != 1 || blocked[0] != noisy {
+		t.Fatalf("block group member: %v %v", blocked, err)
+	}
+	if _, err := svc.AddToBlocklist(banned); err != nil {
+		t.Fatalf("add to blocklist: %v", err)
+	}
+
+	listed, err := svc.ListGroupMembers(first.Group.ID)
+	if err != nil {
+		t.Fatalf("list group members: %v", err)
+	}
+	scopes := map[string]string{}
+	for _, member := range listed {
+		scopes[member.MemberID] = member.BlockScope
+	}
+	if scopes[noisy] != groupdomain.GroupMemberBlockScopeGroup || scopes[banned] != groupdomain.GroupMemberBlockScopeGlobal || scopes[quiet] != "" {
+		t.Fatalf("unexpected block scopes: %v", scopes)
+	}
+
+	deliver := func(groupID, senderID, messageID string) {
+		svc.handleInboundGroupMessage(
+			messagingapp.InboundPrivateMessage{ID: messageID, SenderID: senderID},
+			contracts.WirePayload{
+				Kind:              "plain",
+				Plain:             []byte("hello"),
+				ConversationID:    groupID,
+				EventID:           messageID,
+				MembershipVersion: first.Version,
+				GroupKeyVersion:   first.LastKeyVersion,
+				SenderDeviceID:    senderID + "-device",
+			},
+		)
+	}
+	deliver(first.Group.ID, noisy, "msg-hidden")
+	deliver(first.Group.ID, quiet, "msg-first")
+	deliver(second.Group.ID, noisy, "msg-second")
+	if _, ok := svc.messageStore.GetMessage("msg-hidden"); ok {
+		t.Fatal("expected the blocked member's message to be hidden")
+	}
+	for _, id := range []string{"msg-first", "msg-second"} {
+		if _, ok := svc.messageStore.GetMessage(id); !ok {
+			t.Fatalf("expected %s to be stored", id)
+		}
+	}
+
+	if blocked, err := svc.UnblockGroupMember(first.Group.ID, noisy); err != nil || len(blocked) != 0 {
+		t.Fatalf("unblock group member: %v %v", blocked, err)
+	}
+	deliver(first.Group.ID, noisy, "msg-visible")
+	if _, ok := svc.messageStore.GetMessage("msg-visible"); !ok {
+		t.Fatal("expected messages to show again after unblocking")
+	}
+}
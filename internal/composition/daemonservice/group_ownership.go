@@ -0,0 +1,16 @@
+package daemonservice
+
+import groupdomain "aim-chat/go-backend/internal/domains/group"
+
+// TransferGroupOwnership hands the group to an active admin and sends the
+// signed transfer to the members. This identity stays on as an admin.
+func (s *Service) TransferGroupOwnership(groupID, newOwnerID string) (groupdomain.GroupMember, error) {
+	member, event, err := s.groupCore.TransferGroupOwnership(groupID, newOwnerID)
+	if err != nil {
+		return groupdomain.GroupMember{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return member, nil
+}
@@ -0,0 +1,92 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestRuntimeE2E_GroupOwnershipTransfer(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceCard.IdentityID, aliceCard.PublicKey, bob, bobCard.IdentityID, bobCard.PublicKey)
+
+	groupID := "group_ownership_e2e"
+	members := []string{aliceCard.IdentityID, bobCard.IdentityID}
+	seed := func() groupdomain.GroupState {
+		state := seededActiveGroupState(groupID, "Ownership E2E", aliceCard.IdentityID, members)
+		admin := state.Members[bobCard.IdentityID]
+		admin.Role = groupdomain.GroupMemberRoleAdmin
+		state.Members[bobCard.IdentityID] = admin
+		return state
+	}
+	applySeedGroupState(groupID, seed(), alice)
+	applySeedGroupState(groupID, seed(), bob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	if _, err := alice.TransferGroupOwnership(groupID, aliceCard.IdentityID); !errors.Is(err, groupdomain.ErrInvalidGroupOwnershipTarget) {
+		t.Fatalf("owner must not transfer to itself, got %v", err)
+	}
+	if _, err := bob.TransferGroupOwnership(groupID, aliceCard.IdentityID); !errors.Is(err, groupdomain.ErrGroupPermissionDenied) {
+		t.Fatalf("only the owner may transfer, got %v", err)
+	}
+
+	owner, err := alice.TransferGroupOwnership(groupID, bobCard.IdentityID)
+	if err != nil {
+		t.Fatalf("alice transfer ownership: %v", err)
+	}
+	if !owner.IsOwner() {
+		t.Fatalf("bob must be the owner locally: %+v", owner)
+	}
+	waitForGroupMember(t, bob, groupID, bobCard.IdentityID, func(member groupdomain.GroupMember) bool {
+		return member.IsOwner()
+	})
+	waitForGroupMember(t, bob, groupID, aliceCard.IdentityID, func(member groupdomain.GroupMember) bool {
+		return member.Role == groupdomain.GroupMemberRoleAdmin
+	})
+
+	// The new owner's owner-only changes must be accepted by the old owner.
+	if _, err := bob.TransferGroupOwnership(groupID, aliceCard.IdentityID); err != nil {
+		t.Fatalf("bob transfer ownership back: %v", err)
+	}
+	waitForGroupMember(t, alice, groupID, aliceCard.IdentityID, func(member groupdomain.GroupMember) bool {
+		return member.IsOwner()
+	})
+}
@@ -0,0 +1,30 @@
+package daemonservice
+
+import (
+	"strings"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+)
+
+const (
+	groupPersistenceModeEnv = "AIM_GROUP_PERSISTENCE"
+	groupSnapshotEveryEnv   = "AIM_GROUP_SNAPSHOT_EVERY"
+)
+
+// groupStatePersistence is implemented by both the snapshot store and the
+// event-sourced store so the mode can be switched per deployment.
+type groupStatePersistence interface {
+	Configure(path, secret string)
+	Bootstrap() (map[string]groupdomain.GroupState, map[string][]groupdomain.GroupEvent, error)
+	Persist(states map[string]groupdomain.GroupState, eventLog map[string][]groupdomain.GroupEvent) error
+	Wipe() error
+}
+
+func newGroupStateStoreFromEnv() groupStatePersistence {
+	switch strings.ToLower(envString(groupPersistenceModeEnv)) {
+	case "event_sourced", "event-sourced", "events":
+		return groupdomain.NewEventSourcedStore(envIntWithFallback(groupSnapshotEveryEnv, groupdomain.DefaultGroupSnapshotEvery))
+	default:
+		return groupdomain.NewSnapshotStore()
+	}
+}
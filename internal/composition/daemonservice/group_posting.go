@@ -0,0 +1,47 @@
+package daemonservice
+
+import (
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+)
+
+// MuteGroupMember keeps a member from posting until the given time, or
+// unmutes them for a zero time, and sends the change to the members, who
+// reject the muted member's posts on receipt.
+func (s *Service) MuteGroupMember(groupID, memberID string, until time.Time) (groupdomain.GroupMember, error) {
+	member, event, err := s.groupCore.MuteGroupMember(groupID, memberID, until)
+	if err != nil {
+		return groupdomain.GroupMember{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return member, nil
+}
+
+// SetGroupPostingMode switches a group between everyone posting and owners
+// and admins only.
+func (s *Service) SetGroupPostingMode(groupID, mode string) (groupdomain.Group, error) {
+	group, event, err := s.groupCore.SetGroupPostingMode(groupID, mode)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return group, nil
+}
+
+// SetGroupMemberPrivacy hides the member list from plain members and has
+// every member attribute messages to pseudonyms, or undoes it.
+func (s *Service) SetGroupMemberPrivacy(groupID string, hidden bool) (groupdomain.Group, error) {
+	group, event, err := s.groupCore.SetGroupMemberPrivacy(groupID, hidden)
+	if err != nil {
+		return groupdomain.Group{}, err
+	}
+	if event.ID != "" {
+		s.distributeGroupEvent(event, nil)
+	}
+	return group, nil
+}
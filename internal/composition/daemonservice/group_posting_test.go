@@ -0,0 +1,121 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestRuntimeE2E_GroupMuteAndAdminsOnlyPosting(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new alice service: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new bob service: %v", err)
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceCard.IdentityID, aliceCard.PublicKey, bob, bobCard.IdentityID, bobCard.PublicKey)
+
+	groupID := "group_posting_e2e"
+	members := []string{aliceCard.IdentityID, bobCard.IdentityID}
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, "Posting E2E", aliceCard.IdentityID, members), alice)
+	applySeedGroupState(groupID, seededActiveGroupState(groupID, "Posting E2E", aliceCard.IdentityID, members), bob)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	if _, err := bob.MuteGroupMember(groupID, aliceCard.IdentityID, time.Now().Add(time.Hour)); err == nil {
+		t.Fatal("members must not mute the owner")
+	}
+	if _, err := alice.MuteGroupMember(groupID, bobCard.IdentityID, time.Now().Add(-time.Minute)); !errors.Is(err, groupdomain.ErrInvalidGroupMuteUntil) {
+		t.Fatalf("mute in the past must be rejected, got %v", err)
+	}
+	if _, err := alice.MuteGroupMember(groupID, bobCard.IdentityID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("alice mute bob: %v", err)
+	}
+	waitForGroupMember(t, bob, groupID, bobCard.IdentityID, func(member groupdomain.GroupMember) bool {
+		return member.IsMuted(time.Now())
+	})
+	if _, err := bob.SendGroupMessage(context.Background(), groupID, "muted"); !errors.Is(err, groupdomain.ErrGroupMemberMuted) {
+		t.Fatalf("muted member must not post, got %v", err)
+	}
+
+	if _, err := alice.MuteGroupMember(groupID, bobCard.IdentityID, time.Time{}); err != nil {
+		t.Fatalf("alice unmute bob: %v", err)
+	}
+	waitForGroupMember(t, bob, groupID, bobCard.IdentityID, func(member groupdomain.GroupMember) bool {
+		return !member.IsMuted(time.Now())
+	})
+	if _, err := bob.SendGroupMessage(context.Background(), groupID, "unmuted"); err != nil {
+		t.Fatalf("bob post after unmute: %v", err)
+	}
+	waitForGroupMessage(t, alice, groupID, "unmuted")
+
+	if _, err := bob.SetGroupPostingMode(groupID, "admins_only"); err == nil {
+		t.Fatal("members must not change the posting mode")
+	}
+	if _, err := alice.SetGroupPostingMode(groupID, "readonly"); !errors.Is(err, groupdomain.ErrInvalidGroupPostingMode) {
+		t.Fatalf("unknown posting mode must be rejected, got %v", err)
+	}
+	if _, err := alice.SetGroupPostingMode(groupID, "admins_only"); err != nil {
+		t.Fatalf("alice set admins only: %v", err)
+	}
+	waitForGroup(t, bob, groupID, func(group groupdomain.Group) bool {
+		return group.PostingMode == groupdomain.GroupPostingModeAdminsOnly
+	})
+	if _, err := bob.SendGroupMessage(context.Background(), groupID, "restricted"); !errors.Is(err, groupdomain.ErrGroupPostingRestricted) {
+		t.Fatalf("admins-only group must reject member posts, got %v", err)
+	}
+	if _, err := alice.SendGroupMessage(context.Background(), groupID, "announcement"); err != nil {
+		t.Fatalf("alice post in admins-only group: %v", err)
+	}
+	waitForGroupMessage(t, bob, groupID, "announcement")
+}
+
+func waitForGroupMember(t *testing.T, svc *Service, groupID, memberID string, ready func(groupdomain.GroupMember) bool) {
+	t.Helper()
+	deadline := time.Now().Add(8 * time.Second)
+	for time.Now().Before(deadline) {
+		members, err := svc.ListGroupMembers(groupID)
+		if err != nil {
+			t.Fatalf("list group members %s: %v", groupID, err)
+		}
+		for _, member := range members {
+			if member.MemberID == memberID && ready(member) {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("member %s of group %s did not reach the expected state", memberID, groupID)
+}
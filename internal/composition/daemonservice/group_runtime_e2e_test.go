@@ -88,7 +88,7 @@ func TestRuntimeE2E_GroupChatThreeProfiles(t *testing.T) {
 	)
 
 	messageText := "runtime-group-e2e-" + time.Now().UTC().Format("20060102150405.000000000")
-	fanout, err := alice.SendGroupMessage(groupID, messageText)
+	fanout, err := alice.SendGroupMessage(context.Background(), groupID, messageText)
 	if err != nil {
 		t.Fatalf("alice send group message: %v", err)
 	}
@@ -163,12 +163,12 @@ func TestRuntimeE2E_ChannelPublishPermissions(t *testing.T) {
 	)
 
 	ownerMsg := "runtime-channel-owner-" + time.Now().UTC().Format("20060102150405.000000000")
-	if _, err := alice.SendGroupMessage(groupID, ownerMsg); err != nil {
+	if _, err := alice.SendGroupMessage(context.Background(), groupID, ownerMsg); err != nil {
 		t.Fatalf("alice send channel message: %v", err)
 	}
 	waitForGroupMessage(t, bob, groupID, ownerMsg)
 
-	if _, err := bob.SendGroupMessage(groupID, "runtime-channel-user-denied"); err == nil {
+	if _, err := bob.SendGroupMessage(context.Background(), groupID, "runtime-channel-user-denied"); err == nil {
 		t.Fatalf("expected permission error for user publish")
 	} else if !strings.Contains(strings.ToLower(err.Error()), "permission denied") {
 		t.Fatalf("expected permission denied error, got: %v", err)
@@ -1,13 +1,13 @@
 package daemonservice
 
 import (
+	"aim-chat/go-backend/internal/domains/contracts"
 	groupdomain "aim-chat/go-backend/internal/domains/group"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
-	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"errors"
 	"strings"
-	"time"
 )
 
 func (s *Service) groupUseCases() *groupdomain.Service {
@@ -15,9 +15,10 @@ func (s *Service) groupUseCases() *groupdomain.Service {
 		IdentityID:           func() string { return s.identityManager.GetIdentity().ID },
 		WithMembership:       s.withGroupMembership,
 		SnapshotStates:       s.snapshotGroupStates,
-		GenerateID:           runtimeapp.GeneratePrefixedID,
+		SnapshotEvents:       s.snapshotGroupEvents,
+		GenerateID:           s.generateID,
 		GenerateEventID:      s.mustGenerateEventID,
-		Now:                  time.Now,
+		Now:                  s.now,
 		Abuse:                s.groupAbuse,
 		IsBlockedSender:      s.privacyCore.IsBlockedSender,
 		ActiveDeviceID:       s.activeDeviceID,
@@ -40,6 +41,30 @@ func (s *Service) withGroupMembership(fn func(ms *groupdomain.MembershipService)
 	return fn(s.groupMembershipServiceLocked())
 }
 
+// GetMessageStatus reports aggregated recipient receipts for group messages
+// and falls back to the single direct-message status otherwise.
+func (s *Service) GetMessageStatus(messageID string) (models.MessageStatus, error) {
+	return s.messageStatus(messageID, false)
+}
+
+// GetMessageStatusWithMembers is GetMessageStatus with the per-member
+// receipts of group messages listed as well.
+func (s *Service) GetMessageStatusWithMembers(messageID string) (models.MessageStatus, error) {
+	return s.messageStatus(messageID, true)
+}
+
+func (s *Service) messageStatus(messageID string, includeMembers bool) (models.MessageStatus, error) {
+	status, err := s.messagingCore.GetMessageStatus(messageID)
+	if err != nil {
+		return status, err
+	}
+	msg, ok := s.messageStore.GetMessage(status.MessageID)
+	if !ok || msg.ConversationType != models.ConversationTypeGroup {
+		return status, nil
+	}
+	return s.groupCore.GetGroupMessageReceipts(msg.ConversationID, msg.ID, includeMembers)
+}
+
 func (s *Service) snapshotGroupStates() map[string]groupdomain.GroupState {
 	s.groupRuntime.StateMu.RLock()
 	defer s.groupRuntime.StateMu.RUnlock()
@@ -50,6 +75,12 @@ func (s *Service) snapshotGroupStates() map[string]groupdomain.GroupState {
 	return out
 }
 
+func (s *Service) snapshotGroupEvents(groupID string) []groupdomain.GroupEvent {
+	s.groupRuntime.StateMu.RLock()
+	defer s.groupRuntime.StateMu.RUnlock()
+	return append([]groupdomain.GroupEvent(nil), s.groupRuntime.EventLog[groupID]...)
+}
+
 func (s *Service) groupMembershipServiceLocked() *groupdomain.MembershipService {
 	return &groupdomain.MembershipService{
 		States:   s.groupRuntime.States,
@@ -66,14 +97,14 @@ func (s *Service) groupMembershipServiceLocked() *groupdomain.MembershipService
 }
 
 func (s *Service) mustGenerateEventID() string {
-	eventID, err := runtimeapp.GeneratePrefixedID("gevt")
+	eventID, err := s.generateID("gevt")
 	if err != nil {
-		return "gevt_fallback_" + time.Now().UTC().Format("20060102150405.000000000")
+		return "gevt_fallback_" + s.now().UTC().Format("20060102150405.000000000")
 	}
 	return eventID
 }
 
-func (s *Service) prepareAndPublishGroupMessage(msg models.Message, recipientID string, meta groupdomain.GroupMessageWireMeta) (string, string, error) {
+func (s *Service) prepareAndPublishGroupMessage(ctx context.Context, msg models.Message, recipientID string, meta groupdomain.GroupMessageWireMeta) (string, string, error) {
 	wire, _, err := messagingapp.BuildWireForOutboundMessage(msg, s.sessionManager)
 	if err != nil {
 		return "", messagingapp.ErrorCategory(err), err
@@ -86,14 +117,69 @@ func (s *Service) prepareAndPublishGroupMessage(msg models.Message, recipientID
 	wire.MembershipVersion = meta.MembershipVersion
 	wire.GroupKeyVersion = meta.GroupKeyVersion
 	wire.SenderDeviceID = meta.SenderDeviceID
+	wire.Emergency = meta.Emergency
 
-	sentID, err := s.publishQueuedMessage(msg, recipientID, wire)
+	sentID, err := s.publishQueuedMessage(ctx, msg, recipientID, wire)
 	if err != nil {
 		return "", "", err
 	}
 	return sentID, "", nil
 }
 
+// distributeGroupEvent sends a signed group event to the other active
+// members, who apply it like any inbound event. Avatar bytes ride along with
+// the profile change that references them.
+func (s *Service) distributeGroupEvent(event groupdomain.GroupEvent, avatar *models.GroupAvatar) {
+	ctx, err := s.networkContext("")
+	if err != nil {
+		return
+	}
+	plain, err := groupdomain.EncodeGroupEventPlain(event)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+		return
+	}
+	deviceID, err := s.activeDeviceID()
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryCrypto, err)
+		return
+	}
+	members, err := s.groupCore.ListGroupMembers(event.GroupID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+		return
+	}
+	wirePrefix := "gevw"
+	if avatar != nil {
+		wirePrefix = "gavt"
+	}
+	self := s.identityManager.GetIdentity().ID
+	for _, member := range members {
+		if member.Status != groupdomain.GroupMemberStatusActive || member.MemberID == self {
+			continue
+		}
+		wireID, err := s.generateID(wirePrefix)
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryAPI, err)
+			return
+		}
+		wire := contracts.WirePayload{
+			Kind:              "plain",
+			Plain:             plain,
+			ConversationType:  models.ConversationTypeGroup,
+			ConversationID:    event.GroupID,
+			EventID:           event.ID,
+			EventType:         string(event.Type),
+			MembershipVersion: event.Version,
+			SenderDeviceID:    deviceID,
+			GroupAvatar:       avatar,
+		}
+		if err := s.publishSignedWireWithContext(ctx, wireID, member.MemberID, wire); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}
+}
+
 func (s *Service) activeDeviceID() (string, error) {
 	device, _, err := s.identityManager.ActiveDeviceAuth([]byte("group-device-id"))
 	if err != nil {
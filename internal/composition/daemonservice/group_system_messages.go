@@ -0,0 +1,34 @@
+package daemonservice
+
+import (
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+)
+
+// recordGroupSystemMessageLocked stores the local timeline entry for an
+// applied group event. Callers hold groupRuntime.StateMu, so the event log
+// already contains the event and can be replayed to describe it.
+func (s *Service) recordGroupSystemMessageLocked(event groupdomain.GroupEvent) {
+	if s.messageStore == nil {
+		return
+	}
+	feed := groupdomain.BuildGroupActivityFeed(event.GroupID, s.groupRuntime.EventLog[event.GroupID])
+	for i := len(feed) - 1; i >= 0; i-- {
+		if feed[i].EventID != event.ID {
+			continue
+		}
+		msg := groupdomain.BuildGroupSystemMessage(feed[i])
+		if _, exists := s.messageStore.GetMessage(msg.ID); exists {
+			return
+		}
+		if err := s.messageStore.SaveMessage(msg); err != nil {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+			return
+		}
+		s.notify("notify.group.message.new", map[string]any{
+			"group_id": event.GroupID,
+			"message":  msg,
+		})
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestGroupSystemMessagesFollowEventLog(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	group, err := svc.CreateGroup("Weekend")
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if _, err := svc.UpdateGroupTitle(group.ID, "Weekend trip"); err != nil {
+		t.Fatalf("update title: %v", err)
+	}
+
+	system, err := svc.ListGroupMessagesFiltered(group.ID, "system", 50, 0)
+	if err != nil {
+		t.Fatalf("list system messages: %v", err)
+	}
+	if len(system) != 2 {
+		t.Fatalf("expected 2 system messages, got %+v", system)
+	}
+	for _, msg := range system {
+		if msg.ContentType != models.MessageContentTypeSystem || msg.Status != "read" {
+			t.Fatalf("unexpected system message: %+v", msg)
+		}
+	}
+	if !strings.Contains(string(system[1].Content), `"Weekend trip"`) {
+		t.Fatalf("expected title change text, got %q", system[1].Content)
+	}
+
+	regular, err := svc.ListGroupMessagesFiltered(group.ID, "messages", 50, 0)
+	if err != nil {
+		t.Fatalf("list regular messages: %v", err)
+	}
+	if len(regular) != 0 {
+		t.Fatalf("expected no regular messages, got %+v", regular)
+	}
+	if _, err := svc.ListGroupMessagesFiltered(group.ID, "bogus", 50, 0); err == nil {
+		t.Fatal("expected invalid filter error")
+	}
+
+	activity, err := svc.ListGroupActivity(group.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("list activity: %v", err)
+	}
+	if len(activity) != 2 || activity[0].EventID != system[0].EventID || activity[1].EventID != system[1].EventID {
+		t.Fatalf("activity feed does not match system messages: %+v", activity)
+	}
+}
@@ -0,0 +1,190 @@
+package daemonservice
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	// historyBackfillCheckInterval is how often the retry loop looks for
+	// gaps that are due for a backfill request.
+	historyBackfillCheckInterval = time.Minute
+	// historyGapGrace leaves room for plain reordering before a gap is
+	// treated as lost.
+	historyGapGrace = 2 * time.Minute
+	// historyBackfillRetry spaces repeated requests for the same gap.
+	historyBackfillRetry = time.Hour
+	// historyBackfillReplyInterval limits how often a contact can make us
+	// re-send old messages.
+	historyBackfillReplyInterval = time.Minute
+)
+
+// historyBackfillState throttles backfill requests and replies. It is kept in
+// memory; losing it only delays the next request.
+type historyBackfillState struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	replied   map[string]time.Time
+}
+
+func newHistoryBackfillState() *historyBackfillState {
+	return &historyBackfillState{replied: map[string]time.Time{}}
+}
+
+// observeInboundSeq records the sequence number of a stored direct message
+// and announces gaps it opens.
+func (s *Service) observeInboundSeq(senderID string, wire contracts.WirePayload) {
+	if wire.Seq == 0 || wire.Device == nil {
+		return
+	}
+	deviceID := wire.Device.ID
+	now := time.Now()
+	var opened *models.HistoryGap
+	_, err := s.messageSeqs.UpdateInbound(senderID, deviceID, func(state models.InboundSequence) (models.InboundSequence, bool) {
+		next, changed := messagingapp.ObserveInboundSeq(state, deviceID, wire.Seq, now)
+		if wire.Seq > state.Highest+1 {
+			opened = &models.HistoryGap{DeviceID: deviceID, From: state.Highest + 1, To: wire.Seq - 1, DetectedAt: now.UTC()}
+		}
+		return next, changed
+	})
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	if opened != nil {
+		s.notify("notify.message.history_gap", map[string]any{
+			"contact_id": senderID,
+			"gap":        *opened,
+		})
+	}
+}
+
+// requestHistoryBackfill asks contacts to re-send messages that are still
+// missing. The request is addressed to the contact's identity, so whichever
+// of their devices or bound nodes holds the history can answer it.
+func (s *Service) requestHistoryBackfill(ctx context.Context, now time.Time) {
+	state := s.historyBackfill
+	state.mu.Lock()
+	if !state.lastCheck.IsZero() && now.Sub(state.lastCheck) < historyBackfillCheckInterval {
+		state.mu.Unlock()
+		return
+	}
+	state.lastCheck = now
+	state.mu.Unlock()
+
+	for _, contactID := range s.messageSeqs.ContactsWithGaps() {
+		if !s.identityManager.HasVerifiedContact(contactID) ||
+			!s.contactSupports(contactID, identityapp.CapabilityHistoryBackfill, true) {
+			continue
+		}
+		for deviceID, inbound := range s.messageSeqs.Inbound(contactID) {
+			ranges := messagingapp.DueBackfillRanges(inbound.Gaps, now, historyGapGrace, historyBackfillRetry)
+			if len(ranges) == 0 {
+				continue
+			}
+			wire := contracts.WirePayload{
+				Kind:     messagingapp.WireKindHistoryBackfill,
+				Backfill: &models.HistoryBackfillRequest{DeviceID: deviceID, Ranges: ranges},
+			}
+			if err := s.sendHistoryBackfillWire(ctx, contactID, wire); err != nil {
+				s.recordError(contracts.ErrorCategoryNetwork, err)
+				continue
+			}
+			_, err := s.messageSeqs.UpdateInbound(contactID, deviceID, func(state models.InboundSequence) (models.InboundSequence, bool) {
+				return messagingapp.MarkBackfillRequested(state, ranges, now), true
+			})
+			if err != nil {
+				s.recordError(contracts.ErrorCategoryStorage, err)
+			}
+		}
+	}
+}
+
+// handleHistoryBackfillWire re-sends the requested messages to a verified
+// contact. Only the device that numbered the messages answers, since the
+// sequence numbers of other devices mean nothing to it.
+func (s *Service) handleHistoryBackfillWire(senderID string, wire contracts.WirePayload) {
+	if !s.identityManager.HasVerifiedContact(senderID) || wire.Backfill == nil {
+		return
+	}
+	deviceID, err := s.activeDeviceID()
+	if err != nil || wire.Backfill.DeviceID != deviceID {
+		return
+	}
+	now := time.Now()
+	state := s.historyBackfill
+	state.mu.Lock()
+	if last, ok := state.replied[senderID]; ok && now.Sub(last) < historyBackfillReplyInterval {
+		state.mu.Unlock()
+		return
+	}
+	state.replied[senderID] = now
+	state.mu.Unlock()
+
+	ctx, err := s.networkContext(contracts.ErrorCategoryNetwork)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryNetwork, err)
+		return
+	}
+	for _, msg := range s.backfillCandidates(senderID, wire.Backfill.Ranges) {
+		wire, err := s.buildStoredMessageWire(msg)
+		if err != nil {
+			s.recordError(messagingapp.ErrorCategory(err), err)
+			continue
+		}
+		if err := s.publishSignedWireWithContext(ctx, msg.ID, senderID, wire); err != nil {
+			s.recordError(messagingapp.ErrorCategory(err), err)
+			return
+		}
+	}
+	s.logInfo("message.history_backfill", senderID, "history backfill answered", "contact_id", senderID)
+}
+
+// backfillCandidates returns our sent messages to contactID whose sequence
+// numbers fall in ranges. Messages still queued are left to the retry loop.
+func (s *Service) backfillCandidates(contactID string, ranges []models.SeqRange) []models.Message {
+	out := make([]models.Message, 0)
+	for _, msg := range s.messageStore.ListMessages(contactID, 0, 0) {
+		if msg.Direction != "out" || msg.Seq == 0 || msg.Status == "pending" || msg.Status == "cancelled" {
+			continue
+		}
+		for _, r := range ranges {
+			if msg.Seq >= r.From && msg.Seq <= r.To {
+				out = append(out, msg)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (s *Service) sendHistoryBackfillWire(ctx context.Context, contactID string, wire contracts.WirePayload) error {
+	wireID, err := s.generateID("bkfl")
+	if err != nil {
+		return err
+	}
+	return s.publishSignedWireWithContext(ctx, wireID, contactID, wire)
+}
+
+// historyGaps lists the unresolved gaps in the messages received from
+// contactID, ordered by device and position.
+func (s *Service) historyGaps(contactID string) []models.HistoryGap {
+	out := make([]models.HistoryGap, 0)
+	for _, state := range s.messageSeqs.Inbound(contactID) {
+		out = append(out, state.Gaps...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DeviceID != out[j].DeviceID {
+			return out[i].DeviceID < out[j].DeviceID
+		}
+		return out[i].From < out[j].From
+	})
+	return out
+}
@@ -0,0 +1,127 @@
+package daemonservice
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestRuntimeE2E_HistoryGapIsDetectedAndBackfilled(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	makeService := func(name string) *Service {
+		svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new service %s: %v", name, err)
+		}
+		return svc
+	}
+	alice := makeService("alice")
+	bob := makeService("bob")
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, _ := alice.GetIdentity()
+	bobIdentity, _ := bob.GetIdentity()
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	waitForInbound := func(messageID string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, ok := bob.messageStore.GetMessage(messageID); ok {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("message %s did not reach bob", messageID)
+	}
+
+	firstID, err := alice.SendMessage(context.Background(), bobIdentity.ID, "first")
+	if err != nil {
+		t.Fatalf("send first: %v", err)
+	}
+	waitForInbound(firstID)
+
+	// The second message is numbered and stored as sent but never reaches
+	// the network, as if its publish had been lost.
+	lostSeq, err := alice.messageSeqs.NextOutbound(bobIdentity.ID)
+	if err != nil {
+		t.Fatalf("reserve seq: %v", err)
+	}
+	lost := models.Message{
+		ID:          "msg-lost",
+		ContactID:   bobIdentity.ID,
+		Content:     []byte("lost in transit"),
+		ContentType: "text",
+		Timestamp:   time.Now().UTC(),
+		Direction:   "out",
+		Status:      "sent",
+		Seq:         lostSeq,
+	}
+	if err := alice.messageStore.SaveMessage(lost); err != nil {
+		t.Fatalf("save lost message: %v", err)
+	}
+
+	thirdID, err := alice.SendMessage(context.Background(), bobIdentity.ID, "third")
+	if err != nil {
+		t.Fatalf("send third: %v", err)
+	}
+	waitForInbound(thirdID)
+
+	// The sequence is recorded right after the message is stored.
+	waitForGaps := func(want int) models.ChatSecurityInfo {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			info, err := bob.GetChatSecurityInfo(aliceIdentity.ID)
+			if err != nil {
+				t.Fatalf("security info: %v", err)
+			}
+			if len(info.HistoryGaps) == want || time.Now().After(deadline) {
+				return info
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	info := waitForGaps(1)
+	if len(info.HistoryGaps) != 1 || info.HistoryGaps[0].From != lostSeq || info.HistoryGaps[0].To != lostSeq {
+		t.Fatalf("expected gap at seq %d, got %+v", lostSeq, info.HistoryGaps)
+	}
+
+	bob.requestHistoryBackfill(ctx, time.Now().Add(historyGapGrace+time.Second))
+	waitForInbound(lost.ID)
+	if msg, _ := bob.messageStore.GetMessage(lost.ID); string(msg.Content) != "lost in transit" {
+		t.Fatalf("unexpected backfilled content: %q", msg.Content)
+	}
+	if info := waitForGaps(0); len(info.HistoryGaps) != 0 {
+		t.Fatalf("gap must close after backfill, got %+v", info.HistoryGaps)
+	}
+}
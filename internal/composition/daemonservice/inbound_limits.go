@@ -0,0 +1,140 @@
+package daemonservice
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+)
+
+const (
+	inboundViolationAlertKind     = "inbound_limit_violation"
+	inboundViolationTrackerMaxIDs = 4096
+)
+
+type inboundLimitsConfig struct {
+	MaxWireBytes       int
+	MaxPlaintextBytes  int
+	AllowedKindsByMode map[privacydomain.MessagePrivacyMode]map[string]bool
+	AlertThreshold     int
+	AlertWindow        time.Duration
+}
+
+func resolveInboundLimitsConfigFromEnv() inboundLimitsConfig {
+	cfg := inboundLimitsConfig{
+		MaxWireBytes:       envBoundedIntWithFallback("AIM_INBOUND_MAX_WIRE_BYTES", messagingapp.DefaultInboundMaxWireBytes, 1024, 16*1024*1024),
+		MaxPlaintextBytes:  envBoundedIntWithFallback("AIM_INBOUND_MAX_PLAINTEXT_BYTES", messagingapp.DefaultInboundMaxPlaintextBytes, 256, 16*1024*1024),
+		AllowedKindsByMode: map[privacydomain.MessagePrivacyMode]map[string]bool{},
+		AlertThreshold:     envBoundedIntWithFallback("AIM_INBOUND_VIOLATION_ALERT_THRESHOLD", 3, 1, 1000),
+		AlertWindow:        time.Duration(envBoundedIntWithFallback("AIM_INBOUND_VIOLATION_WINDOW_SEC", 600, 10, 86400)) * time.Second,
+	}
+	modes := map[privacydomain.MessagePrivacyMode]string{
+		privacydomain.MessagePrivacyContactsOnly: "AIM_INBOUND_ALLOWED_KINDS_CONTACTS_ONLY",
+		privacydomain.MessagePrivacyRequests:     "AIM_INBOUND_ALLOWED_KINDS_REQUESTS",
+		privacydomain.MessagePrivacyEveryone:     "AIM_INBOUND_ALLOWED_KINDS_EVERYONE",
+	}
+	for mode, key := range modes {
+		kinds := map[string]bool{}
+		for _, kind := range envCSV(key) {
+			kind = strings.ToLower(strings.TrimSpace(kind))
+			if isKnownInboundWireKind(kind) {
+				kinds[kind] = true
+			}
+		}
+		if len(kinds) > 0 {
+			cfg.AllowedKindsByMode[mode] = kinds
+		}
+	}
+	return cfg
+}
+
+func isKnownInboundWireKind(kind string) bool {
+	for _, known := range messagingapp.KnownInboundWireKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) currentInboundLimits() messagingapp.InboundLimits {
+	return messagingapp.InboundLimits{
+		MaxWireBytes:      s.inboundLimits.MaxWireBytes,
+		MaxPlaintextBytes: s.inboundLimits.MaxPlaintextBytes,
+		AllowedKinds:      s.inboundLimits.AllowedKindsByMode[s.privacyCore.CurrentMode()],
+	}
+}
+
+// reportInboundLimitViolation counts a rejected payload and raises a security
+// alert once a sender crosses the violation threshold within the window.
+func (s *Service) reportInboundLimitViolation(senderID string, err error) {
+	s.metrics.RecordInboundLimitViolation(inboundViolationReason(err))
+	count, alert := s.inboundViolations.record(senderID, time.Now().UTC(), s.inboundLimits.AlertThreshold, s.inboundLimits.AlertWindow)
+	if alert {
+		s.notifySecurityAlertWithArgs(inboundViolationAlertKind, senderID, fmt.Sprintf("%d inbound payloads rejected: %v", count, err), map[string]string{
+			"count": strconv.Itoa(count),
+		})
+	}
+}
+
+func inboundViolationReason(err error) string {
+	switch {
+	case errors.Is(err, messagingapp.ErrInboundWireTooLarge):
+		return "wire_too_large"
+	case errors.Is(err, messagingapp.ErrInboundPlaintextTooLarge):
+		return "plaintext_too_large"
+	case errors.Is(err, messagingapp.ErrInboundKindNotAllowed):
+		return "kind_not_allowed"
+	default:
+		return "other"
+	}
+}
+
+type inboundViolationWindow struct {
+	start   time.Time
+	count   int
+	alerted bool
+}
+
+type inboundViolationTracker struct {
+	mu       sync.Mutex
+	bySender map[string]inboundViolationWindow
+}
+
+func newInboundViolationTracker() *inboundViolationTracker {
+	return &inboundViolationTracker{bySender: map[string]inboundViolationWindow{}}
+}
+
+// record returns the sender's violation count in the current window and
+// whether this violation should raise an alert (at most once per window).
+func (t *inboundViolationTracker) record(senderID string, now time.Time, threshold int, window time.Duration) (int, bool) {
+	senderID = strings.TrimSpace(senderID)
+	if t == nil || senderID == "" {
+		return 0, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.bySender) >= inboundViolationTrackerMaxIDs {
+		for id, entry := range t.bySender {
+			if now.Sub(entry.start) > window {
+				delete(t.bySender, id)
+			}
+		}
+	}
+	entry := t.bySender[senderID]
+	if entry.start.IsZero() || now.Sub(entry.start) > window {
+		entry = inboundViolationWindow{start: now}
+	}
+	entry.count++
+	alert := !entry.alerted && entry.count >= threshold
+	if alert {
+		entry.alerted = true
+	}
+	t.bySender[senderID] = entry
+	return entry.count, alert
+}
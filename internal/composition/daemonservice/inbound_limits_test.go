@@ -0,0 +1,45 @@
+package daemonservice
+
+import (
+	"testing"
+	"time"
+
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+)
+
+func TestResolveInboundLimitsConfigFromEnv(t *testing.T) {
+	t.Setenv("AIM_INBOUND_MAX_WIRE_BYTES", "10")
+	t.Setenv("AIM_INBOUND_ALLOWED_KINDS_EVERYONE", "e2ee, receipt, bogus")
+
+	cfg := resolveInboundLimitsConfigFromEnv()
+	if cfg.MaxWireBytes != 1024 {
+		t.Fatalf("expected wire limit clamped to 1024, got %d", cfg.MaxWireBytes)
+	}
+	kinds := cfg.AllowedKindsByMode[privacydomain.MessagePrivacyEveryone]
+	if len(kinds) != 2 || !kinds["e2ee"] || !kinds["receipt"] {
+		t.Fatalf("unexpected allowed kinds: %#v", kinds)
+	}
+	if _, ok := cfg.AllowedKindsByMode[privacydomain.MessagePrivacyContactsOnly]; ok {
+		t.Fatal("expected unset mode to fall back to known kinds")
+	}
+}
+
+func TestInboundViolationTrackerAlertsOncePerWindow(t *testing.T) {
+	tracker := newInboundViolationTracker()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+
+	alerts := 0
+	for i := 0; i < 5; i++ {
+		if _, alert := tracker.record("aim1spam", now.Add(time.Duration(i)*time.Second), 3, window); alert {
+			alerts++
+		}
+	}
+	if alerts != 1 {
+		t.Fatalf("expected one alert within window, got %d", alerts)
+	}
+	count, alert := tracker.record("aim1spam", now.Add(window+time.Minute), 3, window)
+	if count != 1 || alert {
+		t.Fatalf("expected window reset, got count=%d alert=%v", count, alert)
+	}
+}
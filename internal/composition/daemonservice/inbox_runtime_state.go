@@ -30,6 +30,12 @@ func (s *Service) snapshotRequestInbox() map[string][]models.Message {
 	return inboxapp.CopyInboxState(s.requestRuntime.Inbox)
 }
 
+func (s *Service) hasMessageRequest(senderID string) bool {
+	s.requestRuntime.Mu.RLock()
+	defer s.requestRuntime.Mu.RUnlock()
+	return len(s.requestRuntime.Inbox[senderID]) > 0
+}
+
 func (s *Service) persistRequestInboxSnapshotLocked(next map[string][]models.Message) error {
 	if s.requestInboxState == nil {
 		return nil
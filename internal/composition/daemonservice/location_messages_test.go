@@ -0,0 +1,86 @@
+package daemonservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestSendLocationStoresStructuredContent(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	ctx := context.Background()
+
+	msgID, err := svc.SendLocation(ctx, self.ID, models.MessageLocation{Latitude: 52.52, Longitude: 13.405, AccuracyMeters: 25, Label: "  Alexanderplatz "})
+	if err != nil {
+		t.Fatalf("send location: %v", err)
+	}
+	msg, ok := svc.messageStore.GetMessage(msgID)
+	if !ok || msg.ContentType != models.MessageContentTypeLocation || msg.Location == nil || msg.Location.Label != "Alexanderplatz" {
+		t.Fatalf("expected a location message, got %+v", msg)
+	}
+	if decoded, err := messagingapp.DecodeMessageLocation(msg.Content); err != nil || decoded != *msg.Location {
+		t.Fatalf("expected the content to encode the location, got %+v %v", decoded, err)
+	}
+	if _, err := svc.EditMessage(self.ID, msgID, "somewhere else"); err == nil {
+		t.Fatal("expected location messages to be read-only")
+	}
+	if _, err := svc.SendLocation(ctx, self.ID, models.MessageLocation{Latitude: 91, Longitude: 0}); !errors.Is(err, messagingapp.ErrInvalidMessageLocation) {
+		t.Fatalf("expected out-of-range latitude to be rejected, got %v", err)
+	}
+}
+
+func TestInboundLocationWireIsDecoded(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "bob"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	identity, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(privacydomain.MessagePrivacyRequests)); err != nil {
+		t.Fatalf("requests mode: %v", err)
+	}
+	deliver := func(id string, content []byte) {
+		payload, err := json.Marshal(contracts.WirePayload{Kind: "plain", Plain: content, ContentType: models.MessageContentTypeLocation})
+		if err != nil {
+			t.Fatalf("marshal wire: %v", err)
+		}
+		svc.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+			ID:        id,
+			SenderID:  "aim1stranger",
+			Recipient: identity.ID,
+			Payload:   payload,
+		})
+	}
+	deliver("msg-bad", []byte(`{"lat":10}`))
+	deliver("msg-loc", []byte(`{"lat":-33.8568,"lng":151.2153,"label":"Opera House"}`))
+
+	thread := svc.snapshotRequestInbox()["aim1stranger"]
+	if len(thread) != 1 || thread[0].ID != "msg-loc" {
+		t.Fatalf("expected only the valid location to arrive, got %+v", thread)
+	}
+	got := thread[0]
+	if got.ContentType != models.MessageContentTypeLocation || got.Location == nil || got.Location.Longitude != 151.2153 || got.Location.Label != "Opera House" {
+		t.Fatalf("expected decoded location metadata, got %+v", got)
+	}
+}
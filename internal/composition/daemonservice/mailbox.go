@@ -0,0 +1,130 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	errMailboxRequiresBinding = errors.New("mailbox administration requires a bound node")
+	errMailboxNotSupported    = errors.New("mailbox is not supported")
+	errInvalidMailboxPolicy   = errors.New("invalid mailbox policy")
+)
+
+// mailboxNode is the store-and-forward mailbox kept by the transport when
+// it runs as a store node.
+type mailboxNode interface {
+	MailboxStatus() waku.MailboxStatus
+	ConfigureMailbox(senderQuotaBytes int64, eviction string) error
+	FlushMailbox() (int, error)
+	PurgeMailbox(senderID string) (int, error)
+}
+
+// GetMailboxStatus reports what the bound node's mailbox holds per sender.
+func (s *Service) GetMailboxStatus() (models.MailboxStatus, error) {
+	node, record, err := s.boundMailbox()
+	if err != nil {
+		return models.MailboxStatus{}, err
+	}
+	status := node.MailboxStatus()
+	out := models.MailboxStatus{
+		NodeID:              record.NodeID,
+		Eviction:            status.Eviction,
+		SenderQuotaMB:       int(status.SenderQuotaBytes >> 20),
+		QuotaBytes:          status.QuotaBytes,
+		RetentionSeconds:    int64(status.Retention / time.Second),
+		StoredMessages:      status.StoredMessages,
+		StoredBytes:         status.StoredBytes,
+		Senders:             make([]models.MailboxSenderUsage, 0, len(status.Senders)),
+		EvictedTotal:        status.EvictedTotal,
+		PurgedTotal:         status.PurgedTotal,
+		RejectedSenderQuota: status.RejectedSenderQuota,
+	}
+	for _, sender := range status.Senders {
+		out.Senders = append(out.Senders, models.MailboxSenderUsage{
+			SenderID: sender.SenderID,
+			Messages: sender.Messages,
+			Bytes:    sender.Bytes,
+			OldestAt: sender.Oldest.UTC(),
+		})
+	}
+	return out, nil
+}
+
+// SetMailboxPolicy changes the per-sender quota, the eviction policy or
+// both. The policy is kept with the node policies so it survives restarts.
+func (s *Service) SetMailboxPolicy(senderQuotaMB *int, eviction *string) (models.MailboxStatus, error) {
+	if _, _, err := s.boundMailbox(); err != nil {
+		return models.MailboxStatus{}, err
+	}
+	if senderQuotaMB == nil && eviction == nil {
+		return models.MailboxStatus{}, errInvalidMailboxPolicy
+	}
+	if senderQuotaMB != nil && *senderQuotaMB < 0 {
+		return models.MailboxStatus{}, errInvalidMailboxPolicy
+	}
+	if eviction != nil && !privacydomain.MailboxEvictionPolicy(*eviction).Valid() {
+		return models.MailboxStatus{}, errInvalidMailboxPolicy
+	}
+	if _, err := s.UpdateNodePolicies(models.NodePoliciesPatch{
+		Personal: &models.NodePersonalPolicyPatch{
+			MailboxSenderQuotaMB: senderQuotaMB,
+			MailboxEviction:      eviction,
+		},
+	}); err != nil {
+		return models.MailboxStatus{}, err
+	}
+	return s.GetMailboxStatus()
+}
+
+// FlushMailbox applies retention, quotas and the eviction policy right away
+// and reports how many envelopes were dropped.
+func (s *Service) FlushMailbox() (int, error) {
+	node, _, err := s.boundMailbox()
+	if err != nil {
+		return 0, err
+	}
+	return node.FlushMailbox()
+}
+
+// PurgeMailbox drops everything stored from senderID, or the whole mailbox
+// when senderID is empty.
+func (s *Service) PurgeMailbox(senderID string) (int, error) {
+	node, _, err := s.boundMailbox()
+	if err != nil {
+		return 0, err
+	}
+	return node.PurgeMailbox(strings.TrimSpace(senderID))
+}
+
+// boundMailbox returns the mailbox only to the account owner, that is the
+// identity holding the node binding.
+func (s *Service) boundMailbox() (mailboxNode, models.NodeBindingRecord, error) {
+	record, bound, err := s.GetNodeBinding()
+	if err != nil {
+		return nil, models.NodeBindingRecord{}, err
+	}
+	if !bound {
+		return nil, models.NodeBindingRecord{}, errMailboxRequiresBinding
+	}
+	node, ok := s.wakuNode.(mailboxNode)
+	if !ok {
+		return nil, models.NodeBindingRecord{}, errMailboxNotSupported
+	}
+	return node, record, nil
+}
+
+func (s *Service) configureMailbox(policy privacydomain.NodePersonalPolicy) {
+	node, ok := s.wakuNode.(mailboxNode)
+	if !ok {
+		return
+	}
+	if err := node.ConfigureMailbox(int64(policy.MailboxSenderQuotaMB)<<20, string(policy.MailboxEviction)); err != nil {
+		s.logger.Warn("mailbox policy was not applied", "error", err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package daemonservice
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestMailboxAdministrationRequiresBoundNode(t *testing.T) {
+	svc := newNodeBindingTestService(t)
+	if _, err := svc.GetMailboxStatus(); !errors.Is(err, errMailboxRequiresBinding) {
+		t.Fatalf("expected binding requirement, got %v", err)
+	}
+	if _, err := svc.PurgeMailbox(""); !errors.Is(err, errMailboxRequiresBinding) {
+		t.Fatalf("expected binding requirement, got %v", err)
+	}
+
+	link, err := svc.CreateNodeBindingLinkCode(120)
+	if err != nil {
+		t.Fatalf("create link code: %v", err)
+	}
+	nodePub, nodePriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate node key: %v", err)
+	}
+	nodeSig := ed25519.Sign(nodePriv, nodeBindingChallengeBytes(link.IdentityID, link.LinkCode, "node-1", link.Challenge))
+	if _, err := svc.CompleteNodeBinding(
+		link.LinkCode,
+		"node-1",
+		base64.StdEncoding.EncodeToString(nodePub),
+		base64.StdEncoding.EncodeToString(nodeSig),
+		false,
+	); err != nil {
+		t.Fatalf("complete binding: %v", err)
+	}
+
+	status, err := svc.GetMailboxStatus()
+	if err != nil {
+		t.Fatalf("mailbox status: %v", err)
+	}
+	if status.NodeID != "node-1" || status.Eviction != waku.MailboxEvictionNone || status.Senders == nil {
+		t.Fatalf("unexpected mailbox status: %+v", status)
+	}
+
+	quota, eviction := 5, waku.MailboxEvictionSenderFairness
+	status, err = svc.SetMailboxPolicy(&quota, &eviction)
+	if err != nil {
+		t.Fatalf("set mailbox policy: %v", err)
+	}
+	if status.SenderQuotaMB != 5 || status.Eviction != waku.MailboxEvictionSenderFairness {
+		t.Fatalf("policy must apply to the node: %+v", status)
+	}
+	policies := svc.GetNodePolicies()
+	if policies.Personal.MailboxSenderQuotaMB != 5 || policies.Personal.MailboxEviction != waku.MailboxEvictionSenderFairness {
+		t.Fatalf("policy must persist with node policies: %+v", policies.Personal)
+	}
+	unknown := "newest_first"
+	if _, err := svc.SetMailboxPolicy(nil, &unknown); !errors.Is(err, errInvalidMailboxPolicy) {
+		t.Fatalf("expected invalid policy, got %v", err)
+	}
+
+	if evicted, err := svc.FlushMailbox(); err != nil || evicted != 0 {
+		t.Fatalf("flush empty mailbox: evicted=%d err=%v", evicted, err)
+	}
+	if purged, err := svc.PurgeMailbox("sender-1"); err != nil || purged != 0 {
+		t.Fatalf("purge empty mailbox: purged=%d err=%v", purged, err)
+	}
+}
@@ -0,0 +1,38 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errMessageAnnotationsUnavailable = errors.New("message annotation store is not available")
+
+// AnnotateMessage sets a private annotation for the caller namespace. An empty
+// value removes the key. The updated annotation list of the message is
+// returned. Annotations are kept locally and never leave this device.
+func (s *Service) AnnotateMessage(namespace, messageID, key, value string) ([]models.MessageAnnotation, error) {
+	if s.annotations == nil {
+		return nil, errMessageAnnotationsUnavailable
+	}
+	messageID = strings.TrimSpace(messageID)
+	if _, ok := s.messageStore.GetMessage(messageID); !ok {
+		return nil, errors.New("message not found")
+	}
+	if value == "" {
+		if _, err := s.annotations.Remove(namespace, messageID, key); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.annotations.Set(namespace, messageID, key, value); err != nil {
+		return nil, err
+	}
+	return s.annotations.List(namespace, messageID), nil
+}
+
+func (s *Service) ListMessageAnnotations(namespace, messageID string) ([]models.MessageAnnotation, error) {
+	if s.annotations == nil {
+		return nil, errMessageAnnotationsUnavailable
+	}
+	return s.annotations.List(namespace, messageID), nil
+}
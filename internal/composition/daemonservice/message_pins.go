@@ -0,0 +1,205 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errMessageNotFound = errors.New("message not found")
+
+// PinMessage pins a message in its conversation. Pins in direct chats stay
+// on this device; pinning a group post is shared with the other members.
+func (s *Service) PinMessage(messageID string) (models.MessagePin, error) {
+	msg, ok := s.messageStore.GetMessage(strings.TrimSpace(messageID))
+	if !ok {
+		return models.MessagePin{}, errMessageNotFound
+	}
+	msg = models.NormalizeMessageConversation(msg)
+	if msg.ConversationType == models.ConversationTypeGroup {
+		return s.PinGroupMessage(msg.ConversationID, msg.ID)
+	}
+	return s.pinStoredMessage(msg, s.identityManager.GetIdentity().ID, s.now())
+}
+
+// UnpinMessage removes a pin and reports whether the message was pinned.
+func (s *Service) UnpinMessage(messageID string) (bool, error) {
+	msg, ok := s.messageStore.GetMessage(strings.TrimSpace(messageID))
+	if !ok {
+		return false, errMessageNotFound
+	}
+	msg = models.NormalizeMessageConversation(msg)
+	if msg.ConversationType == models.ConversationTypeGroup {
+		return s.UnpinGroupMessage(msg.ConversationID, msg.ID)
+	}
+	return s.unpinStoredMessage(msg, s.identityManager.GetIdentity().ID)
+}
+
+// ListPinnedMessages returns the pinned messages of a direct chat or group,
+// most recently pinned first.
+func (s *Service) ListPinnedMessages(conversationID string) ([]models.PinnedMessage, error) {
+	conversationID = strings.TrimSpace(conversationID)
+	if conversationID == "" {
+		return nil, errors.New("conversation id is required")
+	}
+	conversationType := models.ConversationTypeDirect
+	if _, err := s.groupCore.GetGroup(conversationID); err == nil {
+		conversationType = models.ConversationTypeGroup
+	}
+	return s.messageStore.ListPinnedMessages(conversationID, conversationType), nil
+}
+
+// PinGroupMessage pins a group post for every member. Only owners and
+// admins pin in groups.
+func (s *Service) PinGroupMessage(groupID, messageID string) (models.MessagePin, error) {
+	msg, err := s.groupPinTarget(groupID, messageID)
+	if err != nil {
+		return models.MessagePin{}, err
+	}
+	self := s.identityManager.GetIdentity().ID
+	now := s.now().UTC()
+	pin, err := s.pinStoredMessage(msg, self, now)
+	if err != nil {
+		return models.MessagePin{}, err
+	}
+	s.distributeMessagePin(models.MessagePinUpdate{GroupID: msg.ConversationID, EventID: msg.EventID, Pinned: true, At: now})
+	return pin, nil
+}
+
+// UnpinGroupMessage removes a group pin for every member.
+func (s *Service) UnpinGroupMessage(groupID, messageID string) (bool, error) {
+	msg, err := s.groupPinTarget(groupID, messageID)
+	if err != nil {
+		return false, err
+	}
+	removed, err := s.unpinStoredMessage(msg, s.identityManager.GetIdentity().ID)
+	if err != nil || !removed {
+		return removed, err
+	}
+	s.distributeMessagePin(models.MessagePinUpdate{GroupID: msg.ConversationID, EventID: msg.EventID, Pinned: false, At: s.now().UTC()})
+	return true, nil
+}
+
+// groupPinTarget resolves a post of groupID the user may pin or unpin.
+func (s *Service) groupPinTarget(groupID, messageID string) (models.Message, error) {
+	group, err := s.groupCore.GetGroup(groupID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	msg, ok := s.messageStore.GetMessage(strings.TrimSpace(messageID))
+	if !ok || msg.ConversationType != models.ConversationTypeGroup || msg.ConversationID != group.ID {
+		return models.Message{}, errMessageNotFound
+	}
+	if strings.TrimSpace(msg.EventID) == "" || msg.ContentType == models.MessageContentTypeSystem {
+		return models.Message{}, errors.New("only group posts can be pinned")
+	}
+	if !s.canPinInGroup(group.ID, s.identityManager.GetIdentity().ID) {
+		return models.Message{}, groupdomain.ErrGroupPermissionDenied
+	}
+	return msg, nil
+}
+
+func (s *Service) canPinInGroup(groupID, memberID string) bool {
+	s.groupRuntime.StateMu.RLock()
+	defer s.groupRuntime.StateMu.RUnlock()
+	member, ok := s.groupRuntime.States[groupID].Members[memberID]
+	return ok && member.Status == groupdomain.GroupMemberStatusActive && member.CanManageMembers()
+}
+
+func (s *Service) pinStoredMessage(msg models.Message, pinnedBy string, at time.Time) (models.MessagePin, error) {
+	pin, ok, err := s.messageStore.PinMessage(msg.ID, pinnedBy, at)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return models.MessagePin{}, err
+	}
+	if !ok {
+		return models.MessagePin{}, errMessageNotFound
+	}
+	s.notifyMessagePinned(msg, true, pin.PinnedBy)
+	return pin, nil
+}
+
+func (s *Service) unpinStoredMessage(msg models.Message, actorID string) (bool, error) {
+	removed, err := s.messageStore.UnpinMessage(msg.ID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return false, err
+	}
+	if removed {
+		s.notifyMessagePinned(msg, false, actorID)
+	}
+	return removed, nil
+}
+
+func (s *Service) notifyMessagePinned(msg models.Message, pinned bool, actorID string) {
+	s.notify("notify.message.pinned", map[string]any{
+		"conversation_id":   msg.ConversationID,
+		"conversation_type": msg.ConversationType,
+		"message_id":        msg.ID,
+		"pinned":            pinned,
+		"actor_id":          actorID,
+	})
+}
+
+// distributeMessagePin sends a group pin change to the other active
+// members, who apply it to their own copy of the post.
+func (s *Service) distributeMessagePin(update models.MessagePinUpdate) {
+	ctx, err := s.networkContext("")
+	if err != nil {
+		return
+	}
+	members, err := s.groupCore.ListGroupMembers(update.GroupID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+		return
+	}
+	self := s.identityManager.GetIdentity().ID
+	for _, member := range members {
+		if member.Status != groupdomain.GroupMemberStatusActive || member.MemberID == self {
+			continue
+		}
+		wireID, err := s.generateID("gpin")
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryAPI, err)
+			return
+		}
+		pin := update
+		wire := contracts.WirePayload{Kind: messagingapp.WireKindMessagePin, Pin: &pin}
+		if err := s.publishSignedWireWithContext(ctx, wireID, member.MemberID, wire); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}
+}
+
+// handleMessagePinWire applies a pin change from a group owner or admin to
+// the local copy of the post.
+func (s *Service) handleMessagePinWire(senderID string, wire contracts.WirePayload) {
+	update := wire.Pin
+	if update == nil {
+		return
+	}
+	groupID := strings.TrimSpace(update.GroupID)
+	if !s.canPinInGroup(groupID, senderID) {
+		s.recordError(contracts.ErrorCategoryCrypto, groupdomain.ErrGroupPermissionDenied)
+		return
+	}
+	self := s.identityManager.GetIdentity().ID
+	msg, ok := s.messageStore.GetMessage(groupdomain.DeriveRecipientMessageID(strings.TrimSpace(update.EventID), self))
+	if !ok || msg.ConversationID != groupID {
+		return
+	}
+	if update.Pinned {
+		at := update.At
+		if at.IsZero() {
+			at = s.now()
+		}
+		_, _ = s.pinStoredMessage(msg, senderID, at)
+		return
+	}
+	_, _ = s.unpinStoredMessage(msg, senderID)
+}
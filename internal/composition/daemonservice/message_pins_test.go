@@ -0,0 +1,95 @@
+package daemonservice
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestDirectMessagePinLifecycleNotifies(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if err := svc.messageStore.SaveMessage(models.Message{ID: "dm-1", ContactID: "bob", Direction: "in", Status: "delivered", Timestamp: time.Now().UTC()}); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+
+	pin, err := svc.PinMessage("dm-1")
+	if err != nil || pin.ConversationID != "bob" || pin.ConversationType != models.ConversationTypeDirect {
+		t.Fatalf("pin message: %+v %v", pin, err)
+	}
+	pins, err := svc.ListPinnedMessages("bob")
+	if err != nil || len(pins) != 1 || pins[0].Message.ID != "dm-1" {
+		t.Fatalf("expected one pinned message, got %+v %v", pins, err)
+	}
+	if removed, err := svc.UnpinMessage("dm-1"); err != nil || !removed {
+		t.Fatalf("unpin message: removed=%v err=%v", removed, err)
+	}
+	if _, err := svc.PinMessage("missing"); !errors.Is(err, errMessageNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	replay, _, cancel := svc.SubscribeNotifications(0)
+	defer cancel()
+	var pinned []any
+	for _, evt := range replay {
+		if evt.Method == "notify.message.pinned" {
+			pinned = append(pinned, evt.Payload.(map[string]any)["pinned"])
+		}
+	}
+	if len(pinned) != 2 || pinned[0] != true || pinned[1] != false {
+		t.Fatalf("expected pin and unpin notifications, got %+v", pinned)
+	}
+}
+
+func TestGroupMessagePinRequiresAdminAndAppliesFromWire(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	state := seededActiveGroupState("group_pins", "Pins", "owner-peer", []string{"owner-peer", "member-peer", self.ID})
+	svc.groupRuntime.SetSnapshot(
+		map[string]groupdomain.GroupState{state.Group.ID: state},
+		map[string][]groupdomain.GroupEvent{state.Group.ID: {}},
+	)
+	copyID := groupdomain.DeriveRecipientMessageID("evt-1", self.ID)
+	if err := svc.messageStore.SaveMessage(models.Message{
+		ID: copyID, ContactID: "owner-peer", ConversationID: state.Group.ID, ConversationType: models.ConversationTypeGroup,
+		EventID: "evt-1", Direction: "in", Status: "delivered", ContentType: "text", Timestamp: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("save group message: %v", err)
+	}
+
+	if _, err := svc.PinGroupMessage(state.Group.ID, copyID); !errors.Is(err, groupdomain.ErrGroupPermissionDenied) {
+		t.Fatalf("expected regular member to be denied, got %v", err)
+	}
+	wire := contracts.WirePayload{
+		Kind: messagingapp.WireKindMessagePin,
+		Pin:  &models.MessagePinUpdate{GroupID: state.Group.ID, EventID: "evt-1", Pinned: true, At: time.Now().UTC()},
+	}
+	svc.handleMessagePinWire("member-peer", wire)
+	if pins, _ := svc.ListPinnedMessages(state.Group.ID); len(pins) != 0 {
+		t.Fatalf("expected pin from a regular member to be ignored, got %+v", pins)
+	}
+	svc.handleMessagePinWire("owner-peer", wire)
+	pins, err := svc.ListPinnedMessages(state.Group.ID)
+	if err != nil || len(pins) != 1 || pins[0].MessageID != copyID || pins[0].PinnedBy != "owner-peer" {
+		t.Fatalf("expected owner's pin to apply, got %+v %v", pins, err)
+	}
+}
@@ -0,0 +1,44 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errMessageSearchUnsupported = errors.New("message search is not supported")
+
+// SearchMessages finds text messages containing every word of query. An
+// empty contactID searches all conversations; otherwise contactID names a
+// direct chat or a group.
+func (s *Service) SearchMessages(contactID, query string, limit, offset int) (models.MessageSearchResult, error) {
+	return s.SearchMessagesInRange(contactID, query, time.Time{}, time.Time{}, limit, offset)
+}
+
+// SearchMessagesInRange is SearchMessages limited to messages sent in
+// [since, until). A zero bound leaves that end open.
+func (s *Service) SearchMessagesInRange(contactID, query string, since, until time.Time, limit, offset int) (models.MessageSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return models.MessageSearchResult{}, errors.New("search query is required")
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return models.MessageSearchResult{}, errors.New("search range start must be before its end")
+	}
+	searcher, ok := s.messageStore.(interface {
+		SearchMessages(query models.MessageSearchQuery) models.MessageSearchResult
+	})
+	if !ok {
+		return models.MessageSearchResult{}, errMessageSearchUnsupported
+	}
+	return searcher.SearchMessages(models.MessageSearchQuery{
+		ConversationID: strings.TrimSpace(contactID),
+		Query:          query,
+		Since:          since.UTC(),
+		Until:          until.UTC(),
+		Limit:          limit,
+		Offset:         offset,
+	}), nil
+}
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"aim-chat/go-backend/internal/domains/contracts"
@@ -14,8 +15,13 @@ import (
 
 var sizeBuckets = []int{256, 512, 1024, 2048, 4096, 8192}
 
+// coverSizeBuckets are the coarser buckets used in cover traffic mode, so
+// receipts, chat messages and dummies mostly share a single size.
+var coverSizeBuckets = []int{2048, 8192, 32768}
+
 type outboundMetadataHardening struct {
 	enabled      bool
+	cover        atomic.Bool
 	batchWindow  time.Duration
 	jitterMax    time.Duration
 	randomMu     sync.Mutex
@@ -38,13 +44,27 @@ func boundedDurationFromEnv(key string, def, min, max int) time.Duration {
 	return time.Duration(value) * time.Millisecond
 }
 
+// setCoverMode switches between the regular buckets and cover traffic mode,
+// which pads every payload, latency-critical ones included.
+func (p *outboundMetadataHardening) setCoverMode(enabled bool) {
+	if p == nil {
+		return
+	}
+	p.cover.Store(enabled)
+}
+
 func (p *outboundMetadataHardening) harden(wire contracts.WirePayload) (contracts.WirePayload, time.Duration, error) {
-	if p == nil || !p.enabled || p.isLatencyCritical(wire) {
+	if p == nil {
+		return wire, 0, nil
+	}
+	cover := p.cover.Load()
+	latencyCritical := p.isLatencyCritical(wire)
+	if !cover && (!p.enabled || latencyCritical) {
 		return wire, 0, nil
 	}
 	hardened := wire
 	hardened.Padding = ""
-	target, err := p.targetSize(hardened)
+	target, err := p.targetSize(hardened, cover)
 	if err != nil {
 		return contracts.WirePayload{}, 0, err
 	}
@@ -55,6 +75,9 @@ func (p *outboundMetadataHardening) harden(wire contracts.WirePayload) (contract
 		}
 		hardened.Padding = padding
 	}
+	if latencyCritical || !p.enabled {
+		return hardened, 0, nil
+	}
 	return hardened, p.batchWindow + p.randomJitter(), nil
 }
 
@@ -67,13 +90,17 @@ func (p *outboundMetadataHardening) isLatencyCritical(wire contracts.WirePayload
 	}
 }
 
-func (p *outboundMetadataHardening) targetSize(wire contracts.WirePayload) (int, error) {
+func (p *outboundMetadataHardening) targetSize(wire contracts.WirePayload, cover bool) (int, error) {
 	raw, err := json.Marshal(wire)
 	if err != nil {
 		return 0, err
 	}
 	size := len(raw)
-	for _, bucket := range sizeBuckets {
+	buckets := sizeBuckets
+	if cover {
+		buckets = coverSizeBuckets
+	}
+	for _, bucket := range buckets {
 		if size <= bucket {
 			return bucket, nil
 		}
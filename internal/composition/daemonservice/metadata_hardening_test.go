@@ -67,3 +67,33 @@ func TestMetadataHardeningSkipsLatencyCriticalKinds(t *testing.T) {
 		t.Fatalf("latency-critical payload must not be delayed, got=%v", delay)
 	}
 }
+
+func TestMetadataHardeningCoverModePadsEveryKind(t *testing.T) {
+	h := &outboundMetadataHardening{
+		enabled:      true,
+		batchWindow:  80 * time.Millisecond,
+		jitterMax:    200 * time.Millisecond,
+		randomSource: rand.New(rand.NewSource(1)),
+	}
+	h.setCoverMode(true)
+	for _, wire := range []contracts.WirePayload{
+		{Kind: "receipt"},
+		{Kind: "plain", Plain: []byte("hello")},
+		{Kind: "e2ee", Plain: make([]byte, 900)},
+	} {
+		hardened, delay, err := h.harden(wire)
+		if err != nil {
+			t.Fatalf("harden %s failed: %v", wire.Kind, err)
+		}
+		raw, err := json.Marshal(hardened)
+		if err != nil {
+			t.Fatalf("marshal hardened payload: %v", err)
+		}
+		if len(raw) != coverSizeBuckets[0] {
+			t.Fatalf("%s payload must fill the first cover bucket, got=%d", wire.Kind, len(raw))
+		}
+		if wire.Kind == "receipt" && delay != 0 {
+			t.Fatalf("cover mode must not delay receipts, got=%v", delay)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package daemonservice
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const metricsHistorySampleInterval = 10 * time.Second
+
+// Series kept in the metrics history. Error series count the errors recorded
+// since the previous sample, per category and in total.
+const (
+	metricsSeriesPeerCount  = "peer_count"
+	metricsSeriesQueueDepth = "queue_depth"
+	metricsSeriesErrors     = "errors"
+)
+
+// metricsHistorySampler spaces samples out on the retry tick and remembers
+// the error counters of the previous sample.
+type metricsHistorySampler struct {
+	mu         sync.Mutex
+	lastSample time.Time
+	lastErrors map[string]int
+}
+
+// sampleMetricsHistory runs on the retry tick and records peer count, queue
+// depth and error counts into the metrics history.
+func (s *Service) sampleMetricsHistory(now time.Time) {
+	sampler := s.metricsSampler
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+	if !sampler.lastSample.IsZero() && now.Sub(sampler.lastSample) < metricsHistorySampleInterval {
+		return
+	}
+	sampler.lastSample = now
+	counters, _, _, _, _, _, _ := s.metrics.Snapshot()
+	values := map[string]float64{
+		metricsSeriesPeerCount:  float64(s.wakuNode.Status().PeerCount),
+		metricsSeriesQueueDepth: float64(s.messageStore.PendingCount()),
+	}
+	total := 0
+	for category, count := range counters {
+		delta := count - sampler.lastErrors[category]
+		if delta < 0 {
+			// The counters were reset, for instance by an account switch.
+			delta = count
+		}
+		values[metricsSeriesErrors+"."+category] = float64(delta)
+		total += delta
+	}
+	values[metricsSeriesErrors] = float64(total)
+	sampler.lastErrors = counters
+	if err := s.metricsHistory.Record(now, values); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+	}
+}
+
+// QueryMetrics returns the metrics history of the last span, given as a
+// duration such as "6h". Resolution is "1m" or "1h"; when empty, minutes are
+// used while minute buckets still cover the span. No series means all.
+func (s *Service) QueryMetrics(span, resolution string, series []string) (models.MetricsQueryResult, error) {
+	window, err := time.ParseDuration(strings.TrimSpace(span))
+	if err != nil || window <= 0 || window > storage.MetricsHourRetention {
+		return models.MetricsQueryResult{}, storage.ErrInvalidMetricsQuery
+	}
+	var step time.Duration
+	switch strings.TrimSpace(resolution) {
+	case "":
+		step = time.Hour
+		if window <= storage.MetricsMinuteRetention {
+			step = time.Minute
+		}
+	case "1m":
+		step = time.Minute
+	case "1h":
+		step = time.Hour
+	default:
+		return models.MetricsQueryResult{}, storage.ErrInvalidMetricsQuery
+	}
+	names := make([]string, 0, len(series))
+	for _, name := range series {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	to := time.Now().UTC()
+	from := to.Add(-window).Truncate(step)
+	out, err := s.metricsHistory.Query(from, to, step, names)
+	if err != nil {
+		return models.MetricsQueryResult{}, err
+	}
+	label := "1m"
+	if step == time.Hour {
+		label = "1h"
+	}
+	return models.MetricsQueryResult{From: from, To: to, Resolution: label, Series: out}, nil
+}
@@ -0,0 +1,59 @@
+package daemonservice
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestMetricsHistorySamplesErrorDeltas(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	now := time.Now().UTC().Add(-time.Minute)
+	svc.recordError(contracts.ErrorCategoryNetwork, errors.New("dial failed"))
+	svc.sampleMetricsHistory(now)
+	svc.recordError(contracts.ErrorCategoryNetwork, errors.New("dial failed"))
+	svc.sampleMetricsHistory(now.Add(time.Second))
+	svc.recordError(contracts.ErrorCategoryStorage, errors.New("disk full"))
+	svc.sampleMetricsHistory(now.Add(metricsHistorySampleInterval))
+
+	result, err := svc.QueryMetrics("10m", "", []string{"errors", "errors.network", "queue_depth"})
+	if err != nil {
+		t.Fatalf("query metrics: %v", err)
+	}
+	if result.Resolution != "1m" || len(result.Series) != 3 {
+		t.Fatalf("unexpected query result: %+v", result)
+	}
+	sums := map[string]float64{}
+	samples := map[string]int{}
+	for _, series := range result.Series {
+		for _, point := range series.Points {
+			sums[series.Name] += point.Sum
+			samples[series.Name] += point.Samples
+		}
+	}
+	// The sample one second after the first is skipped, so its error lands
+	// in the next sample.
+	if sums["errors"] != 3 || sums["errors.network"] != 2 || samples["queue_depth"] != 2 {
+		t.Fatalf("unexpected sums=%v samples=%v", sums, samples)
+	}
+
+	if _, err := svc.QueryMetrics("forever", "", nil); !errors.Is(err, storage.ErrInvalidMetricsQuery) {
+		t.Fatalf("expected invalid range to be rejected, got %v", err)
+	}
+	if _, err := svc.QueryMetrics("1h", "5m", nil); !errors.Is(err, storage.ErrInvalidMetricsQuery) {
+		t.Fatalf("expected invalid resolution to be rejected, got %v", err)
+	}
+	if result, err := svc.QueryMetrics("72h", "", nil); err != nil || result.Resolution != "1h" {
+		t.Fatalf("long ranges must use hour buckets: %+v %v", result, err)
+	}
+}
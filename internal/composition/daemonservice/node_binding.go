@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -108,6 +109,7 @@ func (s *Service) CompleteNodeBinding(linkCode, nodeID, nodePublicKeyBase64, nod
 	if err := s.bindingStore.Upsert(record); err != nil {
 		return models.NodeBindingRecord{}, err
 	}
+	s.recordAudit(models.AuditKindNodeBound, "node_id", nodeID, "rebind", strconv.FormatBool(exists && strings.TrimSpace(existing.NodeID) != nodeID))
 	return record, nil
 }
 
@@ -138,6 +140,7 @@ func (s *Service) UnbindNode(nodeID string, confirm bool) (bool, error) {
 	if err := s.bindingStore.Delete(identityID); err != nil {
 		return false, err
 	}
+	s.recordAudit(models.AuditKindNodeUnbound, "node_id", strings.TrimSpace(record.NodeID))
 	return true, nil
 }
 
@@ -156,6 +159,12 @@ func (s *Service) consumeValidBindingLink(code string) (pendingNodeBindingLink,
 	return link, true
 }
 
+// NodeBindingChallenge returns the bytes a node signs with its key to
+// complete the binding offered by link.
+func NodeBindingChallenge(link models.NodeBindingLinkCode, nodeID string) []byte {
+	return nodeBindingChallengeBytes(link.IdentityID, link.LinkCode, nodeID, link.Challenge)
+}
+
 func nodeBindingChallengeBytes(identityID, linkCode, nodeID, challenge string) []byte {
 	return []byte(fmt.Sprintf("aim-bind-v1|challenge|%s|%s|%s|%s", identityID, linkCode, nodeID, challenge))
 }
@@ -52,6 +52,7 @@ func (s *Service) applyNodePolicies(policies privacydomain.NodePolicies) {
 	cfg.PublicServingEnabled = policies.Public.ServingEnabled
 	s.nodePreset = cfg
 	s.presetMu.Unlock()
+	s.configureMailbox(policies.Personal)
 }
 
 func (s *Service) syncNodePoliciesFromPreset(cfg blobNodePresetConfig) error {
@@ -61,6 +62,11 @@ func (s *Service) syncNodePoliciesFromPreset(cfg blobNodePresetConfig) error {
 	policies.Public.RelayEnabled = cfg.RelayEnabled
 	policies.Public.DiscoveryEnabled = cfg.PublicDiscoveryEnabled
 	policies.Public.ServingEnabled = cfg.PublicServingEnabled
+	// Presets do not cover the mailbox, so its settings carry over.
+	if current, err := s.privacyCore.GetNodePolicies(); err == nil {
+		policies.Personal.MailboxSenderQuotaMB = current.Personal.MailboxSenderQuotaMB
+		policies.Personal.MailboxEviction = current.Personal.MailboxEviction
+	}
 	_, err := s.privacyCore.UpdateNodePolicies(policies)
 	return err
 }
@@ -69,10 +75,12 @@ func nodePoliciesToModels(in privacydomain.NodePolicies) models.NodePolicies {
 	return models.NodePolicies{
 		ProfileSchemaVersion: in.ProfileSchemaVersion,
 		Personal: models.NodePersonalPolicy{
-			StoreEnabled: in.Personal.StoreEnabled,
-			TTLDays:      in.Personal.TTLDays,
-			QuotaMB:      in.Personal.QuotaMB,
-			PinEnabled:   in.Personal.PinEnabled,
+			StoreEnabled:         in.Personal.StoreEnabled,
+			TTLDays:              in.Personal.TTLDays,
+			QuotaMB:              in.Personal.QuotaMB,
+			PinEnabled:           in.Personal.PinEnabled,
+			MailboxSenderQuotaMB: in.Personal.MailboxSenderQuotaMB,
+			MailboxEviction:      string(in.Personal.MailboxEviction),
 		},
 		Public: models.NodePublicPolicy{
 			RelayEnabled:     in.Public.RelayEnabled,
@@ -115,6 +123,12 @@ func applyNodePoliciesPatch(current privacydomain.NodePolicies, patch models.Nod
 		if patch.Personal.QuotaMB != nil {
 			next.Personal.QuotaMB = *patch.Personal.QuotaMB
 		}
+		if patch.Personal.MailboxSenderQuotaMB != nil {
+			next.Personal.MailboxSenderQuotaMB = *patch.Personal.MailboxSenderQuotaMB
+		}
+		if patch.Personal.MailboxEviction != nil {
+			next.Personal.MailboxEviction = privacydomain.MailboxEvictionPolicy(*patch.Personal.MailboxEviction)
+		}
 	}
 	if patch.Public != nil {
 		if patch.Public.RelayEnabled != nil {
@@ -0,0 +1,43 @@
+package daemonservice
+
+import (
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// withNotificationHints attaches the conversation's alerting preferences to
+// new-message notifications so every connected client alerts the same way.
+func (s *Service) withNotificationHints(method string, payload any) any {
+	if method != events.MethodMessageNew {
+		return payload
+	}
+	evt, ok := payload.(events.MessageNew)
+	if !ok {
+		return payload
+	}
+	hints := s.notificationHints(models.NormalizeMessageConversation(evt.Message).ConversationID)
+	evt.Notification = &hints
+	return evt
+}
+
+func (s *Service) notificationHints(conversationID string) models.NotificationHints {
+	if s.conversationSync == nil || conversationID == "" {
+		return models.NotificationHints{}
+	}
+	state, ok := s.conversationSync.Get(conversationID)
+	if !ok {
+		return models.NotificationHints{}
+	}
+	state.ConversationID = conversationID
+	return messagingapp.NotificationHintsFromFlags(messagingapp.ResolveConversationFlags(state))
+}
+
+// emergencyNotificationHints alert for an emergency channel post at high
+// priority, overriding a muted conversation.
+func (s *Service) emergencyNotificationHints(conversationID string) models.NotificationHints {
+	hints := s.notificationHints(conversationID)
+	hints.Priority = models.NotificationPriorityHigh
+	hints.Muted = false
+	return hints
+}
@@ -0,0 +1,72 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestNewMessageNotificationsCarryConversationHints(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "svc"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("pass"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	sound, priority := "chime-2", models.NotificationPriorityHigh
+	flags, err := svc.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{
+		NotificationSound:    &sound,
+		NotificationPriority: &priority,
+	})
+	if err != nil {
+		t.Fatalf("set notification flags: %v", err)
+	}
+	if flags.NotificationSound != sound || flags.NotificationPriority != priority {
+		t.Fatalf("flags not resolved: %+v", flags)
+	}
+	bad := "../../etc/passwd"
+	if _, err := svc.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{NotificationSound: &bad}); err == nil {
+		t.Fatal("expected invalid sound name to be rejected")
+	}
+	urgent := "urgent"
+	if _, err := svc.SetConversationFlags("aim1friend", models.ConversationFlagsUpdate{NotificationPriority: &urgent}); err == nil {
+		t.Fatal("expected unknown priority class to be rejected")
+	}
+
+	_, stream, cancel := svc.SubscribeEvents(0)
+	defer cancel()
+	for _, contactID := range []string{"aim1friend", "aim1other"} {
+		svc.notify(events.MethodMessageNew, events.MessageNew{
+			ContactID: contactID,
+			Message:   models.Message{ID: "msg-" + contactID, ContactID: contactID, Direction: "in"},
+		})
+	}
+	want := map[string]models.NotificationHints{
+		"aim1friend": {Sound: sound, Priority: priority},
+		"aim1other":  {},
+	}
+	for len(want) > 0 {
+		select {
+		case notification := <-stream:
+			evt, ok := notification.Event.(events.MessageNew)
+			if !ok {
+				continue
+			}
+			if evt.Notification == nil || *evt.Notification != want[evt.ContactID] {
+				t.Fatalf("%s: unexpected hints %#v", evt.ContactID, evt.Notification)
+			}
+			delete(want, evt.ContactID)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("missing notifications for %v", want)
+		}
+	}
+}
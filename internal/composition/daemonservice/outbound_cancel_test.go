@@ -0,0 +1,93 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func newOutboundCancelTestService(t *testing.T, status string) (*Service, models.Message) {
+	t.Helper()
+	store := storage.NewMessageStore()
+	msg := models.Message{
+		ID:        "msg-cancel",
+		ContactID: "aim1_contact",
+		Content:   []byte("payload"),
+		Timestamp: time.Now().UTC(),
+		Direction: "out",
+		Status:    status,
+	}
+	if err := store.SaveMessage(msg); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+	if status == "pending" {
+		if err := store.AddOrUpdatePending(msg, 1, time.Now(), "network down"); err != nil {
+			t.Fatalf("add pending: %v", err)
+		}
+	}
+	svc := &Service{
+		messageStore:     store,
+		logger:           runtimeapp.DefaultLogger(),
+		metrics:          runtimeapp.NewServiceMetricsState(),
+		notifier:         runtimeapp.NewNotificationHub(32),
+		outboundMu:       &sync.Mutex{},
+		outboundInFlight: map[string]struct{}{},
+		deadLetters:      storage.NewDeadLetterStore(),
+	}
+	return svc, msg
+}
+
+func TestCancelMessageRemovesPendingAndBlocksRetry(t *testing.T) {
+	t.Parallel()
+
+	svc, msg := newOutboundCancelTestService(t, "pending")
+	cancelled, err := svc.CancelMessage(msg.ID)
+	if err != nil {
+		t.Fatalf("cancel message: %v", err)
+	}
+	if cancelled.Status != "cancelled" {
+		t.Fatalf("expected cancelled status, got %q", cancelled.Status)
+	}
+	if count := svc.messageStore.PendingCount(); count != 0 {
+		t.Fatalf("cancelled message must leave the retry queue, got=%d", count)
+	}
+	if svc.beginOutboundPublish(msg.ID) {
+		t.Fatal("cancelled message must not be published")
+	}
+	// A retry batch captured before the cancellation must skip the message.
+	svc.processPendingBatch(context.Background(), []storage.PendingMessage{{Message: msg, RetryCount: 1}}, nil)
+}
+
+func TestCancelMessageRefusesInFlightPublish(t *testing.T) {
+	t.Parallel()
+
+	svc, msg := newOutboundCancelTestService(t, "pending")
+	if !svc.beginOutboundPublish(msg.ID) {
+		t.Fatal("pending message must be publishable")
+	}
+	if _, err := svc.CancelMessage(msg.ID); !errors.Is(err, ErrMessagePublishInFlight) {
+		t.Fatalf("expected in-flight error, got %v", err)
+	}
+	svc.endOutboundPublish(msg.ID)
+	if _, err := svc.CancelMessage(msg.ID); err != nil {
+		t.Fatalf("cancel after publish settled: %v", err)
+	}
+}
+
+func TestCancelMessageRejectsDeliveredMessage(t *testing.T) {
+	t.Parallel()
+
+	svc, msg := newOutboundCancelTestService(t, "delivered")
+	if _, err := svc.CancelMessage(msg.ID); !errors.Is(err, storage.ErrMessageNotCancellable) {
+		t.Fatalf("expected not cancellable error, got %v", err)
+	}
+	if _, err := svc.CancelMessage("missing"); err == nil {
+		t.Fatal("expected unknown message to be rejected")
+	}
+}
@@ -2,11 +2,14 @@ package daemonservice
 
 import (
 	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"errors"
-	"time"
+	"strings"
 )
 
 func (s *Service) buildStoredMessageWire(msg models.Message) (contracts.WirePayload, error) {
@@ -17,8 +20,8 @@ func (s *Service) sendReceipt(contactID, messageID, status string) error {
 	if !s.identityManager.HasVerifiedContact(contactID) {
 		return errors.New("receipt target is not a verified contact")
 	}
-	wire := messagingapp.NewReceiptWire(messageID, status, time.Now())
-	wireID, err := runtimeapp.GeneratePrefixedID("rcpt")
+	wire := messagingapp.NewReceiptWire(messageID, status, s.now())
+	wireID, err := s.generateID("rcpt")
 	if err != nil {
 		return err
 	}
@@ -26,7 +29,22 @@ func (s *Service) sendReceipt(contactID, messageID, status string) error {
 	if err != nil {
 		return err
 	}
-	return s.publishSignedWireWithContext(ctx, wireID, contactID, wire)
+	// Peers that never advertised the receipts channel do not subscribe to
+	// it, so they keep getting receipts inline.
+	channel, ok := s.receiptChannel()
+	if !ok || !s.contactSupports(contactID, identityapp.CapabilityReceiptsChannel, false) {
+		return s.publishSignedWireWithContext(ctx, wireID, contactID, wire)
+	}
+	wmsg, err := s.composeHardenedPrivateMessage(ctx, wireID, contactID, wire)
+	if err != nil {
+		return err
+	}
+	publishCtx, cancel := context.WithTimeout(ctx, runtimeapp.PublishTimeout)
+	defer cancel()
+	if err := channel.PublishReceipt(publishCtx, wmsg); err != nil {
+		return contracts.WrapCategorizedError(contracts.ErrorCategoryNetwork, err)
+	}
+	return nil
 }
 
 func (s *Service) applyAutoRead(message *models.Message, contactID string) {
@@ -37,23 +55,39 @@ func (s *Service) applyAutoRead(message *models.Message, contactID string) {
 		return
 	}
 	message.Status = "read"
+	if !s.sharingWith(contactID).ReadReceipts {
+		return
+	}
 	if err := s.sendReceipt(contactID, message.ID, "read"); err != nil {
 		s.recordErrorWithContext(contracts.ErrorCategoryNetwork, err, "message.auto_read_receipt", messageCorrelationID(message.ID, contactID), "message_id", message.ID, "contact_id", contactID)
 	}
 }
 
-func (s *Service) publishQueuedMessage(msg models.Message, contactID string, wire contracts.WirePayload) (string, error) {
+// publishQueuedMessage publishes a stored message within the caller's
+// context. When the caller's context ends first the message is queued for
+// the retry loop rather than reported as failed.
+func (s *Service) publishQueuedMessage(ctx context.Context, msg models.Message, contactID string, wire contracts.WirePayload) (string, error) {
 	correlationID := messageCorrelationID(msg.ID, contactID)
+	if !s.beginOutboundPublish(msg.ID) {
+		return msg.ID, nil
+	}
+	defer s.endOutboundPublish(msg.ID)
 	s.logInfo("message.outbound_queue", correlationID, "message queued", "message_id", msg.ID, "contact_id", contactID, "kind", wire.Kind)
-	ctx, err := s.networkContext("network")
+	publishCtx, cancel, err := s.callerNetworkContext(ctx, "network")
 	if err == nil {
-		err = s.publishSignedWireWithContext(ctx, msg.ID, contactID, wire)
+		defer cancel()
+		if err = publishCtx.Err(); err == nil {
+			err = s.publishSignedWireWithContext(publishCtx, msg.ID, contactID, wire)
+		}
 	}
 	if err != nil {
 		category := messagingapp.ErrorCategory(err)
+		if ctx.Err() != nil {
+			category = contracts.ErrorCategoryNetwork
+		}
 		s.recordError(category, err)
 		if category == contracts.ErrorCategoryNetwork {
-			if perr := s.messageStore.AddOrUpdatePending(msg, 1, messagingapp.NextRetryTime(1), err.Error()); perr != nil {
+			if perr := s.messageStore.AddOrUpdatePending(msg, 1, s.nextRetryTime(1), err.Error()); perr != nil {
 				s.recordErrorWithContext(contracts.ErrorCategoryStorage, perr, "message.outbound_queue", correlationID, "message_id", msg.ID, "contact_id", contactID)
 				return "", perr
 			}
@@ -65,3 +99,59 @@ func (s *Service) publishQueuedMessage(msg models.Message, contactID string, wir
 	s.markMessageAsSent(msg.ID)
 	return msg.ID, nil
 }
+
+// ErrMessagePublishInFlight is returned when a message is cancelled while its
+// publish is running; the caller can retry once the attempt settles.
+var ErrMessagePublishInFlight = errors.New("message publish is in progress")
+
+// CancelMessage withdraws an outbound message that has not been sent yet.
+// A publish attempt and a cancellation never overlap: cancellation is refused
+// while a publish runs, and a cancelled message is never published again.
+func (s *Service) CancelMessage(messageID string) (models.Message, error) {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return models.Message{}, errors.New("message id is required")
+	}
+	s.outboundMu.Lock()
+	if _, busy := s.outboundInFlight[messageID]; busy {
+		s.outboundMu.Unlock()
+		return models.Message{}, ErrMessagePublishInFlight
+	}
+	msg, ok, err := s.messageStore.CancelPending(messageID)
+	s.outboundMu.Unlock()
+	if err != nil {
+		if !errors.Is(err, storage.ErrMessageNotCancellable) {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+		}
+		return models.Message{}, err
+	}
+	if !ok {
+		return models.Message{}, errors.New("message not found")
+	}
+	s.forgetDeadLetter(msg.ID)
+	s.logInfo("message.outbound_cancelled", messageCorrelationID(msg.ID, msg.ContactID), "message cancelled", "message_id", msg.ID, "contact_id", msg.ContactID)
+	s.notifyMessageStatus(msg.ID, msg.Status)
+	return msg, nil
+}
+
+// beginOutboundPublish marks a message as being published. It reports false
+// when the message was cancelled or another publish of it is running, in
+// which case it must not be sent.
+func (s *Service) beginOutboundPublish(messageID string) bool {
+	s.outboundMu.Lock()
+	defer s.outboundMu.Unlock()
+	if _, busy := s.outboundInFlight[messageID]; busy {
+		return false
+	}
+	if msg, ok := s.messageStore.GetMessage(messageID); ok && msg.Status == "cancelled" {
+		return false
+	}
+	s.outboundInFlight[messageID] = struct{}{}
+	return true
+}
+
+func (s *Service) endOutboundPublish(messageID string) {
+	s.outboundMu.Lock()
+	defer s.outboundMu.Unlock()
+	delete(s.outboundInFlight, messageID)
+}
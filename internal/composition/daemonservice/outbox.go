@@ -0,0 +1,88 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// maxOutboxBulkIDs bounds a single bulk retry or cancel request.
+const maxOutboxBulkIDs = 500
+
+// ListOutbox returns outbound messages across all conversations that are
+// pending, waiting for a retry, failed or sent but not yet read.
+func (s *Service) ListOutbox(categories []string, limit, offset int) ([]models.OutboxEntry, error) {
+	selected, err := messagingapp.ParseOutboxCategories(categories)
+	if err != nil {
+		return nil, err
+	}
+	messages, pending := s.messageStore.Snapshot()
+	list := make([]models.Message, 0, len(messages))
+	for _, msg := range messages {
+		list = append(list, msg)
+	}
+	return messagingapp.BuildOutbox(list, pending, selected, time.Now(), limit, offset), nil
+}
+
+// RetryMessage queues a pending or failed outbound message for an immediate
+// attempt with a fresh retry budget.
+func (s *Service) RetryMessage(messageID string) (models.Message, error) {
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return models.Message{}, errors.New("message id is required")
+	}
+	s.outboundMu.Lock()
+	if _, busy := s.outboundInFlight[messageID]; busy {
+		s.outboundMu.Unlock()
+		return models.Message{}, ErrMessagePublishInFlight
+	}
+	msg, ok, err := s.messageStore.RequeueMessage(messageID, time.Now())
+	s.outboundMu.Unlock()
+	if err != nil {
+		if !errors.Is(err, storage.ErrMessageNotRetryable) {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+		}
+		return models.Message{}, err
+	}
+	if !ok {
+		return models.Message{}, errors.New("message not found")
+	}
+	s.forgetDeadLetter(msg.ID)
+	s.logInfo("message.outbound_requeued", messageCorrelationID(msg.ID, msg.ContactID), "message queued for retry", "message_id", msg.ID, "contact_id", msg.ContactID)
+	s.notifyMessageStatus(msg.ID, msg.Status)
+	return msg, nil
+}
+
+// RetryMessages retries each message independently; one failure does not
+// stop the rest.
+func (s *Service) RetryMessages(messageIDs []string) (models.OutboxBulkResult, error) {
+	return runOutboxBulk(messageIDs, s.RetryMessage)
+}
+
+// CancelMessages cancels each message independently.
+func (s *Service) CancelMessages(messageIDs []string) (models.OutboxBulkResult, error) {
+	return runOutboxBulk(messageIDs, s.CancelMessage)
+}
+
+func runOutboxBulk(messageIDs []string, apply func(string) (models.Message, error)) (models.OutboxBulkResult, error) {
+	if len(messageIDs) == 0 || len(messageIDs) > maxOutboxBulkIDs {
+		return models.OutboxBulkResult{}, errors.New("between 1 and 500 message ids are required")
+	}
+	result := models.OutboxBulkResult{Succeeded: []string{}}
+	for _, id := range messageIDs {
+		if _, err := apply(id); err != nil {
+			if result.Failed == nil {
+				result.Failed = map[string]string{}
+			}
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, id)
+	}
+	return result, nil
+}
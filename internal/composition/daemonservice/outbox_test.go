@@ -0,0 +1,95 @@
+package daemonservice
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestOutboxListsAndRetriesAcrossConversations(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewMessageStore()
+	now := time.Now().UTC()
+	save := func(id, contactID, direction, status string, age time.Duration) models.Message {
+		msg := models.Message{
+			ID:          id,
+			ContactID:   contactID,
+			Content:     []byte("payload " + id),
+			ContentType: "text",
+			Timestamp:   now.Add(-age),
+			Direction:   direction,
+			Status:      status,
+		}
+		if err := store.SaveMessage(msg); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+		return msg
+	}
+	save("m-failed", "aim1_a", "out", "failed", time.Minute)
+	backoff := save("m-backoff", "aim1_b", "out", "pending", 2*time.Minute)
+	if err := store.AddOrUpdatePending(backoff, 2, now.Add(time.Hour), "network down"); err != nil {
+		t.Fatalf("add pending: %v", err)
+	}
+	save("m-sent", "aim1_b", "out", "delivered", 3*time.Minute)
+	save("m-read", "aim1_a", "out", "read", 4*time.Minute)
+	save("m-in", "aim1_a", "in", "delivered", 5*time.Minute)
+
+	svc := &Service{
+		messageStore:     store,
+		logger:           runtimeapp.DefaultLogger(),
+		metrics:          runtimeapp.NewServiceMetricsState(),
+		notifier:         runtimeapp.NewNotificationHub(32),
+		outboundMu:       &sync.Mutex{},
+		outboundInFlight: map[string]struct{}{},
+		deadLetters:      storage.NewDeadLetterStore(),
+	}
+
+	all, err := svc.ListOutbox(nil, 0, 0)
+	if err != nil {
+		t.Fatalf("list outbox: %v", err)
+	}
+	want := []struct{ id, category string }{
+		{"m-failed", models.OutboxFailed},
+		{"m-backoff", models.OutboxScheduled},
+		{"m-sent", models.OutboxSentUnread},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("unexpected outbox: %+v", all)
+	}
+	for i, w := range want {
+		if all[i].Message.ID != w.id || all[i].Category != w.category {
+			t.Fatalf("entry %d: got %s/%s, want %s/%s", i, all[i].Message.ID, all[i].Category, w.id, w.category)
+		}
+	}
+	if _, err := svc.ListOutbox([]string{"bogus"}, 0, 0); err == nil {
+		t.Fatal("expected unknown category to be rejected")
+	}
+
+	result, err := svc.RetryMessages([]string{"m-failed", "m-backoff", "m-sent"})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(result.Succeeded) != 2 || result.Failed["m-sent"] == "" {
+		t.Fatalf("unexpected retry result: %+v", result)
+	}
+	pending, err := svc.ListOutbox([]string{models.OutboxPending}, 0, 0)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("retried messages must be pending again: %+v err=%v", pending, err)
+	}
+	if due := store.DuePending(time.Now()); len(due) != 2 {
+		t.Fatalf("retried messages must be due now, got %d", len(due))
+	}
+
+	result, err = svc.CancelMessages([]string{"m-failed", "m-read"})
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(result.Succeeded) != 1 || result.Succeeded[0] != "m-failed" || result.Failed["m-read"] == "" {
+		t.Fatalf("unexpected cancel result: %+v", result)
+	}
+}
@@ -0,0 +1,79 @@
+package daemonservice
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/crypto"
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/internal/waku"
+)
+
+const (
+	// pairTopicCheckInterval bounds how long a new session waits before its
+	// pair topic is listened to.
+	pairTopicCheckInterval = 10 * time.Second
+	// pairTopicResubscribeInterval re-pushes unchanged secrets so the
+	// transport moves its subscriptions across topic rollovers.
+	pairTopicResubscribeInterval = 10 * time.Minute
+)
+
+// pairTopicState remembers what was last handed to the transport so the
+// retry loop only pushes changes.
+type pairTopicState struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+	pushed    string
+}
+
+func newPairTopicState() *pairTopicState {
+	return &pairTopicState{}
+}
+
+// refreshPairTopics hands the transport one topic secret per session. A
+// contact's pair topic is only published to once it advertised pair topics,
+// so it listens on them, and we have decrypted a message from it, which
+// proves it holds the session the topic is derived from.
+func (s *Service) refreshPairTopics(now time.Time) {
+	if s.wakuCfg == nil || !s.wakuCfg.PairTopics {
+		return
+	}
+	setter, ok := s.wakuNode.(interface {
+		SetPairTopicSecrets(secrets map[string]waku.PairTopicSecret)
+	})
+	if !ok {
+		return
+	}
+	state := s.pairTopics
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.lastCheck.IsZero() && now.Sub(state.lastCheck) < pairTopicCheckInterval {
+		return
+	}
+	state.lastCheck = now
+
+	sessions, err := s.sessionManager.Snapshot()
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	secrets := make(map[string]waku.PairTopicSecret, len(sessions))
+	keys := make([]string, 0, len(sessions)+1)
+	for _, session := range sessions {
+		publish := session.RecvChainIndex > 0 && s.contactSupports(session.ContactID, identityapp.CapabilityPairTopics, false)
+		secrets[session.ContactID] = waku.PairTopicSecret{Secret: crypto.DerivePairTopicSecret(session), Publish: publish}
+		keys = append(keys, session.SessionID+":"+strconv.FormatBool(publish))
+	}
+	sort.Strings(keys)
+	keys = append(keys, now.UTC().Truncate(pairTopicResubscribeInterval).Format(time.RFC3339))
+	pushed := strings.Join(keys, ",")
+	if pushed == state.pushed {
+		return
+	}
+	state.pushed = pushed
+	setter.SetPairTopicSecrets(secrets)
+}
@@ -0,0 +1,77 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/crypto"
+	"aim-chat/go-backend/internal/domains/contracts"
+	identityapp "aim-chat/go-backend/internal/domains/identity"
+	"aim-chat/go-backend/internal/waku"
+)
+
+// pairTopicTransport records the secrets handed to the transport.
+type pairTopicTransport struct {
+	contracts.TransportNode
+	mu      sync.Mutex
+	secrets map[string]waku.PairTopicSecret
+}
+
+func (p *pairTopicTransport) SetPairTopicSecrets(secrets map[string]waku.PairTopicSecret) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets = secrets
+}
+
+func (p *pairTopicTransport) last(contactID string) waku.PairTopicSecret {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.secrets[contactID]
+}
+
+func TestPairTopicsArePublishedOnlyToContactsThatAdvertiseThem(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	cfg.PairTopics = true
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if !slices.Contains(svc.localCapabilities(), identityapp.CapabilityPairTopics) {
+		t.Fatalf("pair topics must be advertised when enabled, got %v", svc.localCapabilities())
+	}
+	transport := &pairTopicTransport{TransportNode: svc.wakuNode}
+	svc.wakuNode = transport
+
+	now := time.Now()
+	if err := svc.sessionManager.RestoreSnapshot([]crypto.SessionState{{
+		SessionID:      "sess-bob",
+		ContactID:      "aim1bob",
+		RootKey:        make([]byte, 32),
+		RecvChainIndex: 1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}}); err != nil {
+		t.Fatalf("restore session: %v", err)
+	}
+
+	svc.refreshPairTopics(now)
+	if got := transport.last("aim1bob"); len(got.Secret) == 0 || got.Publish {
+		t.Fatalf("a contact without the capability must only be listened to, got %+v", got)
+	}
+
+	svc.observeWireCapabilities("aim1bob", contracts.WirePayload{
+		SenderDeviceID: "dev-1",
+		Caps:           []string{identityapp.CapabilityPairTopics},
+	})
+	now = now.Add(pairTopicCheckInterval)
+	svc.refreshPairTopics(now)
+	if got := transport.last("aim1bob"); !got.Publish {
+		t.Fatalf("a contact advertising pair topics must be published to, got %+v", got)
+	}
+}
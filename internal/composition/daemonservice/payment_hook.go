@@ -0,0 +1,72 @@
+package daemonservice
+
+import (
+	"context"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// paymentVerifyTimeout bounds how long a verifier may hold up inbound
+// processing, e.g. while looking up an invoice.
+const paymentVerifyTimeout = 10 * time.Second
+
+// SetPaymentVerifier installs the optional payment hook. While a verifier is
+// installed, unknown senders must attach a first-contact payment it accepts,
+// and tips it accepts are recorded as verified. Nil removes the hook.
+func (s *Service) SetPaymentVerifier(verifier contracts.PaymentVerifier) {
+	s.paymentMu.Lock()
+	s.paymentVerifier = verifier
+	s.paymentMu.Unlock()
+}
+
+func (s *Service) currentPaymentVerifier() contracts.PaymentVerifier {
+	s.paymentMu.RLock()
+	defer s.paymentMu.RUnlock()
+	return s.paymentVerifier
+}
+
+func (s *Service) verifyFirstContactPayment(msg messagingapp.InboundPrivateMessage, wire contracts.WirePayload) error {
+	verifier := s.currentPaymentVerifier()
+	if !privacydomain.FirstContactPaymentRequired(s.identityManager.HasContact(msg.SenderID), verifier != nil) {
+		return nil
+	}
+	if wire.Payment == nil || wire.Payment.Purpose != models.PaymentPurposeFirstContact {
+		return privacydomain.ErrFirstContactPaymentRequired
+	}
+	return s.verifyPayment(verifier, msg, *wire.Payment)
+}
+
+// resolveInboundTip records a tip carried by a message. Without a verifier
+// the tip is kept but marked unverified.
+func (s *Service) resolveInboundTip(msg messagingapp.InboundPrivateMessage, payment models.PaymentProof) *models.MessageTip {
+	tip := &models.MessageTip{
+		Method: payment.Method,
+		Amount: payment.Amount,
+		Unit:   payment.Unit,
+	}
+	verifier := s.currentPaymentVerifier()
+	if verifier == nil {
+		return tip
+	}
+	if err := s.verifyPayment(verifier, msg, payment); err != nil {
+		s.recordError(contracts.ErrorCategoryAPI, err)
+		return tip
+	}
+	tip.Verified = true
+	return tip
+}
+
+func (s *Service) verifyPayment(verifier contracts.PaymentVerifier, msg messagingapp.InboundPrivateMessage, payment models.PaymentProof) error {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentVerifyTimeout)
+	defer cancel()
+	return verifier.VerifyPayment(ctx, contracts.PaymentVerification{
+		SenderID:    msg.SenderID,
+		RecipientID: s.identityManager.GetIdentity().ID,
+		MessageID:   msg.ID,
+		Proof:       payment,
+	})
+}
@@ -0,0 +1,94 @@
+package daemonservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type fakePaymentVerifier struct {
+	requests []contracts.PaymentVerification
+}
+
+func (f *fakePaymentVerifier) VerifyPayment(_ context.Context, req contracts.PaymentVerification) error {
+	f.requests = append(f.requests, req)
+	if !bytes.Equal(req.Proof.Proof, []byte("paid")) {
+		return errors.New("payment not found")
+	}
+	return nil
+}
+
+func deliverPlainFromStranger(t *testing.T, svc *Service, senderID, messageID string, payment *models.PaymentProof) {
+	t.Helper()
+	payload, err := json.Marshal(contracts.WirePayload{Kind: "plain", Plain: []byte("hello"), Payment: payment})
+	if err != nil {
+		t.Fatalf("marshal wire: %v", err)
+	}
+	svc.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{
+		ID:       messageID,
+		SenderID: senderID,
+		Payload:  payload,
+	})
+}
+
+func TestPaymentVerifierGatesFirstContact(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	verifier := &fakePaymentVerifier{}
+	svc.SetPaymentVerifier(verifier)
+
+	deliverPlainFromStranger(t, svc, "aim1stranger", "msg-unpaid", nil)
+	deliverPlainFromStranger(t, svc, "aim1stranger", "msg-bogus", &models.PaymentProof{
+		Purpose: models.PaymentPurposeFirstContact, Method: "ln_invoice", Proof: []byte("bogus"),
+	})
+	if got := svc.messageStore.ListMessages("aim1stranger", 0, 0); len(got) != 0 {
+		t.Fatalf("unpaid first contact must be dropped, got %d messages", len(got))
+	}
+
+	deliverPlainFromStranger(t, svc, "aim1stranger", "msg-paid", &models.PaymentProof{
+		Purpose: models.PaymentPurposeFirstContact, Method: "ln_invoice", Proof: []byte("paid"),
+	})
+	if _, ok := svc.messageStore.GetMessage("msg-paid"); !ok {
+		t.Fatal("paid first contact must be accepted")
+	}
+	last := verifier.requests[len(verifier.requests)-1]
+	if last.SenderID != "aim1stranger" || last.MessageID != "msg-paid" || last.Proof.Method != "ln_invoice" {
+		t.Fatalf("unexpected verification request: %+v", last)
+	}
+}
+
+func TestInboundTipIsRecordedOnMessage(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	tip := &models.PaymentProof{Purpose: models.PaymentPurposeTip, Method: "onchain", Amount: 1500, Unit: "sat", Proof: []byte("paid")}
+
+	deliverPlainFromStranger(t, svc, "aim1tipper", "msg-tip", tip)
+	msg, ok := svc.messageStore.GetMessage("msg-tip")
+	if !ok || msg.Tip == nil {
+		t.Fatalf("tip must be recorded on the message: %+v", msg)
+	}
+	if msg.Tip.Verified || msg.Tip.Amount != 1500 || msg.Tip.Unit != "sat" {
+		t.Fatalf("tip without verifier must stay unverified: %+v", msg.Tip)
+	}
+
+	svc.SetPaymentVerifier(&fakePaymentVerifier{})
+	recorded := svc.resolveInboundTip(messagingapp.InboundPrivateMessage{ID: "msg-tip-2", SenderID: "aim1tipper"}, *tip)
+	if recorded == nil || !recorded.Verified {
+		t.Fatalf("accepted tip must be marked verified: %+v", recorded)
+	}
+}
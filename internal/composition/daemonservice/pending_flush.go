@@ -0,0 +1,103 @@
+package daemonservice
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// reconnectFlushInterval is the minimum gap between presence-triggered
+// flushes for one contact, so a burst of inbound traffic costs one flush.
+const reconnectFlushInterval = 10 * time.Second
+
+// FlushPendingMessages attempts every queued message for contactID right
+// away instead of waiting for its backoff timer. Messages that still fail
+// follow the normal retry schedule.
+func (s *Service) FlushPendingMessages(contactID string) (models.PendingFlushResult, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return models.PendingFlushResult{}, errors.New("contact id is required")
+	}
+	ctx, err := s.networkContext(contracts.ErrorCategoryNetwork)
+	if err != nil {
+		return models.PendingFlushResult{}, err
+	}
+	pending := s.messageStore.PendingForContact(contactID)
+	s.processPendingBatch(ctx, pending, s.handleRetryPublishError)
+	result := models.PendingFlushResult{ContactID: contactID, Attempted: len(pending)}
+	for _, p := range pending {
+		msg, ok := s.messageStore.GetMessage(p.Message.ID)
+		switch {
+		case !ok:
+		case msg.Status == "pending":
+			result.Pending++
+		case msg.Status == "failed":
+			result.Failed++
+		default:
+			result.Sent++
+		}
+	}
+	return result, nil
+}
+
+// noteContactOnline flushes the contact's queue when verified direct or group
+// traffic, or regained peers, show that a contact with messages waiting on a
+// retry backoff is reachable again.
+func (s *Service) noteContactOnline(contactID string) {
+	if s.flushMu == nil || !s.runtime.IsNetworking() {
+		return
+	}
+	now := time.Now()
+	if !hasBackedOffPending(s.messageStore.PendingForContact(contactID), now) {
+		return
+	}
+	s.flushMu.Lock()
+	if last, ok := s.lastFlushAt[contactID]; ok && now.Sub(last) < reconnectFlushInterval {
+		s.flushMu.Unlock()
+		return
+	}
+	s.lastFlushAt[contactID] = now
+	s.flushMu.Unlock()
+	go func() {
+		result, err := s.FlushPendingMessages(contactID)
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+			return
+		}
+		s.logInfo("message.reconnect_flush", contactID, "pending messages flushed on contact reconnect", "contact_id", contactID, "attempted", result.Attempted, "sent", result.Sent)
+	}()
+}
+
+// notePeersReconnected flushes every contact with backed-off messages when
+// the node regains peers after having none. Messages that failed while the
+// node was cut off can reach the contact, or the node relaying for them,
+// again.
+func (s *Service) notePeersReconnected() {
+	if s.flushMu == nil || !s.runtime.IsNetworking() {
+		return
+	}
+	now := time.Now()
+	_, pending := s.messageStore.Snapshot()
+	contacts := make(map[string]struct{})
+	for _, p := range pending {
+		if p.NextRetry.After(now) {
+			contacts[p.Message.ContactID] = struct{}{}
+		}
+	}
+	for contactID := range contacts {
+		s.noteContactOnline(contactID)
+	}
+}
+
+func hasBackedOffPending(pending []storage.PendingMessage, now time.Time) bool {
+	for _, p := range pending {
+		if p.NextRetry.After(now) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package daemonservice
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestRuntimeE2E_ContactReconnectFlushesBackedOffMessages(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	makeService := func(name string) *Service {
+		svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, name))
+		if err != nil {
+			t.Fatalf("new service %s: %v", name, err)
+		}
+		return svc
+	}
+	alice := makeService("alice")
+	bob := makeService("bob")
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, _ := alice.GetIdentity()
+	bobIdentity, _ := bob.GetIdentity()
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	if _, err := alice.FlushPendingMessages(bobIdentity.ID); err == nil {
+		t.Fatal("expected flush to fail while networking is stopped")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	// Queued after startup recovery: a message that already failed twice
+	// while Bob was unreachable waits an hour for its next attempt.
+	queued := models.Message{
+		ID:          "msg-backoff",
+		ContactID:   bobIdentity.ID,
+		Content:     []byte("sent while you were away"),
+		ContentType: "text",
+		Timestamp:   time.Now().UTC().Add(-time.Minute),
+		Direction:   "out",
+		Status:      "pending",
+	}
+	if err := alice.messageStore.SaveMessage(queued); err != nil {
+		t.Fatalf("save queued message: %v", err)
+	}
+	if err := alice.messageStore.AddOrUpdatePending(queued, 2, time.Now().Add(time.Hour), "peer unreachable"); err != nil {
+		t.Fatalf("queue message: %v", err)
+	}
+
+	if _, err := bob.SendMessage(context.Background(), aliceIdentity.ID, "back online"); err != nil {
+		t.Fatalf("bob send: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if msg, ok := alice.messageStore.GetMessage(queued.ID); ok && msg.Status != "pending" {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if msg, _ := alice.messageStore.GetMessage(queued.ID); msg.Status == "pending" {
+		t.Fatalf("backed-off message was not flushed on reconnect: %+v", msg)
+	}
+	if left := alice.messageStore.PendingForContact(bobIdentity.ID); len(left) != 0 {
+		t.Fatalf("expected empty queue, got %+v", left)
+	}
+
+	result, err := alice.FlushPendingMessages(bobIdentity.ID)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if result.Attempted != 0 || result.ContactID != bobIdentity.ID {
+		t.Fatalf("unexpected flush result: %+v", result)
+	}
+}
+
+// countingTransport records private publishes per message id and holds each
+// one briefly so that concurrent flushes overlap.
+type countingTransport struct {
+	contracts.TransportNode
+	mu        sync.Mutex
+	published map[string]int
+}
+
+func (c *countingTransport) PublishPrivate(ctx context.Context, msg waku.PrivateMessage) error {
+	c.mu.Lock()
+	c.published[msg.ID]++
+	c.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	return c.TransportNode.PublishPrivate(ctx, msg)
+}
+
+func TestRuntimeE2E_ConcurrentFlushesPublishEachMessageOnce(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new service alice: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new service bob: %v", err)
+	}
+	counter := &countingTransport{TransportNode: alice.wakuNode, published: map[string]int{}}
+	alice.wakuNode = counter
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, _ := alice.GetIdentity()
+	bobIdentity, _ := bob.GetIdentity()
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		queued := models.Message{
+			ID:          fmt.Sprintf("msg-flush-%d", i),
+			ContactID:   bobIdentity.ID,
+			Content:     []byte("queued"),
+			ContentType: "text",
+			Timestamp:   time.Now().UTC().Add(time.Duration(i-10) * time.Second),
+			Direction:   "out",
+			Status:      "pending",
+		}
+		if err := alice.messageStore.SaveMessage(queued); err != nil {
+			t.Fatalf("save queued message: %v", err)
+		}
+		if err := alice.messageStore.AddOrUpdatePending(queued, 2, time.Now().Add(time.Hour), "peer unreachable"); err != nil {
+			t.Fatalf("queue message: %v", err)
+		}
+		ids = append(ids, queued.ID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := alice.FlushPendingMessages(bobIdentity.ID); err != nil {
+				t.Errorf("flush: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	for _, id := range ids {
+		if got := counter.published[id]; got != 1 {
+			t.Fatalf("message %s published %d times, want once", id, got)
+		}
+	}
+	if left := alice.messageStore.PendingForContact(bobIdentity.ID); len(left) != 0 {
+		t.Fatalf("expected empty queue, got %+v", left)
+	}
+}
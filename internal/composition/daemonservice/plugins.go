@@ -0,0 +1,102 @@
+package daemonservice
+
+import (
+	"context"
+	"path/filepath"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/platform/plugins"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	pluginsWorkDir   = "plugins"
+	pluginsStateFile = "plugins-state.json"
+	// pluginAnnotationPrefix namespaces each plugin's annotations, so a
+	// plugin never overwrites another integration's labels.
+	pluginAnnotationPrefix = "plugin:"
+)
+
+// configurePlugins loads the plugins listed in the file named by
+// AIM_PLUGINS_CONFIG and asks each for its capabilities. Without the
+// variable no plugins run.
+func (s *Service) configurePlugins() error {
+	path := envString("AIM_PLUGINS_CONFIG")
+	if path == "" {
+		return nil
+	}
+	configs, err := plugins.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	host, err := plugins.NewHost(configs, filepath.Join(s.dataDir, pluginsWorkDir), filepath.Join(s.dataDir, pluginsStateFile))
+	if err != nil {
+		return err
+	}
+	host.Start(context.Background())
+	s.plugins = host
+	s.logger.Info("message plugins loaded", "count", len(configs))
+	return nil
+}
+
+func (s *Service) ListPlugins() ([]models.PluginInfo, error) {
+	return s.plugins.List(), nil
+}
+
+// SetPluginEnabled turns a plugin on or off. The choice survives restarts.
+func (s *Service) SetPluginEnabled(name string, enabled bool) (models.PluginInfo, error) {
+	info, err := s.plugins.SetEnabled(context.Background(), name, enabled)
+	if err != nil {
+		return models.PluginInfo{}, err
+	}
+	s.logger.Info("message plugin toggled", "plugin", info.Name, "enabled", info.Enabled)
+	return info, nil
+}
+
+func (s *Service) processOutboundDraft(ctx context.Context, conversationID, conversationType, threadID, content string) (string, error) {
+	return s.plugins.ProcessOutbound(ctx, plugins.Draft{
+		ConversationID:   conversationID,
+		ConversationType: conversationType,
+		ThreadID:         threadID,
+		Content:          content,
+	})
+}
+
+// annotateWithPlugins offers a saved inbound message to the annotating
+// plugins on their own worker, off the receive path, and stores each
+// plugin's annotations as they arrive.
+func (s *Service) annotateWithPlugins(msg models.Message) {
+	if msg.ContentType == models.MessageContentTypeSystem {
+		return
+	}
+	msg = models.NormalizeMessageConversation(msg)
+	queued := s.plugins.QueueInboundAnnotation(plugins.Message{
+		MessageID:        msg.ID,
+		ConversationID:   msg.ConversationID,
+		ConversationType: msg.ConversationType,
+		SenderID:         msg.ContactID,
+		ContentType:      msg.ContentType,
+		Content:          string(msg.Content),
+	}, func(name string, annotations map[string]string) {
+		s.storePluginAnnotations(name, msg.ID, annotations)
+	})
+	if !queued {
+		s.logger.Warn("message plugin annotation skipped", "reason", "queue_full", "message_id", msg.ID)
+	}
+}
+
+func (s *Service) storePluginAnnotations(name, messageID string, annotations map[string]string) {
+	// An account switch while the plugin ran leaves the message behind.
+	if _, ok := s.messageStore.GetMessage(messageID); !ok {
+		return
+	}
+	for key, value := range annotations {
+		if value == "" {
+			continue
+		}
+		if _, err := s.annotations.Set(pluginAnnotationPrefix+name, messageID, key, value); err != nil {
+			s.plugins.RecordError(name, err)
+			s.recordError(contracts.ErrorCategoryStorage, err)
+		}
+	}
+}
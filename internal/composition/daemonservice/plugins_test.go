@@ -0,0 +1,117 @@
+package daemonservice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/platform/plugins"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// TestMessagePluginHelperProcess is the plugin the tests configure: it
+// redacts drafts, rejects drafts saying BLOCK and tags inbound messages.
+func TestMessagePluginHelperProcess(t *testing.T) {
+	if os.Getenv("AIM_PLUGIN_PROTOCOL") == "" {
+		return
+	}
+	var req struct {
+		Method  string          `json:"method"`
+		Draft   plugins.Draft   `json:"draft"`
+		Message plugins.Message `json:"message"`
+	}
+	line, _ := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	_ = json.Unmarshal(line, &req)
+	var resp any
+	switch req.Method {
+	case plugins.MethodDescribe:
+		resp = map[string]any{"version": "0.1", "capabilities": []string{plugins.CapabilityOutboundTransform, plugins.CapabilityInboundAnnotate}}
+	case plugins.MethodOutboundDraft:
+		if strings.Contains(req.Draft.Content, "BLOCK") {
+			resp = map[string]any{"reject": "blocked word"}
+		} else {
+			resp = map[string]any{"content": strings.ReplaceAll(req.Draft.Content, "hunter2", "[redacted]")}
+		}
+	case plugins.MethodInboundMessage:
+		resp = map[string]any{"annotations": map[string]string{"tag": "greeting"}}
+	}
+	raw, _ := json.Marshal(resp)
+	fmt.Println(string(raw))
+	os.Exit(0)
+}
+
+func TestMessagePluginsTransformDraftsAndAnnotateInbound(t *testing.T) {
+	exe, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		t.Fatalf("resolve test binary: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "plugins.json")
+	raw, _ := json.Marshal(map[string]any{"plugins": []plugins.Config{{
+		Name:    "redact",
+		Command: exe,
+		Args:    []string{"-test.run=^TestMessagePluginHelperProcess$"},
+	}}})
+	if err := os.WriteFile(configPath, raw, 0o600); err != nil {
+		t.Fatalf("write plugins config: %v", err)
+	}
+	t.Setenv("AIM_PLUGINS_CONFIG", configPath)
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	self, err := svc.GetIdentity()
+	if err != nil {
+		t.Fatalf("identity: %v", err)
+	}
+	list, err := svc.ListPlugins()
+	if err != nil || len(list) != 1 || !list[0].Enabled || len(list[0].Capabilities) != 2 {
+		t.Fatalf("expected the configured plugin to be described, got %+v %v", list, err)
+	}
+
+	ctx := context.Background()
+	noteID, err := svc.SendMessage(ctx, self.ID, "password is hunter2")
+	if err != nil {
+		t.Fatalf("send message: %v", err)
+	}
+	if note, _ := svc.messageStore.GetMessage(noteID); string(note.Content) != "password is [redacted]" {
+		t.Fatalf("expected the draft to be redacted, got %q", note.Content)
+	}
+	if _, err := svc.SendMessage(ctx, self.ID, "BLOCK this"); !errors.Is(err, plugins.ErrDraftRejected) {
+		t.Fatalf("expected the draft to be rejected, got %v", err)
+	}
+
+	in := models.Message{ID: "in-1", ContactID: "bob", Direction: "in", Status: "delivered", ContentType: "text", Content: []byte("hi"), Timestamp: time.Now().UTC()}
+	if !svc.persistInboundMessage(in, "bob") {
+		t.Fatal("persist inbound message failed")
+	}
+	// Annotation runs off the receive path and lands shortly after.
+	notes, err := svc.ListMessageAnnotations(pluginAnnotationPrefix+"redact", "in-1")
+	for deadline := time.Now().Add(5 * time.Second); err == nil && len(notes) == 0 && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+		notes, err = svc.ListMessageAnnotations(pluginAnnotationPrefix+"redact", "in-1")
+	}
+	if err != nil || len(notes) != 1 || notes[0].Key != "tag" || notes[0].Value != "greeting" {
+		t.Fatalf("expected the plugin annotation, got %+v %v", notes, err)
+	}
+
+	if info, err := svc.SetPluginEnabled("redact", false); err != nil || info.Enabled {
+		t.Fatalf("disable plugin: %+v %v", info, err)
+	}
+	if _, err := svc.SendMessage(ctx, self.ID, "BLOCK this"); err != nil {
+		t.Fatalf("disabled plugin must not see drafts: %v", err)
+	}
+	if err := svc.Close(); err != nil {
+		t.Fatalf("close service: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestSendRejectionsCarryPolicyContext(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "alice"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	const (
+		stranger  = "aim1strangerpeer0001"
+		requester = "aim1requesterpeer001"
+		blocked   = "aim1blockedpeer00001"
+	)
+	svc.requestRuntime.Mu.Lock()
+	svc.requestRuntime.Inbox[requester] = []models.Message{{ID: "req-1", ContactID: requester, Direction: "in", Content: []byte("hi")}}
+	svc.requestRuntime.Mu.Unlock()
+	if _, err := svc.AddToBlocklist(blocked); err != nil {
+		t.Fatalf("add to blocklist: %v", err)
+	}
+
+	cases := []struct {
+		contactID   string
+		sentinel    error
+		reason      string
+		setting     string
+		remediation string
+	}{
+		{stranger, messagingapp.ErrContactNotAdded, contracts.PolicyReasonNotContact, contracts.PolicySettingContacts, "contact.add"},
+		{requester, messagingapp.ErrContactNotAdded, contracts.PolicyReasonPrivacyMode, contracts.PolicySettingMessagePrivacyMode, "request.accept"},
+		{blocked, messagingapp.ErrContactBlocked, contracts.PolicyReasonBlocked, contracts.PolicySettingBlocklist, "blocklist.remove"},
+	}
+	for _, tc := range cases {
+		_, err := svc.SendMessage(context.Background(), tc.contactID, "hello")
+		if !errors.Is(err, tc.sentinel) {
+			t.Fatalf("send to %s: expected %v, got %v", tc.contactID, tc.sentinel, err)
+		}
+		var rejection *contracts.PolicyRejectionError
+		if !errors.As(err, &rejection) || rejection.ErrorData() != rejection {
+			t.Fatalf("send to %s: expected policy rejection, got %T", tc.contactID, err)
+		}
+		if rejection.Reason != tc.reason || rejection.Setting != tc.setting || rejection.Remediation != tc.remediation || rejection.ContactID != tc.contactID {
+			t.Fatalf("send to %s: unexpected rejection %+v", tc.contactID, rejection)
+		}
+	}
+}
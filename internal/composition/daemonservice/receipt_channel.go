@@ -0,0 +1,66 @@
+package daemonservice
+
+import (
+	"context"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/waku"
+)
+
+const receiptsInlineEnv = "AIM_RECEIPTS_INLINE"
+
+// receiptChannelTransport is implemented by transports that carry receipts on
+// a dedicated per-identity channel instead of the private message topic.
+type receiptChannelTransport interface {
+	SubscribeReceipts(handler func(waku.PrivateMessage)) error
+	PublishReceipt(ctx context.Context, msg waku.PrivateMessage) error
+	FetchReceiptsSince(ctx context.Context, recipient string, since time.Time, limit int) ([]waku.PrivateMessage, error)
+}
+
+func resolveReceiptsInlineFromEnv() bool {
+	return envBoolWithFallback(receiptsInlineEnv, false)
+}
+
+// receiptChannel returns the dedicated receipts transport, or false when
+// receipts must travel inline on the private channel.
+func (s *Service) receiptChannel() (receiptChannelTransport, bool) {
+	if s.receiptsInline {
+		return nil, false
+	}
+	transport, ok := s.wakuNode.(receiptChannelTransport)
+	return transport, ok
+}
+
+// subscribeReceiptChannel is best effort: inbound receipts sent inline by
+// older peers keep flowing through the private subscription regardless.
+func (s *Service) subscribeReceiptChannel() {
+	transport, ok := s.wakuNode.(receiptChannelTransport)
+	if !ok {
+		return
+	}
+	if err := transport.SubscribeReceipts(s.handleIncomingReceiptMessage); err != nil {
+		s.recordError(contracts.ErrorCategoryNetwork, err)
+	}
+}
+
+func (s *Service) syncMissedReceipts(identityID string) {
+	transport, ok := s.wakuNode.(receiptChannelTransport)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	missed, err := transport.FetchReceiptsSince(ctx, identityID, time.Now().Add(-24*time.Hour), 500)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryNetwork, err)
+		return
+	}
+	for _, msg := range missed {
+		s.handleIncomingReceiptMessage(msg)
+	}
+}
+
+func (s *Service) handleIncomingReceiptMessage(msg waku.PrivateMessage) {
+	s.inboundMessagingCore.HandleIncomingReceipt(toInboundPrivateMessage(msg))
+}
@@ -0,0 +1,75 @@
+package daemonservice
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errDeadLetterNotFound = errors.New("message is not in the dead-letter queue")
+
+// GetRetryPolicy returns the retry policy of outbound messages.
+func (s *Service) GetRetryPolicy() models.RetryPolicy {
+	s.retryPolicyMu.RLock()
+	defer s.retryPolicyMu.RUnlock()
+	return s.retryPolicy
+}
+
+// SetRetryPolicy replaces the retry policy until the daemon restarts; the
+// config file holds the one it starts with. Messages already waiting keep
+// their scheduled time.
+func (s *Service) SetRetryPolicy(policy models.RetryPolicy) (models.RetryPolicy, error) {
+	policy, err := messagingapp.NormalizeRetryPolicy(policy)
+	if err != nil {
+		return models.RetryPolicy{}, err
+	}
+	s.retryPolicyMu.Lock()
+	s.retryPolicy = policy
+	s.retryPolicyMu.Unlock()
+	return policy, nil
+}
+
+func (s *Service) nextRetryTime(retryCount int) time.Time {
+	return time.Now().Add(messagingapp.RetryBackoff(s.GetRetryPolicy(), retryCount, rand.Float64()))
+}
+
+// ListDeadLetters returns messages that ran out of retries, newest first,
+// for one contact or, with an empty id, for all of them.
+func (s *Service) ListDeadLetters(contactID string, limit, offset int) []models.DeadLetter {
+	return s.deadLetters.List(contactID, limit, offset)
+}
+
+// RequeueDeadLetter puts a dead-lettered message back on the retry queue
+// with a fresh retry budget.
+func (s *Service) RequeueDeadLetter(messageID string) (models.Message, error) {
+	if _, ok := s.deadLetters.Get(messageID); !ok {
+		return models.Message{}, errDeadLetterNotFound
+	}
+	return s.RetryMessage(messageID)
+}
+
+// deadLetterMessage moves a message that ran out of retries to the
+// dead-letter queue. The caller drops it from the retry queue.
+func (s *Service) deadLetterMessage(p storage.PendingMessage, attempts int, err error) {
+	msg := p.Message
+	if stored, ok := s.messageStore.GetMessage(msg.ID); ok {
+		msg = stored
+	}
+	entry := models.DeadLetter{Message: msg, Attempts: attempts, LastError: err.Error(), DeadAt: time.Now().UTC()}
+	if addErr := s.deadLetters.Add(entry); addErr != nil {
+		s.recordError(contracts.ErrorCategoryStorage, addErr)
+	}
+}
+
+// forgetDeadLetter drops a message from the dead-letter queue once it was
+// retried or cancelled another way.
+func (s *Service) forgetDeadLetter(messageID string) {
+	if _, err := s.deadLetters.Remove(messageID); err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+	}
+}
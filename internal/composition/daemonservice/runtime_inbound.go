@@ -8,6 +8,7 @@ import (
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/events"
 	"aim-chat/go-backend/pkg/models"
 	"errors"
 	"strings"
@@ -27,23 +28,35 @@ func (s *Service) handleInboundGroupMessage(msg messagingapp.InboundPrivateMessa
 		states[strings.TrimSpace(wire.ConversationID)] = state
 	}
 	svc := &groupdomain.InboundOrchestrationService{
-		States:          states,
-		Now:             time.Now,
-		IsBlockedSender: s.privacyCore.IsBlockedSender,
-		GuardReplay:     s.guardInboundGroupReplay,
+		States:           states,
+		Now:              time.Now,
+		IsBlockedSender:  s.privacyCore.IsBlockedSender,
+		IsBlockedInGroup: s.privacyCore.IsBlockedInGroup,
+		GuardReplay:      s.guardInboundGroupReplay,
 		ResolveInboundContent: func() ([]byte, string, error) {
 			return messagingapp.ResolveInboundContent(msg, wire, s.sessionManager)
 		},
 		BuildStoredMessage: func(content []byte, contentType string, now time.Time) models.Message {
-			return messagingapp.BuildInboundGroupStoredMessage(msg, wire.ConversationID, wire.ThreadID, content, contentType, now)
+			stored := messagingapp.BuildInboundGroupStoredMessage(msg, wire.ConversationID, wire.ThreadID, content, contentType, now)
+			// Moderators and comment threads refer to posts by event id.
+			stored.EventID = strings.TrimSpace(wire.EventID)
+			stored.SafetyFlags = s.safetyFlagsForMessage(stored)
+			return stored
 		},
 		SaveMessage:         s.messageStore.SaveMessage,
 		IsMessageIDConflict: func(err error) bool { return errors.Is(err, storage.ErrMessageIDConflict) },
 		NotifyGroupMessage: func(groupID string, stored models.Message) {
-			s.notify("notify.group.message.new", map[string]any{
+			payload := map[string]any{
 				"group_id": groupID,
 				"message":  stored,
-			})
+			}
+			if stored.Emergency {
+				payload["notification"] = s.emergencyNotificationHints(groupID)
+			}
+			s.annotateWithPlugins(stored)
+			s.notify("notify.group.message.new", payload)
+			s.notifyThreadReply(groupID, stored)
+			s.noteContactOnline(stored.ContactID)
 		},
 		RecordError:          s.recordError,
 		RecordGroupAggregate: s.recordGroupAggregate,
@@ -55,10 +68,12 @@ func (s *Service) handleInboundGroupMessage(msg messagingapp.InboundPrivateMessa
 		SenderID:          msg.SenderID,
 		Payload:           msg.Payload,
 		ConversationID:    wire.ConversationID,
+		ThreadID:          wire.ThreadID,
 		EventID:           wire.EventID,
 		MembershipVersion: wire.MembershipVersion,
 		GroupKeyVersion:   wire.GroupKeyVersion,
 		SenderDeviceID:    wire.SenderDeviceID,
+		Emergency:         wire.Emergency,
 	})
 }
 
@@ -85,6 +100,20 @@ func (s *Service) handleInboundGroupEvent(msg messagingapp.InboundPrivateMessage
 		)
 		return
 	}
+	if wire.GroupAvatar != nil {
+		if err := groupdomain.VerifyGroupAvatar(*wire.GroupAvatar, event.Avatar); err != nil {
+			s.recordError(contracts.ErrorCategoryCrypto, err)
+			s.recordGroupAggregate("policy_reject")
+			s.logger.Warn(
+				"group event rejected",
+				"reason", "invalid_avatar",
+				"group_id", event.GroupID,
+				"event_id", event.ID,
+				"actor_id", event.ActorID,
+			)
+			return
+		}
+	}
 
 	s.groupRuntime.StateMu.Lock()
 	defer s.groupRuntime.StateMu.Unlock()
@@ -97,19 +126,11 @@ func (s *Service) handleInboundGroupEvent(msg messagingapp.InboundPrivateMessage
 			}
 			return s.groupStateStore.Persist(states, eventLog)
 		},
-		Now:             func() time.Time { return now },
-		IdentityID:      func() string { return s.identityManager.GetIdentity().ID },
-		IsBlockedSender: s.privacyCore.IsBlockedSender,
-		GuardReplay:     s.guardInboundGroupReplay,
-		NotifyGroupUpdated: func(event groupdomain.GroupEvent) {
-			s.notify("notify.group.updated", map[string]any{
-				"group_id":           event.GroupID,
-				"event_id":           event.ID,
-				"event_type":         event.Type,
-				"membership_version": event.Version,
-				"actor_id":           event.ActorID,
-			})
-		},
+		Now:                  func() time.Time { return now },
+		IdentityID:           func() string { return s.identityManager.GetIdentity().ID },
+		IsBlockedSender:      s.privacyCore.IsBlockedSender,
+		GuardReplay:          s.guardInboundGroupReplay,
+		NotifyGroupUpdated:   s.notifyGroupUpdated,
 		RecordError:          s.recordError,
 		RecordGroupAggregate: s.recordGroupAggregate,
 		Warn:                 s.logger.Warn,
@@ -126,6 +147,9 @@ func (s *Service) handleInboundGroupEvent(msg messagingapp.InboundPrivateMessage
 			return wire.Device.ID
 		}(),
 	})
+	if wire.GroupAvatar != nil {
+		s.cacheInboundGroupAvatar(event, *wire.GroupAvatar)
+	}
 }
 
 func toInboundPrivateMessage(msg waku.PrivateMessage) messagingapp.InboundPrivateMessage {
@@ -145,6 +169,20 @@ func (s *Service) notifyGroupUpdated(event groupdomain.GroupEvent) {
 		"membership_version": event.Version,
 		"actor_id":           event.ActorID,
 	})
+	if event.Type == groupdomain.GroupEventTypeRulesChange {
+		// Clients prompt members to acknowledge the new rules on this event.
+		s.notify("notify.group.rules_updated", map[string]any{
+			"group_id":      event.GroupID,
+			"event_id":      event.ID,
+			"actor_id":      event.ActorID,
+			"rules_version": event.RulesVersion,
+			"rules":         event.Rules,
+		})
+	}
+	if event.Type == groupdomain.GroupEventTypeMessageRemove {
+		s.removeModeratedGroupMessageLocked(event)
+	}
+	s.recordGroupSystemMessageLocked(event)
 }
 
 func (s *Service) guardInboundGroupReplay(kind, groupID, senderDeviceID, uniqueID string, occurredAt, now time.Time) error {
@@ -174,6 +212,15 @@ func (s *Service) guardInboundGroupReplay(kind, groupID, senderDeviceID, uniqueI
 	return nil
 }
 
+// ignoreInboundSender reports whether a sender must not learn the identity
+// exists. Our own devices are never contacts but always get through.
+func (s *Service) ignoreInboundSender(senderID string) bool {
+	if senderID == s.identityManager.GetIdentity().ID {
+		return false
+	}
+	return privacydomain.ShouldIgnoreInboundSender(s.privacyCore.Discoverability(), s.identityManager.HasContact(senderID))
+}
+
 func (s *Service) evaluateInboundPolicy(senderID string) privacydomain.InboundMessagePolicyDecision {
 	return privacydomain.EvaluateInboundMessagePolicy(privacydomain.InboundMessagePolicyInput{
 		IsKnownContact: s.identityManager.HasContact(senderID),
@@ -182,12 +229,26 @@ func (s *Service) evaluateInboundPolicy(senderID string) privacydomain.InboundMe
 	})
 }
 
+// applyInboundReceiptStatus records the receipt status at the time the
+// recipient reports, which cannot lie in the future.
 func (s *Service) applyInboundReceiptStatus(receiptHandling messagingapp.InboundReceiptHandling) {
-	s.updateMessageStatusAndNotify(receiptHandling.MessageID, receiptHandling.Status)
+	now := time.Now().UTC()
+	at := receiptHandling.At
+	if at.IsZero() || at.After(now) {
+		at = now
+	}
+	before, found := s.messageStore.GetMessage(receiptHandling.MessageID)
+	if !s.updateMessageStatusAtAndNotify(receiptHandling.MessageID, receiptHandling.Status, at) {
+		return
+	}
+	if found {
+		s.recordDeliveryReceipt(before, receiptHandling.Status, now)
+	}
 }
 
 func (s *Service) persistInboundMessage(in models.Message, senderID string) bool {
 	correlationID := messageCorrelationID(in.ID, in.ContactID)
+	in.SafetyFlags = s.safetyFlagsForMessage(in)
 	if err := s.messageStore.SaveMessage(in); err != nil {
 		if errors.Is(err, storage.ErrMessageIDConflict) {
 			s.logWarn("message.inbound_conflict", correlationID, "inbound message id conflict ignored", "message_id", in.ID, "contact_id", in.ContactID)
@@ -197,15 +258,14 @@ func (s *Service) persistInboundMessage(in models.Message, senderID string) bool
 		return false
 	}
 	s.logInfo("message.inbound_received", correlationID, "message received", "message_id", in.ID, "contact_id", in.ContactID, "content_type", in.ContentType)
-	s.notify("notify.message.new", map[string]any{
-		"contact_id": senderID,
-		"message":    in,
-	})
+	s.annotateWithPlugins(in)
+	s.notify(events.MethodMessageNew, events.MessageNew{ContactID: senderID, Message: in})
 	return true
 }
 
 func (s *Service) persistInboundRequest(in models.Message) bool {
 	correlationID := messageCorrelationID(in.ID, in.ContactID)
+	in.SafetyFlags = s.safetyFlagsForMessage(in)
 	s.requestRuntime.Mu.Lock()
 
 	thread := s.requestRuntime.Inbox[in.ContactID]
@@ -224,9 +284,6 @@ func (s *Service) persistInboundRequest(in models.Message) bool {
 	}
 	s.requestRuntime.Inbox = nextInbox
 	s.requestRuntime.Mu.Unlock()
-	s.notify("notify.request.new", map[string]any{
-		"contact_id": in.ContactID,
-		"message":    in,
-	})
+	s.notify(events.MethodRequestNew, events.RequestNew{ContactID: in.ContactID, Message: in})
 	return true
 }
@@ -0,0 +1,34 @@
+package daemonservice
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// savedMessagesTitle names the notes-to-self conversation in chat.list.
+// Clients are free to localize it by conversation type.
+const savedMessagesTitle = "Saved Messages"
+
+// storeSyncedSavedMessage keeps a note written on another of our devices.
+// Notes never pass contact policy and are never acknowledged with receipts.
+// Notes we already hold, including our own broadcast echoing back, are
+// ignored.
+func (s *Service) storeSyncedSavedMessage(selfID string, note models.SavedMessage) {
+	if _, exists := s.messageStore.GetMessage(note.ID); exists {
+		return
+	}
+	stored := messagingapp.BuildStoredSavedMessage(note, selfID, time.Now())
+	if err := s.messageStore.SaveMessage(stored); err != nil {
+		if !errors.Is(err, storage.ErrMessageIDConflict) {
+			s.recordError(contracts.ErrorCategoryStorage, err)
+		}
+		return
+	}
+	s.notify(events.MethodMessageNew, events.MessageNew{ContactID: selfID, Message: stored})
+}
@@ -0,0 +1,83 @@
+package daemonservice
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestSavedMessagesSyncBetweenOwnDevices(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	phone, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "phone"))
+	if err != nil {
+		t.Fatalf("new phone service: %v", err)
+	}
+	laptop, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "laptop"))
+	if err != nil {
+		t.Fatalf("new laptop service: %v", err)
+	}
+	self, mnemonic, err := phone.CreateIdentity("shared-pass")
+	if err != nil {
+		t.Fatalf("phone identity: %v", err)
+	}
+	if _, err := laptop.ImportIdentity(mnemonic, "shared-pass"); err != nil {
+		t.Fatalf("laptop import: %v", err)
+	}
+
+	// Networking is down, so the note waits in the retry queue.
+	noteID, err := phone.SendMessage(context.Background(), self.ID, "buy milk")
+	if err != nil {
+		t.Fatalf("send saved message: %v", err)
+	}
+	note, ok := phone.messageStore.GetMessage(noteID)
+	if !ok || note.Status != "pending" || note.Seq != 0 {
+		t.Fatalf("unexpected local note: %+v", note)
+	}
+
+	wire, err := phone.buildStoredMessageWire(note)
+	if err != nil {
+		t.Fatalf("build note wire: %v", err)
+	}
+	if wire.Kind != messagingapp.WireKindDeviceSync || wire.SavedMessage == nil {
+		t.Fatalf("note must travel as device sync, got %+v", wire)
+	}
+	wmsg, err := messagingapp.ComposeSignedPrivateMessage(noteID, self.ID, wire, phone.identityManager)
+	if err != nil {
+		t.Fatalf("compose note: %v", err)
+	}
+	inbound := messagingapp.InboundPrivateMessage{ID: wmsg.ID, SenderID: wmsg.SenderID, Recipient: wmsg.Recipient, Payload: wmsg.Payload}
+	laptop.HandleIncomingPrivateMessage(inbound)
+	laptop.HandleIncomingPrivateMessage(inbound)
+	phone.HandleIncomingPrivateMessage(inbound)
+
+	got, err := laptop.GetMessages(self.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("laptop list: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != noteID || string(got[0].Content) != "buy milk" || got[0].Direction != "out" {
+		t.Fatalf("unexpected synced notes: %+v", got)
+	}
+	if echoed, _ := phone.messageStore.GetMessage(noteID); echoed.Status != "pending" {
+		t.Fatalf("own echo must not touch the local note: %+v", echoed)
+	}
+
+	pinned := true
+	if _, err := laptop.SetConversationFlags(self.ID, models.ConversationFlagsUpdate{Pinned: &pinned}); err != nil {
+		t.Fatalf("pin saved messages: %v", err)
+	}
+	chats, err := laptop.ListChats()
+	if err != nil {
+		t.Fatalf("list chats: %v", err)
+	}
+	if len(chats) == 0 || chats[0].ConversationID != self.ID || chats[0].ConversationType != models.ConversationTypeSaved || !chats[0].Pinned {
+		t.Fatalf("saved messages must be listed and pinnable: %+v", chats)
+	}
+}
@@ -0,0 +1,68 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestSendMessageHonorsCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	alice, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "alice"))
+	if err != nil {
+		t.Fatalf("new service alice: %v", err)
+	}
+	bob, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "bob"))
+	if err != nil {
+		t.Fatalf("new service bob: %v", err)
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	defer func() { _ = alice.StopNetworking(stopCtx) }()
+	defer func() { _ = bob.StopNetworking(stopCtx) }()
+
+	aliceIdentity, _ := alice.GetIdentity()
+	bobIdentity, _ := bob.GetIdentity()
+	aliceCard, err := alice.SelfContactCard("Alice")
+	if err != nil {
+		t.Fatalf("alice self card: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("Bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	mustAddContactCard(t, alice, bobCard)
+	mustAddContactCard(t, bob, aliceCard)
+	mustInitPairSession(t, alice, aliceIdentity.ID, aliceCard.PublicKey, bob, bobIdentity.ID, bobCard.PublicKey)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mustStartNetworkingNamed(t, ctx,
+		namedRuntimeService{name: "alice", svc: alice},
+		namedRuntimeService{name: "bob", svc: bob},
+	)
+
+	callerCtx, callerCancel := context.WithCancel(context.Background())
+	callerCancel()
+	if _, err := alice.SendMessage(callerCtx, bobIdentity.ID, "never sent"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if msgs := alice.messageStore.ListMessages(bobIdentity.ID, 0, 0); len(msgs) != 0 {
+		t.Fatalf("cancelled send must not store a message, got %+v", msgs)
+	}
+	if _, err := alice.AcceptMessageRequest(callerCtx, bobIdentity.ID); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from accept, got %v", err)
+	}
+
+	if _, err := alice.SendMessage(context.Background(), bobIdentity.ID, "sent"); err != nil {
+		t.Fatalf("send with live context: %v", err)
+	}
+}
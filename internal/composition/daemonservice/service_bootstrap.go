@@ -1,6 +1,7 @@
 package daemonservice
 
 import (
+	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
 	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
 	"aim-chat/go-backend/internal/domains/contracts"
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
@@ -13,23 +14,43 @@ func NewServiceForDaemon(wakuCfg waku.Config) (*Service, error) {
 }
 
 func NewServiceForDaemonWithDataDir(wakuCfg waku.Config, dataDir string) (*Service, error) {
+	return NewServiceForDaemonWithOptions(wakuCfg, dataDir, contracts.ServiceOptions{})
+}
+
+// NewServiceForDaemonWithOptions builds a daemon service like
+// NewServiceForDaemonWithDataDir. Stores always come from dataDir; the
+// logger, id generator and clock are taken from opts when set.
+func NewServiceForDaemonWithOptions(wakuCfg waku.Config, dataDir string, opts contracts.ServiceOptions) (*Service, error) {
 	resolvedDir, secret, bundle, err := daemoncomposition.ResolveStorage(dataDir)
 	if err != nil {
 		return nil, err
 	}
-	return newServiceForDaemonWithBundle(wakuCfg, bundle, secret, resolvedDir)
+	return newServiceForDaemonWithBundle(wakuCfg, bundle, secret, resolvedDir, opts)
 }
 
-func newServiceForDaemonWithBundle(wakuCfg waku.Config, bundle daemoncomposition.StorageBundle, secret, dataDir string) (*Service, error) {
+func newServiceForDaemonWithBundle(wakuCfg waku.Config, bundle daemoncomposition.StorageBundle, secret, dataDir string, opts contracts.ServiceOptions) (*Service, error) {
+	if opts.Logger == nil {
+		opts.Logger = runtimeapp.DefaultLogger()
+	}
+	grant, err := applyEnrollmentGrant(&wakuCfg, dataDir, opts.Logger)
+	if err != nil {
+		return nil, err
+	}
 	svc, err := newServiceWithOptions(wakuCfg, contracts.ServiceOptions{
 		SessionStore:    bundle.SessionStore,
 		MessageStore:    bundle.MessageStore,
 		AttachmentStore: bundle.AttachmentStore,
-		Logger:          runtimeapp.DefaultLogger(),
+		Logger:          opts.Logger,
+		GenerateID:      opts.GenerateID,
+		Now:             opts.Now,
 	})
 	if err != nil {
 		return nil, err
 	}
+	svc.enrollmentGrant = grant
+	if !grant.Allows(enrollmenttoken.CapabilityBlobProvider) {
+		svc.blobFlags.announceEnabled = false
+	}
 	svc.identityState.Configure(bundle.IdentityPath, secret)
 	if err := svc.identityState.Bootstrap(svc.identityManager); err != nil {
 		return nil, err
@@ -46,6 +67,9 @@ func newServiceForDaemonWithBundle(wakuCfg waku.Config, bundle daemoncomposition
 		return nil, err
 	}
 	svc.applyNodePoliciesFromSettings(settings)
+	svc.applyFirstContactPowFromSettings(settings)
+	svc.advertiseCapabilities()
+	svc.applyCoverTrafficFromSettings(settings)
 	svc.bootstrapStateStores(bundle, secret)
 	svc.storageSecret = secret
 	svc.dataDir = dataDir
@@ -56,5 +80,15 @@ func newServiceForDaemonWithBundle(wakuCfg waku.Config, bundle daemoncomposition
 	if err := svc.configureEnrollmentTokenFlow(); err != nil {
 		return nil, err
 	}
+	if err := svc.configureContentSafety(); err != nil {
+		return nil, err
+	}
+	if err := svc.configureAuditExport(); err != nil {
+		return nil, err
+	}
+	if err := svc.configurePlugins(); err != nil {
+		_ = svc.Close()
+		return nil, err
+	}
 	return svc, nil
 }
@@ -3,6 +3,7 @@ package daemonservice
 import (
 	"log/slog"
 	"sync"
+	"time"
 
 	"aim-chat/go-backend/internal/crypto"
 	"aim-chat/go-backend/internal/domains/contracts"
@@ -11,10 +12,13 @@ import (
 	inboxapp "aim-chat/go-backend/internal/domains/inbox"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
 	privacyapp "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/internal/platform/plugins"
 	"aim-chat/go-backend/internal/platform/privacylog"
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
 )
 
 func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (*Service, error) {
@@ -41,6 +45,8 @@ func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (
 		attachmentStore:   opts.AttachmentStore,
 		notifier:          runtimeapp.NewNotificationHub(2048),
 		logger:            opts.Logger,
+		generateID:        opts.GenerateID,
+		now:               opts.Now,
 		metrics:           runtimeapp.NewServiceMetricsState(),
 		runtime:           runtimeapp.NewServiceRuntime(),
 		requestRuntime:    requestRuntime,
@@ -48,10 +54,11 @@ func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (
 		identityState:     identityapp.NewStateStore(),
 		privacyState:      privacyStore,
 		requestInboxState: inboxapp.NewRequestStore(),
-		groupStateStore:   groupdomain.NewSnapshotStore(),
+		groupStateStore:   newGroupStateStoreFromEnv(),
 		groupAbuse:        groupdomain.NewAbuseProtectionFromEnv(),
 		startStopMu:       &sync.Mutex{},
 		metaHardening:     newOutboundMetadataHardeningFromEnv(),
+		receiptsInline:    resolveReceiptsInlineFromEnv(),
 		replicationMu:     &sync.RWMutex{},
 		replicationMode:   resolveBlobReplicationModeFromEnv(),
 		blobFlags:         resolveBlobFeatureFlagsFromEnv(),
@@ -77,18 +84,66 @@ func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (
 			defaultPreset.PublicEphemeralCacheMaxMB,
 			defaultPreset.PublicEphemeralCacheTTLMin,
 		),
-		degradeMu:     &sync.Mutex{},
-		degradeCfg:    resolvePublicServingDegradeConfigFromEnv(),
-		diagEventsMu:  &sync.Mutex{},
-		diagEvents:    make([]diagnosticEventEntry, 0, 128),
-		blobACLMu:     &sync.RWMutex{},
-		blobACL:       resolveBlobACLPolicyFromEnv(),
-		bindingStore:  newNodeBindingStore(),
-		bindingLinkMu: &sync.Mutex{},
-		bindingLinks:  map[string]pendingNodeBindingLink{},
-		blobProviders: newBlobProviderRegistry(),
-		wakuCfg:       &wakuCfg,
-		profileMu:     &sync.Mutex{},
+		degradeMu:          &sync.Mutex{},
+		degradeCfg:         resolvePublicServingDegradeConfigFromEnv(),
+		diagEventsMu:       &sync.Mutex{},
+		diagEvents:         make([]diagnosticEventEntry, 0, 128),
+		securityAlertsMu:   &sync.Mutex{},
+		securityAlerts:     map[string][]models.SecurityAlert{},
+		inboundLimits:      resolveInboundLimitsConfigFromEnv(),
+		inboundViolations:  newInboundViolationTracker(),
+		deferredDecryption: newDeferredDecryptionQueue(),
+		blobACLMu:          &sync.RWMutex{},
+		blobACL:            resolveBlobACLPolicyFromEnv(),
+		bindingStore:       newNodeBindingStore(),
+		clientState:        newClientStateStoreFromEnv(),
+		annotations:        storage.NewMessageAnnotationStore(),
+		conversationSync:   storage.NewConversationSyncStore(),
+		groupAvatars:       storage.NewGroupAvatarStore(),
+		messageSeqs:        storage.NewMessageSequenceStore(),
+		snippets:           storage.NewSnippetStore(),
+		attachmentPolicies: storage.NewAttachmentPolicyStore(),
+		threadSubs:         storage.NewThreadSubscriptionStore(),
+		metricsHistory:     storage.NewMetricsHistoryStore(),
+		metricsSampler:     &metricsHistorySampler{},
+		contactSharing:     storage.NewContactSharingStore(),
+		auditLog:           storage.NewAuditLogStore(),
+		deadLetters:        storage.NewDeadLetterStore(),
+		retryPolicyMu:      &sync.RWMutex{},
+		retryPolicy:        messagingapp.DefaultRetryPolicy(),
+		attestationSources: newTrustBundleAttestationSources(wakuCfg),
+		bindingLinkMu:      &sync.Mutex{},
+		bindingLinks:       map[string]pendingNodeBindingLink{},
+		emergencyMu:        &sync.Mutex{},
+		emergencyConfirms:  map[string]pendingEmergencyBroadcast{},
+		stateHashMu:        &sync.Mutex{},
+		stateDivergence:    map[string]models.StateDivergence{},
+		exportMu:           &sync.Mutex{},
+		exportTokens:       map[string]pendingConversationExport{},
+		peerExportConsents: map[string]models.ExportConsent{},
+		blobProviders:      newBlobProviderRegistry(),
+		blobAnnounce:       newBlobAnnounceSchedule(blobAnnounceConfigFromPreset(defaultPreset)),
+		wakuCfg:            &wakuCfg,
+		profileMu:          &sync.Mutex{},
+		rotationMu:         &sync.Mutex{},
+		cardRefresh:        newContactCardRefreshState(),
+		historyBackfill:    newHistoryBackfillState(),
+		peerCapabilities:   newCapabilityCache(),
+		storageScrub:       newStorageScrubState(time.Now()),
+		pairTopics:         newPairTopicState(),
+		coverTraffic:       newCoverTrafficState(),
+		dailySummary:       newDailySummaryState(),
+		thumbnails:         newAttachmentThumbnailCache(),
+		downloads:          newAttachmentDownloads(),
+		contentSafety:      contentsafety.NewChecker(""),
+		plugins:            &plugins.Host{},
+		outboundMu:         &sync.Mutex{},
+		outboundInFlight:   map[string]struct{}{},
+		paymentMu:          &sync.RWMutex{},
+		expiryNoticeMu:     &sync.Mutex{},
+		expiryNotified:     map[string]time.Time{},
+		flushMu:            &sync.Mutex{},
+		lastFlushAt:        map[string]time.Time{},
 	}
 	svc.configurePublicServingLimits(defaultPreset)
 
@@ -100,6 +155,7 @@ func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (
 		svc.attachmentStore,
 		svc.logger,
 	)
+	svc.configureIdentityCore()
 	svc.privacyCore = privacyapp.NewService(privacyStore, blocklistStore, svc.recordError)
 	svc.messagingCore = messagingapp.NewService(buildMessagingDeps(svc))
 	svc.inboundMessagingCore = messagingapp.NewInboundService(buildInboundMessagingDeps(svc))
@@ -108,6 +164,13 @@ func newServiceWithOptions(wakuCfg waku.Config, opts contracts.ServiceOptions) (
 	return svc, nil
 }
 
+// configureIdentityCore applies runtime policies to a freshly built identity
+// core; it runs on startup and after every account switch.
+func (s *Service) configureIdentityCore() {
+	s.identityCore.SetAttachmentMimePolicy(resolveAttachmentMimePolicyFromEnv())
+	s.identityCore.SetClientStateStore(s.clientState)
+}
+
 func ensureServiceOptions(opts contracts.ServiceOptions) (contracts.ServiceOptions, error) {
 	var err error
 	if opts.SessionStore == nil {
@@ -120,6 +183,12 @@ func ensureServiceOptions(opts contracts.ServiceOptions) (contracts.ServiceOptio
 		opts.Logger = runtimeapp.DefaultLogger()
 	}
 	opts.Logger = slog.New(privacylog.WrapHandler(opts.Logger.Handler()))
+	if opts.GenerateID == nil {
+		opts.GenerateID = runtimeapp.GeneratePrefixedID
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
 	if opts.AttachmentStore == nil {
 		opts.AttachmentStore, err = storage.NewAttachmentStore("")
 		if err != nil {
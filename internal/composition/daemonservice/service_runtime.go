@@ -3,6 +3,7 @@ package daemonservice
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"aim-chat/go-backend/internal/bootstrap/bootstrapmanager"
@@ -11,6 +12,7 @@ import (
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/events"
 	"aim-chat/go-backend/pkg/models"
 )
 
@@ -26,8 +28,10 @@ func (s *Service) StartNetworking(ctx context.Context) error {
 		s.recordError(contracts.ErrorCategoryNetwork, err)
 		return err
 	}
+	s.recordBootstrapLatencies()
 	localIdentity := s.identityManager.GetIdentity()
 	s.wakuNode.SetIdentity(localIdentity.ID)
+	s.refreshPairTopics(time.Now())
 	if err := s.wakuNode.SubscribePrivate(s.handleIncomingPrivateMessage); err != nil {
 		stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		_ = s.wakuNode.Stop(stopCtx)
@@ -35,7 +39,9 @@ func (s *Service) StartNetworking(ctx context.Context) error {
 		s.recordError(contracts.ErrorCategoryNetwork, err)
 		return err
 	}
+	s.subscribeReceiptChannel()
 	s.syncMissedInboundMessages(localIdentity.ID)
+	s.syncMissedReceipts(localIdentity.ID)
 	s.announceAllLocalBlobProviders()
 	networkCtx, networkCancel := context.WithCancel(ctx)
 	s.recoverPendingOnStartup(networkCtx)
@@ -55,6 +61,15 @@ func (s *Service) StartNetworking(ctx context.Context) error {
 	return nil
 }
 
+// Close releases what the service keeps across networking restarts, stops
+// the plugin annotation worker and flushes the audit export queue.
+// StopNetworking only pauses the service; Close is for the final shutdown,
+// after the last StopNetworking.
+func (s *Service) Close() error {
+	s.plugins.Close()
+	return s.auditExport.Close()
+}
+
 func (s *Service) syncMissedInboundMessages(identityID string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -86,6 +101,7 @@ func (s *Service) StopNetworking(ctx context.Context) error {
 	if networkCancel != nil {
 		networkCancel()
 	}
+	s.recordBootstrapLatencies()
 	if err := s.wakuNode.Stop(ctx); err != nil {
 		s.recordError(contracts.ErrorCategoryNetwork, err)
 		return err
@@ -114,21 +130,40 @@ func (s *Service) runRetryLoop(ctx context.Context) {
 				lag = 0
 			}
 			lastTick = now
-			s.notifyNetworkStatus(false)
-			s.enforceRetentionPolicies(now)
-			s.purgePublicEphemeralCache(now)
-			s.evaluatePublicServingAutodegrade(now, lag)
-			pending := s.messageStore.DuePending(now)
-			s.processPendingBatch(ctx, pending, s.handleRetryPublishError)
+			s.runRetryTick(ctx, now, lag)
 		}
 	}
 }
 
+// runRetryTick is one pass of the retry loop. lag is how late the tick fired,
+// which hints at an overloaded host.
+func (s *Service) runRetryTick(ctx context.Context, now time.Time, lag time.Duration) {
+	s.notifyNetworkStatus(false)
+	s.enforceRetentionPolicies(now)
+	s.scheduleStorageScrub(now)
+	s.purgePublicEphemeralCache(now)
+	s.drainBlobAnnouncements(now)
+	s.drainDeferredDecryption(now)
+	s.evaluatePublicServingAutodegrade(now, lag)
+	s.refreshContactCards(ctx, now)
+	s.requestHistoryBackfill(ctx, now)
+	s.refreshPairTopics(now)
+	s.sendCoverTraffic(ctx, now)
+	s.publishDailySummary(now)
+	s.sampleMetricsHistory(now)
+	pending := s.messageStore.DuePending(now)
+	s.processPendingBatch(ctx, pending, s.handleRetryPublishError)
+}
+
 func (s *Service) notifyNetworkStatus(force bool) {
 	current := s.GetNetworkStatus()
+	previous, known := s.runtime.LastNetworkStatus()
 	shouldNotify := s.runtime.UpdateLastNetworkStatus(current, force)
+	if known && previous.PeerCount == 0 && current.PeerCount > 0 {
+		s.notePeersReconnected()
+	}
 	if shouldNotify {
-		s.notify("notify.network", current)
+		s.notify(events.MethodNetworkChanged, events.NetworkChanged{NetworkStatus: current})
 	}
 }
 
@@ -162,6 +197,7 @@ func (s *Service) startBootstrapRefreshLoop(ctx context.Context) {
 		if applier, ok := s.wakuNode.(interface{ ApplyBootstrapConfig(waku.Config) }); ok {
 			applier.ApplyBootstrapConfig(cfg)
 		}
+		s.annotateManifestExpiry()
 	})
 	refreshCtx, cancel := context.WithCancel(ctx)
 	s.bootstrapCancel = cancel
@@ -172,6 +208,23 @@ func (s *Service) startBootstrapRefreshLoop(ctx context.Context) {
 	}()
 }
 
+// FetchManifestNow downloads, verifies and applies the network manifest
+// without waiting for the next scheduled refresh.
+func (s *Service) FetchManifestNow(ctx context.Context) (models.ManifestFetchResult, error) {
+	result, err := s.bootstrapRefresher.RefreshNow(ctx)
+	if err != nil {
+		return models.ManifestFetchResult{}, err
+	}
+	return models.ManifestFetchResult{
+		Manifest:        result.Manifest,
+		TrustBundle:     result.TrustBundle,
+		Attempts:        result.Attempts,
+		FetchedAt:       result.FetchedAt,
+		Source:          result.Source,
+		ManifestVersion: result.ManifestVersion,
+	}, nil
+}
+
 func (s *Service) stopBootstrapRefreshLoop() {
 	cancel := s.bootstrapCancel
 	s.bootstrapCancel = nil
@@ -191,14 +244,11 @@ func (s *Service) recoverPendingOnStartup(ctx context.Context) {
 }
 
 func (s *Service) publishSignedWireWithContext(ctx context.Context, messageID, recipient string, wire contracts.WirePayload) error {
-	hardenedWire, delay, err := s.metaHardening.harden(wire)
+	wire, err := s.attachFirstContactPow(ctx, messageID, recipient, wire)
 	if err != nil {
-		return contracts.WrapCategorizedError(contracts.ErrorCategoryAPI, err)
-	}
-	if err := waitWithContext(ctx, delay); err != nil {
-		return contracts.WrapCategorizedError(contracts.ErrorCategoryNetwork, err)
+		return err
 	}
-	wmsg, err := messagingapp.ComposeSignedPrivateMessage(messageID, recipient, hardenedWire, s.identityManager)
+	wmsg, err := s.composeHardenedPrivateMessage(ctx, messageID, recipient, wire)
 	if err != nil {
 		return err
 	}
@@ -208,7 +258,24 @@ func (s *Service) publishSignedWireWithContext(ctx context.Context, messageID, r
 	return nil
 }
 
+func (s *Service) composeHardenedPrivateMessage(ctx context.Context, messageID, recipient string, wire contracts.WirePayload) (waku.PrivateMessage, error) {
+	if wire.Caps == nil {
+		wire.Caps = s.localCapabilities()
+	}
+	hardenedWire, delay, err := s.metaHardening.harden(wire)
+	if err != nil {
+		return waku.PrivateMessage{}, contracts.WrapCategorizedError(contracts.ErrorCategoryAPI, err)
+	}
+	if err := waitWithContext(ctx, delay); err != nil {
+		return waku.PrivateMessage{}, contracts.WrapCategorizedError(contracts.ErrorCategoryNetwork, err)
+	}
+	return messagingapp.ComposeSignedPrivateMessage(messageID, recipient, hardenedWire, s.identityManager)
+}
+
 func (s *Service) markMessageAsSent(messageID string) {
+	if msg, ok := s.messageStore.GetMessage(messageID); ok && (msg.Status == "" || msg.Status == "pending") {
+		s.metrics.RecordDeliverySent(msg.ContactID)
+	}
 	s.updateMessageStatusAndNotify(messageID, "sent")
 	if err := s.messageStore.RemovePending(messageID); err != nil {
 		s.recordError(contracts.ErrorCategoryStorage, err)
@@ -236,6 +303,21 @@ func (s *Service) networkContext(category string) (context.Context, error) {
 	return nil, contracts.WrapCategorizedError(category, err)
 }
 
+// callerNetworkContext joins a caller's context with the networking lifetime,
+// so the result ends when either the caller gives up or networking stops.
+func (s *Service) callerNetworkContext(ctx context.Context, category string) (context.Context, context.CancelFunc, error) {
+	netCtx, err := s.networkContext(category)
+	if err != nil {
+		return nil, nil, err
+	}
+	joined, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(netCtx, cancel)
+	return joined, func() {
+		stop()
+		cancel()
+	}, nil
+}
+
 func (s *Service) GetNetworkStatus() models.NetworkStatus {
 	status := s.wakuNode.Status()
 	s.presetMu.RLock()
@@ -246,6 +328,7 @@ func (s *Service) GetNetworkStatus() models.NetworkStatus {
 		peerTarget = s.wakuCfg.MinPeers
 	}
 	healthSummary, actionHint := describeNetworkStatus(status.State, status.PeerCount, peerTarget, status.BootstrapSource)
+	clock := s.clockEstimate()
 	return models.NetworkStatus{
 		Status:                   status.State,
 		PeerCount:                status.PeerCount,
@@ -262,6 +345,9 @@ func (s *Service) GetNetworkStatus() models.NetworkStatus {
 		BootstrapSource:          status.BootstrapSource,
 		BootstrapManifestVersion: status.BootstrapManifestVersion,
 		BootstrapManifestKeyID:   status.BootstrapManifestKeyID,
+		ClockOffsetMS:            clock.Offset.Milliseconds(),
+		ClockPeers:               clock.Peers,
+		ClockConfidence:          clock.Confidence,
 	}
 }
 
@@ -284,6 +370,33 @@ func describeNetworkStatus(state string, peerCount int, peerTarget int, bootstra
 	return "Connection is stable.", "No action needed."
 }
 
+// GetStoreNodeStatus reports the community store mode of the transport.
+func (s *Service) GetStoreNodeStatus() (models.StoreNodeStatus, error) {
+	reporter, ok := s.wakuNode.(interface {
+		StoreNodeStatus() waku.StoreNodeStatus
+	})
+	if !ok {
+		return models.StoreNodeStatus{}, errors.New("store node status is not supported")
+	}
+	status := reporter.StoreNodeStatus()
+	return models.StoreNodeStatus{
+		Enabled:             status.Enabled,
+		Transport:           status.Transport,
+		Topics:              status.Topics,
+		StoredMessages:      status.StoredMessages,
+		StoredBytes:         status.StoredBytes,
+		QuotaBytes:          status.QuotaBytes,
+		RetentionSeconds:    int64(status.Retention / time.Second),
+		QueriesPerMinute:    status.QueriesPerMinute,
+		AcceptedTotal:       status.AcceptedTotal,
+		RejectedTopic:       status.RejectedTopic,
+		RejectedQuota:       status.RejectedQuota,
+		RejectedSenderQuota: status.RejectedSenderQuota,
+		QueriesServed:       status.QueriesServed,
+		QueriesRateLimited:  status.QueriesRateLimited,
+	}, nil
+}
+
 func (s *Service) ListenAddresses() []string {
 	return s.wakuNode.ListenAddresses()
 }
@@ -293,9 +406,31 @@ func (s *Service) processPendingBatch(
 	pending []storage.PendingMessage,
 	onPublishError func(storage.PendingMessage, error),
 ) {
+	for _, p := range pending {
+		s.processPendingMessage(ctx, p, onPublishError)
+	}
+}
+
+// processPendingMessage publishes one queued message while holding its
+// in-flight mark, so cancellation cannot interleave with the retry outcome.
+// Batches are snapshots: a message another flush already settled is no
+// longer queued and is skipped.
+func (s *Service) processPendingMessage(
+	ctx context.Context,
+	p storage.PendingMessage,
+	onPublishError func(storage.PendingMessage, error),
+) {
+	if !s.beginOutboundPublish(p.Message.ID) {
+		return
+	}
+	defer s.endOutboundPublish(p.Message.ID)
+	p, queued := s.messageStore.GetPending(p.Message.ID)
+	if !queued {
+		return
+	}
 	messagingapp.ProcessPendingMessages(
 		ctx,
-		pending,
+		[]storage.PendingMessage{p},
 		func(msg models.Message) (contracts.WirePayload, error) {
 			return s.buildStoredMessageWire(msg)
 		},
@@ -313,9 +448,13 @@ func (s *Service) handleRetryPublishError(p storage.PendingMessage, err error) {
 	s.recordError(messagingapp.ErrorCategory(err), err)
 	nextCount := p.RetryCount + 1
 	correlationID := messageCorrelationID(p.Message.ID, p.Message.ContactID)
-	if nextCount > 8 {
-		s.logWarn("message.retry_limit", correlationID, "message retry limit reached", "message_id", p.Message.ID, "contact_id", p.Message.ContactID, "retry_count", nextCount)
+	if policy := s.GetRetryPolicy(); nextCount > policy.MaxAttempts {
+		s.logWarn("message.retry_limit", correlationID, "message retry limit reached", "message_id", p.Message.ID, "contact_id", p.Message.ContactID, "retry_count", nextCount, "dead_letter", policy.DeadLetter)
 		s.updateMessageStatusAndNotify(p.Message.ID, "failed")
+		s.metrics.RecordDeliveryFailed(p.Message.ContactID)
+		if policy.DeadLetter {
+			s.deadLetterMessage(p, nextCount, err)
+		}
 		if remErr := s.messageStore.RemovePending(p.Message.ID); remErr != nil {
 			s.recordError(contracts.ErrorCategoryStorage, remErr)
 		}
@@ -323,7 +462,7 @@ func (s *Service) handleRetryPublishError(p storage.PendingMessage, err error) {
 	}
 	s.recordRetryAttempt()
 	s.logWarn("message.retry_scheduled", correlationID, "message retry scheduled", "message_id", p.Message.ID, "contact_id", p.Message.ContactID, "retry_count", nextCount)
-	if perr := s.messageStore.AddOrUpdatePending(p.Message, nextCount, messagingapp.NextRetryTime(nextCount), err.Error()); perr != nil {
+	if perr := s.messageStore.AddOrUpdatePending(p.Message, nextCount, s.nextRetryTime(nextCount), err.Error()); perr != nil {
 		s.recordError(contracts.ErrorCategoryStorage, perr)
 	}
 }
@@ -336,8 +475,46 @@ func (s *Service) SubscribeNotifications(cursor int64) ([]contracts.Notification
 	return s.notifier.Subscribe(cursor)
 }
 
+// SubscribeEvents is SubscribeNotifications with typed payloads for Go
+// embedders. Notifications without a typed payload arrive as events.Raw.
+func (s *Service) SubscribeEvents(cursor int64) ([]events.Notification, <-chan events.Notification, func()) {
+	replay, source, cancelSource := s.notifier.Subscribe(cursor)
+	typedReplay := make([]events.Notification, 0, len(replay))
+	for _, evt := range replay {
+		typedReplay = append(typedReplay, typedNotification(evt))
+	}
+	out := make(chan events.Notification, cap(source))
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		for evt := range source {
+			select {
+			case out <- typedNotification(evt):
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			cancelSource()
+		})
+	}
+	return typedReplay, out, cancel
+}
+
+func typedNotification(evt contracts.NotificationEvent) events.Notification {
+	return events.Notification{
+		Seq:       evt.Seq,
+		Timestamp: evt.Timestamp,
+		Event:     events.FromPayload(evt.Method, evt.Payload),
+	}
+}
+
 func (s *Service) notify(method string, payload any) {
-	s.notifier.Publish(method, payload)
+	s.notifier.Publish(method, s.withNotificationHints(method, payload))
 }
 
 func (s *Service) notifyMessageStatus(messageID, status string) {
@@ -353,15 +530,29 @@ func (s *Service) notifyMessageStatus(messageID, status string) {
 }
 
 func (s *Service) notifySecurityAlert(kind, contactID, message string) {
-	s.notify("notify.security.alert", map[string]any{
-		"kind":       kind,
-		"contact_id": contactID,
-		"message":    message,
-	})
+	s.notifySecurityAlertWithArgs(kind, contactID, message, nil)
+}
+
+// notifySecurityAlertWithArgs publishes an alert whose English message can be
+// re-rendered per subscriber locale from kind and messageArgs.
+func (s *Service) notifySecurityAlertWithArgs(kind, contactID, message string, messageArgs map[string]string) {
+	s.recordSecurityAlert(kind, contactID, message)
+	alert := events.SecurityAlert{
+		ContactID:   contactID,
+		Kind:        kind,
+		Message:     message,
+		MessageArgs: messageArgs,
+	}
+	s.exportSecurityAlert(alert)
+	s.notify(events.MethodSecurityAlert, alert)
 }
 
 func (s *Service) updateMessageStatusAndNotify(messageID, status string) bool {
-	if _, err := s.messageStore.UpdateMessageStatus(messageID, status); err != nil {
+	return s.updateMessageStatusAtAndNotify(messageID, status, time.Now())
+}
+
+func (s *Service) updateMessageStatusAtAndNotify(messageID, status string, at time.Time) bool {
+	if _, err := s.messageStore.UpdateMessageStatusAt(messageID, status, at); err != nil {
 		s.recordError(contracts.ErrorCategoryStorage, err)
 		return false
 	}
@@ -389,18 +580,28 @@ func (s *Service) GetMetrics() models.MetricsSnapshot {
 		PendingQueueSize:       s.messageStore.PendingCount(),
 		ErrorCounters:          counters,
 		GroupAggregates:        groupAggregates,
+		InboundLimitViolations: s.metrics.InboundLimitViolations(),
 		NetworkMetrics:         s.wakuNode.NetworkMetrics(),
 		DiskUsageByClass:       usageByClass,
 		GCEvictionCountByClass: gcEvictionByClass,
 		BlobFetchStats:         blobStats,
 		StorageGuardrails:      guardrails,
+		StorageIntegrity:       s.metrics.StorageIntegrity(),
+		BlobAnnouncements:      s.blobAnnouncementStats(),
 		OperationStats:         opStats,
 		RetryAttemptsTotal:     retries,
 		LastUpdatedAt:          lastAt,
 		NotificationBacklog:    s.notifier.BacklogSize(),
+		DeliveryLatency:        s.metrics.DeliveryLatencySummary(),
 	}
 }
 
+// OperationLatencyHistograms returns per-operation latency histograms for
+// the metrics exporter.
+func (s *Service) OperationLatencyHistograms() map[string]models.LatencyHistogram {
+	return s.metrics.OperationHistograms()
+}
+
 func (s *Service) recordError(category string, err error) {
 	s.recordErrorWithContext(category, err, "service.error", "n/a")
 }
@@ -1,9 +1,12 @@
 package daemonservice
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/pkg/models"
@@ -29,10 +32,13 @@ func TestHandleRetryPublishErrorStopsAfterRetryCap(t *testing.T) {
 	}
 
 	svc := &Service{
-		messageStore: store,
-		logger:       runtimeapp.DefaultLogger(),
-		metrics:      runtimeapp.NewServiceMetricsState(),
-		notifier:     runtimeapp.NewNotificationHub(32),
+		messageStore:  store,
+		logger:        runtimeapp.DefaultLogger(),
+		metrics:       runtimeapp.NewServiceMetricsState(),
+		notifier:      runtimeapp.NewNotificationHub(32),
+		deadLetters:   storage.NewDeadLetterStore(),
+		retryPolicyMu: &sync.RWMutex{},
+		retryPolicy:   messagingapp.DefaultRetryPolicy(),
 	}
 
 	svc.handleRetryPublishError(storage.PendingMessage{
@@ -52,6 +58,71 @@ func TestHandleRetryPublishErrorStopsAfterRetryCap(t *testing.T) {
 	if updated.Status != "failed" {
 		t.Fatalf("message status must be failed after retry cap, got=%q", updated.Status)
 	}
+	dead := svc.ListDeadLetters("", 0, 0)
+	if len(dead) != 1 || dead[0].Attempts != 9 || dead[0].LastError != "network" || dead[0].Message.Status != "failed" {
+		t.Fatalf("message must move to the dead-letter queue: %+v", dead)
+	}
+}
+
+func TestRetryPolicyDeadLetterRequeue(t *testing.T) {
+	t.Parallel()
+
+	store := storage.NewMessageStore()
+	msg := models.Message{
+		ID:               "msg-dead",
+		ContactID:        "aim1_contact",
+		Content:          []byte("payload"),
+		Timestamp:        time.Now().UTC(),
+		Direction:        "out",
+		Status:           "pending",
+		ConversationType: models.ConversationTypeDirect,
+	}
+	if err := store.SaveMessage(msg); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+	svc := &Service{
+		messageStore:     store,
+		logger:           runtimeapp.DefaultLogger(),
+		metrics:          runtimeapp.NewServiceMetricsState(),
+		notifier:         runtimeapp.NewNotificationHub(32),
+		deadLetters:      storage.NewDeadLetterStore(),
+		retryPolicyMu:    &sync.RWMutex{},
+		retryPolicy:      messagingapp.DefaultRetryPolicy(),
+		outboundMu:       &sync.Mutex{},
+		outboundInFlight: map[string]struct{}{},
+	}
+	if _, err := svc.SetRetryPolicy(models.RetryPolicy{BaseIntervalMS: 100, JitterRatio: 2, MaxAttempts: 1}); !errors.Is(err, messagingapp.ErrInvalidRetryPolicy) {
+		t.Fatalf("expected jitter above 1 to be rejected, got %v", err)
+	}
+	policy, err := svc.SetRetryPolicy(models.RetryPolicy{BaseIntervalMS: 100, MaxIntervalMS: 250, MaxAttempts: 2, DeadLetter: true})
+	if err != nil {
+		t.Fatalf("set retry policy: %v", err)
+	}
+	if backoff := messagingapp.RetryBackoff(policy, 3, 0); backoff != 250*time.Millisecond {
+		t.Fatalf("backoff must be capped at the max interval, got %v", backoff)
+	}
+
+	before := time.Now()
+	svc.handleRetryPublishError(storage.PendingMessage{Message: msg, RetryCount: 1}, assertErr("network"))
+	pending := store.PendingForContact(msg.ContactID)
+	if len(pending) != 1 || pending[0].NextRetry.After(before.Add(time.Second)) {
+		t.Fatalf("second attempt must be scheduled with the configured backoff: %+v", pending)
+	}
+	svc.handleRetryPublishError(pending[0], assertErr("network"))
+	if store.PendingCount() != 0 || len(svc.ListDeadLetters(msg.ContactID, 0, 0)) != 1 {
+		t.Fatal("message must be dead-lettered after the configured attempts")
+	}
+
+	if _, err := svc.RequeueDeadLetter("msg-other"); !errors.Is(err, errDeadLetterNotFound) {
+		t.Fatalf("expected unknown dead letter to be rejected, got %v", err)
+	}
+	requeued, err := svc.RequeueDeadLetter(msg.ID)
+	if err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	if requeued.Status != "pending" || store.PendingCount() != 1 || len(svc.ListDeadLetters("", 0, 0)) != 0 {
+		t.Fatalf("requeue must move the message back to the retry queue: %+v", requeued)
+	}
 }
 
 func assertErr(text string) error {
@@ -1,6 +1,7 @@
 package daemonservice
 
 import (
+	"context"
 	"crypto/ed25519"
 	"log/slog"
 	"sync"
@@ -14,8 +15,12 @@ import (
 	inboxapp "aim-chat/go-backend/internal/domains/inbox"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
 	privacyapp "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/platform/auditexport"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/internal/platform/plugins"
 	"aim-chat/go-backend/internal/platform/ratelimiter"
 	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
 	"aim-chat/go-backend/pkg/models"
 )
@@ -30,8 +35,20 @@ type groupCore interface {
 	ListGroups() ([]groupdomain.Group, error)
 	UpdateGroupTitle(groupID, title string) (groupdomain.Group, error)
 	UpdateGroupProfile(groupID, title, description, avatar string) (groupdomain.Group, error)
+	SetGroupRules(groupID, rules string) (groupdomain.Group, error)
+	AcknowledgeGroupRules(groupID string) (groupdomain.GroupMember, error)
+	SetChannelComments(groupID string, enabled bool) (groupdomain.Group, groupdomain.GroupEvent, error)
+	SetGroupThreadLocked(groupID, threadID string, locked bool) (groupdomain.Group, groupdomain.GroupEvent, error)
+	RemoveGroupMessage(groupID, messageID string) (groupdomain.GroupEvent, error)
+	MuteGroupMember(groupID, memberID string, until time.Time) (groupdomain.GroupMember, groupdomain.GroupEvent, error)
+	SetGroupPostingMode(groupID, mode string) (groupdomain.Group, groupdomain.GroupEvent, error)
+	SetGroupMemberPrivacy(groupID string, hidden bool) (groupdomain.Group, groupdomain.GroupEvent, error)
+	ResolveGroupPseudonym(groupID, pseudonym string) (groupdomain.GroupMember, error)
+	TransferGroupOwnership(groupID, newOwnerID string) (groupdomain.GroupMember, groupdomain.GroupEvent, error)
 	DeleteGroup(groupID string) (bool, error)
 	ListGroupMembers(groupID string) ([]groupdomain.GroupMember, error)
+	ListGroupHistory(groupID string, limit, offset int) ([]groupdomain.GroupEvent, error)
+	ListGroupActivity(groupID string, limit, offset int) ([]groupdomain.GroupActivity, error)
 	LeaveGroup(groupID string) (bool, error)
 	InviteToGroup(groupID, memberID string) (groupdomain.GroupMember, error)
 	AcceptGroupInvite(groupID string) (bool, error)
@@ -39,18 +56,23 @@ type groupCore interface {
 	RemoveGroupMember(groupID, memberID string) (bool, error)
 	PromoteGroupMember(groupID, memberID string) (groupdomain.GroupMember, error)
 	DemoteGroupMember(groupID, memberID string) (groupdomain.GroupMember, error)
-	SendGroupMessage(groupID, content string) (groupdomain.GroupMessageFanoutResult, error)
-	SendGroupMessageInThread(groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error)
+	SendGroupMessage(ctx context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error)
+	SendGroupMessageInThread(ctx context.Context, groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error)
+	CheckChannelEmergencyBroadcast(groupID string) error
+	SendChannelEmergencyBroadcast(ctx context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error)
 	ListGroupMessages(groupID string, limit, offset int) ([]models.Message, error)
+	ListGroupMessagesFiltered(groupID, filter string, limit, offset int) ([]models.Message, error)
 	ListGroupMessagesByThread(groupID, threadID string, limit, offset int) ([]models.Message, error)
 	GetGroupMessageStatus(groupID, messageID string) (models.MessageStatus, error)
+	GetGroupMessageReceipts(groupID, messageID string, includeMembers bool) (models.MessageStatus, error)
+	GetChannelStats(groupID string, limit int) (groupdomain.ChannelStats, error)
 	DeleteGroupMessage(groupID, messageID string) error
 }
 
 type inboxCore interface {
 	ListMessageRequests() ([]models.MessageRequest, error)
 	GetMessageRequest(senderID string) (models.MessageRequestThread, error)
-	AcceptMessageRequest(senderID string) (bool, error)
+	AcceptMessageRequest(ctx context.Context, senderID string) (bool, error)
 	DeclineMessageRequest(senderID string) (bool, error)
 	BlockSender(senderID string) (models.BlockSenderResult, error)
 }
@@ -63,6 +85,8 @@ type Service struct {
 	attachmentStore contracts.AttachmentRepository
 	notifier        *runtimeapp.NotificationHub
 	logger          *slog.Logger
+	generateID      func(prefix string) (string, error)
+	now             func() time.Time
 	*identityCore
 	*privacyCore
 	*messagingCore
@@ -76,10 +100,11 @@ type Service struct {
 	identityState      *identityapp.StateStore
 	privacyState       *privacyapp.SettingsStore
 	requestInboxState  *inboxapp.RequestStore
-	groupStateStore    *groupdomain.SnapshotStore
+	groupStateStore    groupStatePersistence
 	groupAbuse         *groupdomain.AbuseProtection
 	startStopMu        *sync.Mutex
 	metaHardening      *outboundMetadataHardening
+	receiptsInline     bool
 	replicationMu      *sync.RWMutex
 	replicationMode    blobReplicationMode
 	blobFlags          blobFeatureFlags
@@ -99,12 +124,42 @@ type Service struct {
 	degradeCfg         publicServingDegradeConfig
 	diagEventsMu       *sync.Mutex
 	diagEvents         []diagnosticEventEntry
+	securityAlertsMu   *sync.Mutex
+	securityAlerts     map[string][]models.SecurityAlert
+	inboundLimits      inboundLimitsConfig
+	inboundViolations  *inboundViolationTracker
+	deferredDecryption *deferredDecryptionQueue
 	blobACLMu          *sync.RWMutex
 	blobACL            blobACLPolicy
 	bindingStore       *nodeBindingStore
+	clientState        *storage.ClientStateStore
+	annotations        *storage.MessageAnnotationStore
+	conversationSync   *storage.ConversationSyncStore
+	groupAvatars       *storage.GroupAvatarStore
+	messageSeqs        *storage.MessageSequenceStore
+	snippets           *storage.SnippetStore
+	attachmentPolicies *storage.AttachmentPolicyStore
+	threadSubs         *storage.ThreadSubscriptionStore
+	metricsHistory     *storage.MetricsHistoryStore
+	metricsSampler     *metricsHistorySampler
+	contactSharing     *storage.ContactSharingStore
+	auditLog           *storage.AuditLogStore
+	auditExport        *auditexport.Exporter
+	deadLetters        *storage.DeadLetterStore
+	retryPolicyMu      *sync.RWMutex
+	retryPolicy        models.RetryPolicy
+	attestationSources identityapp.AttestationSources
 	bindingLinkMu      *sync.Mutex
 	bindingLinks       map[string]pendingNodeBindingLink
+	emergencyMu        *sync.Mutex
+	emergencyConfirms  map[string]pendingEmergencyBroadcast
+	stateHashMu        *sync.Mutex
+	stateDivergence    map[string]models.StateDivergence
+	exportMu           *sync.Mutex
+	exportTokens       map[string]pendingConversationExport
+	peerExportConsents map[string]models.ExportConsent
 	blobProviders      *blobProviderRegistry
+	blobAnnounce       *blobAnnounceSchedule
 	wakuCfg            *waku.Config
 	bootstrapManager   *bootstrapmanager.Manager
 	bootstrapRefresher *bootstrapmanager.Refresher
@@ -114,8 +169,31 @@ type Service struct {
 	storageSecret      string
 	currentProfileID   string
 	profileMu          *sync.Mutex
+	rotationMu         *sync.Mutex
+	rotation           models.StorageKeyRotationStatus
 	enrollmentStore    *enrollmenttoken.FileStore
 	enrollmentKeys     map[string]ed25519.PublicKey
+	enrollmentGrant    enrollmenttoken.Grant
+	cardRefresh        *contactCardRefreshState
+	historyBackfill    *historyBackfillState
+	peerCapabilities   *capabilityCache
+	storageScrub       *storageScrubState
+	pairTopics         *pairTopicState
+	coverTraffic       *coverTrafficState
+	dailySummary       *dailySummaryState
+	thumbnails         *attachmentThumbnailCache
+	downloads          *attachmentDownloads
+	contentSafety      *contentsafety.Checker
+	safetyFeedKeys     map[string]ed25519.PublicKey
+	plugins            *plugins.Host
+	outboundMu         *sync.Mutex
+	outboundInFlight   map[string]struct{}
+	paymentMu          *sync.RWMutex
+	paymentVerifier    contracts.PaymentVerifier
+	expiryNoticeMu     *sync.Mutex
+	expiryNotified     map[string]time.Time
+	flushMu            *sync.Mutex
+	lastFlushAt        map[string]time.Time
 }
 
 type publicServingDegradeConfig struct {
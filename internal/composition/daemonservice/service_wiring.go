@@ -1,12 +1,12 @@
 package daemonservice
 
 import (
+	"context"
 	"errors"
 
 	"aim-chat/go-backend/internal/domains/contracts"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
-	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
 	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
 	"aim-chat/go-backend/pkg/models"
@@ -17,7 +17,8 @@ func buildMessagingDeps(svc *Service) messagingapp.ServiceDeps {
 		Identity:       svc.identityManager,
 		Sessions:       svc.sessionManager,
 		Messages:       svc.messageStore,
-		GenerateID:     runtimeapp.GeneratePrefixedID,
+		GenerateID:     svc.generateID,
+		Now:            svc.now,
 		TrackOperation: svc.trackOperation,
 		PublishQueued:  svc.publishQueuedMessage,
 		ApplyAutoRead:  svc.applyAutoRead,
@@ -31,11 +32,21 @@ func buildMessagingDeps(svc *Service) messagingapp.ServiceDeps {
 		Notify:              svc.notify,
 		RecordError:         svc.recordError,
 		IsMessageIDConflict: func(err error) bool { return errors.Is(err, storage.ErrMessageIDConflict) },
+		ResolveAttachments:  svc.resolveMessageAttachments,
+		ResolveContactID:    svc.identityCore.ResolveContactID,
+		NextSequence:        svc.messageSeqs.NextOutbound,
+		SessionEstablished:  svc.retryDeferredDecryption,
+		IsBlocked:           func(contactID string) bool { return svc.privacyCore.IsBlockedSender(contactID) },
+		HasMessageRequest:   svc.hasMessageRequest,
+		ProcessOutboundDraft: func(ctx context.Context, contactID, threadID, content string) (string, error) {
+			return svc.processOutboundDraft(ctx, contactID, models.ConversationTypeDirect, threadID, content)
+		},
 	}
 }
 
 func buildInboundMessagingDeps(svc *Service) messagingapp.InboundServiceDeps {
 	return messagingapp.InboundServiceDeps{
+		IgnoreInboundSender: svc.ignoreInboundSender,
 		EvaluateInboundPolicy: func(senderID string) messagingapp.InboundPolicyDecision {
 			decision := svc.evaluateInboundPolicy(senderID)
 			switch decision.Action {
@@ -99,11 +110,27 @@ func buildInboundMessagingDeps(svc *Service) messagingapp.InboundServiceDeps {
 		HandleInboundGroupMessage: svc.handleInboundGroupMessage,
 		HandleInboundGroupEvent:   svc.handleInboundGroupEvent,
 		ApplyInboundReceiptStatus: svc.applyInboundReceiptStatus,
+		HandleContactCardWire:     svc.handleContactCardWire,
+		HandleDeviceSyncWire:      svc.handleDeviceSyncWire,
+		HandleHistoryBackfillWire: svc.handleHistoryBackfillWire,
+		HandleStateHashWire:       svc.handleStateHashWire,
+		HandleExportConsentWire:   svc.handleExportConsentWire,
+		HandleMessagePinWire:      svc.handleMessagePinWire,
+		ObserveInboundSeq:         svc.observeInboundSeq,
+		ObserveWireCapabilities:   svc.observeWireCapabilities,
+		VerifyFirstContactPow:     svc.verifyFirstContactPow,
+		VerifyFirstContactPayment: svc.verifyFirstContactPayment,
+		ResolveInboundTip:         svc.resolveInboundTip,
+		FilterInboundAttachments:  svc.filterInboundAttachments,
 		PersistInboundMessage:     svc.persistInboundMessage,
 		PersistInboundRequest:     svc.persistInboundRequest,
 		SendReceiptDelivered: func(senderID, messageID string) error {
 			return svc.sendReceipt(senderID, messageID, "delivered")
 		},
-		RecordError: svc.recordError,
+		RecordError:            svc.recordError,
+		InboundLimits:          svc.currentInboundLimits,
+		ReportLimitViolation:   svc.reportInboundLimitViolation,
+		NoteContactOnline:      svc.noteContactOnline,
+		DeferInboundDecryption: svc.deferInboundDecryption,
 	}
 }
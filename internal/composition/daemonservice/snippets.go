@@ -0,0 +1,120 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/storage"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// ListSnippets returns the canned responses visible to the caller. With a
+// group id only snippets usable in that group are returned.
+func (s *Service) ListSnippets(namespace, groupID string) []models.Snippet {
+	groupID = strings.TrimSpace(groupID)
+	all := s.snippets.List(namespace)
+	if groupID == "" {
+		return all
+	}
+	out := make([]models.Snippet, 0, len(all))
+	for _, snippet := range all {
+		if snippet.GroupID == "" || snippet.GroupID == groupID {
+			out = append(out, snippet)
+		}
+	}
+	return out
+}
+
+// SaveSnippet creates a snippet, or replaces the one with the given id.
+func (s *Service) SaveSnippet(namespace string, snippet models.Snippet) (models.Snippet, error) {
+	snippet.Name = strings.TrimSpace(snippet.Name)
+	snippet.GroupID = strings.TrimSpace(snippet.GroupID)
+	if err := messagingapp.ValidateSnippet(snippet.Name, snippet.Body); err != nil {
+		return models.Snippet{}, err
+	}
+	if snippet.GroupID != "" {
+		if _, err := s.groupCore.GetGroup(snippet.GroupID); err != nil {
+			return models.Snippet{}, err
+		}
+	}
+	if strings.TrimSpace(snippet.ID) == "" {
+		id, err := s.generateID("snip")
+		if err != nil {
+			return models.Snippet{}, err
+		}
+		snippet.ID = id
+	}
+	snippet.Placeholders = messagingapp.SnippetPlaceholders(snippet.Body)
+	snippet.UpdatedAt = time.Now().UTC()
+	saved, err := s.snippets.Put(namespace, snippet)
+	if err != nil && !errors.Is(err, storage.ErrSnippetNotFound) && !errors.Is(err, storage.ErrSnippetQuotaExceeded) {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+	}
+	return saved, err
+}
+
+func (s *Service) DeleteSnippet(namespace, snippetID string) error {
+	removed, err := s.snippets.Remove(namespace, snippetID)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return err
+	}
+	if !removed {
+		return storage.ErrSnippetNotFound
+	}
+	return nil
+}
+
+// SendTemplate expands a snippet and sends it to one direct or group
+// conversation. Besides the caller's values, contact_name and group_title
+// are filled in from local state when the caller leaves them out.
+func (s *Service) SendTemplate(ctx context.Context, namespace string, req models.TemplateSendRequest) (any, error) {
+	contactID := strings.TrimSpace(req.ContactID)
+	groupID := strings.TrimSpace(req.GroupID)
+	if (contactID == "") == (groupID == "") {
+		return nil, errors.New("exactly one of contact id and group id is required")
+	}
+	snippet, ok := s.snippets.Get(namespace, req.SnippetID)
+	if !ok {
+		return nil, storage.ErrSnippetNotFound
+	}
+	if snippet.GroupID != "" && snippet.GroupID != groupID {
+		return nil, errors.New("snippet is limited to another group")
+	}
+	values := make(map[string]string, len(req.Values)+1)
+	for name, value := range req.Values {
+		values[name] = value
+	}
+	if groupID != "" {
+		group, err := s.groupCore.GetGroup(groupID)
+		if err != nil {
+			return nil, err
+		}
+		if _, set := values["group_title"]; !set {
+			values["group_title"] = group.Title
+		}
+	} else if _, set := values["contact_name"]; !set {
+		for _, contact := range s.identityManager.Contacts() {
+			if contact.ID == contactID {
+				values["contact_name"] = contact.DisplayName
+				break
+			}
+		}
+	}
+	content, err := messagingapp.ExpandSnippet(snippet.Body, values)
+	if err != nil {
+		return nil, err
+	}
+	if groupID != "" {
+		return s.SendGroupMessage(ctx, groupID, content)
+	}
+	messageID, err := s.SendMessage(ctx, contactID, content)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"message_id": messageID, "content": content}, nil
+}
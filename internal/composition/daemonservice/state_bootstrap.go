@@ -34,4 +34,66 @@ func (s *Service) bootstrapStateStores(bundle daemoncomposition.StorageBundle, s
 	if err := s.bindingStore.Bootstrap(); err != nil {
 		s.logger.Warn("node binding bootstrap failed, using empty state", "error", err.Error())
 	}
+
+	s.clientState.Configure(bundle.ClientStatePath, secret)
+	if err := s.clientState.Bootstrap(); err != nil {
+		s.logger.Warn("client state bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.annotations.Configure(bundle.AnnotationsPath, secret)
+	if err := s.annotations.Bootstrap(); err != nil {
+		s.logger.Warn("message annotations bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.conversationSync.Configure(bundle.ConversationSyncPath, secret)
+	if err := s.conversationSync.Bootstrap(); err != nil {
+		s.logger.Warn("conversation sync bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.groupAvatars.Configure(bundle.GroupAvatarsPath, secret)
+	if err := s.groupAvatars.Bootstrap(); err != nil {
+		s.logger.Warn("group avatars bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.messageSeqs.Configure(bundle.MessageSequencesPath, secret)
+	if err := s.messageSeqs.Bootstrap(); err != nil {
+		s.logger.Warn("message sequences bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.snippets.Configure(bundle.SnippetsPath, secret)
+	if err := s.snippets.Bootstrap(); err != nil {
+		s.logger.Warn("snippets bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.attachmentPolicies.Configure(bundle.AttachmentPolicyPath, secret)
+	if err := s.attachmentPolicies.Bootstrap(); err != nil {
+		s.logger.Warn("attachment policies bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.threadSubs.Configure(bundle.ThreadSubsPath, secret)
+	if err := s.threadSubs.Bootstrap(); err != nil {
+		s.logger.Warn("thread subscriptions bootstrap failed, using empty state", "error", err.Error())
+	}
+
+	s.metricsHistory.Configure(bundle.MetricsHistoryPath, secret)
+	if err := s.metricsHistory.Bootstrap(); err != nil {
+		s.logger.Warn("metrics history bootstrap failed, using empty history", "error", err.Error())
+	}
+
+	s.contactSharing.Configure(bundle.ContactSharingPath, secret)
+	if err := s.contactSharing.Bootstrap(); err != nil {
+		s.logger.Warn("contact sharing bootstrap failed, sharing everything", "error", err.Error())
+	}
+
+	s.auditLog.Configure(bundle.AuditLogPath, secret)
+	if err := s.auditLog.Bootstrap(); err != nil {
+		s.logger.Warn("audit log bootstrap failed, using empty log", "error", err.Error())
+	} else if check := s.auditLog.Verify(); !check.Valid {
+		s.logger.Warn("audit log chain is broken", "broken_at", check.BrokenAt, "reason", check.Reason)
+	}
+
+	s.deadLetters.Configure(bundle.DeadLettersPath, secret)
+	if err := s.deadLetters.Bootstrap(); err != nil {
+		s.logger.Warn("dead letters bootstrap failed, using empty queue", "error", err.Error())
+	}
 }
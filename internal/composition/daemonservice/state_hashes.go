@@ -0,0 +1,194 @@
+package daemonservice
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// StateHashes summarises this device's state: a Merkle root per
+// conversation and a settings root over the synced conversation flags and
+// the privacy settings.
+func (s *Service) StateHashes() (models.StateHashes, error) {
+	settings, err := s.privacyCore.GetPrivacySettings()
+	if err != nil {
+		return models.StateHashes{}, err
+	}
+	settingsRoot, err := messagingapp.SettingsStateRoot(s.ListConversationFlags(), settings)
+	if err != nil {
+		return models.StateHashes{}, err
+	}
+	messages, _ := s.messageStore.Snapshot()
+	conversations := messagingapp.ConversationStateHashes(messages)
+	// Without an active device the hashes are still useful locally.
+	deviceID, _ := s.activeDeviceID()
+	return models.StateHashes{
+		DeviceID:      deviceID,
+		ComputedAt:    s.now().UTC(),
+		Root:          messagingapp.StateRoot(settingsRoot, conversations),
+		SettingsRoot:  settingsRoot,
+		Conversations: conversations,
+	}, nil
+}
+
+// ListStateDivergence returns what differed in the last comparison with
+// each device or peer that reported hashes. Sources found in sync are
+// dropped.
+func (s *Service) ListStateDivergence() []models.StateDivergence {
+	s.stateHashMu.Lock()
+	defer s.stateHashMu.Unlock()
+	out := make([]models.StateDivergence, 0, len(s.stateDivergence))
+	for _, divergence := range s.stateDivergence {
+		divergence.Conversations = append([]string(nil), divergence.Conversations...)
+		out = append(out, divergence)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PeerID != out[j].PeerID {
+			return out[i].PeerID < out[j].PeerID
+		}
+		return out[i].DeviceID < out[j].DeviceID
+	})
+	return out
+}
+
+// ExchangeStateHashes sends this device's hashes and asks for the other
+// side's in return. With an empty contactID they go to the user's other
+// devices; a contact only receives the hash of the chat shared with them.
+func (s *Service) ExchangeStateHashes(contactID string) error {
+	hashes, err := s.StateHashes()
+	if err != nil {
+		return err
+	}
+	hashes.ReplyRequested = true
+	return s.sendStateHashes(strings.TrimSpace(contactID), hashes)
+}
+
+func (s *Service) sendStateHashes(contactID string, hashes models.StateHashes) error {
+	ctx, err := s.networkContext("")
+	if err != nil {
+		return err
+	}
+	self := s.identityManager.GetIdentity().ID
+	wire := contracts.WirePayload{Kind: messagingapp.WireKindDeviceSync, StateHashes: &hashes}
+	recipient := self
+	if contactID != "" && contactID != self {
+		if !s.identityManager.HasContact(contactID) {
+			return errors.New("contact not found")
+		}
+		wire = contracts.WirePayload{Kind: messagingapp.WireKindStateHash, StateHashes: peerStateHashes(hashes, contactID)}
+		recipient = contactID
+	}
+	wireID, err := s.generateID("shash")
+	if err != nil {
+		return err
+	}
+	return s.publishSignedWireWithContext(ctx, wireID, recipient, wire)
+}
+
+// peerStateHashes narrows hashes to the direct chat with contactID and
+// leaves out the settings, which are nobody else's business.
+func peerStateHashes(hashes models.StateHashes, contactID string) *models.StateHashes {
+	conversations := make([]models.ConversationStateHash, 0, 1)
+	for _, conv := range hashes.Conversations {
+		if conv.ConversationType == models.ConversationTypeDirect && conv.ConversationID == contactID {
+			conversations = append(conversations, conv)
+		}
+	}
+	return &models.StateHashes{
+		DeviceID:       hashes.DeviceID,
+		ComputedAt:     hashes.ComputedAt,
+		Root:           messagingapp.StateRoot("", conversations),
+		Conversations:  conversations,
+		ReplyRequested: hashes.ReplyRequested,
+	}
+}
+
+// handleOwnDeviceStateHashes compares hashes from another of our devices
+// with ours and answers when asked to. Answers never ask back, so an echo of
+// our own request costs one extra message at most.
+func (s *Service) handleOwnDeviceStateHashes(remote models.StateHashes) {
+	local, err := s.StateHashes()
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryStorage, err)
+		return
+	}
+	settingsDiverged, conversations := messagingapp.DiffStateHashes(local, remote)
+	s.recordStateComparison(s.identityManager.GetIdentity().ID, remote.DeviceID, settingsDiverged, conversations)
+	if remote.ReplyRequested {
+		if err := s.sendStateHashes("", local); err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}
+}
+
+// handleStateHashWire compares a contact's hash of our direct chat with
+// ours. The peer's conversation id names us, so the chat is looked up by
+// sender instead.
+func (s *Service) handleStateHashWire(senderID string, wire contracts.WirePayload) {
+	if wire.StateHashes == nil || !s.identityManager.HasContact(senderID) {
+		return
+	}
+	remote := *wire.StateHashes
+	var localRoot, remoteRoot string
+	if local, ok := s.directConversationHash(senderID); ok {
+		localRoot = local.Root
+	}
+	if len(remote.Conversations) == 1 {
+		remoteRoot = remote.Conversations[0].Root
+	}
+	var conversations []string
+	if localRoot != remoteRoot {
+		conversations = []string{senderID}
+	}
+	s.recordStateComparison(senderID, remote.DeviceID, false, conversations)
+	if remote.ReplyRequested {
+		local, err := s.StateHashes()
+		if err == nil {
+			err = s.sendStateHashes(senderID, local)
+		}
+		if err != nil {
+			s.recordError(contracts.ErrorCategoryNetwork, err)
+		}
+	}
+}
+
+func (s *Service) directConversationHash(contactID string) (models.ConversationStateHash, bool) {
+	messages := map[string]models.Message{}
+	for _, msg := range s.messageStore.ListMessagesByConversation(contactID, models.ConversationTypeDirect, 0, 0) {
+		messages[msg.ID] = msg
+	}
+	hashes := messagingapp.ConversationStateHashes(messages)
+	if len(hashes) != 1 {
+		return models.ConversationStateHash{}, false
+	}
+	return hashes[0], true
+}
+
+// recordStateComparison remembers what differs from a device or peer and
+// tells clients, so sync knows which conversations need repair.
+func (s *Service) recordStateComparison(peerID, deviceID string, settingsDiverged bool, conversations []string) {
+	key := peerID + "/" + deviceID
+	if conversations == nil {
+		conversations = []string{}
+	}
+	s.stateHashMu.Lock()
+	if !settingsDiverged && len(conversations) == 0 {
+		delete(s.stateDivergence, key)
+		s.stateHashMu.Unlock()
+		return
+	}
+	divergence := models.StateDivergence{
+		PeerID:           peerID,
+		DeviceID:         deviceID,
+		ComparedAt:       s.now().UTC(),
+		SettingsDiverged: settingsDiverged,
+		Conversations:    conversations,
+	}
+	s.stateDivergence[key] = divergence
+	s.stateHashMu.Unlock()
+	s.notify("notify.state.diverged", divergence)
+}
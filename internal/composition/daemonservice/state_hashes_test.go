@@ -0,0 +1,76 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestStateHashesFlagDivergenceBetweenOwnDevices(t *testing.T) {
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	baseDir := t.TempDir()
+	phone, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "phone"))
+	if err != nil {
+		t.Fatalf("new phone service: %v", err)
+	}
+	laptop, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(baseDir, "laptop"))
+	if err != nil {
+		t.Fatalf("new laptop service: %v", err)
+	}
+	self, mnemonic, err := phone.CreateIdentity("shared-pass")
+	if err != nil {
+		t.Fatalf("phone identity: %v", err)
+	}
+	if _, err := laptop.ImportIdentity(mnemonic, "shared-pass"); err != nil {
+		t.Fatalf("laptop import: %v", err)
+	}
+	shared := models.Message{ID: "m1", ContactID: "bob", Direction: "in", Status: "delivered", ContentType: "text", Content: []byte("hi"), Timestamp: time.Now().UTC()}
+	for _, svc := range []*Service{phone, laptop} {
+		if err := svc.messageStore.SaveMessage(shared); err != nil {
+			t.Fatalf("save shared message: %v", err)
+		}
+	}
+	if err := phone.messageStore.SaveMessage(models.Message{ID: "m2", ContactID: "carol", Direction: "in", Status: "read", ContentType: "text", Content: []byte("only on the phone"), Timestamp: time.Now().UTC()}); err != nil {
+		t.Fatalf("save phone-only message: %v", err)
+	}
+
+	deliver := func() {
+		t.Helper()
+		hashes, err := phone.StateHashes()
+		if err != nil {
+			t.Fatalf("phone state hashes: %v", err)
+		}
+		wire := contracts.WirePayload{Kind: messagingapp.WireKindDeviceSync, StateHashes: &hashes}
+		wmsg, err := messagingapp.ComposeSignedPrivateMessage("shash-"+hashes.Root[:8], self.ID, wire, phone.identityManager)
+		if err != nil {
+			t.Fatalf("compose state hashes: %v", err)
+		}
+		laptop.HandleIncomingPrivateMessage(messagingapp.InboundPrivateMessage{ID: wmsg.ID, SenderID: wmsg.SenderID, Recipient: wmsg.Recipient, Payload: wmsg.Payload})
+	}
+
+	deliver()
+	divergence := laptop.ListStateDivergence()
+	if len(divergence) != 1 || divergence[0].PeerID != self.ID || divergence[0].SettingsDiverged || !slices.Equal(divergence[0].Conversations, []string{"carol"}) {
+		t.Fatalf("expected only carol's chat to diverge, got %+v", divergence)
+	}
+
+	if err := laptop.messageStore.SaveMessage(models.Message{ID: "m2", ContactID: "carol", Direction: "in", Status: "delivered", ContentType: "text", Content: []byte("only on the phone"), Timestamp: time.Now().UTC()}); err != nil {
+		t.Fatalf("repair laptop: %v", err)
+	}
+	deliver()
+	if divergence := laptop.ListStateDivergence(); len(divergence) != 0 {
+		t.Fatalf("expected repaired devices to agree, got %+v", divergence)
+	}
+	phoneHashes, _ := phone.StateHashes()
+	laptopHashes, _ := laptop.StateHashes()
+	if phoneHashes.Root != laptopHashes.Root {
+		t.Fatalf("expected equal roots once repaired: %s vs %s", phoneHashes.Root, laptopHashes.Root)
+	}
+}
@@ -60,6 +60,27 @@ func (s *Service) SetStorageScopeOverride(
 	})
 }
 
+// SetStorageScopeOverrides validates every override the same way as
+// SetStorageScopeOverride before storing them in one step.
+func (s *Service) SetStorageScopeOverrides(items []privacydomain.StorageScopeOverrideInput) ([]privacydomain.StorageScopeOverrideEntry, error) {
+	for _, item := range items {
+		if _, err := privacydomain.ParseStoragePolicy(
+			string(item.Override.StorageProtection),
+			string(item.Override.ContentRetentionMode),
+			item.Override.MessageTTLSeconds,
+			item.Override.ImageTTLSeconds,
+			item.Override.FileTTLSeconds,
+			item.Override.ImageQuotaMB,
+			item.Override.FileQuotaMB,
+			item.Override.ImageMaxItemSizeMB,
+			item.Override.FileMaxItemSizeMB,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return s.privacyCore.SetStorageScopeOverrides(items)
+}
+
 func (s *Service) GetStorageScopeOverride(scope string, scopeID string) (privacydomain.StoragePolicyOverride, bool, error) {
 	return s.privacyCore.GetStorageScopeOverride(scope, scopeID)
 }
@@ -133,7 +154,12 @@ func (s *Service) ExportBackup(consentToken, passphrase string) (string, error)
 	if settings.ContentRetentionMode == privacydomain.RetentionZeroRetention {
 		return "", ErrBackupDisabledByRetentionPolicy
 	}
-	return s.identityCore.ExportBackup(consentToken, passphrase)
+	backup, err := s.identityCore.ExportBackup(consentToken, passphrase)
+	if err != nil {
+		return "", err
+	}
+	s.recordAudit(models.AuditKindBackupExported)
+	return backup, nil
 }
 
 func (s *Service) applyStoragePolicy(policy privacydomain.StoragePolicy) error {
@@ -167,6 +193,19 @@ func (s *Service) applyStoragePolicy(policy privacydomain.StoragePolicy) error {
 	if setter, ok := s.sessionManager.(interface{ SetPersistenceEnabled(bool) }); ok {
 		setter.SetPersistenceEnabled(persistentContentAllowed)
 	}
+	if lifecycle, ok := s.attachmentStore.(interface {
+		ApplyBlobLifecycle(imageTTLSeconds, fileTTLSeconds int) error
+	}); ok && persistentContentAllowed {
+		imageTTL, fileTTL := 0, 0
+		if policy.ContentRetentionMode == privacydomain.RetentionEphemeral {
+			imageTTL, fileTTL = policy.ImageTTLSeconds, policy.FileTTLSeconds
+		}
+		// The bucket only backs up local GC, so an unreachable object store
+		// must not block the policy change.
+		if err := lifecycle.ApplyBlobLifecycle(imageTTL, fileTTL); err != nil {
+			s.recordError("storage", err)
+		}
+	}
 
 	if !persistentContentAllowed {
 		return s.wipeContentState()
@@ -231,6 +270,7 @@ func (s *Service) enforceRetentionPolicies(now time.Time) {
 		if report.DeletedCount > 0 {
 			s.recordGCEvictions(report.DeletedByClass)
 		}
+		s.notifyExpiringAttachments(now, imageTTL, fileTTL)
 		return
 	}
 	// Legacy fallback.
@@ -271,6 +311,18 @@ func (s *Service) wipeContentState() error {
 	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.sessionManager))
 	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.attachmentStore))
 	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.bindingStore))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.clientState))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.annotations))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.conversationSync))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.groupAvatars))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.messageSeqs))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.snippets))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.attachmentPolicies))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.threadSubs))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.metricsHistory))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.contactSharing))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.auditLog))
+	wipeErr = errors.Join(wipeErr, wipeIfSupported(s.deadLetters))
 	if s.requestInboxState != nil {
 		wipeErr = errors.Join(wipeErr, s.requestInboxState.Wipe())
 	}
@@ -306,9 +358,38 @@ func (s *Service) resetVolatileRuntimeState() {
 	if s.notifier != nil {
 		s.notifier.Reset()
 	}
+	if s.downloads != nil {
+		s.downloads.reset()
+	}
 	if s.bindingLinkMu != nil {
 		s.bindingLinkMu.Lock()
 		s.bindingLinks = map[string]pendingNodeBindingLink{}
 		s.bindingLinkMu.Unlock()
 	}
+	if s.emergencyMu != nil {
+		s.emergencyMu.Lock()
+		s.emergencyConfirms = map[string]pendingEmergencyBroadcast{}
+		s.emergencyMu.Unlock()
+	}
+	if s.stateHashMu != nil {
+		s.stateHashMu.Lock()
+		s.stateDivergence = map[string]models.StateDivergence{}
+		s.stateHashMu.Unlock()
+	}
+	if s.exportMu != nil {
+		s.exportMu.Lock()
+		s.exportTokens = map[string]pendingConversationExport{}
+		s.peerExportConsents = map[string]models.ExportConsent{}
+		s.exportMu.Unlock()
+	}
+	if s.securityAlertsMu != nil {
+		s.securityAlertsMu.Lock()
+		s.securityAlerts = map[string][]models.SecurityAlert{}
+		s.securityAlertsMu.Unlock()
+	}
+	if s.expiryNoticeMu != nil {
+		s.expiryNoticeMu.Lock()
+		s.expiryNotified = map[string]time.Time{}
+		s.expiryNoticeMu.Unlock()
+	}
 }
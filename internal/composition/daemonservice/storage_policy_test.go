@@ -8,6 +8,7 @@ import (
 	"time"
 
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/internal/storage"
 	"aim-chat/go-backend/internal/waku"
 	"aim-chat/go-backend/pkg/models"
 )
@@ -271,3 +272,65 @@ func TestRunAttachmentGCDryRunDoesNotMutateState(t *testing.T) {
 		t.Fatalf("dry-run must not delete attachments, got: %v", err)
 	}
 }
+
+func TestEnforceRetentionPoliciesNotifiesExpiringAttachmentsOnce(t *testing.T) {
+	t.Parallel()
+
+	svc := newStoragePolicyTestService(t)
+	att, err := svc.attachmentStore.Put("notes.txt", "text/plain", []byte("notes"))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if _, err := svc.UpdateStoragePolicy("standard", "ephemeral", 0, 3600, 3600, 0, 0, 0, 0); err != nil {
+		t.Fatalf("update storage policy: %v", err)
+	}
+	expiringEvents := func() []models.AttachmentExpiry {
+		backlog, _, cancel := svc.notifier.Subscribe(0)
+		cancel()
+		var out []models.AttachmentExpiry
+		for _, event := range backlog {
+			if event.Method != "notify.storage.expiring" {
+				continue
+			}
+			out = append(out, event.Payload.(map[string]any)["blobs"].([]models.AttachmentExpiry)...)
+		}
+		return out
+	}
+
+	now := att.CreatedAt.Add(30 * time.Minute)
+	svc.enforceRetentionPolicies(now)
+	svc.enforceRetentionPolicies(now)
+	events := expiringEvents()
+	if len(events) != 1 || events[0].BlobID != att.ID || !events[0].ExpiresAt.Equal(att.CreatedAt.Add(time.Hour)) {
+		t.Fatalf("expected one expiry notice for the attachment, got %+v", events)
+	}
+
+	extended, err := svc.ExtendAttachmentExpiry(att.ID, 1800)
+	if err != nil {
+		t.Fatalf("extend attachment expiry: %v", err)
+	}
+	if !extended.ExpiresAt.Equal(att.CreatedAt.Add(90 * time.Minute)) {
+		t.Fatalf("unexpected extended deadline: %+v", extended)
+	}
+	svc.enforceRetentionPolicies(att.CreatedAt.Add(time.Hour))
+	if _, _, err := svc.attachmentStore.Get(att.ID); err != nil {
+		t.Fatalf("extended attachment must survive its original ttl: %v", err)
+	}
+	events = expiringEvents()
+	if len(events) != 2 || !events[1].ExpiresAt.Equal(extended.ExpiresAt) {
+		t.Fatalf("moved deadline must be announced again, got %+v", events)
+	}
+}
+
+func TestExtendAttachmentExpiryRequiresTTL(t *testing.T) {
+	t.Parallel()
+
+	svc := newStoragePolicyTestService(t)
+	att, err := svc.attachmentStore.Put("keep.txt", "text/plain", []byte("keep"))
+	if err != nil {
+		t.Fatalf("put attachment: %v", err)
+	}
+	if _, err := svc.ExtendAttachmentExpiry(att.ID, 60); !errors.Is(err, storage.ErrAttachmentNotExpiring) {
+		t.Fatalf("expected ErrAttachmentNotExpiring, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errStorageKeyRotationRunning = errors.New("storage key rotation is already running")
+
+// RotateStorageKey replaces the storage secret shared by all account profiles.
+// The identity password authorizes the call. Re-encryption runs in the
+// background; if the daemon stops halfway it is finished on the next start.
+func (s *Service) RotateStorageKey(password string) (models.StorageKeyRotationStatus, error) {
+	if daemoncomposition.StorageKeyFromEnv() {
+		return models.StorageKeyRotationStatus{}, daemoncomposition.ErrStorageKeyManagedByEnv
+	}
+	if daemoncomposition.AttachmentObjectBackendConfigured() {
+		return models.StorageKeyRotationStatus{}, daemoncomposition.ErrStorageKeyRotationObjectBackend
+	}
+	if err := s.identityManager.VerifyPassword(password); err != nil {
+		return models.StorageKeyRotationStatus{}, err
+	}
+
+	s.rotationMu.Lock()
+	if s.rotation.State == models.StorageKeyRotationRunning {
+		s.rotationMu.Unlock()
+		return models.StorageKeyRotationStatus{}, errStorageKeyRotationRunning
+	}
+	s.rotation = models.StorageKeyRotationStatus{
+		State:     models.StorageKeyRotationRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	status := s.rotation
+	s.rotationMu.Unlock()
+
+	s.notify("notify.storage.rotation", status)
+	go s.runStorageKeyRotation()
+	return status, nil
+}
+
+func (s *Service) GetStorageKeyRotationStatus() models.StorageKeyRotationStatus {
+	s.rotationMu.Lock()
+	defer s.rotationMu.Unlock()
+	if s.rotation.State == "" {
+		return models.StorageKeyRotationStatus{State: models.StorageKeyRotationIdle}
+	}
+	return s.rotation
+}
+
+func (s *Service) runStorageKeyRotation() {
+	s.profileMu.Lock()
+	err := s.rotateStorageKeyLocked()
+	s.profileMu.Unlock()
+
+	s.rotationMu.Lock()
+	s.rotation.FinishedAt = time.Now().UTC()
+	if err != nil {
+		s.rotation.State = models.StorageKeyRotationFailed
+		s.rotation.Error = err.Error()
+	} else {
+		s.rotation.State = models.StorageKeyRotationCompleted
+	}
+	status := s.rotation
+	s.rotationMu.Unlock()
+
+	if err != nil {
+		s.recordError("storage", err)
+	} else {
+		s.logger.Info("storage key rotation completed", "files", status.FilesTotal)
+	}
+	s.notify("notify.storage.rotation", status)
+}
+
+func (s *Service) rotateStorageKeyLocked() error {
+	oldSecret := s.storageSecret
+	newSecret, err := daemoncomposition.BeginStorageKeyRotation(s.dataDir, oldSecret)
+	if err != nil {
+		return err
+	}
+
+	wasRunning := s.runtime.IsNetworking()
+	if wasRunning {
+		stopCtx, cancel := context.WithTimeout(context.Background(), networkSwitchTimeout)
+		_ = s.StopNetworking(stopCtx)
+		cancel()
+		defer func() { _ = s.StartNetworking(context.Background()) }()
+	}
+
+	if _, err := daemoncomposition.RekeyStorage(s.dataDir, oldSecret, newSecret, s.setStorageKeyRotationProgress); err != nil {
+		return err
+	}
+	// Live stores kept writing with the old secret during the first pass.
+	// Retiring it closes them: writes under way finish, later ones fail until
+	// the stores are reopened with the new secret below, so the last pass
+	// sees every file in its final state.
+	securestore.RetireSecret(oldSecret)
+	committed := false
+	defer func() {
+		if !committed {
+			securestore.ReinstateSecret(oldSecret)
+		}
+	}()
+	unreadable, err := daemoncomposition.RekeyStorage(s.dataDir, oldSecret, newSecret, nil)
+	if err != nil {
+		return err
+	}
+	if err := daemoncomposition.CommitStorageKeyRotation(s.dataDir, newSecret); err != nil {
+		return err
+	}
+	committed = true
+	s.storageSecret = newSecret
+	if len(unreadable) > 0 {
+		s.rotationMu.Lock()
+		s.rotation.UnreadableFiles = unreadable
+		s.rotationMu.Unlock()
+		s.logger.Warn("storage files opened with neither key during rotation", "category", "storage", "files", unreadable)
+	}
+	return s.activateAccountLocked(s.currentProfileID, false)
+}
+
+func (s *Service) setStorageKeyRotationProgress(done, total int) {
+	s.rotationMu.Lock()
+	previous := s.rotation
+	s.rotation.FilesDone = done
+	s.rotation.FilesTotal = total
+	status := s.rotation
+	s.rotationMu.Unlock()
+
+	if total > 0 && (done == total || done*100/total != previous.FilesDone*100/max(previous.FilesTotal, 1)) {
+		s.notify("notify.storage.rotation", status)
+	}
+}
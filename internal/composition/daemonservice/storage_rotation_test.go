@@ -0,0 +1,104 @@
+package daemonservice
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestRotateStorageKeyReencryptsStateAndReloadsAccount(t *testing.T) {
+	t.Setenv("AIM_STORAGE_PASSPHRASE", "")
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	dataDir := t.TempDir()
+	svc, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	created, _, err := svc.CreateIdentity("password-1")
+	if err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	msg := models.Message{
+		ID:        "msg_rotate_1",
+		ContactID: "aim1_contact",
+		Content:   []byte("secret"),
+		Timestamp: time.Now().UTC(),
+		Direction: "out",
+		Status:    "sent",
+	}
+	if err := svc.messageStore.SaveMessage(msg); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+	oldKey, err := os.ReadFile(filepath.Join(dataDir, "storage.key"))
+	if err != nil {
+		t.Fatalf("read storage key: %v", err)
+	}
+
+	staleStore := svc.messageStore
+
+	if status := svc.GetStorageKeyRotationStatus(); status.State != models.StorageKeyRotationIdle {
+		t.Fatalf("unexpected state before rotation: %s", status.State)
+	}
+	if _, err := svc.RotateStorageKey("password-1"); err != nil {
+		t.Fatalf("rotate storage key: %v", err)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	status := svc.GetStorageKeyRotationStatus()
+	for status.State == models.StorageKeyRotationRunning && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		status = svc.GetStorageKeyRotationStatus()
+	}
+	if status.State != models.StorageKeyRotationCompleted {
+		t.Fatalf("rotation did not complete: %+v", status)
+	}
+	if status.FilesTotal == 0 || status.FilesDone != status.FilesTotal {
+		t.Fatalf("unexpected progress: %+v", status)
+	}
+	newKey, err := os.ReadFile(filepath.Join(dataDir, "storage.key"))
+	if err != nil {
+		t.Fatalf("read rotated storage key: %v", err)
+	}
+	if string(newKey) == string(oldKey) {
+		t.Fatal("storage key was not replaced")
+	}
+	if _, ok := svc.messageStore.GetMessage(msg.ID); !ok {
+		t.Fatal("message must survive rotation in the running service")
+	}
+	if len(status.UnreadableFiles) != 0 {
+		t.Fatalf("unexpected unreadable files: %v", status.UnreadableFiles)
+	}
+	// A store opened before the rotation must not write with the old key.
+	late := msg
+	late.ID = "msg_rotate_late"
+	if err := staleStore.SaveMessage(late); !errors.Is(err, securestore.ErrSecretRetired) {
+		t.Fatalf("expected stale store write to be rejected, got %v", err)
+	}
+	if err := svc.messageStore.SaveMessage(late); err != nil {
+		t.Fatalf("save after rotation: %v", err)
+	}
+
+	reopened, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("reopen service: %v", err)
+	}
+	identity, err := reopened.GetIdentity()
+	if err != nil || identity.ID != created.ID {
+		t.Fatalf("identity mismatch after reopen: %v", err)
+	}
+	for _, id := range []string{msg.ID, late.ID} {
+		if _, ok := reopened.messageStore.GetMessage(id); !ok {
+			t.Fatalf("message %s must be readable with the rotated key", id)
+		}
+	}
+	if _, err := reopened.RotateStorageKey("wrong-password"); err == nil {
+		t.Fatal("expected password check to reject rotation")
+	}
+}
@@ -0,0 +1,123 @@
+package daemonservice
+
+import (
+	"sync"
+	"time"
+
+	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	storageScrubIntervalEnv     = "AIM_STORAGE_SCRUB_INTERVAL"
+	defaultStorageScrubInterval = 6 * time.Hour
+	// storageScrubFirstDelay keeps the first pass clear of startup work.
+	storageScrubFirstDelay = 10 * time.Minute
+	// storageScrubPace spaces store reads so a pass stays in the background.
+	storageScrubPace = 50 * time.Millisecond
+)
+
+// storageScrubState schedules integrity passes. An interval of zero turns
+// scrubbing off.
+type storageScrubState struct {
+	mu       sync.Mutex
+	interval time.Duration
+	nextRun  time.Time
+	running  bool
+}
+
+func newStorageScrubState(now time.Time) *storageScrubState {
+	return &storageScrubState{
+		interval: envDurationWithFallback(storageScrubIntervalEnv, defaultStorageScrubInterval),
+		nextRun:  now.Add(storageScrubFirstDelay),
+	}
+}
+
+// scheduleStorageScrub starts a background pass when one is due and none is
+// running yet.
+func (s *Service) scheduleStorageScrub(now time.Time) {
+	state := s.storageScrub
+	state.mu.Lock()
+	if state.interval <= 0 || state.running || now.Before(state.nextRun) {
+		state.mu.Unlock()
+		return
+	}
+	state.running = true
+	state.nextRun = now.Add(state.interval)
+	state.mu.Unlock()
+
+	go func() {
+		defer func() {
+			state.mu.Lock()
+			state.running = false
+			state.mu.Unlock()
+		}()
+		if _, err := s.scrubStorage(func() { time.Sleep(storageScrubPace) }); err != nil {
+			s.recordError("storage", err)
+		}
+	}()
+}
+
+// scrubStorage checks every store without holding locks. Only when that pass
+// finds damage are writers stopped for a repair pass, which restores records
+// from snapshots and reloads the active account.
+func (s *Service) scrubStorage(pace func()) (models.StorageScrubReport, error) {
+	s.profileMu.Lock()
+	dataDir, secret := s.dataDir, s.storageSecret
+	s.profileMu.Unlock()
+	if dataDir == "" {
+		return models.StorageScrubReport{}, nil
+	}
+	report, err := daemoncomposition.ScrubStorage(dataDir, secret, false, pace)
+	if err != nil {
+		return report, err
+	}
+	if len(report.Corrupt) > 0 {
+		if report, err = s.repairStorage(); err != nil {
+			return report, err
+		}
+	}
+
+	restored := 0
+	for _, record := range report.Corrupt {
+		if record.Restored {
+			restored++
+		}
+	}
+	s.metrics.RecordStorageScrub(report.Records, len(report.Corrupt), restored)
+	if len(report.Corrupt) > 0 {
+		s.logger.Warn("storage corruption found",
+			"category", "storage",
+			"corrupt_records", len(report.Corrupt),
+			"restored_records", restored,
+		)
+		s.notify("notify.storage.corruption", report)
+	}
+	return report, nil
+}
+
+func (s *Service) repairStorage() (models.StorageScrubReport, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.ensureStorageSnapshotsAllowed(); err != nil {
+		return models.StorageScrubReport{}, err
+	}
+	resume := s.pauseNetworkingLocked()
+	defer resume()
+
+	report, err := daemoncomposition.ScrubStorage(s.dataDir, s.storageSecret, true, nil)
+	if err != nil {
+		return report, err
+	}
+	for _, record := range report.Corrupt {
+		if !record.Restored {
+			continue
+		}
+		reg, err := s.loadAccountRegistry()
+		if err != nil {
+			return report, err
+		}
+		return report, s.activateAccountLocked(reg.ActiveID, false)
+	}
+	return report, nil
+}
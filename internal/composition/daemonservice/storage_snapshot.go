@@ -0,0 +1,147 @@
+package daemonservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	daemoncomposition "aim-chat/go-backend/internal/composition/daemon"
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errStorageSnapshotDuringRotation = errors.New("storage snapshots are unavailable while the storage key rotates")
+
+// CreateStorageSnapshot copies the state of every account profile into a new
+// snapshot. Store writes are held while the files are copied, so the snapshot
+// is one point in time across stores, and networking is paused so inbound
+// traffic does not pile up behind the hold.
+func (s *Service) CreateStorageSnapshot(label string) (models.StorageSnapshot, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.ensureStorageSnapshotsAllowed(); err != nil {
+		return models.StorageSnapshot{}, err
+	}
+
+	resume := s.pauseNetworkingLocked()
+	snapshot, err := s.createStorageSnapshotLocked(models.StorageSnapshotReasonManual, label)
+	resume()
+	if err != nil {
+		s.recordError("storage", err)
+		return models.StorageSnapshot{}, err
+	}
+	s.notify("notify.storage.snapshot", snapshot)
+	return snapshot, nil
+}
+
+func (s *Service) ListStorageSnapshots() ([]models.StorageSnapshot, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	return daemoncomposition.ListStorageSnapshots(s.dataDir)
+}
+
+// RestoreStorageSnapshot rolls the data dir back to a snapshot and reloads
+// the active account from it. The replaced state is kept as a pre_restore
+// snapshot, so a restore can itself be undone.
+func (s *Service) RestoreStorageSnapshot(snapshotID string) (models.StorageSnapshotRestoreResult, error) {
+	snapshotID = strings.TrimSpace(snapshotID)
+	if snapshotID == "" {
+		return models.StorageSnapshotRestoreResult{}, errors.New("snapshot id is required")
+	}
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	if err := s.ensureStorageSnapshotsAllowed(); err != nil {
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	snapshots, err := daemoncomposition.ListStorageSnapshots(s.dataDir)
+	if err != nil {
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	if !slices.ContainsFunc(snapshots, func(snapshot models.StorageSnapshot) bool { return snapshot.ID == snapshotID }) {
+		return models.StorageSnapshotRestoreResult{}, daemoncomposition.ErrStorageSnapshotNotFound
+	}
+
+	resume := s.pauseNetworkingLocked()
+	defer resume()
+
+	// The safety copy and the restore run under one hold, so no write lands
+	// between them. Writes that waited meanwhile carry the replaced state and
+	// are discarded rather than written over the restored files.
+	hold := securestore.HoldWrites()
+	safety, err := daemoncomposition.CreateStorageSnapshot(s.dataDir, models.StorageSnapshotReasonPreRestore, "before restoring "+snapshotID)
+	if err != nil {
+		hold.Release()
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	restored, err := daemoncomposition.RestoreStorageSnapshot(s.dataDir, snapshotID)
+	if err != nil {
+		hold.Release()
+		s.recordError("storage", err)
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	hold.Discard()
+	s.pruneStorageSnapshotsLocked(snapshotID, safety.ID)
+	reg, err := s.loadAccountRegistry()
+	if err != nil {
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	if err := s.activateAccountLocked(reg.ActiveID, false); err != nil {
+		s.recordError("storage", err)
+		return models.StorageSnapshotRestoreResult{}, err
+	}
+	result := models.StorageSnapshotRestoreResult{Restored: restored, Safety: safety}
+	s.logger.Info("storage snapshot restored", "snapshot_id", restored.ID, "safety_snapshot_id", safety.ID)
+	s.notify("notify.storage.restored", result)
+	return result, nil
+}
+
+// GetStorageVersion reports the schema version of every store on disk next to
+// the version this build supports.
+func (s *Service) GetStorageVersion() (models.StorageVersionInfo, error) {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	stores, err := daemoncomposition.StorageSchemaVersions(s.dataDir, s.storageSecret)
+	if err != nil {
+		return models.StorageVersionInfo{}, err
+	}
+	return models.StorageVersionInfo{AppVersion: daemoncomposition.AppVersion(), Stores: stores}, nil
+}
+
+func (s *Service) createStorageSnapshotLocked(reason, label string) (models.StorageSnapshot, error) {
+	hold := securestore.HoldWrites()
+	snapshot, err := daemoncomposition.CreateStorageSnapshot(s.dataDir, reason, label)
+	hold.Release()
+	if err != nil {
+		return models.StorageSnapshot{}, err
+	}
+	s.pruneStorageSnapshotsLocked(snapshot.ID)
+	return snapshot, nil
+}
+
+func (s *Service) pruneStorageSnapshotsLocked(protect ...string) {
+	if err := daemoncomposition.PruneStorageSnapshots(s.dataDir, daemoncomposition.DefaultStorageSnapshotRetention, protect...); err != nil {
+		s.recordError("storage", err)
+	}
+}
+
+func (s *Service) ensureStorageSnapshotsAllowed() error {
+	s.rotationMu.Lock()
+	defer s.rotationMu.Unlock()
+	if s.rotation.State == models.StorageKeyRotationRunning {
+		return errStorageSnapshotDuringRotation
+	}
+	return nil
+}
+
+// pauseNetworkingLocked stops networking if it runs and returns the function
+// that starts it again.
+func (s *Service) pauseNetworkingLocked() func() {
+	if !s.runtime.IsNetworking() {
+		return func() {}
+	}
+	stopCtx, cancel := context.WithTimeout(context.Background(), networkSwitchTimeout)
+	_ = s.StopNetworking(stopCtx)
+	cancel()
+	return func() { _ = s.StartNetworking(context.Background()) }
+}
@@ -0,0 +1,81 @@
+package daemonservice
+
+import (
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestRestoreStorageSnapshotRollsBackAndReloadsState(t *testing.T) {
+	t.Setenv("AIM_STORAGE_PASSPHRASE", "")
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	dataDir := t.TempDir()
+	svc, err := NewServiceForDaemonWithDataDir(cfg, dataDir)
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+	if _, _, err := svc.CreateIdentity("password-1"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	before := models.Message{
+		ID:        "msg_snapshot_before",
+		ContactID: "aim1_contact",
+		Content:   []byte("kept"),
+		Timestamp: time.Now().UTC(),
+		Direction: "out",
+		Status:    "sent",
+	}
+	if err := svc.messageStore.SaveMessage(before); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+
+	snapshot, err := svc.CreateStorageSnapshot("before cleanup")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if snapshot.Reason != models.StorageSnapshotReasonManual || snapshot.Files == 0 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	after := before
+	after.ID = "msg_snapshot_after"
+	if err := svc.messageStore.SaveMessage(after); err != nil {
+		t.Fatalf("save message: %v", err)
+	}
+
+	result, err := svc.RestoreStorageSnapshot(snapshot.ID)
+	if err != nil {
+		t.Fatalf("restore snapshot: %v", err)
+	}
+	if result.Restored.ID != snapshot.ID || result.Safety.Reason != models.StorageSnapshotReasonPreRestore {
+		t.Fatalf("unexpected restore result: %+v", result)
+	}
+	if _, ok := svc.messageStore.GetMessage(before.ID); !ok {
+		t.Fatal("message from before the snapshot must survive restore")
+	}
+	if _, ok := svc.messageStore.GetMessage(after.ID); ok {
+		t.Fatal("message written after the snapshot must be rolled back")
+	}
+
+	snapshots, err := svc.ListStorageSnapshots()
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].ID != result.Safety.ID {
+		t.Fatalf("unexpected snapshots: %+v", snapshots)
+	}
+
+	// The safety snapshot undoes the restore.
+	if _, err := svc.RestoreStorageSnapshot(result.Safety.ID); err != nil {
+		t.Fatalf("undo restore: %v", err)
+	}
+	if _, ok := svc.messageStore.GetMessage(after.ID); !ok {
+		t.Fatal("undoing the restore must bring back the newer message")
+	}
+	if _, err := svc.RestoreStorageSnapshot("snap_missing"); err == nil {
+		t.Fatal("expected error for unknown snapshot")
+	}
+}
@@ -0,0 +1,33 @@
+package daemonservice
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/waku"
+)
+
+func TestGetStoreNodeStatusReflectsConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	cfg.StoreNodeEnabled = true
+	cfg.StoreNodeTopics = []string{"/community/1/chat/proto"}
+	cfg.StoreNodeQuotaBytes = 4096
+	svc, err := NewServiceForDaemonWithDataDir(cfg, filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatalf("new service: %v", err)
+	}
+
+	status, err := svc.GetStoreNodeStatus()
+	if err != nil {
+		t.Fatalf("store status: %v", err)
+	}
+	if !status.Enabled || status.QuotaBytes != 4096 || len(status.Topics) != 1 || status.Topics[0] != "/community/1/chat/proto" {
+		t.Fatalf("unexpected store status: %+v", status)
+	}
+	if status.RetentionSeconds <= 0 || status.QueriesPerMinute <= 0 {
+		t.Fatalf("defaults must be applied: %+v", status)
+	}
+}
@@ -0,0 +1,70 @@
+package daemonservice
+
+import (
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// ClaimUsername sets this identity's username, or withdraws it when username
+// is empty, and sends the signed claim to every verified contact. Contacts
+// that are unreachable now pick the claim up with their next card refresh.
+func (s *Service) ClaimUsername(username string) (map[string]any, error) {
+	card, err := s.identityCore.ClaimUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	notified := 0
+	if ctx, err := s.networkContext(""); err == nil {
+		wire := messagingapp.NewUsernameClaimWire(card)
+		for _, contact := range s.identityManager.Contacts() {
+			if contact.MergedInto != "" || !s.identityManager.HasVerifiedContact(contact.ID) {
+				continue
+			}
+			if err := s.sendContactCardWire(ctx, contact.ID, wire); err != nil {
+				s.recordError(contracts.ErrorCategoryNetwork, err)
+				continue
+			}
+			notified++
+		}
+	}
+	return map[string]any{
+		"username": card.Username,
+		"notified": notified,
+	}, nil
+}
+
+func (s *Service) SetUsernameDisplay(mode string) (string, error) {
+	return s.identityCore.SetUsernameDisplay(mode)
+}
+
+// handleUsernameClaim applies a username claimed by a verified contact and
+// warns about contacts the claim puts in conflict with each other.
+func (s *Service) handleUsernameClaim(senderID string, card *models.ContactCard, now time.Time) {
+	if card == nil || card.IdentityID != senderID {
+		return
+	}
+	for _, contact := range s.identityManager.Contacts() {
+		if contact.ID == senderID && contact.Username == card.Username {
+			return
+		}
+	}
+	contact, flagged, err := s.identityCore.ApplyUsernameClaim(*card, now)
+	if err != nil {
+		s.recordError(contracts.ErrorCategoryCrypto, err)
+		return
+	}
+	s.notify("notify.contact.username_claimed", map[string]any{
+		"contact_id": contact.ID,
+		"username":   contact.Username,
+		"conflict":   contact.UsernameConflict,
+	})
+	if len(flagged) > 0 {
+		s.notify("notify.contact.username_conflict", map[string]any{
+			"username":    contact.Username,
+			"contact_ids": flagged,
+		})
+	}
+}
@@ -19,6 +19,7 @@ var (
 	ErrInvalidPeerKey    = errors.New("invalid peer key")
 	ErrInvalidContact    = errors.New("invalid contact id")
 	ErrSessionNotFound   = errors.New("session not found")
+	ErrSessionMismatch   = errors.New("session mismatch")
 	ErrReplayDetected    = errors.New("replay detected")
 	ErrInvalidChainIndex = errors.New("invalid chain index")
 )
@@ -193,7 +194,7 @@ func (m *SessionManager) Decrypt(contactID string, env MessageEnvelope) ([]byte,
 		return nil, ErrSessionNotFound
 	}
 	if env.SessionID != state.SessionID {
-		return nil, errors.New("session mismatch")
+		return nil, ErrSessionMismatch
 	}
 	if seen(state.SeenMessageIDs, env.MessageID) {
 		return nil, ErrReplayDetected
@@ -360,6 +361,13 @@ func deriveRootKey(localIdentityID, contactID string, peerPublicKey []byte) []by
 	return kdf32(input, append([]byte("aim/session/root/v1|"), salt...))
 }
 
+// DerivePairTopicSecret derives the secret both peers of a session use to
+// name their rotating transport topics. Its own KDF label keeps it unrelated
+// to any message key.
+func DerivePairTopicSecret(state SessionState) []byte {
+	return kdf32(state.RootKey, []byte("aim/topic/pair/v1"))
+}
+
 func deriveInitialChainKeys(rootKey []byte, localID, contactID string) ([]byte, []byte) {
 	a2b := kdf32(rootKey, []byte("aim/ratchet/chain/a2b/v1"))
 	b2a := kdf32(rootKey, []byte("aim/ratchet/chain/b2a/v1"))
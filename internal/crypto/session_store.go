@@ -177,9 +177,13 @@ func (s *FileSessionStore) writeAllLocked(all map[string]SessionState) error {
 	if err != nil {
 		return err
 	}
+	release, err := securestore.BeginWrite(s.secret)
+	if err != nil {
+		return err
+	}
+	defer release()
 	if s.secret != "" {
-		data, err = securestore.Encrypt(s.secret, data)
-		if err != nil {
+		if data, err = securestore.Encrypt(s.secret, data); err != nil {
 			return err
 		}
 	}
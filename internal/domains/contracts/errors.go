@@ -15,6 +15,33 @@ const (
 	ErrorCategoryNetwork = "network"
 )
 
+// Policy rejection reasons and the settings behind them, as reported in
+// PolicyRejectionError.
+const (
+	PolicyReasonBlocked     = "blocked"
+	PolicyReasonNotContact  = "not_contact"
+	PolicyReasonPrivacyMode = "privacy_mode"
+
+	PolicySettingBlocklist          = "blocklist"
+	PolicySettingContacts           = "contacts"
+	PolicySettingMessagePrivacyMode = "message_privacy_mode"
+)
+
+// RejectByPolicy wraps err with the policy that caused it. Callers keep
+// matching err with errors.Is.
+func RejectByPolicy(err error, reason, setting, remediation, contactID string) error {
+	if err == nil {
+		return nil
+	}
+	return &PolicyRejectionError{
+		Reason:      reason,
+		Setting:     setting,
+		Remediation: remediation,
+		ContactID:   contactID,
+		Err:         err,
+	}
+}
+
 func normalizeErrorCategory(category string) string {
 	switch strings.ToLower(strings.TrimSpace(category)) {
 	case ErrorCategoryCrypto:
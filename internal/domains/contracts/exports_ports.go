@@ -12,7 +12,11 @@ type NetworkAPI = contractports.NetworkAPI
 type DaemonService = contractports.DaemonService
 type NotificationEvent = contractports.NotificationEvent
 type IdentityDomain = contractports.IdentityDomain
+type PaymentVerification = contractports.PaymentVerification
+type PaymentVerifier = contractports.PaymentVerifier
+type IdentityDirectory = contractports.IdentityDirectory
 type PrivacySettingsStateStore = contractports.PrivacySettingsStateStore
 type BlocklistStateStore = contractports.BlocklistStateStore
 type CategorizedError = contractports.CategorizedError
 type DeviceRevocationDeliveryError = contractports.DeviceRevocationDeliveryError
+type PolicyRejectionError = contractports.PolicyRejectionError
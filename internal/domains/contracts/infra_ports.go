@@ -26,7 +26,10 @@ type MessageRepository interface {
 	Snapshot() (map[string]models.Message, map[string]storage.PendingMessage)
 	AddOrUpdatePending(message models.Message, retryCount int, nextRetry time.Time, lastErr string) error
 	RemovePending(messageID string) error
+	CancelPending(messageID string) (models.Message, bool, error)
+	RequeueMessage(messageID string, now time.Time) (models.Message, bool, error)
 	UpdateMessageStatus(messageID, status string) (bool, error)
+	UpdateMessageStatusAt(messageID, status string, at time.Time) (bool, error)
 	GetMessage(messageID string) (models.Message, bool)
 	UpdateMessageContent(messageID string, content []byte, contentType string) (models.Message, bool, error)
 	DeleteMessage(contactID, messageID string) (bool, error)
@@ -36,6 +39,11 @@ type MessageRepository interface {
 	ListMessagesByConversationThread(conversationID, conversationType, threadID string, limit, offset int) []models.Message
 	PendingCount() int
 	DuePending(now time.Time) []storage.PendingMessage
+	PendingForContact(contactID string) []storage.PendingMessage
+	GetPending(messageID string) (storage.PendingMessage, bool)
+	PinMessage(messageID, pinnedBy string, at time.Time) (models.MessagePin, bool, error)
+	UnpinMessage(messageID string) (bool, error)
+	ListPinnedMessages(conversationID, conversationType string) []models.PinnedMessage
 }
 
 type AttachmentRepository = contractports.AttachmentRepository
@@ -59,24 +67,46 @@ type ServiceOptions struct {
 	MessageStore    MessageRepository
 	AttachmentStore AttachmentRepository
 	Logger          *slog.Logger
+	// GenerateID mints prefixed ids for messages, receipts, revocations and
+	// other wires. Nil means random ids.
+	GenerateID func(prefix string) (string, error)
+	// Now is the service clock. Nil means time.Now.
+	Now func() time.Time
 }
 
 type WirePayload struct {
-	Kind              string                   `json:"kind"`
-	Envelope          crypto.MessageEnvelope   `json:"envelope"`
-	Plain             []byte                   `json:"plain"`
-	Padding           string                   `json:"padding,omitempty"`
-	ConversationID    string                   `json:"conversation_id,omitempty"`
-	ConversationType  string                   `json:"conversation_type,omitempty"`
-	ThreadID          string                   `json:"thread_id,omitempty"`
-	EventID           string                   `json:"event_id,omitempty"`
-	EventType         string                   `json:"event_type,omitempty"`
-	MembershipVersion uint64                   `json:"membership_version,omitempty"`
-	GroupKeyVersion   uint32                   `json:"group_key_version,omitempty"`
-	SenderDeviceID    string                   `json:"sender_device_id,omitempty"`
-	Card              *models.ContactCard      `json:"card,omitempty"`
-	Receipt           *models.MessageReceipt   `json:"receipt,omitempty"`
-	Device            *models.Device           `json:"device,omitempty"`
-	DeviceSig         []byte                   `json:"device_sig,omitempty"`
-	Revocation        *models.DeviceRevocation `json:"revocation,omitempty"`
+	Kind              string                         `json:"kind"`
+	Envelope          crypto.MessageEnvelope         `json:"envelope"`
+	Plain             []byte                         `json:"plain"`
+	Padding           string                         `json:"padding,omitempty"`
+	ConversationID    string                         `json:"conversation_id,omitempty"`
+	ConversationType  string                         `json:"conversation_type,omitempty"`
+	ThreadID          string                         `json:"thread_id,omitempty"`
+	EventID           string                         `json:"event_id,omitempty"`
+	EventType         string                         `json:"event_type,omitempty"`
+	MembershipVersion uint64                         `json:"membership_version,omitempty"`
+	GroupKeyVersion   uint32                         `json:"group_key_version,omitempty"`
+	SenderDeviceID    string                         `json:"sender_device_id,omitempty"`
+	Emergency         bool                           `json:"emergency,omitempty"`
+	Card              *models.ContactCard            `json:"card,omitempty"`
+	Receipt           *models.MessageReceipt         `json:"receipt,omitempty"`
+	Device            *models.Device                 `json:"device,omitempty"`
+	DeviceSig         []byte                         `json:"device_sig,omitempty"`
+	Revocation        *models.DeviceRevocation       `json:"revocation,omitempty"`
+	Attachments       []models.MessageAttachment     `json:"attachments,omitempty"`
+	Pow               *models.PowStamp               `json:"pow,omitempty"`
+	Payment           *models.PaymentProof           `json:"payment,omitempty"`
+	ConversationSync  []models.ConversationSyncState `json:"conversation_sync,omitempty"`
+	GroupAvatar       *models.GroupAvatar            `json:"group_avatar,omitempty"`
+	Seq               uint64                         `json:"seq,omitempty"`
+	Backfill          *models.HistoryBackfillRequest `json:"backfill,omitempty"`
+	SavedMessage      *models.SavedMessage           `json:"saved_message,omitempty"`
+	Pin               *models.MessagePinUpdate       `json:"pin,omitempty"`
+	StateHashes       *models.StateHashes            `json:"state_hashes,omitempty"`
+	ExportConsent     *models.ExportConsent          `json:"export_consent,omitempty"`
+	// ContentType names structured message content, such as a location;
+	// empty means text.
+	ContentType string `json:"content_type,omitempty"`
+	// Caps advertises the sender client's wire features.
+	Caps []string `json:"caps,omitempty"`
 }
@@ -39,9 +39,11 @@ type IdentityAPI interface {
 }
 
 // MessagingAPI is a transport-neutral direct messaging/session contract.
+// Sends take the caller's context: once it is done, delivery is left to the
+// retry queue instead of blocking the caller.
 type MessagingAPI interface {
-	SendMessage(contactID, content string) (string, error)
-	SendMessageInThread(contactID, content, threadID string) (string, error)
+	SendMessage(ctx context.Context, contactID, content string) (string, error)
+	SendMessageInThread(ctx context.Context, contactID, content, threadID string) (string, error)
 	EditMessage(contactID, messageID, content string) (models.Message, error)
 	DeleteMessage(contactID, messageID string) error
 	ClearMessages(contactID string) (int, error)
@@ -67,8 +69,8 @@ type GroupAPI interface {
 	RemoveGroupMember(groupID, memberID string) (bool, error)
 	PromoteGroupMember(groupID, memberID string) (groupdomain.GroupMember, error)
 	DemoteGroupMember(groupID, memberID string) (groupdomain.GroupMember, error)
-	SendGroupMessage(groupID, content string) (groupdomain.GroupMessageFanoutResult, error)
-	SendGroupMessageInThread(groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error)
+	SendGroupMessage(ctx context.Context, groupID, content string) (groupdomain.GroupMessageFanoutResult, error)
+	SendGroupMessageInThread(ctx context.Context, groupID, content, threadID string) (groupdomain.GroupMessageFanoutResult, error)
 	ListGroupMessages(groupID string, limit, offset int) ([]models.Message, error)
 	ListGroupMessagesByThread(groupID, threadID string, limit, offset int) ([]models.Message, error)
 	GetGroupMessageStatus(groupID, messageID string) (models.MessageStatus, error)
@@ -79,7 +81,7 @@ type GroupAPI interface {
 type InboxAPI interface {
 	ListMessageRequests() ([]models.MessageRequest, error)
 	GetMessageRequest(senderID string) (models.MessageRequestThread, error)
-	AcceptMessageRequest(senderID string) (bool, error)
+	AcceptMessageRequest(ctx context.Context, senderID string) (bool, error)
 	DeclineMessageRequest(senderID string) (bool, error)
 	BlockSender(senderID string) (models.BlockSenderResult, error)
 }
@@ -169,6 +171,31 @@ type IdentityDomain interface {
 	SnapshotIdentityKeys() (publicKey []byte, privateKey []byte)
 }
 
+// PaymentVerification asks a verifier to check a payment proof carried by an
+// inbound message.
+type PaymentVerification struct {
+	SenderID    string
+	RecipientID string
+	MessageID   string
+	Proof       models.PaymentProof
+}
+
+// PaymentVerifier is the hook for optional paid first-contact and tip
+// features. Core ships no implementation: a plugin validates proofs such as
+// paid invoices or on-chain payments and returns an error to reject them.
+type PaymentVerifier interface {
+	VerifyPayment(ctx context.Context, req PaymentVerification) error
+}
+
+// IdentityDirectory resolves identities by a human-readable name to their
+// signed contact cards. Core ships no implementation; pkg/testsupport has an
+// in-process one that scripts name the daemons of a test by.
+type IdentityDirectory interface {
+	Register(name string, card models.ContactCard) error
+	Resolve(name string) (models.ContactCard, bool)
+	Names() []string
+}
+
 type AttachmentRepository interface {
 	Put(name, mimeType string, data []byte) (models.AttachmentMeta, error)
 	Get(id string) (models.AttachmentMeta, []byte, error)
@@ -200,9 +227,9 @@ func (e *CategorizedError) Unwrap() error {
 }
 
 type DeviceRevocationDeliveryError struct {
-	Attempted int
-	Failed    int
-	Failures  map[string]string
+	Attempted int               `json:"attempted"`
+	Failed    int               `json:"failed"`
+	Failures  map[string]string `json:"failures"`
 }
 
 func (e *DeviceRevocationDeliveryError) Error() string {
@@ -221,3 +248,36 @@ func (e *DeviceRevocationDeliveryError) Error() string {
 func (e *DeviceRevocationDeliveryError) IsFullFailure() bool {
 	return e != nil && e.Attempted > 0 && e.Failed >= e.Attempted
 }
+
+func (e *DeviceRevocationDeliveryError) ErrorData() any {
+	return e
+}
+
+// PolicyRejectionError reports a send refused by a local policy. It doubles
+// as RPC error data, naming the setting behind the refusal and the RPC that
+// lifts it.
+type PolicyRejectionError struct {
+	Reason      string `json:"reason"`
+	Setting     string `json:"setting"`
+	Remediation string `json:"remediation,omitempty"`
+	ContactID   string `json:"contact_id,omitempty"`
+	Err         error  `json:"-"`
+}
+
+func (e *PolicyRejectionError) Error() string {
+	if e == nil || e.Err == nil {
+		return "rejected by policy"
+	}
+	return e.Err.Error()
+}
+
+func (e *PolicyRejectionError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+func (e *PolicyRejectionError) ErrorData() any {
+	return e
+}
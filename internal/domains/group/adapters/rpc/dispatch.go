@@ -1,16 +1,19 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	"aim-chat/go-backend/internal/domains/contracts"
 	groupdomain "aim-chat/go-backend/internal/domains/group"
 	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
 )
 
 const (
@@ -20,14 +23,25 @@ const (
 
 var channelGroupTitlePrefixRe = regexp.MustCompile(`^\[channel(?::(public|private))?]\s*`)
 
-func Dispatch(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
-	if result, rpcErr, ok := dispatchGroupRPC(service, method, rawParams); ok {
+var (
+	errChannelCommentsUnsupported = errors.New("channel comments are not supported")
+	errGroupPostingUnsupported    = errors.New("group posting restrictions are not supported")
+
+	errGroupOwnershipTransferUnsupported = errors.New("group ownership transfer is not supported")
+	errChannelStatsUnsupported           = errors.New("channel stats are not supported")
+	errChannelEmergencyUnsupported       = errors.New("channel emergency broadcasts are not supported")
+	errGroupMessagePinUnsupported        = errors.New("group message pins are not supported")
+	errGroupMemberPrivacyUnsupported     = errors.New("hidden group members are not supported")
+)
+
+func Dispatch(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	if result, rpcErr, ok := dispatchGroupRPC(ctx, service, method, rawParams); ok {
 		return result, rpcErr, true
 	}
-	return dispatchChannelRPC(service, method, rawParams)
+	return dispatchChannelRPC(ctx, service, method, rawParams)
 }
 
-func dispatchGroupRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+func dispatchGroupRPC(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
 	switch method {
 	case "group.create":
 		result, rpcErr := callWithSingleStringParam(rawParams, -32100, func(title string) (any, error) {
@@ -49,6 +63,28 @@ func dispatchGroupRPC(service contracts.DaemonService, method string, rawParams
 			return service.ListGroupMembers(groupID)
 		})
 		return result, rpcErr, true
+	case "group.history":
+		result, rpcErr := callWithMessageListParams(rawParams, -32126, func(groupID string, limit, offset int) (any, error) {
+			history, ok := service.(interface {
+				ListGroupHistory(groupID string, limit, offset int) ([]groupdomain.GroupEvent, error)
+			})
+			if !ok {
+				return nil, errors.New("group history is not supported")
+			}
+			return history.ListGroupHistory(groupID, limit, offset)
+		})
+		return result, rpcErr, true
+	case "group.activity.list":
+		result, rpcErr := callWithMessageListParams(rawParams, -32131, func(groupID string, limit, offset int) (any, error) {
+			activity, ok := service.(interface {
+				ListGroupActivity(groupID string, limit, offset int) ([]groupdomain.GroupActivity, error)
+			})
+			if !ok {
+				return nil, errors.New("group activity is not supported")
+			}
+			return activity.ListGroupActivity(groupID, limit, offset)
+		})
+		return result, rpcErr, true
 	case "group.leave":
 		result, rpcErr := callWithSingleStringParam(rawParams, -32104, func(groupID string) (any, error) {
 			left, err := service.LeaveGroup(groupID)
@@ -68,6 +104,55 @@ func dispatchGroupRPC(service contracts.DaemonService, method string, rawParams
 			return service.UpdateGroupProfile(groupID, title, description, avatar)
 		})
 		return result, rpcErr, true
+	case "group.avatar.set":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32127, func(groupID, dataBase64 string) (any, error) {
+			setter, ok := service.(interface {
+				SetGroupAvatar(groupID, dataBase64 string) (groupdomain.Group, error)
+			})
+			if !ok {
+				return nil, errors.New("group avatars are not supported")
+			}
+			return setter.SetGroupAvatar(groupID, dataBase64)
+		})
+		return result, rpcErr, true
+	case "group.avatar.get":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32128, func(groupID string) (any, error) {
+			getter, ok := service.(interface {
+				GetGroupAvatar(groupID string) (models.GroupAvatar, error)
+			})
+			if !ok {
+				return nil, errors.New("group avatars are not supported")
+			}
+			return getter.GetGroupAvatar(groupID)
+		})
+		return result, rpcErr, true
+	case "group.rules.set":
+		groupID, rules, err := decodeGroupRulesParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		setter, ok := service.(interface {
+			SetGroupRules(groupID, rules string) (groupdomain.Group, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32129, errors.New("group rules are not supported")), true
+		}
+		result, err := setter.SetGroupRules(groupID, rules)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32129, err), true
+		}
+		return result, nil, true
+	case "group.rules.ack":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32130, func(groupID string) (any, error) {
+			acker, ok := service.(interface {
+				AcknowledgeGroupRules(groupID string) (groupdomain.GroupMember, error)
+			})
+			if !ok {
+				return nil, errors.New("group rules are not supported")
+			}
+			return acker.AcknowledgeGroupRules(groupID)
+		})
+		return result, rpcErr, true
 	case "group.delete":
 		result, rpcErr := callWithSingleStringParam(rawParams, -32106, func(groupID string) (any, error) {
 			deleted, err := service.DeleteGroup(groupID)
@@ -119,29 +204,138 @@ func dispatchGroupRPC(service contracts.DaemonService, method string, rawParams
 			return service.DemoteGroupMember(groupID, memberID)
 		})
 		return result, rpcErr, true
+	case "group.block_member", "group.unblock_member":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32356, func(groupID, memberID string) (any, error) {
+			blocker, ok := service.(interface {
+				BlockGroupMember(groupID, memberID string) ([]string, error)
+				UnblockGroupMember(groupID, memberID string) ([]string, error)
+			})
+			if !ok {
+				return nil, errors.New("group member blocking is not supported")
+			}
+			update := blocker.BlockGroupMember
+			if method == "group.unblock_member" {
+				update = blocker.UnblockGroupMember
+			}
+			blocked, err := update(groupID, memberID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"group_id": groupID, "blocked": blocked}, nil
+		})
+		return result, rpcErr, true
+	case "group.member.mute":
+		groupID, memberID, until, err := decodeGroupMemberMuteParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		muter, ok := service.(interface {
+			MuteGroupMember(groupID, memberID string, until time.Time) (groupdomain.GroupMember, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32132, errGroupPostingUnsupported), true
+		}
+		result, err := muter.MuteGroupMember(groupID, memberID, until)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32132, err), true
+		}
+		return result, nil, true
+	case "group.mode.set":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32133, func(groupID, mode string) (any, error) {
+			setter, ok := service.(interface {
+				SetGroupPostingMode(groupID, mode string) (groupdomain.Group, error)
+			})
+			if !ok {
+				return nil, errGroupPostingUnsupported
+			}
+			return setter.SetGroupPostingMode(groupID, mode)
+		})
+		return result, rpcErr, true
+	case "group.members.privacy.set":
+		groupID, hidden, err := decodeGroupToggleParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		setter, ok := service.(interface {
+			SetGroupMemberPrivacy(groupID string, hidden bool) (groupdomain.Group, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32136, errGroupMemberPrivacyUnsupported), true
+		}
+		result, err := setter.SetGroupMemberPrivacy(groupID, hidden)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32136, err), true
+		}
+		return result, nil, true
+	case "group.pseudonym.resolve":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32137, func(groupID, pseudonym string) (any, error) {
+			resolver, ok := service.(interface {
+				ResolveGroupPseudonym(groupID, pseudonym string) (groupdomain.GroupMember, error)
+			})
+			if !ok {
+				return nil, errGroupMemberPrivacyUnsupported
+			}
+			return resolver.ResolveGroupPseudonym(groupID, pseudonym)
+		})
+		return result, rpcErr, true
+	case "group.transfer_ownership":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32134, func(groupID, newOwnerID string) (any, error) {
+			transferrer, ok := service.(interface {
+				TransferGroupOwnership(groupID, newOwnerID string) (groupdomain.GroupMember, error)
+			})
+			if !ok {
+				return nil, errGroupOwnershipTransferUnsupported
+			}
+			return transferrer.TransferGroupOwnership(groupID, newOwnerID)
+		})
+		return result, rpcErr, true
+	case "group.message.pin":
+		groupID, messageID, pinned, err := decodeGroupMessagePinParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := setGroupMessagePinned(service, groupID, messageID, pinned)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32135, err), true
+		}
+		return result, nil, true
 	case "group.send":
 		result, rpcErr := callWithTwoStringParams(rawParams, -32120, func(groupID, content string) (any, error) {
-			return service.SendGroupMessage(groupID, content)
+			return service.SendGroupMessage(ctx, groupID, content)
 		})
 		return result, rpcErr, true
 	case "group.thread.send":
 		result, rpcErr := callWithThreadSendParams(rawParams, -32124, func(groupID, content, threadID string) (any, error) {
-			return service.SendGroupMessageInThread(groupID, content, threadID)
+			return service.SendGroupMessageInThread(ctx, groupID, content, threadID)
 		})
 		return result, rpcErr, true
 	case "group.messages.list":
-		result, rpcErr := callWithMessageListParams(rawParams, -32121, func(groupID string, limit, offset int) (any, error) {
-			return service.ListGroupMessages(groupID, limit, offset)
-		})
-		return result, rpcErr, true
+		groupID, limit, offset, filter, err := decodeGroupMessageListParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		var result any
+		if filter == "" {
+			result, err = service.ListGroupMessages(groupID, limit, offset)
+		} else if lister, ok := service.(interface {
+			ListGroupMessagesFiltered(groupID, filter string, limit, offset int) ([]models.Message, error)
+		}); ok {
+			result, err = lister.ListGroupMessagesFiltered(groupID, filter, limit, offset)
+		} else {
+			err = errors.New("group message filters are not supported")
+		}
+		if err != nil {
+			return nil, rpckit.ServiceError(-32121, err), true
+		}
+		return result, nil, true
 	case "group.thread.list":
 		result, rpcErr := callWithThreadListParams(rawParams, -32125, func(groupID, threadID string, limit, offset int) (any, error) {
 			return service.ListGroupMessagesByThread(groupID, threadID, limit, offset)
 		})
 		return result, rpcErr, true
 	case "group.message.status":
-		result, rpcErr := callWithTwoStringParams(rawParams, -32122, func(groupID, messageID string) (any, error) {
-			return service.GetGroupMessageStatus(groupID, messageID)
+		result, rpcErr := callWithMessageStatusParams(rawParams, -32122, func(groupID, messageID string, includeMembers bool) (any, error) {
+			return getGroupMessageStatus(service, groupID, messageID, includeMembers)
 		})
 		return result, rpcErr, true
 	case "group.message.delete":
@@ -152,12 +346,128 @@ func dispatchGroupRPC(service contracts.DaemonService, method string, rawParams
 			return map[string]bool{"deleted": true}, nil
 		})
 		return result, rpcErr, true
+	case "channel.comments.set":
+		groupID, enabled, err := decodeGroupToggleParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := setChannelComments(service, groupID, enabled)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32226, err), true
+		}
+		return result, nil, true
+	case "channel.thread.lock", "channel.thread.unlock":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32227, func(groupID, threadID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			locker, ok := service.(interface {
+				SetGroupThreadLocked(groupID, threadID string, locked bool) (groupdomain.Group, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			return locker.SetGroupThreadLocked(groupID, threadID, method == "channel.thread.lock")
+		})
+		return result, rpcErr, true
+	case "channel.comment.remove":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32228, func(groupID, messageID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			remover, ok := service.(interface {
+				RemoveGroupMessage(groupID, messageID string) error
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			if err := remover.RemoveGroupMessage(groupID, messageID); err != nil {
+				return nil, err
+			}
+			return map[string]bool{"removed": true}, nil
+		})
+		return result, rpcErr, true
+	case "channel.thread.subscribe", "channel.thread.unsubscribe":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32229, func(groupID, threadID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			subscriber, ok := service.(interface {
+				SubscribeGroupThread(groupID, threadID string) ([]string, error)
+				UnsubscribeGroupThread(groupID, threadID string) ([]string, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			update := subscriber.SubscribeGroupThread
+			if method == "channel.thread.unsubscribe" {
+				update = subscriber.UnsubscribeGroupThread
+			}
+			threads, err := update(groupID, threadID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"group_id": groupID, "threads": threads}, nil
+		})
+		return result, rpcErr, true
+	case "channel.thread.subscriptions":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32230, func(groupID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			lister, ok := service.(interface {
+				ListGroupThreadSubscriptions(groupID string) ([]string, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			threads, err := lister.ListGroupThreadSubscriptions(groupID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"group_id": groupID, "threads": threads}, nil
+		})
+		return result, rpcErr, true
+	case "channel.emergency.prepare":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32232, func(groupID, content string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			preparer, ok := service.(interface {
+				PrepareChannelEmergencyBroadcast(groupID, content string) (groupdomain.EmergencyBroadcastConfirmation, error)
+			})
+			if !ok {
+				return nil, errChannelEmergencyUnsupported
+			}
+			return preparer.PrepareChannelEmergencyBroadcast(groupID, content)
+		})
+		return result, rpcErr, true
+	case "channel.emergency.send":
+		groupID, content, token, err := decodeEmergencySendParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := sendChannelEmergencyBroadcast(ctx, service, groupID, content, token)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32233, err), true
+		}
+		return result, nil, true
+	case "channel.stats":
+		groupID, limit, err := decodeChannelStatsParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := getChannelStats(service, groupID, limit)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32231, err), true
+		}
+		return result, nil, true
 	default:
 		return nil, nil, false
 	}
 }
 
-func dispatchChannelRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+func dispatchChannelRPC(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
 	switch method {
 	case "channel.create":
 		result, rpcErr := callWithChannelCreateParams(rawParams, -32200, func(name, visibility, description string) (any, error) {
@@ -307,12 +617,12 @@ func dispatchChannelRPC(service contracts.DaemonService, method string, rawParam
 		return result, rpcErr, true
 	case "channel.send":
 		result, rpcErr := callWithTwoStringParams(rawParams, -32220, func(groupID, content string) (any, error) {
-			return service.SendGroupMessage(groupID, content)
+			return service.SendGroupMessage(ctx, groupID, content)
 		})
 		return result, rpcErr, true
 	case "channel.thread.send":
 		result, rpcErr := callWithThreadSendParams(rawParams, -32224, func(groupID, content, threadID string) (any, error) {
-			return service.SendGroupMessageInThread(groupID, content, threadID)
+			return service.SendGroupMessageInThread(ctx, groupID, content, threadID)
 		})
 		return result, rpcErr, true
 	case "channel.messages.list":
@@ -332,11 +642,11 @@ func dispatchChannelRPC(service contracts.DaemonService, method string, rawParam
 		})
 		return result, rpcErr, true
 	case "channel.message.status":
-		result, rpcErr := callWithTwoStringParams(rawParams, -32222, func(groupID, messageID string) (any, error) {
+		result, rpcErr := callWithMessageStatusParams(rawParams, -32222, func(groupID, messageID string, includeMembers bool) (any, error) {
 			if _, err := ensureChannelGroup(service, groupID); err != nil {
 				return nil, err
 			}
-			return service.GetGroupMessageStatus(groupID, messageID)
+			return getGroupMessageStatus(service, groupID, messageID, includeMembers)
 		})
 		return result, rpcErr, true
 	case "channel.message.delete":
@@ -350,6 +660,88 @@ func dispatchChannelRPC(service contracts.DaemonService, method string, rawParam
 			return map[string]bool{"deleted": true}, nil
 		})
 		return result, rpcErr, true
+	case "channel.comments.set":
+		groupID, enabled, err := decodeGroupToggleParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := setChannelComments(service, groupID, enabled)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32226, err), true
+		}
+		return result, nil, true
+	case "channel.thread.lock", "channel.thread.unlock":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32227, func(groupID, threadID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			locker, ok := service.(interface {
+				SetGroupThreadLocked(groupID, threadID string, locked bool) (groupdomain.Group, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			return locker.SetGroupThreadLocked(groupID, threadID, method == "channel.thread.lock")
+		})
+		return result, rpcErr, true
+	case "channel.comment.remove":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32228, func(groupID, messageID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			remover, ok := service.(interface {
+				RemoveGroupMessage(groupID, messageID string) error
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			if err := remover.RemoveGroupMessage(groupID, messageID); err != nil {
+				return nil, err
+			}
+			return map[string]bool{"removed": true}, nil
+		})
+		return result, rpcErr, true
+	case "channel.thread.subscribe", "channel.thread.unsubscribe":
+		result, rpcErr := callWithTwoStringParams(rawParams, -32229, func(groupID, threadID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			subscriber, ok := service.(interface {
+				SubscribeGroupThread(groupID, threadID string) ([]string, error)
+				UnsubscribeGroupThread(groupID, threadID string) ([]string, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			update := subscriber.SubscribeGroupThread
+			if method == "channel.thread.unsubscribe" {
+				update = subscriber.UnsubscribeGroupThread
+			}
+			threads, err := update(groupID, threadID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"group_id": groupID, "threads": threads}, nil
+		})
+		return result, rpcErr, true
+	case "channel.thread.subscriptions":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32230, func(groupID string) (any, error) {
+			if _, err := ensureChannelGroup(service, groupID); err != nil {
+				return nil, err
+			}
+			lister, ok := service.(interface {
+				ListGroupThreadSubscriptions(groupID string) ([]string, error)
+			})
+			if !ok {
+				return nil, errChannelCommentsUnsupported
+			}
+			threads, err := lister.ListGroupThreadSubscriptions(groupID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"group_id": groupID, "threads": threads}, nil
+		})
+		return result, rpcErr, true
 	default:
 		return nil, nil, false
 	}
@@ -387,6 +779,22 @@ func callWithTwoStringParams(rawParams json.RawMessage, serviceErrCode int, call
 	return result, nil
 }
 
+func callWithMessageStatusParams(
+	rawParams json.RawMessage,
+	serviceErrCode int,
+	call func(groupID, messageID string, includeMembers bool) (any, error),
+) (any, *rpckit.Error) {
+	groupID, messageID, includeMembers, err := decodeMessageStatusParams(rawParams)
+	if err != nil {
+		return nil, rpckit.InvalidParams()
+	}
+	result, err := call(groupID, messageID, includeMembers)
+	if err != nil {
+		return nil, rpckit.ServiceError(serviceErrCode, err)
+	}
+	return result, nil
+}
+
 func callWithFourStringParams(rawParams json.RawMessage, serviceErrCode int, call func(string, string, string, string) (any, error)) (any, *rpckit.Error) {
 	a, b, c, d, err := decodeFourStringParams(rawParams)
 	if err != nil {
@@ -475,6 +883,140 @@ func decodeTwoStringParams(raw json.RawMessage) (string, string, error) {
 	return "", "", errors.New("invalid params")
 }
 
+// decodeGroupRulesParams accepts [group_id, rules]; empty rules clear the
+// current text.
+func decodeGroupRulesParams(raw json.RawMessage) (string, string, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 || arr[0] == "" {
+		return "", "", errors.New("invalid params")
+	}
+	return arr[0], arr[1], nil
+}
+
+// decodeGroupMemberMuteParams accepts [group_id, member_id, muted_until]
+// with muted_until in RFC 3339; an empty muted_until unmutes.
+func decodeGroupMemberMuteParams(raw json.RawMessage) (string, string, time.Time, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 3 {
+		return "", "", time.Time{}, errors.New("invalid params")
+	}
+	groupID, memberID := strings.TrimSpace(arr[0]), strings.TrimSpace(arr[1])
+	if groupID == "" || memberID == "" {
+		return "", "", time.Time{}, errors.New("invalid params")
+	}
+	var until time.Time
+	if raw := strings.TrimSpace(arr[2]); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return "", "", time.Time{}, errors.New("invalid params")
+		}
+		until = parsed
+	}
+	return groupID, memberID, until, nil
+}
+
+// decodeGroupToggleParams accepts [group_id, enabled].
+func decodeGroupToggleParams(raw json.RawMessage) (string, bool, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 {
+		return "", false, errors.New("invalid params")
+	}
+	groupID, ok := arr[0].(string)
+	if !ok || strings.TrimSpace(groupID) == "" {
+		return "", false, errors.New("invalid params")
+	}
+	enabled, ok := arr[1].(bool)
+	if !ok {
+		return "", false, errors.New("invalid params")
+	}
+	return strings.TrimSpace(groupID), enabled, nil
+}
+
+// decodeChannelStatsParams accepts [group_id] with an optional trailing
+// limit on the number of recent broadcasts to report.
+func decodeChannelStatsParams(raw json.RawMessage) (string, int, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) < 1 || len(arr) > 2 {
+		return "", 0, errors.New("invalid params")
+	}
+	groupID, ok := arr[0].(string)
+	if !ok || strings.TrimSpace(groupID) == "" {
+		return "", 0, errors.New("invalid params")
+	}
+	limit := groupdomain.DefaultChannelStatsBroadcasts
+	if len(arr) == 2 {
+		value, err := decodeStrictNonNegativeInt(arr[1])
+		if err != nil || value == 0 || value > groupdomain.MaxChannelStatsBroadcasts {
+			return "", 0, errors.New("invalid params")
+		}
+		limit = value
+	}
+	return strings.TrimSpace(groupID), limit, nil
+}
+
+// decodeEmergencySendParams accepts [group_id, content, confirmation_token].
+func decodeEmergencySendParams(raw json.RawMessage) (string, string, string, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 3 {
+		return "", "", "", errors.New("invalid params")
+	}
+	groupID := strings.TrimSpace(arr[0])
+	content := strings.TrimSpace(arr[1])
+	token := strings.TrimSpace(arr[2])
+	if groupID == "" || content == "" || token == "" {
+		return "", "", "", errors.New("invalid params")
+	}
+	return groupID, content, token, nil
+}
+
+// decodeGroupMessagePinParams accepts [group_id, message_id] with an
+// optional trailing pinned flag; false unpins.
+func decodeGroupMessagePinParams(raw json.RawMessage) (string, string, bool, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) < 2 || len(arr) > 3 {
+		return "", "", false, errors.New("invalid params")
+	}
+	groupID, ok := arr[0].(string)
+	if !ok || strings.TrimSpace(groupID) == "" {
+		return "", "", false, errors.New("invalid params")
+	}
+	messageID, ok := arr[1].(string)
+	if !ok || strings.TrimSpace(messageID) == "" {
+		return "", "", false, errors.New("invalid params")
+	}
+	pinned := true
+	if len(arr) == 3 {
+		if pinned, ok = arr[2].(bool); !ok {
+			return "", "", false, errors.New("invalid params")
+		}
+	}
+	return strings.TrimSpace(groupID), strings.TrimSpace(messageID), pinned, nil
+}
+
+// decodeMessageStatusParams accepts [group_id, message_id] with an optional
+// trailing include_members flag.
+func decodeMessageStatusParams(raw json.RawMessage) (string, string, bool, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) < 2 || len(arr) > 3 {
+		return "", "", false, errors.New("invalid params")
+	}
+	groupID, ok := arr[0].(string)
+	if !ok || groupID == "" {
+		return "", "", false, errors.New("invalid params")
+	}
+	messageID, ok := arr[1].(string)
+	if !ok || messageID == "" {
+		return "", "", false, errors.New("invalid params")
+	}
+	includeMembers := false
+	if len(arr) == 3 {
+		if includeMembers, ok = arr[2].(bool); !ok {
+			return "", "", false, errors.New("invalid params")
+		}
+	}
+	return groupID, messageID, includeMembers, nil
+}
+
 func decodeFourStringParams(raw json.RawMessage) (string, string, string, string, error) {
 	var arr []string
 	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 4 || arr[0] == "" || arr[1] == "" {
@@ -506,6 +1048,31 @@ func decodeMessageListParams(raw json.RawMessage) (string, int, int, error) {
 	return contactID, limit, offset, nil
 }
 
+// decodeGroupMessageListParams accepts [group_id, limit, offset] with an
+// optional trailing timeline filter.
+func decodeGroupMessageListParams(raw json.RawMessage) (string, int, int, string, error) {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || (len(arr) != 3 && len(arr) != 4) {
+		return "", 0, 0, "", errors.New("invalid params")
+	}
+	filter := ""
+	if len(arr) == 4 {
+		if err := json.Unmarshal(arr[3], &filter); err != nil {
+			return "", 0, 0, "", errors.New("invalid params")
+		}
+		arr = arr[:3]
+	}
+	head, err := json.Marshal(arr)
+	if err != nil {
+		return "", 0, 0, "", errors.New("invalid params")
+	}
+	groupID, limit, offset, err := decodeMessageListParams(head)
+	if err != nil {
+		return "", 0, 0, "", err
+	}
+	return groupID, limit, offset, filter, nil
+}
+
 func decodeThreadSendParams(raw json.RawMessage) (string, string, string, error) {
 	var arr []string
 	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 3 {
@@ -632,6 +1199,76 @@ func channelVisibilityFromTitle(title string) string {
 	return "public"
 }
 
+func setChannelComments(service contracts.DaemonService, groupID string, enabled bool) (groupdomain.Group, error) {
+	if _, err := ensureChannelGroup(service, groupID); err != nil {
+		return groupdomain.Group{}, err
+	}
+	setter, ok := service.(interface {
+		SetChannelComments(groupID string, enabled bool) (groupdomain.Group, error)
+	})
+	if !ok {
+		return groupdomain.Group{}, errChannelCommentsUnsupported
+	}
+	return setter.SetChannelComments(groupID, enabled)
+}
+
+func setGroupMessagePinned(service contracts.DaemonService, groupID, messageID string, pinned bool) (any, error) {
+	pinner, ok := service.(interface {
+		PinGroupMessage(groupID, messageID string) (models.MessagePin, error)
+		UnpinGroupMessage(groupID, messageID string) (bool, error)
+	})
+	if !ok {
+		return nil, errGroupMessagePinUnsupported
+	}
+	if pinned {
+		return pinner.PinGroupMessage(groupID, messageID)
+	}
+	removed, err := pinner.UnpinGroupMessage(groupID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]bool{"unpinned": removed}, nil
+}
+
+func sendChannelEmergencyBroadcast(ctx context.Context, service contracts.DaemonService, groupID, content, token string) (groupdomain.GroupMessageFanoutResult, error) {
+	if _, err := ensureChannelGroup(service, groupID); err != nil {
+		return groupdomain.GroupMessageFanoutResult{}, err
+	}
+	sender, ok := service.(interface {
+		SendChannelEmergencyBroadcast(ctx context.Context, groupID, content, token string) (groupdomain.GroupMessageFanoutResult, error)
+	})
+	if !ok {
+		return groupdomain.GroupMessageFanoutResult{}, errChannelEmergencyUnsupported
+	}
+	return sender.SendChannelEmergencyBroadcast(ctx, groupID, content, token)
+}
+
+func getChannelStats(service contracts.DaemonService, groupID string, limit int) (groupdomain.ChannelStats, error) {
+	if _, err := ensureChannelGroup(service, groupID); err != nil {
+		return groupdomain.ChannelStats{}, err
+	}
+	reader, ok := service.(interface {
+		GetChannelStats(groupID string, limit int) (groupdomain.ChannelStats, error)
+	})
+	if !ok {
+		return groupdomain.ChannelStats{}, errChannelStatsUnsupported
+	}
+	return reader.GetChannelStats(groupID, limit)
+}
+
+func getGroupMessageStatus(service contracts.DaemonService, groupID, messageID string, includeMembers bool) (any, error) {
+	if !includeMembers {
+		return service.GetGroupMessageStatus(groupID, messageID)
+	}
+	receipts, ok := service.(interface {
+		GetGroupMessageReceipts(groupID, messageID string, includeMembers bool) (models.MessageStatus, error)
+	})
+	if !ok {
+		return nil, errors.New("per-member message receipts are not supported")
+	}
+	return receipts.GetGroupMessageReceipts(groupID, messageID, true)
+}
+
 func ensureChannelGroup(service contracts.DaemonService, groupID string) (groupdomain.Group, error) {
 	group, err := service.GetGroup(groupID)
 	if err != nil {
@@ -0,0 +1,98 @@
+package group
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyGroupEventChannelComments(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := NewGroupState(Group{ID: "channel-1", Title: "[channel:public] news", CreatedBy: "aim1owner", CreatedAt: now})
+	state.Members["aim1owner"] = GroupMember{GroupID: "channel-1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}
+	state.Members["aim1sub"] = GroupMember{GroupID: "channel-1", MemberID: "aim1sub", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive}
+
+	apply := func(evt GroupEvent) {
+		t.Helper()
+		evt.GroupID = "channel-1"
+		evt.ActorID = "aim1owner"
+		evt.Version = state.Version + 1
+		evt.OccurredAt = now.Add(time.Duration(evt.Version) * time.Second)
+		if _, err := ApplyGroupEvent(&state, evt); err != nil {
+			t.Fatalf("apply event %s failed: %v", evt.ID, err)
+		}
+	}
+	owner, sub := state.Members["aim1owner"], state.Members["aim1sub"]
+
+	if err := ValidateChannelPost(state.Group, sub, "post-1"); !errors.Is(err, ErrGroupPermissionDenied) {
+		t.Fatalf("comments must be off by default, got %v", err)
+	}
+	apply(GroupEvent{ID: "evt-1", Type: GroupEventTypeCommentsChange, CommentsEnabled: true})
+	if err := ValidateChannelPost(state.Group, sub, "post-1"); err != nil {
+		t.Fatalf("subscriber must be able to comment, got %v", err)
+	}
+	if err := ValidateChannelPost(state.Group, sub, ""); !errors.Is(err, ErrGroupPermissionDenied) {
+		t.Fatalf("main feed must stay admin-only, got %v", err)
+	}
+
+	apply(GroupEvent{ID: "evt-2", Type: GroupEventTypeThreadLock, ThreadID: "post-1", ThreadLocked: true})
+	if !state.Group.IsThreadLocked("post-1") || state.Group.IsThreadLocked("post-2") {
+		t.Fatalf("unexpected locked threads: %v", state.Group.LockedThreads)
+	}
+	if err := ValidateChannelPost(state.Group, sub, "post-1"); !errors.Is(err, ErrGroupThreadLocked) {
+		t.Fatalf("locked thread must reject comments, got %v", err)
+	}
+	if err := ValidateChannelPost(state.Group, owner, "post-1"); err != nil {
+		t.Fatalf("owner must be able to post in a locked thread, got %v", err)
+	}
+
+	apply(GroupEvent{ID: "evt-3", Type: GroupEventTypeThreadLock, ThreadID: "post-1"})
+	if len(state.Group.LockedThreads) != 0 {
+		t.Fatalf("unlock must clear the thread, got %v", state.Group.LockedThreads)
+	}
+	apply(GroupEvent{ID: "evt-4", Type: GroupEventTypeMessageRemove, MessageEventID: "gevtmsg-1"})
+	if state.Version != 4 {
+		t.Fatalf("message removal must be versioned, got version %d", state.Version)
+	}
+
+	if err := ValidateChannelPost(Group{ID: "group-1", Title: "friends"}, sub, ""); err != nil {
+		t.Fatalf("regular groups must not restrict posting, got %v", err)
+	}
+}
+
+func TestDecodeInboundGroupEventChannelModeration(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []GroupEvent{
+		{ID: "evt-1", GroupID: "channel-1", Version: 2, Type: GroupEventTypeCommentsChange, ActorID: "aim1owner", OccurredAt: now, CommentsEnabled: true},
+		{ID: "evt-2", GroupID: "channel-1", Version: 3, Type: GroupEventTypeThreadLock, ActorID: "aim1owner", OccurredAt: now, ThreadID: "post-1", ThreadLocked: true},
+		{ID: "evt-3", GroupID: "channel-1", Version: 4, Type: GroupEventTypeMessageRemove, ActorID: "aim1owner", OccurredAt: now, MessageEventID: "gevtmsg-1"},
+	}
+	for _, in := range events {
+		plain, err := EncodeGroupEventPlain(in)
+		if err != nil {
+			t.Fatalf("encode %s failed: %v", in.Type, err)
+		}
+		out, err := DecodeInboundGroupEvent(InboundGroupEventWire{
+			EventID:           in.ID,
+			ConversationID:    in.GroupID,
+			MembershipVersion: in.Version,
+			EventType:         string(in.Type),
+			Plain:             plain,
+			SenderID:          in.ActorID,
+		}, now)
+		if err != nil {
+			t.Fatalf("decode %s failed: %v", in.Type, err)
+		}
+		if out.CommentsEnabled != in.CommentsEnabled || out.ThreadID != in.ThreadID ||
+			out.ThreadLocked != in.ThreadLocked || out.MessageEventID != in.MessageEventID {
+			t.Fatalf("round trip mismatch for %s: %+v", in.Type, out)
+		}
+	}
+
+	if err := ValidateGroupEvent(GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeThreadLock, ActorID: "a", OccurredAt: now}); !errors.Is(err, ErrInvalidGroupEventPayload) {
+		t.Fatalf("thread lock without thread must be rejected, got %v", err)
+	}
+	if err := ValidateGroupEvent(GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeMessageRemove, ActorID: "a", OccurredAt: now}); !errors.Is(err, ErrInvalidGroupEventPayload) {
+		t.Fatalf("message removal without message must be rejected, got %v", err)
+	}
+}
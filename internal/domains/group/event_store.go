@@ -0,0 +1,466 @@
+package group
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+const (
+	DefaultGroupSnapshotEvery = 64
+
+	maxGroupLogLineBytes = 16 << 20
+)
+
+var ErrInvalidGroupLogRecord = errors.New("group event log record is invalid")
+
+type groupLogRecordKind string
+
+const (
+	groupLogRecordCheckpoint groupLogRecordKind = "checkpoint"
+	groupLogRecordEvent      groupLogRecordKind = "event"
+	groupLogRecordDelete     groupLogRecordKind = "delete"
+)
+
+// groupLogRecord is one entry of the append-only group log. Events carry the
+// domain history; checkpoints capture state changes that events alone do not
+// describe, such as group creation, so that replay stays deterministic.
+type groupLogRecord struct {
+	Seq        uint64             `json:"seq"`
+	Kind       groupLogRecordKind `json:"kind"`
+	GroupID    string             `json:"group_id"`
+	State      *GroupState        `json:"state,omitempty"`
+	Event      *GroupEvent        `json:"event,omitempty"`
+	RecordedAt time.Time          `json:"recorded_at"`
+}
+
+// EventSourcedStore persists group state as an encrypted append-only event
+// log plus periodic snapshots. It is a drop-in alternative to SnapshotStore:
+// the snapshot file keeps the same format and records how much of the log it
+// covers, so bootstrap only replays the log tail. The log is never truncated
+// and serves as the audit trail of membership history.
+type EventSourcedStore struct {
+	mu            sync.Mutex
+	path          string
+	logPath       string
+	secret        string
+	snapshotEvery int
+	seq           uint64
+	sinceSnapshot int
+	known         map[string]GroupState
+	knownEvents   map[string]int
+}
+
+func NewEventSourcedStore(snapshotEvery int) *EventSourcedStore {
+	if snapshotEvery <= 0 {
+		snapshotEvery = DefaultGroupSnapshotEvery
+	}
+	return &EventSourcedStore{
+		snapshotEvery: snapshotEvery,
+		known:         map[string]GroupState{},
+		knownEvents:   map[string]int{},
+	}
+}
+
+// GroupEventLogPath derives the log location from the snapshot path.
+func GroupEventLogPath(snapshotPath string) string {
+	snapshotPath = strings.TrimSpace(snapshotPath)
+	if snapshotPath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(snapshotPath, filepath.Ext(snapshotPath)) + ".events.log"
+}
+
+func (s *EventSourcedStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+	s.logPath = GroupEventLogPath(s.path)
+}
+
+func (s *EventSourcedStore) Bootstrap() (map[string]GroupState, map[string][]GroupEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLocked()
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return map[string]GroupState{}, map[string][]GroupEvent{}, nil
+	}
+
+	states := map[string]GroupState{}
+	eventLog := map[string][]GroupEvent{}
+	var offset int64
+	snapshotMissing := false
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	switch {
+	case err == nil:
+		var snapshot persistedGroupState
+		if err := json.Unmarshal(plaintext, &snapshot); err != nil {
+			return nil, nil, err
+		}
+		if snapshot.Version != 1 {
+			return nil, nil, errors.New("group state persistence payload is invalid")
+		}
+		states, eventLog, err = NormalizeGroupSnapshot(snapshot.States, snapshot.EventLog)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.seq = snapshot.LogSeq
+		offset = snapshot.LogOffset
+	case errors.Is(err, fs.ErrNotExist):
+		snapshotMissing = true
+	default:
+		return nil, nil, err
+	}
+
+	tail, err := s.readLogTailLocked(offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	replayed := 0
+	for _, record := range tail {
+		if record.Seq <= s.seq {
+			continue
+		}
+		if err := replayGroupLogRecord(states, eventLog, record); err != nil {
+			return nil, nil, err
+		}
+		s.seq = record.Seq
+		replayed++
+	}
+	states, eventLog, err = NormalizeGroupSnapshot(states, eventLog)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.rememberLocked(states, eventLog)
+	if snapshotMissing || replayed > 0 {
+		if err := s.writeSnapshotLocked(states, eventLog); err != nil {
+			return nil, nil, err
+		}
+	}
+	return states, eventLog, nil
+}
+
+// Persist appends the difference between the given state and the last
+// persisted one to the log and compacts it into a snapshot every
+// snapshotEvery records.
+func (s *EventSourcedStore) Persist(states map[string]GroupState, eventLog map[string][]GroupEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	normalizedStates, normalizedEvents, err := NormalizeGroupSnapshot(states, eventLog)
+	if err != nil {
+		return err
+	}
+	records := s.diffLocked(normalizedStates, normalizedEvents, time.Now().UTC())
+	if len(records) == 0 {
+		return nil
+	}
+	if err := s.appendLogLocked(records); err != nil {
+		return err
+	}
+	s.rememberLocked(normalizedStates, normalizedEvents)
+	s.sinceSnapshot += len(records)
+	if s.sinceSnapshot >= s.snapshotEvery {
+		// The log already holds these records, so a failed compaction only
+		// lengthens the next replay and must not fail the mutation.
+		_ = s.writeSnapshotLocked(normalizedStates, normalizedEvents)
+	}
+	return nil
+}
+
+func (s *EventSourcedStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLocked()
+	var wipeErr error
+	for _, path := range []string{s.path, s.logPath} {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			wipeErr = errors.Join(wipeErr, err)
+		}
+	}
+	return wipeErr
+}
+
+func (s *EventSourcedStore) resetLocked() {
+	s.seq = 0
+	s.sinceSnapshot = 0
+	s.known = map[string]GroupState{}
+	s.knownEvents = map[string]int{}
+}
+
+func (s *EventSourcedStore) rememberLocked(states map[string]GroupState, eventLog map[string][]GroupEvent) {
+	s.known = cloneGroupStates(states)
+	s.knownEvents = make(map[string]int, len(eventLog))
+	for groupID, events := range eventLog {
+		s.knownEvents[groupID] = len(events)
+	}
+}
+
+// diffLocked turns the transition from the last persisted state into log
+// records. New events are replayed against the previous state; when the
+// result differs from the target state a checkpoint is written first, which
+// makes the following events no-ops during replay.
+func (s *EventSourcedStore) diffLocked(states map[string]GroupState, eventLog map[string][]GroupEvent, now time.Time) []groupLogRecord {
+	groupIDs := make([]string, 0, len(states))
+	for groupID := range states {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	records := make([]groupLogRecord, 0)
+	for _, groupID := range groupIDs {
+		state := states[groupID]
+		events := eventLog[groupID]
+		previous, wasKnown := s.known[groupID]
+		start := s.knownEvents[groupID]
+		if start > len(events) {
+			// History shrank without a delete; restart the group so replay
+			// does not duplicate events that are already logged.
+			records = append(records, groupLogRecord{Kind: groupLogRecordDelete, GroupID: groupID, RecordedAt: now})
+			wasKnown = false
+		}
+		if !wasKnown {
+			start = 0
+		}
+
+		consistent := wasKnown
+		if consistent {
+			replayed := CloneState(previous)
+			for _, event := range events[start:] {
+				if _, err := ApplyGroupEvent(&replayed, event); err != nil {
+					consistent = false
+					break
+				}
+			}
+			consistent = consistent && sameGroupState(replayed, state)
+		}
+		if !consistent {
+			checkpoint := CloneState(state)
+			records = append(records, groupLogRecord{Kind: groupLogRecordCheckpoint, GroupID: groupID, State: &checkpoint, RecordedAt: now})
+		}
+		for i := range events[start:] {
+			event := events[start+i]
+			records = append(records, groupLogRecord{Kind: groupLogRecordEvent, GroupID: groupID, Event: &event, RecordedAt: now})
+		}
+	}
+
+	deleted := make([]string, 0)
+	for groupID := range s.known {
+		if _, ok := states[groupID]; !ok {
+			deleted = append(deleted, groupID)
+		}
+	}
+	sort.Strings(deleted)
+	for _, groupID := range deleted {
+		records = append(records, groupLogRecord{Kind: groupLogRecordDelete, GroupID: groupID, RecordedAt: now})
+	}
+	return records
+}
+
+func (s *EventSourcedStore) appendLogLocked(records []groupLogRecord) error {
+	previousSeq := s.seq
+	for i := range records {
+		s.seq++
+		records[i].Seq = s.seq
+	}
+	err := s.writeLogLineLocked(records)
+	if err != nil {
+		s.seq = previousSeq
+	}
+	return err
+}
+
+func (s *EventSourcedStore) writeLogLineLocked(records []groupLogRecord) error {
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	release, err := securestore.BeginWrite(s.secret)
+	if err != nil {
+		return err
+	}
+	defer release()
+	encrypted, err := securestore.Encrypt(s.secret, payload)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.logPath), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(base64.StdEncoding.EncodeToString(encrypted) + "\n"); err != nil {
+		_ = f.Truncate(info.Size())
+		return err
+	}
+	return f.Sync()
+}
+
+// readLogTailLocked decodes log lines starting at offset. A torn final line
+// left by a crash during append is cut off so later appends start on a clean
+// line; corruption elsewhere is an error.
+func (s *EventSourcedStore) readLogTailLocked(offset int64) ([]groupLogRecord, error) {
+	f, err := os.OpenFile(s.logPath, os.O_RDWR, 0o600)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || offset > info.Size() {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	out := make([]groupLogRecord, 0)
+	reader := bufio.NewReader(f)
+	position := offset
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > maxGroupLogLineBytes {
+			return nil, fmt.Errorf("%w: line exceeds %d bytes", ErrInvalidGroupLogRecord, maxGroupLogLineBytes)
+		}
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, readErr
+		}
+		lineStart := position
+		position += int64(len(line))
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			records, err := s.decodeLogLine(trimmed)
+			if err != nil {
+				if errors.Is(readErr, io.EOF) || isLastLogLine(reader) {
+					if truncErr := f.Truncate(lineStart); truncErr != nil {
+						return nil, truncErr
+					}
+					break
+				}
+				return nil, fmt.Errorf("%w: %v", ErrInvalidGroupLogRecord, err)
+			}
+			out = append(out, records...)
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+	}
+	return out, nil
+}
+
+func isLastLogLine(reader *bufio.Reader) bool {
+	_, err := reader.Peek(1)
+	return errors.Is(err, io.EOF)
+}
+
+func (s *EventSourcedStore) decodeLogLine(line []byte) ([]groupLogRecord, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return nil, err
+	}
+	payload, err := securestore.Decrypt(s.secret, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	var records []groupLogRecord
+	if err := json.Unmarshal(payload, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (s *EventSourcedStore) writeSnapshotLocked(states map[string]GroupState, eventLog map[string][]GroupEvent) error {
+	var offset int64
+	if info, err := os.Stat(s.logPath); err == nil {
+		offset = info.Size()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err := securestore.WriteEncryptedJSON(s.path, s.secret, persistedGroupState{
+		Version:   1,
+		States:    states,
+		EventLog:  eventLog,
+		LogSeq:    s.seq,
+		LogOffset: offset,
+	})
+	if err != nil {
+		return err
+	}
+	s.sinceSnapshot = 0
+	return nil
+}
+
+func replayGroupLogRecord(states map[string]GroupState, eventLog map[string][]GroupEvent, record groupLogRecord) error {
+	groupID := strings.TrimSpace(record.GroupID)
+	if groupID == "" {
+		return ErrInvalidGroupLogRecord
+	}
+	switch record.Kind {
+	case groupLogRecordCheckpoint:
+		if record.State == nil || record.State.Group.ID != groupID {
+			return ErrInvalidGroupLogRecord
+		}
+		states[groupID] = CloneState(*record.State)
+	case groupLogRecordEvent:
+		if record.Event == nil || record.Event.GroupID != groupID {
+			return ErrInvalidGroupLogRecord
+		}
+		eventLog[groupID] = append(eventLog[groupID], *record.Event)
+		state, ok := states[groupID]
+		if !ok {
+			return ErrInvalidGroupLogRecord
+		}
+		if _, err := ApplyGroupEvent(&state, *record.Event); err != nil {
+			return err
+		}
+		states[groupID] = state
+	case groupLogRecordDelete:
+		delete(states, groupID)
+		delete(eventLog, groupID)
+	default:
+		return ErrInvalidGroupLogRecord
+	}
+	return nil
+}
+
+func sameGroupState(a, b GroupState) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(left, right)
+}
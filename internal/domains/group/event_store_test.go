@@ -0,0 +1,148 @@
+package group
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newEventSourcedMembership(t *testing.T, store *EventSourcedStore) *MembershipService {
+	t.Helper()
+	states, eventLog, err := store.Bootstrap()
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	seq := 0
+	return &MembershipService{
+		States:   states,
+		EventLog: eventLog,
+		Persist:  store.Persist,
+		GenerateEventID: func() string {
+			seq++
+			return fmt.Sprintf("evt-%d", seq)
+		},
+	}
+}
+
+func TestEventSourcedStoreReplaysLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.enc")
+	store := NewEventSourcedStore(1000)
+	store.Configure(path, "test-secret")
+	ms := newEventSourcedMembership(t, store)
+
+	ids := 0
+	generateID := func(prefix string) (string, error) {
+		ids++
+		return fmt.Sprintf("%s-%d", prefix, ids), nil
+	}
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	group, _, err := ms.CreateGroup("alpha", "aim1owner", now, generateID)
+	if err != nil {
+		t.Fatalf("create group failed: %v", err)
+	}
+	if _, _, err := ms.InviteToGroup(group.ID, "aim1owner", "aim1user", now.Add(time.Second), nil, nil); err != nil {
+		t.Fatalf("invite failed: %v", err)
+	}
+	if _, _, err := ms.UpdateGroupTitle(group.ID, "aim1owner", "beta", now.Add(2*time.Second), nil); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	doomed, _, err := ms.CreateGroup("doomed", "aim1owner", now, generateID)
+	if err != nil {
+		t.Fatalf("create second group failed: %v", err)
+	}
+	if _, err := ms.DeleteGroup(doomed.ID, "aim1owner", now.Add(3*time.Second), nil); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := os.Stat(GroupEventLogPath(path)); err != nil {
+		t.Fatalf("expected event log file, err=%v", err)
+	}
+
+	reload := NewEventSourcedStore(1000)
+	reload.Configure(path, "test-secret")
+	states, eventLog, err := reload.Bootstrap()
+	if err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected deleted group to stay deleted, got %d groups", len(states))
+	}
+	if !sameGroupState(states[group.ID], ms.States[group.ID]) {
+		t.Fatalf("replayed state differs:\n got=%+v\nwant=%+v", states[group.ID], ms.States[group.ID])
+	}
+	if len(eventLog[group.ID]) != 3 {
+		t.Fatalf("expected three events in history, got %+v", eventLog[group.ID])
+	}
+}
+
+func TestEventSourcedStoreCompactsIntoSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.enc")
+	store := NewEventSourcedStore(1)
+	store.Configure(path, "test-secret")
+	ms := newEventSourcedMembership(t, store)
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	group, _, err := ms.CreateGroup("alpha", "aim1owner", now, func(prefix string) (string, error) { return prefix + "-1", nil })
+	if err != nil {
+		t.Fatalf("create group failed: %v", err)
+	}
+	if _, _, err := ms.UpdateGroupTitle(group.ID, "aim1owner", "beta", now.Add(time.Second), nil); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+
+	// A snapshot-mode reader sees the compacted state without the log.
+	snapshotOnly := NewSnapshotStore()
+	snapshotOnly.Configure(path, "test-secret")
+	states, eventLog, err := snapshotOnly.Bootstrap()
+	if err != nil {
+		t.Fatalf("snapshot bootstrap failed: %v", err)
+	}
+	if states[group.ID].Group.Title != "beta" || len(eventLog[group.ID]) != 2 {
+		t.Fatalf("snapshot is not compacted: state=%+v events=%d", states[group.ID].Group, len(eventLog[group.ID]))
+	}
+}
+
+func TestEventSourcedStoreIgnoresTornFinalLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.enc")
+	store := NewEventSourcedStore(1000)
+	store.Configure(path, "test-secret")
+	ms := newEventSourcedMembership(t, store)
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	group, _, err := ms.CreateGroup("alpha", "aim1owner", now, func(prefix string) (string, error) { return prefix + "-1", nil })
+	if err != nil {
+		t.Fatalf("create group failed: %v", err)
+	}
+	f, err := os.OpenFile(GroupEventLogPath(path), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open log failed: %v", err)
+	}
+	_, _ = f.WriteString("QUlNRU5DMQp7InZlcnNpb24i")
+	_ = f.Close()
+
+	reload := NewEventSourcedStore(1000)
+	reload.Configure(path, "test-secret")
+	states, eventLog, err := reload.Bootstrap()
+	if err != nil {
+		t.Fatalf("bootstrap must tolerate a torn final line: %v", err)
+	}
+	if _, ok := states[group.ID]; !ok {
+		t.Fatal("expected group to be restored from the log")
+	}
+
+	// Appends after recovery must start on a clean line.
+	ms = &MembershipService{States: states, EventLog: eventLog, Persist: reload.Persist, GenerateEventID: func() string { return "evt-2" }}
+	if _, _, err := ms.UpdateGroupTitle(group.ID, "aim1owner", "beta", now.Add(time.Second), nil); err != nil {
+		t.Fatalf("rename after recovery failed: %v", err)
+	}
+	final := NewEventSourcedStore(1000)
+	final.Configure(path, "test-secret")
+	states, _, err = final.Bootstrap()
+	if err != nil {
+		t.Fatalf("bootstrap after recovery failed: %v", err)
+	}
+	if states[group.ID].Group.Title != "beta" {
+		t.Fatalf("unexpected title after recovery: %q", states[group.ID].Group.Title)
+	}
+}
@@ -1,7 +1,11 @@
 //goland:noinspection GoNameStartsWithPackageName
 package group
 
-import groupmodel "aim-chat/go-backend/internal/domains/group/model"
+import (
+	"time"
+
+	groupmodel "aim-chat/go-backend/internal/domains/group/model"
+)
 
 //goland:noinspection GoNameStartsWithPackageName
 type GroupMemberRole = groupmodel.GroupMemberRole
@@ -24,6 +28,11 @@ const (
 	GroupMemberStatusRemoved = groupmodel.GroupMemberStatusRemoved
 )
 
+const (
+	GroupMemberBlockScopeGlobal = groupmodel.GroupMemberBlockScopeGlobal
+	GroupMemberBlockScopeGroup  = groupmodel.GroupMemberBlockScopeGroup
+)
+
 var (
 	ErrInvalidGroupID                     = groupmodel.ErrInvalidGroupID
 	ErrInvalidGroupMemberID               = groupmodel.ErrInvalidGroupMemberID
@@ -36,14 +45,46 @@ var (
 	ErrInvalidGroupEventActorID           = groupmodel.ErrInvalidGroupEventActorID
 	ErrInvalidGroupEventPayload           = groupmodel.ErrInvalidGroupEventPayload
 	ErrOutOfOrderGroupEvent               = groupmodel.ErrOutOfOrderGroupEvent
+	ErrInvalidGroupRules                  = groupmodel.ErrInvalidGroupRules
+	ErrGroupRulesNotAcknowledged          = groupmodel.ErrGroupRulesNotAcknowledged
+	ErrInvalidGroupMessageFilter          = groupmodel.ErrInvalidGroupMessageFilter
+	ErrInvalidGroupMessageContent         = groupmodel.ErrInvalidGroupMessageContent
+	ErrGroupPermissionDenied              = groupmodel.ErrGroupPermissionDenied
+	ErrGroupMembershipNotFound            = groupmodel.ErrGroupMembershipNotFound
+	ErrGroupMemberBlocked                 = groupmodel.ErrGroupMemberBlocked
+	ErrGroupNotChannel                    = groupmodel.ErrGroupNotChannel
+	ErrGroupThreadLocked                  = groupmodel.ErrGroupThreadLocked
+	ErrInvalidGroupThreadID               = groupmodel.ErrInvalidGroupThreadID
+	ErrGroupMemberMuted                   = groupmodel.ErrGroupMemberMuted
+	ErrGroupPostingRestricted             = groupmodel.ErrGroupPostingRestricted
+	ErrInvalidGroupPostingMode            = groupmodel.ErrInvalidGroupPostingMode
+	ErrInvalidGroupMuteUntil              = groupmodel.ErrInvalidGroupMuteUntil
+	ErrInvalidGroupOwnershipTarget        = groupmodel.ErrInvalidGroupOwnershipTarget
+	ErrInvalidEmergencyConfirmation       = groupmodel.ErrInvalidEmergencyConfirmation
+	ErrGroupPseudonymNotFound             = groupmodel.ErrGroupPseudonymNotFound
 )
 
+const MaxGroupRulesLength = groupmodel.MaxGroupRulesLength
+
 //goland:noinspection GoNameStartsWithPackageName
 type Group = groupmodel.Group
 
 //goland:noinspection GoNameStartsWithPackageName
 type GroupMember = groupmodel.GroupMember
 
+//goland:noinspection GoNameStartsWithPackageName
+type GroupPostingMode = groupmodel.GroupPostingMode
+
+//goland:noinspection GoNameStartsWithPackageName
+const (
+	GroupPostingModeAll        = groupmodel.GroupPostingModeAll
+	GroupPostingModeAdminsOnly = groupmodel.GroupPostingModeAdminsOnly
+)
+
+func ParseGroupPostingMode(raw string) (GroupPostingMode, error) {
+	return groupmodel.ParseGroupPostingMode(raw)
+}
+
 func NormalizeGroupMemberID(memberID string) (string, error) {
 	return groupmodel.NormalizeGroupMemberID(memberID)
 }
@@ -69,12 +110,23 @@ type GroupEventType = groupmodel.GroupEventType
 
 //goland:noinspection GoNameStartsWithPackageName
 const (
-	GroupEventTypeMemberAdd     = groupmodel.GroupEventTypeMemberAdd
-	GroupEventTypeMemberRemove  = groupmodel.GroupEventTypeMemberRemove
-	GroupEventTypeMemberLeave   = groupmodel.GroupEventTypeMemberLeave
-	GroupEventTypeTitleChange   = groupmodel.GroupEventTypeTitleChange
-	GroupEventTypeProfileChange = groupmodel.GroupEventTypeProfileChange
-	GroupEventTypeKeyRotate     = groupmodel.GroupEventTypeKeyRotate
+	GroupEventTypeMemberAdd      = groupmodel.GroupEventTypeMemberAdd
+	GroupEventTypeMemberRemove   = groupmodel.GroupEventTypeMemberRemove
+	GroupEventTypeMemberLeave    = groupmodel.GroupEventTypeMemberLeave
+	GroupEventTypeTitleChange    = groupmodel.GroupEventTypeTitleChange
+	GroupEventTypeProfileChange  = groupmodel.GroupEventTypeProfileChange
+	GroupEventTypeKeyRotate      = groupmodel.GroupEventTypeKeyRotate
+	GroupEventTypeRulesChange    = groupmodel.GroupEventTypeRulesChange
+	GroupEventTypeRulesAck       = groupmodel.GroupEventTypeRulesAck
+	GroupEventTypeCommentsChange = groupmodel.GroupEventTypeCommentsChange
+	GroupEventTypeThreadLock     = groupmodel.GroupEventTypeThreadLock
+	GroupEventTypeMessageRemove  = groupmodel.GroupEventTypeMessageRemove
+
+	GroupEventTypeMemberMute        = groupmodel.GroupEventTypeMemberMute
+	GroupEventTypePostingModeChange = groupmodel.GroupEventTypePostingModeChange
+	GroupEventTypeOwnershipTransfer = groupmodel.GroupEventTypeOwnershipTransfer
+
+	GroupEventTypeMemberPrivacyChange = groupmodel.GroupEventTypeMemberPrivacyChange
 )
 
 //goland:noinspection GoNameStartsWithPackageName
@@ -95,6 +147,28 @@ func ValidateGroupEvent(event GroupEvent) error {
 	return groupmodel.ValidateGroupEvent(event)
 }
 
+func ValidateChannelPost(group Group, member GroupMember, threadID string) error {
+	return groupmodel.ValidateChannelPost(group, member, threadID)
+}
+
+func ValidateOwnershipTransfer(state GroupState, actorID, newOwnerID string) error {
+	return groupmodel.ValidateOwnershipTransfer(state, actorID, newOwnerID)
+}
+
+func ValidateMemberPost(group Group, member GroupMember, now time.Time) error {
+	return groupmodel.ValidateMemberPost(group, member, now)
+}
+
+const GroupPseudonymPrefix = groupmodel.GroupPseudonymPrefix
+
+func MemberPseudonym(group Group, memberID string) string {
+	return groupmodel.MemberPseudonym(group, memberID)
+}
+
+func VisibleGroupMembers(group Group, members []GroupMember, viewerID string) []GroupMember {
+	return groupmodel.VisibleGroupMembers(group, members, viewerID)
+}
+
 func ApplyGroupEvent(state *GroupState, event GroupEvent) (bool, error) {
 	return groupmodel.ApplyGroupEvent(state, event)
 }
@@ -104,3 +178,65 @@ type GroupMessageRecipientStatus = groupmodel.GroupMessageRecipientStatus
 
 //goland:noinspection GoNameStartsWithPackageName
 type GroupMessageFanoutResult = groupmodel.GroupMessageFanoutResult
+
+//goland:noinspection GoNameStartsWithPackageName
+type GroupMessageDeliveryError = groupmodel.GroupMessageDeliveryError
+
+type ChannelStats = groupmodel.ChannelStats
+
+type ChannelBroadcastStats = groupmodel.ChannelBroadcastStats
+
+const (
+	DefaultChannelStatsBroadcasts = groupmodel.DefaultChannelStatsBroadcasts
+	MaxChannelStatsBroadcasts     = groupmodel.MaxChannelStatsBroadcasts
+)
+
+type EmergencyBroadcastConfirmation = groupmodel.EmergencyBroadcastConfirmation
+
+const EmergencyBroadcastConfirmationTTL = groupmodel.EmergencyBroadcastConfirmationTTL
+
+//goland:noinspection GoNameStartsWithPackageName
+type GroupMessageFilter = groupmodel.GroupMessageFilter
+
+//goland:noinspection GoNameStartsWithPackageName
+const (
+	GroupMessageFilterAll      = groupmodel.GroupMessageFilterAll
+	GroupMessageFilterMessages = groupmodel.GroupMessageFilterMessages
+	GroupMessageFilterSystem   = groupmodel.GroupMessageFilterSystem
+)
+
+func ParseGroupMessageFilter(raw string) (GroupMessageFilter, error) {
+	return groupmodel.ParseGroupMessageFilter(raw)
+}
+
+//goland:noinspection GoNameStartsWithPackageName
+type GroupActivityKind = groupmodel.GroupActivityKind
+
+//goland:noinspection GoNameStartsWithPackageName
+const (
+	GroupActivityKindCreated       = groupmodel.GroupActivityKindCreated
+	GroupActivityKindMemberInvited = groupmodel.GroupActivityKindMemberInvited
+	GroupActivityKindMemberJoined  = groupmodel.GroupActivityKindMemberJoined
+	GroupActivityKindMemberLeft    = groupmodel.GroupActivityKindMemberLeft
+	GroupActivityKindMemberRemoved = groupmodel.GroupActivityKindMemberRemoved
+	GroupActivityKindPromoted      = groupmodel.GroupActivityKindPromoted
+	GroupActivityKindDemoted       = groupmodel.GroupActivityKindDemoted
+	GroupActivityKindTitleChanged  = groupmodel.GroupActivityKindTitleChanged
+	GroupActivityKindRulesChanged  = groupmodel.GroupActivityKindRulesChanged
+	GroupActivityKindMemberMuted   = groupmodel.GroupActivityKindMemberMuted
+	GroupActivityKindMemberUnmuted = groupmodel.GroupActivityKindMemberUnmuted
+	GroupActivityKindPostingMode   = groupmodel.GroupActivityKindPostingMode
+	GroupActivityKindOwnership     = groupmodel.GroupActivityKindOwnership
+	GroupActivityKindMemberPrivacy = groupmodel.GroupActivityKindMemberPrivacy
+)
+
+//goland:noinspection GoNameStartsWithPackageName
+type GroupActivity = groupmodel.GroupActivity
+
+func DescribeGroupActivity(before GroupState, event GroupEvent) (GroupActivity, bool) {
+	return groupmodel.DescribeGroupActivity(before, event)
+}
+
+func BuildGroupActivityFeed(groupID string, events []GroupEvent) []GroupActivity {
+	return groupmodel.BuildGroupActivityFeed(groupID, events)
+}
@@ -2,6 +2,7 @@ package group
 
 import (
 	grouppolicy "aim-chat/go-backend/internal/domains/group/policy"
+	"aim-chat/go-backend/pkg/models"
 	"time"
 )
 
@@ -24,3 +25,39 @@ func BuildReplayGuardKey(kind, groupID, senderDeviceID, uniqueID string) (string
 func ValidateReplayOccurredAt(occurredAt, now time.Time) error {
 	return grouppolicy.ValidateReplayOccurredAt(occurredAt, now)
 }
+
+const (
+	GroupAvatarMaxDimension = grouppolicy.GroupAvatarMaxDimension
+	MaxGroupAvatarBytes     = grouppolicy.MaxGroupAvatarBytes
+)
+
+var (
+	ErrInvalidGroupAvatar      = grouppolicy.ErrInvalidGroupAvatar
+	ErrGroupAvatarHashMismatch = grouppolicy.ErrGroupAvatarHashMismatch
+)
+
+func NormalizeGroupAvatar(data []byte) (models.GroupAvatar, error) {
+	return grouppolicy.NormalizeGroupAvatar(data)
+}
+
+func GroupAvatarRef(hash string) string {
+	return grouppolicy.GroupAvatarRef(hash)
+}
+
+func ParseGroupAvatarRef(avatar string) (string, bool) {
+	return grouppolicy.ParseGroupAvatarRef(avatar)
+}
+
+func VerifyGroupAvatar(avatar models.GroupAvatar, ref string) error {
+	return grouppolicy.VerifyGroupAvatar(avatar, ref)
+}
+
+func BuildGroupSystemMessage(activity GroupActivity) models.Message {
+	return grouppolicy.BuildGroupSystemMessage(activity)
+}
+
+const GroupFanoutTransportContentType = grouppolicy.GroupFanoutTransportContentType
+
+func DeriveRecipientMessageID(eventID, recipientID string) string {
+	return grouppolicy.DeriveRecipientMessageID(eventID, recipientID)
+}
@@ -0,0 +1,49 @@
+package group
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildGroupActivityFeedDescribesMembershipChanges(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []GroupEvent{
+		{Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Title: "Book club"},
+		{Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1bob", Role: GroupMemberRoleUser},
+		{Type: GroupEventTypeKeyRotate, ActorID: "aim1owner", KeyVersion: 2},
+		{Type: GroupEventTypeMemberAdd, ActorID: "aim1bob", MemberID: "aim1bob", Role: GroupMemberRoleUser},
+		{Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1bob", Role: GroupMemberRoleAdmin},
+		{Type: GroupEventTypeProfileChange, ActorID: "aim1owner", Title: "Book club", Avatar: "sha256:abc"},
+		{Type: GroupEventTypeProfileChange, ActorID: "aim1bob", Title: "Readers"},
+		{Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1bob", Role: GroupMemberRoleUser},
+		{Type: GroupEventTypeMemberLeave, ActorID: "aim1bob", MemberID: "aim1bob"},
+	}
+	for i := range events {
+		events[i].ID = "evt-" + string(rune('a'+i))
+		events[i].GroupID = "group-1"
+		events[i].Version = uint64(i + 1)
+		events[i].OccurredAt = now.Add(time.Duration(i) * time.Second)
+	}
+
+	feed := BuildGroupActivityFeed("group-1", events)
+	want := []struct {
+		kind GroupActivityKind
+		text string
+	}{
+		{GroupActivityKindCreated, `aim1owner created the group "Book club"`},
+		{GroupActivityKindMemberInvited, "aim1owner invited aim1bob"},
+		{GroupActivityKindMemberJoined, "aim1bob joined the group"},
+		{GroupActivityKindPromoted, "aim1owner promoted aim1bob to admin"},
+		{GroupActivityKindTitleChanged, `aim1bob changed the group title to "Readers"`},
+		{GroupActivityKindDemoted, "aim1owner demoted aim1bob to user"},
+		{GroupActivityKindMemberLeft, "aim1bob left the group"},
+	}
+	if len(feed) != len(want) {
+		t.Fatalf("unexpected feed length: got=%d want=%d (%+v)", len(feed), len(want), feed)
+	}
+	for i, entry := range feed {
+		if entry.Kind != want[i].kind || entry.Text != want[i].text {
+			t.Fatalf("entry %d: got kind=%s text=%q, want kind=%s text=%q", i, entry.Kind, entry.Text, want[i].kind, want[i].text)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package group
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestNormalizeGroupAvatarResizesAndVerifiesHash(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 300, 900))
+	for y := 0; y < 900; y++ {
+		for x := 0; x < 300; x++ {
+			src.Set(x, y, color.NRGBA{R: 0x20, G: uint8(y), B: uint8(x), A: uint8(x % 256)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	avatar, err := NormalizeGroupAvatar(buf.Bytes())
+	if err != nil {
+		t.Fatalf("normalize avatar: %v", err)
+	}
+	if avatar.Width != 85 || avatar.Height != GroupAvatarMaxDimension || avatar.MimeType != "image/jpeg" {
+		t.Fatalf("unexpected avatar geometry: %dx%d %s", avatar.Width, avatar.Height, avatar.MimeType)
+	}
+	if len(avatar.Data) > MaxGroupAvatarBytes {
+		t.Fatalf("avatar exceeds size bound: %d", len(avatar.Data))
+	}
+
+	ref := GroupAvatarRef(avatar.SHA256)
+	if hash, ok := ParseGroupAvatarRef(ref); !ok || hash != avatar.SHA256 {
+		t.Fatalf("ref must round-trip, got %q %v", hash, ok)
+	}
+	if err := VerifyGroupAvatar(avatar, ref); err != nil {
+		t.Fatalf("verify avatar: %v", err)
+	}
+	tampered := avatar
+	tampered.Data = append(append([]byte(nil), avatar.Data...), 0)
+	if err := VerifyGroupAvatar(tampered, ref); !errors.Is(err, ErrGroupAvatarHashMismatch) {
+		t.Fatalf("expected hash mismatch, got %v", err)
+	}
+	if err := VerifyGroupAvatar(avatar, "https://example.invalid/a.png"); !errors.Is(err, ErrInvalidGroupAvatar) {
+		t.Fatalf("expected invalid ref, got %v", err)
+	}
+	if _, err := NormalizeGroupAvatar([]byte("not an image")); !errors.Is(err, ErrInvalidGroupAvatar) {
+		t.Fatalf("expected invalid avatar, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package group
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyGroupEventRulesRequireAcknowledgment(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := NewGroupState(Group{ID: "group-1", Title: "group", CreatedBy: "aim1owner", CreatedAt: now})
+	state.Members["aim1owner"] = GroupMember{GroupID: "group-1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}
+
+	apply := func(evt GroupEvent) {
+		t.Helper()
+		evt.GroupID = "group-1"
+		evt.Version = state.Version + 1
+		evt.OccurredAt = now.Add(time.Duration(evt.Version) * time.Second)
+		if _, err := ApplyGroupEvent(&state, evt); err != nil {
+			t.Fatalf("apply event %s failed: %v", evt.ID, err)
+		}
+	}
+
+	apply(GroupEvent{ID: "evt-1", Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1user", Role: GroupMemberRoleUser})
+	apply(GroupEvent{ID: "evt-2", Type: GroupEventTypeRulesChange, ActorID: "aim1owner", Rules: "be kind", RulesVersion: 1})
+
+	if state.Group.Rules != "be kind" || state.Group.RulesVersion != 1 {
+		t.Fatalf("unexpected rules state: %+v", state.Group)
+	}
+	if state.Members["aim1owner"].NeedsRulesAck(state.Group) {
+		t.Fatal("rules author must be acknowledged implicitly")
+	}
+	if !state.Members["aim1user"].NeedsRulesAck(state.Group) {
+		t.Fatal("member must acknowledge new rules")
+	}
+
+	apply(GroupEvent{ID: "evt-3", Type: GroupEventTypeRulesAck, ActorID: "aim1user", RulesVersion: 1})
+	if state.Members["aim1user"].NeedsRulesAck(state.Group) {
+		t.Fatal("member must not need acknowledgment after rules_ack")
+	}
+
+	apply(GroupEvent{ID: "evt-4", Type: GroupEventTypeRulesChange, ActorID: "aim1owner", Rules: "be very kind", RulesVersion: 2})
+	if !state.Members["aim1user"].NeedsRulesAck(state.Group) {
+		t.Fatal("rules change must re-trigger acknowledgment")
+	}
+
+	apply(GroupEvent{ID: "evt-5", Type: GroupEventTypeRulesChange, ActorID: "aim1owner", RulesVersion: 3})
+	if state.Members["aim1user"].NeedsRulesAck(state.Group) {
+		t.Fatal("cleared rules must not require acknowledgment")
+	}
+}
+
+func TestValidateGroupEventRules(t *testing.T) {
+	now := time.Now().UTC()
+	tooLong := make([]byte, MaxGroupRulesLength+1)
+	for i := range tooLong {
+		tooLong[i] = 'a'
+	}
+	cases := []struct {
+		name string
+		in   GroupEvent
+	}{
+		{name: "rules change without version", in: GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeRulesChange, ActorID: "a", OccurredAt: now, Rules: "x"}},
+		{name: "rules change too long", in: GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeRulesChange, ActorID: "a", OccurredAt: now, Rules: string(tooLong), RulesVersion: 1}},
+		{name: "rules ack without version", in: GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeRulesAck, ActorID: "a", OccurredAt: now}},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateGroupEvent(tc.in); !errors.Is(err, ErrInvalidGroupEventPayload) {
+				t.Fatalf("expected ErrInvalidGroupEventPayload, got %v", err)
+			}
+		})
+	}
+}
@@ -7,13 +7,26 @@ import (
 )
 
 type inboundGroupEventPayload struct {
-	MemberID    string `json:"member_id"`
-	Role        string `json:"role"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Avatar      string `json:"avatar"`
-	KeyVersion  uint32 `json:"key_version"`
-	OccurredAt  string `json:"occurred_at"`
+	MemberID     string `json:"member_id"`
+	Role         string `json:"role"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Avatar       string `json:"avatar"`
+	Rules        string `json:"rules,omitempty"`
+	RulesVersion uint64 `json:"rules_version,omitempty"`
+	KeyVersion   uint32 `json:"key_version"`
+	OccurredAt   string `json:"occurred_at"`
+
+	CommentsEnabled bool   `json:"comments_enabled,omitempty"`
+	ThreadID        string `json:"thread_id,omitempty"`
+	ThreadLocked    bool   `json:"thread_locked,omitempty"`
+	MessageEventID  string `json:"message_event_id,omitempty"`
+
+	MutedUntil  string `json:"muted_until,omitempty"`
+	PostingMode string `json:"posting_mode,omitempty"`
+
+	HiddenMembers bool   `json:"hidden_members,omitempty"`
+	PseudonymSalt string `json:"pseudonym_salt,omitempty"`
 }
 
 type InboundGroupEventWire struct {
@@ -26,6 +39,40 @@ type InboundGroupEventWire struct {
 	RecipientID       string
 }
 
+// EncodeGroupEventPlain builds the wire plain body DecodeInboundGroupEvent
+// reads back on the receiving side.
+func EncodeGroupEventPlain(event GroupEvent) ([]byte, error) {
+	return json.Marshal(inboundGroupEventPayload{
+		MemberID:     event.MemberID,
+		Role:         string(event.Role),
+		Title:        event.Title,
+		Description:  event.Description,
+		Avatar:       event.Avatar,
+		Rules:        event.Rules,
+		RulesVersion: event.RulesVersion,
+		KeyVersion:   event.KeyVersion,
+		OccurredAt:   event.OccurredAt.UTC().Format(time.RFC3339Nano),
+
+		CommentsEnabled: event.CommentsEnabled,
+		ThreadID:        event.ThreadID,
+		ThreadLocked:    event.ThreadLocked,
+		MessageEventID:  event.MessageEventID,
+
+		MutedUntil:  formatGroupEventTime(event.MutedUntil),
+		PostingMode: string(event.PostingMode),
+
+		HiddenMembers: event.HiddenMembers,
+		PseudonymSalt: event.PseudonymSalt,
+	})
+}
+
+func formatGroupEventTime(at time.Time) string {
+	if at.IsZero() {
+		return ""
+	}
+	return at.UTC().Format(time.RFC3339Nano)
+}
+
 func DecodeInboundGroupEvent(
 	wire InboundGroupEventWire,
 	occurredAt time.Time,
@@ -41,17 +88,29 @@ func DecodeInboundGroupEvent(
 		}
 	}
 	event := GroupEvent{
-		ID:          strings.TrimSpace(wire.EventID),
-		GroupID:     strings.TrimSpace(wire.ConversationID),
-		Version:     wire.MembershipVersion,
-		Type:        eventType,
-		ActorID:     strings.TrimSpace(wire.SenderID),
-		OccurredAt:  occurredAt,
-		MemberID:    strings.TrimSpace(details.MemberID),
-		Title:       strings.TrimSpace(details.Title),
-		Description: strings.TrimSpace(details.Description),
-		Avatar:      strings.TrimSpace(details.Avatar),
-		KeyVersion:  details.KeyVersion,
+		ID:           strings.TrimSpace(wire.EventID),
+		GroupID:      strings.TrimSpace(wire.ConversationID),
+		Version:      wire.MembershipVersion,
+		Type:         eventType,
+		ActorID:      strings.TrimSpace(wire.SenderID),
+		OccurredAt:   occurredAt,
+		MemberID:     strings.TrimSpace(details.MemberID),
+		Title:        strings.TrimSpace(details.Title),
+		Description:  strings.TrimSpace(details.Description),
+		Avatar:       strings.TrimSpace(details.Avatar),
+		Rules:        strings.TrimSpace(details.Rules),
+		RulesVersion: details.RulesVersion,
+		KeyVersion:   details.KeyVersion,
+
+		CommentsEnabled: details.CommentsEnabled,
+		ThreadID:        strings.TrimSpace(details.ThreadID),
+		ThreadLocked:    details.ThreadLocked,
+		MessageEventID:  strings.TrimSpace(details.MessageEventID),
+
+		PostingMode: GroupPostingMode(strings.TrimSpace(details.PostingMode)),
+
+		HiddenMembers: details.HiddenMembers,
+		PseudonymSalt: strings.TrimSpace(details.PseudonymSalt),
 	}
 	if parsedRole, err := ParseGroupMemberRole(details.Role); err == nil {
 		event.Role = parsedRole
@@ -61,6 +120,13 @@ func DecodeInboundGroupEvent(
 			event.OccurredAt = parsed.UTC()
 		}
 	}
+	if ts := strings.TrimSpace(details.MutedUntil); ts != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return GroupEvent{}, ErrInvalidGroupEventPayload
+		}
+		event.MutedUntil = parsed.UTC()
+	}
 	switch event.Type {
 	case GroupEventTypeMemberLeave:
 		if event.MemberID == "" {
@@ -0,0 +1,69 @@
+package group
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyGroupEventMemberPrivacy(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := NewGroupState(Group{ID: "group-1", Title: "support", CreatedBy: "aim1owner", CreatedAt: now})
+	state.Members["aim1owner"] = GroupMember{GroupID: "group-1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}
+	state.Members["aim1admin"] = GroupMember{GroupID: "group-1", MemberID: "aim1admin", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusActive}
+	state.Members["aim1user"] = GroupMember{GroupID: "group-1", MemberID: "aim1user", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive}
+
+	apply := func(evt GroupEvent) {
+		t.Helper()
+		evt.GroupID = "group-1"
+		evt.ActorID = "aim1owner"
+		evt.Version = state.Version + 1
+		evt.OccurredAt = now
+		if _, err := ApplyGroupEvent(&state, evt); err != nil {
+			t.Fatalf("apply event %s failed: %v", evt.ID, err)
+		}
+	}
+
+	if MemberPseudonym(state.Group, "aim1user") != "" {
+		t.Fatal("groups that never hid members must have no pseudonyms")
+	}
+	if err := ValidateGroupEvent(GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeMemberPrivacyChange, ActorID: "a", OccurredAt: now, HiddenMembers: true}); err == nil {
+		t.Fatal("hiding members without a salt must be rejected")
+	}
+	apply(GroupEvent{ID: "evt-1", Type: GroupEventTypeMemberPrivacyChange, HiddenMembers: true, PseudonymSalt: "salt-1"})
+	pseudonym := MemberPseudonym(state.Group, "aim1user")
+	if !strings.HasPrefix(pseudonym, GroupPseudonymPrefix) || pseudonym == MemberPseudonym(state.Group, "aim1admin") {
+		t.Fatalf("unexpected pseudonym %q", pseudonym)
+	}
+
+	members := []GroupMember{state.Members["aim1admin"], state.Members["aim1owner"], state.Members["aim1user"]}
+	if visible := VisibleGroupMembers(state.Group, members, "aim1user"); len(visible) != 1 || visible[0].MemberID != "aim1user" {
+		t.Fatalf("members must only see themselves, got %+v", visible)
+	}
+	if visible := VisibleGroupMembers(state.Group, members, "aim1admin"); len(visible) != 3 {
+		t.Fatalf("admins must see every member, got %+v", visible)
+	}
+
+	// A later salt must not change anyone's pseudonym.
+	apply(GroupEvent{ID: "evt-2", Type: GroupEventTypeMemberPrivacyChange})
+	apply(GroupEvent{ID: "evt-3", Type: GroupEventTypeMemberPrivacyChange, HiddenMembers: true, PseudonymSalt: "salt-2"})
+	if MemberPseudonym(state.Group, "aim1user") != pseudonym {
+		t.Fatal("pseudonyms must stay stable across privacy changes")
+	}
+
+	plain, err := EncodeGroupEventPlain(GroupEvent{ID: "evt-4", GroupID: "group-1", Version: 5, Type: GroupEventTypeMemberPrivacyChange, ActorID: "aim1owner", OccurredAt: now, HiddenMembers: true, PseudonymSalt: "salt-1"})
+	if err != nil {
+		t.Fatalf("encode event: %v", err)
+	}
+	out, err := DecodeInboundGroupEvent(InboundGroupEventWire{
+		EventID:           "evt-4",
+		ConversationID:    "group-1",
+		MembershipVersion: 5,
+		EventType:         string(GroupEventTypeMemberPrivacyChange),
+		Plain:             plain,
+		SenderID:          "aim1owner",
+	}, now)
+	if err != nil || !out.HiddenMembers || out.PseudonymSalt != "salt-1" {
+		t.Fatalf("round trip mismatch: %+v %v", out, err)
+	}
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type GroupActivityKind string
+
+const (
+	GroupActivityKindCreated       GroupActivityKind = "group_created"
+	GroupActivityKindMemberInvited GroupActivityKind = "member_invited"
+	GroupActivityKindMemberJoined  GroupActivityKind = "member_joined"
+	GroupActivityKindMemberLeft    GroupActivityKind = "member_left"
+	GroupActivityKindMemberRemoved GroupActivityKind = "member_removed"
+	GroupActivityKindPromoted      GroupActivityKind = "member_promoted"
+	GroupActivityKindDemoted       GroupActivityKind = "member_demoted"
+	GroupActivityKindTitleChanged  GroupActivityKind = "title_changed"
+	GroupActivityKindRulesChanged  GroupActivityKind = "rules_changed"
+	GroupActivityKindMemberMuted   GroupActivityKind = "member_muted"
+	GroupActivityKindMemberUnmuted GroupActivityKind = "member_unmuted"
+	GroupActivityKindPostingMode   GroupActivityKind = "posting_mode_changed"
+	GroupActivityKindOwnership     GroupActivityKind = "ownership_transferred"
+	GroupActivityKindMemberPrivacy GroupActivityKind = "member_privacy_changed"
+)
+
+// GroupActivity is a human-facing view of a group event, used for the
+// activity feed and for system messages in the chat timeline.
+type GroupActivity struct {
+	EventID    string            `json:"event_id"`
+	GroupID    string            `json:"group_id"`
+	Version    uint64            `json:"version"`
+	Kind       GroupActivityKind `json:"kind"`
+	ActorID    string            `json:"actor_id"`
+	MemberID   string            `json:"member_id,omitempty"`
+	Role       GroupMemberRole   `json:"role,omitempty"`
+	Title      string            `json:"title,omitempty"`
+	MutedUntil time.Time         `json:"muted_until,omitempty"`
+	// PostingMode is set on posting_mode_changed.
+	PostingMode GroupPostingMode `json:"posting_mode,omitempty"`
+	// HiddenMembers is set on member_privacy_changed.
+	HiddenMembers bool      `json:"hidden_members,omitempty"`
+	Text          string    `json:"text"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// DescribeGroupActivity explains event against the state it was applied to.
+// Events without a visible effect (key rotations, acknowledgments, no-op
+// profile edits) report false.
+func DescribeGroupActivity(before GroupState, event GroupEvent) (GroupActivity, bool) {
+	activity := GroupActivity{
+		EventID:    event.ID,
+		GroupID:    event.GroupID,
+		Version:    event.Version,
+		ActorID:    strings.TrimSpace(event.ActorID),
+		MemberID:   strings.TrimSpace(event.MemberID),
+		OccurredAt: event.OccurredAt.UTC(),
+	}
+	switch event.Type {
+	case GroupEventTypeMemberAdd:
+		member, exists := before.Members[activity.MemberID]
+		switch {
+		case event.Version == 1 && activity.ActorID == activity.MemberID:
+			activity.Kind = GroupActivityKindCreated
+			activity.MemberID = ""
+			activity.Title = strings.TrimSpace(event.Title)
+		case exists && member.Status == GroupMemberStatusActive:
+			if member.Role == event.Role {
+				return GroupActivity{}, false
+			}
+			activity.Role = event.Role
+			activity.Kind = GroupActivityKindDemoted
+			if event.Role == GroupMemberRoleAdmin || event.Role == GroupMemberRoleOwner {
+				activity.Kind = GroupActivityKindPromoted
+			}
+		case activity.ActorID == activity.MemberID:
+			activity.Kind = GroupActivityKindMemberJoined
+		default:
+			activity.Kind = GroupActivityKindMemberInvited
+		}
+	case GroupEventTypeMemberLeave:
+		activity.Kind = GroupActivityKindMemberLeft
+	case GroupEventTypeMemberRemove:
+		activity.Kind = GroupActivityKindMemberRemoved
+	case GroupEventTypeTitleChange, GroupEventTypeProfileChange:
+		title := strings.TrimSpace(event.Title)
+		if title == "" || title == before.Group.Title {
+			return GroupActivity{}, false
+		}
+		// Logs written before creation events carried the title cannot tell
+		// whether the first profile edit renamed the group.
+		if event.Type == GroupEventTypeProfileChange && before.Group.Title == "" {
+			return GroupActivity{}, false
+		}
+		activity.Kind = GroupActivityKindTitleChanged
+		activity.Title = title
+		activity.MemberID = ""
+	case GroupEventTypeRulesChange:
+		activity.Kind = GroupActivityKindRulesChanged
+		activity.MemberID = ""
+	case GroupEventTypeMemberMute:
+		activity.Kind = GroupActivityKindMemberUnmuted
+		if !event.MutedUntil.IsZero() {
+			activity.Kind = GroupActivityKindMemberMuted
+			activity.MutedUntil = event.MutedUntil.UTC()
+		}
+	case GroupEventTypePostingModeChange:
+		activity.Kind = GroupActivityKindPostingMode
+		activity.PostingMode = event.PostingMode
+		activity.MemberID = ""
+	case GroupEventTypeOwnershipTransfer:
+		activity.Kind = GroupActivityKindOwnership
+	case GroupEventTypeMemberPrivacyChange:
+		activity.Kind = GroupActivityKindMemberPrivacy
+		activity.HiddenMembers = event.HiddenMembers
+		activity.MemberID = ""
+	default:
+		return GroupActivity{}, false
+	}
+	activity.Text = activity.render()
+	return activity, true
+}
+
+// BuildGroupActivityFeed replays a group event log from the beginning and
+// describes every event with a visible effect, oldest first.
+func BuildGroupActivityFeed(groupID string, events []GroupEvent) []GroupActivity {
+	ordered := append([]GroupEvent(nil), events...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+	state := NewGroupState(Group{ID: groupID})
+	out := make([]GroupActivity, 0, len(ordered))
+	for _, event := range ordered {
+		if activity, ok := DescribeGroupActivity(state, event); ok {
+			out = append(out, activity)
+		}
+		// A gap in a partially synced log only degrades later descriptions.
+		_, _ = ApplyGroupEvent(&state, event)
+		if event.Version == 1 && strings.TrimSpace(event.Title) != "" {
+			state.Group.Title = strings.TrimSpace(event.Title)
+		}
+	}
+	return out
+}
+
+func (a GroupActivity) render() string {
+	switch a.Kind {
+	case GroupActivityKindCreated:
+		if a.Title != "" {
+			return fmt.Sprintf("%s created the group %q", a.ActorID, a.Title)
+		}
+		return fmt.Sprintf("%s created the group", a.ActorID)
+	case GroupActivityKindMemberInvited:
+		return fmt.Sprintf("%s invited %s", a.ActorID, a.MemberID)
+	case GroupActivityKindMemberJoined:
+		return fmt.Sprintf("%s joined the group", a.MemberID)
+	case GroupActivityKindMemberLeft:
+		return fmt.Sprintf("%s left the group", a.MemberID)
+	case GroupActivityKindMemberRemoved:
+		return fmt.Sprintf("%s removed %s", a.ActorID, a.MemberID)
+	case GroupActivityKindPromoted:
+		return fmt.Sprintf("%s promoted %s to %s", a.ActorID, a.MemberID, a.Role)
+	case GroupActivityKindDemoted:
+		return fmt.Sprintf("%s demoted %s to %s", a.ActorID, a.MemberID, a.Role)
+	case GroupActivityKindTitleChanged:
+		return fmt.Sprintf("%s changed the group title to %q", a.ActorID, a.Title)
+	case GroupActivityKindRulesChanged:
+		return fmt.Sprintf("%s updated the group rules", a.ActorID)
+	case GroupActivityKindMemberMuted:
+		return fmt.Sprintf("%s muted %s until %s", a.ActorID, a.MemberID, a.MutedUntil.Format(time.RFC3339))
+	case GroupActivityKindMemberUnmuted:
+		return fmt.Sprintf("%s unmuted %s", a.ActorID, a.MemberID)
+	case GroupActivityKindPostingMode:
+		if a.PostingMode == GroupPostingModeAdminsOnly {
+			return fmt.Sprintf("%s allowed only admins to post", a.ActorID)
+		}
+		return fmt.Sprintf("%s allowed all members to post", a.ActorID)
+	case GroupActivityKindOwnership:
+		return fmt.Sprintf("%s transferred ownership to %s", a.ActorID, a.MemberID)
+	case GroupActivityKindMemberPrivacy:
+		if a.HiddenMembers {
+			return fmt.Sprintf("%s hid the member list", a.ActorID)
+		}
+		return fmt.Sprintf("%s made the member list visible", a.ActorID)
+	default:
+		return ""
+	}
+}
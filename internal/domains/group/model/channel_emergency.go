@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// EmergencyBroadcastConfirmationTTL bounds how long an owner has to confirm
+// an emergency broadcast after preparing it.
+const EmergencyBroadcastConfirmationTTL = 2 * time.Minute
+
+// EmergencyBroadcastConfirmation is a one-time token that authorizes one
+// emergency broadcast of the exact content it was prepared for.
+type EmergencyBroadcastConfirmation struct {
+	GroupID   string    `json:"group_id"`
+	Token     string    `json:"confirmation_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
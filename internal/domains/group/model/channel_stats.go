@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Channel stats cover the most recent broadcasts only; older posts still
+// count towards BroadcastCount.
+const (
+	DefaultChannelStatsBroadcasts = 20
+	MaxChannelStatsBroadcasts     = 100
+)
+
+// ChannelBroadcastStats is the reach of one channel post. Counts are
+// aggregates, so subscribers' individual reads stay private.
+type ChannelBroadcastStats struct {
+	MessageID      string    `json:"message_id"`
+	SentAt         time.Time `json:"sent_at"`
+	RecipientCount int       `json:"recipient_count"`
+	DeliveredCount int       `json:"delivered_count"`
+	ReadCount      int       `json:"read_count"`
+	PendingCount   int       `json:"pending_count"`
+	FailedCount    int       `json:"failed_count"`
+}
+
+// ChannelStats summarizes the audience of a channel and the reach of the
+// posts this identity broadcast, newest first.
+type ChannelStats struct {
+	GroupID         string                  `json:"group_id"`
+	SubscriberCount int                     `json:"subscriber_count"`
+	AdminCount      int                     `json:"admin_count"`
+	BroadcastCount  int                     `json:"broadcast_count"`
+	Broadcasts      []ChannelBroadcastStats `json:"broadcasts"`
+}
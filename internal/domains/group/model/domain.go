@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"sort"
 	"strings"
 	"time"
 )
@@ -23,6 +24,33 @@ const (
 	GroupMemberStatusRemoved GroupMemberStatus = "removed"
 )
 
+// GroupPostingMode says who may post to a group. The empty mode is
+// GroupPostingModeAll.
+type GroupPostingMode string
+
+const (
+	GroupPostingModeAll        GroupPostingMode = "all"
+	GroupPostingModeAdminsOnly GroupPostingMode = "admins_only"
+)
+
+func ParseGroupPostingMode(raw string) (GroupPostingMode, error) {
+	switch GroupPostingMode(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", GroupPostingModeAll:
+		return GroupPostingModeAll, nil
+	case GroupPostingModeAdminsOnly:
+		return GroupPostingModeAdminsOnly, nil
+	default:
+		return "", ErrInvalidGroupPostingMode
+	}
+}
+
+// Block scopes reported in member listings. A global block is the blocklist;
+// a group block hides the member's messages in that group only.
+const (
+	GroupMemberBlockScopeGlobal = "global"
+	GroupMemberBlockScopeGroup  = "group"
+)
+
 var (
 	ErrInvalidGroupID                     = errors.New("invalid group id")
 	ErrInvalidGroupMemberID               = errors.New("invalid group member id")
@@ -48,6 +76,97 @@ type Group struct {
 	CreatedBy   string    `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// Rules must be acknowledged by members before they can post; every
+	// change bumps RulesVersion and asks for a fresh acknowledgment.
+	Rules        string `json:"rules,omitempty"`
+	RulesVersion uint64 `json:"rules_version,omitempty"`
+	// CommentsEnabled lets channel subscribers reply in per-post threads
+	// while the main feed stays restricted to owners and admins.
+	CommentsEnabled bool `json:"comments_enabled,omitempty"`
+	// LockedThreads lists comment threads closed to further replies, sorted.
+	LockedThreads []string `json:"locked_threads,omitempty"`
+	// PostingMode restricts posting to owners and admins when set to
+	// GroupPostingModeAdminsOnly.
+	PostingMode GroupPostingMode `json:"posting_mode,omitempty"`
+	// HiddenMembers shows the member list to owners and admins only and
+	// attributes messages to member pseudonyms instead of identity ids.
+	HiddenMembers bool `json:"hidden_members,omitempty"`
+	// PseudonymSalt keys the member pseudonyms. It is set the first time
+	// the members are hidden and kept afterwards, so pseudonyms are stable.
+	PseudonymSalt string `json:"pseudonym_salt,omitempty"`
+}
+
+// IsChannel reports whether the group is a broadcast channel. Channels are
+// groups whose title carries the "[channel" prefix.
+func (g Group) IsChannel() bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(g.Title)), "[channel")
+}
+
+func (g Group) IsThreadLocked(threadID string) bool {
+	threadID = strings.TrimSpace(threadID)
+	i := sort.SearchStrings(g.LockedThreads, threadID)
+	return i < len(g.LockedThreads) && g.LockedThreads[i] == threadID
+}
+
+// ValidateChannelPost checks that member may post to threadID, "" being the
+// main feed. Outside channels any member may post. In channels owners and
+// admins post anywhere; subscribers only reply in unlocked threads while
+// comments are enabled.
+func ValidateChannelPost(group Group, member GroupMember, threadID string) error {
+	if !group.IsChannel() || member.CanManageMembers() {
+		return nil
+	}
+	threadID = strings.TrimSpace(threadID)
+	if threadID == "" || !group.CommentsEnabled {
+		return ErrGroupPermissionDenied
+	}
+	if group.IsThreadLocked(threadID) {
+		return ErrGroupThreadLocked
+	}
+	return nil
+}
+
+// ValidateMemberPost checks the group-wide posting restrictions: a muted
+// member cannot post until the mute ends, and in admins-only mode only
+// owners and admins post.
+func ValidateMemberPost(group Group, member GroupMember, now time.Time) error {
+	if member.IsMuted(now) {
+		return ErrGroupMemberMuted
+	}
+	if group.PostingMode == GroupPostingModeAdminsOnly && !member.CanManageMembers() {
+		return ErrGroupPostingRestricted
+	}
+	return nil
+}
+
+// ValidateEmergencyBroadcast checks that member may send an emergency post
+// to threadID. Only the active owner of a channel may, and only to the main
+// feed.
+func ValidateEmergencyBroadcast(group Group, member GroupMember, threadID string) error {
+	if !group.IsChannel() {
+		return ErrGroupNotChannel
+	}
+	if member.Status != GroupMemberStatusActive || !member.IsOwner() || strings.TrimSpace(threadID) != "" {
+		return ErrGroupPermissionDenied
+	}
+	return nil
+}
+
+func withLockedThread(threads []string, threadID string, locked bool) []string {
+	out := make([]string, 0, len(threads)+1)
+	for _, existing := range threads {
+		if existing != threadID {
+			out = append(out, existing)
+		}
+	}
+	if locked {
+		out = append(out, threadID)
+	}
+	sort.Strings(out)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
 }
 
 // GroupMember describes member role and lifecycle state inside a group.
@@ -59,6 +178,14 @@ type GroupMember struct {
 	InvitedAt   time.Time         `json:"invited_at"`
 	ActivatedAt time.Time         `json:"activated_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	// RulesAckVersion is the Group.RulesVersion the member last accepted.
+	RulesAckVersion uint64 `json:"rules_ack_version,omitempty"`
+	// MutedUntil is when a mute set by a moderator ends; the member cannot
+	// post before then.
+	MutedUntil time.Time `json:"muted_until,omitempty"`
+	// BlockScope is the local user's block on the member, if any. It is only
+	// set in listings and never replicated.
+	BlockScope string `json:"block_scope,omitempty"`
 }
 
 func (m GroupMember) IsOwner() bool {
@@ -69,6 +196,42 @@ func (m GroupMember) CanManageMembers() bool {
 	return m.Role == GroupMemberRoleOwner || m.Role == GroupMemberRoleAdmin
 }
 
+func (m GroupMember) IsMuted(now time.Time) bool {
+	return now.Before(m.MutedUntil)
+}
+
+// CanModerate reports whether actor may mute or remove target. Nobody
+// moderates the owner, and admins do not moderate each other.
+func (m GroupMember) CanModerate(target GroupMember) bool {
+	if !m.CanManageMembers() || target.IsOwner() {
+		return false
+	}
+	return m.IsOwner() || target.Role != GroupMemberRoleAdmin
+}
+
+// ValidateOwnershipTransfer checks that actorID owns the group and may hand
+// it to newOwnerID, who must be an active admin.
+func ValidateOwnershipTransfer(state GroupState, actorID, newOwnerID string) error {
+	actor, ok := state.Members[actorID]
+	if !ok || actor.Status != GroupMemberStatusActive || !actor.IsOwner() {
+		return ErrGroupPermissionDenied
+	}
+	target, ok := state.Members[newOwnerID]
+	if !ok {
+		return ErrGroupMembershipNotFound
+	}
+	if newOwnerID == actorID || target.Status != GroupMemberStatusActive || target.Role != GroupMemberRoleAdmin {
+		return ErrInvalidGroupOwnershipTarget
+	}
+	return nil
+}
+
+// NeedsRulesAck reports whether the member has yet to accept the current
+// group rules.
+func (m GroupMember) NeedsRulesAck(group Group) bool {
+	return strings.TrimSpace(group.Rules) != "" && m.RulesAckVersion < group.RulesVersion
+}
+
 func (m GroupMember) CanMutateRole() bool {
 	return m.Status == GroupMemberStatusActive || m.Status == GroupMemberStatusInvited
 }
@@ -15,6 +15,19 @@ const (
 	GroupEventTypeTitleChange   GroupEventType = "title_change"
 	GroupEventTypeProfileChange GroupEventType = "profile_change"
 	GroupEventTypeKeyRotate     GroupEventType = "key_rotate"
+	GroupEventTypeRulesChange   GroupEventType = "rules_change"
+	GroupEventTypeRulesAck      GroupEventType = "rules_ack"
+	// Channel comment moderation.
+	GroupEventTypeCommentsChange GroupEventType = "comments_change"
+	GroupEventTypeThreadLock     GroupEventType = "thread_lock"
+	GroupEventTypeMessageRemove  GroupEventType = "message_remove"
+	// Posting restrictions.
+	GroupEventTypeMemberMute        GroupEventType = "member_mute"
+	GroupEventTypePostingModeChange GroupEventType = "posting_mode_change"
+	// OwnershipTransfer hands the group from the actor to MemberID.
+	GroupEventTypeOwnershipTransfer GroupEventType = "ownership_transfer"
+	// MemberPrivacyChange hides the member list or shows it again.
+	GroupEventTypeMemberPrivacyChange GroupEventType = "member_privacy_change"
 )
 
 var (
@@ -41,7 +54,24 @@ type GroupEvent struct {
 	Description string          `json:"description,omitempty"`
 	Avatar      string          `json:"avatar,omitempty"`
 
+	Rules        string `json:"rules,omitempty"`
+	RulesVersion uint64 `json:"rules_version,omitempty"`
+
 	KeyVersion uint32 `json:"key_version,omitempty"`
+
+	CommentsEnabled bool   `json:"comments_enabled,omitempty"`
+	ThreadID        string `json:"thread_id,omitempty"`
+	ThreadLocked    bool   `json:"thread_locked,omitempty"`
+	// MessageEventID is the event id of the group message a message_remove
+	// takes down for every member.
+	MessageEventID string `json:"message_event_id,omitempty"`
+
+	// MutedUntil ends the mute of MemberID; zero lifts it.
+	MutedUntil  time.Time        `json:"muted_until,omitempty"`
+	PostingMode GroupPostingMode `json:"posting_mode,omitempty"`
+
+	HiddenMembers bool   `json:"hidden_members,omitempty"`
+	PseudonymSalt string `json:"pseudonym_salt,omitempty"`
 }
 
 // GroupState is an in-memory event-application state used by domain flows.
@@ -64,7 +94,9 @@ func NewGroupState(group Group) GroupState {
 
 func (t GroupEventType) Valid() bool {
 	switch t {
-	case GroupEventTypeMemberAdd, GroupEventTypeMemberRemove, GroupEventTypeMemberLeave, GroupEventTypeTitleChange, GroupEventTypeProfileChange, GroupEventTypeKeyRotate:
+	case GroupEventTypeMemberAdd, GroupEventTypeMemberRemove, GroupEventTypeMemberLeave, GroupEventTypeTitleChange, GroupEventTypeProfileChange, GroupEventTypeKeyRotate,
+		GroupEventTypeRulesChange, GroupEventTypeRulesAck, GroupEventTypeCommentsChange, GroupEventTypeThreadLock, GroupEventTypeMessageRemove,
+		GroupEventTypeMemberMute, GroupEventTypePostingModeChange, GroupEventTypeOwnershipTransfer, GroupEventTypeMemberPrivacyChange:
 		return true
 	default:
 		return false
@@ -127,6 +159,39 @@ func ValidateGroupEvent(event GroupEvent) error {
 		if event.KeyVersion == 0 {
 			return ErrInvalidGroupEventPayload
 		}
+	case GroupEventTypeRulesChange:
+		if len(strings.TrimSpace(event.Rules)) > MaxGroupRulesLength || event.RulesVersion == 0 {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeRulesAck:
+		if event.RulesVersion == 0 {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeThreadLock:
+		if strings.TrimSpace(event.ThreadID) == "" {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeMessageRemove:
+		if strings.TrimSpace(event.MessageEventID) == "" {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeMemberMute:
+		if strings.TrimSpace(event.MemberID) == "" {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeOwnershipTransfer:
+		memberID := strings.TrimSpace(event.MemberID)
+		if memberID == "" || memberID == strings.TrimSpace(event.ActorID) {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypePostingModeChange:
+		if event.PostingMode != GroupPostingModeAll && event.PostingMode != GroupPostingModeAdminsOnly {
+			return ErrInvalidGroupEventPayload
+		}
+	case GroupEventTypeMemberPrivacyChange:
+		if event.HiddenMembers && strings.TrimSpace(event.PseudonymSalt) == "" {
+			return ErrInvalidGroupEventPayload
+		}
 	}
 	return nil
 }
@@ -229,6 +294,63 @@ func ApplyGroupEvent(state *GroupState, event GroupEvent) (bool, error) {
 	case GroupEventTypeKeyRotate:
 		state.LastKeyVersion = event.KeyVersion
 		state.Group.UpdatedAt = event.OccurredAt.UTC()
+	case GroupEventTypeRulesChange:
+		state.Group.Rules = strings.TrimSpace(event.Rules)
+		state.Group.RulesVersion = event.RulesVersion
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
+		// The author of the rules has accepted them by writing them.
+		if actor, ok := state.Members[strings.TrimSpace(event.ActorID)]; ok {
+			actor.RulesAckVersion = state.Group.RulesVersion
+			state.Members[actor.MemberID] = actor
+		}
+	case GroupEventTypeRulesAck:
+		if member, ok := state.Members[strings.TrimSpace(event.ActorID)]; ok && event.RulesVersion == state.Group.RulesVersion {
+			member.RulesAckVersion = event.RulesVersion
+			member.UpdatedAt = event.OccurredAt.UTC()
+			state.Members[member.MemberID] = member
+		}
+	case GroupEventTypeCommentsChange:
+		state.Group.CommentsEnabled = event.CommentsEnabled
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
+	case GroupEventTypeThreadLock:
+		state.Group.LockedThreads = withLockedThread(state.Group.LockedThreads, strings.TrimSpace(event.ThreadID), event.ThreadLocked)
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
+	case GroupEventTypeMessageRemove:
+		// Removal only touches stored messages; the event is versioned so
+		// every member applies it in the same order as other changes.
+	case GroupEventTypeMemberMute:
+		if member, ok := state.Members[strings.TrimSpace(event.MemberID)]; ok {
+			member.MutedUntil = event.MutedUntil.UTC()
+			member.UpdatedAt = event.OccurredAt.UTC()
+			state.Members[member.MemberID] = member
+		}
+	case GroupEventTypePostingModeChange:
+		state.Group.PostingMode = event.PostingMode
+		if event.PostingMode == GroupPostingModeAll {
+			state.Group.PostingMode = ""
+		}
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
+	case GroupEventTypeMemberPrivacyChange:
+		state.Group.HiddenMembers = event.HiddenMembers
+		// The first salt sticks so a member keeps the same pseudonym.
+		if state.Group.PseudonymSalt == "" {
+			state.Group.PseudonymSalt = strings.TrimSpace(event.PseudonymSalt)
+		}
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
+	case GroupEventTypeOwnershipTransfer:
+		// The previous owner stays on as an admin, so the group never has
+		// two owners or none.
+		if previous, ok := state.Members[strings.TrimSpace(event.ActorID)]; ok {
+			previous.Role = GroupMemberRoleAdmin
+			previous.UpdatedAt = event.OccurredAt.UTC()
+			state.Members[previous.MemberID] = previous
+		}
+		if next, ok := state.Members[strings.TrimSpace(event.MemberID)]; ok {
+			next.Role = GroupMemberRoleOwner
+			next.UpdatedAt = event.OccurredAt.UTC()
+			state.Members[next.MemberID] = next
+		}
+		state.Group.UpdatedAt = event.OccurredAt.UTC()
 	}
 
 	state.Version = event.Version
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// GroupPseudonymPrefix marks member pseudonyms so clients never mistake one
+// for an identity id.
+const GroupPseudonymPrefix = "anon_"
+
+// NewPseudonymSalt returns the random key a group's member pseudonyms are
+// derived from.
+func NewPseudonymSalt() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// MemberPseudonym is the name memberID goes by in a group with hidden
+// members. It stays the same for as long as the group keeps its salt, and is
+// empty for groups that never hid their members.
+func MemberPseudonym(group Group, memberID string) string {
+	if group.PseudonymSalt == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(group.PseudonymSalt))
+	mac.Write([]byte(group.ID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.TrimSpace(memberID)))
+	return GroupPseudonymPrefix + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// CanSeeMembers reports whether viewer may list the members of group. With
+// hidden members only owners and admins may.
+func CanSeeMembers(group Group, viewer GroupMember) bool {
+	return !group.HiddenMembers || viewer.CanManageMembers()
+}
+
+// VisibleGroupMembers trims members to what viewerID may see: everyone, or
+// just the viewer's own entry when the group hides its members from them.
+func VisibleGroupMembers(group Group, members []GroupMember, viewerID string) []GroupMember {
+	viewerID = strings.TrimSpace(viewerID)
+	for _, member := range members {
+		if member.MemberID != viewerID {
+			continue
+		}
+		if CanSeeMembers(group, member) {
+			return members
+		}
+		return []GroupMember{member}
+	}
+	if !group.HiddenMembers {
+		return members
+	}
+	return []GroupMember{}
+}
+
+// ResolveMemberPseudonym finds the member a pseudonym stands for.
+func ResolveMemberPseudonym(state GroupState, pseudonym string) (GroupMember, bool) {
+	pseudonym = strings.TrimSpace(pseudonym)
+	if pseudonym == "" {
+		return GroupMember{}, false
+	}
+	for memberID, member := range state.Members {
+		if MemberPseudonym(state.Group, memberID) == pseudonym {
+			return member, true
+		}
+	}
+	return GroupMember{}, false
+}
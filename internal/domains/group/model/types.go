@@ -17,8 +17,23 @@ var (
 	ErrGroupRateLimitExceeded           = errors.New("group operation rate limit exceeded")
 	ErrGroupMemberLimitExceeded         = errors.New("group member limit exceeded")
 	ErrGroupPendingInvitesLimitExceeded = errors.New("group pending invites limit exceeded")
+	ErrInvalidGroupRules                = errors.New("group rules are too long")
+	ErrGroupRulesNotAcknowledged        = errors.New("group rules must be acknowledged")
+	ErrGroupNotChannel                  = errors.New("group is not a channel")
+	ErrGroupThreadLocked                = errors.New("group thread is locked")
+	ErrInvalidGroupThreadID             = errors.New("group thread id is required")
+	ErrGroupMemberMuted                 = errors.New("group member is muted")
+	ErrGroupPostingRestricted           = errors.New("only group admins can post")
+	ErrInvalidGroupPostingMode          = errors.New("invalid group posting mode")
+	ErrInvalidGroupMuteUntil            = errors.New("group mute must end in the future")
+	ErrInvalidGroupOwnershipTarget      = errors.New("new group owner must be an active admin")
+	ErrInvalidEmergencyConfirmation     = errors.New("emergency broadcast confirmation is invalid or expired")
+	ErrGroupPseudonymNotFound           = errors.New("group pseudonym not found")
 )
 
+// MaxGroupRulesLength bounds the rules text in bytes.
+const MaxGroupRulesLength = 4000
+
 func NormalizeGroupID(groupID string) (string, error) {
 	groupID = strings.TrimSpace(groupID)
 	if groupID == "" {
@@ -37,12 +52,39 @@ func NormalizeGroupTitle(title string) (string, error) {
 
 var ErrInvalidGroupMessageContent = errors.New("group message content is required")
 
+var ErrInvalidGroupMessageFilter = errors.New("invalid group message filter")
+
+// GroupMessageFilter selects which timeline entries a group message listing
+// returns: regular messages, locally generated system messages or both.
+type GroupMessageFilter string
+
+const (
+	GroupMessageFilterAll      GroupMessageFilter = "all"
+	GroupMessageFilterMessages GroupMessageFilter = "messages"
+	GroupMessageFilterSystem   GroupMessageFilter = "system"
+)
+
+func ParseGroupMessageFilter(raw string) (GroupMessageFilter, error) {
+	switch GroupMessageFilter(strings.TrimSpace(raw)) {
+	case "", GroupMessageFilterAll:
+		return GroupMessageFilterAll, nil
+	case GroupMessageFilterMessages:
+		return GroupMessageFilterMessages, nil
+	case GroupMessageFilterSystem:
+		return GroupMessageFilterSystem, nil
+	default:
+		return "", ErrInvalidGroupMessageFilter
+	}
+}
+
 type GroupMessageRecipientStatus struct {
 	RecipientID string `json:"recipient_id"`
 	MessageID   string `json:"message_id"`
 	Status      string `json:"status"`
 	Error       string `json:"error,omitempty"`
-	Duplicate   bool   `json:"duplicate"`
+	// ErrorCategory classifies Error as api, crypto, storage or network.
+	ErrorCategory string `json:"error_category,omitempty"`
+	Duplicate     bool   `json:"duplicate"`
 }
 
 type GroupMessageFanoutResult struct {
@@ -53,4 +95,54 @@ type GroupMessageFanoutResult struct {
 	Pending    int                           `json:"pending"`
 	Failed     int                           `json:"failed"`
 	Recipients []GroupMessageRecipientStatus `json:"recipients"`
+	// Failures maps each failed member to its error category so clients can
+	// offer a per-member retry.
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// DeliveryError returns the failure stats of the fanout, or nil when every
+// attempted member accepted the message.
+func (r GroupMessageFanoutResult) DeliveryError() *GroupMessageDeliveryError {
+	if r.Failed == 0 {
+		return nil
+	}
+	failures := make(map[string]string, len(r.Failures))
+	for memberID, category := range r.Failures {
+		failures[memberID] = category
+	}
+	return &GroupMessageDeliveryError{
+		GroupID:   r.GroupID,
+		EventID:   r.EventID,
+		Attempted: r.Attempted,
+		Failed:    r.Failed,
+		Failures:  failures,
+	}
+}
+
+// GroupMessageDeliveryError reports members a group message did not reach.
+// It doubles as RPC error data, mirroring device revocation delivery stats.
+type GroupMessageDeliveryError struct {
+	GroupID   string            `json:"group_id"`
+	EventID   string            `json:"event_id"`
+	Attempted int               `json:"attempted"`
+	Failed    int               `json:"failed"`
+	Failures  map[string]string `json:"failures"`
+}
+
+func (e *GroupMessageDeliveryError) Error() string {
+	if e == nil {
+		return "group message delivery failed"
+	}
+	if e.Failed >= e.Attempted {
+		return "group message delivery failed for all members"
+	}
+	return "group message delivery partially failed"
+}
+
+func (e *GroupMessageDeliveryError) IsFullFailure() bool {
+	return e != nil && e.Attempted > 0 && e.Failed >= e.Attempted
+}
+
+func (e *GroupMessageDeliveryError) ErrorData() any {
+	return e
 }
@@ -0,0 +1,50 @@
+package group
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyGroupEventOwnershipTransfer(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := NewGroupState(Group{ID: "group-1", Title: "friends", CreatedBy: "aim1owner", CreatedAt: now})
+	state.Members["aim1owner"] = GroupMember{GroupID: "group-1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}
+	state.Members["aim1admin"] = GroupMember{GroupID: "group-1", MemberID: "aim1admin", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusActive}
+	state.Members["aim1user"] = GroupMember{GroupID: "group-1", MemberID: "aim1user", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive}
+	state.Members["aim1invited"] = GroupMember{GroupID: "group-1", MemberID: "aim1invited", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusInvited}
+
+	cases := []struct {
+		actor, target string
+		want          error
+	}{
+		{"aim1admin", "aim1user", ErrGroupPermissionDenied},
+		{"aim1owner", "aim1user", ErrInvalidGroupOwnershipTarget},
+		{"aim1owner", "aim1invited", ErrInvalidGroupOwnershipTarget},
+		{"aim1owner", "aim1owner", ErrInvalidGroupOwnershipTarget},
+		{"aim1owner", "aim1ghost", ErrGroupMembershipNotFound},
+		{"aim1owner", "aim1admin", nil},
+	}
+	for _, tc := range cases {
+		if err := ValidateOwnershipTransfer(state, tc.actor, tc.target); !errors.Is(err, tc.want) {
+			t.Fatalf("%s -> %s: want %v, got %v", tc.actor, tc.target, tc.want, err)
+		}
+	}
+
+	event := GroupEvent{ID: "evt-1", GroupID: "group-1", Version: 1, Type: GroupEventTypeOwnershipTransfer, ActorID: "aim1owner", OccurredAt: now, MemberID: "aim1admin"}
+	activity, ok := DescribeGroupActivity(state, event)
+	if !ok || activity.Kind != GroupActivityKindOwnership || activity.Text != "aim1owner transferred ownership to aim1admin" {
+		t.Fatalf("unexpected activity: %+v", activity)
+	}
+	if _, err := ApplyGroupEvent(&state, event); err != nil {
+		t.Fatalf("apply transfer failed: %v", err)
+	}
+	if !state.Members["aim1admin"].IsOwner() || state.Members["aim1owner"].Role != GroupMemberRoleAdmin {
+		t.Fatalf("roles not swapped: %+v", state.Members)
+	}
+
+	self := GroupEvent{ID: "evt-2", GroupID: "group-1", Version: 2, Type: GroupEventTypeOwnershipTransfer, ActorID: "aim1admin", OccurredAt: now, MemberID: "aim1admin"}
+	if err := ValidateGroupEvent(self); !errors.Is(err, ErrInvalidGroupEventPayload) {
+		t.Fatalf("transfer to self must be rejected, got %v", err)
+	}
+}
@@ -18,6 +18,8 @@ const (
 	groupSendRateLimitBurstEnv       = "AIM_GROUP_SEND_RATE_LIMIT_BURST"
 	groupMembershipRateLimitRPSEnv   = "AIM_GROUP_MEMBERSHIP_RATE_LIMIT_RPS"
 	groupMembershipRateLimitBurstEnv = "AIM_GROUP_MEMBERSHIP_RATE_LIMIT_BURST"
+	groupEmergencyRateLimitRPSEnv    = "AIM_GROUP_EMERGENCY_RATE_LIMIT_RPS"
+	groupEmergencyRateLimitBurstEnv  = "AIM_GROUP_EMERGENCY_RATE_LIMIT_BURST"
 )
 
 type abuseProtectionConfig struct {
@@ -29,6 +31,8 @@ type abuseProtectionConfig struct {
 	SendRateLimitBurst       int
 	MembershipRateLimitRPS   float64
 	MembershipRateLimitBurst int
+	EmergencyRateLimitRPS    float64
+	EmergencyRateLimitBurst  int
 }
 
 func loadAbuseProtectionConfigFromEnv() abuseProtectionConfig {
@@ -41,6 +45,10 @@ func loadAbuseProtectionConfigFromEnv() abuseProtectionConfig {
 		SendRateLimitBurst:       400,
 		MembershipRateLimitRPS:   100,
 		MembershipRateLimitBurst: 200,
+		// Emergency posts bypass recipients' mutes, so they are kept rare:
+		// three at once, then one per ten minutes.
+		EmergencyRateLimitRPS:   1.0 / 600,
+		EmergencyRateLimitBurst: 3,
 	}
 	cfg.MaxMembers = readPositiveIntEnv(groupMaxMembersEnv, cfg.MaxMembers)
 	cfg.MaxPendingInvites = readPositiveIntEnv(groupMaxPendingInvitesEnv, cfg.MaxPendingInvites)
@@ -50,6 +58,8 @@ func loadAbuseProtectionConfigFromEnv() abuseProtectionConfig {
 	cfg.SendRateLimitBurst = readPositiveIntEnv(groupSendRateLimitBurstEnv, cfg.SendRateLimitBurst)
 	cfg.MembershipRateLimitRPS = readPositiveFloatEnv(groupMembershipRateLimitRPSEnv, cfg.MembershipRateLimitRPS)
 	cfg.MembershipRateLimitBurst = readPositiveIntEnv(groupMembershipRateLimitBurstEnv, cfg.MembershipRateLimitBurst)
+	cfg.EmergencyRateLimitRPS = readPositiveFloatEnv(groupEmergencyRateLimitRPSEnv, cfg.EmergencyRateLimitRPS)
+	cfg.EmergencyRateLimitBurst = readPositiveIntEnv(groupEmergencyRateLimitBurstEnv, cfg.EmergencyRateLimitBurst)
 	if cfg.MaxPendingInvites > cfg.MaxMembers {
 		cfg.MaxPendingInvites = cfg.MaxMembers
 	}
@@ -106,6 +116,7 @@ type AbuseProtection struct {
 	inviteLimiter     *operationRateLimiter
 	sendLimiter       *operationRateLimiter
 	membershipLimiter *operationRateLimiter
+	emergencyLimiter  *operationRateLimiter
 }
 
 func NewAbuseProtectionFromEnv() *AbuseProtection {
@@ -115,6 +126,7 @@ func NewAbuseProtectionFromEnv() *AbuseProtection {
 		inviteLimiter:     newOperationRateLimiter(cfg.InviteRateLimitRPS, cfg.InviteRateLimitBurst),
 		sendLimiter:       newOperationRateLimiter(cfg.SendRateLimitRPS, cfg.SendRateLimitBurst),
 		membershipLimiter: newOperationRateLimiter(cfg.MembershipRateLimitRPS, cfg.MembershipRateLimitBurst),
+		emergencyLimiter:  newOperationRateLimiter(cfg.EmergencyRateLimitRPS, cfg.EmergencyRateLimitBurst),
 	}
 }
 
@@ -130,6 +142,12 @@ func (p *AbuseProtection) AllowMembership(actorID string, now time.Time) bool {
 	return allowByLimiter(p, p.membershipLimiter, actorID, now)
 }
 
+// AllowEmergency admits an emergency channel broadcast. It is limited
+// separately from regular sends and far more tightly.
+func (p *AbuseProtection) AllowEmergency(actorID string, now time.Time) bool {
+	return allowByLimiter(p, p.emergencyLimiter, actorID, now)
+}
+
 func allowByLimiter(p *AbuseProtection, limiter *operationRateLimiter, actorID string, now time.Time) bool {
 	if p == nil {
 		return true
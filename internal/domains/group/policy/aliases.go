@@ -1,10 +1,16 @@
 package policy
 
-import groupmodel "aim-chat/go-backend/internal/domains/group/model"
+import (
+	"time"
+
+	groupmodel "aim-chat/go-backend/internal/domains/group/model"
+)
 
 type Group = groupmodel.Group
+type GroupMember = groupmodel.GroupMember
 type GroupEvent = groupmodel.GroupEvent
 type GroupState = groupmodel.GroupState
+type GroupActivity = groupmodel.GroupActivity
 
 const (
 	GroupMemberStatusInvited = groupmodel.GroupMemberStatusInvited
@@ -19,8 +25,17 @@ var (
 	ErrGroupMemberLimitExceeded         = groupmodel.ErrGroupMemberLimitExceeded
 	ErrGroupPendingInvitesLimitExceeded = groupmodel.ErrGroupPendingInvitesLimitExceeded
 	ErrInvalidGroupEventPayload         = groupmodel.ErrInvalidGroupEventPayload
+	ErrGroupRulesNotAcknowledged        = groupmodel.ErrGroupRulesNotAcknowledged
 )
 
+func ValidateChannelPost(group Group, member GroupMember, threadID string) error {
+	return groupmodel.ValidateChannelPost(group, member, threadID)
+}
+
+func ValidateMemberPost(group Group, member GroupMember, now time.Time) error {
+	return groupmodel.ValidateMemberPost(group, member, now)
+}
+
 func NewGroupState(group Group) GroupState {
 	return groupmodel.NewGroupState(group)
 }
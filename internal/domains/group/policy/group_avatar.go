@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"strings"
+
+	"aim-chat/go-backend/internal/platform/imaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	// GroupAvatarMaxDimension bounds the longer side of a stored avatar.
+	GroupAvatarMaxDimension = 256
+	// MaxGroupAvatarBytes keeps avatars small enough to ride inside a single
+	// signed profile wire.
+	MaxGroupAvatarBytes = 48 << 10
+	// GroupAvatarRefPrefix marks Group.Avatar values that name a cached asset
+	// by content hash rather than an opaque client string.
+	GroupAvatarRefPrefix = "sha256:"
+)
+
+var (
+	ErrInvalidGroupAvatar      = errors.New("invalid group avatar")
+	ErrGroupAvatarHashMismatch = errors.New("group avatar hash mismatch")
+)
+
+// NormalizeGroupAvatar decodes an uploaded image, scales it to fit
+// GroupAvatarMaxDimension and re-encodes it as JPEG on a white background.
+func NormalizeGroupAvatar(data []byte) (models.GroupAvatar, error) {
+	thumb, err := imaging.Thumbnail(data, GroupAvatarMaxDimension, MaxGroupAvatarBytes)
+	if err != nil {
+		return models.GroupAvatar{}, ErrInvalidGroupAvatar
+	}
+	return models.GroupAvatar{
+		SHA256:   GroupAvatarHash(thumb.Data),
+		MimeType: imaging.ThumbnailMimeType,
+		Width:    thumb.Width,
+		Height:   thumb.Height,
+		Data:     thumb.Data,
+	}, nil
+}
+
+// GroupAvatarHash returns the lowercase hex SHA-256 of avatar bytes.
+func GroupAvatarHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GroupAvatarRef builds the Group.Avatar value that points at an asset.
+func GroupAvatarRef(hash string) string {
+	return GroupAvatarRefPrefix + strings.ToLower(strings.TrimSpace(hash))
+}
+
+// ParseGroupAvatarRef extracts the asset hash from a Group.Avatar value.
+func ParseGroupAvatarRef(avatar string) (string, bool) {
+	avatar = strings.TrimSpace(avatar)
+	if !strings.HasPrefix(avatar, GroupAvatarRefPrefix) {
+		return "", false
+	}
+	hash := strings.TrimPrefix(avatar, GroupAvatarRefPrefix)
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+		return "", false
+	}
+	return hash, true
+}
+
+// VerifyGroupAvatar checks that received avatar bytes are a bounded image
+// whose hash matches the reference carried by the signed profile change.
+func VerifyGroupAvatar(avatar models.GroupAvatar, ref string) error {
+	hash, ok := ParseGroupAvatarRef(ref)
+	if !ok {
+		return ErrInvalidGroupAvatar
+	}
+	if len(avatar.Data) == 0 || len(avatar.Data) > MaxGroupAvatarBytes {
+		return ErrInvalidGroupAvatar
+	}
+	if GroupAvatarHash(avatar.Data) != hash || !strings.EqualFold(avatar.SHA256, hash) {
+		return ErrGroupAvatarHashMismatch
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(avatar.Data))
+	if err != nil || cfg.Width > GroupAvatarMaxDimension || cfg.Height > GroupAvatarMaxDimension {
+		return ErrInvalidGroupAvatar
+	}
+	return nil
+}
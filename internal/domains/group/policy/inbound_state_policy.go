@@ -2,6 +2,7 @@ package policy
 
 import (
 	"strings"
+	"time"
 )
 
 type InboundGroupMessageRejectReason string
@@ -10,18 +11,33 @@ const (
 	InboundGroupMessageReasonUnauthorizedSender        InboundGroupMessageRejectReason = "unauthorized_sender"
 	InboundGroupMessageReasonMembershipVersionMismatch InboundGroupMessageRejectReason = "membership_version_mismatch"
 	InboundGroupMessageReasonGroupKeyVersionMismatch   InboundGroupMessageRejectReason = "group_key_version_mismatch"
+	InboundGroupMessageReasonRulesNotAcknowledged      InboundGroupMessageRejectReason = "rules_not_acknowledged"
+	InboundGroupMessageReasonChannelPostDenied         InboundGroupMessageRejectReason = "channel_post_denied"
+	InboundGroupMessageReasonPostingRestricted         InboundGroupMessageRejectReason = "posting_restricted"
+	InboundGroupMessageReasonEmergencyDenied           InboundGroupMessageRejectReason = "emergency_denied"
 )
 
 func ValidateInboundGroupMessageState(
 	state GroupState,
 	senderID string,
+	threadID string,
 	membershipVersion uint64,
 	groupKeyVersion uint32,
+	now time.Time,
 ) (InboundGroupMessageRejectReason, error) {
 	member, memberExists := state.Members[senderID]
 	if !memberExists || member.Status != GroupMemberStatusActive {
 		return InboundGroupMessageReasonUnauthorizedSender, ErrGroupPermissionDenied
 	}
+	if member.NeedsRulesAck(state.Group) {
+		return InboundGroupMessageReasonRulesNotAcknowledged, ErrGroupRulesNotAcknowledged
+	}
+	if err := ValidateChannelPost(state.Group, member, threadID); err != nil {
+		return InboundGroupMessageReasonChannelPostDenied, err
+	}
+	if err := ValidateMemberPost(state.Group, member, now); err != nil {
+		return InboundGroupMessageReasonPostingRestricted, err
+	}
 	if membershipVersion != state.Version {
 		return InboundGroupMessageReasonMembershipVersionMismatch, ErrOutOfOrderGroupEvent
 	}
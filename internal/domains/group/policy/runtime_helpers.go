@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"aim-chat/go-backend/pkg/models"
 	"crypto/sha256"
 	"encoding/hex"
 	"strings"
@@ -12,6 +13,10 @@ const (
 	ReplayFutureSkew = 2 * time.Minute
 )
 
+// GroupFanoutTransportContentType marks the per-recipient delivery records a
+// sender keeps for each group post, as opposed to the post itself.
+const GroupFanoutTransportContentType = "group_fanout_transport"
+
 func DeriveRecipientMessageID(eventID, recipientID string) string {
 	input := strings.TrimSpace(eventID) + "|" + strings.TrimSpace(recipientID)
 	sum := sha256.Sum256([]byte(input))
@@ -52,3 +57,28 @@ func ValidateReplayOccurredAt(occurredAt, now time.Time) error {
 	}
 	return nil
 }
+
+// DeriveSystemMessageID maps a group event to the id of its local system
+// message so re-applied events never duplicate timeline entries.
+func DeriveSystemMessageID(eventID string) string {
+	sum := sha256.Sum256([]byte("system|" + strings.TrimSpace(eventID)))
+	return "gsys_" + hex.EncodeToString(sum[:12])
+}
+
+// BuildGroupSystemMessage turns a group activity into a timeline entry. System
+// messages are stored as already read so they never count as unread and never
+// trigger delivery or read receipts.
+func BuildGroupSystemMessage(activity GroupActivity) models.Message {
+	return models.Message{
+		ID:               DeriveSystemMessageID(activity.EventID),
+		ContactID:        activity.ActorID,
+		ConversationID:   activity.GroupID,
+		ConversationType: models.ConversationTypeGroup,
+		EventID:          activity.EventID,
+		Content:          []byte(activity.Text),
+		Timestamp:        activity.OccurredAt,
+		Direction:        "in",
+		Status:           "read",
+		ContentType:      models.MessageContentTypeSystem,
+	}
+}
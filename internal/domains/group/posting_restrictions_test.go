@@ -0,0 +1,98 @@
+package group
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyGroupEventPostingRestrictions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := NewGroupState(Group{ID: "group-1", Title: "friends", CreatedBy: "aim1owner", CreatedAt: now})
+	state.Members["aim1owner"] = GroupMember{GroupID: "group-1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}
+	state.Members["aim1admin"] = GroupMember{GroupID: "group-1", MemberID: "aim1admin", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusActive}
+	state.Members["aim1user"] = GroupMember{GroupID: "group-1", MemberID: "aim1user", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive}
+
+	apply := func(evt GroupEvent) {
+		t.Helper()
+		evt.GroupID = "group-1"
+		evt.ActorID = "aim1owner"
+		evt.Version = state.Version + 1
+		evt.OccurredAt = now
+		if _, err := ApplyGroupEvent(&state, evt); err != nil {
+			t.Fatalf("apply event %s failed: %v", evt.ID, err)
+		}
+	}
+
+	until := now.Add(time.Hour)
+	apply(GroupEvent{ID: "evt-1", Type: GroupEventTypeMemberMute, MemberID: "aim1user", MutedUntil: until})
+	if err := ValidateMemberPost(state.Group, state.Members["aim1user"], now); !errors.Is(err, ErrGroupMemberMuted) {
+		t.Fatalf("muted member must not post, got %v", err)
+	}
+	if err := ValidateMemberPost(state.Group, state.Members["aim1user"], until); err != nil {
+		t.Fatalf("mute must end at its deadline, got %v", err)
+	}
+	apply(GroupEvent{ID: "evt-2", Type: GroupEventTypeMemberMute, MemberID: "aim1user"})
+	if state.Members["aim1user"].IsMuted(now) {
+		t.Fatal("zero mute must lift the mute")
+	}
+
+	apply(GroupEvent{ID: "evt-3", Type: GroupEventTypePostingModeChange, PostingMode: GroupPostingModeAdminsOnly})
+	if err := ValidateMemberPost(state.Group, state.Members["aim1user"], now); !errors.Is(err, ErrGroupPostingRestricted) {
+		t.Fatalf("admins-only mode must reject members, got %v", err)
+	}
+	if err := ValidateMemberPost(state.Group, state.Members["aim1admin"], now); err != nil {
+		t.Fatalf("admins-only mode must let admins post, got %v", err)
+	}
+	apply(GroupEvent{ID: "evt-4", Type: GroupEventTypePostingModeChange, PostingMode: GroupPostingModeAll})
+	if state.Group.PostingMode != "" {
+		t.Fatalf("all mode must be stored as the default, got %q", state.Group.PostingMode)
+	}
+
+	owner, admin, user := state.Members["aim1owner"], state.Members["aim1admin"], state.Members["aim1user"]
+	if !owner.CanModerate(admin) || !admin.CanModerate(user) {
+		t.Fatal("owner must moderate admins and admins must moderate members")
+	}
+	if admin.CanModerate(owner) || admin.CanModerate(admin) || user.CanModerate(user) {
+		t.Fatal("admins must not moderate the owner or other admins")
+	}
+
+	if err := ValidateGroupEvent(GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypeMemberMute, ActorID: "a", OccurredAt: now}); !errors.Is(err, ErrInvalidGroupEventPayload) {
+		t.Fatalf("mute without member must be rejected, got %v", err)
+	}
+	if err := ValidateGroupEvent(GroupEvent{ID: "e", GroupID: "g", Version: 1, Type: GroupEventTypePostingModeChange, ActorID: "a", OccurredAt: now, PostingMode: "readonly"}); !errors.Is(err, ErrInvalidGroupEventPayload) {
+		t.Fatalf("unknown posting mode must be rejected, got %v", err)
+	}
+	if _, err := ParseGroupPostingMode("readonly"); !errors.Is(err, ErrInvalidGroupPostingMode) {
+		t.Fatalf("unknown posting mode must not parse, got %v", err)
+	}
+}
+
+func TestDecodeInboundGroupEventPostingRestrictions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []GroupEvent{
+		{ID: "evt-1", GroupID: "group-1", Version: 2, Type: GroupEventTypeMemberMute, ActorID: "aim1owner", OccurredAt: now, MemberID: "aim1user", MutedUntil: now.Add(time.Hour)},
+		{ID: "evt-2", GroupID: "group-1", Version: 3, Type: GroupEventTypeMemberMute, ActorID: "aim1owner", OccurredAt: now, MemberID: "aim1user"},
+		{ID: "evt-3", GroupID: "group-1", Version: 4, Type: GroupEventTypePostingModeChange, ActorID: "aim1owner", OccurredAt: now, PostingMode: GroupPostingModeAdminsOnly},
+	}
+	for _, in := range events {
+		plain, err := EncodeGroupEventPlain(in)
+		if err != nil {
+			t.Fatalf("encode %s failed: %v", in.ID, err)
+		}
+		out, err := DecodeInboundGroupEvent(InboundGroupEventWire{
+			EventID:           in.ID,
+			ConversationID:    in.GroupID,
+			MembershipVersion: in.Version,
+			EventType:         string(in.Type),
+			Plain:             plain,
+			SenderID:          in.ActorID,
+		}, now)
+		if err != nil {
+			t.Fatalf("decode %s failed: %v", in.ID, err)
+		}
+		if out.MemberID != in.MemberID || !out.MutedUntil.Equal(in.MutedUntil) || out.PostingMode != in.PostingMode {
+			t.Fatalf("round trip mismatch for %s: %+v", in.ID, out)
+		}
+	}
+}
@@ -72,12 +72,16 @@ func (s *SnapshotStore) Persist(states map[string]GroupState, eventLog map[strin
 	return securestore.WriteEncryptedJSON(s.path, s.secret, state)
 }
 
+// Wipe also removes an event log left behind by EventSourcedStore, since it
+// holds the same group history.
 func (s *SnapshotStore) Wipe() error {
 	if s.path == "" {
 		return nil
 	}
-	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
-		return err
+	for _, path := range []string{s.path, GroupEventLogPath(s.path)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
 	}
 	return nil
 }
@@ -203,4 +207,8 @@ type persistedGroupState struct {
 	Version  int                     `json:"version"`
 	States   map[string]GroupState   `json:"states"`
 	EventLog map[string][]GroupEvent `json:"event_log"`
+	// LogSeq and LogOffset are set by EventSourcedStore and mark how much of
+	// the append-only log the snapshot already covers.
+	LogSeq    uint64 `json:"log_seq,omitempty"`
+	LogOffset int64  `json:"log_offset,omitempty"`
 }
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"time"
+
 	groupmodel "aim-chat/go-backend/internal/domains/group/model"
 	grouppolicy "aim-chat/go-backend/internal/domains/group/policy"
 )
@@ -14,14 +16,31 @@ type GroupMemberRole = groupmodel.GroupMemberRole
 type GroupMemberStatus = groupmodel.GroupMemberStatus
 type GroupMessageRecipientStatus = groupmodel.GroupMessageRecipientStatus
 type GroupMessageFanoutResult = groupmodel.GroupMessageFanoutResult
+type GroupMessageDeliveryError = groupmodel.GroupMessageDeliveryError
+type GroupMessageFilter = groupmodel.GroupMessageFilter
+type ChannelStats = groupmodel.ChannelStats
+type ChannelBroadcastStats = groupmodel.ChannelBroadcastStats
+type GroupActivity = groupmodel.GroupActivity
+type GroupPostingMode = groupmodel.GroupPostingMode
 
 const (
-	GroupEventTypeMemberAdd     = groupmodel.GroupEventTypeMemberAdd
-	GroupEventTypeMemberRemove  = groupmodel.GroupEventTypeMemberRemove
-	GroupEventTypeMemberLeave   = groupmodel.GroupEventTypeMemberLeave
-	GroupEventTypeTitleChange   = groupmodel.GroupEventTypeTitleChange
-	GroupEventTypeProfileChange = groupmodel.GroupEventTypeProfileChange
-	GroupEventTypeKeyRotate     = groupmodel.GroupEventTypeKeyRotate
+	GroupEventTypeMemberAdd      = groupmodel.GroupEventTypeMemberAdd
+	GroupEventTypeMemberRemove   = groupmodel.GroupEventTypeMemberRemove
+	GroupEventTypeMemberLeave    = groupmodel.GroupEventTypeMemberLeave
+	GroupEventTypeTitleChange    = groupmodel.GroupEventTypeTitleChange
+	GroupEventTypeProfileChange  = groupmodel.GroupEventTypeProfileChange
+	GroupEventTypeKeyRotate      = groupmodel.GroupEventTypeKeyRotate
+	GroupEventTypeRulesChange    = groupmodel.GroupEventTypeRulesChange
+	GroupEventTypeRulesAck       = groupmodel.GroupEventTypeRulesAck
+	GroupEventTypeCommentsChange = groupmodel.GroupEventTypeCommentsChange
+	GroupEventTypeThreadLock     = groupmodel.GroupEventTypeThreadLock
+	GroupEventTypeMessageRemove  = groupmodel.GroupEventTypeMessageRemove
+
+	GroupEventTypeMemberMute        = groupmodel.GroupEventTypeMemberMute
+	GroupEventTypePostingModeChange = groupmodel.GroupEventTypePostingModeChange
+	GroupEventTypeOwnershipTransfer = groupmodel.GroupEventTypeOwnershipTransfer
+
+	GroupEventTypeMemberPrivacyChange = groupmodel.GroupEventTypeMemberPrivacyChange
 )
 
 const (
@@ -49,8 +68,29 @@ var (
 	ErrInvalidGroupMemberState    = groupmodel.ErrInvalidGroupMemberState
 	ErrGroupRateLimitExceeded     = groupmodel.ErrGroupRateLimitExceeded
 	ErrInvalidGroupMessageContent = groupmodel.ErrInvalidGroupMessageContent
+	ErrInvalidGroupRules          = groupmodel.ErrInvalidGroupRules
+	ErrOutOfOrderGroupEvent       = groupmodel.ErrOutOfOrderGroupEvent
+	ErrGroupRulesNotAcknowledged  = groupmodel.ErrGroupRulesNotAcknowledged
+	ErrGroupNotChannel            = groupmodel.ErrGroupNotChannel
+	ErrGroupThreadLocked          = groupmodel.ErrGroupThreadLocked
+	ErrInvalidGroupThreadID       = groupmodel.ErrInvalidGroupThreadID
+	ErrInvalidGroupMuteUntil      = groupmodel.ErrInvalidGroupMuteUntil
+	ErrGroupPseudonymNotFound     = groupmodel.ErrGroupPseudonymNotFound
+)
+
+const (
+	DefaultChannelStatsBroadcasts = groupmodel.DefaultChannelStatsBroadcasts
+	MaxChannelStatsBroadcasts     = groupmodel.MaxChannelStatsBroadcasts
 )
 
+const (
+	GroupMessageFilterAll      = groupmodel.GroupMessageFilterAll
+	GroupMessageFilterMessages = groupmodel.GroupMessageFilterMessages
+	GroupMessageFilterSystem   = groupmodel.GroupMessageFilterSystem
+)
+
+const MaxGroupRulesLength = groupmodel.MaxGroupRulesLength
+
 func NormalizeGroupID(groupID string) (string, error) {
 	return groupmodel.NormalizeGroupID(groupID)
 }
@@ -71,6 +111,50 @@ func ApplyGroupEvent(state *GroupState, event GroupEvent) (bool, error) {
 	return groupmodel.ApplyGroupEvent(state, event)
 }
 
+func ValidateChannelPost(group Group, member GroupMember, threadID string) error {
+	return groupmodel.ValidateChannelPost(group, member, threadID)
+}
+
+func ValidateMemberPost(group Group, member GroupMember, now time.Time) error {
+	return groupmodel.ValidateMemberPost(group, member, now)
+}
+
+func MemberPseudonym(group Group, memberID string) string {
+	return groupmodel.MemberPseudonym(group, memberID)
+}
+
+func CanSeeMembers(group Group, viewer GroupMember) bool {
+	return groupmodel.CanSeeMembers(group, viewer)
+}
+
+func ResolveMemberPseudonym(state GroupState, pseudonym string) (GroupMember, bool) {
+	return groupmodel.ResolveMemberPseudonym(state, pseudonym)
+}
+
+func NewPseudonymSalt() (string, error) {
+	return groupmodel.NewPseudonymSalt()
+}
+
+func ValidateEmergencyBroadcast(group Group, member GroupMember, threadID string) error {
+	return groupmodel.ValidateEmergencyBroadcast(group, member, threadID)
+}
+
+func ValidateOwnershipTransfer(state GroupState, actorID, newOwnerID string) error {
+	return groupmodel.ValidateOwnershipTransfer(state, actorID, newOwnerID)
+}
+
+func ParseGroupPostingMode(raw string) (GroupPostingMode, error) {
+	return groupmodel.ParseGroupPostingMode(raw)
+}
+
+func ParseGroupMessageFilter(raw string) (GroupMessageFilter, error) {
+	return groupmodel.ParseGroupMessageFilter(raw)
+}
+
+func BuildGroupActivityFeed(groupID string, events []GroupEvent) []GroupActivity {
+	return groupmodel.BuildGroupActivityFeed(groupID, events)
+}
+
 type AbuseProtection = grouppolicy.AbuseProtection
 
 type InboundGroupMessageRejectReason = grouppolicy.InboundGroupMessageRejectReason
@@ -78,15 +162,20 @@ type InboundGroupMessageRejectReason = grouppolicy.InboundGroupMessageRejectReas
 const (
 	InboundGroupMessageReasonMembershipVersionMismatch = grouppolicy.InboundGroupMessageReasonMembershipVersionMismatch
 	InboundGroupMessageReasonGroupKeyVersionMismatch   = grouppolicy.InboundGroupMessageReasonGroupKeyVersionMismatch
+	InboundGroupMessageReasonRulesNotAcknowledged      = grouppolicy.InboundGroupMessageReasonRulesNotAcknowledged
+	InboundGroupMessageReasonPostingRestricted         = grouppolicy.InboundGroupMessageReasonPostingRestricted
+	InboundGroupMessageReasonEmergencyDenied           = grouppolicy.InboundGroupMessageReasonEmergencyDenied
 )
 
 func ValidateInboundGroupMessageState(
 	state GroupState,
 	senderID string,
+	threadID string,
 	membershipVersion uint64,
 	groupKeyVersion uint32,
+	now time.Time,
 ) (InboundGroupMessageRejectReason, error) {
-	return grouppolicy.ValidateInboundGroupMessageState(state, senderID, membershipVersion, groupKeyVersion)
+	return grouppolicy.ValidateInboundGroupMessageState(state, senderID, threadID, membershipVersion, groupKeyVersion, now)
 }
 
 func EnsureInboundEventState(
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+)
+
+// CheckChannelEmergencyBroadcast reports whether this identity may send an
+// emergency broadcast to the channel, without consuming any rate budget.
+func (s *Service) CheckChannelEmergencyBroadcast(groupID string) error {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return err
+	}
+	state, ok := s.SnapshotStates()[groupID]
+	if !ok {
+		return ErrGroupNotFound
+	}
+	return ValidateEmergencyBroadcast(state.Group, state.Members[s.actorID()], "")
+}
+
+// SendChannelEmergencyBroadcast fans content out to every subscriber of a
+// channel this identity owns, marked so recipients alert for it even when
+// they muted the channel.
+func (s *Service) SendChannelEmergencyBroadcast(ctx context.Context, groupID, content string) (GroupMessageFanoutResult, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" || s.GenerateID == nil {
+		return GroupMessageFanoutResult{}, ErrInvalidGroupMessageContent
+	}
+	if err := ctx.Err(); err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	eventID, err := s.GenerateID("gevtmsg")
+	if err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	result, err := s.newFanoutService().SendEmergencyBroadcast(ctx, groupID, eventID, content)
+	if err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	s.recordAggregate("send")
+	s.logInfo(
+		"group emergency broadcast completed",
+		"correlation_id", CorrelationID(groupID, eventID),
+		"group_id", groupID,
+		"event_id", eventID,
+		"attempted", result.Attempted,
+		"delivered", result.Delivered,
+		"pending", result.Pending,
+		"failed", result.Failed,
+	)
+	if deliveryErr := result.DeliveryError(); deliveryErr.IsFullFailure() {
+		return result, deliveryErr
+	}
+	return result, nil
+}
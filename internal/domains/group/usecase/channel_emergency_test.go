@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	grouppolicy "aim-chat/go-backend/internal/domains/group/policy"
+	"aim-chat/go-backend/pkg/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func emergencyChannelState() GroupState {
+	return GroupState{
+		Group: Group{ID: "channel-1", Title: "[channel:public] alerts"},
+		Members: map[string]GroupMember{
+			"owner": {MemberID: "owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive},
+			"admin": {MemberID: "admin", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusActive},
+			"sub":   {MemberID: "sub", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+		},
+		Version: 3,
+	}
+}
+
+func TestGroupMessageFanout_EmergencyBroadcastOwnerOnlyAndRateLimited(t *testing.T) {
+	t.Setenv("AIM_GROUP_EMERGENCY_RATE_LIMIT_BURST", "1")
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	actor := "admin"
+	saved := map[string]models.Message{}
+	var published []GroupMessageWireMeta
+	service := &GroupMessageFanoutService{
+		States:         map[string]GroupState{"channel-1": emergencyChannelState()},
+		Abuse:          grouppolicy.NewAbuseProtectionFromEnv(),
+		IdentityID:     func() string { return actor },
+		ActiveDeviceID: func() (string, error) { return "dev-1", nil },
+		Now:            func() time.Time { return now },
+		GetMessage: func(id string) (models.Message, bool) {
+			m, ok := saved[id]
+			return m, ok
+		},
+		SaveMessage: func(msg models.Message) error {
+			saved[msg.ID] = msg
+			return nil
+		},
+		PrepareAndPublish: func(_ context.Context, msg models.Message, _ string, meta GroupMessageWireMeta) (string, string, error) {
+			published = append(published, meta)
+			return msg.ID, "", nil
+		},
+	}
+
+	if _, err := service.SendEmergencyBroadcast(context.Background(), "channel-1", "evt-admin", "alert"); !errors.Is(err, ErrGroupPermissionDenied) {
+		t.Fatalf("expected admin to be denied, got %v", err)
+	}
+
+	actor = "owner"
+	result, err := service.SendEmergencyBroadcast(context.Background(), "channel-1", "evt-1", "alert")
+	if err != nil || result.Attempted != 2 {
+		t.Fatalf("send emergency broadcast: %+v %v", result, err)
+	}
+	if sender := saved[DeriveRecipientMessageID("evt-1", "owner")]; !sender.Emergency {
+		t.Fatalf("expected sender copy to be marked emergency: %+v", sender)
+	}
+	if len(published) != 2 || !published[0].Emergency || !published[1].Emergency {
+		t.Fatalf("expected every transport to carry the emergency mark: %+v", published)
+	}
+	if _, err := service.SendEmergencyBroadcast(context.Background(), "channel-1", "evt-2", "again"); !errors.Is(err, ErrGroupRateLimitExceeded) {
+		t.Fatalf("expected emergency rate limit, got %v", err)
+	}
+	if _, err := service.SendGroupMessageFanout(context.Background(), "channel-1", "evt-3", "regular", ""); err != nil {
+		t.Fatalf("regular posts must not share the emergency budget: %v", err)
+	}
+}
+
+func TestInboundGroupMessage_EmergencyMarkOnlyFromOwner(t *testing.T) {
+	var stored []models.Message
+	service := &InboundOrchestrationService{
+		States: map[string]GroupState{"channel-1": emergencyChannelState()},
+		BuildStoredMessage: func(content []byte, contentType string, now time.Time) models.Message {
+			return models.Message{ID: "in-1", ConversationID: "channel-1", Content: content, ContentType: contentType, Timestamp: now}
+		},
+		SaveMessage: func(msg models.Message) error {
+			stored = append(stored, msg)
+			return nil
+		},
+	}
+	params := InboundGroupMessageParams{
+		MessageID:         "in-1",
+		SenderID:          "admin",
+		Payload:           []byte("alert"),
+		ConversationID:    "channel-1",
+		EventID:           "evt-1",
+		MembershipVersion: 3,
+		GroupKeyVersion:   1,
+		SenderDeviceID:    "dev-1",
+		Emergency:         true,
+	}
+
+	service.HandleInboundGroupMessage(params)
+	if len(stored) != 0 {
+		t.Fatalf("expected emergency post from an admin to be rejected, got %+v", stored)
+	}
+
+	params.SenderID = "owner"
+	service.HandleInboundGroupMessage(params)
+	if len(stored) != 1 || !stored[0].Emergency {
+		t.Fatalf("expected owner's emergency post to be stored marked, got %+v", stored)
+	}
+}
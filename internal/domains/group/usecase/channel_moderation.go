@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"aim-chat/go-backend/pkg/models"
+	"errors"
+	"strings"
+	"time"
+)
+
+// SetChannelComments turns subscriber comment threads on or off.
+func (s *MembershipService) SetChannelComments(groupID, actorID string, enabled bool, now time.Time, abuse *AbuseProtection) (Group, GroupEvent, error) {
+	groupID, actorID, state, err := s.loadModeratorState(groupID, actorID, now, abuse)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if !state.Group.IsChannel() {
+		return Group{}, GroupEvent{}, ErrGroupNotChannel
+	}
+	if state.Group.CommentsEnabled == enabled {
+		return state.Group, GroupEvent{}, nil
+	}
+	event := GroupEvent{
+		ID:              s.generateEventID(),
+		GroupID:         groupID,
+		Version:         state.Version + 1,
+		Type:            GroupEventTypeCommentsChange,
+		ActorID:         actorID,
+		OccurredAt:      now,
+		CommentsEnabled: enabled,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	return next.Group, event, nil
+}
+
+// SetGroupThreadLocked closes a comment thread to further replies or opens
+// it again. Owners and admins can still post in a locked thread.
+func (s *MembershipService) SetGroupThreadLocked(groupID, actorID, threadID string, locked bool, now time.Time, abuse *AbuseProtection) (Group, GroupEvent, error) {
+	threadID = strings.TrimSpace(threadID)
+	if threadID == "" {
+		return Group{}, GroupEvent{}, ErrInvalidGroupThreadID
+	}
+	groupID, actorID, state, err := s.loadModeratorState(groupID, actorID, now, abuse)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if !state.Group.IsChannel() {
+		return Group{}, GroupEvent{}, ErrGroupNotChannel
+	}
+	if state.Group.IsThreadLocked(threadID) == locked {
+		return state.Group, GroupEvent{}, nil
+	}
+	event := GroupEvent{
+		ID:           s.generateEventID(),
+		GroupID:      groupID,
+		Version:      state.Version + 1,
+		Type:         GroupEventTypeThreadLock,
+		ActorID:      actorID,
+		OccurredAt:   now,
+		ThreadID:     threadID,
+		ThreadLocked: locked,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	return next.Group, event, nil
+}
+
+// RemoveGroupMessage takes a message down for every member. Unlike a local
+// delete it is a versioned event, so only owners and admins may issue it.
+func (s *MembershipService) RemoveGroupMessage(groupID, actorID, messageEventID string, now time.Time, abuse *AbuseProtection) (GroupEvent, error) {
+	messageEventID = strings.TrimSpace(messageEventID)
+	if messageEventID == "" {
+		return GroupEvent{}, ErrInvalidGroupEventPayload
+	}
+	groupID, actorID, state, err := s.loadModeratorState(groupID, actorID, now, abuse)
+	if err != nil {
+		return GroupEvent{}, err
+	}
+	event := GroupEvent{
+		ID:             s.generateEventID(),
+		GroupID:        groupID,
+		Version:        state.Version + 1,
+		Type:           GroupEventTypeMessageRemove,
+		ActorID:        actorID,
+		OccurredAt:     now,
+		MessageEventID: messageEventID,
+	}
+	if _, err := s.applyEvent(state, event); err != nil {
+		return GroupEvent{}, err
+	}
+	return event, nil
+}
+
+// loadModeratorState normalizes the ids and loads the group for an owner or
+// admin acting on it.
+func (s *MembershipService) loadModeratorState(groupID, actorID string, now time.Time, abuse *AbuseProtection) (string, string, GroupState, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return "", "", GroupState{}, err
+	}
+	actorID, err = NormalizeGroupMemberID(actorID)
+	if err != nil {
+		return "", "", GroupState{}, err
+	}
+	if abuse != nil && !abuse.AllowMembership(actorID, now) {
+		return "", "", GroupState{}, ErrGroupRateLimitExceeded
+	}
+	state, err := LoadStateForActor(s.States, groupID, actorID, true)
+	if err != nil {
+		return "", "", GroupState{}, err
+	}
+	if !state.Members[actorID].CanManageMembers() {
+		return "", "", GroupState{}, ErrGroupPermissionDenied
+	}
+	return groupID, actorID, state, nil
+}
+
+// SetChannelComments enables or disables subscriber comments on a channel.
+// The event is empty when nothing changed.
+func (s *Service) SetChannelComments(groupID string, enabled bool) (Group, GroupEvent, error) {
+	var (
+		group Group
+		event GroupEvent
+	)
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		group, event, err = ms.SetChannelComments(groupID, s.actorID(), enabled, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if strings.TrimSpace(event.ID) != "" {
+		s.recordAggregate("comments_update")
+		s.logInfo(
+			"channel comments updated",
+			"correlation_id", CorrelationID(groupID, event.ID),
+			"group_id", groupID,
+			"actor_id", s.actorID(),
+			"enabled", enabled,
+		)
+	}
+	return group, event, nil
+}
+
+func (s *Service) SetGroupThreadLocked(groupID, threadID string, locked bool) (Group, GroupEvent, error) {
+	var (
+		group Group
+		event GroupEvent
+	)
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		group, event, err = ms.SetGroupThreadLocked(groupID, s.actorID(), threadID, locked, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if strings.TrimSpace(event.ID) != "" {
+		s.recordAggregate("thread_lock")
+		s.logInfo(
+			"group thread lock updated",
+			"correlation_id", CorrelationID(groupID, event.ID),
+			"group_id", groupID,
+			"actor_id", s.actorID(),
+			"thread_id", event.ThreadID,
+			"locked", locked,
+		)
+	}
+	return group, event, nil
+}
+
+// RemoveGroupMessage takes down the message with the given local id for all
+// members. The caller deletes the local copies when the event is applied.
+func (s *Service) RemoveGroupMessage(groupID, messageID string) (GroupEvent, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return GroupEvent{}, err
+	}
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" || s.GetMessage == nil {
+		return GroupEvent{}, ErrInvalidGroupEventPayload
+	}
+	msg, ok := s.GetMessage(messageID)
+	if !ok {
+		return GroupEvent{}, errors.New("message not found")
+	}
+	if msg.ConversationType != models.ConversationTypeGroup || strings.TrimSpace(msg.ConversationID) != groupID {
+		return GroupEvent{}, errors.New("message does not belong to group")
+	}
+	if strings.TrimSpace(msg.EventID) == "" {
+		return GroupEvent{}, ErrInvalidGroupEventPayload
+	}
+	var event GroupEvent
+	err = s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		event, err = ms.RemoveGroupMessage(groupID, s.actorID(), msg.EventID, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return GroupEvent{}, err
+	}
+	s.recordAggregate("message_remove")
+	s.logInfo(
+		"group message removed",
+		"correlation_id", CorrelationID(groupID, event.ID),
+		"group_id", groupID,
+		"actor_id", s.actorID(),
+		"message_event_id", event.MessageEventID,
+	)
+	return event, nil
+}
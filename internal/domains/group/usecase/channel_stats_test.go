@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"aim-chat/go-backend/pkg/models"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupReadService_ChannelStatsAggregatesRecentBroadcasts(t *testing.T) {
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	saved := map[string]models.Message{}
+	var timeline []models.Message
+	addPost := func(eventID string, at time.Time, statuses map[string]string) string {
+		id := DeriveRecipientMessageID(eventID, "owner")
+		msg := models.Message{
+			ID: id, ContactID: "owner", ConversationID: "channel-1", ConversationType: models.ConversationTypeGroup,
+			EventID: eventID, Direction: "out", Status: "sent", ContentType: "text", Timestamp: at,
+		}
+		saved[id] = msg
+		timeline = append(timeline, msg)
+		for memberID, status := range statuses {
+			transportID := DeriveRecipientMessageID(eventID, memberID)
+			saved[transportID] = models.Message{ID: transportID, ContactID: memberID, Status: status, ContentType: groupFanoutTransportContentType}
+		}
+		return id
+	}
+	addPost("evt-1", base, map[string]string{"sub-a": "read", "sub-b": "read", "admin": "read"})
+	second := addPost("evt-2", base.Add(time.Minute), map[string]string{"sub-a": "read", "sub-b": "delivered", "admin": "failed"})
+	third := addPost("evt-3", base.Add(2*time.Minute), map[string]string{"sub-a": "pending"})
+	timeline = append(timeline,
+		models.Message{ID: "comment", ConversationID: "channel-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-4", Direction: "out", ThreadID: "evt-1", Timestamp: base.Add(3 * time.Minute)},
+		models.Message{ID: "inbound", ConversationID: "channel-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-5", Direction: "in", Timestamp: base.Add(4 * time.Minute)},
+		models.Message{ID: "system", ConversationID: "channel-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-6", Direction: "out", ContentType: models.MessageContentTypeSystem, Timestamp: base.Add(5 * time.Minute)},
+	)
+
+	read := &GroupReadService{
+		States: map[string]GroupState{
+			"channel-1": {
+				Group: Group{ID: "channel-1", Title: "[channel:public] news"},
+				Members: map[string]GroupMember{
+					"owner": {MemberID: "owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive},
+					"admin": {MemberID: "admin", Role: GroupMemberRoleAdmin, Status: GroupMemberStatusActive},
+					"sub-a": {MemberID: "sub-a", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+					"sub-b": {MemberID: "sub-b", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+					"left":  {MemberID: "left", Role: GroupMemberRoleUser, Status: GroupMemberStatusLeft},
+				},
+			},
+			"group-1": {
+				Group:   Group{ID: "group-1", Title: "general"},
+				Members: map[string]GroupMember{"owner": {MemberID: "owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive}},
+			},
+		},
+		GetMessage: func(id string) (models.Message, bool) {
+			m, ok := saved[id]
+			return m, ok
+		},
+		ListMessagesByConversation: func(conversationID, conversationType string, limit, offset int) []models.Message {
+			if conversationID != "channel-1" {
+				return nil
+			}
+			return timeline
+		},
+	}
+
+	stats, err := read.GetChannelStats("channel-1", "owner", 2)
+	if err != nil {
+		t.Fatalf("get channel stats: %v", err)
+	}
+	if stats.SubscriberCount != 2 || stats.AdminCount != 2 || stats.BroadcastCount != 3 {
+		t.Fatalf("unexpected audience counts: %+v", stats)
+	}
+	if len(stats.Broadcasts) != 2 || stats.Broadcasts[0].MessageID != third || stats.Broadcasts[1].MessageID != second {
+		t.Fatalf("expected two newest broadcasts, got %+v", stats.Broadcasts)
+	}
+	if got := stats.Broadcasts[0]; got.RecipientCount != 1 || got.PendingCount != 1 || got.DeliveredCount != 0 {
+		t.Fatalf("unexpected newest broadcast reach: %+v", got)
+	}
+	if got := stats.Broadcasts[1]; got.RecipientCount != 3 || got.DeliveredCount != 2 || got.ReadCount != 1 || got.FailedCount != 1 {
+		t.Fatalf("unexpected second broadcast reach: %+v", got)
+	}
+	raw, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("marshal stats: %v", err)
+	}
+	if strings.Contains(string(raw), "sub-a") || strings.Contains(string(raw), "sub-b") {
+		t.Fatalf("stats must not expose subscriber ids: %s", raw)
+	}
+
+	if _, err := read.GetChannelStats("channel-1", "sub-a", 0); !errors.Is(err, ErrGroupPermissionDenied) {
+		t.Fatalf("expected permission denied for subscriber, got %v", err)
+	}
+	if _, err := read.GetChannelStats("group-1", "owner", 0); !errors.Is(err, ErrGroupNotChannel) {
+		t.Fatalf("expected not-channel error, got %v", err)
+	}
+}
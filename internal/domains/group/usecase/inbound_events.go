@@ -73,6 +73,52 @@ func AuthorizeInboundGroupEvent(state GroupState, event GroupEvent) error {
 			return ErrGroupPermissionDenied
 		}
 		return nil
+	case GroupEventTypeRulesChange:
+		if !actorExists || actor.Status != GroupMemberStatusActive {
+			return ErrGroupPermissionDenied
+		}
+		if !actor.CanManageMembers() {
+			return ErrGroupPermissionDenied
+		}
+		if event.RulesVersion <= state.Group.RulesVersion {
+			return ErrOutOfOrderGroupEvent
+		}
+		return nil
+	case GroupEventTypeRulesAck:
+		if !actorExists || actor.Status != GroupMemberStatusActive {
+			return ErrGroupPermissionDenied
+		}
+		if event.RulesVersion != state.Group.RulesVersion {
+			return ErrOutOfOrderGroupEvent
+		}
+		return nil
+	case GroupEventTypeMemberMute:
+		if !actorExists || actor.Status != GroupMemberStatusActive {
+			return ErrGroupPermissionDenied
+		}
+		target, targetExists := state.Members[event.MemberID]
+		if !targetExists {
+			return ErrGroupMembershipNotFound
+		}
+		if !actor.CanModerate(target) {
+			return ErrGroupPermissionDenied
+		}
+		return nil
+	case GroupEventTypeCommentsChange, GroupEventTypeThreadLock, GroupEventTypeMessageRemove, GroupEventTypePostingModeChange:
+		if !actorExists || actor.Status != GroupMemberStatusActive {
+			return ErrGroupPermissionDenied
+		}
+		if !actor.CanManageMembers() {
+			return ErrGroupPermissionDenied
+		}
+		return nil
+	case GroupEventTypeOwnershipTransfer:
+		return ValidateOwnershipTransfer(state, event.ActorID, event.MemberID)
+	case GroupEventTypeMemberPrivacyChange:
+		if !actorExists || actor.Status != GroupMemberStatusActive || !actor.IsOwner() {
+			return ErrGroupPermissionDenied
+		}
+		return nil
 	case GroupEventTypeKeyRotate:
 		if !actorExists || actor.Status != GroupMemberStatusActive {
 			return ErrGroupPermissionDenied
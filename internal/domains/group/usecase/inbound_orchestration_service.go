@@ -12,10 +12,12 @@ type InboundGroupMessageParams struct {
 	SenderID          string
 	Payload           []byte
 	ConversationID    string
+	ThreadID          string
 	EventID           string
 	MembershipVersion uint64
 	GroupKeyVersion   uint32
 	SenderDeviceID    string
+	Emergency         bool
 }
 
 type InboundGroupEventParams struct {
@@ -33,6 +35,7 @@ type InboundOrchestrationService struct {
 	Now                   func() time.Time
 	IdentityID            func() string
 	IsBlockedSender       func(string) bool
+	IsBlockedInGroup      func(groupID, memberID string) bool
 	GuardReplay           func(kind, groupID, senderDeviceID, uniqueID string, occurredAt, now time.Time) error
 	ResolveInboundContent func() ([]byte, string, error)
 	BuildStoredMessage    func(content []byte, contentType string, now time.Time) models.Message
@@ -67,11 +70,20 @@ func (s *InboundOrchestrationService) HandleInboundGroupMessage(in InboundGroupM
 		s.warn("group message rejected", "reason", "unknown_group", "correlation_id", correlationID, "group_id", in.ConversationID, "event_id", in.EventID, "actor_id", in.SenderID)
 		return
 	}
+	// A member blocked in this group only is hidden without an error: the
+	// message is valid, the user just does not want to see it.
+	if s.IsBlockedInGroup != nil && s.IsBlockedInGroup(state.Group.ID, in.SenderID) {
+		s.recordAggregate("member_hidden")
+		s.debug("group message hidden", "reason", "blocked_in_group", "correlation_id", correlationID, "group_id", in.ConversationID, "event_id", in.EventID, "actor_id", in.SenderID)
+		return
+	}
 	reason, err := ValidateInboundGroupMessageState(
 		state,
 		strings.TrimSpace(in.SenderID),
+		in.ThreadID,
 		in.MembershipVersion,
 		in.GroupKeyVersion,
+		now,
 	)
 	if err != nil {
 		s.recordErr("crypto", err)
@@ -96,6 +108,16 @@ func (s *InboundOrchestrationService) HandleInboundGroupMessage(in InboundGroupM
 		s.warn("group message rejected", logArgs...)
 		return
 	}
+	// Emergency posts bypass recipients' mutes, so the mark is honoured
+	// only from the channel owner.
+	if in.Emergency {
+		if err := ValidateEmergencyBroadcast(state.Group, state.Members[strings.TrimSpace(in.SenderID)], in.ThreadID); err != nil {
+			s.recordErr("crypto", err)
+			s.recordAggregate("policy_reject")
+			s.warn("group message rejected", "reason", InboundGroupMessageReasonEmergencyDenied, "correlation_id", correlationID, "group_id", in.ConversationID, "event_id", in.EventID, "actor_id", in.SenderID)
+			return
+		}
+	}
 	replayID := strings.TrimSpace(in.EventID)
 	if replayID == "" {
 		replayID = strings.TrimSpace(in.MessageID)
@@ -127,6 +149,10 @@ func (s *InboundOrchestrationService) HandleInboundGroupMessage(in InboundGroupM
 		return
 	}
 	stored := s.BuildStoredMessage(content, contentType, now)
+	stored.Emergency = in.Emergency
+	if state.Group.HiddenMembers {
+		stored.ContactID = MemberPseudonym(state.Group, in.SenderID)
+	}
 	if err := s.SaveMessage(stored); err != nil {
 		if s.IsMessageIDConflict != nil && s.IsMessageIDConflict(err) {
 			s.warn("inbound group message id conflict ignored", "message_id", stored.ID, "group_id", stored.ConversationID)
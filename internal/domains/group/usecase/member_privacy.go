@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+)
+
+// SetGroupMemberPrivacy hides the member list from everyone but owners and
+// admins, attributing messages to pseudonyms, or shows it again. Only the
+// owner may change it, as only the owner may map pseudonyms back.
+func (s *MembershipService) SetGroupMemberPrivacy(groupID, actorID string, hidden bool, now time.Time, abuse *AbuseProtection) (Group, GroupEvent, error) {
+	groupID, actorID, state, err := s.loadModeratorState(groupID, actorID, now, abuse)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if !state.Members[actorID].IsOwner() {
+		return Group{}, GroupEvent{}, ErrGroupPermissionDenied
+	}
+	if state.Group.HiddenMembers == hidden {
+		return state.Group, GroupEvent{}, nil
+	}
+	salt := state.Group.PseudonymSalt
+	if hidden && salt == "" {
+		if salt, err = NewPseudonymSalt(); err != nil {
+			return Group{}, GroupEvent{}, err
+		}
+	}
+	event := GroupEvent{
+		ID:            s.generateEventID(),
+		GroupID:       groupID,
+		Version:       state.Version + 1,
+		Type:          GroupEventTypeMemberPrivacyChange,
+		ActorID:       actorID,
+		OccurredAt:    now,
+		HiddenMembers: hidden,
+		PseudonymSalt: salt,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	return next.Group, event, nil
+}
+
+// SetGroupMemberPrivacy switches hidden members on or off. The event is
+// empty when nothing changed.
+func (s *Service) SetGroupMemberPrivacy(groupID string, hidden bool) (Group, GroupEvent, error) {
+	var (
+		group Group
+		event GroupEvent
+	)
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		group, event, err = ms.SetGroupMemberPrivacy(groupID, s.actorID(), hidden, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if strings.TrimSpace(event.ID) != "" {
+		s.recordAggregate("member_privacy_update")
+		s.logInfo(
+			"group member privacy updated",
+			"correlation_id", CorrelationID(groupID, event.ID),
+			"group_id", groupID,
+			"actor_id", s.actorID(),
+			"hidden_members", hidden,
+		)
+	}
+	return group, event, nil
+}
+
+// ResolveGroupPseudonym tells the owner which member a pseudonym stands for.
+// Nobody else may map pseudonyms back to identities.
+func (s *Service) ResolveGroupPseudonym(groupID, pseudonym string) (GroupMember, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return GroupMember{}, err
+	}
+	state, ok := s.SnapshotStates()[groupID]
+	if !ok {
+		return GroupMember{}, ErrGroupNotFound
+	}
+	actor, ok := state.Members[s.actorID()]
+	if !ok || actor.Status != GroupMemberStatusActive || !actor.IsOwner() {
+		return GroupMember{}, ErrGroupPermissionDenied
+	}
+	member, ok := ResolveMemberPseudonym(state, pseudonym)
+	if !ok {
+		return GroupMember{}, ErrGroupPseudonymNotFound
+	}
+	return member, nil
+}
@@ -74,6 +74,8 @@ func (s *MembershipService) CreateGroup(
 		OccurredAt: now,
 		MemberID:   identityID,
 		Role:       GroupMemberRoleOwner,
+		// Carried so that replaying the log knows the initial title.
+		Title: title,
 	}
 	if _, err := ApplyGroupEvent(&state, event); err != nil {
 		return Group{}, GroupEvent{}, err
@@ -282,6 +284,86 @@ func (s *MembershipService) UpdateGroupProfile(
 	return next.Group, event, nil
 }
 
+// SetGroupRules replaces the group rules. Members other than the author must
+// acknowledge the new version before they can post again; empty rules lift
+// the requirement.
+func (s *MembershipService) SetGroupRules(groupID, actorID, rules string, now time.Time, abuse *AbuseProtection) (Group, GroupEvent, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	actorID, err = NormalizeGroupMemberID(actorID)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	rules = strings.TrimSpace(rules)
+	if len(rules) > MaxGroupRulesLength {
+		return Group{}, GroupEvent{}, ErrInvalidGroupRules
+	}
+	if abuse != nil && !abuse.AllowMembership(actorID, now) {
+		return Group{}, GroupEvent{}, ErrGroupRateLimitExceeded
+	}
+	state, err := LoadStateForActor(s.States, groupID, actorID, true)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	if !state.Members[actorID].CanManageMembers() {
+		return Group{}, GroupEvent{}, ErrGroupPermissionDenied
+	}
+	if state.Group.Rules == rules {
+		return state.Group, GroupEvent{}, nil
+	}
+	event := GroupEvent{
+		ID:           s.generateEventID(),
+		GroupID:      groupID,
+		Version:      state.Version + 1,
+		Type:         GroupEventTypeRulesChange,
+		ActorID:      actorID,
+		OccurredAt:   now,
+		Rules:        rules,
+		RulesVersion: state.Group.RulesVersion + 1,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return Group{}, GroupEvent{}, err
+	}
+	return next.Group, event, nil
+}
+
+// AcknowledgeGroupRules records that the actor accepted the current rules.
+func (s *MembershipService) AcknowledgeGroupRules(groupID, actorID string, now time.Time) (GroupMember, GroupEvent, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	actorID, err = NormalizeGroupMemberID(actorID)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	state, err := LoadStateForActor(s.States, groupID, actorID, true)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	member := state.Members[actorID]
+	if !member.NeedsRulesAck(state.Group) {
+		return member, GroupEvent{}, nil
+	}
+	event := GroupEvent{
+		ID:           s.generateEventID(),
+		GroupID:      groupID,
+		Version:      state.Version + 1,
+		Type:         GroupEventTypeRulesAck,
+		ActorID:      actorID,
+		OccurredAt:   now,
+		RulesVersion: state.Group.RulesVersion,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	return next.Members[actorID], event, nil
+}
+
 func (s *MembershipService) DeleteGroup(groupID, actorID string, now time.Time, abuse *AbuseProtection) (bool, error) {
 	groupID, err := NormalizeGroupID(groupID)
 	if err != nil {
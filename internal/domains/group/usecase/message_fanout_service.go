@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	grouppolicy "aim-chat/go-backend/internal/domains/group/policy"
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"math/rand"
 	"strings"
 	"time"
@@ -13,6 +15,7 @@ type GroupMessageWireMeta struct {
 	MembershipVersion uint64
 	GroupKeyVersion   uint32
 	SenderDeviceID    string
+	Emergency         bool
 }
 
 type GroupMessageFanoutService struct {
@@ -26,12 +29,18 @@ type GroupMessageFanoutService struct {
 	IsBlockedSender    func(string) bool
 	GetMessage         func(string) (models.Message, bool)
 	SaveMessage        func(models.Message) error
-	PrepareAndPublish  func(msg models.Message, recipientID string, meta GroupMessageWireMeta) (sentID string, category string, err error)
+	PrepareAndPublish  func(ctx context.Context, msg models.Message, recipientID string, meta GroupMessageWireMeta) (sentID string, category string, err error)
 	RecordError        func(category string, err error)
 	NotifyGroupMessage func(groupID string, msg models.Message)
 }
 
-const groupFanoutTransportContentType = "group_fanout_transport"
+const groupFanoutTransportContentType = grouppolicy.GroupFanoutTransportContentType
+
+// Recipient failure categories; they match the daemon-wide error categories.
+const (
+	groupFanoutFailureStorage = "storage"
+	groupFanoutFailureNetwork = "network"
+)
 
 type fanoutContext struct {
 	groupID         string
@@ -43,30 +52,69 @@ type fanoutContext struct {
 	now             time.Time
 	state           GroupState
 	groupKeyVersion uint32
+	emergency       bool
+}
+
+// SendGroupMessageFanout stores the message for every recipient and
+// publishes it. Recipients still left when ctx ends are queued for retry.
+func (s *GroupMessageFanoutService) SendGroupMessageFanout(ctx context.Context, groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
+	fc, err := s.prepareFanoutContext(groupID, eventID, content, threadID, false)
+	if err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	return s.fanout(ctx, fc)
 }
 
-func (s *GroupMessageFanoutService) SendGroupMessageFanout(groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
-	ctx, err := s.prepareFanoutContext(groupID, eventID, content, threadID)
+// SendEmergencyBroadcast fans out an emergency post to the main feed of a
+// channel the actor owns. It is rate limited on its own budget instead of
+// the regular send limit.
+func (s *GroupMessageFanoutService) SendEmergencyBroadcast(ctx context.Context, groupID, eventID, content string) (GroupMessageFanoutResult, error) {
+	fc, err := s.prepareFanoutContext(groupID, eventID, content, "", true)
 	if err != nil {
 		return GroupMessageFanoutResult{}, err
 	}
-	recipients := s.collectRecipients(ctx.state, ctx.actorID, ctx.now)
+	return s.fanout(ctx, fc)
+}
+
+func (s *GroupMessageFanoutService) fanout(ctx context.Context, fc fanoutContext) (GroupMessageFanoutResult, error) {
+	recipients := s.collectRecipients(fc.state, fc.actorID, fc.now)
 	result := GroupMessageFanoutResult{
-		GroupID:    ctx.groupID,
-		EventID:    ctx.eventID,
+		GroupID:    fc.groupID,
+		EventID:    fc.eventID,
 		Attempted:  len(recipients),
 		Recipients: make([]GroupMessageRecipientStatus, 0, len(recipients)),
 	}
-	s.persistSenderMessage(ctx)
+	s.persistSenderMessage(fc)
 	for _, recipientID := range recipients {
-		if err := s.processRecipient(ctx, recipientID, &result); err != nil {
+		if err := s.processRecipient(ctx, fc, recipientID, &result); err != nil {
 			return GroupMessageFanoutResult{}, err
 		}
 	}
+	if !CanSeeMembers(fc.state.Group, fc.state.Members[fc.actorID]) {
+		pseudonymizeFanoutResult(fc.state.Group, &result)
+	}
 	return result, nil
 }
 
-func (s *GroupMessageFanoutService) prepareFanoutContext(groupID, eventID, content, threadID string) (fanoutContext, error) {
+// pseudonymizeFanoutResult replaces recipient ids with their pseudonyms, so
+// sending to a group with hidden members does not reveal who is in it. The
+// transport message ids go too: they are derived from the recipient ids.
+func pseudonymizeFanoutResult(group Group, result *GroupMessageFanoutResult) {
+	for i := range result.Recipients {
+		result.Recipients[i].RecipientID = MemberPseudonym(group, result.Recipients[i].RecipientID)
+		result.Recipients[i].MessageID = ""
+	}
+	if result.Failures == nil {
+		return
+	}
+	failures := make(map[string]string, len(result.Failures))
+	for recipientID, category := range result.Failures {
+		failures[MemberPseudonym(group, recipientID)] = category
+	}
+	result.Failures = failures
+}
+
+func (s *GroupMessageFanoutService) prepareFanoutContext(groupID, eventID, content, threadID string, emergency bool) (fanoutContext, error) {
 	normalizedGroupID, err := NormalizeGroupID(groupID)
 	if err != nil {
 		return fanoutContext{}, err
@@ -84,7 +132,11 @@ func (s *GroupMessageFanoutService) prepareFanoutContext(groupID, eventID, conte
 		return fanoutContext{}, err
 	}
 	now := s.resolveNow()
-	if s.Abuse != nil && !s.Abuse.AllowSend(actorID, now) {
+	if emergency {
+		if s.Abuse != nil && !s.Abuse.AllowEmergency(actorID, now) {
+			return fanoutContext{}, ErrGroupRateLimitExceeded
+		}
+	} else if s.Abuse != nil && !s.Abuse.AllowSend(actorID, now) {
 		return fanoutContext{}, ErrGroupRateLimitExceeded
 	}
 	if s.ActiveDeviceID == nil {
@@ -98,7 +150,11 @@ func (s *GroupMessageFanoutService) prepareFanoutContext(groupID, eventID, conte
 	if !ok {
 		return fanoutContext{}, ErrGroupNotFound
 	}
-	if err := validateActorCanFanout(state, actorID); err != nil {
+	if emergency {
+		if err := ValidateEmergencyBroadcast(state.Group, state.Members[actorID], threadID); err != nil {
+			return fanoutContext{}, err
+		}
+	} else if err := validateActorCanFanout(state, actorID, threadID, now); err != nil {
 		return fanoutContext{}, err
 	}
 	groupKeyVersion := state.LastKeyVersion
@@ -115,6 +171,7 @@ func (s *GroupMessageFanoutService) prepareFanoutContext(groupID, eventID, conte
 		now:             now,
 		state:           state,
 		groupKeyVersion: groupKeyVersion,
+		emergency:       emergency,
 	}, nil
 }
 
@@ -147,13 +204,19 @@ func (s *GroupMessageFanoutService) resolveNow() time.Time {
 	return s.Now().UTC()
 }
 
-func validateActorCanFanout(state GroupState, actorID string) error {
+func validateActorCanFanout(state GroupState, actorID, threadID string, now time.Time) error {
 	actor, ok := state.Members[actorID]
 	if !ok || actor.Status != GroupMemberStatusActive {
 		return ErrGroupPermissionDenied
 	}
-	if isChannelGroupTitle(state.Group.Title) && actor.Role != GroupMemberRoleOwner && actor.Role != GroupMemberRoleAdmin {
-		return ErrGroupPermissionDenied
+	if err := ValidateChannelPost(state.Group, actor, threadID); err != nil {
+		return err
+	}
+	if err := ValidateMemberPost(state.Group, actor, now); err != nil {
+		return err
+	}
+	if actor.NeedsRulesAck(state.Group) {
+		return ErrGroupRulesNotAcknowledged
 	}
 	return nil
 }
@@ -177,30 +240,32 @@ func (s *GroupMessageFanoutService) collectRecipients(state GroupState, actorID
 	return recipients
 }
 
-func (s *GroupMessageFanoutService) persistSenderMessage(ctx fanoutContext) {
+func (s *GroupMessageFanoutService) persistSenderMessage(fc fanoutContext) {
 	if s.SaveMessage == nil {
 		return
 	}
-	senderMessageID := DeriveRecipientMessageID(ctx.eventID, ctx.actorID)
+	senderMessageID := DeriveRecipientMessageID(fc.eventID, fc.actorID)
 	if s.GetMessage != nil {
 		if existing, exists := s.GetMessage(senderMessageID); exists {
 			if s.NotifyGroupMessage != nil {
-				s.NotifyGroupMessage(ctx.groupID, existing)
+				s.NotifyGroupMessage(fc.groupID, existing)
 			}
 			return
 		}
 	}
 	senderMsg := models.Message{
 		ID:               senderMessageID,
-		ContactID:        ctx.actorID,
-		ConversationID:   ctx.groupID,
+		ContactID:        fc.actorID,
+		ConversationID:   fc.groupID,
 		ConversationType: models.ConversationTypeGroup,
-		ThreadID:         ctx.threadID,
-		Content:          []byte(ctx.content),
-		Timestamp:        ctx.now,
+		ThreadID:         fc.threadID,
+		EventID:          fc.eventID,
+		Content:          []byte(fc.content),
+		Timestamp:        fc.now,
 		Direction:        "out",
 		Status:           "sent",
 		ContentType:      "text",
+		Emergency:        fc.emergency,
 	}
 	if err := s.SaveMessage(senderMsg); err != nil {
 		if s.RecordError != nil {
@@ -209,12 +274,12 @@ func (s *GroupMessageFanoutService) persistSenderMessage(ctx fanoutContext) {
 		return
 	}
 	if s.NotifyGroupMessage != nil {
-		s.NotifyGroupMessage(ctx.groupID, senderMsg)
+		s.NotifyGroupMessage(fc.groupID, senderMsg)
 	}
 }
 
-func (s *GroupMessageFanoutService) processRecipient(ctx fanoutContext, recipientID string, result *GroupMessageFanoutResult) error {
-	messageID := DeriveRecipientMessageID(ctx.eventID, recipientID)
+func (s *GroupMessageFanoutService) processRecipient(ctx context.Context, fc fanoutContext, recipientID string, result *GroupMessageFanoutResult) error {
+	messageID := DeriveRecipientMessageID(fc.eventID, recipientID)
 	if s.GetMessage != nil {
 		if existing, exists := s.GetMessage(messageID); exists {
 			result.Recipients = append(result.Recipients, GroupMessageRecipientStatus{
@@ -237,37 +302,43 @@ func (s *GroupMessageFanoutService) processRecipient(ctx fanoutContext, recipien
 	msg := models.Message{
 		ID:               messageID,
 		ContactID:        recipientID,
-		ConversationID:   ctx.groupID,
+		ConversationID:   fc.groupID,
 		ConversationType: models.ConversationTypeGroup,
-		ThreadID:         ctx.threadID,
-		Content:          []byte(ctx.content),
-		Timestamp:        ctx.now,
+		ThreadID:         fc.threadID,
+		EventID:          fc.eventID,
+		Content:          []byte(fc.content),
+		Timestamp:        fc.now,
 		Direction:        "out",
 		Status:           "pending",
 		ContentType:      groupFanoutTransportContentType,
+		Emergency:        fc.emergency,
 	}
 	if err := s.SaveMessage(msg); err != nil {
 		if s.RecordError != nil {
-			s.RecordError("storage", err)
+			s.RecordError(groupFanoutFailureStorage, err)
 		}
-		s.appendRecipientFailure(result, recipientID, messageID, err)
+		s.appendRecipientFailure(result, recipientID, messageID, groupFanoutFailureStorage, err)
 		return nil
 	}
 	if s.PrepareAndPublish == nil {
 		return ErrGroupNotFound
 	}
-	sentID, category, err := s.PrepareAndPublish(msg, recipientID, GroupMessageWireMeta{
-		GroupID:           ctx.groupID,
-		EventID:           ctx.eventID,
-		MembershipVersion: ctx.state.Version,
-		GroupKeyVersion:   ctx.groupKeyVersion,
-		SenderDeviceID:    ctx.deviceID,
+	sentID, category, err := s.PrepareAndPublish(ctx, msg, recipientID, GroupMessageWireMeta{
+		GroupID:           fc.groupID,
+		EventID:           fc.eventID,
+		MembershipVersion: fc.state.Version,
+		GroupKeyVersion:   fc.groupKeyVersion,
+		SenderDeviceID:    fc.deviceID,
+		Emergency:         fc.emergency,
 	})
 	if err != nil {
 		if category != "" && s.RecordError != nil {
 			s.RecordError(category, err)
 		}
-		s.appendRecipientFailure(result, recipientID, messageID, err)
+		if category == "" {
+			category = groupFanoutFailureNetwork
+		}
+		s.appendRecipientFailure(result, recipientID, messageID, category, err)
 		return nil
 	}
 	statusValue := "sent"
@@ -293,18 +364,19 @@ func (s *GroupMessageFanoutService) appendRecipientFailure(
 	result *GroupMessageFanoutResult,
 	recipientID string,
 	messageID string,
+	category string,
 	err error,
 ) {
 	result.Failed++
 	result.Recipients = append(result.Recipients, GroupMessageRecipientStatus{
-		RecipientID: recipientID,
-		MessageID:   messageID,
-		Status:      "failed",
-		Error:       err.Error(),
+		RecipientID:   recipientID,
+		MessageID:     messageID,
+		Status:        "failed",
+		Error:         err.Error(),
+		ErrorCategory: category,
 	})
-}
-
-func isChannelGroupTitle(title string) bool {
-	normalized := strings.ToLower(strings.TrimSpace(title))
-	return strings.HasPrefix(normalized, "[channel")
+	if result.Failures == nil {
+		result.Failures = make(map[string]string)
+	}
+	result.Failures[recipientID] = category
 }
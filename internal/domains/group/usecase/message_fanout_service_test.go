@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -23,7 +24,7 @@ func TestGroupMessageFanout_ChannelRequiresAdminOrOwner(t *testing.T) {
 		Now:            func() time.Time { return now },
 	}
 
-	_, err := service.SendGroupMessageFanout("group-1", "evt-1", "hello", "")
+	_, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", "")
 	if !errors.Is(err, ErrGroupPermissionDenied) {
 		t.Fatalf("expected ErrGroupPermissionDenied, got %v", err)
 	}
@@ -60,7 +61,7 @@ func TestGroupMessageFanout_MixedDuplicateAndDeliveredStatuses(t *testing.T) {
 			saved[msg.ID] = msg
 			return nil
 		},
-		PrepareAndPublish: func(msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
+		PrepareAndPublish: func(_ context.Context, msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
 			if recipientID != "recipient-delivered" {
 				t.Fatalf("unexpected recipient %s", recipientID)
 			}
@@ -69,7 +70,7 @@ func TestGroupMessageFanout_MixedDuplicateAndDeliveredStatuses(t *testing.T) {
 		},
 	}
 
-	result, err := service.SendGroupMessageFanout("group-1", "evt-1", "hello", "thread-1")
+	result, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", "thread-1")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -110,12 +111,12 @@ func TestGroupMessageFanout_SaveFailureMarksRecipientFailed(t *testing.T) {
 			}
 			return nil
 		},
-		PrepareAndPublish: func(msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
+		PrepareAndPublish: func(_ context.Context, msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
 			return msg.ID, "", nil
 		},
 	}
 
-	result, err := service.SendGroupMessageFanout("group-1", "evt-1", "hello", "")
+	result, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -123,3 +124,181 @@ func TestGroupMessageFanout_SaveFailureMarksRecipientFailed(t *testing.T) {
 		t.Fatalf("unexpected result: %+v", result)
 	}
 }
+
+func TestGroupReadService_AggregatesFanoutReceipts(t *testing.T) {
+	senderMsgID := DeriveRecipientMessageID("evt-1", "actor")
+	readID := DeriveRecipientMessageID("evt-1", "member-read")
+	deliveredID := DeriveRecipientMessageID("evt-1", "member-delivered")
+	pendingID := DeriveRecipientMessageID("evt-1", "member-pending")
+	saved := map[string]models.Message{
+		senderMsgID: {ID: senderMsgID, ContactID: "actor", ConversationID: "group-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-1", Direction: "out", Status: "sent", ContentType: "text"},
+		readID:      {ID: readID, ContactID: "member-read", Status: "read", ContentType: groupFanoutTransportContentType},
+		deliveredID: {ID: deliveredID, ContactID: "member-delivered", Status: "delivered", ContentType: groupFanoutTransportContentType},
+		pendingID:   {ID: pendingID, ContactID: "member-pending", Status: "pending", ContentType: groupFanoutTransportContentType},
+	}
+	read := &GroupReadService{
+		States: map[string]GroupState{
+			"group-1": {
+				Group: Group{ID: "group-1", Title: "general"},
+				Members: map[string]GroupMember{
+					"actor":            {MemberID: "actor", Status: GroupMemberStatusActive},
+					"member-read":      {MemberID: "member-read", Status: GroupMemberStatusActive},
+					"member-delivered": {MemberID: "member-delivered", Status: GroupMemberStatusActive},
+					"member-pending":   {MemberID: "member-pending", Status: GroupMemberStatusActive},
+					"member-late":      {MemberID: "member-late", Status: GroupMemberStatusActive},
+				},
+			},
+		},
+		GetMessage: func(id string) (models.Message, bool) {
+			m, ok := saved[id]
+			return m, ok
+		},
+	}
+
+	status, err := read.GetGroupMessageStatus("group-1", senderMsgID)
+	if err != nil {
+		t.Fatalf("get group message status: %v", err)
+	}
+	if status.RecipientCount != 3 || status.DeliveredCount != 2 || status.ReadCount != 1 || status.PendingCount != 1 {
+		t.Fatalf("unexpected aggregation: %+v", status)
+	}
+	if status.Status != "sent" || len(status.Recipients) != 0 {
+		t.Fatalf("expected sent status without member detail, got %+v", status)
+	}
+
+	detailed, err := read.GetGroupMessageReceipts("group-1", senderMsgID, true)
+	if err != nil {
+		t.Fatalf("get group message receipts: %v", err)
+	}
+	if len(detailed.Recipients) != 3 || detailed.Recipients[0].MemberID != "member-delivered" {
+		t.Fatalf("unexpected member detail: %+v", detailed.Recipients)
+	}
+
+	saved[deliveredID] = models.Message{ID: deliveredID, Status: "read"}
+	saved[pendingID] = models.Message{ID: pendingID, Status: "read"}
+	status, err = read.GetGroupMessageStatus("group-1", senderMsgID)
+	if err != nil {
+		t.Fatalf("get group message status after reads: %v", err)
+	}
+	if status.Status != "read" || status.ReadCount != 3 {
+		t.Fatalf("expected read aggregate, got %+v", status)
+	}
+}
+
+func TestGroupMessageFanout_RequiresRulesAcknowledgment(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := GroupState{
+		Group: Group{ID: "group-1", Title: "general", Rules: "be kind", RulesVersion: 2},
+		Members: map[string]GroupMember{
+			"actor": {MemberID: "actor", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive, RulesAckVersion: 1},
+		},
+		Version: 3,
+	}
+	service := &GroupMessageFanoutService{
+		States:         map[string]GroupState{"group-1": state},
+		IdentityID:     func() string { return "actor" },
+		ActiveDeviceID: func() (string, error) { return "dev-1", nil },
+		Now:            func() time.Time { return now },
+	}
+
+	if _, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", ""); !errors.Is(err, ErrGroupRulesNotAcknowledged) {
+		t.Fatalf("expected ErrGroupRulesNotAcknowledged, got %v", err)
+	}
+	reason, err := ValidateInboundGroupMessageState(state, "actor", "", 3, 1, time.Now())
+	if !errors.Is(err, ErrGroupRulesNotAcknowledged) || reason != InboundGroupMessageReasonRulesNotAcknowledged {
+		t.Fatalf("expected rules_not_acknowledged rejection, got reason=%q err=%v", reason, err)
+	}
+}
+
+func TestGroupMessageFanout_FailuresCarryCategories(t *testing.T) {
+	now := time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)
+	service := &GroupMessageFanoutService{
+		States: map[string]GroupState{
+			"group-1": {
+				Group: Group{ID: "group-1", Title: "general"},
+				Members: map[string]GroupMember{
+					"actor":   {MemberID: "actor", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive},
+					"ok":      {MemberID: "ok", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+					"offline": {MemberID: "offline", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+					"nokey":   {MemberID: "nokey", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+				},
+			},
+		},
+		IdentityID:     func() string { return "actor" },
+		ActiveDeviceID: func() (string, error) { return "dev-1", nil },
+		Now:            func() time.Time { return now },
+		GetMessage:     func(string) (models.Message, bool) { return models.Message{}, false },
+		SaveMessage:    func(models.Message) error { return nil },
+		PrepareAndPublish: func(_ context.Context, msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
+			switch recipientID {
+			case "offline":
+				return "", "", errors.New("publish failed")
+			case "nokey":
+				return "", "crypto", errors.New("no session")
+			default:
+				return msg.ID, "", nil
+			}
+		},
+	}
+
+	result, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Failed != 2 || result.Failures["offline"] != "network" || result.Failures["nokey"] != "crypto" {
+		t.Fatalf("unexpected failures: %+v", result)
+	}
+	for _, recipient := range result.Recipients {
+		if recipient.Status == "failed" && recipient.ErrorCategory != result.Failures[recipient.RecipientID] {
+			t.Fatalf("recipient category mismatch: %+v", recipient)
+		}
+	}
+	deliveryErr := result.DeliveryError()
+	if deliveryErr == nil || deliveryErr.IsFullFailure() || deliveryErr.Attempted != 3 || len(deliveryErr.Failures) != 2 {
+		t.Fatalf("unexpected delivery error: %+v", deliveryErr)
+	}
+	if (GroupMessageFanoutResult{Attempted: 3, Delivered: 3}).DeliveryError() != nil {
+		t.Fatal("expected no delivery error without failures")
+	}
+}
+
+func TestGroupMessageFanout_HiddenMembersArePseudonymized(t *testing.T) {
+	now := time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)
+	group := Group{ID: "group-1", Title: "support", HiddenMembers: true, PseudonymSalt: "salt"}
+	service := &GroupMessageFanoutService{
+		States: map[string]GroupState{
+			"group-1": {
+				Group: group,
+				Members: map[string]GroupMember{
+					"owner":   {MemberID: "owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive},
+					"actor":   {MemberID: "actor", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+					"offline": {MemberID: "offline", Role: GroupMemberRoleUser, Status: GroupMemberStatusActive},
+				},
+			},
+		},
+		IdentityID:     func() string { return "actor" },
+		ActiveDeviceID: func() (string, error) { return "dev-1", nil },
+		Now:            func() time.Time { return now },
+		GetMessage:     func(string) (models.Message, bool) { return models.Message{}, false },
+		SaveMessage:    func(models.Message) error { return nil },
+		PrepareAndPublish: func(_ context.Context, msg models.Message, recipientID string, _ GroupMessageWireMeta) (string, string, error) {
+			if recipientID == "offline" {
+				return "", "", errors.New("publish failed")
+			}
+			return msg.ID, "", nil
+		},
+	}
+
+	result, err := service.SendGroupMessageFanout(context.Background(), "group-1", "evt-1", "hello", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Recipients) != 2 || result.Failures[MemberPseudonym(group, "offline")] != "network" {
+		t.Fatalf("expected failures keyed by pseudonym, got %+v", result)
+	}
+	for _, recipient := range result.Recipients {
+		if recipient.RecipientID == "owner" || recipient.RecipientID == "offline" || recipient.MessageID != "" {
+			t.Fatalf("recipient identity leaked: %+v", recipient)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+)
+
+// TransferGroupOwnership hands the group from its owner to newOwnerID, who
+// must be an active admin. The previous owner becomes an admin.
+func (s *MembershipService) TransferGroupOwnership(groupID, actorID, newOwnerID string, now time.Time, abuse *AbuseProtection) (GroupMember, GroupEvent, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	actorID, err = NormalizeGroupMemberID(actorID)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	newOwnerID, err = NormalizeGroupMemberID(newOwnerID)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	if abuse != nil && !abuse.AllowMembership(actorID, now) {
+		return GroupMember{}, GroupEvent{}, ErrGroupRateLimitExceeded
+	}
+	state, err := LoadStateForActor(s.States, groupID, actorID, true)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	if err := ValidateOwnershipTransfer(state, actorID, newOwnerID); err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	event := GroupEvent{
+		ID:         s.generateEventID(),
+		GroupID:    groupID,
+		Version:    state.Version + 1,
+		Type:       GroupEventTypeOwnershipTransfer,
+		ActorID:    actorID,
+		OccurredAt: now,
+		MemberID:   newOwnerID,
+	}
+	next, err := s.applyEvent(state, event)
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	return next.Members[newOwnerID], event, nil
+}
+
+func (s *Service) TransferGroupOwnership(groupID, newOwnerID string) (GroupMember, GroupEvent, error) {
+	var (
+		member GroupMember
+		event  GroupEvent
+	)
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		member, event, err = ms.TransferGroupOwnership(groupID, s.actorID(), newOwnerID, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return GroupMember{}, GroupEvent{}, err
+	}
+	if strings.TrimSpace(event.ID) != "" {
+		s.recordAggregate("ownership_transfer")
+		s.logInfo(
+			"group ownership transferred",
+			"correlation_id", CorrelationID(groupID, event.ID),
+			"group_id", groupID,
+			"actor_id", s.actorID(),
+			"member_id", event.MemberID,
+		)
+	}
+	return member, event, nil
+}
@@ -61,6 +61,13 @@ func (s *GroupReadService) ListGroupMembers(groupID string) ([]GroupMember, erro
 }
 
 func (s *GroupReadService) ListGroupMessages(groupID string, limit, offset int) ([]models.Message, error) {
+	return s.ListGroupMessagesFiltered(groupID, GroupMessageFilterAll, limit, offset)
+}
+
+// ListGroupMessagesFiltered lists the group timeline, optionally restricted to
+// regular or system messages. Like transport copies, filtered entries are
+// dropped after paging so offsets stay aligned with the unfiltered store.
+func (s *GroupReadService) ListGroupMessagesFiltered(groupID string, filter GroupMessageFilter, limit, offset int) ([]models.Message, error) {
 	groupID, err := NormalizeGroupID(groupID)
 	if err != nil {
 		return nil, err
@@ -72,14 +79,7 @@ func (s *GroupReadService) ListGroupMessages(groupID string, limit, offset int)
 		return nil, errors.New("message list repository is not configured")
 	}
 	msgs := s.ListMessagesByConversation(groupID, models.ConversationTypeGroup, limit, offset)
-	filtered := make([]models.Message, 0, len(msgs))
-	for _, msg := range msgs {
-		if strings.TrimSpace(msg.ContentType) == groupFanoutTransportContentType {
-			continue
-		}
-		filtered = append(filtered, msg)
-	}
-	return append([]models.Message(nil), filtered...), nil
+	return filterGroupTimeline(msgs, filter), nil
 }
 
 func (s *GroupReadService) ListGroupMessagesByThread(groupID, threadID string, limit, offset int) ([]models.Message, error) {
@@ -98,17 +98,32 @@ func (s *GroupReadService) ListGroupMessagesByThread(groupID, threadID string, l
 		return nil, errors.New("threaded message list repository is not configured")
 	}
 	msgs := s.ListMessagesByConversationThread(groupID, models.ConversationTypeGroup, threadID, limit, offset)
+	return filterGroupTimeline(msgs, GroupMessageFilterAll), nil
+}
+
+func filterGroupTimeline(msgs []models.Message, filter GroupMessageFilter) []models.Message {
 	filtered := make([]models.Message, 0, len(msgs))
 	for _, msg := range msgs {
-		if strings.TrimSpace(msg.ContentType) == groupFanoutTransportContentType {
+		contentType := strings.TrimSpace(msg.ContentType)
+		if contentType == groupFanoutTransportContentType {
+			continue
+		}
+		isSystem := contentType == models.MessageContentTypeSystem
+		if (filter == GroupMessageFilterMessages && isSystem) || (filter == GroupMessageFilterSystem && !isSystem) {
 			continue
 		}
 		filtered = append(filtered, msg)
 	}
-	return append([]models.Message(nil), filtered...), nil
+	return filtered
 }
 
 func (s *GroupReadService) GetGroupMessageStatus(groupID, messageID string) (models.MessageStatus, error) {
+	return s.GetGroupMessageReceipts(groupID, messageID, false)
+}
+
+// GetGroupMessageReceipts aggregates per-recipient transport statuses of an
+// outbound group message. Per-member detail is returned only on request.
+func (s *GroupReadService) GetGroupMessageReceipts(groupID, messageID string, includeMembers bool) (models.MessageStatus, error) {
 	groupID, err := NormalizeGroupID(groupID)
 	if err != nil {
 		return models.MessageStatus{}, err
@@ -127,10 +142,131 @@ func (s *GroupReadService) GetGroupMessageStatus(groupID, messageID string) (mod
 	if msg.ConversationType != models.ConversationTypeGroup || strings.TrimSpace(msg.ConversationID) != groupID {
 		return models.MessageStatus{}, errors.New("message does not belong to group")
 	}
-	return models.MessageStatus{
+	return s.aggregateGroupReceipts(s.States[groupID], msg, includeMembers), nil
+}
+
+// aggregateGroupReceipts counts the recipient statuses of an outbound group
+// message from its per-member transport copies.
+func (s *GroupReadService) aggregateGroupReceipts(state GroupState, msg models.Message, includeMembers bool) models.MessageStatus {
+	status := models.MessageStatus{
 		MessageID: msg.ID,
 		Status:    msg.Status,
-	}, nil
+	}
+	if state.Members == nil || msg.Direction != "out" || strings.TrimSpace(msg.EventID) == "" ||
+		strings.TrimSpace(msg.ContentType) == groupFanoutTransportContentType {
+		return status
+	}
+	memberIDs := make([]string, 0, len(state.Members))
+	for memberID := range state.Members {
+		if memberID != msg.ContactID {
+			memberIDs = append(memberIDs, memberID)
+		}
+	}
+	sort.Strings(memberIDs)
+	// The sender of an outbound message is the local user.
+	pseudonymize := !CanSeeMembers(state.Group, state.Members[msg.ContactID])
+	for _, memberID := range memberIDs {
+		transportID := DeriveRecipientMessageID(msg.EventID, memberID)
+		transport, exists := s.GetMessage(transportID)
+		if !exists {
+			continue
+		}
+		status.RecipientCount++
+		switch transport.Status {
+		case "read":
+			status.ReadCount++
+			status.DeliveredCount++
+		case "delivered":
+			status.DeliveredCount++
+		case "failed":
+			status.FailedCount++
+		case "pending":
+			status.PendingCount++
+		}
+		if includeMembers {
+			recipient := models.MessageRecipientStatus{
+				MemberID:  memberID,
+				MessageID: transportID,
+				Status:    transport.Status,
+			}
+			if pseudonymize {
+				recipient.MemberID = MemberPseudonym(state.Group, memberID)
+				recipient.MessageID = ""
+			}
+			status.Recipients = append(status.Recipients, recipient)
+		}
+	}
+	switch {
+	case status.RecipientCount == 0:
+	case status.ReadCount == status.RecipientCount:
+		status.Status = "read"
+	case status.DeliveredCount == status.RecipientCount:
+		status.Status = "delivered"
+	}
+	return status
+}
+
+// GetChannelStats reports the channel audience and the aggregate reach of
+// the last limit main-feed broadcasts sent from this node. Only owners and
+// admins may see it, and per-subscriber receipts are never included.
+func (s *GroupReadService) GetChannelStats(groupID, actorID string, limit int) (ChannelStats, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return ChannelStats{}, err
+	}
+	state, ok := s.States[groupID]
+	if !ok {
+		return ChannelStats{}, ErrGroupNotFound
+	}
+	if !state.Group.IsChannel() {
+		return ChannelStats{}, ErrGroupNotChannel
+	}
+	actor, ok := state.Members[strings.TrimSpace(actorID)]
+	if !ok || actor.Status != GroupMemberStatusActive || !actor.CanManageMembers() {
+		return ChannelStats{}, ErrGroupPermissionDenied
+	}
+	if s.ListMessagesByConversation == nil || s.GetMessage == nil {
+		return ChannelStats{}, errors.New("message repository is not configured")
+	}
+	if limit <= 0 {
+		limit = DefaultChannelStatsBroadcasts
+	}
+	limit = min(limit, MaxChannelStatsBroadcasts)
+	stats := ChannelStats{GroupID: groupID, Broadcasts: []ChannelBroadcastStats{}}
+	for _, member := range state.Members {
+		if member.Status != GroupMemberStatusActive {
+			continue
+		}
+		if member.CanManageMembers() {
+			stats.AdminCount++
+		} else {
+			stats.SubscriberCount++
+		}
+	}
+	msgs := s.ListMessagesByConversation(groupID, models.ConversationTypeGroup, 0, 0)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		contentType := strings.TrimSpace(msg.ContentType)
+		if msg.Direction != "out" || strings.TrimSpace(msg.ThreadID) != "" || strings.TrimSpace(msg.EventID) == "" ||
+			contentType == groupFanoutTransportContentType || contentType == models.MessageContentTypeSystem {
+			continue
+		}
+		stats.BroadcastCount++
+		if len(stats.Broadcasts) >= limit {
+			continue
+		}
+		receipts := s.aggregateGroupReceipts(state, msg, false)
+		stats.Broadcasts = append(stats.Broadcasts, ChannelBroadcastStats{
+			MessageID:      msg.ID,
+			SentAt:         msg.Timestamp,
+			RecipientCount: receipts.RecipientCount,
+			DeliveredCount: receipts.DeliveredCount,
+			ReadCount:      receipts.ReadCount,
+			PendingCount:   receipts.PendingCount,
+			FailedCount:    receipts.FailedCount,
+		})
+	}
+	return stats, nil
 }
 
 func (s *GroupReadService) DeleteGroupMessage(groupID, messageID string) error {
@@ -3,6 +3,8 @@ package usecase
 import (
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
 	"aim-chat/go-backend/pkg/models"
+	"context"
+	"sort"
 	"strings"
 	"time"
 )
@@ -12,6 +14,7 @@ type Service struct {
 
 	WithMembership func(fn func(svc *MembershipService) error) error
 	SnapshotStates func() map[string]GroupState
+	SnapshotEvents func(groupID string) []GroupEvent
 
 	GenerateID      func(prefix string) (string, error)
 	GenerateEventID func() string
@@ -27,7 +30,7 @@ type Service struct {
 	ListMessages         func(conversationID, conversationType string, limit, offset int) []models.Message
 	ListMessagesByThread func(conversationID, conversationType, threadID string, limit, offset int) []models.Message
 
-	PrepareAndPublish func(msg models.Message, recipientID string, meta GroupMessageWireMeta) (string, string, error)
+	PrepareAndPublish func(ctx context.Context, msg models.Message, recipientID string, meta GroupMessageWireMeta) (string, string, error)
 	RecordError       func(category string, err error)
 	Notify            func(method string, payload any)
 	RecordAggregate   func(string)
@@ -123,6 +126,55 @@ func (s *Service) ListGroupMembers(groupID string) ([]GroupMember, error) {
 	return read.ListGroupMembers(groupID)
 }
 
+// ListGroupHistory returns the administrative events of a group in version
+// order. Any member record, including left or removed members, grants access.
+func (s *Service) ListGroupHistory(groupID string, limit, offset int) ([]GroupEvent, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := LoadStateForActor(s.SnapshotStates(), groupID, s.actorID(), false); err != nil {
+		return nil, err
+	}
+	if s.SnapshotEvents == nil {
+		return []GroupEvent{}, nil
+	}
+	events := s.SnapshotEvents(groupID)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Version < events[j].Version })
+	if offset >= len(events) {
+		return []GroupEvent{}, nil
+	}
+	events = events[offset:]
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// ListGroupActivity renders the group event log as a membership and profile
+// activity feed, oldest first, with the same access rule as the history.
+func (s *Service) ListGroupActivity(groupID string, limit, offset int) ([]GroupActivity, error) {
+	groupID, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := LoadStateForActor(s.SnapshotStates(), groupID, s.actorID(), false); err != nil {
+		return nil, err
+	}
+	if s.SnapshotEvents == nil {
+		return []GroupActivity{}, nil
+	}
+	feed := BuildGroupActivityFeed(groupID, s.SnapshotEvents(groupID))
+	if offset >= len(feed) {
+		return []GroupActivity{}, nil
+	}
+	feed = feed[offset:]
+	if limit > 0 && limit < len(feed) {
+		feed = feed[:limit]
+	}
+	return feed, nil
+}
+
 func (s *Service) LeaveGroup(groupID string) (bool, error) {
 	var ok bool
 	err := s.WithMembership(func(ms *MembershipService) error {
@@ -183,6 +235,44 @@ func (s *Service) UpdateGroupProfile(groupID, title, description, avatar string)
 	return group, nil
 }
 
+func (s *Service) SetGroupRules(groupID, rules string) (Group, error) {
+	var (
+		group Group
+		event GroupEvent
+	)
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		group, event, err = ms.SetGroupRules(groupID, s.actorID(), rules, s.nowUTC(), s.Abuse)
+		return err
+	})
+	if err != nil {
+		return Group{}, err
+	}
+	if strings.TrimSpace(event.ID) != "" {
+		s.recordAggregate("rules_update")
+		s.logInfo(
+			"group rules updated",
+			"correlation_id", CorrelationID(groupID, event.ID),
+			"group_id", groupID,
+			"actor_id", s.actorID(),
+		)
+	}
+	return group, nil
+}
+
+func (s *Service) AcknowledgeGroupRules(groupID string) (GroupMember, error) {
+	var member GroupMember
+	err := s.WithMembership(func(ms *MembershipService) error {
+		var err error
+		member, _, err = ms.AcknowledgeGroupRules(groupID, s.actorID(), s.nowUTC())
+		return err
+	})
+	if err != nil {
+		return GroupMember{}, err
+	}
+	return member, nil
+}
+
 func (s *Service) DeleteGroup(groupID string) (bool, error) {
 	var deleted bool
 	err := s.WithMembership(func(ms *MembershipService) error {
@@ -327,19 +417,19 @@ func (s *Service) changeGroupMemberRole(groupID, memberID string, role GroupMemb
 	return member, err
 }
 
-func (s *Service) SendGroupMessage(groupID, content string) (GroupMessageFanoutResult, error) {
-	return s.sendGroupMessageWithThread(groupID, content, "")
+func (s *Service) SendGroupMessage(ctx context.Context, groupID, content string) (GroupMessageFanoutResult, error) {
+	return s.sendGroupMessageWithThread(ctx, groupID, content, "")
 }
 
-func (s *Service) SendGroupMessageInThread(groupID, content, threadID string) (GroupMessageFanoutResult, error) {
+func (s *Service) SendGroupMessageInThread(ctx context.Context, groupID, content, threadID string) (GroupMessageFanoutResult, error) {
 	threadID = strings.TrimSpace(threadID)
 	if threadID == "" {
 		return GroupMessageFanoutResult{}, ErrInvalidGroupMessageContent
 	}
-	return s.sendGroupMessageWithThread(groupID, content, threadID)
+	return s.sendGroupMessageWithThread(ctx, groupID, content, threadID)
 }
 
-func (s *Service) sendGroupMessageWithThread(groupID, content, threadID string) (GroupMessageFanoutResult, error) {
+func (s *Service) sendGroupMessageWithThread(ctx context.Context, groupID, content, threadID string) (GroupMessageFanoutResult, error) {
 	groupID, err := NormalizeGroupID(groupID)
 	if err != nil {
 		return GroupMessageFanoutResult{}, err
@@ -355,10 +445,10 @@ func (s *Service) sendGroupMessageWithThread(groupID, content, threadID string)
 	if err != nil {
 		return GroupMessageFanoutResult{}, err
 	}
-	return s.SendGroupMessageFanout(groupID, eventID, content, threadID)
+	return s.SendGroupMessageFanout(ctx, groupID, eventID, content, threadID)
 }
 
-func (s *Service) SendGroupMessageFanout(groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
+func (s *Service) SendGroupMessageFanout(ctx context.Context, groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
 	groupID, err := NormalizeGroupID(groupID)
 	if err != nil {
 		return GroupMessageFanoutResult{}, err
@@ -377,7 +467,10 @@ func (s *Service) SendGroupMessageFanout(groupID, eventID, content, threadID str
 	if content == "" {
 		return GroupMessageFanoutResult{}, ErrInvalidGroupMessageContent
 	}
-	result, err := s.sendGroupMessageFanout(groupID, eventID, content, strings.TrimSpace(threadID))
+	if err := ctx.Err(); err != nil {
+		return GroupMessageFanoutResult{}, err
+	}
+	result, err := s.sendGroupMessageFanout(ctx, groupID, eventID, content, strings.TrimSpace(threadID))
 	if err != nil {
 		return GroupMessageFanoutResult{}, err
 	}
@@ -392,11 +485,18 @@ func (s *Service) SendGroupMessageFanout(groupID, eventID, content, threadID str
 		"pending", result.Pending,
 		"failed", result.Failed,
 	)
+	if deliveryErr := result.DeliveryError(); deliveryErr.IsFullFailure() {
+		return result, deliveryErr
+	}
 	return result, nil
 }
 
-func (s *Service) sendGroupMessageFanout(groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
-	fanout := &GroupMessageFanoutService{
+func (s *Service) sendGroupMessageFanout(ctx context.Context, groupID, eventID, content, threadID string) (GroupMessageFanoutResult, error) {
+	return s.newFanoutService().SendGroupMessageFanout(ctx, groupID, eventID, content, threadID)
+}
+
+func (s *Service) newFanoutService() *GroupMessageFanoutService {
+	return &GroupMessageFanoutService{
 		States:             s.SnapshotStates(),
 		Abuse:              s.Abuse,
 		IdentityID:         s.IdentityID,
@@ -410,7 +510,6 @@ func (s *Service) sendGroupMessageFanout(groupID, eventID, content, threadID str
 		RecordError:        s.RecordError,
 		NotifyGroupMessage: func(groupID string, msg models.Message) { s.notifyGroupMessage(groupID, msg) },
 	}
-	return fanout.SendGroupMessageFanout(groupID, eventID, content, threadID)
 }
 
 func (s *Service) notifyGroupMessage(groupID string, msg models.Message) {
@@ -431,6 +530,20 @@ func (s *Service) ListGroupMessages(groupID string, limit, offset int) ([]models
 	return read.ListGroupMessages(groupID, limit, offset)
 }
 
+// ListGroupMessagesFiltered lists the group timeline restricted by filter:
+// "all" (default), "messages" or "system".
+func (s *Service) ListGroupMessagesFiltered(groupID, filter string, limit, offset int) ([]models.Message, error) {
+	parsed, err := ParseGroupMessageFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	read := &GroupReadService{
+		States:                     s.SnapshotStates(),
+		ListMessagesByConversation: s.ListMessages,
+	}
+	return read.ListGroupMessagesFiltered(groupID, parsed, limit, offset)
+}
+
 func (s *Service) ListGroupMessagesByThread(groupID, threadID string, limit, offset int) ([]models.Message, error) {
 	read := &GroupReadService{
 		States:                           s.SnapshotStates(),
@@ -441,8 +554,26 @@ func (s *Service) ListGroupMessagesByThread(groupID, threadID string, limit, off
 }
 
 func (s *Service) GetGroupMessageStatus(groupID, messageID string) (models.MessageStatus, error) {
-	read := &GroupReadService{GetMessage: s.GetMessage}
-	return read.GetGroupMessageStatus(groupID, messageID)
+	return s.GetGroupMessageReceipts(groupID, messageID, false)
+}
+
+func (s *Service) GetGroupMessageReceipts(groupID, messageID string, includeMembers bool) (models.MessageStatus, error) {
+	read := &GroupReadService{
+		States:     s.SnapshotStates(),
+		GetMessage: s.GetMessage,
+	}
+	return read.GetGroupMessageReceipts(groupID, messageID, includeMembers)
+}
+
+// GetChannelStats reports subscriber counts and the reach of the last limit
+// broadcasts of this identity in a channel it administers.
+func (s *Service) GetChannelStats(groupID string, limit int) (ChannelStats, error) {
+	read := &GroupReadService{
+		States:                     s.SnapshotStates(),
+		GetMessage:                 s.GetMessage,
+		ListMessagesByConversation: s.ListMessages,
+	}
+	return read.GetChannelStats(groupID, s.actorID(), limit)
 }
 
 func (s *Service) DeleteGroupMessage(groupID, messageID string) error {
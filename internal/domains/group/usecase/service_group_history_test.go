@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestServiceListGroupHistoryPaginatesForMembers(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	states := map[string]GroupState{
+		"g1": {
+			Group: Group{ID: "g1", Title: "alpha", CreatedBy: "aim1owner", CreatedAt: now, UpdatedAt: now},
+			Members: map[string]GroupMember{
+				"aim1owner": {GroupID: "g1", MemberID: "aim1owner", Role: GroupMemberRoleOwner, Status: GroupMemberStatusActive},
+				"aim1gone":  {GroupID: "g1", MemberID: "aim1gone", Role: GroupMemberRoleUser, Status: GroupMemberStatusRemoved},
+			},
+		},
+	}
+	events := []GroupEvent{
+		{ID: "e3", GroupID: "g1", Version: 3, Type: GroupEventTypeMemberRemove, ActorID: "aim1owner", MemberID: "aim1gone", OccurredAt: now.Add(2 * time.Minute)},
+		{ID: "e1", GroupID: "g1", Version: 1, Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1owner", Role: GroupMemberRoleOwner, OccurredAt: now},
+		{ID: "e2", GroupID: "g1", Version: 2, Type: GroupEventTypeMemberAdd, ActorID: "aim1owner", MemberID: "aim1gone", Role: GroupMemberRoleUser, OccurredAt: now.Add(time.Minute)},
+	}
+	actorID := "aim1gone"
+	svc := &Service{
+		IdentityID:     func() string { return actorID },
+		SnapshotStates: func() map[string]GroupState { return states },
+		SnapshotEvents: func(groupID string) []GroupEvent {
+			if groupID != "g1" {
+				return nil
+			}
+			return append([]GroupEvent(nil), events...)
+		},
+	}
+
+	history, err := svc.ListGroupHistory("g1", 2, 1)
+	if err != nil {
+		t.Fatalf("removed member must still read history: %v", err)
+	}
+	if len(history) != 2 || history[0].ID != "e2" || history[1].ID != "e3" {
+		t.Fatalf("unexpected history page: %+v", history)
+	}
+
+	actorID = "aim1stranger"
+	if _, err := svc.ListGroupHistory("g1", 0, 0); !errors.Is(err, ErrGroupMembershipNotFound) {
+		t.Fatalf("expected membership error for non-member, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"aim-chat/go-backend/internal/domains/contracts"
 	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
 	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
 )
 
 func Dispatch(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
@@ -102,6 +103,17 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 			return map[string]bool{"changed": true}, nil
 		})
 		return result, rpcErr, true
+	case identitytransport.MethodSecurityLockout:
+		result, rpcErr := callWithoutParams(-32349, func() (any, error) {
+			lockout, ok := service.(interface {
+				PasswordLockoutStatus() (models.PasswordLockoutStatus, error)
+			})
+			if !ok {
+				return nil, errors.New("password lockout status is not supported")
+			}
+			return lockout.PasswordLockoutStatus()
+		})
+		return result, rpcErr, true
 	case identitytransport.MethodAccountList:
 		result, rpcErr := callWithoutParams(-32040, func() (any, error) {
 			accountSvc, ok := service.(contracts.AccountAPI)
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type attachmentPolicyService interface {
+	GetContactAttachmentPolicy(contactID string) (models.ContactAttachmentPolicy, error)
+	SetContactAttachmentPolicy(policy models.ContactAttachmentPolicy) (models.ContactAttachmentPolicy, error)
+	ListHeldAttachments(contactID string) []models.HeldAttachment
+	ApproveHeldAttachment(attachmentID string) (models.HeldAttachment, error)
+}
+
+var errAttachmentPolicyNotSupported = errors.New("contact attachment policies are not supported")
+
+func dispatchAttachmentPolicyRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case "contact.attachment_policy.get":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32341, func(contactID string) (any, error) {
+			policies, ok := service.(attachmentPolicyService)
+			if !ok {
+				return nil, errAttachmentPolicyNotSupported
+			}
+			return policies.GetContactAttachmentPolicy(contactID)
+		})
+		return result, rpcErr, true
+	case "contact.attachment_policy.set":
+		policy, err := decodeAttachmentPolicyParam(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32342, func() (any, error) {
+			policies, ok := service.(attachmentPolicyService)
+			if !ok {
+				return nil, errAttachmentPolicyNotSupported
+			}
+			return policies.SetContactAttachmentPolicy(policy)
+		})
+		return result, rpcErr, true
+	case "contact.attachment_policy.held":
+		var params []string
+		if len(rawParams) > 0 && string(rawParams) != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil || len(params) > 1 {
+				return nil, rpckit.InvalidParams(), true
+			}
+		}
+		result, rpcErr := callWithoutParams(-32343, func() (any, error) {
+			policies, ok := service.(attachmentPolicyService)
+			if !ok {
+				return nil, errAttachmentPolicyNotSupported
+			}
+			contactID := ""
+			if len(params) == 1 {
+				contactID = params[0]
+			}
+			return policies.ListHeldAttachments(contactID), nil
+		})
+		return result, rpcErr, true
+	case "contact.attachment_policy.approve":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32343, func(attachmentID string) (any, error) {
+			policies, ok := service.(attachmentPolicyService)
+			if !ok {
+				return nil, errAttachmentPolicyNotSupported
+			}
+			return policies.ApproveHeldAttachment(attachmentID)
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// decodeAttachmentPolicyParam accepts the policy object directly or as the
+// only positional param.
+func decodeAttachmentPolicyParam(raw json.RawMessage) (models.ContactAttachmentPolicy, error) {
+	var arr []models.ContactAttachmentPolicy
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 && arr[0].ContactID != "" {
+		return arr[0], nil
+	}
+	var policy models.ContactAttachmentPolicy
+	if err := json.Unmarshal(raw, &policy); err == nil && policy.ContactID != "" {
+		return policy, nil
+	}
+	return models.ContactAttachmentPolicy{}, errors.New("invalid params")
+}
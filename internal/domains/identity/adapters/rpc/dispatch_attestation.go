@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type attestationService interface {
+	SetSelfAttestations(attestations []models.Attestation) ([]models.Attestation, error)
+	SelfAttestations() []models.Attestation
+	VerifyContactAttestations(contactID string) (models.Contact, error)
+}
+
+var errAttestationsNotSupported = errors.New("attestations are not supported")
+
+func dispatchAttestationRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodAttestationsSet:
+		attestations, err := decodeAttestationsParam(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32357, func() (any, error) {
+			attest, ok := service.(attestationService)
+			if !ok {
+				return nil, errAttestationsNotSupported
+			}
+			updated, err := attest.SetSelfAttestations(attestations)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"attestations": updated}, nil
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodAttestationsGet:
+		result, rpcErr := callWithoutParams(-32358, func() (any, error) {
+			attest, ok := service.(attestationService)
+			if !ok {
+				return nil, errAttestationsNotSupported
+			}
+			attestations := attest.SelfAttestations()
+			if attestations == nil {
+				attestations = []models.Attestation{}
+			}
+			return map[string]any{"attestations": attestations}, nil
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodAttestationsVerify:
+		result, rpcErr := callWithSingleStringParam(rawParams, -32359, func(contactID string) (any, error) {
+			attest, ok := service.(attestationService)
+			if !ok {
+				return nil, errAttestationsNotSupported
+			}
+			return attest.VerifyContactAttestations(contactID)
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// decodeAttestationsParam accepts [[...attestations]] or
+// {"attestations": [...]}. An empty list withdraws every attestation.
+func decodeAttestationsParam(raw json.RawMessage) ([]models.Attestation, error) {
+	var arr [][]models.Attestation
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		return arr[0], nil
+	}
+	var wrapper struct {
+		Attestations *[]models.Attestation `json:"attestations"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err == nil && wrapper.Attestations != nil {
+		return *wrapper.Attestations, nil
+	}
+	return nil, errors.New("invalid params")
+}
@@ -133,6 +133,51 @@ func dispatchBlobRPC(service contracts.DaemonService, method string, rawParams j
 			return aclAPI.SetBlobACLPolicy(mode, allowlist)
 		})
 		return result, rpcErr, true
+	case "blob.acl.override.set":
+		blobID, mode, identities, groupID, err := decodeBlobACLOverrideParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32350, func() (any, error) {
+			aclAPI, ok := service.(interface {
+				SetBlobACLOverride(blobID, mode string, identities []string, groupID string) (models.BlobACLOverrideState, error)
+			})
+			if !ok {
+				return nil, errors.New("blob acl overrides are not supported")
+			}
+			return aclAPI.SetBlobACLOverride(blobID, mode, identities, groupID)
+		})
+		return result, rpcErr, true
+	case "blob.acl.override.get":
+		blobID, err := decodeBlobProvidersParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32351, func() (any, error) {
+			aclAPI, ok := service.(interface {
+				GetBlobACLOverride(blobID string) (models.BlobACLOverrideState, error)
+			})
+			if !ok {
+				return nil, errors.New("blob acl overrides are not supported")
+			}
+			return aclAPI.GetBlobACLOverride(blobID)
+		})
+		return result, rpcErr, true
+	case "blob.acl.override.remove":
+		blobID, err := decodeBlobProvidersParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32352, func() (any, error) {
+			aclAPI, ok := service.(interface {
+				RemoveBlobACLOverride(blobID string) (models.BlobACLOverrideState, error)
+			})
+			if !ok {
+				return nil, errors.New("blob acl overrides are not supported")
+			}
+			return aclAPI.RemoveBlobACLOverride(blobID)
+		})
+		return result, rpcErr, true
 	case "blob.preset.get":
 		result, rpcErr := callWithoutParams(-32079, func() (any, error) {
 			presetAPI, ok := service.(interface {
@@ -257,6 +302,32 @@ func decodeBlobACLPolicyParams(raw json.RawMessage) (string, []string, error) {
 	return "", nil, errors.New("invalid params")
 }
 
+func decodeBlobACLOverrideParams(raw json.RawMessage) (string, string, []string, string, error) {
+	type payload struct {
+		BlobID     string   `json:"blob_id"`
+		Mode       string   `json:"mode"`
+		Identities []string `json:"identities"`
+		GroupID    string   `json:"group_id"`
+	}
+	parse := func(p payload) (string, string, []string, string, error) {
+		blobID := strings.TrimSpace(p.BlobID)
+		mode := strings.TrimSpace(p.Mode)
+		if blobID == "" || mode == "" {
+			return "", "", nil, "", errors.New("invalid params")
+		}
+		return blobID, mode, p.Identities, strings.TrimSpace(p.GroupID), nil
+	}
+	var arr []payload
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		return parse(arr[0])
+	}
+	var direct payload
+	if err := json.Unmarshal(raw, &direct); err == nil {
+		return parse(direct)
+	}
+	return "", "", nil, "", errors.New("invalid params")
+}
+
 func decodeBlobNodePresetParams(raw json.RawMessage) (string, error) {
 	var arr []string
 	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
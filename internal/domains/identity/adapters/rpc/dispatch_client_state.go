@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type clientStateService interface {
+	SetClientState(namespace, key, value string) (models.ClientStateEntry, error)
+	GetClientState(namespace, key string) (models.ClientStateEntry, bool, error)
+	ListClientState(namespace string) ([]models.ClientStateEntry, error)
+	DeleteClientState(namespace, key string) (bool, error)
+}
+
+var errClientStateNotSupported = errors.New("client state is not supported")
+
+func dispatchClientStateRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodClientStateSet:
+		result, rpcErr := callWithClientStateParams(rawParams, 3, 3, -32302, func(args []string) (any, error) {
+			store, ok := service.(clientStateService)
+			if !ok {
+				return nil, errClientStateNotSupported
+			}
+			return store.SetClientState(args[0], args[1], args[2])
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodClientStateGet:
+		result, rpcErr := callWithClientStateParams(rawParams, 1, 2, -32303, func(args []string) (any, error) {
+			store, ok := service.(clientStateService)
+			if !ok {
+				return nil, errClientStateNotSupported
+			}
+			if len(args) == 1 {
+				entries, err := store.ListClientState(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return map[string]any{"entries": entries}, nil
+			}
+			entry, found, err := store.GetClientState(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return map[string]any{"found": false}, nil
+			}
+			return map[string]any{"found": true, "entry": entry}, nil
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodClientStateDelete:
+		result, rpcErr := callWithClientStateParams(rawParams, 2, 2, -32304, func(args []string) (any, error) {
+			store, ok := service.(clientStateService)
+			if !ok {
+				return nil, errClientStateNotSupported
+			}
+			deleted, err := store.DeleteClientState(args[0], args[1])
+			if err != nil {
+				return nil, err
+			}
+			return map[string]bool{"deleted": deleted}, nil
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// callWithClientStateParams decodes [namespace, key, value] style params.
+// Namespace and key must be non-empty; the value may be an empty string.
+func callWithClientStateParams(
+	rawParams json.RawMessage,
+	minArgs, maxArgs int,
+	serviceErrCode int,
+	call func(args []string) (any, error),
+) (any, *rpckit.Error) {
+	var args []string
+	if err := json.Unmarshal(rawParams, &args); err != nil || len(args) < minArgs || len(args) > maxArgs {
+		return nil, rpckit.InvalidParams()
+	}
+	for i := 0; i < len(args) && i < 2; i++ {
+		if strings.TrimSpace(args[i]) == "" {
+			return nil, rpckit.InvalidParams()
+		}
+	}
+	result, err := call(args)
+	if err != nil {
+		return nil, rpckit.ServiceError(serviceErrCode, err)
+	}
+	return result, nil
+}
@@ -29,8 +29,15 @@ func dispatchContactRPC(service contracts.DaemonService, method string, rawParam
 		})
 		return result, rpcErr, true
 	case "contact.list":
-		result, rpcErr := callWithoutParams(-32012, func() (any, error) {
-			return service.GetContacts()
+		result, rpcErr := dispatchContactList(service, rawParams)
+		return result, rpcErr, true
+	case "contact.cleanup.suggest":
+		result, rpcErr := callWithoutParams(-32393, func() (any, error) {
+			dormancy, ok := service.(contactDormancyService)
+			if !ok {
+				return nil, errors.New("dormant contact detection is not supported")
+			}
+			return dormancy.SuggestContactCleanup()
 		})
 		return result, rpcErr, true
 	case "contact.remove":
@@ -41,6 +48,24 @@ func dispatchContactRPC(service contracts.DaemonService, method string, rawParam
 			return map[string]bool{"removed": true}, nil
 		})
 		return result, rpcErr, true
+	case "contact.stats":
+		result, rpcErr := dispatchContactStats(service, rawParams)
+		return result, rpcErr, true
+	case "contact.merge":
+		result, rpcErr := dispatchContactMerge(service, rawParams)
+		return result, rpcErr, true
+	case "contact.update":
+		result, rpcErr := dispatchContactUpdate(service, rawParams)
+		return result, rpcErr, true
+	case "contact.capabilities":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32348, func(contactID string) (any, error) {
+			capabilities, ok := service.(contactCapabilitiesService)
+			if !ok {
+				return nil, errors.New("contact capabilities are not supported")
+			}
+			return capabilities.ContactCapabilities(contactID)
+		})
+		return result, rpcErr, true
 	default:
 		return nil, nil, false
 	}
@@ -94,6 +119,119 @@ func dispatchAddContactByID(service contracts.DaemonService, contactID, displayN
 	return map[string]bool{"added": true}, nil
 }
 
+type contactDormancyService interface {
+	ListDormantContacts() ([]models.DormantContact, error)
+	SuggestContactCleanup() ([]models.ContactCleanupSuggestion, error)
+}
+
+// dispatchContactList accepts no params or {dormant}; with dormant set only
+// the contacts that went silent while deliveries to them failed are listed.
+func dispatchContactList(service contracts.DaemonService, rawParams json.RawMessage) (any, *rpckit.Error) {
+	var params struct {
+		Dormant bool `json:"dormant"`
+	}
+	if len(rawParams) > 0 && string(rawParams) != "null" && string(rawParams) != "[]" {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, rpckit.InvalidParams()
+		}
+	}
+	return callWithoutParams(-32012, func() (any, error) {
+		if !params.Dormant {
+			return service.GetContacts()
+		}
+		dormancy, ok := service.(contactDormancyService)
+		if !ok {
+			return nil, errors.New("dormant contact detection is not supported")
+		}
+		return dormancy.ListDormantContacts()
+	})
+}
+
+type contactCapabilitiesService interface {
+	ContactCapabilities(contactID string) (models.ContactCapabilities, error)
+}
+
+type contactMetaService interface {
+	UpdateContactMeta(contactID, alias, notes, color string) (models.Contact, error)
+}
+
+// dispatchContactUpdate handles {"contact_id", "alias", "notes", "color"} or
+// the same values as an array. All three fields are replaced; an omitted one
+// is cleared.
+func dispatchContactUpdate(service contracts.DaemonService, rawParams json.RawMessage) (any, *rpckit.Error) {
+	contactID, alias, notes, color, err := decodeContactUpdateParams(rawParams)
+	if err != nil {
+		return nil, rpckit.InvalidParams()
+	}
+	updater, ok := service.(contactMetaService)
+	if !ok {
+		return nil, rpckit.ServiceError(-32364, errors.New("contact metadata is not supported"))
+	}
+	contact, err := updater.UpdateContactMeta(contactID, alias, notes, color)
+	if err != nil {
+		return nil, rpckit.ServiceError(-32364, err)
+	}
+	return contact, nil
+}
+
+func decodeContactUpdateParams(raw json.RawMessage) (string, string, string, string, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err == nil {
+		if len(arr) == 0 || len(arr) > 4 || arr[0] == "" {
+			return "", "", "", "", errors.New("invalid params")
+		}
+		arr = append(arr, make([]string, 4-len(arr))...)
+		return arr[0], arr[1], arr[2], arr[3], nil
+	}
+	var direct struct {
+		ContactID string `json:"contact_id"`
+		Alias     string `json:"alias"`
+		Notes     string `json:"notes"`
+		Color     string `json:"color"`
+	}
+	if err := json.Unmarshal(raw, &direct); err != nil || direct.ContactID == "" {
+		return "", "", "", "", errors.New("invalid params")
+	}
+	return direct.ContactID, direct.Alias, direct.Notes, direct.Color, nil
+}
+
+type contactMergeService interface {
+	MergeContact(oldID, newID string, transition models.IdentityTransition) (models.Contact, error)
+}
+
+// dispatchContactMerge handles [old_id, new_id, transition].
+func dispatchContactMerge(service contracts.DaemonService, rawParams json.RawMessage) (any, *rpckit.Error) {
+	oldID, newID, transition, err := decodeContactMergeParams(rawParams)
+	if err != nil {
+		return nil, rpckit.InvalidParams()
+	}
+	merger, ok := service.(contactMergeService)
+	if !ok {
+		return nil, rpckit.ServiceError(-32328, errors.New("contact merge is not supported"))
+	}
+	contact, err := merger.MergeContact(oldID, newID, transition)
+	if err != nil {
+		return nil, rpckit.ServiceError(-32328, err)
+	}
+	return contact, nil
+}
+
+func decodeContactMergeParams(raw json.RawMessage) (string, string, models.IdentityTransition, error) {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 3 {
+		return "", "", models.IdentityTransition{}, errors.New("invalid params")
+	}
+	var oldID, newID string
+	var transition models.IdentityTransition
+	if json.Unmarshal(arr[0], &oldID) != nil || json.Unmarshal(arr[1], &newID) != nil || json.Unmarshal(arr[2], &transition) != nil {
+		return "", "", models.IdentityTransition{}, errors.New("invalid params")
+	}
+	if oldID == "" || newID == "" {
+		return "", "", models.IdentityTransition{}, errors.New("invalid params")
+	}
+	return oldID, newID, transition, nil
+}
+
 func decodeCardParam(raw json.RawMessage) (models.ContactCard, error) {
 	var arr []models.ContactCard
 	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
@@ -132,3 +270,34 @@ func decodeContactByIDParams(raw json.RawMessage) (string, string, error) {
 	}
 	return "", "", errors.New("invalid params")
 }
+
+// dispatchContactStats returns delivery statistics for [contact_id], or for
+// every contact with outbound activity when no contact is given.
+func dispatchContactStats(service contracts.DaemonService, rawParams json.RawMessage) (any, *rpckit.Error) {
+	var params []string
+	if len(rawParams) > 0 && string(rawParams) != "null" {
+		if err := json.Unmarshal(rawParams, &params); err != nil || len(params) > 1 || (len(params) == 1 && params[0] == "") {
+			return nil, rpckit.InvalidParams()
+		}
+	}
+	stats, ok := service.(interface {
+		GetContactDeliveryStats(contactID string) (models.ContactDeliveryStats, error)
+		ListContactDeliveryStats() ([]models.ContactDeliveryStats, error)
+	})
+	if !ok {
+		return nil, rpckit.ServiceError(-32015, errors.New("contact delivery stats are not supported"))
+	}
+	var (
+		result any
+		err    error
+	)
+	if len(params) == 1 {
+		result, err = stats.GetContactDeliveryStats(params[0])
+	} else {
+		result, err = stats.ListContactDeliveryStats()
+	}
+	if err != nil {
+		return nil, rpckit.ServiceError(-32015, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type contactSharingService interface {
+	GetContactSharing(contactID string) (models.ContactSharingProfile, error)
+	SetContactSharing(profile models.ContactSharingProfile) (models.ContactSharingProfile, error)
+}
+
+var errContactSharingNotSupported = errors.New("contact sharing profiles are not supported")
+
+func dispatchContactSharingRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case "contact.sharing.get":
+		var params []string
+		if len(rawParams) > 0 && string(rawParams) != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil || len(params) > 1 {
+				return nil, rpckit.InvalidParams(), true
+			}
+		}
+		result, rpcErr := callWithoutParams(-32366, func() (any, error) {
+			sharing, ok := service.(contactSharingService)
+			if !ok {
+				return nil, errContactSharingNotSupported
+			}
+			contactID := ""
+			if len(params) == 1 {
+				contactID = params[0]
+			}
+			return sharing.GetContactSharing(contactID)
+		})
+		return result, rpcErr, true
+	case "contact.sharing.set":
+		profile, err := decodeContactSharingParam(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32367, func() (any, error) {
+			sharing, ok := service.(contactSharingService)
+			if !ok {
+				return nil, errContactSharingNotSupported
+			}
+			return sharing.SetContactSharing(profile)
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// decodeContactSharingParam accepts the profile object directly or as the
+// only positional param. Without a contact id it sets the default profile.
+func decodeContactSharingParam(raw json.RawMessage) (models.ContactSharingProfile, error) {
+	var arr []models.ContactSharingProfile
+	if err := json.Unmarshal(raw, &arr); err == nil {
+		if len(arr) != 1 {
+			return models.ContactSharingProfile{}, errors.New("invalid params")
+		}
+		return arr[0], nil
+	}
+	var profile models.ContactSharingProfile
+	if err := json.Unmarshal(raw, &profile); err != nil {
+		return models.ContactSharingProfile{}, err
+	}
+	return profile, nil
+}
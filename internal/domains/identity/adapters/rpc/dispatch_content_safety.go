@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type contentSafetyService interface {
+	ApplySafetyFeed(feed contentsafety.Feed) (models.SafetyFeedStatus, error)
+	GetSafetyFeedStatus() (models.SafetyFeedStatus, error)
+}
+
+var errContentSafetyNotSupported = errors.New("content safety is not supported")
+
+func dispatchContentSafetyRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodSafetyFeedApply:
+		feed, err := decodeSafetyFeedParam(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32326, func() (any, error) {
+			safety, ok := service.(contentSafetyService)
+			if !ok {
+				return nil, errContentSafetyNotSupported
+			}
+			return safety.ApplySafetyFeed(feed)
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodSafetyFeedStatus:
+		result, rpcErr := callWithoutParams(-32327, func() (any, error) {
+			safety, ok := service.(contentSafetyService)
+			if !ok {
+				return nil, errContentSafetyNotSupported
+			}
+			return safety.GetSafetyFeedStatus()
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+func decodeSafetyFeedParam(raw json.RawMessage) (contentsafety.Feed, error) {
+	var arr []contentsafety.Feed
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		return arr[0], nil
+	}
+	var wrapper struct {
+		Feed *contentsafety.Feed `json:"feed"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err == nil && wrapper.Feed != nil {
+		return *wrapper.Feed, nil
+	}
+	return contentsafety.Feed{}, errors.New("invalid params")
+}
@@ -34,9 +34,9 @@ func mapDeviceRevokeRPCError(err error) *rpckit.Error {
 	var deliveryErr *contracts.DeviceRevocationDeliveryError
 	if errors.As(err, &deliveryErr) {
 		if deliveryErr.IsFullFailure() {
-			return &rpckit.Error{Code: -32054, Message: err.Error()}
+			return &rpckit.Error{Code: -32054, Message: err.Error(), Data: deliveryErr}
 		}
-		return &rpckit.Error{Code: -32053, Message: err.Error()}
+		return &rpckit.Error{Code: -32053, Message: err.Error(), Data: deliveryErr}
 	}
 	return &rpckit.Error{Code: -32052, Message: err.Error()}
 }
@@ -14,8 +14,58 @@ import (
 func dispatchFileUploadRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
 	switch method {
 	case "file.put":
-		result, rpcErr := callWithFilePutParams(rawParams, -32060, func(name, mimeType, dataBase64 string) (any, error) {
-			return service.PutAttachment(name, mimeType, dataBase64)
+		result, rpcErr := callWithFilePutParams(rawParams, -32060, func(name, mimeType, dataBase64, altText string) (any, error) {
+			if altText == "" {
+				return service.PutAttachment(name, mimeType, dataBase64)
+			}
+			describer, ok := service.(interface {
+				PutAttachmentWithAltText(name, mimeType, dataBase64, altText string) (models.AttachmentMeta, error)
+			})
+			if !ok {
+				return nil, errors.New("attachment alt text is not supported")
+			}
+			return describer.PutAttachmentWithAltText(name, mimeType, dataBase64, altText)
+		})
+		return result, rpcErr, true
+	case "file.alt_text.set":
+		attachmentID, altText, err := decodeFileAltTextParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32309, func() (any, error) {
+			describer, ok := service.(interface {
+				SetAttachmentAltText(attachmentID, altText string) (models.AttachmentMeta, error)
+			})
+			if !ok {
+				return nil, errors.New("attachment alt text is not supported")
+			}
+			return describer.SetAttachmentAltText(attachmentID, altText)
+		})
+		return result, rpcErr, true
+	case "file.extend":
+		blobID, extraSeconds, err := decodeFileExtendParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32316, func() (any, error) {
+			extender, ok := service.(interface {
+				ExtendAttachmentExpiry(blobID string, extraSeconds int) (models.AttachmentExpiry, error)
+			})
+			if !ok {
+				return nil, errors.New("attachment grace extension is not supported")
+			}
+			return extender.ExtendAttachmentExpiry(blobID, extraSeconds)
+		})
+		return result, rpcErr, true
+	case "file.thumbnail.get":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32389, func(blobID string) (any, error) {
+			previewer, ok := service.(interface {
+				GetAttachmentThumbnail(blobID string) (models.AttachmentThumbnail, error)
+			})
+			if !ok {
+				return nil, errors.New("attachment thumbnails are not supported")
+			}
+			return previewer.GetAttachmentThumbnail(blobID)
 		})
 		return result, rpcErr, true
 	case "file.upload.init":
@@ -25,12 +75,12 @@ func dispatchFileUploadRPC(service contracts.DaemonService, method string, rawPa
 		}
 		result, rpcErr := callWithoutParams(-32061, func() (any, error) {
 			uploader, ok := service.(interface {
-				InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256 string) (identityusecase.AttachmentUploadInitResult, error)
+				InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256, altText string) (identityusecase.AttachmentUploadInitResult, error)
 			})
 			if !ok {
 				return nil, errors.New("chunked file upload is not supported")
 			}
-			return uploader.InitAttachmentUpload(params.Name, params.MimeType, params.TotalSize, params.TotalChunks, params.ChunkSize, params.FileSHA256)
+			return uploader.InitAttachmentUpload(params.Name, params.MimeType, params.TotalSize, params.TotalChunks, params.ChunkSize, params.FileSHA256, params.AltText)
 		})
 		return result, rpcErr, true
 	case "file.upload.chunk":
@@ -78,6 +128,36 @@ func dispatchFileUploadRPC(service contracts.DaemonService, method string, rawPa
 			return uploader.CommitAttachmentUpload(params.UploadID)
 		})
 		return result, rpcErr, true
+	case "file.download.init":
+		params, err := decodeFileDownloadInitParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32391, func() (any, error) {
+			downloader, ok := service.(interface {
+				InitAttachmentDownload(attachmentID string, chunkSize int) (models.AttachmentDownload, error)
+			})
+			if !ok {
+				return nil, errors.New("chunked file download is not supported")
+			}
+			return downloader.InitAttachmentDownload(params.AttachmentID, params.ChunkSize)
+		})
+		return result, rpcErr, true
+	case "file.download.chunk":
+		params, err := decodeFileDownloadChunkParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32392, func() (any, error) {
+			downloader, ok := service.(interface {
+				GetAttachmentDownloadChunk(downloadID string, chunkIndex int) (models.AttachmentDownloadChunk, error)
+			})
+			if !ok {
+				return nil, errors.New("chunked file download is not supported")
+			}
+			return downloader.GetAttachmentDownloadChunk(params.DownloadID, params.ChunkIndex)
+		})
+		return result, rpcErr, true
 	default:
 		return nil, nil, false
 	}
@@ -86,39 +166,82 @@ func dispatchFileUploadRPC(service contracts.DaemonService, method string, rawPa
 func callWithFilePutParams(
 	rawParams json.RawMessage,
 	serviceErrCode int,
-	call func(name, mimeType, dataBase64 string) (any, error),
+	call func(name, mimeType, dataBase64, altText string) (any, error),
 ) (any, *rpckit.Error) {
-	name, mimeType, dataBase64, err := decodeFilePutParams(rawParams)
+	name, mimeType, dataBase64, altText, err := decodeFilePutParams(rawParams)
 	if err != nil {
 		return nil, rpckit.InvalidParams()
 	}
-	result, err := call(name, mimeType, dataBase64)
+	result, err := call(name, mimeType, dataBase64, altText)
 	if err != nil {
 		return nil, rpckit.ServiceError(serviceErrCode, err)
 	}
 	return result, nil
 }
 
-func decodeFilePutParams(raw json.RawMessage) (string, string, string, error) {
+// decodeFilePutParams accepts [name, mime_type, data_base64] with an optional
+// fourth alt_text element, or the equivalent object form.
+func decodeFilePutParams(raw json.RawMessage) (string, string, string, string, error) {
 	var arr []string
-	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 3 {
+	if err := json.Unmarshal(raw, &arr); err == nil && (len(arr) == 3 || len(arr) == 4) {
 		if strings.TrimSpace(arr[0]) == "" || strings.TrimSpace(arr[2]) == "" {
-			return "", "", "", errors.New("invalid params")
+			return "", "", "", "", errors.New("invalid params")
+		}
+		altText := ""
+		if len(arr) == 4 {
+			altText = arr[3]
 		}
-		return arr[0], arr[1], arr[2], nil
+		return arr[0], arr[1], arr[2], altText, nil
 	}
 	var payload struct {
 		Name       string `json:"name"`
 		MimeType   string `json:"mime_type"`
 		DataBase64 string `json:"data_base64"`
+		AltText    string `json:"alt_text"`
 	}
 	if err := json.Unmarshal(raw, &payload); err != nil {
-		return "", "", "", errors.New("invalid params")
+		return "", "", "", "", errors.New("invalid params")
 	}
 	if strings.TrimSpace(payload.Name) == "" || strings.TrimSpace(payload.DataBase64) == "" {
-		return "", "", "", errors.New("invalid params")
+		return "", "", "", "", errors.New("invalid params")
+	}
+	return payload.Name, payload.MimeType, payload.DataBase64, payload.AltText, nil
+}
+
+// decodeFileAltTextParams accepts [attachment_id, alt_text]. An empty alt_text
+// clears the description.
+func decodeFileAltTextParams(raw json.RawMessage) (string, string, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 || strings.TrimSpace(arr[0]) == "" {
+		return "", "", errors.New("invalid params")
+	}
+	return arr[0], arr[1], nil
+}
+
+// decodeFileExtendParams accepts [blob_id, extra_seconds] or the equivalent
+// object form.
+func decodeFileExtendParams(raw json.RawMessage) (string, int, error) {
+	var blobID string
+	var extraSeconds int
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err == nil {
+		if len(arr) != 2 || json.Unmarshal(arr[0], &blobID) != nil || json.Unmarshal(arr[1], &extraSeconds) != nil {
+			return "", 0, errors.New("invalid params")
+		}
+	} else {
+		var payload struct {
+			BlobID       string `json:"blob_id"`
+			ExtraSeconds int    `json:"extra_seconds"`
+		}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", 0, errors.New("invalid params")
+		}
+		blobID, extraSeconds = payload.BlobID, payload.ExtraSeconds
 	}
-	return payload.Name, payload.MimeType, payload.DataBase64, nil
+	if strings.TrimSpace(blobID) == "" || extraSeconds <= 0 {
+		return "", 0, errors.New("invalid params")
+	}
+	return blobID, extraSeconds, nil
 }
 
 type fileUploadInitParams struct {
@@ -128,6 +251,7 @@ type fileUploadInitParams struct {
 	TotalChunks int    `json:"total_chunks"`
 	ChunkSize   int    `json:"chunk_size"`
 	FileSHA256  string `json:"file_sha256"`
+	AltText     string `json:"alt_text"`
 }
 
 type fileUploadChunkParams struct {
@@ -197,3 +321,41 @@ func decodeFileUploadCommitParams(raw json.RawMessage) (fileUploadCommitParams,
 	}
 	return p, nil
 }
+
+type fileDownloadInitParams struct {
+	AttachmentID string `json:"attachment_id"`
+	ChunkSize    int    `json:"chunk_size"`
+}
+
+type fileDownloadChunkParams struct {
+	DownloadID string `json:"download_id"`
+	ChunkIndex int    `json:"chunk_index"`
+}
+
+func decodeFileDownloadInitParams(raw json.RawMessage) (fileDownloadInitParams, error) {
+	var arr []fileDownloadInitParams
+	var p fileDownloadInitParams
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		p = arr[0]
+	} else if err := json.Unmarshal(raw, &p); err != nil {
+		return fileDownloadInitParams{}, errors.New("invalid params")
+	}
+	if strings.TrimSpace(p.AttachmentID) == "" || p.ChunkSize < 0 {
+		return fileDownloadInitParams{}, errors.New("invalid params")
+	}
+	return p, nil
+}
+
+func decodeFileDownloadChunkParams(raw json.RawMessage) (fileDownloadChunkParams, error) {
+	var arr []fileDownloadChunkParams
+	var p fileDownloadChunkParams
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		p = arr[0]
+	} else if err := json.Unmarshal(raw, &p); err != nil {
+		return fileDownloadChunkParams{}, errors.New("invalid params")
+	}
+	if strings.TrimSpace(p.DownloadID) == "" || p.ChunkIndex < 0 {
+		return fileDownloadChunkParams{}, errors.New("invalid params")
+	}
+	return p, nil
+}
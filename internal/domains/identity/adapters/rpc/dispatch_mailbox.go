@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func dispatchMailboxRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case "mailbox.status":
+		result, rpcErr := callWithoutParams(-32360, func() (any, error) {
+			mailboxAPI, ok := service.(interface {
+				GetMailboxStatus() (models.MailboxStatus, error)
+			})
+			if !ok {
+				return nil, errors.New("mailbox is not supported")
+			}
+			return mailboxAPI.GetMailboxStatus()
+		})
+		return result, rpcErr, true
+	case "mailbox.policy.set":
+		senderQuotaMB, eviction, err := decodeMailboxPolicyParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32361, func() (any, error) {
+			mailboxAPI, ok := service.(interface {
+				SetMailboxPolicy(senderQuotaMB *int, eviction *string) (models.MailboxStatus, error)
+			})
+			if !ok {
+				return nil, errors.New("mailbox is not supported")
+			}
+			return mailboxAPI.SetMailboxPolicy(senderQuotaMB, eviction)
+		})
+		return result, rpcErr, true
+	case "mailbox.flush":
+		result, rpcErr := callWithoutParams(-32362, func() (any, error) {
+			mailboxAPI, ok := service.(interface {
+				FlushMailbox() (int, error)
+			})
+			if !ok {
+				return nil, errors.New("mailbox is not supported")
+			}
+			evicted, err := mailboxAPI.FlushMailbox()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]int{"evicted": evicted}, nil
+		})
+		return result, rpcErr, true
+	case "mailbox.purge":
+		senderID, err := decodeMailboxPurgeParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32363, func() (any, error) {
+			mailboxAPI, ok := service.(interface {
+				PurgeMailbox(senderID string) (int, error)
+			})
+			if !ok {
+				return nil, errors.New("mailbox is not supported")
+			}
+			purged, err := mailboxAPI.PurgeMailbox(senderID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]int{"purged": purged}, nil
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
+
+func decodeMailboxPolicyParams(raw json.RawMessage) (*int, *string, error) {
+	type payload struct {
+		SenderQuotaMB *int    `json:"sender_quota_mb"`
+		Eviction      *string `json:"eviction"`
+	}
+	parse := func(p payload) (*int, *string, error) {
+		if p.SenderQuotaMB == nil && p.Eviction == nil {
+			return nil, nil, errors.New("invalid params")
+		}
+		if p.Eviction != nil {
+			eviction := strings.TrimSpace(*p.Eviction)
+			p.Eviction = &eviction
+		}
+		return p.SenderQuotaMB, p.Eviction, nil
+	}
+	var arr []payload
+	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 1 {
+		return parse(arr[0])
+	}
+	var direct payload
+	if err := json.Unmarshal(raw, &direct); err == nil {
+		return parse(direct)
+	}
+	return nil, nil, errors.New("invalid params")
+}
+
+// decodeMailboxPurgeParams reads the optional sender to purge; without one
+// the whole mailbox is purged.
+func decodeMailboxPurgeParams(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	type payload struct {
+		SenderID string `json:"sender_id"`
+	}
+	var arr []payload
+	if err := json.Unmarshal(raw, &arr); err == nil {
+		if len(arr) == 0 {
+			return "", nil
+		}
+		if len(arr) == 1 {
+			return strings.TrimSpace(arr[0].SenderID), nil
+		}
+		return "", errors.New("invalid params")
+	}
+	var direct payload
+	if err := json.Unmarshal(raw, &direct); err == nil {
+		return strings.TrimSpace(direct.SenderID), nil
+	}
+	return "", errors.New("invalid params")
+}
@@ -11,6 +11,18 @@ func dispatchContactFileBlobNodeDevice(service contracts.DaemonService, method s
 	if result, rpcErr, ok := dispatchContactRPC(service, method, rawParams); ok {
 		return result, rpcErr, true
 	}
+	if result, rpcErr, ok := dispatchAttachmentPolicyRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchContactSharingRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchUsernameRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchAttestationRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
 	if result, rpcErr, ok := dispatchFileUploadRPC(service, method, rawParams); ok {
 		return result, rpcErr, true
 	}
@@ -20,5 +32,20 @@ func dispatchContactFileBlobNodeDevice(service contracts.DaemonService, method s
 	if result, rpcErr, ok := dispatchNodeBindingRPC(service, method, rawParams); ok {
 		return result, rpcErr, true
 	}
+	if result, rpcErr, ok := dispatchMailboxRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchClientStateRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchStorageKeyRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchStorageSnapshotRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
+	if result, rpcErr, ok := dispatchContentSafetyRPC(service, method, rawParams); ok {
+		return result, rpcErr, true
+	}
 	return dispatchDeviceRPC(service, method, rawParams)
 }
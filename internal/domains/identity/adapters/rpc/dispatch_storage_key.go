@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type storageKeyService interface {
+	RotateStorageKey(password string) (models.StorageKeyRotationStatus, error)
+	GetStorageKeyRotationStatus() models.StorageKeyRotationStatus
+}
+
+var errStorageKeyRotationNotSupported = errors.New("storage key rotation is not supported")
+
+func dispatchStorageKeyRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodStorageRotateKey:
+		result, rpcErr := callWithSingleStringParam(rawParams, -32307, func(password string) (any, error) {
+			rotator, ok := service.(storageKeyService)
+			if !ok {
+				return nil, errStorageKeyRotationNotSupported
+			}
+			return rotator.RotateStorageKey(password)
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodStorageRotation:
+		result, rpcErr := callWithoutParams(-32308, func() (any, error) {
+			rotator, ok := service.(storageKeyService)
+			if !ok {
+				return nil, errStorageKeyRotationNotSupported
+			}
+			return rotator.GetStorageKeyRotationStatus(), nil
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
+)
+
+type storageSnapshotService interface {
+	CreateStorageSnapshot(label string) (models.StorageSnapshot, error)
+	ListStorageSnapshots() ([]models.StorageSnapshot, error)
+	RestoreStorageSnapshot(snapshotID string) (models.StorageSnapshotRestoreResult, error)
+}
+
+type storageVersionService interface {
+	GetStorageVersion() (models.StorageVersionInfo, error)
+}
+
+var errStorageSnapshotsNotSupported = errors.New("storage snapshots are not supported")
+
+func dispatchStorageSnapshotRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodStorageSnapCreate:
+		var params []string
+		if len(rawParams) > 0 && string(rawParams) != "null" {
+			if err := json.Unmarshal(rawParams, &params); err != nil || len(params) > 1 {
+				return nil, rpckit.InvalidParams(), true
+			}
+		}
+		result, rpcErr := callWithoutParams(-32321, func() (any, error) {
+			snapshots, ok := service.(storageSnapshotService)
+			if !ok {
+				return nil, errStorageSnapshotsNotSupported
+			}
+			label := ""
+			if len(params) == 1 {
+				label = params[0]
+			}
+			return snapshots.CreateStorageSnapshot(label)
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodStorageSnapList:
+		result, rpcErr := callWithoutParams(-32322, func() (any, error) {
+			snapshots, ok := service.(storageSnapshotService)
+			if !ok {
+				return nil, errStorageSnapshotsNotSupported
+			}
+			return snapshots.ListStorageSnapshots()
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodStorageSnapRestore:
+		result, rpcErr := callWithSingleStringParam(rawParams, -32323, func(snapshotID string) (any, error) {
+			snapshots, ok := service.(storageSnapshotService)
+			if !ok {
+				return nil, errStorageSnapshotsNotSupported
+			}
+			return snapshots.RestoreStorageSnapshot(snapshotID)
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodStorageVersion:
+		result, rpcErr := callWithoutParams(-32324, func() (any, error) {
+			versions, ok := service.(storageVersionService)
+			if !ok {
+				return nil, errors.New("storage version reporting is not supported")
+			}
+			return versions.GetStorageVersion()
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	identitytransport "aim-chat/go-backend/internal/domains/identity/transport"
+	"aim-chat/go-backend/internal/domains/rpckit"
+)
+
+type usernameService interface {
+	ClaimUsername(username string) (map[string]any, error)
+	SetUsernameDisplay(mode string) (string, error)
+}
+
+var errUsernamesNotSupported = errors.New("username claims are not supported")
+
+func dispatchUsernameRPC(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	switch method {
+	case identitytransport.MethodUsernameClaim:
+		result, rpcErr := callWithSingleStringParam(rawParams, -32344, func(username string) (any, error) {
+			usernames, ok := service.(usernameService)
+			if !ok {
+				return nil, errUsernamesNotSupported
+			}
+			return usernames.ClaimUsername(username)
+		})
+		return result, rpcErr, true
+	case identitytransport.MethodUsernameRelease:
+		result, rpcErr := callWithoutParams(-32345, func() (any, error) {
+			usernames, ok := service.(usernameService)
+			if !ok {
+				return nil, errUsernamesNotSupported
+			}
+			return usernames.ClaimUsername("")
+		})
+		return result, rpcErr, true
+	case "contact.username_display.set":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32346, func(mode string) (any, error) {
+			usernames, ok := service.(usernameService)
+			if !ok {
+				return nil, errUsernamesNotSupported
+			}
+			display, err := usernames.SetUsernameDisplay(mode)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"username_display": display}, nil
+		})
+		return result, rpcErr, true
+	default:
+		return nil, nil, false
+	}
+}
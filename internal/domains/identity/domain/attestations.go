@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// SetSelfAttestations sets the attestations carried by cards signed by this
+// identity and reports whether they changed.
+func (m *Manager) SetSelfAttestations(attestations []models.Attestation) (bool, error) {
+	normalized, err := identitypolicy.NormalizeAttestations(attestations)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if sameAttestations(m.attestations, normalized) {
+		return false, nil
+	}
+	m.attestations = normalized
+	return true, nil
+}
+
+func (m *Manager) SelfAttestations() []models.Attestation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return cloneAttestations(m.attestations)
+}
+
+// ApplyContactVerification records the outcome of checking a contact's
+// attestations. An empty source clears the badge.
+func (m *Manager) ApplyContactVerification(contactID, source string, now time.Time) (models.Contact, bool, error) {
+	contactID = strings.TrimSpace(contactID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	contact, ok := m.contacts[contactID]
+	if !ok {
+		return models.Contact{}, false, ErrInvalidContactID
+	}
+	if source != "" && !slices.ContainsFunc(contact.Attestations, func(att models.Attestation) bool {
+		return identitypolicy.AttestationSource(att) == source
+	}) {
+		return models.Contact{}, false, identitypolicy.ErrInvalidAttestation
+	}
+	changed := contact.Verified != (source != "") || contact.VerifiedSource != source
+	contact.Verified = source != ""
+	contact.VerifiedSource = source
+	contact.VerifiedAt = time.Time{}
+	if contact.Verified {
+		contact.VerifiedAt = now.UTC()
+	}
+	m.contacts[contactID] = contact
+	return contact, changed, nil
+}
+
+// withCardAttestations stores the attestations of a newly verified card. The
+// badge survives only while the card still carries the attestation that
+// earned it.
+func withCardAttestations(contact models.Contact, attestations []models.Attestation) models.Contact {
+	contact.Attestations = cloneAttestations(attestations)
+	if !contact.Verified {
+		return contact
+	}
+	for _, att := range contact.Attestations {
+		if identitypolicy.AttestationSource(att) == contact.VerifiedSource {
+			return contact
+		}
+	}
+	contact.Verified = false
+	contact.VerifiedSource = ""
+	contact.VerifiedAt = time.Time{}
+	return contact
+}
+
+func cloneAttestations(in []models.Attestation) []models.Attestation {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]models.Attestation, len(in))
+	for i, att := range in {
+		att.Signature = append([]byte(nil), att.Signature...)
+		out[i] = att
+	}
+	return out
+}
+
+func sameAttestations(a, b []models.Attestation) bool {
+	return slices.EqualFunc(a, b, func(x, y models.Attestation) bool {
+		return x.Kind == y.Kind && x.Subject == y.Subject && string(x.Signature) == string(y.Signature)
+	})
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactVerificationFollowsCardAttestations(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	identity := sender.GetIdentity()
+	_, authorityPriv, _ := ed25519.GenerateKey(rand.Reader)
+	att, err := identitypolicy.SignAuthorityAttestation("community-1", identity.ID, identity.SigningPublicKey, authorityPriv)
+	if err != nil {
+		t.Fatalf("sign attestation: %v", err)
+	}
+	if changed, err := sender.SetSelfAttestations([]models.Attestation{att}); err != nil || !changed {
+		t.Fatalf("set self attestations: changed=%v err=%v", changed, err)
+	}
+	card, err := sender.SelfContactCard("sender")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	if _, _, err := receiver.ApplyContactCardRefresh(card, time.Now()); err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if _, _, err := receiver.ApplyContactVerification(identity.ID, "domain:example.org", time.Now()); err == nil {
+		t.Fatal("a source the card does not carry must be rejected")
+	}
+	contact, changed, err := receiver.ApplyContactVerification(identity.ID, "authority:community-1", time.Now())
+	if err != nil || !changed || !contact.Verified || contact.VerifiedSource != "authority:community-1" {
+		t.Fatalf("unexpected verification: %+v changed=%v err=%v", contact, changed, err)
+	}
+
+	restored, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := restored.RestoreRuntimeStateJSON(receiver.SnapshotRuntimeStateJSON()); err != nil {
+		t.Fatalf("restore runtime state: %v", err)
+	}
+	for _, c := range restored.Contacts() {
+		if c.ID == identity.ID && (!c.Verified || len(c.Attestations) != 1) {
+			t.Fatalf("verification must survive a restart: %+v", c)
+		}
+	}
+
+	if _, err := sender.SetSelfAttestations(nil); err != nil {
+		t.Fatalf("clear self attestations: %v", err)
+	}
+	card, err = sender.SelfContactCard("sender")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	contact, _, err = receiver.ApplyContactCardRefresh(card, time.Now())
+	if err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if contact.Verified || contact.VerifiedSource != "" || len(contact.Attestations) != 0 {
+		t.Fatalf("badge must go with the attestation: %+v", contact)
+	}
+}
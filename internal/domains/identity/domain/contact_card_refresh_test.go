@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyContactCardRefreshFollowsAdvertisedName(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	senderID := sender.GetIdentity().ID
+	now := time.Now().Add(time.Hour)
+
+	card, err := sender.SelfContactCard("sender renamed")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	contact, changed, err := receiver.ApplyContactCardRefresh(card, now)
+	if err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if !changed || contact.DisplayName != "sender renamed" || contact.CardName != "sender renamed" {
+		t.Fatalf("unexpected contact after refresh: %+v changed=%v", contact, changed)
+	}
+	if !contact.CardRefreshedAt.Equal(now.UTC()) || contact.ID != senderID {
+		t.Fatalf("refresh time not recorded: %+v", contact)
+	}
+
+	_, changed, err = receiver.ApplyContactCardRefresh(card, now)
+	if err != nil || changed {
+		t.Fatalf("same card must not report a change: changed=%v err=%v", changed, err)
+	}
+}
+
+func TestApplyContactCardRefreshKeepsLocalAlias(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	senderID := sender.GetIdentity().ID
+	receiver.mu.Lock()
+	contact := receiver.contacts[senderID]
+	contact.DisplayName = "my friend"
+	receiver.contacts[senderID] = contact
+	receiver.mu.Unlock()
+
+	card, err := sender.SelfContactCard("sender renamed")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	updated, changed, err := receiver.ApplyContactCardRefresh(card, time.Now())
+	if err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if !changed || updated.DisplayName != "my friend" || updated.CardName != "sender renamed" {
+		t.Fatalf("local alias must be preserved: %+v", updated)
+	}
+}
+
+func TestApplyContactCardRefreshRejectsUnknownOrRotatedKey(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	stranger, err := NewManager()
+	if err != nil {
+		t.Fatalf("new stranger manager: %v", err)
+	}
+	if _, _, err := stranger.CreateIdentity("pass-3"); err != nil {
+		t.Fatalf("create stranger identity: %v", err)
+	}
+	strangerCard, err := stranger.SelfContactCard("stranger")
+	if err != nil {
+		t.Fatalf("stranger self card: %v", err)
+	}
+	if _, _, err := receiver.ApplyContactCardRefresh(strangerCard, time.Now()); !errors.Is(err, ErrUnverifiedContact) {
+		t.Fatalf("expected unverified contact error, got %v", err)
+	}
+
+	senderID := sender.GetIdentity().ID
+	receiver.mu.Lock()
+	contact := receiver.contacts[senderID]
+	contact.PublicKey = append([]byte(nil), strangerCard.PublicKey...)
+	receiver.contacts[senderID] = contact
+	receiver.mu.Unlock()
+	card, err := sender.SelfContactCard("sender")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	if _, _, err := receiver.ApplyContactCardRefresh(card, time.Now()); !errors.Is(err, ErrContactKeyMismatch) {
+		t.Fatalf("expected key mismatch, got %v", err)
+	}
+}
+
+func TestMarkStaleContactCardsFlagsOnceAndRefreshClears(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	senderID := sender.GetIdentity().ID
+
+	if marked := receiver.MarkStaleContactCards(time.Now().Add(-time.Hour)); len(marked) != 0 {
+		t.Fatalf("fresh card must not be stale: %v", marked)
+	}
+	marked := receiver.MarkStaleContactCards(time.Now().Add(time.Hour))
+	if len(marked) != 1 || marked[0] != senderID {
+		t.Fatalf("expected sender to become stale, got %v", marked)
+	}
+	if again := receiver.MarkStaleContactCards(time.Now().Add(time.Hour)); len(again) != 0 {
+		t.Fatalf("stale contact must be reported once, got %v", again)
+	}
+
+	card, err := sender.SelfContactCard("sender")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	contact, _, err := receiver.ApplyContactCardRefresh(card, time.Now())
+	if err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if contact.CardStale {
+		t.Fatal("refresh must clear the stale flag")
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/internal/platform/contentsafety"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrContactAlreadyMerged = errors.New("contact is already merged into another identity")
+
+// maxMergeHops bounds redirect chains so a corrupted state cannot loop.
+const maxMergeHops = 8
+
+// MergeContact moves a verified contact to the identity announced by its
+// transition statement. The new contact inherits the local alias and the
+// history links; the old one stays as a read-only pointer to the new id.
+func (m *Manager) MergeContact(oldID, newID string, transition models.IdentityTransition, now time.Time) (models.Contact, error) {
+	oldID = strings.TrimSpace(oldID)
+	newID = strings.TrimSpace(newID)
+	if oldID == "" || newID == "" || oldID == newID {
+		return models.Contact{}, ErrInvalidContactID
+	}
+	if transition.OldID != oldID || transition.NewCard.IdentityID != newID {
+		return models.Contact{}, identitypolicy.ErrInvalidIdentityTransition
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.contacts[oldID]
+	if !ok || len(old.PublicKey) != ed25519.PublicKeySize {
+		return models.Contact{}, ErrUnverifiedContact
+	}
+	if err := identitypolicy.VerifyIdentityTransition(transition, old.PublicKey); err != nil {
+		return models.Contact{}, err
+	}
+	if old.MergedInto != "" && old.MergedInto != newID {
+		return models.Contact{}, ErrContactAlreadyMerged
+	}
+	card := transition.NewCard
+	next, exists := m.contacts[newID]
+	if exists && len(next.PublicKey) == ed25519.PublicKeySize && !bytes.Equal(next.PublicKey, card.PublicKey) {
+		return models.Contact{}, ErrContactKeyMismatch
+	}
+	if !exists {
+		next = models.Contact{ID: newID, AddedAt: now}
+	}
+	if next.DisplayName == "" || next.DisplayName == newID {
+		next.DisplayName = old.DisplayName
+	}
+	if next.Alias == "" {
+		next.Alias, next.Notes, next.Color = old.Alias, old.Notes, old.Color
+	}
+	if next.Alias != "" {
+		next.DisplayName = next.Alias
+	}
+	next.PublicKey = append([]byte(nil), card.PublicKey...)
+	next.CardName = card.DisplayName
+	next.CardRefreshedAt = now.UTC()
+	next.CardStale = false
+	next.PowDifficulty = card.PowDifficulty
+	next.Username = card.Username
+	next.Capabilities = append([]string(nil), card.Capabilities...)
+	next = withCardAttestations(next, card.Attestations)
+	next.SafetyFlags = contentsafety.CheckDisplayName(card.DisplayName)
+	next.MergedFrom = appendMissing(next.MergedFrom, append(append([]string(nil), old.MergedFrom...), oldID)...)
+	old.MergedInto = newID
+	m.contacts[oldID] = old
+	m.contacts[newID] = next
+	m.refreshUsernameConflictsLocked()
+	return m.contacts[newID], nil
+}
+
+// ResolveContactID follows merge redirects and returns the id that currently
+// receives messages for contactID.
+func (m *Manager) ResolveContactID(contactID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := 0; i < maxMergeHops; i++ {
+		contact, ok := m.contacts[contactID]
+		if !ok || contact.MergedInto == "" {
+			return contactID
+		}
+		contactID = contact.MergedInto
+	}
+	return contactID
+}
+
+func appendMissing(ids []string, add ...string) []string {
+	for _, id := range add {
+		found := false
+		for _, existing := range ids {
+			if existing == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func newRotationTransition(t *testing.T, oldManager *Manager) (models.IdentityTransition, *Manager) {
+	t.Helper()
+	next, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if _, _, err := next.CreateIdentity("pass-3"); err != nil {
+		t.Fatalf("create new identity: %v", err)
+	}
+	card, err := next.SelfContactCard("sender new")
+	if err != nil {
+		t.Fatalf("new self card: %v", err)
+	}
+	_, oldPriv := oldManager.SnapshotIdentityKeys()
+	transition, err := identitypolicy.SignIdentityTransition(oldManager.GetIdentity().ID, card, time.Now(), oldPriv)
+	if err != nil {
+		t.Fatalf("sign transition: %v", err)
+	}
+	return transition, next
+}
+
+func TestMergeContactRedirectsToNewIdentity(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	oldID := sender.GetIdentity().ID
+	receiver.mu.Lock()
+	contact := receiver.contacts[oldID]
+	contact.DisplayName = "my friend"
+	receiver.contacts[oldID] = contact
+	receiver.mu.Unlock()
+
+	transition, next := newRotationTransition(t, sender)
+	newID := next.GetIdentity().ID
+	merged, err := receiver.MergeContact(oldID, newID, transition, time.Now())
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if merged.ID != newID || merged.DisplayName != "my friend" || merged.CardName != "sender new" {
+		t.Fatalf("unexpected merged contact: %+v", merged)
+	}
+	if len(merged.MergedFrom) != 1 || merged.MergedFrom[0] != oldID {
+		t.Fatalf("history link missing: %+v", merged.MergedFrom)
+	}
+	if got := receiver.ResolveContactID(oldID); got != newID {
+		t.Fatalf("expected sends to redirect to %s, got %s", newID, got)
+	}
+	if !receiver.HasContact(oldID) || !receiver.HasVerifiedContact(newID) {
+		t.Fatal("old thread must be kept and new contact verified")
+	}
+
+	restored, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := restored.RestoreRuntimeStateJSON(receiver.SnapshotRuntimeStateJSON()); err != nil {
+		t.Fatalf("restore runtime state: %v", err)
+	}
+	if got := restored.ResolveContactID(oldID); got != newID {
+		t.Fatalf("merge not persisted: %s", got)
+	}
+}
+
+func TestMergeContactRejectsForgedTransition(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	oldID := sender.GetIdentity().ID
+	stranger, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if _, _, err := stranger.CreateIdentity("pass-4"); err != nil {
+		t.Fatalf("create stranger identity: %v", err)
+	}
+	transition, next := newRotationTransition(t, stranger)
+	transition.OldID = oldID
+	if _, err := receiver.MergeContact(oldID, next.GetIdentity().ID, transition, time.Now()); !errors.Is(err, identitypolicy.ErrInvalidIdentityTransition) {
+		t.Fatalf("expected ErrInvalidIdentityTransition, got %v", err)
+	}
+	if got := receiver.ResolveContactID(oldID); got != oldID {
+		t.Fatalf("rejected merge must not redirect, got %s", got)
+	}
+}
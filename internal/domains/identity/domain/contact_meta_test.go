@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+)
+
+func TestUpdateContactMetaSetsAndClearsAlias(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	senderID := sender.GetIdentity().ID
+
+	contact, err := receiver.UpdateContactMeta(senderID, "  Bobby ", "met at the conference", "#A0B1C2")
+	if err != nil {
+		t.Fatalf("update contact meta: %v", err)
+	}
+	if contact.Alias != "Bobby" || contact.DisplayName != "Bobby" || contact.Notes != "met at the conference" || contact.Color != "#a0b1c2" {
+		t.Fatalf("unexpected contact: %+v", contact)
+	}
+
+	card, err := sender.SelfContactCard("Robert")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	refreshed, _, err := receiver.ApplyContactCardRefresh(card, time.Now())
+	if err != nil {
+		t.Fatalf("apply refresh: %v", err)
+	}
+	if refreshed.DisplayName != "Bobby" || refreshed.CardName != "Robert" || refreshed.Notes == "" {
+		t.Fatalf("card refresh must keep the alias and metadata: %+v", refreshed)
+	}
+
+	restored, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := restored.RestoreRuntimeStateJSON(receiver.SnapshotRuntimeStateJSON()); err != nil {
+		t.Fatalf("restore runtime state: %v", err)
+	}
+	for _, c := range restored.Contacts() {
+		if c.ID == senderID && (c.Alias != "Bobby" || c.Color != "#a0b1c2" || c.Notes != "met at the conference") {
+			t.Fatalf("metadata must persist: %+v", c)
+		}
+	}
+
+	cleared, err := receiver.UpdateContactMeta(senderID, "", "", "")
+	if err != nil {
+		t.Fatalf("clear contact meta: %v", err)
+	}
+	if cleared.Alias != "" || cleared.DisplayName != "Robert" || cleared.Notes != "" || cleared.Color != "" {
+		t.Fatalf("clearing the alias must restore the card name: %+v", cleared)
+	}
+}
+
+func TestUpdateContactMetaRejectsInvalidInput(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	senderID := sender.GetIdentity().ID
+
+	if _, err := receiver.UpdateContactMeta("aim1unknowncontact", "x", "", ""); !errors.Is(err, ErrInvalidContactID) {
+		t.Fatalf("expected unknown contact error, got %v", err)
+	}
+	for _, tc := range []struct{ alias, notes, color string }{
+		{alias: strings.Repeat("a", identitypolicy.MaxContactAliasLen+1)},
+		{alias: "two\nlines"},
+		{notes: strings.Repeat("n", identitypolicy.MaxContactNotesLen+1)},
+		{color: "red"},
+		{color: "#12345g"},
+	} {
+		if _, err := receiver.UpdateContactMeta(senderID, tc.alias, tc.notes, tc.color); !errors.Is(err, identitypolicy.ErrInvalidContactMeta) {
+			t.Fatalf("expected invalid metadata for %+v, got %v", tc, err)
+		}
+	}
+}
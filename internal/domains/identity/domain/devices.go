@@ -1,11 +1,13 @@
 package domain
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,11 +17,13 @@ import (
 )
 
 var (
-	ErrDeviceNotFound    = errors.New("device not found")
-	ErrDeviceRevoked     = errors.New("device revoked")
-	ErrInvalidDeviceSig  = errors.New("invalid device signature")
-	ErrInvalidDeviceCert = errors.New("invalid device certificate")
-	ErrUnverifiedContact = errors.New("contact is not verified")
+	ErrDeviceNotFound            = errors.New("device not found")
+	ErrDeviceRevoked             = errors.New("device revoked")
+	ErrInvalidDeviceSig          = errors.New("invalid device signature")
+	ErrInvalidDeviceCert         = errors.New("invalid device certificate")
+	ErrInvalidSelfSigningKey     = errors.New("invalid self-signing key")
+	ErrUnverifiedContact         = errors.New("contact is not verified")
+	errSelfSigningKeyUnavailable = errors.New("self-signing key is not initialized")
 )
 
 type devicePrivate struct {
@@ -31,28 +35,81 @@ func (m *Manager) initPrimaryDevice() error {
 	if len(m.selfPriv) < 32 {
 		return errors.New("invalid identity private key")
 	}
+	if err := m.initSelfSigningKeyLocked(); err != nil {
+		return err
+	}
 	seed := m.selfPriv[:32]
 	priv := ed25519.NewKeyFromSeed(seed)
 	pub := priv.Public().(ed25519.PublicKey)
 	id := deviceIDFromPub(pub)
-	certSig := ed25519.Sign(m.selfPriv, deviceCertBytes(m.identity.ID, id, pub))
-	now := time.Now().UTC()
+	device, err := m.certifyDeviceLocked(models.Device{
+		ID:        id,
+		Name:      "primary",
+		PublicKey: append([]byte(nil), pub...),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
 	m.devices = map[string]devicePrivate{
 		id: {
-			model: models.Device{
-				ID:        id,
-				Name:      "primary",
-				PublicKey: append([]byte(nil), pub...),
-				CertSig:   certSig,
-				CreatedAt: now,
-			},
-			priv: append(ed25519.PrivateKey(nil), priv...),
+			model: device,
+			priv:  append(ed25519.PrivateKey(nil), priv...),
 		},
 	}
 	m.activeDeviceID = id
 	return nil
 }
 
+// initSelfSigningKeyLocked derives the intermediate self-signing key from the
+// identity seed. The identity (master) key only signs the self-signing key;
+// device certificates are issued by the self-signing key.
+func (m *Manager) initSelfSigningKeyLocked() error {
+	seed, err := deriveSelfSigningSeed(m.selfPriv[:32])
+	if err != nil {
+		return err
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub := priv.Public().(ed25519.PublicKey)
+	m.selfSigningPriv = append(ed25519.PrivateKey(nil), priv...)
+	m.selfSigningSig = ed25519.Sign(m.selfPriv, selfSigningKeyBytes(m.identity.ID, pub))
+	return nil
+}
+
+func (m *Manager) certifyDeviceLocked(device models.Device) (models.Device, error) {
+	if len(m.selfSigningPriv) != ed25519.PrivateKeySize {
+		return models.Device{}, errSelfSigningKeyUnavailable
+	}
+	sskPub := m.selfSigningPriv.Public().(ed25519.PublicKey)
+	device.SelfSigningKey = append([]byte(nil), sskPub...)
+	device.SelfSigningSig = append([]byte(nil), m.selfSigningSig...)
+	device.CertSig = ed25519.Sign(m.selfSigningPriv, deviceCertBytes(m.identity.ID, device.ID, device.PublicKey))
+	device.CertFingerprint = deviceCertFingerprint(m.identity.ID, device)
+	return device, nil
+}
+
+// recertifyDevicesLocked upgrades devices restored from state written before
+// cross-signing existed, so their certificates chain through the current
+// self-signing key.
+func (m *Manager) recertifyDevicesLocked() error {
+	if len(m.selfSigningPriv) != ed25519.PrivateKeySize {
+		return nil
+	}
+	sskPub := m.selfSigningPriv.Public().(ed25519.PublicKey)
+	for id, d := range m.devices {
+		if bytes.Equal(d.model.SelfSigningKey, sskPub) && d.model.CertFingerprint != "" {
+			continue
+		}
+		certified, err := m.certifyDeviceLocked(d.model)
+		if err != nil {
+			return err
+		}
+		d.model = certified
+		m.devices[id] = d
+	}
+	return nil
+}
+
 func (m *Manager) ListDevices() []models.Device {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -78,13 +135,14 @@ func (m *Manager) AddDevice(name string) (models.Device, error) {
 	priv := ed25519.NewKeyFromSeed(seed)
 	pub := priv.Public().(ed25519.PublicKey)
 	id := deviceIDFromPub(pub)
-	now := time.Now().UTC()
-	device := models.Device{
+	device, err := m.certifyDeviceLocked(models.Device{
 		ID:        id,
 		Name:      name,
 		PublicKey: append([]byte(nil), pub...),
-		CertSig:   ed25519.Sign(m.selfPriv, deviceCertBytes(m.identity.ID, id, pub)),
-		CreatedAt: now,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return models.Device{}, err
 	}
 	m.devices[id] = devicePrivate{
 		model: device,
@@ -124,8 +182,8 @@ func (m *Manager) ActiveDeviceAuth(payload []byte) (models.Device, []byte, error
 }
 
 func (m *Manager) VerifyInboundDevice(contactID string, device models.Device, payload, sig []byte) error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	contact, ok := m.contacts[contactID]
 	if !ok {
@@ -140,8 +198,26 @@ func (m *Manager) VerifyInboundDevice(contactID string, device models.Device, pa
 			return ErrDeviceRevoked
 		}
 	}
-	if !ed25519.Verify(contact.PublicKey, deviceCertBytes(contactID, device.ID, device.PublicKey), device.CertSig) {
-		return ErrInvalidDeviceCert
+	if err := verifyDeviceChain(contactID, contact.PublicKey, device); err != nil {
+		return err
+	}
+	if !ed25519.Verify(device.PublicKey, payload, sig) {
+		return ErrInvalidDeviceSig
+	}
+	m.recordPeerDeviceLocked(contactID, device)
+	return nil
+}
+
+// VerifyOwnDevice checks a payload signed by one of our own devices, such as
+// another installation of this identity. Devices revoked locally are refused.
+func (m *Manager) VerifyOwnDevice(device models.Device, payload, sig []byte) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if own, ok := m.devices[device.ID]; ok && own.model.IsRevoked {
+		return ErrDeviceRevoked
+	}
+	if err := verifyDeviceChain(m.identity.ID, m.identity.SigningPublicKey, device); err != nil {
+		return err
 	}
 	if !ed25519.Verify(device.PublicKey, payload, sig) {
 		return ErrInvalidDeviceSig
@@ -149,6 +225,47 @@ func (m *Manager) VerifyInboundDevice(contactID string, device models.Device, pa
 	return nil
 }
 
+// recordPeerDeviceLocked remembers a verified peer device. The first-seen
+// certificate is kept unless the device presents a different chain.
+func (m *Manager) recordPeerDeviceLocked(contactID string, device models.Device) {
+	if m.peerDevices == nil {
+		m.peerDevices = make(map[string]map[string]models.Device)
+	}
+	if m.peerDevices[contactID] == nil {
+		m.peerDevices[contactID] = make(map[string]models.Device)
+	}
+	fingerprint := deviceCertFingerprint(contactID, device)
+	if known, ok := m.peerDevices[contactID][device.ID]; ok && known.CertFingerprint == fingerprint {
+		return
+	}
+	recorded := cloneDevice(device)
+	recorded.CertFingerprint = fingerprint
+	recorded.IsRevoked = false
+	recorded.RevokedAt = time.Time{}
+	if recorded.CreatedAt.IsZero() {
+		recorded.CreatedAt = time.Now().UTC()
+	}
+	m.peerDevices[contactID][device.ID] = recorded
+}
+
+// PeerDevices lists the verified devices seen for a contact, including ones
+// that have since been revoked.
+func (m *Manager) PeerDevices(contactID string) []models.Device {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	known := m.peerDevices[contactID]
+	out := make([]models.Device, 0, len(known))
+	for deviceID, device := range known {
+		d := cloneDevice(device)
+		if _, revoked := m.revokedDevices[contactID][deviceID]; revoked {
+			d.IsRevoked = true
+		}
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
 func (m *Manager) ApplyDeviceRevocation(contactID string, rev models.DeviceRevocation) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -186,6 +303,32 @@ func (m *Manager) buildRevocationLocked(deviceID string) models.DeviceRevocation
 	}
 }
 
+// verifyDeviceChain checks identity key -> self-signing key -> device key.
+// Devices without a self-signing key are legacy certificates signed directly
+// by the identity key.
+func verifyDeviceChain(identityID string, identityPub []byte, device models.Device) error {
+	if len(device.PublicKey) != ed25519.PublicKeySize {
+		return ErrInvalidDeviceCert
+	}
+	certPayload := deviceCertBytes(identityID, device.ID, device.PublicKey)
+	if len(device.SelfSigningKey) == 0 {
+		if !ed25519.Verify(identityPub, certPayload, device.CertSig) {
+			return ErrInvalidDeviceCert
+		}
+		return nil
+	}
+	if len(device.SelfSigningKey) != ed25519.PublicKeySize {
+		return ErrInvalidSelfSigningKey
+	}
+	if !ed25519.Verify(identityPub, selfSigningKeyBytes(identityID, device.SelfSigningKey), device.SelfSigningSig) {
+		return ErrInvalidSelfSigningKey
+	}
+	if !ed25519.Verify(device.SelfSigningKey, certPayload, device.CertSig) {
+		return ErrInvalidDeviceCert
+	}
+	return nil
+}
+
 func deviceIDFromPub(pub []byte) string {
 	sum := sha256.Sum256(pub)
 	return "dev1_" + hex.EncodeToString(sum[:8])
@@ -201,6 +344,31 @@ func deviceCertBytes(identityID, deviceID string, pub []byte) []byte {
 	return b
 }
 
+func selfSigningKeyBytes(identityID string, pub []byte) []byte {
+	b := make([]byte, 0, len(identityID)+len(pub)+8)
+	b = append(b, []byte("aim/ssk")...)
+	b = append(b, 0)
+	b = append(b, []byte(identityID)...)
+	b = append(b, 0)
+	b = append(b, pub...)
+	return b
+}
+
+// deviceCertFingerprint renders a short, human-comparable digest of the full
+// certificate chain for out-of-band verification.
+func deviceCertFingerprint(identityID string, device models.Device) string {
+	h := sha256.New()
+	h.Write(deviceCertBytes(identityID, device.ID, device.PublicKey))
+	h.Write(device.CertSig)
+	h.Write(device.SelfSigningKey)
+	sum := hex.EncodeToString(h.Sum(nil)[:16])
+	groups := make([]string, 0, len(sum)/4)
+	for i := 0; i < len(sum); i += 4 {
+		groups = append(groups, sum[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
+
 func deviceRevocationBytes(identityID, deviceID string, ts time.Time) []byte {
 	return []byte(fmt.Sprintf("%s:%s:%d", identityID, deviceID, ts.UnixNano()))
 }
@@ -212,14 +380,24 @@ func deriveDeviceSeed(masterSeed []byte, index int) ([]byte, error) {
 	return out, err
 }
 
+func deriveSelfSigningSeed(masterSeed []byte) ([]byte, error) {
+	reader := hkdf.New(sha256.New, masterSeed, nil, []byte("aim/self-signing/1"))
+	out := make([]byte, 32)
+	_, err := reader.Read(out)
+	return out, err
+}
+
 func cloneDevice(d models.Device) models.Device {
 	return models.Device{
-		ID:        d.ID,
-		Name:      d.Name,
-		PublicKey: append([]byte(nil), d.PublicKey...),
-		CertSig:   append([]byte(nil), d.CertSig...),
-		CreatedAt: d.CreatedAt,
-		IsRevoked: d.IsRevoked,
-		RevokedAt: d.RevokedAt,
+		ID:              d.ID,
+		Name:            d.Name,
+		PublicKey:       append([]byte(nil), d.PublicKey...),
+		CertSig:         append([]byte(nil), d.CertSig...),
+		SelfSigningKey:  append([]byte(nil), d.SelfSigningKey...),
+		SelfSigningSig:  append([]byte(nil), d.SelfSigningSig...),
+		CertFingerprint: d.CertFingerprint,
+		CreatedAt:       d.CreatedAt,
+		IsRevoked:       d.IsRevoked,
+		RevokedAt:       d.RevokedAt,
 	}
 }
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func newPairedManagers(t *testing.T) (*Manager, *Manager) {
+	t.Helper()
+	sender, err := NewManager()
+	if err != nil {
+		t.Fatalf("new sender manager: %v", err)
+	}
+	if _, _, err := sender.CreateIdentity("pass-1"); err != nil {
+		t.Fatalf("create sender identity: %v", err)
+	}
+	receiver, err := NewManager()
+	if err != nil {
+		t.Fatalf("new receiver manager: %v", err)
+	}
+	if _, _, err := receiver.CreateIdentity("pass-2"); err != nil {
+		t.Fatalf("create receiver identity: %v", err)
+	}
+	card, err := sender.SelfContactCard("sender")
+	if err != nil {
+		t.Fatalf("sender self card: %v", err)
+	}
+	if err := receiver.AddContact(card); err != nil {
+		t.Fatalf("receiver add sender card: %v", err)
+	}
+	return sender, receiver
+}
+
+func TestVerifyInboundDeviceAcceptsCrossSignedChain(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	added, err := sender.AddDevice("laptop")
+	if err != nil {
+		t.Fatalf("add device: %v", err)
+	}
+	if len(added.SelfSigningKey) != ed25519.PublicKeySize || len(added.SelfSigningSig) == 0 {
+		t.Fatalf("expected device certificate to carry self-signing key")
+	}
+	if added.CertFingerprint == "" {
+		t.Fatalf("expected device certificate fingerprint")
+	}
+
+	payload := []byte("payload")
+	device, sig, err := sender.ActiveDeviceAuth(payload)
+	if err != nil {
+		t.Fatalf("active device auth: %v", err)
+	}
+	senderID := sender.GetIdentity().ID
+	if err := receiver.VerifyInboundDevice(senderID, device, payload, sig); err != nil {
+		t.Fatalf("verify cross-signed device: %v", err)
+	}
+	peers := receiver.PeerDevices(senderID)
+	if len(peers) != 1 || peers[0].ID != device.ID || peers[0].CertFingerprint == "" {
+		t.Fatalf("expected verified peer device to be recorded, got %+v", peers)
+	}
+}
+
+func TestVerifyInboundDeviceRejectsForgedSelfSigningKey(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	payload := []byte("payload")
+	device, sig, err := sender.ActiveDeviceAuth(payload)
+	if err != nil {
+		t.Fatalf("active device auth: %v", err)
+	}
+
+	forgedPub, forgedPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate forged key: %v", err)
+	}
+	senderID := sender.GetIdentity().ID
+	device.SelfSigningKey = forgedPub
+	device.CertSig = ed25519.Sign(forgedPriv, deviceCertBytes(senderID, device.ID, device.PublicKey))
+	err = receiver.VerifyInboundDevice(senderID, device, payload, sig)
+	if !errors.Is(err, ErrInvalidSelfSigningKey) {
+		t.Fatalf("expected invalid self-signing key error, got %v", err)
+	}
+}
+
+func TestVerifyInboundDeviceAcceptsLegacyIdentitySignedCert(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	payload := []byte("payload")
+	device, sig, err := sender.ActiveDeviceAuth(payload)
+	if err != nil {
+		t.Fatalf("active device auth: %v", err)
+	}
+	senderID := sender.GetIdentity().ID
+	_, senderPriv := sender.SnapshotIdentityKeys()
+	device.SelfSigningKey = nil
+	device.SelfSigningSig = nil
+	device.CertSig = ed25519.Sign(senderPriv, deviceCertBytes(senderID, device.ID, device.PublicKey))
+	if err := receiver.VerifyInboundDevice(senderID, device, payload, sig); err != nil {
+		t.Fatalf("verify legacy device certificate: %v", err)
+	}
+}
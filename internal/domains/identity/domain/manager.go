@@ -18,14 +18,23 @@ var (
 )
 
 type Manager struct {
-	mu             sync.RWMutex
-	identity       models.Identity
-	selfPriv       ed25519.PrivateKey
-	contacts       map[string]models.Contact
-	devices        map[string]devicePrivate
-	activeDeviceID string
-	revokedDevices map[string]map[string]struct{}
-	seeds          *SeedManager
+	mu              sync.RWMutex
+	identity        models.Identity
+	selfPriv        ed25519.PrivateKey
+	selfSigningPriv ed25519.PrivateKey
+	selfSigningSig  []byte
+	contacts        map[string]models.Contact
+	selfDisplayName string
+	powDifficulty   int
+	capabilities    []string
+	selfUsername    string
+	attestations    []models.Attestation
+	usernameDisplay string
+	devices         map[string]devicePrivate
+	activeDeviceID  string
+	revokedDevices  map[string]map[string]struct{}
+	peerDevices     map[string]map[string]models.Device
+	seeds           *SeedManager
 }
 
 func newIdentityManager() (*Manager, error) {
@@ -46,6 +55,7 @@ func newIdentityManager() (*Manager, error) {
 		contacts:       make(map[string]models.Contact),
 		devices:        make(map[string]devicePrivate),
 		revokedDevices: make(map[string]map[string]struct{}),
+		peerDevices:    make(map[string]map[string]models.Device),
 		seeds:          NewSeedManager(),
 	}
 	if err := m.initPrimaryDevice(); err != nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/internal/platform/contentsafety"
 	"aim-chat/go-backend/pkg/models"
 )
 
@@ -24,19 +25,149 @@ func (m *Manager) AddContact(card models.ContactCard) error {
 			return ErrContactKeyMismatch
 		}
 	}
+	now := time.Now()
 	m.contacts[card.IdentityID] = models.Contact{
-		ID:          card.IdentityID,
-		DisplayName: card.DisplayName,
-		PublicKey:   append([]byte(nil), card.PublicKey...),
-		AddedAt:     time.Now(),
+		ID:              card.IdentityID,
+		DisplayName:     card.DisplayName,
+		PublicKey:       append([]byte(nil), card.PublicKey...),
+		AddedAt:         now,
+		CardName:        card.DisplayName,
+		CardRefreshedAt: now.UTC(),
+		PowDifficulty:   card.PowDifficulty,
+		SafetyFlags:     contentsafety.CheckDisplayName(card.DisplayName),
+		Username:        card.Username,
+		Capabilities:    append([]string(nil), card.Capabilities...),
+		Attestations:    cloneAttestations(card.Attestations),
 	}
+	m.refreshUsernameConflictsLocked()
 	return nil
 }
 
+// ApplyContactCardRefresh records a re-fetched card for a verified contact.
+// The pinned key must not change. The local display name follows the card only
+// while the user has not renamed the contact. It reports whether the advertised
+// name changed.
+func (m *Manager) ApplyContactCardRefresh(card models.ContactCard, now time.Time) (models.Contact, bool, error) {
+	if ok, err := identitypolicy.VerifyContactCard(card); err != nil || !ok {
+		if err != nil {
+			return models.Contact{}, false, err
+		}
+		return models.Contact{}, false, ErrInvalidContactCard
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	contact, ok := m.contacts[card.IdentityID]
+	if !ok || len(contact.PublicKey) != ed25519.PublicKeySize {
+		return models.Contact{}, false, ErrUnverifiedContact
+	}
+	if !bytes.Equal(contact.PublicKey, card.PublicKey) {
+		return models.Contact{}, false, ErrContactKeyMismatch
+	}
+	changed := false
+	if name := strings.TrimSpace(card.DisplayName); name != "" && name != card.IdentityID && name != contact.CardName {
+		if contact.Alias == "" && (contact.DisplayName == "" || contact.DisplayName == contact.ID || contact.DisplayName == contact.CardName) {
+			contact.DisplayName = name
+		}
+		contact.CardName = name
+		contact.SafetyFlags = contentsafety.CheckDisplayName(name)
+		changed = true
+	}
+	contact.PowDifficulty = card.PowDifficulty
+	contact.Username = card.Username
+	contact.Capabilities = append([]string(nil), card.Capabilities...)
+	contact = withCardAttestations(contact, card.Attestations)
+	contact.CardRefreshedAt = now.UTC()
+	contact.CardStale = false
+	m.contacts[card.IdentityID] = contact
+	m.refreshUsernameConflictsLocked()
+	return m.contacts[card.IdentityID], changed, nil
+}
+
+// UpdateContactMeta replaces the local alias, notes and color of a contact.
+// Clearing the alias puts the name from the contact's card back.
+func (m *Manager) UpdateContactMeta(contactID, alias, notes, color string) (models.Contact, error) {
+	contactID = strings.TrimSpace(contactID)
+	alias, notes, color, err := identitypolicy.NormalizeContactMeta(alias, notes, color)
+	if err != nil {
+		return models.Contact{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	contact, ok := m.contacts[contactID]
+	if !ok {
+		return models.Contact{}, ErrInvalidContactID
+	}
+	switch {
+	case alias != "":
+		contact.DisplayName = alias
+	case contact.Alias != "" && contact.CardName != "":
+		contact.DisplayName = contact.CardName
+	case contact.Alias != "":
+		contact.DisplayName = contact.ID
+	}
+	contact.Alias = alias
+	contact.Notes = notes
+	contact.Color = color
+	m.contacts[contactID] = contact
+	contact.DisplayLabel = identitypolicy.ContactDisplayLabel(contact, m.usernameDisplay)
+	return contact, nil
+}
+
+// MarkStaleContactCards flags verified contacts whose card has not been
+// refreshed since cutoff and returns the ids that became stale.
+func (m *Manager) MarkStaleContactCards(cutoff time.Time) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var marked []string
+	for id, contact := range m.contacts {
+		if contact.CardStale || len(contact.PublicKey) != ed25519.PublicKeySize {
+			continue
+		}
+		if contactCardFreshness(contact).After(cutoff) {
+			continue
+		}
+		contact.CardStale = true
+		m.contacts[id] = contact
+		marked = append(marked, id)
+	}
+	return marked
+}
+
+// contactCardFreshness falls back to AddedAt for contacts stored before card
+// refresh tracking existed.
+func contactCardFreshness(contact models.Contact) time.Time {
+	if !contact.CardRefreshedAt.IsZero() {
+		return contact.CardRefreshedAt
+	}
+	return contact.AddedAt
+}
+
+// SetSelfDisplayName remembers the name this identity advertises in cards sent
+// in reply to card refresh requests.
+func (m *Manager) SetSelfDisplayName(displayName string) bool {
+	displayName = strings.TrimSpace(displayName)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if displayName == "" || displayName == m.selfDisplayName {
+		return false
+	}
+	m.selfDisplayName = displayName
+	return true
+}
+
+func (m *Manager) SelfDisplayName() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.selfDisplayName == "" {
+		return m.identity.ID
+	}
+	return m.selfDisplayName
+}
+
 func (m *Manager) AddContactByIdentityID(contactID, displayName string) error {
 	contactID = strings.TrimSpace(contactID)
 	displayName = strings.TrimSpace(displayName)
-	if !strings.HasPrefix(contactID, "aim1") || len(contactID) < 12 {
+	if identitypolicy.ValidateIdentityID(contactID) != nil {
 		return ErrInvalidContactID
 	}
 	if displayName == "" {
@@ -69,6 +200,8 @@ func (m *Manager) RemoveContact(contactID string) error {
 		return ErrInvalidContactID
 	}
 	delete(m.contacts, contactID)
+	delete(m.peerDevices, contactID)
+	m.refreshUsernameConflictsLocked()
 	return nil
 }
 
@@ -81,6 +214,7 @@ func (m *Manager) Contacts() []models.Contact {
 	defer m.mu.RUnlock()
 	out := make([]models.Contact, 0, len(m.contacts))
 	for _, c := range m.contacts {
+		c.DisplayLabel = identitypolicy.ContactDisplayLabel(c, m.usernameDisplay)
 		out = append(out, c)
 	}
 	return out
@@ -118,5 +252,29 @@ func (m *Manager) SelfContactCard(displayName string) (models.ContactCard, error
 	defer m.mu.RUnlock()
 	pub := ed25519.PublicKey(append([]byte(nil), m.identity.SigningPublicKey...))
 	priv := ed25519.PrivateKey(append([]byte(nil), m.selfPriv...))
-	return identitypolicy.SignContactCard(m.identity.ID, displayName, pub, priv)
+	return identitypolicy.SignContactCardWithAttestations(m.identity.ID, displayName, m.powDifficulty, m.selfUsername, m.capabilities, m.attestations, pub, priv)
+}
+
+// SetAdvertisedCapabilities sets the wire features advertised in cards signed
+// by this identity.
+func (m *Manager) SetAdvertisedCapabilities(capabilities []string) error {
+	normalized, err := identitypolicy.NormalizeCapabilities(capabilities)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.capabilities = normalized
+	m.mu.Unlock()
+	return nil
+}
+
+// SetAdvertisedPowDifficulty sets the first-contact proof-of-work difficulty
+// advertised in cards signed by this identity.
+func (m *Manager) SetAdvertisedPowDifficulty(bits int) {
+	if bits < 0 {
+		bits = 0
+	}
+	m.mu.Lock()
+	m.powDifficulty = bits
+	m.mu.Unlock()
 }
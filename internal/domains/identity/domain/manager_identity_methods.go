@@ -25,6 +25,7 @@ func (m *Manager) CreateIdentity(seedPassword string) (models.Identity, string,
 	}
 	m.selfPriv = append(ed25519.PrivateKey(nil), keys.SigningPrivateKey...)
 	m.contacts = make(map[string]models.Contact)
+	m.selfDisplayName = ""
 	m.revokedDevices = make(map[string]map[string]struct{})
 	if err := m.initPrimaryDevice(); err != nil {
 		m.mu.Unlock()
@@ -55,6 +56,7 @@ func (m *Manager) ImportIdentity(mnemonic, seedPassword string) (models.Identity
 	}
 	m.selfPriv = append(ed25519.PrivateKey(nil), keys.SigningPrivateKey...)
 	m.contacts = make(map[string]models.Contact)
+	m.selfDisplayName = ""
 	m.revokedDevices = make(map[string]map[string]struct{})
 	if err := m.initPrimaryDevice(); err != nil {
 		m.mu.Unlock()
@@ -114,6 +116,7 @@ func (m *Manager) RestoreIdentityPrivateKey(privateKey []byte) error {
 	}
 	m.selfPriv = append(ed25519.PrivateKey(nil), priv...)
 	m.contacts = make(map[string]models.Contact)
+	m.selfDisplayName = ""
 	m.revokedDevices = make(map[string]map[string]struct{})
 	return m.initPrimaryDevice()
 }
@@ -1,6 +1,10 @@
 package domain
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"aim-chat/go-backend/pkg/models"
+)
 
 func (m *Manager) SnapshotSeedEnvelope() *EncryptedSeedEnvelope {
 	return m.seeds.SnapshotEnvelope()
@@ -33,3 +37,7 @@ func (m *Manager) RestoreSeedEnvelopeJSON(raw []byte) error {
 	m.seeds.RestoreEnvelope(&env)
 	return nil
 }
+
+func (m *Manager) PasswordLockoutStatus() models.PasswordLockoutStatus {
+	return m.seeds.LockoutStatus()
+}
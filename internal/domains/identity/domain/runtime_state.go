@@ -3,15 +3,31 @@ package domain
 import (
 	"crypto/ed25519"
 	"encoding/json"
+	"time"
 
 	"aim-chat/go-backend/pkg/models"
 )
 
 type persistedRuntimeState struct {
-	Contacts       []models.Contact    `json:"contacts,omitempty"`
-	Devices        []persistedDevice   `json:"devices,omitempty"`
-	ActiveDeviceID string              `json:"active_device_id,omitempty"`
-	RevokedDevices map[string][]string `json:"revoked_devices,omitempty"`
+	Contacts       []models.Contact           `json:"contacts,omitempty"`
+	Devices        []persistedDevice          `json:"devices,omitempty"`
+	ActiveDeviceID string                     `json:"active_device_id,omitempty"`
+	RevokedDevices map[string][]string        `json:"revoked_devices,omitempty"`
+	PeerDevices    map[string][]models.Device `json:"peer_devices,omitempty"`
+	SelfName       string                     `json:"self_display_name,omitempty"`
+	SelfUsername   string                     `json:"self_username,omitempty"`
+	// SelfAttestations are the attestations carried by this identity's cards.
+	SelfAttestations []models.Attestation `json:"self_attestations,omitempty"`
+	// UsernameDisplay is the local preference for rendering contact names.
+	UsernameDisplay string `json:"username_display,omitempty"`
+	// PasswordAttempts keeps seed password throttling across restarts.
+	PasswordAttempts *persistedPasswordAttempts `json:"password_attempts,omitempty"`
+}
+
+type persistedPasswordAttempts struct {
+	FailedAttempts int       `json:"failed_attempts"`
+	LockedUntil    time.Time `json:"locked_until,omitempty"`
+	LastFailureAt  time.Time `json:"last_failure_at,omitempty"`
 }
 
 type persistedDevice struct {
@@ -24,19 +40,48 @@ func (m *Manager) SnapshotRuntimeStateJSON() []byte {
 	defer m.mu.RUnlock()
 
 	state := persistedRuntimeState{
-		Contacts:       make([]models.Contact, 0, len(m.contacts)),
-		Devices:        make([]persistedDevice, 0, len(m.devices)),
-		ActiveDeviceID: m.activeDeviceID,
-		RevokedDevices: make(map[string][]string, len(m.revokedDevices)),
+		Contacts:         make([]models.Contact, 0, len(m.contacts)),
+		Devices:          make([]persistedDevice, 0, len(m.devices)),
+		ActiveDeviceID:   m.activeDeviceID,
+		RevokedDevices:   make(map[string][]string, len(m.revokedDevices)),
+		PeerDevices:      make(map[string][]models.Device, len(m.peerDevices)),
+		SelfName:         m.selfDisplayName,
+		SelfUsername:     m.selfUsername,
+		SelfAttestations: cloneAttestations(m.attestations),
+		UsernameDisplay:  m.usernameDisplay,
+	}
+	if lockout := m.seeds.LockoutStatus(); lockout.FailedAttempts > 0 {
+		state.PasswordAttempts = &persistedPasswordAttempts{
+			FailedAttempts: lockout.FailedAttempts,
+			LockedUntil:    lockout.LockedUntil,
+			LastFailureAt:  lockout.LastFailureAt,
+		}
 	}
 
 	for _, c := range m.contacts {
 		state.Contacts = append(state.Contacts, models.Contact{
-			ID:          c.ID,
-			DisplayName: c.DisplayName,
-			PublicKey:   append([]byte(nil), c.PublicKey...),
-			AddedAt:     c.AddedAt,
-			LastSeen:    c.LastSeen,
+			ID:               c.ID,
+			DisplayName:      c.DisplayName,
+			PublicKey:        append([]byte(nil), c.PublicKey...),
+			AddedAt:          c.AddedAt,
+			LastSeen:         c.LastSeen,
+			CardName:         c.CardName,
+			CardRefreshedAt:  c.CardRefreshedAt,
+			CardStale:        c.CardStale,
+			PowDifficulty:    c.PowDifficulty,
+			SafetyFlags:      append([]models.SafetyFlag(nil), c.SafetyFlags...),
+			MergedInto:       c.MergedInto,
+			MergedFrom:       append([]string(nil), c.MergedFrom...),
+			Username:         c.Username,
+			UsernameConflict: c.UsernameConflict,
+			Capabilities:     append([]string(nil), c.Capabilities...),
+			Attestations:     cloneAttestations(c.Attestations),
+			Verified:         c.Verified,
+			VerifiedSource:   c.VerifiedSource,
+			VerifiedAt:       c.VerifiedAt,
+			Alias:            c.Alias,
+			Notes:            c.Notes,
+			Color:            c.Color,
 		})
 	}
 
@@ -55,6 +100,14 @@ func (m *Manager) SnapshotRuntimeStateJSON() []byte {
 		state.RevokedDevices[identityID] = ids
 	}
 
+	for contactID, known := range m.peerDevices {
+		devices := make([]models.Device, 0, len(known))
+		for _, device := range known {
+			devices = append(devices, cloneDevice(device))
+		}
+		state.PeerDevices[contactID] = devices
+	}
+
 	raw, err := json.Marshal(state)
 	if err != nil {
 		return nil
@@ -77,13 +130,37 @@ func (m *Manager) RestoreRuntimeStateJSON(raw []byte) error {
 	m.contacts = make(map[string]models.Contact, len(state.Contacts))
 	for _, c := range state.Contacts {
 		m.contacts[c.ID] = models.Contact{
-			ID:          c.ID,
-			DisplayName: c.DisplayName,
-			PublicKey:   append([]byte(nil), c.PublicKey...),
-			AddedAt:     c.AddedAt,
-			LastSeen:    c.LastSeen,
+			ID:               c.ID,
+			DisplayName:      c.DisplayName,
+			PublicKey:        append([]byte(nil), c.PublicKey...),
+			AddedAt:          c.AddedAt,
+			LastSeen:         c.LastSeen,
+			CardName:         c.CardName,
+			CardRefreshedAt:  c.CardRefreshedAt,
+			CardStale:        c.CardStale,
+			PowDifficulty:    c.PowDifficulty,
+			SafetyFlags:      append([]models.SafetyFlag(nil), c.SafetyFlags...),
+			MergedInto:       c.MergedInto,
+			MergedFrom:       append([]string(nil), c.MergedFrom...),
+			Username:         c.Username,
+			UsernameConflict: c.UsernameConflict,
+			Capabilities:     append([]string(nil), c.Capabilities...),
+			Attestations:     cloneAttestations(c.Attestations),
+			Verified:         c.Verified,
+			VerifiedSource:   c.VerifiedSource,
+			VerifiedAt:       c.VerifiedAt,
+			Alias:            c.Alias,
+			Notes:            c.Notes,
+			Color:            c.Color,
 		}
 	}
+	m.selfDisplayName = state.SelfName
+	m.selfUsername = state.SelfUsername
+	m.attestations = cloneAttestations(state.SelfAttestations)
+	m.usernameDisplay = state.UsernameDisplay
+	if attempts := state.PasswordAttempts; attempts != nil {
+		m.seeds.RestoreLockoutState(attempts.FailedAttempts, attempts.LockedUntil, attempts.LastFailureAt)
+	}
 
 	m.devices = make(map[string]devicePrivate, len(state.Devices))
 	for _, d := range state.Devices {
@@ -110,6 +187,24 @@ func (m *Manager) RestoreRuntimeStateJSON(raw []byte) error {
 		}
 	}
 
+	m.peerDevices = make(map[string]map[string]models.Device, len(state.PeerDevices))
+	for contactID, devices := range state.PeerDevices {
+		known := make(map[string]models.Device, len(devices))
+		for _, device := range devices {
+			if device.ID == "" {
+				continue
+			}
+			known[device.ID] = cloneDevice(device)
+		}
+		if len(known) > 0 {
+			m.peerDevices[contactID] = known
+		}
+	}
+
+	if err := m.recertifyDevicesLocked(); err != nil {
+		return err
+	}
+
 	if _, ok := m.devices[state.ActiveDeviceID]; ok {
 		m.activeDeviceID = state.ActiveDeviceID
 	} else if _, ok := m.devices[m.activeDeviceID]; !ok {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
 
 	"github.com/tyler-smith/go-bip39"
 )
@@ -23,11 +24,20 @@ var (
 	ErrPasswordLocked   = errors.New("password attempts are temporarily locked")
 )
 
+const (
+	// passwordLockoutThreshold is the number of consecutive failures after
+	// which the short retry delay turns into a lockout.
+	passwordLockoutThreshold = 5
+	passwordLockoutBase      = 5 * time.Minute
+	passwordLockoutMax       = time.Hour
+)
+
 type SeedManager struct {
 	mu             sync.RWMutex
 	envelope       *EncryptedSeedEnvelope
 	failedAttempts int
 	lockedUntil    time.Time
+	lastFailureAt  time.Time
 	now            func() time.Time
 }
 
@@ -176,6 +186,37 @@ func (s *SeedManager) SnapshotEnvelope() *EncryptedSeedEnvelope {
 	return cloneEnvelope(s.envelope)
 }
 
+// LockoutStatus reports the password attempt state so clients can explain a
+// wait instead of retrying blindly.
+func (s *SeedManager) LockoutStatus() models.PasswordLockoutStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	status := models.PasswordLockoutStatus{
+		FailedAttempts: s.failedAttempts,
+		LastFailureAt:  s.lastFailureAt,
+	}
+	if remaining := s.lockedUntil.Sub(s.now()); remaining > 0 {
+		status.Locked = true
+		status.LockedUntil = s.lockedUntil
+		status.RetryAfterSeconds = int64((remaining + time.Second - 1) / time.Second)
+		status.Lockout = s.failedAttempts >= passwordLockoutThreshold
+	}
+	return status
+}
+
+// RestoreLockoutState brings back attempt counters persisted before a
+// restart, so restarting the daemon does not reset a lockout.
+func (s *SeedManager) RestoreLockoutState(failedAttempts int, lockedUntil, lastFailureAt time.Time) {
+	if failedAttempts < 0 {
+		failedAttempts = 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedAttempts = failedAttempts
+	s.lockedUntil = lockedUntil
+	s.lastFailureAt = lastFailureAt
+}
+
 func (s *SeedManager) RestoreEnvelope(env *EncryptedSeedEnvelope) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -195,25 +236,35 @@ func (s *SeedManager) ensureUnlocked() error {
 
 func (s *SeedManager) onFailedPasswordAttempt() {
 	s.failedAttempts++
-	backoff := failedAttemptBackoff(s.failedAttempts)
-	s.lockedUntil = s.now().Add(backoff)
+	now := s.now()
+	s.lastFailureAt = now
+	s.lockedUntil = now.Add(failedAttemptBackoff(s.failedAttempts))
 }
 
 func (s *SeedManager) resetPasswordAttemptState() {
 	s.failedAttempts = 0
 	s.lockedUntil = time.Time{}
+	s.lastFailureAt = time.Time{}
 }
 
 func failedAttemptBackoff(attempt int) time.Duration {
 	if attempt <= 0 {
 		return 0
 	}
-	// 1s, 2s, 4s... up to 32s max.
-	shift := attempt - 1
-	if shift > 5 {
-		shift = 5
+	if attempt < passwordLockoutThreshold {
+		// 1s, 2s, 4s, 8s before the lockout starts.
+		return time.Second * time.Duration(1<<(attempt-1))
+	}
+	// 5m, 10m, 20m, 40m, then an hour per failure.
+	shift := attempt - passwordLockoutThreshold
+	if shift > 4 {
+		return passwordLockoutMax
+	}
+	lockout := passwordLockoutBase * time.Duration(1<<shift)
+	if lockout > passwordLockoutMax {
+		lockout = passwordLockoutMax
 	}
-	return time.Second * time.Duration(1<<shift)
+	return lockout
 }
 
 func FromKeys(keys *DerivedKeys) (id string, publicKey ed25519.PublicKey, err error) {
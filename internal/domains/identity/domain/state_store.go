@@ -92,6 +92,11 @@ func (s *StateStore) Persist(identityManager contracts.IdentityDomain) error {
 	if err != nil {
 		return err
 	}
+	release, err := securestore.BeginWrite(s.secret)
+	if err != nil {
+		return err
+	}
+	defer release()
 	encrypted, err := securestore.Encrypt(s.secret, payload)
 	if err != nil {
 		return err
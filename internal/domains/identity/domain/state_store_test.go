@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestStateStorePersistAndBootstrapRestoresSeedEnvelope(t *testing.T) {
@@ -104,3 +106,61 @@ func TestStateStorePersistAndBootstrapRestoresRuntimeState(t *testing.T) {
 		t.Fatalf("expected restored added device id=%q", addedDevice.ID)
 	}
 }
+
+func TestPasswordLockoutSurvivesRestart(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "identity.state")
+	secret := "test-secret"
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	manager, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	manager.seeds.now = clock
+	if _, _, err := manager.CreateIdentity("pass-1"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	for i := 1; i <= passwordLockoutThreshold; i++ {
+		if _, err := manager.ExportSeed("wrong"); !errors.Is(err, ErrInvalidPassword) {
+			t.Fatalf("attempt %d: expected ErrInvalidPassword, got %v", i, err)
+		}
+		if i < passwordLockoutThreshold {
+			if _, err := manager.ExportSeed("pass-1"); !errors.Is(err, ErrPasswordLocked) {
+				t.Fatalf("attempt %d: expected the retry delay to apply, got %v", i, err)
+			}
+			now = now.Add(failedAttemptBackoff(i))
+		}
+	}
+	status := manager.PasswordLockoutStatus()
+	if !status.Locked || !status.Lockout || status.RetryAfterSeconds != int64(passwordLockoutBase/time.Second) {
+		t.Fatalf("expected a lockout after %d failures, got %+v", passwordLockoutThreshold, status)
+	}
+
+	store := NewStateStore()
+	store.Configure(storePath, secret)
+	if err := store.Persist(manager); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	reloaded, err := NewManager()
+	if err != nil {
+		t.Fatalf("new reloaded manager: %v", err)
+	}
+	reloaded.seeds.now = clock
+	reloadStore := NewStateStore()
+	reloadStore.Configure(storePath, secret)
+	if err := reloadStore.Bootstrap(reloaded); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if _, err := reloaded.ExportSeed("pass-1"); !errors.Is(err, ErrPasswordLocked) {
+		t.Fatalf("expected the lockout to survive a restart, got %v", err)
+	}
+
+	now = now.Add(passwordLockoutBase)
+	if _, err := reloaded.ExportSeed("pass-1"); err != nil {
+		t.Fatalf("export after lockout expired: %v", err)
+	}
+	if status := reloaded.PasswordLockoutStatus(); status.FailedAttempts != 0 || status.Locked {
+		t.Fatalf("expected a successful attempt to reset the counters, got %+v", status)
+	}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"sort"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// SetSelfUsername sets the username claimed in cards signed by this identity.
+// An empty username withdraws the claim. It reports whether the claim
+// changed.
+func (m *Manager) SetSelfUsername(username string) (string, bool, error) {
+	if username != "" {
+		normalized, err := identitypolicy.NormalizeUsername(username)
+		if err != nil {
+			return "", false, err
+		}
+		username = normalized
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if username == m.selfUsername {
+		return username, false, nil
+	}
+	m.selfUsername = username
+	m.refreshUsernameConflictsLocked()
+	return username, true, nil
+}
+
+func (m *Manager) SelfUsername() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.selfUsername
+}
+
+// SetUsernameDisplay sets how contact listings render names and reports
+// whether the preference changed.
+func (m *Manager) SetUsernameDisplay(mode string) (bool, error) {
+	mode, err := identitypolicy.NormalizeUsernameDisplay(mode)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mode == m.usernameDisplay || (m.usernameDisplay == "" && mode == models.UsernameDisplayName) {
+		return false, nil
+	}
+	m.usernameDisplay = mode
+	return true, nil
+}
+
+func (m *Manager) UsernameDisplay() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.usernameDisplay == "" {
+		return models.UsernameDisplayName
+	}
+	return m.usernameDisplay
+}
+
+// ApplyUsernameClaim records the username claimed by a verified contact's
+// signed card. The card must carry the pinned key, which is what proves the
+// claim came from the contact. It returns the contact and the ids of every
+// contact newly flagged as clashing by this claim.
+func (m *Manager) ApplyUsernameClaim(card models.ContactCard, now time.Time) (models.Contact, []string, error) {
+	if ok, err := identitypolicy.VerifyContactCard(card); err != nil || !ok {
+		if err != nil {
+			return models.Contact{}, nil, err
+		}
+		return models.Contact{}, nil, ErrInvalidContactCard
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	contact, ok := m.contacts[card.IdentityID]
+	if !ok || len(contact.PublicKey) != ed25519.PublicKeySize {
+		return models.Contact{}, nil, ErrUnverifiedContact
+	}
+	if !bytes.Equal(contact.PublicKey, card.PublicKey) {
+		return models.Contact{}, nil, ErrContactKeyMismatch
+	}
+	contact.Username = card.Username
+	contact.CardRefreshedAt = now.UTC()
+	contact.CardStale = false
+	m.contacts[card.IdentityID] = contact
+	flagged := m.refreshUsernameConflictsLocked()
+	return m.contacts[card.IdentityID], flagged, nil
+}
+
+// refreshUsernameConflictsLocked flags every contact sharing a username with
+// another contact, or with this identity's own claim, and clears the flag on
+// the rest. Uniqueness is best effort: there is no directory, so clashes can
+// only be noticed among the contacts one has. Merged-away contacts are
+// ignored since they are the same person under an old id. It returns the ids
+// that were not flagged before, sorted.
+func (m *Manager) refreshUsernameConflictsLocked() []string {
+	claims := make(map[string]int, len(m.contacts))
+	for _, contact := range m.contacts {
+		if contact.Username != "" && contact.MergedInto == "" {
+			claims[contact.Username]++
+		}
+	}
+	var flagged []string
+	for id, contact := range m.contacts {
+		conflict := contact.Username != "" && contact.MergedInto == "" &&
+			(claims[contact.Username] > 1 || contact.Username == m.selfUsername)
+		if conflict == contact.UsernameConflict {
+			continue
+		}
+		if conflict {
+			flagged = append(flagged, id)
+		}
+		contact.UsernameConflict = conflict
+		m.contacts[id] = contact
+	}
+	sort.Strings(flagged)
+	return flagged
+}
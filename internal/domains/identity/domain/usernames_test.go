@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestUsernameClaimsFlagBothClashingContacts(t *testing.T) {
+	alice, receiver := newPairedManagers(t)
+	bob, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if _, _, err := bob.CreateIdentity("pass-3"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	bobCard, err := bob.SelfContactCard("bob")
+	if err != nil {
+		t.Fatalf("bob self card: %v", err)
+	}
+	if err := receiver.AddContact(bobCard); err != nil {
+		t.Fatalf("add bob: %v", err)
+	}
+	now := time.Now()
+
+	if _, _, err := alice.SetSelfUsername("@Alice"); err != nil {
+		t.Fatalf("alice claim: %v", err)
+	}
+	card, err := alice.SelfContactCard("alice")
+	if err != nil || card.Username != "alice" {
+		t.Fatalf("alice card: %+v err=%v", card, err)
+	}
+	contact, flagged, err := receiver.ApplyUsernameClaim(card, now)
+	if err != nil || contact.Username != "alice" || contact.UsernameConflict || len(flagged) != 0 {
+		t.Fatalf("first claim: %+v flagged=%v err=%v", contact, flagged, err)
+	}
+
+	if _, _, err := bob.SetSelfUsername("alice"); err != nil {
+		t.Fatalf("bob claim: %v", err)
+	}
+	card, err = bob.SelfContactCard("bob")
+	if err != nil {
+		t.Fatalf("bob card: %v", err)
+	}
+	_, flagged, err = receiver.ApplyUsernameClaim(card, now)
+	if err != nil || len(flagged) != 2 {
+		t.Fatalf("clashing claim must flag both contacts: flagged=%v err=%v", flagged, err)
+	}
+	for _, c := range receiver.Contacts() {
+		if !c.UsernameConflict {
+			t.Fatalf("contact %s not flagged", c.ID)
+		}
+	}
+
+	if _, _, err := bob.SetSelfUsername("bob"); err != nil {
+		t.Fatalf("bob rename: %v", err)
+	}
+	card, _ = bob.SelfContactCard("bob")
+	if _, _, err := receiver.ApplyUsernameClaim(card, now); err != nil {
+		t.Fatalf("bob new claim: %v", err)
+	}
+	for _, c := range receiver.Contacts() {
+		if c.UsernameConflict {
+			t.Fatalf("conflict must clear once the claims differ: %+v", c)
+		}
+	}
+}
+
+func TestUsernameClaimRequiresPinnedKey(t *testing.T) {
+	_, receiver := newPairedManagers(t)
+	stranger, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if _, _, err := stranger.CreateIdentity("pass-3"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	if _, _, err := stranger.SetSelfUsername("sender"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	card, _ := stranger.SelfContactCard("stranger")
+	if _, _, err := receiver.ApplyUsernameClaim(card, time.Now()); !errors.Is(err, ErrUnverifiedContact) {
+		t.Fatalf("expected ErrUnverifiedContact, got %v", err)
+	}
+
+	card.Username = "someone.else"
+	if ok, _ := identitypolicy.VerifyContactCard(card); ok {
+		t.Fatal("username must be covered by the card signature")
+	}
+}
+
+func TestUsernameStateSurvivesRestore(t *testing.T) {
+	sender, receiver := newPairedManagers(t)
+	if _, _, err := sender.SetSelfUsername("sender_1"); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	card, _ := sender.SelfContactCard("sender")
+	if _, _, err := receiver.ApplyUsernameClaim(card, time.Now()); err != nil {
+		t.Fatalf("apply claim: %v", err)
+	}
+	if _, err := receiver.SetUsernameDisplay(models.UsernameDisplayBoth); err != nil {
+		t.Fatalf("set display: %v", err)
+	}
+
+	restored, err := NewManager()
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := restored.RestoreRuntimeStateJSON(receiver.SnapshotRuntimeStateJSON()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.UsernameDisplay() != models.UsernameDisplayBoth {
+		t.Fatalf("display preference lost: %q", restored.UsernameDisplay())
+	}
+	contacts := restored.Contacts()
+	if len(contacts) != 1 || contacts[0].Username != "sender_1" || contacts[0].DisplayLabel != "sender (@sender_1)" {
+		t.Fatalf("unexpected restored contacts: %+v", contacts)
+	}
+	if _, err := restored.SetUsernameDisplay("nickname"); !errors.Is(err, identitypolicy.ErrInvalidUsernameDisplay) {
+		t.Fatalf("expected ErrInvalidUsernameDisplay, got %v", err)
+	}
+}
@@ -5,12 +5,51 @@ import (
 	"log/slog"
 
 	"aim-chat/go-backend/internal/domains/contracts"
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	identityports "aim-chat/go-backend/internal/domains/identity/ports"
 	identityusecase "aim-chat/go-backend/internal/domains/identity/usecase"
 )
 
 type Service = identityusecase.Service
 type BackupExportResult = identityusecase.BackupExportResult
 type BackupRestoreResult = identityusecase.BackupRestoreResult
+type AttachmentMimePolicy = identitypolicy.AttachmentMimePolicy
+type AttestationSources = identityports.AttestationSources
+
+var ErrAttachmentTypeBlocked = identitypolicy.ErrAttachmentTypeBlocked
+
+const (
+	CapabilityReceiptsChannel = identitypolicy.CapabilityReceiptsChannel
+	CapabilityHistoryBackfill = identitypolicy.CapabilityHistoryBackfill
+	CapabilityPairTopics      = identitypolicy.CapabilityPairTopics
+)
+
+func NormalizeCapabilities(capabilities []string) ([]string, error) {
+	return identitypolicy.NormalizeCapabilities(capabilities)
+}
+
+// DomainAttestationURL is where a domain attestation is looked up.
+func DomainAttestationURL(domain string) string {
+	return identitypolicy.DomainAttestationURL(domain)
+}
+
+// VerifyIdentityID reports whether identityID belongs to signingPublicKey.
+func VerifyIdentityID(identityID string, signingPublicKey []byte) (bool, error) {
+	return identitypolicy.VerifyIdentityID(identityID, signingPublicKey)
+}
+
+// ValidateIdentityID checks the format of an identity id of any version.
+func ValidateIdentityID(identityID string) error {
+	return identitypolicy.ValidateIdentityID(identityID)
+}
+
+func NormalizeAttachmentDownloadChunkSize(chunkSize int) (int, error) {
+	return identitypolicy.NormalizeAttachmentDownloadChunkSize(chunkSize)
+}
+
+func DefaultAttachmentMimePolicy() AttachmentMimePolicy {
+	return identitypolicy.DefaultAttachmentMimePolicy()
+}
 
 type Module struct {
 	Service *Service
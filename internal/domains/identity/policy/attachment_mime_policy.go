@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+var ErrAttachmentTypeBlocked = errors.New("attachment type is not allowed")
+
+const genericBinaryMime = "application/octet-stream"
+
+// AttachmentMimePolicy restricts uploads by MIME type and file extension.
+// Deny entries always win; a non-empty allow list rejects anything unlisted.
+// MIME entries may use a "type/*" wildcard and extensions omit the dot.
+type AttachmentMimePolicy struct {
+	AllowedMimes      []string
+	DeniedMimes       []string
+	AllowedExtensions []string
+	DeniedExtensions  []string
+}
+
+// AttachmentMimeInspection is the outcome of sniffing an upload.
+type AttachmentMimeInspection struct {
+	SniffedMime string
+	Warnings    []string
+}
+
+func DefaultAttachmentMimePolicy() AttachmentMimePolicy {
+	return AttachmentMimePolicy{
+		DeniedMimes: []string{
+			"application/x-msdownload",
+			"application/x-dosexec",
+			"application/x-msdos-program",
+			"application/vnd.microsoft.portable-executable",
+			"application/x-executable",
+			"application/x-mach-binary",
+			"application/x-sharedlib",
+			"application/java-archive",
+			"application/vnd.android.package-archive",
+		},
+		DeniedExtensions: []string{
+			"exe", "dll", "msi", "bat", "cmd", "com", "scr", "pif", "cpl",
+			"ps1", "vbs", "vbe", "jse", "wsf", "hta", "jar", "apk",
+		},
+	}
+}
+
+// SniffAttachmentMime detects the payload type from its leading bytes. On top
+// of http.DetectContentType it recognizes native executables, which the
+// standard sniffer reports as generic binary.
+func SniffAttachmentMime(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("MZ")):
+		return "application/x-dosexec"
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return "application/x-executable"
+	case bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xce}):
+		return "application/x-mach-binary"
+	}
+	return baseMime(http.DetectContentType(data))
+}
+
+// CheckAttachmentDeclaration validates the name and declared MIME type before
+// any payload bytes are available.
+func CheckAttachmentDeclaration(policy AttachmentMimePolicy, name, declaredMime string) error {
+	ext := attachmentExtension(name)
+	if ext != "" && containsFold(policy.DeniedExtensions, ext) {
+		return fmt.Errorf("%w: extension .%s", ErrAttachmentTypeBlocked, ext)
+	}
+	if len(policy.AllowedExtensions) > 0 && !containsFold(policy.AllowedExtensions, ext) {
+		return fmt.Errorf("%w: extension .%s", ErrAttachmentTypeBlocked, ext)
+	}
+	declared := baseMime(declaredMime)
+	if declared != "" && mimeMatchesAny(policy.DeniedMimes, declared) {
+		return fmt.Errorf("%w: %s", ErrAttachmentTypeBlocked, declared)
+	}
+	if declared != "" && len(policy.AllowedMimes) > 0 && !mimeMatchesAny(policy.AllowedMimes, declared) {
+		return fmt.Errorf("%w: %s", ErrAttachmentTypeBlocked, declared)
+	}
+	return nil
+}
+
+// InspectAttachmentPayload enforces the policy against the sniffed content
+// type and reports mismatches between the declared type, the extension and
+// the detected content.
+func InspectAttachmentPayload(policy AttachmentMimePolicy, name, declaredMime string, data []byte) (AttachmentMimeInspection, error) {
+	if err := CheckAttachmentDeclaration(policy, name, declaredMime); err != nil {
+		return AttachmentMimeInspection{}, err
+	}
+	sniffed := SniffAttachmentMime(data)
+	if mimeMatchesAny(policy.DeniedMimes, sniffed) {
+		return AttachmentMimeInspection{}, fmt.Errorf("%w: detected %s", ErrAttachmentTypeBlocked, sniffed)
+	}
+	if len(policy.AllowedMimes) > 0 && !isGenericSniffedMime(sniffed) && !mimeMatchesAny(policy.AllowedMimes, sniffed) {
+		return AttachmentMimeInspection{}, fmt.Errorf("%w: detected %s", ErrAttachmentTypeBlocked, sniffed)
+	}
+
+	inspection := AttachmentMimeInspection{SniffedMime: sniffed}
+	if isGenericSniffedMime(sniffed) {
+		return inspection, nil
+	}
+	declared := baseMime(declaredMime)
+	if declared != "" && declared != sniffed {
+		inspection.Warnings = append(inspection.Warnings, fmt.Sprintf("declared type %s does not match detected %s", declared, sniffed))
+	}
+	if ext := attachmentExtension(name); ext != "" {
+		if byExt := baseMime(mime.TypeByExtension("." + ext)); byExt != "" && byExt != sniffed {
+			inspection.Warnings = append(inspection.Warnings, fmt.Sprintf("extension .%s does not match detected %s", ext, sniffed))
+		}
+	}
+	return inspection, nil
+}
+
+// isGenericSniffedMime reports sniffer fallbacks that carry no evidence about
+// the real format and therefore should not raise mismatch warnings.
+func isGenericSniffedMime(mimeType string) bool {
+	return mimeType == genericBinaryMime || mimeType == "text/plain"
+}
+
+func baseMime(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if idx := strings.IndexByte(mimeType, ';'); idx >= 0 {
+		mimeType = strings.TrimSpace(mimeType[:idx])
+	}
+	return mimeType
+}
+
+func attachmentExtension(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimSpace(name)), "."))
+}
+
+func mimeMatchesAny(patterns []string, mimeType string) bool {
+	for _, pattern := range patterns {
+		pattern = baseMime(pattern)
+		if pattern == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mimeType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if pattern == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(value), "."), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInspectAttachmentPayload_BlocksExecutablesByDefault(t *testing.T) {
+	policy := DefaultAttachmentMimePolicy()
+	if err := CheckAttachmentDeclaration(policy, "setup.EXE", "application/octet-stream"); !errors.Is(err, ErrAttachmentTypeBlocked) {
+		t.Fatalf("expected blocked extension, got %v", err)
+	}
+	// A renamed PE binary must be caught by content sniffing.
+	payload := append([]byte("MZ"), make([]byte, 64)...)
+	if _, err := InspectAttachmentPayload(policy, "report.pdf", "application/pdf", payload); !errors.Is(err, ErrAttachmentTypeBlocked) {
+		t.Fatalf("expected sniffed executable to be blocked, got %v", err)
+	}
+}
+
+func TestInspectAttachmentPayload_RecordsMismatchWarnings(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000000000000000")
+	inspection, err := InspectAttachmentPayload(DefaultAttachmentMimePolicy(), "notes.pdf", "application/pdf", png)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inspection.SniffedMime != "image/png" {
+		t.Fatalf("unexpected sniffed mime: %q", inspection.SniffedMime)
+	}
+	if len(inspection.Warnings) != 2 {
+		t.Fatalf("expected declared and extension mismatch warnings, got %v", inspection.Warnings)
+	}
+
+	plain, err := InspectAttachmentPayload(DefaultAttachmentMimePolicy(), "data.csv", "text/csv", []byte("a,b\n1,2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plain.Warnings) != 0 {
+		t.Fatalf("generic sniff must not raise warnings, got %v", plain.Warnings)
+	}
+}
+
+func TestInspectAttachmentPayload_AllowList(t *testing.T) {
+	policy := AttachmentMimePolicy{AllowedMimes: []string{"image/*"}, AllowedExtensions: []string{"png", "jpg"}}
+	png := []byte("\x89PNG\r\n\x1a\n0000000000000000")
+	if _, err := InspectAttachmentPayload(policy, "photo.png", "image/png", png); err != nil {
+		t.Fatalf("expected allowed image, got %v", err)
+	}
+	if err := CheckAttachmentDeclaration(policy, "doc.pdf", "application/pdf"); !errors.Is(err, ErrAttachmentTypeBlocked) {
+		t.Fatalf("expected unlisted extension to be blocked, got %v", err)
+	}
+	if _, err := InspectAttachmentPayload(policy, "fake.png", "image/png", []byte("%PDF-1.7\n")); !errors.Is(err, ErrAttachmentTypeBlocked) {
+		t.Fatalf("expected unlisted sniffed type to be blocked, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	// DomainAttestationPath is where a domain lists the identities it vouches
+	// for. It is only ever fetched over HTTPS.
+	DomainAttestationPath = "/.well-known/aim-chat-identities.json"
+
+	MaxAttestations          = 8
+	maxAttestationSubjectLen = 253
+)
+
+var ErrInvalidAttestation = errors.New("invalid attestation")
+
+// DomainAttestationDocument is the file served at DomainAttestationPath.
+type DomainAttestationDocument struct {
+	Identities []string `json:"identities"`
+}
+
+// NormalizeAttestations validates attestations, drops duplicates and sorts
+// them so the signed form of a card does not depend on their order.
+func NormalizeAttestations(attestations []models.Attestation) ([]models.Attestation, error) {
+	if len(attestations) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(attestations))
+	out := make([]models.Attestation, 0, len(attestations))
+	for _, att := range attestations {
+		att.Kind = strings.ToLower(strings.TrimSpace(att.Kind))
+		att.Subject = strings.TrimSpace(att.Subject)
+		switch att.Kind {
+		case models.AttestationKindDomain:
+			att.Subject = strings.ToLower(att.Subject)
+			if !isAttestationDomain(att.Subject) || len(att.Signature) != 0 {
+				return nil, ErrInvalidAttestation
+			}
+		case models.AttestationKindAuthority:
+			if att.Subject == "" || len(att.Subject) > maxAttestationSubjectLen || len(att.Signature) != ed25519.SignatureSize {
+				return nil, ErrInvalidAttestation
+			}
+		default:
+			return nil, ErrInvalidAttestation
+		}
+		source := AttestationSource(att)
+		if _, dup := seen[source]; dup {
+			continue
+		}
+		seen[source] = struct{}{}
+		att.Signature = append([]byte(nil), att.Signature...)
+		out = append(out, att)
+	}
+	if len(out) > MaxAttestations {
+		return nil, ErrInvalidAttestation
+	}
+	sort.Slice(out, func(i, j int) bool { return AttestationSource(out[i]) < AttestationSource(out[j]) })
+	return out, nil
+}
+
+// AttestationSource names an attestation as "<kind>:<subject>".
+func AttestationSource(att models.Attestation) string {
+	return att.Kind + ":" + att.Subject
+}
+
+// SignAuthorityAttestation is what an authority runs to vouch for an
+// identity. The signature binds the identity id to its signing key, so it
+// cannot be moved to another card.
+func SignAuthorityAttestation(keyID, identityID string, publicKey []byte, authority ed25519.PrivateKey) (models.Attestation, error) {
+	keyID = strings.TrimSpace(keyID)
+	if keyID == "" || len(authority) != ed25519.PrivateKeySize || len(publicKey) != ed25519.PublicKeySize {
+		return models.Attestation{}, ErrInvalidAttestation
+	}
+	return models.Attestation{
+		Kind:      models.AttestationKindAuthority,
+		Subject:   keyID,
+		Signature: ed25519.Sign(authority, authorityAttestationSigningBytes(identityID, publicKey)),
+	}, nil
+}
+
+// VerifyAuthorityAttestation checks an authority attestation against the
+// authority keys trusted by this node.
+func VerifyAuthorityAttestation(att models.Attestation, identityID string, publicKey []byte, authorities map[string]ed25519.PublicKey) bool {
+	if att.Kind != models.AttestationKindAuthority {
+		return false
+	}
+	key, ok := authorities[att.Subject]
+	if !ok || len(key) != ed25519.PublicKeySize || len(att.Signature) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(key, authorityAttestationSigningBytes(identityID, publicKey), att.Signature)
+}
+
+// DomainAttestationURL is the HTTPS address of a domain's attestation file.
+func DomainAttestationURL(domain string) string {
+	return "https://" + domain + DomainAttestationPath
+}
+
+// DomainDocumentListsIdentity reports whether a fetched attestation file
+// vouches for identityID.
+func DomainDocumentListsIdentity(body []byte, identityID string) bool {
+	var doc DomainAttestationDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return false
+	}
+	for _, listed := range doc.Identities {
+		if strings.TrimSpace(listed) == identityID {
+			return true
+		}
+	}
+	return false
+}
+
+func authorityAttestationSigningBytes(identityID string, publicKey []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("aim-attestation:")
+	b.WriteString(identityID)
+	b.WriteByte(0)
+	b.Write(publicKey)
+	return b.Bytes()
+}
+
+func isAttestationDomain(domain string) bool {
+	if len(domain) == 0 || len(domain) > maxAttestationSubjectLen || !strings.Contains(domain, ".") {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attestationSigningField(attestations []models.Attestation) string {
+	parts := make([]string, 0, len(attestations))
+	for _, att := range attestations {
+		part := AttestationSource(att)
+		if len(att.Signature) > 0 {
+			part += ":" + hex.EncodeToString(att.Signature)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
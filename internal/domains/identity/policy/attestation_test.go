@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestAuthorityAttestationBindsIdentityKey(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	authorityPub, authorityPriv, _ := ed25519.GenerateKey(rand.Reader)
+	id, err := BuildIdentityID(pub)
+	if err != nil {
+		t.Fatalf("build id failed: %v", err)
+	}
+	att, err := SignAuthorityAttestation("community-1", id, pub, authorityPriv)
+	if err != nil {
+		t.Fatalf("sign attestation failed: %v", err)
+	}
+	authorities := map[string]ed25519.PublicKey{"community-1": authorityPub}
+	if !VerifyAuthorityAttestation(att, id, pub, authorities) {
+		t.Fatal("attestation must verify against the trusted authority")
+	}
+	if VerifyAuthorityAttestation(att, id, pub, map[string]ed25519.PublicKey{"other": authorityPub}) {
+		t.Fatal("attestation must not verify for an unknown authority")
+	}
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherID, _ := BuildIdentityID(otherPub)
+	if VerifyAuthorityAttestation(att, otherID, otherPub, authorities) {
+		t.Fatal("attestation must not transfer to another identity")
+	}
+
+	card, err := SignContactCardWithAttestations(id, "alice", 0, "", nil, []models.Attestation{
+		att,
+		{Kind: "Domain", Subject: "Example.ORG"},
+	}, pub, priv)
+	if err != nil {
+		t.Fatalf("sign card failed: %v", err)
+	}
+	if len(card.Attestations) != 2 || card.Attestations[1].Subject != "example.org" {
+		t.Fatalf("attestations must be normalized and sorted: %+v", card.Attestations)
+	}
+	if ok, err := VerifyContactCard(card); err != nil || !ok {
+		t.Fatalf("card must verify: ok=%v err=%v", ok, err)
+	}
+	card.Attestations = card.Attestations[:1]
+	if ok, _ := VerifyContactCard(card); ok {
+		t.Fatal("stripping an attestation must break the card signature")
+	}
+}
+
+func TestNormalizeAttestationsRejectsMalformed(t *testing.T) {
+	for _, att := range []models.Attestation{
+		{Kind: models.AttestationKindDomain, Subject: "localhost"},
+		{Kind: models.AttestationKindDomain, Subject: "https://example.org/path"},
+		{Kind: models.AttestationKindDomain, Subject: "example.org", Signature: []byte{1}},
+		{Kind: models.AttestationKindAuthority, Subject: "key", Signature: []byte{1, 2}},
+		{Kind: "email", Subject: "a@example.org"},
+	} {
+		if _, err := NormalizeAttestations([]models.Attestation{att}); err == nil {
+			t.Fatalf("attestation %+v must be rejected", att)
+		}
+	}
+}
+
+func TestDomainDocumentListsIdentity(t *testing.T) {
+	body := []byte(`{"identities":["aim1first","aim1second"]}`)
+	if !DomainDocumentListsIdentity(body, "aim1second") {
+		t.Fatal("listed identity must match")
+	}
+	if DomainDocumentListsIdentity(body, "aim1third") || DomainDocumentListsIdentity([]byte("not json"), "aim1first") {
+		t.Fatal("unlisted identity or malformed file must not match")
+	}
+	if got := DomainAttestationURL("example.org"); got != "https://example.org/.well-known/aim-chat-identities.json" {
+		t.Fatalf("unexpected url %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Capabilities are the optional wire features a client advertises in its
+// cards and wires. Peers pick features from the advertised set instead of
+// probing; names they do not recognize are kept but never selected.
+const (
+	CapabilityReceiptsChannel = "receipts_channel"
+	CapabilityHistoryBackfill = "history_backfill"
+	CapabilityPairTopics      = "pair_topics"
+
+	MaxCapabilities     = 32
+	maxCapabilityLength = 32
+)
+
+var ErrInvalidCapabilities = errors.New("capabilities must be at most 32 names of a-z, 0-9 or '_'")
+
+// NormalizeCapabilities lowercases, sorts and de-duplicates capability names
+// so the signed form of a card does not depend on advertisement order.
+func NormalizeCapabilities(capabilities []string) ([]string, error) {
+	if len(capabilities) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(capabilities))
+	out := make([]string, 0, len(capabilities))
+	for _, name := range capabilities {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || len(name) > maxCapabilityLength {
+			return nil, ErrInvalidCapabilities
+		}
+		for _, r := range name {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+				return nil, ErrInvalidCapabilities
+			}
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	if len(out) > MaxCapabilities {
+		return nil, ErrInvalidCapabilities
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,44 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxContactAliasLen = 64
+	MaxContactNotesLen = 2000
+)
+
+var ErrInvalidContactMeta = errors.New("invalid contact metadata")
+
+// NormalizeContactMeta trims the local metadata a user keeps about a
+// contact. Color is empty or a "#rrggbb" hex value and comes back lowercase.
+func NormalizeContactMeta(alias, notes, color string) (string, string, string, error) {
+	alias = strings.TrimSpace(alias)
+	notes = strings.TrimSpace(notes)
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !utf8.ValidString(alias) || !utf8.ValidString(notes) ||
+		utf8.RuneCountInString(alias) > MaxContactAliasLen ||
+		utf8.RuneCountInString(notes) > MaxContactNotesLen ||
+		strings.ContainsAny(alias, "\r\n") {
+		return "", "", "", ErrInvalidContactMeta
+	}
+	if color != "" && !isHexColor(color) {
+		return "", "", "", ErrInvalidContactMeta
+	}
+	return alias, notes, color, nil
+}
+
+func isHexColor(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	for _, r := range color[1:] {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
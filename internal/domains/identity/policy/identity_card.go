@@ -3,11 +3,10 @@ package policy
 import (
 	"crypto/ed25519"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"aim-chat/go-backend/pkg/models"
-
-	"github.com/mr-tron/base58/base58"
-	"golang.org/x/crypto/blake2b"
 )
 
 var (
@@ -15,30 +14,58 @@ var (
 	ErrIdentityMismatch   = fmt.Errorf("identity_id does not match public key")
 )
 
-func BuildIdentityID(signingPublicKey []byte) (string, error) {
-	if len(signingPublicKey) != ed25519.PublicKeySize {
-		return "", fmt.Errorf("invalid signing public key size: %d", len(signingPublicKey))
-	}
-	h := blake2b.Sum256(signingPublicKey)
-	return "aim1" + base58.Encode(h[:]), nil
+func SignContactCard(identityID, displayName string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
+	return SignContactCardWithPow(identityID, displayName, 0, publicKey, privateKey)
 }
 
-func VerifyIdentityID(identityID string, signingPublicKey []byte) (bool, error) {
-	expected, err := BuildIdentityID(signingPublicKey)
-	if err != nil {
-		return false, err
-	}
-	return identityID == expected, nil
+// SignContactCardWithPow signs a card that also advertises the first-contact
+// proof-of-work difficulty required by this identity.
+func SignContactCardWithPow(identityID, displayName string, powDifficulty int, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
+	return SignContactCardWithUsername(identityID, displayName, powDifficulty, "", publicKey, privateKey)
 }
 
-func SignContactCard(identityID, displayName string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
-	if privateKey == nil || publicKey == nil {
+// SignContactCardWithUsername signs a card that additionally claims username.
+// The private key signing the card is the proof of possession: a claim can
+// only be made, or re-made, by the identity it names.
+func SignContactCardWithUsername(identityID, displayName string, powDifficulty int, username string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
+	return SignContactCardWithCapabilities(identityID, displayName, powDifficulty, username, nil, publicKey, privateKey)
+}
+
+// SignContactCardWithCapabilities signs a card that also advertises the wire
+// features supported by this identity's client.
+func SignContactCardWithCapabilities(identityID, displayName string, powDifficulty int, username string, capabilities []string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
+	return SignContactCardWithAttestations(identityID, displayName, powDifficulty, username, capabilities, nil, publicKey, privateKey)
+}
+
+// SignContactCardWithAttestations signs a card that also carries third-party
+// attestations. Signing them keeps relays from stripping or adding any.
+func SignContactCardWithAttestations(identityID, displayName string, powDifficulty int, username string, capabilities []string, attestations []models.Attestation, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (models.ContactCard, error) {
+	if privateKey == nil || publicKey == nil || powDifficulty < 0 {
 		return models.ContactCard{}, ErrInvalidContactCard
 	}
+	if username != "" {
+		normalized, err := NormalizeUsername(username)
+		if err != nil {
+			return models.ContactCard{}, err
+		}
+		username = normalized
+	}
+	capabilities, err := NormalizeCapabilities(capabilities)
+	if err != nil {
+		return models.ContactCard{}, err
+	}
+	attestations, err = NormalizeAttestations(attestations)
+	if err != nil {
+		return models.ContactCard{}, err
+	}
 	card := models.ContactCard{
-		IdentityID:  identityID,
-		DisplayName: displayName,
-		PublicKey:   append([]byte(nil), publicKey...),
+		IdentityID:    identityID,
+		DisplayName:   displayName,
+		PublicKey:     append([]byte(nil), publicKey...),
+		PowDifficulty: powDifficulty,
+		Username:      username,
+		Capabilities:  capabilities,
+		Attestations:  attestations,
 	}
 	if ok, err := VerifyIdentityID(identityID, publicKey); err != nil || !ok {
 		if err != nil {
@@ -51,9 +78,24 @@ func SignContactCard(identityID, displayName string, publicKey ed25519.PublicKey
 }
 
 func VerifyContactCard(card models.ContactCard) (bool, error) {
-	if len(card.PublicKey) != ed25519.PublicKeySize || len(card.Signature) != ed25519.SignatureSize {
+	if len(card.PublicKey) != ed25519.PublicKeySize || len(card.Signature) != ed25519.SignatureSize || card.PowDifficulty < 0 {
 		return false, ErrInvalidContactCard
 	}
+	if card.Username != "" {
+		if normalized, err := NormalizeUsername(card.Username); err != nil || normalized != card.Username {
+			return false, ErrInvalidContactCard
+		}
+	}
+	if len(card.Capabilities) > 0 {
+		if normalized, err := NormalizeCapabilities(card.Capabilities); err != nil || strings.Join(normalized, ",") != strings.Join(card.Capabilities, ",") {
+			return false, ErrInvalidContactCard
+		}
+	}
+	if len(card.Attestations) > 0 {
+		if normalized, err := NormalizeAttestations(card.Attestations); err != nil || attestationSigningField(normalized) != attestationSigningField(card.Attestations) {
+			return false, ErrInvalidContactCard
+		}
+	}
 	ok, err := VerifyIdentityID(card.IdentityID, card.PublicKey)
 	if err != nil {
 		return false, err
@@ -71,5 +113,23 @@ func contactCardSigningBytes(card models.ContactCard) []byte {
 	b = append(b, []byte(card.DisplayName)...)
 	b = append(b, 0)
 	b = append(b, card.PublicKey...)
+	// The difficulty is only signed when advertised, so cards issued before
+	// it existed keep verifying.
+	if card.PowDifficulty > 0 {
+		b = append(b, 0)
+		b = append(b, []byte("pow:"+strconv.Itoa(card.PowDifficulty))...)
+	}
+	if card.Username != "" {
+		b = append(b, 0)
+		b = append(b, []byte("username:"+card.Username)...)
+	}
+	if len(card.Capabilities) > 0 {
+		b = append(b, 0)
+		b = append(b, []byte("caps:"+strings.Join(card.Capabilities, ","))...)
+	}
+	if len(card.Attestations) > 0 {
+		b = append(b, 0)
+		b = append(b, []byte("attest:"+attestationSigningField(card.Attestations))...)
+	}
 	return b
 }
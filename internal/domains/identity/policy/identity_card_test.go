@@ -49,3 +49,69 @@ func TestContactCardSignVerify(t *testing.T) {
 		t.Fatal("signed contact card should verify")
 	}
 }
+
+func TestContactCardPowDifficultyIsSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	id, err := BuildIdentityID(pub)
+	if err != nil {
+		t.Fatalf("build id failed: %v", err)
+	}
+	card, err := SignContactCardWithPow(id, "alice", 12, pub, priv)
+	if err != nil {
+		t.Fatalf("sign card failed: %v", err)
+	}
+	if ok, err := VerifyContactCard(card); err != nil || !ok {
+		t.Fatalf("card with difficulty should verify: ok=%v err=%v", ok, err)
+	}
+	card.PowDifficulty = 0
+	if ok, _ := VerifyContactCard(card); ok {
+		t.Fatal("stripping the advertised difficulty must break the signature")
+	}
+}
+
+func TestContactCardCapabilitiesAreSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	id, err := BuildIdentityID(pub)
+	if err != nil {
+		t.Fatalf("build id failed: %v", err)
+	}
+	caps := []string{" Receipts_Channel", "history_backfill", "receipts_channel"}
+	card, err := SignContactCardWithCapabilities(id, "alice", 0, "", caps, pub, priv)
+	if err != nil {
+		t.Fatalf("sign card failed: %v", err)
+	}
+	if got := strings.Join(card.Capabilities, ","); got != "history_backfill,receipts_channel" {
+		t.Fatalf("capabilities must be normalized, got %q", got)
+	}
+	if ok, err := VerifyContactCard(card); err != nil || !ok {
+		t.Fatalf("card with capabilities should verify: ok=%v err=%v", ok, err)
+	}
+	card.Capabilities = card.Capabilities[:1]
+	if ok, _ := VerifyContactCard(card); ok {
+		t.Fatal("dropping a capability must break the signature")
+	}
+	if _, err := SignContactCardWithCapabilities(id, "alice", 0, "", []string{"pq-kem"}, pub, priv); err == nil {
+		t.Fatal("expected an invalid capability name to be rejected")
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	valid := map[string]string{"Alice": "alice", "@bob_99": "bob_99", " c.d.e ": "c.d.e"}
+	for in, want := range valid {
+		got, err := NormalizeUsername(in)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeUsername(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "ab", "1abc", "_abc", "al ice", "аlice", strings.Repeat("a", 33)} {
+		if _, err := NormalizeUsername(in); err == nil {
+			t.Fatalf("NormalizeUsername(%q) must fail", in)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mr-tron/base58/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Identity IDs are "aim" followed by a single version digit and a base58
+// body, e.g. "aim1...". The version pins how the body is derived from the
+// signing public key:
+//
+//   - Version 1 is base58(blake2b-256(ed25519 public key)). It has no
+//     checksum of its own, since VerifyIdentityID recomputes the digest.
+//   - Every later version is base58(digest || checksum), with checksum the
+//     first IdentityIDChecksumSize bytes of blake2b-256(prefix || digest).
+//
+// Because the checksum scheme is fixed for all versions after the first,
+// ValidateIdentityID accepts IDs of versions this build does not know yet,
+// so contacts, blocklists and group rosters keep working while peers roll
+// out a new key type. Only VerifyIdentityID needs the version registered.
+//
+// A new key type (say post-quantum) registers a scheme under the next
+// version. Existing identities keep their version 1 IDs; an identity that
+// moves to the new key publishes an IdentityTransition signed by the old
+// key, so contacts can follow it without re-verifying out of band.
+const (
+	IdentityIDPrefixBase      = "aim"
+	CurrentIdentityIDVersion  = 1
+	MaxIdentityIDVersion      = 9
+	IdentityIDChecksumSize    = 4
+	minLegacyIdentityIDLength = 12
+	minIdentityIDDigestSize   = 16
+)
+
+var (
+	ErrInvalidIdentityID            = errors.New("invalid identity id")
+	ErrUnsupportedIdentityIDVersion = errors.New("unsupported identity id version")
+	ErrIdentityIDVersionRegistered  = errors.New("identity id version is already registered")
+)
+
+// IdentityIDScheme describes the signing key an ID version is derived from.
+type IdentityIDScheme struct {
+	Version int
+	KeyType string
+	KeySize int
+}
+
+var (
+	identityIDSchemesMu sync.RWMutex
+	identityIDSchemes   = map[int]IdentityIDScheme{
+		1: {Version: 1, KeyType: "ed25519", KeySize: ed25519.PublicKeySize},
+	}
+)
+
+// RegisterIdentityIDScheme adds a version so IDs of it can be built and
+// verified. Versions cannot be redefined.
+func RegisterIdentityIDScheme(scheme IdentityIDScheme) error {
+	if scheme.Version < 2 || scheme.Version > MaxIdentityIDVersion || strings.TrimSpace(scheme.KeyType) == "" || scheme.KeySize <= 0 {
+		return ErrUnsupportedIdentityIDVersion
+	}
+	identityIDSchemesMu.Lock()
+	defer identityIDSchemesMu.Unlock()
+	if _, exists := identityIDSchemes[scheme.Version]; exists {
+		return ErrIdentityIDVersionRegistered
+	}
+	identityIDSchemes[scheme.Version] = scheme
+	return nil
+}
+
+// IdentityIDSchemes returns the registered schemes by version.
+func IdentityIDSchemes() []IdentityIDScheme {
+	identityIDSchemesMu.RLock()
+	defer identityIDSchemesMu.RUnlock()
+	out := make([]IdentityIDScheme, 0, len(identityIDSchemes))
+	for _, scheme := range identityIDSchemes {
+		out = append(out, scheme)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+func lookupIdentityIDScheme(version int) (IdentityIDScheme, bool) {
+	identityIDSchemesMu.RLock()
+	defer identityIDSchemesMu.RUnlock()
+	scheme, ok := identityIDSchemes[version]
+	return scheme, ok
+}
+
+// IdentityIDPrefix returns the prefix of IDs of the given version.
+func IdentityIDPrefix(version int) string {
+	return fmt.Sprintf("%s%d", IdentityIDPrefixBase, version)
+}
+
+// IdentityIDVersion returns the version an ID claims, without checking its
+// body.
+func IdentityIDVersion(identityID string) (int, error) {
+	rest, ok := strings.CutPrefix(identityID, IdentityIDPrefixBase)
+	if !ok || rest == "" || rest[0] < '1' || rest[0] > '0'+MaxIdentityIDVersion {
+		return 0, ErrInvalidIdentityID
+	}
+	return int(rest[0] - '0'), nil
+}
+
+// ValidateIdentityID checks the format of an ID of any version, including
+// versions this build cannot verify yet.
+func ValidateIdentityID(identityID string) error {
+	version, err := IdentityIDVersion(identityID)
+	if err != nil {
+		return err
+	}
+	if version == 1 {
+		// Version 1 predates the versioned format and was only ever checked
+		// for its prefix and a minimum length.
+		if len(identityID) < minLegacyIdentityIDLength {
+			return ErrInvalidIdentityID
+		}
+		return nil
+	}
+	_, err = decodeChecksummedIdentityID(identityID, version)
+	return err
+}
+
+// BuildIdentityID derives the current version ID of an ed25519 key.
+func BuildIdentityID(signingPublicKey []byte) (string, error) {
+	return BuildIdentityIDVersion(CurrentIdentityIDVersion, signingPublicKey)
+}
+
+// BuildIdentityIDVersion derives the ID of a key under a registered version.
+func BuildIdentityIDVersion(version int, signingPublicKey []byte) (string, error) {
+	scheme, ok := lookupIdentityIDScheme(version)
+	if !ok {
+		return "", ErrUnsupportedIdentityIDVersion
+	}
+	if len(signingPublicKey) != scheme.KeySize {
+		return "", fmt.Errorf("invalid signing public key size: %d", len(signingPublicKey))
+	}
+	digest := blake2b.Sum256(signingPublicKey)
+	prefix := IdentityIDPrefix(version)
+	if version == 1 {
+		return prefix + base58.Encode(digest[:]), nil
+	}
+	return prefix + base58.Encode(append(digest[:], identityIDChecksum(prefix, digest[:])...)), nil
+}
+
+// VerifyIdentityID reports whether identityID was derived from the key. The
+// ID's version must be registered.
+func VerifyIdentityID(identityID string, signingPublicKey []byte) (bool, error) {
+	version, err := IdentityIDVersion(identityID)
+	if err != nil {
+		return false, err
+	}
+	expected, err := BuildIdentityIDVersion(version, signingPublicKey)
+	if err != nil {
+		return false, err
+	}
+	return identityID == expected, nil
+}
+
+func decodeChecksummedIdentityID(identityID string, version int) ([]byte, error) {
+	prefix := IdentityIDPrefix(version)
+	raw, err := base58.Decode(strings.TrimPrefix(identityID, prefix))
+	if err != nil || len(raw) < minIdentityIDDigestSize+IdentityIDChecksumSize {
+		return nil, ErrInvalidIdentityID
+	}
+	digest, checksum := raw[:len(raw)-IdentityIDChecksumSize], raw[len(raw)-IdentityIDChecksumSize:]
+	if !bytes.Equal(checksum, identityIDChecksum(prefix, digest)) {
+		return nil, ErrInvalidIdentityID
+	}
+	return digest, nil
+}
+
+func identityIDChecksum(prefix string, digest []byte) []byte {
+	sum := blake2b.Sum256(append([]byte(prefix), digest...))
+	return sum[:IdentityIDChecksumSize]
+}
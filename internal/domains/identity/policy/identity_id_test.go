@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/mr-tron/base58/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestValidateIdentityIDAcceptsFutureVersions(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	v1, err := BuildIdentityID(pub)
+	if err != nil {
+		t.Fatalf("build id failed: %v", err)
+	}
+	if err := ValidateIdentityID(v1); err != nil {
+		t.Fatalf("v1 id must validate: %v", err)
+	}
+
+	// A version this build has no scheme for still validates through the
+	// shared checksum, but cannot be verified against a key.
+	digest := blake2b.Sum256(pub)
+	future := IdentityIDPrefix(7) + base58.Encode(append(digest[:], identityIDChecksum(IdentityIDPrefix(7), digest[:])...))
+	if err := ValidateIdentityID(future); err != nil {
+		t.Fatalf("future version id must validate: %v", err)
+	}
+	if _, err := VerifyIdentityID(future, pub); !errors.Is(err, ErrUnsupportedIdentityIDVersion) {
+		t.Fatalf("unregistered version must not verify, got %v", err)
+	}
+	corrupted := future[:len(future)-1] + "2"
+	if corrupted == future {
+		corrupted = future[:len(future)-1] + "3"
+	}
+	if err := ValidateIdentityID(corrupted); !errors.Is(err, ErrInvalidIdentityID) {
+		t.Fatalf("checksum mismatch must be rejected, got %v", err)
+	}
+
+	for _, bad := range []string{"", "aim1short", "aim0abcdefghijk", "bob1abcdefghijk", "aim"} {
+		if err := ValidateIdentityID(bad); !errors.Is(err, ErrInvalidIdentityID) {
+			t.Fatalf("%q must be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestRegisterIdentityIDScheme(t *testing.T) {
+	if err := RegisterIdentityIDScheme(IdentityIDScheme{Version: 1, KeyType: "ed25519", KeySize: ed25519.PublicKeySize}); !errors.Is(err, ErrUnsupportedIdentityIDVersion) {
+		t.Fatalf("v1 must not be redefined, got %v", err)
+	}
+	scheme := IdentityIDScheme{Version: 9, KeyType: "test-pq", KeySize: 48}
+	if err := RegisterIdentityIDScheme(scheme); err != nil {
+		t.Fatalf("register scheme failed: %v", err)
+	}
+	t.Cleanup(func() {
+		identityIDSchemesMu.Lock()
+		delete(identityIDSchemes, scheme.Version)
+		identityIDSchemesMu.Unlock()
+	})
+	if err := RegisterIdentityIDScheme(scheme); !errors.Is(err, ErrIdentityIDVersionRegistered) {
+		t.Fatalf("duplicate version must be rejected, got %v", err)
+	}
+	key := make([]byte, 48)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("random key failed: %v", err)
+	}
+	id, err := BuildIdentityIDVersion(9, key)
+	if err != nil {
+		t.Fatalf("build v9 id failed: %v", err)
+	}
+	if version, err := IdentityIDVersion(id); err != nil || version != 9 {
+		t.Fatalf("unexpected version %d: %v", version, err)
+	}
+	if err := ValidateIdentityID(id); err != nil {
+		t.Fatalf("v9 id must validate: %v", err)
+	}
+	if ok, err := VerifyIdentityID(id, key); err != nil || !ok {
+		t.Fatalf("v9 id must verify: ok=%v err=%v", ok, err)
+	}
+	if _, err := BuildIdentityIDVersion(9, key[:32]); err == nil {
+		t.Fatal("key of the wrong size must be rejected")
+	}
+}
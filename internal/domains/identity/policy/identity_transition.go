@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strconv"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrInvalidIdentityTransition = fmt.Errorf("invalid identity transition")
+
+// SignIdentityTransition signs the move from oldID to the identity in newCard
+// with the old private key. newCard must already be signed by the new key.
+func SignIdentityTransition(oldID string, newCard models.ContactCard, issuedAt time.Time, oldPrivateKey ed25519.PrivateKey) (models.IdentityTransition, error) {
+	if len(oldPrivateKey) != ed25519.PrivateKeySize {
+		return models.IdentityTransition{}, ErrInvalidIdentityTransition
+	}
+	t := models.IdentityTransition{
+		OldID:    oldID,
+		NewCard:  newCard,
+		IssuedAt: issuedAt.UTC(),
+	}
+	t.Signature = ed25519.Sign(oldPrivateKey, identityTransitionSigningBytes(t))
+	if err := VerifyIdentityTransition(t, oldPrivateKey.Public().(ed25519.PublicKey)); err != nil {
+		return models.IdentityTransition{}, err
+	}
+	return t, nil
+}
+
+// VerifyIdentityTransition checks that the statement was signed by the
+// pinned key of the old identity and that the new card is valid.
+func VerifyIdentityTransition(t models.IdentityTransition, oldPublicKey []byte) error {
+	if len(oldPublicKey) != ed25519.PublicKeySize || len(t.Signature) != ed25519.SignatureSize || t.IssuedAt.IsZero() {
+		return ErrInvalidIdentityTransition
+	}
+	if ok, err := VerifyIdentityID(t.OldID, oldPublicKey); err != nil || !ok {
+		return ErrIdentityMismatch
+	}
+	if t.NewCard.IdentityID == t.OldID {
+		return fmt.Errorf("%w: new id equals old id", ErrInvalidIdentityTransition)
+	}
+	if ok, err := VerifyContactCard(t.NewCard); err != nil || !ok {
+		return fmt.Errorf("%w: new card is not valid", ErrInvalidIdentityTransition)
+	}
+	if !ed25519.Verify(oldPublicKey, identityTransitionSigningBytes(t), t.Signature) {
+		return fmt.Errorf("%w: bad signature", ErrInvalidIdentityTransition)
+	}
+	return nil
+}
+
+func identityTransitionSigningBytes(t models.IdentityTransition) []byte {
+	b := []byte("aim-identity-transition-v1")
+	b = append(b, 0)
+	b = append(b, []byte(t.OldID)...)
+	b = append(b, 0)
+	b = append(b, []byte(t.NewCard.IdentityID)...)
+	b = append(b, 0)
+	b = append(b, t.NewCard.PublicKey...)
+	b = append(b, 0)
+	b = append(b, []byte(strconv.FormatInt(t.IssuedAt.UTC().UnixNano(), 10))...)
+	return b
+}
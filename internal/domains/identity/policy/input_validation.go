@@ -4,6 +4,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"strings"
+	"unicode/utf8"
+
+	"aim-chat/go-backend/pkg/models"
 )
 
 const (
@@ -14,6 +17,10 @@ const (
 	maxAttachmentChunkSize    = 1024 * 1024
 )
 
+// DefaultAttachmentDownloadChunkSize is the download chunk size used when the
+// client does not pick one.
+const DefaultAttachmentDownloadChunkSize = 256 * 1024
+
 func DecodeAttachmentInput(name, mimeType, dataBase64 string) (string, string, []byte, error) {
 	name = strings.TrimSpace(name)
 	mimeType = strings.TrimSpace(mimeType)
@@ -63,6 +70,18 @@ func ValidateAttachmentUploadInit(name, mimeType string, totalSize int64, totalC
 	return name, mimeType, totalSize, totalChunks, chunkSize, nil
 }
 
+// NormalizeAttachmentDownloadChunkSize applies the upload chunk bounds to a
+// download. Zero picks the default.
+func NormalizeAttachmentDownloadChunkSize(chunkSize int) (int, error) {
+	if chunkSize == 0 {
+		return DefaultAttachmentDownloadChunkSize, nil
+	}
+	if chunkSize < minAttachmentChunkSize || chunkSize > maxAttachmentChunkSize {
+		return 0, errors.New("invalid chunk size")
+	}
+	return chunkSize, nil
+}
+
 func ValidateAttachmentChunkInput(uploadID string, index int, data []byte, expectedChunkSize int, totalSize int64, totalChunks int) (string, error) {
 	uploadID = strings.TrimSpace(uploadID)
 	if uploadID == "" {
@@ -86,6 +105,19 @@ func ValidateAttachmentChunkInput(uploadID string, index int, data []byte, expec
 	return uploadID, nil
 }
 
+// NormalizeAttachmentAltText trims the accessibility description and enforces
+// its length and character limits. An empty result means no description.
+func NormalizeAttachmentAltText(altText string) (string, error) {
+	altText = strings.TrimSpace(altText)
+	if !utf8.ValidString(altText) {
+		return "", errors.New("invalid attachment alt text")
+	}
+	if utf8.RuneCountInString(altText) > models.MaxAttachmentAltTextRunes {
+		return "", errors.New("attachment alt text is too long")
+	}
+	return altText, nil
+}
+
 func ValidateLoginInput(accountID, password, currentIdentityID string) error {
 	accountID = strings.TrimSpace(accountID)
 	password = strings.TrimSpace(password)
@@ -0,0 +1,74 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	minUsernameLen = 3
+	maxUsernameLen = 32
+)
+
+var (
+	ErrInvalidUsername        = errors.New("username must be 3-32 characters of a-z, 0-9, '_' or '.', starting with a letter")
+	ErrInvalidUsernameDisplay = errors.New("username display must be display_name, username or both")
+)
+
+// NormalizeUsername lowercases a username claim and checks its shape. Names
+// are ASCII-only so visually identical claims compare equal.
+func NormalizeUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	username = strings.TrimPrefix(username, "@")
+	if len(username) < minUsernameLen || len(username) > maxUsernameLen {
+		return "", ErrInvalidUsername
+	}
+	for i, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_' || r == '.'):
+		default:
+			return "", ErrInvalidUsername
+		}
+	}
+	return username, nil
+}
+
+func NormalizeUsernameDisplay(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		return models.UsernameDisplayName, nil
+	case models.UsernameDisplayName, models.UsernameDisplayUsername, models.UsernameDisplayBoth:
+		return mode, nil
+	default:
+		return "", ErrInvalidUsernameDisplay
+	}
+}
+
+// ContactDisplayLabel renders the name of contact under the given display
+// preference. Contacts without a claimed username fall back to their display
+// name, and conflicting claims are never shown on their own.
+func ContactDisplayLabel(contact models.Contact, mode string) string {
+	name := contact.DisplayName
+	if name == "" {
+		name = contact.ID
+	}
+	if contact.Username == "" {
+		return name
+	}
+	handle := "@" + contact.Username
+	switch mode {
+	case models.UsernameDisplayUsername:
+		if contact.UsernameConflict {
+			return handle + " (" + name + ")"
+		}
+		return handle
+	case models.UsernameDisplayBoth:
+		return name + " (" + handle + ")"
+	default:
+		return name
+	}
+}
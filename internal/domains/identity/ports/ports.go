@@ -1,6 +1,8 @@
 package ports
 
 import (
+	"context"
+	"crypto/ed25519"
 	"time"
 
 	"aim-chat/go-backend/internal/crypto"
@@ -13,6 +15,13 @@ type IdentityStateStore interface {
 	Persist(identityManager contracts.IdentityDomain) error
 }
 
+// AttestationSources is what contact attestations are checked against: the
+// authority keys of the trust bundle and the domains' well-known files.
+type AttestationSources interface {
+	AuthorityKeys() map[string]ed25519.PublicKey
+	FetchDomainAttestation(ctx context.Context, domain string) ([]byte, error)
+}
+
 type BackupIdentityReader interface {
 	GetIdentity() models.Identity
 	Contacts() []models.Contact
@@ -46,6 +55,11 @@ type BackupSessionRestorer interface {
 	RestoreSnapshot(states []crypto.SessionState) error
 }
 
+type BackupClientStateStore interface {
+	Snapshot() []models.ClientStateEntry
+	RestoreSnapshot(entries []models.ClientStateEntry) error
+}
+
 type AccountIdentityAccess interface {
 	GetIdentity() models.Identity
 	VerifyPassword(seedPassword string) error
@@ -9,10 +9,27 @@ const (
 	MethodIdentityImportSeed = "identity.import_seed"
 	MethodIdentityMnemonic   = "identity.validate_mnemonic"
 	MethodIdentityChangePwd  = "identity.change_password"
+	MethodSecurityLockout    = "security.lockout.status"
 	MethodBackupExport       = "backup.export"
 	MethodBackupRestore      = "backup.restore"
 	MethodDataWipe           = "data.wipe"
 	MethodAccountList        = "account.list"
 	MethodAccountCurrent     = "account.current"
 	MethodAccountSwitch      = "account.switch"
+	MethodClientStateSet     = "clientstate.set"
+	MethodClientStateGet     = "clientstate.get"
+	MethodClientStateDelete  = "clientstate.delete"
+	MethodStorageRotateKey   = "storage.rotate_key"
+	MethodStorageRotation    = "storage.rotation_status"
+	MethodStorageSnapCreate  = "storage.snapshot.create"
+	MethodStorageSnapList    = "storage.snapshot.list"
+	MethodStorageSnapRestore = "storage.snapshot.restore"
+	MethodStorageVersion     = "storage.version"
+	MethodSafetyFeedApply    = "safety.feed.apply"
+	MethodSafetyFeedStatus   = "safety.feed.status"
+	MethodUsernameClaim      = "identity.username.claim"
+	MethodUsernameRelease    = "identity.username.release"
+	MethodAttestationsSet    = "identity.attestations.set"
+	MethodAttestationsGet    = "identity.attestations.get"
+	MethodAttestationsVerify = "contact.attestations.verify"
 )
@@ -21,6 +21,7 @@ type attachmentUploadSession struct {
 	TotalChunks int
 	ChunkSize   int
 	FileSHA256  string
+	AltText     string
 	Chunks      map[int][]byte
 	UpdatedAt   time.Time
 }
@@ -45,11 +46,18 @@ type AttachmentUploadStatus struct {
 	NextChunk     int    `json:"next_chunk"`
 }
 
-func (s *Service) InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256 string) (AttachmentUploadInitResult, error) {
-	name, mimeType, totalSize, totalChunks, chunkSize, err := identitypolicy.ValidateAttachmentUploadInit(name, mimeType, totalSize, totalChunks, chunkSize)
+func (s *Service) InitAttachmentUpload(name, mimeType string, totalSize int64, totalChunks, chunkSize int, fileSHA256, altText string) (AttachmentUploadInitResult, error) {
+	altText, err := identitypolicy.NormalizeAttachmentAltText(altText)
 	if err != nil {
 		return AttachmentUploadInitResult{}, err
 	}
+	name, mimeType, totalSize, totalChunks, chunkSize, err = identitypolicy.ValidateAttachmentUploadInit(name, mimeType, totalSize, totalChunks, chunkSize)
+	if err != nil {
+		return AttachmentUploadInitResult{}, err
+	}
+	if err := identitypolicy.CheckAttachmentDeclaration(s.attachmentMimePolicy(), name, mimeType); err != nil {
+		return AttachmentUploadInitResult{}, err
+	}
 	fileSHA256, err = identitypolicy.NormalizeOptionalSHA256Hex(fileSHA256, "file")
 	if err != nil {
 		return AttachmentUploadInitResult{}, err
@@ -69,6 +77,7 @@ func (s *Service) InitAttachmentUpload(name, mimeType string, totalSize int64, t
 		TotalChunks: totalChunks,
 		ChunkSize:   chunkSize,
 		FileSHA256:  fileSHA256,
+		AltText:     altText,
 		Chunks:      make(map[int][]byte, totalChunks),
 		UpdatedAt:   now,
 	}
@@ -188,7 +197,7 @@ func (s *Service) CommitAttachmentUpload(uploadID string) (models.AttachmentMeta
 	}
 	delete(s.uploads, uploadID)
 	s.uploadMu.Unlock()
-	return s.attachmentStore.Put(name, mimeType, normalized)
+	return s.storeInspectedAttachment(name, mimeType, session.AltText, normalized)
 }
 
 func newUploadID() (string, error) {
@@ -2,10 +2,12 @@ package usecase
 
 import (
 	"encoding/base64"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
+	"aim-chat/go-backend/internal/domains/contracts"
 	"aim-chat/go-backend/pkg/models"
 )
 
@@ -40,7 +42,7 @@ func TestAttachmentChunkUploadRoundtrip(t *testing.T) {
 		uploads: make(map[string]attachmentUploadSession),
 	}
 
-	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 2, 16*1024, "")
+	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 2, 16*1024, "", "")
 	if err != nil {
 		t.Fatalf("init upload failed: %v", err)
 	}
@@ -70,7 +72,7 @@ func TestAttachmentChunkUploadFailsOnDigestMismatch(t *testing.T) {
 		attachmentStore: &chunkTestAttachmentStore{},
 		uploads:         make(map[string]attachmentUploadSession),
 	}
-	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "")
+	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "", "")
 	if err != nil {
 		t.Fatalf("init upload failed: %v", err)
 	}
@@ -86,7 +88,7 @@ func TestAttachmentChunkUploadRejectsInvalidChunkDigestFormat(t *testing.T) {
 		attachmentStore: &chunkTestAttachmentStore{},
 		uploads:         make(map[string]attachmentUploadSession),
 	}
-	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "")
+	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "", "")
 	if err != nil {
 		t.Fatalf("init upload failed: %v", err)
 	}
@@ -102,7 +104,7 @@ func TestAttachmentChunkUploadRejectsInvalidFileDigestFormat(t *testing.T) {
 		attachmentStore: &chunkTestAttachmentStore{},
 		uploads:         make(map[string]attachmentUploadSession),
 	}
-	if _, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, strings.Repeat("g", 64)); err == nil {
+	if _, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, strings.Repeat("g", 64), ""); err == nil {
 		t.Fatal("expected invalid file digest format error")
 	}
 }
@@ -113,7 +115,7 @@ func TestAttachmentChunkUploadStatusAndExpiry(t *testing.T) {
 		attachmentStore: &chunkTestAttachmentStore{},
 		uploads:         make(map[string]attachmentUploadSession),
 	}
-	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "")
+	initRes, err := svc.InitAttachmentUpload("doc.txt", "text/plain", int64(len(payload)), 1, 16*1024, "", "")
 	if err != nil {
 		t.Fatalf("init upload failed: %v", err)
 	}
@@ -142,7 +144,7 @@ func TestAttachmentChunkUploadCommitRejectsSpoofedImage(t *testing.T) {
 		attachmentStore: &chunkTestAttachmentStore{},
 		uploads:         make(map[string]attachmentUploadSession),
 	}
-	initRes, err := svc.InitAttachmentUpload("photo.png", "image/png", int64(len(payload)), 1, 16*1024, "")
+	initRes, err := svc.InitAttachmentUpload("photo.png", "image/png", int64(len(payload)), 1, 16*1024, "", "")
 	if err != nil {
 		t.Fatalf("init upload failed: %v", err)
 	}
@@ -154,3 +156,22 @@ func TestAttachmentChunkUploadCommitRejectsSpoofedImage(t *testing.T) {
 		t.Fatal("expected commit to fail for spoofed image payload")
 	}
 }
+
+type failingInspectionAttachmentStore struct {
+	chunkTestAttachmentStore
+}
+
+func (s *failingInspectionAttachmentStore) SetMimeInspection(_, _ string, _ []string) (models.AttachmentMeta, error) {
+	return models.AttachmentMeta{}, errors.New("index write failed")
+}
+
+func TestPutAttachmentReportsFailedInspectionRecord(t *testing.T) {
+	svc := &Service{attachmentStore: &failingInspectionAttachmentStore{}}
+	_, err := svc.PutAttachment("doc.txt", "text/plain", base64.StdEncoding.EncodeToString([]byte("hello")))
+	if err == nil {
+		t.Fatal("expected the failed inspection record to be reported")
+	}
+	if got := contracts.ErrorCategory(err); got != contracts.ErrorCategoryStorage {
+		t.Fatalf("expected a storage error, got %q", got)
+	}
+}
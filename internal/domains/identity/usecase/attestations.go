@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
+	identityports "aim-chat/go-backend/internal/domains/identity/ports"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errAttestationsUnsupported = errors.New("attestations are not supported")
+
+type attestationManager interface {
+	SetSelfAttestations(attestations []models.Attestation) (bool, error)
+	SelfAttestations() []models.Attestation
+	ApplyContactVerification(contactID, source string, now time.Time) (models.Contact, bool, error)
+}
+
+// SetSelfAttestations replaces the attestations carried by this identity's
+// cards and returns the self card carrying them, ready to be sent to
+// contacts.
+func (s *Service) SetSelfAttestations(attestations []models.Attestation) (models.ContactCard, error) {
+	manager, ok := s.identityManager.(attestationManager)
+	if !ok {
+		return models.ContactCard{}, errAttestationsUnsupported
+	}
+	changed, err := manager.SetSelfAttestations(attestations)
+	if err != nil {
+		return models.ContactCard{}, err
+	}
+	if changed {
+		if err := s.identityState.Persist(s.identityManager); err != nil {
+			return models.ContactCard{}, err
+		}
+	}
+	return s.SelfCardForRefresh()
+}
+
+func (s *Service) SelfAttestations() []models.Attestation {
+	manager, ok := s.identityManager.(attestationManager)
+	if !ok {
+		return nil
+	}
+	return manager.SelfAttestations()
+}
+
+// VerifyContactAttestations checks the attestations of a contact's latest
+// card and updates its verified badge. Authority signatures are checked
+// first since they need no network; the first attestation that checks out
+// becomes the badge source. A domain that cannot be reached counts as not
+// vouching.
+func (s *Service) VerifyContactAttestations(ctx context.Context, contactID string, sources identityports.AttestationSources, now time.Time) (models.Contact, error) {
+	manager, ok := s.identityManager.(attestationManager)
+	if !ok {
+		return models.Contact{}, errAttestationsUnsupported
+	}
+	var contact models.Contact
+	found := false
+	for _, c := range s.identityManager.Contacts() {
+		if c.ID == contactID {
+			contact, found = c, true
+			break
+		}
+	}
+	if !found {
+		return models.Contact{}, errors.New("contact not found")
+	}
+	source := ""
+	if sources != nil {
+		source = verifiedAttestationSource(ctx, contact, sources)
+	}
+	updated, changed, err := manager.ApplyContactVerification(contact.ID, source, now)
+	if err != nil {
+		return models.Contact{}, err
+	}
+	if changed {
+		if err := s.identityState.Persist(s.identityManager); err != nil {
+			return models.Contact{}, err
+		}
+	}
+	return updated, nil
+}
+
+func verifiedAttestationSource(ctx context.Context, contact models.Contact, sources identityports.AttestationSources) string {
+	authorities := sources.AuthorityKeys()
+	for _, att := range contact.Attestations {
+		if identitypolicy.VerifyAuthorityAttestation(att, contact.ID, contact.PublicKey, authorities) {
+			return identitypolicy.AttestationSource(att)
+		}
+	}
+	for _, att := range contact.Attestations {
+		if att.Kind != models.AttestationKindDomain {
+			continue
+		}
+		body, err := sources.FetchDomainAttestation(ctx, att.Subject)
+		if err != nil {
+			continue
+		}
+		if identitypolicy.DomainDocumentListsIdentity(body, contact.ID) {
+			return identitypolicy.AttestationSource(att)
+		}
+	}
+	return ""
+}
@@ -16,16 +16,18 @@ import (
 )
 
 type BackupExportResult struct {
-	Blob         string
-	IdentityID   string
-	MessageCount int
-	SessionCount int
+	Blob             string
+	IdentityID       string
+	MessageCount     int
+	SessionCount     int
+	ClientStateCount int
 }
 
 type BackupRestoreResult struct {
-	IdentityID   string
-	MessageCount int
-	SessionCount int
+	IdentityID       string
+	MessageCount     int
+	SessionCount     int
+	ClientStateCount int
 }
 
 type backupPayload struct {
@@ -38,6 +40,7 @@ type backupPayload struct {
 	Messages          map[string]models.Message         `json:"messages"`
 	Pending           map[string]storage.PendingMessage `json:"pending"`
 	Sessions          []crypto.SessionState             `json:"sessions"`
+	ClientState       []models.ClientStateEntry         `json:"client_state,omitempty"`
 }
 
 func ExportBackup(
@@ -45,6 +48,7 @@ func ExportBackup(
 	identity identityports.BackupIdentityReader,
 	messageStore identityports.BackupMessageSnapshotter,
 	sessionManager identityports.BackupSessionSnapshotter,
+	clientState identityports.BackupClientStateStore,
 ) (BackupExportResult, error) {
 	consentToken = strings.TrimSpace(consentToken)
 	password = strings.TrimSpace(password)
@@ -80,6 +84,9 @@ func ExportBackup(
 		Pending:           pending,
 		Sessions:          sessions,
 	}
+	if clientState != nil {
+		payload.ClientState = clientState.Snapshot()
+	}
 	raw, err := json.Marshal(payload)
 	if err != nil {
 		return BackupExportResult{}, err
@@ -89,10 +96,11 @@ func ExportBackup(
 		return BackupExportResult{}, err
 	}
 	return BackupExportResult{
-		Blob:         base64.StdEncoding.EncodeToString(encrypted),
-		IdentityID:   payload.Identity.ID,
-		MessageCount: len(messages),
-		SessionCount: len(sessions),
+		Blob:             base64.StdEncoding.EncodeToString(encrypted),
+		IdentityID:       payload.Identity.ID,
+		MessageCount:     len(messages),
+		SessionCount:     len(sessions),
+		ClientStateCount: len(payload.ClientState),
 	}, nil
 }
 
@@ -101,6 +109,7 @@ func RestoreBackup(
 	identity identityports.BackupIdentityRestorer,
 	messageStore identityports.BackupMessageRestorer,
 	sessionManager identityports.BackupSessionRestorer,
+	clientState identityports.BackupClientStateStore,
 ) (BackupRestoreResult, error) {
 	consentToken = strings.TrimSpace(consentToken)
 	password = strings.TrimSpace(password)
@@ -184,10 +193,16 @@ func RestoreBackup(
 	if err := sessionManager.RestoreSnapshot(payload.Sessions); err != nil {
 		return BackupRestoreResult{}, err
 	}
+	if clientState != nil {
+		if err := clientState.RestoreSnapshot(payload.ClientState); err != nil {
+			return BackupRestoreResult{}, err
+		}
+	}
 
 	return BackupRestoreResult{
-		IdentityID:   identity.GetIdentity().ID,
-		MessageCount: len(payload.Messages),
-		SessionCount: len(payload.Sessions),
+		IdentityID:       identity.GetIdentity().ID,
+		MessageCount:     len(payload.Messages),
+		SessionCount:     len(payload.Sessions),
+		ClientStateCount: len(payload.ClientState),
 	}, nil
 }
@@ -110,15 +110,28 @@ func (f *fakeBackupSessions) RestoreSnapshot(states []crypto.SessionState) error
 	return nil
 }
 
+type fakeBackupClientState struct {
+	entries []models.ClientStateEntry
+}
+
+func (f *fakeBackupClientState) Snapshot() []models.ClientStateEntry {
+	return append([]models.ClientStateEntry(nil), f.entries...)
+}
+
+func (f *fakeBackupClientState) RestoreSnapshot(entries []models.ClientStateEntry) error {
+	f.entries = append([]models.ClientStateEntry(nil), entries...)
+	return nil
+}
+
 func TestExportBackup_Validation(t *testing.T) {
 	id := &fakeBackupIdentity{}
 	msgs := &fakeBackupMessages{}
 	sessions := &fakeBackupSessions{}
 
-	if _, err := ExportBackup("", "pass", id, msgs, sessions); err == nil {
+	if _, err := ExportBackup("", "pass", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected consent token error")
 	}
-	if _, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "   ", id, msgs, sessions); err == nil {
+	if _, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "   ", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected password error")
 	}
 }
@@ -128,7 +141,7 @@ func TestExportBackup_SessionSnapshotError(t *testing.T) {
 	msgs := &fakeBackupMessages{}
 	sessions := &fakeBackupSessions{err: errors.New("snapshot failed")}
 
-	if _, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "pass", id, msgs, sessions); err == nil {
+	if _, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "pass", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected snapshot error")
 	}
 }
@@ -148,7 +161,7 @@ func TestExportBackup_Success(t *testing.T) {
 	}
 	sessions := &fakeBackupSessions{sessions: []crypto.SessionState{}}
 
-	result, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", id, msgs, sessions)
+	result, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", id, msgs, sessions, nil)
 	if err != nil {
 		t.Fatalf("ExportBackup failed: %v", err)
 	}
@@ -206,8 +219,11 @@ func TestRestoreBackup_Success(t *testing.T) {
 	sessions := &fakeBackupSessions{
 		sessions: []crypto.SessionState{{SessionID: "sess-1", ContactID: "c-1"}},
 	}
+	clientState := &fakeBackupClientState{
+		entries: []models.ClientStateEntry{{Namespace: "app.ui", Key: "theme", Value: "dark"}},
+	}
 
-	exported, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", identity, messages, sessions)
+	exported, err := ExportBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", identity, messages, sessions, clientState)
 	if err != nil {
 		t.Fatalf("ExportBackup failed: %v", err)
 	}
@@ -218,11 +234,12 @@ func TestRestoreBackup_Success(t *testing.T) {
 		pending:  map[string]storage.PendingMessage{},
 	}
 	restoredSessions := &fakeBackupSessions{}
-	result, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", exported.Blob, restoredIdentity, restoredMessages, restoredSessions)
+	restoredClientState := &fakeBackupClientState{}
+	result, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "secret-pass", exported.Blob, restoredIdentity, restoredMessages, restoredSessions, restoredClientState)
 	if err != nil {
 		t.Fatalf("RestoreBackup failed: %v", err)
 	}
-	if result.MessageCount != 1 || result.SessionCount != 1 {
+	if result.MessageCount != 1 || result.SessionCount != 1 || result.ClientStateCount != 1 {
 		t.Fatalf("unexpected restore counts: %#v", result)
 	}
 	if restoredIdentity.identity.ID != "id-1" {
@@ -240,6 +257,9 @@ func TestRestoreBackup_Success(t *testing.T) {
 	if len(restoredSessions.sessions) != 1 || restoredSessions.sessions[0].SessionID != "sess-1" {
 		t.Fatalf("unexpected restored sessions: %#v", restoredSessions.sessions)
 	}
+	if len(restoredClientState.entries) != 1 || restoredClientState.entries[0].Value != "dark" {
+		t.Fatalf("unexpected restored client state: %#v", restoredClientState.entries)
+	}
 }
 
 func TestRestoreBackup_Validation(t *testing.T) {
@@ -247,13 +267,13 @@ func TestRestoreBackup_Validation(t *testing.T) {
 	msgs := &fakeBackupMessages{messages: map[string]models.Message{}, pending: map[string]storage.PendingMessage{}}
 	sessions := &fakeBackupSessions{}
 
-	if _, err := RestoreBackup("", "pass", "blob", id, msgs, sessions); err == nil {
+	if _, err := RestoreBackup("", "pass", "blob", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected consent token error")
 	}
-	if _, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "", "blob", id, msgs, sessions); err == nil {
+	if _, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "", "blob", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected password error")
 	}
-	if _, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "pass", "", id, msgs, sessions); err == nil {
+	if _, err := RestoreBackup("I_UNDERSTAND_BACKUP_RISK", "pass", "", id, msgs, sessions, nil); err == nil {
 		t.Fatal("expected blob error")
 	}
 }
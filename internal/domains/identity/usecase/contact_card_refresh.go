@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type contactCardRefresher interface {
+	ApplyContactCardRefresh(card models.ContactCard, now time.Time) (models.Contact, bool, error)
+	MarkStaleContactCards(cutoff time.Time) []string
+	SetSelfDisplayName(displayName string) bool
+	SelfDisplayName() string
+}
+
+// rememberSelfDisplayName keeps the name the user last shared in a self card
+// so refresh replies advertise the same name.
+func (s *Service) rememberSelfDisplayName(displayName string) {
+	refresher, ok := s.identityManager.(contactCardRefresher)
+	if !ok || !refresher.SetSelfDisplayName(displayName) {
+		return
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil && s.logger != nil {
+		s.logger.Warn("self display name persist failed", "error", err.Error())
+	}
+}
+
+// SelfCardForRefresh signs the card sent in reply to a contact's card request.
+func (s *Service) SelfCardForRefresh() (models.ContactCard, error) {
+	refresher, ok := s.identityManager.(contactCardRefresher)
+	if !ok {
+		return models.ContactCard{}, errors.New("contact card refresh is not supported")
+	}
+	return s.identityManager.SelfContactCard(refresher.SelfDisplayName())
+}
+
+// ApplyContactCardRefresh stores a refreshed card received from a verified
+// contact.
+func (s *Service) ApplyContactCardRefresh(card models.ContactCard, now time.Time) (models.Contact, bool, error) {
+	refresher, ok := s.identityManager.(contactCardRefresher)
+	if !ok {
+		return models.Contact{}, false, errors.New("contact card refresh is not supported")
+	}
+	contact, changed, err := refresher.ApplyContactCardRefresh(card, now)
+	if err != nil {
+		return models.Contact{}, false, err
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil {
+		return models.Contact{}, false, err
+	}
+	return contact, changed, nil
+}
+
+// MarkStaleContactCards flags contacts whose card could not be refreshed since
+// cutoff.
+func (s *Service) MarkStaleContactCards(cutoff time.Time) ([]string, error) {
+	refresher, ok := s.identityManager.(contactCardRefresher)
+	if !ok {
+		return nil, nil
+	}
+	marked := refresher.MarkStaleContactCards(cutoff)
+	if len(marked) == 0 {
+		return nil, nil
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil {
+		return nil, err
+	}
+	return marked, nil
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type contactMerger interface {
+	MergeContact(oldID, newID string, transition models.IdentityTransition, now time.Time) (models.Contact, error)
+	ResolveContactID(contactID string) string
+}
+
+// MergeContact links a contact's history to the identity it rotated to.
+func (s *Service) MergeContact(oldID, newID string, transition models.IdentityTransition, now time.Time) (models.Contact, error) {
+	merger, ok := s.identityManager.(contactMerger)
+	if !ok {
+		return models.Contact{}, errors.New("contact merge is not supported")
+	}
+	contact, err := merger.MergeContact(oldID, newID, transition, now)
+	if err != nil {
+		return models.Contact{}, err
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil {
+		return models.Contact{}, err
+	}
+	return contact, nil
+}
+
+// ResolveContactID returns the id that currently receives messages for
+// contactID, following merges.
+func (s *Service) ResolveContactID(contactID string) string {
+	merger, ok := s.identityManager.(contactMerger)
+	if !ok {
+		return contactID
+	}
+	return merger.ResolveContactID(contactID)
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"errors"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type contactMetaUpdater interface {
+	UpdateContactMeta(contactID, alias, notes, color string) (models.Contact, error)
+}
+
+// UpdateContactMeta sets the local alias, notes and color of a contact. The
+// contact's signed card is left untouched.
+func (s *Service) UpdateContactMeta(contactID, alias, notes, color string) (models.Contact, error) {
+	updater, ok := s.identityManager.(contactMetaUpdater)
+	if !ok {
+		return models.Contact{}, errors.New("contact metadata is not supported")
+	}
+	contact, err := updater.UpdateContactMeta(contactID, alias, notes, color)
+	if err != nil {
+		return models.Contact{}, err
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil {
+		return models.Contact{}, err
+	}
+	return contact, nil
+}
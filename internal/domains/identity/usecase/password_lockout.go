@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"errors"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type passwordLockoutReporter interface {
+	PasswordLockoutStatus() models.PasswordLockoutStatus
+}
+
+var errPasswordLockoutUnsupported = errors.New("password lockout status is not supported")
+
+// PasswordLockoutStatus reports how long password checks are held back after
+// failed attempts.
+func (s *Service) PasswordLockoutStatus() (models.PasswordLockoutStatus, error) {
+	reporter, ok := s.identityManager.(passwordLockoutReporter)
+	if !ok {
+		return models.PasswordLockoutStatus{}, errPasswordLockoutUnsupported
+	}
+	return reporter.PasswordLockoutStatus(), nil
+}
+
+func (s *Service) passwordLockoutStatus() models.PasswordLockoutStatus {
+	status, _ := s.PasswordLockoutStatus()
+	return status
+}
+
+// notePasswordAttempt audits failed and blocked password checks and persists
+// the attempt counters whenever they change, so a restart cannot be used to
+// skip a lockout.
+func (s *Service) notePasswordAttempt(operation string, before models.PasswordLockoutStatus, err error) {
+	after := s.passwordLockoutStatus()
+	switch {
+	case after.FailedAttempts > before.FailedAttempts:
+		if s.logger != nil {
+			s.logger.Warn("password attempt failed",
+				"category", "security",
+				"event_type", "identity.password.failed",
+				"operation", operation,
+				"failed_attempts", after.FailedAttempts,
+				"lockout", after.Lockout,
+				"retry_after_seconds", after.RetryAfterSeconds,
+			)
+		}
+	case err != nil && after.Locked:
+		if s.logger != nil {
+			s.logger.Warn("password attempt blocked",
+				"category", "security",
+				"event_type", "identity.password.blocked",
+				"operation", operation,
+				"failed_attempts", after.FailedAttempts,
+				"retry_after_seconds", after.RetryAfterSeconds,
+			)
+		}
+		return
+	case after.FailedAttempts == before.FailedAttempts:
+		return
+	}
+	if persistErr := s.identityState.Persist(s.identityManager); persistErr != nil && s.logger != nil {
+		s.logger.Warn("password attempt state persist failed", "error", persistErr.Error())
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"errors"
 	"log/slog"
 	"strings"
 	"sync"
@@ -21,6 +22,9 @@ type Service struct {
 	logger          *slog.Logger
 	uploadMu        sync.Mutex
 	uploads         map[string]attachmentUploadSession
+	configMu        sync.Mutex
+	mimePolicy      identitypolicy.AttachmentMimePolicy
+	clientState     identityports.BackupClientStateStore
 }
 
 func NewService(
@@ -39,9 +43,35 @@ func NewService(
 		attachmentStore: attachmentStore,
 		logger:          logger,
 		uploads:         make(map[string]attachmentUploadSession),
+		mimePolicy:      identitypolicy.DefaultAttachmentMimePolicy(),
 	}
 }
 
+func (s *Service) SetAttachmentMimePolicy(policy identitypolicy.AttachmentMimePolicy) {
+	s.configMu.Lock()
+	s.mimePolicy = policy
+	s.configMu.Unlock()
+}
+
+// SetClientStateStore includes client app state in backup export and restore.
+func (s *Service) SetClientStateStore(store identityports.BackupClientStateStore) {
+	s.configMu.Lock()
+	s.clientState = store
+	s.configMu.Unlock()
+}
+
+func (s *Service) backupClientState() identityports.BackupClientStateStore {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.clientState
+}
+
+func (s *Service) attachmentMimePolicy() identitypolicy.AttachmentMimePolicy {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.mimePolicy
+}
+
 func (s *Service) Logout() error {
 	return nil
 }
@@ -55,7 +85,10 @@ func (s *Service) Login(identityID, seedPassword string) error {
 }
 
 func (s *Service) ExportSeed(seedPassword string) (string, error) {
-	return s.identityManager.ExportSeed(strings.TrimSpace(seedPassword))
+	before := s.passwordLockoutStatus()
+	mnemonic, err := s.identityManager.ExportSeed(strings.TrimSpace(seedPassword))
+	s.notePasswordAttempt("identity.export_seed", before, err)
+	return mnemonic, err
 }
 
 func (s *Service) ValidateMnemonic(mnemonic string) bool {
@@ -63,11 +96,22 @@ func (s *Service) ValidateMnemonic(mnemonic string) bool {
 }
 
 func (s *Service) ChangePassword(oldSeedPassword, newSeedPassword string) error {
-	return s.identityManager.ChangePassword(strings.TrimSpace(oldSeedPassword), strings.TrimSpace(newSeedPassword))
+	before := s.passwordLockoutStatus()
+	err := s.identityManager.ChangePassword(strings.TrimSpace(oldSeedPassword), strings.TrimSpace(newSeedPassword))
+	s.notePasswordAttempt("identity.change_password", before, err)
+	if err != nil {
+		return err
+	}
+	return s.identityState.Persist(s.identityManager)
 }
 
 func (s *Service) SelfContactCard(displayName string) (models.ContactCard, error) {
-	return s.identityManager.SelfContactCard(displayName)
+	card, err := s.identityManager.SelfContactCard(displayName)
+	if err != nil {
+		return models.ContactCard{}, err
+	}
+	s.rememberSelfDisplayName(displayName)
+	return card, nil
 }
 
 func (s *Service) AddContactCard(card models.ContactCard) error {
@@ -121,18 +165,18 @@ func (s *Service) CreateIdentity(seedPassword string) (models.Identity, string,
 }
 
 func (s *Service) ExportBackup(consentToken, password string) (string, error) {
-	result, err := ExportBackup(consentToken, password, s.identityManager, s.messageStore, s.sessionManager)
+	result, err := ExportBackup(consentToken, password, s.identityManager, s.messageStore, s.sessionManager, s.backupClientState())
 	if err != nil {
 		return "", err
 	}
 	if s.logger != nil {
-		s.logger.Warn("backup export executed", "identity_id", result.IdentityID, "messages", result.MessageCount, "sessions", result.SessionCount)
+		s.logger.Warn("backup export executed", "identity_id", result.IdentityID, "messages", result.MessageCount, "sessions", result.SessionCount, "client_state", result.ClientStateCount)
 	}
 	return result.Blob, nil
 }
 
 func (s *Service) RestoreBackup(consentToken, password, backupBlob string) (models.Identity, error) {
-	result, err := RestoreBackup(consentToken, password, backupBlob, s.identityManager, s.messageStore, s.sessionManager)
+	result, err := RestoreBackup(consentToken, password, backupBlob, s.identityManager, s.messageStore, s.sessionManager, s.backupClientState())
 	if err != nil {
 		return models.Identity{}, err
 	}
@@ -140,7 +184,7 @@ func (s *Service) RestoreBackup(consentToken, password, backupBlob string) (mode
 		return models.Identity{}, err
 	}
 	if s.logger != nil {
-		s.logger.Warn("backup restore executed", "identity_id", result.IdentityID, "messages", result.MessageCount, "sessions", result.SessionCount)
+		s.logger.Warn("backup restore executed", "identity_id", result.IdentityID, "messages", result.MessageCount, "sessions", result.SessionCount, "client_state", result.ClientStateCount)
 	}
 	return s.identityManager.GetIdentity(), nil
 }
@@ -152,11 +196,72 @@ func (s *Service) ImportIdentity(mnemonic, seedPassword string) (models.Identity
 }
 
 func (s *Service) PutAttachment(name, mimeType, dataBase64 string) (models.AttachmentMeta, error) {
+	return s.PutAttachmentWithAltText(name, mimeType, dataBase64, "")
+}
+
+// PutAttachmentWithAltText stores an attachment together with its
+// accessibility description.
+func (s *Service) PutAttachmentWithAltText(name, mimeType, dataBase64, altText string) (models.AttachmentMeta, error) {
+	altText, err := identitypolicy.NormalizeAttachmentAltText(altText)
+	if err != nil {
+		return models.AttachmentMeta{}, err
+	}
 	name, mimeType, data, err := identitypolicy.DecodeAttachmentInput(name, mimeType, dataBase64)
 	if err != nil {
 		return models.AttachmentMeta{}, err
 	}
-	return s.attachmentStore.Put(name, mimeType, data)
+	return s.storeInspectedAttachment(name, mimeType, altText, data)
+}
+
+// storeInspectedAttachment enforces the MIME policy on the final payload and
+// records sniffing results on the stored metadata. Both upload paths end here.
+func (s *Service) storeInspectedAttachment(name, mimeType, altText string, data []byte) (models.AttachmentMeta, error) {
+	inspection, err := identitypolicy.InspectAttachmentPayload(s.attachmentMimePolicy(), name, mimeType, data)
+	if err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	meta, err := s.attachmentStore.Put(name, mimeType, data)
+	if err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	if altText != "" {
+		if described, err := s.SetAttachmentAltText(meta.ID, altText); err == nil {
+			meta = described
+		}
+	}
+	annotator, ok := s.attachmentStore.(interface {
+		SetMimeInspection(id, sniffedMime string, warnings []string) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		meta.SniffedMime = inspection.SniffedMime
+		meta.MimeWarnings = inspection.Warnings
+		return meta, nil
+	}
+	annotated, err := annotator.SetMimeInspection(meta.ID, inspection.SniffedMime, inspection.Warnings)
+	if err != nil {
+		return models.AttachmentMeta{}, contracts.WrapCategorizedError(contracts.ErrorCategoryStorage, err)
+	}
+	return annotated, nil
+}
+
+// SetAttachmentAltText edits the accessibility description of a stored
+// attachment. An empty text clears it.
+func (s *Service) SetAttachmentAltText(attachmentID, altText string) (models.AttachmentMeta, error) {
+	attachmentID, err := identitypolicy.ValidateAttachmentID(attachmentID)
+	if err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	altText, err = identitypolicy.NormalizeAttachmentAltText(altText)
+	if err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	describer, ok := s.attachmentStore.(interface {
+		SetAltText(id, altText string) (models.AttachmentMeta, error)
+	})
+	if !ok {
+		return models.AttachmentMeta{}, errors.New("attachment alt text is not supported")
+	}
+	return describer.SetAltText(attachmentID, altText)
 }
 
 func (s *Service) GetAttachment(attachmentID string) (models.AttachmentMeta, []byte, error) {
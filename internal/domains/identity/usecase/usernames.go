@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var errUsernamesUnsupported = errors.New("username claims are not supported")
+
+type usernameClaimer interface {
+	SetSelfUsername(username string) (string, bool, error)
+	SelfUsername() string
+	SetUsernameDisplay(mode string) (bool, error)
+	UsernameDisplay() string
+	ApplyUsernameClaim(card models.ContactCard, now time.Time) (models.Contact, []string, error)
+}
+
+// ClaimUsername sets or, with an empty username, withdraws this identity's
+// username claim and returns the self card carrying it, ready to be sent to
+// contacts.
+func (s *Service) ClaimUsername(username string) (models.ContactCard, error) {
+	claimer, ok := s.identityManager.(usernameClaimer)
+	if !ok {
+		return models.ContactCard{}, errUsernamesUnsupported
+	}
+	_, changed, err := claimer.SetSelfUsername(username)
+	if err != nil {
+		return models.ContactCard{}, err
+	}
+	if changed {
+		if err := s.identityState.Persist(s.identityManager); err != nil {
+			return models.ContactCard{}, err
+		}
+	}
+	return s.SelfCardForRefresh()
+}
+
+func (s *Service) SelfUsername() string {
+	claimer, ok := s.identityManager.(usernameClaimer)
+	if !ok {
+		return ""
+	}
+	return claimer.SelfUsername()
+}
+
+// SetUsernameDisplay stores how contact.list renders contact names.
+func (s *Service) SetUsernameDisplay(mode string) (string, error) {
+	claimer, ok := s.identityManager.(usernameClaimer)
+	if !ok {
+		return "", errUsernamesUnsupported
+	}
+	changed, err := claimer.SetUsernameDisplay(mode)
+	if err != nil {
+		return "", err
+	}
+	if changed {
+		if err := s.identityState.Persist(s.identityManager); err != nil {
+			return "", err
+		}
+	}
+	return claimer.UsernameDisplay(), nil
+}
+
+// ApplyUsernameClaim stores a username claim received from a verified
+// contact and returns the ids of contacts it newly put in conflict.
+func (s *Service) ApplyUsernameClaim(card models.ContactCard, now time.Time) (models.Contact, []string, error) {
+	claimer, ok := s.identityManager.(usernameClaimer)
+	if !ok {
+		return models.Contact{}, nil, errUsernamesUnsupported
+	}
+	contact, flagged, err := claimer.ApplyUsernameClaim(card, now)
+	if err != nil {
+		return models.Contact{}, nil, err
+	}
+	if err := s.identityState.Persist(s.identityManager); err != nil {
+		return models.Contact{}, nil, err
+	}
+	return contact, flagged, nil
+}
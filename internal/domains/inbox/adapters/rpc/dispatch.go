@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -9,7 +10,7 @@ import (
 	"aim-chat/go-backend/internal/domains/rpckit"
 )
 
-func Dispatch(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+func Dispatch(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
 	switch method {
 	case "request.list":
 		result, rpcErr := callWithoutParams(-32093, func() (any, error) {
@@ -23,7 +24,7 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 		return result, rpcErr, true
 	case "request.accept":
 		result, rpcErr := callWithSingleStringParam(rawParams, -32095, func(senderID string) (any, error) {
-			accepted, err := service.AcceptMessageRequest(senderID)
+			accepted, err := service.AcceptMessageRequest(ctx, senderID)
 			if err != nil {
 				return nil, err
 			}
@@ -4,7 +4,9 @@ import (
 	inboxmodel "aim-chat/go-backend/internal/domains/inbox/model"
 	messagingdomain "aim-chat/go-backend/internal/domains/messaging"
 	privacydomain "aim-chat/go-backend/internal/domains/privacy"
+	"aim-chat/go-backend/pkg/events"
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"errors"
 )
 
@@ -96,11 +98,17 @@ func (s *Service) GetMessageRequest(senderID string) (models.MessageRequestThrea
 	}, nil
 }
 
-func (s *Service) AcceptMessageRequest(senderID string) (bool, error) {
+// AcceptMessageRequest moves a request thread into the conversation list.
+// ctx is only checked up front: once the thread is taken the move completes,
+// so a cancelled caller never leaves it half moved.
+func (s *Service) AcceptMessageRequest(ctx context.Context, senderID string) (bool, error) {
 	senderID, err := messagingdomain.ValidateListMessagesContactID(senderID)
 	if err != nil {
 		return false, err
 	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
 
 	thread, exists, err := s.TakeThread(senderID)
 	if err != nil {
@@ -138,10 +146,7 @@ func (s *Service) AcceptMessageRequest(senderID string) (bool, error) {
 			return false, err
 		}
 		if s.Notify != nil {
-			s.Notify("notify.message.new", map[string]any{
-				"contact_id": senderID,
-				"message":    msg,
-			})
+			s.Notify(events.MethodMessageNew, events.MessageNew{ContactID: senderID, Message: msg})
 		}
 	}
 	if s.Notify != nil {
@@ -1,22 +1,55 @@
 package rpc
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"math"
 	"strings"
+	"time"
 
 	"aim-chat/go-backend/internal/domains/contracts"
 	"aim-chat/go-backend/internal/domains/rpckit"
+	"aim-chat/go-backend/pkg/models"
 )
 
 const (
 	maxMessageListLimit  = 1000
 	maxMessageListOffset = 1_000_000
+
+	messageListFieldsFull         = "full"
+	messageListFieldsMetadataOnly = "metadata_only"
 )
 
-func Dispatch(service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+type deadLetterService interface {
+	ListDeadLetters(contactID string, limit, offset int) []models.DeadLetter
+	RequeueDeadLetter(messageID string) (models.Message, error)
+}
+
+var errDeadLettersNotSupported = errors.New("dead-letter queue is not supported")
+
+type messagePinService interface {
+	PinMessage(messageID string) (models.MessagePin, error)
+	UnpinMessage(messageID string) (bool, error)
+	ListPinnedMessages(conversationID string) ([]models.PinnedMessage, error)
+}
+
+var errMessagePinsNotSupported = errors.New("message pins are not supported")
+
+type stateHashService interface {
+	StateHashes() (models.StateHashes, error)
+	ListStateDivergence() []models.StateDivergence
+	ExchangeStateHashes(contactID string) error
+}
+
+var errStateHashesNotSupported = errors.New("state hashes are not supported")
+
+type locationSender interface {
+	SendLocation(ctx context.Context, contactID string, location models.MessageLocation) (string, error)
+}
+
+func Dispatch(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
 	switch method {
 	case "session.init":
 		result, rpcErr := callWithSessionInitParams(rawParams, -32030, func(contactID string, peerPublicKey []byte) (any, error) {
@@ -24,17 +57,32 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 		})
 		return result, rpcErr, true
 	case "message.send":
-		result, rpcErr := callWithTwoStringParams(rawParams, -32040, func(contactID, content string) (any, error) {
-			messageID, err := service.SendMessage(contactID, content)
-			if err != nil {
-				return nil, err
-			}
-			return map[string]string{"message_id": messageID}, nil
-		})
-		return result, rpcErr, true
+		contactID, content, attachmentIDs, err := decodeSendMessageParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		messageID, err := sendMessage(ctx, service, contactID, content, attachmentIDs)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32040, err), true
+		}
+		return map[string]string{"message_id": messageID}, nil, true
+	case "message.send_location":
+		contactID, location, err := decodeSendLocationParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		sender, ok := service.(locationSender)
+		if !ok {
+			return nil, rpckit.ServiceError(-32384, errors.New("location messages are not supported")), true
+		}
+		messageID, err := sender.SendLocation(ctx, contactID, location)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32384, err), true
+		}
+		return map[string]string{"message_id": messageID}, nil, true
 	case "message.thread.send":
 		result, rpcErr := callWithThreadSendParams(rawParams, -32046, func(contactID, content, threadID string) (any, error) {
-			messageID, err := service.SendMessageInThread(contactID, content, threadID)
+			messageID, err := service.SendMessageInThread(ctx, contactID, content, threadID)
 			if err != nil {
 				return nil, err
 			}
@@ -54,6 +102,119 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 			return map[string]bool{"deleted": true}, nil
 		})
 		return result, rpcErr, true
+	case "message.cancel":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32310, func(messageID string) (any, error) {
+			canceller, ok := service.(interface {
+				CancelMessage(messageID string) (models.Message, error)
+			})
+			if !ok {
+				return nil, errors.New("message.cancel is not supported")
+			}
+			return canceller.CancelMessage(messageID)
+		})
+		return result, rpcErr, true
+	case "message.outbox":
+		categories, limit, offset, err := decodeOutboxListParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		lister, ok := service.(interface {
+			ListOutbox(categories []string, limit, offset int) ([]models.OutboxEntry, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32329, errors.New("message.outbox is not supported")), true
+		}
+		entries, err := lister.ListOutbox(categories, limit, offset)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32329, err), true
+		}
+		return map[string]any{"messages": entries}, nil, true
+	case "message.outbox.retry", "message.outbox.cancel":
+		var messageIDs []string
+		if err := json.Unmarshal(rawParams, &messageIDs); err != nil || len(messageIDs) == 0 {
+			return nil, rpckit.InvalidParams(), true
+		}
+		bulk, ok := service.(interface {
+			RetryMessages(messageIDs []string) (models.OutboxBulkResult, error)
+			CancelMessages(messageIDs []string) (models.OutboxBulkResult, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32330, errors.New("outbox bulk operations are not supported")), true
+		}
+		run := bulk.RetryMessages
+		if method == "message.outbox.cancel" {
+			run = bulk.CancelMessages
+		}
+		result, err := run(messageIDs)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32330, err), true
+		}
+		return result, nil, true
+	case "message.deadletter.list":
+		contactID, limit, offset, err := decodeDeadLetterListParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		queue, ok := service.(deadLetterService)
+		if !ok {
+			return nil, rpckit.ServiceError(-32373, errDeadLettersNotSupported), true
+		}
+		return map[string]any{"messages": queue.ListDeadLetters(contactID, limit, offset)}, nil, true
+	case "message.deadletter.requeue":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32374, func(messageID string) (any, error) {
+			queue, ok := service.(deadLetterService)
+			if !ok {
+				return nil, errDeadLettersNotSupported
+			}
+			return queue.RequeueDeadLetter(messageID)
+		})
+		return result, rpcErr, true
+	case "message.pin":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32377, func(messageID string) (any, error) {
+			pins, ok := service.(messagePinService)
+			if !ok {
+				return nil, errMessagePinsNotSupported
+			}
+			return pins.PinMessage(messageID)
+		})
+		return result, rpcErr, true
+	case "message.unpin":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32378, func(messageID string) (any, error) {
+			pins, ok := service.(messagePinService)
+			if !ok {
+				return nil, errMessagePinsNotSupported
+			}
+			removed, err := pins.UnpinMessage(messageID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]bool{"unpinned": removed}, nil
+		})
+		return result, rpcErr, true
+	case "message.pins.list":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32379, func(conversationID string) (any, error) {
+			pins, ok := service.(messagePinService)
+			if !ok {
+				return nil, errMessagePinsNotSupported
+			}
+			pinned, err := pins.ListPinnedMessages(conversationID)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"pins": pinned}, nil
+		})
+		return result, rpcErr, true
+	case "message.flush":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32331, func(contactID string) (any, error) {
+			flusher, ok := service.(interface {
+				FlushPendingMessages(contactID string) (models.PendingFlushResult, error)
+			})
+			if !ok {
+				return nil, errors.New("message.flush is not supported")
+			}
+			return flusher.FlushPendingMessages(contactID)
+		})
+		return result, rpcErr, true
 	case "message.clear":
 		result, rpcErr := callWithSingleStringParam(rawParams, -32045, func(contactID string) (any, error) {
 			cleared, err := service.ClearMessages(contactID)
@@ -64,25 +225,318 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 		})
 		return result, rpcErr, true
 	case "message.list":
-		result, rpcErr := callWithMessageListParams(rawParams, -32041, func(contactID string, limit, offset int) (any, error) {
+		result, rpcErr := callWithMessageListParams(rawParams, -32041, func(contactID string, limit, offset int, fields string) (any, error) {
+			if fields == messageListFieldsMetadataOnly {
+				return getMessageSummaries(service, contactID, limit, offset)
+			}
 			return service.GetMessages(contactID, limit, offset)
 		})
 		return result, rpcErr, true
+	case "message.search":
+		params, err := decodeMessageSearchParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		searcher, ok := service.(interface {
+			SearchMessagesInRange(contactID, query string, since, until time.Time, limit, offset int) (models.MessageSearchResult, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32355, errors.New("message search is not supported")), true
+		}
+		result, err := searcher.SearchMessagesInRange(params.ConversationID, params.Query, params.Since, params.Until, params.Limit, params.Offset)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32355, err), true
+		}
+		return result, nil, true
+	case "message.get":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32301, func(messageID string) (any, error) {
+			getter, ok := service.(interface {
+				GetMessage(messageID string) (models.Message, error)
+			})
+			if !ok {
+				return nil, errors.New("message.get is not supported")
+			}
+			return getter.GetMessage(messageID)
+		})
+		return result, rpcErr, true
 	case "message.thread.list":
 		result, rpcErr := callWithThreadListParams(rawParams, -32047, func(contactID, threadID string, limit, offset int) (any, error) {
 			return service.GetMessagesByThread(contactID, threadID, limit, offset)
 		})
 		return result, rpcErr, true
 	case "message.status":
-		result, rpcErr := callWithSingleStringParam(rawParams, -32042, func(messageID string) (any, error) {
-			return service.GetMessageStatus(messageID)
+		messageID, includeMembers, err := decodeMessageStatusParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, err := getMessageStatus(service, messageID, includeMembers)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32042, err), true
+		}
+		return result, nil, true
+	case "chat.security_info":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32300, func(contactID string) (any, error) {
+			reporter, ok := service.(interface {
+				GetChatSecurityInfo(contactID string) (models.ChatSecurityInfo, error)
+			})
+			if !ok {
+				return nil, errors.New("chat security info is not supported")
+			}
+			return reporter.GetChatSecurityInfo(contactID)
 		})
 		return result, rpcErr, true
+	case "chat.flags.list":
+		lister, ok := service.(interface {
+			ListConversationFlags() []models.ConversationFlags
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32317, errors.New("conversation flags are not supported")), true
+		}
+		return map[string]any{"conversations": lister.ListConversationFlags()}, nil, true
+	case "chat.flags.set":
+		conversationID, update, err := decodeConversationFlagsParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		setter, ok := service.(interface {
+			SetConversationFlags(conversationID string, update models.ConversationFlagsUpdate) (models.ConversationFlags, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32318, errors.New("conversation flags are not supported")), true
+		}
+		flags, err := setter.SetConversationFlags(conversationID, update)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32318, err), true
+		}
+		return flags, nil, true
+	case "state.hashes":
+		hasher, ok := service.(stateHashService)
+		if !ok {
+			return nil, rpckit.ServiceError(-32382, errStateHashesNotSupported), true
+		}
+		hashes, err := hasher.StateHashes()
+		if err != nil {
+			return nil, rpckit.ServiceError(-32382, err), true
+		}
+		return map[string]any{"local": hashes, "divergence": hasher.ListStateDivergence()}, nil, true
+	case "state.hashes.exchange":
+		contactID, err := decodeOptionalContactIDParam(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		hasher, ok := service.(stateHashService)
+		if !ok {
+			return nil, rpckit.ServiceError(-32383, errStateHashesNotSupported), true
+		}
+		if err := hasher.ExchangeStateHashes(contactID); err != nil {
+			return nil, rpckit.ServiceError(-32383, err), true
+		}
+		return map[string]bool{"sent": true}, nil, true
+	case "chat.list":
+		lister, ok := service.(interface {
+			ListChats() ([]models.ChatSummary, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32319, errors.New("chat list is not supported")), true
+		}
+		chats, err := lister.ListChats()
+		if err != nil {
+			return nil, rpckit.ServiceError(-32319, err), true
+		}
+		return map[string]any{"chats": chats}, nil, true
+	case "chat.language.set":
+		conversationID, language, err := decodeChatLanguageParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		setter, ok := service.(interface {
+			SetChatLanguage(conversationID, language string) (models.ConversationFlags, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32320, errors.New("chat language is not supported")), true
+		}
+		flags, err := setter.SetChatLanguage(conversationID, language)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32320, err), true
+		}
+		return flags, nil, true
+	case "chat.media.list":
+		conversationID, class, limit, offset, err := decodeChatMediaListParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		lister, ok := service.(interface {
+			ListChatMedia(conversationID, class string, limit, offset int) ([]models.ChatMediaItem, error)
+		})
+		if !ok {
+			return nil, rpckit.ServiceError(-32354, errors.New("chat media listing is not supported")), true
+		}
+		items, err := lister.ListChatMedia(conversationID, class, limit, offset)
+		if err != nil {
+			return nil, rpckit.ServiceError(-32354, err), true
+		}
+		return map[string]any{"items": items}, nil, true
 	default:
 		return nil, nil, false
 	}
 }
 
+// decodeMessageSearchParams accepts [contact_id, query, limit, offset] or
+// {contact_id, query, since, until, limit, offset}. An empty contact_id
+// searches every conversation; since and until are RFC 3339 timestamps.
+func decodeMessageSearchParams(raw json.RawMessage) (models.MessageSearchQuery, error) {
+	var params models.MessageSearchQuery
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err == nil {
+		if len(arr) != 4 {
+			return models.MessageSearchQuery{}, errors.New("invalid params")
+		}
+		contactID, okContact := arr[0].(string)
+		query, okQuery := arr[1].(string)
+		limit, errLimit := decodeStrictNonNegativeInt(arr[2])
+		offset, errOffset := decodeStrictNonNegativeInt(arr[3])
+		if !okContact || !okQuery || errLimit != nil || errOffset != nil {
+			return models.MessageSearchQuery{}, errors.New("invalid params")
+		}
+		params = models.MessageSearchQuery{ConversationID: contactID, Query: query, Limit: limit, Offset: offset}
+	} else {
+		var payload struct {
+			ContactID string    `json:"contact_id"`
+			Query     string    `json:"query"`
+			Since     time.Time `json:"since"`
+			Until     time.Time `json:"until"`
+			Limit     int       `json:"limit"`
+			Offset    int       `json:"offset"`
+		}
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return models.MessageSearchQuery{}, errors.New("invalid params")
+		}
+		params = models.MessageSearchQuery{
+			ConversationID: payload.ContactID,
+			Query:          payload.Query,
+			Since:          payload.Since,
+			Until:          payload.Until,
+			Limit:          payload.Limit,
+			Offset:         payload.Offset,
+		}
+	}
+	params.ConversationID = strings.TrimSpace(params.ConversationID)
+	if strings.TrimSpace(params.Query) == "" || params.Limit < 0 || params.Offset < 0 ||
+		params.Limit > maxMessageListLimit || params.Offset > maxMessageListOffset {
+		return models.MessageSearchQuery{}, errors.New("invalid params")
+	}
+	return params, nil
+}
+
+// decodeChatMediaListParams accepts [conversation_id, class, limit, offset];
+// an empty class lists images and files together.
+func decodeChatMediaListParams(raw json.RawMessage) (string, string, int, int, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 4 {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	conversationID, ok := arr[0].(string)
+	if !ok || strings.TrimSpace(conversationID) == "" {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	class, ok := arr[1].(string)
+	if !ok {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	limit, err := decodeStrictNonNegativeInt(arr[2])
+	if err != nil {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	offset, err := decodeStrictNonNegativeInt(arr[3])
+	if err != nil {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	if limit > maxMessageListLimit || offset > maxMessageListOffset {
+		return "", "", 0, 0, errors.New("invalid params")
+	}
+	return strings.TrimSpace(conversationID), strings.TrimSpace(class), limit, offset, nil
+}
+
+// decodeConversationFlagsParams accepts {conversation_id, pinned, archived,
+// muted, draft}; omitted flags stay unchanged.
+func decodeConversationFlagsParams(raw json.RawMessage) (string, models.ConversationFlagsUpdate, error) {
+	var payload struct {
+		ConversationID string `json:"conversation_id"`
+		models.ConversationFlagsUpdate
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil || strings.TrimSpace(payload.ConversationID) == "" {
+		return "", models.ConversationFlagsUpdate{}, errors.New("invalid params")
+	}
+	return payload.ConversationID, payload.ConversationFlagsUpdate, nil
+}
+
+// decodeChatLanguageParams accepts [conversation_id, language]; an empty
+// language clears the override and falls back to detection.
+func decodeChatLanguageParams(raw json.RawMessage) (string, string, error) {
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 || strings.TrimSpace(arr[0]) == "" {
+		return "", "", errors.New("invalid params")
+	}
+	return arr[0], arr[1], nil
+}
+
+// DispatchGuest serves guest callers. A guest reads on the owner's behalf,
+// so message.list must not mark messages read or send read receipts.
+func DispatchGuest(ctx context.Context, service contracts.DaemonService, method string, rawParams json.RawMessage) (any, *rpckit.Error, bool) {
+	if method != "message.list" {
+		return Dispatch(ctx, service, method, rawParams)
+	}
+	result, rpcErr := callWithMessageListParams(rawParams, -32041, func(contactID string, limit, offset int, fields string) (any, error) {
+		if fields == messageListFieldsMetadataOnly {
+			return getMessageSummaries(service, contactID, limit, offset)
+		}
+		peeker, ok := service.(interface {
+			PeekMessages(contactID string, limit, offset int) ([]models.Message, error)
+		})
+		if !ok {
+			return nil, errors.New("guest message listing is not supported")
+		}
+		return peeker.PeekMessages(contactID, limit, offset)
+	})
+	return result, rpcErr, true
+}
+
+func getMessageSummaries(service contracts.DaemonService, contactID string, limit, offset int) (any, error) {
+	lister, ok := service.(interface {
+		GetMessageSummaries(contactID string, limit, offset int) ([]models.MessageSummary, error)
+	})
+	if !ok {
+		return nil, errors.New("metadata-only message listing is not supported")
+	}
+	return lister.GetMessageSummaries(contactID, limit, offset)
+}
+
+func getMessageStatus(service contracts.DaemonService, messageID string, includeMembers bool) (any, error) {
+	if !includeMembers {
+		return service.GetMessageStatus(messageID)
+	}
+	receipts, ok := service.(interface {
+		GetMessageStatusWithMembers(messageID string) (models.MessageStatus, error)
+	})
+	if !ok {
+		return nil, errors.New("per-member message receipts are not supported")
+	}
+	return receipts.GetMessageStatusWithMembers(messageID)
+}
+
+func sendMessage(ctx context.Context, service contracts.DaemonService, contactID, content string, attachmentIDs []string) (string, error) {
+	if len(attachmentIDs) == 0 {
+		return service.SendMessage(ctx, contactID, content)
+	}
+	sender, ok := service.(interface {
+		SendMessageWithAttachments(ctx context.Context, contactID, content string, attachmentIDs []string) (string, error)
+	})
+	if !ok {
+		return "", errors.New("message attachments are not supported")
+	}
+	return sender.SendMessageWithAttachments(ctx, contactID, content, attachmentIDs)
+}
+
 func callWithSingleStringParam(rawParams json.RawMessage, serviceErrCode int, call func(string) (any, error)) (any, *rpckit.Error) {
 	param, err := decodeSingleStringParam(rawParams)
 	if err != nil {
@@ -142,13 +596,13 @@ func callWithMessageEditParams(
 func callWithMessageListParams(
 	rawParams json.RawMessage,
 	serviceErrCode int,
-	call func(contactID string, limit, offset int) (any, error),
+	call func(contactID string, limit, offset int, fields string) (any, error),
 ) (any, *rpckit.Error) {
-	contactID, limit, offset, err := decodeMessageListParams(rawParams)
+	contactID, limit, offset, fields, err := decodeMessageListParams(rawParams)
 	if err != nil {
 		return nil, rpckit.InvalidParams()
 	}
-	result, err := call(contactID, limit, offset)
+	result, err := call(contactID, limit, offset, fields)
 	if err != nil {
 		return nil, rpckit.ServiceError(serviceErrCode, err)
 	}
@@ -195,6 +649,26 @@ func decodeSingleStringParam(raw json.RawMessage) (string, error) {
 	return "", errors.New("invalid params")
 }
 
+// decodeMessageStatusParams accepts [message_id] with an optional trailing
+// include_members flag.
+func decodeMessageStatusParams(raw json.RawMessage) (string, bool, error) {
+	var arr []any
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) < 1 || len(arr) > 2 {
+		return "", false, errors.New("invalid params")
+	}
+	messageID, ok := arr[0].(string)
+	if !ok || strings.TrimSpace(messageID) == "" {
+		return "", false, errors.New("invalid params")
+	}
+	includeMembers := false
+	if len(arr) == 2 {
+		if includeMembers, ok = arr[1].(bool); !ok {
+			return "", false, errors.New("invalid params")
+		}
+	}
+	return messageID, includeMembers, nil
+}
+
 func decodeTwoStringParams(raw json.RawMessage) (string, string, error) {
 	var arr []string
 	if err := json.Unmarshal(raw, &arr); err == nil && len(arr) == 2 && strings.TrimSpace(arr[0]) != "" && strings.TrimSpace(arr[1]) != "" {
@@ -203,6 +677,63 @@ func decodeTwoStringParams(raw json.RawMessage) (string, string, error) {
 	return "", "", errors.New("invalid params")
 }
 
+// decodeSendMessageParams accepts [contact_id, content] with an optional third
+// list of attachment ids.
+func decodeSendMessageParams(raw json.RawMessage) (string, string, []string, error) {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || (len(arr) != 2 && len(arr) != 3) {
+		return "", "", nil, errors.New("invalid params")
+	}
+	var contactID, content string
+	if json.Unmarshal(arr[0], &contactID) != nil || json.Unmarshal(arr[1], &content) != nil {
+		return "", "", nil, errors.New("invalid params")
+	}
+	if strings.TrimSpace(contactID) == "" || strings.TrimSpace(content) == "" {
+		return "", "", nil, errors.New("invalid params")
+	}
+	if len(arr) == 2 {
+		return contactID, content, nil, nil
+	}
+	var attachmentIDs []string
+	if err := json.Unmarshal(arr[2], &attachmentIDs); err != nil {
+		return "", "", nil, errors.New("invalid params")
+	}
+	for _, id := range attachmentIDs {
+		if strings.TrimSpace(id) == "" {
+			return "", "", nil, errors.New("invalid params")
+		}
+	}
+	return contactID, content, attachmentIDs, nil
+}
+
+// decodeSendLocationParams reads [contact_id, {lat, lng, accuracy?, label?}].
+// Both coordinates must be given; a missing one is not taken as zero.
+func decodeSendLocationParams(raw json.RawMessage) (string, models.MessageLocation, error) {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 {
+		return "", models.MessageLocation{}, errors.New("invalid params")
+	}
+	var contactID string
+	var payload struct {
+		Latitude       *float64 `json:"lat"`
+		Longitude      *float64 `json:"lng"`
+		AccuracyMeters float64  `json:"accuracy"`
+		Label          string   `json:"label"`
+	}
+	if json.Unmarshal(arr[0], &contactID) != nil || json.Unmarshal(arr[1], &payload) != nil {
+		return "", models.MessageLocation{}, errors.New("invalid params")
+	}
+	if strings.TrimSpace(contactID) == "" || payload.Latitude == nil || payload.Longitude == nil {
+		return "", models.MessageLocation{}, errors.New("invalid params")
+	}
+	return contactID, models.MessageLocation{
+		Latitude:       *payload.Latitude,
+		Longitude:      *payload.Longitude,
+		AccuracyMeters: payload.AccuracyMeters,
+		Label:          payload.Label,
+	}, nil
+}
+
 func decodeSessionInitParams(raw json.RawMessage) (string, []byte, error) {
 	var arr []string
 	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 2 || strings.TrimSpace(arr[0]) == "" || strings.TrimSpace(arr[1]) == "" {
@@ -226,27 +757,132 @@ func decodeMessageEditParams(raw json.RawMessage) (string, string, string, error
 	return arr[0], arr[1], arr[2], nil
 }
 
-func decodeMessageListParams(raw json.RawMessage) (string, int, int, error) {
+// decodeMessageListParams accepts [contact_id, limit, offset] with an optional
+// fourth fields selector: "full" (default) or "metadata_only".
+func decodeMessageListParams(raw json.RawMessage) (string, int, int, string, error) {
 	var arr []any
-	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) != 3 {
-		return "", 0, 0, errors.New("invalid params")
+	if err := json.Unmarshal(raw, &arr); err != nil || (len(arr) != 3 && len(arr) != 4) {
+		return "", 0, 0, "", errors.New("invalid params")
 	}
 	contactID, ok := arr[0].(string)
 	if !ok || strings.TrimSpace(contactID) == "" {
-		return "", 0, 0, errors.New("invalid params")
+		return "", 0, 0, "", errors.New("invalid params")
 	}
 	limit, err := decodeStrictNonNegativeInt(arr[1])
 	if err != nil {
-		return "", 0, 0, errors.New("invalid params")
+		return "", 0, 0, "", errors.New("invalid params")
 	}
 	offset, err := decodeStrictNonNegativeInt(arr[2])
 	if err != nil {
+		return "", 0, 0, "", errors.New("invalid params")
+	}
+	if limit > maxMessageListLimit || offset > maxMessageListOffset {
+		return "", 0, 0, "", errors.New("invalid params")
+	}
+	fields := messageListFieldsFull
+	if len(arr) == 4 {
+		raw, ok := arr[3].(string)
+		if !ok {
+			return "", 0, 0, "", errors.New("invalid params")
+		}
+		switch strings.TrimSpace(raw) {
+		case "", messageListFieldsFull:
+		case messageListFieldsMetadataOnly:
+			fields = messageListFieldsMetadataOnly
+		default:
+			return "", 0, 0, "", errors.New("invalid params")
+		}
+	}
+	return contactID, limit, offset, fields, nil
+}
+
+// decodeOutboxListParams accepts no params or [categories, limit, offset];
+// an empty category list selects every category.
+func decodeOutboxListParams(raw json.RawMessage) ([]string, int, int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, 0, 0, nil
+	}
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) > 3 {
+		return nil, 0, 0, errors.New("invalid params")
+	}
+	var categories []string
+	if len(arr) > 0 {
+		if err := json.Unmarshal(arr[0], &categories); err != nil {
+			return nil, 0, 0, errors.New("invalid params")
+		}
+	}
+	var limit, offset int
+	for i, dst := range []*int{&limit, &offset} {
+		if len(arr) <= i+1 {
+			break
+		}
+		var v any
+		if err := json.Unmarshal(arr[i+1], &v); err != nil {
+			return nil, 0, 0, errors.New("invalid params")
+		}
+		n, err := decodeStrictNonNegativeInt(v)
+		if err != nil {
+			return nil, 0, 0, errors.New("invalid params")
+		}
+		*dst = n
+	}
+	if limit > maxMessageListLimit || offset > maxMessageListOffset {
+		return nil, 0, 0, errors.New("invalid params")
+	}
+	return categories, limit, offset, nil
+}
+
+// decodeOptionalContactIDParam reads no params or [contact_id].
+func decodeOptionalContactIDParam(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var arr []string
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) > 1 {
+		return "", errors.New("invalid params")
+	}
+	if len(arr) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(arr[0]), nil
+}
+
+// decodeDeadLetterListParams reads [contact_id?, limit?, offset?]; an empty
+// contact id lists every contact.
+func decodeDeadLetterListParams(raw json.RawMessage) (string, int, int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", 0, 0, nil
+	}
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil || len(arr) > 3 {
 		return "", 0, 0, errors.New("invalid params")
 	}
+	var contactID string
+	if len(arr) > 0 {
+		if err := json.Unmarshal(arr[0], &contactID); err != nil {
+			return "", 0, 0, errors.New("invalid params")
+		}
+	}
+	var limit, offset int
+	for i, dst := range []*int{&limit, &offset} {
+		if len(arr) <= i+1 {
+			break
+		}
+		var v any
+		if err := json.Unmarshal(arr[i+1], &v); err != nil {
+			return "", 0, 0, errors.New("invalid params")
+		}
+		n, err := decodeStrictNonNegativeInt(v)
+		if err != nil {
+			return "", 0, 0, errors.New("invalid params")
+		}
+		*dst = n
+	}
 	if limit > maxMessageListLimit || offset > maxMessageListOffset {
 		return "", 0, 0, errors.New("invalid params")
 	}
-	return contactID, limit, offset, nil
+	return strings.TrimSpace(contactID), limit, offset, nil
 }
 
 func decodeThreadSendParams(raw json.RawMessage) (string, string, string, error) {
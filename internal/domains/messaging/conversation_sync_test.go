@@ -0,0 +1,76 @@
+package messaging_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestMergeConversationSyncStateConverges(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	pinned, archived := true, true
+	draft := "half-written"
+
+	phone := messagingapp.BuildConversationSyncUpdate("aim1peer", models.ConversationFlagsUpdate{Pinned: &pinned, Draft: &draft}, "dev-a", base)
+	unpin := false
+	laptop := messagingapp.BuildConversationSyncUpdate("aim1peer", models.ConversationFlagsUpdate{Pinned: &unpin, Archived: &archived}, "dev-b", base)
+
+	left, changed := messagingapp.MergeConversationSyncState(phone, laptop)
+	if !changed {
+		t.Fatal("merging a concurrent update must change the local state")
+	}
+	right, _ := messagingapp.MergeConversationSyncState(laptop, phone)
+	leftFlags := messagingapp.ResolveConversationFlags(left)
+	rightFlags := messagingapp.ResolveConversationFlags(right)
+	if leftFlags != rightFlags {
+		t.Fatalf("merge must be order independent: %+v vs %+v", leftFlags, rightFlags)
+	}
+	// Equal timestamps resolve to the larger device id.
+	if leftFlags.Pinned || !leftFlags.Archived || leftFlags.Draft != draft {
+		t.Fatalf("unexpected merged flags: %+v", leftFlags)
+	}
+
+	if _, changed := messagingapp.MergeConversationSyncState(left, phone); changed {
+		t.Fatal("re-applying an older state must be a no-op")
+	}
+	repin := messagingapp.BuildConversationSyncUpdate("aim1peer", models.ConversationFlagsUpdate{Pinned: &pinned}, "dev-a", base.Add(time.Second))
+	later, _ := messagingapp.MergeConversationSyncState(left, repin)
+	if !messagingapp.ResolveConversationFlags(later).Pinned {
+		t.Fatal("a later write must win regardless of device id")
+	}
+}
+
+func TestValidateWirePayloadDeviceSync(t *testing.T) {
+	valid := contracts.WirePayload{
+		Kind: messagingapp.WireKindDeviceSync,
+		ConversationSync: []models.ConversationSyncState{{
+			ConversationID: "aim1peer",
+			Registers: map[string]models.SyncRegister{
+				models.ConversationFlagMuted: {Value: "true", UpdatedAt: time.Now(), DeviceID: "dev-a"},
+			},
+		}},
+	}
+	if err := messagingapp.ValidateWirePayload(valid); err != nil {
+		t.Fatalf("device sync payload must be valid, got %v", err)
+	}
+
+	unknown := valid
+	unknown.ConversationSync = []models.ConversationSyncState{{
+		ConversationID: "aim1peer",
+		Registers: map[string]models.SyncRegister{
+			"color": {Value: "red", UpdatedAt: time.Now(), DeviceID: "dev-a"},
+		},
+	}}
+	if err := messagingapp.ValidateWirePayload(unknown); !errors.Is(err, messagingapp.ErrInvalidConversationSync) {
+		t.Fatalf("expected ErrInvalidConversationSync for unknown register, got %v", err)
+	}
+
+	smuggled := contracts.WirePayload{Kind: "plain", Plain: []byte("hi"), ConversationSync: valid.ConversationSync}
+	if err := messagingapp.ValidateWirePayload(smuggled); !errors.Is(err, messagingapp.ErrInvalidConversationSync) {
+		t.Fatalf("expected ErrInvalidConversationSync for non-sync kind, got %v", err)
+	}
+}
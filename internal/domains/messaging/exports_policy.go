@@ -8,10 +8,190 @@ import (
 )
 
 var ErrOutboundSessionRequired = messagingpolicy.ErrOutboundSessionRequired
+var ErrConversationReadOnly = messagingpolicy.ErrConversationReadOnly
+var ErrContactNotAdded = messagingpolicy.ErrContactNotAdded
+var ErrContactBlocked = messagingpolicy.ErrContactBlocked
 var ErrInvalidGroupWirePayload = messagingpolicy.ErrInvalidGroupWirePayload
+var ErrInboundWireTooLarge = messagingpolicy.ErrInboundWireTooLarge
+var ErrInboundPlaintextTooLarge = messagingpolicy.ErrInboundPlaintextTooLarge
+var ErrInboundKindNotAllowed = messagingpolicy.ErrInboundKindNotAllowed
+var KnownInboundWireKinds = messagingpolicy.KnownInboundWireKinds
+
+type InboundLimits = messagingpolicy.InboundLimits
+
+const (
+	DefaultInboundMaxWireBytes      = messagingpolicy.DefaultInboundMaxWireBytes
+	DefaultInboundMaxPlaintextBytes = messagingpolicy.DefaultInboundMaxPlaintextBytes
+)
 
 const GroupWireEventTypeMessage = messagingpolicy.GroupWireEventTypeMessage
 
+const (
+	WireKindCardRequest   = messagingpolicy.WireKindCardRequest
+	WireKindCardResponse  = messagingpolicy.WireKindCardResponse
+	WireKindUsernameClaim = messagingpolicy.WireKindUsernameClaim
+	WireKindDeviceSync    = messagingpolicy.WireKindDeviceSync
+)
+
+var (
+	ErrInvalidSnippet      = messagingpolicy.ErrInvalidSnippet
+	ErrSnippetValueMissing = messagingpolicy.ErrSnippetValueMissing
+)
+
+var ErrInvalidAttachmentPolicy = messagingpolicy.ErrInvalidAttachmentPolicy
+
+var ErrInvalidRetryPolicy = messagingpolicy.ErrInvalidRetryPolicy
+
+func DefaultRetryPolicy() models.RetryPolicy {
+	return messagingpolicy.DefaultRetryPolicy()
+}
+
+func NormalizeRetryPolicy(policy models.RetryPolicy) (models.RetryPolicy, error) {
+	return messagingpolicy.NormalizeRetryPolicy(policy)
+}
+
+func RetryBackoff(policy models.RetryPolicy, retryCount int, jitter float64) time.Duration {
+	return messagingpolicy.RetryBackoff(policy, retryCount, jitter)
+}
+
+func NormalizeAttachmentPolicy(policy models.ContactAttachmentPolicy) (models.ContactAttachmentPolicy, error) {
+	return messagingpolicy.NormalizeAttachmentPolicy(policy)
+}
+
+func IsDefaultAttachmentPolicy(policy models.ContactAttachmentPolicy) bool {
+	return messagingpolicy.IsDefaultAttachmentPolicy(policy)
+}
+
+func ApplyAttachmentPolicy(policy models.ContactAttachmentPolicy, attachments []models.MessageAttachment) (kept, held, dropped []models.MessageAttachment) {
+	return messagingpolicy.ApplyAttachmentPolicy(policy, attachments)
+}
+
+func NewCoverTrafficContent(filler []byte) []byte {
+	return messagingpolicy.NewCoverTrafficContent(filler)
+}
+
+func IsCoverTrafficContent(content []byte) bool {
+	return messagingpolicy.IsCoverTrafficContent(content)
+}
+
+func ValidateSnippet(name, body string) error {
+	return messagingpolicy.ValidateSnippet(name, body)
+}
+
+func SnippetPlaceholders(body string) []string {
+	return messagingpolicy.SnippetPlaceholders(body)
+}
+
+func ExpandSnippet(body string, values map[string]string) (string, error) {
+	return messagingpolicy.ExpandSnippet(body, values)
+}
+
+var ErrInvalidSavedMessage = messagingpolicy.ErrInvalidSavedMessage
+
+func BuildStoredSavedMessage(note models.SavedMessage, selfID string, now time.Time) models.Message {
+	return messagingpolicy.BuildStoredSavedMessage(note, selfID, now)
+}
+
+const WireKindHistoryBackfill = messagingpolicy.WireKindHistoryBackfill
+
+var ErrInvalidHistoryBackfill = messagingpolicy.ErrInvalidHistoryBackfill
+
+func ValidateHistoryBackfill(req *models.HistoryBackfillRequest) error {
+	return messagingpolicy.ValidateHistoryBackfill(req)
+}
+
+const WireKindMessagePin = messagingpolicy.WireKindMessagePin
+
+var ErrInvalidMessagePin = messagingpolicy.ErrInvalidMessagePin
+
+const WireKindStateHash = messagingpolicy.WireKindStateHash
+
+var ErrInvalidStateHashes = messagingpolicy.ErrInvalidStateHashes
+
+func ConversationStateHashes(messages map[string]models.Message) []models.ConversationStateHash {
+	return messagingpolicy.ConversationStateHashes(messages)
+}
+
+func SettingsStateRoot(values ...any) (string, error) {
+	return messagingpolicy.SettingsStateRoot(values...)
+}
+
+func StateRoot(settingsRoot string, conversations []models.ConversationStateHash) string {
+	return messagingpolicy.StateRoot(settingsRoot, conversations)
+}
+
+func DiffStateHashes(local, remote models.StateHashes) (bool, []string) {
+	return messagingpolicy.DiffStateHashes(local, remote)
+}
+
+const WireKindExportConsent = messagingpolicy.WireKindExportConsent
+
+var ErrInvalidExportConsent = messagingpolicy.ErrInvalidExportConsent
+
+func ValidateExportConsent(consent *models.ExportConsent) error {
+	return messagingpolicy.ValidateExportConsent(consent)
+}
+
+var ErrInvalidMessageLocation = messagingpolicy.ErrInvalidMessageLocation
+
+func EncodeMessageLocation(location models.MessageLocation) ([]byte, error) {
+	return messagingpolicy.EncodeMessageLocation(location)
+}
+
+func DecodeMessageLocation(content []byte) (models.MessageLocation, error) {
+	return messagingpolicy.DecodeMessageLocation(content)
+}
+
+func ObserveInboundSeq(state models.InboundSequence, deviceID string, seq uint64, now time.Time) (models.InboundSequence, bool) {
+	return messagingpolicy.ObserveInboundSeq(state, deviceID, seq, now)
+}
+
+func DueBackfillRanges(gaps []models.HistoryGap, now time.Time, grace, retryAfter time.Duration) []models.SeqRange {
+	return messagingpolicy.DueBackfillRanges(gaps, now, grace, retryAfter)
+}
+
+func MarkBackfillRequested(state models.InboundSequence, ranges []models.SeqRange, now time.Time) models.InboundSequence {
+	return messagingpolicy.MarkBackfillRequested(state, ranges, now)
+}
+
+var ErrInvalidConversationSync = messagingpolicy.ErrInvalidConversationSync
+
+func MergeConversationSyncState(local, remote models.ConversationSyncState) (models.ConversationSyncState, bool) {
+	return messagingpolicy.MergeConversationSyncState(local, remote)
+}
+
+func BuildConversationSyncUpdate(conversationID string, update models.ConversationFlagsUpdate, deviceID string, now time.Time) models.ConversationSyncState {
+	return messagingpolicy.BuildConversationSyncUpdate(conversationID, update, deviceID, now)
+}
+
+func ResolveConversationFlags(state models.ConversationSyncState) models.ConversationFlags {
+	return messagingpolicy.ResolveConversationFlags(state)
+}
+
+func ValidateConversationSync(states []models.ConversationSyncState) error {
+	return messagingpolicy.ValidateConversationSync(states)
+}
+
+var ErrInvalidLanguageTag = messagingpolicy.ErrInvalidLanguageTag
+
+const LanguageDetectionSampleSize = messagingpolicy.LanguageDetectionSampleSize
+
+func NotificationHintsFromFlags(flags models.ConversationFlags) models.NotificationHints {
+	return messagingpolicy.NotificationHintsFromFlags(flags)
+}
+
+func NormalizeLanguageTag(raw string) (string, error) {
+	return messagingpolicy.NormalizeLanguageTag(raw)
+}
+
+func DetectLanguage(texts []string) string {
+	return messagingpolicy.DetectLanguage(texts)
+}
+
+func ResolveChatLanguage(manual string, recentTexts []string) (string, string) {
+	return messagingpolicy.ResolveChatLanguage(manual, recentTexts)
+}
+
 func ValidateEditMessageInput(contactID, messageID, content string) (string, string, string, error) {
 	return messagingpolicy.ValidateEditMessageInput(contactID, messageID, content)
 }
@@ -51,3 +231,7 @@ func EnsureVerifiedContact(verified bool) error {
 func ValidateWirePayload(wire contracts.WirePayload) error {
 	return messagingpolicy.ValidateWirePayload(wire)
 }
+
+func CheckInboundPayload(limits InboundLimits, payload []byte, wire contracts.WirePayload, parsed bool) error {
+	return messagingpolicy.CheckInboundPayload(limits, payload, wire, parsed)
+}
@@ -96,6 +96,18 @@ func NewReceiptWire(messageID, status string, now time.Time) contracts.WirePaylo
 	return messagingusecase.NewReceiptWire(messageID, status, now)
 }
 
+func NewCardRequestWire() contracts.WirePayload {
+	return messagingusecase.NewCardRequestWire()
+}
+
+func NewCardResponseWire(card models.ContactCard) contracts.WirePayload {
+	return messagingusecase.NewCardResponseWire(card)
+}
+
+func NewUsernameClaimWire(card models.ContactCard) contracts.WirePayload {
+	return messagingusecase.NewUsernameClaimWire(card)
+}
+
 func ProcessPendingMessages(ctx context.Context, pending []storage.PendingMessage, buildWire func(models.Message) (contracts.WirePayload, error), publish func(context.Context, string, string, contracts.WirePayload) error, onPublishError func(storage.PendingMessage, error), onPublished func(string)) {
 	converted := make([]messagingusecase.PendingMessage, len(pending))
 	for i := range pending {
@@ -126,6 +138,25 @@ func ProcessPendingMessages(ctx context.Context, pending []storage.PendingMessag
 	)
 }
 
+var ErrInvalidOutboxCategory = messagingusecase.ErrInvalidOutboxCategory
+
+func ParseOutboxCategories(raw []string) (map[string]bool, error) {
+	return messagingusecase.ParseOutboxCategories(raw)
+}
+
+func BuildOutbox(messages []models.Message, pending map[string]storage.PendingMessage, categories map[string]bool, now time.Time, limit, offset int) []models.OutboxEntry {
+	converted := make(map[string]messagingusecase.PendingMessage, len(pending))
+	for id, p := range pending {
+		converted[id] = messagingusecase.PendingMessage{
+			Message:    p.Message,
+			RetryCount: p.RetryCount,
+			NextRetry:  p.NextRetry,
+			LastError:  p.LastError,
+		}
+	}
+	return messagingusecase.BuildOutbox(messages, converted, categories, now, limit, offset)
+}
+
 func ComposeSignedPrivateMessage(messageID, recipient string, wire contracts.WirePayload, identity interface {
 	GetIdentity() models.Identity
 	ActiveDeviceAuth(payload []byte) (models.Device, []byte, error)
@@ -133,12 +164,12 @@ func ComposeSignedPrivateMessage(messageID, recipient string, wire contracts.Wir
 	return messagingusecase.ComposeSignedPrivateMessage(messageID, recipient, wire, identity)
 }
 
-func ErrorCategory(err error) string {
-	return messagingusecase.ErrorCategory(err)
+func IsDirectChatWire(wire contracts.WirePayload) bool {
+	return messagingusecase.IsDirectChatWire(wire)
 }
 
-func NextRetryTime(retryCount int) time.Time {
-	return messagingusecase.NextRetryTime(retryCount)
+func ErrorCategory(err error) string {
+	return messagingusecase.ErrorCategory(err)
 }
 
 func DispatchDeviceRevocation(localIdentityID string, contacts []models.Contact, payload []byte, nextID func() (string, error), publish func(msg waku.PrivateMessage) error) []RevocationFailure {
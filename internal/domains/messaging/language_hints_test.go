@@ -0,0 +1,53 @@
+package messaging_test
+
+import (
+	"testing"
+
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestDetectLanguageFromRecentMessages(t *testing.T) {
+	cases := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"english", []string{"Are you coming to the party tonight?", "Yes, and I will bring the cake for you"}, "en"},
+		{"german", []string{"Ich bin nicht sicher, ob das stimmt", "Die Antwort ist mit dem Brief"}, "de"},
+		{"russian", []string{"Привет, как дела? Увидимся завтра вечером"}, "ru"},
+		{"ukrainian", []string{"Привіт, як справи? Побачимося їхати завтра"}, "uk"},
+		{"japanese", []string{"こんにちは、今日はいい天気ですね。明日も晴れるといいです"}, "ja"},
+		{"too short", []string{"ok"}, ""},
+		{"ambiguous latin", []string{"lol haha xd brb omg ttyl gg wp"}, ""},
+	}
+	for _, tc := range cases {
+		if got := messagingapp.DetectLanguage(tc.texts); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestResolveChatLanguagePrefersManualHint(t *testing.T) {
+	texts := []string{"Are you coming to the party tonight? It is at the old place"}
+	if lang, source := messagingapp.ResolveChatLanguage("fr", texts); lang != "fr" || source != models.ChatLanguageSourceManual {
+		t.Fatalf("manual hint must win, got %q/%q", lang, source)
+	}
+	if lang, source := messagingapp.ResolveChatLanguage("", texts); lang != "en" || source != models.ChatLanguageSourceDetected {
+		t.Fatalf("expected detected english, got %q/%q", lang, source)
+	}
+}
+
+func TestNormalizeLanguageTag(t *testing.T) {
+	for raw, want := range map[string]string{"EN": "en", "pt_BR": "pt-BR", " sr-Latn ": "sr-Latn"} {
+		got, err := messagingapp.NormalizeLanguageTag(raw)
+		if err != nil || got != want {
+			t.Fatalf("normalize %q: expected %q, got %q (%v)", raw, want, got, err)
+		}
+	}
+	for _, raw := range []string{"", "e", "english", "en-", "1n", "ру"} {
+		if _, err := messagingapp.NormalizeLanguageTag(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
@@ -80,6 +80,9 @@ func TestShouldAutoMarkRead(t *testing.T) {
 	if ShouldAutoMarkRead(models.Message{Direction: "in", Status: "read"}) {
 		t.Fatal("already read message must not be auto-marked read")
 	}
+	if ShouldAutoMarkRead(models.Message{Direction: "in", Status: "delivered", ContentType: models.MessageContentTypeSystem}) {
+		t.Fatal("system message must not be auto-marked read")
+	}
 }
 
 func TestAllocateOutboundMessage(t *testing.T) {
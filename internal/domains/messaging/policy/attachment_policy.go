@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrInvalidAttachmentPolicy = errors.New("invalid attachment policy")
+
+// NormalizeAttachmentPolicy validates a contact attachment policy. An empty
+// mode means allow.
+func NormalizeAttachmentPolicy(policy models.ContactAttachmentPolicy) (models.ContactAttachmentPolicy, error) {
+	policy.ContactID = strings.TrimSpace(policy.ContactID)
+	policy.Mode = strings.ToLower(strings.TrimSpace(policy.Mode))
+	if policy.Mode == "" {
+		policy.Mode = models.AttachmentPolicyAllow
+	}
+	if policy.ContactID == "" || policy.MaxSizeBytes < 0 {
+		return models.ContactAttachmentPolicy{}, ErrInvalidAttachmentPolicy
+	}
+	switch policy.Mode {
+	case models.AttachmentPolicyAllow, models.AttachmentPolicyImagesOnly, models.AttachmentPolicyBlock:
+		return policy, nil
+	default:
+		return models.ContactAttachmentPolicy{}, ErrInvalidAttachmentPolicy
+	}
+}
+
+// IsDefaultAttachmentPolicy reports whether policy accepts every attachment
+// without approval, which is what contacts without a policy get.
+func IsDefaultAttachmentPolicy(policy models.ContactAttachmentPolicy) bool {
+	return policy.Mode == models.AttachmentPolicyAllow && policy.MaxSizeBytes == 0 && !policy.RequireApproval
+}
+
+// ApplyAttachmentPolicy splits inbound attachments into those kept on the
+// message, the subset of kept ones held for approval, and those dropped.
+// Sizes and MIME types are the sender's claims; an attachment whose size is
+// unknown never passes a size limit.
+func ApplyAttachmentPolicy(policy models.ContactAttachmentPolicy, attachments []models.MessageAttachment) (kept, held, dropped []models.MessageAttachment) {
+	for _, attachment := range attachments {
+		if !attachmentAllowed(policy, attachment) {
+			dropped = append(dropped, attachment)
+			continue
+		}
+		kept = append(kept, attachment)
+		if policy.RequireApproval {
+			held = append(held, attachment)
+		}
+	}
+	return kept, held, dropped
+}
+
+func attachmentAllowed(policy models.ContactAttachmentPolicy, attachment models.MessageAttachment) bool {
+	switch policy.Mode {
+	case models.AttachmentPolicyBlock:
+		return false
+	case models.AttachmentPolicyImagesOnly:
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(attachment.MimeType)), "image/") {
+			return false
+		}
+	}
+	if policy.MaxSizeBytes > 0 && (attachment.Size <= 0 || attachment.Size > policy.MaxSizeBytes) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// WireKindDeviceSync carries conversation flags and saved messages between
+// the user's own devices. It is addressed to the sender's own identity.
+const WireKindDeviceSync = "device_sync"
+
+// MaxConversationDraftBytes bounds a synced draft.
+const MaxConversationDraftBytes = 8 << 10
+
+var ErrInvalidConversationSync = errors.New("invalid conversation sync payload")
+
+// ValidateConversationSync checks conversation ids, register names and values.
+func ValidateConversationSync(states []models.ConversationSyncState) error {
+	for _, state := range states {
+		id := strings.TrimSpace(state.ConversationID)
+		if id == "" || id != state.ConversationID || strings.IndexFunc(id, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+			return ErrInvalidConversationSync
+		}
+		if len(state.Registers) == 0 {
+			return ErrInvalidConversationSync
+		}
+		for name, register := range state.Registers {
+			if register.UpdatedAt.IsZero() || strings.TrimSpace(register.DeviceID) == "" {
+				return ErrInvalidConversationSync
+			}
+			switch name {
+			case models.ConversationFlagPinned, models.ConversationFlagArchived, models.ConversationFlagMuted:
+				if register.Value != "true" && register.Value != "false" {
+					return ErrInvalidConversationSync
+				}
+			case models.ConversationFlagDraft:
+				if len(register.Value) > MaxConversationDraftBytes {
+					return ErrInvalidConversationSync
+				}
+			case models.ConversationFlagLanguage:
+				if register.Value == "" {
+					continue
+				}
+				if normalized, err := NormalizeLanguageTag(register.Value); err != nil || normalized != register.Value {
+					return ErrInvalidConversationSync
+				}
+			case models.ConversationFlagNotificationSound:
+				if !ValidNotificationSound(register.Value) {
+					return ErrInvalidConversationSync
+				}
+			case models.ConversationFlagNotificationPriority:
+				if !ValidNotificationPriority(register.Value) {
+					return ErrInvalidConversationSync
+				}
+			default:
+				return ErrInvalidConversationSync
+			}
+		}
+	}
+	return nil
+}
+
+// MergeSyncRegister returns the winning register and whether it differs from
+// local. The later write wins; equal timestamps fall back to the larger device
+// id, which makes the merge commutative.
+func MergeSyncRegister(local, remote models.SyncRegister) (models.SyncRegister, bool) {
+	if remote.UpdatedAt.After(local.UpdatedAt) {
+		return remote, true
+	}
+	if remote.UpdatedAt.Equal(local.UpdatedAt) && remote.DeviceID > local.DeviceID {
+		return remote, true
+	}
+	return local, false
+}
+
+// MergeConversationSyncState merges remote registers into local and reports
+// whether anything changed.
+func MergeConversationSyncState(local, remote models.ConversationSyncState) (models.ConversationSyncState, bool) {
+	merged := models.ConversationSyncState{
+		ConversationID: local.ConversationID,
+		Registers:      make(map[string]models.SyncRegister, len(local.Registers)+len(remote.Registers)),
+	}
+	if merged.ConversationID == "" {
+		merged.ConversationID = remote.ConversationID
+	}
+	for name, register := range local.Registers {
+		merged.Registers[name] = register
+	}
+	changed := false
+	for name, register := range remote.Registers {
+		current, exists := merged.Registers[name]
+		if !exists {
+			merged.Registers[name] = register
+			changed = true
+			continue
+		}
+		if winner, updated := MergeSyncRegister(current, register); updated {
+			merged.Registers[name] = winner
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
+// BuildConversationSyncUpdate turns a flags update into registers stamped by
+// the writing device.
+func BuildConversationSyncUpdate(conversationID string, update models.ConversationFlagsUpdate, deviceID string, now time.Time) models.ConversationSyncState {
+	state := models.ConversationSyncState{
+		ConversationID: strings.TrimSpace(conversationID),
+		Registers:      map[string]models.SyncRegister{},
+	}
+	set := func(name, value string) {
+		state.Registers[name] = models.SyncRegister{Value: value, UpdatedAt: now.UTC(), DeviceID: deviceID}
+	}
+	boolValue := func(v bool) string {
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	if update.Pinned != nil {
+		set(models.ConversationFlagPinned, boolValue(*update.Pinned))
+	}
+	if update.Archived != nil {
+		set(models.ConversationFlagArchived, boolValue(*update.Archived))
+	}
+	if update.Muted != nil {
+		set(models.ConversationFlagMuted, boolValue(*update.Muted))
+	}
+	if update.Draft != nil {
+		set(models.ConversationFlagDraft, *update.Draft)
+	}
+	if update.Language != nil {
+		set(models.ConversationFlagLanguage, *update.Language)
+	}
+	if update.NotificationSound != nil {
+		set(models.ConversationFlagNotificationSound, *update.NotificationSound)
+	}
+	if update.NotificationPriority != nil {
+		set(models.ConversationFlagNotificationPriority, *update.NotificationPriority)
+	}
+	return state
+}
+
+// ResolveConversationFlags reads the register values of a synced state.
+func ResolveConversationFlags(state models.ConversationSyncState) models.ConversationFlags {
+	flags := models.ConversationFlags{ConversationID: state.ConversationID}
+	for name, register := range state.Registers {
+		if register.UpdatedAt.After(flags.UpdatedAt) {
+			flags.UpdatedAt = register.UpdatedAt
+		}
+		switch name {
+		case models.ConversationFlagPinned:
+			flags.Pinned = register.Value == "true"
+		case models.ConversationFlagArchived:
+			flags.Archived = register.Value == "true"
+		case models.ConversationFlagMuted:
+			flags.Muted = register.Value == "true"
+		case models.ConversationFlagDraft:
+			flags.Draft = register.Value
+		case models.ConversationFlagLanguage:
+			flags.Language = register.Value
+		case models.ConversationFlagNotificationSound:
+			flags.NotificationSound = register.Value
+		case models.ConversationFlagNotificationPriority:
+			flags.NotificationPriority = register.Value
+		}
+	}
+	return flags
+}
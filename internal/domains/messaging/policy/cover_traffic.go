@@ -0,0 +1,21 @@
+package policy
+
+import "bytes"
+
+// coverTrafficMarker prefixes the plaintext of dummy messages. It is only
+// visible after decryption, so relays see an ordinary e2ee message.
+var coverTrafficMarker = []byte("\x00aim-cover\x00")
+
+// NewCoverTrafficContent builds the plaintext of a dummy message. The filler
+// should be random so dummies vary in length like real messages do.
+func NewCoverTrafficContent(filler []byte) []byte {
+	out := make([]byte, 0, len(coverTrafficMarker)+len(filler))
+	out = append(out, coverTrafficMarker...)
+	return append(out, filler...)
+}
+
+// IsCoverTrafficContent reports whether decrypted content is a dummy message
+// that must be dropped without storing or acknowledging it.
+func IsCoverTrafficContent(content []byte) bool {
+	return bytes.HasPrefix(content, coverTrafficMarker)
+}
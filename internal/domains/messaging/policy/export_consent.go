@@ -0,0 +1,38 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// WireKindExportConsent carries a contact's signed consent that we may
+// export the plaintext of our conversation with them.
+const WireKindExportConsent = "export_consent"
+
+var ErrInvalidExportConsent = errors.New("invalid export consent")
+
+// ValidateExportConsent checks the shape of a consent. Signatures are
+// checked by the receiver against the grantor's pinned key.
+func ValidateExportConsent(consent *models.ExportConsent) error {
+	if consent == nil {
+		return ErrInvalidExportConsent
+	}
+	grantorID := strings.TrimSpace(consent.GrantorID)
+	exporterID := strings.TrimSpace(consent.ExporterID)
+	contactID := strings.TrimSpace(consent.ContactID)
+	if grantorID == "" || exporterID == "" || contactID == "" || exporterID == contactID {
+		return ErrInvalidExportConsent
+	}
+	if grantorID != exporterID && grantorID != contactID {
+		return ErrInvalidExportConsent
+	}
+	if consent.GrantedAt.IsZero() || !consent.ExpiresAt.After(consent.GrantedAt) {
+		return ErrInvalidExportConsent
+	}
+	if consent.PublicKey == "" || consent.Signature == "" {
+		return ErrInvalidExportConsent
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// WireKindHistoryBackfill asks a contact to send missing messages again.
+const WireKindHistoryBackfill = "history_backfill"
+
+const (
+	// MaxBackfillRanges bounds the ranges in one backfill request.
+	MaxBackfillRanges = 16
+	// MaxBackfillMessages bounds the messages one request can ask for.
+	MaxBackfillMessages = 500
+	// MaxTrackedHistoryGaps bounds the gaps kept per sender device; the
+	// oldest ones are dropped first.
+	MaxTrackedHistoryGaps = 64
+	// MaxBackfillAttempts stops re-requesting gaps the sender cannot fill.
+	// Such gaps stay visible as unresolved.
+	MaxBackfillAttempts = 3
+)
+
+var ErrInvalidHistoryBackfill = errors.New("invalid history backfill request")
+
+// ValidateHistoryBackfill checks that a request names a bounded set of
+// well-formed sequence ranges.
+func ValidateHistoryBackfill(req *models.HistoryBackfillRequest) error {
+	if req == nil || len(req.Ranges) == 0 || len(req.Ranges) > MaxBackfillRanges {
+		return ErrInvalidHistoryBackfill
+	}
+	var total uint64
+	for _, r := range req.Ranges {
+		if r.From == 0 || r.To < r.From {
+			return ErrInvalidHistoryBackfill
+		}
+		total += r.To - r.From + 1
+		if total > MaxBackfillMessages {
+			return ErrInvalidHistoryBackfill
+		}
+	}
+	return nil
+}
+
+// ObserveInboundSeq records that message seq arrived from a sender device.
+// Skipping ahead opens a gap; a late arrival shrinks or splits the gap that
+// contains it. It reports whether the state changed.
+func ObserveInboundSeq(state models.InboundSequence, deviceID string, seq uint64, now time.Time) (models.InboundSequence, bool) {
+	if seq == 0 {
+		return state, false
+	}
+	if seq > state.Highest {
+		gaps := append([]models.HistoryGap(nil), state.Gaps...)
+		if seq > state.Highest+1 {
+			gaps = append(gaps, models.HistoryGap{
+				DeviceID:   deviceID,
+				From:       state.Highest + 1,
+				To:         seq - 1,
+				DetectedAt: now.UTC(),
+			})
+		}
+		if len(gaps) > MaxTrackedHistoryGaps {
+			gaps = gaps[len(gaps)-MaxTrackedHistoryGaps:]
+		}
+		return models.InboundSequence{Highest: seq, Gaps: gaps}, true
+	}
+	for i, gap := range state.Gaps {
+		if seq < gap.From || seq > gap.To {
+			continue
+		}
+		gaps := append([]models.HistoryGap(nil), state.Gaps[:i]...)
+		if seq > gap.From {
+			head := gap
+			head.To = seq - 1
+			gaps = append(gaps, head)
+		}
+		if seq < gap.To {
+			tail := gap
+			tail.From = seq + 1
+			gaps = append(gaps, tail)
+		}
+		gaps = append(gaps, state.Gaps[i+1:]...)
+		return models.InboundSequence{Highest: state.Highest, Gaps: gaps}, true
+	}
+	return state, false
+}
+
+// DueBackfillRanges returns the gaps that are old enough to rule out plain
+// reordering, have not been requested within retryAfter and still have
+// attempts left. The ranges are capped by the request limits.
+func DueBackfillRanges(gaps []models.HistoryGap, now time.Time, grace, retryAfter time.Duration) []models.SeqRange {
+	ranges := make([]models.SeqRange, 0)
+	var total uint64
+	for _, gap := range gaps {
+		if len(ranges) == MaxBackfillRanges || total >= MaxBackfillMessages {
+			break
+		}
+		if gap.BackfillAttempts >= MaxBackfillAttempts || now.Sub(gap.DetectedAt) < grace {
+			continue
+		}
+		if !gap.BackfillRequestedAt.IsZero() && now.Sub(gap.BackfillRequestedAt) < retryAfter {
+			continue
+		}
+		r := models.SeqRange{From: gap.From, To: gap.To}
+		if span := r.To - r.From + 1; total+span > MaxBackfillMessages {
+			r.To = r.From + (MaxBackfillMessages - total) - 1
+		}
+		total += r.To - r.From + 1
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// MarkBackfillRequested stamps the gaps covered by ranges as requested.
+func MarkBackfillRequested(state models.InboundSequence, ranges []models.SeqRange, now time.Time) models.InboundSequence {
+	gaps := append([]models.HistoryGap(nil), state.Gaps...)
+	for i := range gaps {
+		for _, r := range ranges {
+			if r.From == gaps[i].From {
+				gaps[i].BackfillRequestedAt = now.UTC()
+				gaps[i].BackfillAttempts++
+				break
+			}
+		}
+	}
+	return models.InboundSequence{Highest: state.Highest, Gaps: gaps}
+}
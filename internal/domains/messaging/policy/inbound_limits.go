@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+)
+
+var (
+	ErrInboundWireTooLarge      = errors.New("inbound wire payload exceeds size limit")
+	ErrInboundPlaintextTooLarge = errors.New("inbound plaintext exceeds length limit")
+	ErrInboundKindNotAllowed    = errors.New("inbound payload kind is not allowed")
+)
+
+const (
+	DefaultInboundMaxWireBytes      = 256 * 1024
+	DefaultInboundMaxPlaintextBytes = 64 * 1024
+
+	// envelopeAEADOverhead is the XChaCha20-Poly1305 tag size, used to bound
+	// plaintext length from ciphertext before decrypting.
+	envelopeAEADOverhead = 16
+)
+
+// KnownInboundWireKinds lists every wire kind the daemon knows how to handle.
+var KnownInboundWireKinds = []string{"plain", "e2ee", "receipt", "device_revoke", WireKindCardRequest, WireKindCardResponse, WireKindUsernameClaim, WireKindDeviceSync, WireKindHistoryBackfill, WireKindMessagePin, WireKindStateHash, WireKindExportConsent}
+
+// InboundLimits bounds inbound payloads. A zero size disables that check and
+// an empty AllowedKinds set falls back to KnownInboundWireKinds.
+type InboundLimits struct {
+	MaxWireBytes      int
+	MaxPlaintextBytes int
+	AllowedKinds      map[string]bool
+}
+
+// CheckInboundPayload validates a raw inbound payload against the limits
+// without decrypting it. Payloads that are not wire JSON are treated as
+// legacy plaintext.
+func CheckInboundPayload(limits InboundLimits, payload []byte, wire contracts.WirePayload, parsed bool) error {
+	if limits.MaxWireBytes > 0 && len(payload) > limits.MaxWireBytes {
+		return ErrInboundWireTooLarge
+	}
+	if !parsed {
+		return checkPlaintextLength(limits, len(payload))
+	}
+	if !inboundKindAllowed(limits, wire.Kind) {
+		return ErrInboundKindNotAllowed
+	}
+	switch strings.TrimSpace(wire.Kind) {
+	case "plain":
+		return checkPlaintextLength(limits, len(wire.Plain))
+	case "e2ee":
+		return checkPlaintextLength(limits, len(wire.Envelope.Ciphertext)-envelopeAEADOverhead)
+	}
+	return nil
+}
+
+func inboundKindAllowed(limits InboundLimits, kind string) bool {
+	kind = strings.TrimSpace(kind)
+	if len(limits.AllowedKinds) > 0 {
+		return limits.AllowedKinds[kind]
+	}
+	for _, known := range KnownInboundWireKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPlaintextLength(limits InboundLimits, size int) error {
+	if limits.MaxPlaintextBytes > 0 && size > limits.MaxPlaintextBytes {
+		return ErrInboundPlaintextTooLarge
+	}
+	return nil
+}
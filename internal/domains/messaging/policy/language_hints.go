@@ -0,0 +1,172 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrInvalidLanguageTag = errors.New("invalid language tag")
+
+const (
+	// LanguageDetectionSampleSize is how many recent messages feed detection.
+	LanguageDetectionSampleSize = 20
+
+	maxLanguageTagBytes       = 35
+	minLanguageDetectionRunes = 20
+	minLatinStopwordHits      = 2
+)
+
+// latinStopwords tells Latin-script languages apart by their most frequent
+// short words.
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "you", "to", "of", "it", "that", "for", "with", "are", "this", "have", "not"},
+	"de": {"der", "die", "und", "ist", "nicht", "das", "ich", "du", "mit", "sie", "ein", "zu"},
+	"fr": {"le", "les", "et", "est", "vous", "je", "pas", "une", "des", "pour", "dans", "mais"},
+	"es": {"el", "los", "y", "es", "no", "por", "para", "con", "pero", "muy", "esta", "está"},
+	"it": {"il", "di", "che", "non", "sono", "per", "gli", "ciao", "della", "questo"},
+	"pt": {"o", "não", "uma", "com", "você", "os", "mas", "muito", "obrigado", "está"},
+}
+
+// NormalizeLanguageTag validates a BCP 47 style tag such as "en" or "pt-BR"
+// and returns it with a lowercase primary subtag.
+func NormalizeLanguageTag(raw string) (string, error) {
+	tag := strings.TrimSpace(strings.ReplaceAll(raw, "_", "-"))
+	if tag == "" || len(tag) > maxLanguageTagBytes {
+		return "", ErrInvalidLanguageTag
+	}
+	parts := strings.Split(tag, "-")
+	for i, part := range parts {
+		minLen, maxLen := 1, 8
+		if i == 0 {
+			minLen, maxLen = 2, 3
+		}
+		if len(part) < minLen || len(part) > maxLen {
+			return "", ErrInvalidLanguageTag
+		}
+		for _, r := range part {
+			if r > unicode.MaxASCII || !(unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+				return "", ErrInvalidLanguageTag
+			}
+		}
+	}
+	parts[0] = strings.ToLower(parts[0])
+	return strings.Join(parts, "-"), nil
+}
+
+// DetectLanguage guesses the language of a conversation from message texts.
+// It runs locally on script and stopword statistics and returns an empty
+// string when the sample is too small or ambiguous.
+func DetectLanguage(texts []string) string {
+	scripts := map[string]int{}
+	letters := 0
+	ukrainianLetters := 0
+	for _, text := range texts {
+		for _, r := range text {
+			script := runeScript(r)
+			if script == "" {
+				continue
+			}
+			letters++
+			scripts[script]++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				ukrainianLetters++
+			}
+		}
+	}
+	if letters < minLanguageDetectionRunes {
+		return ""
+	}
+	dominant, best := "", 0
+	for script, count := range scripts {
+		if count > best || (count == best && script < dominant) {
+			dominant, best = script, count
+		}
+	}
+	switch dominant {
+	case "cyrillic":
+		if ukrainianLetters > 0 {
+			return "uk"
+		}
+		return "ru"
+	case "han":
+		if scripts["kana"] > 0 {
+			return "ja"
+		}
+		return "zh"
+	case "kana":
+		return "ja"
+	case "latin":
+		return detectLatinLanguage(texts)
+	default:
+		return dominant
+	}
+}
+
+// ResolveChatLanguage prefers the user's choice over detection.
+func ResolveChatLanguage(manual string, recentTexts []string) (string, string) {
+	if manual != "" {
+		return manual, models.ChatLanguageSourceManual
+	}
+	if detected := DetectLanguage(recentTexts); detected != "" {
+		return detected, models.ChatLanguageSourceDetected
+	}
+	return "", ""
+}
+
+func runeScript(r rune) string {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return "latin"
+	case unicode.Is(unicode.Cyrillic, r):
+		return "cyrillic"
+	case unicode.Is(unicode.Greek, r):
+		return "el"
+	case unicode.Is(unicode.Arabic, r):
+		return "ar"
+	case unicode.Is(unicode.Hebrew, r):
+		return "he"
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return "kana"
+	case unicode.Is(unicode.Hangul, r):
+		return "ko"
+	case unicode.Is(unicode.Han, r):
+		return "han"
+	default:
+		return ""
+	}
+}
+
+func detectLatinLanguage(texts []string) string {
+	lookup := map[string][]string{}
+	for lang, words := range latinStopwords {
+		for _, word := range words {
+			lookup[word] = append(lookup[word], lang)
+		}
+	}
+	hits := map[string]int{}
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			for _, lang := range lookup[word] {
+				hits[lang]++
+			}
+		}
+	}
+	winner, best, tied := "", 0, false
+	for lang, count := range hits {
+		switch {
+		case count > best:
+			winner, best, tied = lang, count, false
+		case count == best:
+			tied = true
+		}
+	}
+	if tied || best < minLatinStopwordHits {
+		return ""
+	}
+	return winner
+}
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// MaxLocationAccuracyMeters bounds the reported accuracy radius. Anything
+// coarser than a large city is not worth sharing as a point.
+const MaxLocationAccuracyMeters = 100_000
+
+var (
+	ErrInvalidMessageLocation = errors.New("invalid location")
+	errLocationNotEditable    = errors.New("location messages cannot be edited")
+)
+
+// NormalizeMessageLocation trims the label and checks that the coordinates
+// are on the globe and the accuracy is plausible.
+func NormalizeMessageLocation(location models.MessageLocation) (models.MessageLocation, error) {
+	location.Label = strings.TrimSpace(location.Label)
+	if !inRange(location.Latitude, -90, 90) || !inRange(location.Longitude, -180, 180) {
+		return models.MessageLocation{}, ErrInvalidMessageLocation
+	}
+	if !inRange(location.AccuracyMeters, 0, MaxLocationAccuracyMeters) {
+		return models.MessageLocation{}, ErrInvalidMessageLocation
+	}
+	if !utf8.ValidString(location.Label) || utf8.RuneCountInString(location.Label) > models.MaxLocationLabelRunes {
+		return models.MessageLocation{}, ErrInvalidMessageLocation
+	}
+	return location, nil
+}
+
+// EncodeMessageLocation builds the content of a location message.
+func EncodeMessageLocation(location models.MessageLocation) ([]byte, error) {
+	location, err := NormalizeMessageLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(location)
+}
+
+// DecodeMessageLocation parses and validates the content of a location
+// message. Both coordinates must be present: a missing one would otherwise
+// silently become zero.
+func DecodeMessageLocation(content []byte) (models.MessageLocation, error) {
+	var payload struct {
+		Latitude       *float64 `json:"lat"`
+		Longitude      *float64 `json:"lng"`
+		AccuracyMeters float64  `json:"accuracy"`
+		Label          string   `json:"label"`
+	}
+	if err := json.Unmarshal(content, &payload); err != nil || payload.Latitude == nil || payload.Longitude == nil {
+		return models.MessageLocation{}, ErrInvalidMessageLocation
+	}
+	return NormalizeMessageLocation(models.MessageLocation{
+		Latitude:       *payload.Latitude,
+		Longitude:      *payload.Longitude,
+		AccuracyMeters: payload.AccuracyMeters,
+		Label:          payload.Label,
+	})
+}
+
+// ApplyMessageLocation marks msg as a location message when its content
+// decodes as one, and reports whether it did. Content that does not decode
+// stays as it is, so a malformed location still shows up as text.
+func ApplyMessageLocation(msg *models.Message) bool {
+	location, err := DecodeMessageLocation(msg.Content)
+	if err != nil {
+		return false
+	}
+	msg.ContentType = models.MessageContentTypeLocation
+	msg.Location = &location
+	return true
+}
+
+func inRange(value, low, high float64) bool {
+	return !math.IsNaN(value) && value >= low && value <= high
+}
@@ -0,0 +1,21 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// WireKindMessagePin tells group members that a post was pinned or unpinned.
+const WireKindMessagePin = "message_pin"
+
+var ErrInvalidMessagePin = errors.New("invalid message pin update")
+
+// ValidateMessagePinUpdate checks that a pin update names a group post.
+func ValidateMessagePinUpdate(update *models.MessagePinUpdate) error {
+	if update == nil || strings.TrimSpace(update.GroupID) == "" || strings.TrimSpace(update.EventID) == "" {
+		return ErrInvalidMessagePin
+	}
+	return nil
+}
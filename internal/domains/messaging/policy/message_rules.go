@@ -5,20 +5,29 @@ import (
 	"errors"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
-	ErrOutboundSessionRequired = errors.New("outbound session is required")
-	errInvalidEditMessageInput = errors.New("contact id, message id and content are required")
-	errMessageNotFound         = errors.New("message not found")
-	errMessageWrongContact     = errors.New("message does not belong to contact")
-	errMessageNotOutbound      = errors.New("only outbound messages can be edited")
-	errContactIDRequired       = errors.New("contact id is required")
-	errMessageIDRequired       = errors.New("message id is required")
-	errInvalidSendMessageInput = errors.New("contact id and content are required")
-	errContactNotVerified      = errors.New("contact is not verified")
+	ErrOutboundSessionRequired   = errors.New("outbound session is required")
+	errInvalidEditMessageInput   = errors.New("contact id, message id and content are required")
+	errMessageNotFound           = errors.New("message not found")
+	errMessageWrongContact       = errors.New("message does not belong to contact")
+	errMessageNotOutbound        = errors.New("only outbound messages can be edited")
+	errContactIDRequired         = errors.New("contact id is required")
+	errMessageIDRequired         = errors.New("message id is required")
+	errInvalidSendMessageInput   = errors.New("contact id and content are required")
+	errContactNotVerified        = errors.New("contact is not verified")
+	ErrInvalidMessageAttachments = errors.New("invalid message attachments")
+	ErrConversationReadOnly      = errors.New("conversation is read-only after the contact moved to a new identity")
+	ErrContactNotAdded           = errors.New("contact is not added")
+	ErrContactBlocked            = errors.New("contact is blocked")
 )
 
+// MaxMessageAttachments bounds how many attachments a single message may
+// reference.
+const MaxMessageAttachments = 10
+
 func ValidateEditMessageInput(contactID, messageID, content string) (string, string, string, error) {
 	contactID = strings.TrimSpace(contactID)
 	messageID = strings.TrimSpace(messageID)
@@ -39,6 +48,9 @@ func EnsureEditableMessage(msg models.Message, found bool, contactID string) err
 	if msg.Direction != "out" {
 		return errMessageNotOutbound
 	}
+	if msg.ContentType == models.MessageContentTypeLocation {
+		return errLocationNotEditable
+	}
 	return nil
 }
 
@@ -62,7 +74,13 @@ func BuildMessageStatus(msg models.Message, found bool) (models.MessageStatus, e
 	if !found {
 		return models.MessageStatus{}, errMessageNotFound
 	}
-	return models.MessageStatus{MessageID: msg.ID, Status: msg.Status}, nil
+	return models.MessageStatus{
+		MessageID:   msg.ID,
+		Status:      msg.Status,
+		RelayedAt:   msg.RelayedAt,
+		DeliveredAt: msg.DeliveredAt,
+		ReadAt:      msg.ReadAt,
+	}, nil
 }
 
 func ValidateSendMessageInput(contactID, content string) (string, string, error) {
@@ -74,6 +92,23 @@ func ValidateSendMessageInput(contactID, content string) (string, string, error)
 	return contactID, content, nil
 }
 
+// ValidateMessageAttachments checks attachment references carried by a
+// message, including ones received from peers.
+func ValidateMessageAttachments(attachments []models.MessageAttachment) error {
+	if len(attachments) > MaxMessageAttachments {
+		return ErrInvalidMessageAttachments
+	}
+	for _, attachment := range attachments {
+		if strings.TrimSpace(attachment.ID) == "" || attachment.Size < 0 {
+			return ErrInvalidMessageAttachments
+		}
+		if !utf8.ValidString(attachment.AltText) || utf8.RuneCountInString(attachment.AltText) > models.MaxAttachmentAltTextRunes {
+			return ErrInvalidMessageAttachments
+		}
+	}
+	return nil
+}
+
 func NewOutboundMessage(messageID, contactID, content string, now time.Time) models.Message {
 	return models.Message{
 		ID:               messageID,
@@ -89,7 +124,7 @@ func NewOutboundMessage(messageID, contactID, content string, now time.Time) mod
 }
 
 func ShouldAutoMarkRead(msg models.Message) bool {
-	return msg.Direction == "in" && msg.Status != "read"
+	return msg.Direction == "in" && msg.Status != "read" && msg.ContentType != models.MessageContentTypeSystem
 }
 
 func NormalizeDeviceID(deviceID string) string {
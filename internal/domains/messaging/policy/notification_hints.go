@@ -0,0 +1,43 @@
+package policy
+
+import "aim-chat/go-backend/pkg/models"
+
+// MaxNotificationSoundBytes bounds a synced sound name.
+const MaxNotificationSoundBytes = 64
+
+// ValidNotificationSound accepts an empty name or a short identifier made of
+// letters, digits, '.', '_' and '-'. Clients map it to their own sound files.
+func ValidNotificationSound(sound string) bool {
+	if len(sound) > MaxNotificationSoundBytes {
+		return false
+	}
+	for _, r := range sound {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.' || r == '_' || r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidNotificationPriority accepts an empty priority or a known class.
+func ValidNotificationPriority(priority string) bool {
+	switch priority {
+	case "", models.NotificationPriorityLow, models.NotificationPriorityNormal, models.NotificationPriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationHintsFromFlags extracts the alerting preferences of a
+// conversation.
+func NotificationHintsFromFlags(flags models.ConversationFlags) models.NotificationHints {
+	return models.NotificationHints{
+		Sound:    flags.NotificationSound,
+		Priority: flags.NotificationPriority,
+		Muted:    flags.Muted,
+	}
+}
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"errors"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+const maxRetryPolicyAttempts = 100
+
+// DefaultRetryPolicy is the retry schedule the daemon always used: 2s
+// doubling up to 30s, no jitter, eight retries.
+func DefaultRetryPolicy() models.RetryPolicy {
+	return models.RetryPolicy{
+		BaseIntervalMS: 2000,
+		MaxIntervalMS:  30000,
+		MaxAttempts:    8,
+		DeadLetter:     true,
+	}
+}
+
+// NormalizeRetryPolicy validates a retry policy. A zero max interval means
+// the base interval.
+func NormalizeRetryPolicy(policy models.RetryPolicy) (models.RetryPolicy, error) {
+	if policy.MaxIntervalMS == 0 {
+		policy.MaxIntervalMS = policy.BaseIntervalMS
+	}
+	switch {
+	case policy.BaseIntervalMS <= 0, policy.MaxIntervalMS < policy.BaseIntervalMS:
+		return models.RetryPolicy{}, ErrInvalidRetryPolicy
+	case policy.JitterRatio < 0, policy.JitterRatio > 1:
+		return models.RetryPolicy{}, ErrInvalidRetryPolicy
+	case policy.MaxAttempts < 1, policy.MaxAttempts > maxRetryPolicyAttempts:
+		return models.RetryPolicy{}, ErrInvalidRetryPolicy
+	}
+	return policy, nil
+}
+
+// RetryBackoff is the delay before retry number retryCount. jitter is a
+// uniform sample in [0, 1) that spreads the delay by the policy's ratio.
+func RetryBackoff(policy models.RetryPolicy, retryCount int, jitter float64) time.Duration {
+	if retryCount < 1 {
+		retryCount = 1
+	}
+	maxBackoff := time.Duration(policy.MaxIntervalMS) * time.Millisecond
+	backoff := time.Duration(policy.BaseIntervalMS) * time.Millisecond
+	for i := 1; i < retryCount && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxBackoff)
+	if policy.JitterRatio > 0 {
+		backoff = time.Duration(float64(backoff) * (1 + policy.JitterRatio*(2*jitter-1)))
+	}
+	return backoff
+}
@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// MaxSavedMessageBytes bounds the body of a note copied between devices.
+const MaxSavedMessageBytes = 64 << 10
+
+var ErrInvalidSavedMessage = errors.New("invalid saved message")
+
+// ValidateSavedMessage checks a note received from another of our devices.
+func ValidateSavedMessage(note models.SavedMessage) error {
+	if strings.TrimSpace(note.ID) == "" || note.Timestamp.IsZero() {
+		return ErrInvalidSavedMessage
+	}
+	if len(note.Content) == 0 || len(note.Content) > MaxSavedMessageBytes {
+		return ErrInvalidSavedMessage
+	}
+	if note.Location != nil {
+		if _, err := DecodeMessageLocation(note.Content); err != nil {
+			return ErrInvalidSavedMessage
+		}
+	}
+	return ValidateMessageAttachments(note.Attachments)
+}
+
+// NewSavedMessage copies the parts of a stored note that other devices need.
+func NewSavedMessage(msg models.Message) models.SavedMessage {
+	return models.SavedMessage{
+		ID:          msg.ID,
+		ThreadID:    strings.TrimSpace(msg.ThreadID),
+		Content:     append([]byte(nil), msg.Content...),
+		Timestamp:   msg.Timestamp.UTC(),
+		Attachments: append([]models.MessageAttachment(nil), msg.Attachments...),
+		Location:    msg.Location,
+	}
+}
+
+// BuildStoredSavedMessage turns a note received from another device into the
+// local copy in the saved messages conversation of selfID.
+func BuildStoredSavedMessage(note models.SavedMessage, selfID string, now time.Time) models.Message {
+	stored := NewOutboundMessage(note.ID, selfID, string(note.Content), note.Timestamp)
+	stored.ThreadID = strings.TrimSpace(note.ThreadID)
+	stored.Attachments = append([]models.MessageAttachment(nil), note.Attachments...)
+	if note.Location != nil {
+		ApplyMessageLocation(&stored)
+	}
+	stored.Status = "sent"
+	stored.RelayedAt = now.UTC()
+	return stored
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSnippetNameBytes bounds the short name clients show in pickers.
+	MaxSnippetNameBytes = 64
+	// MaxSnippetBodyBytes bounds a snippet body before expansion.
+	MaxSnippetBodyBytes = 4 << 10
+	// MaxSnippetValueBytes bounds one placeholder value.
+	MaxSnippetValueBytes = 1 << 10
+)
+
+var (
+	ErrInvalidSnippet      = errors.New("invalid snippet")
+	ErrSnippetValueMissing = errors.New("snippet placeholder has no value")
+)
+
+// snippetPlaceholder matches {{name}} with optional inner spaces. Names are
+// lower-case identifiers so they cannot be confused with ordinary text.
+var snippetPlaceholder = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]{0,31})\s*\}\}`)
+
+// ValidateSnippet checks the name and body of a canned response.
+func ValidateSnippet(name, body string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxSnippetNameBytes || !utf8.ValidString(name) {
+		return ErrInvalidSnippet
+	}
+	if strings.TrimSpace(body) == "" || len(body) > MaxSnippetBodyBytes || !utf8.ValidString(body) {
+		return ErrInvalidSnippet
+	}
+	return nil
+}
+
+// SnippetPlaceholders lists the distinct placeholder names of body in order
+// of first appearance.
+func SnippetPlaceholders(body string) []string {
+	out := make([]string, 0)
+	seen := map[string]struct{}{}
+	for _, match := range snippetPlaceholder.FindAllStringSubmatch(body, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		out = append(out, match[1])
+	}
+	return out
+}
+
+// ExpandSnippet fills every placeholder of body from values. A placeholder
+// without a value fails the expansion instead of leaking into the message.
+func ExpandSnippet(body string, values map[string]string) (string, error) {
+	for _, name := range SnippetPlaceholders(body) {
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrSnippetValueMissing, name)
+		}
+		if len(value) > MaxSnippetValueBytes || !utf8.ValidString(value) {
+			return "", ErrInvalidSnippet
+		}
+	}
+	return snippetPlaceholder.ReplaceAllStringFunc(body, func(match string) string {
+		return values[snippetPlaceholder.FindStringSubmatch(match)[1]]
+	}), nil
+}
@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// WireKindStateHash carries the state hash of a direct conversation to the
+// peer on the other side of it.
+const WireKindStateHash = "state_hash"
+
+// MaxStateHashConversations bounds the conversations one state hash payload
+// may describe.
+const MaxStateHashConversations = 10000
+
+var ErrInvalidStateHashes = errors.New("invalid state hashes")
+
+// Domain separation for Merkle leaves and inner nodes, so a leaf can never
+// be passed off as a node.
+const (
+	stateHashLeafPrefix = 0x00
+	stateHashNodePrefix = 0x01
+)
+
+// ConversationStateHashes hashes the message set of every conversation in
+// messages. Only what every copy of a message agrees on is hashed: its id,
+// content type, thread and content. Delivery status and local timestamps
+// differ between devices by design and are left out. Group posts are keyed
+// by event id, which all members share, and local system entries and group
+// delivery records are skipped.
+func ConversationStateHashes(messages map[string]models.Message) []models.ConversationStateHash {
+	type conversationKey struct{ conversationType, conversationID string }
+	leaves := map[conversationKey]map[string][]byte{}
+	for _, msg := range messages {
+		contentType := strings.TrimSpace(msg.ContentType)
+		if contentType == models.MessageContentTypeSystem || contentType == groupdomain.GroupFanoutTransportContentType {
+			continue
+		}
+		msg = models.NormalizeMessageConversation(msg)
+		if msg.ConversationID == "" {
+			continue
+		}
+		leafKey := msg.ID
+		if msg.ConversationType == models.ConversationTypeGroup && strings.TrimSpace(msg.EventID) != "" {
+			leafKey = strings.TrimSpace(msg.EventID)
+		}
+		key := conversationKey{msg.ConversationType, msg.ConversationID}
+		if leaves[key] == nil {
+			leaves[key] = map[string][]byte{}
+		}
+		leaves[key][leafKey] = messageLeaf(leafKey, contentType, msg.ThreadID, msg.Content)
+	}
+
+	out := make([]models.ConversationStateHash, 0, len(leaves))
+	for key, byID := range leaves {
+		ids := make([]string, 0, len(byID))
+		for id := range byID {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		ordered := make([][]byte, 0, len(ids))
+		for _, id := range ids {
+			ordered = append(ordered, byID[id])
+		}
+		out = append(out, models.ConversationStateHash{
+			ConversationID:   key.conversationID,
+			ConversationType: key.conversationType,
+			MessageCount:     len(ids),
+			Root:             hex.EncodeToString(merkleRoot(ordered)),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ConversationType != out[j].ConversationType {
+			return out[i].ConversationType < out[j].ConversationType
+		}
+		return out[i].ConversationID < out[j].ConversationID
+	})
+	return out
+}
+
+// SettingsStateRoot hashes the JSON encoding of each settings value in
+// order. Maps encode with sorted keys, so equal settings hash equally.
+func SettingsStateRoot(values ...any) (string, error) {
+	leaves := make([][]byte, 0, len(values))
+	for _, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		leaves = append(leaves, leafHash(raw))
+	}
+	return hex.EncodeToString(merkleRoot(leaves)), nil
+}
+
+// StateRoot combines the settings root and the conversation roots, which
+// must be in ConversationStateHashes order, into one root.
+func StateRoot(settingsRoot string, conversations []models.ConversationStateHash) string {
+	leaves := make([][]byte, 0, len(conversations)+1)
+	leaves = append(leaves, leafHash([]byte("settings\x00"+settingsRoot)))
+	for _, conv := range conversations {
+		leaves = append(leaves, leafHash([]byte(conv.ConversationType+"\x00"+conv.ConversationID+"\x00"+conv.Root)))
+	}
+	return hex.EncodeToString(merkleRoot(leaves))
+}
+
+// DiffStateHashes reports whether the settings differ and which
+// conversations differ or exist on one side only.
+func DiffStateHashes(local, remote models.StateHashes) (bool, []string) {
+	settingsDiverged := local.SettingsRoot != remote.SettingsRoot
+	if local.Root == remote.Root && !settingsDiverged {
+		return false, nil
+	}
+	roots := make(map[string]string, len(local.Conversations))
+	for _, conv := range local.Conversations {
+		roots[conv.ConversationType+"\x00"+conv.ConversationID] = conv.Root
+	}
+	var diverged []string
+	for _, conv := range remote.Conversations {
+		key := conv.ConversationType + "\x00" + conv.ConversationID
+		if root, ok := roots[key]; !ok || root != conv.Root {
+			diverged = append(diverged, conv.ConversationID)
+		}
+		delete(roots, key)
+	}
+	for key := range roots {
+		_, conversationID, _ := strings.Cut(key, "\x00")
+		diverged = append(diverged, conversationID)
+	}
+	sort.Strings(diverged)
+	return settingsDiverged, diverged
+}
+
+// ValidateStateHashes checks the shape of received state hashes.
+func ValidateStateHashes(hashes *models.StateHashes) error {
+	if hashes == nil || !isStateHashHex(hashes.Root) || len(hashes.Conversations) > MaxStateHashConversations {
+		return ErrInvalidStateHashes
+	}
+	if hashes.SettingsRoot != "" && !isStateHashHex(hashes.SettingsRoot) {
+		return ErrInvalidStateHashes
+	}
+	for _, conv := range hashes.Conversations {
+		if strings.TrimSpace(conv.ConversationID) == "" || !isStateHashHex(conv.Root) || conv.MessageCount < 0 {
+			return ErrInvalidStateHashes
+		}
+		switch conv.ConversationType {
+		case models.ConversationTypeDirect, models.ConversationTypeGroup:
+		default:
+			return ErrInvalidStateHashes
+		}
+	}
+	return nil
+}
+
+func messageLeaf(key, contentType, threadID string, content []byte) []byte {
+	contentSum := sha256.Sum256(content)
+	return leafHash([]byte(key + "\x00" + contentType + "\x00" + strings.TrimSpace(threadID) + "\x00" + hex.EncodeToString(contentSum[:])))
+}
+
+func leafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{stateHashLeafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// merkleRoot folds leaf hashes pairwise; an odd node is carried up
+// unchanged. No leaves hash to the empty leaf.
+func merkleRoot(level [][]byte) []byte {
+	if len(level) == 0 {
+		return leafHash(nil)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			h := sha256.New()
+			h.Write([]byte{stateHashNodePrefix})
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func isStateHashHex(value string) bool {
+	if len(value) != hex.EncodedLen(sha256.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -9,11 +9,100 @@ import (
 )
 
 var ErrInvalidGroupWirePayload = errors.New("invalid group wire payload")
+var ErrInvalidCardWirePayload = errors.New("invalid contact card wire payload")
+var ErrTooManyWireCapabilities = errors.New("wire advertises too many capabilities")
+
+// maxWireCapabilities matches the limit on capabilities in contact cards.
+const maxWireCapabilities = 32
 
 const GroupWireEventTypeMessage = "message"
 
+// Card refresh wires let verified contacts ask each other for a current
+// self-signed contact card. A username claim pushes a card unasked when its
+// username changes.
+const (
+	WireKindCardRequest   = "card_request"
+	WireKindCardResponse  = "card_response"
+	WireKindUsernameClaim = "username_claim"
+)
+
 func ValidateWirePayload(wire contracts.WirePayload) error {
+	if err := ValidateMessageAttachments(wire.Attachments); err != nil {
+		return err
+	}
+	if len(wire.Caps) > maxWireCapabilities {
+		return ErrTooManyWireCapabilities
+	}
+	if (wire.Kind == WireKindCardResponse || wire.Kind == WireKindUsernameClaim) && wire.Card == nil {
+		return ErrInvalidCardWirePayload
+	}
+	if wire.SavedMessage != nil {
+		if wire.Kind != WireKindDeviceSync || len(wire.ConversationSync) > 0 || wire.StateHashes != nil {
+			return ErrInvalidSavedMessage
+		}
+		if err := ValidateSavedMessage(*wire.SavedMessage); err != nil {
+			return err
+		}
+	} else if wire.StateHashes != nil {
+		if (wire.Kind != WireKindDeviceSync && wire.Kind != WireKindStateHash) || len(wire.ConversationSync) > 0 {
+			return ErrInvalidStateHashes
+		}
+		if err := ValidateStateHashes(wire.StateHashes); err != nil {
+			return err
+		}
+		// A peer only hashes the conversation it shares with us.
+		if wire.Kind == WireKindStateHash && len(wire.StateHashes.Conversations) > 1 {
+			return ErrInvalidStateHashes
+		}
+	} else if wire.Kind == WireKindStateHash {
+		return ErrInvalidStateHashes
+	} else if wire.Kind == WireKindDeviceSync || len(wire.ConversationSync) > 0 {
+		if wire.Kind != WireKindDeviceSync || len(wire.ConversationSync) == 0 {
+			return ErrInvalidConversationSync
+		}
+		if err := ValidateConversationSync(wire.ConversationSync); err != nil {
+			return err
+		}
+	}
+	if wire.Kind == WireKindHistoryBackfill || wire.Backfill != nil {
+		if wire.Kind != WireKindHistoryBackfill {
+			return ErrInvalidHistoryBackfill
+		}
+		if err := ValidateHistoryBackfill(wire.Backfill); err != nil {
+			return err
+		}
+	}
+	if wire.Kind == WireKindMessagePin || wire.Pin != nil {
+		if wire.Kind != WireKindMessagePin {
+			return ErrInvalidMessagePin
+		}
+		if err := ValidateMessagePinUpdate(wire.Pin); err != nil {
+			return err
+		}
+	}
+	if wire.Kind == WireKindExportConsent || wire.ExportConsent != nil {
+		if wire.Kind != WireKindExportConsent {
+			return ErrInvalidExportConsent
+		}
+		if err := ValidateExportConsent(wire.ExportConsent); err != nil {
+			return err
+		}
+	}
+	if wire.ContentType != "" {
+		if wire.ContentType != models.MessageContentTypeLocation || (wire.Kind != "plain" && wire.Kind != "e2ee") {
+			return ErrInvalidMessageLocation
+		}
+		if wire.Kind == "plain" {
+			if _, err := DecodeMessageLocation(wire.Plain); err != nil {
+				return err
+			}
+		}
+	}
 	conversationType := strings.TrimSpace(wire.ConversationType)
+	if wire.GroupAvatar != nil && (conversationType != models.ConversationTypeGroup ||
+		strings.TrimSpace(wire.EventType) != string(groupdomain.GroupEventTypeProfileChange)) {
+		return ErrInvalidGroupWirePayload
+	}
 	switch conversationType {
 	case "", models.ConversationTypeDirect, models.ConversationTypeGroup:
 	default:
@@ -0,0 +1,95 @@
+package messaging_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/domains/contracts"
+	groupdomain "aim-chat/go-backend/internal/domains/group"
+	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestConversationStateHashesIgnoreDeviceLocalFields(t *testing.T) {
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	phone := map[string]models.Message{
+		"m1": {ID: "m1", ContactID: "bob", Direction: "out", Status: "read", ContentType: "text", Content: []byte("hi"), Timestamp: base},
+		"m2": {ID: "m2", ContactID: "bob", Direction: "in", Status: "delivered", ContentType: "text", Content: []byte("hey"), Timestamp: base.Add(time.Second)},
+		"g1": {ID: "gmsg_a", ContactID: "bob", ConversationID: "group-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-1", ContentType: "text", Content: []byte("post"), Timestamp: base},
+		"t1": {ID: "t1", ContactID: "carol", ConversationID: "group-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-1", ContentType: groupdomain.GroupFanoutTransportContentType, Content: []byte("post"), Timestamp: base},
+		"s1": {ID: "s1", ContactID: "bob", ConversationID: "group-1", ConversationType: models.ConversationTypeGroup, ContentType: models.MessageContentTypeSystem, Content: []byte("joined"), Timestamp: base},
+	}
+	// The same posts seen from another member: own copy id, status and
+	// receive times differ, and no delivery records or system entries.
+	peer := map[string]models.Message{
+		"m1": {ID: "m1", ContactID: "bob", Direction: "in", Status: "delivered", ContentType: "text", Content: []byte("hi"), Timestamp: base.Add(time.Minute)},
+		"m2": {ID: "m2", ContactID: "bob", Direction: "out", Status: "sent", ContentType: "text", Content: []byte("hey"), Timestamp: base},
+		"g1": {ID: "gmsg_b", ContactID: "alice", ConversationID: "group-1", ConversationType: models.ConversationTypeGroup, EventID: "evt-1", ContentType: "text", Content: []byte("post"), Timestamp: base.Add(time.Hour)},
+	}
+	left := messagingapp.ConversationStateHashes(phone)
+	right := messagingapp.ConversationStateHashes(peer)
+	if len(left) != 2 || !slices.Equal(left, right) {
+		t.Fatalf("expected equal hashes for equal message sets:\n%+v\n%+v", left, right)
+	}
+	if left[0].ConversationID != "bob" || left[0].MessageCount != 2 || left[1].ConversationID != "group-1" || left[1].MessageCount != 1 {
+		t.Fatalf("unexpected conversation summaries: %+v", left)
+	}
+
+	edited := peer["m2"]
+	edited.Content = []byte("hey!")
+	peer["m2"] = edited
+	if changed := messagingapp.ConversationStateHashes(peer); changed[0].Root == left[0].Root || changed[1].Root != left[1].Root {
+		t.Fatalf("expected only the edited conversation to change: %+v", changed)
+	}
+}
+
+func TestDiffStateHashesNamesDivergedConversations(t *testing.T) {
+	local := stateHashesFor(t, map[string]models.Message{
+		"m1": {ID: "m1", ContactID: "bob", ContentType: "text", Content: []byte("hi")},
+		"m2": {ID: "m2", ContactID: "carol", ContentType: "text", Content: []byte("yo")},
+	}, "flags-a")
+	remote := stateHashesFor(t, map[string]models.Message{
+		"m1": {ID: "m1", ContactID: "bob", ContentType: "text", Content: []byte("hi")},
+		"m3": {ID: "m3", ContactID: "dave", ContentType: "text", Content: []byte("hello")},
+	}, "flags-b")
+
+	settingsDiverged, conversations := messagingapp.DiffStateHashes(local, remote)
+	if !settingsDiverged || !slices.Equal(conversations, []string{"carol", "dave"}) {
+		t.Fatalf("unexpected divergence: settings=%v conversations=%v", settingsDiverged, conversations)
+	}
+	if settingsDiverged, conversations := messagingapp.DiffStateHashes(local, local); settingsDiverged || len(conversations) != 0 {
+		t.Fatalf("expected identical hashes to agree, got %v %v", settingsDiverged, conversations)
+	}
+}
+
+func TestValidateWirePayloadStateHashes(t *testing.T) {
+	hashes := stateHashesFor(t, map[string]models.Message{
+		"m1": {ID: "m1", ContactID: "bob", ContentType: "text", Content: []byte("hi")},
+		"m2": {ID: "m2", ContactID: "carol", ContentType: "text", Content: []byte("yo")},
+	}, "flags")
+	if err := messagingapp.ValidateWirePayload(contracts.WirePayload{Kind: messagingapp.WireKindDeviceSync, StateHashes: &hashes}); err != nil {
+		t.Fatalf("device sync state hashes must validate: %v", err)
+	}
+	for name, wire := range map[string]contracts.WirePayload{
+		"peer with every conversation": {Kind: messagingapp.WireKindStateHash, StateHashes: &hashes},
+		"kind without hashes":          {Kind: messagingapp.WireKindStateHash},
+		"hashes on a chat message":     {Kind: "plain", StateHashes: &hashes},
+		"malformed root":               {Kind: messagingapp.WireKindDeviceSync, StateHashes: &models.StateHashes{Root: "zz"}},
+	} {
+		if err := messagingapp.ValidateWirePayload(wire); !errors.Is(err, messagingapp.ErrInvalidStateHashes) {
+			t.Fatalf("%s: expected invalid state hashes, got %v", name, err)
+		}
+	}
+}
+
+func stateHashesFor(t *testing.T, messages map[string]models.Message, settings string) models.StateHashes {
+	t.Helper()
+	settingsRoot, err := messagingapp.SettingsStateRoot(settings)
+	if err != nil {
+		t.Fatalf("settings root: %v", err)
+	}
+	conversations := messagingapp.ConversationStateHashes(messages)
+	return models.StateHashes{Root: messagingapp.StateRoot(settingsRoot, conversations), SettingsRoot: settingsRoot, Conversations: conversations}
+}
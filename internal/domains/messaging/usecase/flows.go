@@ -108,6 +108,12 @@ func ResolveInboundContent(msg InboundPrivateMessage, wire contracts.WirePayload
 	return content, contentType, nil
 }
 
+// IsDeferrableDecryptError reports whether decryption failed only because the
+// session the sender used is not set up here yet, so a later retry may work.
+func IsDeferrableDecryptError(err error) bool {
+	return errors.Is(err, crypto.ErrSessionNotFound) || errors.Is(err, crypto.ErrSessionMismatch)
+}
+
 func BuildInboundStoredMessage(msg InboundPrivateMessage, threadID string, content []byte, contentType string, now time.Time) models.Message {
 	return models.Message{ID: msg.ID, ContactID: msg.SenderID, ConversationID: msg.SenderID, ConversationType: models.ConversationTypeDirect, ThreadID: strings.TrimSpace(threadID), Content: content, Timestamp: now.UTC(), Direction: "in", Status: "delivered", ContentType: contentType}
 }
@@ -121,6 +127,8 @@ type InboundReceiptHandling struct {
 	ShouldUpdate bool
 	MessageID    string
 	Status       string
+	// At is when the recipient reports the message was delivered or read.
+	At time.Time
 }
 
 type PendingMessage struct {
@@ -139,6 +147,7 @@ func ResolveInboundReceiptHandling(wire contracts.WirePayload) InboundReceiptHan
 		h.ShouldUpdate = true
 		h.MessageID = wire.Receipt.MessageID
 		h.Status = wire.Receipt.Status
+		h.At = wire.Receipt.Timestamp
 	}
 	return h
 }
@@ -169,6 +178,15 @@ func AllocateOutboundMessage(
 	return models.Message{}, errors.New("failed to allocate unique message id")
 }
 
+// OutboundWireContentType is the wire content type announcing msg's
+// structured content. Text goes without one.
+func OutboundWireContentType(msg models.Message) string {
+	if msg.ContentType == models.MessageContentTypeLocation {
+		return msg.ContentType
+	}
+	return ""
+}
+
 func NewPlainWire(content []byte) contracts.WirePayload {
 	return contracts.WirePayload{Kind: "plain", Plain: append([]byte(nil), content...)}
 }
@@ -200,11 +218,49 @@ func BuildWireForOutboundMessage(msg models.Message, session messageSessionAcces
 	return contracts.WirePayload{Kind: "e2ee", Envelope: env}, true, nil
 }
 
+// NewSavedMessageWire copies a note to self to the user's other devices.
+func NewSavedMessageWire(msg models.Message) contracts.WirePayload {
+	note := messagingpolicy.NewSavedMessage(msg)
+	return contracts.WirePayload{Kind: messagingpolicy.WireKindDeviceSync, SavedMessage: &note}
+}
+
 func NewReceiptWire(messageID, status string, now time.Time) contracts.WirePayload {
 	receipt := models.MessageReceipt{MessageID: messageID, Status: status, Timestamp: now.UTC()}
 	return contracts.WirePayload{Kind: "receipt", Receipt: &receipt}
 }
 
+func NewCardRequestWire() contracts.WirePayload {
+	return contracts.WirePayload{Kind: messagingpolicy.WireKindCardRequest}
+}
+
+func NewCardResponseWire(card models.ContactCard) contracts.WirePayload {
+	return contracts.WirePayload{Kind: messagingpolicy.WireKindCardResponse, Card: &card}
+}
+
+func NewUsernameClaimWire(card models.ContactCard) contracts.WirePayload {
+	return contracts.WirePayload{Kind: messagingpolicy.WireKindUsernameClaim, Card: &card}
+}
+
+// IsContactCardWire reports whether the wire belongs to the card refresh
+// exchange rather than to chat history.
+func IsContactCardWire(wire contracts.WirePayload) bool {
+	switch wire.Kind {
+	case messagingpolicy.WireKindCardRequest, messagingpolicy.WireKindCardResponse, messagingpolicy.WireKindUsernameClaim:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDirectChatWire reports whether the wire carries a direct chat message, the
+// only kind that can open a conversation with a stranger.
+func IsDirectChatWire(wire contracts.WirePayload) bool {
+	if wire.ConversationType == models.ConversationTypeGroup {
+		return false
+	}
+	return wire.Kind == "plain" || wire.Kind == "e2ee"
+}
+
 const RetryLoopTick = 1 * time.Second
 const StartupRecoveryLookahead = 24 * time.Hour
 
@@ -267,21 +323,6 @@ func ErrorCategory(err error) string {
 	return contracts.ErrorCategory(err)
 }
 
-func NextRetryTime(retryCount int) time.Time {
-	if retryCount < 1 {
-		retryCount = 1
-	}
-	backoff := 2 * time.Second
-	for i := 1; i < retryCount; i++ {
-		backoff *= 2
-		if backoff >= 30*time.Second {
-			backoff = 30 * time.Second
-			break
-		}
-	}
-	return time.Now().Add(backoff)
-}
-
 type RevocationFailure struct {
 	ContactID string
 	Category  string
@@ -329,23 +370,29 @@ func BuildWireAuthPayload(messageID, senderID, recipient string, wire contracts.
 		return nil, err
 	}
 	auth := struct {
-		MessageID         string `json:"message_id"`
-		SenderID          string `json:"sender_id"`
-		Recipient         string `json:"recipient"`
-		Kind              string `json:"kind"`
-		ConversationID    string `json:"conversation_id,omitempty"`
-		ConversationType  string `json:"conversation_type,omitempty"`
-		ThreadID          string `json:"thread_id,omitempty"`
-		EventID           string `json:"event_id,omitempty"`
-		EventType         string `json:"event_type,omitempty"`
-		MembershipVersion uint64 `json:"membership_version,omitempty"`
-		GroupKeyVersion   uint32 `json:"group_key_version,omitempty"`
-		SenderDeviceID    string `json:"sender_device_id,omitempty"`
-		Envelope          any    `json:"envelope"`
-		Plain             []byte `json:"plain"`
-		Card              any    `json:"card,omitempty"`
-		Receipt           any    `json:"receipt,omitempty"`
-		Revocation        any    `json:"revocation,omitempty"`
+		MessageID         string                         `json:"message_id"`
+		SenderID          string                         `json:"sender_id"`
+		Recipient         string                         `json:"recipient"`
+		Kind              string                         `json:"kind"`
+		ConversationID    string                         `json:"conversation_id,omitempty"`
+		ConversationType  string                         `json:"conversation_type,omitempty"`
+		ThreadID          string                         `json:"thread_id,omitempty"`
+		EventID           string                         `json:"event_id,omitempty"`
+		EventType         string                         `json:"event_type,omitempty"`
+		MembershipVersion uint64                         `json:"membership_version,omitempty"`
+		GroupKeyVersion   uint32                         `json:"group_key_version,omitempty"`
+		SenderDeviceID    string                         `json:"sender_device_id,omitempty"`
+		Envelope          any                            `json:"envelope"`
+		Plain             []byte                         `json:"plain"`
+		Card              any                            `json:"card,omitempty"`
+		Receipt           any                            `json:"receipt,omitempty"`
+		Revocation        any                            `json:"revocation,omitempty"`
+		Attachments       []models.MessageAttachment     `json:"attachments,omitempty"`
+		ConversationSync  []models.ConversationSyncState `json:"conversation_sync,omitempty"`
+		GroupAvatar       *models.GroupAvatar            `json:"group_avatar,omitempty"`
+		Seq               uint64                         `json:"seq,omitempty"`
+		Backfill          *models.HistoryBackfillRequest `json:"backfill,omitempty"`
+		SavedMessage      *models.SavedMessage           `json:"saved_message,omitempty"`
 	}{
 		MessageID:         messageID,
 		SenderID:          senderID,
@@ -364,6 +411,12 @@ func BuildWireAuthPayload(messageID, senderID, recipient string, wire contracts.
 		Card:              wire.Card,
 		Receipt:           wire.Receipt,
 		Revocation:        wire.Revocation,
+		Attachments:       wire.Attachments,
+		ConversationSync:  wire.ConversationSync,
+		GroupAvatar:       wire.GroupAvatar,
+		Seq:               wire.Seq,
+		Backfill:          wire.Backfill,
+		SavedMessage:      wire.SavedMessage,
 	}
 	return json.Marshal(auth)
 }
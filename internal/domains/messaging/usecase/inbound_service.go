@@ -5,9 +5,12 @@ import (
 	messagingpolicy "aim-chat/go-backend/internal/domains/messaging/policy"
 	"aim-chat/go-backend/pkg/models"
 	"encoding/json"
+	"errors"
 	"time"
 )
 
+var ErrUnexpectedReceiptChannelKind = errors.New("receipts channel carries a non-receipt payload")
+
 type InboundPolicyAction string
 
 const (
@@ -23,6 +26,7 @@ type InboundPolicyDecision struct {
 }
 
 type InboundServiceDeps struct {
+	IgnoreInboundSender         func(senderID string) bool
 	EvaluateInboundPolicy       func(senderID string) InboundPolicyDecision
 	ShouldAutoAddUnknownSender  func(decision InboundPolicyDecision, senderID, conversationType string, hasCard bool) bool
 	ShouldBypassInboundDevice   func(decision InboundPolicyDecision, senderID, conversationType string, hasCard bool) bool
@@ -36,10 +40,28 @@ type InboundServiceDeps struct {
 	HandleInboundGroupMessage   func(msg InboundPrivateMessage, wire contracts.WirePayload)
 	HandleInboundGroupEvent     func(msg InboundPrivateMessage, wire contracts.WirePayload)
 	ApplyInboundReceiptStatus   func(receiptHandling InboundReceiptHandling)
+	HandleContactCardWire       func(senderID string, wire contracts.WirePayload)
+	HandleDeviceSyncWire        func(msg InboundPrivateMessage, wire contracts.WirePayload)
+	HandleHistoryBackfillWire   func(senderID string, wire contracts.WirePayload)
+	HandleMessagePinWire        func(senderID string, wire contracts.WirePayload)
+	HandleStateHashWire         func(senderID string, wire contracts.WirePayload)
+	HandleExportConsentWire     func(senderID string, wire contracts.WirePayload)
+	ObserveInboundSeq           func(senderID string, wire contracts.WirePayload)
+	ObserveWireCapabilities     func(senderID string, wire contracts.WirePayload)
+	VerifyFirstContactPow       func(msg InboundPrivateMessage, wire contracts.WirePayload) error
+	VerifyFirstContactPayment   func(msg InboundPrivateMessage, wire contracts.WirePayload) error
+	ResolveInboundTip           func(msg InboundPrivateMessage, payment models.PaymentProof) *models.MessageTip
+	FilterInboundAttachments    func(msg InboundPrivateMessage, attachments []models.MessageAttachment) []models.MessageAttachment
 	PersistInboundMessage       func(in models.Message, senderID string) bool
 	PersistInboundRequest       func(in models.Message) bool
 	SendReceiptDelivered        func(senderID, messageID string) error
 	RecordError                 func(category string, err error)
+	InboundLimits               func() messagingpolicy.InboundLimits
+	ReportLimitViolation        func(senderID string, err error)
+	NoteContactOnline           func(contactID string)
+	// DeferInboundDecryption parks a ciphertext that arrived before its
+	// session and reports whether it was queued for RetryDeferredDecryption.
+	DeferInboundDecryption func(msg InboundPrivateMessage, wire contracts.WirePayload) bool
 }
 
 type InboundService struct {
@@ -51,6 +73,15 @@ func NewInboundService(deps InboundServiceDeps) *InboundService {
 }
 
 func (s *InboundService) HandleIncomingPrivateMessage(msg InboundPrivateMessage) {
+	if s.ignoresSender(msg.SenderID) {
+		return
+	}
+	if !s.withinInboundLimits(msg) {
+		return
+	}
+	if s.handleDeviceSyncWire(msg) {
+		return
+	}
 	content := append([]byte(nil), msg.Payload...)
 	contentType := "text"
 	decision, shouldStop := s.evaluateInboundPolicy(msg)
@@ -61,7 +92,53 @@ func (s *InboundService) HandleIncomingPrivateMessage(msg InboundPrivateMessage)
 	if handled {
 		return
 	}
-	s.persistInboundMessageAndReceipt(msg, wire.ThreadID, content, contentType)
+	s.persistInboundMessageAndReceipt(msg, wire, content, contentType)
+}
+
+// ignoresSender drops traffic from senders the identity is hidden from. It
+// runs before limits and policy so nothing is recorded or answered.
+func (s *InboundService) ignoresSender(senderID string) bool {
+	return s.deps.IgnoreInboundSender != nil && s.deps.IgnoreInboundSender(senderID)
+}
+
+// withinInboundLimits rejects oversized or unknown payloads before any trust
+// evaluation or decryption takes place.
+func (s *InboundService) withinInboundLimits(msg InboundPrivateMessage) bool {
+	if s.deps.InboundLimits == nil {
+		return true
+	}
+	limits := s.deps.InboundLimits()
+	var wire contracts.WirePayload
+	parsed := false
+	if limits.MaxWireBytes <= 0 || len(msg.Payload) <= limits.MaxWireBytes {
+		parsed = json.Unmarshal(msg.Payload, &wire) == nil
+	}
+	err := messagingpolicy.CheckInboundPayload(limits, msg.Payload, wire, parsed)
+	if err == nil {
+		return true
+	}
+	s.recordErr(contracts.ErrorCategoryAPI, err)
+	if s.deps.ReportLimitViolation != nil {
+		s.deps.ReportLimitViolation(msg.SenderID, err)
+	}
+	return false
+}
+
+// handleDeviceSyncWire routes payloads from our own devices before contact
+// policy runs, since our own identity is never a contact.
+func (s *InboundService) handleDeviceSyncWire(msg InboundPrivateMessage) bool {
+	var wire contracts.WirePayload
+	if err := json.Unmarshal(msg.Payload, &wire); err != nil || wire.Kind != messagingpolicy.WireKindDeviceSync {
+		return false
+	}
+	if err := messagingpolicy.ValidateWirePayload(wire); err != nil {
+		s.recordErr(contracts.ErrorCategoryAPI, err)
+		return true
+	}
+	if s.deps.HandleDeviceSyncWire != nil {
+		s.deps.HandleDeviceSyncWire(msg, wire)
+	}
+	return true
 }
 
 func (s *InboundService) evaluateInboundPolicy(msg InboundPrivateMessage) (InboundPolicyDecision, bool) {
@@ -97,11 +174,14 @@ func (s *InboundService) processInboundWire(
 ) (contracts.WirePayload, bool) {
 	wire, parsed, valid := s.decodeInboundWire(msg)
 	if !parsed {
-		return contracts.WirePayload{}, false
+		return contracts.WirePayload{}, !s.passesFirstContactGates(msg, contracts.WirePayload{})
 	}
 	if !valid {
 		return contracts.WirePayload{}, true
 	}
+	if IsDirectChatWire(wire) && !s.passesFirstContactGates(msg, wire) {
+		return contracts.WirePayload{}, true
+	}
 	hasCard := wire.Card != nil
 	if s.deps.ShouldAutoAddUnknownSender(decision, msg.SenderID, wire.ConversationType, hasCard) {
 		if err := s.deps.AddContactByIdentityID(msg.SenderID, msg.SenderID); err != nil {
@@ -126,6 +206,10 @@ func (s *InboundService) processInboundWire(
 			return contracts.WirePayload{}, true
 		}
 	}
+	s.noteContactOnline(msg.SenderID)
+	if len(wire.Caps) > 0 && s.deps.ObserveWireCapabilities != nil {
+		s.deps.ObserveWireCapabilities(msg.SenderID, wire)
+	}
 	if wire.ConversationType == models.ConversationTypeGroup {
 		if wire.EventType == messagingpolicy.GroupWireEventTypeMessage {
 			s.deps.HandleInboundGroupMessage(msg, wire)
@@ -141,10 +225,46 @@ func (s *InboundService) processInboundWire(
 		}
 		return contracts.WirePayload{}, true
 	}
+	if IsContactCardWire(wire) {
+		if s.deps.HandleContactCardWire != nil {
+			s.deps.HandleContactCardWire(msg.SenderID, wire)
+		}
+		return contracts.WirePayload{}, true
+	}
+	if wire.Kind == messagingpolicy.WireKindHistoryBackfill {
+		if s.deps.HandleHistoryBackfillWire != nil {
+			s.deps.HandleHistoryBackfillWire(msg.SenderID, wire)
+		}
+		return contracts.WirePayload{}, true
+	}
+	if wire.Kind == messagingpolicy.WireKindMessagePin {
+		if s.deps.HandleMessagePinWire != nil {
+			s.deps.HandleMessagePinWire(msg.SenderID, wire)
+		}
+		return contracts.WirePayload{}, true
+	}
+	if wire.Kind == messagingpolicy.WireKindStateHash {
+		if s.deps.HandleStateHashWire != nil {
+			s.deps.HandleStateHashWire(msg.SenderID, wire)
+		}
+		return contracts.WirePayload{}, true
+	}
+	if wire.Kind == messagingpolicy.WireKindExportConsent {
+		if s.deps.HandleExportConsentWire != nil {
+			s.deps.HandleExportConsentWire(msg.SenderID, wire)
+		}
+		return contracts.WirePayload{}, true
+	}
 	resolvedContent, resolvedType, decryptErr := s.deps.ResolveInboundContent(msg, wire)
 	if decryptErr != nil {
+		if IsDeferrableDecryptError(decryptErr) && s.deps.DeferInboundDecryption != nil && s.deps.DeferInboundDecryption(msg, wire) {
+			return contracts.WirePayload{}, true
+		}
 		s.recordErr(contracts.ErrorCategoryCrypto, decryptErr)
 	}
+	if messagingpolicy.IsCoverTrafficContent(resolvedContent) {
+		return contracts.WirePayload{}, true
+	}
 	*content = resolvedContent
 	*contentType = resolvedType
 	return wire, false
@@ -152,18 +272,24 @@ func (s *InboundService) processInboundWire(
 
 func (s *InboundService) persistInboundMessageAndReceipt(
 	msg InboundPrivateMessage,
-	threadID string,
+	wire contracts.WirePayload,
 	content []byte,
 	contentType string,
 ) {
-	s.persistInboundAndSendReceipt(msg, threadID, content, contentType, func(in models.Message) bool {
-		return s.deps.PersistInboundMessage(in, msg.SenderID)
+	s.persistInboundAndSendReceipt(msg, wire, content, contentType, func(in models.Message) bool {
+		if !s.deps.PersistInboundMessage(in, msg.SenderID) {
+			return false
+		}
+		if s.deps.ObserveInboundSeq != nil {
+			s.deps.ObserveInboundSeq(msg.SenderID, wire)
+		}
+		return true
 	})
 }
 
 func (s *InboundService) persistInboundAndSendReceipt(
 	msg InboundPrivateMessage,
-	threadID string,
+	wire contracts.WirePayload,
 	content []byte,
 	contentType string,
 	persist func(models.Message) bool,
@@ -171,7 +297,18 @@ func (s *InboundService) persistInboundAndSendReceipt(
 	if persist == nil {
 		return
 	}
-	in := BuildInboundStoredMessage(msg, threadID, content, contentType, time.Now())
+	in := BuildInboundStoredMessage(msg, wire.ThreadID, content, contentType, time.Now())
+	in.Seq = wire.Seq
+	if wire.ContentType == models.MessageContentTypeLocation {
+		messagingpolicy.ApplyMessageLocation(&in)
+	}
+	in.Attachments = append([]models.MessageAttachment(nil), wire.Attachments...)
+	if len(in.Attachments) > 0 && s.deps.FilterInboundAttachments != nil {
+		in.Attachments = s.deps.FilterInboundAttachments(msg, in.Attachments)
+	}
+	if wire.Payment != nil && wire.Payment.Purpose == models.PaymentPurposeTip && s.deps.ResolveInboundTip != nil {
+		in.Tip = s.deps.ResolveInboundTip(msg, *wire.Payment)
+	}
 	if !persist(in) {
 		return
 	}
@@ -183,6 +320,69 @@ func (s *InboundService) persistInboundAndSendReceipt(
 	}
 }
 
+// RetryDeferredDecryption decrypts a ciphertext parked by
+// DeferInboundDecryption and stores the message. It reports false, leaving
+// the entry parked, while the session is still missing and final is unset. A
+// final retry stores the message even when it stays unreadable and returns
+// the decryption error.
+func (s *InboundService) RetryDeferredDecryption(msg InboundPrivateMessage, wire contracts.WirePayload, final bool) (bool, error) {
+	content, contentType, decryptErr := s.deps.ResolveInboundContent(msg, wire)
+	if decryptErr != nil {
+		if !final && IsDeferrableDecryptError(decryptErr) {
+			return false, decryptErr
+		}
+		s.recordErr(contracts.ErrorCategoryCrypto, decryptErr)
+	}
+	if messagingpolicy.IsCoverTrafficContent(content) {
+		return true, nil
+	}
+	s.persistInboundMessageAndReceipt(msg, wire, content, contentType)
+	return true, decryptErr
+}
+
+// HandleIncomingReceipt processes a message from the dedicated receipts
+// channel. Only signed receipts from verified contacts are applied; anything
+// else on that channel is dropped without touching message history.
+func (s *InboundService) HandleIncomingReceipt(msg InboundPrivateMessage) {
+	if s.ignoresSender(msg.SenderID) {
+		return
+	}
+	if !s.withinInboundLimits(msg) {
+		return
+	}
+	if decision := s.deps.EvaluateInboundPolicy(msg.SenderID); decision.Action != InboundPolicyActionAccept {
+		return
+	}
+	if !s.deps.HasVerifiedContact(msg.SenderID) {
+		return
+	}
+	wire, parsed, valid := s.decodeInboundWire(msg)
+	if !parsed || !valid {
+		return
+	}
+	if wire.Kind != "receipt" || wire.ConversationType == models.ConversationTypeGroup {
+		s.recordErr(contracts.ErrorCategoryAPI, ErrUnexpectedReceiptChannelKind)
+		return
+	}
+	if err := s.deps.ValidateInboundDeviceAuth(msg, wire); err != nil {
+		s.recordErr(ErrorCategory(err), err)
+		return
+	}
+	s.noteContactOnline(msg.SenderID)
+	receiptHandling := ResolveInboundReceiptHandling(wire)
+	if receiptHandling.ShouldUpdate && s.deps.ApplyInboundReceiptStatus != nil {
+		s.deps.ApplyInboundReceiptStatus(receiptHandling)
+	}
+}
+
+// noteContactOnline reports traffic from a sender that passed trust and
+// device checks, which shows the contact is reachable right now.
+func (s *InboundService) noteContactOnline(senderID string) {
+	if s.deps.NoteContactOnline != nil {
+		s.deps.NoteContactOnline(senderID)
+	}
+}
+
 func (s *InboundService) HandleInboundMessageRequest(msg InboundPrivateMessage) {
 	content := append([]byte(nil), msg.Payload...)
 	contentType := "text"
@@ -193,7 +393,12 @@ func (s *InboundService) HandleInboundMessageRequest(msg InboundPrivateMessage)
 			return
 		}
 		receiptHandling := ResolveInboundReceiptHandling(wire)
-		if receiptHandling.Handled {
+		if receiptHandling.Handled || IsContactCardWire(wire) || wire.Kind == messagingpolicy.WireKindHistoryBackfill ||
+			wire.Kind == messagingpolicy.WireKindMessagePin || wire.Kind == messagingpolicy.WireKindStateHash ||
+			wire.Kind == messagingpolicy.WireKindExportConsent {
+			return
+		}
+		if !s.passesFirstContactGates(msg, wire) {
 			return
 		}
 		var decryptErr error
@@ -201,8 +406,31 @@ func (s *InboundService) HandleInboundMessageRequest(msg InboundPrivateMessage)
 		if decryptErr != nil {
 			s.recordErr(contracts.ErrorCategoryCrypto, decryptErr)
 		}
+		if messagingpolicy.IsCoverTrafficContent(content) {
+			return
+		}
+	} else if !s.passesFirstContactGates(msg, wire) {
+		return
+	}
+	s.persistInboundAndSendReceipt(msg, wire, content, contentType, s.deps.PersistInboundRequest)
+}
+
+// passesFirstContactGates drops chat messages from unknown senders that lack
+// the proof-of-work stamp or the payment the recipient asks for.
+func (s *InboundService) passesFirstContactGates(msg InboundPrivateMessage, wire contracts.WirePayload) bool {
+	for _, verify := range []func(InboundPrivateMessage, contracts.WirePayload) error{
+		s.deps.VerifyFirstContactPow,
+		s.deps.VerifyFirstContactPayment,
+	} {
+		if verify == nil {
+			continue
+		}
+		if err := verify(msg, wire); err != nil {
+			s.recordErr(contracts.ErrorCategoryAPI, err)
+			return false
+		}
 	}
-	s.persistInboundAndSendReceipt(msg, wire.ThreadID, content, contentType, s.deps.PersistInboundRequest)
+	return true
 }
 
 func (s *InboundService) recordErr(category string, err error) {
@@ -1,10 +1,13 @@
 package usecase
 
 import (
+	"aim-chat/go-backend/internal/crypto"
 	"aim-chat/go-backend/internal/domains/contracts"
+	messagingpolicy "aim-chat/go-backend/internal/domains/messaging/policy"
 	"aim-chat/go-backend/pkg/models"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -75,6 +78,29 @@ func TestInboundService_RejectPolicyStopsProcessing(t *testing.T) {
 	}
 }
 
+func TestInboundService_IgnoredSenderLeavesNoTrace(t *testing.T) {
+	deps := defaultInboundDeps()
+	deps.IgnoreInboundSender = func(senderID string) bool { return senderID == "stranger" }
+	deps.EvaluateInboundPolicy = func(senderID string) InboundPolicyDecision {
+		t.Fatalf("policy must not run for ignored sender %q", senderID)
+		return InboundPolicyDecision{}
+	}
+	deps.RecordError = func(category string, err error) {
+		t.Fatalf("ignored sender must not be recorded: %s %v", category, err)
+	}
+	deps.ReportLimitViolation = func(senderID string, err error) {
+		t.Fatalf("ignored sender must not raise limit alerts: %v", err)
+	}
+	deps.PersistInboundRequest = func(in models.Message) bool {
+		t.Fatal("ignored sender must not reach the request inbox")
+		return false
+	}
+	service := NewInboundService(deps)
+
+	service.HandleIncomingPrivateMessage(InboundPrivateMessage{ID: "m1", SenderID: "stranger", Payload: []byte("hello")})
+	service.HandleIncomingReceipt(InboundPrivateMessage{ID: "r1", SenderID: "stranger", Payload: []byte("{}")})
+}
+
 func TestInboundService_ReceiptUpdateHandledWithoutPersist(t *testing.T) {
 	now := time.Now().UTC()
 	payload, err := json.Marshal(contracts.WirePayload{
@@ -370,3 +396,189 @@ func TestInboundService_RequestFlowInvalidWireStopsWithAPIError(t *testing.T) {
 		t.Fatalf("invalid wire payload should not be persisted in request flow")
 	}
 }
+
+func TestInboundService_ReceiptChannelAppliesOnlyVerifiedReceipts(t *testing.T) {
+	receipt := mustMarshalWirePayload(t, contracts.WirePayload{
+		Kind:    "receipt",
+		Receipt: &models.MessageReceipt{MessageID: "msg-7", Status: "read", Timestamp: time.Now().UTC()},
+	})
+	plain := mustMarshalWirePayload(t, contracts.WirePayload{Kind: "plain", Plain: []byte("hello")})
+
+	applied := 0
+	persisted := 0
+	recorded := make([]error, 0, 1)
+	verified := false
+	deps := defaultInboundDeps()
+	deps.HasVerifiedContact = func(senderID string) bool { return verified }
+	deps.ApplyInboundReceiptStatus = func(h InboundReceiptHandling) {
+		if h.MessageID == "msg-7" && h.Status == "read" {
+			applied++
+		}
+	}
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		persisted++
+		return true
+	}
+	deps.RecordError = func(category string, err error) { recorded = append(recorded, err) }
+	service := NewInboundService(deps)
+
+	service.HandleIncomingReceipt(InboundPrivateMessage{ID: "r1", SenderID: "alice", Payload: receipt})
+	if applied != 0 {
+		t.Fatalf("receipt from unverified sender must be ignored")
+	}
+
+	verified = true
+	service.HandleIncomingReceipt(InboundPrivateMessage{ID: "r2", SenderID: "alice", Payload: receipt})
+	if applied != 1 {
+		t.Fatalf("expected receipt to be applied once, got %d", applied)
+	}
+
+	service.HandleIncomingReceipt(InboundPrivateMessage{ID: "r3", SenderID: "alice", Payload: plain})
+	if persisted != 0 {
+		t.Fatalf("receipts channel must never persist messages")
+	}
+	if len(recorded) != 1 || !errors.Is(recorded[0], ErrUnexpectedReceiptChannelKind) {
+		t.Fatalf("expected unexpected kind error, got %v", recorded)
+	}
+}
+
+func TestInboundService_PersistsAttachmentAltText(t *testing.T) {
+	var persisted models.Message
+	deps := defaultInboundDeps()
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		persisted = in
+		return true
+	}
+	service := NewInboundService(deps)
+	payload := mustMarshalWirePayload(t, contracts.WirePayload{
+		Kind:        "plain",
+		Plain:       []byte("photo"),
+		Attachments: []models.MessageAttachment{{ID: "att1_x", Name: "cat.png", MimeType: "image/png", Size: 10, AltText: "A cat asleep on a keyboard"}},
+	})
+
+	service.HandleIncomingPrivateMessage(InboundPrivateMessage{ID: "m-att", SenderID: "alice", Payload: payload})
+
+	if len(persisted.Attachments) != 1 || persisted.Attachments[0].AltText != "A cat asleep on a keyboard" {
+		t.Fatalf("expected attachment alt text to be stored, got %+v", persisted.Attachments)
+	}
+}
+
+func TestInboundService_RejectsOversizedAttachmentAltText(t *testing.T) {
+	persistCalled := false
+	deps := defaultInboundDeps()
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		persistCalled = true
+		return true
+	}
+	service := NewInboundService(deps)
+	payload := mustMarshalWirePayload(t, contracts.WirePayload{
+		Kind:        "plain",
+		Plain:       []byte("photo"),
+		Attachments: []models.MessageAttachment{{ID: "att1_x", AltText: strings.Repeat("a", models.MaxAttachmentAltTextRunes+1)}},
+	})
+
+	service.HandleIncomingPrivateMessage(InboundPrivateMessage{ID: "m-att", SenderID: "alice", Payload: payload})
+
+	if persistCalled {
+		t.Fatal("wire with oversized alt text must be dropped")
+	}
+}
+
+func TestInboundService_CoverTrafficDroppedSilently(t *testing.T) {
+	deps := defaultInboundDeps()
+	deps.ResolveInboundContent = func(msg InboundPrivateMessage, wire contracts.WirePayload) ([]byte, string, error) {
+		return messagingpolicy.NewCoverTrafficContent([]byte("filler")), "e2ee", nil
+	}
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		t.Fatal("cover traffic must not be stored")
+		return false
+	}
+	deps.SendReceiptDelivered = func(senderID, messageID string) error {
+		t.Fatal("cover traffic must not be acknowledged")
+		return nil
+	}
+	resolved := false
+	resolve := deps.ResolveInboundContent
+	deps.ResolveInboundContent = func(msg InboundPrivateMessage, wire contracts.WirePayload) ([]byte, string, error) {
+		resolved = true
+		return resolve(msg, wire)
+	}
+	service := NewInboundService(deps)
+
+	payload := mustMarshalWirePayload(t, contracts.WirePayload{Kind: "e2ee"})
+	service.HandleIncomingPrivateMessage(InboundPrivateMessage{ID: "m1", SenderID: "alice", Payload: payload})
+	if !resolved {
+		t.Fatal("cover traffic must be decrypted before it is dropped")
+	}
+}
+
+func TestInboundService_DefersCiphertextUntilSessionExists(t *testing.T) {
+	sessionReady := false
+	var parked []InboundPrivateMessage
+	var persisted []models.Message
+	deps := defaultInboundDeps()
+	deps.ResolveInboundContent = func(msg InboundPrivateMessage, wire contracts.WirePayload) ([]byte, string, error) {
+		if !sessionReady {
+			return append([]byte(nil), msg.Payload...), "e2ee-unreadable", crypto.ErrSessionNotFound
+		}
+		return []byte("hello"), "e2ee", nil
+	}
+	deps.DeferInboundDecryption = func(msg InboundPrivateMessage, wire contracts.WirePayload) bool {
+		parked = append(parked, msg)
+		return true
+	}
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		persisted = append(persisted, in)
+		return true
+	}
+	deps.RecordError = func(category string, err error) {
+		t.Fatalf("a deferred ciphertext must not be recorded as a crypto error: %v", err)
+	}
+	service := NewInboundService(deps)
+	payload := mustMarshalWirePayload(t, contracts.WirePayload{Kind: "e2ee"})
+	msg := InboundPrivateMessage{ID: "m1", SenderID: "alice", Payload: payload}
+
+	service.HandleIncomingPrivateMessage(msg)
+	if len(parked) != 1 || len(persisted) != 0 {
+		t.Fatalf("expected the ciphertext parked and nothing stored, parked=%d persisted=%d", len(parked), len(persisted))
+	}
+	if done, _ := service.RetryDeferredDecryption(msg, contracts.WirePayload{Kind: "e2ee"}, false); done {
+		t.Fatal("expected the retry to keep waiting for the session")
+	}
+
+	sessionReady = true
+	if done, err := service.RetryDeferredDecryption(msg, contracts.WirePayload{Kind: "e2ee"}, false); !done || err != nil {
+		t.Fatalf("expected the retry to decrypt, done=%v err=%v", done, err)
+	}
+	if len(persisted) != 1 || persisted[0].ContentType != "e2ee" || string(persisted[0].Content) != "hello" {
+		t.Fatalf("unexpected stored message: %+v", persisted)
+	}
+}
+
+func TestInboundService_FinalDeferredRetryStoresUnreadable(t *testing.T) {
+	var recorded []string
+	var persisted []models.Message
+	deps := defaultInboundDeps()
+	deps.ResolveInboundContent = func(msg InboundPrivateMessage, wire contracts.WirePayload) ([]byte, string, error) {
+		return append([]byte(nil), msg.Payload...), "e2ee-unreadable", crypto.ErrSessionMismatch
+	}
+	deps.PersistInboundMessage = func(in models.Message, senderID string) bool {
+		persisted = append(persisted, in)
+		return true
+	}
+	deps.RecordError = func(category string, err error) {
+		recorded = append(recorded, category)
+	}
+	service := NewInboundService(deps)
+
+	done, err := service.RetryDeferredDecryption(InboundPrivateMessage{ID: "m1", SenderID: "alice", Payload: []byte("sealed")}, contracts.WirePayload{Kind: "e2ee"}, true)
+	if !done || !errors.Is(err, crypto.ErrSessionMismatch) {
+		t.Fatalf("expected the final retry to give up with the decrypt error, done=%v err=%v", done, err)
+	}
+	if len(persisted) != 1 || persisted[0].ContentType != "e2ee-unreadable" {
+		t.Fatalf("expected an unreadable message stored, got %+v", persisted)
+	}
+	if len(recorded) != 1 || recorded[0] != contracts.ErrorCategoryCrypto {
+		t.Fatalf("expected one crypto error, got %v", recorded)
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrInvalidOutboxCategory = errors.New("invalid outbox category")
+
+var outboxCategories = []string{
+	models.OutboxPending,
+	models.OutboxScheduled,
+	models.OutboxFailed,
+	models.OutboxSentUnread,
+}
+
+// ParseOutboxCategories validates a category filter. An empty filter selects
+// every category.
+func ParseOutboxCategories(raw []string) (map[string]bool, error) {
+	selected := make(map[string]bool, len(outboxCategories))
+	for _, category := range raw {
+		category = strings.TrimSpace(category)
+		valid := false
+		for _, known := range outboxCategories {
+			if category == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, ErrInvalidOutboxCategory
+		}
+		selected[category] = true
+	}
+	if len(selected) == 0 {
+		for _, known := range outboxCategories {
+			selected[known] = true
+		}
+	}
+	return selected, nil
+}
+
+// OutboxCategory places an outbound message in the outbox. Messages that are
+// read, cancelled or inbound are not part of it.
+func OutboxCategory(msg models.Message, queued *PendingMessage, now time.Time) (string, bool) {
+	if msg.Direction != "out" || msg.ContentType == models.MessageContentTypeSystem {
+		return "", false
+	}
+	switch msg.Status {
+	case "", "pending":
+		if queued != nil && queued.RetryCount > 0 && queued.NextRetry.After(now) {
+			return models.OutboxScheduled, true
+		}
+		return models.OutboxPending, true
+	case "failed":
+		return models.OutboxFailed, true
+	case "sent", "delivered":
+		return models.OutboxSentUnread, true
+	default:
+		return "", false
+	}
+}
+
+// BuildOutbox lists outbound messages across all conversations in the
+// selected categories, newest first.
+func BuildOutbox(messages []models.Message, pending map[string]PendingMessage, categories map[string]bool, now time.Time, limit, offset int) []models.OutboxEntry {
+	entries := make([]models.OutboxEntry, 0)
+	for _, msg := range messages {
+		var queued *PendingMessage
+		if p, ok := pending[msg.ID]; ok {
+			queued = &p
+		}
+		category, ok := OutboxCategory(msg, queued, now)
+		if !ok || !categories[category] {
+			continue
+		}
+		entry := models.OutboxEntry{
+			Message:  models.SummarizeMessage(msg, models.DefaultMessagePreviewRunes),
+			Category: category,
+		}
+		if queued != nil {
+			entry.RetryCount = queued.RetryCount
+			entry.NextRetry = queued.NextRetry
+			entry.LastError = queued.LastError
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].Message, entries[j].Message
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.ID < b.ID
+	})
+	if offset >= len(entries) {
+		return []models.OutboxEntry{}
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries
+}
@@ -4,7 +4,9 @@ import (
 	"aim-chat/go-backend/internal/domains/contracts"
 	messagingpolicy "aim-chat/go-backend/internal/domains/messaging/policy"
 	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/events"
 	"aim-chat/go-backend/pkg/models"
+	"context"
 	"errors"
 	"strings"
 	"time"
@@ -16,13 +18,31 @@ type ServiceDeps struct {
 	Messages contracts.MessageRepository
 
 	GenerateID          func(prefix string) (string, error)
+	Now                 func() time.Time
 	TrackOperation      func(operation string, errRef *error) func()
-	PublishQueued       func(msg models.Message, contactID string, wire contracts.WirePayload) (string, error)
+	PublishQueued       func(ctx context.Context, msg models.Message, contactID string, wire contracts.WirePayload) (string, error)
 	ApplyAutoRead       func(message *models.Message, contactID string)
 	PublishPrivate      func(msg waku.PrivateMessage) error
 	Notify              func(method string, payload any)
 	RecordError         func(category string, err error)
 	IsMessageIDConflict func(err error) bool
+	ResolveAttachments  func(attachmentIDs []string) ([]models.MessageAttachment, error)
+	// ResolveContactID redirects sends to the id a merged contact moved to.
+	ResolveContactID func(contactID string) string
+	// NextSequence reserves the next per-contact sequence number that lets
+	// the recipient detect messages that never arrived.
+	NextSequence func(contactID string) (uint64, error)
+	// SessionEstablished runs after a session with contactID is set up or
+	// replaced.
+	SessionEstablished func(contactID string)
+	// IsBlocked reports whether contactID is on the blocklist.
+	IsBlocked func(contactID string) bool
+	// HasMessageRequest reports whether contactID waits in the message
+	// request inbox.
+	HasMessageRequest func(contactID string) bool
+	// ProcessOutboundDraft lets local plugins observe a draft and rewrite or
+	// reject its content before it is stored.
+	ProcessOutboundDraft func(ctx context.Context, contactID, threadID, content string) (string, error)
 }
 
 type Service struct {
@@ -33,45 +53,140 @@ func NewService(deps ServiceDeps) *Service {
 	return &Service{deps: deps}
 }
 
-func (s *Service) SendMessage(contactID, content string) (msgID string, err error) {
-	return s.sendMessageWithThread(contactID, content, "")
+func (s *Service) now() time.Time {
+	if s.deps.Now == nil {
+		return time.Now()
+	}
+	return s.deps.Now()
+}
+
+// checkOutboundPolicy refuses sends to blocked senders and to anyone not
+// added as a contact, naming the setting and the RPC that would allow them.
+func (s *Service) checkOutboundPolicy(contactID string) error {
+	if s.deps.IsBlocked != nil && s.deps.IsBlocked(contactID) {
+		return contracts.RejectByPolicy(messagingpolicy.ErrContactBlocked,
+			contracts.PolicyReasonBlocked, contracts.PolicySettingBlocklist, "blocklist.remove", contactID)
+	}
+	if s.deps.Identity.HasContact(contactID) {
+		return nil
+	}
+	if s.deps.HasMessageRequest != nil && s.deps.HasMessageRequest(contactID) {
+		return contracts.RejectByPolicy(messagingpolicy.ErrContactNotAdded,
+			contracts.PolicyReasonPrivacyMode, contracts.PolicySettingMessagePrivacyMode, "request.accept", contactID)
+	}
+	return contracts.RejectByPolicy(messagingpolicy.ErrContactNotAdded,
+		contracts.PolicyReasonNotContact, contracts.PolicySettingContacts, "contact.add", contactID)
+}
+
+func (s *Service) SendMessage(ctx context.Context, contactID, content string) (msgID string, err error) {
+	return s.sendMessageWithThread(ctx, contactID, content, "", nil, nil)
+}
+
+// SendLocation shares a location with contactID. The content is the JSON
+// encoded location; the stored message also carries it decoded in Location.
+func (s *Service) SendLocation(ctx context.Context, contactID string, location models.MessageLocation) (msgID string, err error) {
+	location, err = messagingpolicy.NormalizeMessageLocation(location)
+	if err != nil {
+		return "", err
+	}
+	content, err := messagingpolicy.EncodeMessageLocation(location)
+	if err != nil {
+		return "", err
+	}
+	return s.sendMessageWithThread(ctx, contactID, string(content), "", nil, &location)
+}
+
+// SendMessageWithAttachments sends a message referencing locally stored
+// attachments. Their metadata, including alt text, travels with the message.
+func (s *Service) SendMessageWithAttachments(ctx context.Context, contactID, content string, attachmentIDs []string) (msgID string, err error) {
+	if len(attachmentIDs) == 0 {
+		return s.sendMessageWithThread(ctx, contactID, content, "", nil, nil)
+	}
+	if s.deps.ResolveAttachments == nil {
+		return "", errors.New("message attachments are not supported")
+	}
+	attachments, err := s.deps.ResolveAttachments(attachmentIDs)
+	if err != nil {
+		return "", err
+	}
+	if err := messagingpolicy.ValidateMessageAttachments(attachments); err != nil {
+		return "", err
+	}
+	return s.sendMessageWithThread(ctx, contactID, content, "", attachments, nil)
 }
 
-func (s *Service) SendMessageInThread(contactID, content, threadID string) (msgID string, err error) {
+func (s *Service) SendMessageInThread(ctx context.Context, contactID, content, threadID string) (msgID string, err error) {
 	threadID = strings.TrimSpace(threadID)
 	if threadID == "" {
 		return "", errors.New("thread id is required")
 	}
-	return s.sendMessageWithThread(contactID, content, threadID)
+	return s.sendMessageWithThread(ctx, contactID, content, threadID, nil, nil)
 }
 
-func (s *Service) sendMessageWithThread(contactID, content, threadID string) (msgID string, err error) {
+func (s *Service) sendMessageWithThread(ctx context.Context, contactID, content, threadID string, attachments []models.MessageAttachment, location *models.MessageLocation) (msgID string, err error) {
 	if s.deps.TrackOperation != nil {
 		defer s.deps.TrackOperation("message.send", &err)()
 	}
+	// Nothing is stored yet, so a caller that already gave up gets an error
+	// rather than a queued message.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	contactID, content, err = ParseSendMessageInput(contactID, content)
 	if err != nil {
 		return "", err
 	}
-	if !s.deps.Identity.HasContact(contactID) {
-		return "", errors.New("contact is not added")
+	contactID = s.resolveContactID(contactID)
+	saved := s.isSavedMessages(contactID)
+	if !saved {
+		if err := s.checkOutboundPolicy(contactID); err != nil {
+			return "", err
+		}
+	}
+	// Plugins rewrite text; a location's structured content is left alone.
+	if s.deps.ProcessOutboundDraft != nil && location == nil {
+		processed, perr := s.deps.ProcessOutboundDraft(ctx, contactID, threadID, content)
+		if perr != nil {
+			return "", perr
+		}
+		if _, content, err = ParseSendMessageInput(contactID, processed); err != nil {
+			return "", err
+		}
 	}
 
-	draft := BuildOutboundDraft("draft", contactID, content, time.Now())
+	withContent := func(msg *models.Message) {
+		msg.Attachments = attachments
+		if location != nil {
+			msg.ContentType = models.MessageContentTypeLocation
+			msg.Location = location
+		}
+	}
+	draft := BuildOutboundDraft("draft", contactID, content, s.now())
 	draft.ThreadID = threadID
+	withContent(&draft)
 	wire, werr := s.BuildStoredMessageWire(draft)
 	if werr != nil {
 		s.deps.RecordError(contracts.ErrorCategoryCrypto, werr)
 		return "", werr
 	}
+	if s.deps.NextSequence != nil && !saved {
+		seq, serr := s.deps.NextSequence(contactID)
+		if serr != nil {
+			s.deps.RecordError(contracts.ErrorCategoryStorage, serr)
+			return "", serr
+		}
+		wire.Seq = seq
+	}
 
 	msg, err := AllocateOutboundMessage(
 		contactID,
 		content,
 		threadID,
-		time.Now,
+		s.now,
 		func() (string, error) { return s.deps.GenerateID("msg") },
 		func(msg models.Message) error {
+			withContent(&msg)
+			msg.Seq = wire.Seq
 			err := s.deps.Messages.SaveMessage(msg)
 			if err != nil && (s.deps.IsMessageIDConflict == nil || !s.deps.IsMessageIDConflict(err)) {
 				s.deps.RecordError(contracts.ErrorCategoryStorage, err)
@@ -83,12 +198,14 @@ func (s *Service) sendMessageWithThread(contactID, content, threadID string) (ms
 	if err != nil {
 		return "", err
 	}
+	withContent(&msg)
+	msg.Seq = wire.Seq
+	if saved {
+		wire = NewSavedMessageWire(msg)
+	}
 
-	s.deps.Notify("notify.message.new", map[string]any{
-		"contact_id": contactID,
-		"message":    msg,
-	})
-	return s.deps.PublishQueued(msg, contactID, wire)
+	s.deps.Notify(events.MethodMessageNew, events.MessageNew{ContactID: contactID, Message: msg})
+	return s.deps.PublishQueued(ctx, msg, contactID, wire)
 }
 
 func (s *Service) EditMessage(contactID, messageID, content string) (models.Message, error) {
@@ -100,6 +217,9 @@ func (s *Service) EditMessage(contactID, messageID, content string) (models.Mess
 	if err := ValidateEditableMessage(msg, ok, contactID); err != nil {
 		return models.Message{}, err
 	}
+	if s.resolveContactID(contactID) != contactID {
+		return models.Message{}, messagingpolicy.ErrConversationReadOnly
+	}
 
 	updated, ok, err := s.deps.Messages.UpdateMessageContent(messageID, []byte(content), msg.ContentType)
 	if err != nil {
@@ -176,6 +296,63 @@ func (s *Service) GetMessages(contactID string, limit, offset int) (messages []m
 	return messages, nil
 }
 
+// PeekMessages lists messages like GetMessages but leaves them unread and
+// sends no read receipts, for readers acting without the owner.
+func (s *Service) PeekMessages(contactID string, limit, offset int) (messages []models.Message, err error) {
+	if s.deps.TrackOperation != nil {
+		defer s.deps.TrackOperation("message.list", &err)()
+	}
+	contactID, err = ParseMessageListContactID(contactID)
+	if err != nil {
+		return nil, err
+	}
+	return s.deps.Messages.ListMessages(contactID, limit, offset), nil
+}
+
+// GetMessageSummaries is the metadata-only variant of GetMessages. It does not
+// mark messages as read because no body is shown to the user.
+func (s *Service) GetMessageSummaries(contactID string, limit, offset int) (summaries []models.MessageSummary, err error) {
+	if s.deps.TrackOperation != nil {
+		defer s.deps.TrackOperation("message.list", &err)()
+	}
+	contactID, err = ParseMessageListContactID(contactID)
+	if err != nil {
+		return nil, err
+	}
+	if lister, ok := s.deps.Messages.(interface {
+		ListMessageSummaries(contactID string, limit, offset, previewRunes int) []models.MessageSummary
+	}); ok {
+		return lister.ListMessageSummaries(contactID, limit, offset, models.DefaultMessagePreviewRunes), nil
+	}
+	messages := s.deps.Messages.ListMessages(contactID, limit, offset)
+	summaries = make([]models.MessageSummary, 0, len(messages))
+	for _, msg := range messages {
+		summaries = append(summaries, models.SummarizeMessage(msg, models.DefaultMessagePreviewRunes))
+	}
+	return summaries, nil
+}
+
+// GetMessage returns a single message with its full body, applying the same
+// auto-read behaviour as listing a direct conversation.
+func (s *Service) GetMessage(messageID string) (msg models.Message, err error) {
+	if s.deps.TrackOperation != nil {
+		defer s.deps.TrackOperation("message.get", &err)()
+	}
+	messageID, err = ParseMessageStatusID(messageID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	msg, ok := s.deps.Messages.GetMessage(messageID)
+	if !ok {
+		return models.Message{}, errors.New("message not found")
+	}
+	msg = models.NormalizeMessageConversation(msg)
+	if msg.ConversationType == models.ConversationTypeDirect && ShouldAutoReadOnList(msg) && s.deps.ApplyAutoRead != nil {
+		s.deps.ApplyAutoRead(&msg, msg.ContactID)
+	}
+	return msg, nil
+}
+
 func (s *Service) GetMessagesByThread(contactID, threadID string, limit, offset int) (messages []models.Message, err error) {
 	if s.deps.TrackOperation != nil {
 		defer s.deps.TrackOperation("message.list", &err)()
@@ -212,6 +389,9 @@ func (s *Service) GetMessageStatus(messageID string) (status models.MessageStatu
 }
 
 func (s *Service) BuildStoredMessageWire(msg models.Message) (contracts.WirePayload, error) {
+	if s.isSavedMessages(msg.ContactID) {
+		return NewSavedMessageWire(msg), nil
+	}
 	wire, _, err := BuildWireForOutboundMessage(msg, s.deps.Sessions)
 	if errors.Is(err, messagingpolicy.ErrOutboundSessionRequired) {
 		card, cardErr := s.deps.Identity.SelfContactCard(s.deps.Identity.GetIdentity().ID)
@@ -220,13 +400,19 @@ func (s *Service) BuildStoredMessageWire(msg models.Message) (contracts.WirePayl
 		}
 		plainWire := NewPlainWire(msg.Content)
 		plainWire.ThreadID = strings.TrimSpace(msg.ThreadID)
+		plainWire.Attachments = msg.Attachments
+		plainWire.ContentType = OutboundWireContentType(msg)
 		plainWire.Card = &card
+		plainWire.Seq = msg.Seq
 		return plainWire, nil
 	}
 	if err != nil {
 		return contracts.WirePayload{}, contracts.WrapCategorizedError(contracts.ErrorCategoryCrypto, err)
 	}
 	wire.ThreadID = strings.TrimSpace(msg.ThreadID)
+	wire.Attachments = msg.Attachments
+	wire.ContentType = OutboundWireContentType(msg)
+	wire.Seq = msg.Seq
 	return wire, nil
 }
 
@@ -244,6 +430,9 @@ func (s *Service) InitSession(contactID string, peerPublicKey []byte) (session m
 		s.deps.RecordError(contracts.ErrorCategoryCrypto, err)
 		return models.SessionState{}, err
 	}
+	if s.deps.SessionEstablished != nil {
+		s.deps.SessionEstablished(contactID)
+	}
 	return MapSessionState(state), nil
 }
 
@@ -273,3 +462,16 @@ func (s *Service) RevokeDevice(deviceID string) (models.DeviceRevocation, error)
 	}
 	return rev, nil
 }
+
+// isSavedMessages reports whether contactID is the user's own identity, whose
+// conversation holds notes to self that only travel to our other devices.
+func (s *Service) isSavedMessages(contactID string) bool {
+	return contactID != "" && contactID == s.deps.Identity.GetIdentity().ID
+}
+
+func (s *Service) resolveContactID(contactID string) string {
+	if s.deps.ResolveContactID == nil {
+		return contactID
+	}
+	return s.deps.ResolveContactID(contactID)
+}
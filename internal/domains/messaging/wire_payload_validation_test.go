@@ -3,8 +3,10 @@ package messaging_test
 import (
 	"aim-chat/go-backend/internal/domains/contracts"
 	messagingapp "aim-chat/go-backend/internal/domains/messaging"
+	"aim-chat/go-backend/pkg/models"
 	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 )
 
@@ -149,6 +151,30 @@ func TestValidateWirePayloadRejectsGroupMessageWithoutKeyVersion(t *testing.T) {
 	}
 }
 
+func TestValidateWirePayloadChecksLocationContent(t *testing.T) {
+	valid, err := messagingapp.EncodeMessageLocation(models.MessageLocation{Latitude: 48.8584, Longitude: 2.2945, AccuracyMeters: 10})
+	if err != nil {
+		t.Fatalf("encode location: %v", err)
+	}
+	if err := messagingapp.ValidateWirePayload(contracts.WirePayload{Kind: "plain", Plain: valid, ContentType: models.MessageContentTypeLocation}); err != nil {
+		t.Fatalf("location wire must be valid, got %v", err)
+	}
+	for name, wire := range map[string]contracts.WirePayload{
+		"unknown type":    {Kind: "plain", Plain: valid, ContentType: "sticker"},
+		"non-message":     {Kind: "receipt", ContentType: models.MessageContentTypeLocation},
+		"missing lng":     {Kind: "plain", Plain: []byte(`{"lat":1}`), ContentType: models.MessageContentTypeLocation},
+		"out of range":    {Kind: "plain", Plain: []byte(`{"lat":1,"lng":181}`), ContentType: models.MessageContentTypeLocation},
+		"negative radius": {Kind: "plain", Plain: []byte(`{"lat":1,"lng":1,"accuracy":-1}`), ContentType: models.MessageContentTypeLocation},
+	} {
+		if err := messagingapp.ValidateWirePayload(wire); !errors.Is(err, messagingapp.ErrInvalidMessageLocation) {
+			t.Fatalf("%s: expected invalid location, got %v", name, err)
+		}
+	}
+	if _, err := messagingapp.EncodeMessageLocation(models.MessageLocation{Latitude: math.NaN()}); !errors.Is(err, messagingapp.ErrInvalidMessageLocation) {
+		t.Fatalf("expected NaN to be rejected, got %v", err)
+	}
+}
+
 func TestBuildWireAuthPayloadRejectsInvalidGroupPayload(t *testing.T) {
 	_, err := messagingapp.BuildWireAuthPayload("m1", "sender", "recipient", contracts.WirePayload{
 		Kind:             "plain",
@@ -162,3 +188,32 @@ func TestBuildWireAuthPayloadRejectsInvalidGroupPayload(t *testing.T) {
 		t.Fatalf("expected messagingapp.ErrInvalidGroupWirePayload, got %v", err)
 	}
 }
+
+func TestCheckInboundPayloadLimits(t *testing.T) {
+	limits := messagingapp.InboundLimits{MaxWireBytes: 512, MaxPlaintextBytes: 32}
+
+	ok := contracts.WirePayload{Kind: "plain", Plain: []byte("hello")}
+	if err := messagingapp.CheckInboundPayload(limits, []byte("{}"), ok, true); err != nil {
+		t.Fatalf("expected small plain payload to pass, got %v", err)
+	}
+	if err := messagingapp.CheckInboundPayload(limits, make([]byte, 513), ok, true); !errors.Is(err, messagingapp.ErrInboundWireTooLarge) {
+		t.Fatalf("expected wire size rejection, got %v", err)
+	}
+	long := contracts.WirePayload{Kind: "plain", Plain: make([]byte, 33)}
+	if err := messagingapp.CheckInboundPayload(limits, []byte("{}"), long, true); !errors.Is(err, messagingapp.ErrInboundPlaintextTooLarge) {
+		t.Fatalf("expected plaintext rejection, got %v", err)
+	}
+	sealed := contracts.WirePayload{Kind: "e2ee"}
+	sealed.Envelope.Ciphertext = make([]byte, 64)
+	if err := messagingapp.CheckInboundPayload(limits, []byte("{}"), sealed, true); !errors.Is(err, messagingapp.ErrInboundPlaintextTooLarge) {
+		t.Fatalf("expected ciphertext bound to reject before decryption, got %v", err)
+	}
+	unknown := contracts.WirePayload{Kind: "macro"}
+	if err := messagingapp.CheckInboundPayload(limits, []byte("{}"), unknown, true); !errors.Is(err, messagingapp.ErrInboundKindNotAllowed) {
+		t.Fatalf("expected unknown kind rejection, got %v", err)
+	}
+	limits.AllowedKinds = map[string]bool{"e2ee": true, "receipt": true}
+	if err := messagingapp.CheckInboundPayload(limits, []byte("{}"), ok, true); !errors.Is(err, messagingapp.ErrInboundKindNotAllowed) {
+		t.Fatalf("expected plain kind to be blocked by mode allowlist, got %v", err)
+	}
+}
@@ -22,6 +22,70 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 			return service.UpdatePrivacySettings(mode)
 		})
 		return result, rpcErr, true
+	case "privacy.pow.set":
+		bits, err := decodeSingleOrDirect[int](rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32312, func() (any, error) {
+			powAPI, ok := service.(interface {
+				UpdateFirstContactPow(bits int) (privacydomain.PrivacySettings, error)
+			})
+			if !ok {
+				return nil, errors.New("first-contact proof-of-work is not supported")
+			}
+			return powAPI.UpdateFirstContactPow(bits)
+		})
+		return result, rpcErr, true
+	case "privacy.discoverability.set":
+		result, rpcErr := callWithSingleStringParam(rawParams, -32325, func(mode string) (any, error) {
+			discoverabilityAPI, ok := service.(interface {
+				UpdateDiscoverability(mode string) (privacydomain.PrivacySettings, error)
+			})
+			if !ok {
+				return nil, errors.New("discoverability settings are not supported")
+			}
+			return discoverabilityAPI.UpdateDiscoverability(mode)
+		})
+		return result, rpcErr, true
+	case "privacy.cover_traffic.set":
+		settings, toggleOnly, err := decodeCoverTrafficParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32340, func() (any, error) {
+			coverAPI, ok := service.(interface {
+				UpdateCoverTraffic(settings privacydomain.CoverTrafficSettings) (privacydomain.PrivacySettings, error)
+			})
+			if !ok {
+				return nil, errors.New("cover traffic is not supported")
+			}
+			if toggleOnly {
+				current, err := service.GetPrivacySettings()
+				if err != nil {
+					return nil, err
+				}
+				current.CoverTraffic.Enabled = settings.Enabled
+				settings = current.CoverTraffic
+			}
+			return coverAPI.UpdateCoverTraffic(settings)
+		})
+		return result, rpcErr, true
+	case "privacy.daily_summary.set":
+		settings, err := decodeSingleOrDirect[privacydomain.DailySummarySettings](rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32353, func() (any, error) {
+			summaryAPI, ok := service.(interface {
+				UpdateDailySummary(settings privacydomain.DailySummarySettings) (privacydomain.PrivacySettings, error)
+			})
+			if !ok {
+				return nil, errors.New("daily summary is not supported")
+			}
+			return summaryAPI.UpdateDailySummary(settings)
+		})
+		return result, rpcErr, true
 	case "privacy.storage.get":
 		result, rpcErr := callWithoutParams(-32082, func() (any, error) {
 			storageAPI, ok := service.(interface {
@@ -126,6 +190,59 @@ func Dispatch(service contracts.DaemonService, method string, rawParams json.Raw
 			return map[string]bool{"removed": removed}, nil
 		})
 		return result, rpcErr, true
+	case "privacy.storage.scope.list":
+		result, rpcErr := callWithoutParams(-32313, func() (any, error) {
+			storageAPI, ok := service.(interface {
+				ListStorageScopeOverrides() ([]privacydomain.StorageScopeOverrideEntry, error)
+			})
+			if !ok {
+				return nil, errors.New("privacy scoped storage policy is not supported")
+			}
+			overrides, err := storageAPI.ListStorageScopeOverrides()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"overrides": overrides}, nil
+		})
+		return result, rpcErr, true
+	case "privacy.storage.scope.bulk_set":
+		items, err := decodeStorageScopeBulkSetParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32314, func() (any, error) {
+			storageAPI, ok := service.(interface {
+				SetStorageScopeOverrides(items []privacydomain.StorageScopeOverrideInput) ([]privacydomain.StorageScopeOverrideEntry, error)
+			})
+			if !ok {
+				return nil, errors.New("privacy scoped storage policy is not supported")
+			}
+			overrides, err := storageAPI.SetStorageScopeOverrides(items)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"overrides": overrides}, nil
+		})
+		return result, rpcErr, true
+	case "privacy.storage.scope.bulk_delete":
+		refs, err := decodeStorageScopeBulkDeleteParams(rawParams)
+		if err != nil {
+			return nil, rpckit.InvalidParams(), true
+		}
+		result, rpcErr := callWithoutParams(-32315, func() (any, error) {
+			storageAPI, ok := service.(interface {
+				RemoveStorageScopeOverrides(refs []privacydomain.StorageScopeRef) (int, error)
+			})
+			if !ok {
+				return nil, errors.New("privacy scoped storage policy is not supported")
+			}
+			removed, err := storageAPI.RemoveStorageScopeOverrides(refs)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]int{"removed": removed}, nil
+		})
+		return result, rpcErr, true
 	case "blocklist.list":
 		result, rpcErr := callWithoutParams(-32090, func() (any, error) {
 			blocked, err := service.GetBlocklist()
@@ -199,6 +316,19 @@ func decodeSingleOrDirect[T any](raw json.RawMessage) (T, error) {
 	return zero, errors.New("invalid params")
 }
 
+// decodeCoverTrafficParams accepts a bare on/off flag, which keeps the
+// configured limits, or a full settings object.
+func decodeCoverTrafficParams(raw json.RawMessage) (privacydomain.CoverTrafficSettings, bool, error) {
+	if enabled, err := decodeSingleOrDirect[bool](raw); err == nil {
+		return privacydomain.CoverTrafficSettings{Enabled: enabled}, true, nil
+	}
+	settings, err := decodeSingleOrDirect[privacydomain.CoverTrafficSettings](raw)
+	if err != nil {
+		return privacydomain.CoverTrafficSettings{}, false, err
+	}
+	return settings, false, nil
+}
+
 func intPtrValue(v *int) int {
 	if v == nil {
 		return 0
@@ -310,3 +440,45 @@ func decodeStorageScopePolicyRefParams(raw json.RawMessage) (string, string, boo
 	}
 	return "", "", false, errors.New("invalid params")
 }
+
+func decodeStorageScopeBulkSetParams(raw json.RawMessage) ([]privacydomain.StorageScopeOverrideInput, error) {
+	type item struct {
+		Scope   string `json:"scope"`
+		ScopeID string `json:"scope_id"`
+		privacydomain.StoragePolicyOverride
+	}
+	type payload struct {
+		Overrides []item `json:"overrides"`
+	}
+	p, err := decodeSingleOrDirect[payload](raw)
+	if err != nil || len(p.Overrides) == 0 {
+		return nil, errors.New("invalid params")
+	}
+	out := make([]privacydomain.StorageScopeOverrideInput, 0, len(p.Overrides))
+	for _, it := range p.Overrides {
+		if strings.TrimSpace(it.Scope) == "" {
+			return nil, errors.New("invalid params")
+		}
+		out = append(out, privacydomain.StorageScopeOverrideInput{
+			StorageScopeRef: privacydomain.StorageScopeRef{Scope: it.Scope, ScopeID: it.ScopeID},
+			Override:        it.StoragePolicyOverride,
+		})
+	}
+	return out, nil
+}
+
+func decodeStorageScopeBulkDeleteParams(raw json.RawMessage) ([]privacydomain.StorageScopeRef, error) {
+	type payload struct {
+		Scopes []privacydomain.StorageScopeRef `json:"scopes"`
+	}
+	p, err := decodeSingleOrDirect[payload](raw)
+	if err != nil || len(p.Scopes) == 0 {
+		return nil, errors.New("invalid params")
+	}
+	for _, ref := range p.Scopes {
+		if strings.TrimSpace(ref.Scope) == "" {
+			return nil, errors.New("invalid params")
+		}
+	}
+	return p.Scopes, nil
+}
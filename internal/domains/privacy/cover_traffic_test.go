@@ -0,0 +1,46 @@
+package privacy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServiceUpdateCoverTraffic(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	store := &fakePrivacyStore{settings: DefaultPrivacySettings()}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(DefaultPrivacySettings(), bl)
+
+	initial := svc.CoverTraffic()
+	if initial.Enabled || initial.DailyBudgetKB != DefaultCoverTrafficBudgetKB {
+		t.Fatalf("unexpected default cover traffic: %+v", initial)
+	}
+	if _, err := svc.UpdateCoverTraffic(CoverTrafficSettings{Enabled: true, DailyBudgetKB: MaxCoverTrafficBudgetKB + 1}); !errors.Is(err, ErrInvalidCoverTraffic) {
+		t.Fatalf("expected invalid budget, got %v", err)
+	}
+	if _, err := svc.UpdateCoverTraffic(CoverTrafficSettings{Enabled: true, IntervalSeconds: 1}); !errors.Is(err, ErrInvalidCoverTraffic) {
+		t.Fatalf("expected invalid interval, got %v", err)
+	}
+	updated, err := svc.UpdateCoverTraffic(CoverTrafficSettings{Enabled: true, DailyBudgetKB: 512})
+	if err != nil {
+		t.Fatalf("update cover traffic failed: %v", err)
+	}
+	if !updated.CoverTraffic.Enabled || updated.CoverTraffic.DailyBudgetKB != 512 || updated.CoverTraffic.IntervalSeconds == 0 {
+		t.Fatalf("unexpected cover traffic settings: %+v", updated.CoverTraffic)
+	}
+	if store.settings.CoverTraffic != updated.CoverTraffic {
+		t.Fatalf("cover traffic not persisted: %+v", store.settings.CoverTraffic)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(MessagePrivacyRequests)); err != nil {
+		t.Fatalf("update mode failed: %v", err)
+	}
+	if !svc.CoverTraffic().Enabled {
+		t.Fatal("mode change must keep cover traffic")
+	}
+	if got := NormalizePrivacySettings(PrivacySettings{}).CoverTraffic; got.Enabled || got.DailyBudgetKB != DefaultCoverTrafficBudgetKB {
+		t.Fatalf("legacy settings must normalize to cover traffic off, got %+v", got)
+	}
+}
@@ -0,0 +1,40 @@
+package privacy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServiceUpdateDailySummary(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	store := &fakePrivacyStore{settings: DefaultPrivacySettings()}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(DefaultPrivacySettings(), bl)
+
+	if svc.DailySummary().Enabled {
+		t.Fatal("daily summary must be off by default")
+	}
+	if _, err := svc.UpdateDailySummary(DailySummarySettings{Enabled: true, Hour: MaxDailySummaryHour + 1}); !errors.Is(err, ErrInvalidDailySummary) {
+		t.Fatalf("expected invalid hour, got %v", err)
+	}
+	if _, err := svc.UpdateDailySummary(DailySummarySettings{Enabled: true, UTCOffsetMinutes: 15 * 60}); !errors.Is(err, ErrInvalidDailySummary) {
+		t.Fatalf("expected invalid offset, got %v", err)
+	}
+	want := DailySummarySettings{Enabled: true, Hour: 8, UTCOffsetMinutes: 180, SaveToSavedMessages: true}
+	updated, err := svc.UpdateDailySummary(want)
+	if err != nil {
+		t.Fatalf("update daily summary failed: %v", err)
+	}
+	if updated.DailySummary != want || store.settings.DailySummary != want {
+		t.Fatalf("daily summary not persisted: %+v", store.settings.DailySummary)
+	}
+	if _, err := svc.UpdateCoverTraffic(CoverTrafficSettings{Enabled: true}); err != nil {
+		t.Fatalf("update cover traffic failed: %v", err)
+	}
+	if svc.DailySummary() != want {
+		t.Fatalf("other updates must keep the daily summary, got %+v", svc.DailySummary())
+	}
+}
@@ -0,0 +1,51 @@
+package privacy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShouldIgnoreInboundSender(t *testing.T) {
+	if ShouldIgnoreInboundSender(DiscoverabilityEveryone, false) {
+		t.Fatal("discoverable identity must not ignore strangers")
+	}
+	if ShouldIgnoreInboundSender(DiscoverabilityContactsOnly, true) {
+		t.Fatal("hidden identity must still hear from contacts")
+	}
+	if !ShouldIgnoreInboundSender(DiscoverabilityContactsOnly, false) {
+		t.Fatal("hidden identity must ignore strangers")
+	}
+}
+
+func TestServiceUpdateDiscoverability(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	store := &fakePrivacyStore{settings: DefaultPrivacySettings()}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(DefaultPrivacySettings(), bl)
+
+	if svc.Discoverability() != DiscoverabilityEveryone {
+		t.Fatalf("unexpected default discoverability: %q", svc.Discoverability())
+	}
+	if _, err := svc.UpdateDiscoverability("nobody"); !errors.Is(err, ErrInvalidDiscoverabilityMode) {
+		t.Fatalf("expected invalid discoverability, got %v", err)
+	}
+	updated, err := svc.UpdateDiscoverability(string(DiscoverabilityContactsOnly))
+	if err != nil {
+		t.Fatalf("update discoverability failed: %v", err)
+	}
+	if updated.Discoverability != DiscoverabilityContactsOnly || store.settings.Discoverability != DiscoverabilityContactsOnly {
+		t.Fatalf("discoverability not persisted: %+v", updated)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(MessagePrivacyRequests)); err != nil {
+		t.Fatalf("update mode failed: %v", err)
+	}
+	if svc.Discoverability() != DiscoverabilityContactsOnly {
+		t.Fatal("mode change must keep discoverability")
+	}
+	if got := NormalizePrivacySettings(PrivacySettings{}).Discoverability; got != DefaultDiscoverabilityMode {
+		t.Fatalf("legacy settings must normalize to %q, got %q", DefaultDiscoverabilityMode, got)
+	}
+}
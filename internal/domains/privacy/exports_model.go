@@ -9,6 +9,8 @@ type MessagePrivacyMode = privacymodel.MessagePrivacyMode
 type StorageProtectionMode = privacymodel.StorageProtectionMode
 type ContentRetentionMode = privacymodel.ContentRetentionMode
 type StoragePolicyScope = privacymodel.StoragePolicyScope
+type DiscoverabilityMode = privacymodel.DiscoverabilityMode
+type MailboxEvictionPolicy = privacymodel.MailboxEvictionPolicy
 
 const (
 	MessagePrivacyContactsOnly        = privacymodel.MessagePrivacyContactsOnly
@@ -25,12 +27,27 @@ const (
 	DefaultEphemeralMessageTTLSeconds = privacymodel.DefaultEphemeralMessageTTLSeconds
 	DefaultEphemeralFileTTLSeconds    = privacymodel.DefaultEphemeralFileTTLSeconds
 	CurrentProfileSchemaVersion       = privacymodel.CurrentProfileSchemaVersion
+	MaxFirstContactPowBits            = privacymodel.MaxFirstContactPowBits
+	DiscoverabilityEveryone           = privacymodel.DiscoverabilityEveryone
+	DiscoverabilityContactsOnly       = privacymodel.DiscoverabilityContactsOnly
+	DefaultDiscoverabilityMode        = privacymodel.DefaultDiscoverabilityMode
+	MailboxEvictionNone               = privacymodel.MailboxEvictionNone
+	MailboxEvictionOldestFirst        = privacymodel.MailboxEvictionOldestFirst
+	MailboxEvictionSenderFairness     = privacymodel.MailboxEvictionSenderFairness
+	DefaultCoverTrafficBudgetKB       = privacymodel.DefaultCoverTrafficBudgetKB
+	MaxCoverTrafficBudgetKB           = privacymodel.MaxCoverTrafficBudgetKB
+	MaxDailySummaryHour               = privacymodel.MaxDailySummaryHour
 )
 
 var (
-	ErrInvalidMessagePrivacyMode = privacymodel.ErrInvalidMessagePrivacyMode
-	ErrInvalidIdentityID         = privacymodel.ErrInvalidIdentityID
-	ErrInfiniteTTLRequiresPinned = privacymodel.ErrInfiniteTTLRequiresPinned
+	ErrInvalidMessagePrivacyMode  = privacymodel.ErrInvalidMessagePrivacyMode
+	ErrInvalidIdentityID          = privacymodel.ErrInvalidIdentityID
+	ErrInfiniteTTLRequiresPinned  = privacymodel.ErrInfiniteTTLRequiresPinned
+	ErrInvalidPowDifficulty       = privacymodel.ErrInvalidPowDifficulty
+	ErrInvalidDiscoverabilityMode = privacymodel.ErrInvalidDiscoverabilityMode
+	ErrInvalidCoverTraffic        = privacymodel.ErrInvalidCoverTraffic
+	ErrInvalidDailySummary        = privacymodel.ErrInvalidDailySummary
+	ErrInvalidGroupID             = privacymodel.ErrInvalidGroupID
 )
 
 // noinspection GoNameStartsWithPackageName
@@ -38,9 +55,21 @@ type PrivacySettings = privacymodel.PrivacySettings
 type StoragePolicy = privacymodel.StoragePolicy
 type StoragePolicyOverride = privacymodel.StoragePolicyOverride
 type NodePolicies = privacymodel.NodePolicies
+type CoverTrafficSettings = privacymodel.CoverTrafficSettings
+type DailySummarySettings = privacymodel.DailySummarySettings
 type NodePersonalPolicy = privacymodel.NodePersonalPolicy
 type NodePublicPolicy = privacymodel.NodePublicPolicy
 type Blocklist = privacymodel.Blocklist
+type GroupMemberBlocks = privacymodel.GroupMemberBlocks
+type StorageScopeRef = privacymodel.StorageScopeRef
+type StorageScopeOverrideInput = privacymodel.StorageScopeOverrideInput
+type StorageScopeOverrideEntry = privacymodel.StorageScopeOverrideEntry
+
+const (
+	ScopeWarningInfiniteTTLRequiresPin     = privacymodel.ScopeWarningInfiniteTTLRequiresPin
+	ScopeWarningPinningDisabled            = privacymodel.ScopeWarningPinningDisabled
+	ScopeWarningHoldBlockedByZeroRetention = privacymodel.ScopeWarningHoldBlockedByZeroRetention
+)
 
 func DefaultPrivacySettings() PrivacySettings {
 	return privacymodel.DefaultPrivacySettings()
@@ -50,6 +79,14 @@ func NormalizePrivacySettings(in PrivacySettings) PrivacySettings {
 	return privacymodel.NormalizePrivacySettings(in)
 }
 
+func ListStorageScopeOverrides(settings PrivacySettings) []StorageScopeOverrideEntry {
+	return privacymodel.ListStorageScopeOverrides(settings)
+}
+
+func DescribeStorageScopeOverride(settings PrivacySettings, scope StoragePolicyScope, scopeID string, override StoragePolicyOverride) StorageScopeOverrideEntry {
+	return privacymodel.DescribeStorageScopeOverride(settings, scope, scopeID, override)
+}
+
 func ParseMessagePrivacyMode(raw string) (MessagePrivacyMode, error) {
 	return privacymodel.ParseMessagePrivacyMode(raw)
 }
@@ -1,7 +1,11 @@
 package privacy
 
 import (
+	"context"
+	"time"
+
 	privacypolicy "aim-chat/go-backend/internal/domains/privacy/policy"
+	"aim-chat/go-backend/pkg/models"
 )
 
 type InboundMessagePolicyAction = privacypolicy.InboundMessagePolicyAction
@@ -27,6 +31,10 @@ func EvaluateInboundMessagePolicy(input InboundMessagePolicyInput) InboundMessag
 	return privacypolicy.EvaluateInboundMessagePolicy(input)
 }
 
+func ShouldIgnoreInboundSender(mode DiscoverabilityMode, isKnownContact bool) bool {
+	return privacypolicy.ShouldIgnoreInboundSender(mode, isKnownContact)
+}
+
 func ShouldAutoAddUnknownSenderContact(
 	decision InboundMessagePolicyDecision,
 	conversationType string,
@@ -71,3 +79,30 @@ const (
 func EvaluateInboundGroupInvitePolicy(input InboundGroupInvitePolicyInput) InboundGroupInvitePolicyDecision {
 	return privacypolicy.EvaluateInboundGroupInvitePolicy(input)
 }
+
+const PowStampMaxSkew = privacypolicy.PowStampMaxSkew
+
+var (
+	ErrPowStampRequired     = privacypolicy.ErrPowStampRequired
+	ErrPowStampInsufficient = privacypolicy.ErrPowStampInsufficient
+	ErrPowStampExpired      = privacypolicy.ErrPowStampExpired
+	ErrPowStampInvalid      = privacypolicy.ErrPowStampInvalid
+)
+
+func FirstContactPowRequired(isKnownContact bool, requiredBits int) bool {
+	return privacypolicy.FirstContactPowRequired(isKnownContact, requiredBits)
+}
+
+func MintPowStamp(ctx context.Context, senderID, recipientID, messageID string, difficulty int, now time.Time) (models.PowStamp, error) {
+	return privacypolicy.MintPowStamp(ctx, senderID, recipientID, messageID, difficulty, now)
+}
+
+func VerifyPowStamp(stamp *models.PowStamp, senderID, recipientID, messageID string, requiredBits int, now time.Time) error {
+	return privacypolicy.VerifyPowStamp(stamp, senderID, recipientID, messageID, requiredBits, now)
+}
+
+var ErrFirstContactPaymentRequired = privacypolicy.ErrFirstContactPaymentRequired
+
+func FirstContactPaymentRequired(isKnownContact bool, verifierInstalled bool) bool {
+	return privacypolicy.FirstContactPaymentRequired(isKnownContact, verifierInstalled)
+}
@@ -0,0 +1,87 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPowStampMintAndVerify(t *testing.T) {
+	now := time.Now()
+	stamp, err := MintPowStamp(context.Background(), "aim1sender", "aim1recipient", "msg1", 8, now)
+	if err != nil {
+		t.Fatalf("mint stamp: %v", err)
+	}
+	if err := VerifyPowStamp(&stamp, "aim1sender", "aim1recipient", "msg1", 8, now); err != nil {
+		t.Fatalf("valid stamp rejected: %v", err)
+	}
+	if err := VerifyPowStamp(nil, "aim1sender", "aim1recipient", "msg1", 0, now); err != nil {
+		t.Fatalf("no difficulty must not require a stamp: %v", err)
+	}
+	if err := VerifyPowStamp(nil, "aim1sender", "aim1recipient", "msg1", 8, now); !errors.Is(err, ErrPowStampRequired) {
+		t.Fatalf("expected stamp required, got %v", err)
+	}
+	if err := VerifyPowStamp(&stamp, "aim1sender", "aim1recipient", "msg1", 12, now); !errors.Is(err, ErrPowStampInsufficient) {
+		t.Fatalf("expected insufficient stamp, got %v", err)
+	}
+	if err := VerifyPowStamp(&stamp, "aim1sender", "aim1recipient", "msg1", 8, now.Add(2*PowStampMaxSkew)); !errors.Is(err, ErrPowStampExpired) {
+		t.Fatalf("expected expired stamp, got %v", err)
+	}
+}
+
+func TestPowStampIsBoundToMessage(t *testing.T) {
+	now := time.Now()
+	stamp, err := MintPowStamp(context.Background(), "aim1sender", "aim1recipient", "msg1", 12, now)
+	if err != nil {
+		t.Fatalf("mint stamp: %v", err)
+	}
+	rejected := 0
+	for _, messageID := range []string{"msg2", "msg3", "msg4", "msg5"} {
+		if errors.Is(VerifyPowStamp(&stamp, "aim1sender", "aim1recipient", messageID, 12, now), ErrPowStampInvalid) {
+			rejected++
+		}
+	}
+	// A reused stamp passes by chance with probability 2^-12 per message.
+	if rejected < 3 {
+		t.Fatalf("stamp reused for other messages should be rejected, rejected=%d", rejected)
+	}
+}
+
+func TestMintPowStampRejectsExcessiveDifficulty(t *testing.T) {
+	if _, err := MintPowStamp(context.Background(), "a", "b", "m", MaxFirstContactPowBits+1, time.Now()); !errors.Is(err, ErrInvalidPowDifficulty) {
+		t.Fatalf("expected invalid difficulty, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := MintPowStamp(ctx, "a", "b", "m", MaxFirstContactPowBits, time.Now()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled mint, got %v", err)
+	}
+}
+
+func TestServiceUpdateFirstContactPow(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	store := &fakePrivacyStore{settings: DefaultPrivacySettings()}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(DefaultPrivacySettings(), bl)
+
+	if _, err := svc.UpdateFirstContactPow(MaxFirstContactPowBits + 1); !errors.Is(err, ErrInvalidPowDifficulty) {
+		t.Fatalf("expected invalid difficulty, got %v", err)
+	}
+	updated, err := svc.UpdateFirstContactPow(16)
+	if err != nil {
+		t.Fatalf("update pow failed: %v", err)
+	}
+	if updated.FirstContactPowBits != 16 || svc.FirstContactPowBits() != 16 || store.settings.FirstContactPowBits != 16 {
+		t.Fatalf("difficulty not applied: %+v", updated)
+	}
+	if _, err := svc.UpdatePrivacySettings(string(MessagePrivacyRequests)); err != nil {
+		t.Fatalf("update mode failed: %v", err)
+	}
+	if svc.FirstContactPowBits() != 16 {
+		t.Fatal("mode change must keep the pow difficulty")
+	}
+}
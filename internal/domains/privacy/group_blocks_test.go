@@ -0,0 +1,50 @@
+package privacy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServiceGroupMemberBlocks(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	store := &fakePrivacyStore{settings: DefaultPrivacySettings()}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(DefaultPrivacySettings(), bl)
+
+	member := "aim1UUMgCUXE93BxtwVDUivN2q3eYPKwaPkqjnNp9QVV9pF"
+	if _, err := svc.BlockGroupMember(" ", member); !errors.Is(err, ErrInvalidGroupID) {
+		t.Fatalf("expected invalid group id, got %v", err)
+	}
+	if _, err := svc.BlockGroupMember("group-1", "bob"); !errors.Is(err, ErrInvalidIdentityID) {
+		t.Fatalf("expected invalid identity id, got %v", err)
+	}
+	if _, err := svc.BlockGroupMember("group-1", member); err != nil {
+		t.Fatalf("block group member: %v", err)
+	}
+	blocked, err := svc.BlockGroupMember("group-1", member)
+	if err != nil || len(blocked) != 1 {
+		t.Fatalf("expected a repeated block to keep one entry, got %v %v", blocked, err)
+	}
+	if !svc.IsBlockedInGroup("group-1", member) || svc.IsBlockedInGroup("group-2", member) {
+		t.Fatal("group block must apply to its group only")
+	}
+	if svc.IsBlockedSender(member) {
+		t.Fatal("group block must not touch the blocklist")
+	}
+	if got := store.settings.GroupMemberBlocks["group-1"]; len(got) != 1 || got[0] != member {
+		t.Fatalf("group block not persisted: %v", store.settings.GroupMemberBlocks)
+	}
+
+	if _, err := svc.UnblockGroupMember("group-1", member); err != nil {
+		t.Fatalf("unblock group member: %v", err)
+	}
+	if svc.IsBlockedInGroup("group-1", member) || len(store.settings.GroupMemberBlocks) != 0 {
+		t.Fatalf("expected the group entry to be dropped, got %v", store.settings.GroupMemberBlocks)
+	}
+	if got := svc.GroupMemberBlocks("group-1"); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty list, got %#v", got)
+	}
+}
@@ -1,16 +1,17 @@
 package model
 
 import (
-	"errors"
 	"sort"
 	"strings"
+
+	identitypolicy "aim-chat/go-backend/internal/domains/identity/policy"
 )
 
-var ErrInvalidIdentityID = errors.New("invalid identity id")
+var ErrInvalidIdentityID = identitypolicy.ErrInvalidIdentityID
 
 func NormalizeIdentityID(identityID string) (string, error) {
 	identityID = strings.TrimSpace(identityID)
-	if !strings.HasPrefix(identityID, "aim1") || len(identityID) < 12 {
+	if identitypolicy.ValidateIdentityID(identityID) != nil {
 		return "", ErrInvalidIdentityID
 	}
 	return identityID, nil
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidGroupID = errors.New("invalid group id")
+
+// GroupMemberBlocks lists, per group id, the members whose messages are
+// hidden in that group only. Unlike the blocklist, a group block does not
+// stop direct messages or the member's group events, so membership and keys
+// stay in step with the rest of the group.
+type GroupMemberBlocks map[string][]string
+
+// Contains reports whether memberID is blocked in groupID.
+func (b GroupMemberBlocks) Contains(groupID, memberID string) bool {
+	memberID, err := NormalizeIdentityID(memberID)
+	if err != nil {
+		return false
+	}
+	_, found := slices.BinarySearch(b[strings.TrimSpace(groupID)], memberID)
+	return found
+}
+
+// Members returns the members blocked in groupID, sorted.
+func (b GroupMemberBlocks) Members(groupID string) []string {
+	members := slices.Clone(b[strings.TrimSpace(groupID)])
+	if members == nil {
+		members = []string{}
+	}
+	return members
+}
+
+// With returns a copy of the blocks with memberID added to or removed from
+// groupID.
+func (b GroupMemberBlocks) With(groupID, memberID string, blocked bool) (GroupMemberBlocks, error) {
+	groupID = strings.TrimSpace(groupID)
+	if groupID == "" {
+		return nil, ErrInvalidGroupID
+	}
+	memberID, err := NormalizeIdentityID(memberID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(GroupMemberBlocks, len(b)+1)
+	for id, members := range b {
+		out[id] = slices.Clone(members)
+	}
+	members := slices.DeleteFunc(out[groupID], func(id string) bool { return id == memberID })
+	if blocked {
+		members = append(members, memberID)
+		slices.Sort(members)
+	}
+	if len(members) == 0 {
+		delete(out, groupID)
+	} else {
+		out[groupID] = members
+	}
+	return out, nil
+}
+
+func normalizeGroupMemberBlocks(in GroupMemberBlocks) GroupMemberBlocks {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(GroupMemberBlocks, len(in))
+	for groupID, members := range in {
+		groupID = strings.TrimSpace(groupID)
+		if groupID == "" {
+			continue
+		}
+		for _, raw := range members {
+			if id, err := NormalizeIdentityID(raw); err == nil {
+				out[groupID] = append(out[groupID], id)
+			}
+		}
+	}
+	for groupID, members := range out {
+		slices.Sort(members)
+		out[groupID] = slices.Compact(members)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Warning codes reported for storage scope overrides that cannot behave as
+// configured.
+const (
+	// ScopeWarningInfiniteTTLRequiresPin: unpinned content in the scope cannot
+	// use the override and fails closed.
+	ScopeWarningInfiniteTTLRequiresPin = "infinite_ttl_requires_pin"
+	// ScopeWarningPinningDisabled: the override needs pinned content, but the
+	// personal node policy has pinning turned off, so it never applies.
+	ScopeWarningPinningDisabled = "pinning_disabled"
+	// ScopeWarningHoldBlockedByZeroRetention: an infinite-TTL override acts as
+	// a retention hold, which zero-retention mode cannot honor because no
+	// content is persisted at all.
+	ScopeWarningHoldBlockedByZeroRetention = "hold_blocked_by_zero_retention"
+)
+
+var ErrInvalidStorageScopeOverrideKey = errors.New("invalid storage scope override key")
+
+// StorageScopeRef addresses one scoped override.
+type StorageScopeRef struct {
+	Scope   string `json:"scope"`
+	ScopeID string `json:"scope_id,omitempty"`
+}
+
+// StorageScopeOverrideInput is one item of a bulk override update.
+type StorageScopeOverrideInput struct {
+	StorageScopeRef
+	Override StoragePolicyOverride `json:"override"`
+}
+
+// StorageScopeOverrideEntry describes a configured override together with
+// the policy it resolves to. Effective is nil when unpinned content cannot
+// use the override.
+type StorageScopeOverrideEntry struct {
+	Scope           StoragePolicyScope    `json:"scope"`
+	ScopeID         string                `json:"scope_id,omitempty"`
+	Override        StoragePolicyOverride `json:"override"`
+	Effective       *StoragePolicy        `json:"effective,omitempty"`
+	EffectivePinned StoragePolicy         `json:"effective_pinned"`
+	Warnings        []string              `json:"warnings,omitempty"`
+}
+
+// ParseScopeOverrideKey splits a key built by ScopeOverrideKey.
+func ParseScopeOverrideKey(key string) (StoragePolicyScope, string, error) {
+	scopeRaw, scopeID, _ := strings.Cut(key, ":")
+	scope, scopeID, err := normalizeScope(scopeRaw, scopeID)
+	if err != nil {
+		return "", "", ErrInvalidStorageScopeOverrideKey
+	}
+	return scope, scopeID, nil
+}
+
+// ListStorageScopeOverrides enumerates the configured overrides sorted by key.
+func ListStorageScopeOverrides(settings PrivacySettings) []StorageScopeOverrideEntry {
+	settings = NormalizePrivacySettings(settings)
+	keys := make([]string, 0, len(settings.StorageScopeOverrides))
+	for key := range settings.StorageScopeOverrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]StorageScopeOverrideEntry, 0, len(keys))
+	for _, key := range keys {
+		scope, scopeID, err := ParseScopeOverrideKey(key)
+		if err != nil {
+			continue
+		}
+		out = append(out, DescribeStorageScopeOverride(settings, scope, scopeID, settings.StorageScopeOverrides[key]))
+	}
+	return out
+}
+
+// DescribeStorageScopeOverride resolves an override against the current
+// settings and reports conflicts with pin requirements and retention holds.
+func DescribeStorageScopeOverride(settings PrivacySettings, scope StoragePolicyScope, scopeID string, override StoragePolicyOverride) StorageScopeOverrideEntry {
+	settings = NormalizePrivacySettings(settings)
+	override = NormalizeStoragePolicyOverride(override)
+	entry := StorageScopeOverrideEntry{
+		Scope:    scope,
+		ScopeID:  scopeID,
+		Override: override,
+	}
+	if policy, err := override.Resolve(false); err == nil {
+		entry.Effective = &policy
+	} else {
+		entry.Warnings = append(entry.Warnings, ScopeWarningInfiniteTTLRequiresPin)
+	}
+	entry.EffectivePinned, _ = override.Resolve(true)
+	if override.InfiniteTTL && override.PinRequiredForInfinite && settings.NodePolicies != nil && !settings.NodePolicies.Personal.PinEnabled {
+		entry.Warnings = append(entry.Warnings, ScopeWarningPinningDisabled)
+	}
+	if override.InfiniteTTL && settings.ContentRetentionMode == RetentionZeroRetention {
+		entry.Warnings = append(entry.Warnings, ScopeWarningHoldBlockedByZeroRetention)
+	}
+	return entry
+}
@@ -12,6 +12,9 @@ type StorageProtectionMode string
 type ContentRetentionMode string
 type StoragePolicyScope string
 
+// DiscoverabilityMode defines who may learn that the identity is reachable.
+type DiscoverabilityMode string
+
 const (
 	MessagePrivacyContactsOnly MessagePrivacyMode = "contacts_only"
 	MessagePrivacyRequests     MessagePrivacyMode = "requests"
@@ -28,14 +31,39 @@ const (
 	StoragePolicyScopeGroup   StoragePolicyScope = "group"
 	StoragePolicyScopeChannel StoragePolicyScope = "channel"
 	StoragePolicyScopeChat    StoragePolicyScope = "chat"
+
+	DiscoverabilityEveryone     DiscoverabilityMode = "everyone"
+	DiscoverabilityContactsOnly DiscoverabilityMode = "contacts_only"
 )
 
 const DefaultMessagePrivacyMode = MessagePrivacyEveryone
 const DefaultStorageProtectionMode = StorageProtectionStandard
 const DefaultContentRetentionMode = RetentionPersistent
+const DefaultDiscoverabilityMode = DiscoverabilityEveryone
 const DefaultEphemeralMessageTTLSeconds = 86400
 const CurrentProfileSchemaVersion = 2
 
+// MaxFirstContactPowBits caps the first-contact proof-of-work so a stamp stays
+// affordable on slow devices.
+const MaxFirstContactPowBits = 24
+
+// Cover traffic limits. The budget counts the padded bytes of dummy messages
+// sent per UTC day; the interval spaces dummy messages out.
+const (
+	DefaultCoverTrafficBudgetKB        = 2048
+	MaxCoverTrafficBudgetKB            = 64 * 1024
+	DefaultCoverTrafficIntervalSeconds = 120
+	MinCoverTrafficIntervalSeconds     = 30
+	MaxCoverTrafficIntervalSeconds     = 3600
+)
+
+// Daily summary schedule bounds. The offset covers every civil time zone.
+const (
+	MaxDailySummaryHour          = 23
+	MinDailySummaryOffsetMinutes = -12 * 60
+	MaxDailySummaryOffsetMinutes = 14 * 60
+)
+
 // DefaultEphemeralFileTTLSeconds Ephemeral mode keeps file blobs unless an explicit file TTL is provided.
 const DefaultEphemeralFileTTLSeconds = 0
 
@@ -46,6 +74,29 @@ var ErrInvalidTTLSeconds = errors.New("invalid ttl seconds")
 var ErrInvalidStoragePolicyScope = errors.New("invalid storage policy scope")
 var ErrInvalidStoragePolicyScopeID = errors.New("invalid storage policy scope id")
 var ErrInfiniteTTLRequiresPinned = errors.New("infinite ttl requires pinned blob")
+var ErrInvalidPowDifficulty = errors.New("invalid pow difficulty")
+var ErrInvalidDiscoverabilityMode = errors.New("invalid discoverability mode")
+var ErrInvalidCoverTraffic = errors.New("invalid cover traffic settings")
+var ErrInvalidDailySummary = errors.New("invalid daily summary settings")
+
+// CoverTrafficSettings configures the traffic analysis resistance mode. When
+// enabled, every outbound wire is padded to a fixed size bucket and frequent
+// contacts receive encrypted dummy messages within the daily budget.
+type CoverTrafficSettings struct {
+	Enabled         bool `json:"enabled"`
+	DailyBudgetKB   int  `json:"daily_budget_kb,omitempty"`
+	IntervalSeconds int  `json:"interval_seconds,omitempty"`
+}
+
+// DailySummarySettings schedules the local activity summary. It is sent once
+// a day at Hour in the time zone given by UTCOffsetMinutes and covers the
+// preceding 24 hours.
+type DailySummarySettings struct {
+	Enabled             bool `json:"enabled"`
+	Hour                int  `json:"hour"`
+	UTCOffsetMinutes    int  `json:"utc_offset_minutes,omitempty"`
+	SaveToSavedMessages bool `json:"save_to_saved_messages,omitempty"`
+}
 
 // PrivacySettings stores user-level inbound message privacy preferences.
 type PrivacySettings struct {
@@ -62,6 +113,21 @@ type PrivacySettings struct {
 	FileMaxItemSizeMB     int                              `json:"file_max_item_size_mb,omitempty"`
 	StorageScopeOverrides map[string]StoragePolicyOverride `json:"storage_scope_overrides,omitempty"`
 	NodePolicies          *NodePolicies                    `json:"node_policies,omitempty"`
+	// FirstContactPowBits is the proof-of-work unknown senders must attach to
+	// their first messages. Zero disables the requirement.
+	FirstContactPowBits int `json:"first_contact_pow_bits,omitempty"`
+	// Discoverability set to contacts_only hides the identity from everyone
+	// who is not a contact: their traffic is dropped silently and nothing is
+	// advertised to provider directories.
+	Discoverability DiscoverabilityMode `json:"discoverability,omitempty"`
+	// CoverTraffic pads traffic and sends dummy messages to hide when and
+	// how much the user actually writes.
+	CoverTraffic CoverTrafficSettings `json:"cover_traffic"`
+	// DailySummary publishes a local activity summary for self-hosters.
+	DailySummary DailySummarySettings `json:"daily_summary"`
+	// GroupMemberBlocks hides messages of individual members inside groups
+	// the user stays in.
+	GroupMemberBlocks GroupMemberBlocks `json:"group_member_blocks,omitempty"`
 }
 
 type StoragePolicy struct {
@@ -101,6 +167,28 @@ type NodePersonalPolicy struct {
 	TTLDays      int  `json:"ttl_days,omitempty"`
 	QuotaMB      int  `json:"quota_mb,omitempty"`
 	PinEnabled   bool `json:"pin_enabled,omitempty"`
+	// MailboxSenderQuotaMB caps what one sender may keep in the bound
+	// node's mailbox; zero means no per-sender cap.
+	MailboxSenderQuotaMB int                   `json:"mailbox_sender_quota_mb,omitempty"`
+	MailboxEviction      MailboxEvictionPolicy `json:"mailbox_eviction,omitempty"`
+}
+
+// MailboxEvictionPolicy decides what a full mailbox drops to make room.
+type MailboxEvictionPolicy string
+
+const (
+	MailboxEvictionNone           MailboxEvictionPolicy = "none"
+	MailboxEvictionOldestFirst    MailboxEvictionPolicy = "oldest_first"
+	MailboxEvictionSenderFairness MailboxEvictionPolicy = "sender_fairness"
+)
+
+func (p MailboxEvictionPolicy) Valid() bool {
+	switch p {
+	case MailboxEvictionNone, MailboxEvictionOldestFirst, MailboxEvictionSenderFairness:
+		return true
+	default:
+		return false
+	}
 }
 
 type NodePublicPolicy struct {
@@ -116,10 +204,11 @@ func DefaultNodePolicies() NodePolicies {
 	return NodePolicies{
 		ProfileSchemaVersion: CurrentProfileSchemaVersion,
 		Personal: NodePersonalPolicy{
-			StoreEnabled: true,
-			TTLDays:      0,
-			QuotaMB:      10 * 1024,
-			PinEnabled:   true,
+			StoreEnabled:    true,
+			TTLDays:         0,
+			QuotaMB:         10 * 1024,
+			PinEnabled:      true,
+			MailboxEviction: MailboxEvictionNone,
 		},
 		Public: NodePublicPolicy{
 			RelayEnabled:     true,
@@ -147,6 +236,8 @@ func DefaultPrivacySettings() PrivacySettings {
 		ImageMaxItemSizeMB:   0,
 		FileMaxItemSizeMB:    0,
 		NodePolicies:         &policies,
+		Discoverability:      DefaultDiscoverabilityMode,
+		CoverTraffic:         NormalizeCoverTrafficSettings(CoverTrafficSettings{}),
 	}
 }
 
@@ -169,6 +260,13 @@ func NormalizePrivacySettings(in PrivacySettings) PrivacySettings {
 	in.ImageMaxItemSizeMB = normalizeLimitValue(in.ImageMaxItemSizeMB)
 	in.FileMaxItemSizeMB = normalizeLimitValue(in.FileMaxItemSizeMB)
 	in.StorageScopeOverrides = normalizeStorageScopeOverrides(in.StorageScopeOverrides)
+	in.FirstContactPowBits = min(normalizeLimitValue(in.FirstContactPowBits), MaxFirstContactPowBits)
+	if !in.Discoverability.Valid() {
+		in.Discoverability = DefaultDiscoverabilityMode
+	}
+	in.CoverTraffic = NormalizeCoverTrafficSettings(in.CoverTraffic)
+	in.DailySummary = NormalizeDailySummarySettings(in.DailySummary)
+	in.GroupMemberBlocks = normalizeGroupMemberBlocks(in.GroupMemberBlocks)
 	policies := normalizeNodePolicies(in.NodePolicies)
 	in.NodePolicies = &policies
 	if in.ContentRetentionMode != RetentionEphemeral {
@@ -187,6 +285,50 @@ func NormalizePrivacySettings(in PrivacySettings) PrivacySettings {
 	return in
 }
 
+// NormalizeCoverTrafficSettings fills unset limits with defaults and clamps
+// the rest to the supported range.
+func NormalizeCoverTrafficSettings(in CoverTrafficSettings) CoverTrafficSettings {
+	if in.DailyBudgetKB <= 0 {
+		in.DailyBudgetKB = DefaultCoverTrafficBudgetKB
+	}
+	in.DailyBudgetKB = min(in.DailyBudgetKB, MaxCoverTrafficBudgetKB)
+	if in.IntervalSeconds <= 0 {
+		in.IntervalSeconds = DefaultCoverTrafficIntervalSeconds
+	}
+	in.IntervalSeconds = max(min(in.IntervalSeconds, MaxCoverTrafficIntervalSeconds), MinCoverTrafficIntervalSeconds)
+	return in
+}
+
+// ValidateCoverTrafficSettings rejects limits outside the supported range.
+// Zero keeps the default.
+func ValidateCoverTrafficSettings(in CoverTrafficSettings) error {
+	if in.DailyBudgetKB < 0 || in.DailyBudgetKB > MaxCoverTrafficBudgetKB {
+		return fmt.Errorf("%w: daily budget must be between 0 and %d KB", ErrInvalidCoverTraffic, MaxCoverTrafficBudgetKB)
+	}
+	if in.IntervalSeconds != 0 && (in.IntervalSeconds < MinCoverTrafficIntervalSeconds || in.IntervalSeconds > MaxCoverTrafficIntervalSeconds) {
+		return fmt.Errorf("%w: interval must be between %d and %d seconds", ErrInvalidCoverTraffic, MinCoverTrafficIntervalSeconds, MaxCoverTrafficIntervalSeconds)
+	}
+	return nil
+}
+
+// NormalizeDailySummarySettings clamps the schedule to the supported range.
+func NormalizeDailySummarySettings(in DailySummarySettings) DailySummarySettings {
+	in.Hour = max(min(in.Hour, MaxDailySummaryHour), 0)
+	in.UTCOffsetMinutes = max(min(in.UTCOffsetMinutes, MaxDailySummaryOffsetMinutes), MinDailySummaryOffsetMinutes)
+	return in
+}
+
+// ValidateDailySummarySettings rejects schedules outside the supported range.
+func ValidateDailySummarySettings(in DailySummarySettings) error {
+	if in.Hour < 0 || in.Hour > MaxDailySummaryHour {
+		return fmt.Errorf("%w: hour must be between 0 and %d", ErrInvalidDailySummary, MaxDailySummaryHour)
+	}
+	if in.UTCOffsetMinutes < MinDailySummaryOffsetMinutes || in.UTCOffsetMinutes > MaxDailySummaryOffsetMinutes {
+		return fmt.Errorf("%w: utc offset must be between %d and %d minutes", ErrInvalidDailySummary, MinDailySummaryOffsetMinutes, MaxDailySummaryOffsetMinutes)
+	}
+	return nil
+}
+
 func normalizeNodePolicies(in *NodePolicies) NodePolicies {
 	base := DefaultNodePolicies()
 	if in == nil {
@@ -197,6 +339,10 @@ func normalizeNodePolicies(in *NodePolicies) NodePolicies {
 	base.Personal.PinEnabled = in.Personal.PinEnabled
 	base.Personal.TTLDays = normalizeLimitValue(in.Personal.TTLDays)
 	base.Personal.QuotaMB = normalizeLimitValue(in.Personal.QuotaMB)
+	base.Personal.MailboxSenderQuotaMB = normalizeLimitValue(in.Personal.MailboxSenderQuotaMB)
+	if in.Personal.MailboxEviction.Valid() {
+		base.Personal.MailboxEviction = in.Personal.MailboxEviction
+	}
 	base.Public.RelayEnabled = in.Public.RelayEnabled
 	base.Public.DiscoveryEnabled = in.Public.DiscoveryEnabled
 	base.Public.ServingEnabled = in.Public.ServingEnabled
@@ -252,6 +398,23 @@ func (s StoragePolicyScope) Valid() bool {
 	}
 }
 
+func (m DiscoverabilityMode) Valid() bool {
+	switch m {
+	case DiscoverabilityEveryone, DiscoverabilityContactsOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+func ParseDiscoverabilityMode(raw string) (DiscoverabilityMode, error) {
+	mode := DiscoverabilityMode(strings.TrimSpace(raw))
+	if !mode.Valid() {
+		return "", ErrInvalidDiscoverabilityMode
+	}
+	return mode, nil
+}
+
 func ParseMessagePrivacyMode(raw string) (MessagePrivacyMode, error) {
 	mode := MessagePrivacyMode(strings.TrimSpace(raw))
 	if !mode.Valid() {
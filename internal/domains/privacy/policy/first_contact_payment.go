@@ -0,0 +1,11 @@
+package policy
+
+import "errors"
+
+var ErrFirstContactPaymentRequired = errors.New("first-contact message requires a payment proof")
+
+// FirstContactPaymentRequired reports whether an inbound message must carry a
+// payment: only unknown senders pay, and only when a verifier is installed.
+func FirstContactPaymentRequired(isKnownContact bool, verifierInstalled bool) bool {
+	return !isKnownContact && verifierInstalled
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+import privacymodel "aim-chat/go-backend/internal/domains/privacy/model"
+
+// PowStampMaxSkew bounds how far a stamp timestamp may drift from the
+// recipient clock, so old stamps cannot be stockpiled.
+const PowStampMaxSkew = 24 * time.Hour
+
+var ErrPowStampRequired = errors.New("first-contact message requires a proof-of-work stamp")
+var ErrPowStampInsufficient = errors.New("proof-of-work stamp is below the required difficulty")
+var ErrPowStampExpired = errors.New("proof-of-work stamp timestamp is out of range")
+var ErrPowStampInvalid = errors.New("proof-of-work stamp does not match its difficulty")
+
+// FirstContactPowRequired reports whether an inbound message must carry
+// a stamp: only unknown senders pay, and only when a difficulty is set.
+func FirstContactPowRequired(isKnownContact bool, requiredBits int) bool {
+	return !isKnownContact && requiredBits > 0
+}
+
+// MintPowStamp searches for a nonce whose stamp hash has at least difficulty
+// leading zero bits. The search stops early when ctx is cancelled.
+func MintPowStamp(ctx context.Context, senderID, recipientID, messageID string, difficulty int, now time.Time) (models.PowStamp, error) {
+	if difficulty <= 0 || difficulty > privacymodel.MaxFirstContactPowBits {
+		return models.PowStamp{}, privacymodel.ErrInvalidPowDifficulty
+	}
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return models.PowStamp{}, err
+	}
+	stamp := models.PowStamp{
+		Bits:      difficulty,
+		Timestamp: now.Unix(),
+		Nonce:     binary.BigEndian.Uint64(seed[:]),
+	}
+	for i := 0; ; i++ {
+		if i%4096 == 0 && ctx != nil {
+			if err := ctx.Err(); err != nil {
+				return models.PowStamp{}, err
+			}
+		}
+		if powLeadingZeroBits(powStampHash(senderID, recipientID, messageID, stamp)) >= difficulty {
+			return stamp, nil
+		}
+		stamp.Nonce++
+	}
+}
+
+// VerifyPowStamp checks a first-contact stamp against the difficulty the
+// recipient currently requires.
+func VerifyPowStamp(stamp *models.PowStamp, senderID, recipientID, messageID string, requiredBits int, now time.Time) error {
+	if requiredBits <= 0 {
+		return nil
+	}
+	if stamp == nil {
+		return ErrPowStampRequired
+	}
+	if stamp.Bits < requiredBits {
+		return ErrPowStampInsufficient
+	}
+	issuedAt := time.Unix(stamp.Timestamp, 0)
+	if issuedAt.Before(now.Add(-PowStampMaxSkew)) || issuedAt.After(now.Add(PowStampMaxSkew)) {
+		return ErrPowStampExpired
+	}
+	if powLeadingZeroBits(powStampHash(senderID, recipientID, messageID, *stamp)) < stamp.Bits {
+		return ErrPowStampInvalid
+	}
+	return nil
+}
+
+func powStampHash(senderID, recipientID, messageID string, stamp models.PowStamp) [sha256.Size]byte {
+	b := make([]byte, 0, len(senderID)+len(recipientID)+len(messageID)+32)
+	b = append(b, "aim-pow-v1"...)
+	b = append(b, 0)
+	b = append(b, senderID...)
+	b = append(b, 0)
+	b = append(b, recipientID...)
+	b = append(b, 0)
+	b = append(b, messageID...)
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint64(b, uint64(stamp.Timestamp))
+	b = binary.BigEndian.AppendUint64(b, stamp.Nonce)
+	return sha256.Sum256(b)
+}
+
+func powLeadingZeroBits(sum [sha256.Size]byte) int {
+	total := 0
+	for _, v := range sum {
+		if v != 0 {
+			return total + bits.LeadingZeros8(v)
+		}
+		total += 8
+	}
+	return total
+}
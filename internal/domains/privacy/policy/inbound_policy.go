@@ -82,6 +82,13 @@ func EvaluateInboundMessagePolicy(input InboundMessagePolicyInput) InboundMessag
 	}
 }
 
+// ShouldIgnoreInboundSender reports whether traffic from a sender is dropped
+// before any other policy runs. A hidden identity gives strangers no reject,
+// receipt or request entry, so they cannot tell it exists.
+func ShouldIgnoreInboundSender(mode privacymodel.DiscoverabilityMode, isKnownContact bool) bool {
+	return mode == privacymodel.DiscoverabilityContactsOnly && !isKnownContact
+}
+
 // ShouldAutoAddUnknownSenderContact returns true when unknown sender in everyone mode
 // can be auto-added as contact without trusting card-bound auth.
 func ShouldAutoAddUnknownSenderContact(
@@ -229,6 +229,116 @@ func TestServiceStorageScopeOverrideRoundtripAndResolve(t *testing.T) {
 	}
 }
 
+func TestServiceStorageScopeOverrideBulkAndList(t *testing.T) {
+	bl, err := NewBlocklist(nil)
+	if err != nil {
+		t.Fatalf("new blocklist failed: %v", err)
+	}
+	initial := NormalizePrivacySettings(PrivacySettings{
+		StorageProtection:    StorageProtectionStandard,
+		ContentRetentionMode: RetentionEphemeral,
+		MessageTTLSeconds:    120,
+	})
+	store := &fakePrivacyStore{settings: initial}
+	svc := NewService(store, &fakeBlocklistStore{list: bl}, nil)
+	svc.SetState(initial, bl)
+
+	if _, err := svc.SetStorageScopeOverrides([]StorageScopeOverrideInput{
+		{StorageScopeRef: StorageScopeRef{Scope: "chat", ScopeID: "c1"}},
+		{StorageScopeRef: StorageScopeRef{Scope: "group"}},
+	}); err == nil {
+		t.Fatal("expected missing scope id to be rejected")
+	}
+	if len(store.settings.StorageScopeOverrides) != 0 {
+		t.Fatalf("invalid bulk set must not persist anything: %+v", store.settings.StorageScopeOverrides)
+	}
+
+	entries, err := svc.SetStorageScopeOverrides([]StorageScopeOverrideInput{
+		{
+			StorageScopeRef: StorageScopeRef{Scope: "group", ScopeID: "g1"},
+			Override: StoragePolicyOverride{
+				StorageProtection:      StorageProtectionProtected,
+				ContentRetentionMode:   RetentionPersistent,
+				InfiniteTTL:            true,
+				PinRequiredForInfinite: true,
+			},
+		},
+		{
+			StorageScopeRef: StorageScopeRef{Scope: "chat", ScopeID: "c1"},
+			Override: StoragePolicyOverride{
+				StorageProtection:    StorageProtectionProtected,
+				ContentRetentionMode: RetentionPersistent,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("bulk set failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ScopeID != "g1" || entries[1].ScopeID != "c1" {
+		t.Fatalf("bulk set must describe items in request order: %+v", entries)
+	}
+
+	listed, err := svc.ListStorageScopeOverrides()
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(listed) != 2 || listed[0].Scope != StoragePolicyScope("chat") || listed[1].Scope != StoragePolicyScope("group") {
+		t.Fatalf("unexpected listed overrides: %+v", listed)
+	}
+	chat, group := listed[0], listed[1]
+	if chat.Effective == nil || chat.Effective.ContentRetentionMode != RetentionPersistent || len(chat.Warnings) != 0 {
+		t.Fatalf("unexpected chat entry: %+v", chat)
+	}
+	if group.Effective != nil || group.EffectivePinned.ContentRetentionMode != RetentionPersistent {
+		t.Fatalf("pin-gated override must only resolve for pinned content: %+v", group)
+	}
+	if len(group.Warnings) != 1 || group.Warnings[0] != ScopeWarningInfiniteTTLRequiresPin {
+		t.Fatalf("unexpected group warnings: %v", group.Warnings)
+	}
+
+	removed, err := svc.RemoveStorageScopeOverrides([]StorageScopeRef{
+		{Scope: "group", ScopeID: "g1"},
+		{Scope: "group", ScopeID: "missing"},
+	})
+	if err != nil {
+		t.Fatalf("bulk delete failed: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected one removed override, got %d", removed)
+	}
+	if _, exists := store.settings.StorageScopeOverrides["group:g1"]; exists {
+		t.Fatal("removed override must be persisted")
+	}
+}
+
+func TestDescribeStorageScopeOverrideReportsHoldConflicts(t *testing.T) {
+	policies := DefaultNodePolicies()
+	policies.Personal.PinEnabled = false
+	settings := NormalizePrivacySettings(PrivacySettings{
+		ContentRetentionMode: RetentionZeroRetention,
+		NodePolicies:         &policies,
+	})
+	entry := DescribeStorageScopeOverride(settings, StoragePolicyScope("chat"), "c1", StoragePolicyOverride{
+		StorageProtection:      StorageProtectionProtected,
+		ContentRetentionMode:   RetentionPersistent,
+		InfiniteTTL:            true,
+		PinRequiredForInfinite: true,
+	})
+	want := []string{
+		ScopeWarningInfiniteTTLRequiresPin,
+		ScopeWarningPinningDisabled,
+		ScopeWarningHoldBlockedByZeroRetention,
+	}
+	if len(entry.Warnings) != len(want) {
+		t.Fatalf("unexpected warnings: %v", entry.Warnings)
+	}
+	for i := range want {
+		if entry.Warnings[i] != want[i] {
+			t.Fatalf("unexpected warnings: %v", entry.Warnings)
+		}
+	}
+}
+
 func TestServiceUpdateNodePoliciesPersistsIndependently(t *testing.T) {
 	bl, err := NewBlocklist(nil)
 	if err != nil {
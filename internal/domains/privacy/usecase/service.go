@@ -132,6 +132,135 @@ func (s *Service) UpdatePrivacySettings(mode string) (privacymodel.PrivacySettin
 	return updated, nil
 }
 
+// Discoverability returns who may learn that the identity is reachable.
+func (s *Service) Discoverability() privacymodel.DiscoverabilityMode {
+	s.mu.RLock()
+	mode := s.privacy.Discoverability
+	s.mu.RUnlock()
+	return mode
+}
+
+func (s *Service) UpdateDiscoverability(mode string) (privacymodel.PrivacySettings, error) {
+	parsedMode, err := privacymodel.ParseDiscoverabilityMode(mode)
+	if err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	updated := current
+	updated.Discoverability = parsedMode
+	updated = privacymodel.NormalizePrivacySettings(updated)
+	if err := s.privacyState.Persist(updated); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return privacymodel.PrivacySettings{}, err
+	}
+
+	s.mu.Lock()
+	s.privacy = updated
+	s.mu.Unlock()
+	return updated, nil
+}
+
+// FirstContactPowBits returns the proof-of-work required from unknown senders.
+func (s *Service) FirstContactPowBits() int {
+	s.mu.RLock()
+	bits := s.privacy.FirstContactPowBits
+	s.mu.RUnlock()
+	return bits
+}
+
+func (s *Service) UpdateFirstContactPow(bits int) (privacymodel.PrivacySettings, error) {
+	if bits < 0 || bits > privacymodel.MaxFirstContactPowBits {
+		return privacymodel.PrivacySettings{}, privacymodel.ErrInvalidPowDifficulty
+	}
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	updated := current
+	updated.FirstContactPowBits = bits
+	updated = privacymodel.NormalizePrivacySettings(updated)
+	if err := s.privacyState.Persist(updated); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return privacymodel.PrivacySettings{}, err
+	}
+
+	s.mu.Lock()
+	s.privacy = updated
+	s.mu.Unlock()
+	return updated, nil
+}
+
+// CoverTraffic returns the current cover traffic settings.
+func (s *Service) CoverTraffic() privacymodel.CoverTrafficSettings {
+	s.mu.RLock()
+	settings := s.privacy.CoverTraffic
+	s.mu.RUnlock()
+	return settings
+}
+
+func (s *Service) UpdateCoverTraffic(in privacymodel.CoverTrafficSettings) (privacymodel.PrivacySettings, error) {
+	if err := privacymodel.ValidateCoverTrafficSettings(in); err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	updated := current
+	updated.CoverTraffic = in
+	updated = privacymodel.NormalizePrivacySettings(updated)
+	if err := s.privacyState.Persist(updated); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return privacymodel.PrivacySettings{}, err
+	}
+
+	s.mu.Lock()
+	s.privacy = updated
+	s.mu.Unlock()
+	return updated, nil
+}
+
+// DailySummary returns the current daily summary schedule.
+func (s *Service) DailySummary() privacymodel.DailySummarySettings {
+	s.mu.RLock()
+	settings := s.privacy.DailySummary
+	s.mu.RUnlock()
+	return settings
+}
+
+func (s *Service) UpdateDailySummary(in privacymodel.DailySummarySettings) (privacymodel.PrivacySettings, error) {
+	if err := privacymodel.ValidateDailySummarySettings(in); err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return privacymodel.PrivacySettings{}, err
+	}
+	updated := current
+	updated.DailySummary = in
+	updated = privacymodel.NormalizePrivacySettings(updated)
+	if err := s.privacyState.Persist(updated); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return privacymodel.PrivacySettings{}, err
+	}
+
+	s.mu.Lock()
+	s.privacy = updated
+	s.mu.Unlock()
+	return updated, nil
+}
+
 func (s *Service) GetStoragePolicy() (privacymodel.StoragePolicy, error) {
 	settings, err := s.GetPrivacySettings()
 	if err != nil {
@@ -262,6 +391,86 @@ func (s *Service) RemoveStorageScopeOverride(scope, scopeID string) (bool, error
 	return true, nil
 }
 
+func (s *Service) ListStorageScopeOverrides() ([]privacymodel.StorageScopeOverrideEntry, error) {
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return nil, err
+	}
+	return privacymodel.ListStorageScopeOverrides(current), nil
+}
+
+// SetStorageScopeOverrides applies several overrides at once. Every item is
+// validated first, so either all of them are stored or none is.
+func (s *Service) SetStorageScopeOverrides(items []privacymodel.StorageScopeOverrideInput) ([]privacymodel.StorageScopeOverrideEntry, error) {
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return nil, err
+	}
+	if current.StorageScopeOverrides == nil {
+		current.StorageScopeOverrides = map[string]privacymodel.StoragePolicyOverride{}
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		key, err := privacymodel.ScopeOverrideKey(item.Scope, item.ScopeID)
+		if err != nil {
+			return nil, err
+		}
+		current.StorageScopeOverrides[key] = privacymodel.NormalizeStoragePolicyOverride(item.Override)
+		keys = append(keys, key)
+	}
+	current = privacymodel.NormalizePrivacySettings(current)
+	if err := s.privacyState.Persist(current); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return nil, err
+	}
+	s.mu.Lock()
+	s.privacy = current
+	s.mu.Unlock()
+
+	out := make([]privacymodel.StorageScopeOverrideEntry, 0, len(keys))
+	for _, key := range keys {
+		scope, scopeID, _ := privacymodel.ParseScopeOverrideKey(key)
+		out = append(out, privacymodel.DescribeStorageScopeOverride(current, scope, scopeID, current.StorageScopeOverrides[key]))
+	}
+	return out, nil
+}
+
+// RemoveStorageScopeOverrides deletes several overrides at once and reports
+// how many existed.
+func (s *Service) RemoveStorageScopeOverrides(refs []privacymodel.StorageScopeRef) (int, error) {
+	current, err := s.GetPrivacySettings()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, ref := range refs {
+		key, err := privacymodel.ScopeOverrideKey(ref.Scope, ref.ScopeID)
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := current.StorageScopeOverrides[key]; ok {
+			delete(current.StorageScopeOverrides, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	current = privacymodel.NormalizePrivacySettings(current)
+	if err := s.privacyState.Persist(current); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return 0, err
+	}
+	s.mu.Lock()
+	s.privacy = current
+	s.mu.Unlock()
+	return removed, nil
+}
+
 func (s *Service) ResolveStoragePolicy(scope, scopeID string, isPinned bool) (privacymodel.StoragePolicy, error) {
 	current, err := s.GetPrivacySettings()
 	if err != nil {
@@ -350,6 +559,51 @@ func (s *Service) updateBlocklist(mutate func(privacymodel.Blocklist) error) ([]
 	return next.List(), nil
 }
 
+// IsBlockedInGroup reports whether messages of memberID are hidden in
+// groupID.
+func (s *Service) IsBlockedInGroup(groupID, memberID string) bool {
+	s.mu.RLock()
+	blocked := s.privacy.GroupMemberBlocks.Contains(groupID, memberID)
+	s.mu.RUnlock()
+	return blocked
+}
+
+// GroupMemberBlocks returns the members blocked in groupID.
+func (s *Service) GroupMemberBlocks(groupID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.privacy.GroupMemberBlocks.Members(groupID)
+}
+
+func (s *Service) BlockGroupMember(groupID, memberID string) ([]string, error) {
+	return s.setGroupMemberBlocked(groupID, memberID, true)
+}
+
+func (s *Service) UnblockGroupMember(groupID, memberID string) ([]string, error) {
+	return s.setGroupMemberBlocked(groupID, memberID, false)
+}
+
+func (s *Service) setGroupMemberBlocked(groupID, memberID string, blocked bool) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blocks, err := s.privacy.GroupMemberBlocks.With(groupID, memberID, blocked)
+	if err != nil {
+		return nil, err
+	}
+	updated := s.privacy
+	updated.GroupMemberBlocks = blocks
+	updated = privacymodel.NormalizePrivacySettings(updated)
+	if err := s.privacyState.Persist(updated); err != nil {
+		if s.recordError != nil {
+			s.recordError("storage", err)
+		}
+		return nil, err
+	}
+	s.privacy = updated
+	return updated.GroupMemberBlocks.Members(groupID), nil
+}
+
 func (s *Service) WipeState() error {
 	var wipeErr error
 	if wiper, ok := s.privacyState.(interface{ Wipe() error }); ok {
@@ -1,10 +1,20 @@
 package rpckit
 
+import "errors"
+
 // Error is a transport-level RPC error that can be mapped by the caller
 // to a concrete wire format (e.g. JSON-RPC error object).
 type Error struct {
 	Code    int
 	Message string
+	// Data is optional structured detail for the error "data" member.
+	Data any
+}
+
+// DataError is implemented by service errors that carry machine-readable
+// detail, such as per-recipient delivery stats.
+type DataError interface {
+	ErrorData() any
 }
 
 func InvalidParams() *Error {
@@ -12,5 +22,15 @@ func InvalidParams() *Error {
 }
 
 func ServiceError(code int, err error) *Error {
-	return &Error{Code: code, Message: err.Error()}
+	return &Error{Code: code, Message: err.Error(), Data: ErrorData(err)}
+}
+
+// ErrorData returns the structured detail of err, or nil when none of the
+// wrapped errors carries any.
+func ErrorData(err error) any {
+	var dataErr DataError
+	if errors.As(err, &dataErr) {
+		return dataErr.ErrorData()
+	}
+	return nil
 }
@@ -8,16 +8,27 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"aim-chat/go-backend/internal/bootstrap/enrollmenttoken"
+	"aim-chat/go-backend/internal/platform/datadirlock"
 )
 
 var hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
 
+// maxClockOffset is the peer clock offset above which expiry checks on
+// enrollment tokens and manifests are likely to misjudge.
+const maxClockOffset = 2 * time.Minute
+
 type DoctorInput struct {
 	ListenPort       int
 	AdvertiseAddress string
 	RPCAddr          string
 	RPCToken         string
 	MinPeers         int
+	// StoreNodeEnabled and NetworkSegment come from the daemon config and
+	// are checked against the enrollment grant.
+	StoreNodeEnabled bool
+	NetworkSegment   string
 }
 
 type DoctorCheck struct {
@@ -27,9 +38,16 @@ type DoctorCheck struct {
 }
 
 type DoctorReport struct {
-	Ready     bool          `json:"ready"`
-	Checks    []DoctorCheck `json:"checks"`
-	CheckedAt time.Time     `json:"checked_at"`
+	Ready       bool               `json:"ready"`
+	Checks      []DoctorCheck      `json:"checks"`
+	DataDirLock datadirlock.Status `json:"data_dir_lock"`
+	// Capabilities is the set the enrollment token allows the node to run.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// ClockOffsetMS is how far the peers' clock is ahead of the local one,
+	// as estimated by the daemon.
+	ClockOffsetMS   int64     `json:"clock_offset_ms"`
+	ClockConfidence string    `json:"clock_confidence,omitempty"`
+	CheckedAt       time.Time `json:"checked_at"`
 }
 
 func (s *Service) Doctor(ctx context.Context, input DoctorInput) (DoctorReport, error) {
@@ -55,6 +73,19 @@ func (s *Service) Doctor(ctx context.Context, input DoctorInput) (DoctorReport,
 	}
 	appendCheck("state_initialized", exists, failReason(!exists, "node-agent is not initialized"))
 	appendCheck("state_enrolled", exists && state.Enrollment != nil, failReason(!(exists && state.Enrollment != nil), "node is not enrolled"))
+	if exists && state.Enrollment != nil {
+		grant := state.Enrollment.grant()
+		report.Capabilities = grant.ActiveCapabilities()
+		reason := enrollmentGrantReason(grant, input)
+		appendCheck("enrollment_grant", reason == "", reason)
+	}
+
+	lock, err := datadirlock.Inspect(s.dataDir, now)
+	if err != nil {
+		return DoctorReport{}, err
+	}
+	report.DataDirLock = lock
+	appendCheck("data_dir_lock", !lock.Stale, failReason(lock.Stale, staleLockReason(lock)))
 
 	listenPortValid := input.ListenPort >= 1 && input.ListenPort <= 65535
 	appendCheck("listen_port_valid", listenPortValid, failReason(!listenPortValid, "listen port must be in [1..65535]"))
@@ -74,17 +105,53 @@ func (s *Service) Doctor(ctx context.Context, input DoctorInput) (DoctorReport,
 	}
 
 	if strings.TrimSpace(input.RPCAddr) != "" {
-		peerCount, err := s.probe(ctx, input.RPCAddr, input.RPCToken)
+		probe, err := s.probe(ctx, input.RPCAddr, input.RPCToken)
 		if err != nil {
 			appendCheck("rpc_reachable", false, err.Error())
 		} else {
+			peerCount := probe.PeerCount
 			appendCheck("rpc_reachable", true, "")
 			appendCheck("peer_count_min", peerCount >= input.MinPeers, failReason(peerCount < input.MinPeers, fmt.Sprintf("peer_count=%d < min_peers=%d", peerCount, input.MinPeers)))
+			report.ClockOffsetMS = probe.ClockOffsetMS
+			report.ClockConfidence = probe.ClockConfidence
+			skewed := clockSkewed(probe)
+			appendCheck("clock_offset", !skewed, failReason(skewed, clockSkewReason(probe)))
 		}
 	}
 	return report, nil
 }
 
+// clockSkewed only trusts a high confidence estimate; a few disagreeing
+// peers are not enough to call the local clock wrong.
+func clockSkewed(probe networkProbe) bool {
+	if probe.ClockConfidence != "high" {
+		return false
+	}
+	offset := time.Duration(probe.ClockOffsetMS) * time.Millisecond
+	return offset > maxClockOffset || offset < -maxClockOffset
+}
+
+func clockSkewReason(probe networkProbe) string {
+	offset := time.Duration(probe.ClockOffsetMS) * time.Millisecond
+	direction := "behind"
+	if offset < 0 {
+		direction, offset = "ahead of", -offset
+	}
+	return fmt.Sprintf("local clock is %s %s peers; expiry checks may misjudge tokens and manifests", offset.Round(time.Second), direction)
+}
+
+// enrollmentGrantReason explains why the daemon would refuse the config, or
+// returns "" when the grant covers it.
+func enrollmentGrantReason(grant enrollmenttoken.Grant, input DoctorInput) string {
+	if !grant.AllowsSegment(input.NetworkSegment) {
+		return fmt.Sprintf("network segment %q is not claimed by the enrollment token", input.NetworkSegment)
+	}
+	if input.StoreNodeEnabled && !grant.Allows(enrollmenttoken.CapabilityStoreEnabled) {
+		return "store node is enabled but not claimed by the enrollment token; the daemon will run without it"
+	}
+	return ""
+}
+
 func failReason(failed bool, reason string) string {
 	if !failed {
 		return ""
@@ -92,6 +159,14 @@ func failReason(failed bool, reason string) string {
 	return reason
 }
 
+func staleLockReason(lock datadirlock.Status) string {
+	if lock.Owner == nil {
+		return "data dir lock file is unreadable; start the daemon with --force-takeover"
+	}
+	return fmt.Sprintf("stale data dir lock of pid %d (last heartbeat %s); start the daemon with --force-takeover",
+		lock.Owner.PID, lock.Owner.HeartbeatAt.Format(time.RFC3339))
+}
+
 func checkPortAvailable(port int) error {
 	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -2,9 +2,14 @@ package nodeagent
 
 import (
 	"context"
+	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"aim-chat/go-backend/internal/platform/datadirlock"
 )
 
 func TestDoctorDetectsUnavailablePort(t *testing.T) {
@@ -90,7 +95,9 @@ func TestDoctorPassesReadyNode(t *testing.T) {
 	if err := svc.saveState(state); err != nil {
 		t.Fatalf("save state: %v", err)
 	}
-	svc.probe = func(context.Context, string, string) (int, error) { return 3, nil }
+	svc.probe = func(context.Context, string, string) (networkProbe, error) {
+		return networkProbe{PeerCount: 3, ClockOffsetMS: 1500, ClockConfidence: "high"}, nil
+	}
 
 	report, err := svc.Doctor(context.Background(), DoctorInput{
 		ListenPort:       freePort(t),
@@ -105,6 +112,94 @@ func TestDoctorPassesReadyNode(t *testing.T) {
 		t.Fatalf("expected readiness pass, report=%+v", report)
 	}
 	assertCheck(t, report, "peer_count_min", true)
+	assertCheck(t, report, "data_dir_lock", true)
+	assertCheck(t, report, "clock_offset", true)
+	if report.ClockOffsetMS != 1500 || report.ClockConfidence != "high" {
+		t.Fatalf("expected clock offset in report, got %d (%s)", report.ClockOffsetMS, report.ClockConfidence)
+	}
+}
+
+func TestDoctorFlagsSkewedClockOnlyWithConfidence(t *testing.T) {
+	svc := New(t.TempDir())
+	input := DoctorInput{ListenPort: freePort(t), RPCAddr: "127.0.0.1:8787", MinPeers: 1}
+
+	svc.probe = func(context.Context, string, string) (networkProbe, error) {
+		return networkProbe{PeerCount: 5, ClockOffsetMS: -(10 * time.Minute).Milliseconds(), ClockConfidence: "high"}, nil
+	}
+	report, err := svc.Doctor(context.Background(), input)
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	assertCheck(t, report, "clock_offset", false)
+
+	svc.probe = func(context.Context, string, string) (networkProbe, error) {
+		return networkProbe{PeerCount: 3, ClockOffsetMS: -(10 * time.Minute).Milliseconds(), ClockConfidence: "low"}, nil
+	}
+	report, err = svc.Doctor(context.Background(), input)
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	assertCheck(t, report, "clock_offset", true)
+}
+
+func TestDoctorChecksEnrollmentGrant(t *testing.T) {
+	svc := New(t.TempDir())
+	if _, _, err := svc.Init(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	state, exists, err := svc.loadState()
+	if err != nil || !exists {
+		t.Fatalf("load state failed: exists=%v err=%v", exists, err)
+	}
+	state.Enrollment = &EnrollmentState{
+		TokenID:         "tok-1",
+		ExpiresAt:       time.Now().UTC().Add(10 * time.Minute),
+		Capabilities:    []string{"relay_only"},
+		NetworkSegments: []string{"eu-1"},
+	}
+	if err := svc.saveState(state); err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+
+	report, err := svc.Doctor(context.Background(), DoctorInput{ListenPort: freePort(t), NetworkSegment: "eu-1"})
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	assertCheck(t, report, "enrollment_grant", true)
+	if len(report.Capabilities) != 1 || report.Capabilities[0] != "relay_only" {
+		t.Fatalf("unexpected capabilities: %v", report.Capabilities)
+	}
+
+	for _, input := range []DoctorInput{
+		{ListenPort: freePort(t), NetworkSegment: "us-1"},
+		{ListenPort: freePort(t), NetworkSegment: "eu-1", StoreNodeEnabled: true},
+	} {
+		report, err := svc.Doctor(context.Background(), input)
+		if err != nil {
+			t.Fatalf("doctor failed: %v", err)
+		}
+		assertCheck(t, report, "enrollment_grant", false)
+	}
+}
+
+func TestDoctorReportsStaleDataDirLock(t *testing.T) {
+	dir := t.TempDir()
+	svc := New(dir)
+	heartbeat := time.Now().UTC().Add(-time.Hour)
+	raw := fmt.Sprintf(`{"pid":4242,"hostname":"other-host","started_at":%q,"heartbeat_at":%q}`,
+		heartbeat.Format(time.RFC3339), heartbeat.Format(time.RFC3339))
+	if err := os.WriteFile(filepath.Join(dir, datadirlock.FileName), []byte(raw), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	report, err := svc.Doctor(context.Background(), DoctorInput{ListenPort: freePort(t)})
+	if err != nil {
+		t.Fatalf("doctor failed: %v", err)
+	}
+	assertCheck(t, report, "data_dir_lock", false)
+	if !report.DataDirLock.Stale || report.DataDirLock.Owner == nil || report.DataDirLock.Owner.PID != 4242 {
+		t.Fatalf("unexpected lock status: %+v", report.DataDirLock)
+	}
 }
 
 func freePort(t *testing.T) int {
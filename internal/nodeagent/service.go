@@ -31,6 +31,12 @@ type EnrollmentState struct {
 	KeyID            string    `json:"key_id"`
 	ExpiresAt        time.Time `json:"expires_at"`
 	EnrolledAt       time.Time `json:"enrolled_at"`
+	Capabilities     []string  `json:"capabilities,omitempty"`
+	NetworkSegments  []string  `json:"network_segments,omitempty"`
+}
+
+func (e EnrollmentState) grant() enrollmenttoken.Grant {
+	return enrollmenttoken.Grant{TokenID: e.TokenID, Capabilities: e.Capabilities, NetworkSegments: e.NetworkSegments}
 }
 
 type State struct {
@@ -43,21 +49,31 @@ type State struct {
 }
 
 type Status struct {
-	NodeID      string    `json:"node_id"`
-	Initialized bool      `json:"initialized"`
-	Enrolled    bool      `json:"enrolled"`
-	ProfileID   string    `json:"profile_id,omitempty"`
-	Health      string    `json:"health"`
-	PeerCount   int       `json:"peer_count"`
-	CheckedAt   time.Time `json:"checked_at"`
-	Source      string    `json:"source"`
-	LastError   string    `json:"last_error,omitempty"`
+	NodeID      string `json:"node_id"`
+	Initialized bool   `json:"initialized"`
+	Enrolled    bool   `json:"enrolled"`
+	ProfileID   string `json:"profile_id,omitempty"`
+	// Capabilities is the set the enrollment token allows the node to run.
+	Capabilities    []string  `json:"capabilities,omitempty"`
+	NetworkSegments []string  `json:"network_segments,omitempty"`
+	Health          string    `json:"health"`
+	PeerCount       int       `json:"peer_count"`
+	CheckedAt       time.Time `json:"checked_at"`
+	Source          string    `json:"source"`
+	LastError       string    `json:"last_error,omitempty"`
 }
 
 type Service struct {
 	dataDir string
 	now     func() time.Time
-	probe   func(ctx context.Context, rpcAddr, rpcToken string) (int, error)
+	probe   func(ctx context.Context, rpcAddr, rpcToken string) (networkProbe, error)
+}
+
+// networkProbe is the part of network.status the node agent reads.
+type networkProbe struct {
+	PeerCount       int    `json:"peer_count"`
+	ClockOffsetMS   int64  `json:"clock_offset_ms"`
+	ClockConfidence string `json:"clock_confidence"`
 }
 
 func New(dataDir string) *Service {
@@ -67,7 +83,7 @@ func New(dataDir string) *Service {
 	return &Service{
 		dataDir: dataDir,
 		now:     func() time.Time { return time.Now().UTC() },
-		probe:   probePeerCount,
+		probe:   probeNetworkStatus,
 	}
 }
 
@@ -130,11 +146,18 @@ func (s *Service) Enroll(token string, issuerKeys map[string]ed25519.PublicKey)
 		KeyID:            claims.KeyID,
 		ExpiresAt:        claims.ExpiresAt.UTC(),
 		EnrolledAt:       s.now(),
+		Capabilities:     claims.Capabilities,
+		NetworkSegments:  claims.NetworkSegments,
 	}
 	state.Enrollment = enrollment
 	if err := s.saveState(state); err != nil {
 		return EnrollmentState{}, err
 	}
+	// The daemon reads the grant from the shared data dir when it starts.
+	// It is written last so a failed enrollment never restricts the node.
+	if err := enrollmenttoken.SaveGrant(s.dataDir, claims.Grant()); err != nil {
+		return EnrollmentState{}, err
+	}
 	return *enrollment, nil
 }
 
@@ -166,6 +189,9 @@ func (s *Service) Status(ctx context.Context, rpcAddr, rpcToken string) (Status,
 		Source:      "local",
 	}
 	if state.Enrollment != nil {
+		grant := state.Enrollment.grant()
+		status.Capabilities = grant.ActiveCapabilities()
+		status.NetworkSegments = grant.NetworkSegments
 		if state.Enrollment.ExpiresAt.After(now) {
 			status.Health = "enrolled"
 		} else {
@@ -174,9 +200,9 @@ func (s *Service) Status(ctx context.Context, rpcAddr, rpcToken string) (Status,
 		}
 	}
 	if strings.TrimSpace(rpcAddr) != "" {
-		peerCount, err := s.probe(ctx, rpcAddr, rpcToken)
+		probe, err := s.probe(ctx, rpcAddr, rpcToken)
 		if err == nil {
-			status.PeerCount = peerCount
+			status.PeerCount = probe.PeerCount
 			status.Source = "rpc"
 		} else if status.LastError == "" {
 			status.LastError = err.Error()
@@ -218,7 +244,7 @@ func generateNodeID(publicKey ed25519.PublicKey) string {
 	return "node_" + encoded
 }
 
-func probePeerCount(ctx context.Context, rpcAddr, rpcToken string) (peerCount int, retErr error) {
+func probeNetworkStatus(ctx context.Context, rpcAddr, rpcToken string) (probe networkProbe, retErr error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -228,7 +254,7 @@ func probePeerCount(ctx context.Context, rpcAddr, rpcToken string) (peerCount in
 	body := `{"jsonrpc":"2.0","id":1,"method":"network.status","params":[]}`
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+strings.TrimSpace(rpcAddr), strings.NewReader(body))
 	if err != nil {
-		return 0, err
+		return networkProbe{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	if strings.TrimSpace(rpcToken) != "" {
@@ -236,7 +262,7 @@ func probePeerCount(ctx context.Context, rpcAddr, rpcToken string) (peerCount in
 	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return 0, err
+		return networkProbe{}, err
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil && retErr == nil {
@@ -244,19 +270,17 @@ func probePeerCount(ctx context.Context, rpcAddr, rpcToken string) (peerCount in
 		}
 	}()
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("rpc status %d", resp.StatusCode)
+		return networkProbe{}, fmt.Errorf("rpc status %d", resp.StatusCode)
 	}
 	var decoded struct {
-		Result struct {
-			PeerCount int `json:"peer_count"`
-		} `json:"result"`
-		Error any `json:"error"`
+		Result networkProbe `json:"result"`
+		Error  any          `json:"error"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
-		return 0, err
+		return networkProbe{}, err
 	}
 	if decoded.Error != nil {
-		return 0, errors.New("rpc returned error")
+		return networkProbe{}, errors.New("rpc returned error")
 	}
-	return decoded.Result.PeerCount, nil
+	return decoded.Result, nil
 }
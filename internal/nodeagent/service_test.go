@@ -71,7 +71,8 @@ func TestEnrollRejectsInvalidIssuer(t *testing.T) {
 }
 
 func TestEnrollSingleUseToken(t *testing.T) {
-	svc := New(t.TempDir())
+	dataDir := t.TempDir()
+	svc := New(dataDir)
 	now := time.Date(2026, 2, 19, 12, 0, 0, 0, time.UTC)
 	svc.now = func() time.Time { return now }
 	if _, _, err := svc.Init(); err != nil {
@@ -94,6 +95,9 @@ func TestEnrollSingleUseToken(t *testing.T) {
 	if _, err := svc.Enroll(token, map[string]ed25519.PublicKey{"issuer-k1": pub}); err != nil {
 		t.Fatalf("first enroll failed: %v", err)
 	}
+	if grant, ok, err := enrollmenttoken.LoadGrant(dataDir); err != nil || !ok || grant.TokenID != claims.TokenID {
+		t.Fatalf("expected enroll to persist the grant, got %+v ok=%v err=%v", grant, ok, err)
+	}
 	if _, err := svc.Enroll(token, map[string]ed25519.PublicKey{"issuer-k1": pub}); !errors.Is(err, enrollmenttoken.ErrTokenAlreadyUsed) {
 		t.Fatalf("expected ErrTokenAlreadyUsed, got %v", err)
 	}
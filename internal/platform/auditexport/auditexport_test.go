@@ -0,0 +1,192 @@
+package auditexport
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestParseTargetAndFormat(t *testing.T) {
+	target, err := ParseTarget("syslog: siem.local:6514 ")
+	if err != nil || target.Kind != TargetSyslog || target.Address != "siem.local:6514" {
+		t.Fatalf("unexpected target %+v (%v)", target, err)
+	}
+	for _, spec := range []string{"", "file:", "udp:siem.local:514"} {
+		if _, err := ParseTarget(spec); !errors.Is(err, ErrInvalidTarget) {
+			t.Fatalf("%q: expected ErrInvalidTarget, got %v", spec, err)
+		}
+	}
+	if format, err := ParseFormat(""); err != nil || format != FormatJSONLines {
+		t.Fatalf("expected JSON Lines by default, got %q (%v)", format, err)
+	}
+	if _, err := ParseFormat("leef"); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestEncodeCEFEscapesAndMapsFields(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec := FromAuditEvent(models.AuditEvent{Seq: 7, At: at, Kind: "device.revoked", Details: map[string]string{"device_id": "d|1=x"}, Hash: "h7", PrevHash: "h6"})
+	line, err := encodeCEF(rec)
+	if err != nil {
+		t.Fatalf("encode cef: %v", err)
+	}
+	want := `CEF:0|Ardents|aim-chat|1|device.revoked|device.revoked|3|rt=1772366400000 cat=audit externalId=7 cs1Label=details cs1=device_id\=d|1\=x cs2Label=hash cs2=h7 cs3Label=prev_hash cs3=h6` + "\n"
+	if string(line) != want {
+		t.Fatalf("unexpected cef line\n got %s\nwant %s", line, want)
+	}
+
+	alert := FromSecurityAlert(at, events.SecurityAlert{ContactID: "aim1_bob", Kind: "key.changed", Message: "key changed | verify\nnow"})
+	line, err = encodeCEF(alert)
+	if err != nil {
+		t.Fatalf("encode cef: %v", err)
+	}
+	if !strings.HasPrefix(string(line), `CEF:0|Ardents|aim-chat|1|key.changed|key changed \| verify now|7|`) || !strings.Contains(string(line), `duid=aim1_bob msg=key changed | verify\nnow`) {
+		t.Fatalf("unexpected alert line %s", line)
+	}
+	framed := frameSyslog(alert, "node-1", line)
+	if !strings.HasPrefix(string(framed), "<108>1 2026-03-01T12:00:00Z node-1 aim-chat - security_alert - CEF:0|") {
+		t.Fatalf("unexpected syslog framing %s", framed)
+	}
+}
+
+func TestFieldMappingsCoverTypedEvents(t *testing.T) {
+	mappings := FieldMappings()
+	if len(mappings) == 0 {
+		t.Fatal("expected field mappings")
+	}
+	doc := FieldMappingDoc()
+	for _, m := range mappings {
+		if m.JSONKey == "" || m.CEFKey == "" {
+			t.Fatalf("field %s of %s is not mapped", m.Field, m.Source)
+		}
+		if !strings.Contains(doc, "| "+m.Source+" | "+m.Field+" |") {
+			t.Fatalf("doc misses %s.%s", m.Source, m.Field)
+		}
+	}
+}
+
+// gatedWriter fails writes until opened, then records the lines.
+type gatedWriter struct {
+	mu    sync.Mutex
+	open  bool
+	lines []string
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.open {
+		return 0, errors.New("collector down")
+	}
+	w.lines = append(w.lines, string(p))
+	return len(p), nil
+}
+
+func (w *gatedWriter) setOpen() {
+	w.mu.Lock()
+	w.open = true
+	w.mu.Unlock()
+}
+
+func (w *gatedWriter) snapshot() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.lines...)
+}
+
+func TestExporterDropsOnFullQueueAndAnnouncesGap(t *testing.T) {
+	w := &gatedWriter{}
+	e := newExporter(Config{Format: FormatJSONLines, Target: Target{Kind: TargetFile, Address: "mem"}, QueueSize: 2}, w, nil)
+	publish := func(kind string) {
+		e.Publish(Record{Source: SourceAudit, At: time.Now().UTC(), Kind: kind})
+	}
+	publish("first")
+	waitFor(t, func() bool { return e.Status().WriteErrors > 0 })
+	// One record is being retried; two fill the queue, the rest are dropped.
+	for _, kind := range []string{"a", "b", "c", "d"} {
+		publish(kind)
+	}
+	if status := e.Status(); status.Dropped != 2 || status.Queued != 2 {
+		t.Fatalf("expected two dropped and two queued, got %+v", status)
+	}
+	w.setOpen()
+	waitFor(t, func() bool { return e.Status().Exported == 4 })
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	lines := w.snapshot()
+	var kinds []string
+	for _, line := range lines {
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		kinds = append(kinds, rec.Kind)
+		if rec.Kind == KindDropped && rec.Details["dropped"] != "2" {
+			t.Fatalf("expected the gap record to count two drops, got %+v", rec)
+		}
+	}
+	if strings.Join(kinds, ",") != "first,"+KindDropped+",a,b" {
+		t.Fatalf("unexpected export order %v", kinds)
+	}
+	e.Publish(Record{Kind: "after-close"})
+	var disabled *Exporter
+	disabled.Publish(Record{Kind: "ignored"})
+	if disabled.Status().Enabled {
+		t.Fatal("expected a nil exporter to report disabled")
+	}
+}
+
+// slowWriter holds each write until released, so records pile up in the
+// queue behind the first one.
+type slowWriter struct {
+	release chan struct{}
+	gatedWriter
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.gatedWriter.Write(p)
+}
+
+func TestExporterCloseFlushesQueuedRecords(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{})}
+	w.setOpen()
+	e := newExporter(Config{Format: FormatJSONLines, Target: Target{Kind: TargetFile, Address: "mem"}}, w, nil)
+	for _, kind := range []string{"a", "b", "c"} {
+		e.Publish(Record{Source: SourceAudit, At: time.Now().UTC(), Kind: kind})
+	}
+	waitFor(t, func() bool { return e.Status().Queued == 2 })
+
+	closed := make(chan error, 1)
+	go func() { closed <- e.Close() }()
+	close(w.release)
+	if err := <-closed; err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if lines := w.snapshot(); len(lines) != 3 {
+		t.Fatalf("expected every queued record to be written on close, got %q", lines)
+	}
+	if status := e.Status(); status.Exported != 3 || status.Dropped != 0 {
+		t.Fatalf("unexpected status after close: %+v", status)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,81 @@
+// Package auditexport streams security audit events and security alerts to
+// a SIEM. Records are encoded as JSON Lines or CEF and written to a
+// size-rotated file or, framed as RFC 5424 syslog, to a TCP collector.
+//
+// Publishing never blocks the daemon: records wait in a bounded queue while
+// the target lags, the newest ones are dropped once it is full, and the
+// first record written after a gap is preceded by an export.dropped record
+// counting what was lost.
+package auditexport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"aim-chat/go-backend/internal/platform/logsink"
+)
+
+const (
+	FormatJSONLines = "jsonl"
+	FormatCEF       = "cef"
+
+	TargetFile   = "file"
+	TargetSyslog = "syslog"
+
+	DefaultQueueSize = 1024
+)
+
+var (
+	ErrInvalidFormat = errors.New("invalid audit export format")
+	ErrInvalidTarget = errors.New("invalid audit export target")
+)
+
+// Config selects the encoding and destination of the export.
+type Config struct {
+	Format string
+	Target Target
+	// QueueSize bounds the records waiting for a slow or unreachable
+	// target.
+	QueueSize int
+}
+
+type Target struct {
+	Kind string
+	// Address is the file path or the host:port of the syslog collector.
+	Address        string
+	FileMaxBytes   int64
+	FileMaxBackups int
+}
+
+func (t Target) String() string {
+	return t.Kind + ":" + t.Address
+}
+
+// ParseTarget parses "file:<path>" or "syslog:<host:port>".
+func ParseTarget(spec string) (Target, error) {
+	kind, address, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	address = strings.TrimSpace(address)
+	if address == "" || (kind != TargetFile && kind != TargetSyslog) {
+		return Target{}, fmt.Errorf("%w: %q", ErrInvalidTarget, spec)
+	}
+	return Target{
+		Kind:           kind,
+		Address:        address,
+		FileMaxBytes:   logsink.DefaultFileMaxMB * 1024 * 1024,
+		FileMaxBackups: logsink.DefaultFileMaxBackups,
+	}, nil
+}
+
+// ParseFormat accepts jsonl or cef; an empty format is JSON Lines.
+func ParseFormat(raw string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(raw)); format {
+	case "":
+		return FormatJSONLines, nil
+	case FormatJSONLines, FormatCEF:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidFormat, raw)
+	}
+}
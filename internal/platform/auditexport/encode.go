@@ -0,0 +1,125 @@
+package auditexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cefVendor  = "Ardents"
+	cefProduct = "aim-chat"
+	cefVersion = "1"
+
+	syslogAppName = "aim-chat"
+	// syslogFacility is "log audit" in RFC 5424.
+	syslogFacility = 13
+)
+
+// encodeJSONLine returns the record as one JSON line.
+func encodeJSONLine(rec Record) ([]byte, error) {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, '\n'), nil
+}
+
+// encodeCEF returns the record as one CEF line. The header carries the kind
+// as signature id; everything else goes to the extension as mapped by
+// FieldMappings.
+func encodeCEF(rec Record) ([]byte, error) {
+	name := rec.Kind
+	if rec.Message != "" {
+		name = rec.Message
+	}
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+escapeCEFExtension(value))
+		}
+	}
+	add("rt", strconv.FormatInt(rec.At.UnixMilli(), 10))
+	add("cat", rec.Source)
+	if rec.Seq > 0 {
+		add("externalId", strconv.FormatInt(rec.Seq, 10))
+	}
+	add("duid", rec.ContactID)
+	add("msg", rec.Message)
+	if details := joinDetails(rec.Details); details != "" {
+		add("cs1Label", "details")
+		add("cs1", details)
+	}
+	if rec.Hash != "" {
+		add("cs2Label", "hash")
+		add("cs2", rec.Hash)
+	}
+	if rec.PrevHash != "" {
+		add("cs3Label", "prev_hash")
+		add("cs3", rec.PrevHash)
+	}
+	line := fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s\n",
+		cefVendor, cefProduct, cefVersion,
+		escapeCEFHeader(rec.Kind), escapeCEFHeader(name), cefSeverity(rec), strings.Join(ext, " "))
+	return []byte(line), nil
+}
+
+// frameSyslog wraps an encoded line in an RFC 5424 header for TCP syslog,
+// one message per line as collectors accept without octet counting.
+func frameSyslog(rec Record, hostname string, line []byte) []byte {
+	severity := 5 // notice
+	if rec.Source != SourceAudit {
+		severity = 4 // warning
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s - %s - ",
+		syslogFacility*8+severity, rec.At.UTC().Format(time.RFC3339Nano), hostname, syslogAppName, rec.Source)
+	return append([]byte(header), line...)
+}
+
+// cefSeverity ranks alerts above audit entries, which record changes the
+// user made on purpose.
+func cefSeverity(rec Record) int {
+	switch rec.Source {
+	case SourceSecurityAlert:
+		return 7
+	case SourceExport:
+		return 5
+	default:
+		return 3
+	}
+}
+
+func joinDetails(details map[string]string) string {
+	if len(details) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+details[key])
+	}
+	return strings.Join(pairs, ";")
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func escapeCEFHeader(value string) string {
+	return cefHeaderEscaper.Replace(value)
+}
+
+func escapeCEFExtension(value string) string {
+	return cefExtensionEscaper.Replace(value)
+}
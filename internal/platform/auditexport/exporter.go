@@ -0,0 +1,222 @@
+package auditexport
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/platform/logsink"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	retryBackoffBase = 250 * time.Millisecond
+	retryBackoffMax  = 5 * time.Second
+)
+
+// Exporter writes published records to the target from its own goroutine.
+// A nil Exporter is a disabled export.
+type Exporter struct {
+	cfg      Config
+	writer   io.Writer
+	closer   io.Closer
+	hostname string
+	queue    chan Record
+	stop     chan struct{}
+	done     chan struct{}
+	now      func() time.Time
+
+	mu          sync.Mutex
+	closed      bool
+	exported    int64
+	dropped     int64
+	unannounced int64
+	writeErrors int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// New opens the target and starts exporting.
+func New(cfg Config) (*Exporter, error) {
+	format, err := ParseFormat(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Format = format
+	var w io.WriteCloser
+	switch cfg.Target.Kind {
+	case TargetFile:
+		if w, err = logsink.OpenRotatingFile(cfg.Target.Address, cfg.Target.FileMaxBytes, cfg.Target.FileMaxBackups); err != nil {
+			return nil, err
+		}
+	case TargetSyslog:
+		w = logsink.NewTCPWriter(cfg.Target.Address)
+	default:
+		return nil, ErrInvalidTarget
+	}
+	return newExporter(cfg, w, w), nil
+}
+
+func newExporter(cfg Config, w io.Writer, closer io.Closer) *Exporter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	hostname, _ := os.Hostname()
+	e := &Exporter{
+		cfg:      cfg,
+		writer:   w,
+		closer:   closer,
+		hostname: hostname,
+		queue:    make(chan Record, cfg.QueueSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+	go e.run()
+	return e
+}
+
+// Publish queues rec without blocking. When the queue is full the record is
+// dropped and counted.
+func (e *Exporter) Publish(rec Record) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- rec:
+	default:
+		e.dropped++
+		e.unannounced++
+	}
+}
+
+// Close stops the export. Queued records get one more write attempt each.
+func (e *Exporter) Close() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	close(e.stop)
+	close(e.queue)
+	e.mu.Unlock()
+	<-e.done
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
+
+func (e *Exporter) Status() models.AuditExportStatus {
+	if e == nil {
+		return models.AuditExportStatus{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return models.AuditExportStatus{
+		Enabled:     true,
+		Format:      e.cfg.Format,
+		Target:      e.cfg.Target.String(),
+		Queued:      len(e.queue),
+		Exported:    e.exported,
+		Dropped:     e.dropped,
+		WriteErrors: e.writeErrors,
+		LastError:   e.lastError,
+		LastErrorAt: e.lastErrorAt,
+	}
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	for rec := range e.queue {
+		if dropped := e.takeUnannounced(); dropped > 0 {
+			e.deliver(Record{
+				Source:  SourceExport,
+				At:      e.now(),
+				Kind:    KindDropped,
+				Details: map[string]string{"dropped": strconv.FormatInt(dropped, 10)},
+			})
+		}
+		e.deliver(rec)
+	}
+}
+
+// deliver writes rec, retrying with backoff while the target fails. The
+// queue absorbs what is published meanwhile. Once the exporter is closing a
+// failed record is dropped.
+func (e *Exporter) deliver(rec Record) {
+	line, err := e.encode(rec)
+	if err != nil {
+		e.recordFailure(err, true)
+		return
+	}
+	backoff := retryBackoffBase
+	for {
+		_, err := e.writer.Write(line)
+		if err == nil {
+			e.mu.Lock()
+			e.exported++
+			e.mu.Unlock()
+			return
+		}
+		select {
+		case <-e.stop:
+			e.recordFailure(err, true)
+			return
+		default:
+		}
+		e.recordFailure(err, false)
+		select {
+		case <-e.stop:
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, retryBackoffMax)
+	}
+}
+
+func (e *Exporter) encode(rec Record) ([]byte, error) {
+	var (
+		line []byte
+		err  error
+	)
+	if e.cfg.Format == FormatCEF {
+		line, err = encodeCEF(rec)
+	} else {
+		line, err = encodeJSONLine(rec)
+	}
+	if err != nil || e.cfg.Target.Kind != TargetSyslog {
+		return line, err
+	}
+	return frameSyslog(rec, e.hostname, line), nil
+}
+
+func (e *Exporter) recordFailure(err error, dropped bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.writeErrors++
+	e.lastError = err.Error()
+	e.lastErrorAt = e.now()
+	if dropped {
+		e.dropped++
+		e.unannounced++
+	}
+}
+
+func (e *Exporter) takeUnannounced() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := e.unannounced
+	e.unannounced = 0
+	return n
+}
@@ -0,0 +1,88 @@
+package auditexport
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// FieldMapping says where a field of a typed event ends up in each format.
+type FieldMapping struct {
+	Source string
+	// Field is the JSON name of the field in the typed event.
+	Field   string
+	JSONKey string
+	CEFKey  string
+}
+
+// exportedEvents are the typed events the export carries, by source.
+var exportedEvents = []struct {
+	source string
+	event  any
+}{
+	{SourceAudit, models.AuditEvent{}},
+	{SourceSecurityAlert, events.SecurityAlert{}},
+}
+
+// fieldTargets maps the JSON name of each typed event field to its JSON
+// Lines key and CEF key.
+var fieldTargets = map[string]map[string][2]string{
+	SourceAudit: {
+		"seq":       {"seq", "externalId"},
+		"at":        {"at", "rt"},
+		"kind":      {"kind", "signatureId"},
+		"details":   {"details", "cs1"},
+		"prev_hash": {"prev_hash", "cs3"},
+		"hash":      {"hash", "cs2"},
+	},
+	SourceSecurityAlert: {
+		"contact_id":   {"contact_id", "duid"},
+		"kind":         {"kind", "signatureId"},
+		"message":      {"message", "msg"},
+		"message_args": {"details", "cs1"},
+	},
+}
+
+// FieldMappings lists every field of the exported typed events in
+// declaration order. A field without a mapping has empty keys.
+func FieldMappings() []FieldMapping {
+	var out []FieldMapping
+	for _, exported := range exportedEvents {
+		typ := reflect.TypeOf(exported.event)
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			target := fieldTargets[exported.source][name]
+			out = append(out, FieldMapping{Source: exported.source, Field: name, JSONKey: target[0], CEFKey: target[1]})
+		}
+	}
+	return out
+}
+
+// FieldMappingDoc renders FieldMappings and the fixed fields of each format
+// as Markdown.
+func FieldMappingDoc() string {
+	var b strings.Builder
+	b.WriteString("# Audit export field mapping\n\n")
+	b.WriteString("Generated from the typed events by `go run ./cmd/audit-export-fields`.\n\n")
+	b.WriteString("| Source | Event field | JSON Lines key | CEF key |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, m := range FieldMappings() {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", m.Source, m.Field, m.JSONKey, m.CEFKey)
+	}
+	b.WriteString("\nEvery record also carries:\n\n")
+	b.WriteString("- `source` (CEF `cat`): `audit`, `security_alert` or `export`.\n")
+	b.WriteString("- `at` (CEF `rt`, epoch milliseconds): for security alerts, when the daemon published the alert.\n")
+	b.WriteString("\nCEF details are `key=value` pairs joined by `;`, labelled `details` by `cs1Label`; ")
+	b.WriteString("`cs2Label` and `cs3Label` name the hash fields. ")
+	b.WriteString("The CEF name is the alert message, or the kind for audit entries. ")
+	fmt.Fprintf(&b, "Severity is %d for security alerts, %d for export notices and %d for audit entries.\n",
+		cefSeverity(Record{Source: SourceSecurityAlert}), cefSeverity(Record{Source: SourceExport}), cefSeverity(Record{Source: SourceAudit}))
+	fmt.Fprintf(&b, "\nAn `%s` record with a `dropped` detail precedes the first record written after the queue overflowed.\n", KindDropped)
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package auditexport
+
+import (
+	"time"
+
+	"aim-chat/go-backend/pkg/events"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// Record sources.
+const (
+	SourceAudit         = "audit"
+	SourceSecurityAlert = "security_alert"
+	SourceExport        = "export"
+)
+
+// KindDropped is the kind of the record announcing how many records the
+// export dropped while its queue was full.
+const KindDropped = "export.dropped"
+
+// Record is one exported event. Which fields are set depends on Source; see
+// FieldMappings for where each typed event field ends up.
+type Record struct {
+	Source    string            `json:"source"`
+	Seq       int64             `json:"seq,omitempty"`
+	At        time.Time         `json:"at"`
+	Kind      string            `json:"kind"`
+	ContactID string            `json:"contact_id,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	PrevHash  string            `json:"prev_hash,omitempty"`
+	Hash      string            `json:"hash,omitempty"`
+}
+
+func FromAuditEvent(evt models.AuditEvent) Record {
+	return Record{
+		Source:   SourceAudit,
+		Seq:      evt.Seq,
+		At:       evt.At.UTC(),
+		Kind:     evt.Kind,
+		Details:  evt.Details,
+		PrevHash: evt.PrevHash,
+		Hash:     evt.Hash,
+	}
+}
+
+// FromSecurityAlert exports an alert published at the given time; the
+// notification itself carries no timestamp.
+func FromSecurityAlert(at time.Time, alert events.SecurityAlert) Record {
+	return Record{
+		Source:    SourceSecurityAlert,
+		At:        at.UTC(),
+		Kind:      alert.Kind,
+		ContactID: alert.ContactID,
+		Message:   alert.Message,
+		Details:   alert.MessageArgs,
+	}
+}
@@ -0,0 +1,166 @@
+// Package contentsafety flags risky inbound content so clients can warn before
+// the user follows a link, opens an attachment or trusts a display name.
+//
+// Flags are advisory: content is always delivered, and the checks run locally
+// without contacting any service.
+package contentsafety
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	FlagMaliciousLink        = "malicious_link"
+	FlagExecutableAttachment = "executable_attachment"
+	FlagSpoofedName          = "spoofed_name"
+)
+
+// Details reported with FlagSpoofedName.
+const (
+	SpoofInvisibleChars = "invisible_or_bidi_chars"
+	SpoofMixedScripts   = "mixed_scripts"
+	SpoofCombiningMarks = "excessive_combining_marks"
+)
+
+// maxCombiningRun is the longest run of combining marks a legitimate name
+// needs; longer runs are used to overdraw neighbouring characters.
+const maxCombiningRun = 2
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'` + "`" + `]+`)
+
+var executableExtensions = map[string]struct{}{
+	".apk": {}, ".app": {}, ".bat": {}, ".cmd": {}, ".com": {}, ".cpl": {},
+	".dll": {}, ".dmg": {}, ".exe": {}, ".hta": {}, ".jar": {}, ".js": {},
+	".jse": {}, ".lnk": {}, ".msi": {}, ".msp": {}, ".pif": {}, ".ps1": {},
+	".scr": {}, ".sh": {}, ".vbe": {}, ".vbs": {}, ".wsf": {},
+}
+
+var executableMimeTypes = map[string]struct{}{
+	"application/vnd.android.package-archive": {},
+	"application/java-archive":                {},
+	"application/x-apple-diskimage":           {},
+	"application/x-bat":                       {},
+	"application/x-dosexec":                   {},
+	"application/x-executable":                {},
+	"application/x-msdos-program":             {},
+	"application/x-msdownload":                {},
+	"application/x-msi":                       {},
+	"application/x-sh":                        {},
+}
+
+// CheckAttachments flags attachments that would run code when opened, judged
+// by file extension or declared MIME type.
+func CheckAttachments(attachments []models.MessageAttachment) []models.SafetyFlag {
+	var flags []models.SafetyFlag
+	for _, att := range attachments {
+		ext := strings.ToLower(path.Ext(strings.TrimSpace(att.Name)))
+		mime, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(att.MimeType)), ";")
+		_, badExt := executableExtensions[ext]
+		_, badMime := executableMimeTypes[strings.TrimSpace(mime)]
+		if badExt || badMime {
+			flags = append(flags, models.SafetyFlag{Kind: FlagExecutableAttachment, Detail: att.ID})
+		}
+	}
+	return flags
+}
+
+// CheckDisplayName flags names built to impersonate someone: hidden or
+// direction-changing characters, words mixing look-alike scripts, and stacks
+// of combining marks.
+func CheckDisplayName(name string) []models.SafetyFlag {
+	var details []string
+	if hasInvisibleChars(name) {
+		details = append(details, SpoofInvisibleChars)
+	}
+	if hasMixedScriptWord(name) {
+		details = append(details, SpoofMixedScripts)
+	}
+	if hasCombiningRun(name) {
+		details = append(details, SpoofCombiningMarks)
+	}
+	flags := make([]models.SafetyFlag, 0, len(details))
+	for _, detail := range details {
+		flags = append(flags, models.SafetyFlag{Kind: FlagSpoofedName, Detail: detail})
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+	return flags
+}
+
+// linkHosts returns the lower-cased hosts of links found in text.
+func linkHosts(text string) []string {
+	var hosts []string
+	for _, raw := range linkPattern.FindAllString(text, -1) {
+		raw = strings.TrimRight(raw, ".,;:!?)]}")
+		if strings.HasPrefix(strings.ToLower(raw), "www.") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if host := normalizeDomain(u.Hostname()); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func hasInvisibleChars(name string) bool {
+	for _, r := range name {
+		switch {
+		case r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+			return true
+		case r >= 0x200B && r <= 0x200F, r == 0x2060, r == 0xFEFF, r == 0x061C:
+			return true
+		}
+	}
+	return false
+}
+
+// confusableScripts are scripts with many letters that look like Latin ones.
+var confusableScripts = []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic, unicode.Greek}
+
+func hasMixedScriptWord(name string) bool {
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsMark(r) }) {
+		seen := 0
+		for _, table := range confusableScripts {
+			for _, r := range word {
+				if unicode.Is(table, r) {
+					seen++
+					break
+				}
+			}
+		}
+		if seen > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCombiningRun(name string) bool {
+	run := 0
+	for _, r := range name {
+		if unicode.Is(unicode.Mn, r) {
+			run++
+			if run > maxCombiningRun {
+				return true
+			}
+			continue
+		}
+		run = 0
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package contentsafety
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func signedFeed(t *testing.T, prv ed25519.PrivateKey, version int64, domains ...string) Feed {
+	t.Helper()
+	feed := Feed{
+		Version:  version,
+		IssuedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		KeyID:    "feed-k1",
+		Domains:  domains,
+	}
+	feed.Signature = ed25519.Sign(prv, feed.SigningPayload())
+	return feed
+}
+
+func feedKeys(t *testing.T) (map[string]ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return map[string]ed25519.PublicKey{"feed-k1": pub}, prv
+}
+
+func TestCheckerFlagsFeedDomainsAndSubdomains(t *testing.T) {
+	keys, prv := feedKeys(t)
+	checker := NewChecker("")
+	if _, err := checker.Apply(signedFeed(t, prv, 1, "Evil.example."), keys, time.Now()); err != nil {
+		t.Fatalf("apply feed: %v", err)
+	}
+	flags := checker.CheckText("see https://login.evil.example/x, www.evil.example and https://notevil.example")
+	if len(flags) != 1 || flags[0] != (models.SafetyFlag{Kind: FlagMaliciousLink, Detail: "evil.example"}) {
+		t.Fatalf("unexpected flags: %+v", flags)
+	}
+	if flags := checker.CheckText("no links here"); flags != nil {
+		t.Fatalf("expected no flags, got %+v", flags)
+	}
+}
+
+func TestCheckerRejectsTamperedAndReplayedFeeds(t *testing.T) {
+	keys, prv := feedKeys(t)
+	path := filepath.Join(t.TempDir(), "feed.json")
+	checker := NewChecker(path)
+
+	tampered := signedFeed(t, prv, 1, "evil.example")
+	tampered.Domains = nil
+	if _, err := checker.Apply(tampered, keys, time.Now()); !errors.Is(err, ErrFeedSignatureInvalid) {
+		t.Fatalf("expected ErrFeedSignatureInvalid, got %v", err)
+	}
+	if _, err := checker.Apply(signedFeed(t, prv, 2, "evil.example"), nil, time.Now()); !errors.Is(err, ErrFeedKeysNotConfigured) {
+		t.Fatalf("expected ErrFeedKeysNotConfigured, got %v", err)
+	}
+	if _, err := checker.Apply(signedFeed(t, prv, 2, "evil.example"), keys, time.Now()); err != nil {
+		t.Fatalf("apply feed: %v", err)
+	}
+	if _, err := checker.Apply(signedFeed(t, prv, 1, "other.example"), keys, time.Now()); !errors.Is(err, ErrFeedOutdated) {
+		t.Fatalf("expected ErrFeedOutdated, got %v", err)
+	}
+
+	reloaded := NewChecker(path)
+	if err := reloaded.Bootstrap(keys); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if status := reloaded.Status(); status.Version != 2 || status.DomainCount != 1 {
+		t.Fatalf("unexpected status after reload: %+v", status)
+	}
+}
+
+func TestCheckAttachmentsFlagsExecutables(t *testing.T) {
+	flags := CheckAttachments([]models.MessageAttachment{
+		{ID: "a1", Name: "photo.jpg", MimeType: "image/jpeg"},
+		{ID: "a2", Name: "invoice.pdf.EXE", MimeType: "application/pdf"},
+		{ID: "a3", Name: "setup", MimeType: "application/x-msdownload; charset=binary"},
+	})
+	if len(flags) != 2 || flags[0].Detail != "a2" || flags[1].Detail != "a3" {
+		t.Fatalf("unexpected flags: %+v", flags)
+	}
+}
+
+func TestCheckDisplayName(t *testing.T) {
+	cases := []struct {
+		name   string
+		detail string
+	}{
+		{"Alice Smith", ""},
+		{"Алиса", ""},
+		{"Zoë", ""},
+		{"PаyPal", SpoofMixedScripts},
+		{"Alice‮gnp.exe", SpoofInvisibleChars},
+		{"Bó̂̃b", SpoofCombiningMarks},
+	}
+	for _, tc := range cases {
+		flags := CheckDisplayName(tc.name)
+		if tc.detail == "" {
+			if flags != nil {
+				t.Fatalf("%q: expected no flags, got %+v", tc.name, flags)
+			}
+			continue
+		}
+		if len(flags) != 1 || flags[0] != (models.SafetyFlag{Kind: FlagSpoofedName, Detail: tc.detail}) {
+			t.Fatalf("%q: unexpected flags %+v", tc.name, flags)
+		}
+	}
+}
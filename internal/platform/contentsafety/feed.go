@@ -0,0 +1,211 @@
+package contentsafety
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	ErrFeedKeysNotConfigured = errors.New("safety feed keys are not configured")
+	ErrFeedKeyUnknown        = errors.New("safety feed key unknown")
+	ErrFeedSignatureInvalid  = errors.New("safety feed signature invalid")
+	ErrFeedInvalid           = errors.New("safety feed invalid")
+	ErrFeedOutdated          = errors.New("safety feed is not newer than the applied one")
+)
+
+// Feed is a signed list of known-bad link domains. Publishers bump Version
+// with every update so an old feed cannot be replayed over a newer one.
+type Feed struct {
+	Version   int64     `json:"version"`
+	IssuedAt  time.Time `json:"issued_at"`
+	KeyID     string    `json:"key_id"`
+	Domains   []string  `json:"domains"`
+	Signature []byte    `json:"signature"`
+}
+
+// SigningPayload is the byte string the feed signature covers.
+func (f Feed) SigningPayload() []byte {
+	payload, _ := json.Marshal(struct {
+		Version  int64     `json:"version"`
+		IssuedAt time.Time `json:"issued_at"`
+		KeyID    string    `json:"key_id"`
+		Domains  []string  `json:"domains"`
+	}{f.Version, f.IssuedAt.UTC(), f.KeyID, f.Domains})
+	return payload
+}
+
+// Verify checks the feed shape and its signature against the trusted keys.
+func (f Feed) Verify(keys map[string]ed25519.PublicKey) error {
+	if len(keys) == 0 {
+		return ErrFeedKeysNotConfigured
+	}
+	if f.Version <= 0 || f.IssuedAt.IsZero() {
+		return fmt.Errorf("%w: version and issued_at are required", ErrFeedInvalid)
+	}
+	key, ok := keys[strings.TrimSpace(f.KeyID)]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrFeedKeyUnknown, f.KeyID)
+	}
+	if !ed25519.Verify(key, f.SigningPayload(), f.Signature) {
+		return ErrFeedSignatureInvalid
+	}
+	return nil
+}
+
+// Checker holds the applied feed and flags inbound content against it. The
+// zero feed flags no links; attachment and name checks need no feed.
+type Checker struct {
+	mu        sync.RWMutex
+	path      string
+	feed      Feed
+	domains   map[string]struct{}
+	appliedAt time.Time
+}
+
+// NewChecker returns a checker persisting applied feeds at path. An empty
+// path keeps feeds in memory only.
+func NewChecker(path string) *Checker {
+	return &Checker{path: strings.TrimSpace(path), domains: map[string]struct{}{}}
+}
+
+// Bootstrap loads the last applied feed. It was verified when applied, and
+// the file is re-verified in case it was replaced on disk.
+func (c *Checker) Bootstrap(keys map[string]ed25519.PublicKey) error {
+	if c.path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var stored struct {
+		Feed      Feed      `json:"feed"`
+		AppliedAt time.Time `json:"applied_at"`
+	}
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return err
+	}
+	if err := stored.Feed.Verify(keys); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.setFeedLocked(stored.Feed, stored.AppliedAt)
+	c.mu.Unlock()
+	return nil
+}
+
+// Apply verifies feed and makes it the active list when it is newer than the
+// current one.
+func (c *Checker) Apply(feed Feed, keys map[string]ed25519.PublicKey, now time.Time) (models.SafetyFeedStatus, error) {
+	if err := feed.Verify(keys); err != nil {
+		return models.SafetyFeedStatus{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if feed.Version <= c.feed.Version {
+		return models.SafetyFeedStatus{}, fmt.Errorf("%w: have version %d", ErrFeedOutdated, c.feed.Version)
+	}
+	if c.path != "" {
+		raw, err := json.Marshal(map[string]any{"feed": feed, "applied_at": now.UTC()})
+		if err != nil {
+			return models.SafetyFeedStatus{}, err
+		}
+		if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+			return models.SafetyFeedStatus{}, err
+		}
+		if err := os.WriteFile(c.path, raw, 0o600); err != nil {
+			return models.SafetyFeedStatus{}, err
+		}
+	}
+	c.setFeedLocked(feed, now.UTC())
+	return c.statusLocked(), nil
+}
+
+// Status describes the active feed.
+func (c *Checker) Status() models.SafetyFeedStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.statusLocked()
+}
+
+// CheckText flags links in text whose domain, or any parent domain, is on
+// the feed.
+func (c *Checker) CheckText(text string) []models.SafetyFlag {
+	hosts := linkHosts(text)
+	if len(hosts) == 0 {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var flags []models.SafetyFlag
+	seen := map[string]struct{}{}
+	for _, host := range hosts {
+		domain, bad := c.matchDomainLocked(host)
+		if !bad {
+			continue
+		}
+		if _, dup := seen[domain]; dup {
+			continue
+		}
+		seen[domain] = struct{}{}
+		flags = append(flags, models.SafetyFlag{Kind: FlagMaliciousLink, Detail: domain})
+	}
+	return flags
+}
+
+// CheckMessage combines the link and attachment checks for one message.
+func (c *Checker) CheckMessage(content []byte, contentType string, attachments []models.MessageAttachment) []models.SafetyFlag {
+	var flags []models.SafetyFlag
+	if contentType == "text" {
+		flags = append(flags, c.CheckText(string(content))...)
+	}
+	return append(flags, CheckAttachments(attachments)...)
+}
+
+func (c *Checker) matchDomainLocked(host string) (string, bool) {
+	for candidate := host; candidate != ""; {
+		if _, ok := c.domains[candidate]; ok {
+			return candidate, true
+		}
+		_, parent, found := strings.Cut(candidate, ".")
+		if !found {
+			break
+		}
+		candidate = parent
+	}
+	return "", false
+}
+
+func (c *Checker) setFeedLocked(feed Feed, appliedAt time.Time) {
+	domains := make(map[string]struct{}, len(feed.Domains))
+	for _, domain := range feed.Domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			domains[domain] = struct{}{}
+		}
+	}
+	c.feed = feed
+	c.domains = domains
+	c.appliedAt = appliedAt
+}
+
+func (c *Checker) statusLocked() models.SafetyFeedStatus {
+	return models.SafetyFeedStatus{
+		Version:     c.feed.Version,
+		IssuedAt:    c.feed.IssuedAt,
+		KeyID:       c.feed.KeyID,
+		DomainCount: len(c.domains),
+		AppliedAt:   c.appliedAt,
+	}
+}
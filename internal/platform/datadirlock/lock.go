@@ -0,0 +1,298 @@
+// Package datadirlock keeps two daemon processes from using the same data
+// dir at once. The lock is advisory: a JSON file naming the owner process,
+// refreshed by a heartbeat so a crashed owner can be told from a live one.
+package datadirlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	FileName          = "daemon.lock"
+	HeartbeatInterval = 5 * time.Second
+	StaleAfter        = 30 * time.Second
+
+	claimAttempts = 3
+	claimRetry    = 10 * time.Millisecond
+	waitRetry     = 250 * time.Millisecond
+)
+
+var ErrLocked = errors.New("data dir is locked by another daemon process")
+
+// Owner identifies the process holding the lock.
+type Owner struct {
+	PID         int       `json:"pid"`
+	Hostname    string    `json:"hostname"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+// Status describes the lock file of a data dir. A stale lock was left by a
+// process that exited or stopped sending heartbeats.
+type Status struct {
+	Held  bool   `json:"held"`
+	Stale bool   `json:"stale"`
+	Owner *Owner `json:"owner,omitempty"`
+}
+
+// Lock is a held data dir lock.
+type Lock struct {
+	path  string
+	owner Owner
+	once  sync.Once
+	stop  chan struct{}
+	done  chan struct{}
+	lost  chan struct{}
+}
+
+// Acquire takes the lock of dir. A stale lock is replaced; a live one is only
+// taken over with force, in which case the previous owner notices on its
+// next heartbeat and reports the lock as lost.
+func Acquire(dir string, force bool) (*Lock, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now().UTC()
+	l := &Lock{
+		path:  filepath.Join(dir, FileName),
+		owner: Owner{PID: os.Getpid(), Hostname: hostname, StartedAt: now, HeartbeatAt: now},
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := l.create()
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			go l.heartbeat()
+			return l, nil
+		}
+		status, err := Inspect(dir, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		if status.Held && !status.Stale && !force {
+			return nil, fmt.Errorf("%w: pid %d on %q, last heartbeat %s",
+				ErrLocked, status.Owner.PID, status.Owner.Hostname, status.Owner.HeartbeatAt.Format(time.RFC3339))
+		}
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: lock file keeps reappearing", ErrLocked)
+}
+
+// AcquireWithin takes the lock of dir like Acquire without force, retrying
+// while a live owner holds it until ctx ends or wait elapsed. A successor
+// uses it when the previous owner is still letting go, or died while doing
+// so and leaves a lock that has yet to go stale.
+func AcquireWithin(ctx context.Context, dir string, wait time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		l, err := Acquire(dir, false)
+		if err == nil || !errors.Is(err, ErrLocked) || !time.Now().Add(waitRetry).Before(deadline) {
+			return l, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (%v)", err, ctx.Err())
+		case <-time.After(waitRetry):
+		}
+	}
+}
+
+// Inspect reads the lock file of dir without taking it.
+func Inspect(dir string, now time.Time) (Status, error) {
+	owner, err := readOwner(filepath.Join(dir, FileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Status{}, nil
+		}
+		return Status{}, err
+	}
+	if owner == nil {
+		// An unreadable lock file cannot belong to a working owner.
+		return Status{Held: true, Stale: true}, nil
+	}
+	return Status{Held: true, Stale: isStale(*owner, now), Owner: owner}, nil
+}
+
+// Lost is closed when another process took the lock over.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops the heartbeat and removes the lock file if it is still ours.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		owner, claimErr := l.claim()
+		if claimErr != nil {
+			if !errors.Is(claimErr, fs.ErrNotExist) {
+				err = claimErr
+			}
+			return
+		}
+		if !l.owns(owner) {
+			l.restore(l.claimPath())
+			return
+		}
+		if rmErr := os.Remove(l.claimPath()); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+			err = rmErr
+		}
+	})
+	return err
+}
+
+func (l *Lock) create() (bool, error) {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	raw, err := json.Marshal(l.owner)
+	if err == nil {
+		_, err = f.Write(raw)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(l.path)
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *Lock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+		if !l.refresh() {
+			close(l.lost)
+			return
+		}
+	}
+}
+
+// refresh renews the heartbeat while the lock is still ours and reports
+// whether it is. The lock file is moved aside before it is checked, so a
+// takeover cannot slip in between the check and the write: a successor's
+// file is put back untouched, and one created after ours was moved aside
+// makes putting ours back fail.
+func (l *Lock) refresh() bool {
+	owner, err := l.claim()
+	if err != nil {
+		return false
+	}
+	if !l.owns(owner) {
+		l.restore(l.claimPath())
+		return false
+	}
+	next := l.owner
+	next.HeartbeatAt = time.Now().UTC()
+	raw, err := json.Marshal(next)
+	if err == nil {
+		err = os.WriteFile(l.nextPath(), raw, 0o600)
+	}
+	if err != nil {
+		_ = os.Remove(l.nextPath())
+		return l.restore(l.claimPath())
+	}
+	_ = os.Remove(l.claimPath())
+	if !l.restore(l.nextPath()) {
+		return false
+	}
+	l.owner = next
+	return true
+}
+
+// claim moves the lock file aside and returns the owner it names. A missing
+// file is retried briefly, since another owner may be checking it as well.
+func (l *Lock) claim() (*Owner, error) {
+	var err error
+	for attempt := 0; attempt < claimAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(claimRetry)
+		}
+		if err = os.Rename(l.path, l.claimPath()); !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readOwner(l.claimPath())
+}
+
+// restore moves a file into place as the lock file unless another process
+// created one meanwhile, and reports whether it did.
+func (l *Lock) restore(from string) bool {
+	err := os.Link(from, l.path)
+	_ = os.Remove(from)
+	return err == nil
+}
+
+func (l *Lock) stillOwned() bool {
+	owner, err := readOwner(l.path)
+	return err == nil && l.owns(owner)
+}
+
+func (l *Lock) owns(owner *Owner) bool {
+	return owner != nil && owner.PID == l.owner.PID && owner.StartedAt.Equal(l.owner.StartedAt)
+}
+
+// claimPath and nextPath are private to this lock, so concurrent owners
+// never touch each other's files.
+func (l *Lock) claimPath() string {
+	return fmt.Sprintf("%s.%d-%d.claim", l.path, l.owner.PID, l.owner.StartedAt.UnixNano())
+}
+
+func (l *Lock) nextPath() string {
+	return fmt.Sprintf("%s.%d-%d.next", l.path, l.owner.PID, l.owner.StartedAt.UnixNano())
+}
+
+// readOwner returns nil without an error for a lock file that is not valid
+// JSON, e.g. one torn by a crash while it was written.
+func readOwner(path string) (*Owner, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var owner Owner
+	if err := json.Unmarshal(raw, &owner); err != nil || owner.PID <= 0 {
+		return nil, nil
+	}
+	return &owner, nil
+}
+
+func isStale(owner Owner, now time.Time) bool {
+	if hostname, _ := os.Hostname(); hostname != "" && hostname == owner.Hostname && !processAlive(owner.PID) {
+		return true
+	}
+	return now.Sub(owner.HeartbeatAt) > StaleAfter
+}
@@ -0,0 +1,154 @@
+package datadirlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestOwner(t *testing.T, dir string, owner Owner) {
+	t.Helper()
+	raw, err := json.Marshal(owner)
+	if err != nil {
+		t.Fatalf("marshal owner: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), raw, 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+}
+
+func TestAcquireRefusesLiveLockUnlessForced(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := Acquire(dir, false); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	status, err := Inspect(dir, time.Now().UTC())
+	if err != nil || !status.Held || status.Stale || status.Owner == nil || status.Owner.PID != os.Getpid() {
+		t.Fatalf("unexpected status: %+v err=%v", status, err)
+	}
+
+	second, err := Acquire(dir, true)
+	if err != nil {
+		t.Fatalf("force takeover: %v", err)
+	}
+	if first.stillOwned() {
+		t.Fatal("previous owner must notice the takeover")
+	}
+	if err := first.Release(); err != nil {
+		t.Fatalf("release previous owner: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("previous owner must not remove the new lock: %v", err)
+	}
+	if err := second.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Fatalf("lock file must be removed on release, stat err=%v", err)
+	}
+}
+
+func TestAcquireReplacesStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().UTC().Add(-time.Hour)
+	writeTestOwner(t, dir, Owner{PID: 4242, Hostname: "other-host", StartedAt: old, HeartbeatAt: old})
+
+	status, err := Inspect(dir, time.Now().UTC())
+	if err != nil || !status.Held || !status.Stale {
+		t.Fatalf("expected stale lock, got %+v err=%v", status, err)
+	}
+	lock, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("acquire over stale lock: %v", err)
+	}
+	defer func() { _ = lock.Release() }()
+
+	// A fresh heartbeat from another host cannot be checked by PID and
+	// counts as live.
+	other := t.TempDir()
+	fresh := time.Now().UTC()
+	writeTestOwner(t, other, Owner{PID: 1, Hostname: "other-host", StartedAt: fresh, HeartbeatAt: fresh})
+	if _, err := Acquire(other, false); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked for a fresh remote owner, got %v", err)
+	}
+}
+
+func TestAcquireWithinWaitsForTheOwnerToLetGo(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := AcquireWithin(context.Background(), dir, 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked without a wait, got %v", err)
+	}
+	go func() {
+		time.Sleep(2 * waitRetry)
+		_ = first.Release()
+	}()
+	second, err := AcquireWithin(context.Background(), dir, time.Minute)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	defer func() { _ = second.Release() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AcquireWithin(ctx, dir, time.Minute); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked once ctx ended, got %v", err)
+	}
+}
+
+func TestInspectTreatsTornLockAsStale(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	status, err := Inspect(dir, time.Now().UTC())
+	if err != nil || !status.Held || !status.Stale || status.Owner != nil {
+		t.Fatalf("unexpected status: %+v err=%v", status, err)
+	}
+	if status, err := Inspect(t.TempDir(), time.Now().UTC()); err != nil || status.Held {
+		t.Fatalf("missing lock must be reported free: %+v err=%v", status, err)
+	}
+}
+
+func TestHeartbeatNeverOverwritesASuccessor(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	before := first.owner.HeartbeatAt
+	time.Sleep(time.Millisecond)
+	if !first.refresh() {
+		t.Fatal("owner must keep its lock on refresh")
+	}
+	if status, _ := Inspect(dir, time.Now().UTC()); status.Owner == nil || !status.Owner.HeartbeatAt.After(before) {
+		t.Fatalf("expected a newer heartbeat, got %+v", status.Owner)
+	}
+
+	second, err := Acquire(dir, true)
+	if err != nil {
+		t.Fatalf("force takeover: %v", err)
+	}
+	defer func() { _ = second.Release() }()
+	if first.refresh() {
+		t.Fatal("refresh must report the lock as lost after a takeover")
+	}
+	if !second.stillOwned() {
+		t.Fatal("the successor's owner record must survive the old owner's heartbeat")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, FileName+".*")); len(matches) != 0 {
+		t.Fatalf("expected no leftover claim files, got %v", matches)
+	}
+	_ = first.Release()
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package datadirlock
+
+import "os"
+
+// os.FindProcess opens a handle on Windows and fails for exited processes.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
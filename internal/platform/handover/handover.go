@@ -0,0 +1,180 @@
+// Package handover lets a new daemon process take the RPC listener over from
+// the running one, so an upgrade refuses no connections.
+//
+// The running daemon offers a unix socket in its data dir. A successor
+// connects and says hello; the running daemon answers with a duplicate of its
+// listening socket, stops serving, flushes its state, releases the data dir
+// lock and finally reports "released". Connections arriving meanwhile wait in
+// the shared accept backlog until the successor serves them. The socket
+// itself is passed rather than a second one bound with SO_REUSEPORT, because
+// connections queued on a reuseport socket are reset when it closes.
+//
+// Every message is one JSON object per line.
+package handover
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	SocketName = "handover.sock"
+
+	protocolVersion = 1
+	helloTimeout    = 5 * time.Second
+)
+
+const (
+	msgHello    = "hello"
+	msgListener = "listener"
+	msgReleased = "released"
+	msgError    = "error"
+)
+
+var (
+	ErrUnsupported = errors.New("listener handover is not supported on this platform")
+	ErrRejected    = errors.New("handover rejected by the running daemon")
+)
+
+type message struct {
+	Type    string `json:"type"`
+	Version int    `json:"version,omitempty"`
+	PID     int    `json:"pid,omitempty"`
+	Addr    string `json:"addr,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Source is the handover socket of a running daemon.
+type Source struct {
+	ln       net.Listener
+	requests chan *Request
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Request is a successor asking for the listener. It must end with Complete
+// or Reject.
+type Request struct {
+	PID  int
+	conn net.Conn
+}
+
+// Takeover is the successor's side of a handover in progress.
+type Takeover struct {
+	// Listener is the inherited RPC listener. It keeps accepting into the
+	// same backlog the previous daemon served.
+	Listener net.Listener
+	Addr     string
+	conn     net.Conn
+	reader   *bufio.Reader
+}
+
+func newSource(ln net.Listener) *Source {
+	s := &Source{ln: ln, requests: make(chan *Request), done: make(chan struct{})}
+	go s.accept()
+	return s
+}
+
+// Requests delivers successors asking for the listener, one at a time.
+func (s *Source) Requests() <-chan *Request {
+	return s.requests
+}
+
+// Close stops offering handovers and removes the socket.
+func (s *Source) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.ln.Close()
+	})
+	return err
+}
+
+func (s *Source) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Source) handle(conn net.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	var hello message
+	if err := json.NewDecoder(conn).Decode(&hello); err != nil || hello.Type != msgHello || hello.Version != protocolVersion {
+		_ = writeMessage(conn, message{Type: msgError, Error: "unsupported handover request"})
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	req := &Request{PID: hello.PID, conn: conn}
+	select {
+	case s.requests <- req:
+	case <-s.done:
+		req.Reject(errors.New("daemon is stopping"))
+	}
+}
+
+// Complete tells the successor that this daemon stopped and released the
+// data dir, so it may open the stores.
+func (r *Request) Complete() error {
+	defer func() { _ = r.conn.Close() }()
+	return writeMessage(r.conn, message{Type: msgReleased})
+}
+
+// Reject tells the successor the handover will not happen.
+func (r *Request) Reject(err error) {
+	_ = writeMessage(r.conn, message{Type: msgError, Error: err.Error()})
+	_ = r.conn.Close()
+}
+
+// WaitReleased blocks until the previous daemon released the data dir.
+func (t *Takeover) WaitReleased(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() { _ = t.conn.SetReadDeadline(time.Now()) })
+	defer stop()
+	line, err := t.reader.ReadBytes('\n')
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, io.EOF) {
+			return errors.New("previous daemon closed the handover before releasing the data dir")
+		}
+		return err
+	}
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return err
+	}
+	switch msg.Type {
+	case msgReleased:
+		return nil
+	case msgError:
+		return fmt.Errorf("%w: %s", ErrRejected, msg.Error)
+	default:
+		return fmt.Errorf("unexpected handover message %q", msg.Type)
+	}
+}
+
+// Close ends the handover connection. The inherited listener stays open.
+func (t *Takeover) Close() error {
+	return t.conn.Close()
+}
+
+func writeMessage(conn net.Conn, msg message) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(raw, '\n'))
+	return err
+}
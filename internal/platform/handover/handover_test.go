@@ -0,0 +1,168 @@
+//go:build !windows
+
+package handover
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	*http.Server
+	mu   sync.Mutex
+	busy map[net.Conn]bool
+}
+
+func serveAs(t *testing.T, ln net.Listener, name string) *testServer {
+	t.Helper()
+	srv := &testServer{busy: map[net.Conn]bool{}}
+	srv.Server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, name)
+		}),
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if state == http.StateNew || state == http.StateActive {
+				srv.busy[conn] = true
+			} else {
+				delete(srv.busy, conn)
+			}
+		},
+	}
+	go func() { _ = srv.Serve(ln) }()
+	return srv
+}
+
+// drain stops accepting on this process's copy of ln and lets accepted
+// connections finish, the way a daemon hands over.
+func (s *testServer) drain(t *testing.T, ln net.Listener) {
+	t.Helper()
+	s.SetKeepAlivesEnabled(false)
+	_ = ln.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		busy := len(s.busy)
+		s.mu.Unlock()
+		if busy == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = s.Close()
+}
+
+func TestHandoverPassesListenerWithoutRefusingConnections(t *testing.T) {
+	dir := t.TempDir()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	oldServer := serveAs(t, ln, "old")
+	source, err := Listen(dir)
+	if err != nil {
+		t.Fatalf("offer handover: %v", err)
+	}
+
+	var (
+		failures atomic.Int64
+		served   sync.Map
+		stop     = make(chan struct{})
+		wg       sync.WaitGroup
+	)
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				resp, err := client.Get("http://" + addr + "/")
+				if err != nil {
+					failures.Add(1)
+					t.Logf("request failed: %v", err)
+					continue
+				}
+				body, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				served.Store(string(body), true)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan *Takeover, 1)
+	go func() {
+		takeover, err := Take(ctx, dir)
+		if err != nil {
+			t.Errorf("take listener: %v", err)
+		}
+		done <- takeover
+	}()
+
+	req := <-source.Requests()
+	if err := req.SendListener(ln); err != nil {
+		t.Fatalf("send listener: %v", err)
+	}
+	takeover := <-done
+	if takeover == nil {
+		t.FailNow()
+	}
+	if takeover.Addr != addr {
+		t.Fatalf("unexpected handed over address %q", takeover.Addr)
+	}
+	oldServer.drain(t, ln)
+	_ = source.Close()
+	if err := req.Complete(); err != nil {
+		t.Fatalf("complete handover: %v", err)
+	}
+	if err := takeover.WaitReleased(ctx); err != nil {
+		t.Fatalf("wait released: %v", err)
+	}
+	_ = takeover.Close()
+	newServer := serveAs(t, takeover.Listener, "new")
+	defer func() { _ = newServer.Close() }()
+
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	if n := failures.Load(); n != 0 {
+		t.Fatalf("%d requests failed during the handover", n)
+	}
+	if _, ok := served.Load("new"); !ok {
+		t.Fatal("the successor never served a request")
+	}
+}
+
+func TestHandoverRejectedWhenSourceCloses(t *testing.T) {
+	dir := t.TempDir()
+	source, err := Listen(dir)
+	if err != nil {
+		t.Fatalf("offer handover: %v", err)
+	}
+	go func() {
+		req := <-source.Requests()
+		req.Reject(errors.New("upgrade refused"))
+	}()
+	if _, err := Take(context.Background(), dir); !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+	_ = source.Close()
+	if _, err := Take(context.Background(), dir); err == nil {
+		t.Fatal("a closed source must not accept takeovers")
+	}
+}
@@ -0,0 +1,155 @@
+//go:build !windows
+
+package handover
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Listen offers handovers on the socket in dir. The data dir lock must be
+// held, since a socket left by a crashed daemon is replaced.
+func Listen(dir string) (*Source, error) {
+	path := filepath.Join(dir, SocketName)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	ln.SetUnlinkOnClose(true)
+	return newSource(ln), nil
+}
+
+// SendListener passes a duplicate of ln to the successor. ln keeps serving
+// in this process until it is closed.
+func (r *Request) SendListener(ln net.Listener) error {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("listener %T cannot be handed over", ln)
+	}
+	conn, ok := r.conn.(*net.UnixConn)
+	if !ok {
+		return ErrUnsupported
+	}
+	f, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	raw, err := json.Marshal(message{Type: msgListener, Addr: ln.Addr().String()})
+	if err != nil {
+		return err
+	}
+	// Fd would switch the shared socket to blocking mode, so the descriptor
+	// is borrowed through Control instead.
+	rawConn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var writeErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		_, _, writeErr = conn.WriteMsgUnix(append(raw, '\n'), syscall.UnixRights(int(fd)), nil)
+	}); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// Take asks the daemon running on dir for its listener. The successor must
+// call WaitReleased before it opens anything in dir.
+func Take(ctx context.Context, dir string) (*Takeover, error) {
+	var dialer net.Dialer
+	c, err := dialer.DialContext(ctx, "unix", filepath.Join(dir, SocketName))
+	if err != nil {
+		return nil, err
+	}
+	conn := c.(*net.UnixConn)
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+	t, err := takeListener(conn)
+	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func takeListener(conn *net.UnixConn) (*Takeover, error) {
+	if err := writeMessage(conn, message{Type: msgHello, Version: protocolVersion, PID: os.Getpid()}); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, err
+	}
+	line, rest, found := bytes.Cut(buf[:n], []byte{'\n'})
+	if !found {
+		return nil, errors.New("handover message too long")
+	}
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, err
+	}
+	switch msg.Type {
+	case msgListener:
+	case msgError:
+		return nil, fmt.Errorf("%w: %s", ErrRejected, msg.Error)
+	default:
+		return nil, fmt.Errorf("unexpected handover message %q", msg.Type)
+	}
+	fd, err := parseListenerFD(oob[:oobn])
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "rpc-listener")
+	ln, err := net.FileListener(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &Takeover{
+		Listener: ln,
+		Addr:     msg.Addr,
+		conn:     conn,
+		reader:   bufio.NewReader(io.MultiReader(bytes.NewReader(rest), conn)),
+	}, nil
+}
+
+func parseListenerFD(oob []byte) (int, error) {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return -1, err
+	}
+	for i := range msgs {
+		fds, err := syscall.ParseUnixRights(&msgs[i])
+		if err != nil || len(fds) == 0 {
+			continue
+		}
+		for _, extra := range fds[1:] {
+			_ = syscall.Close(extra)
+		}
+		return fds[0], nil
+	}
+	return -1, errors.New("handover message carried no listener")
+}
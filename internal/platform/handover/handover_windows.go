@@ -0,0 +1,22 @@
+//go:build windows
+
+package handover
+
+import (
+	"context"
+	"net"
+)
+
+// Listen reports ErrUnsupported: Windows cannot pass sockets over a unix
+// socket, so upgrades there stop the old daemon first.
+func Listen(string) (*Source, error) {
+	return nil, ErrUnsupported
+}
+
+func (r *Request) SendListener(net.Listener) error {
+	return ErrUnsupported
+}
+
+func Take(context.Context, string) (*Takeover, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,222 @@
+// Package i18n translates user-facing error and notification text.
+//
+// Catalogs live in catalogs/<locale>.json and map stable message keys to
+// text. en.json is the source catalog: every key must exist there, and its
+// text doubles as the lookup key for errors that carry no explicit key, so
+// existing errors become translatable by adding them to en.json. Placeholders
+// use {name} syntax and must match between catalogs.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const DefaultLocale = "en"
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+type catalogFile struct {
+	Locale   string            `json:"locale"`
+	Messages map[string]string `json:"messages"`
+}
+
+// Catalogs holds every loaded locale plus a reverse index of the source text.
+type Catalogs struct {
+	messages map[string]map[string]string
+	bySource map[string]string
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultCatalogs *Catalogs
+	defaultErr      error
+)
+
+// Default returns the catalogs embedded into the binary.
+func Default() *Catalogs {
+	defaultOnce.Do(func() {
+		defaultCatalogs, defaultErr = loadEmbedded()
+	})
+	if defaultErr != nil {
+		panic(fmt.Sprintf("i18n: embedded catalogs are invalid: %v", defaultErr))
+	}
+	return defaultCatalogs
+}
+
+func loadEmbedded() (*Catalogs, error) {
+	entries, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		return nil, err
+	}
+	files := make([]catalogFile, 0, len(entries))
+	for _, entry := range entries {
+		raw, err := catalogFS.ReadFile(path.Join("catalogs", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var file catalogFile
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if want := strings.TrimSuffix(entry.Name(), ".json"); file.Locale != want {
+			return nil, fmt.Errorf("%s: locale %q does not match file name", entry.Name(), file.Locale)
+		}
+		files = append(files, file)
+	}
+	return newCatalogs(files...)
+}
+
+// newCatalogs validates catalogs against the source locale.
+func newCatalogs(files ...catalogFile) (*Catalogs, error) {
+	c := &Catalogs{messages: map[string]map[string]string{}, bySource: map[string]string{}}
+	for _, file := range files {
+		c.messages[normalizeLocale(file.Locale)] = file.Messages
+	}
+	source, ok := c.messages[DefaultLocale]
+	if !ok {
+		return nil, fmt.Errorf("source catalog %q is missing", DefaultLocale)
+	}
+	for key, text := range source {
+		if previous, dup := c.bySource[text]; dup {
+			return nil, fmt.Errorf("keys %q and %q share source text", previous, key)
+		}
+		c.bySource[text] = key
+	}
+	for locale, messages := range c.messages {
+		for key, text := range messages {
+			sourceText, ok := source[key]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q is not in the source catalog", locale, key)
+			}
+			if !samePlaceholders(sourceText, text) {
+				return nil, fmt.Errorf("%s: key %q placeholders differ from the source catalog", locale, key)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Locales lists the loaded locales in sorted order.
+func (c *Catalogs) Locales() []string {
+	out := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		out = append(out, locale)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Negotiate picks the first supported locale among the candidates. Each
+// candidate is a locale tag or an Accept-Language value; region subtags fall
+// back to the base language. The source locale is returned when nothing
+// matches.
+func (c *Catalogs) Negotiate(candidates ...string) string {
+	for _, candidate := range candidates {
+		for _, tag := range strings.Split(candidate, ",") {
+			tag, _, _ = strings.Cut(tag, ";")
+			if locale, ok := c.supported(tag); ok {
+				return locale
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+func (c *Catalogs) supported(tag string) (string, bool) {
+	locale := normalizeLocale(tag)
+	if locale == "" || locale == "*" {
+		return "", false
+	}
+	if _, ok := c.messages[locale]; ok {
+		return locale, true
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	if _, ok := c.messages[base]; ok {
+		return base, true
+	}
+	return "", false
+}
+
+// Message renders key in locale, falling back to the base language and then
+// to the source catalog.
+func (c *Catalogs) Message(locale, key string, args map[string]string) (string, bool) {
+	text, ok := c.lookup(locale, key)
+	if !ok {
+		return "", false
+	}
+	for name, value := range args {
+		text = strings.ReplaceAll(text, "{"+name+"}", value)
+	}
+	return text, true
+}
+
+func (c *Catalogs) lookup(locale, key string) (string, bool) {
+	locale = normalizeLocale(locale)
+	base, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, base, DefaultLocale} {
+		if text, ok := c.messages[candidate][key]; ok && text != "" {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// Translate localizes source-language text that carries no explicit key.
+// Wrapped errors ("known text: detail") translate their known prefix and keep
+// the detail untouched. The matched key is returned alongside the text.
+func (c *Catalogs) Translate(locale, text string) (string, string, bool) {
+	if key, ok := c.bySource[text]; ok {
+		translated, _ := c.lookup(locale, key)
+		return translated, key, true
+	}
+	head, detail, found := strings.Cut(text, ": ")
+	if !found {
+		return text, "", false
+	}
+	key, ok := c.bySource[head]
+	if !ok {
+		return text, "", false
+	}
+	translated, _ := c.lookup(locale, key)
+	return translated + ": " + detail, key, true
+}
+
+func normalizeLocale(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+func samePlaceholders(a, b string) bool {
+	left, right := placeholders(a), placeholders(b)
+	if len(left) != len(right) {
+		return false
+	}
+	for name := range left {
+		if _, ok := right[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func placeholders(text string) map[string]struct{} {
+	out := map[string]struct{}{}
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			return out
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			return out
+		}
+		out[text[start+1:start+end]] = struct{}{}
+		text = text[start+end+1:]
+	}
+}
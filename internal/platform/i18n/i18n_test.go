@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedCatalogsAreConsistent(t *testing.T) {
+	c, err := loadEmbedded()
+	if err != nil {
+		t.Fatalf("load embedded catalogs: %v", err)
+	}
+	locales := c.Locales()
+	if len(locales) < 2 || locales[0] != DefaultLocale {
+		t.Fatalf("unexpected locales: %v", locales)
+	}
+}
+
+func TestNegotiateFallsBackToBaseLanguageAndDefault(t *testing.T) {
+	c := Default()
+	cases := []struct {
+		candidates []string
+		want       string
+	}{
+		{[]string{"ru"}, "ru"},
+		{[]string{"ru_RU"}, "ru"},
+		{[]string{"", "de-DE,ru;q=0.8,en;q=0.5"}, "ru"},
+		{[]string{"xx", "*"}, DefaultLocale},
+		{nil, DefaultLocale},
+	}
+	for _, tc := range cases {
+		if got := c.Negotiate(tc.candidates...); got != tc.want {
+			t.Fatalf("Negotiate(%q) = %q, want %q", tc.candidates, got, tc.want)
+		}
+	}
+}
+
+func TestTranslateKnownAndWrappedText(t *testing.T) {
+	c := Default()
+	text, key, ok := c.Translate("ru", "message not found")
+	if !ok || key != "error.message_not_found" || text == "message not found" {
+		t.Fatalf("unexpected translation: %q %q %v", text, key, ok)
+	}
+	text, key, ok = c.Translate("ru-RU", "message annotation quota exceeded: value exceeds 4096 bytes")
+	if !ok || key != "error.message_annotation_quota_exceeded" || !strings.HasSuffix(text, ": value exceeds 4096 bytes") {
+		t.Fatalf("wrapped detail must be preserved: %q %q %v", text, key, ok)
+	}
+	if text, _, ok := c.Translate("ru", "something unknown"); ok || text != "something unknown" {
+		t.Fatalf("unknown text must pass through: %q %v", text, ok)
+	}
+	if text, _, _ := c.Translate("fr", "message not found"); text != "message not found" {
+		t.Fatalf("unsupported locale must fall back to source text, got %q", text)
+	}
+}
+
+func TestMessageRendersPlaceholders(t *testing.T) {
+	text, ok := Default().Message("en", "notify.security.alert.inbound_limit_violation", map[string]string{"count": "7"})
+	if !ok || text != "7 inbound payloads rejected" {
+		t.Fatalf("unexpected message: %q %v", text, ok)
+	}
+}
+
+func TestNewCatalogsRejectsInconsistentTranslations(t *testing.T) {
+	source := catalogFile{Locale: "en", Messages: map[string]string{"a": "{count} items"}}
+	if _, err := newCatalogs(source, catalogFile{Locale: "de", Messages: map[string]string{"b": "x"}}); err == nil {
+		t.Fatal("expected unknown key to be rejected")
+	}
+	if _, err := newCatalogs(source, catalogFile{Locale: "de", Messages: map[string]string{"a": "{anzahl} Elemente"}}); err == nil {
+		t.Fatal("expected placeholder mismatch to be rejected")
+	}
+	if _, err := newCatalogs(catalogFile{Locale: "de", Messages: map[string]string{}}); err == nil {
+		t.Fatal("expected missing source catalog to be rejected")
+	}
+}
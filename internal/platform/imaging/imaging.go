@@ -0,0 +1,108 @@
+// Package imaging scales user images down into small JPEG previews such as
+// group avatars and attachment thumbnails.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// ThumbnailMimeType is the encoding of every image Thumbnail produces.
+const ThumbnailMimeType = "image/jpeg"
+
+const (
+	maxSourceSide   = 8192
+	maxSourcePixels = 30_000_000
+)
+
+var ErrInvalidImage = errors.New("invalid image")
+
+// Image is an encoded preview and its dimensions.
+type Image struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Thumbnail decodes a bounded source image, scales it so the longer side is
+// at most maxSide and re-encodes it as JPEG on a white background, lowering
+// the quality until the result fits in maxBytes.
+func Thumbnail(data []byte, maxSide, maxBytes int) (Image, error) {
+	if len(data) == 0 {
+		return Image{}, ErrInvalidImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 ||
+		cfg.Width > maxSourceSide || cfg.Height > maxSourceSide ||
+		cfg.Width*cfg.Height > maxSourcePixels {
+		return Image{}, ErrInvalidImage
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrInvalidImage
+	}
+	resized := ResizeToFit(src, maxSide)
+	var encoded []byte
+	for quality := 85; quality >= 40; quality -= 15 {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: quality}); err != nil {
+			return Image{}, ErrInvalidImage
+		}
+		encoded = buf.Bytes()
+		if len(encoded) <= maxBytes {
+			break
+		}
+	}
+	if len(encoded) > maxBytes {
+		return Image{}, ErrInvalidImage
+	}
+	bounds := resized.Bounds()
+	return Image{Data: encoded, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// ResizeToFit downsamples with a box filter so the longer side is at most
+// maxSide, flattening transparency onto white.
+func ResizeToFit(src image.Image, maxSide int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					px := flat.RGBAAt(sx, sy)
+					r += uint32(px.R)
+					g += uint32(px.G)
+					b += uint32(px.B)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xff})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,212 @@
+// Package logsink routes daemon logs to configurable sinks.
+//
+// Sinks are listed in AIM_LOG_SINKS, comma separated:
+//
+//	stdout | stderr          JSON lines on the standard streams
+//	file:<path>              JSON lines in a size-rotated file
+//	syslog[:<tag>]           the local syslog daemon
+//	tcp:<host:port>          JSON lines over TCP, for log shippers
+//
+// AIM_LOG_LEVEL sets the default level and AIM_LOG_LEVELS overrides it per
+// record category, e.g. "network=debug,crypto=warn". The same settings can
+// be given in the logging section of config.yaml, which the environment
+// overrides. Configuration can be reloaded at runtime; loggers created
+// earlier follow the new sinks.
+package logsink
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkTCP    = "tcp"
+
+	DefaultFileMaxMB      = 100
+	DefaultFileMaxBackups = 5
+	defaultSyslogTag      = "aim-chat"
+)
+
+var (
+	ErrInvalidSink  = errors.New("invalid log sink")
+	ErrInvalidLevel = errors.New("invalid log level")
+
+	errCollectorUnavailable = errors.New("log collector is unavailable")
+)
+
+// Config selects where logs go and how verbose each category is.
+type Config struct {
+	Sinks []SinkConfig
+	// Level applies to records without a category override.
+	Level slog.Level
+	// Levels overrides Level for records whose "category" attribute
+	// matches, such as network, crypto or storage.
+	Levels map[string]slog.Level
+}
+
+type SinkConfig struct {
+	Kind string
+	// Target is the file path, syslog tag or TCP address.
+	Target         string
+	FileMaxBytes   int64
+	FileMaxBackups int
+}
+
+// DefaultConfig writes JSON lines to stdout at info level, which is how the
+// daemon logged before sinks were configurable.
+func DefaultConfig() Config {
+	return Config{Sinks: []SinkConfig{{Kind: SinkStdout}}, Level: slog.LevelInfo}
+}
+
+// Spec is the textual form of a Config, as written in the logging section of
+// config.yaml. Empty fields keep their defaults.
+type Spec struct {
+	Sinks          []string          `yaml:"sinks"`
+	Level          string            `yaml:"level"`
+	Levels         map[string]string `yaml:"levels"`
+	FileMaxMB      int               `yaml:"fileMaxMB"`
+	FileMaxBackups int               `yaml:"fileMaxBackups"`
+}
+
+// WithEnv overrides spec with AIM_LOG_SINKS, AIM_LOG_LEVEL, AIM_LOG_LEVELS,
+// AIM_LOG_FILE_MAX_MB and AIM_LOG_FILE_MAX_BACKUPS where they are set.
+func (spec Spec) WithEnv() (Spec, error) {
+	if raw := strings.TrimSpace(os.Getenv("AIM_LOG_SINKS")); raw != "" {
+		spec.Sinks = strings.Split(raw, ",")
+	}
+	if raw := strings.TrimSpace(os.Getenv("AIM_LOG_LEVEL")); raw != "" {
+		spec.Level = raw
+	}
+	if raw := strings.TrimSpace(os.Getenv("AIM_LOG_LEVELS")); raw != "" {
+		levels := map[string]string{}
+		for _, pair := range strings.Split(raw, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			category, level, ok := strings.Cut(pair, "=")
+			if !ok {
+				return Spec{}, fmt.Errorf("%w: %q", ErrInvalidLevel, pair)
+			}
+			levels[category] = level
+		}
+		spec.Levels = levels
+	}
+	var err error
+	if spec.FileMaxMB, err = envPositiveInt("AIM_LOG_FILE_MAX_MB", spec.FileMaxMB); err != nil {
+		return Spec{}, err
+	}
+	if spec.FileMaxBackups, err = envPositiveInt("AIM_LOG_FILE_MAX_BACKUPS", spec.FileMaxBackups); err != nil {
+		return Spec{}, err
+	}
+	return spec, nil
+}
+
+// Config parses spec.
+func (spec Spec) Config() (Config, error) {
+	cfg := DefaultConfig()
+	maxMB, maxBackups := spec.FileMaxMB, spec.FileMaxBackups
+	if maxMB <= 0 {
+		maxMB = DefaultFileMaxMB
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultFileMaxBackups
+	}
+	if len(spec.Sinks) > 0 {
+		cfg.Sinks = nil
+		for _, raw := range spec.Sinks {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			sink, err := ParseSink(raw)
+			if err != nil {
+				return Config{}, err
+			}
+			sink.FileMaxBytes = int64(maxMB) * 1024 * 1024
+			sink.FileMaxBackups = maxBackups
+			cfg.Sinks = append(cfg.Sinks, sink)
+		}
+		if len(cfg.Sinks) == 0 {
+			return Config{}, fmt.Errorf("%w: no sink listed", ErrInvalidSink)
+		}
+	}
+	if strings.TrimSpace(spec.Level) != "" {
+		level, err := ParseLevel(spec.Level)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Level = level
+	}
+	if len(spec.Levels) > 0 {
+		cfg.Levels = make(map[string]slog.Level, len(spec.Levels))
+		for category, raw := range spec.Levels {
+			category = strings.ToLower(strings.TrimSpace(category))
+			if category == "" {
+				return Config{}, fmt.Errorf("%w: empty category", ErrInvalidLevel)
+			}
+			level, err := ParseLevel(raw)
+			if err != nil {
+				return Config{}, err
+			}
+			cfg.Levels[category] = level
+		}
+	}
+	return cfg, nil
+}
+
+// ParseSink parses one AIM_LOG_SINKS entry.
+func ParseSink(spec string) (SinkConfig, error) {
+	spec = strings.TrimSpace(spec)
+	kind, target, _ := strings.Cut(spec, ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	target = strings.TrimSpace(target)
+	switch kind {
+	case SinkStdout, SinkStderr:
+		if target != "" {
+			return SinkConfig{}, fmt.Errorf("%w: %q takes no target", ErrInvalidSink, spec)
+		}
+	case SinkFile, SinkTCP:
+		if target == "" {
+			return SinkConfig{}, fmt.Errorf("%w: %q needs a target", ErrInvalidSink, spec)
+		}
+	case SinkSyslog:
+		if target == "" {
+			target = defaultSyslogTag
+		}
+	default:
+		return SinkConfig{}, fmt.Errorf("%w: %q", ErrInvalidSink, spec)
+	}
+	return SinkConfig{
+		Kind:           kind,
+		Target:         target,
+		FileMaxBytes:   DefaultFileMaxMB * 1024 * 1024,
+		FileMaxBackups: DefaultFileMaxBackups,
+	}, nil
+}
+
+func ParseLevel(raw string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(raw))); err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, raw)
+	}
+	return level, nil
+}
+
+func envPositiveInt(key string, fallback int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,111 @@
+package logsink
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingFile appends to path and, once a write would take it past
+// maxBytes, shifts it to path.1, path.1 to path.2 and so on, dropping the
+// oldest of maxBackups copies.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxBytes int64, maxBackups int) (*rotatingFile, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultFileMaxMB * 1024 * 1024
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultFileMaxBackups
+	}
+	w := &rotatingFile{path: filepath.Clean(path), maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := w.openLocked(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotatingFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingFile) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *rotatingFile) openLocked() error {
+	// Logs carry fingerprints of contact and message ids, so they are kept
+	// private to the daemon user like the rest of the data dir.
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *rotatingFile) rotateLocked() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(w.path, i), backupPath(w.path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, backupPath(w.path, 1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return w.openLocked()
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// OpenRotatingFile opens a writer with the rotation of the file sink, for
+// other streams such as the audit export.
+func OpenRotatingFile(path string, maxBytes int64, maxBackups int) (io.WriteCloser, error) {
+	return openRotatingFile(path, maxBytes, maxBackups)
+}
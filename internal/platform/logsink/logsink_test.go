@@ -0,0 +1,241 @@
+package logsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func fileConfig(path string) Config {
+	return Config{
+		Sinks: []SinkConfig{{Kind: SinkFile, Target: path, FileMaxBytes: 1 << 20, FileMaxBackups: 2}},
+		Level: slog.LevelInfo,
+	}
+}
+
+func TestRouterCategoryLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.log")
+	cfg := fileConfig(path)
+	cfg.Levels = map[string]slog.Level{"network": slog.LevelDebug, "crypto": slog.LevelWarn}
+	router, err := NewRouter(cfg)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	defer func() { _ = router.Close() }()
+
+	logger := slog.New(router)
+	logger.Debug("network debug", "category", "network")
+	logger.Debug("plain debug")
+	logger.Info("crypto info", "category", "crypto")
+	logger.Warn("crypto warn", "category", "crypto")
+	logger.With("category", "network").Debug("bound network debug")
+
+	var got []string
+	for _, rec := range readLines(t, path) {
+		got = append(got, rec["msg"].(string))
+	}
+	want := []string{"network debug", "crypto warn", "bound network debug"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected records: got %v want %v", got, want)
+	}
+}
+
+func TestRouterReconfigureAffectsDerivedLoggers(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+	router, err := NewRouter(fileConfig(first))
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	defer func() { _ = router.Close() }()
+
+	logger := slog.New(router).With("component", "test").WithGroup("ctx")
+	logger.Info("before", "k", "v")
+	if err := router.Reconfigure(fileConfig(second)); err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	logger.Info("after", "k", "v")
+
+	if recs := readLines(t, first); len(recs) != 1 || recs[0]["msg"] != "before" {
+		t.Fatalf("unexpected first sink records: %v", recs)
+	}
+	recs := readLines(t, second)
+	if len(recs) != 1 || recs[0]["msg"] != "after" || recs[0]["component"] != "test" {
+		t.Fatalf("unexpected second sink records: %v", recs)
+	}
+	if group, ok := recs[0]["ctx"].(map[string]any); !ok || group["k"] != "v" {
+		t.Fatalf("group attrs were not replayed: %v", recs[0])
+	}
+}
+
+func TestRouterReconfigureKeepsPreviousOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.log")
+	router, err := NewRouter(fileConfig(path))
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	defer func() { _ = router.Close() }()
+
+	bad := fileConfig(filepath.Join(path, "not-a-dir", "x.log"))
+	if err := router.Reconfigure(bad); err == nil {
+		t.Fatal("expected reconfigure to fail")
+	}
+	slog.New(router).Info("still here")
+	if recs := readLines(t, path); len(recs) != 1 || recs[0]["msg"] != "still here" {
+		t.Fatalf("previous sink was not kept: %v", recs)
+	}
+}
+
+func TestRotatingFileKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.log")
+	w, err := openRotatingFile(path, 64, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	line := []byte(strings.Repeat("x", 40) + "\n")
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		if info.Size() > 64 {
+			t.Fatalf("%s exceeds the size limit: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected at most two backups, stat .3: %v", err)
+	}
+}
+
+func TestTCPSinkShipsJSONLines(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		scanner := bufio.NewScanner(conn)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	router, err := NewRouter(Config{
+		Sinks: []SinkConfig{{Kind: SinkTCP, Target: ln.Addr().String()}},
+		Level: slog.LevelInfo,
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	defer func() { _ = router.Close() }()
+	slog.New(router).Info("shipped", "category", "audit")
+
+	select {
+	case line := <-lines:
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		if rec["msg"] != "shipped" || rec["category"] != "audit" {
+			t.Fatalf("unexpected record: %v", rec)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("collector received nothing")
+	}
+}
+
+func TestSpecConfig(t *testing.T) {
+	cfg, err := Spec{
+		Sinks:     []string{"stderr", " file:/tmp/aim.log ", "syslog"},
+		Level:     "warn",
+		Levels:    map[string]string{"Network": "debug"},
+		FileMaxMB: 2,
+	}.Config()
+	if err != nil {
+		t.Fatalf("Config: %v", err)
+	}
+	if len(cfg.Sinks) != 3 || cfg.Sinks[1].Target != "/tmp/aim.log" || cfg.Sinks[1].FileMaxBytes != 2<<20 {
+		t.Fatalf("unexpected sinks: %+v", cfg.Sinks)
+	}
+	if cfg.Sinks[2].Target != defaultSyslogTag {
+		t.Fatalf("unexpected syslog tag: %q", cfg.Sinks[2].Target)
+	}
+	if cfg.Level != slog.LevelWarn || cfg.Levels["network"] != slog.LevelDebug {
+		t.Fatalf("unexpected levels: %v %v", cfg.Level, cfg.Levels)
+	}
+
+	defaults, err := Spec{}.Config()
+	if err != nil || len(defaults.Sinks) != 1 || defaults.Sinks[0].Kind != SinkStdout || defaults.Level != slog.LevelInfo {
+		t.Fatalf("unexpected defaults: %+v err=%v", defaults, err)
+	}
+
+	for _, bad := range []Spec{
+		{Sinks: []string{"kafka:x"}},
+		{Sinks: []string{"file"}},
+		{Sinks: []string{"stdout:x"}},
+		{Level: "loud"},
+		{Levels: map[string]string{"network": "loud"}},
+	} {
+		if _, err := bad.Config(); !errors.Is(err, ErrInvalidSink) && !errors.Is(err, ErrInvalidLevel) {
+			t.Fatalf("expected invalid spec error for %+v, got %v", bad, err)
+		}
+	}
+}
+
+func TestSpecWithEnvOverridesFile(t *testing.T) {
+	t.Setenv("AIM_LOG_SINKS", "stderr")
+	t.Setenv("AIM_LOG_LEVELS", "storage=error,network=debug")
+	spec, err := Spec{Sinks: []string{"stdout"}, Level: "debug"}.WithEnv()
+	if err != nil {
+		t.Fatalf("WithEnv: %v", err)
+	}
+	if len(spec.Sinks) != 1 || spec.Sinks[0] != "stderr" || spec.Level != "debug" || spec.Levels["storage"] != "error" {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+
+	t.Setenv("AIM_LOG_LEVELS", "storage")
+	if _, err := (Spec{}).WithEnv(); !errors.Is(err, ErrInvalidLevel) {
+		t.Fatalf("expected ErrInvalidLevel, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// categoryKey is the record attribute that selects a per-category level.
+const categoryKey = "category"
+
+// Router is a slog.Handler that fans records out to the configured sinks.
+// Handlers derived from it with WithAttrs or WithGroup keep following the
+// router, so Reconfigure takes effect for loggers that already exist.
+type Router struct {
+	state atomic.Pointer[routerState]
+}
+
+type routerState struct {
+	sinks  []sink
+	level  slog.Level
+	levels map[string]slog.Level
+	// min is the lowest level any category accepts.
+	min slog.Level
+}
+
+type sink struct {
+	handler slog.Handler
+	closer  io.Closer
+}
+
+func NewRouter(cfg Config) (*Router, error) {
+	r := &Router{}
+	if err := r.Reconfigure(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reconfigure opens the sinks of cfg and swaps them in. If any sink fails to
+// open the previous configuration stays in place.
+func (r *Router) Reconfigure(cfg Config) error {
+	next, err := buildState(cfg)
+	if err != nil {
+		return err
+	}
+	if prev := r.state.Swap(next); prev != nil {
+		_ = prev.close()
+	}
+	return nil
+}
+
+// Close releases the sinks. Records logged afterwards are dropped.
+func (r *Router) Close() error {
+	closed := &routerState{level: slog.LevelError + 1, min: slog.LevelError + 1}
+	if prev := r.state.Swap(closed); prev != nil {
+		return prev.close()
+	}
+	return nil
+}
+
+func (r *Router) Enabled(ctx context.Context, level slog.Level) bool {
+	return (&routedHandler{router: r}).Enabled(ctx, level)
+}
+
+func (r *Router) Handle(ctx context.Context, rec slog.Record) error {
+	return (&routedHandler{router: r}).Handle(ctx, rec)
+}
+
+func (r *Router) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return (&routedHandler{router: r}).WithAttrs(attrs)
+}
+
+func (r *Router) WithGroup(name string) slog.Handler {
+	return (&routedHandler{router: r}).WithGroup(name)
+}
+
+// routedHandler records WithAttrs and WithGroup calls and replays them on the
+// sinks current at Handle time.
+type routedHandler struct {
+	router   *Router
+	ops      []handlerOp
+	category string
+	grouped  bool
+}
+
+type handlerOp struct {
+	attrs []slog.Attr
+	group string
+}
+
+func (h *routedHandler) Enabled(_ context.Context, level slog.Level) bool {
+	st := h.router.state.Load()
+	return st != nil && level >= st.min
+}
+
+func (h *routedHandler) Handle(ctx context.Context, rec slog.Record) error {
+	st := h.router.state.Load()
+	if st == nil {
+		return nil
+	}
+	category := h.category
+	if !h.grouped {
+		rec.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == categoryKey {
+				category = attr.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if rec.Level < st.levelFor(category) {
+		return nil
+	}
+	var errs []error
+	for _, s := range st.sinks {
+		handler := s.handler
+		for _, op := range h.ops {
+			if op.group != "" {
+				handler = handler.WithGroup(op.group)
+			} else {
+				handler = handler.WithAttrs(op.attrs)
+			}
+		}
+		if !handler.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, rec.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *routedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	next := h.with(handlerOp{attrs: append([]slog.Attr(nil), attrs...)})
+	if !h.grouped {
+		for _, attr := range attrs {
+			if attr.Key == categoryKey {
+				next.category = attr.Value.String()
+			}
+		}
+	}
+	return next
+}
+
+func (h *routedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := h.with(handlerOp{group: name})
+	next.grouped = true
+	return next
+}
+
+func (h *routedHandler) with(op handlerOp) *routedHandler {
+	return &routedHandler{
+		router:   h.router,
+		ops:      append(append([]handlerOp(nil), h.ops...), op),
+		category: h.category,
+		grouped:  h.grouped,
+	}
+}
+
+func (st *routerState) levelFor(category string) slog.Level {
+	if level, ok := st.levels[strings.ToLower(category)]; ok {
+		return level
+	}
+	return st.level
+}
+
+func (st *routerState) close() error {
+	var errs []error
+	for _, s := range st.sinks {
+		if s.closer != nil {
+			errs = append(errs, s.closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func buildState(cfg Config) (*routerState, error) {
+	if len(cfg.Sinks) == 0 {
+		cfg.Sinks = DefaultConfig().Sinks
+	}
+	st := &routerState{level: cfg.Level, levels: map[string]slog.Level{}, min: cfg.Level}
+	for category, level := range cfg.Levels {
+		st.levels[strings.ToLower(category)] = level
+		if level < st.min {
+			st.min = level
+		}
+	}
+	// Sinks pass everything through; the router already filtered by level.
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug - 4}
+	for _, sc := range cfg.Sinks {
+		s, err := openSink(sc, opts)
+		if err != nil {
+			_ = st.close()
+			return nil, fmt.Errorf("open %s log sink: %w", sc.Kind, err)
+		}
+		st.sinks = append(st.sinks, s)
+	}
+	return st, nil
+}
+
+func openSink(sc SinkConfig, opts *slog.HandlerOptions) (sink, error) {
+	switch sc.Kind {
+	case SinkStdout:
+		return sink{handler: slog.NewJSONHandler(os.Stdout, opts)}, nil
+	case SinkStderr:
+		return sink{handler: slog.NewJSONHandler(os.Stderr, opts)}, nil
+	case SinkFile:
+		w, err := openRotatingFile(sc.Target, sc.FileMaxBytes, sc.FileMaxBackups)
+		if err != nil {
+			return sink{}, err
+		}
+		return sink{handler: slog.NewJSONHandler(w, opts), closer: w}, nil
+	case SinkSyslog:
+		w, err := openSyslog(sc.Target)
+		if err != nil {
+			return sink{}, err
+		}
+		return sink{handler: slog.NewJSONHandler(w, opts), closer: w}, nil
+	case SinkTCP:
+		w := newTCPWriter(sc.Target)
+		return sink{handler: slog.NewJSONHandler(w, opts), closer: w}, nil
+	default:
+		return sink{}, fmt.Errorf("%w: %q", ErrInvalidSink, sc.Kind)
+	}
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultRouter *Router
+)
+
+// Default returns the process-wide router, configured from the environment
+// on first use. An invalid environment falls back to DefaultConfig.
+func Default() *Router {
+	defaultOnce.Do(func() {
+		cfg, err := LoadSpec(Spec{})
+		if err == nil {
+			defaultRouter, err = NewRouter(cfg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "log sink configuration ignored: %v\n", err)
+			defaultRouter, _ = NewRouter(DefaultConfig())
+		}
+	})
+	return defaultRouter
+}
+
+// LoadSpec applies the environment overrides to spec and parses it.
+func LoadSpec(spec Spec) (Config, error) {
+	spec, err := spec.WithEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	return spec.Config()
+}
+
+// Apply reconfigures Default with spec and the environment overrides. On
+// error the running configuration is kept.
+func Apply(spec Spec) error {
+	cfg, err := LoadSpec(spec)
+	if err != nil {
+		return err
+	}
+	return Default().Reconfigure(cfg)
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package logsink
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// openSyslog connects to the local syslog daemon. Records are sent as JSON
+// under the daemon facility; the level is part of the JSON body.
+func openSyslog(tag string) (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
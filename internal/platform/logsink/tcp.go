@@ -0,0 +1,77 @@
+package logsink
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	tcpDialTimeout  = 3 * time.Second
+	tcpWriteTimeout = 3 * time.Second
+	// tcpRedialBackoff keeps an unreachable collector from stalling every
+	// log call on a dial attempt.
+	tcpRedialBackoff = 5 * time.Second
+)
+
+// tcpWriter ships JSON lines to a collector such as a log shipper listening
+// on TCP. It dials lazily and redials after failures; records written while
+// the collector is unreachable are dropped rather than blocking the daemon.
+type tcpWriter struct {
+	mu         sync.Mutex
+	addr       string
+	conn       net.Conn
+	lastFailed time.Time
+	closed     bool
+}
+
+func newTCPWriter(addr string) *tcpWriter {
+	return &tcpWriter{addr: addr}
+}
+
+func (w *tcpWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.conn == nil {
+		if !w.lastFailed.IsZero() && time.Since(w.lastFailed) < tcpRedialBackoff {
+			return 0, errCollectorUnavailable
+		}
+		conn, err := net.DialTimeout("tcp", w.addr, tcpDialTimeout)
+		if err != nil {
+			w.lastFailed = time.Now()
+			return 0, err
+		}
+		w.conn = conn
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(tcpWriteTimeout))
+	n, err := w.conn.Write(p)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.lastFailed = time.Now()
+	}
+	return n, err
+}
+
+func (w *tcpWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// NewTCPWriter returns a writer with the dialing and drop behaviour of the
+// TCP sink, for other streams such as the audit export.
+func NewTCPWriter(addr string) io.WriteCloser {
+	return newTCPWriter(addr)
+}
@@ -0,0 +1,632 @@
+// Package plugins runs third-party message processors, such as translators,
+// DLP scanners and auto-taggers, as sandboxed subprocesses.
+//
+// A plugin is an executable speaking a one-shot JSON protocol: for every call
+// the host starts it, writes one request line to stdin and reads one response
+// line from stdout. The process gets an empty environment and a private
+// working directory, and is killed once its timeout expires. A plugin that
+// fails or times out is skipped and the failure is recorded in its status,
+// so processing fails open.
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const ProtocolVersion = 1
+
+// Capabilities a plugin declares in its describe response. A plugin only
+// receives the calls its effective capabilities cover.
+const (
+	CapabilityOutboundObserve   = "outbound.observe"
+	CapabilityOutboundTransform = "outbound.transform"
+	CapabilityInboundAnnotate   = "inbound.annotate"
+)
+
+const (
+	MethodDescribe       = "describe"
+	MethodOutboundDraft  = "outbound.draft"
+	MethodInboundMessage = "inbound.message"
+)
+
+const (
+	DefaultTimeout = 2 * time.Second
+	MaxTimeout     = 30 * time.Second
+	// MaxAnnotateTime bounds all annotating plugins together for one
+	// message, however many are configured.
+	MaxAnnotateTime = 10 * time.Second
+	// MaxOutboundTime bounds all outbound plugins together for one draft,
+	// so a send never waits on every plugin's own timeout in turn.
+	MaxOutboundTime = 10 * time.Second
+
+	maxPlugins       = 32
+	maxResponseBytes = 1 << 20
+	// annotateQueueSize bounds the messages waiting for annotation; more
+	// are dropped rather than held in memory.
+	annotateQueueSize = 256
+	// waitDelay bounds how long a killed plugin's children may keep its
+	// output pipe open.
+	waitDelay = time.Second
+)
+
+var (
+	ErrPluginNotFound = errors.New("plugin not found")
+	ErrInvalidConfig  = errors.New("invalid plugin config")
+	ErrDraftRejected  = errors.New("draft rejected by plugin")
+)
+
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+var knownCapabilities = []string{
+	CapabilityOutboundObserve,
+	CapabilityOutboundTransform,
+	CapabilityInboundAnnotate,
+}
+
+// Config describes one plugin. Capabilities, when set, grants only those
+// capabilities whatever the plugin declares. Command must be an absolute
+// path, since plugins run without PATH.
+type Config struct {
+	Name         string   `json:"name"`
+	Command      string   `json:"command"`
+	Args         []string `json:"args,omitempty"`
+	TimeoutMS    int64    `json:"timeout_ms,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Disabled     bool     `json:"disabled,omitempty"`
+}
+
+// Draft is an outbound message before it is stored and sent.
+type Draft struct {
+	ConversationID   string `json:"conversation_id"`
+	ConversationType string `json:"conversation_type"`
+	ThreadID         string `json:"thread_id,omitempty"`
+	Content          string `json:"content"`
+}
+
+// Message is a received message offered for annotation.
+type Message struct {
+	MessageID        string `json:"message_id"`
+	ConversationID   string `json:"conversation_id"`
+	ConversationType string `json:"conversation_type"`
+	SenderID         string `json:"sender_id"`
+	ContentType      string `json:"content_type"`
+	Content          string `json:"content"`
+}
+
+type request struct {
+	Protocol int      `json:"protocol"`
+	Method   string   `json:"method"`
+	Draft    *Draft   `json:"draft,omitempty"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+// response carries the fields of every method; each method reads its own.
+type response struct {
+	Version      string            `json:"version,omitempty"`
+	Capabilities []string          `json:"capabilities,omitempty"`
+	Content      *string           `json:"content,omitempty"`
+	Reject       string            `json:"reject,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// LoadConfig reads a plugins file of the form {"plugins": [...]}.
+func LoadConfig(path string) ([]Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Plugins []Config `json:"plugins"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return file.Plugins, nil
+}
+
+func validateConfigs(configs []Config) error {
+	if len(configs) > maxPlugins {
+		return fmt.Errorf("%w: at most %d plugins", ErrInvalidConfig, maxPlugins)
+	}
+	seen := make(map[string]struct{}, len(configs))
+	for _, cfg := range configs {
+		if !pluginNamePattern.MatchString(cfg.Name) {
+			return fmt.Errorf("%w: bad name %q", ErrInvalidConfig, cfg.Name)
+		}
+		if _, dup := seen[cfg.Name]; dup {
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidConfig, cfg.Name)
+		}
+		seen[cfg.Name] = struct{}{}
+		if !filepath.IsAbs(cfg.Command) {
+			return fmt.Errorf("%w: %s: command must be an absolute path", ErrInvalidConfig, cfg.Name)
+		}
+		if cfg.TimeoutMS < 0 || time.Duration(cfg.TimeoutMS)*time.Millisecond > MaxTimeout {
+			return fmt.Errorf("%w: %s: timeout must be at most %s", ErrInvalidConfig, cfg.Name, MaxTimeout)
+		}
+		for _, capability := range cfg.Capabilities {
+			if !slices.Contains(knownCapabilities, capability) {
+				return fmt.Errorf("%w: %s: unknown capability %q", ErrInvalidConfig, cfg.Name, capability)
+			}
+		}
+	}
+	return nil
+}
+
+// Host owns the configured plugins and dispatches calls to them. The zero
+// number of plugins is valid and makes every call a no-op.
+type Host struct {
+	mu        sync.RWMutex
+	plugins   []*plugin
+	workDir   string
+	statePath string
+
+	// annotateTimeout and outboundTimeout override MaxAnnotateTime and
+	// MaxOutboundTime when set.
+	annotateTimeout time.Duration
+	outboundTimeout time.Duration
+	// The annotation worker is started by the first queued message.
+	annotateOnce   sync.Once
+	annotateQueue  chan annotateJob
+	annotateCancel context.CancelFunc
+	annotateDone   chan struct{}
+	closed         bool
+}
+
+type annotateJob struct {
+	msg   Message
+	store func(plugin string, annotations map[string]string)
+}
+
+type plugin struct {
+	cfg         Config
+	timeout     time.Duration
+	enabled     bool
+	described   bool
+	version     string
+	declared    []string
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// target is a snapshot of what a call needs, taken so plugins run without
+// holding the host lock.
+type target struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+	caps    []string
+}
+
+// NewHost validates configs and restores the enable/disable choices saved at
+// statePath. Each plugin works in its own directory under workDir.
+func NewHost(configs []Config, workDir, statePath string) (*Host, error) {
+	if err := validateConfigs(configs); err != nil {
+		return nil, err
+	}
+	h := &Host{workDir: workDir, statePath: statePath}
+	for _, cfg := range configs {
+		timeout := time.Duration(cfg.TimeoutMS) * time.Millisecond
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		h.plugins = append(h.plugins, &plugin{cfg: cfg, timeout: timeout, enabled: !cfg.Disabled})
+	}
+	enabled, err := h.loadState()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range h.plugins {
+		if on, ok := enabled[p.cfg.Name]; ok {
+			p.enabled = on
+		}
+	}
+	return h, nil
+}
+
+// Start asks every enabled plugin for its version and capabilities. A plugin
+// that does not answer gets no calls until it is re-enabled.
+func (h *Host) Start(ctx context.Context) {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.plugins))
+	for _, p := range h.plugins {
+		if p.enabled {
+			names = append(names, p.cfg.Name)
+		}
+	}
+	h.mu.RUnlock()
+	for _, name := range names {
+		h.describe(ctx, name)
+	}
+}
+
+func (h *Host) List() []models.PluginInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]models.PluginInfo, 0, len(h.plugins))
+	for _, p := range h.plugins {
+		out = append(out, p.info())
+	}
+	return out
+}
+
+// SetEnabled turns a plugin on or off and remembers the choice. Enabling
+// repeats the describe handshake, so an updated plugin binary is picked up.
+func (h *Host) SetEnabled(ctx context.Context, name string, enabled bool) (models.PluginInfo, error) {
+	h.mu.Lock()
+	p := h.findLocked(name)
+	if p == nil {
+		h.mu.Unlock()
+		return models.PluginInfo{}, ErrPluginNotFound
+	}
+	prev := p.enabled
+	p.enabled = enabled
+	if err := h.saveStateLocked(); err != nil {
+		p.enabled = prev
+		h.mu.Unlock()
+		return models.PluginInfo{}, err
+	}
+	h.mu.Unlock()
+
+	if enabled {
+		h.describe(ctx, name)
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return p.info(), nil
+}
+
+// ProcessOutbound runs a draft through the enabled plugins in configuration
+// order and returns the content to send. Observers see the draft as it
+// stands; transformers may replace the content or reject the draft, which
+// fails with ErrDraftRejected. All plugins together get MaxOutboundTime;
+// those not reached by then are skipped and the draft goes out as it stands.
+func (h *Host) ProcessOutbound(ctx context.Context, draft Draft) (string, error) {
+	budget := MaxOutboundTime
+	if h.outboundTimeout > 0 {
+		budget = h.outboundTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	for _, t := range h.targets(CapabilityOutboundObserve, CapabilityOutboundTransform) {
+		if ctx.Err() != nil {
+			break
+		}
+		current := draft
+		resp, err := h.call(ctx, t, request{Method: MethodOutboundDraft, Draft: &current})
+		if err != nil || !slices.Contains(t.caps, CapabilityOutboundTransform) {
+			continue
+		}
+		if reason := strings.TrimSpace(resp.Reject); reason != "" {
+			return "", fmt.Errorf("%w: %s: %s", ErrDraftRejected, t.name, reason)
+		}
+		if resp.Content != nil {
+			draft.Content = *resp.Content
+		}
+	}
+	return draft.Content, nil
+}
+
+// AnnotateInbound offers a received message to the enabled annotating
+// plugins in configuration order and hands each plugin's annotations to
+// store as soon as it answers. All plugins together get MaxAnnotateTime;
+// those not reached by then are skipped.
+func (h *Host) AnnotateInbound(ctx context.Context, msg Message, store func(plugin string, annotations map[string]string)) {
+	budget := MaxAnnotateTime
+	if h.annotateTimeout > 0 {
+		budget = h.annotateTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	for _, t := range h.targets(CapabilityInboundAnnotate) {
+		if ctx.Err() != nil {
+			return
+		}
+		current := msg
+		resp, err := h.call(ctx, t, request{Method: MethodInboundMessage, Message: &current})
+		if err != nil || len(resp.Annotations) == 0 {
+			continue
+		}
+		store(t.name, resp.Annotations)
+	}
+}
+
+// QueueInboundAnnotation runs AnnotateInbound on the host's own worker, so
+// slow plugins never hold up the caller. It reports false when the message
+// was not queued because the queue is full or the host is closed.
+func (h *Host) QueueInboundAnnotation(msg Message, store func(plugin string, annotations map[string]string)) bool {
+	if len(h.targets(CapabilityInboundAnnotate)) == 0 {
+		return true
+	}
+	h.annotateOnce.Do(h.startAnnotateWorker)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return false
+	}
+	select {
+	case h.annotateQueue <- annotateJob{msg: msg, store: store}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops the annotation worker. The message being annotated is
+// abandoned and queued ones are dropped.
+func (h *Host) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	started := h.annotateQueue != nil
+	if started {
+		h.annotateCancel()
+		close(h.annotateQueue)
+	}
+	h.mu.Unlock()
+	if started {
+		<-h.annotateDone
+	}
+}
+
+func (h *Host) startAnnotateWorker() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.annotateQueue = make(chan annotateJob, annotateQueueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	h.annotateCancel = cancel
+	h.annotateDone = make(chan struct{})
+	go h.runAnnotateWorker(ctx, h.annotateQueue)
+}
+
+func (h *Host) runAnnotateWorker(ctx context.Context, queue <-chan annotateJob) {
+	defer close(h.annotateDone)
+	for job := range queue {
+		if ctx.Err() != nil {
+			continue
+		}
+		h.AnnotateInbound(ctx, job.msg, job.store)
+	}
+}
+
+// RecordError notes a failure the caller hit while applying a plugin's
+// result, such as an annotation the store refused.
+func (h *Host) RecordError(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if p := h.findLocked(name); p != nil {
+		p.lastError = err.Error()
+		p.lastErrorAt = time.Now().UTC()
+	}
+}
+
+func (h *Host) describe(ctx context.Context, name string) {
+	h.mu.RLock()
+	p := h.findLocked(name)
+	if p == nil {
+		h.mu.RUnlock()
+		return
+	}
+	t := p.target()
+	h.mu.RUnlock()
+
+	resp, err := h.call(ctx, t, request{Method: MethodDescribe})
+	if err != nil {
+		return
+	}
+	declared := make([]string, 0, len(resp.Capabilities))
+	for _, capability := range resp.Capabilities {
+		if slices.Contains(knownCapabilities, capability) && !slices.Contains(declared, capability) {
+			declared = append(declared, capability)
+		}
+	}
+	h.mu.Lock()
+	p.described = true
+	p.version = strings.TrimSpace(resp.Version)
+	p.declared = declared
+	h.mu.Unlock()
+}
+
+// targets snapshots the enabled, described plugins holding any of caps.
+func (h *Host) targets(caps ...string) []target {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var out []target
+	for _, p := range h.plugins {
+		if !p.enabled || !p.described {
+			continue
+		}
+		t := p.target()
+		if slices.ContainsFunc(caps, func(c string) bool { return slices.Contains(t.caps, c) }) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// call runs one request against a plugin and records any failure.
+func (h *Host) call(ctx context.Context, t target, req request) (response, error) {
+	resp, err := h.run(ctx, t, req)
+	if err != nil {
+		h.RecordError(t.name, err)
+	}
+	return resp, err
+}
+
+func (h *Host) run(ctx context.Context, t target, req request) (response, error) {
+	req.Protocol = ProtocolVersion
+	line, err := json.Marshal(req)
+	if err != nil {
+		return response{}, err
+	}
+	dir, err := h.pluginDir(t.name)
+	if err != nil {
+		return response{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, t.command, t.args...)
+	cmd.Env = []string{fmt.Sprintf("AIM_PLUGIN_PROTOCOL=%d", ProtocolVersion)}
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(append(line, '\n'))
+	stdout := &limitedBuffer{max: maxResponseBytes}
+	cmd.Stdout = stdout
+	cmd.WaitDelay = waitDelay
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return response{}, fmt.Errorf("%s %s: timed out after %s", req.Method, t.name, t.timeout)
+		}
+		return response{}, fmt.Errorf("%s %s: %w", req.Method, t.name, err)
+	}
+
+	first, err := bufio.NewReader(&stdout.buf).ReadBytes('\n')
+	if err != nil && len(first) == 0 {
+		return response{}, fmt.Errorf("%s %s: empty response", req.Method, t.name)
+	}
+	var resp response
+	if err := json.Unmarshal(first, &resp); err != nil {
+		return response{}, fmt.Errorf("%s %s: malformed response: %w", req.Method, t.name, err)
+	}
+	if msg := strings.TrimSpace(resp.Error); msg != "" {
+		return response{}, fmt.Errorf("%s %s: %s", req.Method, t.name, msg)
+	}
+	return resp, nil
+}
+
+// pluginDir returns the plugin's private working directory, or the system
+// temp directory when the host has none.
+func (h *Host) pluginDir(name string) (string, error) {
+	if h.workDir == "" {
+		return os.TempDir(), nil
+	}
+	dir := filepath.Join(h.workDir, name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func (h *Host) findLocked(name string) *plugin {
+	name = strings.TrimSpace(name)
+	for _, p := range h.plugins {
+		if p.cfg.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (h *Host) loadState() (map[string]bool, error) {
+	if h.statePath == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(h.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state struct {
+		Enabled map[string]bool `json:"enabled"`
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+	return state.Enabled, nil
+}
+
+func (h *Host) saveStateLocked() error {
+	if h.statePath == "" {
+		return nil
+	}
+	enabled := make(map[string]bool, len(h.plugins))
+	for _, p := range h.plugins {
+		enabled[p.cfg.Name] = p.enabled
+	}
+	raw, err := json.Marshal(map[string]any{"enabled": enabled})
+	if err != nil {
+		return err
+	}
+	release, err := securestore.BeginWrite("")
+	if err != nil {
+		return err
+	}
+	defer release()
+	return os.WriteFile(h.statePath, raw, 0o600)
+}
+
+// effectiveCaps is what the plugin declared, narrowed to what the config
+// grants.
+func (p *plugin) effectiveCaps() []string {
+	if len(p.cfg.Capabilities) == 0 {
+		return slices.Clone(p.declared)
+	}
+	out := make([]string, 0, len(p.declared))
+	for _, capability := range p.declared {
+		if slices.Contains(p.cfg.Capabilities, capability) {
+			out = append(out, capability)
+		}
+	}
+	return out
+}
+
+func (p *plugin) target() target {
+	return target{
+		name:    p.cfg.Name,
+		command: p.cfg.Command,
+		args:    slices.Clone(p.cfg.Args),
+		timeout: p.timeout,
+		caps:    p.effectiveCaps(),
+	}
+}
+
+func (p *plugin) info() models.PluginInfo {
+	return models.PluginInfo{
+		Name:         p.cfg.Name,
+		Version:      p.version,
+		Capabilities: p.effectiveCaps(),
+		Enabled:      p.enabled,
+		TimeoutMS:    p.timeout.Milliseconds(),
+		LastError:    p.lastError,
+		LastErrorAt:  p.lastErrorAt,
+	}
+}
+
+// limitedBuffer fails writes past max, which makes a plugin flooding stdout
+// fail instead of exhausting memory.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		return 0, errors.New("plugin response too large")
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,267 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginHelperProcess is the plugin the tests start: the test binary
+// re-run with the behaviour after "--". It only acts when started by a host.
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("AIM_PLUGIN_PROTOCOL") == "" {
+		return
+	}
+	mode := os.Args[len(os.Args)-1]
+	var req request
+	line, _ := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	_ = json.Unmarshal(line, &req)
+	reply := func(v any) {
+		raw, _ := json.Marshal(v)
+		fmt.Println(string(raw))
+		os.Exit(0)
+	}
+	if req.Method == MethodDescribe {
+		caps := map[string][]string{
+			"upper":    {CapabilityOutboundTransform},
+			"dlp":      {CapabilityOutboundTransform},
+			"observer": {CapabilityOutboundObserve},
+			"tagger":   {CapabilityInboundAnnotate, CapabilityOutboundTransform},
+			"slow":     {CapabilityOutboundTransform},
+			"slowtag":  {CapabilityInboundAnnotate},
+		}[mode]
+		reply(map[string]any{"version": "1.0", "capabilities": append(caps, "shell.exec")})
+	}
+	switch mode {
+	case "upper":
+		reply(map[string]any{"content": strings.ToUpper(req.Draft.Content)})
+	case "dlp":
+		if strings.Contains(req.Draft.Content, "SECRET") {
+			reply(map[string]any{"reject": "contains a secret"})
+		}
+		reply(map[string]any{})
+	case "observer":
+		reply(map[string]any{"content": "observers cannot rewrite"})
+	case "tagger":
+		if req.Method == MethodInboundMessage {
+			reply(map[string]any{"annotations": map[string]string{"lang": "en", "from": req.Message.SenderID}})
+		}
+		reply(map[string]any{"content": "rewritten"})
+	case "slow", "slowtag":
+		time.Sleep(5 * time.Second)
+	}
+	os.Exit(1)
+}
+
+func helperConfig(t *testing.T, name, mode string) Config {
+	t.Helper()
+	exe, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		t.Fatalf("resolve test binary: %v", err)
+	}
+	return Config{Name: name, Command: exe, Args: []string{"-test.run=^TestPluginHelperProcess$", "--", mode}}
+}
+
+func TestHostTransformsDraftsInOrderAndHonoursCapabilities(t *testing.T) {
+	dlp := helperConfig(t, "dlp", "dlp")
+	tagger := helperConfig(t, "tagger", "tagger")
+	tagger.Capabilities = []string{CapabilityInboundAnnotate}
+	host, err := NewHost([]Config{
+		helperConfig(t, "upper", "upper"),
+		dlp,
+		helperConfig(t, "observer", "observer"),
+		tagger,
+	}, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("new host: %v", err)
+	}
+	ctx := context.Background()
+	host.Start(ctx)
+
+	for _, info := range host.List() {
+		if info.Version != "1.0" || len(info.Capabilities) != 1 {
+			t.Fatalf("expected one effective capability per plugin, got %+v", info)
+		}
+	}
+	content, err := host.ProcessOutbound(ctx, Draft{ConversationID: "c1", Content: "hello"})
+	if err != nil || content != "HELLO" {
+		t.Fatalf("expected only the transformer to rewrite, got %q %v", content, err)
+	}
+	if _, err := host.ProcessOutbound(ctx, Draft{ConversationID: "c1", Content: "my secret"}); !errors.Is(err, ErrDraftRejected) {
+		t.Fatalf("expected DLP rejection of the transformed draft, got %v", err)
+	}
+	notes := map[string]map[string]string{}
+	host.AnnotateInbound(ctx, Message{MessageID: "m1", SenderID: "bob", Content: "hi"}, func(plugin string, annotations map[string]string) {
+		notes[plugin] = annotations
+	})
+	if len(notes) != 1 || notes["tagger"]["lang"] != "en" || notes["tagger"]["from"] != "bob" {
+		t.Fatalf("unexpected annotations: %+v", notes)
+	}
+}
+
+func TestHostTimeoutFailsOpenAndEnableStatePersists(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "plugins-state.json")
+	slow := helperConfig(t, "slow", "slow")
+	slow.TimeoutMS = 1000
+	configs := []Config{slow, helperConfig(t, "upper", "upper")}
+	host, err := NewHost(configs, dir, statePath)
+	if err != nil {
+		t.Fatalf("new host: %v", err)
+	}
+	ctx := context.Background()
+	host.Start(ctx)
+	started := time.Now()
+	if content, err := host.ProcessOutbound(ctx, Draft{Content: "hello"}); err != nil || content != "HELLO" {
+		t.Fatalf("expected the slow plugin to be skipped, got %q %v", content, err)
+	}
+	if elapsed := time.Since(started); elapsed > 4*time.Second {
+		t.Fatalf("expected the slow plugin to be killed at its timeout, took %s", elapsed)
+	}
+	if infos := host.List(); !strings.Contains(infos[0].LastError, "timed out") || infos[1].LastError != "" {
+		t.Fatalf("expected only the slow plugin to record a timeout, got %+v", infos)
+	}
+
+	if info, err := host.SetEnabled(ctx, "upper", false); err != nil || info.Enabled {
+		t.Fatalf("disable plugin: %+v %v", info, err)
+	}
+	if content, _ := host.ProcessOutbound(ctx, Draft{Content: "hello"}); content != "hello" {
+		t.Fatalf("expected disabled plugin to be skipped, got %q", content)
+	}
+	if _, err := host.SetEnabled(ctx, "missing", true); !errors.Is(err, ErrPluginNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	reloaded, err := NewHost(configs, dir, statePath)
+	if err != nil {
+		t.Fatalf("reload host: %v", err)
+	}
+	if infos := reloaded.List(); !infos[0].Enabled || infos[1].Enabled {
+		t.Fatalf("expected enable state to survive a restart, got %+v", infos)
+	}
+}
+
+func TestHostOutboundPluginsShareOneBudget(t *testing.T) {
+	var configs []Config
+	for _, name := range []string{"slow-a", "slow-b", "slow-c"} {
+		slow := helperConfig(t, name, "slow")
+		slow.TimeoutMS = 3000
+		configs = append(configs, slow)
+	}
+	configs = append(configs, helperConfig(t, "upper", "upper"))
+	host, err := NewHost(configs, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("new host: %v", err)
+	}
+	host.outboundTimeout = 1500 * time.Millisecond
+	ctx := context.Background()
+	host.Start(ctx)
+
+	started := time.Now()
+	if content, err := host.ProcessOutbound(ctx, Draft{Content: "hello"}); err != nil || content != "hello" {
+		t.Fatalf("expected the draft to go out unchanged once the budget ran out, got %q %v", content, err)
+	}
+	if elapsed := time.Since(started); elapsed > 2500*time.Millisecond {
+		t.Fatalf("outbound plugins exceeded their budget, took %s", elapsed)
+	}
+	var failed []string
+	for _, info := range host.List() {
+		if info.LastError != "" {
+			failed = append(failed, info.Name)
+		}
+	}
+	if len(failed) != 1 || failed[0] != "slow-a" {
+		t.Fatalf("expected only the first slow plugin to run out of time, got %v", failed)
+	}
+}
+
+func TestHostQueuedAnnotationRunsOffCallerWithinOneBudget(t *testing.T) {
+	configs := []Config{helperConfig(t, "tagger", "tagger")}
+	for _, name := range []string{"slow-a", "slow-b", "slow-c"} {
+		slow := helperConfig(t, name, "slowtag")
+		slow.TimeoutMS = 3000
+		configs = append(configs, slow)
+	}
+	host, err := NewHost(configs, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("new host: %v", err)
+	}
+	host.annotateTimeout = 1500 * time.Millisecond
+	host.Start(context.Background())
+
+	type stored struct {
+		plugin      string
+		annotations map[string]string
+	}
+	results := make(chan stored, len(configs))
+	started := time.Now()
+	if !host.QueueInboundAnnotation(Message{MessageID: "m1", SenderID: "bob"}, func(plugin string, annotations map[string]string) {
+		results <- stored{plugin: plugin, annotations: annotations}
+	}) {
+		t.Fatal("expected the message to be queued")
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("queueing must not wait for plugins, took %s", elapsed)
+	}
+	select {
+	case got := <-results:
+		if got.plugin != "tagger" || got.annotations["from"] != "bob" {
+			t.Fatalf("unexpected annotations: %+v", got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the fast plugin's annotations before the slow ones finish")
+	}
+
+	// One budget covers the whole message: the first slow plugin spends it
+	// and the rest are skipped rather than each getting its own timeout.
+	failedPlugins := func() []string {
+		var failed []string
+		for _, info := range host.List() {
+			if info.LastError != "" {
+				failed = append(failed, info.Name)
+			}
+		}
+		return failed
+	}
+	deadline := time.Now().Add(4 * time.Second)
+	for len(failedPlugins()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(500 * time.Millisecond)
+	failed := failedPlugins()
+	if len(failed) != 1 || failed[0] != "slow-a" {
+		t.Fatalf("expected only the first slow plugin to run out of time, got %v", failed)
+	}
+	if elapsed := time.Since(started); elapsed > 4*time.Second {
+		t.Fatalf("message annotation exceeded its budget, took %s", elapsed)
+	}
+
+	host.Close()
+	if host.QueueInboundAnnotation(Message{MessageID: "m2"}, func(string, map[string]string) {}) {
+		t.Fatal("a closed host must not queue messages")
+	}
+}
+
+func TestNewHostRejectsInvalidConfig(t *testing.T) {
+	valid := helperConfig(t, "upper", "upper")
+	for name, cfg := range map[string]Config{
+		"bad name":         {Name: "Upper Case", Command: valid.Command},
+		"relative command": {Name: "rel", Command: "plugin"},
+		"long timeout":     {Name: "slow", Command: valid.Command, TimeoutMS: MaxTimeout.Milliseconds() + 1},
+		"unknown grant":    {Name: "grant", Command: valid.Command, Capabilities: []string{"shell.exec"}},
+	} {
+		if _, err := NewHost([]Config{cfg}, "", ""); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("%s: expected invalid config, got %v", name, err)
+		}
+	}
+	if _, err := NewHost([]Config{valid, valid}, "", ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected duplicate names to be rejected, got %v", err)
+	}
+}
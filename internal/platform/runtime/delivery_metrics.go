@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+// DeliverySampleWindow bounds the latency samples kept per contact and
+// receipt kind, so old behaviour ages out of the percentiles.
+const DeliverySampleWindow = 256
+
+type deliveryMetricState struct {
+	sentTotal      int
+	deliveredTotal int
+	readTotal      int
+	failedTotal    int
+	delivered      latencyWindow
+	read           latencyWindow
+	lastReceiptAt  time.Time
+}
+
+type latencyWindow struct {
+	samples []int64
+	next    int
+}
+
+func (w *latencyWindow) add(latencyMs int64) {
+	if len(w.samples) < DeliverySampleWindow {
+		w.samples = append(w.samples, latencyMs)
+		return
+	}
+	w.samples[w.next] = latencyMs
+	w.next = (w.next + 1) % DeliverySampleWindow
+}
+
+func (m *ServiceMetricsState) deliveryLocked(contactID string) *deliveryMetricState {
+	if m.delivery == nil {
+		m.delivery = map[string]*deliveryMetricState{}
+	}
+	state, ok := m.delivery[contactID]
+	if !ok {
+		state = &deliveryMetricState{}
+		m.delivery[contactID] = state
+	}
+	return state
+}
+
+// RecordDeliverySent counts an outbound message that left the device.
+func (m *ServiceMetricsState) RecordDeliverySent(contactID string) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveryLocked(contactID).sentTotal++
+	m.lastUpdatedAt = time.Now().UTC()
+}
+
+// RecordDeliveryFailed counts an outbound message that gave up retrying.
+func (m *ServiceMetricsState) RecordDeliveryFailed(contactID string) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveryLocked(contactID).failedTotal++
+	m.lastUpdatedAt = time.Now().UTC()
+}
+
+// RecordDeliveryReceipt records the time between sending a message and its
+// first "delivered" or "read" receipt.
+func (m *ServiceMetricsState) RecordDeliveryReceipt(contactID, status string, latency time.Duration) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return
+	}
+	if latency < 0 {
+		latency = 0
+	}
+	latencyMs := latency.Milliseconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := m.deliveryLocked(contactID)
+	switch status {
+	case "delivered":
+		state.deliveredTotal++
+		state.delivered.add(latencyMs)
+	case "read":
+		state.readTotal++
+		state.read.add(latencyMs)
+	default:
+		return
+	}
+	now := time.Now().UTC()
+	state.lastReceiptAt = now
+	m.lastUpdatedAt = now
+}
+
+// ContactDeliveryStats returns the delivery statistics of one contact. The
+// pending backlog lives in the message store and is filled in by callers.
+func (m *ServiceMetricsState) ContactDeliveryStats(contactID string) models.ContactDeliveryStats {
+	contactID = strings.TrimSpace(contactID)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := models.ContactDeliveryStats{ContactID: contactID}
+	state, ok := m.delivery[contactID]
+	if !ok {
+		return out
+	}
+	out.SentTotal = state.sentTotal
+	out.DeliveredTotal = state.deliveredTotal
+	out.ReadTotal = state.readTotal
+	out.FailedTotal = state.failedTotal
+	out.FailureRateBps = failureRateBps(state.failedTotal, state.sentTotal)
+	out.Delivered = summarizeLatencies(state.delivered.samples)
+	out.Read = summarizeLatencies(state.read.samples)
+	out.LastReceiptAt = state.lastReceiptAt
+	return out
+}
+
+// DeliveryContacts lists the contacts with recorded delivery activity.
+func (m *ServiceMetricsState) DeliveryContacts() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]string, 0, len(m.delivery))
+	for contactID := range m.delivery {
+		out = append(out, contactID)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DeliveryLatencySummary aggregates percentiles over the sample windows of
+// all contacts.
+func (m *ServiceMetricsState) DeliveryLatencySummary() models.DeliveryLatencyMetric {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := models.DeliveryLatencyMetric{Contacts: len(m.delivery)}
+	delivered := make([]int64, 0)
+	read := make([]int64, 0)
+	for _, state := range m.delivery {
+		out.SentTotal += state.sentTotal
+		out.FailedTotal += state.failedTotal
+		delivered = append(delivered, state.delivered.samples...)
+		read = append(read, state.read.samples...)
+	}
+	out.FailureRateBps = failureRateBps(out.FailedTotal, out.SentTotal)
+	out.Delivered = summarizeLatencies(delivered)
+	out.Read = summarizeLatencies(read)
+	return out
+}
+
+func failureRateBps(failed, sent int) int64 {
+	attempts := failed + sent
+	if attempts == 0 {
+		return 0
+	}
+	return int64(failed*10000) / int64(attempts)
+}
+
+func summarizeLatencies(samples []int64) models.LatencyPercentiles {
+	if len(samples) == 0 {
+		return models.LatencyPercentiles{}
+	}
+	sorted := append([]int64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	total := int64(0)
+	for _, v := range sorted {
+		total += v
+	}
+	return models.LatencyPercentiles{
+		Samples: len(sorted),
+		AvgMs:   total / int64(len(sorted)),
+		P50Ms:   percentile(sorted, 50),
+		P90Ms:   percentile(sorted, 90),
+		P99Ms:   percentile(sorted, 99),
+		MaxMs:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile uses the nearest-rank method on an ascending slice.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
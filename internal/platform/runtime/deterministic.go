@@ -0,0 +1,56 @@
+package runtime
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SequentialIDGenerator mints ids shaped like GeneratePrefixedID's, but
+// numbered in call order instead of random. It is meant for tests and
+// simulation runs whose output must not differ between runs.
+type SequentialIDGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+func NewSequentialIDGenerator() *SequentialIDGenerator {
+	return &SequentialIDGenerator{}
+}
+
+// GeneratePrefixedID has the signature of the package-level function so it
+// can be injected in its place.
+func (g *SequentialIDGenerator) GeneratePrefixedID(prefix string) (string, error) {
+	g.mu.Lock()
+	g.next++
+	n := g.next
+	g.mu.Unlock()
+	return fmt.Sprintf("%s_%024x", prefix, n), nil
+}
+
+// SteppingClock is a deterministic clock that starts at a fixed instant and
+// moves forward by step on every reading.
+type SteppingClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func NewSteppingClock(start time.Time, step time.Duration) *SteppingClock {
+	return &SteppingClock{now: start, step: step}
+}
+
+func (c *SteppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+// Advance moves the clock forward by d without taking a reading.
+func (c *SteppingClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
@@ -5,12 +5,13 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"log/slog"
-	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/internal/platform/logsink"
 	"aim-chat/go-backend/pkg/models"
 )
 
@@ -22,6 +23,24 @@ type OpMetric struct {
 	TotalNs int64
 	MaxNs   int64
 	LastNs  int64
+	// Buckets counts latencies per OpLatencyBuckets bound; the extra last
+	// slot holds those above the largest bound.
+	Buckets []uint64
+}
+
+// OpLatencyBuckets are the upper bounds of the operation latency histogram.
+var OpLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
 }
 
 type NotificationEvent = contracts.NotificationEvent
@@ -189,6 +208,14 @@ func (r *ServiceRuntime) CurrentNetworkContext() (context.Context, bool) {
 	return r.NetworkCtx, true
 }
 
+// LastNetworkStatus returns the status recorded by the last update and
+// whether one was recorded yet.
+func (r *ServiceRuntime) LastNetworkStatus() (models.NetworkStatus, bool) {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	return r.LastNetwork, r.NetworkStateSet
+}
+
 func (r *ServiceRuntime) UpdateLastNetworkStatus(current models.NetworkStatus, force bool) bool {
 	r.Mu.Lock()
 	defer r.Mu.Unlock()
@@ -202,8 +229,10 @@ func (r *ServiceRuntime) UpdateLastNetworkStatus(current models.NetworkStatus, f
 	return force || changed
 }
 
+// DefaultLogger logs through the process-wide sink router, so sinks and
+// levels follow the AIM_LOG_* configuration and its reloads.
 func DefaultLogger() *slog.Logger {
-	return slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	return slog.New(logsink.Default())
 }
 
 func GeneratePrefixedID(prefix string) (string, error) {
@@ -218,9 +247,13 @@ type ServiceMetricsState struct {
 	mu                sync.RWMutex
 	errorCounters     map[string]int
 	groupCounters     map[string]int
+	inboundLimitHits  map[string]int
 	gcEvictionByClass map[string]int
+	storageIntegrity  map[string]int
+	blobAnnounce      map[string]int
 	opMetrics         map[string]*OpMetric
 	blobFetchMetric   blobFetchMetricState
+	delivery          map[string]*deliveryMetricState
 	retryAttempts     int
 	lastUpdatedAt     time.Time
 }
@@ -257,7 +290,11 @@ func NewServiceMetricsState() *ServiceMetricsState {
 			"image": 0,
 			"file":  0,
 		},
-		opMetrics: map[string]*OpMetric{},
+		inboundLimitHits: map[string]int{},
+		storageIntegrity: map[string]int{},
+		blobAnnounce:     map[string]int{},
+		opMetrics:        map[string]*OpMetric{},
+		delivery:         map[string]*deliveryMetricState{},
 		blobFetchMetric: blobFetchMetricState{
 			unavailableReasons: map[string]int{},
 		},
@@ -345,6 +382,66 @@ func (m *ServiceMetricsState) RecordGroupAggregate(name string) {
 	m.mu.Unlock()
 }
 
+func (m *ServiceMetricsState) RecordInboundLimitViolation(reason string) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return
+	}
+	m.mu.Lock()
+	m.inboundLimitHits[reason] = m.inboundLimitHits[reason] + 1
+	m.lastUpdatedAt = time.Now().UTC()
+	m.mu.Unlock()
+}
+
+func (m *ServiceMetricsState) InboundLimitViolations() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]int, len(m.inboundLimitHits))
+	for k, v := range m.inboundLimitHits {
+		out[k] = v
+	}
+	return out
+}
+
+// RecordStorageScrub counts one integrity pass over the stores.
+func (m *ServiceMetricsState) RecordStorageScrub(records, corrupt, restored int) {
+	m.mu.Lock()
+	m.storageIntegrity["scrub_runs"]++
+	m.storageIntegrity["records_checked"] += records
+	m.storageIntegrity["corrupt_records"] += corrupt
+	m.storageIntegrity["restored_records"] += restored
+	m.lastUpdatedAt = time.Now().UTC()
+	m.mu.Unlock()
+}
+
+func (m *ServiceMetricsState) StorageIntegrity() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]int, len(m.storageIntegrity))
+	for k, v := range m.storageIntegrity {
+		out[k] = v
+	}
+	return out
+}
+
+// RecordBlobAnnouncements counts provider announcements by outcome.
+func (m *ServiceMetricsState) RecordBlobAnnouncements(kind string, n int) {
+	m.mu.Lock()
+	m.blobAnnounce[kind] += n
+	m.lastUpdatedAt = time.Now().UTC()
+	m.mu.Unlock()
+}
+
+func (m *ServiceMetricsState) BlobAnnouncements() map[string]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]int, len(m.blobAnnounce))
+	for k, v := range m.blobAnnounce {
+		out[k] = v
+	}
+	return out
+}
+
 func (m *ServiceMetricsState) RecordGCEvictions(evictedByClass map[string]int) {
 	if len(evictedByClass) == 0 {
 		return
@@ -376,12 +473,45 @@ func (m *ServiceMetricsState) RecordOp(operation string, started time.Time) {
 	metric.Count++
 	metric.TotalNs += latency
 	metric.LastNs = latency
+	if metric.Buckets == nil {
+		metric.Buckets = make([]uint64, len(OpLatencyBuckets)+1)
+	}
+	metric.Buckets[sort.Search(len(OpLatencyBuckets), func(i int) bool {
+		return time.Duration(latency) <= OpLatencyBuckets[i]
+	})]++
 	if latency > metric.MaxNs {
 		metric.MaxNs = latency
 	}
 	m.lastUpdatedAt = time.Now().UTC()
 }
 
+// OperationHistograms returns the latency histogram of every operation that
+// completed at least once.
+func (m *ServiceMetricsState) OperationHistograms() map[string]models.LatencyHistogram {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]models.LatencyHistogram, len(m.opMetrics))
+	for name, metric := range m.opMetrics {
+		if metric.Buckets == nil {
+			continue
+		}
+		hist := models.LatencyHistogram{
+			Count:            uint64(metric.Count),
+			SumSeconds:       time.Duration(metric.TotalNs).Seconds(),
+			UpperBounds:      make([]float64, len(OpLatencyBuckets)),
+			CumulativeCounts: make([]uint64, len(OpLatencyBuckets)),
+		}
+		var cumulative uint64
+		for i, bound := range OpLatencyBuckets {
+			cumulative += metric.Buckets[i]
+			hist.UpperBounds[i] = bound.Seconds()
+			hist.CumulativeCounts[i] = cumulative
+		}
+		out[name] = hist
+	}
+	return out
+}
+
 func (m *ServiceMetricsState) RecordOpError(operation string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
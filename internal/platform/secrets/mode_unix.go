@@ -0,0 +1,12 @@
+//go:build !windows
+
+package secrets
+
+import "io/fs"
+
+func checkFileMode(mode fs.FileMode) error {
+	if mode.Perm()&0o077 != 0 {
+		return ErrInsecureFile
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package secrets resolves secret references so tokens and keys do not have
+// to be passed in plain text on the command line or in the environment.
+//
+// A value starting with "secret://" names a provider:
+//
+//	secret://file/<path>              whole file, trimmed
+//	secret://env-file/<path>#<KEY>    KEY=VALUE entry of a dotenv-style file
+//	secret://exec/<command> [args]    stdout of an external command, e.g. "pass show aim/rpc"
+//
+// Files must not be readable by group or others. Any other value is returned
+// unchanged, so plain secrets keep working.
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	Scheme      = "secret://"
+	ExecTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidReference = errors.New("invalid secret reference")
+	ErrInsecureFile     = errors.New("secret file is accessible by other users")
+	ErrEmptySecret      = errors.New("secret is empty")
+)
+
+// IsReference reports whether value is a secret reference.
+func IsReference(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), Scheme)
+}
+
+// Resolve returns the secret value refers to.
+func Resolve(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, Scheme) {
+		return value, nil
+	}
+	provider, target, ok := strings.Cut(strings.TrimPrefix(value, Scheme), "/")
+	if !ok || strings.TrimSpace(target) == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidReference, redact(value))
+	}
+	var (
+		secret string
+		err    error
+	)
+	switch provider {
+	case "file":
+		secret, err = readFileSecret(target)
+	case "env-file":
+		secret, err = readEnvFileSecret(target)
+	case "exec":
+		secret, err = runSecretCommand(target)
+	default:
+		return "", fmt.Errorf("%w: unknown provider %q", ErrInvalidReference, provider)
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", redact(value), err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("resolve %s: %w", redact(value), ErrEmptySecret)
+	}
+	return secret, nil
+}
+
+// ResolveEnv resolves the value of an environment variable.
+func ResolveEnv(name string) (string, error) {
+	value, err := Resolve(os.Getenv(name))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return value, nil
+}
+
+func readFileSecret(path string) (string, error) {
+	raw, err := readStrictFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+func readEnvFileSecret(target string) (string, error) {
+	path, key, ok := strings.Cut(target, "#")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", fmt.Errorf("%w: env-file reference needs #KEY", ErrInvalidReference)
+	}
+	raw, err := readStrictFile(path)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(name) != key {
+			continue
+		}
+		return unquote(strings.TrimSpace(value)), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%w: key %q is not set", ErrEmptySecret, key)
+}
+
+func runSecretCommand(commandLine string) (string, error) {
+	args := strings.Fields(commandLine)
+	if len(args) == 0 {
+		return "", fmt.Errorf("%w: empty command", ErrInvalidReference)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ExecTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	// Password managers print the secret on the first line.
+	first, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(first), nil
+}
+
+func readStrictFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	if err := checkFileMode(info.Mode()); err != nil {
+		return nil, fmt.Errorf("%w: %s has mode %04o", err, path, info.Mode().Perm())
+	}
+	return os.ReadFile(path)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// redact hides exec command lines, whose arguments may themselves be
+// sensitive. File locations are kept to make errors actionable.
+func redact(value string) string {
+	provider, _, _ := strings.Cut(strings.TrimPrefix(value, Scheme), "/")
+	if provider == "exec" {
+		return Scheme + "exec/…"
+	}
+	return value
+}
@@ -0,0 +1,100 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeSecretFile(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatalf("chmod secret: %v", err)
+	}
+	return path
+}
+
+func TestResolvePassesPlainValuesThrough(t *testing.T) {
+	got, err := Resolve("  plain-token ")
+	if err != nil || got != "plain-token" {
+		t.Fatalf("unexpected resolve: %q err=%v", got, err)
+	}
+}
+
+func TestResolveFileReference(t *testing.T) {
+	path := writeSecretFile(t, "file-token\n", 0o600)
+	got, err := Resolve(Scheme + "file/" + path)
+	if err != nil || got != "file-token" {
+		t.Fatalf("unexpected resolve: %q err=%v", got, err)
+	}
+}
+
+func TestResolveRejectsGroupReadableFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+	path := writeSecretFile(t, "file-token", 0o644)
+	if _, err := Resolve(Scheme + "file/" + path); !errors.Is(err, ErrInsecureFile) {
+		t.Fatalf("expected ErrInsecureFile, got %v", err)
+	}
+}
+
+func TestResolveEnvFileReference(t *testing.T) {
+	path := writeSecretFile(t, "# rpc\nexport AIM_RPC_TOKEN=\"quoted-token\"\nOTHER=x\n", 0o600)
+	got, err := Resolve(Scheme + "env-file/" + path + "#AIM_RPC_TOKEN")
+	if err != nil || got != "quoted-token" {
+		t.Fatalf("unexpected resolve: %q err=%v", got, err)
+	}
+	if _, err := Resolve(Scheme + "env-file/" + path + "#MISSING"); !errors.Is(err, ErrEmptySecret) {
+		t.Fatalf("expected ErrEmptySecret, got %v", err)
+	}
+	if _, err := Resolve(Scheme + "env-file/" + path); !errors.Is(err, ErrInvalidReference) {
+		t.Fatalf("expected ErrInvalidReference, got %v", err)
+	}
+}
+
+func TestResolveExecReferenceUsesFirstLineAndRedactsErrors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on a posix shell")
+	}
+	got, err := Resolve(Scheme + "exec/printf exec-token\\nsecond-line")
+	if err != nil || got != "exec-token" {
+		t.Fatalf("unexpected resolve: %q err=%v", got, err)
+	}
+	_, err = Resolve(Scheme + "exec/false hunter2")
+	if err == nil {
+		t.Fatal("expected failing command error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("command line leaked into error: %v", err)
+	}
+}
+
+func TestResolveRejectsUnknownProvider(t *testing.T) {
+	if _, err := Resolve(Scheme + "vault/path"); !errors.Is(err, ErrInvalidReference) {
+		t.Fatalf("expected ErrInvalidReference, got %v", err)
+	}
+	if _, err := Resolve(Scheme + "file"); !errors.Is(err, ErrInvalidReference) {
+		t.Fatalf("expected ErrInvalidReference, got %v", err)
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	path := writeSecretFile(t, "env-token", 0o600)
+	t.Setenv("AIM_TEST_SECRET", Scheme+"file/"+path)
+	got, err := ResolveEnv("AIM_TEST_SECRET")
+	if err != nil || got != "env-token" {
+		t.Fatalf("unexpected resolve: %q err=%v", got, err)
+	}
+	t.Setenv("AIM_TEST_SECRET", Scheme+"file/"+filepath.Join(t.TempDir(), "missing"))
+	if _, err := ResolveEnv("AIM_TEST_SECRET"); err == nil || !strings.HasPrefix(err.Error(), "AIM_TEST_SECRET: ") {
+		t.Fatalf("expected env-prefixed error, got %v", err)
+	}
+}
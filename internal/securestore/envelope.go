@@ -133,3 +133,8 @@ func zeroBytes(b []byte) {
 		b[i] = 0
 	}
 }
+
+// IsEnvelope reports whether data looks like an Encrypt output.
+func IsEnvelope(data []byte) bool {
+	return strings.HasPrefix(string(data), filePrefix)
+}
@@ -0,0 +1,97 @@
+package securestore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// RecordChecksumsField is the store document field holding a checksum for
+// every record, keyed by collection field and then by record key.
+const RecordChecksumsField = "record_checksums"
+
+// AddRecordChecksums adds record checksums to a store document. The records
+// of a document are the entries of its top-level objects and arrays; array
+// entries are keyed by index. Payloads that are not JSON objects are
+// returned unchanged. Only plaintext stores need them: an encrypted
+// envelope already authenticates every record it holds.
+func AddRecordChecksums(payload []byte) ([]byte, error) {
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return payload, nil
+	}
+	delete(doc, RecordChecksumsField)
+	sums := map[string]map[string]string{}
+	for field, records := range DocumentRecords(doc) {
+		fieldSums := make(map[string]string, len(records))
+		for key, raw := range records {
+			fieldSums[key] = RecordChecksum(raw)
+		}
+		sums[field] = fieldSums
+	}
+	if len(sums) == 0 {
+		return json.Marshal(doc)
+	}
+	raw, err := json.Marshal(sums)
+	if err != nil {
+		return nil, err
+	}
+	doc[RecordChecksumsField] = raw
+	return json.Marshal(doc)
+}
+
+// RecordChecksum returns the checksum of one encoded record.
+func RecordChecksum(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:12])
+}
+
+// DocumentRecords splits a decoded store document into its records. Scalar
+// fields and the checksums themselves are not records.
+func DocumentRecords(doc map[string]json.RawMessage) map[string]map[string]json.RawMessage {
+	out := map[string]map[string]json.RawMessage{}
+	for field, raw := range doc {
+		if field == RecordChecksumsField {
+			continue
+		}
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) == 0 {
+			continue
+		}
+		switch trimmed[0] {
+		case '{':
+			records := map[string]json.RawMessage{}
+			if err := json.Unmarshal(trimmed, &records); err != nil {
+				continue
+			}
+			out[field] = records
+		case '[':
+			var items []json.RawMessage
+			if err := json.Unmarshal(trimmed, &items); err != nil {
+				continue
+			}
+			records := make(map[string]json.RawMessage, len(items))
+			for i, item := range items {
+				records[strconv.Itoa(i)] = item
+			}
+			out[field] = records
+		}
+	}
+	return out
+}
+
+// DocumentRecordChecksums returns the checksums stored in a document, or nil
+// when it was written before checksums existed.
+func DocumentRecordChecksums(doc map[string]json.RawMessage) map[string]map[string]string {
+	raw, ok := doc[RecordChecksumsField]
+	if !ok {
+		return nil
+	}
+	var sums map[string]map[string]string
+	if err := json.Unmarshal(raw, &sums); err != nil {
+		return nil
+	}
+	return sums
+}
@@ -32,6 +32,11 @@ func WriteEncryptedJSON(path, secret string, v any) error {
 	if err != nil {
 		return err
 	}
+	release, err := BeginWrite(secret)
+	if err != nil {
+		return err
+	}
+	defer release()
 	encrypted, err := Encrypt(secret, payload)
 	if err != nil {
 		return err
@@ -0,0 +1,87 @@
+package securestore
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSecretRetired is returned to writers whose storage secret was retired by
+// a key rotation. Their store must be reopened with the new secret.
+var ErrSecretRetired = errors.New("securestore secret was retired")
+
+// ErrWritesDiscarded is returned to writers that waited on a hold whose
+// holder replaced the store files. Their store must be reopened.
+var ErrWritesDiscarded = errors.New("securestore write was discarded by a store restore")
+
+// Writers hold writeGate for reading from the secret check until the file is
+// written, so RetireSecret and HoldWrites can wait for writes that are
+// already under way. writeEpoch moves on every discarding hold.
+var (
+	writeGate      sync.RWMutex
+	retiredSecrets = map[string]struct{}{}
+	writeEpoch     atomic.Uint64
+)
+
+// BeginWrite admits a store write. Stores pass the secret they encrypt with,
+// or "" when they write plaintext. The returned release must be called once
+// the file is written. Writes under a retired secret fail, and writes wait
+// while HoldWrites holds the gate.
+func BeginWrite(secret string) (func(), error) {
+	epoch := writeEpoch.Load()
+	writeGate.RLock()
+	if writeEpoch.Load() != epoch {
+		writeGate.RUnlock()
+		return nil, ErrWritesDiscarded
+	}
+	if _, retired := retiredSecrets[secret]; retired {
+		writeGate.RUnlock()
+		return nil, ErrSecretRetired
+	}
+	return writeGate.RUnlock, nil
+}
+
+// WriteHold keeps every store write out, see HoldWrites. It ends with
+// Release or Discard; later calls do nothing.
+type WriteHold struct {
+	once sync.Once
+}
+
+// HoldWrites waits for the store writes under way and keeps new ones waiting
+// until the hold ends, so the store files can be copied or replaced as one
+// consistent set. The holder must not write through a store meanwhile.
+func HoldWrites() *WriteHold {
+	writeGate.Lock()
+	return &WriteHold{}
+}
+
+// Release lets the waiting writes go ahead.
+func (h *WriteHold) Release() {
+	h.once.Do(writeGate.Unlock)
+}
+
+// Discard ends the hold after the store files were replaced. The writes that
+// waited fail with ErrWritesDiscarded instead of overwriting the new files
+// with the state their stores held before.
+func (h *WriteHold) Discard() {
+	h.once.Do(func() {
+		writeEpoch.Add(1)
+		writeGate.Unlock()
+	})
+}
+
+// RetireSecret stops every store from writing with secret and returns once
+// the writes already admitted have finished.
+func RetireSecret(secret string) {
+	writeGate.Lock()
+	defer writeGate.Unlock()
+	retiredSecrets[secret] = struct{}{}
+}
+
+// ReinstateSecret lets stores write with secret again after a rotation that
+// did not commit.
+func ReinstateSecret(secret string) {
+	writeGate.Lock()
+	defer writeGate.Unlock()
+	delete(retiredSecrets, secret)
+}
@@ -0,0 +1,70 @@
+package securestore
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetireSecretWaitsForAdmittedWritesAndRejectsNewOnes(t *testing.T) {
+	const secret = "retire-test-secret"
+	release, err := BeginWrite(secret)
+	if err != nil {
+		t.Fatalf("begin write: %v", err)
+	}
+	retired := make(chan struct{})
+	go func() {
+		RetireSecret(secret)
+		close(retired)
+	}()
+	select {
+	case <-retired:
+		t.Fatal("retire must wait for the admitted write")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	<-retired
+
+	path := filepath.Join(t.TempDir(), "state.enc")
+	if err := WriteEncryptedJSON(path, secret, map[string]string{"k": "v"}); !errors.Is(err, ErrSecretRetired) {
+		t.Fatalf("expected retired secret to be rejected, got %v", err)
+	}
+	if err := WriteEncryptedJSON(path, "other-secret", map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("other secrets must keep writing: %v", err)
+	}
+
+	ReinstateSecret(secret)
+	if err := WriteEncryptedJSON(path, secret, map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("reinstated secret must write again: %v", err)
+	}
+}
+
+func TestHoldWritesBlocksWritersAndDiscardFailsTheWaitingOnes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.enc")
+	hold := HoldWrites()
+	written := make(chan error, 1)
+	go func() { written <- WriteEncryptedJSON(path, "hold-test-secret", map[string]string{"k": "stale"}) }()
+	select {
+	case err := <-written:
+		t.Fatalf("write must wait for the hold, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	hold.Discard()
+	if err := <-written; !errors.Is(err, ErrWritesDiscarded) {
+		t.Fatalf("expected the waiting write to be discarded, got %v", err)
+	}
+
+	hold = HoldWrites()
+	go func() { written <- WriteEncryptedJSON(path, "hold-test-secret", map[string]string{"k": "v"}) }()
+	hold.Release()
+	hold.Discard()
+	if err := <-written; err != nil {
+		t.Fatalf("expected the write to go ahead after release, got %v", err)
+	}
+	if release, err := BeginWrite(""); err != nil {
+		t.Fatalf("new writes must be admitted: %v", err)
+	} else {
+		release()
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AttachmentBlobRef identifies a stored blob. Class and Pinned let object
+// backends lay out keys and tags for bucket lifecycle rules.
+type AttachmentBlobRef struct {
+	ID     string
+	Class  string
+	Pinned bool
+}
+
+// AttachmentBlobBackend holds the blobs of an attachment store. Blobs reach
+// the backend already encrypted; the index stays in the local store dir.
+type AttachmentBlobBackend interface {
+	PutBlob(ref AttachmentBlobRef, data []byte) error
+	// GetBlob returns ErrAttachmentNotFound for blobs the backend lacks.
+	GetBlob(ref AttachmentBlobRef) ([]byte, error)
+	// DeleteBlob succeeds for blobs that are already gone.
+	DeleteBlob(ref AttachmentBlobRef) error
+}
+
+// AttachmentBlobPinner is implemented by backends that must learn about pin
+// changes, so their own expiry never removes a pinned blob.
+type AttachmentBlobPinner interface {
+	SetBlobPinned(ref AttachmentBlobRef) error
+}
+
+// AttachmentLifecycleRule expires unpinned blobs of a class once they are
+// older than ExpireAfter.
+type AttachmentLifecycleRule struct {
+	Class       string
+	ExpireAfter time.Duration
+}
+
+// AttachmentBlobLifecycle is implemented by backends that can expire blobs
+// on their own. An empty rule set removes every expiry.
+type AttachmentBlobLifecycle interface {
+	ApplyBlobLifecycle(rules []AttachmentLifecycleRule) error
+}
+
+// fileBlobBackend keeps blobs next to the index, one file per attachment.
+type fileBlobBackend struct {
+	dir string
+}
+
+func (b fileBlobBackend) PutBlob(ref AttachmentBlobRef, data []byte) error {
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(b.path(ref.ID), data, 0o600)
+}
+
+func (b fileBlobBackend) GetBlob(ref AttachmentBlobRef) ([]byte, error) {
+	data, err := os.ReadFile(b.path(ref.ID))
+	if os.IsNotExist(err) {
+		return nil, ErrAttachmentNotFound
+	}
+	return data, err
+}
+
+func (b fileBlobBackend) DeleteBlob(ref AttachmentBlobRef) error {
+	if err := os.Remove(b.path(ref.ID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (b fileBlobBackend) path(id string) string {
+	return filepath.Join(b.dir, id+".bin")
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrHeldAttachmentsFull = errors.New("too many attachments awaiting approval")
+
+const (
+	attachmentPolicySchemaVersion = 1
+
+	maxHeldAttachments = 1000
+)
+
+// AttachmentPolicyStore keeps per-contact attachment policies and the
+// inbound attachments waiting for approval in an encrypted per-account file.
+type AttachmentPolicyStore struct {
+	mu       sync.RWMutex
+	path     string
+	secret   string
+	policies map[string]models.ContactAttachmentPolicy
+	held     map[string]models.HeldAttachment
+}
+
+type persistedAttachmentPolicies struct {
+	Version  int                              `json:"version"`
+	Policies []models.ContactAttachmentPolicy `json:"policies"`
+	Held     []models.HeldAttachment          `json:"held,omitempty"`
+}
+
+func NewAttachmentPolicyStore() *AttachmentPolicyStore {
+	return &AttachmentPolicyStore{
+		policies: map[string]models.ContactAttachmentPolicy{},
+		held:     map[string]models.HeldAttachment{},
+	}
+}
+
+func (s *AttachmentPolicyStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *AttachmentPolicyStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = map[string]models.ContactAttachmentPolicy{}
+	s.held = map[string]models.HeldAttachment{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedAttachmentPolicies
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != attachmentPolicySchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, policy := range payload.Policies {
+		if policy.ContactID != "" {
+			s.policies[policy.ContactID] = policy
+		}
+	}
+	for _, entry := range payload.Held {
+		if entry.Attachment.ID != "" {
+			s.held[entry.Attachment.ID] = entry
+		}
+	}
+	return nil
+}
+
+// Get returns the policy of one contact.
+func (s *AttachmentPolicyStore) Get(contactID string) (models.ContactAttachmentPolicy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	policy, ok := s.policies[strings.TrimSpace(contactID)]
+	return policy, ok
+}
+
+// Put stores a contact's policy. With remove set the contact falls back to
+// accepting everything and its entry is dropped.
+func (s *AttachmentPolicyStore) Put(policy models.ContactAttachmentPolicy, remove bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]models.ContactAttachmentPolicy, len(s.policies)+1)
+	for id, existing := range s.policies {
+		next[id] = existing
+	}
+	if remove {
+		delete(next, policy.ContactID)
+	} else {
+		next[policy.ContactID] = policy
+	}
+	if err := s.persistLocked(next, s.held); err != nil {
+		return err
+	}
+	s.policies = next
+	return nil
+}
+
+// Hold records attachments that must not be fetched until approved.
+func (s *AttachmentPolicyStore) Hold(entries []models.HeldAttachment) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := cloneHeldAttachments(s.held)
+	for _, entry := range entries {
+		next[entry.Attachment.ID] = entry
+	}
+	if len(next) > maxHeldAttachments {
+		return fmt.Errorf("%w: %d held", ErrHeldAttachmentsFull, maxHeldAttachments)
+	}
+	if err := s.persistLocked(s.policies, next); err != nil {
+		return err
+	}
+	s.held = next
+	return nil
+}
+
+// IsHeld reports whether an attachment still waits for approval.
+func (s *AttachmentPolicyStore) IsHeld(attachmentID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.held[strings.TrimSpace(attachmentID)]
+	return ok
+}
+
+// Held lists attachments waiting for approval, oldest first. An empty
+// contact id lists them for every contact.
+func (s *AttachmentPolicyStore) Held(contactID string) []models.HeldAttachment {
+	contactID = strings.TrimSpace(contactID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.HeldAttachment, 0, len(s.held))
+	for _, entry := range s.held {
+		if contactID == "" || entry.ContactID == contactID {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].HeldAt.Equal(out[j].HeldAt) {
+			return out[i].HeldAt.Before(out[j].HeldAt)
+		}
+		return out[i].Attachment.ID < out[j].Attachment.ID
+	})
+	return out
+}
+
+// Release removes the hold on an attachment and reports whether there was
+// one.
+func (s *AttachmentPolicyStore) Release(attachmentID string) (models.HeldAttachment, bool, error) {
+	attachmentID = strings.TrimSpace(attachmentID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.held[attachmentID]
+	if !ok {
+		return models.HeldAttachment{}, false, nil
+	}
+	next := cloneHeldAttachments(s.held)
+	delete(next, attachmentID)
+	if err := s.persistLocked(s.policies, next); err != nil {
+		return models.HeldAttachment{}, false, err
+	}
+	s.held = next
+	return entry, true, nil
+}
+
+func (s *AttachmentPolicyStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policies = map[string]models.ContactAttachmentPolicy{}
+	s.held = map[string]models.HeldAttachment{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *AttachmentPolicyStore) persistLocked(policies map[string]models.ContactAttachmentPolicy, held map[string]models.HeldAttachment) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	payload := persistedAttachmentPolicies{
+		Version:  attachmentPolicySchemaVersion,
+		Policies: make([]models.ContactAttachmentPolicy, 0, len(policies)),
+		Held:     make([]models.HeldAttachment, 0, len(held)),
+	}
+	for _, policy := range policies {
+		payload.Policies = append(payload.Policies, policy)
+	}
+	for _, entry := range held {
+		payload.Held = append(payload.Held, entry)
+	}
+	sort.Slice(payload.Policies, func(i, j int) bool { return payload.Policies[i].ContactID < payload.Policies[j].ContactID })
+	sort.Slice(payload.Held, func(i, j int) bool { return payload.Held[i].Attachment.ID < payload.Held[j].Attachment.ID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, payload)
+}
+
+func cloneHeldAttachments(in map[string]models.HeldAttachment) map[string]models.HeldAttachment {
+	out := make(map[string]models.HeldAttachment, len(in))
+	for id, entry := range in {
+		out[id] = entry
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestAttachmentPolicyStorePersistsPoliciesAndHolds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attachment_policies.enc")
+	store := NewAttachmentPolicyStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	policy := models.ContactAttachmentPolicy{ContactID: "aim1peer", Mode: models.AttachmentPolicyImagesOnly, RequireApproval: true}
+	if err := store.Put(policy, false); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	held := models.HeldAttachment{ContactID: "aim1peer", MessageID: "msg-1", Attachment: models.MessageAttachment{ID: "att-1"}, HeldAt: time.Now().UTC()}
+	if err := store.Hold([]models.HeldAttachment{held}); err != nil {
+		t.Fatalf("hold failed: %v", err)
+	}
+
+	reloaded := NewAttachmentPolicyStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	if got, ok := reloaded.Get("aim1peer"); !ok || got.Mode != models.AttachmentPolicyImagesOnly || !got.RequireApproval {
+		t.Fatalf("policy not persisted: %+v", got)
+	}
+	if !reloaded.IsHeld("att-1") || len(reloaded.Held("")) != 1 || len(reloaded.Held("aim1other")) != 0 {
+		t.Fatal("held attachment not persisted")
+	}
+	if _, ok, err := reloaded.Release("att-1"); err != nil || !ok {
+		t.Fatalf("release failed: ok=%v err=%v", ok, err)
+	}
+	if reloaded.IsHeld("att-1") {
+		t.Fatal("released attachment must not stay held")
+	}
+	if err := reloaded.Put(models.ContactAttachmentPolicy{ContactID: "aim1peer"}, true); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if _, ok := reloaded.Get("aim1peer"); ok {
+		t.Fatal("default policy must not be kept")
+	}
+}
+
+func TestAttachmentPolicyStoreCapsHeldAttachments(t *testing.T) {
+	store := NewAttachmentPolicyStore()
+	entries := make([]models.HeldAttachment, 0, maxHeldAttachments+1)
+	for i := 0; i <= maxHeldAttachments; i++ {
+		entries = append(entries, models.HeldAttachment{Attachment: models.MessageAttachment{ID: "att-" + strconv.Itoa(i)}})
+	}
+	if err := store.Hold(entries); !errors.Is(err, ErrHeldAttachmentsFull) {
+		t.Fatalf("expected held attachments cap, got %v", err)
+	}
+	if len(store.Held("")) != 0 {
+		t.Fatal("failed hold must not change state")
+	}
+}
@@ -0,0 +1,488 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultS3PartSize is the multipart part size; blobs up to this size go
+	// up in a single request.
+	DefaultS3PartSize = 8 << 20
+	// MinS3PartSize is the smallest part S3 accepts for all but the last part.
+	MinS3PartSize = 5 << 20
+
+	defaultS3Region  = "us-east-1"
+	defaultS3Timeout = 60 * time.Second
+	s3PinnedTag      = "aim-pinned"
+	s3RuleIDPrefix   = "aim-attachments:"
+	// s3AbortMultipartDays cleans up uploads a crash left unfinished.
+	s3AbortMultipartDays = 1
+)
+
+var ErrInvalidS3Config = errors.New("invalid s3 attachment backend config")
+
+// S3BlobConfig configures an S3-compatible bucket (AWS S3, MinIO, ...).
+// Objects are addressed path-style: Endpoint/Bucket/Prefix<class>/<id>.bin.
+type S3BlobConfig struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Prefix scopes the objects of one store within a shared bucket.
+	Prefix     string
+	PartSize   int64
+	HTTPClient *http.Client
+}
+
+// S3BlobBackend stores attachment blobs in an S3-compatible bucket. Pin
+// states travel as object tags, so bucket lifecycle rules skip pinned blobs.
+type S3BlobBackend struct {
+	endpoint *url.URL
+	cfg      S3BlobConfig
+	client   *http.Client
+	now      func() time.Time
+
+	lifecycleMu sync.Mutex
+	lifecycle   string
+}
+
+func NewS3BlobBackend(cfg S3BlobConfig) (*S3BlobBackend, error) {
+	cfg.Endpoint = strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/")
+	cfg.Bucket = strings.TrimSpace(cfg.Bucket)
+	cfg.Region = strings.TrimSpace(cfg.Region)
+	cfg.Prefix = strings.TrimLeft(strings.TrimSpace(cfg.Prefix), "/")
+	if cfg.Prefix != "" && !strings.HasSuffix(cfg.Prefix, "/") {
+		cfg.Prefix += "/"
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = DefaultS3PartSize
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	switch {
+	case err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https"):
+		return nil, fmt.Errorf("%w: endpoint must be an http(s) url", ErrInvalidS3Config)
+	case cfg.Bucket == "":
+		return nil, fmt.Errorf("%w: bucket is required", ErrInvalidS3Config)
+	case cfg.AccessKey == "" || cfg.SecretKey == "":
+		return nil, fmt.Errorf("%w: access key and secret key are required", ErrInvalidS3Config)
+	case cfg.PartSize < MinS3PartSize:
+		return nil, fmt.Errorf("%w: part size must be at least %d bytes", ErrInvalidS3Config, MinS3PartSize)
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: defaultS3Timeout}
+	}
+	return &S3BlobBackend{endpoint: endpoint, cfg: cfg, client: client, now: time.Now}, nil
+}
+
+func (b *S3BlobBackend) PutBlob(ref AttachmentBlobRef, data []byte) error {
+	if int64(len(data)) <= b.cfg.PartSize {
+		_, err := b.do(http.MethodPut, b.objectKey(ref), nil, data, b.tagHeader(ref))
+		return err
+	}
+	return b.putMultipart(ref, data)
+}
+
+func (b *S3BlobBackend) GetBlob(ref AttachmentBlobRef) ([]byte, error) {
+	body, err := b.do(http.MethodGet, b.objectKey(ref), nil, nil, nil)
+	if isS3NotFound(err) {
+		return nil, ErrAttachmentNotFound
+	}
+	return body, err
+}
+
+func (b *S3BlobBackend) DeleteBlob(ref AttachmentBlobRef) error {
+	if _, err := b.do(http.MethodDelete, b.objectKey(ref), nil, nil, nil); err != nil && !isS3NotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (b *S3BlobBackend) SetBlobPinned(ref AttachmentBlobRef) error {
+	payload, err := xml.Marshal(s3Tagging{Tags: []s3Tag{{Key: s3PinnedTag, Value: strconv.FormatBool(ref.Pinned)}}})
+	if err != nil {
+		return err
+	}
+	_, err = b.do(http.MethodPut, b.objectKey(ref), url.Values{"tagging": {""}}, payload, contentMD5Header(payload))
+	return err
+}
+
+// ApplyBlobLifecycle replaces the rules this store owns in the bucket
+// lifecycle configuration and keeps every other rule as it is. Expiry is
+// rounded up to whole days and only reaches objects tagged as unpinned.
+func (b *S3BlobBackend) ApplyBlobLifecycle(rules []AttachmentLifecycleRule) error {
+	owned := []s3LifecycleRule{newS3LifecycleRule(b.ruleID("multipart"),
+		fmt.Sprintf("<Filter><Prefix>%s</Prefix></Filter><AbortIncompleteMultipartUpload><DaysAfterInitiation>%d</DaysAfterInitiation></AbortIncompleteMultipartUpload>",
+			xmlEscape(b.cfg.Prefix), s3AbortMultipartDays))}
+	for _, rule := range rules {
+		days := int((rule.ExpireAfter + 24*time.Hour - 1) / (24 * time.Hour))
+		if days < 1 {
+			days = 1
+		}
+		owned = append(owned, newS3LifecycleRule(b.ruleID(rule.Class),
+			fmt.Sprintf("<Filter><And><Prefix>%s</Prefix><Tag><Key>%s</Key><Value>false</Value></Tag></And></Filter><Expiration><Days>%d</Days></Expiration>",
+				xmlEscape(b.cfg.Prefix+rule.Class+"/"), s3PinnedTag, days)))
+	}
+	signature := fmt.Sprint(owned)
+
+	b.lifecycleMu.Lock()
+	defer b.lifecycleMu.Unlock()
+	if b.lifecycle == signature {
+		return nil
+	}
+	current, err := b.do(http.MethodGet, "", url.Values{"lifecycle": {""}}, nil, nil)
+	if err != nil && !isS3Code(err, "NoSuchLifecycleConfiguration") && !isS3NotFound(err) {
+		return err
+	}
+	var config s3LifecycleConfig
+	if len(current) > 0 {
+		if err := xml.Unmarshal(current, &config); err != nil {
+			return fmt.Errorf("decode bucket lifecycle: %w", err)
+		}
+	}
+	kept := config.Rules[:0]
+	for _, rule := range config.Rules {
+		if !strings.HasPrefix(rule.id(), b.ruleID("")) {
+			kept = append(kept, rule)
+		}
+	}
+	config.Rules = append(kept, owned...)
+	payload, err := xml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if _, err := b.do(http.MethodPut, "", url.Values{"lifecycle": {""}}, payload, contentMD5Header(payload)); err != nil {
+		return err
+	}
+	b.lifecycle = signature
+	return nil
+}
+
+func (b *S3BlobBackend) putMultipart(ref AttachmentBlobRef, data []byte) error {
+	key := b.objectKey(ref)
+	body, err := b.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil, b.tagHeader(ref))
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &initiated); err != nil || initiated.UploadID == "" {
+		return fmt.Errorf("s3 multipart upload was not initiated: %v", err)
+	}
+	complete := s3CompleteMultipart{}
+	for offset, part := int64(0), 1; offset < int64(len(data)); offset, part = offset+b.cfg.PartSize, part+1 {
+		end := min(offset+b.cfg.PartSize, int64(len(data)))
+		query := url.Values{"partNumber": {strconv.Itoa(part)}, "uploadId": {initiated.UploadID}}
+		etag, err := b.doETag(http.MethodPut, key, query, data[offset:end])
+		if err != nil {
+			b.abortMultipart(key, initiated.UploadID)
+			return err
+		}
+		complete.Parts = append(complete.Parts, s3CompletedPart{PartNumber: part, ETag: etag})
+	}
+	payload, err := xml.Marshal(complete)
+	if err != nil {
+		b.abortMultipart(key, initiated.UploadID)
+		return err
+	}
+	body, err = b.do(http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, payload, nil)
+	if err == nil {
+		// CompleteMultipartUpload reports late failures in a 200 response.
+		err = s3ErrorFromBody(http.StatusOK, body)
+	}
+	if err != nil {
+		b.abortMultipart(key, initiated.UploadID)
+	}
+	return err
+}
+
+func (b *S3BlobBackend) abortMultipart(key, uploadID string) {
+	_, _ = b.do(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+}
+
+func (b *S3BlobBackend) objectKey(ref AttachmentBlobRef) string {
+	class := ref.Class
+	if class == "" {
+		class = "file"
+	}
+	return b.cfg.Prefix + class + "/" + ref.ID + ".bin"
+}
+
+func (b *S3BlobBackend) ruleID(suffix string) string {
+	return s3RuleIDPrefix + b.cfg.Prefix + ":" + suffix
+}
+
+func (b *S3BlobBackend) tagHeader(ref AttachmentBlobRef) http.Header {
+	header := http.Header{}
+	header.Set("X-Amz-Tagging", url.Values{s3PinnedTag: {strconv.FormatBool(ref.Pinned)}}.Encode())
+	return header
+}
+
+func (b *S3BlobBackend) do(method, key string, query url.Values, payload []byte, header http.Header) ([]byte, error) {
+	resp, err := b.send(method, key, query, payload, header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, s3ErrorFromBody(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+func (b *S3BlobBackend) doETag(method, key string, query url.Values, payload []byte) (string, error) {
+	resp, err := b.send(method, key, query, payload, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return "", s3ErrorFromBody(resp.StatusCode, body)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", errors.New("s3 part upload returned no etag")
+	}
+	return etag, nil
+}
+
+func (b *S3BlobBackend) send(method, key string, query url.Values, payload []byte, header http.Header) (*http.Response, error) {
+	target := *b.endpoint
+	target.Path = strings.TrimRight(b.endpoint.Path, "/") + "/" + b.cfg.Bucket
+	if key != "" {
+		target.Path += "/" + key
+	}
+	target.RawPath = s3EscapePath(target.Path)
+	target.RawQuery = s3CanonicalQuery(query)
+	req, err := http.NewRequest(method, target.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.ContentLength = int64(len(payload))
+	signS3Request(req, payload, b.cfg.Region, b.cfg.AccessKey, b.cfg.SecretKey, b.now())
+	return b.client.Do(req)
+}
+
+// signS3Request adds AWS Signature Version 4 headers. Every header already
+// set on the request is signed along with the host.
+func signS3Request(req *http.Request, payload []byte, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		sum := sha256.Sum256(payload)
+		payloadHash = hex.EncodeToString(sum[:])
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalQuery encodes query parameters sorted by key, the form both the
+// request URL and its signature use.
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes everything but the unreserved characters, and
+// slashes too when escapeSlash is set.
+func s3Escape(value string, escapeSlash bool) string {
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			out.WriteByte(c)
+		case c == '/' && !escapeSlash:
+			out.WriteByte(c)
+		default:
+			fmt.Fprintf(&out, "%%%02X", c)
+		}
+	}
+	return out.String()
+}
+
+func contentMD5Header(payload []byte) http.Header {
+	sum := md5.Sum(payload)
+	header := http.Header{}
+	header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	return header
+}
+
+func xmlEscape(value string) string {
+	var out strings.Builder
+	_ = xml.EscapeText(&out, []byte(value))
+	return out.String()
+}
+
+// S3Error is an error response of the object store.
+type S3Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 request failed: status %d", e.Status)
+	}
+	return fmt.Sprintf("s3 request failed: %s (status %d): %s", e.Code, e.Status, e.Message)
+}
+
+// s3ErrorFromBody returns nil for successful responses that carry no
+// <Error> document.
+func s3ErrorFromBody(status int, body []byte) error {
+	var doc struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil || doc.XMLName.Local != "Error" {
+		if status/100 == 2 {
+			return nil
+		}
+		return &S3Error{Status: status}
+	}
+	return &S3Error{Status: status, Code: doc.Code, Message: doc.Message}
+}
+
+func isS3NotFound(err error) bool {
+	var s3Err *S3Error
+	return errors.As(err, &s3Err) && s3Err.Status == http.StatusNotFound
+}
+
+func isS3Code(err error, code string) bool {
+	var s3Err *S3Error
+	return errors.As(err, &s3Err) && s3Err.Code == code
+}
+
+type s3Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+type s3Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Tags    []s3Tag  `xml:"TagSet>Tag"`
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type s3CompleteMultipart struct {
+	XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+	Parts   []s3CompletedPart `xml:"Part"`
+}
+
+// s3LifecycleRule keeps the rule as raw XML so rules written by other tools
+// survive a rewrite unchanged.
+type s3LifecycleRule struct {
+	Inner string `xml:",innerxml"`
+}
+
+func newS3LifecycleRule(id, body string) s3LifecycleRule {
+	return s3LifecycleRule{Inner: "<ID>" + xmlEscape(id) + "</ID><Status>Enabled</Status>" + body}
+}
+
+func (r s3LifecycleRule) id() string {
+	var rule struct {
+		ID string `xml:"ID"`
+	}
+	_ = xml.Unmarshal([]byte("<Rule>"+r.Inner+"</Rule>"), &rule)
+	return rule.ID
+}
+
+type s3LifecycleConfig struct {
+	XMLName xml.Name          `xml:"LifecycleConfiguration"`
+	Rules   []s3LifecycleRule `xml:"Rule"`
+}
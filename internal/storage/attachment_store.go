@@ -20,16 +20,30 @@ import (
 var ErrAttachmentNotFound = errors.New("attachment not found")
 var ErrAttachmentHardCapReached = errors.New("attachment class hard cap reached")
 var ErrUnsupportedStorageSchema = errors.New("unsupported storage schema version")
+var ErrAttachmentNotExpiring = errors.New("attachment is not scheduled to expire")
+var ErrAttachmentGraceLimitReached = errors.New("attachment grace limit reached")
+var ErrAttachmentGraceOverQuota = errors.New("attachment class quota exceeded")
+var ErrAttachmentBackendNeedsSecret = errors.New("attachment object backend requires a storage secret")
+
+// MaxAttachmentGraceSeconds caps how far grace extensions can push an
+// attachment past its TTL.
+const MaxAttachmentGraceSeconds = 7 * 24 * 60 * 60
 
 const attachmentIndexSchemaVersion = 2
 
+// AttachmentStore guards its index with mu. Blob backend calls, which may
+// go over the network, run without mu so a slow backend only delays the
+// calls that need it.
 type AttachmentStore struct {
-	mu        sync.RWMutex
+	mu sync.RWMutex
+	// pinMu orders pin changes, which update the backend and then the index.
+	pinMu     sync.Mutex
 	dir       string
 	indexPath string
 	secret    string
 	items     map[string]models.AttachmentMeta
 	blobs     map[string][]byte
+	backend   AttachmentBlobBackend
 	persist   bool
 	limits    attachmentClassLimits
 	hardCap   attachmentHardCapPolicy
@@ -66,11 +80,23 @@ func NewAttachmentStore(dir string) (*AttachmentStore, error) {
 }
 
 func NewAttachmentStoreWithSecret(dir, secret string) (*AttachmentStore, error) {
+	return NewAttachmentStoreWithBackend(dir, secret, nil)
+}
+
+// NewAttachmentStoreWithBackend keeps the index in dir and the blobs in
+// backend, or in dir as well when backend is nil. Blobs are encrypted before
+// they reach the backend, so object backends require a secret.
+func NewAttachmentStoreWithBackend(dir, secret string, backend AttachmentBlobBackend) (*AttachmentStore, error) {
+	secret = strings.TrimSpace(secret)
+	if backend != nil && secret == "" {
+		return nil, ErrAttachmentBackendNeedsSecret
+	}
 	s := &AttachmentStore{
 		dir:     dir,
-		secret:  strings.TrimSpace(secret),
+		secret:  secret,
 		items:   make(map[string]models.AttachmentMeta),
 		blobs:   make(map[string][]byte),
+		backend: backend,
 		persist: true,
 		hardCap: attachmentHardCapPolicy{
 			HighWatermarkPercent:    90,
@@ -109,43 +135,58 @@ func (s *AttachmentStore) Put(name, mimeType string, data []byte) (models.Attach
 		Size:         int64(len(data)),
 		CreatedAt:    now,
 	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if err := s.enforceClassLimitsLocked(meta.Class, meta.Size); err != nil {
+	if err := s.storeBlob(meta, data, true); err != nil {
 		return models.AttachmentMeta{}, err
 	}
-	if s.dir != "" {
-		if !s.persist {
-			s.items[id] = meta
-			s.blobs[id] = append([]byte(nil), data...)
-			return meta, nil
-		}
-		if err := os.MkdirAll(s.dir, 0o700); err != nil {
-			return models.AttachmentMeta{}, err
-		}
-		blob := append([]byte(nil), data...)
-		if s.secret != "" {
-			blob, err = securestore.Encrypt(s.secret, blob)
-			if err != nil {
-				return models.AttachmentMeta{}, err
-			}
-		}
-		filePath := s.filePath(id)
-		if err := os.WriteFile(filePath, blob, 0o600); err != nil {
-			return models.AttachmentMeta{}, err
+	return meta, nil
+}
+
+// storeBlob admits meta under the class limits, uploads its blob and then
+// records it in the index. The upload runs without the lock, so the limits
+// are checked again before the index update. With discardOnFailure the
+// uploaded blob is removed when the index update fails.
+func (s *AttachmentStore) storeBlob(meta models.AttachmentMeta, data []byte, discardOnFailure bool) error {
+	s.mu.Lock()
+	evicted, err := s.enforceClassLimitsLocked(meta.Class, meta.Size)
+	remote := err == nil && s.dir != "" && s.persist
+	if err == nil && !remote {
+		s.items[meta.ID] = meta
+		if s.dir != "" {
+			s.blobs[meta.ID] = append([]byte(nil), data...)
 		}
+	}
+	s.mu.Unlock()
+	if delErr := s.deleteBlobs(evicted); err == nil {
+		err = delErr
+	}
+	if err != nil || !remote {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	ref := s.blobRef(meta)
+	if err := s.putBlob(ref, data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	evicted, err = s.enforceClassLimitsLocked(meta.Class, meta.Size)
+	if err == nil {
 		nextItems := cloneAttachmentMetaMap(s.items)
-		nextItems[id] = meta
-		if err := s.persistItemsLocked(nextItems); err != nil {
-			_ = os.Remove(filePath)
-			return models.AttachmentMeta{}, err
+		nextItems[meta.ID] = meta
+		if err = s.persistItemsLocked(nextItems); err == nil {
+			s.items = nextItems
 		}
-		s.items = nextItems
-		return meta, nil
 	}
-	s.items[id] = meta
-	return meta, nil
+	s.mu.Unlock()
+	if err != nil && discardOnFailure {
+		evicted = append(evicted, ref)
+	}
+	if delErr := s.deleteBlobs(evicted); err == nil {
+		err = delErr
+	}
+	return err
 }
 
 func (s *AttachmentStore) Get(id string) (models.AttachmentMeta, []byte, error) {
@@ -163,11 +204,8 @@ func (s *AttachmentStore) Get(id string) (models.AttachmentMeta, []byte, error)
 	if s.dir == "" {
 		return meta, nil, ErrAttachmentNotFound
 	}
-	data, err := os.ReadFile(s.filePath(id))
+	data, err := s.blobBackend().GetBlob(s.blobRef(meta))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return models.AttachmentMeta{}, nil, ErrAttachmentNotFound
-		}
 		return models.AttachmentMeta{}, nil, err
 	}
 	if s.secret == "" {
@@ -189,16 +227,27 @@ func (s *AttachmentStore) Get(id string) (models.AttachmentMeta, []byte, error)
 
 func (s *AttachmentStore) Wipe() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	items := s.items
 	s.items = make(map[string]models.AttachmentMeta)
 	s.blobs = make(map[string][]byte)
 	if strings.TrimSpace(s.dir) == "" {
+		s.mu.Unlock()
 		return nil
 	}
+	var errs []error
 	if err := os.RemoveAll(s.dir); err != nil && !os.IsNotExist(err) {
-		return err
+		errs = append(errs, err)
 	}
-	return nil
+	s.mu.Unlock()
+	if s.backend != nil {
+		// Remote blobs outlive the local dir, so each one is deleted.
+		refs := make([]AttachmentBlobRef, 0, len(items))
+		for _, meta := range items {
+			refs = append(refs, s.blobRef(meta))
+		}
+		errs = append(errs, s.deleteBlobs(refs))
+	}
+	return errors.Join(errs...)
 }
 
 func (s *AttachmentStore) SetPersistenceEnabled(enabled bool) {
@@ -209,20 +258,18 @@ func (s *AttachmentStore) SetPersistenceEnabled(enabled bool) {
 
 func (s *AttachmentStore) PurgeOlderThan(cutoff time.Time) (int, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	deletedIDs := make([]string, 0)
 	for id, meta := range s.items {
 		if !meta.CreatedAt.After(cutoff) {
 			deletedIDs = append(deletedIDs, id)
 		}
 	}
-	if len(deletedIDs) == 0 {
-		return 0, nil
-	}
-	if err := s.deleteIDsLocked(deletedIDs); err != nil {
+	refs, err := s.deleteIDsLocked(deletedIDs)
+	s.mu.Unlock()
+	if err != nil {
 		return 0, err
 	}
-	return len(deletedIDs), nil
+	return len(deletedIDs), s.deleteBlobs(refs)
 }
 
 func (s *AttachmentStore) PurgeOlderThanByClass(class string, cutoff time.Time) (int, error) {
@@ -232,7 +279,6 @@ func (s *AttachmentStore) PurgeOlderThanByClass(class string, cutoff time.Time)
 	}
 
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	deletedIDs := make([]string, 0)
 	for id, meta := range s.items {
 		metaClass := strings.ToLower(strings.TrimSpace(meta.Class))
@@ -243,13 +289,12 @@ func (s *AttachmentStore) PurgeOlderThanByClass(class string, cutoff time.Time)
 			deletedIDs = append(deletedIDs, id)
 		}
 	}
-	if len(deletedIDs) == 0 {
-		return 0, nil
-	}
-	if err := s.deleteIDsLocked(deletedIDs); err != nil {
+	refs, err := s.deleteIDsLocked(deletedIDs)
+	s.mu.Unlock()
+	if err != nil {
 		return 0, err
 	}
-	return len(deletedIDs), nil
+	return len(deletedIDs), s.deleteBlobs(refs)
 }
 
 func (s *AttachmentStore) SetClassPolicies(imageQuotaMB, imageMaxItemSizeMB, fileQuotaMB, fileMaxItemSizeMB int) {
@@ -357,42 +402,7 @@ func (s *AttachmentStore) PutExisting(meta models.AttachmentMeta, data []byte) e
 	if strings.TrimSpace(meta.PinState) == "" {
 		meta.PinState = string(models.AttachmentPinStateUnpinned)
 	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if err := s.enforceClassLimitsLocked(meta.Class, meta.Size); err != nil {
-		return err
-	}
-	if s.dir != "" {
-		if !s.persist {
-			s.items[meta.ID] = meta
-			s.blobs[meta.ID] = append([]byte(nil), data...)
-			return nil
-		}
-		if err := os.MkdirAll(s.dir, 0o700); err != nil {
-			return err
-		}
-		blob := append([]byte(nil), data...)
-		var err error
-		if s.secret != "" {
-			blob, err = securestore.Encrypt(s.secret, blob)
-			if err != nil {
-				return err
-			}
-		}
-		if err := os.WriteFile(s.filePath(meta.ID), blob, 0o600); err != nil {
-			return err
-		}
-		nextItems := cloneAttachmentMetaMap(s.items)
-		nextItems[meta.ID] = meta
-		if err := s.persistItemsLocked(nextItems); err != nil {
-			return err
-		}
-		s.items = nextItems
-		return nil
-	}
-	s.items[meta.ID] = meta
-	return nil
+	return s.storeBlob(meta, data, false)
 }
 
 func (s *AttachmentStore) SetPinState(id, pinState string) error {
@@ -406,15 +416,41 @@ func (s *AttachmentStore) SetPinState(id, pinState string) error {
 	default:
 		return errors.New("invalid pin state")
 	}
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.pinMu.Lock()
+	defer s.pinMu.Unlock()
+	s.mu.RLock()
 	meta, ok := s.items[id]
+	tracksPins := s.persist
+	s.mu.RUnlock()
 	if !ok {
 		return ErrAttachmentNotFound
 	}
 	if meta.PinState == pinState {
 		return nil
 	}
+	previous := meta
+	meta.PinState = pinState
+	pinner, ok := s.backend.(AttachmentBlobPinner)
+	tracksPins = tracksPins && ok
+	if tracksPins {
+		if err := pinner.SetBlobPinned(s.blobRef(meta)); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	err := s.setPinStateLocked(id, pinState)
+	s.mu.Unlock()
+	if err != nil && tracksPins {
+		_ = pinner.SetBlobPinned(s.blobRef(previous))
+	}
+	return err
+}
+
+func (s *AttachmentStore) setPinStateLocked(id, pinState string) error {
+	meta, ok := s.items[id]
+	if !ok {
+		return ErrAttachmentNotFound
+	}
 	meta.PinState = pinState
 	meta.LastAccessAt = time.Now().UTC()
 	nextItems := cloneAttachmentMetaMap(s.items)
@@ -426,19 +462,268 @@ func (s *AttachmentStore) SetPinState(id, pinState string) error {
 	return nil
 }
 
+// ApplyBlobLifecycle hands the retention TTLs to a backend that expires blobs
+// on its own. Its rules trail the TTLs by the grace limit, so local GC and
+// grace extensions stay authoritative and the backend only catches blobs the
+// index lost track of. A TTL of zero keeps the class.
+func (s *AttachmentStore) ApplyBlobLifecycle(imageTTLSeconds, fileTTLSeconds int) error {
+	lifecycle, ok := s.backend.(AttachmentBlobLifecycle)
+	if !ok {
+		return nil
+	}
+	rules := make([]AttachmentLifecycleRule, 0, 2)
+	for _, class := range []struct {
+		name string
+		ttl  int
+	}{{"image", imageTTLSeconds}, {"file", fileTTLSeconds}} {
+		if class.ttl <= 0 {
+			continue
+		}
+		rules = append(rules, AttachmentLifecycleRule{
+			Class:       class.name,
+			ExpireAfter: time.Duration(int64(class.ttl)+MaxAttachmentGraceSeconds) * time.Second,
+		})
+	}
+	return lifecycle.ApplyBlobLifecycle(rules)
+}
+
+// SetMimeInspection records the sniffed content type and any mismatch
+// warnings produced when the attachment was uploaded.
+func (s *AttachmentStore) SetMimeInspection(id, sniffedMime string, warnings []string) (models.AttachmentMeta, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.AttachmentMeta{}, errors.New("attachment id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.items[id]
+	if !ok {
+		return models.AttachmentMeta{}, ErrAttachmentNotFound
+	}
+	meta.SniffedMime = strings.TrimSpace(sniffedMime)
+	meta.MimeWarnings = append([]string(nil), warnings...)
+	nextItems := cloneAttachmentMetaMap(s.items)
+	nextItems[id] = meta
+	if err := s.persistItemsLocked(nextItems); err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	s.items = nextItems
+	return meta, nil
+}
+
+// SetAltText replaces the accessibility description of an attachment. An
+// empty text clears it.
+func (s *AttachmentStore) SetAltText(id, altText string) (models.AttachmentMeta, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.AttachmentMeta{}, errors.New("attachment id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.items[id]
+	if !ok {
+		return models.AttachmentMeta{}, ErrAttachmentNotFound
+	}
+	if meta.AltText == altText {
+		return meta, nil
+	}
+	meta.AltText = altText
+	nextItems := cloneAttachmentMetaMap(s.items)
+	nextItems[id] = meta
+	if err := s.persistItemsLocked(nextItems); err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	s.items = nextItems
+	return meta, nil
+}
+
+// SetThumbnailID links an attachment to the blob holding its preview. An
+// empty id removes the link.
+func (s *AttachmentStore) SetThumbnailID(id, thumbnailID string) (models.AttachmentMeta, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.AttachmentMeta{}, errors.New("attachment id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.items[id]
+	if !ok {
+		return models.AttachmentMeta{}, ErrAttachmentNotFound
+	}
+	thumbnailID = strings.TrimSpace(thumbnailID)
+	if meta.ThumbnailID == thumbnailID {
+		return meta, nil
+	}
+	meta.ThumbnailID = thumbnailID
+	nextItems := cloneAttachmentMetaMap(s.items)
+	nextItems[id] = meta
+	if err := s.persistItemsLocked(nextItems); err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	s.items = nextItems
+	return meta, nil
+}
+
+// SetACLOverride stores the per-blob ACL of an attachment. A nil override
+// restores the node-wide policy.
+func (s *AttachmentStore) SetACLOverride(id string, override *models.BlobACLOverride) (models.AttachmentMeta, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.AttachmentMeta{}, errors.New("attachment id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.items[id]
+	if !ok {
+		return models.AttachmentMeta{}, ErrAttachmentNotFound
+	}
+	if override != nil {
+		copied := *override
+		copied.Identities = append([]string(nil), override.Identities...)
+		override = &copied
+	}
+	meta.ACL = override
+	nextItems := cloneAttachmentMetaMap(s.items)
+	nextItems[id] = meta
+	if err := s.persistItemsLocked(nextItems); err != nil {
+		return models.AttachmentMeta{}, err
+	}
+	s.items = nextItems
+	return meta, nil
+}
+
+// Meta returns the metadata of an attachment without reading its blob.
+func (s *AttachmentStore) Meta(id string) (models.AttachmentMeta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	meta, ok := s.items[strings.TrimSpace(id)]
+	if !ok {
+		return models.AttachmentMeta{}, ErrAttachmentNotFound
+	}
+	return meta, nil
+}
+
+// ListExpiring returns unpinned attachments the TTL deletes within the given
+// window, soonest first.
+func (s *AttachmentStore) ListExpiring(now time.Time, imageTTLSeconds, fileTTLSeconds int, within time.Duration) []models.AttachmentExpiry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	horizon := now.Add(within)
+	out := make([]models.AttachmentExpiry, 0)
+	for _, meta := range s.items {
+		expiresAt, ok := s.expiresAtLocked(meta, imageTTLSeconds, fileTTLSeconds)
+		if !ok || expiresAt.After(horizon) {
+			continue
+		}
+		out = append(out, s.expiryLocked(meta, expiresAt))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ExpiresAt.Equal(out[j].ExpiresAt) {
+			return out[i].BlobID < out[j].BlobID
+		}
+		return out[i].ExpiresAt.Before(out[j].ExpiresAt)
+	})
+	return out
+}
+
+// ExtendGrace postpones the TTL deletion of an attachment. Extensions are
+// refused once the class is over quota, since LRU eviction would remove the
+// blob anyway, and once the total grace exceeds MaxAttachmentGraceSeconds.
+func (s *AttachmentStore) ExtendGrace(id string, extraSeconds int, imageTTLSeconds, fileTTLSeconds int) (models.AttachmentExpiry, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.AttachmentExpiry{}, errors.New("attachment id is required")
+	}
+	if extraSeconds <= 0 {
+		return models.AttachmentExpiry{}, errors.New("grace extension must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta, ok := s.items[id]
+	if !ok {
+		return models.AttachmentExpiry{}, ErrAttachmentNotFound
+	}
+	if _, expiring := s.expiresAtLocked(meta, imageTTLSeconds, fileTTLSeconds); !expiring {
+		return models.AttachmentExpiry{}, ErrAttachmentNotExpiring
+	}
+	if meta.GraceSeconds+int64(extraSeconds) > MaxAttachmentGraceSeconds {
+		return models.AttachmentExpiry{}, ErrAttachmentGraceLimitReached
+	}
+	class := s.normalizedClass(meta)
+	if _, quotaBytes := s.classLimitsLocked(class); quotaBytes > 0 && s.usageByClassLocked(class) > quotaBytes {
+		return models.AttachmentExpiry{}, ErrAttachmentGraceOverQuota
+	}
+	meta.GraceSeconds += int64(extraSeconds)
+	nextItems := cloneAttachmentMetaMap(s.items)
+	nextItems[id] = meta
+	if err := s.persistItemsLocked(nextItems); err != nil {
+		return models.AttachmentExpiry{}, err
+	}
+	s.items = nextItems
+	expiresAt, _ := s.expiresAtLocked(meta, imageTTLSeconds, fileTTLSeconds)
+	return s.expiryLocked(meta, expiresAt), nil
+}
+
+// expiresAtLocked reports when the TTL deletes meta; ok is false for pinned
+// attachments and classes without a TTL.
+func (s *AttachmentStore) expiresAtLocked(meta models.AttachmentMeta, imageTTLSeconds, fileTTLSeconds int) (time.Time, bool) {
+	if strings.TrimSpace(meta.PinState) == string(models.AttachmentPinStatePinned) {
+		return time.Time{}, false
+	}
+	ttl := fileTTLSeconds
+	if s.normalizedClass(meta) == "image" {
+		ttl = imageTTLSeconds
+	}
+	if ttl <= 0 {
+		return time.Time{}, false
+	}
+	return meta.CreatedAt.Add(time.Duration(int64(ttl)+meta.GraceSeconds) * time.Second), true
+}
+
+func (s *AttachmentStore) expiryLocked(meta models.AttachmentMeta, expiresAt time.Time) models.AttachmentExpiry {
+	return models.AttachmentExpiry{
+		BlobID:       meta.ID,
+		Name:         meta.Name,
+		Class:        s.normalizedClass(meta),
+		Size:         meta.Size,
+		GraceSeconds: meta.GraceSeconds,
+		ExpiresAt:    expiresAt,
+	}
+}
+
 func (s *AttachmentStore) RunGC(now time.Time, imageTTLSeconds, fileTTLSeconds int, dryRun bool) (AttachmentGCReport, error) {
 	if now.IsZero() {
 		now = time.Now().UTC()
 	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.runGCLocked(now, imageTTLSeconds, fileTTLSeconds, dryRun)
+	report, refs, err := s.runGCLocked(now, imageTTLSeconds, fileTTLSeconds, dryRun)
+	s.mu.Unlock()
+	if err != nil {
+		return AttachmentGCReport{}, err
+	}
+	return report, s.deleteBlobs(refs)
 }
 
 func (s *AttachmentStore) filePath(id string) string {
 	return filepath.Join(s.dir, id+".bin")
 }
 
+// blobBackend returns the configured backend, or the store dir itself.
+func (s *AttachmentStore) blobBackend() AttachmentBlobBackend {
+	if s.backend == nil {
+		return fileBlobBackend{dir: s.dir}
+	}
+	return s.backend
+}
+
+func (s *AttachmentStore) blobRef(meta models.AttachmentMeta) AttachmentBlobRef {
+	return AttachmentBlobRef{
+		ID:     meta.ID,
+		Class:  s.normalizedClass(meta),
+		Pinned: strings.TrimSpace(meta.PinState) == string(models.AttachmentPinStatePinned),
+	}
+}
+
 func (s *AttachmentStore) load() error {
 	if s.indexPath == "" {
 		return nil
@@ -537,19 +822,45 @@ func (s *AttachmentStore) persistItemsLocked(items map[string]models.AttachmentM
 	if err != nil {
 		return err
 	}
+	release, err := securestore.BeginWrite(s.secret)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if s.secret == "" {
+		if data, err = securestore.AddRecordChecksums(data); err != nil {
+			return err
+		}
+	} else if data, err = securestore.Encrypt(s.secret, data); err != nil {
+		return err
+	}
+	return os.WriteFile(s.indexPath, data, 0o600)
+}
+
+// putBlob encrypts data with the store secret and hands it to the backend.
+func (s *AttachmentStore) putBlob(ref AttachmentBlobRef, data []byte) error {
+	blob := append([]byte(nil), data...)
 	if s.secret != "" {
-		data, err = securestore.Encrypt(s.secret, data)
+		release, err := securestore.BeginWrite(s.secret)
 		if err != nil {
 			return err
 		}
+		defer release()
+		if blob, err = securestore.Encrypt(s.secret, blob); err != nil {
+			return err
+		}
 	}
-	return os.WriteFile(s.indexPath, data, 0o600)
+	return s.blobBackend().PutBlob(ref, blob)
 }
 
 func (s *AttachmentStore) migrateLegacyFiles(items map[string]models.AttachmentMeta) error {
 	if s.secret == "" || len(items) == 0 {
 		return nil
 	}
+	if s.backend != nil {
+		// Object backends only ever received encrypted blobs.
+		return nil
+	}
 	for id := range items {
 		path := s.filePath(id)
 		raw, err := os.ReadFile(path)
@@ -595,7 +906,9 @@ func (s *AttachmentStore) touchLastAccess(id string, at time.Time) error {
 	return nil
 }
 
-func (s *AttachmentStore) runGCLocked(now time.Time, imageTTLSeconds, fileTTLSeconds int, dryRun bool) (AttachmentGCReport, error) {
+// runGCLocked drops collected attachments from the index and returns the
+// blobs the caller must delete once it releases the lock.
+func (s *AttachmentStore) runGCLocked(now time.Time, imageTTLSeconds, fileTTLSeconds int, dryRun bool) (AttachmentGCReport, []AttachmentBlobRef, error) {
 	report := AttachmentGCReport{
 		DryRun:         dryRun,
 		DeletedByClass: map[string]int{"image": 0, "file": 0},
@@ -609,23 +922,10 @@ func (s *AttachmentStore) runGCLocked(now time.Time, imageTTLSeconds, fileTTLSec
 		}
 	}
 
-	// 1) TTL phase: expire non-pinned blobs.
+	// 1) TTL phase: expire non-pinned blobs once their TTL plus grace passed.
 	for id, meta := range s.items {
-		if strings.TrimSpace(meta.PinState) == string(models.AttachmentPinStatePinned) {
-			continue
-		}
-		class := s.normalizedClass(meta)
-		ttl := 0
-		if class == "image" {
-			ttl = imageTTLSeconds
-		} else {
-			ttl = fileTTLSeconds
-		}
-		if ttl <= 0 {
-			continue
-		}
-		cutoff := now.Add(-time.Duration(ttl) * time.Second)
-		if !meta.CreatedAt.After(cutoff) {
+		expiresAt, ok := s.expiresAtLocked(meta, imageTTLSeconds, fileTTLSeconds)
+		if ok && !expiresAt.After(now) {
 			tryMarkDelete(id, "expired")
 		}
 	}
@@ -676,40 +976,57 @@ func (s *AttachmentStore) runGCLocked(now time.Time, imageTTLSeconds, fileTTLSec
 		report.DeletedCount++
 	}
 	if report.DeletedCount == 0 {
-		return report, nil
+		return report, nil, nil
 	}
 	if dryRun {
-		return report, nil
+		return report, nil, nil
 	}
 	deletedIDs := make([]string, 0, len(toDelete))
 	for id := range toDelete {
 		deletedIDs = append(deletedIDs, id)
 	}
-	if err := s.deleteIDsLocked(deletedIDs); err != nil {
-		return AttachmentGCReport{}, err
+	refs, err := s.deleteIDsLocked(deletedIDs)
+	if err != nil {
+		return AttachmentGCReport{}, nil, err
 	}
-	return report, nil
+	return report, refs, nil
 }
 
-func (s *AttachmentStore) deleteIDsLocked(ids []string) error {
+// deleteIDsLocked drops attachments from the index and returns the blobs to
+// delete with deleteBlobs after the lock is released. The index goes first,
+// so a failed blob delete leaves an orphan rather than a dangling entry.
+func (s *AttachmentStore) deleteIDsLocked(ids []string) ([]AttachmentBlobRef, error) {
 	if len(ids) == 0 {
-		return nil
+		return nil, nil
 	}
 	nextItems := cloneAttachmentMetaMap(s.items)
+	var refs []AttachmentBlobRef
 	for _, id := range ids {
+		meta, known := nextItems[id]
 		delete(nextItems, id)
-		delete(s.blobs, id)
-		if s.persist && strings.TrimSpace(s.dir) != "" {
-			if err := os.Remove(s.filePath(id)); err != nil && !os.IsNotExist(err) {
-				return err
-			}
+		if known && s.persist && strings.TrimSpace(s.dir) != "" {
+			refs = append(refs, s.blobRef(meta))
 		}
 	}
 	if err := s.persistItemsLocked(nextItems); err != nil {
-		return err
+		return nil, err
+	}
+	for _, id := range ids {
+		delete(s.blobs, id)
 	}
 	s.items = nextItems
-	return nil
+	return refs, nil
+}
+
+// deleteBlobs removes blobs from the backend. It must run without the lock.
+func (s *AttachmentStore) deleteBlobs(refs []AttachmentBlobRef) error {
+	var errs []error
+	for _, ref := range refs {
+		if err := s.blobBackend().DeleteBlob(ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (s *AttachmentStore) usageByClassExcludingLocked(class string, excluded map[string]string) int64 {
@@ -782,10 +1099,13 @@ func cloneAttachmentMetaMap(in map[string]models.AttachmentMeta) map[string]mode
 	return out
 }
 
-func (s *AttachmentStore) enforceClassLimitsLocked(class string, size int64) error {
+// enforceClassLimitsLocked admits an attachment of size into class, evicting
+// least recently used attachments to make room. It returns the evicted blobs
+// for the caller to delete after releasing the lock, even when it fails.
+func (s *AttachmentStore) enforceClassLimitsLocked(class string, size int64) ([]AttachmentBlobRef, error) {
 	maxItemBytes, quotaBytes := s.classLimitsLocked(class)
 	if maxItemBytes > 0 && size > maxItemBytes {
-		return errors.New("attachment exceeds class max item size")
+		return nil, errors.New("attachment exceeds class max item size")
 	}
 	if quotaBytes > 0 {
 		fullCapBytes := (quotaBytes * int64(s.hardCap.FullCapPercent)) / 100
@@ -798,9 +1118,10 @@ func (s *AttachmentStore) enforceClassLimitsLocked(class string, size int64) err
 			} else {
 				s.hits.FileFullCapHits++
 			}
-			return ErrAttachmentHardCapReached
+			return nil, ErrAttachmentHardCapReached
 		}
 	}
+	var evicted []AttachmentBlobRef
 	if quotaBytes > 0 && s.usageByClassLocked(class)+size > quotaBytes {
 		targetUsage := quotaBytes - size
 		usage := s.usageByClassLocked(class)
@@ -820,17 +1141,17 @@ func (s *AttachmentStore) enforceClassLimitsLocked(class string, size int64) err
 					break
 				}
 			}
-			if len(toDelete) > 0 {
-				if err := s.deleteIDsLocked(toDelete); err != nil {
-					return err
-				}
+			refs, err := s.deleteIDsLocked(toDelete)
+			if err != nil {
+				return nil, err
 			}
+			evicted = refs
 		}
 		if s.usageByClassLocked(class)+size > quotaBytes {
-			return errors.New("attachment class quota exceeded")
+			return evicted, errors.New("attachment class quota exceeded")
 		}
 	}
-	return nil
+	return evicted, nil
 }
 
 func (s *AttachmentStore) classLimitsLocked(class string) (maxItemBytes int64, quotaBytes int64) {
@@ -581,3 +581,113 @@ func TestAttachmentStoreHighWatermarkTriggersAggressiveGC(t *testing.T) {
 		t.Fatalf("expected usage reduced close to aggressive target, got %d", usage)
 	}
 }
+
+func TestAttachmentStoreSetAltTextPersists(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewAttachmentStoreWithSecret(dir, "secret")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	meta, err := store.Put("cat.png", "image/png", []byte("png"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, err := store.SetAltText(meta.ID, "A sleeping cat"); err != nil {
+		t.Fatalf("set alt text: %v", err)
+	}
+	if _, err := store.SetAltText("att1_missing", "x"); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	reopened, err := NewAttachmentStoreWithSecret(dir, "secret")
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	got, _, err := reopened.Get(meta.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.AltText != "A sleeping cat" {
+		t.Fatalf("alt text was not persisted, got %q", got.AltText)
+	}
+}
+
+func TestAttachmentStoreListExpiringAndExtendGrace(t *testing.T) {
+	store, err := NewAttachmentStore("")
+	if err != nil {
+		t.Fatalf("new attachment store failed: %v", err)
+	}
+	soon, err := store.Put("soon.bin", "application/octet-stream", []byte("soon"))
+	if err != nil {
+		t.Fatalf("put soon failed: %v", err)
+	}
+	later, err := store.Put("later.bin", "application/octet-stream", []byte("later"))
+	if err != nil {
+		t.Fatalf("put later failed: %v", err)
+	}
+	now := time.Now().UTC()
+	store.mu.Lock()
+	sMeta := store.items[soon.ID]
+	sMeta.CreatedAt = now.Add(-50 * time.Minute)
+	store.items[soon.ID] = sMeta
+	lMeta := store.items[later.ID]
+	lMeta.CreatedAt = now
+	store.items[later.ID] = lMeta
+	store.mu.Unlock()
+
+	expiring := store.ListExpiring(now, 0, 3600, 15*time.Minute)
+	if len(expiring) != 1 || expiring[0].BlobID != soon.ID {
+		t.Fatalf("expected only the soon blob to be expiring, got %+v", expiring)
+	}
+	if !expiring[0].ExpiresAt.Equal(sMeta.CreatedAt.Add(time.Hour)) {
+		t.Fatalf("unexpected deadline: %v", expiring[0].ExpiresAt)
+	}
+
+	extended, err := store.ExtendGrace(soon.ID, 1800, 0, 3600)
+	if err != nil {
+		t.Fatalf("extend grace failed: %v", err)
+	}
+	if extended.GraceSeconds != 1800 || !extended.ExpiresAt.Equal(sMeta.CreatedAt.Add(90*time.Minute)) {
+		t.Fatalf("unexpected extended expiry: %+v", extended)
+	}
+	if got := store.ListExpiring(now, 0, 3600, 15*time.Minute); len(got) != 0 {
+		t.Fatalf("extended blob must leave the notice window, got %+v", got)
+	}
+	report, err := store.RunGC(now.Add(15*time.Minute), 0, 3600, false)
+	if err != nil {
+		t.Fatalf("run gc failed: %v", err)
+	}
+	if report.DeletedCount != 0 {
+		t.Fatalf("grace must postpone ttl deletion, report=%+v", report)
+	}
+
+	if _, err := store.ExtendGrace(soon.ID, MaxAttachmentGraceSeconds, 0, 3600); !errors.Is(err, ErrAttachmentGraceLimitReached) {
+		t.Fatalf("expected ErrAttachmentGraceLimitReached, got %v", err)
+	}
+	if _, err := store.ExtendGrace(soon.ID, 60, 0, 0); !errors.Is(err, ErrAttachmentNotExpiring) {
+		t.Fatalf("expected ErrAttachmentNotExpiring without ttl, got %v", err)
+	}
+	if err := store.SetPinState(later.ID, "pinned"); err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	if _, err := store.ExtendGrace(later.ID, 60, 0, 3600); !errors.Is(err, ErrAttachmentNotExpiring) {
+		t.Fatalf("expected ErrAttachmentNotExpiring for pinned blob, got %v", err)
+	}
+}
+
+func TestAttachmentStoreExtendGraceRefusedOverQuota(t *testing.T) {
+	store, err := NewAttachmentStore("")
+	if err != nil {
+		t.Fatalf("new attachment store failed: %v", err)
+	}
+	meta, err := store.Put("big.bin", "application/octet-stream", make([]byte, 700*1024))
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	store.mu.Lock()
+	store.limits.FileQuotaBytes = 512 * 1024
+	store.mu.Unlock()
+	if _, err := store.ExtendGrace(meta.ID, 60, 0, 3600); !errors.Is(err, ErrAttachmentGraceOverQuota) {
+		t.Fatalf("expected ErrAttachmentGraceOverQuota, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	auditLogSchemaVersion = 1
+
+	defaultAuditPageLimit = 50
+	maxAuditPageLimit     = 500
+)
+
+var ErrInvalidAuditEvent = errors.New("invalid audit event")
+
+type persistedAuditLog struct {
+	Version int                 `json:"version"`
+	Events  []models.AuditEvent `json:"events"`
+}
+
+// auditHashInput is what an entry's hash covers. The time is formatted
+// explicitly so the hash does not depend on how it was decoded.
+type auditHashInput struct {
+	Seq      int64             `json:"seq"`
+	At       string            `json:"at"`
+	Kind     string            `json:"kind"`
+	Details  map[string]string `json:"details,omitempty"`
+	PrevHash string            `json:"prev_hash"`
+}
+
+// AuditLogStore is an append-only log of security-relevant events in an
+// encrypted per-account file. Entries are hash-chained, so editing,
+// reordering or dropping an entry before the newest one breaks Verify.
+type AuditLogStore struct {
+	mu     sync.RWMutex
+	path   string
+	secret string
+	events []models.AuditEvent
+}
+
+func NewAuditLogStore() *AuditLogStore {
+	return &AuditLogStore{}
+}
+
+func (s *AuditLogStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *AuditLogStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedAuditLog
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != auditLogSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	s.events = payload.Events
+	return nil
+}
+
+// Append chains a new event to the log and persists it before returning.
+func (s *AuditLogStore) Append(at time.Time, kind string, details map[string]string) (models.AuditEvent, error) {
+	kind = strings.TrimSpace(kind)
+	if kind == "" {
+		return models.AuditEvent{}, ErrInvalidAuditEvent
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := models.AuditEvent{
+		Seq:     1,
+		At:      at.UTC(),
+		Kind:    kind,
+		Details: details,
+	}
+	if n := len(s.events); n > 0 {
+		event.Seq = s.events[n-1].Seq + 1
+		event.PrevHash = s.events[n-1].Hash
+	}
+	hash, err := auditEventHash(event)
+	if err != nil {
+		return models.AuditEvent{}, err
+	}
+	event.Hash = hash
+	next := append(s.events[:len(s.events):len(s.events)], event)
+	if err := s.persistLocked(next); err != nil {
+		return models.AuditEvent{}, err
+	}
+	s.events = next
+	return event, nil
+}
+
+// List returns events newest first. An empty kind lists every kind; a
+// non-positive limit uses the default page size.
+func (s *AuditLogStore) List(kind string, limit, offset int) models.AuditLogPage {
+	kind = strings.TrimSpace(kind)
+	if limit <= 0 {
+		limit = defaultAuditPageLimit
+	}
+	limit = min(limit, maxAuditPageLimit)
+	offset = max(offset, 0)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	page := models.AuditLogPage{Events: []models.AuditEvent{}}
+	for i := len(s.events) - 1; i >= 0; i-- {
+		event := s.events[i]
+		if kind != "" && event.Kind != kind {
+			continue
+		}
+		if page.Total >= offset && len(page.Events) < limit {
+			page.Events = append(page.Events, event)
+		}
+		page.Total++
+	}
+	return page
+}
+
+// Verify walks the chain from the first entry and reports the first one
+// whose sequence number, link or hash does not check out.
+func (s *AuditLogStore) Verify() models.AuditVerification {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return verifyAuditChain(s.events)
+}
+
+func (s *AuditLogStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *AuditLogStore) persistLocked(events []models.AuditEvent) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedAuditLog{
+		Version: auditLogSchemaVersion,
+		Events:  events,
+	})
+}
+
+func verifyAuditChain(events []models.AuditEvent) models.AuditVerification {
+	result := models.AuditVerification{Valid: true, Entries: len(events)}
+	prevHash := ""
+	for i, event := range events {
+		reason := ""
+		switch hash, err := auditEventHash(event); {
+		case event.Seq != int64(i+1):
+			reason = fmt.Sprintf("expected sequence %d", i+1)
+		case event.PrevHash != prevHash:
+			reason = "previous hash does not match"
+		case err != nil || hash != event.Hash:
+			reason = "hash does not match contents"
+		}
+		if reason != "" {
+			result.Valid = false
+			result.BrokenAt = int64(i + 1)
+			result.Reason = reason
+			return result
+		}
+		prevHash = event.Hash
+	}
+	return result
+}
+
+func auditEventHash(event models.AuditEvent) (string, error) {
+	raw, err := json.Marshal(auditHashInput{
+		Seq:      event.Seq,
+		At:       event.At.UTC().Format(time.RFC3339Nano),
+		Kind:     event.Kind,
+		Details:  event.Details,
+		PrevHash: event.PrevHash,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
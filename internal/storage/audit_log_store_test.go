@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestAuditLogStoreChainsAndDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit_log.enc")
+	store := NewAuditLogStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	kinds := []string{models.AuditKindBlocklistAdded, models.AuditKindPrivacyModeChanged, models.AuditKindBlocklistAdded, models.AuditKindBackupExported}
+	for i, kind := range kinds {
+		if _, err := store.Append(base.Add(time.Duration(i)*time.Minute), kind, map[string]string{"n": string(rune('a' + i))}); err != nil {
+			t.Fatalf("append %s: %v", kind, err)
+		}
+	}
+	if _, err := store.Append(base, " ", nil); err == nil {
+		t.Fatal("event without a kind must be rejected")
+	}
+
+	reloaded := NewAuditLogStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if check := reloaded.Verify(); !check.Valid || check.Entries != 4 {
+		t.Fatalf("untouched log must verify: %+v", check)
+	}
+	page := reloaded.List("", 2, 1)
+	if page.Total != 4 || len(page.Events) != 2 || page.Events[0].Seq != 3 || page.Events[1].Seq != 2 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	page = reloaded.List(models.AuditKindBlocklistAdded, 0, 0)
+	if page.Total != 2 || len(page.Events) != 2 || page.Events[0].Seq != 3 {
+		t.Fatalf("unexpected filtered page: %+v", page)
+	}
+
+	reloaded.events[1].Details["n"] = "z"
+	if check := reloaded.Verify(); check.Valid || check.BrokenAt != 2 {
+		t.Fatalf("edited entry must break the chain: %+v", check)
+	}
+	reloaded.events[1].Details["n"] = "b"
+	reloaded.events = append(reloaded.events[:1:1], reloaded.events[2:]...)
+	if check := reloaded.Verify(); check.Valid || check.BrokenAt != 2 {
+		t.Fatalf("dropped entry must break the chain: %+v", check)
+	}
+}
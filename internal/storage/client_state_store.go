@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	ErrClientStateInvalidName   = errors.New("client state namespace and key must be non-empty printable strings")
+	ErrClientStateQuotaExceeded = errors.New("client state quota exceeded")
+)
+
+const clientStateSchemaVersion = 1
+
+// ClientStateQuota bounds how much opaque client data an account may hold.
+type ClientStateQuota struct {
+	MaxNameBytes           int
+	MaxValueBytes          int
+	MaxEntriesPerNamespace int
+	MaxTotalBytes          int
+}
+
+func DefaultClientStateQuota() ClientStateQuota {
+	return ClientStateQuota{
+		MaxNameBytes:           128,
+		MaxValueBytes:          16 << 10,
+		MaxEntriesPerNamespace: 256,
+		MaxTotalBytes:          1 << 20,
+	}
+}
+
+// ClientStateStore keeps namespaced key-value entries for client apps in an
+// encrypted per-account file.
+type ClientStateStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	quota   ClientStateQuota
+	entries map[string]map[string]models.ClientStateEntry
+}
+
+type persistedClientState struct {
+	Version int                       `json:"version"`
+	Entries []models.ClientStateEntry `json:"entries"`
+}
+
+func NewClientStateStore() *ClientStateStore {
+	return &ClientStateStore{
+		quota:   DefaultClientStateQuota(),
+		entries: map[string]map[string]models.ClientStateEntry{},
+	}
+}
+
+func (s *ClientStateStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *ClientStateStore) SetQuota(quota ClientStateQuota) {
+	def := DefaultClientStateQuota()
+	if quota.MaxNameBytes <= 0 {
+		quota.MaxNameBytes = def.MaxNameBytes
+	}
+	if quota.MaxValueBytes <= 0 {
+		quota.MaxValueBytes = def.MaxValueBytes
+	}
+	if quota.MaxEntriesPerNamespace <= 0 {
+		quota.MaxEntriesPerNamespace = def.MaxEntriesPerNamespace
+	}
+	if quota.MaxTotalBytes <= 0 {
+		quota.MaxTotalBytes = def.MaxTotalBytes
+	}
+	s.mu.Lock()
+	s.quota = quota
+	s.mu.Unlock()
+}
+
+func (s *ClientStateStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]models.ClientStateEntry{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedClientState
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != clientStateSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	s.entries = groupClientStateEntries(payload.Entries)
+	return nil
+}
+
+func (s *ClientStateStore) Set(namespace, key, value string) (models.ClientStateEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	namespace, key, err := s.normalizeNamesLocked(namespace, key)
+	if err != nil {
+		return models.ClientStateEntry{}, err
+	}
+	if len(value) > s.quota.MaxValueBytes {
+		return models.ClientStateEntry{}, fmt.Errorf("%w: value exceeds %d bytes", ErrClientStateQuotaExceeded, s.quota.MaxValueBytes)
+	}
+	current := s.entries[namespace]
+	previous, exists := current[key]
+	if !exists && len(current) >= s.quota.MaxEntriesPerNamespace {
+		return models.ClientStateEntry{}, fmt.Errorf("%w: namespace holds %d entries", ErrClientStateQuotaExceeded, s.quota.MaxEntriesPerNamespace)
+	}
+	total := s.totalBytesLocked() + clientStateEntrySize(namespace, key, value)
+	if exists {
+		total -= clientStateEntrySize(previous.Namespace, previous.Key, previous.Value)
+	}
+	if total > s.quota.MaxTotalBytes {
+		return models.ClientStateEntry{}, fmt.Errorf("%w: total size exceeds %d bytes", ErrClientStateQuotaExceeded, s.quota.MaxTotalBytes)
+	}
+
+	entry := models.ClientStateEntry{Namespace: namespace, Key: key, Value: value, UpdatedAt: time.Now().UTC()}
+	next := cloneClientStateEntries(s.entries)
+	if next[namespace] == nil {
+		next[namespace] = map[string]models.ClientStateEntry{}
+	}
+	next[namespace][key] = entry
+	if err := s.persistLocked(next); err != nil {
+		return models.ClientStateEntry{}, err
+	}
+	s.entries = next
+	return entry, nil
+}
+
+func (s *ClientStateStore) Get(namespace, key string) (models.ClientStateEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[strings.TrimSpace(namespace)][strings.TrimSpace(key)]
+	return entry, ok
+}
+
+// List returns all entries of a namespace sorted by key.
+func (s *ClientStateStore) List(namespace string) []models.ClientStateEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bucket := s.entries[strings.TrimSpace(namespace)]
+	out := make([]models.ClientStateEntry, 0, len(bucket))
+	for _, entry := range bucket {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (s *ClientStateStore) Delete(namespace, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	namespace = strings.TrimSpace(namespace)
+	key = strings.TrimSpace(key)
+	if _, ok := s.entries[namespace][key]; !ok {
+		return false, nil
+	}
+	next := cloneClientStateEntries(s.entries)
+	delete(next[namespace], key)
+	if len(next[namespace]) == 0 {
+		delete(next, namespace)
+	}
+	if err := s.persistLocked(next); err != nil {
+		return false, err
+	}
+	s.entries = next
+	return true, nil
+}
+
+// Snapshot returns every entry ordered by namespace and key, for backups.
+func (s *ClientStateStore) Snapshot() []models.ClientStateEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return flattenClientStateEntries(s.entries)
+}
+
+// RestoreSnapshot replaces the whole store with the given entries.
+func (s *ClientStateStore) RestoreSnapshot(entries []models.ClientStateEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := groupClientStateEntries(entries)
+	if err := s.persistLocked(next); err != nil {
+		return err
+	}
+	s.entries = next
+	return nil
+}
+
+func (s *ClientStateStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]models.ClientStateEntry{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ClientStateStore) normalizeNamesLocked(namespace, key string) (string, string, error) {
+	namespace = strings.TrimSpace(namespace)
+	key = strings.TrimSpace(key)
+	for _, name := range []string{namespace, key} {
+		if name == "" || len(name) > s.quota.MaxNameBytes || strings.IndexFunc(name, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+			return "", "", ErrClientStateInvalidName
+		}
+	}
+	return namespace, key, nil
+}
+
+func (s *ClientStateStore) totalBytesLocked() int {
+	total := 0
+	for _, bucket := range s.entries {
+		for _, entry := range bucket {
+			total += clientStateEntrySize(entry.Namespace, entry.Key, entry.Value)
+		}
+	}
+	return total
+}
+
+func (s *ClientStateStore) persistLocked(entries map[string]map[string]models.ClientStateEntry) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedClientState{
+		Version: clientStateSchemaVersion,
+		Entries: flattenClientStateEntries(entries),
+	})
+}
+
+func clientStateEntrySize(namespace, key, value string) int {
+	return len(namespace) + len(key) + len(value)
+}
+
+func groupClientStateEntries(entries []models.ClientStateEntry) map[string]map[string]models.ClientStateEntry {
+	out := map[string]map[string]models.ClientStateEntry{}
+	for _, entry := range entries {
+		entry.Namespace = strings.TrimSpace(entry.Namespace)
+		entry.Key = strings.TrimSpace(entry.Key)
+		if entry.Namespace == "" || entry.Key == "" {
+			continue
+		}
+		if out[entry.Namespace] == nil {
+			out[entry.Namespace] = map[string]models.ClientStateEntry{}
+		}
+		out[entry.Namespace][entry.Key] = entry
+	}
+	return out
+}
+
+func flattenClientStateEntries(entries map[string]map[string]models.ClientStateEntry) []models.ClientStateEntry {
+	out := make([]models.ClientStateEntry, 0)
+	for _, bucket := range entries {
+		for _, entry := range bucket {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func cloneClientStateEntries(in map[string]map[string]models.ClientStateEntry) map[string]map[string]models.ClientStateEntry {
+	out := make(map[string]map[string]models.ClientStateEntry, len(in))
+	for namespace, bucket := range in {
+		copied := make(map[string]models.ClientStateEntry, len(bucket))
+		for key, entry := range bucket {
+			copied[key] = entry
+		}
+		out[namespace] = copied
+	}
+	return out
+}
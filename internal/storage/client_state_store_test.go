@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestClientStateStoreSetGetListDelete(t *testing.T) {
+	store := NewClientStateStore()
+	if _, err := store.Set("app.drafts", "c-2", "draft two"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := store.Set("app.drafts", "c-1", "draft one"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := store.Set("app.pins", "c-1", ""); err != nil {
+		t.Fatalf("set with empty value failed: %v", err)
+	}
+
+	entry, ok := store.Get("app.drafts", "c-1")
+	if !ok || entry.Value != "draft one" || entry.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected entry: %#v found=%v", entry, ok)
+	}
+	list := store.List("app.drafts")
+	if len(list) != 2 || list[0].Key != "c-1" || list[1].Key != "c-2" {
+		t.Fatalf("unexpected namespace listing: %#v", list)
+	}
+
+	deleted, err := store.Delete("app.drafts", "c-1")
+	if err != nil || !deleted {
+		t.Fatalf("delete failed: deleted=%v err=%v", deleted, err)
+	}
+	if deleted, _ := store.Delete("app.drafts", "c-1"); deleted {
+		t.Fatal("second delete must report missing entry")
+	}
+	if _, ok := store.Get("app.drafts", "c-1"); ok {
+		t.Fatal("deleted entry must not be returned")
+	}
+}
+
+func TestClientStateStoreQuotas(t *testing.T) {
+	store := NewClientStateStore()
+	store.SetQuota(ClientStateQuota{MaxNameBytes: 8, MaxValueBytes: 4, MaxEntriesPerNamespace: 2, MaxTotalBytes: 20})
+
+	if _, err := store.Set("", "k", "v"); !errors.Is(err, ErrClientStateInvalidName) {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+	if _, err := store.Set("ns", strings.Repeat("k", 9), "v"); !errors.Is(err, ErrClientStateInvalidName) {
+		t.Fatalf("expected invalid name error for long key, got %v", err)
+	}
+	if _, err := store.Set("ns", "k\x01", "v"); !errors.Is(err, ErrClientStateInvalidName) {
+		t.Fatalf("expected invalid name error for control characters, got %v", err)
+	}
+	if _, err := store.Set("ns", "k", "12345"); !errors.Is(err, ErrClientStateQuotaExceeded) {
+		t.Fatalf("expected value quota error, got %v", err)
+	}
+
+	if _, err := store.Set("ns", "a", "1"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := store.Set("ns", "b", "2"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := store.Set("ns", "c", "3"); !errors.Is(err, ErrClientStateQuotaExceeded) {
+		t.Fatalf("expected entry count quota error, got %v", err)
+	}
+	if _, err := store.Set("ns", "a", "1111"); err != nil {
+		t.Fatalf("overwriting an existing key must not count as a new entry: %v", err)
+	}
+	if _, err := store.Set("other", "a", "1234"); !errors.Is(err, ErrClientStateQuotaExceeded) {
+		t.Fatalf("expected total size quota error, got %v", err)
+	}
+}
+
+func TestClientStateStorePersistsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client_state.enc")
+	store := NewClientStateStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if _, err := store.Set("app.ui", "theme", "dark"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	reloaded := NewClientStateStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	if entry, ok := reloaded.Get("app.ui", "theme"); !ok || entry.Value != "dark" {
+		t.Fatalf("entry not persisted: %#v found=%v", entry, ok)
+	}
+
+	if err := reloaded.RestoreSnapshot([]models.ClientStateEntry{{Namespace: "app.ui", Key: "lang", Value: "en"}}); err != nil {
+		t.Fatalf("restore snapshot failed: %v", err)
+	}
+	snapshot := reloaded.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Key != "lang" {
+		t.Fatalf("restore must replace existing entries: %#v", snapshot)
+	}
+
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	wiped := NewClientStateStore()
+	wiped.Configure(path, "secret")
+	if err := wiped.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap after wipe failed: %v", err)
+	}
+	if len(wiped.Snapshot()) != 0 {
+		t.Fatal("wiped store must not retain entries")
+	}
+}
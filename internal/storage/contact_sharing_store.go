@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const contactSharingSchemaVersion = 1
+
+// DefaultSharingProfile shares every signal, which is how the daemon
+// behaved before sharing profiles existed.
+var DefaultSharingProfile = models.SharingProfile{
+	Presence:         true,
+	ReadReceipts:     true,
+	ProfilePhoto:     true,
+	TypingIndicators: true,
+}
+
+// ContactSharingStore keeps the default sharing profile and the per-contact
+// overrides in an encrypted per-account file.
+type ContactSharingStore struct {
+	mu         sync.RWMutex
+	path       string
+	secret     string
+	defaults   models.ContactSharingProfile
+	perContact map[string]models.ContactSharingProfile
+}
+
+type persistedContactSharing struct {
+	Version  int                            `json:"version"`
+	Default  models.ContactSharingProfile   `json:"default"`
+	Contacts []models.ContactSharingProfile `json:"contacts"`
+}
+
+func NewContactSharingStore() *ContactSharingStore {
+	return &ContactSharingStore{
+		defaults:   models.ContactSharingProfile{SharingProfile: DefaultSharingProfile},
+		perContact: map[string]models.ContactSharingProfile{},
+	}
+}
+
+func (s *ContactSharingStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *ContactSharingStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = models.ContactSharingProfile{SharingProfile: DefaultSharingProfile}
+	s.perContact = map[string]models.ContactSharingProfile{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedContactSharing
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != contactSharingSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	s.defaults = payload.Default
+	s.defaults.ContactID = ""
+	for _, profile := range payload.Contacts {
+		if profile.ContactID != "" {
+			s.perContact[profile.ContactID] = profile
+		}
+	}
+	return nil
+}
+
+// Default returns the profile of contacts without one of their own.
+func (s *ContactSharingStore) Default() models.ContactSharingProfile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults
+}
+
+// Get returns the profile in effect for a contact, falling back to the
+// default profile.
+func (s *ContactSharingStore) Get(contactID string) models.ContactSharingProfile {
+	contactID = strings.TrimSpace(contactID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if profile, ok := s.perContact[contactID]; ok {
+		return profile
+	}
+	profile := s.defaults
+	profile.ContactID = contactID
+	profile.Inherited = true
+	return profile
+}
+
+// SetDefault replaces the default profile.
+func (s *ContactSharingStore) SetDefault(profile models.ContactSharingProfile) error {
+	profile.ContactID = ""
+	profile.Inherited = false
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.persistLocked(profile, s.perContact); err != nil {
+		return err
+	}
+	s.defaults = profile
+	return nil
+}
+
+// Put stores a contact's own profile. With remove set the contact falls
+// back to the default profile and its entry is dropped.
+func (s *ContactSharingStore) Put(profile models.ContactSharingProfile, remove bool) error {
+	profile.Inherited = false
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]models.ContactSharingProfile, len(s.perContact)+1)
+	for id, existing := range s.perContact {
+		next[id] = existing
+	}
+	if remove {
+		delete(next, profile.ContactID)
+	} else {
+		next[profile.ContactID] = profile
+	}
+	if err := s.persistLocked(s.defaults, next); err != nil {
+		return err
+	}
+	s.perContact = next
+	return nil
+}
+
+func (s *ContactSharingStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = models.ContactSharingProfile{SharingProfile: DefaultSharingProfile}
+	s.perContact = map[string]models.ContactSharingProfile{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ContactSharingStore) persistLocked(defaults models.ContactSharingProfile, perContact map[string]models.ContactSharingProfile) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	payload := persistedContactSharing{
+		Version:  contactSharingSchemaVersion,
+		Default:  defaults,
+		Contacts: make([]models.ContactSharingProfile, 0, len(perContact)),
+	}
+	for _, profile := range perContact {
+		payload.Contacts = append(payload.Contacts, profile)
+	}
+	sort.Slice(payload.Contacts, func(i, j int) bool { return payload.Contacts[i].ContactID < payload.Contacts[j].ContactID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, payload)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestContactSharingStoreFallsBackToDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contact_sharing.enc")
+	store := NewContactSharingStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if got := store.Get("aim1_alice"); !got.Inherited || got.SharingProfile != DefaultSharingProfile {
+		t.Fatalf("fresh store must share everything: %+v", got)
+	}
+
+	if err := store.SetDefault(models.ContactSharingProfile{SharingProfile: models.SharingProfile{ReadReceipts: true}}); err != nil {
+		t.Fatalf("set default: %v", err)
+	}
+	if err := store.Put(models.ContactSharingProfile{ContactID: "aim1_alice", SharingProfile: models.SharingProfile{Presence: true}}, false); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	reloaded := NewContactSharingStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got := reloaded.Get("aim1_alice"); got.Inherited || !got.Presence || got.ReadReceipts {
+		t.Fatalf("unexpected contact profile after reload: %+v", got)
+	}
+	if got := reloaded.Get("aim1_bob"); !got.Inherited || got.ContactID != "aim1_bob" || !got.ReadReceipts || got.Presence {
+		t.Fatalf("unexpected inherited profile after reload: %+v", got)
+	}
+
+	if err := reloaded.Put(models.ContactSharingProfile{ContactID: "aim1_alice"}, true); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := reloaded.Get("aim1_alice"); !got.Inherited || !got.ReadReceipts {
+		t.Fatalf("removed contact must fall back to the default: %+v", got)
+	}
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if got := reloaded.Default(); got.SharingProfile != DefaultSharingProfile {
+		t.Fatalf("wipe must restore the default profile: %+v", got)
+	}
+}
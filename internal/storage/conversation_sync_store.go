@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const conversationSyncSchemaVersion = 1
+
+// ConversationSyncMergeFunc merges a remote state into the local one and
+// reports whether the local state changed.
+type ConversationSyncMergeFunc func(local, remote models.ConversationSyncState) (models.ConversationSyncState, bool)
+
+// ConversationSyncStore keeps the per-conversation registers synced between
+// the user's own devices in an encrypted per-account file.
+type ConversationSyncStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	entries map[string]models.ConversationSyncState
+}
+
+type persistedConversationSync struct {
+	Version int                            `json:"version"`
+	Entries []models.ConversationSyncState `json:"entries"`
+}
+
+func NewConversationSyncStore() *ConversationSyncStore {
+	return &ConversationSyncStore{
+		entries: map[string]models.ConversationSyncState{},
+	}
+}
+
+func (s *ConversationSyncStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *ConversationSyncStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]models.ConversationSyncState{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedConversationSync
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != conversationSyncSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, entry := range payload.Entries {
+		if entry.ConversationID == "" || len(entry.Registers) == 0 {
+			continue
+		}
+		s.entries[entry.ConversationID] = cloneConversationSyncState(entry)
+	}
+	return nil
+}
+
+// Merge applies remote states through merge and persists the result. It
+// returns the merged states that changed.
+func (s *ConversationSyncStore) Merge(remote []models.ConversationSyncState, merge ConversationSyncMergeFunc) ([]models.ConversationSyncState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]models.ConversationSyncState, len(s.entries)+len(remote))
+	for id, state := range s.entries {
+		next[id] = state
+	}
+	changed := make([]models.ConversationSyncState, 0, len(remote))
+	for _, state := range remote {
+		id := strings.TrimSpace(state.ConversationID)
+		if id == "" {
+			continue
+		}
+		merged, updated := merge(next[id], state)
+		if !updated {
+			continue
+		}
+		merged.ConversationID = id
+		next[id] = cloneConversationSyncState(merged)
+		changed = append(changed, cloneConversationSyncState(merged))
+	}
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	if err := s.persistLocked(next); err != nil {
+		return nil, err
+	}
+	s.entries = next
+	return changed, nil
+}
+
+// Get returns the synced state of one conversation.
+func (s *ConversationSyncStore) Get(conversationID string) (models.ConversationSyncState, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.entries[strings.TrimSpace(conversationID)]
+	if !ok {
+		return models.ConversationSyncState{}, false
+	}
+	return cloneConversationSyncState(state), true
+}
+
+// List returns every synced conversation sorted by id.
+func (s *ConversationSyncStore) List() []models.ConversationSyncState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.ConversationSyncState, 0, len(s.entries))
+	for _, state := range s.entries {
+		out = append(out, cloneConversationSyncState(state))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ConversationID < out[j].ConversationID })
+	return out
+}
+
+func (s *ConversationSyncStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]models.ConversationSyncState{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ConversationSyncStore) persistLocked(entries map[string]models.ConversationSyncState) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	flat := make([]models.ConversationSyncState, 0, len(entries))
+	for _, state := range entries {
+		flat = append(flat, state)
+	}
+	sort.Slice(flat, func(i, j int) bool { return flat[i].ConversationID < flat[j].ConversationID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedConversationSync{
+		Version: conversationSyncSchemaVersion,
+		Entries: flat,
+	})
+}
+
+func cloneConversationSyncState(in models.ConversationSyncState) models.ConversationSyncState {
+	out := models.ConversationSyncState{
+		ConversationID: in.ConversationID,
+		Registers:      make(map[string]models.SyncRegister, len(in.Registers)),
+	}
+	for name, register := range in.Registers {
+		out.Registers[name] = register
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestConversationSyncStoreMergePersistsChangedStates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conversation_sync.enc")
+	store := NewConversationSyncStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	state := models.ConversationSyncState{
+		ConversationID: "aim1peer",
+		Registers: map[string]models.SyncRegister{
+			models.ConversationFlagPinned: {Value: "true", UpdatedAt: time.Now().UTC(), DeviceID: "dev-a"},
+		},
+	}
+	calls := 0
+	merge := func(local, remote models.ConversationSyncState) (models.ConversationSyncState, bool) {
+		calls++
+		if len(local.Registers) > 0 {
+			return local, false
+		}
+		return remote, true
+	}
+	changed, err := store.Merge([]models.ConversationSyncState{state}, merge)
+	if err != nil || len(changed) != 1 {
+		t.Fatalf("merge failed: changed=%v err=%v", changed, err)
+	}
+	if changed, err := store.Merge([]models.ConversationSyncState{state}, merge); err != nil || len(changed) != 0 {
+		t.Fatalf("unchanged merge must report nothing: changed=%v err=%v", changed, err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected merge to be consulted twice, got %d", calls)
+	}
+
+	reloaded := NewConversationSyncStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	got, ok := reloaded.Get("aim1peer")
+	if !ok || got.Registers[models.ConversationFlagPinned].Value != "true" {
+		t.Fatalf("state not persisted: %+v", got)
+	}
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if len(reloaded.List()) != 0 {
+		t.Fatal("wipe must clear synced states")
+	}
+}
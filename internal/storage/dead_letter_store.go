@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const deadLetterSchemaVersion = 1
+
+// DeadLetterStore keeps outbound messages that ran out of retries in an
+// encrypted per-account file.
+type DeadLetterStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	entries map[string]models.DeadLetter
+}
+
+type persistedDeadLetters struct {
+	Version int                 `json:"version"`
+	Entries []models.DeadLetter `json:"entries"`
+}
+
+func NewDeadLetterStore() *DeadLetterStore {
+	return &DeadLetterStore{entries: map[string]models.DeadLetter{}}
+}
+
+func (s *DeadLetterStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *DeadLetterStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]models.DeadLetter{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedDeadLetters
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != deadLetterSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, entry := range payload.Entries {
+		if entry.Message.ID != "" {
+			s.entries[entry.Message.ID] = entry
+		}
+	}
+	return nil
+}
+
+// Add records a message that ran out of retries, replacing an older entry
+// for the same message.
+func (s *DeadLetterStore) Add(entry models.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := cloneDeadLetters(s.entries)
+	next[entry.Message.ID] = entry
+	if err := s.persistLocked(next); err != nil {
+		return err
+	}
+	s.entries = next
+	return nil
+}
+
+// Get returns the entry of one message.
+func (s *DeadLetterStore) Get(messageID string) (models.DeadLetter, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[strings.TrimSpace(messageID)]
+	return entry, ok
+}
+
+// Remove drops a message from the queue and reports whether it was there.
+func (s *DeadLetterStore) Remove(messageID string) (bool, error) {
+	messageID = strings.TrimSpace(messageID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[messageID]; !ok {
+		return false, nil
+	}
+	next := cloneDeadLetters(s.entries)
+	delete(next, messageID)
+	if err := s.persistLocked(next); err != nil {
+		return false, err
+	}
+	s.entries = next
+	return true, nil
+}
+
+// List returns dead letters newest first, optionally for one contact.
+func (s *DeadLetterStore) List(contactID string, limit, offset int) []models.DeadLetter {
+	contactID = strings.TrimSpace(contactID)
+	s.mu.RLock()
+	out := make([]models.DeadLetter, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if contactID == "" || entry.Message.ContactID == contactID {
+			out = append(out, entry)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeadAt.Equal(out[j].DeadAt) {
+			return out[i].DeadAt.After(out[j].DeadAt)
+		}
+		return out[i].Message.ID < out[j].Message.ID
+	})
+	if offset >= len(out) {
+		return []models.DeadLetter{}
+	}
+	out = out[max(offset, 0):]
+	if limit > 0 && limit < len(out) {
+		out = out[:limit]
+	}
+	return out
+}
+
+func (s *DeadLetterStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]models.DeadLetter{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *DeadLetterStore) persistLocked(entries map[string]models.DeadLetter) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	payload := persistedDeadLetters{
+		Version: deadLetterSchemaVersion,
+		Entries: make([]models.DeadLetter, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		payload.Entries = append(payload.Entries, entry)
+	}
+	sort.Slice(payload.Entries, func(i, j int) bool { return payload.Entries[i].Message.ID < payload.Entries[j].Message.ID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, payload)
+}
+
+func cloneDeadLetters(in map[string]models.DeadLetter) map[string]models.DeadLetter {
+	out := make(map[string]models.DeadLetter, len(in))
+	for id, entry := range in {
+		out[id] = entry
+	}
+	return out
+}
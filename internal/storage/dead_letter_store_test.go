@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestDeadLetterStorePersistsAndLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letters.enc")
+	store := NewDeadLetterStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, entry := range []struct{ id, contact string }{{"m1", "aim1_a"}, {"m2", "aim1_b"}, {"m3", "aim1_a"}} {
+		if err := store.Add(models.DeadLetter{
+			Message:   models.Message{ID: entry.id, ContactID: entry.contact},
+			Attempts:  9,
+			LastError: "network",
+			DeadAt:    base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("add %s: %v", entry.id, err)
+		}
+	}
+
+	reloaded := NewDeadLetterStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	all := reloaded.List("", 0, 0)
+	if len(all) != 3 || all[0].Message.ID != "m3" || all[2].Message.ID != "m1" {
+		t.Fatalf("dead letters must be listed newest first: %+v", all)
+	}
+	forContact := reloaded.List("aim1_a", 1, 1)
+	if len(forContact) != 1 || forContact[0].Message.ID != "m1" {
+		t.Fatalf("unexpected contact page: %+v", forContact)
+	}
+
+	if removed, err := reloaded.Remove("m2"); err != nil || !removed {
+		t.Fatalf("remove m2: removed=%v err=%v", removed, err)
+	}
+	if removed, err := reloaded.Remove("m2"); err != nil || removed {
+		t.Fatalf("second remove must report nothing removed: removed=%v err=%v", removed, err)
+	}
+	if _, ok := reloaded.Get("m2"); ok {
+		t.Fatal("removed dead letter must be gone")
+	}
+
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if len(reloaded.List("", 0, 0)) != 0 {
+		t.Fatal("wipe must drop dead letters")
+	}
+}
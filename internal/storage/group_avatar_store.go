@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const groupAvatarSchemaVersion = 1
+
+var ErrGroupAvatarNotFound = errors.New("group avatar not found")
+
+// GroupAvatarStore caches the current avatar image of each group in an
+// encrypted per-account file.
+type GroupAvatarStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	avatars map[string]models.GroupAvatar
+}
+
+type persistedGroupAvatars struct {
+	Version int                  `json:"version"`
+	Avatars []models.GroupAvatar `json:"avatars"`
+}
+
+func NewGroupAvatarStore() *GroupAvatarStore {
+	return &GroupAvatarStore{
+		avatars: map[string]models.GroupAvatar{},
+	}
+}
+
+func (s *GroupAvatarStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *GroupAvatarStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.avatars = map[string]models.GroupAvatar{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedGroupAvatars
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != groupAvatarSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, avatar := range payload.Avatars {
+		if avatar.GroupID == "" || len(avatar.Data) == 0 {
+			continue
+		}
+		s.avatars[avatar.GroupID] = cloneGroupAvatar(avatar)
+	}
+	return nil
+}
+
+// Put replaces the cached avatar of avatar.GroupID.
+func (s *GroupAvatarStore) Put(avatar models.GroupAvatar) error {
+	avatar.GroupID = strings.TrimSpace(avatar.GroupID)
+	if avatar.GroupID == "" || len(avatar.Data) == 0 {
+		return errors.New("group avatar requires group id and data")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]models.GroupAvatar, len(s.avatars)+1)
+	for id, existing := range s.avatars {
+		next[id] = existing
+	}
+	next[avatar.GroupID] = cloneGroupAvatar(avatar)
+	if err := s.persistLocked(next); err != nil {
+		return err
+	}
+	s.avatars = next
+	return nil
+}
+
+// Get returns the cached avatar of a group.
+func (s *GroupAvatarStore) Get(groupID string) (models.GroupAvatar, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	avatar, ok := s.avatars[strings.TrimSpace(groupID)]
+	if !ok {
+		return models.GroupAvatar{}, false
+	}
+	return cloneGroupAvatar(avatar), true
+}
+
+func (s *GroupAvatarStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.avatars = map[string]models.GroupAvatar{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *GroupAvatarStore) persistLocked(avatars map[string]models.GroupAvatar) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	flat := make([]models.GroupAvatar, 0, len(avatars))
+	for _, avatar := range avatars {
+		flat = append(flat, avatar)
+	}
+	sort.Slice(flat, func(i, j int) bool { return flat[i].GroupID < flat[j].GroupID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedGroupAvatars{
+		Version: groupAvatarSchemaVersion,
+		Avatars: flat,
+	})
+}
+
+func cloneGroupAvatar(in models.GroupAvatar) models.GroupAvatar {
+	out := in
+	out.Data = append([]byte(nil), in.Data...)
+	return out
+}
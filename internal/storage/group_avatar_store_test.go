@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestGroupAvatarStorePersistsAcrossBootstrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "group_avatars.enc")
+	store := NewGroupAvatarStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	avatar := models.GroupAvatar{GroupID: "group-1", SHA256: "abc", MimeType: "image/jpeg", Width: 2, Height: 2, Data: []byte{1, 2, 3}}
+	if err := store.Put(avatar); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if err := store.Put(models.GroupAvatar{GroupID: "group-2"}); err == nil {
+		t.Fatal("avatar without data must be rejected")
+	}
+
+	reloaded := NewGroupAvatarStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	got, ok := reloaded.Get("group-1")
+	if !ok || got.SHA256 != "abc" || !bytes.Equal(got.Data, avatar.Data) {
+		t.Fatalf("unexpected reloaded avatar: %+v", got)
+	}
+
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if _, ok := reloaded.Get("group-1"); ok {
+		t.Fatal("wipe must drop cached avatars")
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const handoffSchemaVersion = 1
+
+// ConversationHandoffStore keeps conversation handoffs in an encrypted file
+// shared by every account on the daemon. It holds no lock: callers load it,
+// change it and save it under their own lock, since any account may write.
+type ConversationHandoffStore struct {
+	path     string
+	secret   string
+	handoffs map[string]models.ConversationHandoff
+}
+
+type persistedHandoffs struct {
+	Version  int                          `json:"version"`
+	Handoffs []models.ConversationHandoff `json:"handoffs"`
+}
+
+func NewConversationHandoffStore() *ConversationHandoffStore {
+	return &ConversationHandoffStore{handoffs: map[string]models.ConversationHandoff{}}
+}
+
+func (s *ConversationHandoffStore) Configure(path, secret string) {
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *ConversationHandoffStore) Bootstrap() error {
+	s.handoffs = map[string]models.ConversationHandoff{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedHandoffs
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != handoffSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, handoff := range payload.Handoffs {
+		if handoff.ID != "" {
+			s.handoffs[handoff.ID] = handoff
+		}
+	}
+	return nil
+}
+
+func (s *ConversationHandoffStore) Get(id string) (models.ConversationHandoff, bool) {
+	handoff, ok := s.handoffs[strings.TrimSpace(id)]
+	return handoff, ok
+}
+
+// Put stores a handoff and drops the ones expired before now.
+func (s *ConversationHandoffStore) Put(handoff models.ConversationHandoff, now time.Time) error {
+	next := make(map[string]models.ConversationHandoff, len(s.handoffs)+1)
+	for id, existing := range s.handoffs {
+		if existing.ExpiresAt.After(now) {
+			next[id] = existing
+		}
+	}
+	next[handoff.ID] = handoff
+	if err := s.persist(next); err != nil {
+		return err
+	}
+	s.handoffs = next
+	return nil
+}
+
+func (s *ConversationHandoffStore) persist(handoffs map[string]models.ConversationHandoff) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	payload := persistedHandoffs{
+		Version:  handoffSchemaVersion,
+		Handoffs: make([]models.ConversationHandoff, 0, len(handoffs)),
+	}
+	for _, handoff := range handoffs {
+		payload.Handoffs = append(payload.Handoffs, handoff)
+	}
+	sort.Slice(payload.Handoffs, func(i, j int) bool { return payload.Handoffs[i].ID < payload.Handoffs[j].ID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, payload)
+}
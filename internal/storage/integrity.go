@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+// RecordCorruption names a record whose checksum does not match, or one the
+// checksums list but the document lost.
+type RecordCorruption struct {
+	Field   string
+	Key     string
+	Missing bool
+}
+
+// StoreScrubResult is the outcome of checking one store file.
+type StoreScrubResult struct {
+	Store   string
+	Path    string
+	Present bool
+	Records int
+	// Unverified counts plaintext records written before checksums existed.
+	Unverified int
+	Corrupt    []RecordCorruption
+	// Unreadable is set when the file's envelope is damaged or fails
+	// authentication, so none of its
+	// records can be trusted and the whole file needs restoring.
+	Unreadable bool
+}
+
+// ScrubStoreFile checks one store file in dir. An encrypted file is
+// authenticated as a whole, which covers every record in it; one that fails
+// is reported as unreadable. Plaintext files are checked record by record.
+// Absent files are reported as not present.
+func ScrubStoreFile(dir, secret string, schema StoreSchema) (StoreScrubResult, error) {
+	result := StoreScrubResult{Store: schema.Store, Path: filepath.Join(dir, schema.File)}
+	doc, encrypted, err := readSchemaDocument(result.Path, secret)
+	if err != nil {
+		if isSkippedSchemaDocument(err) {
+			return result, nil
+		}
+		if isDamagedEnvelope(err) {
+			result.Present = true
+			result.Unreadable = true
+			return result, nil
+		}
+		return result, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	result.Present = true
+	records := securestore.DocumentRecords(doc)
+	if encrypted {
+		for _, field := range records {
+			result.Records += len(field)
+		}
+		return result, nil
+	}
+	sums := securestore.DocumentRecordChecksums(doc)
+	for _, field := range sortedRecordFields(records) {
+		for _, key := range sortedRecordKeys(records[field]) {
+			result.Records++
+			if sums == nil {
+				result.Unverified++
+				continue
+			}
+			if sums[field][key] != securestore.RecordChecksum(records[field][key]) {
+				result.Corrupt = append(result.Corrupt, RecordCorruption{Field: field, Key: key})
+			}
+		}
+	}
+	for field, fieldSums := range sums {
+		for key := range fieldSums {
+			if _, ok := records[field][key]; !ok {
+				result.Corrupt = append(result.Corrupt, RecordCorruption{Field: field, Key: key, Missing: true})
+			}
+		}
+	}
+	sort.SliceStable(result.Corrupt, func(i, j int) bool {
+		if result.Corrupt[i].Field != result.Corrupt[j].Field {
+			return result.Corrupt[i].Field < result.Corrupt[j].Field
+		}
+		return result.Corrupt[i].Key < result.Corrupt[j].Key
+	})
+	return result, nil
+}
+
+// RepairStoreRecords replaces corrupt records of the store file in dir with
+// their copies in the same file below snapshotDir. A copy is used when it
+// matches the checksum the live file expects, or, for keyed records, when it
+// verifies against the snapshot's own checksums. It returns the records it
+// restored; the rest are left as they are.
+func RepairStoreRecords(dir, snapshotDir, secret string, schema StoreSchema, corrupt []RecordCorruption) ([]RecordCorruption, error) {
+	if len(corrupt) == 0 {
+		return nil, nil
+	}
+	path := filepath.Join(dir, schema.File)
+	doc, encrypted, err := readSchemaDocument(path, secret)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	backup, _, err := readSchemaDocument(filepath.Join(snapshotDir, schema.File), secret)
+	if err != nil {
+		if isSkippedSchemaDocument(err) || isDamagedEnvelope(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s snapshot: %w", schema.Store, err)
+	}
+	expected := securestore.DocumentRecordChecksums(doc)
+	backupRecords := securestore.DocumentRecords(backup)
+	backupSums := securestore.DocumentRecordChecksums(backup)
+
+	var restored []RecordCorruption
+	for _, record := range corrupt {
+		replacement, ok := findRecordCopy(backupRecords[record.Field], backupSums[record.Field], record.Key, expected[record.Field][record.Key])
+		if !ok {
+			continue
+		}
+		if !replaceDocumentRecord(doc, record.Field, record.Key, replacement) {
+			continue
+		}
+		restored = append(restored, record)
+	}
+	if len(restored) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		if data, err = securestore.Encrypt(secret, data); err != nil {
+			return nil, err
+		}
+	} else if data, err = securestore.AddRecordChecksums(data); err != nil {
+		return nil, err
+	}
+	perm := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := replaceStoreFile(path, data, perm); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// RestoreStoreFile replaces the store file in dir with its copy below
+// snapshotDir. It returns false, leaving the file alone, when the snapshot
+// has no copy or its copy does not decrypt with secret either.
+func RestoreStoreFile(dir, snapshotDir, secret string, schema StoreSchema) (bool, error) {
+	backupPath := filepath.Join(snapshotDir, schema.File)
+	if _, _, err := readSchemaDocument(backupPath, secret); err != nil {
+		if isSkippedSchemaDocument(err) || isDamagedEnvelope(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s snapshot: %w", schema.Store, err)
+	}
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, schema.File)
+	perm := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := replaceStoreFile(path, data, perm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// findRecordCopy prefers the exact record the live file expects, wherever it
+// sits in the snapshot, and falls back to the snapshot's own verified record
+// under the same key.
+func findRecordCopy(records map[string]json.RawMessage, sums map[string]string, key, want string) (json.RawMessage, bool) {
+	if want != "" {
+		for _, candidate := range sortedRecordKeys(records) {
+			if securestore.RecordChecksum(records[candidate]) == want {
+				return records[candidate], true
+			}
+		}
+	}
+	raw, ok := records[key]
+	if !ok || sums == nil || sums[key] != securestore.RecordChecksum(raw) {
+		return nil, false
+	}
+	return raw, true
+}
+
+// isDamagedEnvelope reports an encrypted file that cannot be opened with the
+// working secret: its envelope no longer parses or fails authentication.
+func isDamagedEnvelope(err error) bool {
+	return errors.Is(err, securestore.ErrAuthFailed) || errors.Is(err, securestore.ErrInvalid)
+}
+
+func replaceDocumentRecord(doc map[string]json.RawMessage, field, key string, record json.RawMessage) bool {
+	raw := bytes.TrimSpace(doc[field])
+	switch {
+	case len(raw) == 0 || raw[0] == '{' || bytes.Equal(raw, []byte("null")):
+		records := map[string]json.RawMessage{}
+		if len(raw) > 0 && raw[0] == '{' {
+			if err := json.Unmarshal(raw, &records); err != nil {
+				return false
+			}
+		}
+		records[key] = record
+		encoded, err := json.Marshal(records)
+		if err != nil {
+			return false
+		}
+		doc[field] = encoded
+		return true
+	case raw[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return false
+		}
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(items) {
+			return false
+		}
+		items[index] = record
+		encoded, err := json.Marshal(items)
+		if err != nil {
+			return false
+		}
+		doc[field] = encoded
+		return true
+	default:
+		return false
+	}
+}
+
+func sortedRecordFields(records map[string]map[string]json.RawMessage) []string {
+	out := make([]string, 0, len(records))
+	for field := range records {
+		out = append(out, field)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedRecordKeys(records map[string]json.RawMessage) []string {
+	out := make([]string, 0, len(records))
+	for key := range records {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
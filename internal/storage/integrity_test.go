@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+// tamperStoreFile rewrites a plaintext store file through edit without
+// updating its checksums, the way a bad write or bit rot would.
+func tamperStoreFile(t *testing.T, path string, edit func(doc map[string]json.RawMessage)) {
+	t.Helper()
+	plaintext, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(plaintext, &doc); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	edit(doc)
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encode %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestScrubStoreFileFindsAndRepairsCorruptRecords(t *testing.T) {
+	secret := ""
+	dir := t.TempDir()
+	snapshotDir := t.TempDir()
+	schema := StoreSchema{Store: "messages", File: "messages.json", VersionField: "schema_version", Current: messageStoreSchemaVersion}
+
+	store, err := NewEncryptedPersistentMessageStore(filepath.Join(dir, schema.File), secret)
+	if err != nil {
+		t.Fatalf("new message store: %v", err)
+	}
+	for _, id := range []string{"m1", "m2"} {
+		if err := store.SaveMessage(models.Message{ID: id, ContactID: "aim1peer", Content: []byte("hello " + id), Direction: "in"}); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+	}
+	result, err := ScrubStoreFile(dir, secret, schema)
+	if err != nil || !result.Present || result.Records != 2 || len(result.Corrupt) != 0 || result.Unverified != 0 {
+		t.Fatalf("expected a clean store, got %+v err=%v", result, err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, schema.File))
+	if err != nil {
+		t.Fatalf("read store: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(snapshotDir, schema.File), raw, 0o600); err != nil {
+		t.Fatalf("write snapshot copy: %v", err)
+	}
+
+	tamperStoreFile(t, filepath.Join(dir, schema.File), func(doc map[string]json.RawMessage) {
+		messages := map[string]json.RawMessage{}
+		_ = json.Unmarshal(doc["messages"], &messages)
+		messages["m2"] = json.RawMessage(strings.Replace(string(messages["m2"]), `"direction":"in"`, `"direction":"out"`, 1))
+		doc["messages"], _ = json.Marshal(messages)
+	})
+	result, err = ScrubStoreFile(dir, secret, schema)
+	if err != nil || len(result.Corrupt) != 1 || result.Corrupt[0] != (RecordCorruption{Field: "messages", Key: "m2"}) {
+		t.Fatalf("expected m2 to be reported corrupt, got %+v err=%v", result, err)
+	}
+
+	restored, err := RepairStoreRecords(dir, snapshotDir, secret, schema, result.Corrupt)
+	if err != nil || len(restored) != 1 {
+		t.Fatalf("expected m2 to be restored, got %+v err=%v", restored, err)
+	}
+	if result, err := ScrubStoreFile(dir, secret, schema); err != nil || len(result.Corrupt) != 0 || result.Records != 2 {
+		t.Fatalf("expected a clean store after repair, got %+v err=%v", result, err)
+	}
+	reloaded, err := NewEncryptedPersistentMessageStore(filepath.Join(dir, schema.File), secret)
+	if err != nil {
+		t.Fatalf("reload message store: %v", err)
+	}
+	if msg, ok := reloaded.GetMessage("m2"); !ok || msg.Direction != "in" {
+		t.Fatalf("expected the snapshot copy of m2, got %+v ok=%v", msg, ok)
+	}
+}
+
+func TestScrubStoreFileReportsLegacyPlaintextRecordsAsUnverified(t *testing.T) {
+	secret := "test-secret"
+	dir := t.TempDir()
+	schema := StoreSchema{Store: "client_state", File: "client_state.enc", VersionField: "version", Current: clientStateSchemaVersion}
+	payload, _ := json.Marshal(map[string]any{
+		"version": clientStateSchemaVersion,
+		"entries": []map[string]string{{"namespace": "ui", "key": "theme", "value": "dark"}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, schema.File), payload, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err := ScrubStoreFile(dir, secret, schema)
+	if err != nil || result.Records != 1 || result.Unverified != 1 || len(result.Corrupt) != 0 {
+		t.Fatalf("expected one unverified record, got %+v err=%v", result, err)
+	}
+
+	// The envelope of an encrypted file authenticates all of its records.
+	encrypted, err := securestore.Encrypt(secret, payload)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, schema.File), encrypted, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err = ScrubStoreFile(dir, secret, schema)
+	if err != nil || result.Records != 1 || result.Unverified != 0 || len(result.Corrupt) != 0 {
+		t.Fatalf("expected the encrypted record to count as verified, got %+v err=%v", result, err)
+	}
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	ErrMessageAnnotationInvalid       = errors.New("annotation message id and key must be non-empty printable strings")
+	ErrMessageAnnotationQuotaExceeded = errors.New("message annotation quota exceeded")
+)
+
+const (
+	messageAnnotationSchemaVersion = 1
+
+	maxMessageAnnotationKeyBytes      = 64
+	maxMessageAnnotationValueBytes    = 4 << 10
+	maxMessageAnnotationsPerMessage   = 32
+	maxMessageAnnotationsPerNamespace = 10000
+)
+
+// MessageAnnotationStore keeps integration-private message annotations in an
+// encrypted per-account file. Every integration gets its own namespace and
+// never sees entries written under another one.
+type MessageAnnotationStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	entries map[string]map[string]map[string]models.MessageAnnotation
+}
+
+type persistedMessageAnnotation struct {
+	Namespace string `json:"namespace"`
+	models.MessageAnnotation
+}
+
+type persistedMessageAnnotations struct {
+	Version int                          `json:"version"`
+	Entries []persistedMessageAnnotation `json:"entries"`
+}
+
+func NewMessageAnnotationStore() *MessageAnnotationStore {
+	return &MessageAnnotationStore{
+		entries: map[string]map[string]map[string]models.MessageAnnotation{},
+	}
+}
+
+func (s *MessageAnnotationStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *MessageAnnotationStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]map[string]models.MessageAnnotation{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedMessageAnnotations
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != messageAnnotationSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, entry := range payload.Entries {
+		if entry.Namespace == "" || entry.MessageID == "" || entry.Key == "" {
+			continue
+		}
+		setMessageAnnotation(s.entries, entry.Namespace, entry.MessageAnnotation)
+	}
+	return nil
+}
+
+// Set creates or replaces one annotation of a message within a namespace.
+func (s *MessageAnnotationStore) Set(namespace, messageID, key, value string) (models.MessageAnnotation, error) {
+	namespace = strings.TrimSpace(namespace)
+	messageID = strings.TrimSpace(messageID)
+	key = strings.TrimSpace(key)
+	if namespace == "" || !isValidAnnotationName(messageID) || !isValidAnnotationName(key) || len(key) > maxMessageAnnotationKeyBytes {
+		return models.MessageAnnotation{}, ErrMessageAnnotationInvalid
+	}
+	if len(value) > maxMessageAnnotationValueBytes {
+		return models.MessageAnnotation{}, fmt.Errorf("%w: value exceeds %d bytes", ErrMessageAnnotationQuotaExceeded, maxMessageAnnotationValueBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byMessage := s.entries[namespace]
+	if _, exists := byMessage[messageID][key]; !exists {
+		if len(byMessage[messageID]) >= maxMessageAnnotationsPerMessage {
+			return models.MessageAnnotation{}, fmt.Errorf("%w: message holds %d annotations", ErrMessageAnnotationQuotaExceeded, maxMessageAnnotationsPerMessage)
+		}
+		if countNamespaceAnnotations(byMessage) >= maxMessageAnnotationsPerNamespace {
+			return models.MessageAnnotation{}, fmt.Errorf("%w: namespace holds %d annotations", ErrMessageAnnotationQuotaExceeded, maxMessageAnnotationsPerNamespace)
+		}
+	}
+
+	annotation := models.MessageAnnotation{MessageID: messageID, Key: key, Value: value, UpdatedAt: time.Now().UTC()}
+	next := cloneMessageAnnotations(s.entries)
+	setMessageAnnotation(next, namespace, annotation)
+	if err := s.persistLocked(next); err != nil {
+		return models.MessageAnnotation{}, err
+	}
+	s.entries = next
+	return annotation, nil
+}
+
+// Remove deletes one annotation and reports whether it existed.
+func (s *MessageAnnotationStore) Remove(namespace, messageID, key string) (bool, error) {
+	namespace = strings.TrimSpace(namespace)
+	messageID = strings.TrimSpace(messageID)
+	key = strings.TrimSpace(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[namespace][messageID][key]; !ok {
+		return false, nil
+	}
+	next := cloneMessageAnnotations(s.entries)
+	delete(next[namespace][messageID], key)
+	if len(next[namespace][messageID]) == 0 {
+		delete(next[namespace], messageID)
+	}
+	if len(next[namespace]) == 0 {
+		delete(next, namespace)
+	}
+	if err := s.persistLocked(next); err != nil {
+		return false, err
+	}
+	s.entries = next
+	return true, nil
+}
+
+// List returns the annotations a namespace holds for a message, sorted by key.
+func (s *MessageAnnotationStore) List(namespace, messageID string) []models.MessageAnnotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bucket := s.entries[strings.TrimSpace(namespace)][strings.TrimSpace(messageID)]
+	out := make([]models.MessageAnnotation, 0, len(bucket))
+	for _, annotation := range bucket {
+		out = append(out, annotation)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func (s *MessageAnnotationStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]map[string]models.MessageAnnotation{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *MessageAnnotationStore) persistLocked(entries map[string]map[string]map[string]models.MessageAnnotation) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	flat := make([]persistedMessageAnnotation, 0)
+	for namespace, byMessage := range entries {
+		for _, bucket := range byMessage {
+			for _, annotation := range bucket {
+				flat = append(flat, persistedMessageAnnotation{Namespace: namespace, MessageAnnotation: annotation})
+			}
+		}
+	}
+	sort.Slice(flat, func(i, j int) bool {
+		if flat[i].Namespace != flat[j].Namespace {
+			return flat[i].Namespace < flat[j].Namespace
+		}
+		if flat[i].MessageID != flat[j].MessageID {
+			return flat[i].MessageID < flat[j].MessageID
+		}
+		return flat[i].Key < flat[j].Key
+	})
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedMessageAnnotations{
+		Version: messageAnnotationSchemaVersion,
+		Entries: flat,
+	})
+}
+
+func isValidAnnotationName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool { return !unicode.IsPrint(r) }) < 0
+}
+
+func countNamespaceAnnotations(byMessage map[string]map[string]models.MessageAnnotation) int {
+	total := 0
+	for _, bucket := range byMessage {
+		total += len(bucket)
+	}
+	return total
+}
+
+func setMessageAnnotation(entries map[string]map[string]map[string]models.MessageAnnotation, namespace string, annotation models.MessageAnnotation) {
+	if entries[namespace] == nil {
+		entries[namespace] = map[string]map[string]models.MessageAnnotation{}
+	}
+	if entries[namespace][annotation.MessageID] == nil {
+		entries[namespace][annotation.MessageID] = map[string]models.MessageAnnotation{}
+	}
+	entries[namespace][annotation.MessageID][annotation.Key] = annotation
+}
+
+func cloneMessageAnnotations(in map[string]map[string]map[string]models.MessageAnnotation) map[string]map[string]map[string]models.MessageAnnotation {
+	out := make(map[string]map[string]map[string]models.MessageAnnotation, len(in))
+	for namespace, byMessage := range in {
+		copiedNamespace := make(map[string]map[string]models.MessageAnnotation, len(byMessage))
+		for messageID, bucket := range byMessage {
+			copiedBucket := make(map[string]models.MessageAnnotation, len(bucket))
+			for key, annotation := range bucket {
+				copiedBucket[key] = annotation
+			}
+			copiedNamespace[messageID] = copiedBucket
+		}
+		out[namespace] = copiedNamespace
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMessageAnnotationStoreNamespacesAreIsolated(t *testing.T) {
+	store := NewMessageAnnotationStore()
+	if _, err := store.Set("integration:bot", "m-1", "ticket", "T-1"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if _, err := store.Set("integration:bridge", "m-1", "ticket", "B-7"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	bot := store.List("integration:bot", "m-1")
+	if len(bot) != 1 || bot[0].Value != "T-1" {
+		t.Fatalf("unexpected bot annotations: %#v", bot)
+	}
+	if removed, err := store.Remove("integration:bot", "m-1", "ticket"); err != nil || !removed {
+		t.Fatalf("remove failed: removed=%v err=%v", removed, err)
+	}
+	if len(store.List("integration:bot", "m-1")) != 0 {
+		t.Fatal("removed annotation must not be listed")
+	}
+	if bridge := store.List("integration:bridge", "m-1"); len(bridge) != 1 {
+		t.Fatalf("remove must not touch other namespaces: %#v", bridge)
+	}
+}
+
+func TestMessageAnnotationStoreLimits(t *testing.T) {
+	store := NewMessageAnnotationStore()
+	if _, err := store.Set("ns", "m-1", "", "v"); !errors.Is(err, ErrMessageAnnotationInvalid) {
+		t.Fatalf("expected invalid annotation error, got %v", err)
+	}
+	if _, err := store.Set("ns", "m-1", "k", string(make([]byte, maxMessageAnnotationValueBytes+1))); !errors.Is(err, ErrMessageAnnotationQuotaExceeded) {
+		t.Fatalf("expected value quota error, got %v", err)
+	}
+	for i := 0; i < maxMessageAnnotationsPerMessage; i++ {
+		if _, err := store.Set("ns", "m-1", string(rune('A'+i)), "v"); err != nil {
+			t.Fatalf("set %d failed: %v", i, err)
+		}
+	}
+	if _, err := store.Set("ns", "m-1", "overflow", "v"); !errors.Is(err, ErrMessageAnnotationQuotaExceeded) {
+		t.Fatalf("expected per-message quota error, got %v", err)
+	}
+}
+
+func TestMessageAnnotationStorePersistsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "message_annotations.enc")
+	store := NewMessageAnnotationStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if _, err := store.Set("integration:bot", "m-1", "label", "spam"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	reloaded := NewMessageAnnotationStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	list := reloaded.List("integration:bot", "m-1")
+	if len(list) != 1 || list[0].Key != "label" || list[0].Value != "spam" {
+		t.Fatalf("annotation not persisted: %#v", list)
+	}
+}
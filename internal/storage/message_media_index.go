@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+type mediaIndexEntry struct {
+	id        string
+	timestamp time.Time
+}
+
+// mediaIndexBuckets lists a conversation's attachment-bearing messages per
+// attachment class, newest first. The "" bucket holds every such message.
+type mediaIndexBuckets map[models.AttachmentClass][]mediaIndexEntry
+
+func mediaIndexKey(conversationID, conversationType string) string {
+	return conversationType + "\x00" + conversationID
+}
+
+// ListMediaMessages returns the messages of a conversation that carry at
+// least one attachment of the given class, newest first. An empty class
+// selects every attachment-bearing message. Only the requested page is
+// copied; the class index is shared until the next change to the store.
+func (s *MessageStore) ListMediaMessages(conversationID, conversationType string, class models.AttachmentClass, limit, offset int) []models.Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	conversationID = strings.TrimSpace(conversationID)
+	conversationType = models.NormalizeConversationType(conversationType)
+	index := s.mediaIndexLocked()[mediaIndexKey(conversationID, conversationType)][class]
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(index) {
+		return []models.Message{}
+	}
+	index = index[offset:]
+	if limit > 0 && limit < len(index) {
+		index = index[:limit]
+	}
+	out := make([]models.Message, 0, len(index))
+	for _, entry := range index {
+		out = append(out, models.NormalizeMessageConversation(s.messages[entry.id]))
+	}
+	return out
+}
+
+// mediaIndexLocked returns the gallery view, building it if a change dropped
+// it. Callers hold s.mu for reading; writers drop the view under the write
+// lock, so readers only race each other here.
+func (s *MessageStore) mediaIndexLocked() map[string]mediaIndexBuckets {
+	s.mediaMu.Lock()
+	defer s.mediaMu.Unlock()
+	if s.media != nil {
+		return s.media
+	}
+	media := make(map[string]mediaIndexBuckets)
+	for id, msg := range s.messages {
+		if len(msg.Attachments) == 0 {
+			continue
+		}
+		msg = models.NormalizeMessageConversation(msg)
+		key := mediaIndexKey(msg.ConversationID, msg.ConversationType)
+		buckets := media[key]
+		if buckets == nil {
+			buckets = mediaIndexBuckets{}
+			media[key] = buckets
+		}
+		entry := mediaIndexEntry{id: id, timestamp: msg.Timestamp}
+		buckets[""] = append(buckets[""], entry)
+		seen := map[models.AttachmentClass]bool{}
+		for _, attachment := range msg.Attachments {
+			class := models.ClassifyAttachmentMime(attachment.MimeType)
+			if !seen[class] {
+				seen[class] = true
+				buckets[class] = append(buckets[class], entry)
+			}
+		}
+	}
+	for _, buckets := range media {
+		for _, entries := range buckets {
+			sort.Slice(entries, func(i, j int) bool {
+				if !entries[i].timestamp.Equal(entries[j].timestamp) {
+					return entries[i].timestamp.After(entries[j].timestamp)
+				}
+				return entries[i].id > entries[j].id
+			})
+		}
+	}
+	s.media = media
+	return media
+}
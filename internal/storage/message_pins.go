@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+var ErrPinnedMessagesLimit = errors.New("pinned messages limit reached")
+
+// PinMessage pins a stored message in its conversation. Pinning an already
+// pinned message keeps the original pin. The bool reports whether the
+// message exists.
+func (s *MessageStore) PinMessage(messageID, pinnedBy string, at time.Time) (models.MessagePin, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[messageID]
+	if !ok {
+		return models.MessagePin{}, false, nil
+	}
+	if pin, pinned := s.pins[messageID]; pinned {
+		return pin, true, nil
+	}
+	msg = models.NormalizeMessageConversation(msg)
+	nextPins := s.livePinsLocked()
+	count := 0
+	for _, pin := range nextPins {
+		if pin.ConversationID == msg.ConversationID && pin.ConversationType == msg.ConversationType {
+			count++
+		}
+	}
+	if count >= models.MaxPinnedMessages {
+		return models.MessagePin{}, true, ErrPinnedMessagesLimit
+	}
+	pin := models.MessagePin{
+		ConversationID:   msg.ConversationID,
+		ConversationType: msg.ConversationType,
+		MessageID:        messageID,
+		PinnedBy:         pinnedBy,
+		PinnedAt:         at.UTC(),
+	}
+	nextPins[messageID] = pin
+	if err := s.persistLocked(s.messages, s.pending, nextPins); err != nil {
+		return models.MessagePin{}, true, err
+	}
+	s.pins = nextPins
+	return pin, true, nil
+}
+
+// UnpinMessage removes the pin of a message and reports whether it was
+// pinned.
+func (s *MessageStore) UnpinMessage(messageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, pinned := s.pins[messageID]; !pinned {
+		return false, nil
+	}
+	nextPins := s.livePinsLocked()
+	delete(nextPins, messageID)
+	if err := s.persistLocked(s.messages, s.pending, nextPins); err != nil {
+		return false, err
+	}
+	s.pins = nextPins
+	return true, nil
+}
+
+// ListPinnedMessages returns the pinned messages of a conversation, most
+// recently pinned first.
+func (s *MessageStore) ListPinnedMessages(conversationID, conversationType string) []models.PinnedMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	conversationType = models.NormalizeConversationType(conversationType)
+	out := make([]models.PinnedMessage, 0)
+	for messageID, pin := range s.pins {
+		if pin.ConversationID != conversationID || pin.ConversationType != conversationType {
+			continue
+		}
+		msg, ok := s.messages[messageID]
+		if !ok {
+			continue
+		}
+		out = append(out, models.PinnedMessage{MessagePin: pin, Message: msg})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].PinnedAt.Equal(out[j].PinnedAt) {
+			return out[i].PinnedAt.After(out[j].PinnedAt)
+		}
+		return out[i].MessageID < out[j].MessageID
+	})
+	return out
+}
+
+// livePinsLocked copies the pins whose messages still exist, dropping those
+// left behind by deleted or purged messages.
+func (s *MessageStore) livePinsLocked() map[string]models.MessagePin {
+	out := make(map[string]models.MessagePin, len(s.pins)+1)
+	for messageID, pin := range s.pins {
+		if _, ok := s.messages[messageID]; ok {
+			out[messageID] = pin
+		}
+	}
+	return out
+}
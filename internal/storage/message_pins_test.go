@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestMessageStorePinsPersistAndListNewestFirst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.enc")
+	store, err := NewEncryptedPersistentMessageStore(path, "pass")
+	if err != nil {
+		t.Fatalf("new store failed: %v", err)
+	}
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if err := store.SaveMessage(models.Message{ID: id, ContactID: "c1", Status: "sent", Timestamp: base}); err != nil {
+			t.Fatalf("save message failed: %v", err)
+		}
+	}
+	if _, ok, err := store.PinMessage("m1", "alice", base); err != nil || !ok {
+		t.Fatalf("pin m1 failed: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := store.PinMessage("m2", "alice", base.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("pin m2 failed: ok=%v err=%v", ok, err)
+	}
+	again, _, err := store.PinMessage("m1", "bob", base.Add(2*time.Minute))
+	if err != nil || again.PinnedBy != "alice" || !again.PinnedAt.Equal(base) {
+		t.Fatalf("expected repeated pin to keep the original, got %+v %v", again, err)
+	}
+	if _, ok, _ := store.PinMessage("missing", "alice", base); ok {
+		t.Fatal("expected unknown message to be reported missing")
+	}
+
+	reloaded, err := NewEncryptedPersistentMessageStore(path, "pass")
+	if err != nil {
+		t.Fatalf("reload store failed: %v", err)
+	}
+	pins := reloaded.ListPinnedMessages("c1", models.ConversationTypeDirect)
+	if len(pins) != 2 || pins[0].MessageID != "m2" || pins[1].MessageID != "m1" || pins[1].Message.ID != "m1" {
+		t.Fatalf("unexpected pins after reload: %+v", pins)
+	}
+	if got := reloaded.ListPinnedMessages("c1", models.ConversationTypeGroup); len(got) != 0 {
+		t.Fatalf("expected no group pins, got %+v", got)
+	}
+
+	if removed, err := reloaded.UnpinMessage("m2"); err != nil || !removed {
+		t.Fatalf("unpin m2 failed: removed=%v err=%v", removed, err)
+	}
+	if removed, err := reloaded.UnpinMessage("m2"); err != nil || removed {
+		t.Fatalf("expected second unpin to be a no-op: removed=%v err=%v", removed, err)
+	}
+	if _, err := reloaded.DeleteMessage("c1", "m1"); err != nil {
+		t.Fatalf("delete message failed: %v", err)
+	}
+	if pins := reloaded.ListPinnedMessages("c1", models.ConversationTypeDirect); len(pins) != 0 {
+		t.Fatalf("expected deleted message to drop out of pins, got %+v", pins)
+	}
+}
+
+func TestMessageStorePinLimitPerConversation(t *testing.T) {
+	s := NewMessageStore()
+	now := time.Now().UTC()
+	for i := 0; i <= models.MaxPinnedMessages; i++ {
+		id := fmt.Sprintf("m%d", i)
+		if err := s.SaveMessage(models.Message{ID: id, ContactID: "c1", Status: "sent", Timestamp: now}); err != nil {
+			t.Fatalf("save message failed: %v", err)
+		}
+		_, _, err := s.PinMessage(id, "alice", now)
+		if i < models.MaxPinnedMessages && err != nil {
+			t.Fatalf("pin %s failed: %v", id, err)
+		}
+		if i == models.MaxPinnedMessages && !errors.Is(err, ErrPinnedMessagesLimit) {
+			t.Fatalf("expected pin limit error, got %v", err)
+		}
+	}
+	if err := s.SaveMessage(models.Message{ID: "other", ContactID: "c2", Status: "sent", Timestamp: now}); err != nil {
+		t.Fatalf("save message failed: %v", err)
+	}
+	if _, _, err := s.PinMessage("other", "alice", now); err != nil {
+		t.Fatalf("limit must be per conversation: %v", err)
+	}
+}
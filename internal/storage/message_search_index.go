@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	// maxSearchTokenRunes keeps pasted blobs such as keys or URLs from
+	// bloating the index with huge single tokens.
+	maxSearchTokenRunes = 64
+	maxSearchQueryTerms = 16
+)
+
+// messageSearchIndex is an inverted index over the text messages of a
+// MessageStore. It is maintained under the store's write lock alongside
+// every change to the messages map and read under its read lock.
+type messageSearchIndex struct {
+	// postings maps a token to the messages containing it and how often.
+	postings map[string]map[string]int
+	// terms lists the distinct tokens of each indexed message so it can be
+	// removed again.
+	terms map[string][]string
+}
+
+// searchTokens lowercases text and splits it into letter and digit runs.
+func searchTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := fields[:0]
+	for _, field := range fields {
+		if utf8.RuneCountInString(field) <= maxSearchTokenRunes {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// add (re)indexes a message. Messages that are not text only drop their old
+// entries, e.g. after an edit changed the content type.
+func (idx *messageSearchIndex) add(msg models.Message) {
+	idx.remove(msg.ID)
+	if msg.ContentType != "text" {
+		return
+	}
+	counts := map[string]int{}
+	for _, token := range searchTokens(string(msg.Content)) {
+		counts[token]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+	if idx.postings == nil {
+		idx.postings = map[string]map[string]int{}
+		idx.terms = map[string][]string{}
+	}
+	terms := make([]string, 0, len(counts))
+	for token, count := range counts {
+		posting := idx.postings[token]
+		if posting == nil {
+			posting = map[string]int{}
+			idx.postings[token] = posting
+		}
+		posting[msg.ID] = count
+		terms = append(terms, token)
+	}
+	idx.terms[msg.ID] = terms
+}
+
+func (idx *messageSearchIndex) remove(messageID string) {
+	for _, token := range idx.terms[messageID] {
+		posting := idx.postings[token]
+		delete(posting, messageID)
+		if len(posting) == 0 {
+			delete(idx.postings, token)
+		}
+	}
+	delete(idx.terms, messageID)
+}
+
+func (idx *messageSearchIndex) reset() {
+	idx.postings = nil
+	idx.terms = nil
+}
+
+// SearchMessages returns the text messages containing every term of the
+// query, ranked by TF-IDF. Filters run against the live messages, so status
+// changes are visible without reindexing.
+func (s *MessageStore) SearchMessages(query models.MessageSearchQuery) models.MessageSearchResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := models.MessageSearchResult{Hits: []models.MessageSearchHit{}}
+	terms := uniqueSearchTerms(searchTokens(query.Query))
+	if len(terms) == 0 {
+		return result
+	}
+	idx := &s.search
+	for _, term := range terms {
+		if len(idx.postings[term]) == 0 {
+			return result
+		}
+	}
+	// Walk the rarest term's postings and check the rest against them.
+	sort.Slice(terms, func(i, j int) bool {
+		return len(idx.postings[terms[i]]) < len(idx.postings[terms[j]])
+	})
+	conversationID := strings.TrimSpace(query.ConversationID)
+	docs := float64(len(idx.terms))
+	hits := make([]models.MessageSearchHit, 0)
+	for messageID := range idx.postings[terms[0]] {
+		msg, ok := s.messages[messageID]
+		if !ok {
+			continue
+		}
+		msg = models.NormalizeMessageConversation(msg)
+		if conversationID != "" && msg.ConversationID != conversationID {
+			continue
+		}
+		if (!query.Since.IsZero() && msg.Timestamp.Before(query.Since)) ||
+			(!query.Until.IsZero() && !msg.Timestamp.Before(query.Until)) {
+			continue
+		}
+		score := 0.0
+		matched := true
+		for _, term := range terms {
+			posting := idx.postings[term]
+			tf, ok := posting[messageID]
+			if !ok {
+				matched = false
+				break
+			}
+			score += float64(tf) * math.Log(1+docs/float64(len(posting)))
+		}
+		if matched {
+			hits = append(hits, models.MessageSearchHit{Message: msg, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Message.Timestamp.Equal(hits[j].Message.Timestamp) {
+			return hits[i].Message.Timestamp.After(hits[j].Message.Timestamp)
+		}
+		return hits[i].Message.ID < hits[j].Message.ID
+	})
+	result.Total = len(hits)
+	offset := max(query.Offset, 0)
+	if offset >= len(hits) {
+		return result
+	}
+	hits = hits[offset:]
+	if query.Limit > 0 && query.Limit < len(hits) {
+		hits = hits[:query.Limit]
+	}
+	result.Hits = append(result.Hits, hits...)
+	return result
+}
+
+func uniqueSearchTerms(tokens []string) []string {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		out = append(out, token)
+		if len(out) == maxSearchQueryTerms {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const messageSequenceSchemaVersion = 1
+
+// InboundSequenceUpdateFunc derives the next inbound state of a sender
+// device and reports whether it changed.
+type InboundSequenceUpdateFunc func(state models.InboundSequence) (models.InboundSequence, bool)
+
+// MessageSequenceStore keeps the per-contact outbound message counters and
+// the per-sender-device inbound sequence state used for gap detection.
+type MessageSequenceStore struct {
+	mu       sync.RWMutex
+	path     string
+	secret   string
+	outbound map[string]uint64
+	inbound  map[string]map[string]models.InboundSequence
+}
+
+type persistedInboundSequence struct {
+	ContactID string                 `json:"contact_id"`
+	DeviceID  string                 `json:"device_id"`
+	State     models.InboundSequence `json:"state"`
+}
+
+type persistedMessageSequences struct {
+	Version  int                        `json:"version"`
+	Outbound map[string]uint64          `json:"outbound"`
+	Inbound  []persistedInboundSequence `json:"inbound"`
+}
+
+func NewMessageSequenceStore() *MessageSequenceStore {
+	return &MessageSequenceStore{
+		outbound: map[string]uint64{},
+		inbound:  map[string]map[string]models.InboundSequence{},
+	}
+}
+
+func (s *MessageSequenceStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *MessageSequenceStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outbound = map[string]uint64{}
+	s.inbound = map[string]map[string]models.InboundSequence{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedMessageSequences
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != messageSequenceSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for contactID, seq := range payload.Outbound {
+		if contactID == "" || seq == 0 {
+			continue
+		}
+		s.outbound[contactID] = seq
+	}
+	for _, entry := range payload.Inbound {
+		if entry.ContactID == "" || entry.DeviceID == "" {
+			continue
+		}
+		if s.inbound[entry.ContactID] == nil {
+			s.inbound[entry.ContactID] = map[string]models.InboundSequence{}
+		}
+		s.inbound[entry.ContactID][entry.DeviceID] = cloneInboundSequence(entry.State)
+	}
+	return nil
+}
+
+// NextOutbound reserves and returns the next sequence number for messages
+// sent to contactID. Numbering starts at 1.
+func (s *MessageSequenceStore) NextOutbound(contactID string) (uint64, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" {
+		return 0, errors.New("contact id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.outbound[contactID] + 1
+	s.outbound[contactID] = next
+	if err := s.persistLocked(); err != nil {
+		s.outbound[contactID] = next - 1
+		return 0, err
+	}
+	return next, nil
+}
+
+// UpdateInbound applies update to the state of one sender device and
+// persists the result when it changed.
+func (s *MessageSequenceStore) UpdateInbound(contactID, deviceID string, update InboundSequenceUpdateFunc) (models.InboundSequence, error) {
+	contactID = strings.TrimSpace(contactID)
+	deviceID = strings.TrimSpace(deviceID)
+	if contactID == "" || deviceID == "" {
+		return models.InboundSequence{}, errors.New("contact id and device id are required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, hadPrev := s.inbound[contactID][deviceID]
+	next, changed := update(cloneInboundSequence(prev))
+	if !changed {
+		return cloneInboundSequence(prev), nil
+	}
+	if s.inbound[contactID] == nil {
+		s.inbound[contactID] = map[string]models.InboundSequence{}
+	}
+	s.inbound[contactID][deviceID] = cloneInboundSequence(next)
+	if err := s.persistLocked(); err != nil {
+		if hadPrev {
+			s.inbound[contactID][deviceID] = prev
+		} else {
+			delete(s.inbound[contactID], deviceID)
+		}
+		return models.InboundSequence{}, err
+	}
+	return cloneInboundSequence(next), nil
+}
+
+// Inbound returns the inbound state of every known device of contactID,
+// keyed by device id.
+func (s *MessageSequenceStore) Inbound(contactID string) map[string]models.InboundSequence {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	devices := s.inbound[strings.TrimSpace(contactID)]
+	out := make(map[string]models.InboundSequence, len(devices))
+	for deviceID, state := range devices {
+		out[deviceID] = cloneInboundSequence(state)
+	}
+	return out
+}
+
+// ContactsWithGaps returns the contacts that have at least one open gap,
+// sorted by id.
+func (s *MessageSequenceStore) ContactsWithGaps() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0)
+	for contactID, devices := range s.inbound {
+		for _, state := range devices {
+			if len(state.Gaps) > 0 {
+				out = append(out, contactID)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *MessageSequenceStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outbound = map[string]uint64{}
+	s.inbound = map[string]map[string]models.InboundSequence{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *MessageSequenceStore) persistLocked() error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	inbound := make([]persistedInboundSequence, 0, len(s.inbound))
+	for contactID, devices := range s.inbound {
+		for deviceID, state := range devices {
+			inbound = append(inbound, persistedInboundSequence{ContactID: contactID, DeviceID: deviceID, State: state})
+		}
+	}
+	sort.Slice(inbound, func(i, j int) bool {
+		if inbound[i].ContactID != inbound[j].ContactID {
+			return inbound[i].ContactID < inbound[j].ContactID
+		}
+		return inbound[i].DeviceID < inbound[j].DeviceID
+	})
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedMessageSequences{
+		Version:  messageSequenceSchemaVersion,
+		Outbound: s.outbound,
+		Inbound:  inbound,
+	})
+}
+
+func cloneInboundSequence(in models.InboundSequence) models.InboundSequence {
+	return models.InboundSequence{
+		Highest: in.Highest,
+		Gaps:    append([]models.HistoryGap(nil), in.Gaps...),
+	}
+}
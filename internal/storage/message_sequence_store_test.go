@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestMessageSequenceStorePersistsCountersAndGaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "message_sequences.enc")
+	store := NewMessageSequenceStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	for want := uint64(1); want <= 2; want++ {
+		got, err := store.NextOutbound("aim1peer")
+		if err != nil || got != want {
+			t.Fatalf("next outbound: got=%d want=%d err=%v", got, want, err)
+		}
+	}
+	_, err := store.UpdateInbound("aim1peer", "dev-a", func(state models.InboundSequence) (models.InboundSequence, bool) {
+		return models.InboundSequence{Highest: 5, Gaps: []models.HistoryGap{{DeviceID: "dev-a", From: 2, To: 4}}}, true
+	})
+	if err != nil {
+		t.Fatalf("update inbound failed: %v", err)
+	}
+
+	reloaded := NewMessageSequenceStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	if got, err := reloaded.NextOutbound("aim1peer"); err != nil || got != 3 {
+		t.Fatalf("outbound counter not persisted: got=%d err=%v", got, err)
+	}
+	state := reloaded.Inbound("aim1peer")["dev-a"]
+	if state.Highest != 5 || len(state.Gaps) != 1 || state.Gaps[0].From != 2 {
+		t.Fatalf("inbound state not persisted: %+v", state)
+	}
+	if contacts := reloaded.ContactsWithGaps(); len(contacts) != 1 || contacts[0] != "aim1peer" {
+		t.Fatalf("unexpected contacts with gaps: %v", contacts)
+	}
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if len(reloaded.Inbound("aim1peer")) != 0 || len(reloaded.ContactsWithGaps()) != 0 {
+		t.Fatal("wipe must clear sequence state")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -24,6 +25,8 @@ type PendingMessage struct {
 }
 
 var ErrMessageIDConflict = errors.New("message id conflict")
+var ErrMessageNotCancellable = errors.New("message is no longer pending and cannot be cancelled")
+var ErrMessageNotRetryable = errors.New("only unsent direct messages can be retried")
 
 const messageStoreSchemaVersion = 2
 
@@ -31,9 +34,16 @@ type MessageStore struct {
 	mu       sync.RWMutex
 	messages map[string]models.Message
 	pending  map[string]PendingMessage
-	path     string
-	secret   string
-	persist  bool
+	// pins maps a pinned message id to its pin.
+	pins    map[string]models.MessagePin
+	path    string
+	secret  string
+	persist bool
+	// media is the attachment gallery view, rebuilt on first use after a
+	// change that adds, removes or reorders messages.
+	mediaMu sync.Mutex
+	media   map[string]mediaIndexBuckets
+	search  messageSearchIndex
 }
 
 func NewMessageStore() *MessageStore {
@@ -74,16 +84,27 @@ func (s *MessageStore) SaveMessage(msg models.Message) error {
 		return err
 	}
 	s.messages = nextMessages
+	s.media = nil
+	s.search.add(msg)
 	return nil
 }
 
 func (s *MessageStore) UpdateMessageStatus(messageID, status string) (bool, error) {
+	return s.UpdateMessageStatusAt(messageID, status, time.Now())
+}
+
+// UpdateMessageStatusAt merges status into the message and stamps the stage
+// it reports with at, unless that stage was already recorded.
+func (s *MessageStore) UpdateMessageStatusAt(messageID, status string, at time.Time) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	msg, ok := s.messages[messageID]
 	if !ok {
 		return false, nil
 	}
+	if msg.Status != "cancelled" {
+		stampMessageStage(&msg, status, at.UTC())
+	}
 	msg.Status = mergeMessageStatus(msg.Status, status)
 	nextMessages := cloneMessagesMap(s.messages)
 	nextMessages[messageID] = msg
@@ -111,6 +132,8 @@ func (s *MessageStore) UpdateMessageContent(messageID string, content []byte, co
 		return models.Message{}, false, err
 	}
 	s.messages = nextMessages
+	s.media = nil
+	s.search.add(msg)
 	return msg, true, nil
 }
 
@@ -129,6 +152,8 @@ func (s *MessageStore) DeleteMessage(contactID, messageID string) (bool, error)
 		return false, err
 	}
 	s.messages = nextMessages
+	s.media = nil
+	s.search.remove(messageID)
 	s.pending = nextPending
 	return true, nil
 }
@@ -164,6 +189,10 @@ func (s *MessageStore) ClearMessages(contactID string) (int, error) {
 		return 0, err
 	}
 	s.messages = nextMessages
+	s.media = nil
+	for id := range deletedIDs {
+		s.search.remove(id)
+	}
 	s.pending = nextPending
 	return deleted, nil
 }
@@ -238,6 +267,42 @@ func (s *MessageStore) listMessagesFiltered(
 	return paginateMessages(filtered, limit, offset)
 }
 
+// ListMessageSummaries returns metadata-only views of a contact's messages.
+// Ordering and pagination run over a lightweight index so that only the
+// requested page is summarized and message bodies are never copied.
+func (s *MessageStore) ListMessageSummaries(contactID string, limit, offset, previewRunes int) []models.MessageSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	type indexEntry struct {
+		id        string
+		timestamp time.Time
+	}
+	index := make([]indexEntry, 0)
+	for id, msg := range s.messages {
+		if msg.ContactID == contactID {
+			index = append(index, indexEntry{id: id, timestamp: msg.Timestamp})
+		}
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return index[i].timestamp.Before(index[j].timestamp)
+	})
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(index) {
+		return []models.MessageSummary{}
+	}
+	index = index[offset:]
+	if limit > 0 && limit < len(index) {
+		index = index[:limit]
+	}
+	out := make([]models.MessageSummary, 0, len(index))
+	for _, entry := range index {
+		out = append(out, models.SummarizeMessage(s.messages[entry.id], previewRunes))
+	}
+	return out
+}
+
 func paginateMessages(filtered []models.Message, limit, offset int) []models.Message {
 	if offset < 0 {
 		offset = 0
@@ -281,6 +346,61 @@ func (s *MessageStore) RemovePending(messageID string) error {
 	return nil
 }
 
+// RequeueMessage puts an unsent direct message back into the retry queue
+// for an immediate attempt, resetting its retry budget. Failed messages go
+// back to pending.
+func (s *MessageStore) RequeueMessage(messageID string, now time.Time) (models.Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[messageID]
+	if !ok {
+		return models.Message{}, false, nil
+	}
+	if msg.Direction != "out" || msg.ConversationType != models.ConversationTypeDirect ||
+		(msg.Status != "" && msg.Status != "pending" && msg.Status != "failed") {
+		return models.Message{}, true, ErrMessageNotRetryable
+	}
+	msg.Status = "pending"
+	nextMessages := cloneMessagesMap(s.messages)
+	nextMessages[messageID] = msg
+	nextPending := clonePendingMap(s.pending)
+	nextPending[messageID] = PendingMessage{Message: msg, NextRetry: now}
+	if err := s.persistSnapshotLocked(nextMessages, nextPending); err != nil {
+		return models.Message{}, true, err
+	}
+	s.messages = nextMessages
+	s.pending = nextPending
+	return msg, true, nil
+}
+
+// CancelPending removes an unsent or failed outbound message from the retry
+// queue and marks it cancelled in one persisted step. Cancelling twice is a no-op.
+func (s *MessageStore) CancelPending(messageID string) (models.Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[messageID]
+	if !ok {
+		return models.Message{}, false, nil
+	}
+	switch {
+	case msg.Status == "cancelled":
+		return msg, true, nil
+	case msg.Direction != "out" || (msg.Status != "" && msg.Status != "pending" && msg.Status != "failed"):
+		return models.Message{}, true, ErrMessageNotCancellable
+	}
+	msg.Status = "cancelled"
+	nextMessages := cloneMessagesMap(s.messages)
+	nextMessages[messageID] = msg
+	nextPending := clonePendingMap(s.pending)
+	delete(nextPending, messageID)
+	if err := s.persistSnapshotLocked(nextMessages, nextPending); err != nil {
+		return models.Message{}, true, err
+	}
+	s.messages = nextMessages
+	s.pending = nextPending
+	return msg, true, nil
+}
+
 func (s *MessageStore) DuePending(now time.Time) []PendingMessage {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -293,6 +413,31 @@ func (s *MessageStore) DuePending(now time.Time) []PendingMessage {
 	return out
 }
 
+// PendingForContact returns the queued messages addressed to contactID,
+// oldest first, regardless of when their next retry is due.
+func (s *MessageStore) PendingForContact(contactID string) []PendingMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]PendingMessage, 0)
+	for _, p := range s.pending {
+		if p.Message.ContactID == contactID {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Message.Timestamp.Before(out[j].Message.Timestamp)
+	})
+	return out
+}
+
+// GetPending returns the current retry queue entry for messageID.
+func (s *MessageStore) GetPending(messageID string) (PendingMessage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.pending[messageID]
+	return p, ok
+}
+
 func (s *MessageStore) PendingCount() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -317,7 +462,10 @@ func (s *MessageStore) Wipe() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.messages = make(map[string]models.Message)
+	s.media = nil
+	s.search.reset()
 	s.pending = make(map[string]PendingMessage)
+	s.pins = nil
 	if strings.TrimSpace(s.path) == "" {
 		return nil
 	}
@@ -361,6 +509,10 @@ func (s *MessageStore) PurgeOlderThan(cutoff time.Time) (int, error) {
 		return 0, err
 	}
 	s.messages = nextMessages
+	s.media = nil
+	for id := range deletedIDs {
+		s.search.remove(id)
+	}
 	s.pending = nextPending
 	return deleted, nil
 }
@@ -396,9 +548,10 @@ func (s *MessageStore) load() error {
 	}
 
 	var snapshot struct {
-		SchemaVersion int                       `json:"schema_version"`
-		Messages      map[string]models.Message `json:"messages"`
-		Pending       map[string]PendingMessage `json:"pending"`
+		SchemaVersion int                          `json:"schema_version"`
+		Messages      map[string]models.Message    `json:"messages"`
+		Pending       map[string]PendingMessage    `json:"pending"`
+		Pins          map[string]models.MessagePin `json:"pins"`
 	}
 	if err := json.Unmarshal(decoded, &snapshot); err != nil {
 		return err
@@ -418,6 +571,7 @@ func (s *MessageStore) load() error {
 		s.messages = make(map[string]models.Message, len(snapshot.Messages))
 		for id, msg := range snapshot.Messages {
 			s.messages[id] = models.NormalizeMessageConversation(msg)
+			s.search.add(s.messages[id])
 		}
 	}
 	if snapshot.Pending != nil {
@@ -427,6 +581,7 @@ func (s *MessageStore) load() error {
 			s.pending[id] = p
 		}
 	}
+	s.pins = snapshot.Pins
 	if schemaMigrated {
 		if err := s.persistSnapshotLocked(s.messages, s.pending); err != nil {
 			return err
@@ -436,6 +591,10 @@ func (s *MessageStore) load() error {
 }
 
 func (s *MessageStore) persistSnapshotLocked(messages map[string]models.Message, pending map[string]PendingMessage) error {
+	return s.persistLocked(messages, pending, s.pins)
+}
+
+func (s *MessageStore) persistLocked(messages map[string]models.Message, pending map[string]PendingMessage, pins map[string]models.MessagePin) error {
 	if s.path == "" || !s.persist {
 		return nil
 	}
@@ -443,23 +602,31 @@ func (s *MessageStore) persistSnapshotLocked(messages map[string]models.Message,
 		return err
 	}
 	snapshot := struct {
-		SchemaVersion int                       `json:"schema_version"`
-		Messages      map[string]models.Message `json:"messages"`
-		Pending       map[string]PendingMessage `json:"pending"`
+		SchemaVersion int                          `json:"schema_version"`
+		Messages      map[string]models.Message    `json:"messages"`
+		Pending       map[string]PendingMessage    `json:"pending"`
+		Pins          map[string]models.MessagePin `json:"pins,omitempty"`
 	}{
 		SchemaVersion: messageStoreSchemaVersion,
 		Messages:      messages,
 		Pending:       pending,
+		Pins:          pins,
 	}
 	data, err := json.Marshal(snapshot)
 	if err != nil {
 		return err
 	}
-	if s.secret != "" {
-		data, err = securestore.Encrypt(s.secret, data)
-		if err != nil {
+	release, err := securestore.BeginWrite(s.secret)
+	if err != nil {
+		return err
+	}
+	defer release()
+	if s.secret == "" {
+		if data, err = securestore.AddRecordChecksums(data); err != nil {
 			return err
 		}
+	} else if data, err = securestore.Encrypt(s.secret, data); err != nil {
+		return err
 	}
 	return os.WriteFile(s.path, data, 0o600)
 }
@@ -481,11 +648,15 @@ func clonePendingMap(in map[string]PendingMessage) map[string]PendingMessage {
 }
 
 func mergeMessageStatus(current, candidate string) string {
-	if candidate == "failed" {
+	if current == "cancelled" {
+		// Cancellation is final; late publish results must not revive it.
+		return current
+	}
+	if candidate == "failed" || candidate == "cancelled" {
 		// Terminal failure is allowed only for unsent states.
 		switch current {
 		case "", "pending":
-			return "failed"
+			return candidate
 		default:
 			return current
 		}
@@ -496,6 +667,23 @@ func mergeMessageStatus(current, candidate string) string {
 	return current
 }
 
+func stampMessageStage(msg *models.Message, status string, at time.Time) {
+	var stage *time.Time
+	switch status {
+	case "sent":
+		stage = &msg.RelayedAt
+	case "delivered":
+		stage = &msg.DeliveredAt
+	case "read":
+		stage = &msg.ReadAt
+	default:
+		return
+	}
+	if stage.IsZero() {
+		*stage = at
+	}
+}
+
 func statusOrder(status string) int {
 	switch status {
 	case "pending":
@@ -520,10 +708,28 @@ func messagesEqual(a, b models.Message) bool {
 		a.ContactID == b.ContactID &&
 		a.ConversationID == b.ConversationID &&
 		a.ConversationType == b.ConversationType &&
+		a.EventID == b.EventID &&
 		bytes.Equal(a.Content, b.Content) &&
 		a.Timestamp.Equal(b.Timestamp) &&
 		a.Direction == b.Direction &&
 		a.Status == b.Status &&
 		a.ContentType == b.ContentType &&
-		a.Edited == b.Edited
+		a.Edited == b.Edited &&
+		slices.Equal(a.Attachments, b.Attachments) &&
+		messageTipsEqual(a.Tip, b.Tip) &&
+		messageLocationsEqual(a.Location, b.Location)
+}
+
+func messageTipsEqual(a, b *models.MessageTip) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func messageLocationsEqual(a, b *models.MessageLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
 	"time"
 
@@ -44,6 +45,37 @@ func TestMessageStatusMonotonicTransitions(t *testing.T) {
 	}
 }
 
+func TestMessageStatusStampsEachStageOnce(t *testing.T) {
+	s := NewMessageStore()
+	base := time.Now().UTC()
+	if err := s.SaveMessage(models.Message{ID: "m1", ContactID: "c1", Direction: "out", Status: "pending", Timestamp: base}); err != nil {
+		t.Fatalf("save message failed: %v", err)
+	}
+	relayedAt := base.Add(time.Second)
+	readAt := base.Add(3 * time.Second)
+	deliveredAt := base.Add(2 * time.Second)
+	for _, step := range []struct {
+		status string
+		at     time.Time
+	}{
+		{"sent", relayedAt},
+		{"sent", base.Add(10 * time.Second)},
+		{"read", readAt},
+		{"delivered", deliveredAt},
+	} {
+		if _, err := s.UpdateMessageStatusAt("m1", step.status, step.at); err != nil {
+			t.Fatalf("set %s failed: %v", step.status, err)
+		}
+	}
+	got, _ := s.GetMessage("m1")
+	if got.Status != "read" {
+		t.Fatalf("expected status read, got %s", got.Status)
+	}
+	if !got.RelayedAt.Equal(relayedAt) || !got.DeliveredAt.Equal(deliveredAt) || !got.ReadAt.Equal(readAt) {
+		t.Fatalf("unexpected stage timestamps: relayed=%v delivered=%v read=%v", got.RelayedAt, got.DeliveredAt, got.ReadAt)
+	}
+}
+
 func TestMessageStatusAllowsPendingToFailedButKeepsDelivered(t *testing.T) {
 	s := NewMessageStore()
 	now := time.Now().UTC()
@@ -87,6 +119,62 @@ func TestMessageStatusAllowsPendingToFailedButKeepsDelivered(t *testing.T) {
 	}
 }
 
+func TestMessageStoreCancelPendingRemovesQueueEntry(t *testing.T) {
+	s := NewMessageStore()
+	msg := models.Message{
+		ID:        "m-cancel-1",
+		ContactID: "c1",
+		Direction: "out",
+		Status:    "pending",
+		Timestamp: time.Now().UTC(),
+	}
+	if err := s.SaveMessage(msg); err != nil {
+		t.Fatalf("save message failed: %v", err)
+	}
+	if err := s.AddOrUpdatePending(msg, 1, time.Now(), "offline"); err != nil {
+		t.Fatalf("add pending failed: %v", err)
+	}
+
+	cancelled, ok, err := s.CancelPending("m-cancel-1")
+	if err != nil || !ok || cancelled.Status != "cancelled" {
+		t.Fatalf("cancel failed: %+v ok=%v err=%v", cancelled, ok, err)
+	}
+	if s.PendingCount() != 0 {
+		t.Fatalf("cancelled message must leave the retry queue, pending=%d", s.PendingCount())
+	}
+	if _, err := s.UpdateMessageStatus("m-cancel-1", "sent"); err != nil {
+		t.Fatalf("late status update failed: %v", err)
+	}
+	if got, _ := s.GetMessage("m-cancel-1"); got.Status != "cancelled" {
+		t.Fatalf("cancelled status must be final, got %s", got.Status)
+	}
+	if _, ok, err := s.CancelPending("m-cancel-1"); err != nil || !ok {
+		t.Fatalf("repeated cancel must be a no-op: ok=%v err=%v", ok, err)
+	}
+	if _, ok, _ := s.CancelPending("missing"); ok {
+		t.Fatal("unknown message must not be found")
+	}
+}
+
+func TestMessageStoreCancelPendingRejectsSentMessage(t *testing.T) {
+	s := NewMessageStore()
+	if err := s.SaveMessage(models.Message{
+		ID:        "m-cancel-2",
+		ContactID: "c1",
+		Direction: "out",
+		Status:    "delivered",
+		Timestamp: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("save message failed: %v", err)
+	}
+	if _, _, err := s.CancelPending("m-cancel-2"); !errors.Is(err, ErrMessageNotCancellable) {
+		t.Fatalf("expected not cancellable error, got %v", err)
+	}
+	if got, _ := s.GetMessage("m-cancel-2"); got.Status != "delivered" {
+		t.Fatalf("delivered message must be untouched, got %s", got.Status)
+	}
+}
+
 func TestEncryptedPersistentMessageStoreTamperFailsAuth(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "messages.enc")
 	store, err := NewEncryptedPersistentMessageStore(path, "pass")
@@ -117,6 +205,36 @@ func TestEncryptedPersistentMessageStoreTamperFailsAuth(t *testing.T) {
 	}
 }
 
+func TestPlaintextMessageStoreWritesWaitForHeldWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	store, err := NewEncryptedPersistentMessageStore(path, "")
+	if err != nil {
+		t.Fatalf("new store failed: %v", err)
+	}
+	hold := securestore.HoldWrites()
+	saved := make(chan error, 1)
+	go func() {
+		saved <- store.SaveMessage(models.Message{ID: "m-held", ContactID: "c1", Status: "pending", Timestamp: time.Now().UTC()})
+	}()
+	select {
+	case err := <-saved:
+		hold.Release()
+		t.Fatalf("save must wait for the hold, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		hold.Release()
+		t.Fatalf("nothing may be written while writes are held, stat err=%v", err)
+	}
+	hold.Release()
+	if err := <-saved; err != nil {
+		t.Fatalf("save after release failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the store file after release: %v", err)
+	}
+}
+
 func TestMessageStoreRejectsMessageIDConflict(t *testing.T) {
 	s := NewMessageStore()
 	base := models.Message{
@@ -481,3 +599,127 @@ func TestMessageStorePurgeOlderThan(t *testing.T) {
 		t.Fatalf("pending entries for deleted messages must be removed, got %d", s.PendingCount())
 	}
 }
+
+func TestMessageStoreListMessageSummariesPaginatesWithoutBodies(t *testing.T) {
+	s := NewMessageStore()
+	base := time.Now().UTC()
+	body := make([]byte, 4096)
+	for i := range body {
+		body[i] = 'x'
+	}
+	for i, id := range []string{"m1", "m2", "m3"} {
+		if err := s.SaveMessage(models.Message{
+			ID:          id,
+			ContactID:   "c1",
+			Content:     body,
+			ContentType: "text",
+			Timestamp:   base.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("save message failed: %v", err)
+		}
+	}
+	if err := s.SaveMessage(models.Message{ID: "other", ContactID: "c2", Timestamp: base}); err != nil {
+		t.Fatalf("save message failed: %v", err)
+	}
+
+	page := s.ListMessageSummaries("c1", 2, 1, 16)
+	if len(page) != 2 || page[0].ID != "m2" || page[1].ID != "m3" {
+		t.Fatalf("unexpected summary page: %+v", page)
+	}
+	if page[0].ContentSize != len(body) || len(page[0].Preview) != 16 || !page[0].PreviewTruncated {
+		t.Fatalf("unexpected summary body fields: %+v", page[0])
+	}
+	if got := s.ListMessageSummaries("c1", 10, 5, 16); len(got) != 0 {
+		t.Fatalf("expected empty page past the end, got %d", len(got))
+	}
+}
+
+func TestMessageStoreListMediaMessagesByClass(t *testing.T) {
+	s := NewMessageStore()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	photo := models.MessageAttachment{ID: "blob-photo", MimeType: "image/png"}
+	doc := models.MessageAttachment{ID: "blob-doc", MimeType: "application/pdf"}
+	for _, msg := range []models.Message{
+		{ID: "text", ContactID: "c1", Timestamp: base},
+		{ID: "photo", ContactID: "c1", Timestamp: base.Add(time.Minute), Attachments: []models.MessageAttachment{photo}},
+		{ID: "doc", ContactID: "c1", Timestamp: base.Add(2 * time.Minute), Attachments: []models.MessageAttachment{doc}},
+		{ID: "both", ContactID: "c1", Timestamp: base.Add(3 * time.Minute), Attachments: []models.MessageAttachment{photo, doc}},
+		{ID: "other", ContactID: "c2", Timestamp: base, Attachments: []models.MessageAttachment{photo}},
+		{ID: "group", ContactID: "c1", ConversationID: "g1", ConversationType: models.ConversationTypeGroup, Timestamp: base, Attachments: []models.MessageAttachment{photo}},
+	} {
+		if err := s.SaveMessage(msg); err != nil {
+			t.Fatalf("save %s: %v", msg.ID, err)
+		}
+	}
+
+	ids := func(msgs []models.Message) []string {
+		out := make([]string, 0, len(msgs))
+		for _, msg := range msgs {
+			out = append(out, msg.ID)
+		}
+		return out
+	}
+	if got := ids(s.ListMediaMessages("c1", models.ConversationTypeDirect, models.AttachmentClassImage, 0, 0)); !slices.Equal(got, []string{"both", "photo"}) {
+		t.Fatalf("unexpected images: %v", got)
+	}
+	if got := ids(s.ListMediaMessages("c1", models.ConversationTypeDirect, "", 2, 1)); !slices.Equal(got, []string{"doc", "photo"}) {
+		t.Fatalf("unexpected page of all media: %v", got)
+	}
+	if got := ids(s.ListMediaMessages("g1", models.ConversationTypeGroup, models.AttachmentClassFile, 0, 0)); len(got) != 0 {
+		t.Fatalf("expected no group files, got %v", got)
+	}
+
+	if _, err := s.DeleteMessage("c1", "both"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := ids(s.ListMediaMessages("c1", models.ConversationTypeDirect, models.AttachmentClassFile, 0, 0)); !slices.Equal(got, []string{"doc"}) {
+		t.Fatalf("index must follow deletes, got %v", got)
+	}
+}
+
+func TestMessageStoreSearchMessages(t *testing.T) {
+	s := NewMessageStore()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, msg := range []models.Message{
+		{ID: "m1", ContactID: "alice", ContentType: "text", Content: []byte("Lunch on Friday?"), Timestamp: base},
+		{ID: "m2", ContactID: "alice", ContentType: "text", Content: []byte("friday friday, lunch is booked"), Timestamp: base.Add(time.Hour)},
+		{ID: "m3", ContactID: "bob", ContentType: "text", Content: []byte("Friday lunch works"), Timestamp: base.Add(2 * time.Hour)},
+		{ID: "m4", ContactID: "bob", ContentType: "e2ee-unreadable", Content: []byte("friday lunch"), Timestamp: base},
+		{ID: "m5", ContactID: "carol", ContentType: "text", Content: []byte("dinner on Friday"), Timestamp: base},
+	} {
+		if err := s.SaveMessage(msg); err != nil {
+			t.Fatalf("save %s: %v", msg.ID, err)
+		}
+	}
+	ids := func(result models.MessageSearchResult) []string {
+		out := make([]string, 0, len(result.Hits))
+		for _, hit := range result.Hits {
+			out = append(out, hit.Message.ID)
+		}
+		return out
+	}
+
+	global := s.SearchMessages(models.MessageSearchQuery{Query: "FRIDAY lunch"})
+	if global.Total != 3 || !slices.Equal(ids(global), []string{"m2", "m3", "m1"}) {
+		t.Fatalf("unexpected global ranking: total=%d %v", global.Total, ids(global))
+	}
+	if got := ids(s.SearchMessages(models.MessageSearchQuery{ConversationID: "alice", Query: "lunch", Limit: 1, Offset: 1})); !slices.Equal(got, []string{"m1"}) {
+		t.Fatalf("unexpected per-contact page: %v", got)
+	}
+	if got := ids(s.SearchMessages(models.MessageSearchQuery{Query: "lunch", Since: base.Add(time.Minute), Until: base.Add(2 * time.Hour)})); !slices.Equal(got, []string{"m2"}) {
+		t.Fatalf("unexpected date range hits: %v", got)
+	}
+
+	if _, _, err := s.UpdateMessageContent("m2", []byte("moved to saturday"), "text"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, err := s.ClearMessages("bob"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got := ids(s.SearchMessages(models.MessageSearchQuery{Query: "lunch"})); !slices.Equal(got, []string{"m1"}) {
+		t.Fatalf("index must follow edits and deletes, got %v", got)
+	}
+	if got := ids(s.SearchMessages(models.MessageSearchQuery{Query: "saturday"})); !slices.Equal(got, []string{"m2"}) {
+		t.Fatalf("edited text must be searchable, got %v", got)
+	}
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const metricsHistorySchemaVersion = 1
+
+// Metrics history keeps minute buckets for a day and hour buckets for a
+// month, which bounds the file to a few thousand buckets.
+const (
+	MetricsMinuteRetention = 24 * time.Hour
+	MetricsHourRetention   = 30 * 24 * time.Hour
+)
+
+var ErrInvalidMetricsQuery = errors.New("invalid metrics query")
+
+type metricsStat struct {
+	Samples int     `json:"n"`
+	Sum     float64 `json:"sum"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+}
+
+type metricsBucket struct {
+	Start  time.Time              `json:"start"`
+	Series map[string]metricsStat `json:"series"`
+}
+
+type persistedMetricsHistory struct {
+	Version int             `json:"version"`
+	Minutes []metricsBucket `json:"minutes"`
+	Hours   []metricsBucket `json:"hours"`
+}
+
+// MetricsHistoryStore rolls metric samples up into minute and hour buckets
+// and keeps them in an encrypted per-account file, so charts survive
+// restarts. The file is written whenever a minute bucket opens, so a crash
+// loses at most the samples of the current minute.
+type MetricsHistoryStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	minutes []metricsBucket
+	hours   []metricsBucket
+}
+
+func NewMetricsHistoryStore() *MetricsHistoryStore {
+	return &MetricsHistoryStore{}
+}
+
+func (s *MetricsHistoryStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *MetricsHistoryStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minutes, s.hours = nil, nil
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedMetricsHistory
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != metricsHistorySchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	s.minutes = payload.Minutes
+	s.hours = payload.Hours
+	return nil
+}
+
+// Record adds one sample of each series at the given time and drops buckets
+// past retention.
+func (s *MetricsHistoryStore) Record(at time.Time, values map[string]float64) error {
+	at = at.UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var closed bool
+	s.minutes, closed = addMetricsSample(s.minutes, at.Truncate(time.Minute), values)
+	s.hours, _ = addMetricsSample(s.hours, at.Truncate(time.Hour), values)
+	s.minutes = trimMetricsBuckets(s.minutes, at.Add(-MetricsMinuteRetention))
+	s.hours = trimMetricsBuckets(s.hours, at.Add(-MetricsHourRetention))
+	if !closed {
+		return nil
+	}
+	return s.persistLocked()
+}
+
+// Query returns the buckets of the requested series that start in
+// [from, to) at minute or hour resolution. No series means all of them.
+func (s *MetricsHistoryStore) Query(from, to time.Time, resolution time.Duration, series []string) ([]models.MetricsSeries, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidMetricsQuery
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var buckets []metricsBucket
+	switch resolution {
+	case time.Minute:
+		buckets = s.minutes
+	case time.Hour:
+		buckets = s.hours
+	default:
+		return nil, ErrInvalidMetricsQuery
+	}
+	wanted := map[string]struct{}{}
+	for _, name := range series {
+		wanted[name] = struct{}{}
+	}
+	points := map[string][]models.MetricsPoint{}
+	for _, bucket := range buckets {
+		if bucket.Start.Before(from) || !bucket.Start.Before(to) {
+			continue
+		}
+		for name, stat := range bucket.Series {
+			if _, ok := wanted[name]; len(wanted) > 0 && !ok {
+				continue
+			}
+			points[name] = append(points[name], models.MetricsPoint{
+				At:      bucket.Start,
+				Avg:     stat.Sum / float64(stat.Samples),
+				Min:     stat.Min,
+				Max:     stat.Max,
+				Sum:     stat.Sum,
+				Samples: stat.Samples,
+			})
+		}
+	}
+	for name := range wanted {
+		if _, ok := points[name]; !ok {
+			points[name] = []models.MetricsPoint{}
+		}
+	}
+	out := make([]models.MetricsSeries, 0, len(points))
+	for name, seriesPoints := range points {
+		out = append(out, models.MetricsSeries{Name: name, Points: seriesPoints})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *MetricsHistoryStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minutes, s.hours = nil, nil
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *MetricsHistoryStore) persistLocked() error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedMetricsHistory{
+		Version: metricsHistorySchemaVersion,
+		Minutes: s.minutes,
+		Hours:   s.hours,
+	})
+}
+
+// addMetricsSample folds values into the bucket starting at start and
+// reports whether that opened a new bucket, closing the previous one.
+// Samples older than the newest bucket are dropped.
+func addMetricsSample(buckets []metricsBucket, start time.Time, values map[string]float64) ([]metricsBucket, bool) {
+	opened := false
+	if n := len(buckets); n == 0 || buckets[n-1].Start.Before(start) {
+		buckets = append(buckets, metricsBucket{Start: start, Series: map[string]metricsStat{}})
+		opened = n > 0
+	} else if !buckets[n-1].Start.Equal(start) {
+		return buckets, false
+	}
+	bucket := &buckets[len(buckets)-1]
+	for name, value := range values {
+		stat, ok := bucket.Series[name]
+		if !ok || value < stat.Min {
+			stat.Min = value
+		}
+		if !ok || value > stat.Max {
+			stat.Max = value
+		}
+		stat.Samples++
+		stat.Sum += value
+		bucket.Series[name] = stat
+	}
+	return buckets, opened
+}
+
+func trimMetricsBuckets(buckets []metricsBucket, cutoff time.Time) []metricsBucket {
+	drop := 0
+	for drop < len(buckets) && buckets[drop].Start.Before(cutoff) {
+		drop++
+	}
+	if drop == 0 {
+		return buckets
+	}
+	return append([]metricsBucket(nil), buckets[drop:]...)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetricsHistoryStoreRollsUpAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics_history.enc")
+	store := NewMetricsHistoryStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	samples := []struct {
+		offset time.Duration
+		peers  float64
+		errs   float64
+	}{
+		{0, 2, 1},
+		{20 * time.Second, 4, 0},
+		{40 * time.Second, 6, 2},
+		{70 * time.Second, 3, 0},
+	}
+	for _, sample := range samples {
+		if err := store.Record(base.Add(sample.offset), map[string]float64{"peer_count": sample.peers, "errors": sample.errs}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	series, err := store.Query(base, base.Add(2*time.Minute), time.Minute, []string{"peer_count"})
+	if err != nil {
+		t.Fatalf("query minutes: %v", err)
+	}
+	if len(series) != 1 || series[0].Name != "peer_count" || len(series[0].Points) != 2 {
+		t.Fatalf("unexpected minute series: %+v", series)
+	}
+	first := series[0].Points[0]
+	if !first.At.Equal(base) || first.Samples != 3 || first.Avg != 4 || first.Min != 2 || first.Max != 6 {
+		t.Fatalf("unexpected first minute bucket: %+v", first)
+	}
+
+	hours, err := store.Query(base, base.Add(time.Hour), time.Hour, nil)
+	if err != nil {
+		t.Fatalf("query hours: %v", err)
+	}
+	if len(hours) != 2 || hours[0].Name != "errors" || hours[0].Points[0].Sum != 3 || hours[1].Points[0].Samples != 4 {
+		t.Fatalf("unexpected hour series: %+v", hours)
+	}
+	if _, err := store.Query(base, base.Add(time.Hour), 5*time.Minute, nil); !errors.Is(err, ErrInvalidMetricsQuery) {
+		t.Fatalf("expected invalid resolution to be rejected, got %v", err)
+	}
+
+	// The 70s sample opened a new minute bucket, which wrote the file.
+	reloaded := NewMetricsHistoryStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	series, err = reloaded.Query(base, base.Add(2*time.Minute), time.Minute, []string{"peer_count"})
+	if err != nil || len(series) != 1 || len(series[0].Points) != 2 || series[0].Points[0].Samples != 3 {
+		t.Fatalf("unexpected series after reload: %+v %v", series, err)
+	}
+
+	if err := reloaded.Record(base.Add(MetricsMinuteRetention+2*time.Minute), map[string]float64{"peer_count": 1}); err != nil {
+		t.Fatalf("record after a day: %v", err)
+	}
+	series, _ = reloaded.Query(base, base.Add(2*time.Minute), time.Minute, nil)
+	if len(series) != 0 {
+		t.Fatalf("minute buckets past retention must be dropped: %+v", series)
+	}
+	series, _ = reloaded.Query(base, base.Add(time.Hour), time.Hour, nil)
+	if len(series) == 0 {
+		t.Fatal("hour buckets within retention must be kept")
+	}
+
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	series, _ = reloaded.Query(base, base.Add(48*time.Hour), time.Hour, nil)
+	if len(series) != 0 {
+		t.Fatalf("wipe must drop history: %+v", series)
+	}
+}
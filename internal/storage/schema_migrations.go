@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+// errNotSchemaDocument marks store files the framework cannot decode. They
+// are left to the store's own load path.
+var errNotSchemaDocument = errors.New("store file is not a JSON document")
+
+// StoreSchema declares the on-disk format of one store file. Migrations step
+// a decoded document from From to From+1 until it reaches Current.
+type StoreSchema struct {
+	Store        string
+	File         string
+	VersionField string
+	Current      int
+	Migrations   []SchemaMigration
+}
+
+type SchemaMigration struct {
+	From  int
+	Apply func(doc map[string]json.RawMessage) error
+}
+
+// StoreSchemaState is the version found on disk for one store file.
+type StoreSchemaState struct {
+	Schema  StoreSchema
+	Path    string
+	Present bool
+	Version int
+}
+
+// PendingSchemaMigration is a migrated document that has not been written.
+type PendingSchemaMigration struct {
+	Store string
+	Path  string
+	From  int
+	To    int
+	data  []byte
+	perm  fs.FileMode
+}
+
+// backwardCompatibleMigration marks a version bump that only added fields
+// with usable zero values.
+func backwardCompatibleMigration(from int) SchemaMigration {
+	return SchemaMigration{From: from, Apply: func(map[string]json.RawMessage) error { return nil }}
+}
+
+// StoreSchemas lists the stores of this package that live in a profile dir.
+func StoreSchemas() []StoreSchema {
+	return []StoreSchema{
+		{
+			Store:        "messages",
+			File:         "messages.json",
+			VersionField: "schema_version",
+			Current:      messageStoreSchemaVersion,
+			Migrations:   []SchemaMigration{backwardCompatibleMigration(0), backwardCompatibleMigration(1)},
+		},
+		{
+			Store:        "attachments",
+			File:         filepath.Join("attachments", "index.json"),
+			VersionField: "schema_version",
+			Current:      attachmentIndexSchemaVersion,
+			Migrations:   []SchemaMigration{backwardCompatibleMigration(0), backwardCompatibleMigration(1)},
+		},
+		{Store: "client_state", File: "client_state.enc", VersionField: "version", Current: clientStateSchemaVersion},
+		{Store: "message_annotations", File: "message_annotations.enc", VersionField: "version", Current: messageAnnotationSchemaVersion},
+		{Store: "conversation_sync", File: "conversation_sync.enc", VersionField: "version", Current: conversationSyncSchemaVersion},
+		{Store: "group_avatars", File: "group_avatars.enc", VersionField: "version", Current: groupAvatarSchemaVersion},
+	}
+}
+
+// ReadStoreSchemaState reports the schema version of a store file in dir.
+// Files that do not open with secret or are not JSON documents are reported
+// as absent; the store's own legacy handling deals with them on load.
+func ReadStoreSchemaState(dir, secret string, schema StoreSchema) (StoreSchemaState, error) {
+	state := StoreSchemaState{Schema: schema, Path: filepath.Join(dir, schema.File)}
+	doc, _, err := readSchemaDocument(state.Path, secret)
+	if err != nil {
+		if isSkippedSchemaDocument(err) || errors.Is(err, securestore.ErrAuthFailed) {
+			return state, nil
+		}
+		return state, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	state.Present = true
+	state.Version, err = schemaDocumentVersion(doc, schema.VersionField)
+	if err != nil {
+		return state, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	return state, nil
+}
+
+// PlanStoreMigration decodes a store file and runs its migrations in memory.
+// It returns false when the file is absent, unreadable or already current.
+// A file that does not open with secret is left to the store's load path,
+// which either falls back to the legacy secret or fails startup. Nothing is
+// written, so a failing migration leaves the file untouched.
+func PlanStoreMigration(dir, secret string, schema StoreSchema) (PendingSchemaMigration, bool, error) {
+	path := filepath.Join(dir, schema.File)
+	doc, encrypted, err := readSchemaDocument(path, secret)
+	if err != nil {
+		if isSkippedSchemaDocument(err) || errors.Is(err, securestore.ErrAuthFailed) {
+			return PendingSchemaMigration{}, false, nil
+		}
+		return PendingSchemaMigration{}, false, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	from, err := schemaDocumentVersion(doc, schema.VersionField)
+	if err != nil {
+		return PendingSchemaMigration{}, false, fmt.Errorf("%s: %w", schema.Store, err)
+	}
+	switch {
+	case from == schema.Current:
+		return PendingSchemaMigration{}, false, nil
+	case from > schema.Current:
+		return PendingSchemaMigration{}, false, fmt.Errorf("%w: %s=%d current=%d", ErrUnsupportedStorageSchema, schema.Store, from, schema.Current)
+	}
+	for version := from; version < schema.Current; version++ {
+		migration, ok := findSchemaMigration(schema.Migrations, version)
+		if !ok {
+			return PendingSchemaMigration{}, false, fmt.Errorf("%w: %s has no migration from version %d", ErrUnsupportedStorageSchema, schema.Store, version)
+		}
+		if err := migration.Apply(doc); err != nil {
+			return PendingSchemaMigration{}, false, fmt.Errorf("%s: migrate from version %d: %w", schema.Store, version, err)
+		}
+	}
+	doc[schema.VersionField], err = json.Marshal(schema.Current)
+	if err != nil {
+		return PendingSchemaMigration{}, false, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return PendingSchemaMigration{}, false, err
+	}
+	if encrypted {
+		if data, err = securestore.Encrypt(secret, data); err != nil {
+			return PendingSchemaMigration{}, false, err
+		}
+	} else if data, err = securestore.AddRecordChecksums(data); err != nil {
+		// Migrations may rewrite records, so their checksums are taken afresh.
+		return PendingSchemaMigration{}, false, err
+	}
+	perm := fs.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return PendingSchemaMigration{
+		Store: schema.Store,
+		Path:  path,
+		From:  from,
+		To:    schema.Current,
+		data:  data,
+		perm:  perm,
+	}, true, nil
+}
+
+// Write replaces the store file with the migrated document.
+func (m PendingSchemaMigration) Write() error {
+	return replaceStoreFile(m.Path, m.data, m.perm)
+}
+
+func replaceStoreFile(path string, data []byte, perm fs.FileMode) error {
+	release, err := securestore.BeginWrite("")
+	if err != nil {
+		return err
+	}
+	defer release()
+	tmp := path + ".migrate"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func readSchemaDocument(path, secret string) (map[string]json.RawMessage, bool, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(raw) == 0 {
+		return nil, false, fs.ErrNotExist
+	}
+	encrypted := true
+	plaintext := raw
+	if secret != "" {
+		plaintext, err = securestore.Decrypt(secret, raw)
+		if errors.Is(err, securestore.ErrLegacyData) {
+			plaintext, encrypted, err = raw, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		encrypted = false
+	}
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(plaintext, &doc); err != nil {
+		return nil, false, errNotSchemaDocument
+	}
+	return doc, encrypted, nil
+}
+
+// isSkippedSchemaDocument reports files that are absent or not documents.
+// Authentication failures are not included: callers decide whether a file
+// that does not decrypt is foreign or corrupt.
+func isSkippedSchemaDocument(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, errNotSchemaDocument)
+}
+
+func schemaDocumentVersion(doc map[string]json.RawMessage, field string) (int, error) {
+	raw, ok := doc[field]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return version, nil
+}
+
+func findSchemaMigration(migrations []SchemaMigration, from int) (SchemaMigration, bool) {
+	for _, migration := range migrations {
+		if migration.From == from {
+			return migration, true
+		}
+	}
+	return SchemaMigration{}, false
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+func TestPlanStoreMigrationStepsThroughVersions(t *testing.T) {
+	dir := t.TempDir()
+	const secret = "schema-secret"
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dir, "notes.enc"), secret, map[string]any{
+		"version": 1,
+		"title":   "hello",
+	}); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	schema := StoreSchema{
+		Store:        "notes",
+		File:         "notes.enc",
+		VersionField: "version",
+		Current:      3,
+		Migrations: []SchemaMigration{
+			{From: 1, Apply: func(doc map[string]json.RawMessage) error {
+				doc["subject"] = doc["title"]
+				delete(doc, "title")
+				return nil
+			}},
+			{From: 2, Apply: func(doc map[string]json.RawMessage) error {
+				doc["tags"] = json.RawMessage(`[]`)
+				return nil
+			}},
+		},
+	}
+
+	pending, ok, err := PlanStoreMigration(dir, secret, schema)
+	if err != nil || !ok {
+		t.Fatalf("plan migration: ok=%v err=%v", ok, err)
+	}
+	if pending.From != 1 || pending.To != 3 {
+		t.Fatalf("unexpected pending migration: %+v", pending)
+	}
+	state, err := ReadStoreSchemaState(dir, secret, schema)
+	if err != nil || !state.Present || state.Version != 1 {
+		t.Fatalf("planning must not write: %+v err=%v", state, err)
+	}
+	if err := pending.Write(); err != nil {
+		t.Fatalf("write migration: %v", err)
+	}
+
+	plaintext, err := securestore.ReadDecryptedFile(filepath.Join(dir, "notes.enc"), secret)
+	if err != nil {
+		t.Fatalf("migrated file must stay encrypted: %v", err)
+	}
+	var doc struct {
+		Version int      `json:"version"`
+		Subject string   `json:"subject"`
+		Tags    []string `json:"tags"`
+	}
+	if err := json.Unmarshal(plaintext, &doc); err != nil {
+		t.Fatalf("decode migrated doc: %v", err)
+	}
+	if doc.Version != 3 || doc.Subject != "hello" || doc.Tags == nil {
+		t.Fatalf("unexpected migrated doc: %+v", doc)
+	}
+	if _, ok, err := PlanStoreMigration(dir, secret, schema); err != nil || ok {
+		t.Fatalf("current store must not migrate again: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestPlanStoreMigrationRejectsUnknownVersions(t *testing.T) {
+	dir := t.TempDir()
+	const secret = "schema-secret"
+	schema := StoreSchema{Store: "notes", File: "notes.enc", VersionField: "version", Current: 2}
+
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dir, "notes.enc"), secret, map[string]any{"version": 3}); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	if _, _, err := PlanStoreMigration(dir, secret, schema); !errors.Is(err, ErrUnsupportedStorageSchema) {
+		t.Fatalf("expected unsupported schema for newer file, got %v", err)
+	}
+
+	if err := securestore.WriteEncryptedJSON(filepath.Join(dir, "notes.enc"), secret, map[string]any{"version": 1}); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	if _, _, err := PlanStoreMigration(dir, secret, schema); !errors.Is(err, ErrUnsupportedStorageSchema) {
+		t.Fatalf("expected unsupported schema without migration path, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.enc"), []byte("not json"), 0o600); err != nil {
+		t.Fatalf("seed store: %v", err)
+	}
+	if _, ok, err := PlanStoreMigration(dir, secret, schema); err != nil || ok {
+		t.Fatalf("undecodable file must be left to the store: ok=%v err=%v", ok, err)
+	}
+}
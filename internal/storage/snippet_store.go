@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	ErrSnippetNotFound      = errors.New("snippet not found")
+	ErrSnippetQuotaExceeded = errors.New("snippet quota exceeded")
+)
+
+const (
+	snippetSchemaVersion = 1
+
+	maxSnippets = 1000
+)
+
+// SnippetStore keeps canned responses in an encrypted per-account file.
+// Shared snippets have no owner; private ones belong to the RPC caller
+// namespace that saved them and are invisible to every other caller.
+type SnippetStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	entries map[string]persistedSnippet
+}
+
+type persistedSnippet struct {
+	Owner string `json:"owner,omitempty"`
+	models.Snippet
+}
+
+type persistedSnippets struct {
+	Version  int                `json:"version"`
+	Snippets []persistedSnippet `json:"snippets"`
+}
+
+func NewSnippetStore() *SnippetStore {
+	return &SnippetStore{entries: map[string]persistedSnippet{}}
+}
+
+func (s *SnippetStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *SnippetStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]persistedSnippet{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedSnippets
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != snippetSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, entry := range payload.Snippets {
+		if entry.ID == "" {
+			continue
+		}
+		s.entries[entry.ID] = entry
+	}
+	return nil
+}
+
+// Put creates or replaces a snippet on behalf of caller. Private snippets
+// are owned by caller; a snippet owned by someone else is reported as
+// missing rather than overwritten.
+func (s *SnippetStore) Put(caller string, snippet models.Snippet) (models.Snippet, error) {
+	snippet.ID = strings.TrimSpace(snippet.ID)
+	if snippet.ID == "" {
+		return models.Snippet{}, errors.New("snippet id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, exists := s.entries[snippet.ID]
+	if exists && !snippetVisibleTo(existing, caller) {
+		return models.Snippet{}, ErrSnippetNotFound
+	}
+	if !exists && len(s.entries) >= maxSnippets {
+		return models.Snippet{}, fmt.Errorf("%w: %d snippets stored", ErrSnippetQuotaExceeded, maxSnippets)
+	}
+	entry := persistedSnippet{Snippet: snippet}
+	if snippet.Private {
+		entry.Owner = caller
+	}
+	next := cloneSnippets(s.entries)
+	next[snippet.ID] = entry
+	if err := s.persistLocked(next); err != nil {
+		return models.Snippet{}, err
+	}
+	s.entries = next
+	return snippet, nil
+}
+
+// Get returns a snippet visible to caller.
+func (s *SnippetStore) Get(caller, id string) (models.Snippet, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.entries[strings.TrimSpace(id)]
+	if !ok || !snippetVisibleTo(entry, caller) {
+		return models.Snippet{}, false
+	}
+	return entry.Snippet, true
+}
+
+// List returns the snippets visible to caller, sorted by name.
+func (s *SnippetStore) List(caller string) []models.Snippet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.Snippet, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if snippetVisibleTo(entry, caller) {
+			out = append(out, entry.Snippet)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Remove deletes a snippet visible to caller and reports whether it existed.
+func (s *SnippetStore) Remove(caller, id string) (bool, error) {
+	id = strings.TrimSpace(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[id]
+	if !ok || !snippetVisibleTo(entry, caller) {
+		return false, nil
+	}
+	next := cloneSnippets(s.entries)
+	delete(next, id)
+	if err := s.persistLocked(next); err != nil {
+		return false, err
+	}
+	s.entries = next
+	return true, nil
+}
+
+func (s *SnippetStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]persistedSnippet{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *SnippetStore) persistLocked(entries map[string]persistedSnippet) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	flat := make([]persistedSnippet, 0, len(entries))
+	for _, entry := range entries {
+		flat = append(flat, entry)
+	}
+	sort.Slice(flat, func(i, j int) bool { return flat[i].ID < flat[j].ID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedSnippets{
+		Version:  snippetSchemaVersion,
+		Snippets: flat,
+	})
+}
+
+func snippetVisibleTo(entry persistedSnippet, caller string) bool {
+	return entry.Owner == "" || entry.Owner == caller
+}
+
+func cloneSnippets(in map[string]persistedSnippet) map[string]persistedSnippet {
+	out := make(map[string]persistedSnippet, len(in))
+	for id, entry := range in {
+		entry.Placeholders = append([]string(nil), entry.Placeholders...)
+		out[id] = entry
+	}
+	return out
+}
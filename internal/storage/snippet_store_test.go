@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestSnippetStorePrivateSnippetsAreScopedToOwner(t *testing.T) {
+	store := NewSnippetStore()
+	if _, err := store.Put("integration:bot", models.Snippet{ID: "s-1", Name: "greet", Body: "hi", Private: true}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if _, err := store.Put("integration:bot", models.Snippet{ID: "s-2", Name: "bye", Body: "bye"}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if list := store.List("integration:bot"); len(list) != 2 || list[0].ID != "s-2" {
+		t.Fatalf("unexpected owner listing: %#v", list)
+	}
+	if list := store.List("integration:bridge"); len(list) != 1 || list[0].ID != "s-2" {
+		t.Fatalf("private snippet leaked to another caller: %#v", list)
+	}
+	if _, ok := store.Get("integration:bridge", "s-1"); ok {
+		t.Fatal("private snippet must not be readable by another caller")
+	}
+	if _, err := store.Put("integration:bridge", models.Snippet{ID: "s-1", Name: "x", Body: "x"}); !errors.Is(err, ErrSnippetNotFound) {
+		t.Fatalf("expected not found when overwriting a foreign snippet, got %v", err)
+	}
+	if removed, err := store.Remove("integration:bridge", "s-1"); err != nil || removed {
+		t.Fatalf("foreign remove must be a no-op: removed=%v err=%v", removed, err)
+	}
+}
+
+func TestSnippetStorePersistsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippets.enc")
+	store := NewSnippetStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if _, err := store.Put("integration:bot", models.Snippet{ID: "s-1", Name: "greet", Body: "Hi {{name}}", Placeholders: []string{"name"}, Private: true}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	reloaded := NewSnippetStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload bootstrap failed: %v", err)
+	}
+	got, ok := reloaded.Get("integration:bot", "s-1")
+	if !ok || got.Body != "Hi {{name}}" || len(got.Placeholders) != 1 {
+		t.Fatalf("snippet not persisted: %#v", got)
+	}
+	if _, ok := reloaded.Get("other", "s-1"); ok {
+		t.Fatal("owner must survive reload")
+	}
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/securestore"
+)
+
+const threadSubscriptionSchemaVersion = 1
+
+// ThreadSubscriptionStore remembers which group comment threads the account
+// follows, in an encrypted per-account file. Subscriptions are local to the
+// node and never replicated.
+type ThreadSubscriptionStore struct {
+	mu      sync.RWMutex
+	path    string
+	secret  string
+	entries map[string]map[string]struct{}
+}
+
+type persistedThreadSubscription struct {
+	GroupID string   `json:"group_id"`
+	Threads []string `json:"threads"`
+}
+
+type persistedThreadSubscriptions struct {
+	Version int                           `json:"version"`
+	Entries []persistedThreadSubscription `json:"entries"`
+}
+
+func NewThreadSubscriptionStore() *ThreadSubscriptionStore {
+	return &ThreadSubscriptionStore{
+		entries: map[string]map[string]struct{}{},
+	}
+}
+
+func (s *ThreadSubscriptionStore) Configure(path, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path, s.secret = securestore.NormalizeStorageConfig(path, secret)
+}
+
+func (s *ThreadSubscriptionStore) Bootstrap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]struct{}{}
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	plaintext, err := securestore.ReadDecryptedFile(s.path, s.secret)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var payload persistedThreadSubscriptions
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return err
+	}
+	if payload.Version != threadSubscriptionSchemaVersion {
+		return ErrUnsupportedStorageSchema
+	}
+	for _, entry := range payload.Entries {
+		groupID := strings.TrimSpace(entry.GroupID)
+		if groupID == "" {
+			continue
+		}
+		for _, threadID := range entry.Threads {
+			if threadID = strings.TrimSpace(threadID); threadID == "" {
+				continue
+			}
+			if s.entries[groupID] == nil {
+				s.entries[groupID] = map[string]struct{}{}
+			}
+			s.entries[groupID][threadID] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// Set subscribes to or unsubscribes from one thread and reports whether the
+// subscription changed.
+func (s *ThreadSubscriptionStore) Set(groupID, threadID string, subscribed bool) (bool, error) {
+	groupID, threadID = strings.TrimSpace(groupID), strings.TrimSpace(threadID)
+	if groupID == "" || threadID == "" {
+		return false, errors.New("group id and thread id are required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, exists := s.entries[groupID][threadID]
+	if exists == subscribed {
+		return false, nil
+	}
+	next := cloneThreadSubscriptions(s.entries)
+	if subscribed {
+		if next[groupID] == nil {
+			next[groupID] = map[string]struct{}{}
+		}
+		next[groupID][threadID] = struct{}{}
+	} else {
+		delete(next[groupID], threadID)
+		if len(next[groupID]) == 0 {
+			delete(next, groupID)
+		}
+	}
+	if err := s.persistLocked(next); err != nil {
+		return false, err
+	}
+	s.entries = next
+	return true, nil
+}
+
+func (s *ThreadSubscriptionStore) IsSubscribed(groupID, threadID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.entries[strings.TrimSpace(groupID)][strings.TrimSpace(threadID)]
+	return ok
+}
+
+// List returns the threads followed in a group, sorted.
+func (s *ThreadSubscriptionStore) List(groupID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	threads := s.entries[strings.TrimSpace(groupID)]
+	out := make([]string, 0, len(threads))
+	for threadID := range threads {
+		out = append(out, threadID)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *ThreadSubscriptionStore) Wipe() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]map[string]struct{}{}
+	if s.path == "" {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *ThreadSubscriptionStore) persistLocked(entries map[string]map[string]struct{}) error {
+	if !securestore.IsStorageConfigured(s.path, s.secret) {
+		return nil
+	}
+	flat := make([]persistedThreadSubscription, 0, len(entries))
+	for groupID, threads := range entries {
+		entry := persistedThreadSubscription{GroupID: groupID, Threads: make([]string, 0, len(threads))}
+		for threadID := range threads {
+			entry.Threads = append(entry.Threads, threadID)
+		}
+		sort.Strings(entry.Threads)
+		flat = append(flat, entry)
+	}
+	sort.Slice(flat, func(i, j int) bool { return flat[i].GroupID < flat[j].GroupID })
+	return securestore.WriteEncryptedJSON(s.path, s.secret, persistedThreadSubscriptions{
+		Version: threadSubscriptionSchemaVersion,
+		Entries: flat,
+	})
+}
+
+func cloneThreadSubscriptions(in map[string]map[string]struct{}) map[string]map[string]struct{} {
+	out := make(map[string]map[string]struct{}, len(in))
+	for groupID, threads := range in {
+		copied := make(map[string]struct{}, len(threads))
+		for threadID := range threads {
+			copied[threadID] = struct{}{}
+		}
+		out[groupID] = copied
+	}
+	return out
+}
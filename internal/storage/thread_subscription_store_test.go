@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestThreadSubscriptionStorePersistsAcrossBootstrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "thread_subscriptions.enc")
+	store := NewThreadSubscriptionStore()
+	store.Configure(path, "secret")
+	if err := store.Bootstrap(); err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	for _, threadID := range []string{"post-b", "post-a"} {
+		changed, err := store.Set("channel-1", threadID, true)
+		if err != nil || !changed {
+			t.Fatalf("subscribe %s: changed=%v err=%v", threadID, changed, err)
+		}
+	}
+	if changed, err := store.Set("channel-1", "post-a", true); err != nil || changed {
+		t.Fatalf("repeated subscribe must be a no-op: changed=%v err=%v", changed, err)
+	}
+	if _, err := store.Set("channel-1", " ", true); err == nil {
+		t.Fatal("empty thread id must be rejected")
+	}
+
+	reloaded := NewThreadSubscriptionStore()
+	reloaded.Configure(path, "secret")
+	if err := reloaded.Bootstrap(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got := reloaded.List("channel-1"); !reflect.DeepEqual(got, []string{"post-a", "post-b"}) {
+		t.Fatalf("unexpected subscriptions after reload: %v", got)
+	}
+	if changed, err := reloaded.Set("channel-1", "post-a", false); err != nil || !changed {
+		t.Fatalf("unsubscribe: changed=%v err=%v", changed, err)
+	}
+	if reloaded.IsSubscribed("channel-1", "post-a") || !reloaded.IsSubscribed("channel-1", "post-b") {
+		t.Fatalf("unexpected subscriptions after unsubscribe: %v", reloaded.List("channel-1"))
+	}
+
+	if _, err := reloaded.Set("channel-2", "post-c", true); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	if err := reloaded.Wipe(); err != nil {
+		t.Fatalf("wipe failed: %v", err)
+	}
+	if reloaded.IsSubscribed("channel-2", "post-c") {
+		t.Fatal("wipe must drop subscriptions")
+	}
+}
@@ -0,0 +1,36 @@
+package waku
+
+import "time"
+
+// bootstrapProbeTimeout bounds one latency measurement of a bootstrap node.
+const bootstrapProbeTimeout = 5 * time.Second
+
+// BootstrapProbe is the last latency measurement of one bootstrap node.
+// ConnectLatency is how long dialing it took. PublishLatency is the round
+// trip of a ping over that connection: relay publishes go to the whole mesh,
+// so this is the per-node floor on how fast a publish reaches it. Err is set
+// when the node could not be reached.
+type BootstrapProbe struct {
+	Address        string
+	ConnectLatency time.Duration
+	PublishLatency time.Duration
+	Err            error
+	MeasuredAt     time.Time
+}
+
+// Healthy reports whether the node answered the last probe.
+func (p BootstrapProbe) Healthy() bool {
+	return p.Err == nil
+}
+
+// BootstrapProbes returns the latest latency measurements of the bootstrap
+// nodes. The mock transport dials nothing and reports none.
+func (n *Node) BootstrapProbes() []BootstrapProbe {
+	n.mu.RLock()
+	gw := n.gw
+	n.mu.RUnlock()
+	if gw == nil {
+		return nil
+	}
+	return gw.BootstrapProbes()
+}
@@ -15,6 +15,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/waku-org/go-waku/waku/persistence"
@@ -27,10 +29,12 @@ import (
 	"github.com/waku-org/go-waku/waku/v2/utils"
 )
 
-const (
-	privatePubsubTopic  = "/waku/2/default-waku/proto"
-	privateContentTopic = "/aim-chat/1/private-message/proto"
-)
+// privateDedupWindow is how many recent private message IDs are remembered
+// to drop copies that arrive on more than one topic.
+const privateDedupWindow = 4096
+
+// privateFetchTopicBatch caps the content topics sent in one store query.
+const privateFetchTopicBatch = 10
 
 type goWakuNode struct {
 	mu             sync.RWMutex
@@ -42,6 +46,11 @@ type goWakuNode struct {
 	maintainCancel context.CancelFunc
 	maintainWG     sync.WaitGroup
 	metrics        goWakuMetrics
+	privateSeen    *recentMessageIDs
+	storeGuard     *storeNodeGuard
+	pairSecrets    map[string]PairTopicSecret
+	pairTopics     map[string]struct{}
+	probes         map[string]BootstrapProbe
 }
 
 type goWakuMetrics struct {
@@ -53,7 +62,12 @@ type goWakuMetrics struct {
 }
 
 func newGoWakuBackend() goWakuBackend {
-	return &goWakuNode{}
+	return &goWakuNode{
+		privateSeen: newRecentMessageIDs(privateDedupWindow),
+		pairSecrets: map[string]PairTopicSecret{},
+		pairTopics:  map[string]struct{}{},
+		probes:      map[string]BootstrapProbe{},
+	}
 }
 
 func (g *goWakuNode) Start(ctx context.Context, cfg Config) error {
@@ -66,14 +80,23 @@ func (g *goWakuNode) Start(ctx context.Context, cfg Config) error {
 	if cfg.EnableRelay {
 		opts = append(opts, wakuNode.WithWakuRelay())
 	}
-	if cfg.EnableStore {
-		provider, err := newInMemoryMessageProvider()
+	storeNode := cfg.StoreNodeEnabled && cfg.StoreNodePath != "" && g.storeGuard != nil
+	if cfg.EnableStore || storeNode {
+		var provider legacyStore.MessageProvider
+		if storeNode {
+			provider, err = newPersistentMessageProvider(cfg, g.storeGuard)
+		} else {
+			provider, err = newInMemoryMessageProvider()
+		}
 		if err != nil {
 			return err
 		}
 		opts = append(opts, wakuNode.WithMessageProvider(provider))
 		opts = append(opts, wakuNode.WithWakuStore())
 	}
+	if storeNode && !cfg.EnableRelay {
+		opts = append(opts, wakuNode.WithWakuRelay())
+	}
 	if cfg.EnableFilter {
 		opts = append(opts, wakuNode.WithWakuFilterLightNode(), wakuNode.WithWakuFilterFullNode())
 	}
@@ -90,7 +113,7 @@ func (g *goWakuNode) Start(ctx context.Context, cfg Config) error {
 	}
 
 	for _, addr := range cfg.BootstrapNodes {
-		_ = node.DialPeer(ctx, addr)
+		g.probeBootstrapNode(ctx, node, addr)
 	}
 
 	g.mu.Lock()
@@ -98,12 +121,23 @@ func (g *goWakuNode) Start(ctx context.Context, cfg Config) error {
 	g.cfg = cfg
 	g.bootstrapNodes = append([]string(nil), cfg.BootstrapNodes...)
 	g.mu.Unlock()
+	if storeNode {
+		if err := g.subscribeStoreTopics(cfg.storeNodeTopics()); err != nil {
+			return err
+		}
+	}
 	if cfg.FailoverV1 {
 		g.startPeerMaintenance()
 	}
 	return nil
 }
 
+func (g *goWakuNode) UseStoreGuard(guard *storeNodeGuard) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.storeGuard = guard
+}
+
 func (g *goWakuNode) Stop() {
 	g.stopPeerMaintenance()
 
@@ -175,9 +209,69 @@ func (g *goWakuNode) ListenAddresses() []string {
 func (g *goWakuNode) SubscribePrivate(handler func(PrivateMessage)) error {
 	g.mu.Lock()
 	g.handler = handler
-	node := g.node
 	selfID := g.selfID
+	topics := g.cfg.privateContentTopics(selfID, time.Now())
+	g.pairTopics = map[string]struct{}{}
+	g.mu.Unlock()
+	if err := g.subscribeTopic(selfID, g.dedupedPrivateHandler(handler), topics...); err != nil {
+		return err
+	}
+	return g.refreshPairSubscriptions()
+}
+
+func (g *goWakuNode) dedupedPrivateHandler(handler func(PrivateMessage)) func(PrivateMessage) {
+	seen := g.privateSeen
+	return func(msg PrivateMessage) {
+		if seen.firstSeen(msg.ID) {
+			handler(msg)
+		}
+	}
+}
+
+func (g *goWakuNode) SetPairTopicSecrets(secrets map[string]PairTopicSecret) {
+	g.mu.Lock()
+	g.pairSecrets = secrets
+	g.mu.Unlock()
+	if err := g.refreshPairSubscriptions(); err != nil {
+		slog.Default().Warn("pair topic subscription failed", "error", err.Error())
+	}
+}
+
+// refreshPairSubscriptions subscribes to the pair topics due now and drops
+// those whose epoch has passed.
+func (g *goWakuNode) refreshPairSubscriptions() error {
+	g.mu.Lock()
+	node, handler, selfID := g.node, g.handler, g.selfID
+	if node == nil || handler == nil || !g.cfg.pairTopicsEnabled() {
+		g.mu.Unlock()
+		return nil
+	}
+	wanted, added, stale := pairSubscriptionChanges(g.pairTopics, g.pairSecrets, time.Now(), g.cfg.PairTopicOverlap)
+	g.pairTopics = wanted
 	g.mu.Unlock()
+
+	if len(stale) > 0 {
+		if err := node.Relay().Unsubscribe(context.Background(), protocol.NewContentFilter(privatePubsubTopic, stale...)); err != nil {
+			return err
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	return g.subscribeTopic(selfID, g.dedupedPrivateHandler(handler), added...)
+}
+
+func (g *goWakuNode) SubscribeReceipts(handler func(PrivateMessage)) error {
+	g.mu.RLock()
+	selfID := g.selfID
+	g.mu.RUnlock()
+	return g.subscribeTopic(selfID, handler, receiptContentTopic(selfID))
+}
+
+func (g *goWakuNode) subscribeTopic(selfID string, handler func(PrivateMessage), contentTopics ...string) error {
+	g.mu.RLock()
+	node := g.node
+	g.mu.RUnlock()
 	if node == nil {
 		return errors.New("go-waku node is nil")
 	}
@@ -185,7 +279,7 @@ func (g *goWakuNode) SubscribePrivate(handler func(PrivateMessage)) error {
 		return errors.New("identity is not set")
 	}
 
-	filter := protocol.NewContentFilter(privatePubsubTopic, privateContentTopic)
+	filter := protocol.NewContentFilter(privatePubsubTopic, contentTopics...)
 	subs, err := node.Relay().Subscribe(context.Background(), filter)
 	if err != nil {
 		return err
@@ -204,6 +298,9 @@ func (g *goWakuNode) SubscribePrivate(handler func(PrivateMessage)) error {
 				if msg.Recipient != selfID {
 					continue
 				}
+				if ts := env.Message().GetTimestamp(); ts > 0 {
+					msg.SentAt = time.Unix(0, ts)
+				}
 				handler(msg)
 			}
 		}(sub)
@@ -212,7 +309,26 @@ func (g *goWakuNode) SubscribePrivate(handler func(PrivateMessage)) error {
 	return nil
 }
 
+// PublishPrivate sends msg on every topic the recipient listens to. During a
+// sharding migration that is both the recipient's shard and the legacy topic;
+// with pair topics it is only the topic shared with the recipient.
 func (g *goWakuNode) PublishPrivate(ctx context.Context, msg PrivateMessage) error {
+	g.mu.RLock()
+	topics := g.cfg.publishContentTopics(msg.Recipient, g.pairSecrets[msg.Recipient], time.Now())
+	g.mu.RUnlock()
+	for _, topic := range topics {
+		if err := g.publishTopic(ctx, topic, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *goWakuNode) PublishReceipt(ctx context.Context, msg PrivateMessage) error {
+	return g.publishTopic(ctx, receiptContentTopic(msg.Recipient), msg)
+}
+
+func (g *goWakuNode) publishTopic(ctx context.Context, contentTopic string, msg PrivateMessage) error {
 	g.mu.RLock()
 	node := g.node
 	g.mu.RUnlock()
@@ -227,7 +343,7 @@ func (g *goWakuNode) PublishPrivate(ctx context.Context, msg PrivateMessage) err
 	ts := time.Now().UnixNano()
 	wm := &wpb.WakuMessage{
 		Payload:      payload,
-		ContentTopic: privateContentTopic,
+		ContentTopic: contentTopic,
 		Timestamp:    &ts,
 	}
 	_, err = node.Relay().Publish(ctx, wm, relay.WithPubSubTopic(privatePubsubTopic))
@@ -235,6 +351,26 @@ func (g *goWakuNode) PublishPrivate(ctx context.Context, msg PrivateMessage) err
 }
 
 func (g *goWakuNode) FetchPrivateSince(ctx context.Context, recipient string, since time.Time, limit int) ([]PrivateMessage, error) {
+	g.mu.RLock()
+	topics := g.cfg.fetchContentTopics(recipient, g.pairSecrets, since, time.Now())
+	g.mu.RUnlock()
+	out := make([]PrivateMessage, 0)
+	for start := 0; start < len(topics); start += privateFetchTopicBatch {
+		end := min(start+privateFetchTopicBatch, len(topics))
+		batch, err := g.fetchTopicSince(ctx, recipient, since, limit, topics[start:end]...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, batch...)
+	}
+	return out, nil
+}
+
+func (g *goWakuNode) FetchReceiptsSince(ctx context.Context, recipient string, since time.Time, limit int) ([]PrivateMessage, error) {
+	return g.fetchTopicSince(ctx, recipient, since, limit, receiptContentTopic(recipient))
+}
+
+func (g *goWakuNode) fetchTopicSince(ctx context.Context, recipient string, since time.Time, limit int, contentTopics ...string) ([]PrivateMessage, error) {
 	g.mu.RLock()
 	node := g.node
 	g.mu.RUnlock()
@@ -251,7 +387,7 @@ func (g *goWakuNode) FetchPrivateSince(ctx context.Context, recipient string, si
 	end := time.Now().UnixNano()
 	criteria := legacyStore.Query{
 		PubsubTopic:   privatePubsubTopic,
-		ContentTopics: []string{privateContentTopic},
+		ContentTopics: contentTopics,
 		StartTime:     &start,
 		EndTime:       &end,
 	}
@@ -484,7 +620,10 @@ func (g *goWakuNode) redialBootstrapPeers(ctx context.Context, rnd *rand.Rand) b
 			continue
 		}
 		g.recordDialAttempt()
-		if err := node.DialPeer(ctx, addr); err == nil {
+		started := time.Now()
+		err := node.DialPeer(ctx, addr)
+		g.recordBootstrapConnect(addr, time.Since(started), err)
+		if err == nil {
 			g.recordDialSuccess()
 			success = true
 			slog.Info("peer redial succeeded", "peer_addr", addr, "attempt", attempt)
@@ -497,6 +636,59 @@ func (g *goWakuNode) redialBootstrapPeers(ctx context.Context, rnd *rand.Rand) b
 	return success
 }
 
+// probeBootstrapNode dials a bootstrap node and pings it over the new
+// connection, recording both latencies.
+func (g *goWakuNode) probeBootstrapNode(ctx context.Context, node *wakuNode.WakuNode, addr string) {
+	probeCtx, cancel := context.WithTimeout(ctx, bootstrapProbeTimeout)
+	defer cancel()
+	probe := BootstrapProbe{Address: addr, MeasuredAt: time.Now().UTC()}
+	started := time.Now()
+	if err := node.DialPeer(probeCtx, addr); err != nil {
+		probe.Err = err
+		g.storeBootstrapProbe(probe)
+		return
+	}
+	probe.ConnectLatency = time.Since(started)
+	if info, err := peer.AddrInfoFromString(addr); err == nil {
+		if result := <-ping.Ping(probeCtx, node.Host(), info.ID); result.Error == nil {
+			probe.PublishLatency = result.RTT
+		}
+	}
+	g.storeBootstrapProbe(probe)
+}
+
+// recordBootstrapConnect updates the connect latency of a redialed node and
+// keeps its last measured ping.
+func (g *goWakuNode) recordBootstrapConnect(addr string, latency time.Duration, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	probe := g.probes[addr]
+	probe.Address = addr
+	probe.MeasuredAt = time.Now().UTC()
+	probe.Err = err
+	if err == nil {
+		probe.ConnectLatency = latency
+	}
+	g.probes[addr] = probe
+}
+
+func (g *goWakuNode) storeBootstrapProbe(probe BootstrapProbe) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.probes[probe.Address] = probe
+}
+
+func (g *goWakuNode) BootstrapProbes() []BootstrapProbe {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	out := make([]BootstrapProbe, 0, len(g.probes))
+	for _, probe := range g.probes {
+		out = append(out, probe)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
 func (g *goWakuNode) recordDialAttempt() {
 	g.mu.Lock()
 	g.metrics.DialAttempts++
@@ -1,44 +1,76 @@
 package waku
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type PrivateMessage struct {
 	ID        string
 	SenderID  string
 	Recipient string
 	Payload   []byte
+	// SentAt is the sender's clock at publish time as carried by the
+	// transport envelope. It is set for live deliveries only and is not
+	// part of the payload.
+	SentAt time.Time `json:"-"`
+}
+
+type queuedMessage struct {
+	msg      PrivateMessage
+	queuedAt time.Time
 }
 
 type messageBus struct {
 	mu          sync.Mutex
 	subscribers map[string]func(PrivateMessage)
-	mailbox     map[string][]PrivateMessage
+	mailbox     map[string][]queuedMessage
+	// retention bounds how long undelivered mail is kept; zero keeps it until
+	// the recipient subscribes.
+	retention time.Duration
 }
 
-var globalBus = &messageBus{
-	subscribers: make(map[string]func(PrivateMessage)),
-	mailbox:     make(map[string][]PrivateMessage),
+var globalBus = newMessageBus(0)
+
+// globalReceiptBus carries delivery/read receipts separately from private
+// messages so that they never compete with message history.
+var globalReceiptBus = newMessageBus(DefaultConfig().ReceiptRetention)
+
+func newMessageBus(retention time.Duration) *messageBus {
+	return &messageBus{
+		subscribers: make(map[string]func(PrivateMessage)),
+		mailbox:     make(map[string][]queuedMessage),
+		retention:   retention,
+	}
 }
 
 func (b *messageBus) publish(msg PrivateMessage) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 	if handler, ok := b.subscribers[msg.Recipient]; ok {
+		msg.SentAt = time.Now()
 		go handler(msg)
 		return
 	}
-	b.mailbox[msg.Recipient] = append(b.mailbox[msg.Recipient], msg)
+	b.mailbox[msg.Recipient] = append(b.mailbox[msg.Recipient], queuedMessage{msg: msg, queuedAt: time.Now()})
 }
 
 func (b *messageBus) subscribe(recipient string, handler func(PrivateMessage)) {
 	b.mu.Lock()
 	b.subscribers[recipient] = handler
-	pending := append([]PrivateMessage(nil), b.mailbox[recipient]...)
+	queued := b.mailbox[recipient]
 	delete(b.mailbox, recipient)
 	b.mu.Unlock()
 
-	for _, msg := range pending {
-		handler(msg)
+	cutoff := time.Time{}
+	if b.retention > 0 {
+		cutoff = time.Now().Add(-b.retention)
+	}
+	for _, item := range queued {
+		if item.queuedAt.Before(cutoff) {
+			continue
+		}
+		handler(item.msg)
 	}
 }
 
@@ -23,6 +23,7 @@ type Config struct {
 	Transport                  string        `yaml:"transport"`
 	Port                       int           `yaml:"port"`
 	AdvertiseAddress           string        `yaml:"advertiseAddress"`
+	NetworkSegment             string        `yaml:"networkSegment"`
 	EnableRelay                bool          `yaml:"enableRelay"`
 	EnableStore                bool          `yaml:"enableStore"`
 	EnableFilter               bool          `yaml:"enableFilter"`
@@ -31,6 +32,17 @@ type Config struct {
 	FailoverV1                 bool          `yaml:"failoverV1"`
 	MinPeers                   int           `yaml:"minPeers"`
 	StoreQueryFanout           int           `yaml:"storeQueryFanout"`
+	ReceiptRetention           time.Duration `yaml:"receiptRetention"`
+	TopicShards                int           `yaml:"topicShards"`
+	TopicMigrationUntil        time.Time     `yaml:"topicMigrationUntil"`
+	PairTopics                 bool          `yaml:"pairTopics"`
+	PairTopicOverlap           time.Duration `yaml:"pairTopicOverlap"`
+	StoreNodeEnabled           bool          `yaml:"storeNodeEnabled"`
+	StoreNodeTopics            []string      `yaml:"storeNodeTopics"`
+	StoreNodeQuotaBytes        int64         `yaml:"storeNodeQuotaBytes"`
+	StoreNodeRetention         time.Duration `yaml:"storeNodeRetention"`
+	StoreNodeQueriesPerMinute  int           `yaml:"storeNodeQueriesPerMinute"`
+	StoreNodePath              string        `yaml:"-"`
 	ReconnectInterval          time.Duration `yaml:"reconnectInterval"`
 	ReconnectBackoffMax        time.Duration `yaml:"reconnectBackoffMax"`
 	ManifestRefreshInterval    time.Duration `yaml:"manifestRefreshInterval"`
@@ -40,12 +52,16 @@ type Config struct {
 	ManifestBackoffMax         time.Duration `yaml:"manifestBackoffMax"`
 	ManifestBackoffFactor      float64       `yaml:"manifestBackoffFactor"`
 	ManifestBackoffJitterRatio float64       `yaml:"manifestBackoffJitterRatio"`
+	ManifestURL                string        `yaml:"manifestURL"`
+	TrustBundleURL             string        `yaml:"trustBundleURL"`
+	ManifestProxyURL           string        `yaml:"manifestProxy"`
 	BootstrapSource            string        `yaml:"-"`
 	BootstrapManifestVersion   int           `yaml:"-"`
 	BootstrapManifestKeyID     string        `yaml:"-"`
 	BootstrapManifestPath      string        `yaml:"-"`
 	BootstrapTrustBundlePath   string        `yaml:"-"`
 	BootstrapCachePath         string        `yaml:"-"`
+	BootstrapRankingPath       string        `yaml:"-"`
 }
 
 type Status struct {
@@ -64,6 +80,9 @@ type Node struct {
 	selfID  string
 	handler func(PrivateMessage)
 	gw      goWakuBackend
+	store   *storeNodeGuard
+	pairs   map[string]PairTopicSecret
+	clock   *peerClock
 
 	monitorCancel    context.CancelFunc
 	monitorWG        sync.WaitGroup
@@ -77,10 +96,16 @@ type goWakuBackend interface {
 	NetworkMetrics() map[string]int
 	ApplyConfig(cfg Config)
 	SetIdentity(identityID string)
+	SetPairTopicSecrets(secrets map[string]PairTopicSecret)
 	ListenAddresses() []string
 	SubscribePrivate(handler func(PrivateMessage)) error
 	PublishPrivate(ctx context.Context, msg PrivateMessage) error
 	FetchPrivateSince(ctx context.Context, recipient string, since time.Time, limit int) ([]PrivateMessage, error)
+	SubscribeReceipts(handler func(PrivateMessage)) error
+	PublishReceipt(ctx context.Context, msg PrivateMessage) error
+	FetchReceiptsSince(ctx context.Context, recipient string, since time.Time, limit int) ([]PrivateMessage, error)
+	UseStoreGuard(guard *storeNodeGuard)
+	BootstrapProbes() []BootstrapProbe
 }
 
 func DefaultConfig() Config {
@@ -95,6 +120,8 @@ func DefaultConfig() Config {
 		FailoverV1:                 true,
 		MinPeers:                   2,
 		StoreQueryFanout:           3,
+		ReceiptRetention:           1 * time.Hour,
+		PairTopicOverlap:           DefaultPairTopicOverlap,
 		ReconnectInterval:          1 * time.Second,
 		ReconnectBackoffMax:        30 * time.Second,
 		ManifestRefreshInterval:    60 * time.Second,
@@ -110,7 +137,9 @@ func DefaultConfig() Config {
 func NewNode(cfg Config) *Node {
 	cfg = normalizeConfig(cfg)
 	return &Node{
-		cfg: cfg,
+		cfg:   cfg,
+		store: newStoreNodeGuard(cfg),
+		clock: newPeerClock(),
 		status: Status{
 			State:           StateDisconnected,
 			PeerCount:       0,
@@ -127,6 +156,30 @@ func normalizeConfig(cfg Config) Config {
 	if cfg.StoreQueryFanout <= 0 {
 		cfg.StoreQueryFanout = def.StoreQueryFanout
 	}
+	if cfg.TopicShards < 0 {
+		cfg.TopicShards = 0
+	}
+	if cfg.TopicShards > MaxTopicShards {
+		cfg.TopicShards = MaxTopicShards
+	}
+	if cfg.PairTopicOverlap <= 0 {
+		cfg.PairTopicOverlap = DefaultPairTopicOverlap
+	}
+	if cfg.PairTopicOverlap > PairTopicEpoch/2 {
+		cfg.PairTopicOverlap = PairTopicEpoch / 2
+	}
+	if cfg.StoreNodeQuotaBytes <= 0 {
+		cfg.StoreNodeQuotaBytes = DefaultStoreNodeQuotaBytes
+	}
+	if cfg.StoreNodeRetention <= 0 {
+		cfg.StoreNodeRetention = DefaultStoreNodeRetention
+	}
+	if cfg.StoreNodeQueriesPerMinute <= 0 {
+		cfg.StoreNodeQueriesPerMinute = DefaultStoreNodeQueriesPerMinute
+	}
+	if cfg.ReceiptRetention <= 0 {
+		cfg.ReceiptRetention = def.ReceiptRetention
+	}
 	if cfg.ReconnectInterval <= 0 {
 		cfg.ReconnectInterval = def.ReconnectInterval
 	}
@@ -180,6 +233,10 @@ func (n *Node) Start(ctx context.Context) error {
 			n.setDisconnected()
 			return errors.New("go-waku backend is not available in this build")
 		}
+		backend.UseStoreGuard(n.store)
+		n.mu.RLock()
+		backend.SetPairTopicSecrets(clonePairSecrets(n.pairs))
+		n.mu.RUnlock()
 		if err := backend.Start(ctx, n.cfg); err != nil {
 			n.setDisconnected()
 			return err
@@ -229,6 +286,7 @@ func (n *Node) Stop(_ context.Context) error {
 	}
 	if n.selfID != "" {
 		globalBus.unsubscribe(n.selfID)
+		globalReceiptBus.unsubscribe(n.selfID)
 	}
 	n.transitionStateLocked(StateDisconnected)
 	n.status.PeerCount = 0
@@ -251,6 +309,47 @@ func (n *Node) Status() Status {
 	return s
 }
 
+// ClockEstimate reports the median offset of recently heard peers' clocks.
+func (n *Node) ClockEstimate() ClockEstimate {
+	return n.clock.estimate(time.Now())
+}
+
+// sampleClock feeds the send time of live deliveries into the peer clock.
+func (n *Node) sampleClock(handler func(PrivateMessage)) func(PrivateMessage) {
+	return func(msg PrivateMessage) {
+		n.clock.observe(msg.SenderID, msg.SentAt, time.Now())
+		handler(msg)
+	}
+}
+
+// StoreNodeStatus reports the community store mode and its usage.
+func (n *Node) StoreNodeStatus() StoreNodeStatus {
+	return n.store.snapshot()
+}
+
+// MailboxStatus reports what the store holds for each sender.
+func (n *Node) MailboxStatus() MailboxStatus {
+	return n.store.mailboxSnapshot()
+}
+
+// ConfigureMailbox sets the per-sender quota and the eviction policy of the
+// store. A zero quota lifts the per-sender limit.
+func (n *Node) ConfigureMailbox(senderQuotaBytes int64, eviction string) error {
+	return n.store.configureMailbox(senderQuotaBytes, eviction)
+}
+
+// FlushMailbox applies retention, quotas and the eviction policy now and
+// reports how many envelopes were dropped.
+func (n *Node) FlushMailbox() (int, error) {
+	return n.store.flushMailbox(time.Now())
+}
+
+// PurgeMailbox drops everything stored from senderID, or everything stored
+// when senderID is empty, and reports how many envelopes were dropped.
+func (n *Node) PurgeMailbox(senderID string) (int, error) {
+	return n.store.purgeMailbox(senderID)
+}
+
 func (n *Node) SetIdentity(identityID string) {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -260,6 +359,18 @@ func (n *Node) SetIdentity(identityID string) {
 	}
 }
 
+// SetPairTopicSecrets replaces the per-contact topic secrets. Calling it
+// again after a topic rollover moves subscriptions to the new topics.
+func (n *Node) SetPairTopicSecrets(secrets map[string]PairTopicSecret) {
+	n.mu.Lock()
+	n.pairs = clonePairSecrets(secrets)
+	gw := n.gw
+	n.mu.Unlock()
+	if gw != nil {
+		gw.SetPairTopicSecrets(clonePairSecrets(secrets))
+	}
+}
+
 func (n *Node) ApplyBootstrapConfig(cfg Config) {
 	cfg = normalizeConfig(cfg)
 
@@ -297,6 +408,7 @@ func (n *Node) SubscribePrivate(handler func(PrivateMessage)) error {
 	if selfID == "" {
 		return errors.New("identity is not set")
 	}
+	handler = n.sampleClock(handler)
 	if gw != nil {
 		return gw.SubscribePrivate(handler)
 	}
@@ -349,6 +461,78 @@ func (n *Node) FetchPrivateSince(ctx context.Context, recipient string, since ti
 	return gw.FetchPrivateSince(ctx, recipient, since, limit)
 }
 
+// SubscribeReceipts listens on the local identity's dedicated receipts
+// channel. Receipts published inline on the private channel keep arriving
+// through SubscribePrivate.
+func (n *Node) SubscribeReceipts(handler func(PrivateMessage)) error {
+	n.mu.RLock()
+	state := n.status.State
+	selfID := n.selfID
+	gw := n.gw
+	n.mu.RUnlock()
+
+	if state != StateConnected && state != StateDegraded {
+		return errors.New("waku not connected")
+	}
+	if selfID == "" {
+		return errors.New("identity is not set")
+	}
+	handler = n.sampleClock(handler)
+	if gw != nil {
+		return gw.SubscribeReceipts(handler)
+	}
+	globalReceiptBus.subscribe(selfID, handler)
+	return nil
+}
+
+func (n *Node) PublishReceipt(ctx context.Context, msg PrivateMessage) error {
+	n.mu.RLock()
+	state := n.status.State
+	gw := n.gw
+	n.mu.RUnlock()
+	if state != StateConnected && state != StateDegraded {
+		return errors.New("waku not connected")
+	}
+	if msg.Recipient == "" {
+		return errors.New("recipient is required")
+	}
+	if gw != nil {
+		return gw.PublishReceipt(ctx, msg)
+	}
+	globalReceiptBus.publish(msg)
+	return nil
+}
+
+// FetchReceiptsSince queries the receipts channel history. The window is
+// clamped to the receipt retention so that catching up after downtime never
+// pulls stale receipts.
+func (n *Node) FetchReceiptsSince(ctx context.Context, recipient string, since time.Time, limit int) ([]PrivateMessage, error) {
+	n.mu.RLock()
+	state := n.status.State
+	gw := n.gw
+	retention := n.cfg.ReceiptRetention
+	n.mu.RUnlock()
+	if state != StateConnected && state != StateDegraded {
+		return nil, errors.New("waku not connected")
+	}
+	if recipient == "" {
+		return nil, errors.New("recipient is required")
+	}
+	if gw == nil {
+		return nil, nil
+	}
+	if floor := time.Now().Add(-retention); since.Before(floor) {
+		since = floor
+	}
+	return gw.FetchReceiptsSince(ctx, recipient, since, limit)
+}
+
+func (n *Node) ReceiptRetention() time.Duration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.cfg.ReceiptRetention
+}
+
 func (n *Node) setDisconnected() {
 	n.mu.Lock()
 	defer n.mu.Unlock()
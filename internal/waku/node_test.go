@@ -204,6 +204,7 @@ func (f *fakeGoWakuBackend) NetworkMetrics() map[string]int          { return ma
 func (f *fakeGoWakuBackend) ApplyConfig(_ Config)                    {}
 func (f *fakeGoWakuBackend) SetIdentity(_ string)                    {}
 func (f *fakeGoWakuBackend) ListenAddresses() []string               { return nil }
+func (f *fakeGoWakuBackend) BootstrapProbes() []BootstrapProbe       { return nil }
 func (f *fakeGoWakuBackend) SubscribePrivate(_ func(PrivateMessage)) error {
 	return nil
 }
@@ -213,6 +214,18 @@ func (f *fakeGoWakuBackend) PublishPrivate(_ context.Context, _ PrivateMessage)
 func (f *fakeGoWakuBackend) FetchPrivateSince(_ context.Context, _ string, _ time.Time, _ int) ([]PrivateMessage, error) {
 	return nil, nil
 }
+func (f *fakeGoWakuBackend) SubscribeReceipts(_ func(PrivateMessage)) error {
+	return nil
+}
+func (f *fakeGoWakuBackend) PublishReceipt(_ context.Context, _ PrivateMessage) error {
+	return nil
+}
+func (f *fakeGoWakuBackend) FetchReceiptsSince(_ context.Context, _ string, _ time.Time, _ int) ([]PrivateMessage, error) {
+	return nil, nil
+}
+func (f *fakeGoWakuBackend) UseStoreGuard(_ *storeNodeGuard) {}
+
+func (f *fakeGoWakuBackend) SetPairTopicSecrets(_ map[string]PairTopicSecret) {}
 func (f *fakeGoWakuBackend) PeerCount() int {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -223,3 +236,57 @@ func (f *fakeGoWakuBackend) setPeerCount(v int) {
 	f.peerCount = v
 	f.mu.Unlock()
 }
+
+func TestNodeReceiptChannelIsSeparateFromPrivate(t *testing.T) {
+	sender := NewNode(DefaultConfig())
+	receiver := NewNode(DefaultConfig())
+	for _, n := range []*Node{sender, receiver} {
+		if err := n.Start(context.Background()); err != nil {
+			t.Fatalf("start failed: %v", err)
+		}
+	}
+	defer func() { _ = sender.Stop(context.Background()) }()
+	defer func() { _ = receiver.Stop(context.Background()) }()
+	receiver.SetIdentity("aim1receipt-receiver")
+
+	privateCh := make(chan PrivateMessage, 1)
+	receiptCh := make(chan PrivateMessage, 1)
+	if err := receiver.SubscribePrivate(func(msg PrivateMessage) { privateCh <- msg }); err != nil {
+		t.Fatalf("subscribe private: %v", err)
+	}
+	if err := receiver.SubscribeReceipts(func(msg PrivateMessage) { receiptCh <- msg }); err != nil {
+		t.Fatalf("subscribe receipts: %v", err)
+	}
+
+	if err := sender.PublishReceipt(context.Background(), PrivateMessage{ID: "rcpt-1", Recipient: "aim1receipt-receiver"}); err != nil {
+		t.Fatalf("publish receipt: %v", err)
+	}
+	select {
+	case msg := <-receiptCh:
+		if msg.ID != "rcpt-1" {
+			t.Fatalf("unexpected receipt: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("receipt was not delivered")
+	}
+	select {
+	case msg := <-privateCh:
+		t.Fatalf("receipt leaked into private channel: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMessageBusDropsMailboxEntriesPastRetention(t *testing.T) {
+	bus := newMessageBus(time.Minute)
+	bus.publish(PrivateMessage{ID: "stale", Recipient: "r"})
+	bus.publish(PrivateMessage{ID: "fresh", Recipient: "r"})
+	bus.mu.Lock()
+	bus.mailbox["r"][0].queuedAt = time.Now().Add(-2 * time.Minute)
+	bus.mu.Unlock()
+
+	var got []string
+	bus.subscribe("r", func(msg PrivateMessage) { got = append(got, msg.ID) })
+	if len(got) != 1 || got[0] != "fresh" {
+		t.Fatalf("expected only fresh receipt, got %v", got)
+	}
+}
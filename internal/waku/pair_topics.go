@@ -0,0 +1,150 @@
+package waku
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// PairTopicEpoch is how long one pair topic stays in use. Topics roll at
+	// UTC midnight.
+	PairTopicEpoch = 24 * time.Hour
+	// DefaultPairTopicOverlap is how long around a rollover both the old and
+	// the new topic are listened to, covering clock skew and late delivery.
+	DefaultPairTopicOverlap = time.Hour
+	// maxPairTopicFetchEpochs bounds how many past topics a history fetch
+	// asks for per contact.
+	maxPairTopicFetchEpochs = 7
+
+	pairContentTopicPrefix = "/aim-chat/1/pair-"
+)
+
+// Metadata exposure, as measured in pair_topics_test.go. A shard topic is
+// derived from the recipient alone, so a relay watching it learns the
+// recipient's bucket forever and links every conversation that reaches it.
+// A pair topic is keyed with a secret only the two peers hold: it reveals
+// neither identity, is never shared by two conversations and cannot be
+// linked to the next day's topic, so the observable link between two
+// identities lasts at most one epoch plus the overlap window.
+
+// PairTopicSecret is the topic secret shared with one contact. Publish is
+// set once the contact is known to listen on the pair topic; until then
+// messages keep going to the contact's own topics.
+type PairTopicSecret struct {
+	Secret  []byte
+	Publish bool
+}
+
+// pairTopicEpochIndex numbers the epoch containing at.
+func pairTopicEpochIndex(at time.Time) int64 {
+	return at.UTC().Unix() / int64(PairTopicEpoch/time.Second)
+}
+
+// PairContentTopic derives the content topic a pair uses during epoch from
+// their shared topic secret. Both peers compute the same name.
+func PairContentTopic(secret []byte, epoch int64) string {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(epoch))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("aim/topic/pair/epoch/v1|"))
+	mac.Write(buf[:])
+	return pairContentTopicPrefix + hex.EncodeToString(mac.Sum(nil)[:8]) + "/proto"
+}
+
+// pairTopicsAt lists the topics to listen on at now: the current epoch and,
+// within overlap of a rollover, the neighbouring one.
+func pairTopicsAt(secret []byte, now time.Time, overlap time.Duration) []string {
+	epoch := pairTopicEpochIndex(now)
+	start := time.Unix(epoch*int64(PairTopicEpoch/time.Second), 0)
+	topics := []string{PairContentTopic(secret, epoch)}
+	if now.Sub(start) < overlap {
+		topics = append(topics, PairContentTopic(secret, epoch-1))
+	}
+	if start.Add(PairTopicEpoch).Sub(now) < overlap {
+		topics = append(topics, PairContentTopic(secret, epoch+1))
+	}
+	return topics
+}
+
+// pairTopicsBetween lists the topics of every epoch touching [since, until],
+// newest first and capped at maxPairTopicFetchEpochs.
+func pairTopicsBetween(secret []byte, since, until time.Time) []string {
+	first, last := pairTopicEpochIndex(since), pairTopicEpochIndex(until)
+	topics := make([]string, 0, maxPairTopicFetchEpochs)
+	for epoch := last; epoch >= first && len(topics) < maxPairTopicFetchEpochs; epoch-- {
+		topics = append(topics, PairContentTopic(secret, epoch))
+	}
+	return topics
+}
+
+// pairTopicsEnabled reports whether traffic to known contacts moves to pair
+// topics. Like shard counts, all peers must agree on it.
+func (cfg Config) pairTopicsEnabled() bool {
+	return cfg.PairTopics
+}
+
+// publishContentTopics picks the topics for a message to recipient. With a
+// pair secret the pair topic replaces the recipient-derived topics.
+func (cfg Config) publishContentTopics(recipient string, pair PairTopicSecret, now time.Time) []string {
+	if cfg.pairTopicsEnabled() && pair.Publish && len(pair.Secret) > 0 {
+		return []string{PairContentTopic(pair.Secret, pairTopicEpochIndex(now))}
+	}
+	return cfg.privateContentTopics(recipient, now)
+}
+
+// pairSubscriptionChanges works out the pair topics to listen on at now and
+// how they differ from the subscribed set: topics to add and stale ones to
+// drop once their epoch and overlap have passed.
+func pairSubscriptionChanges(subscribed map[string]struct{}, secrets map[string]PairTopicSecret, now time.Time, overlap time.Duration) (wanted map[string]struct{}, added, stale []string) {
+	wanted = map[string]struct{}{}
+	for _, pair := range secrets {
+		for _, topic := range pairTopicsAt(pair.Secret, now, overlap) {
+			wanted[topic] = struct{}{}
+		}
+	}
+	for topic := range wanted {
+		if _, ok := subscribed[topic]; !ok {
+			added = append(added, topic)
+		}
+	}
+	for topic := range subscribed {
+		if _, ok := wanted[topic]; !ok {
+			stale = append(stale, topic)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(stale)
+	return wanted, added, stale
+}
+
+// fetchContentTopics lists the topics history for selfID may sit on since
+// the given time.
+func (cfg Config) fetchContentTopics(selfID string, secrets map[string]PairTopicSecret, since, now time.Time) []string {
+	topics := cfg.privateContentTopics(selfID, now)
+	if !cfg.pairTopicsEnabled() {
+		return topics
+	}
+	for _, pair := range secrets {
+		topics = append(topics, pairTopicsBetween(pair.Secret, since, now)...)
+	}
+	return topics
+}
+
+// isPairContentTopic reports whether topic is named like a pair topic.
+func isPairContentTopic(topic string) bool {
+	return strings.HasPrefix(topic, pairContentTopicPrefix) && strings.HasSuffix(topic, "/proto")
+}
+
+func clonePairSecrets(in map[string]PairTopicSecret) map[string]PairTopicSecret {
+	out := make(map[string]PairTopicSecret, len(in))
+	for peerID, pair := range in {
+		pair.Secret = append([]byte(nil), pair.Secret...)
+		out[peerID] = pair
+	}
+	return out
+}
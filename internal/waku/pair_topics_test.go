@@ -0,0 +1,146 @@
+package waku
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPairContentTopicRotatesDaily(t *testing.T) {
+	secret := []byte("pair-secret-alice-bob")
+	day := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC)
+	today := PairContentTopic(secret, pairTopicEpochIndex(day))
+	if again := PairContentTopic(append([]byte(nil), secret...), pairTopicEpochIndex(day.Add(11*time.Hour))); again != today {
+		t.Fatalf("both peers must derive the same topic within an epoch: %s != %s", again, today)
+	}
+	if tomorrow := PairContentTopic(secret, pairTopicEpochIndex(day.Add(PairTopicEpoch))); tomorrow == today {
+		t.Fatal("topic must rotate every epoch")
+	}
+	if !isPairContentTopic(today) || isPairContentTopic(privateContentTopic) {
+		t.Fatalf("unexpected topic name: %s", today)
+	}
+}
+
+func TestPairTopicsOverlapAroundRollover(t *testing.T) {
+	secret := []byte("s")
+	midnight := time.Date(2030, 3, 5, 0, 0, 0, 0, time.UTC)
+	epoch := pairTopicEpochIndex(midnight)
+	current := PairContentTopic(secret, epoch)
+
+	if got := pairTopicsAt(secret, midnight.Add(12*time.Hour), time.Hour); !slices.Equal(got, []string{current}) {
+		t.Fatalf("mid-epoch only the current topic is used, got %v", got)
+	}
+	if got := pairTopicsAt(secret, midnight.Add(30*time.Minute), time.Hour); !slices.Contains(got, PairContentTopic(secret, epoch-1)) {
+		t.Fatalf("previous topic must stay honored after rollover, got %v", got)
+	}
+	if got := pairTopicsAt(secret, midnight.Add(-30*time.Minute), time.Hour); !slices.Contains(got, current) {
+		t.Fatalf("next topic must be honored before rollover, got %v", got)
+	}
+	if got := pairTopicsBetween(secret, midnight.Add(-30*PairTopicEpoch), midnight); len(got) != maxPairTopicFetchEpochs || got[0] != current {
+		t.Fatalf("fetch must be capped and newest first, got %v", got)
+	}
+}
+
+func TestPairTopicsReplaceRecipientTopicsOnlyWhenPublishing(t *testing.T) {
+	now := time.Now()
+	cfg := Config{TopicShards: 4, PairTopics: true, PairTopicOverlap: time.Hour}
+	pair := PairTopicSecret{Secret: []byte("s")}
+	if got := cfg.publishContentTopics("aim1bob", pair, now); !slices.Equal(got, cfg.privateContentTopics("aim1bob", now)) {
+		t.Fatalf("unconfirmed pair must keep recipient topics, got %v", got)
+	}
+	pair.Publish = true
+	if got := cfg.publishContentTopics("aim1bob", pair, now); len(got) != 1 || !isPairContentTopic(got[0]) {
+		t.Fatalf("confirmed pair must use the pair topic only, got %v", got)
+	}
+}
+
+func TestPairSubscriptionChangesFollowRollover(t *testing.T) {
+	secrets := map[string]PairTopicSecret{"aim1bob": {Secret: []byte("s")}}
+	midnight := time.Date(2030, 3, 5, 0, 0, 0, 0, time.UTC)
+	epoch := pairTopicEpochIndex(midnight)
+	previous := PairContentTopic(secrets["aim1bob"].Secret, epoch-1)
+	current := PairContentTopic(secrets["aim1bob"].Secret, epoch)
+
+	subscribed, added, stale := pairSubscriptionChanges(map[string]struct{}{}, secrets, midnight.Add(-12*time.Hour), time.Hour)
+	if !slices.Equal(added, []string{previous}) || len(stale) != 0 {
+		t.Fatalf("expected the pair topic to be added, got added=%v stale=%v", added, stale)
+	}
+	if _, added, stale = pairSubscriptionChanges(subscribed, secrets, midnight.Add(-11*time.Hour), time.Hour); len(added) != 0 || len(stale) != 0 {
+		t.Fatalf("expected no changes within an epoch, got added=%v stale=%v", added, stale)
+	}
+	subscribed, added, stale = pairSubscriptionChanges(subscribed, secrets, midnight.Add(-30*time.Minute), time.Hour)
+	if !slices.Equal(added, []string{current}) || len(stale) != 0 {
+		t.Fatalf("next topic must be added before rollover while the old one stays, got added=%v stale=%v", added, stale)
+	}
+	if _, added, stale = pairSubscriptionChanges(subscribed, secrets, midnight.Add(2*time.Hour), time.Hour); len(added) != 0 || !slices.Equal(stale, []string{previous}) {
+		t.Fatalf("previous topic must be dropped after the overlap, got added=%v stale=%v", added, stale)
+	}
+	if _, _, stale = pairSubscriptionChanges(subscribed, nil, midnight, time.Hour); len(stale) != 2 {
+		t.Fatalf("topics of removed sessions must be dropped, got %v", stale)
+	}
+}
+
+// TestPairTopicsReduceObservableMetadata measures what a relay watching topic
+// names learns about a small network in which every identity talks to every
+// other one over two days.
+func TestPairTopicsReduceObservableMetadata(t *testing.T) {
+	ids := []string{"aim1a", "aim1b", "aim1c", "aim1d", "aim1e", "aim1f", "aim1g", "aim1h"}
+	day := time.Date(2030, 3, 4, 12, 0, 0, 0, time.UTC)
+	sharded := Config{TopicShards: 4}
+	paired := Config{TopicShards: 4, PairTopics: true}
+
+	// Recipient-derived topics: every conversation into one identity uses
+	// the same topic on both days, so the topic names the recipient's bucket
+	// and links all its conversations across time.
+	shardTopics := map[string]map[string]struct{}{}
+	pairTopics := map[string]map[string]struct{}{}
+	for _, at := range []time.Time{day, day.Add(PairTopicEpoch)} {
+		for i, from := range ids {
+			for j, to := range ids {
+				if i == j {
+					continue
+				}
+				conversation := from + ">" + to
+				for _, topic := range sharded.publishContentTopics(to, PairTopicSecret{}, at) {
+					if shardTopics[topic] == nil {
+						shardTopics[topic] = map[string]struct{}{}
+					}
+					shardTopics[topic][conversation] = struct{}{}
+				}
+				a, b := min(from, to), max(from, to)
+				pair := PairTopicSecret{Secret: []byte("secret|" + a + "|" + b), Publish: true}
+				for _, topic := range paired.publishContentTopics(to, pair, at) {
+					if strings.Contains(topic, from) || strings.Contains(topic, to) {
+						t.Fatalf("topic reveals an identity: %s", topic)
+					}
+					if pairTopics[topic] == nil {
+						pairTopics[topic] = map[string]struct{}{}
+					}
+					pairTopics[topic][a+"|"+b] = struct{}{}
+				}
+			}
+		}
+	}
+
+	maxLinked := func(topics map[string]map[string]struct{}) int {
+		most := 0
+		for _, conversations := range topics {
+			most = max(most, len(conversations))
+		}
+		return most
+	}
+	// With 4 shards each topic ties together every conversation into about
+	// two recipients, on both days.
+	if linked := maxLinked(shardTopics); linked < 7 {
+		t.Fatalf("expected shard topics to link many conversations, got %d", linked)
+	}
+	// A pair topic carries exactly one pair, and a fresh one every day.
+	if linked := maxLinked(pairTopics); linked != 1 {
+		t.Fatalf("pair topic must carry a single pair, got %d", linked)
+	}
+	pairs := len(ids) * (len(ids) - 1) / 2
+	if len(pairTopics) != 2*pairs {
+		t.Fatalf("expected one topic per pair per day: %d topics for %d pairs", len(pairTopics), pairs)
+	}
+}
@@ -0,0 +1,159 @@
+package waku
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ClockConfidenceNone = "none"
+	ClockConfidenceLow  = "low"
+	ClockConfidenceHigh = "high"
+
+	// clockSampleTTL drops peers we have not heard from recently.
+	clockSampleTTL = 30 * time.Minute
+	// maxClockPeers bounds the sample table; the oldest sample is evicted.
+	maxClockPeers = 64
+	// maxClockSampleSkew ignores envelopes that were queued or replayed
+	// rather than delivered live.
+	maxClockSampleSkew = 24 * time.Hour
+	// minClockPeers is the number of peers needed for any estimate.
+	minClockPeers = 3
+	// highConfidencePeers and highConfidenceSpread gate a high confidence
+	// estimate.
+	highConfidencePeers  = 5
+	highConfidenceSpread = 5 * time.Second
+)
+
+// ClockEstimate is the median offset of peer clocks from the local clock.
+// A positive Offset means peers are ahead, i.e. the local clock is behind.
+// Samples come from unauthenticated envelope timestamps, so the estimate is
+// only used to annotate time-based decisions, never to change them.
+type ClockEstimate struct {
+	Offset time.Duration
+	// Spread is the median absolute deviation of the peer offsets.
+	Spread     time.Duration
+	Peers      int
+	Confidence string
+	UpdatedAt  time.Time
+}
+
+// ExpiryCheck compares an expiry decision made with the local clock to the
+// one the peers' clock would make.
+type ExpiryCheck struct {
+	LocalExpired bool
+	PeerExpired  bool
+	Offset       time.Duration
+	Confidence   string
+}
+
+// Disputed reports whether the peers' clock disagrees with the local verdict
+// and the estimate is trustworthy enough to mention.
+func (c ExpiryCheck) Disputed() bool {
+	return c.Confidence != ClockConfidenceNone && c.LocalExpired != c.PeerExpired
+}
+
+// CheckExpiry evaluates expiresAt against localNow and against localNow
+// shifted by the estimated offset.
+func (e ClockEstimate) CheckExpiry(expiresAt, localNow time.Time) ExpiryCheck {
+	check := ExpiryCheck{
+		LocalExpired: !expiresAt.After(localNow),
+		Offset:       e.Offset,
+		Confidence:   e.Confidence,
+	}
+	if check.Confidence == "" {
+		check.Confidence = ClockConfidenceNone
+	}
+	check.PeerExpired = check.LocalExpired
+	if check.Confidence != ClockConfidenceNone {
+		check.PeerExpired = !expiresAt.After(localNow.Add(e.Offset))
+	}
+	return check
+}
+
+type peerClock struct {
+	mu      sync.Mutex
+	samples map[string]clockSample
+}
+
+type clockSample struct {
+	offset time.Duration
+	at     time.Time
+}
+
+func newPeerClock() *peerClock {
+	return &peerClock{samples: map[string]clockSample{}}
+}
+
+// observe records the offset between a peer's send time and our receive
+// time. Only the latest sample per peer counts, so one chatty peer cannot
+// outvote the others.
+func (c *peerClock) observe(peerID string, sentAt, receivedAt time.Time) {
+	if peerID == "" || sentAt.IsZero() {
+		return
+	}
+	offset := sentAt.Sub(receivedAt)
+	if offset > maxClockSampleSkew || offset < -maxClockSampleSkew {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, known := c.samples[peerID]; !known && len(c.samples) >= maxClockPeers {
+		oldestID := ""
+		var oldest time.Time
+		for id, sample := range c.samples {
+			if oldestID == "" || sample.at.Before(oldest) {
+				oldestID, oldest = id, sample.at
+			}
+		}
+		delete(c.samples, oldestID)
+	}
+	c.samples[peerID] = clockSample{offset: offset, at: receivedAt}
+}
+
+func (c *peerClock) estimate(now time.Time) ClockEstimate {
+	c.mu.Lock()
+	offsets := make([]time.Duration, 0, len(c.samples))
+	var updated time.Time
+	for id, sample := range c.samples {
+		if now.Sub(sample.at) > clockSampleTTL {
+			delete(c.samples, id)
+			continue
+		}
+		offsets = append(offsets, sample.offset)
+		if sample.at.After(updated) {
+			updated = sample.at
+		}
+	}
+	c.mu.Unlock()
+
+	est := ClockEstimate{Peers: len(offsets), Confidence: ClockConfidenceNone, UpdatedAt: updated}
+	if len(offsets) < minClockPeers {
+		return est
+	}
+	est.Offset = medianDuration(offsets)
+	deviations := make([]time.Duration, len(offsets))
+	for i, offset := range offsets {
+		deviation := offset - est.Offset
+		if deviation < 0 {
+			deviation = -deviation
+		}
+		deviations[i] = deviation
+	}
+	est.Spread = medianDuration(deviations)
+	est.Confidence = ClockConfidenceLow
+	if len(offsets) >= highConfidencePeers && est.Spread <= highConfidenceSpread {
+		est.Confidence = ClockConfidenceHigh
+	}
+	return est
+}
+
+func medianDuration(values []time.Duration) time.Duration {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
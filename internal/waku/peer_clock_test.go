@@ -0,0 +1,112 @@
+package waku
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPeerClockMedianResistsOutliers(t *testing.T) {
+	clock := newPeerClock()
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{3 * time.Second, 4 * time.Second, 5 * time.Second, 4 * time.Second, 6 * time.Hour}
+	for i, offset := range offsets {
+		clock.observe(fmt.Sprintf("peer-%d", i), now.Add(offset), now)
+	}
+	est := clock.estimate(now)
+	if est.Peers != 5 || est.Offset != 4*time.Second {
+		t.Fatalf("unexpected estimate: %+v", est)
+	}
+	if est.Confidence != ClockConfidenceHigh {
+		t.Fatalf("expected high confidence, got %+v", est)
+	}
+}
+
+func TestPeerClockNeedsEnoughFreshPeers(t *testing.T) {
+	clock := newPeerClock()
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		// The same peer talking repeatedly counts once.
+		clock.observe("chatty", now.Add(time.Minute), now)
+	}
+	clock.observe("other", now.Add(time.Minute), now)
+	if est := clock.estimate(now); est.Peers != 2 || est.Confidence != ClockConfidenceNone || est.Offset != 0 {
+		t.Fatalf("expected no estimate from two peers, got %+v", est)
+	}
+
+	clock.observe("third", now.Add(2*time.Minute), now)
+	if est := clock.estimate(now); est.Confidence != ClockConfidenceLow || est.Offset != time.Minute {
+		t.Fatalf("expected low confidence estimate, got %+v", est)
+	}
+	if est := clock.estimate(now.Add(clockSampleTTL + time.Second)); est.Peers != 0 {
+		t.Fatalf("expected stale samples to expire, got %+v", est)
+	}
+
+	clock.observe("replayed", now.Add(-48*time.Hour), now)
+	if est := clock.estimate(now); est.Peers != 0 {
+		t.Fatalf("expected replayed envelope to be ignored, got %+v", est)
+	}
+}
+
+func TestPeerClockEvictsOldestPeer(t *testing.T) {
+	clock := newPeerClock()
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < maxClockPeers+1; i++ {
+		clock.observe(fmt.Sprintf("peer-%d", i), now, now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if _, ok := clock.samples["peer-0"]; ok || len(clock.samples) != maxClockPeers {
+		t.Fatalf("expected the oldest peer to be evicted, have %d samples", len(clock.samples))
+	}
+}
+
+func TestClockEstimateCheckExpiry(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Minute)
+
+	behind := ClockEstimate{Offset: 5 * time.Minute, Confidence: ClockConfidenceHigh}
+	check := behind.CheckExpiry(expiresAt, now)
+	if check.LocalExpired || !check.PeerExpired || !check.Disputed() {
+		t.Fatalf("expected peers to dispute the local verdict: %+v", check)
+	}
+
+	unknown := ClockEstimate{Offset: 5 * time.Minute}
+	check = unknown.CheckExpiry(expiresAt, now)
+	if check.PeerExpired != check.LocalExpired || check.Disputed() || check.Confidence != ClockConfidenceNone {
+		t.Fatalf("expected no dispute without confidence: %+v", check)
+	}
+}
+
+func TestNodeSamplesPeerClockFromLiveDeliveries(t *testing.T) {
+	receiver := NewNode(DefaultConfig())
+	receiver.SetIdentity("aim1clock-receiver")
+	if err := receiver.Start(context.Background()); err != nil {
+		t.Fatalf("start receiver: %v", err)
+	}
+	defer func() { _ = receiver.Stop(context.Background()) }()
+	got := make(chan PrivateMessage, 1)
+	if err := receiver.SubscribePrivate(func(msg PrivateMessage) { got <- msg }); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	sender := NewNode(DefaultConfig())
+	sender.SetIdentity("aim1clock-sender")
+	if err := sender.Start(context.Background()); err != nil {
+		t.Fatalf("start sender: %v", err)
+	}
+	defer func() { _ = sender.Stop(context.Background()) }()
+	if err := sender.PublishPrivate(context.Background(), PrivateMessage{ID: "clock-1", SenderID: "aim1clock-sender", Recipient: "aim1clock-receiver"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	select {
+	case msg := <-got:
+		if msg.SentAt.IsZero() {
+			t.Fatal("expected live delivery to carry the send time")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message was not delivered")
+	}
+	if est := receiver.ClockEstimate(); est.Peers != 1 || est.Confidence != ClockConfidenceNone {
+		t.Fatalf("expected one clock sample, got %+v", est)
+	}
+}
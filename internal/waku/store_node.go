@@ -0,0 +1,180 @@
+package waku
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultStoreNodeQuotaBytes       = 1 << 30
+	DefaultStoreNodeRetention        = 30 * 24 * time.Hour
+	DefaultStoreNodeQueriesPerMinute = 120
+)
+
+var (
+	ErrStoreTopicNotServed = errors.New("content topic is not served by this store node")
+	ErrStoreQuotaExceeded  = errors.New("store node quota exceeded")
+	ErrStoreRateLimited    = errors.New("store node query rate limit exceeded")
+)
+
+// StoreNodeStatus reports the community store mode. The store only ever holds
+// the encrypted envelopes it relays; it has no access to message plaintext.
+type StoreNodeStatus struct {
+	Enabled          bool
+	Transport        string
+	Topics           []string
+	StoredMessages   int
+	StoredBytes      int64
+	QuotaBytes       int64
+	Retention        time.Duration
+	QueriesPerMinute int
+	AcceptedTotal    int64
+	RejectedTopic    int64
+	RejectedQuota    int64
+	// RejectedSenderQuota counts envelopes refused because their sender
+	// was over the per-sender mailbox quota.
+	RejectedSenderQuota int64
+	QueriesServed       int64
+	QueriesRateLimited  int64
+}
+
+// storeNodeTopics lists the content topics persisted in store mode. Without
+// an explicit list every private topic of this network is served.
+func (cfg Config) storeNodeTopics() []string {
+	if len(cfg.StoreNodeTopics) > 0 {
+		return slices.Clone(cfg.StoreNodeTopics)
+	}
+	topics := []string{privateContentTopic}
+	for shard := 0; shard < cfg.TopicShards; shard++ {
+		topics = append(topics, shardedPrivateContentTopic(shard))
+	}
+	return topics
+}
+
+// storeNodeGuard enforces the store mode policy: which topics are kept, how
+// much disk they may use and how often history can be queried. The backend
+// that actually persists envelopes consults it on every write and query.
+type storeNodeGuard struct {
+	mu        sync.Mutex
+	enabled   bool
+	transport string
+	topics    map[string]struct{}
+	pairs     bool
+	quota     int64
+	retention time.Duration
+	perMinute int
+	usedBytes func() int64
+	count     func() int
+
+	windowStart time.Time
+	windowCount int
+	status      StoreNodeStatus
+
+	mailbox      mailboxIndex
+	deleteStored func(keys []string) error
+}
+
+func newStoreNodeGuard(cfg Config) *storeNodeGuard {
+	g := &storeNodeGuard{
+		enabled:   cfg.StoreNodeEnabled,
+		transport: cfg.Transport,
+		topics:    map[string]struct{}{},
+		pairs:     cfg.pairTopicsEnabled() && len(cfg.StoreNodeTopics) == 0,
+		quota:     cfg.StoreNodeQuotaBytes,
+		retention: cfg.StoreNodeRetention,
+		perMinute: cfg.StoreNodeQueriesPerMinute,
+		mailbox:   newMailboxIndex(),
+	}
+	for _, topic := range cfg.storeNodeTopics() {
+		g.topics[topic] = struct{}{}
+	}
+	return g
+}
+
+// bindUsage connects the guard to the backend's size and count reporting.
+func (g *storeNodeGuard) bindUsage(usedBytes func() int64, count func() int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.usedBytes = usedBytes
+	g.count = count
+}
+
+// admit decides whether an envelope of size bytes on contentTopic, sent by
+// sender, is stored. Without an eviction policy a full store refuses new
+// mail; with one, room is made when the envelope is recorded.
+func (g *storeNodeGuard) admit(contentTopic, sender string, size int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// Pair topics cannot be listed up front, so they are admitted by name.
+	if _, ok := g.topics[contentTopic]; !ok && !(g.pairs && isPairContentTopic(contentTopic)) {
+		g.status.RejectedTopic++
+		return ErrStoreTopicNotServed
+	}
+	if err := g.mailbox.admit(sender, int64(size)); err != nil {
+		g.status.RejectedSenderQuota++
+		return err
+	}
+	evicting := g.mailbox.eviction != MailboxEvictionNone
+	if g.quota > 0 && (int64(size) > g.quota ||
+		!evicting && g.usedBytes != nil && g.usedBytes()+int64(size) > g.quota) {
+		g.status.RejectedQuota++
+		return ErrStoreQuotaExceeded
+	}
+	g.status.AcceptedTotal++
+	return nil
+}
+
+// allowQuery applies the per-minute query budget shared by all requesters.
+func (g *storeNodeGuard) allowQuery(now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.windowStart) >= time.Minute {
+		g.windowStart = now
+		g.windowCount = 0
+	}
+	if g.perMinute > 0 && g.windowCount >= g.perMinute {
+		g.status.QueriesRateLimited++
+		return ErrStoreRateLimited
+	}
+	g.windowCount++
+	g.status.QueriesServed++
+	return nil
+}
+
+func (g *storeNodeGuard) snapshot() StoreNodeStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := g.status
+	out.Enabled = g.enabled
+	out.Transport = g.transport
+	out.QuotaBytes = g.quota
+	out.Retention = g.retention
+	out.QueriesPerMinute = g.perMinute
+	out.Topics = make([]string, 0, len(g.topics))
+	for topic := range g.topics {
+		out.Topics = append(out.Topics, topic)
+	}
+	slices.Sort(out.Topics)
+	if g.usedBytes != nil {
+		out.StoredBytes = g.usedBytes()
+	}
+	if g.count != nil {
+		out.StoredMessages = g.count()
+	}
+	return out
+}
+
+// fileSize reports the on-disk size of the store database, including the
+// SQLite write-ahead log, so the quota tracks real disk usage.
+func fileSize(path string) int64 {
+	var total int64
+	for _, candidate := range []string{path, path + "-wal"} {
+		if info, err := os.Stat(candidate); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
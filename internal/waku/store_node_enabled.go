@@ -0,0 +1,124 @@
+//go:build real_waku
+
+package waku
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/waku-org/go-waku/waku/persistence"
+	"github.com/waku-org/go-waku/waku/persistence/sqlite"
+	"github.com/waku-org/go-waku/waku/v2/protocol"
+	legacyStore "github.com/waku-org/go-waku/waku/v2/protocol/legacy_store"
+	storepb "github.com/waku-org/go-waku/waku/v2/protocol/legacy_store/pb"
+	"github.com/waku-org/go-waku/waku/v2/protocol/relay"
+	"github.com/waku-org/go-waku/waku/v2/utils"
+)
+
+// guardedMessageProvider persists relayed envelopes on disk for the community
+// store mode. Envelopes are stored exactly as relayed, so the store never sees
+// plaintext; the guard limits topics, disk usage and query rate, and keeps
+// the per-sender mailbox index that decides what is evicted.
+type guardedMessageProvider struct {
+	legacyStore.MessageProvider
+	guard *storeNodeGuard
+	db    *sql.DB
+}
+
+func newPersistentMessageProvider(cfg Config, guard *storeNodeGuard) (*guardedMessageProvider, error) {
+	if err := os.MkdirAll(cfg.StoreNodePath, 0o700); err != nil {
+		return nil, err
+	}
+	dbPath := filepath.Join(cfg.StoreNodePath, "store.db")
+	db, err := sqlite.NewDB(dbPath, utils.Logger())
+	if err != nil {
+		return nil, err
+	}
+	store, err := persistence.NewDBStore(
+		prometheus.DefaultRegisterer,
+		utils.Logger(),
+		persistence.WithDB(db),
+		persistence.WithMigrations(sqlite.Migrations),
+		persistence.WithRetentionPolicy(0, cfg.StoreNodeRetention),
+	)
+	if err != nil {
+		return nil, err
+	}
+	guard.bindUsage(
+		func() int64 { return fileSize(dbPath) },
+		func() int {
+			count, err := store.Count()
+			if err != nil {
+				return 0
+			}
+			return count
+		},
+	)
+	stored, err := store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range stored {
+		guard.indexStored(string(msg.ID), envelopeSender(msg.Message.Payload), len(msg.Message.Payload), time.Unix(0, msg.ReceiverTime))
+	}
+	provider := &guardedMessageProvider{MessageProvider: store, guard: guard, db: db}
+	guard.bindDelete(provider.deleteStored)
+	return provider, nil
+}
+
+func (p *guardedMessageProvider) Validate(env *protocol.Envelope) error {
+	msg := env.Message()
+	if err := p.guard.admit(msg.ContentTopic, envelopeSender(msg.Payload), len(msg.Payload)); err != nil {
+		return err
+	}
+	return p.MessageProvider.Validate(env)
+}
+
+func (p *guardedMessageProvider) Put(env *protocol.Envelope) error {
+	if err := p.MessageProvider.Put(env); err != nil {
+		return err
+	}
+	msg := env.Message()
+	return p.guard.recordStored(string(env.Index().Digest), envelopeSender(msg.Payload), len(msg.Payload), time.Now())
+}
+
+// deleteStored removes evicted or purged envelopes by their store digest.
+func (p *guardedMessageProvider) deleteStored(keys []string) error {
+	for _, key := range keys {
+		if _, err := p.db.Exec("DELETE FROM message WHERE id = $1", []byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *guardedMessageProvider) Query(query *storepb.HistoryQuery) (*storepb.Index, []persistence.StoredMessage, error) {
+	if err := p.guard.allowQuery(time.Now()); err != nil {
+		return nil, nil, err
+	}
+	return p.MessageProvider.Query(query)
+}
+
+// subscribeStoreTopics keeps the relay subscribed to the served topics so
+// their traffic reaches the store even when no local identity listens there.
+func (g *goWakuNode) subscribeStoreTopics(topics []string) error {
+	g.mu.RLock()
+	node := g.node
+	g.mu.RUnlock()
+	filter := protocol.NewContentFilter(privatePubsubTopic, topics...)
+	subs, err := node.Relay().Subscribe(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		go func(subscription *relay.Subscription) {
+			for range subscription.Ch {
+			}
+		}(sub)
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package waku
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Mailbox eviction policies. With none a full mailbox refuses new mail, which
+// is how the store behaved before policies existed.
+const (
+	MailboxEvictionNone           = "none"
+	MailboxEvictionOldestFirst    = "oldest_first"
+	MailboxEvictionSenderFairness = "sender_fairness"
+)
+
+var (
+	ErrMailboxSenderQuotaExceeded = errors.New("mailbox sender quota exceeded")
+	ErrInvalidMailboxPolicy       = errors.New("invalid mailbox policy")
+)
+
+// MailboxSenderUsage is what one sender holds in the mailbox.
+type MailboxSenderUsage struct {
+	SenderID string
+	Messages int
+	Bytes    int64
+	Oldest   time.Time
+}
+
+// MailboxStatus describes the store-and-forward mailbox kept by the store
+// node. Sizes count envelope payloads, not database overhead. Senders are
+// ordered by the bytes they hold, largest first.
+type MailboxStatus struct {
+	Eviction            string
+	SenderQuotaBytes    int64
+	QuotaBytes          int64
+	Retention           time.Duration
+	StoredMessages      int
+	StoredBytes         int64
+	Senders             []MailboxSenderUsage
+	EvictedTotal        int64
+	PurgedTotal         int64
+	RejectedSenderQuota int64
+}
+
+type mailboxEntry struct {
+	key      string
+	sender   string
+	size     int64
+	storedAt time.Time
+}
+
+// mailboxIndex tracks the envelopes held by the store per sender, so quotas
+// and eviction can be applied without querying the backend.
+type mailboxIndex struct {
+	eviction    string
+	senderQuota int64
+	entries     map[string]mailboxEntry
+	senderBytes map[string]int64
+	bytes       int64
+	evicted     int64
+	purged      int64
+}
+
+func newMailboxIndex() mailboxIndex {
+	return mailboxIndex{
+		eviction:    MailboxEvictionNone,
+		entries:     map[string]mailboxEntry{},
+		senderBytes: map[string]int64{},
+	}
+}
+
+// NormalizeMailboxEviction maps an empty policy to none and rejects unknown
+// ones.
+func NormalizeMailboxEviction(eviction string) (string, error) {
+	switch eviction {
+	case "", MailboxEvictionNone:
+		return MailboxEvictionNone, nil
+	case MailboxEvictionOldestFirst, MailboxEvictionSenderFairness:
+		return eviction, nil
+	default:
+		return "", ErrInvalidMailboxPolicy
+	}
+}
+
+// envelopeSender reads the sender from a relayed envelope payload. The
+// payload is the transport wrapper whose body stays encrypted.
+func envelopeSender(payload []byte) string {
+	var wire struct {
+		SenderID string
+	}
+	if err := json.Unmarshal(payload, &wire); err != nil {
+		return ""
+	}
+	return wire.SenderID
+}
+
+func (m *mailboxIndex) admit(sender string, size int64) error {
+	if m.senderQuota <= 0 {
+		return nil
+	}
+	if size > m.senderQuota {
+		return ErrMailboxSenderQuotaExceeded
+	}
+	if m.eviction == MailboxEvictionNone && m.senderBytes[sender]+size > m.senderQuota {
+		return ErrMailboxSenderQuotaExceeded
+	}
+	return nil
+}
+
+func (m *mailboxIndex) add(entry mailboxEntry) {
+	if _, ok := m.entries[entry.key]; ok {
+		return
+	}
+	m.entries[entry.key] = entry
+	m.senderBytes[entry.sender] += entry.size
+	m.bytes += entry.size
+}
+
+func (m *mailboxIndex) remove(key string) {
+	entry, ok := m.entries[key]
+	if !ok {
+		return
+	}
+	delete(m.entries, key)
+	m.bytes -= entry.size
+	if m.senderBytes[entry.sender] -= entry.size; m.senderBytes[entry.sender] <= 0 {
+		delete(m.senderBytes, entry.sender)
+	}
+}
+
+func (m *mailboxIndex) oldest(sender string, anySender bool) (mailboxEntry, bool) {
+	var found mailboxEntry
+	ok := false
+	for _, entry := range m.entries {
+		if !anySender && entry.sender != sender {
+			continue
+		}
+		if !ok || entry.storedAt.Before(found.storedAt) || (entry.storedAt.Equal(found.storedAt) && entry.key < found.key) {
+			found, ok = entry, true
+		}
+	}
+	return found, ok
+}
+
+func (m *mailboxIndex) largestSender() string {
+	largest := ""
+	for sender, used := range m.senderBytes {
+		if largest == "" || used > m.senderBytes[largest] || (used == m.senderBytes[largest] && sender < largest) {
+			largest = sender
+		}
+	}
+	return largest
+}
+
+// makeRoom drops expired mail and, under an eviction policy, the mail that
+// keeps senders or the whole mailbox over quota. It returns the keys to
+// delete from the backend.
+func (m *mailboxIndex) makeRoom(quota int64, retention time.Duration, now time.Time) []string {
+	var victims []string
+	drop := func(entry mailboxEntry) {
+		m.remove(entry.key)
+		victims = append(victims, entry.key)
+	}
+	if retention > 0 {
+		cutoff := now.Add(-retention)
+		for _, entry := range m.entries {
+			if entry.storedAt.Before(cutoff) {
+				drop(entry)
+			}
+		}
+	}
+	if m.eviction == MailboxEvictionNone {
+		return victims
+	}
+	expired := len(victims)
+	if m.senderQuota > 0 {
+		for sender, used := range m.senderBytes {
+			for used > m.senderQuota {
+				entry, ok := m.oldest(sender, false)
+				if !ok {
+					break
+				}
+				drop(entry)
+				used = m.senderBytes[sender]
+			}
+		}
+	}
+	for quota > 0 && m.bytes > quota {
+		var (
+			entry mailboxEntry
+			ok    bool
+		)
+		if m.eviction == MailboxEvictionSenderFairness {
+			entry, ok = m.oldest(m.largestSender(), false)
+		} else {
+			entry, ok = m.oldest("", true)
+		}
+		if !ok {
+			break
+		}
+		drop(entry)
+	}
+	m.evicted += int64(len(victims) - expired)
+	return victims
+}
+
+// bindDelete connects the guard to the backend so evicted and purged mail is
+// removed from disk.
+func (g *storeNodeGuard) bindDelete(deleteStored func(keys []string) error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.deleteStored = deleteStored
+}
+
+// indexStored records mail already on disk, such as after a restart.
+func (g *storeNodeGuard) indexStored(key, sender string, size int, storedAt time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mailbox.add(mailboxEntry{key: key, sender: sender, size: int64(size), storedAt: storedAt})
+}
+
+// recordStored records newly stored mail and evicts whatever the policy says
+// must go to make room for it.
+func (g *storeNodeGuard) recordStored(key, sender string, size int, now time.Time) error {
+	g.mu.Lock()
+	g.mailbox.add(mailboxEntry{key: key, sender: sender, size: int64(size), storedAt: now})
+	victims := g.mailbox.makeRoom(g.quota, g.retention, now)
+	deleteStored := g.deleteStored
+	g.mu.Unlock()
+	return deleteMailboxKeys(deleteStored, victims)
+}
+
+// configureMailbox sets the per-sender quota and eviction policy. A zero
+// quota lifts the per-sender limit.
+func (g *storeNodeGuard) configureMailbox(senderQuotaBytes int64, eviction string) error {
+	eviction, err := NormalizeMailboxEviction(eviction)
+	if err != nil || senderQuotaBytes < 0 {
+		return ErrInvalidMailboxPolicy
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mailbox.eviction = eviction
+	g.mailbox.senderQuota = senderQuotaBytes
+	return nil
+}
+
+// flushMailbox applies retention, quotas and the eviction policy right away,
+// for instance after a quota was lowered, and returns how much mail went.
+func (g *storeNodeGuard) flushMailbox(now time.Time) (int, error) {
+	g.mu.Lock()
+	victims := g.mailbox.makeRoom(g.quota, g.retention, now)
+	deleteStored := g.deleteStored
+	g.mu.Unlock()
+	return len(victims), deleteMailboxKeys(deleteStored, victims)
+}
+
+// purgeMailbox deletes the mail of one sender, or all mail when sender is
+// empty, and returns how much mail went.
+func (g *storeNodeGuard) purgeMailbox(sender string) (int, error) {
+	g.mu.Lock()
+	var victims []string
+	for key, entry := range g.mailbox.entries {
+		if sender == "" || entry.sender == sender {
+			victims = append(victims, key)
+		}
+	}
+	for _, key := range victims {
+		g.mailbox.remove(key)
+	}
+	g.mailbox.purged += int64(len(victims))
+	deleteStored := g.deleteStored
+	g.mu.Unlock()
+	return len(victims), deleteMailboxKeys(deleteStored, victims)
+}
+
+func (g *storeNodeGuard) mailboxSnapshot() MailboxStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m := &g.mailbox
+	out := MailboxStatus{
+		Eviction:            m.eviction,
+		SenderQuotaBytes:    m.senderQuota,
+		QuotaBytes:          g.quota,
+		Retention:           g.retention,
+		StoredMessages:      len(m.entries),
+		StoredBytes:         m.bytes,
+		EvictedTotal:        m.evicted,
+		PurgedTotal:         m.purged,
+		RejectedSenderQuota: g.status.RejectedSenderQuota,
+	}
+	senders := map[string]*MailboxSenderUsage{}
+	for _, entry := range m.entries {
+		usage, ok := senders[entry.sender]
+		if !ok {
+			usage = &MailboxSenderUsage{SenderID: entry.sender, Oldest: entry.storedAt}
+			senders[entry.sender] = usage
+		}
+		usage.Messages++
+		usage.Bytes += entry.size
+		if entry.storedAt.Before(usage.Oldest) {
+			usage.Oldest = entry.storedAt
+		}
+	}
+	out.Senders = make([]MailboxSenderUsage, 0, len(senders))
+	for _, usage := range senders {
+		out.Senders = append(out.Senders, *usage)
+	}
+	sort.Slice(out.Senders, func(i, j int) bool {
+		if out.Senders[i].Bytes != out.Senders[j].Bytes {
+			return out.Senders[i].Bytes > out.Senders[j].Bytes
+		}
+		return out.Senders[i].SenderID < out.Senders[j].SenderID
+	})
+	return out
+}
+
+func deleteMailboxKeys(deleteStored func(keys []string) error, keys []string) error {
+	if len(keys) == 0 || deleteStored == nil {
+		return nil
+	}
+	return deleteStored(keys)
+}
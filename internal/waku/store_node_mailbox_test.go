@@ -0,0 +1,125 @@
+package waku
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newMailboxTestGuard(t *testing.T, quota int64) (*storeNodeGuard, *[]string) {
+	t.Helper()
+	guard := newStoreNodeGuard(normalizeConfig(Config{
+		StoreNodeEnabled:    true,
+		StoreNodeTopics:     []string{"/community/1/chat/proto"},
+		StoreNodeQuotaBytes: quota,
+		StoreNodeRetention:  time.Hour,
+	}))
+	var deleted []string
+	guard.bindDelete(func(keys []string) error {
+		deleted = append(deleted, keys...)
+		return nil
+	})
+	return guard, &deleted
+}
+
+func TestMailboxSenderQuotaRejectsWithoutEviction(t *testing.T) {
+	guard, _ := newMailboxTestGuard(t, 1000)
+	if err := guard.configureMailbox(100, ""); err != nil {
+		t.Fatalf("configure mailbox: %v", err)
+	}
+	now := time.Now()
+	if err := guard.admit("/community/1/chat/proto", "alice", 80); err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	if err := guard.recordStored("a1", "alice", 80, now); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := guard.admit("/community/1/chat/proto", "alice", 30); !errors.Is(err, ErrMailboxSenderQuotaExceeded) {
+		t.Fatalf("expected sender quota rejection, got %v", err)
+	}
+	if err := guard.admit("/community/1/chat/proto", "bob", 30); err != nil {
+		t.Fatalf("other senders keep their own quota: %v", err)
+	}
+	if guard.snapshot().RejectedSenderQuota != 1 {
+		t.Fatalf("unexpected counters: %+v", guard.snapshot())
+	}
+	if err := guard.configureMailbox(-1, ""); !errors.Is(err, ErrInvalidMailboxPolicy) {
+		t.Fatalf("expected negative quota rejection, got %v", err)
+	}
+	if err := guard.configureMailbox(0, "newest_first"); !errors.Is(err, ErrInvalidMailboxPolicy) {
+		t.Fatalf("expected unknown policy rejection, got %v", err)
+	}
+}
+
+func TestMailboxEvictionPolicies(t *testing.T) {
+	base := time.Now()
+	fill := func(t *testing.T, eviction string) (*storeNodeGuard, *[]string) {
+		guard, deleted := newMailboxTestGuard(t, 100)
+		if err := guard.configureMailbox(0, eviction); err != nil {
+			t.Fatalf("configure mailbox: %v", err)
+		}
+		for i, entry := range []struct{ key, sender string }{
+			{"b1", "bob"}, {"a1", "alice"}, {"a2", "alice"}, {"a3", "alice"},
+		} {
+			if err := guard.recordStored(entry.key, entry.sender, 25, base.Add(time.Duration(i)*time.Second)); err != nil {
+				t.Fatalf("record: %v", err)
+			}
+		}
+		if err := guard.admit("/community/1/chat/proto", "carol", 25); err != nil {
+			t.Fatalf("eviction policies admit into a full mailbox: %v", err)
+		}
+		if err := guard.recordStored("c1", "carol", 25, base.Add(10*time.Second)); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		return guard, deleted
+	}
+
+	_, deleted := fill(t, MailboxEvictionOldestFirst)
+	if !slices.Equal(*deleted, []string{"b1"}) {
+		t.Fatalf("oldest first must evict the oldest envelope, got %v", *deleted)
+	}
+	guard, deleted := fill(t, MailboxEvictionSenderFairness)
+	if !slices.Equal(*deleted, []string{"a1"}) {
+		t.Fatalf("sender fairness must evict from the largest sender, got %v", *deleted)
+	}
+	status := guard.mailboxSnapshot()
+	if status.StoredBytes != 100 || status.StoredMessages != 4 || status.EvictedTotal != 1 {
+		t.Fatalf("unexpected mailbox status: %+v", status)
+	}
+	if len(status.Senders) != 3 || status.Senders[0].SenderID != "alice" || status.Senders[0].Messages != 2 || status.Senders[0].Bytes != 50 {
+		t.Fatalf("unexpected sender usage: %+v", status.Senders)
+	}
+}
+
+func TestMailboxFlushAndPurge(t *testing.T) {
+	guard, deleted := newMailboxTestGuard(t, 0)
+	now := time.Now()
+	guard.indexStored("old", "alice", 10, now.Add(-2*time.Hour))
+	guard.indexStored("a1", "alice", 10, now)
+	guard.indexStored("a2", "alice", 10, now)
+	guard.indexStored("b1", "bob", 10, now)
+
+	if err := guard.configureMailbox(10, MailboxEvictionOldestFirst); err != nil {
+		t.Fatalf("configure mailbox: %v", err)
+	}
+	dropped, err := guard.flushMailbox(now)
+	if err != nil || dropped != 2 {
+		t.Fatalf("flush must drop the expired and the over-quota envelope: dropped=%d err=%v", dropped, err)
+	}
+	dropped, err = guard.purgeMailbox("bob")
+	if err != nil || dropped != 1 {
+		t.Fatalf("purge sender: dropped=%d err=%v", dropped, err)
+	}
+	dropped, err = guard.purgeMailbox("")
+	if err != nil || dropped != 1 {
+		t.Fatalf("purge all: dropped=%d err=%v", dropped, err)
+	}
+	if len(*deleted) != 4 {
+		t.Fatalf("every dropped envelope must be deleted from the backend, got %v", *deleted)
+	}
+	status := guard.mailboxSnapshot()
+	if status.StoredMessages != 0 || status.StoredBytes != 0 || status.PurgedTotal != 2 || status.EvictedTotal != 1 {
+		t.Fatalf("unexpected mailbox status: %+v", status)
+	}
+}
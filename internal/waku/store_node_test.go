@@ -0,0 +1,77 @@
+package waku
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStoreNodeGuardFiltersTopicsAndEnforcesQuota(t *testing.T) {
+	cfg := normalizeConfig(Config{
+		StoreNodeEnabled:    true,
+		StoreNodeTopics:     []string{"/community/1/chat/proto"},
+		StoreNodeQuotaBytes: 100,
+	})
+	guard := newStoreNodeGuard(cfg)
+	used := int64(0)
+	guard.bindUsage(func() int64 { return used }, func() int { return 1 })
+
+	if err := guard.admit("/other/1/chat/proto", "", 10); !errors.Is(err, ErrStoreTopicNotServed) {
+		t.Fatalf("expected unserved topic rejection, got %v", err)
+	}
+	if err := guard.admit("/community/1/chat/proto", "", 60); err != nil {
+		t.Fatalf("expected envelope to be admitted: %v", err)
+	}
+	used = 60
+	if err := guard.admit("/community/1/chat/proto", "", 60); !errors.Is(err, ErrStoreQuotaExceeded) {
+		t.Fatalf("expected quota rejection, got %v", err)
+	}
+
+	status := guard.snapshot()
+	if !status.Enabled || status.AcceptedTotal != 1 || status.RejectedTopic != 1 || status.RejectedQuota != 1 {
+		t.Fatalf("unexpected counters: %+v", status)
+	}
+	if status.StoredBytes != 60 || status.StoredMessages != 1 || status.QuotaBytes != 100 {
+		t.Fatalf("unexpected usage: %+v", status)
+	}
+}
+
+func TestStoreNodeGuardRateLimitsQueries(t *testing.T) {
+	guard := newStoreNodeGuard(Config{StoreNodeEnabled: true, StoreNodeQueriesPerMinute: 2})
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := guard.allowQuery(now); err != nil {
+			t.Fatalf("query %d must be allowed: %v", i, err)
+		}
+	}
+	if err := guard.allowQuery(now.Add(time.Second)); !errors.Is(err, ErrStoreRateLimited) {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+	if err := guard.allowQuery(now.Add(time.Minute)); err != nil {
+		t.Fatalf("budget must reset after a minute: %v", err)
+	}
+	if status := guard.snapshot(); status.QueriesServed != 3 || status.QueriesRateLimited != 1 {
+		t.Fatalf("unexpected query counters: %+v", status)
+	}
+}
+
+func TestStoreNodeTopicsDefaultToPrivateTopics(t *testing.T) {
+	got := Config{TopicShards: 2}.storeNodeTopics()
+	want := []string{privateContentTopic, shardedPrivateContentTopic(0), shardedPrivateContentTopic(1)}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected default topics: %v", got)
+	}
+}
+
+func TestStoreNodeGuardAdmitsPairTopicsByName(t *testing.T) {
+	pair := PairContentTopic([]byte("s"), 1)
+	guard := newStoreNodeGuard(normalizeConfig(Config{StoreNodeEnabled: true, PairTopics: true}))
+	if err := guard.admit(pair, "", 10); err != nil {
+		t.Fatalf("pair topic must be stored when pair topics are on: %v", err)
+	}
+	explicit := newStoreNodeGuard(normalizeConfig(Config{StoreNodeEnabled: true, PairTopics: true, StoreNodeTopics: []string{privateContentTopic}}))
+	if err := explicit.admit(pair, "", 10); !errors.Is(err, ErrStoreTopicNotServed) {
+		t.Fatalf("explicit topic list must win, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package waku
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	privatePubsubTopic = "/waku/2/default-waku/proto"
+	// privateContentTopic is the legacy topic shared by all private traffic.
+	privateContentTopic = "/aim-chat/1/private-message/proto"
+	// MaxTopicShards bounds the shard count so topic names stay predictable.
+	MaxTopicShards = 1024
+)
+
+// receiptContentTopic derives the per-identity receipts topic. Hashing keeps
+// the identity itself out of topic names visible to relays.
+func receiptContentTopic(identityID string) string {
+	sum := sha256.Sum256([]byte(identityID))
+	return "/aim-chat/1/receipts-" + hex.EncodeToString(sum[:8]) + "/proto"
+}
+
+// TopicShard maps an identity onto one of shards buckets. All peers must
+// agree on the shard count for messages to meet.
+func TopicShard(identityID string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(identityID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(shards))
+}
+
+// shardedPrivateContentTopic names the private topic for one shard.
+func shardedPrivateContentTopic(shard int) string {
+	return "/aim-chat/1/private-shard-" + strconv.Itoa(shard) + "/proto"
+}
+
+// topicShardingEnabled reports whether private traffic is split into shards.
+func (cfg Config) topicShardingEnabled() bool {
+	return cfg.TopicShards > 1
+}
+
+// legacyTopicHonored reports whether the shared legacy topic is still used at
+// now. Without sharding it is the only topic; with sharding it stays in use
+// until the migration window closes.
+func (cfg Config) legacyTopicHonored(now time.Time) bool {
+	if !cfg.topicShardingEnabled() {
+		return true
+	}
+	return now.Before(cfg.TopicMigrationUntil)
+}
+
+// privateContentTopics lists the topics that carry private traffic addressed
+// to identityID. Publish, subscribe and fetch all use the same list, so both
+// old and new topics are honored during the migration window.
+func (cfg Config) privateContentTopics(identityID string, now time.Time) []string {
+	topics := make([]string, 0, 2)
+	if cfg.topicShardingEnabled() {
+		topics = append(topics, shardedPrivateContentTopic(TopicShard(identityID, cfg.TopicShards)))
+	}
+	if cfg.legacyTopicHonored(now) {
+		topics = append(topics, privateContentTopic)
+	}
+	return topics
+}
+
+// recentMessageIDs remembers the last few delivered message IDs so a message
+// published on both the legacy and the sharded topic is handled once.
+type recentMessageIDs struct {
+	mu    sync.Mutex
+	limit int
+	seen  map[string]struct{}
+	order []string
+}
+
+func newRecentMessageIDs(limit int) *recentMessageIDs {
+	return &recentMessageIDs{limit: limit, seen: make(map[string]struct{}, limit)}
+}
+
+// firstSeen records id and reports whether it was not seen before.
+func (r *recentMessageIDs) firstSeen(id string) bool {
+	if id == "" {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.seen[id]; ok {
+		return false
+	}
+	r.seen[id] = struct{}{}
+	r.order = append(r.order, id)
+	if len(r.order) > r.limit {
+		delete(r.seen, r.order[0])
+		r.order = r.order[1:]
+	}
+	return true
+}
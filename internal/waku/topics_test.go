@@ -0,0 +1,66 @@
+package waku
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTopicShardIsStableAndBounded(t *testing.T) {
+	counts := map[int]int{}
+	for i := 0; i < 256; i++ {
+		id := "aim1peer" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		shard := TopicShard(id, 8)
+		if shard < 0 || shard >= 8 {
+			t.Fatalf("shard out of range: %d", shard)
+		}
+		if again := TopicShard(id, 8); again != shard {
+			t.Fatalf("shard must be stable: %d != %d", again, shard)
+		}
+		counts[shard]++
+	}
+	if len(counts) < 4 {
+		t.Fatalf("identities should spread across shards, got %v", counts)
+	}
+	if TopicShard("aim1peer", 0) != 0 || TopicShard("aim1peer", 1) != 0 {
+		t.Fatal("unsharded config must map to shard 0")
+	}
+}
+
+func TestPrivateContentTopicsHonorMigrationWindow(t *testing.T) {
+	now := time.Now()
+	legacy := Config{}
+	if got := legacy.privateContentTopics("aim1peer", now); !slices.Equal(got, []string{privateContentTopic}) {
+		t.Fatalf("unsharded config must use the legacy topic only, got %v", got)
+	}
+
+	sharded := Config{TopicShards: 4, TopicMigrationUntil: now.Add(time.Hour)}
+	shardTopic := shardedPrivateContentTopic(TopicShard("aim1peer", 4))
+	if got := sharded.privateContentTopics("aim1peer", now); !slices.Equal(got, []string{shardTopic, privateContentTopic}) {
+		t.Fatalf("migration window must honor both topics, got %v", got)
+	}
+	if got := sharded.privateContentTopics("aim1peer", now.Add(2*time.Hour)); !slices.Equal(got, []string{shardTopic}) {
+		t.Fatalf("after migration only the shard topic is used, got %v", got)
+	}
+}
+
+func TestNormalizeConfigClampsTopicShards(t *testing.T) {
+	if cfg := normalizeConfig(Config{TopicShards: -3}); cfg.TopicShards != 0 {
+		t.Fatalf("negative shards must be clamped, got %d", cfg.TopicShards)
+	}
+	if cfg := normalizeConfig(Config{TopicShards: MaxTopicShards * 2}); cfg.TopicShards != MaxTopicShards {
+		t.Fatalf("shards must be capped, got %d", cfg.TopicShards)
+	}
+}
+
+func TestRecentMessageIDsDropsDuplicates(t *testing.T) {
+	seen := newRecentMessageIDs(2)
+	if !seen.firstSeen("a") || seen.firstSeen("a") {
+		t.Fatal("second copy must be dropped")
+	}
+	seen.firstSeen("b")
+	seen.firstSeen("c")
+	if !seen.firstSeen("a") {
+		t.Fatal("evicted id must be accepted again")
+	}
+}
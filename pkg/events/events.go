@@ -0,0 +1,90 @@
+// Package events defines typed payloads for the daemon's notify.*
+// notifications. Each type marshals to the same JSON as the notification's
+// wire payload, so embedders can switch on Go types instead of probing
+// map[string]any.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+const (
+	MethodMessageNew     = "notify.message.new"
+	MethodRequestNew     = "notify.request.new"
+	MethodNetworkChanged = "notify.network"
+	MethodSecurityAlert  = "notify.security.alert"
+)
+
+// Event is a notification payload that knows its method.
+type Event interface {
+	Method() string
+}
+
+// Notification is one published event with its position in the stream.
+// Resuming a subscription from Seq replays everything after it.
+type Notification struct {
+	Seq       int64
+	Timestamp time.Time
+	Event     Event
+}
+
+// MessageNew reports a message stored in a conversation, including notes to
+// self and messages moved in from an accepted request. Notification carries
+// the conversation's alerting preferences.
+type MessageNew struct {
+	ContactID    string                    `json:"contact_id"`
+	Message      models.Message            `json:"message"`
+	Notification *models.NotificationHints `json:"notification,omitempty"`
+}
+
+func (MessageNew) Method() string { return MethodMessageNew }
+
+// RequestNew reports a message from a sender who is not a contact yet.
+type RequestNew struct {
+	ContactID string         `json:"contact_id"`
+	Message   models.Message `json:"message"`
+}
+
+func (RequestNew) Method() string { return MethodRequestNew }
+
+// NetworkChanged reports a change of the transport status.
+type NetworkChanged struct {
+	models.NetworkStatus
+}
+
+func (NetworkChanged) Method() string { return MethodNetworkChanged }
+
+// SecurityAlert reports a security relevant event for a contact. Message is
+// English; clients re-render it from Kind and MessageArgs.
+type SecurityAlert struct {
+	ContactID   string            `json:"contact_id"`
+	Kind        string            `json:"kind"`
+	Message     string            `json:"message"`
+	MessageArgs map[string]string `json:"message_args,omitempty"`
+}
+
+func (SecurityAlert) Method() string { return MethodSecurityAlert }
+
+// Raw carries notifications that have no typed payload yet.
+type Raw struct {
+	Name    string
+	Payload any
+}
+
+func (r Raw) Method() string { return r.Name }
+
+// MarshalJSON encodes the payload alone, as on the wire.
+func (r Raw) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Payload)
+}
+
+// FromPayload wraps a published payload as an Event.
+func FromPayload(method string, payload any) Event {
+	if evt, ok := payload.(Event); ok && evt.Method() == method {
+		return evt
+	}
+	return Raw{Name: method, Payload: payload}
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/pkg/models"
+)
+
+func TestTypedEventsMarshalLikeLegacyMaps(t *testing.T) {
+	msg := models.Message{ID: "m1", ContactID: "aim1alice", Direction: "in", ContentType: "text", Content: []byte("hi"), Timestamp: time.Unix(1_700_000_000, 0).UTC()}
+	hints := models.NotificationHints{Sound: "chime", Priority: models.NotificationPriorityHigh}
+	status := models.NetworkStatus{Status: "connected", PeerCount: 3}
+	cases := []struct {
+		name   string
+		typed  Event
+		legacy any
+	}{
+		{
+			name:   "message new",
+			typed:  MessageNew{ContactID: "aim1alice", Message: msg, Notification: &hints},
+			legacy: map[string]any{"contact_id": "aim1alice", "message": msg, "notification": hints},
+		},
+		{
+			name:   "request new",
+			typed:  RequestNew{ContactID: "aim1alice", Message: msg},
+			legacy: map[string]any{"contact_id": "aim1alice", "message": msg},
+		},
+		{
+			name:   "network",
+			typed:  NetworkChanged{NetworkStatus: status},
+			legacy: status,
+		},
+		{
+			name:   "security alert",
+			typed:  SecurityAlert{ContactID: "aim1alice", Kind: "key_changed", Message: "key changed", MessageArgs: map[string]string{"count": "2"}},
+			legacy: map[string]any{"kind": "key_changed", "contact_id": "aim1alice", "message": "key changed", "message_args": map[string]string{"count": "2"}},
+		},
+		{
+			name:   "security alert without args",
+			typed:  SecurityAlert{ContactID: "aim1alice", Kind: "key_changed", Message: "key changed"},
+			legacy: map[string]any{"kind": "key_changed", "contact_id": "aim1alice", "message": "key changed"},
+		},
+		{
+			name:   "raw",
+			typed:  FromPayload("notify.message.status", map[string]any{"message_id": "m1", "status": "read"}),
+			legacy: map[string]any{"message_id": "m1", "status": "read"},
+		},
+	}
+	for _, tc := range cases {
+		got, err := json.Marshal(tc.typed)
+		if err != nil {
+			t.Fatalf("%s: marshal typed: %v", tc.name, err)
+		}
+		want, err := json.Marshal(tc.legacy)
+		if err != nil {
+			t.Fatalf("%s: marshal legacy: %v", tc.name, err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("%s: wire shape changed\n got: %s\nwant: %s", tc.name, got, want)
+		}
+	}
+}
+
+func TestFromPayloadKeepsTypedEvents(t *testing.T) {
+	alert := SecurityAlert{Kind: "key_changed"}
+	if got, ok := FromPayload(MethodSecurityAlert, alert).(SecurityAlert); !ok || got.Kind != alert.Kind {
+		t.Fatalf("expected typed event back, got %#v", got)
+	}
+	if got, ok := FromPayload(MethodMessageNew, alert).(Raw); !ok || got.Method() != MethodMessageNew {
+		t.Fatalf("expected a mismatched payload to stay raw, got %#v", got)
+	}
+}
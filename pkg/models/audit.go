@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Audit event kinds recorded in the security audit log.
+const (
+	AuditKindDeviceRevoked        = "device.revoked"
+	AuditKindBlocklistAdded       = "blocklist.added"
+	AuditKindBlocklistRemoved     = "blocklist.removed"
+	AuditKindGroupMemberBlocked   = "group_member.blocked"
+	AuditKindGroupMemberUnblocked = "group_member.unblocked"
+	AuditKindPrivacyModeChanged   = "privacy_mode.changed"
+	AuditKindBackupExported       = "backup.exported"
+	AuditKindNodeBound            = "node.bound"
+	AuditKindNodeUnbound          = "node.unbound"
+	AuditKindHandoffCreated       = "handoff.created"
+	AuditKindHandoffOpened        = "handoff.opened"
+	AuditKindHandoffImported      = "handoff.imported"
+	AuditKindEmergencyBroadcast   = "channel.emergency_broadcast"
+	AuditKindExportConsentGranted = "export_consent.granted"
+	AuditKindConversationExport   = "conversation.exported"
+)
+
+// AuditEvent is one entry of the security audit log. Hash covers every
+// other field, PrevHash included, so each entry vouches for the one before.
+type AuditEvent struct {
+	Seq      int64             `json:"seq"`
+	At       time.Time         `json:"at"`
+	Kind     string            `json:"kind"`
+	Details  map[string]string `json:"details,omitempty"`
+	PrevHash string            `json:"prev_hash"`
+	Hash     string            `json:"hash"`
+}
+
+// AuditLogPage is one page of audit events, newest first. Total counts the
+// events matching the filter before pagination.
+type AuditLogPage struct {
+	Total  int          `json:"total"`
+	Events []AuditEvent `json:"events"`
+}
+
+// AuditVerification reports whether the audit log chain is intact. BrokenAt
+// is the 1-based position of the first entry that fails the check, which
+// is its sequence number in an intact log.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditExportStatus reports the SIEM export of audit events and security
+// alerts. Dropped counts records lost to a full queue or to the target
+// failing while the daemon shut down.
+type AuditExportStatus struct {
+	Enabled     bool      `json:"enabled"`
+	Format      string    `json:"format,omitempty"`
+	Target      string    `json:"target,omitempty"`
+	Queued      int       `json:"queued"`
+	Exported    int64     `json:"exported"`
+	Dropped     int64     `json:"dropped"`
+	WriteErrors int64     `json:"write_errors"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
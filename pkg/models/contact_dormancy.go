@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// DormantContact is a contact nothing was received from or delivered to for
+// the dormancy period while messages to it kept failing. LastActivityAt is
+// the latest such message, or when the contact was added.
+type DormantContact struct {
+	Contact
+	LastActivityAt   time.Time `json:"last_activity_at"`
+	FailedDeliveries int       `json:"failed_deliveries"`
+}
+
+// Cleanup actions suggested for dormant contacts. Suggestions are never
+// applied by the daemon: archiving goes through conversation.flags.set and
+// removal through contact.remove.
+const (
+	ContactCleanupArchiveHistory = "archive_history"
+	ContactCleanupRemoveContact  = "remove_contact"
+)
+
+// ContactCleanupSuggestion lists the cleanup actions worth offering for one
+// dormant contact.
+type ContactCleanupSuggestion struct {
+	ContactID      string    `json:"contact_id"`
+	DisplayName    string    `json:"display_name"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Actions        []string  `json:"actions"`
+}
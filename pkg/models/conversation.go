@@ -5,6 +5,10 @@ import "strings"
 const (
 	ConversationTypeDirect = "direct"
 	ConversationTypeGroup  = "group"
+	// ConversationTypeSaved marks the notes-to-self conversation in chat
+	// listings. Its messages are stored as direct messages addressed to the
+	// user's own identity.
+	ConversationTypeSaved = "saved"
 )
 
 func NormalizeConversationType(raw string) string {
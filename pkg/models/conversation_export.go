@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ExportConsentToken is the one-time local consent the account owner
+// obtains right before exporting a conversation's plaintext.
+type ExportConsentToken struct {
+	ContactID string    `json:"contact_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExportConsent is a participant's signed agreement that ExporterID may
+// export the plaintext of the direct conversation between ExporterID and
+// ContactID. GrantorID is the participant who agreed, either of the two.
+// The grantor's identity key signs every field except Signature.
+type ExportConsent struct {
+	GrantorID  string    `json:"grantor_id"`
+	ExporterID string    `json:"exporter_id"`
+	ContactID  string    `json:"contact_id"`
+	GrantedAt  time.Time `json:"granted_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	PublicKey  string    `json:"public_key"`
+	Signature  string    `json:"signature"`
+}
+
+// ConversationExportRequest redeems a local consent token for an export.
+// With RequirePeerConsent the export fails unless the contact's signed
+// consent has been received.
+type ConversationExportRequest struct {
+	ContactID          string `json:"contact_id"`
+	Token              string `json:"token"`
+	RequirePeerConsent bool   `json:"require_peer_consent,omitempty"`
+}
+
+// ConversationExport is the plaintext of one direct conversation with the
+// consents that allowed exporting it. ContentHash is the hex SHA-256 of the
+// JSON encoding of Messages. The exporter's signature covers every field
+// except itself, so neither the messages nor the consents can be swapped
+// once the bundle leaves the device.
+type ConversationExport struct {
+	SchemaVersion int            `json:"schema_version"`
+	ExporterID    string         `json:"exporter_id"`
+	ContactID     string         `json:"contact_id"`
+	ExportedAt    time.Time      `json:"exported_at"`
+	Messages      []Message      `json:"messages"`
+	ContentHash   string         `json:"content_hash"`
+	LocalConsent  ExportConsent  `json:"local_consent"`
+	PeerConsent   *ExportConsent `json:"peer_consent,omitempty"`
+	PublicKey     string         `json:"public_key"`
+	Signature     string         `json:"signature"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Handoff access actions recorded on a conversation handoff.
+const (
+	HandoffActionOpen   = "open"
+	HandoffActionImport = "import"
+)
+
+// HandoffCreateRequest asks for a handoff of one direct conversation.
+// Consent must be set: it records that the account owner agreed to share
+// the conversation. Zero TTLSeconds and Limit use the daemon defaults.
+type HandoffCreateRequest struct {
+	ContactID  string `json:"contact_id"`
+	ReadOnly   bool   `json:"read_only,omitempty"`
+	Consent    bool   `json:"consent"`
+	Note       string `json:"note,omitempty"`
+	TTLSeconds int64  `json:"ttl_seconds,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// HandoffConsent records who agreed to hand a conversation off and when.
+type HandoffConsent struct {
+	AccountID  string    `json:"account_id"`
+	IdentityID string    `json:"identity_id"`
+	GrantedAt  time.Time `json:"granted_at"`
+}
+
+// HandoffAccess is one open or import of a handoff by an account.
+type HandoffAccess struct {
+	AccountID string    `json:"account_id"`
+	Action    string    `json:"action"`
+	At        time.Time `json:"at"`
+}
+
+// ConversationHandoff is a signed snapshot of one direct conversation that
+// another account on the same daemon, or an operator holding the primary
+// RPC token, can open. Read-only handoffs can be opened but not imported.
+// The signature covers every field except Access.
+type ConversationHandoff struct {
+	ID             string          `json:"id"`
+	ContactID      string          `json:"contact_id"`
+	ContactName    string          `json:"contact_name,omitempty"`
+	FromAccountID  string          `json:"from_account_id"`
+	FromIdentityID string          `json:"from_identity_id"`
+	ReadOnly       bool            `json:"read_only"`
+	Note           string          `json:"note,omitempty"`
+	Messages       []Message       `json:"messages"`
+	Consent        HandoffConsent  `json:"consent"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	PublicKey      string          `json:"public_key"`
+	Signature      string          `json:"signature"`
+	Access         []HandoffAccess `json:"access,omitempty"`
+}
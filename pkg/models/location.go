@@ -0,0 +1,18 @@
+package models
+
+// MessageContentTypeLocation marks a message that shares a location. Its
+// content is the JSON encoding of a MessageLocation, which is also decoded
+// into Message.Location for rendering.
+const MessageContentTypeLocation = "location"
+
+// MaxLocationLabelRunes bounds the place name shown with a shared location.
+const MaxLocationLabelRunes = 200
+
+// MessageLocation is a point shared in a chat. AccuracyMeters is the radius
+// the sender's device was confident about; zero means unknown.
+type MessageLocation struct {
+	Latitude       float64 `json:"lat"`
+	Longitude      float64 `json:"lng"`
+	AccuracyMeters float64 `json:"accuracy,omitempty"`
+	Label          string  `json:"label,omitempty"`
+}
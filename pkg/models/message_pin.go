@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// MaxPinnedMessages bounds the pins kept per conversation.
+const MaxPinnedMessages = 50
+
+// MessagePin records that a message is pinned in its conversation.
+type MessagePin struct {
+	ConversationID   string    `json:"conversation_id"`
+	ConversationType string    `json:"conversation_type"`
+	MessageID        string    `json:"message_id"`
+	PinnedBy         string    `json:"pinned_by"`
+	PinnedAt         time.Time `json:"pinned_at"`
+}
+
+// PinnedMessage is a pin together with the message it refers to.
+type PinnedMessage struct {
+	MessagePin
+	Message Message `json:"message"`
+}
+
+// MessagePinUpdate carries a group pin change to the other members. Every
+// member stores its own copy of a post, so the post is named by its group
+// event id rather than a message id.
+type MessagePinUpdate struct {
+	GroupID string    `json:"group_id"`
+	EventID string    `json:"event_id"`
+	Pinned  bool      `json:"pinned"`
+	At      time.Time `json:"at"`
+}
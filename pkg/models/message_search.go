@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// MessageSearchQuery selects text messages by full-text match. An empty
+// ConversationID searches every conversation; zero Since or Until leaves
+// that end of the date range open.
+type MessageSearchQuery struct {
+	ConversationID string    `json:"conversation_id,omitempty"`
+	Query          string    `json:"query"`
+	Since          time.Time `json:"since,omitempty"`
+	Until          time.Time `json:"until,omitempty"`
+	Limit          int       `json:"limit,omitempty"`
+	Offset         int       `json:"offset,omitempty"`
+}
+
+// MessageSearchHit is one matching message with its relevance score.
+// Higher scores rank first; equal scores rank newer messages first.
+type MessageSearchHit struct {
+	Message Message `json:"message"`
+	Score   float64 `json:"score"`
+}
+
+// MessageSearchResult is one page of hits. Total counts every match before
+// pagination.
+type MessageSearchResult struct {
+	Total int                `json:"total"`
+	Hits  []MessageSearchHit `json:"hits"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMessagePreviewRunes bounds the snippet returned by metadata-only
+// message listings.
+const DefaultMessagePreviewRunes = 120
+
+// MessageSummary is the metadata-only view of a stored message. It carries a
+// short preview instead of the full body, which is fetched via message.get.
+type MessageSummary struct {
+	ID               string       `json:"id"`
+	ContactID        string       `json:"contact_id"`
+	ConversationID   string       `json:"conversation_id,omitempty"`
+	ConversationType string       `json:"conversation_type,omitempty"`
+	ThreadID         string       `json:"thread_id,omitempty"`
+	Timestamp        time.Time    `json:"timestamp"`
+	Direction        string       `json:"direction"`
+	Status           string       `json:"status"`
+	ContentType      string       `json:"content_type"`
+	Edited           bool         `json:"edited"`
+	ContentSize      int          `json:"content_size"`
+	Preview          string       `json:"preview,omitempty"`
+	PreviewTruncated bool         `json:"preview_truncated,omitempty"`
+	SafetyFlags      []SafetyFlag `json:"safety_flags,omitempty"`
+}
+
+// SummarizeMessage builds a summary without copying the message body. Only
+// text content yields a preview; encrypted or binary bodies stay opaque.
+func SummarizeMessage(msg Message, previewRunes int) MessageSummary {
+	summary := MessageSummary{
+		ID:               msg.ID,
+		ContactID:        msg.ContactID,
+		ConversationID:   msg.ConversationID,
+		ConversationType: msg.ConversationType,
+		ThreadID:         msg.ThreadID,
+		Timestamp:        msg.Timestamp,
+		Direction:        msg.Direction,
+		Status:           msg.Status,
+		ContentType:      msg.ContentType,
+		Edited:           msg.Edited,
+		ContentSize:      len(msg.Content),
+		SafetyFlags:      msg.SafetyFlags,
+	}
+	if msg.ContentType != "text" || previewRunes <= 0 {
+		return summary
+	}
+	content := msg.Content
+	end, runes := 0, 0
+	for end < len(content) && runes < previewRunes {
+		_, size := utf8.DecodeRune(content[end:])
+		end += size
+		runes++
+	}
+	summary.Preview = string(content[:end])
+	summary.PreviewTruncated = end < len(content)
+	return summary
+}
@@ -0,0 +1,17 @@
+package models
+
+import "testing"
+
+func TestSummarizeMessageTruncatesTextPreview(t *testing.T) {
+	summary := SummarizeMessage(Message{ID: "m1", Content: []byte("héllo world"), ContentType: "text"}, 5)
+	if summary.Preview != "héllo" || !summary.PreviewTruncated {
+		t.Fatalf("unexpected preview: %q truncated=%v", summary.Preview, summary.PreviewTruncated)
+	}
+	if summary.ContentSize != len("héllo world") {
+		t.Fatalf("unexpected content size: %d", summary.ContentSize)
+	}
+	opaque := SummarizeMessage(Message{ID: "m2", Content: []byte("ciphertext"), ContentType: "e2ee"}, 5)
+	if opaque.Preview != "" {
+		t.Fatalf("encrypted content must not produce a preview, got %q", opaque.Preview)
+	}
+}
@@ -15,11 +15,69 @@ type Identity struct {
 	SigningPublicKey []byte `json:"signing_public_key"`
 }
 
+// PasswordLockoutStatus describes the throttling of seed password attempts.
+// Lockout is set once the short retry delays turned into a longer lockout.
+type PasswordLockoutStatus struct {
+	Locked            bool      `json:"locked"`
+	Lockout           bool      `json:"lockout"`
+	FailedAttempts    int       `json:"failed_attempts"`
+	LockedUntil       time.Time `json:"locked_until,omitempty"`
+	RetryAfterSeconds int64     `json:"retry_after_seconds"`
+	LastFailureAt     time.Time `json:"last_failure_at,omitempty"`
+}
+
 type ContactCard struct {
 	IdentityID  string `json:"identity_id"`
 	DisplayName string `json:"display_name"`
 	PublicKey   []byte `json:"public_key"`
 	Signature   []byte `json:"signature"`
+	// PowDifficulty is the proof-of-work, in leading zero bits, this identity
+	// requires on first-contact messages. Zero means no stamp is required.
+	PowDifficulty int `json:"pow_difficulty,omitempty"`
+	// Username is a self-asserted handle. Nothing enforces global
+	// uniqueness; clashes are only detected among one's own contacts.
+	Username string `json:"username,omitempty"`
+	// Capabilities lists the optional wire features the identity's client
+	// supports, sorted.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Attestations are third-party statements vouching for the identity.
+	// They are checked by the receiver; the card only carries them.
+	Attestations []Attestation `json:"attestations,omitempty"`
+}
+
+// Attestation kinds.
+const (
+	// AttestationKindDomain points at a domain that lists the identity in a
+	// well-known file served over HTTPS.
+	AttestationKindDomain = "domain"
+	// AttestationKindAuthority is a signature by a community authority key
+	// published in the trust bundle.
+	AttestationKindAuthority = "authority"
+)
+
+// Attestation is one external statement vouching for an identity. Subject is
+// the domain for domain attestations and the authority key id otherwise;
+// Signature is only set for authority attestations.
+type Attestation struct {
+	Kind      string `json:"kind"`
+	Subject   string `json:"subject"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// SafetyFlag is an advisory warning about inbound content. Kind names the
+// check that fired; Detail narrows it to a domain, attachment or reason.
+type SafetyFlag struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SafetyFeedStatus describes the signed bad-link feed currently applied.
+type SafetyFeedStatus struct {
+	Version     int64     `json:"version"`
+	IssuedAt    time.Time `json:"issued_at,omitempty"`
+	KeyID       string    `json:"key_id,omitempty"`
+	DomainCount int       `json:"domain_count"`
+	AppliedAt   time.Time `json:"applied_at,omitempty"`
 }
 
 type Contact struct {
@@ -28,20 +86,166 @@ type Contact struct {
 	PublicKey   []byte    `json:"public_key"`
 	AddedAt     time.Time `json:"added_at"`
 	LastSeen    time.Time `json:"last_seen"`
+	// CardName is the display name advertised by the contact's latest
+	// verified card; DisplayName may be a local alias.
+	CardName        string    `json:"card_name,omitempty"`
+	CardRefreshedAt time.Time `json:"card_refreshed_at,omitempty"`
+	CardStale       bool      `json:"card_stale,omitempty"`
+	// PowDifficulty is the first-contact proof-of-work advertised by the
+	// contact's latest verified card.
+	PowDifficulty int `json:"pow_difficulty,omitempty"`
+	// SafetyFlags are local warnings about the contact's card, such as a
+	// display name built to impersonate someone.
+	SafetyFlags []SafetyFlag `json:"safety_flags,omitempty"`
+	// MergedInto is set once the contact moved to a new identity; its thread
+	// is kept read-only and sends go to the new id. MergedFrom lists the
+	// earlier ids whose history belongs to this contact.
+	MergedInto string   `json:"merged_into,omitempty"`
+	MergedFrom []string `json:"merged_from,omitempty"`
+	// Username is the handle claimed by the contact's latest verified card.
+	// UsernameConflict is set while another contact claims the same one.
+	Username         string `json:"username,omitempty"`
+	UsernameConflict bool   `json:"username_conflict,omitempty"`
+	// Capabilities are the wire features advertised by the latest verified
+	// card.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Attestations are the ones carried by the latest verified card.
+	// Verified is set while one of them checks out; VerifiedSource names it
+	// as "<kind>:<subject>".
+	Attestations   []Attestation `json:"attestations,omitempty"`
+	Verified       bool          `json:"verified,omitempty"`
+	VerifiedSource string        `json:"verified_source,omitempty"`
+	VerifiedAt     time.Time     `json:"verified_at,omitempty"`
+	// Alias, Notes and Color are kept locally and never leave the device or
+	// touch the signed card. A set Alias is what DisplayName shows.
+	Alias string `json:"alias,omitempty"`
+	Notes string `json:"notes,omitempty"`
+	Color string `json:"color,omitempty"`
+	// DisplayLabel is the name to show under the local username display
+	// preference. It is computed for listings and never persisted.
+	DisplayLabel string `json:"display_label,omitempty"`
+}
+
+// Username display preferences for contact listings.
+const (
+	UsernameDisplayName     = "display_name"
+	UsernameDisplayUsername = "username"
+	UsernameDisplayBoth     = "both"
+)
+
+// DeviceCapabilities are the wire features one contact device advertised on
+// its latest wire.
+type DeviceCapabilities struct {
+	DeviceID     string    `json:"device_id,omitempty"`
+	Capabilities []string  `json:"capabilities"`
+	SeenAt       time.Time `json:"seen_at"`
+}
+
+// ContactCapabilities is what is known about the features a contact
+// supports. Effective is the set every known device supports, falling back
+// to the card when no device has been seen; Known is false when neither
+// source advertised anything and callers should assume only the baseline.
+type ContactCapabilities struct {
+	ContactID string               `json:"contact_id"`
+	Known     bool                 `json:"known"`
+	Card      []string             `json:"card,omitempty"`
+	Devices   []DeviceCapabilities `json:"devices,omitempty"`
+	Effective []string             `json:"effective"`
+}
+
+// IdentityTransition announces that an identity moved to a new identity id,
+// e.g. one of a newer id version after a key type change.
+// NewCard is self-signed by the new key and Signature is made by the old key,
+// so the statement proves control of both.
+type IdentityTransition struct {
+	OldID     string      `json:"old_id"`
+	NewCard   ContactCard `json:"new_card"`
+	IssuedAt  time.Time   `json:"issued_at"`
+	Signature []byte      `json:"signature"`
+}
+
+// PowStamp is a proof-of-work attached to first-contact messages. Its hash
+// binds the sender, recipient, message id, timestamp and nonce.
+type PowStamp struct {
+	Bits      int    `json:"bits"`
+	Timestamp int64  `json:"timestamp"`
+	Nonce     uint64 `json:"nonce"`
 }
 
 type Message struct {
-	ID               string    `json:"id"`
-	ContactID        string    `json:"contact_id"`
-	ConversationID   string    `json:"conversation_id,omitempty"`
-	ConversationType string    `json:"conversation_type,omitempty"`
-	ThreadID         string    `json:"thread_id,omitempty"`
-	Content          []byte    `json:"content"`
-	Timestamp        time.Time `json:"timestamp"`
-	Direction        string    `json:"direction"`
-	Status           string    `json:"status"`
-	ContentType      string    `json:"content_type"`
-	Edited           bool      `json:"edited"`
+	ID               string              `json:"id"`
+	ContactID        string              `json:"contact_id"`
+	ConversationID   string              `json:"conversation_id,omitempty"`
+	ConversationType string              `json:"conversation_type,omitempty"`
+	ThreadID         string              `json:"thread_id,omitempty"`
+	EventID          string              `json:"event_id,omitempty"`
+	Content          []byte              `json:"content"`
+	Timestamp        time.Time           `json:"timestamp"`
+	Direction        string              `json:"direction"`
+	Status           string              `json:"status"`
+	ContentType      string              `json:"content_type"`
+	Edited           bool                `json:"edited"`
+	Attachments      []MessageAttachment `json:"attachments,omitempty"`
+	Tip              *MessageTip         `json:"tip,omitempty"`
+	// Location is set on location messages.
+	Location *MessageLocation `json:"location,omitempty"`
+	// Emergency marks a channel owner's emergency broadcast. Recipients
+	// alert for it even when the channel is muted.
+	Emergency bool `json:"emergency,omitempty"`
+	// SafetyFlags are local warnings raised when the message was received.
+	SafetyFlags []SafetyFlag `json:"safety_flags,omitempty"`
+	// Seq numbers direct messages per sender device and recipient so the
+	// recipient can notice messages that never arrived.
+	Seq uint64 `json:"seq,omitempty"`
+	// RelayedAt, DeliveredAt and ReadAt record when the network accepted
+	// the message, when the recipient's device acknowledged it and when it
+	// was read. Each is stamped once, independently of the others.
+	RelayedAt   time.Time `json:"relayed_at,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+	ReadAt      time.Time `json:"read_at,omitempty"`
+}
+
+// MessageContentTypeSystem marks locally generated timeline entries such as
+// group membership changes. They are never sent and never acknowledged.
+const MessageContentTypeSystem = "system"
+
+const (
+	PaymentPurposeFirstContact = "first_contact"
+	PaymentPurposeTip          = "tip"
+)
+
+// PaymentProof is an opaque payment attestation carried with a message, such
+// as a paid invoice or an on-chain transaction reference. Core never
+// interprets Proof; a registered payment verifier does.
+type PaymentProof struct {
+	Purpose string `json:"purpose"`
+	Method  string `json:"method"`
+	Amount  int64  `json:"amount,omitempty"`
+	Unit    string `json:"unit,omitempty"`
+	Proof   []byte `json:"proof"`
+}
+
+// MessageTip records a tip received with a message. Verified is set only when
+// a payment verifier accepted the proof.
+type MessageTip struct {
+	Method   string `json:"method"`
+	Amount   int64  `json:"amount,omitempty"`
+	Unit     string `json:"unit,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// MaxAttachmentAltTextRunes bounds the accessibility description stored on
+// attachments and carried with messages.
+const MaxAttachmentAltTextRunes = 1000
+
+// MessageAttachment describes an attachment referenced by a message. AltText
+// lets screen-reader-capable clients describe the attachment.
+type MessageAttachment struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	AltText  string `json:"alt_text,omitempty"`
 }
 
 type Settings struct {
@@ -68,6 +272,87 @@ type NetworkStatus struct {
 	BootstrapSource          string    `json:"bootstrap_source,omitempty"`
 	BootstrapManifestVersion int       `json:"bootstrap_manifest_version,omitempty"`
 	BootstrapManifestKeyID   string    `json:"bootstrap_manifest_key_id,omitempty"`
+	// ClockOffsetMS is how far the median peer clock is ahead of ours.
+	// It is informational; local time is never adjusted.
+	ClockOffsetMS   int64  `json:"clock_offset_ms"`
+	ClockPeers      int    `json:"clock_peers"`
+	ClockConfidence string `json:"clock_confidence"`
+}
+
+// ManifestFetchResult reports an operator-forced manifest refresh. Manifest
+// and TrustBundle are "updated", "not_modified" or "skipped"; Source is the
+// bootstrap source active afterwards.
+type ManifestFetchResult struct {
+	Manifest        string    `json:"manifest"`
+	TrustBundle     string    `json:"trust_bundle"`
+	Attempts        int       `json:"attempts"`
+	FetchedAt       time.Time `json:"fetched_at"`
+	Source          string    `json:"source"`
+	ManifestVersion int       `json:"manifest_version,omitempty"`
+}
+
+// StoreNodeStatus reports the community store mode. The store holds relayed
+// encrypted envelopes only and never has access to message plaintext.
+type StoreNodeStatus struct {
+	Enabled             bool     `json:"enabled"`
+	Transport           string   `json:"transport"`
+	Topics              []string `json:"topics"`
+	StoredMessages      int      `json:"stored_messages"`
+	StoredBytes         int64    `json:"stored_bytes"`
+	QuotaBytes          int64    `json:"quota_bytes"`
+	RetentionSeconds    int64    `json:"retention_seconds"`
+	QueriesPerMinute    int      `json:"queries_per_minute"`
+	AcceptedTotal       int64    `json:"accepted_total"`
+	RejectedTopic       int64    `json:"rejected_topic"`
+	RejectedQuota       int64    `json:"rejected_quota"`
+	RejectedSenderQuota int64    `json:"rejected_sender_quota"`
+	QueriesServed       int64    `json:"queries_served"`
+	QueriesRateLimited  int64    `json:"queries_rate_limited"`
+}
+
+// BootstrapNodeStats is one bootstrap node in the order the daemon dials
+// them on startup. Rank starts at 1. Latencies are from the node's last
+// probe; PublishLatencyMS is the ping round trip over its connection.
+type BootstrapNodeStats struct {
+	Address          string    `json:"address"`
+	Rank             int       `json:"rank"`
+	Measured         bool      `json:"measured"`
+	Healthy          bool      `json:"healthy"`
+	ConnectLatencyMS int64     `json:"connect_latency_ms,omitempty"`
+	PublishLatencyMS int64     `json:"publish_latency_ms,omitempty"`
+	LastError        string    `json:"last_error,omitempty"`
+	MeasuredAt       time.Time `json:"measured_at,omitempty"`
+}
+
+// BootstrapStats reports the latency ranking of the bootstrap nodes.
+type BootstrapStats struct {
+	Source    string               `json:"source,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at,omitempty"`
+	Nodes     []BootstrapNodeStats `json:"nodes"`
+}
+
+// MailboxSenderUsage is what one sender keeps in the bound node's mailbox.
+type MailboxSenderUsage struct {
+	SenderID string    `json:"sender_id"`
+	Messages int       `json:"messages"`
+	Bytes    int64     `json:"bytes"`
+	OldestAt time.Time `json:"oldest_at"`
+}
+
+// MailboxStatus reports the store-and-forward mailbox of the bound node.
+// Byte counts cover stored envelopes, not database overhead.
+type MailboxStatus struct {
+	NodeID              string               `json:"node_id"`
+	Eviction            string               `json:"eviction"`
+	SenderQuotaMB       int                  `json:"sender_quota_mb"`
+	QuotaBytes          int64                `json:"quota_bytes"`
+	RetentionSeconds    int64                `json:"retention_seconds"`
+	StoredMessages      int                  `json:"stored_messages"`
+	StoredBytes         int64                `json:"stored_bytes"`
+	Senders             []MailboxSenderUsage `json:"senders"`
+	EvictedTotal        int64                `json:"evicted_total"`
+	PurgedTotal         int64                `json:"purged_total"`
+	RejectedSenderQuota int64                `json:"rejected_sender_quota"`
 }
 
 type SessionState struct {
@@ -85,15 +370,93 @@ type MetricsSnapshot struct {
 	PendingQueueSize       int                        `json:"pending_queue_size"`
 	ErrorCounters          map[string]int             `json:"error_counters"`
 	GroupAggregates        map[string]int             `json:"group_aggregates,omitempty"`
+	InboundLimitViolations map[string]int             `json:"inbound_limit_violations,omitempty"`
 	NetworkMetrics         map[string]int             `json:"network_metrics"`
 	DiskUsageByClass       map[string]int64           `json:"disk_usage_by_class,omitempty"`
 	GCEvictionCountByClass map[string]int             `json:"gc_eviction_count_by_class,omitempty"`
 	BlobFetchStats         BlobFetchMetric            `json:"blob_fetch_stats,omitempty"`
 	StorageGuardrails      map[string]int             `json:"storage_guardrails,omitempty"`
+	StorageIntegrity       map[string]int             `json:"storage_integrity,omitempty"`
+	BlobAnnouncements      map[string]int             `json:"blob_announcements,omitempty"`
 	OperationStats         map[string]OperationMetric `json:"operation_stats"`
 	RetryAttemptsTotal     int                        `json:"retry_attempts_total"`
 	LastUpdatedAt          time.Time                  `json:"last_updated_at"`
 	NotificationBacklog    int                        `json:"notification_backlog"`
+	DeliveryLatency        DeliveryLatencyMetric      `json:"delivery_latency"`
+}
+
+// MetricsPoint is one rolled-up bucket of a metrics series. Gauges such as
+// peer_count are read from Avg, Min and Max; counters such as errors from Sum.
+type MetricsPoint struct {
+	At      time.Time `json:"at"`
+	Avg     float64   `json:"avg"`
+	Min     float64   `json:"min"`
+	Max     float64   `json:"max"`
+	Sum     float64   `json:"sum"`
+	Samples int       `json:"samples"`
+}
+
+type MetricsSeries struct {
+	Name   string         `json:"name"`
+	Points []MetricsPoint `json:"points"`
+}
+
+// MetricsQueryResult is the answer to metrics.query.
+type MetricsQueryResult struct {
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"`
+	Resolution string          `json:"resolution"`
+	Series     []MetricsSeries `json:"series"`
+}
+
+// LatencyPercentiles summarizes a bounded window of latency samples.
+type LatencyPercentiles struct {
+	Samples int   `json:"samples"`
+	AvgMs   int64 `json:"avg_ms"`
+	P50Ms   int64 `json:"p50_ms"`
+	P90Ms   int64 `json:"p90_ms"`
+	P99Ms   int64 `json:"p99_ms"`
+	MaxMs   int64 `json:"max_ms"`
+}
+
+// ContactDeliveryStats reports how outbound messages to one contact fare:
+// time from send to delivered and read receipts, terminal failures and the
+// retry backlog still waiting to go out.
+type ContactDeliveryStats struct {
+	ContactID      string             `json:"contact_id"`
+	SentTotal      int                `json:"sent_total"`
+	DeliveredTotal int                `json:"delivered_total"`
+	ReadTotal      int                `json:"read_total"`
+	FailedTotal    int                `json:"failed_total"`
+	FailureRateBps int64              `json:"failure_rate_bps"`
+	PendingBacklog int                `json:"pending_backlog"`
+	Delivered      LatencyPercentiles `json:"delivered"`
+	Read           LatencyPercentiles `json:"read"`
+	LastReceiptAt  time.Time          `json:"last_receipt_at,omitempty"`
+}
+
+// DeliveryLatencyMetric aggregates delivery statistics across all contacts.
+type DeliveryLatencyMetric struct {
+	Contacts       int                `json:"contacts"`
+	SentTotal      int                `json:"sent_total"`
+	FailedTotal    int                `json:"failed_total"`
+	FailureRateBps int64              `json:"failure_rate_bps"`
+	Delivered      LatencyPercentiles `json:"delivered"`
+	Read           LatencyPercentiles `json:"read"`
+}
+
+// DailySummary is the local activity report published as
+// notify.daily.summary. StorageGrowthBytes is measured against the previous
+// summary, or against daemon start for the first one, and may be negative.
+type DailySummary struct {
+	WindowStart        time.Time `json:"window_start"`
+	WindowEnd          time.Time `json:"window_end"`
+	MessagesSent       int       `json:"messages_sent"`
+	MessagesReceived   int       `json:"messages_received"`
+	NewRequests        int       `json:"new_requests"`
+	FailedDeliveries   int       `json:"failed_deliveries"`
+	StorageBytes       int64     `json:"storage_bytes"`
+	StorageGrowthBytes int64     `json:"storage_growth_bytes"`
 }
 
 type OperationMetric struct {
@@ -104,6 +467,16 @@ type OperationMetric struct {
 	LastLatencyMs int64 `json:"last_latency_ms"`
 }
 
+// LatencyHistogram is a Prometheus-style latency histogram. Counts are
+// cumulative per upper bound; Count also includes latencies above the last
+// bound.
+type LatencyHistogram struct {
+	Count            uint64    `json:"count"`
+	SumSeconds       float64   `json:"sum_seconds"`
+	UpperBounds      []float64 `json:"upper_bounds"`
+	CumulativeCounts []uint64  `json:"cumulative_counts"`
+}
+
 type BlobFetchMetric struct {
 	AttemptsTotal      int            `json:"attempts_total"`
 	SuccessTotal       int            `json:"success_total"`
@@ -117,13 +490,16 @@ type BlobFetchMetric struct {
 }
 
 type Device struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	PublicKey []byte    `json:"public_key"`
-	CertSig   []byte    `json:"cert_sig"`
-	CreatedAt time.Time `json:"created_at"`
-	IsRevoked bool      `json:"is_revoked"`
-	RevokedAt time.Time `json:"revoked_at,omitempty"`
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	PublicKey       []byte    `json:"public_key"`
+	CertSig         []byte    `json:"cert_sig"`
+	SelfSigningKey  []byte    `json:"self_signing_key,omitempty"`
+	SelfSigningSig  []byte    `json:"self_signing_sig,omitempty"`
+	CertFingerprint string    `json:"cert_fingerprint,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	IsRevoked       bool      `json:"is_revoked"`
+	RevokedAt       time.Time `json:"revoked_at,omitempty"`
 }
 
 type DeviceRevocation struct {
@@ -142,6 +518,83 @@ type MessageReceipt struct {
 type MessageStatus struct {
 	MessageID string `json:"message_id"`
 	Status    string `json:"status"`
+	// Progress of a direct message: accepted by the network, acknowledged
+	// by the recipient's device and read. Unset stages have not happened.
+	RelayedAt   time.Time `json:"relayed_at,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+	ReadAt      time.Time `json:"read_at,omitempty"`
+	// Aggregated receipts for outbound group messages. Delivered includes
+	// recipients that have already read the message.
+	RecipientCount int                      `json:"recipient_count"`
+	DeliveredCount int                      `json:"delivered_count"`
+	ReadCount      int                      `json:"read_count"`
+	PendingCount   int                      `json:"pending_count"`
+	FailedCount    int                      `json:"failed_count"`
+	Recipients     []MessageRecipientStatus `json:"recipients,omitempty"`
+}
+
+type MessageRecipientStatus struct {
+	MemberID  string `json:"member_id"`
+	MessageID string `json:"message_id"`
+	Status    string `json:"status"`
+}
+
+// Outbox categories group outbound messages that still need attention.
+// Scheduled messages wait in the retry queue for their next attempt;
+// sent_unread ones left the device but have not been read yet.
+const (
+	OutboxPending    = "pending"
+	OutboxScheduled  = "scheduled"
+	OutboxFailed     = "failed"
+	OutboxSentUnread = "sent_unread"
+)
+
+// OutboxEntry is one outbound message in the global outbox view.
+type OutboxEntry struct {
+	Message    MessageSummary `json:"message"`
+	Category   string         `json:"category"`
+	RetryCount int            `json:"retry_count,omitempty"`
+	NextRetry  time.Time      `json:"next_retry,omitempty"`
+	LastError  string         `json:"last_error,omitempty"`
+}
+
+// OutboxBulkResult reports a bulk retry or cancel: the ids that were
+// handled and the error for each one that was not.
+type OutboxBulkResult struct {
+	Succeeded []string          `json:"succeeded"`
+	Failed    map[string]string `json:"failed,omitempty"`
+}
+
+// RetryPolicy controls how outbound messages that failed to publish are
+// retried. The delay doubles from BaseIntervalMS up to MaxIntervalMS and is
+// varied by up to JitterRatio either way. After MaxAttempts retries a
+// message moves to the dead-letter queue, or with DeadLetter off is marked
+// failed and dropped from the queue.
+type RetryPolicy struct {
+	BaseIntervalMS int64   `json:"base_interval_ms"`
+	MaxIntervalMS  int64   `json:"max_interval_ms"`
+	JitterRatio    float64 `json:"jitter_ratio"`
+	MaxAttempts    int     `json:"max_attempts"`
+	DeadLetter     bool    `json:"dead_letter"`
+}
+
+// DeadLetter is an outbound message that ran out of retries. It stays out
+// of the retry queue until requeued.
+type DeadLetter struct {
+	Message   Message   `json:"message"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+	DeadAt    time.Time `json:"dead_at"`
+}
+
+// PendingFlushResult reports an immediate flush of a contact's queued
+// messages. Messages that could not be sent stay in the retry queue.
+type PendingFlushResult struct {
+	ContactID string `json:"contact_id"`
+	Attempted int    `json:"attempted"`
+	Sent      int    `json:"sent"`
+	Pending   int    `json:"pending"`
+	Failed    int    `json:"failed"`
 }
 
 type AttachmentClass string
@@ -151,6 +604,62 @@ const (
 	AttachmentClassFile  AttachmentClass = "file"
 )
 
+// ChatMediaItem is one attachment-bearing message in a conversation's shared
+// media gallery. Attachments holds only those of the requested class.
+type ChatMediaItem struct {
+	MessageID        string                `json:"message_id"`
+	ConversationID   string                `json:"conversation_id"`
+	ConversationType string                `json:"conversation_type"`
+	ContactID        string                `json:"contact_id,omitempty"`
+	ThreadID         string                `json:"thread_id,omitempty"`
+	Direction        string                `json:"direction"`
+	Timestamp        time.Time             `json:"timestamp"`
+	Attachments      []ChatMediaAttachment `json:"attachments"`
+}
+
+// ChatMediaAttachment describes an attachment in the gallery. ThumbnailURL is
+// set for images and is served by the local RPC server.
+type ChatMediaAttachment struct {
+	MessageAttachment
+	Class        AttachmentClass `json:"class"`
+	ThumbnailURL string          `json:"thumbnail_url,omitempty"`
+}
+
+// AttachmentThumbnail is a downscaled JPEG preview of an image attachment.
+type AttachmentThumbnail struct {
+	BlobID   string `json:"blob_id"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// AttachmentDownload describes a blob served in chunks. ChunkSHA256 lists the
+// hex SHA-256 of every chunk in order, so a client can verify each chunk as it
+// arrives. The hashes depend only on the blob and ChunkSize: after an
+// interruption, or once the download expires, the client opens a new one with
+// the same chunk size and continues from the first chunk it does not have.
+type AttachmentDownload struct {
+	DownloadID   string    `json:"download_id"`
+	AttachmentID string    `json:"attachment_id"`
+	Name         string    `json:"name"`
+	MimeType     string    `json:"mime_type"`
+	TotalSize    int64     `json:"total_size"`
+	ChunkSize    int       `json:"chunk_size"`
+	TotalChunks  int       `json:"total_chunks"`
+	FileSHA256   string    `json:"file_sha256"`
+	ChunkSHA256  []string  `json:"chunk_sha256"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// AttachmentDownloadChunk is one chunk of an AttachmentDownload.
+type AttachmentDownloadChunk struct {
+	DownloadID  string `json:"download_id"`
+	ChunkIndex  int    `json:"chunk_index"`
+	DataBase64  string `json:"data_base64"`
+	ChunkSHA256 string `json:"chunk_sha256"`
+}
+
 type AttachmentPinState string
 
 const (
@@ -162,11 +671,29 @@ type AttachmentMeta struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
 	MimeType     string    `json:"mime_type"`
+	SniffedMime  string    `json:"sniffed_mime_type,omitempty"`
+	MimeWarnings []string  `json:"mime_warnings,omitempty"`
 	Class        string    `json:"class,omitempty"`
 	LastAccessAt time.Time `json:"last_access_at,omitempty"`
 	PinState     string    `json:"pin_state,omitempty"`
+	AltText      string    `json:"alt_text,omitempty"`
+	// ThumbnailID names the blob holding a downscaled preview of an image.
+	ThumbnailID  string    `json:"thumbnail_id,omitempty"`
+	GraceSeconds int64     `json:"grace_seconds,omitempty"`
 	Size         int64     `json:"size"`
 	CreatedAt    time.Time `json:"created_at"`
+	// ACL replaces the node-wide blob ACL policy for this blob when set.
+	ACL *BlobACLOverride `json:"acl,omitempty"`
+}
+
+// AttachmentExpiry reports when the storage policy TTL deletes an attachment.
+type AttachmentExpiry struct {
+	BlobID       string    `json:"blob_id"`
+	Name         string    `json:"name"`
+	Class        string    `json:"class"`
+	Size         int64     `json:"size"`
+	GraceSeconds int64     `json:"grace_seconds,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 type BlobProviderInfo struct {
@@ -186,6 +713,23 @@ type BlobACLPolicy struct {
 	Enforced  bool     `json:"enforced"`
 }
 
+// BlobACLOverride restricts who may fetch one blob: nobody but the owner
+// (owner_only), the listed identities (identities) or the active members of
+// one group (group_members).
+type BlobACLOverride struct {
+	Mode       string    `json:"mode"`
+	Identities []string  `json:"identities,omitempty"`
+	GroupID    string    `json:"group_id,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// BlobACLOverrideState is the override of one blob; Override is nil when the
+// node-wide policy applies.
+type BlobACLOverrideState struct {
+	BlobID   string           `json:"blob_id"`
+	Override *BlobACLOverride `json:"override"`
+}
+
 type BlobNodePresetConfig struct {
 	Preset                     string `json:"preset"`
 	ProfileID                  string `json:"profile_id,omitempty"`
@@ -215,13 +759,19 @@ type BlobNodePresetConfig struct {
 	HighWatermarkPercent       int    `json:"high_watermark_percent"`
 	FullCapPercent             int    `json:"full_cap_percent"`
 	AggressiveTargetPercent    int    `json:"aggressive_target_percent"`
+	AnnounceBatchSize          int    `json:"announce_batch_size"`
+	AnnounceBatchIntervalSec   int    `json:"announce_batch_interval_sec"`
+	AnnounceJitterPercent      int    `json:"announce_jitter_percent"`
+	ProviderRecordTTLSec       int    `json:"provider_record_ttl_sec"`
 }
 
 type NodePersonalPolicy struct {
-	StoreEnabled bool `json:"store_enabled"`
-	TTLDays      int  `json:"ttl_days,omitempty"`
-	QuotaMB      int  `json:"quota_mb,omitempty"`
-	PinEnabled   bool `json:"pin_enabled,omitempty"`
+	StoreEnabled         bool   `json:"store_enabled"`
+	TTLDays              int    `json:"ttl_days,omitempty"`
+	QuotaMB              int    `json:"quota_mb,omitempty"`
+	PinEnabled           bool   `json:"pin_enabled,omitempty"`
+	MailboxSenderQuotaMB int    `json:"mailbox_sender_quota_mb,omitempty"`
+	MailboxEviction      string `json:"mailbox_eviction,omitempty"`
 }
 
 type NodePublicPolicy struct {
@@ -240,10 +790,12 @@ type NodePolicies struct {
 }
 
 type NodePersonalPolicyPatch struct {
-	StoreEnabled *bool `json:"store_enabled,omitempty"`
-	TTLDays      *int  `json:"ttl_days,omitempty"`
-	QuotaMB      *int  `json:"quota_mb,omitempty"`
-	PinEnabled   *bool `json:"pin_enabled,omitempty"`
+	StoreEnabled         *bool   `json:"store_enabled,omitempty"`
+	TTLDays              *int    `json:"ttl_days,omitempty"`
+	QuotaMB              *int    `json:"quota_mb,omitempty"`
+	PinEnabled           *bool   `json:"pin_enabled,omitempty"`
+	MailboxSenderQuotaMB *int    `json:"mailbox_sender_quota_mb,omitempty"`
+	MailboxEviction      *string `json:"mailbox_eviction,omitempty"`
 }
 
 type NodePublicPolicyPatch struct {
@@ -321,6 +873,147 @@ type DiagnosticsEvent struct {
 	Message    string    `json:"message"`
 }
 
+type SecurityAlert struct {
+	Kind       string    `json:"kind"`
+	ContactID  string    `json:"contact_id,omitempty"`
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type PeerDeviceSecurity struct {
+	DeviceID          string    `json:"device_id"`
+	Name              string    `json:"name,omitempty"`
+	Fingerprint       string    `json:"fingerprint"`
+	VerificationState string    `json:"verification_state"` // cross_signed, legacy, revoked
+	FirstSeenAt       time.Time `json:"first_seen_at"`
+}
+
+// SeqRange is an inclusive range of per-sender message sequence numbers.
+type SeqRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// HistoryGap is a run of messages from one sender device that never arrived.
+type HistoryGap struct {
+	DeviceID            string    `json:"device_id,omitempty"`
+	From                uint64    `json:"from"`
+	To                  uint64    `json:"to"`
+	DetectedAt          time.Time `json:"detected_at"`
+	BackfillRequestedAt time.Time `json:"backfill_requested_at,omitempty"`
+	BackfillAttempts    int       `json:"backfill_attempts"`
+}
+
+// InboundSequence tracks the messages received from one sender device.
+type InboundSequence struct {
+	Highest uint64       `json:"highest"`
+	Gaps    []HistoryGap `json:"gaps,omitempty"`
+}
+
+// SavedMessage is a note to self copied between the user's own devices.
+type SavedMessage struct {
+	ID          string              `json:"id"`
+	ThreadID    string              `json:"thread_id,omitempty"`
+	Content     []byte              `json:"content"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	Location    *MessageLocation    `json:"location,omitempty"`
+}
+
+// Snippet is a canned response. Its body may contain {{name}} placeholders
+// that are filled in when the snippet is sent.
+type Snippet struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Body         string   `json:"body"`
+	Placeholders []string `json:"placeholders"`
+	// GroupID limits the snippet to one group conversation.
+	GroupID string `json:"group_id,omitempty"`
+	// Private snippets are visible only to the RPC caller that saved them,
+	// which lets each bot integration keep its own set.
+	Private   bool      `json:"private"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateSendRequest sends an expanded snippet to exactly one direct or
+// group conversation.
+type TemplateSendRequest struct {
+	SnippetID string            `json:"snippet_id"`
+	ContactID string            `json:"contact_id,omitempty"`
+	GroupID   string            `json:"group_id,omitempty"`
+	Values    map[string]string `json:"values,omitempty"`
+}
+
+// Attachment policy modes for inbound attachments from one contact.
+const (
+	AttachmentPolicyAllow      = "allow"
+	AttachmentPolicyImagesOnly = "images_only"
+	AttachmentPolicyBlock      = "block"
+)
+
+// ContactAttachmentPolicy limits the attachments accepted from one contact.
+// Attachments that fail the mode or size limit are dropped before anything
+// is fetched or stored; with RequireApproval the rest wait for approval.
+type ContactAttachmentPolicy struct {
+	ContactID       string    `json:"contact_id"`
+	Mode            string    `json:"mode"`
+	MaxSizeBytes    int64     `json:"max_size_bytes,omitempty"`
+	RequireApproval bool      `json:"require_approval,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at,omitempty"`
+}
+
+// HeldAttachment is an inbound attachment whose blob is not fetched until
+// the user approves it.
+type HeldAttachment struct {
+	ContactID  string            `json:"contact_id"`
+	MessageID  string            `json:"message_id"`
+	Attachment MessageAttachment `json:"attachment"`
+	HeldAt     time.Time         `json:"held_at"`
+}
+
+// SharingProfile says which signals about the user a contact may receive.
+// The zero value shares nothing; the default profile shares everything.
+type SharingProfile struct {
+	Presence         bool `json:"presence"`
+	ReadReceipts     bool `json:"read_receipts"`
+	ProfilePhoto     bool `json:"profile_photo"`
+	TypingIndicators bool `json:"typing_indicators"`
+}
+
+// ContactSharingProfile is the sharing profile in effect for one contact.
+// An empty ContactID stands for the default profile, which applies to every
+// contact without one of its own; Inherited marks contacts using it.
+type ContactSharingProfile struct {
+	ContactID string    `json:"contact_id,omitempty"`
+	Inherited bool      `json:"inherited,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	SharingProfile
+}
+
+// HistoryBackfillRequest asks a contact's device to send the messages with
+// the given sequence numbers again.
+type HistoryBackfillRequest struct {
+	DeviceID string     `json:"device_id,omitempty"`
+	Ranges   []SeqRange `json:"ranges"`
+}
+
+type ChatSecurityInfo struct {
+	ContactID         string               `json:"contact_id"`
+	E2EEActive        bool                 `json:"e2ee_active"`
+	SessionID         string               `json:"session_id,omitempty"`
+	SessionCreatedAt  time.Time            `json:"session_created_at,omitempty"`
+	SessionAgeSeconds int64                `json:"session_age_seconds"`
+	SendChainIndex    uint64               `json:"send_chain_index"`
+	RecvChainIndex    uint64               `json:"recv_chain_index"`
+	ContactVerified   bool                 `json:"contact_verified"`
+	PeerDeviceCount   int                  `json:"peer_device_count"`
+	PeerDevices       []PeerDeviceSecurity `json:"peer_devices"`
+	RecentAlerts      []SecurityAlert      `json:"recent_alerts"`
+	// HistoryGaps lists messages from the contact that are known to be
+	// missing and have not been recovered by backfill.
+	HistoryGaps []HistoryGap `json:"history_gaps"`
+}
+
 func ClassifyAttachmentMime(mimeType string) AttachmentClass {
 	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
 	if strings.HasPrefix(mimeType, "image/") {
@@ -349,3 +1042,216 @@ type BlockSenderResult struct {
 	RequestRemoved bool     `json:"request_removed"`
 	ContactExists  bool     `json:"contact_exists"`
 }
+
+// ClientStateEntry is an opaque value persisted by client applications, such
+// as per-chat UI state. The backend never interprets Value.
+type ClientStateEntry struct {
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MessageAnnotation is a private label attached to a message by a local
+// integration. Annotations stay on this device and are never sent to peers.
+type MessageAnnotation struct {
+	MessageID string    `json:"message_id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Conversation flags synced between the user's own devices.
+const (
+	ConversationFlagPinned   = "pinned"
+	ConversationFlagArchived = "archived"
+	ConversationFlagMuted    = "muted"
+	ConversationFlagDraft    = "draft"
+	ConversationFlagLanguage = "language"
+
+	ConversationFlagNotificationSound    = "notification_sound"
+	ConversationFlagNotificationPriority = "notification_priority"
+)
+
+// Notification priority classes a conversation can ask clients to use. An
+// empty priority leaves the choice to the client.
+const (
+	NotificationPriorityLow    = "low"
+	NotificationPriorityNormal = "normal"
+	NotificationPriorityHigh   = "high"
+)
+
+// NotificationHints tell every client frontend how to alert for a new
+// message, so alerting matches across devices sharing one daemon.
+type NotificationHints struct {
+	Sound    string `json:"sound,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Muted    bool   `json:"muted,omitempty"`
+}
+
+// SyncRegister is a last-writer-wins register. Concurrent writes with the same
+// timestamp are ordered by device id so every device picks the same winner.
+type SyncRegister struct {
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeviceID  string    `json:"device_id"`
+}
+
+// ConversationSyncState holds the synced registers of one conversation, keyed
+// by ConversationFlag* names.
+type ConversationSyncState struct {
+	ConversationID string                  `json:"conversation_id"`
+	Registers      map[string]SyncRegister `json:"registers"`
+}
+
+// ConversationFlags is the resolved view of a ConversationSyncState.
+type ConversationFlags struct {
+	ConversationID string    `json:"conversation_id"`
+	Pinned         bool      `json:"pinned"`
+	Archived       bool      `json:"archived"`
+	Muted          bool      `json:"muted"`
+	Draft          string    `json:"draft,omitempty"`
+	Language       string    `json:"language,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// NotificationSound names a client sound; NotificationPriority is one of
+	// the NotificationPriority classes. Empty values use client defaults.
+	NotificationSound    string `json:"notification_sound,omitempty"`
+	NotificationPriority string `json:"notification_priority,omitempty"`
+}
+
+// ConversationFlagsUpdate changes the flags that are set and leaves nil ones
+// untouched.
+type ConversationFlagsUpdate struct {
+	Pinned   *bool   `json:"pinned,omitempty"`
+	Archived *bool   `json:"archived,omitempty"`
+	Muted    *bool   `json:"muted,omitempty"`
+	Draft    *string `json:"draft,omitempty"`
+	Language *string `json:"language,omitempty"`
+
+	NotificationSound    *string `json:"notification_sound,omitempty"`
+	NotificationPriority *string `json:"notification_priority,omitempty"`
+}
+
+// GroupAvatar is a resized group avatar image addressed by its SHA-256.
+// Group.Avatar references it as "sha256:<hash>".
+type GroupAvatar struct {
+	GroupID   string    `json:"group_id,omitempty"`
+	SHA256    string    `json:"sha256"`
+	MimeType  string    `json:"mime_type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Data      []byte    `json:"data,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Where a chat's language hint comes from.
+const (
+	ChatLanguageSourceManual   = "manual"
+	ChatLanguageSourceDetected = "detected"
+)
+
+// ChatSummary is one entry of chat.list. Language is a hint for client
+// spellcheckers, either set by the user or detected locally from recent
+// messages.
+type ChatSummary struct {
+	ConversationID   string `json:"conversation_id"`
+	ConversationType string `json:"conversation_type"`
+	Title            string `json:"title,omitempty"`
+	Language         string `json:"language,omitempty"`
+	LanguageSource   string `json:"language_source,omitempty"`
+	Pinned           bool   `json:"pinned"`
+	Archived         bool   `json:"archived"`
+	Muted            bool   `json:"muted"`
+	Draft            string `json:"draft,omitempty"`
+
+	NotificationSound    string `json:"notification_sound,omitempty"`
+	NotificationPriority string `json:"notification_priority,omitempty"`
+}
+
+const (
+	StorageKeyRotationIdle      = "idle"
+	StorageKeyRotationRunning   = "running"
+	StorageKeyRotationCompleted = "completed"
+	StorageKeyRotationFailed    = "failed"
+)
+
+// StorageKeyRotationStatus reports the progress of a background storage
+// re-encryption started by storage.rotate_key.
+type StorageKeyRotationStatus struct {
+	State      string    `json:"state"`
+	FilesDone  int       `json:"files_done"`
+	FilesTotal int       `json:"files_total"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	// UnreadableFiles lists files, relative to the data dir, that opened with
+	// neither the old nor the new key and were left as they were.
+	UnreadableFiles []string `json:"unreadable_files,omitempty"`
+}
+
+const (
+	StorageSnapshotReasonManual     = "manual"
+	StorageSnapshotReasonPreUpgrade = "pre_upgrade"
+	StorageSnapshotReasonPreRestore = "pre_restore"
+	StorageSnapshotReasonMigration  = "pre_migration"
+)
+
+// StorageSnapshot describes a point-in-time copy of the daemon data dir kept
+// for rolling back after a bad upgrade or a corruption event.
+type StorageSnapshot struct {
+	ID         string    `json:"id"`
+	Reason     string    `json:"reason"`
+	Label      string    `json:"label,omitempty"`
+	AppVersion string    `json:"app_version,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Files      int       `json:"files"`
+	SizeBytes  int64     `json:"size_bytes"`
+}
+
+// StorageSchemaVersion reports the on-disk format of one store of an account
+// profile. Profile is the profile dir relative to the data dir.
+type StorageSchemaVersion struct {
+	Profile   string `json:"profile"`
+	Store     string `json:"store"`
+	Present   bool   `json:"present"`
+	Version   int    `json:"version"`
+	Supported int    `json:"supported"`
+}
+
+// StorageVersionInfo is returned by storage.version.
+type StorageVersionInfo struct {
+	AppVersion string                 `json:"app_version,omitempty"`
+	Stores     []StorageSchemaVersion `json:"stores"`
+}
+
+// StorageSnapshotRestoreResult reports a completed storage.snapshot.restore.
+// Safety is the snapshot of the state that was replaced.
+type StorageSnapshotRestoreResult struct {
+	Restored StorageSnapshot `json:"restored"`
+	Safety   StorageSnapshot `json:"safety"`
+}
+
+// StorageCorruption is a store record whose checksum did not match. Missing
+// is set when the record was listed in the checksums but is gone. SnapshotID
+// names the snapshot a restored record was copied from.
+type StorageCorruption struct {
+	Profile    string `json:"profile"`
+	Store      string `json:"store"`
+	Field      string `json:"field"`
+	Record     string `json:"record"`
+	Missing    bool   `json:"missing,omitempty"`
+	WholeFile  bool   `json:"whole_file,omitempty"`
+	Restored   bool   `json:"restored"`
+	SnapshotID string `json:"snapshot_id,omitempty"`
+}
+
+// StorageScrubReport summarizes one integrity pass over every store.
+// Unverified records were written before checksums existed.
+type StorageScrubReport struct {
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	Stores     int                 `json:"stores"`
+	Records    int                 `json:"records"`
+	Unverified int                 `json:"unverified"`
+	Corrupt    []StorageCorruption `json:"corrupt,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PluginInfo describes a configured message-processing plugin. Capabilities
+// lists what the plugin may do: those it declared that the configuration also
+// grants.
+type PluginInfo struct {
+	Name         string    `json:"name"`
+	Version      string    `json:"version,omitempty"`
+	Capabilities []string  `json:"capabilities"`
+	Enabled      bool      `json:"enabled"`
+	TimeoutMS    int64     `json:"timeout_ms"`
+	LastError    string    `json:"last_error,omitempty"`
+	LastErrorAt  time.Time `json:"last_error_at,omitempty"`
+}
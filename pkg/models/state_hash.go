@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// ConversationStateHash summarises one conversation's message set. Root is
+// the hex Merkle root over the conversation's messages.
+type ConversationStateHash struct {
+	ConversationID   string `json:"conversation_id"`
+	ConversationType string `json:"conversation_type"`
+	MessageCount     int    `json:"message_count"`
+	Root             string `json:"root"`
+}
+
+// StateHashes summarises a device's local state so two devices can find
+// what differs without exchanging the state itself. Root covers the settings
+// root and every conversation root.
+type StateHashes struct {
+	DeviceID      string                  `json:"device_id,omitempty"`
+	ComputedAt    time.Time               `json:"computed_at"`
+	Root          string                  `json:"root"`
+	SettingsRoot  string                  `json:"settings_root,omitempty"`
+	Conversations []ConversationStateHash `json:"conversations"`
+	// ReplyRequested asks the receiver to answer with its own hashes.
+	ReplyRequested bool `json:"reply_requested,omitempty"`
+}
+
+// StateDivergence records what differed from the hashes another of the
+// user's devices, or a conversation peer, last sent, so sync can repair
+// exactly those conversations.
+type StateDivergence struct {
+	PeerID           string    `json:"peer_id"`
+	DeviceID         string    `json:"device_id,omitempty"`
+	ComparedAt       time.Time `json:"compared_at"`
+	SettingsDiverged bool      `json:"settings_diverged,omitempty"`
+	Conversations    []string  `json:"conversations"`
+}
@@ -0,0 +1,185 @@
+package testsupport
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"aim-chat/go-backend/internal/composition/daemonservice"
+	"aim-chat/go-backend/internal/domains/contracts"
+	"aim-chat/go-backend/pkg/models"
+)
+
+var (
+	ErrDirectoryNameTaken   = errors.New("testsupport: directory name is taken")
+	ErrDirectoryInvalidName = errors.New("testsupport: directory name is empty")
+)
+
+// Directory is an in-process identity directory. Every daemon of a cluster
+// is registered under its name, and cards of identities outside the cluster
+// can be added with Register.
+type Directory struct {
+	mu      sync.RWMutex
+	cards   map[string]models.ContactCard
+	daemons map[string]*Daemon
+}
+
+var _ contracts.IdentityDirectory = (*Directory)(nil)
+
+func NewDirectory() *Directory {
+	return &Directory{
+		cards:   map[string]models.ContactCard{},
+		daemons: map[string]*Daemon{},
+	}
+}
+
+// Register publishes card under name. Names are case-insensitive and can
+// only be taken once.
+func (d *Directory) Register(name string, card models.ContactCard) error {
+	return d.register(name, card, nil)
+}
+
+// Resolve returns the card registered under name.
+func (d *Directory) Resolve(name string) (models.ContactCard, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	card, ok := d.cards[directoryKey(name)]
+	return card, ok
+}
+
+// Names returns the registered names in order.
+func (d *Directory) Names() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	names := make([]string, 0, len(d.cards))
+	for name := range d.cards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Daemon returns the in-process daemon registered under name, if the name
+// belongs to one.
+func (d *Directory) Daemon(name string) (*Daemon, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	daemon, ok := d.daemons[directoryKey(name)]
+	return daemon, ok
+}
+
+func (d *Directory) register(name string, card models.ContactCard, daemon *Daemon) error {
+	key := directoryKey(name)
+	if key == "" {
+		return ErrDirectoryInvalidName
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, taken := d.cards[key]; taken {
+		return ErrDirectoryNameTaken
+	}
+	d.cards[key] = card
+	if daemon != nil {
+		d.daemons[key] = daemon
+	}
+	return nil
+}
+
+func directoryKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Directory returns the directory the daemons of the cluster are registered
+// in.
+func (c *Cluster) Directory() *Directory {
+	return c.directory
+}
+
+// Lookup resolves name to a daemon of the cluster and fails the test if no
+// daemon has that name.
+func (c *Cluster) Lookup(name string) *Daemon {
+	c.t.Helper()
+	d, ok := c.directory.Daemon(name)
+	if !ok {
+		c.t.Fatalf("testsupport: no daemon named %q", name)
+	}
+	return d
+}
+
+// Connect makes every pair of the named daemons mutual contacts, so a group
+// scenario needs one call instead of one per pair.
+func (c *Cluster) Connect(names ...string) {
+	c.t.Helper()
+	daemons := make([]*Daemon, 0, len(names))
+	for _, name := range names {
+		daemons = append(daemons, c.Lookup(name))
+	}
+	for i := range daemons {
+		for _, other := range daemons[i+1:] {
+			c.MakeMutualContacts(daemons[i], other)
+		}
+	}
+}
+
+// AddContactByName adds the card registered under name to the contacts of d
+// without the reverse direction, as a one-sided add from a directory
+// lookup would.
+func (c *Cluster) AddContactByName(d *Daemon, name string) models.ContactCard {
+	c.t.Helper()
+	card, ok := c.directory.Resolve(name)
+	if !ok {
+		c.t.Fatalf("testsupport: %q is not in the directory", name)
+	}
+	if err := d.AddContactCard(card); err != nil {
+		c.t.Fatalf("testsupport: %s add %s: %v", d.Name, name, err)
+	}
+	return card
+}
+
+// BindNode binds a fresh node key to the identity of d the way a node
+// enrolls through a link code. It returns the binding record.
+func (c *Cluster) BindNode(d *Daemon, nodeID string) models.NodeBindingRecord {
+	c.t.Helper()
+	link, err := d.svc.CreateNodeBindingLinkCode(0)
+	if err != nil {
+		c.t.Fatalf("testsupport: %s binding link: %v", d.Name, err)
+	}
+	nodePub, nodePriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		c.t.Fatalf("testsupport: node key: %v", err)
+	}
+	signature := ed25519.Sign(nodePriv, daemonservice.NodeBindingChallenge(link, nodeID))
+	record, err := d.svc.CompleteNodeBinding(
+		link.LinkCode,
+		nodeID,
+		base64.StdEncoding.EncodeToString(nodePub),
+		base64.StdEncoding.EncodeToString(signature),
+		true,
+	)
+	if err != nil {
+		c.t.Fatalf("testsupport: %s bind node %s: %v", d.Name, nodeID, err)
+	}
+	return record
+}
+
+// NewRelay builds a daemon that serves as a public relay: relaying, public
+// store and serving are on and it is connected to the mock transport. It is
+// registered in the directory like any other daemon.
+func (c *Cluster) NewRelay(name string) *Daemon {
+	c.t.Helper()
+	d := c.NewDaemon(name)
+	enabled := true
+	if _, err := d.UpdateNodePolicies(models.NodePoliciesPatch{Public: &models.NodePublicPolicyPatch{
+		RelayEnabled:   &enabled,
+		StoreEnabled:   &enabled,
+		ServingEnabled: &enabled,
+	}}); err != nil {
+		c.t.Fatalf("testsupport: %s relay policies: %v", name, err)
+	}
+	c.StartNetworking(d)
+	return d
+}
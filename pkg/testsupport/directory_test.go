@@ -0,0 +1,67 @@
+package testsupport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDirectoryResolvesClusterDaemonsByName(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	alice := cluster.NewDaemon("alice")
+	bob := cluster.NewDaemon("bob")
+	carol := cluster.NewDaemon("carol")
+
+	dir := cluster.Directory()
+	if names := dir.Names(); len(names) != 3 || names[0] != "alice" || names[2] != "carol" {
+		t.Fatalf("unexpected directory names: %v", names)
+	}
+	if card, ok := dir.Resolve(" Bob "); !ok || card.IdentityID != bob.Identity.ID {
+		t.Fatalf("bob must resolve case-insensitively: %+v %v", card, ok)
+	}
+	if cluster.Lookup("carol") != carol {
+		t.Fatal("lookup must return the registered daemon")
+	}
+	if err := dir.Register("ALICE", bob.Card); !errors.Is(err, ErrDirectoryNameTaken) {
+		t.Fatalf("expected taken name to be refused, got %v", err)
+	}
+
+	cluster.Connect("alice", "bob", "carol")
+	cluster.StartNetworking(alice, bob, carol)
+	if _, err := carol.SendMessage(context.Background(), alice.Identity.ID, "hi from carol"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	cluster.WaitFor(5*time.Second, "alice to receive carol's message", func() bool {
+		messages, err := alice.GetMessages(carol.Identity.ID, 10, 0)
+		return err == nil && len(messages) == 1
+	})
+}
+
+func TestClusterSimulatesBoundNodesAndRelays(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	alice := cluster.NewDaemon("alice")
+	record := cluster.BindNode(alice, "alice-node")
+	if record.NodeID != "alice-node" || record.IdentityID != alice.Identity.ID {
+		t.Fatalf("unexpected binding: %+v", record)
+	}
+	if stored, bound, err := alice.GetNodeBinding(); err != nil || !bound || stored.NodeID != "alice-node" {
+		t.Fatalf("binding must be stored: %+v %v %v", stored, bound, err)
+	}
+
+	relay := cluster.NewRelay("relay-1")
+	policies := relay.GetNodePolicies()
+	if !policies.Public.RelayEnabled || !policies.Public.StoreEnabled || !policies.Public.ServingEnabled {
+		t.Fatalf("relay must enable public relaying: %+v", policies.Public)
+	}
+	if status := relay.GetNetworkStatus(); status.Status != "connected" || !status.PublicStoreEnabled {
+		t.Fatalf("relay must be connected with its public store on: %+v", status)
+	}
+	if card := cluster.AddContactByName(alice, "relay-1"); card.IdentityID != relay.Identity.ID {
+		t.Fatalf("unexpected relay card: %+v", card)
+	}
+}
@@ -0,0 +1,258 @@
+// Package testsupport runs fully wired daemons in-process for integration
+// tests of code that embeds the daemon service. Every daemon uses the mock
+// transport and a throwaway data directory, so tests need neither a network
+// nor a real home directory.
+package testsupport
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"aim-chat/go-backend/internal/composition/daemonservice"
+	"aim-chat/go-backend/internal/domains/contracts"
+	runtimeapp "aim-chat/go-backend/internal/platform/runtime"
+	"aim-chat/go-backend/internal/waku"
+	"aim-chat/go-backend/pkg/models"
+)
+
+const stopTimeout = 5 * time.Second
+
+// Daemon is one in-process daemon. It runs the same service a production
+// embedder builds and offers the calls tests drive it with, in terms of
+// pkg/models only; Identity and Card describe its self identity.
+type Daemon struct {
+	Name     string
+	DataDir  string
+	Identity models.Identity
+	Card     models.ContactCard
+
+	svc *daemonservice.Service
+}
+
+// SendMessage sends content to the contact contactID.
+func (d *Daemon) SendMessage(ctx context.Context, contactID, content string) (string, error) {
+	return d.svc.SendMessage(ctx, contactID, content)
+}
+
+// GetMessages lists the conversation with contactID, newest last.
+func (d *Daemon) GetMessages(contactID string, limit, offset int) ([]models.Message, error) {
+	return d.svc.GetMessages(contactID, limit, offset)
+}
+
+func (d *Daemon) GetContacts() ([]models.Contact, error) {
+	return d.svc.GetContacts()
+}
+
+func (d *Daemon) AddContactCard(card models.ContactCard) error {
+	return d.svc.AddContactCard(card)
+}
+
+func (d *Daemon) GetNodeBinding() (models.NodeBindingRecord, bool, error) {
+	return d.svc.GetNodeBinding()
+}
+
+func (d *Daemon) GetNodePolicies() models.NodePolicies {
+	return d.svc.GetNodePolicies()
+}
+
+func (d *Daemon) UpdateNodePolicies(patch models.NodePoliciesPatch) (models.NodePolicies, error) {
+	return d.svc.UpdateNodePolicies(patch)
+}
+
+func (d *Daemon) GetNetworkStatus() models.NetworkStatus {
+	return d.svc.GetNetworkStatus()
+}
+
+// Cluster owns a set of daemons created for one test and stops them when the
+// test ends. It also keeps the virtual clock AdvanceRetryClock moves and the
+// directory that names its daemons.
+type Cluster struct {
+	t         testing.TB
+	baseDir   string
+	cfg       waku.Config
+	directory *Directory
+
+	mu      sync.Mutex
+	daemons []*Daemon
+	clock   time.Time
+	ids     *runtimeapp.SequentialIDGenerator
+}
+
+func NewCluster(t testing.TB) *Cluster {
+	t.Helper()
+	cfg := waku.DefaultConfig()
+	cfg.Transport = waku.TransportMock
+	c := &Cluster{
+		t:         t,
+		baseDir:   t.TempDir(),
+		cfg:       cfg,
+		directory: NewDirectory(),
+		clock:     time.Now(),
+	}
+	t.Cleanup(c.stopAll)
+	return c
+}
+
+// UseDeterministicIDs makes daemons created afterwards, and DeliverWire,
+// number their ids in call order instead of drawing them at random, so a
+// scripted run produces the same ids every time. The daemons share one
+// counter: ids never repeat within the cluster, where inbound dedup would
+// drop a message whose id another daemon already used.
+func (c *Cluster) UseDeterministicIDs() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil {
+		c.ids = runtimeapp.NewSequentialIDGenerator()
+	}
+}
+
+// NewDaemon builds a daemon whose self card advertises name as its display
+// name and registers it in the cluster directory under that name.
+// Networking is left stopped; see StartNetworking.
+func (c *Cluster) NewDaemon(name string) *Daemon {
+	c.t.Helper()
+	dataDir := filepath.Join(c.baseDir, name)
+	svc, err := daemonservice.NewServiceForDaemonWithOptions(c.cfg, dataDir, contracts.ServiceOptions{GenerateID: c.idGenerator()})
+	if err != nil {
+		c.t.Fatalf("testsupport: new daemon %s: %v", name, err)
+	}
+	identity, err := svc.GetIdentity()
+	if err != nil {
+		c.t.Fatalf("testsupport: %s identity: %v", name, err)
+	}
+	card, err := svc.SelfContactCard(name)
+	if err != nil {
+		c.t.Fatalf("testsupport: %s self card: %v", name, err)
+	}
+	d := &Daemon{Name: name, DataDir: dataDir, Identity: identity, Card: card, svc: svc}
+	if err := c.directory.register(name, card, d); err != nil {
+		c.t.Fatalf("testsupport: register %s: %v", name, err)
+	}
+	c.mu.Lock()
+	c.daemons = append(c.daemons, d)
+	c.mu.Unlock()
+	return d
+}
+
+// StartNetworking connects daemons to the shared mock transport. They stay
+// connected until the test ends.
+func (c *Cluster) StartNetworking(daemons ...*Daemon) {
+	c.t.Helper()
+	for _, d := range daemons {
+		if err := d.svc.StartNetworking(context.Background()); err != nil {
+			c.t.Fatalf("testsupport: %s start networking: %v", d.Name, err)
+		}
+	}
+}
+
+// MakeMutualContacts adds a and b to each other's contacts with their signed
+// cards and opens the session between them, as a completed first contact
+// would.
+func (c *Cluster) MakeMutualContacts(a, b *Daemon) {
+	c.t.Helper()
+	if err := a.AddContactCard(b.Card); err != nil {
+		c.t.Fatalf("testsupport: %s add %s: %v", a.Name, b.Name, err)
+	}
+	if err := b.AddContactCard(a.Card); err != nil {
+		c.t.Fatalf("testsupport: %s add %s: %v", b.Name, a.Name, err)
+	}
+	// Both sides must seed the session with the same key; the lower id's
+	// key is the one the daemons agree on.
+	sessionKey := b.Card.PublicKey
+	if a.Identity.ID <= b.Identity.ID {
+		sessionKey = a.Card.PublicKey
+	}
+	if _, err := a.svc.InitSession(b.Identity.ID, sessionKey); err != nil {
+		c.t.Fatalf("testsupport: %s session with %s: %v", a.Name, b.Name, err)
+	}
+	if _, err := b.svc.InitSession(a.Identity.ID, sessionKey); err != nil {
+		c.t.Fatalf("testsupport: %s session with %s: %v", b.Name, a.Name, err)
+	}
+}
+
+// DeliverText signs a plain text message from from and hands it straight to
+// the inbound pipeline of to, bypassing the transport. It returns the message
+// id used. Delivery is synchronous: once it returns, to has processed it.
+func (c *Cluster) DeliverText(from, to *Daemon, text string) string {
+	c.t.Helper()
+	return c.deliverWire(from, to, contracts.WirePayload{Kind: "plain", Plain: []byte(text)})
+}
+
+func (c *Cluster) deliverWire(from, to *Daemon, wire contracts.WirePayload) string {
+	c.t.Helper()
+	generateID := c.idGenerator()
+	if generateID == nil {
+		generateID = runtimeapp.GeneratePrefixedID
+	}
+	messageID, err := generateID("test")
+	if err != nil {
+		c.t.Fatalf("testsupport: message id: %v", err)
+	}
+	msg, err := from.svc.SignWire(messageID, to.Identity.ID, wire)
+	if err != nil {
+		c.t.Fatalf("testsupport: %s sign wire for %s: %v", from.Name, to.Name, err)
+	}
+	to.svc.HandleIncomingPrivateMessage(msg)
+	return messageID
+}
+
+// AdvanceRetryClock moves the cluster clock forward by d and runs one retry
+// loop pass on every daemon at the new time. It returns the new time.
+func (c *Cluster) AdvanceRetryClock(d time.Duration) time.Time {
+	c.mu.Lock()
+	c.clock = c.clock.Add(d)
+	now := c.clock
+	daemons := append([]*Daemon(nil), c.daemons...)
+	c.mu.Unlock()
+	for _, daemon := range daemons {
+		daemon.svc.RunRetryTick(now)
+	}
+	return now
+}
+
+// Now returns the cluster clock.
+func (c *Cluster) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clock
+}
+
+// WaitFor polls cond until it holds and fails the test after timeout.
+func (c *Cluster) WaitFor(timeout time.Duration, what string, cond func() bool) {
+	c.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if cond() {
+			return
+		}
+		if time.Now().After(deadline) {
+			c.t.Fatalf("testsupport: timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// idGenerator returns the shared sequential generator, or nil for the
+// service default.
+func (c *Cluster) idGenerator() func(prefix string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ids == nil {
+		return nil
+	}
+	return c.ids.GeneratePrefixedID
+}
+
+func (c *Cluster) stopAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	c.mu.Lock()
+	daemons := append([]*Daemon(nil), c.daemons...)
+	c.mu.Unlock()
+	for _, d := range daemons {
+		_ = d.svc.StopNetworking(ctx)
+	}
+}
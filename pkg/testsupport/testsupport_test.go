@@ -0,0 +1,92 @@
+package testsupport
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClusterDeliversMessagesBetweenMutualContacts(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	alice := cluster.NewDaemon("alice")
+	bob := cluster.NewDaemon("bob")
+	cluster.MakeMutualContacts(alice, bob)
+	cluster.StartNetworking(alice, bob)
+
+	if _, err := alice.SendMessage(context.Background(), bob.Identity.ID, "hello over the mock transport"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	cluster.WaitFor(5*time.Second, "bob to receive the message", func() bool {
+		messages, err := bob.GetMessages(alice.Identity.ID, 10, 0)
+		return err == nil && len(messages) == 1 && string(messages[0].Content) == "hello over the mock transport"
+	})
+}
+
+func TestDeliverWireIsSynchronous(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	alice := cluster.NewDaemon("alice")
+	bob := cluster.NewDaemon("bob")
+	cluster.MakeMutualContacts(alice, bob)
+
+	id := cluster.DeliverText(alice, bob, "direct")
+	messages, err := bob.GetMessages(alice.Identity.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("list messages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ID != id || string(messages[0].Content) != "direct" {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+}
+
+func TestAdvanceRetryClockDrivesScheduledWork(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	alice := cluster.NewDaemon("alice")
+	bob := cluster.NewDaemon("bob")
+	cluster.MakeMutualContacts(alice, bob)
+
+	// Without networking the card refresh cannot be answered, so a month
+	// later the contact is flagged stale.
+	start := cluster.Now()
+	if now := cluster.AdvanceRetryClock(31 * 24 * time.Hour); !now.Equal(start.Add(31 * 24 * time.Hour)) {
+		t.Fatalf("clock did not advance: start=%v now=%v", start, now)
+	}
+	contacts, err := alice.GetContacts()
+	if err != nil {
+		t.Fatalf("contacts: %v", err)
+	}
+	if len(contacts) != 1 || !contacts[0].CardStale {
+		t.Fatalf("expected bob to be flagged stale: %+v", contacts)
+	}
+}
+
+func TestDeterministicIDsAreSharedAcrossTheCluster(t *testing.T) {
+	t.Parallel()
+
+	cluster := NewCluster(t)
+	cluster.UseDeterministicIDs()
+	alice := cluster.NewDaemon("alice")
+	bob := cluster.NewDaemon("bob")
+	cluster.MakeMutualContacts(alice, bob)
+
+	first, err := alice.SendMessage(context.Background(), bob.Identity.ID, "one")
+	if err != nil {
+		t.Fatalf("alice send: %v", err)
+	}
+	second, err := bob.SendMessage(context.Background(), alice.Identity.ID, "two")
+	if err != nil {
+		t.Fatalf("bob send: %v", err)
+	}
+	if !strings.HasPrefix(first, "msg_0000") || !strings.HasPrefix(second, "msg_0000") {
+		t.Fatalf("expected sequential ids, got %q and %q", first, second)
+	}
+	if first >= second {
+		t.Fatalf("expected ids to keep counting across daemons, got %q then %q", first, second)
+	}
+}